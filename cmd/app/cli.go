@@ -0,0 +1,404 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/jmoloko/taskmange/internal/app"
+	"github.com/jmoloko/taskmange/internal/cache"
+	"github.com/jmoloko/taskmange/internal/config"
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/jmoloko/taskmange/internal/domain/repository"
+	"github.com/jmoloko/taskmange/internal/logger"
+	"github.com/jmoloko/taskmange/internal/repository/postgres"
+	"github.com/jmoloko/taskmange/internal/service"
+	"github.com/redis/go-redis/v9"
+	"github.com/spf13/cobra"
+)
+
+// newRootCommand команды бинарника: без подкоманды запускается HTTP-сервер,
+// остальные команды выполняются операторами напрямую против базы
+func newRootCommand() *cobra.Command {
+	root := &cobra.Command{
+		Use:          "app",
+		Short:        "Task Management API server and operator commands",
+		SilenceUsage: true,
+		Args:         cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			serve()
+		},
+	}
+
+	root.AddCommand(
+		&cobra.Command{
+			Use:   "serve",
+			Short: "Start the HTTP server and background jobs",
+			Args:  cobra.NoArgs,
+			Run: func(cmd *cobra.Command, args []string) {
+				serve()
+			},
+		},
+		newUserCommand(),
+		newTaskCommand(),
+		newMigrateCommand(),
+		newExportUserCommand(),
+		newImportUserCommand(),
+	)
+	return root
+}
+
+// cliEnv конфигурация и соединение с базой для команд оператора
+type cliEnv struct {
+	cfg    *config.Config
+	db     *sql.DB
+	logger logger.Logger
+}
+
+// openCLIEnv загружает конфигурацию и подключается к базе. Логи сервисов ниже уровня warn
+// не выводятся, чтобы не смешиваться с выводом команды
+func openCLIEnv() (*cliEnv, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load configuration: %w", err)
+	}
+	cfg.Logger.Level = "warn"
+
+	db, err := postgres.NewPostgresDB(cfg.Database)
+	if err != nil {
+		return nil, err
+	}
+
+	return &cliEnv{cfg: cfg, db: db, logger: logger.NewSLogLogger(cfg.Logger)}, nil
+}
+
+func (e *cliEnv) Close() {
+	e.db.Close()
+	e.logger.Close()
+}
+
+func (e *cliEnv) authService() (*service.AuthService, error) {
+	passwords, err := app.NewPasswordHasher(e.cfg.Auth)
+	if err != nil {
+		return nil, err
+	}
+	policy, err := app.NewPasswordPolicy(e.cfg.Auth, e.logger)
+	if err != nil {
+		return nil, err
+	}
+	return service.NewAuthService(postgres.NewUserRepository(e.db), nil, passwords, policy, e.logger, e.cfg.Auth.SigningKey), nil
+}
+
+func newUserCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "user",
+		Short: "Manage user accounts",
+	}
+
+	var email, password string
+	createAdmin := &cobra.Command{
+		Use:   "create-admin",
+		Short: "Create a user with the admin role",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			env, err := openCLIEnv()
+			if err != nil {
+				return err
+			}
+			defer env.Close()
+
+			auth, err := env.authService()
+			if err != nil {
+				return err
+			}
+
+			user, err := auth.CreateUser(cmd.Context(), models.RegisterRequest{Email: email, Password: password})
+			if err != nil {
+				return err
+			}
+			if err := postgres.NewUserRepository(env.db).SetRole(cmd.Context(), user.ID, models.RoleAdmin); err != nil {
+				return err
+			}
+
+			cmd.Printf("Created admin user %s (%s)\n", user.ID, user.Email)
+			return nil
+		},
+	}
+	createAdmin.Flags().StringVar(&email, "email", "", "user email")
+	createAdmin.Flags().StringVar(&password, "password", "", "user password, must satisfy the password policy")
+	_ = createAdmin.MarkFlagRequired("email")
+	_ = createAdmin.MarkFlagRequired("password")
+
+	var resetEmail, resetPassword string
+	reset := &cobra.Command{
+		Use:   "reset-password",
+		Short: "Set a new password for a user",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			env, err := openCLIEnv()
+			if err != nil {
+				return err
+			}
+			defer env.Close()
+
+			auth, err := env.authService()
+			if err != nil {
+				return err
+			}
+
+			user, err := auth.ResetPassword(cmd.Context(), resetEmail, resetPassword)
+			if err != nil {
+				return err
+			}
+
+			cmd.Printf("Password of user %s (%s) has been reset\n", user.ID, user.Email)
+			return nil
+		},
+	}
+	reset.Flags().StringVar(&resetEmail, "email", "", "user email")
+	reset.Flags().StringVar(&resetPassword, "password", "", "new password, must satisfy the password policy")
+	_ = reset.MarkFlagRequired("email")
+	_ = reset.MarkFlagRequired("password")
+
+	var roleEmail, role string
+	setRole := &cobra.Command{
+		Use:   "set-role",
+		Short: "Set the role of a user (user or admin), effective from the next login",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !models.ValidRole(role) {
+				return fmt.Errorf("unknown role %q, expected %s or %s", role, models.RoleUser, models.RoleAdmin)
+			}
+
+			env, err := openCLIEnv()
+			if err != nil {
+				return err
+			}
+			defer env.Close()
+
+			users := postgres.NewUserRepository(env.db)
+			user, err := users.GetByEmail(cmd.Context(), roleEmail)
+			if err != nil {
+				return service.ErrUserNotFound
+			}
+			if err := users.SetRole(cmd.Context(), user.ID, role); err != nil {
+				return err
+			}
+
+			cmd.Printf("User %s (%s) now has the %s role\n", user.ID, user.Email, role)
+			return nil
+		},
+	}
+	setRole.Flags().StringVar(&roleEmail, "email", "", "user email")
+	setRole.Flags().StringVar(&role, "role", "", "new role: user or admin")
+	_ = setRole.MarkFlagRequired("email")
+	_ = setRole.MarkFlagRequired("role")
+
+	cmd.AddCommand(createAdmin, reset, setRole)
+	return cmd
+}
+
+func newTaskCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "task",
+		Short: "Manage tasks",
+	}
+
+	var userID string
+	var confirmed bool
+	purge := &cobra.Command{
+		Use:   "purge",
+		Short: "Delete all tasks of a user",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !confirmed {
+				return errors.New("purging tasks cannot be undone, rerun with --yes to confirm")
+			}
+
+			env, err := openCLIEnv()
+			if err != nil {
+				return err
+			}
+			defer env.Close()
+
+			auth, err := env.authService()
+			if err != nil {
+				return err
+			}
+			if _, err := auth.GetUserByID(cmd.Context(), userID); err != nil {
+				return err
+			}
+
+			// кэш задач по ID сбрасывается, если Redis доступен; иначе записи истекут по TASK_CACHE_TTL
+			var taskCache repository.TaskCache
+			if env.cfg.Redis.TaskCacheTTL > 0 {
+				client := redis.NewClient(&redis.Options{
+					Addr: fmt.Sprintf("%s:%s", env.cfg.Redis.Host, env.cfg.Redis.Port),
+					DB:   env.cfg.Redis.DB,
+				})
+				defer client.Close()
+				if err := client.Ping(cmd.Context()).Err(); err != nil {
+					cmd.PrintErrf("Redis is unavailable, cached tasks expire in %s: %v\n", env.cfg.Redis.TaskCacheTTL, err)
+				} else {
+					taskCache = cache.NewTaskCache(client, env.cfg.Redis.TaskCacheTTL)
+				}
+			}
+
+			taskService := service.NewTaskService(postgres.NewTaskRepository(env.db), nil, nil, nil, taskCache, nil, nil, env.logger)
+			count, err := taskService.PurgeUserTasks(cmd.Context(), userID)
+			if err != nil {
+				return err
+			}
+
+			cmd.Printf("Deleted %d tasks of user %s\n", count, userID)
+			return nil
+		},
+	}
+	purge.Flags().StringVar(&userID, "user", "", "user ID")
+	purge.Flags().BoolVar(&confirmed, "yes", false, "confirm deletion")
+	_ = purge.MarkFlagRequired("user")
+
+	var partitions int
+	partition := &cobra.Command{
+		Use:   "partition",
+		Short: "Convert the tasks table to hash partitioning by user ID",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !confirmed {
+				return errors.New("tasks are locked until partitioning finishes, rerun with --yes during a maintenance window")
+			}
+
+			env, err := openCLIEnv()
+			if err != nil {
+				return err
+			}
+			defer env.Close()
+
+			current, err := postgres.NewTaskRepository(env.db).Partitions(cmd.Context())
+			if err != nil {
+				return err
+			}
+			if current > 0 {
+				return fmt.Errorf("tasks table is already partitioned into %d partitions", current)
+			}
+
+			if err := postgres.PartitionTasks(context.WithoutCancel(cmd.Context()), env.db, partitions); err != nil {
+				return err
+			}
+
+			cmd.Printf("Partitioned tasks into %d partitions\n", partitions)
+			return nil
+		},
+	}
+	partition.Flags().IntVar(&partitions, "partitions", 16, "number of hash partitions")
+	partition.Flags().BoolVar(&confirmed, "yes", false, "confirm locking the tasks table")
+
+	cmd.AddCommand(purge, partition)
+	return cmd
+}
+
+func newMigrateCommand() *cobra.Command {
+	var dir string
+	cmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "Apply database migrations newer than the recorded schema version",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			env, err := openCLIEnv()
+			if err != nil {
+				return err
+			}
+			defer env.Close()
+
+			if dir == "" {
+				dir = env.cfg.Database.MigrationsDir
+			}
+
+			applied, err := postgres.ApplyMigrations(context.WithoutCancel(cmd.Context()), env.db, dir)
+			for _, m := range applied {
+				cmd.Printf("Applied %s\n", m.Name)
+			}
+			if err != nil {
+				return err
+			}
+
+			if len(applied) == 0 {
+				cmd.Println("Schema is up to date")
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&dir, "dir", "", "migrations directory (default DB_MIGRATIONS_DIR)")
+	return cmd
+}
+
+func newExportUserCommand() *cobra.Command {
+	var out string
+	cmd := &cobra.Command{
+		Use:   "export-user <user-id>",
+		Short: "Back up a user with tasks, task relations and notification preferences",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			env, err := openCLIEnv()
+			if err != nil {
+				return err
+			}
+			defer env.Close()
+
+			file, err := os.OpenFile(out, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+			if err != nil {
+				return err
+			}
+
+			backups := service.NewBackupService(postgres.NewBackupRepository(env.db), env.logger)
+			summary, err := backups.ExportUser(cmd.Context(), args[0], file)
+			if closeErr := file.Close(); err == nil {
+				err = closeErr
+			}
+			if err != nil {
+				os.Remove(out)
+				return err
+			}
+
+			cmd.Printf("Exported user %s (%s): %d tasks, %d relations to %s\n",
+				summary.UserID, summary.Email, summary.Tasks, summary.Relations, out)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&out, "out", "", "backup file to create, e.g. user.json.gz")
+	_ = cmd.MarkFlagRequired("out")
+	return cmd
+}
+
+func newImportUserCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "import-user <file>",
+		Short: "Restore a user from a backup made by export-user",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			env, err := openCLIEnv()
+			if err != nil {
+				return err
+			}
+			defer env.Close()
+
+			file, err := os.Open(args[0])
+			if err != nil {
+				return err
+			}
+			defer file.Close()
+
+			backups := service.NewBackupService(postgres.NewBackupRepository(env.db), env.logger)
+			summary, err := backups.ImportUser(cmd.Context(), file)
+			if err != nil {
+				return err
+			}
+
+			cmd.Printf("Imported user %s (%s): %d tasks, %d relations\n",
+				summary.UserID, summary.Email, summary.Tasks, summary.Relations)
+			return nil
+		},
+	}
+}
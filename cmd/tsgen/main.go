@@ -0,0 +1,96 @@
+// Command tsgen генерирует из спецификации Swagger клиент API на TypeScript и JSON Schema
+// моделей Task и Analytics, чтобы типы фронтенда не расходились с моделями Go.
+// Запускается через go generate ./web после swag init
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/jmoloko/taskmange/internal/middleware"
+)
+
+// schemas публикуемые JSON Schema: определение спецификации и имя файла
+var schemas = []struct {
+	definition string
+	file       string
+}{
+	{definition: "models.Task", file: "task.schema.json"},
+	{definition: "models.Analytics", file: "analytics.schema.json"},
+}
+
+// schemaBaseID префикс $id опубликованных схем
+const schemaBaseID = "https://github.com/jmoloko/taskmange/docs/schemas/"
+
+// options флаги генератора
+type options struct {
+	spec    string
+	ts      string
+	schemas string
+	prefix  string
+	// check сравнивает сгенерированное с файлами на диске вместо записи
+	check bool
+}
+
+func main() {
+	var opts options
+	flag.StringVar(&opts.spec, "spec", "docs/swagger.json", "path to the swagger spec")
+	flag.StringVar(&opts.ts, "ts", "web/client/api.ts", "output TypeScript client")
+	flag.StringVar(&opts.schemas, "schemas", "docs/schemas", "output directory for JSON schemas")
+	flag.StringVar(&opts.prefix, "prefix", "/api", "prefix added to spec paths")
+	flag.BoolVar(&opts.check, "check", false, "fail if generated files are out of date instead of writing them")
+	flag.Parse()
+
+	if err := run(opts); err != nil {
+		fmt.Fprintln(os.Stderr, "tsgen:", err)
+		os.Exit(1)
+	}
+}
+
+// run генерирует клиент и схемы и записывает их, если содержимое изменилось
+func run(opts options) error {
+	files, err := generate(opts)
+	if err != nil {
+		return err
+	}
+
+	for path, data := range files {
+		current, err := os.ReadFile(path)
+		if err == nil && bytes.Equal(current, data) {
+			continue
+		}
+		if opts.check {
+			return fmt.Errorf("%s is out of date, run go generate ./web", path)
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return fmt.Errorf("failed to create directory for %s: %w", path, err)
+		}
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", path, err)
+		}
+	}
+	return nil
+}
+
+// generate содержимое всех выходных файлов по путям
+func generate(opts options) (map[string][]byte, error) {
+	s, err := loadSpec(opts.spec)
+	if err != nil {
+		return nil, err
+	}
+
+	files := map[string][]byte{
+		opts.ts: generateTypeScript(s, opts.prefix, middleware.LatestAPIVersion),
+	}
+	for _, schema := range schemas {
+		data, err := generateSchema(s, schema.definition, schemaBaseID+schema.file)
+		if err != nil {
+			return nil, err
+		}
+		files[filepath.Join(opts.schemas, schema.file)] = data
+	}
+	return files, nil
+}
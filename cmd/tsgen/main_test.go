@@ -0,0 +1,55 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestGenerate_UpToDate клиент и схемы в репозитории совпадают со сгенерированными из текущей спецификации
+func TestGenerate_UpToDate(t *testing.T) {
+	err := run(options{
+		spec:    "../../docs/swagger.json",
+		ts:      "../../web/client/api.ts",
+		schemas: "../../docs/schemas",
+		prefix:  "/api",
+		check:   true,
+	})
+	require.NoError(t, err)
+}
+
+func TestMethodName(t *testing.T) {
+	assert.Equal(t, "getTaskByID", methodName("Get a task by ID", "get", "/tasks/{id}"))
+	assert.Equal(t, "getTodaysTasks", methodName("Get today's tasks", "get", "/tasks/today"))
+	assert.Equal(t, "postTasksId", methodName("", "post", "/tasks/{id}"))
+}
+
+func TestTypeName(t *testing.T) {
+	assert.Equal(t, "Task", typeName("#/definitions/models.Task"))
+	assert.Equal(t, "SelfcheckStatus", typeName("selfcheck.Status"))
+}
+
+func TestGenerateSchema_CollectsReferencedDefinitions(t *testing.T) {
+	s, err := loadSpec("../../docs/swagger.json")
+	require.NoError(t, err)
+
+	data, err := generateSchema(s, "models.Task", "task.schema.json")
+	require.NoError(t, err)
+
+	var out struct {
+		Title       string                     `json:"title"`
+		Definitions map[string]json.RawMessage `json:"definitions"`
+		Properties  map[string]json.RawMessage `json:"properties"`
+	}
+	require.NoError(t, json.Unmarshal(data, &out))
+	assert.Equal(t, "Task", out.Title)
+	assert.Contains(t, out.Definitions, "Status")
+	assert.Contains(t, out.Definitions, "TaskLink")
+	assert.NotContains(t, out.Definitions, "Task")
+	assert.Contains(t, string(out.Properties["status"]), "#/definitions/Status")
+
+	_, err = generateSchema(s, "models.Missing", "missing.schema.json")
+	assert.Error(t, err)
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// jsonSchemaDraft версия JSON Schema, совместимая с определениями Swagger 2.0
+const jsonSchemaDraft = "http://json-schema.org/draft-07/schema#"
+
+// generateSchema JSON Schema определения root со всеми определениями, на которые оно ссылается.
+// Ссылки переписываются на #/definitions/<имя типа>, имена совпадают с типами клиента
+func generateSchema(s *spec, root, id string) ([]byte, error) {
+	def, ok := s.raw[root]
+	if !ok {
+		return nil, fmt.Errorf("definition %s not found in spec", root)
+	}
+
+	definitions := make(map[string]interface{})
+	collectRefs(s, def, definitions)
+	delete(definitions, typeName(root))
+
+	out := rewriteRefs(def).(map[string]interface{})
+	out["$schema"] = jsonSchemaDraft
+	out["$id"] = id
+	out["title"] = typeName(root)
+	if len(definitions) > 0 {
+		out["definitions"] = definitions
+	}
+
+	data, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode schema: %w", err)
+	}
+	return append(data, '\n'), nil
+}
+
+// collectRefs добавляет в definitions все определения, достижимые по ссылкам из node
+func collectRefs(s *spec, node interface{}, definitions map[string]interface{}) {
+	switch value := node.(type) {
+	case map[string]interface{}:
+		if ref, ok := value["$ref"].(string); ok && strings.HasPrefix(ref, definitionRef) {
+			name := strings.TrimPrefix(ref, definitionRef)
+			if _, seen := definitions[typeName(name)]; !seen {
+				if def, ok := s.raw[name]; ok {
+					definitions[typeName(name)] = rewriteRefs(def)
+					collectRefs(s, def, definitions)
+				}
+			}
+		}
+		keys := make([]string, 0, len(value))
+		for key := range value {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			collectRefs(s, value[key], definitions)
+		}
+	case []interface{}:
+		for _, item := range value {
+			collectRefs(s, item, definitions)
+		}
+	}
+}
+
+// rewriteRefs копия node со ссылками на имена типов клиента
+func rewriteRefs(node interface{}) interface{} {
+	switch value := node.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(value))
+		for key, item := range value {
+			if ref, ok := item.(string); ok && key == "$ref" {
+				out[key] = definitionRef + typeName(ref)
+				continue
+			}
+			out[key] = rewriteRefs(item)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(value))
+		for i, item := range value {
+			out[i] = rewriteRefs(item)
+		}
+		return out
+	}
+	return node
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// spec часть Swagger 2.0, которую создает swag и которой достаточно для генерации клиента
+type spec struct {
+	Info struct {
+		Title   string `json:"title"`
+		Version string `json:"version"`
+	} `json:"info"`
+	Paths       map[string]map[string]operation `json:"paths"`
+	Definitions map[string]*schema              `json:"definitions"`
+	// raw определения как есть, из них собираются JSON Schema
+	raw map[string]interface{}
+}
+
+type operation struct {
+	Summary    string              `json:"summary"`
+	Tags       []string            `json:"tags"`
+	Produces   []string            `json:"produces"`
+	Parameters []parameter         `json:"parameters"`
+	Responses  map[string]response `json:"responses"`
+}
+
+type parameter struct {
+	Name        string        `json:"name"`
+	In          string        `json:"in"`
+	Type        string        `json:"type"`
+	Description string        `json:"description"`
+	Required    bool          `json:"required"`
+	Enum        []interface{} `json:"enum"`
+	Items       *schema       `json:"items"`
+	Schema      *schema       `json:"schema"`
+}
+
+type response struct {
+	Schema *schema `json:"schema"`
+}
+
+type schema struct {
+	Ref         string             `json:"$ref"`
+	Type        string             `json:"type"`
+	Description string             `json:"description"`
+	Properties  map[string]*schema `json:"properties"`
+	Required    []string           `json:"required"`
+	Items       *schema            `json:"items"`
+	Enum        []interface{}      `json:"enum"`
+	AllOf       []*schema          `json:"allOf"`
+	// AdditionalProperties true или схема значений
+	AdditionalProperties json.RawMessage `json:"additionalProperties"`
+}
+
+// values схема значений словаря, nil — значения любые
+func (s *schema) values() *schema {
+	if len(s.AdditionalProperties) == 0 || s.AdditionalProperties[0] != '{' {
+		return nil
+	}
+	var values schema
+	if err := json.Unmarshal(s.AdditionalProperties, &values); err != nil {
+		return nil
+	}
+	return &values
+}
+
+// loadSpec читает swagger.json
+func loadSpec(path string) (*spec, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read spec: %w", err)
+	}
+
+	var s spec
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("failed to parse spec: %w", err)
+	}
+	var raw struct {
+		Definitions map[string]interface{} `json:"definitions"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse spec: %w", err)
+	}
+	s.raw = raw.Definitions
+
+	return &s, nil
+}
+
+// definitionRef префикс ссылок на определения
+const definitionRef = "#/definitions/"
+
+// typeName имя типа для определения: модели домена под своими именами,
+// остальные с именем пакета, чтобы не совпадали с моделями (selfcheck.Status и models.Status)
+func typeName(definition string) string {
+	definition = strings.TrimPrefix(definition, definitionRef)
+	pkg, name, ok := strings.Cut(definition, ".")
+	if !ok {
+		return definition
+	}
+	if pkg == "models" {
+		return name
+	}
+	return strings.ToUpper(pkg[:1]) + pkg[1:] + name
+}
@@ -0,0 +1,448 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// methodOrder порядок методов одного пути в клиенте
+var methodOrder = []string{"get", "post", "put", "patch", "delete"}
+
+// skippedTags операции, которые клиенту не нужны: пробы живут вне /api
+var skippedTags = map[string]bool{"health": true}
+
+// articles слова, которые не попадают в имя метода
+var articles = map[string]bool{"a": true, "an": true, "the": true}
+
+var pathParam = regexp.MustCompile(`\{([^}]+)\}`)
+
+// generateTypeScript клиент API на TypeScript: типы всех определений спецификации и метод
+// на каждую JSON-операцию. prefix добавляется к путям спецификации, apiVersion отправляется
+// в заголовке API-Version каждого запроса
+func generateTypeScript(s *spec, prefix string, apiVersion int) []byte {
+	var b bytes.Buffer
+	b.WriteString("// Code generated by go run ./cmd/tsgen; DO NOT EDIT.\n")
+	fmt.Fprintf(&b, "// Source: %s %s\n\n", s.Info.Title, s.Info.Version)
+	b.WriteString("/** Версия API, ответы которой описывают типы клиента; отправляется в заголовке API-Version */\n")
+	fmt.Fprintf(&b, "export const API_VERSION = %d;\n\n", apiVersion)
+	b.WriteString("/** Версия спецификации, из которой сгенерирован клиент */\n")
+	fmt.Fprintf(&b, "export const SPEC_VERSION = %s;\n", strconv.Quote(s.Info.Version))
+
+	names := make([]string, 0, len(s.Definitions))
+	for name := range s.Definitions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		b.WriteString("\n")
+		writeDefinition(&b, typeName(name), s.Definitions[name])
+	}
+
+	b.WriteString(clientRuntime)
+	writeOperations(&b, s, prefix)
+	b.WriteString("}\n")
+
+	return b.Bytes()
+}
+
+// writeDefinition перечисление как объединение строк, объект как интерфейс
+func writeDefinition(b *bytes.Buffer, name string, def *schema) {
+	writeComment(b, "", def.Description)
+	if def.Type == "object" && def.Properties != nil {
+		fmt.Fprintf(b, "export interface %s {\n", name)
+		writeProperties(b, def, "  ")
+		b.WriteString("}\n")
+		return
+	}
+	fmt.Fprintf(b, "export type %s = %s;\n", name, tsType(def))
+}
+
+func writeProperties(b *bytes.Buffer, def *schema, indent string) {
+	required := make(map[string]bool, len(def.Required))
+	for _, name := range def.Required {
+		required[name] = true
+	}
+
+	names := make([]string, 0, len(def.Properties))
+	for name := range def.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		prop := def.Properties[name]
+		writeComment(b, indent, prop.Description)
+		optional := "?"
+		if required[name] {
+			optional = ""
+		}
+		fmt.Fprintf(b, "%s%s%s: %s;\n", indent, propertyName(name), optional, tsType(prop))
+	}
+}
+
+// tsType тип TypeScript для схемы
+func tsType(s *schema) string {
+	if s == nil {
+		return "unknown"
+	}
+	if s.Ref != "" {
+		return typeName(s.Ref)
+	}
+	if len(s.AllOf) == 1 {
+		return tsType(s.AllOf[0])
+	}
+	if len(s.Enum) > 0 {
+		literals := make([]string, 0, len(s.Enum))
+		for _, value := range s.Enum {
+			literals = append(literals, literal(value))
+		}
+		return strings.Join(literals, " | ")
+	}
+
+	switch s.Type {
+	case "string":
+		return "string"
+	case "integer", "number":
+		return "number"
+	case "boolean":
+		return "boolean"
+	case "array":
+		item := tsType(s.Items)
+		if strings.Contains(item, " ") {
+			item = "(" + item + ")"
+		}
+		return item + "[]"
+	case "object":
+		if s.Properties != nil {
+			var b bytes.Buffer
+			b.WriteString("{\n")
+			writeProperties(&b, s, "    ")
+			b.WriteString("  }")
+			return b.String()
+		}
+		if values := s.values(); values != nil {
+			return "Record<string, " + tsType(values) + ">"
+		}
+		return "Record<string, unknown>"
+	}
+	return "unknown"
+}
+
+func literal(value interface{}) string {
+	if text, ok := value.(string); ok {
+		return strconv.Quote(text)
+	}
+	return fmt.Sprint(value)
+}
+
+// propertyName имя свойства, в кавычках, если это не идентификатор
+func propertyName(name string) string {
+	if isIdentifier(name) {
+		return name
+	}
+	return strconv.Quote(name)
+}
+
+func isIdentifier(name string) bool {
+	for i, r := range name {
+		if r == '_' || r == '$' || unicode.IsLetter(r) || (i > 0 && unicode.IsDigit(r)) {
+			continue
+		}
+		return false
+	}
+	return name != ""
+}
+
+func writeComment(b *bytes.Buffer, indent, text string) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return
+	}
+	text = strings.ReplaceAll(text, "*/", "* /")
+	lines := strings.Split(text, "\n")
+	if len(lines) == 1 {
+		fmt.Fprintf(b, "%s/** %s */\n", indent, lines[0])
+		return
+	}
+	fmt.Fprintf(b, "%s/**\n", indent)
+	for _, line := range lines {
+		fmt.Fprintf(b, "%s * %s\n", indent, strings.TrimSpace(line))
+	}
+	fmt.Fprintf(b, "%s */\n", indent)
+}
+
+// clientRuntime общая часть клиента: ошибка API и выполнение запроса
+const clientRuntime = `
+/** Параметры отдельного запроса */
+export interface RequestOptions {
+  /** Дополнительные заголовки, например Idempotency-Key */
+  headers?: Record<string, string>;
+  signal?: AbortSignal;
+}
+
+/** Ответ API с кодом не 2xx; message — поле error тела ответа */
+export class ApiError extends Error {
+  constructor(
+    readonly status: number,
+    readonly body: unknown,
+  ) {
+    super(
+      typeof body === "object" && body !== null && "error" in body
+        ? String((body as { error: unknown }).error)
+        : ` + "`HTTP ${status}`" + `,
+    );
+    this.name = "ApiError";
+  }
+}
+
+export interface ClientOptions {
+  /** Токен доступа из ответа на вход */
+  token?: string;
+  fetch?: typeof fetch;
+}
+
+/** Клиент API менеджера задач */
+export class TaskManagerClient {
+  token?: string;
+  private readonly baseUrl: string;
+  private readonly fetchImpl: typeof fetch;
+
+  constructor(baseUrl = "", options: ClientOptions = {}) {
+    this.baseUrl = baseUrl.replace(/\/+$/, "");
+    this.token = options.token;
+    this.fetchImpl = options.fetch ?? globalThis.fetch.bind(globalThis);
+  }
+
+  protected async request<T>(
+    method: string,
+    path: string,
+    query?: object,
+    body?: unknown,
+    options: RequestOptions = {},
+  ): Promise<T> {
+    const params = new URLSearchParams();
+    for (const [key, value] of Object.entries(query ?? {})) {
+      if (value !== undefined && value !== null) {
+        params.append(key, String(value));
+      }
+    }
+    const search = params.toString();
+
+    const headers: Record<string, string> = {
+      Accept: "application/json",
+      "API-Version": String(API_VERSION),
+      ...options.headers,
+    };
+    if (this.token) {
+      headers.Authorization = ` + "`Bearer ${this.token}`" + `;
+    }
+    let payload: string | undefined;
+    if (body !== undefined) {
+      headers["Content-Type"] = "application/json";
+      payload = JSON.stringify(body);
+    }
+
+    const response = await this.fetchImpl(this.baseUrl + path + (search ? ` + "`?${search}`" + ` : ""), {
+      method,
+      headers,
+      body: payload,
+      signal: options.signal,
+    });
+
+    const text = await response.text();
+    let data: unknown;
+    try {
+      data = text ? JSON.parse(text) : undefined;
+    } catch {
+      data = text;
+    }
+    if (!response.ok) {
+      throw new ApiError(response.status, data);
+    }
+    return data as T;
+  }
+`
+
+// writeOperations методы клиента по операциям спецификации в порядке путей
+func writeOperations(b *bytes.Buffer, s *spec, prefix string) {
+	paths := make([]string, 0, len(s.Paths))
+	for path := range s.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	used := make(map[string]bool)
+	for _, path := range paths {
+		for _, method := range methodOrder {
+			op, ok := s.Paths[path][method]
+			if !ok || !producesJSON(op) || skipped(op) {
+				continue
+			}
+			name := methodName(op.Summary, method, path)
+			for i := 2; used[name]; i++ {
+				name = methodName(op.Summary, method, path) + strconv.Itoa(i)
+			}
+			used[name] = true
+			writeOperation(b, name, method, prefix+path, op)
+		}
+	}
+}
+
+func writeOperation(b *bytes.Buffer, name, method, path string, op operation) {
+	var args, pathArgs []string
+	var query []parameter
+	queryRequired := false
+	body := "undefined"
+	for _, param := range op.Parameters {
+		switch param.In {
+		case "path":
+			pathArgs = append(pathArgs, param.Name)
+		case "body":
+			args = append(args, "body: "+tsType(param.Schema))
+			body = "body"
+		case "query":
+			query = append(query, param)
+			queryRequired = queryRequired || param.Required
+		}
+	}
+
+	params := make([]string, 0, len(pathArgs)+len(args)+2)
+	for _, arg := range pathArgs {
+		params = append(params, identifier(arg)+": string | number")
+	}
+	params = append(params, args...)
+	queryArg := "undefined"
+	if len(query) > 0 {
+		var q bytes.Buffer
+		q.WriteString("{\n")
+		for _, param := range query {
+			writeComment(&q, "      ", param.Description)
+			optional := "?"
+			if param.Required {
+				optional = ""
+			}
+			fmt.Fprintf(&q, "      %s%s: %s;\n", propertyName(param.Name), optional, paramType(param))
+		}
+		q.WriteString("    }")
+		if queryRequired {
+			params = append(params, "query: "+q.String())
+		} else {
+			params = append(params, "query?: "+q.String())
+		}
+		queryArg = "query"
+	}
+	params = append(params, "options?: RequestOptions")
+
+	urlPath := pathParam.ReplaceAllStringFunc(path, func(match string) string {
+		return "${encodeURIComponent(String(" + identifier(match[1:len(match)-1]) + "))}"
+	})
+
+	b.WriteString("\n")
+	writeComment(b, "  ", op.Summary)
+	fmt.Fprintf(b, "  %s(\n", name)
+	for _, param := range params {
+		fmt.Fprintf(b, "    %s,\n", param)
+	}
+	fmt.Fprintf(b, "  ): Promise<%s> {\n", responseType(op))
+	fmt.Fprintf(b, "    return this.request(%s, `%s`, %s, %s, options);\n", strconv.Quote(strings.ToUpper(method)), urlPath, queryArg, body)
+	b.WriteString("  }\n")
+}
+
+// responseType объединение типов успешных ответов, void — ответ без тела
+func responseType(op operation) string {
+	codes := make([]string, 0, len(op.Responses))
+	for code := range op.Responses {
+		if strings.HasPrefix(code, "2") {
+			codes = append(codes, code)
+		}
+	}
+	sort.Strings(codes)
+
+	var types []string
+	seen := make(map[string]bool)
+	for _, code := range codes {
+		t := "void"
+		if schema := op.Responses[code].Schema; schema != nil {
+			t = tsType(schema)
+		}
+		if !seen[t] {
+			seen[t] = true
+			types = append(types, t)
+		}
+	}
+	if len(types) == 0 {
+		return "void"
+	}
+	return strings.Join(types, " | ")
+}
+
+func paramType(param parameter) string {
+	return tsType(&schema{Type: param.Type, Enum: param.Enum, Items: param.Items})
+}
+
+// methodName имя метода из краткого описания операции: "Get a task by ID" — getTaskByID.
+// Без описания — из метода и пути
+func methodName(summary, method, path string) string {
+	summary = strings.ReplaceAll(summary, "'", "")
+	words := strings.FieldsFunc(summary, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	if len(words) == 0 {
+		words = append([]string{method}, strings.FieldsFunc(path, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		})...)
+	}
+
+	var name strings.Builder
+	for _, word := range words {
+		if articles[strings.ToLower(word)] {
+			continue
+		}
+		if name.Len() == 0 {
+			name.WriteString(strings.ToLower(word[:1]) + word[1:])
+			continue
+		}
+		name.WriteString(strings.ToUpper(word[:1]) + word[1:])
+	}
+	return identifier(name.String())
+}
+
+// identifier допустимый идентификатор TypeScript из имени параметра
+func identifier(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		switch {
+		case r == '_' || unicode.IsLetter(r) || (i > 0 && unicode.IsDigit(r)):
+			b.WriteRune(r)
+		default:
+			b.WriteRune('_')
+		}
+	}
+	return b.String()
+}
+
+func producesJSON(op operation) bool {
+	if len(op.Produces) == 0 {
+		return true
+	}
+	for _, mime := range op.Produces {
+		if mime == "application/json" {
+			return true
+		}
+	}
+	return false
+}
+
+func skipped(op operation) bool {
+	for _, tag := range op.Tags {
+		if skippedTags[tag] {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,137 @@
+// Package ai клиент языковых моделей с OpenAI-совместимым API (OpenAI, vLLM, Ollama, LiteLLM и др.)
+package ai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	// ответ модели ограничен по размеру, чтобы сбойный сервер не исчерпал память
+	maxResponseBytes = 1 << 20
+	// maxTokens ограничение длины ответа, ассистенту нужны короткие тексты
+	maxTokens = 512
+)
+
+// ErrEmptyResponse модель вернула ответ без текста
+var ErrEmptyResponse = errors.New("model returned empty response")
+
+// Client вызывает /chat/completions OpenAI-совместимого API
+type Client struct {
+	client  *http.Client
+	baseURL string
+	apiKey  string
+	model   string
+	timeout time.Duration
+}
+
+// NewClient создает новый экземпляр Client.
+// baseURL — адрес API вместе с версией, например https://api.openai.com/v1; apiKey может быть пустым
+// для локальных моделей. timeout ограничивает весь запрос, включая чтение ответа
+func NewClient(baseURL, apiKey, model string, timeout time.Duration) *Client {
+	return &Client{
+		client:  &http.Client{},
+		baseURL: strings.TrimRight(baseURL, "/"),
+		apiKey:  apiKey,
+		model:   model,
+		timeout: timeout,
+	}
+}
+
+// Name название модели
+func (c *Client) Name() string {
+	return c.model
+}
+
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type chatRequest struct {
+	Model       string        `json:"model"`
+	Messages    []chatMessage `json:"messages"`
+	Temperature float64       `json:"temperature"`
+	MaxTokens   int           `json:"max_tokens"`
+}
+
+type chatResponse struct {
+	Choices []struct {
+		Message chatMessage `json:"message"`
+	} `json:"choices"`
+}
+
+// Complete ответ модели на prompt. По истечении таймаута возвращается ошибка,
+// оборачивающая context.DeadlineExceeded
+func (c *Client) Complete(ctx context.Context, system, prompt string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	var completion chatResponse
+	err := c.post(ctx, "/chat/completions", chatRequest{
+		Model: c.model,
+		Messages: []chatMessage{
+			{Role: "system", Content: system},
+			{Role: "user", Content: prompt},
+		},
+		// низкая температура: одинаковая задача дает близкие ответы
+		Temperature: 0.2,
+		MaxTokens:   maxTokens,
+	}, &completion)
+	if err != nil {
+		return "", err
+	}
+	if len(completion.Choices) == 0 {
+		return "", ErrEmptyResponse
+	}
+
+	text := strings.TrimSpace(completion.Choices[0].Message.Content)
+	if text == "" {
+		return "", ErrEmptyResponse
+	}
+
+	return text, nil
+}
+
+// post отправляет запрос in в формате JSON на path и декодирует ответ в out
+func (c *Client) post(ctx context.Context, path string, in, out interface{}) error {
+	body, err := json.Marshal(in)
+	if err != nil {
+		return fmt.Errorf("failed to encode request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+path, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("model responded with status %d", resp.StatusCode)
+	}
+
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxResponseBytes)).Decode(out); err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return fmt.Errorf("failed to read response: %w", ctxErr)
+		}
+		return fmt.Errorf("failed to decode model response: %w", err)
+	}
+
+	return nil
+}
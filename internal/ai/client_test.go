@@ -0,0 +1,84 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientComplete(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/chat/completions", r.URL.Path)
+		assert.Equal(t, "Bearer ai-key", r.Header.Get("Authorization"))
+
+		var req chatRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, "test-model", req.Model)
+		require.Len(t, req.Messages, 2)
+		assert.Equal(t, "system", req.Messages[0].Role)
+
+		switch req.Messages[1].Content {
+		case "slow":
+			time.Sleep(200 * time.Millisecond)
+		case "empty":
+			w.Write([]byte(`{"choices":[]}`))
+			return
+		case "fail":
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"  Short summary \n"}}]}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL+"/v1/", "ai-key", "test-model", 50*time.Millisecond)
+	ctx := context.Background()
+	assert.Equal(t, "test-model", client.Name())
+
+	text, err := client.Complete(ctx, "Summarize", "task")
+	require.NoError(t, err)
+	assert.Equal(t, "Short summary", text)
+
+	_, err = client.Complete(ctx, "Summarize", "empty")
+	assert.ErrorIs(t, err, ErrEmptyResponse)
+
+	_, err = client.Complete(ctx, "Summarize", "fail")
+	assert.ErrorContains(t, err, "status 429")
+
+	_, err = client.Complete(ctx, "Summarize", "slow")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestEmbedderEmbed(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/embeddings", r.URL.Path)
+
+		var req embeddingRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, "embedding-model", req.Model)
+
+		if req.Input == "empty" {
+			w.Write([]byte(`{"data":[]}`))
+			return
+		}
+		w.Write([]byte(`{"data":[{"embedding":[0.25,-0.5,1]}]}`))
+	}))
+	defer server.Close()
+
+	embedder := NewEmbedder(server.URL+"/v1", "", "embedding-model", time.Second)
+	ctx := context.Background()
+	assert.Equal(t, "embedding-model", embedder.Name())
+
+	vector, err := embedder.Embed(ctx, "Pay rent")
+	require.NoError(t, err)
+	assert.Equal(t, []float32{0.25, -0.5, 1}, vector)
+
+	_, err = embedder.Embed(ctx, "empty")
+	assert.ErrorIs(t, err, ErrEmptyResponse)
+}
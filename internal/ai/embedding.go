@@ -0,0 +1,48 @@
+package ai
+
+import (
+	"context"
+	"time"
+)
+
+// Embedder получает векторные представления текста через /embeddings OpenAI-совместимого API
+type Embedder struct {
+	api *Client
+}
+
+// NewEmbedder создает новый экземпляр Embedder, параметры — как у NewClient, model — модель эмбеддингов
+func NewEmbedder(baseURL, apiKey, model string, timeout time.Duration) *Embedder {
+	return &Embedder{api: NewClient(baseURL, apiKey, model, timeout)}
+}
+
+// Name название модели, сравнивать можно только векторы одной модели
+func (e *Embedder) Name() string {
+	return e.api.model
+}
+
+type embeddingRequest struct {
+	Model string `json:"model"`
+	Input string `json:"input"`
+}
+
+type embeddingResponse struct {
+	Data []struct {
+		Embedding []float32 `json:"embedding"`
+	} `json:"data"`
+}
+
+// Embed вектор текста. По истечении таймаута возвращается ошибка, оборачивающая context.DeadlineExceeded
+func (e *Embedder) Embed(ctx context.Context, text string) ([]float32, error) {
+	ctx, cancel := context.WithTimeout(ctx, e.api.timeout)
+	defer cancel()
+
+	var resp embeddingResponse
+	if err := e.api.post(ctx, "/embeddings", embeddingRequest{Model: e.api.model, Input: text}, &resp); err != nil {
+		return nil, err
+	}
+	if len(resp.Data) == 0 || len(resp.Data[0].Embedding) == 0 {
+		return nil, ErrEmptyResponse
+	}
+
+	return resp.Data[0].Embedding, nil
+}
@@ -0,0 +1,200 @@
+// Package app собирает сервер задач по конфигурации: соединения с Postgres и Redis, сервисы,
+// конвейер событий, фоновые задачи и HTTP-сервер. Его запускает бинарник cmd/app, а также
+// другие программы, встраивающие сервер, и интеграционные тесты, которые обращаются
+// к обработчику приложения в том же процессе
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/jmoloko/taskmange/internal/config"
+	"github.com/jmoloko/taskmange/internal/crypto"
+	"github.com/jmoloko/taskmange/internal/domain/repository"
+	"github.com/jmoloko/taskmange/internal/lifecycle"
+	"github.com/jmoloko/taskmange/internal/logger"
+	"github.com/jmoloko/taskmange/internal/middleware"
+	"github.com/jmoloko/taskmange/internal/passwordpolicy"
+	"github.com/jmoloko/taskmange/internal/server"
+)
+
+// App собранное приложение
+type App struct {
+	cfg       *config.Config
+	logger    logger.Logger
+	ownLogger bool
+	lifecycle *lifecycle.Lifecycle
+	server    *server.Server
+}
+
+// Option настройка New
+type Option func(*App)
+
+// WithLogger логгер приложения вместо созданного по cfg.Logger. Закрывает его вызывающий
+func WithLogger(l logger.Logger) Option {
+	return func(a *App) {
+		a.logger = l
+	}
+}
+
+// New собирает приложение по конфигурации. Соединения с Postgres и Redis открываются и проверяются сразу,
+// а слушатель изменений задач, конвейер событий и фоновые задачи запускает Start, HTTP-сервер — Run.
+// При ошибке уже открытые соединения закрываются
+func New(cfg *config.Config, opts ...Option) (*App, error) {
+	a := &App{cfg: cfg, lifecycle: lifecycle.New()}
+	for _, opt := range opts {
+		opt(a)
+	}
+	if a.logger == nil {
+		a.logger = logger.NewSLogLogger(cfg.Logger)
+		a.ownLogger = true
+	}
+
+	if err := a.build(); err != nil {
+		if stopErr := a.lifecycle.Stop(context.Background()); stopErr != nil {
+			a.logger.Error("Failed to release resources", map[string]interface{}{
+				"error": stopErr.Error(),
+			})
+		}
+		a.closeLogger()
+		return nil, err
+	}
+
+	return a, nil
+}
+
+// build собирает компоненты приложения, см. wire.go
+func (a *App) build() error {
+	infra, err := newInfrastructure(a.cfg, a.logger, a.lifecycle)
+	if err != nil {
+		return err
+	}
+
+	caches := newCaches(a.cfg, infra.redis)
+	repos := newRepositories(a.cfg, infra, caches)
+	if repository.Supports(repos.task, repository.CapabilityChangeNotifications) {
+		startTaskChangeListener(a.cfg, caches, a.logger, a.lifecycle)
+	}
+	if unsupported := repository.Unsupported(repos.task); len(unsupported) > 0 {
+		a.logger.Warn("Task storage lacks some capabilities, dependent features are disabled", map[string]interface{}{
+			"storage":     a.cfg.Database.Storage,
+			"unsupported": unsupported,
+		})
+	}
+	if a.cfg.Database.Storage == config.StorageMemory {
+		a.logger.Warn("Tasks and users are stored in memory and will be lost on shutdown", map[string]interface{}{
+			"storage": a.cfg.Database.Storage,
+		})
+	}
+	notifications, err := newNotifications(a.cfg, repos, caches, a.logger)
+	if err != nil {
+		return err
+	}
+	services, err := newServices(a.cfg, infra, repos, caches, notifications, a.logger)
+	if err != nil {
+		return err
+	}
+
+	subscribeEvents(services, a.lifecycle)
+	backgroundWorker := newWorker(a.cfg, repos, caches, notifications, services, a.logger)
+	a.lifecycle.Append(lifecycle.Hook{
+		Name:  "worker",
+		Start: func(context.Context) error { backgroundWorker.Start(); return nil },
+		Stop:  func(context.Context) error { backgroundWorker.Stop(); return nil },
+	})
+
+	handlers, err := newHandlers(a.cfg, services, infra.checker, a.logger)
+	if err != nil {
+		return err
+	}
+
+	// сброс низкоприоритетных запросов при перегрузке
+	shedder := middleware.NewLoadShedder(a.cfg.Shedding.LatencyThreshold, a.cfg.Shedding.PoolSaturation, infra.db.Stats)
+
+	a.server = server.NewServer(a.cfg, handlers, shedder, services.usage, services.audit, caches.idempotency, a.logger)
+	// потоки событий не завершаются сами, при остановке их закрывает Hub
+	a.server.RegisterOnShutdown(services.realtime.Close)
+
+	return nil
+}
+
+// Handler обработчик HTTP-запросов приложения, например для httptest.NewServer вместо Run
+func (a *App) Handler() http.Handler {
+	return a.server.Handler()
+}
+
+// Start запускает слушатель изменений задач, конвейер событий и фоновые задачи без HTTP-сервера
+func (a *App) Start(ctx context.Context) error {
+	return a.lifecycle.Start(ctx)
+}
+
+// Run запускает приложение и HTTP-сервер и ждет отмены ctx или ошибки сервера.
+// Компоненты после возврата продолжают работать, их останавливает Shutdown
+func (a *App) Run(ctx context.Context) error {
+	if err := a.Start(ctx); err != nil {
+		return err
+	}
+
+	serverErr := make(chan error, 1)
+	go func() {
+		a.logger.Info(fmt.Sprintf("Starting server on %s", strings.Join(a.cfg.Server.Addresses(), ", ")))
+		if err := a.server.Run(); err != nil && err != http.ErrServerClosed {
+			serverErr <- err
+		}
+	}()
+
+	select {
+	case <-ctx.Done():
+		return nil
+	case err := <-serverErr:
+		return fmt.Errorf("failed to start server: %w", err)
+	}
+}
+
+// Shutdown останавливает HTTP-сервер, затем фоновые задачи, конвейер событий и соединения.
+// Повторный вызов ничего не делает
+func (a *App) Shutdown(ctx context.Context) error {
+	// сервер перестает принимать запросы до остановки компонентов, которыми пользуются обработчики
+	err := a.server.Shutdown(ctx)
+	if stopErr := a.lifecycle.Stop(ctx); stopErr != nil {
+		err = errors.Join(err, stopErr)
+	}
+	a.closeLogger()
+
+	return err
+}
+
+func (a *App) closeLogger() {
+	if a.ownLogger {
+		a.logger.Close()
+		a.ownLogger = false
+	}
+}
+
+// NewPasswordPolicy политика паролей по настройкам аутентификации
+func NewPasswordPolicy(cfg config.AuthConfig, logger logger.Logger) (*passwordpolicy.Policy, error) {
+	policyCfg := passwordpolicy.Config{
+		MinLength:          cfg.PasswordMinLength,
+		BreachCheckTimeout: cfg.PasswordBreachCheckTimeout,
+		History:            cfg.PasswordHistory,
+	}
+	for _, class := range cfg.PasswordRequiredClasses {
+		policyCfg.RequiredClasses = append(policyCfg.RequiredClasses, passwordpolicy.CharClass(class))
+	}
+	if cfg.PasswordBreachCheck {
+		policyCfg.BreachCheckURL = cfg.PasswordBreachCheckURL
+	}
+	return passwordpolicy.New(policyCfg, logger)
+}
+
+// NewPasswordHasher хэширование паролей по настройкам аутентификации
+func NewPasswordHasher(cfg config.AuthConfig) (*crypto.PasswordHasher, error) {
+	return crypto.NewPasswordHasher(crypto.PasswordAlgorithm(cfg.PasswordAlgorithm), cfg.BcryptCost, crypto.Argon2Params{
+		Memory:      uint32(cfg.Argon2Memory),
+		Iterations:  uint32(cfg.Argon2Iterations),
+		Parallelism: uint8(cfg.Argon2Parallelism),
+	})
+}
@@ -0,0 +1,636 @@
+package app
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/jmoloko/taskmange/internal/ai"
+	"github.com/jmoloko/taskmange/internal/cache"
+	"github.com/jmoloko/taskmange/internal/calendar"
+	"github.com/jmoloko/taskmange/internal/config"
+	"github.com/jmoloko/taskmange/internal/crypto"
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/jmoloko/taskmange/internal/domain/repository"
+	domainService "github.com/jmoloko/taskmange/internal/domain/service"
+	"github.com/jmoloko/taskmange/internal/events"
+	"github.com/jmoloko/taskmange/internal/handler"
+	"github.com/jmoloko/taskmange/internal/importer"
+	"github.com/jmoloko/taskmange/internal/integration"
+	"github.com/jmoloko/taskmange/internal/lifecycle"
+	"github.com/jmoloko/taskmange/internal/logger"
+	"github.com/jmoloko/taskmange/internal/metrics"
+	"github.com/jmoloko/taskmange/internal/notification"
+	"github.com/jmoloko/taskmange/internal/realtime"
+	"github.com/jmoloko/taskmange/internal/redact"
+	"github.com/jmoloko/taskmange/internal/repository/memory"
+	"github.com/jmoloko/taskmange/internal/repository/postgres"
+	"github.com/jmoloko/taskmange/internal/repository/sqlite"
+	"github.com/jmoloko/taskmange/internal/selfcheck"
+	"github.com/jmoloko/taskmange/internal/service"
+	"github.com/jmoloko/taskmange/internal/tracing"
+	"github.com/jmoloko/taskmange/internal/worker"
+	"github.com/redis/go-redis/v9"
+)
+
+// Сборка приложения из конструкторов. Каждый шаг получает готовые зависимости предыдущих,
+// а компоненты, которые нужно запускать и останавливать, регистрирует в lifecycle.
+// Новая подсистема добавляет поле в подходящую группу (repositories, services) и,
+// если у нее есть фоновая работа, задачу воркера или подписку на события
+
+// infrastructure соединения с Postgres и Redis и проверки окружения
+type infrastructure struct {
+	db    *sql.DB
+	redis *redis.Client
+	// sqlite база задач и пользователей при STORAGE=sqlite, иначе nil
+	sqlite  *sql.DB
+	checker *selfcheck.Checker
+}
+
+// newInfrastructure открывает соединения и выполняет проверку при запуске.
+// Ошибки проверки только пишутся в лог, отчет доступен в /readyz?verbose=1
+func newInfrastructure(cfg *config.Config, appLogger logger.Logger, lc *lifecycle.Lifecycle) (*infrastructure, error) {
+	// трассировка настраивается первой, а останавливается последней, отправляя накопленные span
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing, cfg.Logger.ServiceName, cfg.Logger.Environment)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize tracing: %w", err)
+	}
+	lc.Append(lifecycle.Hook{Name: "tracing", Stop: shutdownTracing})
+
+	db, err := postgres.NewPostgresDB(cfg.Database)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize db: %w", err)
+	}
+	lc.Append(lifecycle.Hook{Name: "postgres", Stop: func(context.Context) error { return db.Close() }})
+	appLogger.Info("Database connected successfully")
+
+	redisClient := redis.NewClient(&redis.Options{
+		Addr: fmt.Sprintf("%s:%s", cfg.Redis.Host, cfg.Redis.Port),
+		DB:   cfg.Redis.DB,
+	})
+	lc.Append(lifecycle.Hook{Name: "redis", Stop: func(context.Context) error { return redisClient.Close() }})
+	if cfg.Server.TimingEnabled {
+		redisClient.AddHook(cache.TimingHook{})
+	}
+	if cfg.Tracing.Endpoint != "" {
+		redisClient.AddHook(cache.TracingHook{})
+	}
+	// без Redis сервис работает без кэша, поэтому недоступный при запуске Redis не останавливает запуск
+	if err := redisClient.Ping(context.Background()).Err(); err != nil {
+		appLogger.Warn("Redis is unavailable, caches are bypassed until it recovers", map[string]interface{}{
+			"error": err.Error(),
+		})
+	} else {
+		appLogger.Info("Redis connected successfully")
+	}
+
+	var sqliteDB *sql.DB
+	if cfg.Database.Storage == config.StorageSQLite {
+		sqliteDB, err = sqlite.Open(context.Background(), cfg.Database.SQLitePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open sqlite storage: %w", err)
+		}
+		lc.Append(lifecycle.Hook{Name: "sqlite", Stop: func(context.Context) error { return sqliteDB.Close() }})
+		appLogger.Info("SQLite storage opened", map[string]interface{}{
+			"path": cfg.Database.SQLitePath,
+		})
+	}
+
+	// метка tenant бизнес-метрик, при ошибочных настройках метрики не делятся по пользователям
+	if err := metrics.ConfigureTenants(metrics.TenantMode(cfg.Metrics.TenantMode), cfg.Metrics.TenantBuckets, cfg.Metrics.TenantAllowlist); err != nil {
+		appLogger.Warn("Metrics tenant labels are disabled", map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+
+	checker := selfcheck.NewChecker(
+		[]selfcheck.Check{
+			selfcheck.ConfigCheck(cfg),
+			selfcheck.SchemaCheck(db, cfg.Database.MigrationsDir),
+			selfcheck.PartitionCheck(postgres.NewTaskRepository(db).Partitions),
+			selfcheck.RedisCheck(redisClient),
+			selfcheck.ClockSkewCheck(db),
+			selfcheck.LogFileCheck(cfg.Logger.File),
+		},
+		[]selfcheck.Check{
+			selfcheck.DatabaseCheck(db),
+			// без Redis сервис отвечает без кэша и остается готовым
+			selfcheck.Optional(selfcheck.RedisCheck(redisClient)),
+		},
+		cfg.Server.ReadinessTimeout,
+	)
+	for _, result := range checker.Startup(context.Background()).Checks {
+		fields := map[string]interface{}{
+			"check":       result.Name,
+			"message":     result.Message,
+			"duration_ms": result.DurationMs,
+		}
+		switch result.Status {
+		case selfcheck.StatusFail:
+			appLogger.Error("Startup check failed", fields)
+		case selfcheck.StatusWarn:
+			appLogger.Warn("Startup check warning", fields)
+		default:
+			appLogger.Info("Startup check passed", fields)
+		}
+	}
+
+	return &infrastructure{db: db, redis: redisClient, sqlite: sqliteDB, checker: checker}, nil
+}
+
+// caches кэши Redis; кэш с нулевым TTL отключен и равен nil
+type caches struct {
+	client     *redis.Client
+	redis      repository.AnalyticsCache
+	task       repository.TaskCache
+	views      repository.TaskViewCache
+	userStatus repository.UserStatusCache
+	// idempotency ответы на запросы с Idempotency-Key
+	idempotency *cache.IdempotencyStore
+	// invalidators сбрасывают кэши пользователя при изменении его задач
+	invalidators []postgres.InvalidateFunc
+}
+
+func newCaches(cfg *config.Config, client *redis.Client) *caches {
+	redisCache := cache.NewRedisCache(client, cache.NewBreaker("analytics", cfg.Redis.BreakerThreshold, cfg.Redis.BreakerCooldown))
+	c := &caches{
+		client:       client,
+		redis:        redisCache,
+		idempotency:  cache.NewIdempotencyStore(client),
+		invalidators: []postgres.InvalidateFunc{redisCache.InvalidateUserAnalytics},
+	}
+
+	// кэш чтения задач по ID и списков задач по фильтрам
+	if cfg.Redis.TaskCacheTTL > 0 {
+		taskCache := cache.NewTaskCache(client, cfg.Redis.TaskCacheTTL)
+		c.task = taskCache
+		c.invalidators = append(c.invalidators, taskCache.InvalidateTaskLists)
+	}
+	// кэш списков "Сегодня" и "Предстоящие"
+	if cfg.Redis.ViewCacheTTL > 0 {
+		taskViewCache := cache.NewTaskViewCache(client, cfg.Redis.ViewCacheTTL)
+		c.views = taskViewCache
+		c.invalidators = append(c.invalidators, taskViewCache.InvalidateTaskViews)
+	}
+	// статус учетной записи проверяется на каждом запросе
+	if cfg.Redis.UserStatusCacheTTL > 0 {
+		c.userStatus = cache.NewUserStatusCache(client, cfg.Redis.UserStatusCacheTTL)
+	}
+
+	return c
+}
+
+// startTaskChangeListener сбрасывает кэши пользователя при любом изменении его задач,
+// в том числе сделанном другим экземпляром
+func startTaskChangeListener(cfg *config.Config, caches *caches, appLogger logger.Logger, lc *lifecycle.Lifecycle) {
+	listenerCtx, stopListener := context.WithCancel(context.Background())
+	listener := postgres.NewTaskChangeListener(cfg.Database, appLogger, caches.invalidators...)
+	lc.Append(lifecycle.Hook{
+		Name: "task_change_listener",
+		Start: func(context.Context) error {
+			go func() {
+				if err := listener.Run(listenerCtx); err != nil {
+					appLogger.Error("Task change listener stopped", map[string]interface{}{
+						"error": err.Error(),
+					})
+				}
+			}()
+			return nil
+		},
+		Stop: func(context.Context) error { stopListener(); return nil },
+	})
+}
+
+// taskStore хранилище задач, которым пользуются сервисы: Postgres, память или SQLite по STORAGE
+type taskStore interface {
+	repository.TaskRepository
+	repository.TaskRefResolver
+	repository.CapabilityReporter
+}
+
+// repositories репозитории Postgres; задачи и пользователи при STORAGE=memory или sqlite хранятся отдельно
+type repositories struct {
+	user repository.UserRepository
+	task taskStore
+	// taskTables таблицы задач в Postgres для результатов AI, эмбеддингов и архива,
+	// которых нет в хранилищах memory и sqlite
+	taskTables     *postgres.TaskRepository
+	notification   *postgres.NotificationRepository
+	matrixSettings *postgres.MatrixSettingsRepository
+	project        *postgres.ProjectRepository
+	trigger        *postgres.TriggerRepository
+	analytics      *postgres.AnalyticsRepository
+	transfer       *postgres.TransferRepository
+	usage          *postgres.UsageRepository
+	impersonation  *postgres.ImpersonationRepository
+	audit          *postgres.AuditRepository
+	hook           *postgres.IncomingHookRepository
+	externalRef    *postgres.ExternalRefRepository
+	repoLink       *postgres.GitHubRepoLinkRepository
+	tagging        *postgres.TaggingRuleRepository
+	savedSearch    *postgres.SavedSearchRepository
+	taskChange     *postgres.TaskChangeRepository
+	calendarFeed   *postgres.CalendarFeedRepository
+	calendarSync   *postgres.CalendarSyncRepository
+}
+
+func newRepositories(cfg *config.Config, infra *infrastructure, caches *caches) *repositories {
+	db := infra.db
+	repos := &repositories{
+		user:           postgres.NewUserRepository(db),
+		task:           postgres.NewTaskRepository(db),
+		taskTables:     postgres.NewTaskRepository(db),
+		notification:   postgres.NewNotificationRepository(db),
+		matrixSettings: postgres.NewMatrixSettingsRepository(db),
+		project:        postgres.NewProjectRepository(db),
+		trigger:        postgres.NewTriggerRepository(db),
+		analytics:      postgres.NewAnalyticsRepository(db),
+		transfer:       postgres.NewTransferRepository(db),
+		usage:          postgres.NewUsageRepository(db),
+		impersonation:  postgres.NewImpersonationRepository(db),
+		audit:          postgres.NewAuditRepository(db),
+		hook:           postgres.NewIncomingHookRepository(db),
+		externalRef:    postgres.NewExternalRefRepository(db),
+		repoLink:       postgres.NewGitHubRepoLinkRepository(db),
+		tagging:        postgres.NewTaggingRuleRepository(db),
+		savedSearch:    postgres.NewSavedSearchRepository(db),
+		taskChange:     postgres.NewTaskChangeRepository(db),
+		calendarFeed:   postgres.NewCalendarFeedRepository(db),
+		calendarSync:   postgres.NewCalendarSyncRepository(db),
+	}
+
+	// кэши сбрасывает само хранилище, слушателя изменений tasks в Postgres нет
+	switch cfg.Database.Storage {
+	case config.StorageMemory:
+		tasks := memory.NewTaskRepository(repos.project)
+		for _, invalidate := range caches.invalidators {
+			tasks.OnChange(memory.ChangeFunc(invalidate))
+		}
+		repos.task = tasks
+		repos.user = memory.NewUserRepository()
+	case config.StorageSQLite:
+		tasks := sqlite.NewTaskRepository(infra.sqlite, repos.project)
+		for _, invalidate := range caches.invalidators {
+			tasks.OnChange(sqlite.ChangeFunc(invalidate))
+		}
+		repos.task = tasks
+		repos.user = sqlite.NewUserRepository(infra.sqlite)
+	}
+
+	return repos
+}
+
+// notifications шаблоны, каналы уведомлений и действия триггеров
+type notifications struct {
+	renderer       *notification.Renderer
+	channels       map[models.NotificationChannel]domainService.Notifier
+	triggerSenders map[models.TriggerActionType]domainService.TriggerActionSender
+	// telegramBot nil, если TELEGRAM_BOT_TOKEN не задан
+	telegramBot    domainService.TelegramBot
+	vapidPublicKey string
+	defaults       models.NotificationPreferences
+	dispatcher     *notification.Dispatcher
+}
+
+// newNotifications настраивает каналы уведомлений: Web Push без VAPID-ключей, email без SMTP
+// и Telegram без токена бота отключены
+func newNotifications(cfg *config.Config, repos *repositories, caches *caches, appLogger logger.Logger) (*notifications, error) {
+	renderer, err := notification.NewRenderer(cfg.Notification.TemplatesDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load notification templates: %w", err)
+	}
+
+	n := &notifications{
+		renderer: renderer,
+		channels: make(map[models.NotificationChannel]domainService.Notifier),
+	}
+	if notifier, err := notification.NewWebPushNotifier(cfg.Push, repos.notification, appLogger); err != nil {
+		appLogger.Warn("Push notifications are disabled", map[string]interface{}{
+			"error": err.Error(),
+		})
+	} else {
+		n.channels[models.ChannelPush] = notifier
+		n.vapidPublicKey = cfg.Push.VAPIDPublicKey
+	}
+
+	// действия триггеров, email доступен только при настроенном SMTP.
+	// Канал slack отправляет уведомления в webhook из настроек пользователя
+	slackSender := notification.NewSlackSender(renderer)
+	n.channels[models.ChannelSlack] = notification.NewSlackNotifier(slackSender, repos.notification)
+	n.triggerSenders = map[models.TriggerActionType]domainService.TriggerActionSender{
+		models.TriggerActionWebhook: notification.NewWebhookSender(),
+		models.TriggerActionSlack:   slackSender,
+	}
+	if emailSender, err := notification.NewEmailSender(cfg.SMTP, renderer); err != nil {
+		appLogger.Warn("Email notifications are disabled", map[string]interface{}{
+			"error": err.Error(),
+		})
+	} else {
+		n.triggerSenders[models.TriggerActionEmail] = emailSender
+		n.channels[models.ChannelEmail] = notification.NewEmailNotifier(emailSender, repos.user)
+	}
+	if telegramNotifier, err := notification.NewTelegramNotifier(cfg.Telegram, repos.notification, renderer, appLogger); err != nil {
+		appLogger.Warn("Telegram notifications are disabled", map[string]interface{}{
+			"error": err.Error(),
+		})
+	} else {
+		n.channels[models.ChannelTelegram] = telegramNotifier
+		n.telegramBot = telegramNotifier
+	}
+
+	// диспетчер применяет настройки пользователя: каналы, типы событий, дайджест и тихие часы
+	n.defaults = models.NotificationPreferences{
+		Channels:             []models.NotificationChannel{models.ChannelPush},
+		EventTypes:           []string{},
+		DueSoonWindowMinutes: int(cfg.Push.DueSoonWindow.Minutes()),
+		DigestWindowMinutes:  int(cfg.Notification.DigestWindow.Minutes()),
+		Timezone:             "UTC",
+	}
+	n.dispatcher = notification.NewDispatcher(n.channels, cache.NewNotificationBuffer(caches.client), repos.notification, n.defaults, appLogger)
+
+	return n, nil
+}
+
+// services сервисы приложения и конвейер событий задач
+type services struct {
+	eventBus         events.Broker
+	realtime         *realtime.Hub
+	auth             *service.AuthService
+	audit            *service.AuditService
+	impersonation    *service.ImpersonationService
+	userStatus       *service.UserStatusService
+	usage            *service.UsageService
+	quota            *service.QuotaService
+	tagging          *service.TaggingService
+	task             domainService.TaskService
+	trigger          *service.TriggerService
+	analyticsHistory *service.AnalyticsHistoryService
+	transfer         *service.TransferService
+	view             *service.TaskViewService
+	quickAdd         *service.QuickAddService
+	hook             *service.HookService
+	externalRef      *service.ExternalRefService
+	ai               *service.AIService
+	similarity       *service.SimilarityService
+	commit           *service.CommitService
+	admin            *service.AdminService
+	project          *service.ProjectService
+	savedSearch      *service.SavedSearchService
+	changeFeed       *service.ChangeFeedService
+	notification     *service.NotificationService
+	telegram         *service.TelegramService
+	calendar         *service.CalendarService
+	calendarSync     *service.CalendarSyncService
+}
+
+func newServices(cfg *config.Config, infra *infrastructure, repos *repositories, caches *caches, notifications *notifications, appLogger logger.Logger) (*services, error) {
+	// шифрование приватных задач
+	var taskEncryptor domainService.TaskEncryptor
+	if encryptor, err := crypto.NewTaskEncryptor(cfg.Crypto.MasterKey); err != nil {
+		appLogger.Warn("Private tasks are disabled", map[string]interface{}{
+			"error": err.Error(),
+		})
+	} else {
+		taskEncryptor = encryptor
+	}
+
+	// конвейер событий задач
+	var eventBus events.Broker = events.NewBus(0, appLogger)
+	if cfg.Events.Backend == "redis" {
+		eventBus = events.NewRedisStream(infra.redis, cfg.Events, appLogger)
+	}
+
+	passwordHasher, err := NewPasswordHasher(cfg.Auth)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure password hashing: %w", err)
+	}
+	passwordPolicy, err := NewPasswordPolicy(cfg.Auth, appLogger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure password policy: %w", err)
+	}
+
+	s := &services{
+		eventBus: eventBus,
+		// события задач в реальном времени (поток SSE) идут через ту же шину, что и триггеры
+		realtime: realtime.NewHub(cfg.Realtime),
+	}
+	s.auth = service.NewAuthService(repos.user, repos.impersonation, passwordHasher, passwordPolicy, appLogger, cfg.Auth.SigningKey)
+	s.audit = service.NewAuditService(repos.audit, appLogger)
+	s.impersonation = service.NewImpersonationService(s.auth, repos.user, repos.impersonation, s.audit, cfg.Auth.ImpersonationTTL, appLogger)
+	s.userStatus = service.NewUserStatusService(repos.user, caches.userStatus, s.audit, appLogger)
+	s.usage = service.NewUsageService(cache.NewUsageCounter(infra.redis), repos.usage, appLogger)
+
+	// лимиты пользователя: при приближении к ним в ответ добавляется предупреждение и отправляется уведомление
+	s.quota = service.NewQuotaService(cfg.Quota.MaxOpenTasks, cfg.Quota.WarnThreshold, notifications.dispatcher, appLogger)
+	s.tagging = service.NewTaggingService(repos.tagging, appLogger)
+	s.task = service.NewTaskService(repos.task, caches.redis, taskEncryptor, eventBus, caches.task, s.quota, s.tagging, appLogger)
+	s.trigger = service.NewTriggerService(repos.trigger, notifications.triggerSenders, s.quota, appLogger)
+	s.analyticsHistory = service.NewAnalyticsHistoryService(s.task, repos.analytics, appLogger)
+	s.transfer = service.NewTransferService(repos.transfer, appLogger)
+	s.view = service.NewTaskViewService(s.task, repos.notification, repos.matrixSettings, caches.views, appLogger)
+	s.quickAdd = service.NewQuickAddService(s.task, repos.notification, appLogger)
+	s.hook = service.NewHookService(repos.hook, s.task, s.userStatus, cache.NewHookRateLimiter(infra.redis), cfg.Hooks.RateLimit, cfg.Hooks.RateWindow, appLogger)
+
+	// ссылки, результаты AI, эмбеддинги и подключенные календари хранятся в таблицах Postgres, связанных
+	// с задачами и пользователями Postgres; без них эти функции отвечают "не настроено"
+	foreignKeys := repository.Supports(repos.task, repository.CapabilityForeignKeys)
+
+	// внешние трекеры: GitHub доступен всегда, Jira — при заданном JIRA_BASE_URL
+	issueTrackers := map[models.ExternalProvider]domainService.IssueTracker{}
+	if foreignKeys {
+		issueTrackers[models.ProviderGitHub] = integration.NewGitHubTracker(cfg.Integrations.GitHubAPIURL, cfg.Integrations.GitHubToken)
+	}
+	if foreignKeys && cfg.Integrations.JiraBaseURL != "" {
+		issueTrackers[models.ProviderJira] = integration.NewJiraTracker(cfg.Integrations.JiraBaseURL, cfg.Integrations.JiraEmail, cfg.Integrations.JiraAPIToken)
+	}
+	s.externalRef = service.NewExternalRefService(repos.externalRef, s.task, issueTrackers, cfg.Integrations.PollInterval, appLogger)
+
+	// AI-ассистент включается при заданном AI_BASE_URL
+	var languageModel domainService.LanguageModel
+	if cfg.AI.BaseURL != "" && foreignKeys {
+		languageModel = ai.NewClient(cfg.AI.BaseURL, cfg.AI.APIKey, cfg.AI.Model, cfg.AI.Timeout)
+	}
+	s.ai = service.NewAIService(repos.taskTables, s.task, languageModel, appLogger)
+	// поиск похожих задач: векторы строит модель эмбеддингов того же API
+	var embedder domainService.TextEmbedder
+	if cfg.AI.BaseURL != "" && cfg.AI.EmbeddingModel != "" && foreignKeys &&
+		repository.Supports(repos.task, repository.CapabilityVectorSearch) && embeddingsInstalled(repos.taskTables, appLogger) {
+		embedder = ai.NewEmbedder(cfg.AI.BaseURL, cfg.AI.APIKey, cfg.AI.EmbeddingModel, cfg.AI.Timeout)
+	}
+	s.similarity = service.NewSimilarityService(repos.taskTables, s.task, embedder, cfg.AI.SimilarityMinScore, appLogger)
+	s.commit = service.NewCommitService(repos.repoLink, repos.task, s.task, s.userStatus, appLogger)
+	s.admin = service.NewAdminService(repos.user, repos.task, s.task, s.audit, appLogger)
+	s.project = service.NewProjectService(repos.project, s.task, appLogger)
+
+	// ADMIN_USER_IDS — прежний способ назначения администраторов, теперь роль хранится в БД
+	if err := s.admin.PromoteAdmins(context.Background(), cfg.Auth.AdminUserIDs); err != nil {
+		return nil, fmt.Errorf("failed to promote admin users: %w", err)
+	}
+	s.savedSearch = service.NewSavedSearchService(repos.savedSearch, notifications.dispatcher, appLogger)
+	s.changeFeed = service.NewChangeFeedService(repos.taskChange, cfg.ChangeFeed.Enabled, cfg.ChangeFeed.Retention, appLogger)
+	s.notification = service.NewNotificationService(repos.notification, notifications.dispatcher, notifications.renderer, notifications.vapidPublicKey, notifications.defaults, appLogger)
+	s.telegram = service.NewTelegramService(repos.notification, notifications.telegramBot, s.task, appLogger)
+	s.calendar = service.NewCalendarService(repos.calendarFeed, s.task, cfg.Auth.SigningKey, appLogger)
+
+	// синхронизация сроков с календарями: Apple доступен всегда, Google — при заданном OAuth-клиенте
+	calendarClients := map[models.CalendarProvider]domainService.CalendarClient{}
+	if foreignKeys {
+		calendarClients[models.CalendarApple] = calendar.NewCalDAV()
+	}
+	if foreignKeys && cfg.Calendar.GoogleClientID != "" && cfg.Calendar.GoogleClientSecret != "" {
+		calendarClients[models.CalendarGoogle] = calendar.NewGoogleCalendar(cfg.Calendar.GoogleAPIURL,
+			cfg.Calendar.GoogleTokenURL, cfg.Calendar.GoogleClientID, cfg.Calendar.GoogleClientSecret)
+	}
+	s.calendarSync = service.NewCalendarSyncService(repos.calendarSync, s.task, s.userStatus, calendarClients,
+		cfg.Calendar.WebhookURL, cfg.Calendar.SyncInterval, appLogger)
+
+	return s, nil
+}
+
+// embeddingsInstalled проверяет, что в Postgres есть таблица эмбеддингов: без pgvector миграция
+// ее не создает, и поиск похожих задач отключается, а не падает на каждом запросе
+func embeddingsInstalled(tables *postgres.TaskRepository, appLogger logger.Logger) bool {
+	installed, err := tables.EmbeddingsInstalled(context.Background())
+	if err != nil {
+		appLogger.Warn("Similar task suggestions are disabled", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return false
+	}
+	if !installed {
+		appLogger.Warn("Similar task suggestions are disabled: pgvector extension is not installed")
+	}
+
+	return installed
+}
+
+// subscribeEvents подписывает сервисы на события задач. Подключения SSE есть у каждого экземпляра,
+// поэтому поток получает все события; остальные подписчики обрабатывают событие один раз
+func subscribeEvents(s *services, lc *lifecycle.Lifecycle) {
+	s.eventBus.SubscribeLocal("realtime", s.realtime.HandleEvent)
+	s.eventBus.Subscribe("triggers", s.trigger.HandleEvent)
+	s.eventBus.Subscribe("similarity", s.similarity.HandleEvent)
+	s.eventBus.Subscribe("saved_searches", s.savedSearch.HandleEvent)
+	s.eventBus.Subscribe("change_feed", s.changeFeed.HandleEvent)
+	s.eventBus.Subscribe("notifications", s.notification.HandleEvent)
+	s.eventBus.Subscribe("calendar_sync", s.calendarSync.HandleEvent)
+
+	lc.Append(lifecycle.Hook{
+		Name:  "event_bus",
+		Start: func(context.Context) error { s.eventBus.Start(); return nil },
+		Stop:  func(context.Context) error { s.eventBus.Stop(); return nil },
+	})
+}
+
+// newWorker фоновые задачи подсистем
+func newWorker(cfg *config.Config, repos *repositories, caches *caches, notifications *notifications, s *services, appLogger logger.Logger) *worker.BackgroundWorker {
+	backgroundWorker := worker.NewBackgroundWorker(s.task, caches.redis, appLogger)
+	backgroundWorker.AddJob(worker.Job{
+		Name:     "due_soon_push",
+		Interval: cfg.Push.CheckInterval,
+		Run:      s.notification.SendDueSoonReminders,
+	})
+	backgroundWorker.AddJob(worker.Job{
+		Name:     "overdue_alerts",
+		Interval: cfg.Push.CheckInterval,
+		Run:      s.notification.SendOverdueAlerts,
+	})
+	backgroundWorker.AddJob(worker.Job{
+		Name:     "notification_digest",
+		Interval: cfg.Notification.DigestFlushInterval,
+		Run:      notifications.dispatcher.Flush,
+	})
+	backgroundWorker.AddJob(worker.Job{
+		Name:     "analytics_snapshots",
+		Interval: cfg.Analytics.SnapshotInterval,
+		Run:      s.analyticsHistory.SnapshotAll,
+	})
+	backgroundWorker.AddJob(worker.Job{
+		Name:     "usage_rollup",
+		Interval: cfg.Usage.RollupInterval,
+		Run:      s.usage.Rollup,
+	})
+	backgroundWorker.AddJob(worker.Job{
+		Name:     "recurring_tasks",
+		Interval: cfg.Recurrence.CheckInterval,
+		Run:      s.task.MaterializeRecurrences,
+	})
+	backgroundWorker.AddJob(worker.Job{
+		Name:     "task_embeddings",
+		Interval: cfg.AI.EmbeddingBackfillInterval,
+		Run:      s.similarity.Backfill,
+	})
+	backgroundWorker.AddJob(worker.Job{
+		Name:     "external_issues_poll",
+		Interval: cfg.Integrations.PollInterval,
+		Run:      s.externalRef.Poll,
+	})
+	backgroundWorker.AddJob(worker.Job{
+		Name:     "calendar_sync",
+		Interval: cfg.Calendar.SyncInterval,
+		Run:      s.calendarSync.Poll,
+	})
+	if notifications.telegramBot != nil {
+		backgroundWorker.AddJob(worker.Job{
+			Name:     "telegram_daily_digest",
+			Interval: cfg.Telegram.DigestCheckInterval,
+			Run:      s.telegram.SendDailyDigests,
+		})
+	}
+	if cfg.ChangeFeed.Enabled {
+		backgroundWorker.AddJob(worker.Job{
+			Name:     "change_feed_cleanup",
+			Interval: cfg.ChangeFeed.CleanupInterval,
+			Run:      s.changeFeed.Cleanup,
+		})
+	}
+	// архив переносит строки между таблицами Postgres, в других хранилищах его нет
+	if cfg.Archive.AfterMonths > 0 && repository.Supports(repos.task, repository.CapabilityArchive) {
+		archiveService := service.NewArchiveService(repos.taskTables, cfg.Archive.AfterMonths, cfg.Archive.BatchSize, appLogger)
+		backgroundWorker.AddJob(worker.Job{
+			Name:     "archive_tasks",
+			Interval: cfg.Archive.Interval,
+			Run:      archiveService.Run,
+		})
+	}
+
+	return backgroundWorker
+}
+
+// newHandlers HTTP-обработчики всех групп маршрутов
+func newHandlers(cfg *config.Config, s *services, checker *selfcheck.Checker, appLogger logger.Logger) (*handler.Handler, error) {
+	// профили редактирования экспорта и административных ответов; ошибка в них не должна
+	// молча открыть данные, которые установка собиралась скрывать
+	redaction, err := redact.NewPolicy(cfg.Redaction.Profiles, cfg.Redaction.ExportProfile, cfg.Redaction.AdminProfile)
+	if err != nil {
+		return nil, fmt.Errorf("invalid redaction profiles: %w", err)
+	}
+
+	return handler.NewHandler(
+		handler.NewAuthHandler(s.auth, appLogger),
+		handler.NewTaskHandler(s.task, s.transfer, redaction, importer.Limits{MaxBytes: cfg.Import.MaxBytes, MaxRows: cfg.Import.MaxRows}, appLogger),
+		handler.NewNotificationHandler(s.notification, appLogger),
+		handler.NewCalendarSyncHandler(s.calendarSync, appLogger),
+		handler.NewTriggerHandler(s.trigger, appLogger),
+		handler.NewAnalyticsHandler(s.analyticsHistory, appLogger),
+		handler.NewTransferHandler(s.transfer, appLogger),
+		handler.NewHealthHandler(checker),
+		handler.NewUsageHandler(s.usage, redaction, appLogger),
+		handler.NewViewHandler(s.view, appLogger),
+		handler.NewImpersonationHandler(s.impersonation, s.audit, appLogger),
+		handler.NewHookHandler(s.hook, appLogger),
+		handler.NewExternalRefHandler(s.externalRef, appLogger),
+		handler.NewGitHubHandler(s.commit, appLogger),
+		handler.NewUserHandler(s.userStatus, appLogger),
+		handler.NewTaggingHandler(s.tagging, appLogger),
+		handler.NewAIHandler(s.ai, s.similarity, appLogger),
+		handler.NewQuickAddHandler(s.quickAdd, appLogger),
+		handler.NewAdminHandler(s.admin, redaction, appLogger),
+		handler.NewProjectHandler(s.project, appLogger),
+		handler.NewEventsHandler(s.realtime, appLogger),
+		handler.NewSavedSearchHandler(s.savedSearch, appLogger),
+		handler.NewChangeFeedHandler(s.changeFeed, redaction, appLogger),
+		handler.NewTelegramHandler(s.telegram, appLogger),
+		handler.NewCalendarHandler(s.calendar, appLogger),
+	), nil
+}
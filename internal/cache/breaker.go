@@ -0,0 +1,85 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/jmoloko/taskmange/internal/metrics"
+	"github.com/redis/go-redis/v9"
+)
+
+// Breaker размыкатель цепи перед Redis. После threshold ошибок подряд цепь размыкается
+// на cooldown: запросы в Redis не отправляются и не ждут таймаута соединения. По истечении
+// cooldown пропускается один пробный запрос, успех замыкает цепь, ошибка размыкает снова
+type Breaker struct {
+	name      string
+	threshold int
+	cooldown  time.Duration
+	now       func() time.Time
+
+	mu       sync.Mutex
+	failures int
+	// openUntil до какого момента цепь разомкнута, нулевое — цепь замкнута
+	openUntil time.Time
+}
+
+// NewBreaker создает размыкатель цепи; name — метка в метрике taskmanager_cache_circuit_open.
+// threshold 0 отключает размыкание
+func NewBreaker(name string, threshold int, cooldown time.Duration) *Breaker {
+	metrics.CacheCircuitOpen.WithLabelValues(name).Set(0)
+	return &Breaker{name: name, threshold: threshold, cooldown: cooldown, now: time.Now}
+}
+
+// Allow можно ли отправить запрос в Redis. Когда cooldown истек, пропускает один пробный запрос
+// и до его результата держит цепь разомкнутой
+func (b *Breaker) Allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.openUntil.IsZero() {
+		return true
+	}
+	now := b.now()
+	if now.Before(b.openUntil) {
+		return false
+	}
+	b.openUntil = now.Add(b.cooldown)
+	return true
+}
+
+// Done учитывает результат запроса в Redis. Промах (redis.Nil) и отмена запроса клиентом
+// не говорят о недоступности Redis и не считаются ошибкой. Возвращает true, если запрос замкнул цепь
+func (b *Breaker) Done(err error) (closed bool) {
+	if b.threshold <= 0 || errors.Is(err, redis.Nil) || errors.Is(err, context.Canceled) {
+		err = nil
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if err == nil {
+		closed = !b.openUntil.IsZero()
+		b.failures, b.openUntil = 0, time.Time{}
+		if closed {
+			metrics.CacheCircuitOpen.WithLabelValues(b.name).Set(0)
+		}
+		return closed
+	}
+
+	b.failures++
+	if b.failures >= b.threshold {
+		b.openUntil = b.now().Add(b.cooldown)
+		metrics.CacheCircuitOpen.WithLabelValues(b.name).Set(1)
+	}
+	return false
+}
+
+// Open разомкнута ли цепь
+func (b *Breaker) Open() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return !b.openUntil.IsZero()
+}
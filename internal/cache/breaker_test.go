@@ -0,0 +1,130 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jmoloko/taskmange/internal/domain/repository"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBreaker(t *testing.T) {
+	now := time.Date(2024, 6, 10, 12, 0, 0, 0, time.UTC)
+	breaker := NewBreaker("test", 2, time.Minute)
+	breaker.now = func() time.Time { return now }
+	failure := errors.New("connection refused")
+
+	// промах и отмена запроса клиентом не размыкают цепь
+	breaker.Done(redis.Nil)
+	breaker.Done(context.Canceled)
+	breaker.Done(failure)
+	assert.True(t, breaker.Allow())
+	breaker.Done(failure)
+	assert.True(t, breaker.Open())
+	assert.False(t, breaker.Allow())
+
+	// после cooldown пропускается один пробный запрос
+	now = now.Add(time.Minute)
+	assert.True(t, breaker.Allow())
+	assert.False(t, breaker.Allow())
+	breaker.Done(failure)
+	assert.False(t, breaker.Allow())
+
+	now = now.Add(time.Minute)
+	assert.True(t, breaker.Allow())
+	assert.True(t, breaker.Done(nil))
+	assert.False(t, breaker.Open())
+	assert.True(t, breaker.Allow())
+
+	disabled := NewBreaker("test", 0, time.Minute)
+	for range 10 {
+		disabled.Done(failure)
+	}
+	assert.True(t, disabled.Allow())
+}
+
+func TestRedisCache_FallbackWhenUnavailable(t *testing.T) {
+	// порт, на котором никто не слушает
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := listener.Addr().String()
+	listener.Close()
+
+	var dials atomic.Int32
+	client := redis.NewClient(&redis.Options{Addr: addr, MaxRetries: -1, DialTimeout: 100 * time.Millisecond})
+	client.AddHook(dialCounter{&dials})
+	defer client.Close()
+
+	now := time.Date(2024, 6, 10, 12, 0, 0, 0, time.UTC)
+	breaker := NewBreaker("test", 2, time.Minute)
+	breaker.now = func() time.Time { return now }
+	c := NewRedisCache(client, breaker).(*RedisCache)
+	ctx := context.Background()
+
+	_, err = c.GetUserAnalytics(ctx, "user1", "day")
+	assert.Error(t, err)
+	assert.Error(t, c.SetUserAnalytics(ctx, repository.CachedAnalytics{UserID: "user1", Period: "day"}))
+	require.True(t, breaker.Open())
+
+	// цепь разомкнута: промах без обращения к Redis, сброс откладывается
+	attempts := dials.Load()
+	cached, err := c.GetUserAnalytics(ctx, "user1", "day")
+	assert.NoError(t, err)
+	assert.Nil(t, cached)
+	assert.NoError(t, c.SetUserAnalytics(ctx, repository.CachedAnalytics{UserID: "user1", Period: "day"}))
+	assert.NoError(t, c.InvalidateUserAnalytics(ctx, "user2"))
+	assert.Equal(t, attempts, dials.Load())
+	assert.Contains(t, c.pending, "user2")
+
+	// пробный запрос начинается с отложенного сброса; Redis все еще недоступен, сброс остается отложенным
+	now = now.Add(time.Minute)
+	_, err = c.GetUserAnalytics(ctx, "user1", "day")
+	assert.NoError(t, err)
+	assert.Greater(t, dials.Load(), attempts)
+	assert.Contains(t, c.pending, "user2")
+	assert.True(t, breaker.Open())
+}
+
+func TestRedisCache_PendingOverflow(t *testing.T) {
+	c := NewRedisCache(nil, nil).(*RedisCache)
+	for i := range maxPendingInvalidations + 1 {
+		c.postpone(fmt.Sprintf("user%d", i))
+	}
+	assert.True(t, c.pendingAll)
+	assert.Empty(t, c.pending)
+}
+
+func TestRedisCache_FlushPendingWithoutPending(t *testing.T) {
+	// клиента нет: без отложенных сбросов flushPending не обращается к Redis и ничего не выделяет
+	c := NewRedisCache(nil, nil).(*RedisCache)
+	ctx := context.Background()
+	allocs := testing.AllocsPerRun(100, func() {
+		assert.NoError(t, c.flushPending(ctx))
+	})
+	assert.Zero(t, allocs)
+}
+
+// dialCounter считает попытки соединиться с Redis
+type dialCounter struct {
+	dials *atomic.Int32
+}
+
+func (h dialCounter) DialHook(next redis.DialHook) redis.DialHook {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		h.dials.Add(1)
+		return next(ctx, network, addr)
+	}
+}
+
+func (h dialCounter) ProcessHook(next redis.ProcessHook) redis.ProcessHook { return next }
+
+func (h dialCounter) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return next
+}
@@ -0,0 +1,40 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Формат ключа: hooks:rate:{hookID}:{номер окна}
+const hookRateKeyFormat = "hooks:rate:%s:%d"
+
+// HookRateLimiter счетчик входящих запросов на webhook в фиксированном окне
+type HookRateLimiter struct {
+	client *redis.Client
+	now    func() time.Time
+}
+
+// NewHookRateLimiter создает новый экземпляр HookRateLimiter
+func NewHookRateLimiter(client *redis.Client) *HookRateLimiter {
+	return &HookRateLimiter{client: client, now: time.Now}
+}
+
+// AllowHook засчитывает запрос в текущем окне и возвращает false, если лимит исчерпан
+func (l *HookRateLimiter) AllowHook(ctx context.Context, hookID string, limit int, window time.Duration) (bool, error) {
+	key := fmt.Sprintf(hookRateKeyFormat, hookID, l.now().UnixNano()/int64(window))
+
+	var incr *redis.IntCmd
+	_, err := l.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		incr = pipe.Incr(ctx, key)
+		pipe.Expire(ctx, key, window)
+		return nil
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to count hook request: %w", err)
+	}
+
+	return incr.Val() <= int64(limit), nil
+}
@@ -0,0 +1,85 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/redis/go-redis/v9"
+)
+
+// Формат ключа: idempotency:{ключ запроса}
+const idempotencyKeyFormat = "idempotency:%s"
+
+// IdempotencyStore ответы на запросы с заголовком Idempotency-Key в Redis
+type IdempotencyStore struct {
+	client *redis.Client
+}
+
+// NewIdempotencyStore создает новый экземпляр IdempotencyStore
+func NewIdempotencyStore(client *redis.Client) *IdempotencyStore {
+	return &IdempotencyStore{client: client}
+}
+
+// Begin занимает ключ незавершенной записью на lockTTL. Если ключ уже занят, возвращает его запись,
+// иначе nil: запрос выполняется впервые
+func (s *IdempotencyStore) Begin(ctx context.Context, key string, lockTTL time.Duration) (*models.IdempotencyRecord, error) {
+	redisKey := fmt.Sprintf(idempotencyKeyFormat, key)
+	pending, err := json.Marshal(models.IdempotencyRecord{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal idempotency record: %w", err)
+	}
+
+	// запись может истечь между SETNX и GET, тогда ключ занимается заново
+	for attempt := 0; attempt < 2; attempt++ {
+		acquired, err := s.client.SetNX(ctx, redisKey, pending, lockTTL).Result()
+		if err != nil {
+			return nil, fmt.Errorf("failed to acquire idempotency key: %w", err)
+		}
+		if acquired {
+			return nil, nil
+		}
+
+		data, err := s.client.Get(ctx, redisKey).Bytes()
+		if err == redis.Nil {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get idempotency record: %w", err)
+		}
+
+		var record models.IdempotencyRecord
+		if err := json.Unmarshal(data, &record); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal idempotency record: %w", err)
+		}
+		return &record, nil
+	}
+
+	return nil, fmt.Errorf("failed to acquire idempotency key: record keeps expiring")
+}
+
+// Complete сохраняет ответ на запрос на ttl
+func (s *IdempotencyStore) Complete(ctx context.Context, key string, record models.IdempotencyRecord, ttl time.Duration) error {
+	record.Completed = true
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to marshal idempotency record: %w", err)
+	}
+
+	if err := s.client.Set(ctx, fmt.Sprintf(idempotencyKeyFormat, key), data, ttl).Err(); err != nil {
+		return fmt.Errorf("failed to save idempotency record: %w", err)
+	}
+
+	return nil
+}
+
+// Release освобождает ключ, чтобы запрос можно было повторить
+func (s *IdempotencyStore) Release(ctx context.Context, key string) error {
+	if err := s.client.Del(ctx, fmt.Sprintf(idempotencyKeyFormat, key)).Err(); err != nil {
+		return fmt.Errorf("failed to release idempotency key: %w", err)
+	}
+
+	return nil
+}
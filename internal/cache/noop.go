@@ -0,0 +1,28 @@
+package cache
+
+import (
+	"context"
+
+	"github.com/jmoloko/taskmange/internal/domain/repository"
+)
+
+// NoopCache кэш аналитики, который ничего не хранит: каждое чтение — промах, поэтому
+// аналитика считается по задачам при каждом запросе. Заменяет Redis, пока он недоступен
+type NoopCache struct{}
+
+// NewNoopCache создает новый экземпляр NoopCache
+func NewNoopCache() repository.AnalyticsCache {
+	return NoopCache{}
+}
+
+func (NoopCache) GetUserAnalytics(ctx context.Context, userID, period string) (*repository.CachedAnalytics, error) {
+	return nil, nil
+}
+
+func (NoopCache) SetUserAnalytics(ctx context.Context, analytics repository.CachedAnalytics) error {
+	return nil
+}
+
+func (NoopCache) InvalidateUserAnalytics(ctx context.Context, userID string) error {
+	return nil
+}
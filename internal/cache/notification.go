@@ -0,0 +1,108 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// Формат ключа: notifications:digest:{userID}
+	digestKeyFormat = "notifications:digest:%s"
+	// sorted set пользователей с открытым окном, score — время отправки дайджеста
+	digestDueKey = "notifications:digest:due"
+	// страховочный TTL буфера на случай, если воркер долго не работал
+	digestTTL = 7 * 24 * time.Hour
+)
+
+// NotificationBuffer буфер уведомлений для дайджестов в Redis
+type NotificationBuffer struct {
+	client *redis.Client
+}
+
+// NewNotificationBuffer создает новый экземпляр NotificationBuffer
+func NewNotificationBuffer(client *redis.Client) *NotificationBuffer {
+	return &NotificationBuffer{client: client}
+}
+
+// AddNotification добавляет уведомление в буфер пользователя
+func (b *NotificationBuffer) AddNotification(ctx context.Context, userID string, notification models.Notification, flushAt time.Time) error {
+	data, err := json.Marshal(notification)
+	if err != nil {
+		return fmt.Errorf("failed to marshal notification: %w", err)
+	}
+
+	key := fmt.Sprintf(digestKeyFormat, userID)
+	_, err = b.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.RPush(ctx, key, data)
+		pipe.Expire(ctx, key, digestTTL)
+		// NX: окно открывает первое уведомление, последующие его не сдвигают
+		pipe.ZAddNX(ctx, digestDueKey, redis.Z{Score: float64(flushAt.Unix()), Member: userID})
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to buffer notification: %w", err)
+	}
+
+	return nil
+}
+
+// DueNotificationUsers пользователи, дайджест которых пора отправить
+func (b *NotificationBuffer) DueNotificationUsers(ctx context.Context, now time.Time) ([]string, error) {
+	users, err := b.client.ZRangeByScore(ctx, digestDueKey, &redis.ZRangeBy{
+		Min: "-inf",
+		Max: strconv.FormatInt(now.Unix(), 10),
+	}).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get due digests: %w", err)
+	}
+
+	return users, nil
+}
+
+// PopNotifications атомарно забирает буфер пользователя и закрывает окно
+func (b *NotificationBuffer) PopNotifications(ctx context.Context, userID string) ([]models.Notification, error) {
+	key := fmt.Sprintf(digestKeyFormat, userID)
+
+	var items *redis.StringSliceCmd
+	_, err := b.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		items = pipe.LRange(ctx, key, 0, -1)
+		pipe.Del(ctx, key)
+		pipe.ZRem(ctx, digestDueKey, userID)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to pop notifications: %w", err)
+	}
+
+	notifications := make([]models.Notification, 0, len(items.Val()))
+	for _, item := range items.Val() {
+		var n models.Notification
+		if err := json.Unmarshal([]byte(item), &n); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal notification: %w", err)
+		}
+		notifications = append(notifications, n)
+	}
+
+	return notifications, nil
+}
+
+// NotificationBacklog размер буфера: все открытые окна и окна, дайджест которых уже пора отправить
+func (b *NotificationBuffer) NotificationBacklog(ctx context.Context, now time.Time) (int64, int64, error) {
+	var pending, overdue *redis.IntCmd
+	_, err := b.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		pending = pipe.ZCard(ctx, digestDueKey)
+		overdue = pipe.ZCount(ctx, digestDueKey, "-inf", strconv.FormatInt(now.Unix(), 10))
+		return nil
+	})
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to get notification backlog: %w", err)
+	}
+
+	return pending.Val(), overdue.Val(), nil
+}
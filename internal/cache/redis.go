@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/jmoloko/taskmange/internal/domain/repository"
@@ -14,21 +15,45 @@ const (
 	// Формат ключа: analytics:{userID}:{period}
 	analyticsKeyFormat = "analytics:%s:%s"
 	analyticsTTL       = 6 * time.Hour
+
+	// сколько пользователей со сброшенной во время недоступности Redis аналитикой запоминается;
+	// при переполнении после восстановления сбрасывается аналитика всех пользователей
+	maxPendingInvalidations = 10000
 )
 
+// RedisCache кэш аналитики в Redis. Пока размыкатель цепи разомкнут, запросы уходят
+// в запасной кэш (NoopCache), а сброс аналитики запоминается и выполняется после восстановления Redis
 type RedisCache struct {
-	client *redis.Client
+	client   *redis.Client
+	breaker  *Breaker
+	fallback repository.AnalyticsCache
+
+	mu sync.Mutex
+	// pending пользователи, аналитику которых не удалось сбросить в Redis
+	pending map[string]struct{}
+	// pendingAll сбросить аналитику всех пользователей: pending переполнился
+	pendingAll bool
 }
 
-// создание нового экземпляра кэша Redis
-func NewRedisCache(client *redis.Client) repository.AnalyticsCache {
-	return &RedisCache{client: client}
+// создание нового экземпляра кэша Redis; breaker nil — без размыкателя цепи
+func NewRedisCache(client *redis.Client, breaker *Breaker) repository.AnalyticsCache {
+	return &RedisCache{
+		client:   client,
+		breaker:  breaker,
+		fallback: NewNoopCache(),
+		pending:  make(map[string]struct{}),
+	}
 }
 
 // извлечение аналитических данных для определенного пользователя и периода из Redis
 func (c *RedisCache) GetUserAnalytics(ctx context.Context, userID, period string) (*repository.CachedAnalytics, error) {
+	if !c.available(ctx) {
+		return c.fallback.GetUserAnalytics(ctx, userID, period)
+	}
+
 	key := fmt.Sprintf(analyticsKeyFormat, userID, period)
 	data, err := c.client.Get(ctx, key).Bytes()
+	c.done(err)
 	if err != nil {
 		if err == redis.Nil {
 			return nil, nil // Cache miss
@@ -46,6 +71,10 @@ func (c *RedisCache) GetUserAnalytics(ctx context.Context, userID, period string
 
 // хранение аналитических данных для определенного пользователя и периода в Redis.
 func (c *RedisCache) SetUserAnalytics(ctx context.Context, analytics repository.CachedAnalytics) error {
+	if !c.available(ctx) {
+		return c.fallback.SetUserAnalytics(ctx, analytics)
+	}
+
 	key := fmt.Sprintf(analyticsKeyFormat, analytics.UserID, analytics.Period)
 
 	data, err := json.Marshal(analytics)
@@ -53,17 +82,35 @@ func (c *RedisCache) SetUserAnalytics(ctx context.Context, analytics repository.
 		return fmt.Errorf("failed to marshal analytics data: %w", err)
 	}
 
-	if err := c.client.Set(ctx, key, data, analyticsTTL).Err(); err != nil {
+	err = c.client.Set(ctx, key, data, analyticsTTL).Err()
+	c.done(err)
+	if err != nil {
 		return fmt.Errorf("failed to set analytics in cache: %w", err)
 	}
 
 	return nil
 }
 
-// удаление аналитическич данных для определенного пользователя из Redis
+// удаление аналитическич данных для определенного пользователя из Redis.
+// Если Redis недоступен, сброс выполняется после его восстановления
 func (c *RedisCache) InvalidateUserAnalytics(ctx context.Context, userID string) error {
-	pattern := fmt.Sprintf(analyticsKeyFormat, userID, "*")
+	if !c.available(ctx) {
+		c.postpone(userID)
+		return c.fallback.InvalidateUserAnalytics(ctx, userID)
+	}
+
+	err := c.deleteKeys(ctx, fmt.Sprintf(analyticsKeyFormat, userID, "*"))
+	c.done(err)
+	if err != nil {
+		c.postpone(userID)
+		return err
+	}
+
+	return nil
+}
 
+// deleteKeys удаляет ключи аналитики по шаблону
+func (c *RedisCache) deleteKeys(ctx context.Context, pattern string) error {
 	// Находим все ключи для данного пользователя
 	iter := c.client.Scan(ctx, 0, pattern, 0).Iterator()
 	for iter.Next(ctx) {
@@ -78,3 +125,74 @@ func (c *RedisCache) InvalidateUserAnalytics(ctx context.Context, userID string)
 
 	return nil
 }
+
+// available можно ли обратиться к Redis. Сбросы, пропущенные во время недоступности,
+// выполняются до первого обращения, чтобы после восстановления не читать устаревшую аналитику
+func (c *RedisCache) available(ctx context.Context) bool {
+	if c.breaker != nil && !c.breaker.Allow() {
+		return false
+	}
+	if err := c.flushPending(ctx); err != nil {
+		c.done(err)
+		return false
+	}
+	return true
+}
+
+func (c *RedisCache) done(err error) {
+	if c.breaker != nil {
+		c.breaker.Done(err)
+	}
+}
+
+// postpone запоминает пользователя, аналитику которого нужно сбросить после восстановления Redis
+func (c *RedisCache) postpone(userID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.pendingAll {
+		return
+	}
+	if len(c.pending) >= maxPendingInvalidations {
+		c.pending, c.pendingAll = make(map[string]struct{}), true
+		return
+	}
+	c.pending[userID] = struct{}{}
+}
+
+// flushPending выполняет отложенные сбросы; не выполненные из-за ошибки остаются отложенными.
+// Вызывается перед каждой операцией с кэшем, поэтому без отложенных сбросов ничего не выделяет
+func (c *RedisCache) flushPending(ctx context.Context) error {
+	c.mu.Lock()
+	if !c.pendingAll && len(c.pending) == 0 {
+		c.mu.Unlock()
+		return nil
+	}
+	all := c.pendingAll
+	users := make([]string, 0, len(c.pending))
+	for userID := range c.pending {
+		users = append(users, userID)
+	}
+	c.pending, c.pendingAll = make(map[string]struct{}), false
+	c.mu.Unlock()
+
+	if all {
+		if err := c.deleteKeys(ctx, fmt.Sprintf(analyticsKeyFormat, "*", "*")); err != nil {
+			c.mu.Lock()
+			c.pendingAll = true
+			c.mu.Unlock()
+			return err
+		}
+		return nil
+	}
+
+	for i, userID := range users {
+		if err := c.deleteKeys(ctx, fmt.Sprintf(analyticsKeyFormat, userID, "*")); err != nil {
+			for _, rest := range users[i:] {
+				c.postpone(rest)
+			}
+			return err
+		}
+	}
+	return nil
+}
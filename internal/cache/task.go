@@ -0,0 +1,163 @@
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/redis/go-redis/v9"
+)
+
+// Формат ключа: tasks:{taskID}
+const taskKeyFormat = "tasks:%s"
+
+// TaskCache кэш чтения задач по ID и списков задач по фильтрам в Redis с коротким TTL
+type TaskCache struct {
+	client *redis.Client
+	ttl    time.Duration
+}
+
+// NewTaskCache создает новый экземпляр TaskCache
+func NewTaskCache(client *redis.Client, ttl time.Duration) *TaskCache {
+	return &TaskCache{client: client, ttl: ttl}
+}
+
+// GetTask задача из кэша, nil — промах
+func (c *TaskCache) GetTask(ctx context.Context, taskID string) (*models.Task, error) {
+	data, err := c.client.Get(ctx, fmt.Sprintf(taskKeyFormat, taskID)).Bytes()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get task from cache: %w", err)
+	}
+
+	var task models.Task
+	if err := json.Unmarshal(data, &task); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal cached task: %w", err)
+	}
+
+	return &task, nil
+}
+
+// SetTask сохраняет задачу в кэш
+func (c *TaskCache) SetTask(ctx context.Context, task models.Task) error {
+	data, err := json.Marshal(task)
+	if err != nil {
+		return fmt.Errorf("failed to marshal task: %w", err)
+	}
+
+	if err := c.client.Set(ctx, fmt.Sprintf(taskKeyFormat, task.ID), data, c.ttl).Err(); err != nil {
+		return fmt.Errorf("failed to set task in cache: %w", err)
+	}
+
+	return nil
+}
+
+// InvalidateTask удаляет задачу из кэша
+func (c *TaskCache) InvalidateTask(ctx context.Context, taskID string) error {
+	if err := c.client.Del(ctx, fmt.Sprintf(taskKeyFormat, taskID)).Err(); err != nil {
+		return fmt.Errorf("failed to invalidate cached task: %w", err)
+	}
+
+	return nil
+}
+
+// Формат ключа списка: tasks:list:{userID}:{поколение}:{hash фильтров}
+const taskListKeyFormat = "tasks:list:%s:%d:%s"
+
+// Формат ключа поколения списков пользователя: tasks:list-gen:{userID}.
+// Счетчик хранится без TTL, иначе после его истечения поколение начнется заново
+// и откроет списки, сохраненные до сброса
+const taskListGenKeyFormat = "tasks:list-gen:%s"
+
+// taskListKey ключ списка задач: фильтры сериализуются в JSON и хэшируются,
+// одинаковые запросы попадают в один ключ. Поколение в ключе отделяет списки,
+// сохраненные до последнего сброса
+func taskListKey(filters models.TaskFilters, generation int64) (string, error) {
+	data, err := json.Marshal(filters)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal task filters: %w", err)
+	}
+
+	sum := sha256.Sum256(data)
+	return fmt.Sprintf(taskListKeyFormat, filters.UserID, generation, hex.EncodeToString(sum[:])), nil
+}
+
+// listGeneration текущее поколение списков пользователя, 0 — списки еще не сбрасывались
+func (c *TaskCache) listGeneration(ctx context.Context, userID string) (int64, error) {
+	generation, err := c.client.Get(ctx, fmt.Sprintf(taskListGenKeyFormat, userID)).Int64()
+	if err != nil {
+		if err == redis.Nil {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("failed to get task list generation: %w", err)
+	}
+
+	return generation, nil
+}
+
+// GetTaskList список задач из кэша, false — промах. Поколение списков пользователя
+// возвращается и при промахе: список, прочитанный из БД, сохраняется под ним же,
+// и сброс, случившийся между чтением и записью, не оживляет устаревший список
+func (c *TaskCache) GetTaskList(ctx context.Context, filters models.TaskFilters) ([]models.Task, int64, bool, error) {
+	generation, err := c.listGeneration(ctx, filters.UserID)
+	if err != nil {
+		return nil, 0, false, err
+	}
+
+	key, err := taskListKey(filters, generation)
+	if err != nil {
+		return nil, 0, false, err
+	}
+
+	data, err := c.client.Get(ctx, key).Bytes()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, generation, false, nil
+		}
+		return nil, 0, false, fmt.Errorf("failed to get task list from cache: %w", err)
+	}
+
+	var tasks []models.Task
+	if err := json.Unmarshal(data, &tasks); err != nil {
+		return nil, 0, false, fmt.Errorf("failed to unmarshal cached task list: %w", err)
+	}
+
+	return tasks, generation, true, nil
+}
+
+// SetTaskList сохраняет список задач в кэш под поколением, полученным из GetTaskList
+// до чтения списка из БД
+func (c *TaskCache) SetTaskList(ctx context.Context, filters models.TaskFilters, generation int64, tasks []models.Task) error {
+	key, err := taskListKey(filters, generation)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(tasks)
+	if err != nil {
+		return fmt.Errorf("failed to marshal task list: %w", err)
+	}
+
+	if err := c.client.Set(ctx, key, data, c.ttl).Err(); err != nil {
+		return fmt.Errorf("failed to set task list in cache: %w", err)
+	}
+
+	return nil
+}
+
+// InvalidateTaskLists сбрасывает все списки задач пользователя: увеличивает поколение,
+// и старые ключи больше не читаются, а истекают по TTL. Ключи не перебираются SCAN,
+// поэтому сброс стоит одну команду независимо от размера Redis
+func (c *TaskCache) InvalidateTaskLists(ctx context.Context, userID string) error {
+	if err := c.client.Incr(ctx, fmt.Sprintf(taskListGenKeyFormat, userID)).Err(); err != nil {
+		return fmt.Errorf("failed to invalidate cached task lists: %w", err)
+	}
+
+	return nil
+}
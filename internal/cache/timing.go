@@ -0,0 +1,33 @@
+package cache
+
+import (
+	"context"
+	"net"
+
+	"github.com/jmoloko/taskmange/internal/timing"
+	"github.com/redis/go-redis/v9"
+)
+
+// TimingHook учитывает время команд Redis в контексте запроса (timing.Cache) для заголовка
+// Server-Timing; конвейер команд считается одной операцией. Без контекста замера ничего не делает
+type TimingHook struct{}
+
+func (TimingHook) DialHook(next redis.DialHook) redis.DialHook {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		return next(ctx, network, addr)
+	}
+}
+
+func (TimingHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		defer timing.Start(ctx, timing.Cache)()
+		return next(ctx, cmd)
+	}
+}
+
+func (TimingHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		defer timing.Start(ctx, timing.Cache)()
+		return next(ctx, cmds)
+	}
+}
@@ -0,0 +1,49 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"net"
+
+	"github.com/jmoloko/taskmange/internal/tracing"
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TracingHook записывает span команд Redis внутри трассы запроса; конвейер — один span с числом команд.
+// Промах (redis.Nil) ошибкой не считается
+type TracingHook struct{}
+
+func (TracingHook) DialHook(next redis.DialHook) redis.DialHook {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		return next(ctx, network, addr)
+	}
+}
+
+func (TracingHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		ctx, span := tracing.StartChild(ctx, "redis "+cmd.Name(), trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(attribute.String("db.system", "redis"), attribute.String("db.operation", cmd.Name())))
+		err := next(ctx, cmd)
+		tracing.End(span, redisError(err))
+		return err
+	}
+}
+
+func (TracingHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		ctx, span := tracing.StartChild(ctx, "redis pipeline", trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(attribute.String("db.system", "redis"), attribute.Int("db.redis.commands", len(cmds))))
+		err := next(ctx, cmds)
+		tracing.End(span, redisError(err))
+		return err
+	}
+}
+
+func redisError(err error) error {
+	if errors.Is(err, redis.Nil) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,96 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// Формат ключа: usage:{YYYY-MM-DD}, поля {userID}:requests и {userID}:errors
+	usageKeyFormat = "usage:%s"
+	// счетчики дня хранятся, пока фоновая задача не перенесет их в Postgres после окончания дня
+	usageTTL = 3 * 24 * time.Hour
+
+	usageRequestsField = "requests"
+	usageErrorsField   = "errors"
+)
+
+// UsageCounter счетчики запросов пользователей за день в Redis
+type UsageCounter struct {
+	client *redis.Client
+}
+
+// NewUsageCounter создает новый экземпляр UsageCounter
+func NewUsageCounter(client *redis.Client) *UsageCounter {
+	return &UsageCounter{client: client}
+}
+
+// IncrementUsage увеличивает счетчики пользователя за день
+func (c *UsageCounter) IncrementUsage(ctx context.Context, userID string, day time.Time, failed bool) error {
+	key := usageKey(day)
+	_, err := c.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HIncrBy(ctx, key, userID+":"+usageRequestsField, 1)
+		if failed {
+			pipe.HIncrBy(ctx, key, userID+":"+usageErrorsField, 1)
+		}
+		pipe.Expire(ctx, key, usageTTL)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to increment usage: %w", err)
+	}
+
+	return nil
+}
+
+// GetUsage счетчики всех пользователей за день, по возрастанию ID пользователя
+func (c *UsageCounter) GetUsage(ctx context.Context, day time.Time) ([]models.UsageCount, error) {
+	fields, err := c.client.HGetAll(ctx, usageKey(day)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get usage: %w", err)
+	}
+
+	byUser := make(map[string]*models.UsageCount)
+	for field, value := range fields {
+		i := strings.LastIndex(field, ":")
+		if i < 0 {
+			continue
+		}
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse usage counter %s: %w", field, err)
+		}
+
+		userID := field[:i]
+		count, ok := byUser[userID]
+		if !ok {
+			count = &models.UsageCount{UserID: userID, Day: day}
+			byUser[userID] = count
+		}
+		switch field[i+1:] {
+		case usageRequestsField:
+			count.Requests = n
+		case usageErrorsField:
+			count.Errors = n
+		}
+	}
+
+	usage := make([]models.UsageCount, 0, len(byUser))
+	for _, count := range byUser {
+		usage = append(usage, *count)
+	}
+	sort.Slice(usage, func(i, j int) bool { return usage[i].UserID < usage[j].UserID })
+
+	return usage, nil
+}
+
+func usageKey(day time.Time) string {
+	return fmt.Sprintf(usageKeyFormat, day.UTC().Format("2006-01-02"))
+}
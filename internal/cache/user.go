@@ -0,0 +1,50 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Формат ключа: users:{userID}:active
+const userActiveKeyFormat = "users:%s:active"
+
+// UserStatusCache кэш признака активности пользователя в Redis с коротким TTL
+type UserStatusCache struct {
+	client *redis.Client
+	ttl    time.Duration
+}
+
+// NewUserStatusCache создает новый экземпляр UserStatusCache
+func NewUserStatusCache(client *redis.Client, ttl time.Duration) *UserStatusCache {
+	return &UserStatusCache{client: client, ttl: ttl}
+}
+
+// GetUserActive признак активности из кэша, found = false — промах
+func (c *UserStatusCache) GetUserActive(ctx context.Context, userID string) (bool, bool, error) {
+	value, err := c.client.Get(ctx, fmt.Sprintf(userActiveKeyFormat, userID)).Result()
+	if err != nil {
+		if err == redis.Nil {
+			return false, false, nil
+		}
+		return false, false, fmt.Errorf("failed to get user status from cache: %w", err)
+	}
+
+	return value == "1", true, nil
+}
+
+// SetUserActive сохраняет признак активности в кэш
+func (c *UserStatusCache) SetUserActive(ctx context.Context, userID string, active bool) error {
+	value := "0"
+	if active {
+		value = "1"
+	}
+
+	if err := c.client.Set(ctx, fmt.Sprintf(userActiveKeyFormat, userID), value, c.ttl).Err(); err != nil {
+		return fmt.Errorf("failed to set user status in cache: %w", err)
+	}
+
+	return nil
+}
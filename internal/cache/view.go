@@ -0,0 +1,73 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/redis/go-redis/v9"
+)
+
+// Формат ключа: views:{userID}:{key}
+const viewKeyFormat = "views:%s:%s"
+
+// TaskViewCache кэш списков "Сегодня" и "Предстоящие" в Redis с коротким TTL
+type TaskViewCache struct {
+	client *redis.Client
+	ttl    time.Duration
+}
+
+// NewTaskViewCache создает новый экземпляр TaskViewCache
+func NewTaskViewCache(client *redis.Client, ttl time.Duration) *TaskViewCache {
+	return &TaskViewCache{client: client, ttl: ttl}
+}
+
+// GetTaskView список из кэша, false — промах
+func (c *TaskViewCache) GetTaskView(ctx context.Context, userID, key string) ([]models.Task, bool, error) {
+	data, err := c.client.Get(ctx, fmt.Sprintf(viewKeyFormat, userID, key)).Bytes()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, false, nil
+		}
+		return nil, false, fmt.Errorf("failed to get task view from cache: %w", err)
+	}
+
+	var tasks []models.Task
+	if err := json.Unmarshal(data, &tasks); err != nil {
+		return nil, false, fmt.Errorf("failed to unmarshal cached task view: %w", err)
+	}
+
+	return tasks, true, nil
+}
+
+// SetTaskView сохраняет список в кэш
+func (c *TaskViewCache) SetTaskView(ctx context.Context, userID, key string, tasks []models.Task) error {
+	data, err := json.Marshal(tasks)
+	if err != nil {
+		return fmt.Errorf("failed to marshal task view: %w", err)
+	}
+
+	if err := c.client.Set(ctx, fmt.Sprintf(viewKeyFormat, userID, key), data, c.ttl).Err(); err != nil {
+		return fmt.Errorf("failed to set task view in cache: %w", err)
+	}
+
+	return nil
+}
+
+// InvalidateTaskViews удаляет все списки пользователя из кэша
+func (c *TaskViewCache) InvalidateTaskViews(ctx context.Context, userID string) error {
+	iter := c.client.Scan(ctx, 0, fmt.Sprintf(viewKeyFormat, userID, "*"), 0).Iterator()
+	for iter.Next(ctx) {
+		if err := c.client.Del(ctx, iter.Val()).Err(); err != nil {
+			return fmt.Errorf("failed to delete task view key %s: %w", iter.Val(), err)
+		}
+	}
+
+	if err := iter.Err(); err != nil {
+		return fmt.Errorf("failed to scan task view keys: %w", err)
+	}
+
+	return nil
+}
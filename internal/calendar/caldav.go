@@ -0,0 +1,191 @@
+package calendar
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/jmoloko/taskmange/internal/clock"
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/jmoloko/taskmange/internal/notification"
+)
+
+// CalDAV события календаря iCloud и других серверов CalDAV (RFC 4791) с базовой аутентификацией:
+// Apple ID и пароль приложения. Событие задачи хранится ресурсом <ID задачи>.ics в коллекции
+// календаря, изменения читаются отчетом sync-collection (RFC 6578). Уведомлений CalDAV не шлет,
+// поэтому такие календари опрашиваются. Адрес коллекции задает пользователь, поэтому запросы
+// идут клиентом, который не соединяется с адресами внутренней сети
+type CalDAV struct {
+	client *http.Client
+	clock  clock.Clock
+}
+
+// NewCalDAV создает новый экземпляр CalDAV
+func NewCalDAV() *CalDAV {
+	return &CalDAV{
+		client: notification.NewTargetClient(10 * time.Second),
+		clock:  clock.System,
+	}
+}
+
+// PutEvent создает или заменяет ресурс события задачи. ID события — имя ресурса
+func (c *CalDAV) PutEvent(ctx context.Context, link models.CalendarLink, eventID string, task models.Task) (string, error) {
+	if eventID == "" {
+		eventID = task.ID + ".ics"
+	}
+
+	var body bytes.Buffer
+	if err := WriteEvent(&body, task, c.clock.Now()); err != nil {
+		return "", fmt.Errorf("failed to write event: %w", err)
+	}
+
+	resp, err := c.do(ctx, link, http.MethodPut, resourceURL(link, eventID), &body, func(req *http.Request) {
+		req.Header.Set("Content-Type", "text/calendar; charset=utf-8")
+	})
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return "", statusError(resp)
+	}
+	return eventID, nil
+}
+
+// DeleteEvent удаляет ресурс события, уже удаленный ресурс не ошибка
+func (c *CalDAV) DeleteEvent(ctx context.Context, link models.CalendarLink, eventID string) error {
+	resp, err := c.do(ctx, link, http.MethodDelete, resourceURL(link, eventID), nil, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound || (resp.StatusCode >= 200 && resp.StatusCode < 300) {
+		return nil
+	}
+	return statusError(resp)
+}
+
+// Changes ресурсы коллекции, измененные с link.SyncToken. С пустой позицией события
+// не возвращаются, только начальная позиция. 403 и 409 на позицию — она устарела
+func (c *CalDAV) Changes(ctx context.Context, link models.CalendarLink) ([]models.CalendarEvent, string, error) {
+	var body bytes.Buffer
+	body.WriteString(`<?xml version="1.0" encoding="utf-8"?>` +
+		`<d:sync-collection xmlns:d="DAV:" xmlns:c="urn:ietf:params:xml:ns:caldav"><d:sync-token>`)
+	if err := xml.EscapeText(&body, []byte(link.SyncToken)); err != nil {
+		return nil, "", err
+	}
+	body.WriteString(`</d:sync-token><d:sync-level>1</d:sync-level><d:prop><d:getetag/>`)
+	if link.SyncToken != "" {
+		body.WriteString(`<c:calendar-data/>`)
+	}
+	body.WriteString(`</d:prop></d:sync-collection>`)
+
+	resp, err := c.do(ctx, link, "REPORT", link.CalendarID, &body, func(req *http.Request) {
+		req.Header.Set("Content-Type", "application/xml; charset=utf-8")
+		req.Header.Set("Depth", "1")
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+
+	if link.SyncToken != "" && (resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusConflict) {
+		return nil, "", ErrSyncTokenExpired
+	}
+	if resp.StatusCode != http.StatusMultiStatus {
+		return nil, "", statusError(resp)
+	}
+
+	var result multistatus
+	if err := xml.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, "", fmt.Errorf("failed to decode sync report: %w", err)
+	}
+	if link.SyncToken == "" {
+		return nil, result.SyncToken, nil
+	}
+
+	var events []models.CalendarEvent
+	for _, response := range result.Responses {
+		if !strings.HasSuffix(response.Href, ".ics") {
+			continue
+		}
+		event := models.CalendarEvent{ID: path.Base(response.Href)}
+
+		// удаленный ресурс приходит со статусом 404 без свойств
+		if strings.Contains(response.Status, " 404") {
+			event.Deleted = true
+			events = append(events, event)
+			continue
+		}
+
+		for _, propstat := range response.Propstat {
+			if !strings.Contains(propstat.Status, " 200") || propstat.Prop.CalendarData == "" {
+				continue
+			}
+			start, modified, err := ParseEvent(propstat.Prop.CalendarData)
+			if err != nil {
+				return nil, "", fmt.Errorf("event %s: %w", event.ID, err)
+			}
+			event.Start, event.Updated = start, modified
+			events = append(events, event)
+		}
+	}
+
+	return events, result.SyncToken, nil
+}
+
+// multistatus ответ отчета sync-collection
+type multistatus struct {
+	Responses []struct {
+		Href     string `xml:"DAV: href"`
+		Status   string `xml:"DAV: status"`
+		Propstat []struct {
+			Status string `xml:"DAV: status"`
+			Prop   struct {
+				CalendarData string `xml:"urn:ietf:params:xml:ns:caldav calendar-data"`
+			} `xml:"DAV: prop"`
+		} `xml:"DAV: propstat"`
+	} `xml:"DAV: response"`
+	SyncToken string `xml:"DAV: sync-token"`
+}
+
+// do отправляет запрос с учетными данными подключения, prepare дополняет заголовки
+func (c *CalDAV) do(ctx context.Context, link models.CalendarLink, method, endpoint string, body *bytes.Buffer, prepare func(*http.Request)) (*http.Response, error) {
+	var req *http.Request
+	var err error
+	if body != nil {
+		req, err = http.NewRequestWithContext(ctx, method, endpoint, body)
+	} else {
+		req, err = http.NewRequestWithContext(ctx, method, endpoint, nil)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.SetBasicAuth(link.Username, link.Secret)
+	if prepare != nil {
+		prepare(req)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	if resp.StatusCode == http.StatusUnauthorized {
+		resp.Body.Close()
+		return nil, ErrUnauthorized
+	}
+	return resp, nil
+}
+
+// resourceURL адрес ресурса события в коллекции календаря
+func resourceURL(link models.CalendarLink, eventID string) string {
+	return strings.TrimRight(link.CalendarID, "/") + "/" + url.PathEscape(eventID)
+}
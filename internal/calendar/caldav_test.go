@@ -0,0 +1,111 @@
+package calendar
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const syncReport = `<?xml version="1.0" encoding="utf-8"?>
+<multistatus xmlns="DAV:" xmlns:C="urn:ietf:params:xml:ns:caldav">
+  <response>
+    <href>/123/calendars/work/task1.ics</href>
+    <propstat>
+      <prop>
+        <getetag>"2"</getetag>
+        <C:calendar-data>BEGIN:VCALENDAR
+BEGIN:VEVENT
+DTSTART:20240510T080000Z
+LAST-MODIFIED:20240502T100000Z
+END:VEVENT
+END:VCALENDAR
+</C:calendar-data>
+      </prop>
+      <status>HTTP/1.1 200 OK</status>
+    </propstat>
+  </response>
+  <response>
+    <href>/123/calendars/work/task2.ics</href>
+    <status>HTTP/1.1 404 Not Found</status>
+  </response>
+  <sync-token>https://caldav.icloud.com/sync/2</sync-token>
+</multistatus>`
+
+func TestCalDAV(t *testing.T) {
+	var put string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, password, _ := r.BasicAuth()
+		if user != "me@icloud.com" || password != "app-password" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		body, _ := io.ReadAll(r.Body)
+		switch r.Method + " " + r.URL.Path {
+		case "PUT /123/calendars/work/task1.ics":
+			put = string(body)
+			w.WriteHeader(http.StatusCreated)
+		case "DELETE /123/calendars/work/task2.ics":
+			w.WriteHeader(http.StatusNotFound)
+		case "REPORT /123/calendars/work/":
+			assert.Equal(t, "1", r.Header.Get("Depth"))
+			switch {
+			case strings.Contains(string(body), "<d:sync-token></d:sync-token>"):
+				assert.NotContains(t, string(body), "calendar-data")
+				w.WriteHeader(http.StatusMultiStatus)
+				w.Write([]byte(`<multistatus xmlns="DAV:"><sync-token>https://caldav.icloud.com/sync/1</sync-token></multistatus>`))
+			case strings.Contains(string(body), "sync/1<"):
+				w.WriteHeader(http.StatusMultiStatus)
+				w.Write([]byte(syncReport))
+			default:
+				w.WriteHeader(http.StatusForbidden)
+			}
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	c := NewCalDAV()
+	// тестовый сервер слушает loopback, куда клиент по умолчанию не ходит
+	c.client = server.Client()
+	link := models.CalendarLink{CalendarID: server.URL + "/123/calendars/work/", Username: "me@icloud.com", Secret: "app-password"}
+	ctx := context.Background()
+
+	id, err := c.PutEvent(ctx, link, "", models.Task{ID: "task1", Title: "Report", DueDate: time.Date(2024, 5, 3, 9, 0, 0, 0, time.UTC)})
+	require.NoError(t, err)
+	assert.Equal(t, "task1.ics", id)
+	assert.Contains(t, put, "DTSTART:20240503T090000Z\r\n")
+
+	assert.NoError(t, c.DeleteEvent(ctx, link, "task2.ics"))
+
+	events, token, err := c.Changes(ctx, link)
+	require.NoError(t, err)
+	assert.Empty(t, events)
+	assert.Equal(t, "https://caldav.icloud.com/sync/1", token)
+
+	link.SyncToken = token
+	events, token, err = c.Changes(ctx, link)
+	require.NoError(t, err)
+	assert.Equal(t, "https://caldav.icloud.com/sync/2", token)
+	assert.Equal(t, []models.CalendarEvent{
+		{ID: "task1.ics", Start: time.Date(2024, 5, 10, 8, 0, 0, 0, time.UTC), Updated: time.Date(2024, 5, 2, 10, 0, 0, 0, time.UTC)},
+		{ID: "task2.ics", Deleted: true},
+	}, events)
+
+	link.SyncToken = "https://caldav.icloud.com/sync/0"
+	_, _, err = c.Changes(ctx, link)
+	assert.ErrorIs(t, err, ErrSyncTokenExpired)
+
+	link.Secret = "wrong"
+	_, err = c.PutEvent(ctx, link, "task1.ics", models.Task{ID: "task1"})
+	assert.ErrorIs(t, err, ErrUnauthorized)
+}
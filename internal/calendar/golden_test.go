@@ -0,0 +1,70 @@
+package calendar
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// update перезаписывает эталонные файлы в testdata: go test ./internal/calendar -update
+var update = flag.Bool("update", false, "rewrite golden files in testdata")
+
+// goldenTasks задачи для эталонных лент: юникодный заголовок длиннее 75 октетов,
+// спецсимволы iCalendar, задача без completed_at и задача без срока
+func goldenTasks() []models.Task {
+	created := time.Date(2024, 4, 20, 9, 15, 0, 0, time.UTC)
+	completed := time.Date(2024, 5, 2, 8, 0, 0, 0, time.UTC)
+	return append(calendarTasks(),
+		models.Task{
+			ID:          "task4",
+			Title:       "Подготовить квартальный отчёт для совета директоров и разослать участникам 📊",
+			Description: "Проверить \"цифры\"; приложить таблицу, презентацию\\заметки",
+			Status:      models.StatusPending,
+			Priority:    models.PriorityMedium,
+			DueDate:     time.Date(2024, 5, 10, 18, 0, 0, 0, time.UTC),
+			Tags:        []string{"работа", "отчёт,кв2"},
+			CreatedAt:   created,
+			UpdatedAt:   created,
+		},
+		models.Task{
+			ID:          "task5",
+			Title:       "Закрыта без срока",
+			Status:      models.StatusDone,
+			CompletedAt: &completed,
+		},
+	)
+}
+
+// golden сравнивает ленту с testdata/name, с флагом -update перезаписывает файл
+func golden(t *testing.T, name, got string) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *update {
+		require.NoError(t, os.WriteFile(path, []byte(got), 0o644))
+	}
+	want, err := os.ReadFile(path)
+	require.NoError(t, err, "golden file is missing, run go test with -update")
+	assert.Equal(t, string(want), got, "output differs from %s, run go test with -update if the change is intended", path)
+}
+
+func TestWrite_Golden(t *testing.T) {
+	for _, kind := range []Kind{KindEvent, KindTodo} {
+		t.Run(string(kind), func(t *testing.T) {
+			feed := write(t, kind, goldenTasks())
+			// строки ленты разделяются CRLF, перенос длинных строк не должен разрезать символы UTF-8
+			for _, line := range strings.Split(strings.TrimSuffix(feed, "\r\n"), "\r\n") {
+				assert.LessOrEqual(t, len(line), maxLine)
+				assert.True(t, utf8.ValidString(line), line)
+			}
+			golden(t, "tasks-"+string(kind)+".ics", feed)
+		})
+	}
+}
@@ -0,0 +1,325 @@
+package calendar
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jmoloko/taskmange/internal/clock"
+	"github.com/jmoloko/taskmange/internal/domain/models"
+)
+
+// GoogleCalendar события календаря через Google Calendar API v3. Доступ дает refresh token
+// пользователя, выданный OAuth-клиенту сервера; access token обновляется по нему и хранится
+// в памяти до истечения
+type GoogleCalendar struct {
+	client       *http.Client
+	apiURL       string
+	tokenURL     string
+	clientID     string
+	clientSecret string
+
+	mu     sync.Mutex
+	tokens map[string]accessToken
+	clock  clock.Clock
+}
+
+// accessToken access token OAuth и время, после которого его нужно обновить
+type accessToken struct {
+	value     string
+	expiresAt time.Time
+}
+
+// googleEventTime время события: dateTime у событий со временем, date у событий на весь день
+type googleEventTime struct {
+	DateTime string `json:"dateTime,omitempty"`
+	Date     string `json:"date,omitempty"`
+}
+
+// NewGoogleCalendar создает новый экземпляр GoogleCalendar.
+// apiURL — адрес API (https://www.googleapis.com/calendar/v3), tokenURL — адрес выдачи токенов OAuth
+func NewGoogleCalendar(apiURL, tokenURL, clientID, clientSecret string) *GoogleCalendar {
+	return &GoogleCalendar{
+		client:       &http.Client{Timeout: 10 * time.Second},
+		apiURL:       strings.TrimRight(apiURL, "/"),
+		tokenURL:     tokenURL,
+		clientID:     clientID,
+		clientSecret: clientSecret,
+		tokens:       make(map[string]accessToken),
+		clock:        clock.System,
+	}
+}
+
+// PutEvent создает событие задачи (пустой eventID) или переносит и переименовывает существующее.
+// Событие занимает нулевое время в момент срока и не отмечает пользователя занятым
+func (g *GoogleCalendar) PutEvent(ctx context.Context, link models.CalendarLink, eventID string, task models.Task) (string, error) {
+	start := googleEventTime{DateTime: task.DueDate.UTC().Format(time.RFC3339)}
+	event := map[string]interface{}{
+		"start":        start,
+		"end":          start,
+		"transparency": "transparent",
+		"extendedProperties": map[string]interface{}{
+			"private": map[string]string{"taskId": task.ID},
+		},
+	}
+	if task.Private {
+		event["summary"] = PrivateSummary
+		event["description"] = ""
+		event["visibility"] = "private"
+	} else {
+		event["summary"] = task.Title
+		event["description"] = task.Description
+	}
+
+	method, endpoint := http.MethodPost, g.eventsURL(link)
+	if eventID != "" {
+		method, endpoint = http.MethodPatch, endpoint+"/"+url.PathEscape(eventID)
+	}
+
+	var created struct {
+		ID string `json:"id"`
+	}
+	if err := g.do(ctx, link, method, endpoint, event, &created); err != nil {
+		return "", err
+	}
+
+	return created.ID, nil
+}
+
+// DeleteEvent удаляет событие, уже удаленное событие не ошибка
+func (g *GoogleCalendar) DeleteEvent(ctx context.Context, link models.CalendarLink, eventID string) error {
+	err := g.do(ctx, link, http.MethodDelete, g.eventsURL(link)+"/"+url.PathEscape(eventID), nil, nil)
+	if err == ErrEventNotFound {
+		return nil
+	}
+	return err
+}
+
+// Changes события, измененные с link.SyncToken, включая удаленные. С пустой позицией события
+// календаря не возвращаются: Google выдает начальную позицию только после чтения всего списка.
+// 410 — позиция устарела
+func (g *GoogleCalendar) Changes(ctx context.Context, link models.CalendarLink) ([]models.CalendarEvent, string, error) {
+	var (
+		events    []models.CalendarEvent
+		pageToken string
+	)
+	for {
+		query := url.Values{
+			"showDeleted":  {"true"},
+			"singleEvents": {"true"},
+			"maxResults":   {"250"},
+			"fields":       {"items(id,status,updated,start),nextPageToken,nextSyncToken"},
+		}
+		if link.SyncToken != "" {
+			query.Set("syncToken", link.SyncToken)
+		}
+		if pageToken != "" {
+			query.Set("pageToken", pageToken)
+		}
+
+		var page struct {
+			Items []struct {
+				ID      string          `json:"id"`
+				Status  string          `json:"status"`
+				Updated time.Time       `json:"updated"`
+				Start   googleEventTime `json:"start"`
+			} `json:"items"`
+			NextPageToken string `json:"nextPageToken"`
+			NextSyncToken string `json:"nextSyncToken"`
+		}
+		err := g.do(ctx, link, http.MethodGet, g.eventsURL(link)+"?"+query.Encode(), nil, &page)
+		if err == ErrEventNotFound && link.SyncToken != "" {
+			return nil, "", ErrSyncTokenExpired
+		}
+		if err != nil {
+			return nil, "", err
+		}
+
+		if link.SyncToken != "" {
+			for _, item := range page.Items {
+				event := models.CalendarEvent{
+					ID:      item.ID,
+					Updated: item.Updated,
+					Deleted: item.Status == "cancelled",
+				}
+				if !event.Deleted {
+					start, err := item.Start.parse()
+					if err != nil {
+						return nil, "", fmt.Errorf("event %s: %w", item.ID, err)
+					}
+					event.Start = start
+				}
+				events = append(events, event)
+			}
+		}
+
+		if page.NextPageToken == "" {
+			return events, page.NextSyncToken, nil
+		}
+		pageToken = page.NextPageToken
+	}
+}
+
+// Watch открывает канал уведомлений об изменениях событий календаря на address.
+// ID и Token канала задает вызывающий, Google возвращает ресурс и время окончания канала
+func (g *GoogleCalendar) Watch(ctx context.Context, link models.CalendarLink, channel models.CalendarChannel, address string) (models.CalendarChannel, error) {
+	body := map[string]string{
+		"id":      channel.ID,
+		"type":    "web_hook",
+		"address": address,
+		"token":   channel.Token,
+	}
+
+	var watched struct {
+		ResourceID string `json:"resourceId"`
+		// Expiration миллисекунды Unix строкой
+		Expiration string `json:"expiration"`
+	}
+	if err := g.do(ctx, link, http.MethodPost, g.eventsURL(link)+"/watch", body, &watched); err != nil {
+		return models.CalendarChannel{}, err
+	}
+
+	channel.ResourceID = watched.ResourceID
+	if ms, err := strconv.ParseInt(watched.Expiration, 10, 64); err == nil {
+		expiresAt := time.UnixMilli(ms).UTC()
+		channel.ExpiresAt = &expiresAt
+	}
+	return channel, nil
+}
+
+// StopWatch закрывает канал link.Channel, уже закрытый канал не ошибка
+func (g *GoogleCalendar) StopWatch(ctx context.Context, link models.CalendarLink) error {
+	body := map[string]string{"id": link.Channel.ID, "resourceId": link.Channel.ResourceID}
+	err := g.do(ctx, link, http.MethodPost, g.apiURL+"/channels/stop", body, nil)
+	if err == ErrEventNotFound {
+		return nil
+	}
+	return err
+}
+
+func (g *GoogleCalendar) eventsURL(link models.CalendarLink) string {
+	return g.apiURL + "/calendars/" + url.PathEscape(link.CalendarID) + "/events"
+}
+
+// do выполняет запрос к API от имени пользователя подключения: in кодируется в JSON, ответ
+// декодируется в out. 404 и 410 считаются ErrEventNotFound
+func (g *GoogleCalendar) do(ctx context.Context, link models.CalendarLink, method, endpoint string, in, out interface{}) error {
+	token, err := g.accessToken(ctx, link.Secret)
+	if err != nil {
+		return err
+	}
+
+	var body bytes.Buffer
+	if in != nil {
+		if err := json.NewEncoder(&body).Encode(in); err != nil {
+			return fmt.Errorf("failed to encode request: %w", err)
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, &body)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+		return ErrEventNotFound
+	case resp.StatusCode == http.StatusUnauthorized:
+		// access token отозван раньше срока, следующий запрос получит новый
+		g.mu.Lock()
+		delete(g.tokens, link.Secret)
+		g.mu.Unlock()
+		return ErrUnauthorized
+	case resp.StatusCode < 200 || resp.StatusCode >= 300:
+		return statusError(resp)
+	}
+
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode calendar response: %w", err)
+	}
+	return nil
+}
+
+// accessToken действующий access token для refresh token, при необходимости обновляется
+func (g *GoogleCalendar) accessToken(ctx context.Context, refreshToken string) (string, error) {
+	g.mu.Lock()
+	cached, ok := g.tokens[refreshToken]
+	g.mu.Unlock()
+	if ok && g.clock.Now().Before(cached.expiresAt) {
+		return cached.value, nil
+	}
+
+	form := url.Values{
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {refreshToken},
+		"client_id":     {g.clientID},
+		"client_secret": {g.clientSecret},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, g.tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to refresh access token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// отозванный или чужой refresh token — invalid_grant с кодом 400
+	if resp.StatusCode == http.StatusBadRequest || resp.StatusCode == http.StatusUnauthorized {
+		return "", ErrUnauthorized
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", statusError(resp)
+	}
+
+	var issued struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&issued); err != nil {
+		return "", fmt.Errorf("failed to decode token response: %w", err)
+	}
+
+	// обновляем за минуту до истечения, чтобы токен не истек в пути
+	token := accessToken{
+		value:     issued.AccessToken,
+		expiresAt: g.clock.Now().Add(time.Duration(issued.ExpiresIn)*time.Second - time.Minute),
+	}
+	g.mu.Lock()
+	g.tokens[refreshToken] = token
+	g.mu.Unlock()
+
+	return token.value, nil
+}
+
+// parse начало события; событие на весь день начинается в полночь UTC
+func (t googleEventTime) parse() (time.Time, error) {
+	if t.DateTime != "" {
+		start, err := time.Parse(time.RFC3339, t.DateTime)
+		return start.UTC(), err
+	}
+	return time.Parse("2006-01-02", t.Date)
+}
@@ -0,0 +1,108 @@
+package calendar
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGoogleCalendar(t *testing.T) {
+	refreshes := 0
+	var patched map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/token" {
+			require.NoError(t, r.ParseForm())
+			if r.PostForm.Get("refresh_token") != "refresh" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			assert.Equal(t, "client", r.PostForm.Get("client_id"))
+			refreshes++
+			w.Write([]byte(`{"access_token":"access","expires_in":3600}`))
+			return
+		}
+
+		assert.Equal(t, "Bearer access", r.Header.Get("Authorization"))
+		switch r.Method + " " + r.URL.Path {
+		case "POST /calendars/work@group/events":
+			w.Write([]byte(`{"id":"evt1"}`))
+		case "PATCH /calendars/work@group/events/evt1":
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&patched))
+			w.Write([]byte(`{"id":"evt1"}`))
+		case "GET /calendars/work@group/events":
+			switch r.URL.Query().Get("syncToken") {
+			case "":
+				if r.URL.Query().Get("pageToken") == "" {
+					w.Write([]byte(`{"items":[{"id":"old"}],"nextPageToken":"p2"}`))
+				} else {
+					w.Write([]byte(`{"nextSyncToken":"s1"}`))
+				}
+			case "s1":
+				w.Write([]byte(`{"items":[
+					{"id":"evt1","status":"confirmed","updated":"2024-05-02T10:00:00Z","start":{"dateTime":"2024-05-03T12:00:00+03:00"}},
+					{"id":"evt2","status":"confirmed","updated":"2024-05-02T10:00:00Z","start":{"date":"2024-05-04"}},
+					{"id":"evt3","status":"cancelled"}],"nextSyncToken":"s2"}`))
+			default:
+				w.WriteHeader(http.StatusGone)
+			}
+		case "DELETE /calendars/work@group/events/gone":
+			w.WriteHeader(http.StatusGone)
+		case "POST /calendars/work@group/events/watch":
+			w.Write([]byte(`{"resourceId":"res1","expiration":"1714644000000"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	g := NewGoogleCalendar(server.URL, server.URL+"/token", "client", "secret")
+	link := models.CalendarLink{CalendarID: "work@group", Secret: "refresh"}
+	ctx := context.Background()
+	task := models.Task{ID: "task1", Title: "secret", Private: true, DueDate: time.Date(2024, 5, 3, 9, 0, 0, 0, time.UTC)}
+
+	id, err := g.PutEvent(ctx, link, "", task)
+	require.NoError(t, err)
+	assert.Equal(t, "evt1", id)
+	_, err = g.PutEvent(ctx, link, "evt1", task)
+	require.NoError(t, err)
+	assert.Equal(t, PrivateSummary, patched["summary"])
+	assert.Equal(t, map[string]interface{}{"dateTime": "2024-05-03T09:00:00Z"}, patched["start"])
+	// access token переиспользуется до истечения
+	assert.Equal(t, 1, refreshes)
+
+	events, token, err := g.Changes(ctx, link)
+	require.NoError(t, err)
+	assert.Empty(t, events)
+	assert.Equal(t, "s1", token)
+
+	link.SyncToken = "s1"
+	events, token, err = g.Changes(ctx, link)
+	require.NoError(t, err)
+	assert.Equal(t, "s2", token)
+	require.Len(t, events, 3)
+	assert.Equal(t, time.Date(2024, 5, 3, 9, 0, 0, 0, time.UTC), events[0].Start)
+	assert.Equal(t, time.Date(2024, 5, 4, 0, 0, 0, 0, time.UTC), events[1].Start)
+	assert.True(t, events[2].Deleted)
+
+	link.SyncToken = "stale"
+	_, _, err = g.Changes(ctx, link)
+	assert.ErrorIs(t, err, ErrSyncTokenExpired)
+
+	assert.NoError(t, g.DeleteEvent(ctx, link, "gone"))
+
+	channel, err := g.Watch(ctx, link, models.CalendarChannel{ID: "ch1", Token: "tok"}, "https://tasks.example.com/hook")
+	require.NoError(t, err)
+	assert.Equal(t, "res1", channel.ResourceID)
+	require.NotNil(t, channel.ExpiresAt)
+	assert.Equal(t, time.Date(2024, 5, 2, 10, 0, 0, 0, time.UTC), *channel.ExpiresAt)
+
+	_, _, err = g.Changes(ctx, models.CalendarLink{CalendarID: "work@group", Secret: "revoked"})
+	assert.ErrorIs(t, err, ErrUnauthorized)
+}
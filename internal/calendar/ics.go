@@ -0,0 +1,233 @@
+// Package calendar записывает задачи со сроком в формате iCalendar (RFC 5545) для подписки из календарей
+// и синхронизирует сроки задач с событиями календарей Google и Apple
+package calendar
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/jmoloko/taskmange/internal/domain/models"
+)
+
+// Kind вид записей календаря
+type Kind string
+
+const (
+	// KindEvent события в момент срока, их показывают все календари
+	KindEvent Kind = "event"
+	// KindTodo задачи со сроком и статусом выполнения, их понимают не все календари
+	KindTodo Kind = "todo"
+)
+
+// ParseKind разбирает параметр kind, пустое значение — события
+func ParseKind(value string) (Kind, error) {
+	switch Kind(value) {
+	case "", KindEvent:
+		return KindEvent, nil
+	case KindTodo:
+		return KindTodo, nil
+	}
+	return "", fmt.Errorf("unsupported calendar kind %q", value)
+}
+
+// ContentType тип содержимого ленты
+const ContentType = "text/calendar; charset=utf-8"
+
+// PrivateSummary заголовок приватной задачи: календари хранят ленту у себя, поэтому
+// заголовок и описание приватных задач в нее не попадают
+const PrivateSummary = "Private task"
+
+// prodID идентификатор программы, создавшей календарь
+const prodID = "-//taskmanager//Tasks//EN"
+
+// maxLine длина строки в октетах без CRLF, длинные строки переносятся
+const maxLine = 75
+
+// Feed заголовок календаря
+type Feed struct {
+	// Name имя календаря, которое показывают клиенты
+	Name string
+	Kind Kind
+	// Now время формирования ленты, для DTSTAMP
+	Now time.Time
+}
+
+// Write записывает календарь с задачами. Задачи без срока пропускаются
+func Write(w io.Writer, feed Feed, tasks []models.Task) error {
+	out := &writer{w: bufio.NewWriter(w)}
+
+	out.line("BEGIN", "VCALENDAR")
+	out.line("VERSION", "2.0")
+	out.line("PRODID", prodID)
+	out.line("CALSCALE", "GREGORIAN")
+	out.line("METHOD", "PUBLISH")
+	if feed.Name != "" {
+		out.text("X-WR-CALNAME", feed.Name)
+	}
+
+	for _, task := range tasks {
+		if task.DueDate.IsZero() {
+			continue
+		}
+		if feed.Kind == KindTodo {
+			writeTodo(out, feed, task)
+		} else {
+			writeEvent(out, feed, task)
+		}
+	}
+
+	out.line("END", "VCALENDAR")
+	if out.err != nil {
+		return out.err
+	}
+	return out.w.Flush()
+}
+
+// WriteEvent записывает объект календаря CalDAV с событием одной задачи: без METHOD и имени
+// календаря, которых не должно быть в ресурсе коллекции (RFC 4791)
+func WriteEvent(w io.Writer, task models.Task, now time.Time) error {
+	out := &writer{w: bufio.NewWriter(w)}
+
+	out.line("BEGIN", "VCALENDAR")
+	out.line("VERSION", "2.0")
+	out.line("PRODID", prodID)
+	writeEvent(out, Feed{Kind: KindEvent, Now: now}, task)
+	out.line("END", "VCALENDAR")
+	if out.err != nil {
+		return out.err
+	}
+	return out.w.Flush()
+}
+
+func writeEvent(out *writer, feed Feed, task models.Task) {
+	out.line("BEGIN", "VEVENT")
+	writeCommon(out, feed, task)
+	// событие без DTEND занимает нулевое время в момент срока
+	out.line("DTSTART", timestamp(task.DueDate))
+	out.line("TRANSP", "TRANSPARENT")
+	out.line("END", "VEVENT")
+}
+
+func writeTodo(out *writer, feed Feed, task models.Task) {
+	out.line("BEGIN", "VTODO")
+	writeCommon(out, feed, task)
+	out.line("DUE", timestamp(task.DueDate))
+	out.line("STATUS", todoStatus(task.Status))
+	if task.CompletedAt != nil {
+		out.line("COMPLETED", timestamp(*task.CompletedAt))
+	}
+	out.line("END", "VTODO")
+}
+
+// writeCommon свойства, общие для VEVENT и VTODO
+func writeCommon(out *writer, feed Feed, task models.Task) {
+	out.line("UID", task.ID+"@taskmanager")
+	out.line("DTSTAMP", timestamp(feed.Now))
+	if !task.CreatedAt.IsZero() {
+		out.line("CREATED", timestamp(task.CreatedAt))
+	}
+	if !task.UpdatedAt.IsZero() {
+		out.line("LAST-MODIFIED", timestamp(task.UpdatedAt))
+	}
+	if task.Private {
+		out.text("SUMMARY", PrivateSummary)
+		out.line("CLASS", "PRIVATE")
+	} else {
+		out.text("SUMMARY", task.Title)
+		if task.Description != "" {
+			out.text("DESCRIPTION", task.Description)
+		}
+	}
+	if priority := priority(task.Priority); priority != "" {
+		out.line("PRIORITY", priority)
+	}
+	if len(task.Tags) > 0 {
+		values := make([]string, len(task.Tags))
+		for i, tag := range task.Tags {
+			values[i] = escape(tag)
+		}
+		out.line("CATEGORIES", strings.Join(values, ","))
+	}
+}
+
+// todoStatus статус VTODO
+func todoStatus(status models.Status) string {
+	switch status {
+	case models.StatusInProgress:
+		return "IN-PROCESS"
+	case models.StatusDone:
+		return "COMPLETED"
+	}
+	return "NEEDS-ACTION"
+}
+
+// priority приоритет iCalendar: 1 — высший, 5 — средний, 9 — низший
+func priority(priority models.Priority) string {
+	switch priority {
+	case models.PriorityHigh:
+		return "1"
+	case models.PriorityMedium:
+		return "5"
+	case models.PriorityLow:
+		return "9"
+	}
+	return ""
+}
+
+// timestamp время в UTC в формате DATE-TIME
+func timestamp(t time.Time) string {
+	return t.UTC().Format("20060102T150405Z")
+}
+
+// escape экранирует значение TEXT
+func escape(value string) string {
+	return textEscaper.Replace(value)
+}
+
+var textEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	";", `\;`,
+	",", `\,`,
+	"\r\n", `\n`,
+	"\n", `\n`,
+	"\r", `\n`,
+)
+
+// writer пишет строки содержимого с переносом длинных строк и запоминает первую ошибку
+type writer struct {
+	w   *bufio.Writer
+	err error
+}
+
+// text свойство со значением TEXT
+func (o *writer) text(name, value string) {
+	o.line(name, escape(value))
+}
+
+// line свойство со значением как есть. Строки длиннее maxLine октетов переносятся:
+// продолжение начинается с пробела, многобайтовые символы не разрываются
+func (o *writer) line(name, value string) {
+	if o.err != nil {
+		return
+	}
+
+	line := name + ":" + value
+	limit := maxLine
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		if _, o.err = o.w.WriteString(line[:cut] + "\r\n "); o.err != nil {
+			return
+		}
+		line = line[cut:]
+		// пробел в начале продолжения входит в длину строки
+		limit = maxLine - 1
+	}
+	_, o.err = o.w.WriteString(line + "\r\n")
+}
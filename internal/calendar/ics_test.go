@@ -0,0 +1,139 @@
+package calendar
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func calendarTasks() []models.Task {
+	completed := time.Date(2024, 5, 2, 8, 0, 0, 0, time.UTC)
+	return []models.Task{
+		{
+			ID:          "task1",
+			Title:       "Report; final, v2",
+			Description: "line one\nline two",
+			Status:      models.StatusDone,
+			Priority:    models.PriorityHigh,
+			DueDate:     time.Date(2024, 5, 1, 12, 0, 0, 0, time.FixedZone("MSK", 3*3600)),
+			Tags:        []string{"work", "q2"},
+			CompletedAt: &completed,
+		},
+		{ID: "task2", Title: "secret", Description: "secret too", Status: models.StatusInProgress, Priority: models.PriorityLow,
+			DueDate: time.Date(2024, 5, 3, 9, 30, 0, 0, time.UTC), Private: true},
+		{ID: "task3", Title: "No due date", Status: models.StatusPending},
+	}
+}
+
+func write(t *testing.T, kind Kind, tasks []models.Task) string {
+	var buf bytes.Buffer
+	feed := Feed{Name: "Tasks", Kind: kind, Now: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)}
+	require.NoError(t, Write(&buf, feed, tasks))
+	return buf.String()
+}
+
+func TestParseKind(t *testing.T) {
+	kind, err := ParseKind("")
+	require.NoError(t, err)
+	assert.Equal(t, KindEvent, kind)
+
+	kind, err = ParseKind("todo")
+	require.NoError(t, err)
+	assert.Equal(t, KindTodo, kind)
+
+	_, err = ParseKind("journal")
+	assert.Error(t, err)
+}
+
+func TestWrite_Events(t *testing.T) {
+	ics := write(t, KindEvent, calendarTasks())
+
+	assert.True(t, strings.HasPrefix(ics, "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n"))
+	assert.True(t, strings.HasSuffix(ics, "END:VCALENDAR\r\n"))
+	assert.Contains(t, ics, "X-WR-CALNAME:Tasks\r\n")
+	assert.Equal(t, 2, strings.Count(ics, "BEGIN:VEVENT\r\n"))
+	assert.NotContains(t, ics, "VTODO")
+
+	assert.Contains(t, ics, "UID:task1@taskmanager\r\n")
+	assert.Contains(t, ics, "DTSTART:20240501T090000Z\r\n")
+	assert.Contains(t, ics, `SUMMARY:Report\; final\, v2`+"\r\n")
+	assert.Contains(t, ics, `DESCRIPTION:line one\nline two`+"\r\n")
+	assert.Contains(t, ics, "PRIORITY:1\r\n")
+	assert.Contains(t, ics, "CATEGORIES:work,q2\r\n")
+	assert.Contains(t, ics, "DTSTAMP:20240501T000000Z\r\n")
+
+	assert.NotContains(t, ics, "No due date")
+}
+
+func TestWrite_PrivateTask(t *testing.T) {
+	ics := write(t, KindEvent, calendarTasks())
+
+	assert.Contains(t, ics, "SUMMARY:"+PrivateSummary+"\r\n")
+	assert.Contains(t, ics, "CLASS:PRIVATE\r\n")
+	assert.NotContains(t, ics, "secret")
+}
+
+func TestWrite_Todos(t *testing.T) {
+	ics := write(t, KindTodo, calendarTasks())
+
+	assert.Equal(t, 2, strings.Count(ics, "BEGIN:VTODO\r\n"))
+	assert.NotContains(t, ics, "VEVENT")
+	assert.Contains(t, ics, "DUE:20240501T090000Z\r\n")
+	assert.Contains(t, ics, "STATUS:COMPLETED\r\n")
+	assert.Contains(t, ics, "COMPLETED:20240502T080000Z\r\n")
+	assert.Contains(t, ics, "STATUS:IN-PROCESS\r\n")
+	assert.Contains(t, ics, "PRIORITY:9\r\n")
+}
+
+func TestWrite_FoldsLongLines(t *testing.T) {
+	title := strings.Repeat("Задача ", 30)
+	ics := write(t, KindEvent, []models.Task{{ID: "task1", Title: title, DueDate: time.Now()}})
+
+	for _, line := range strings.Split(strings.TrimSuffix(ics, "\r\n"), "\r\n") {
+		assert.LessOrEqual(t, len(line), maxLine)
+		assert.True(t, strings.ToValidUTF8(line, "?") == line, "line %q splits a character", line)
+	}
+
+	// разворачивание переноса восстанавливает исходную строку
+	unfolded := strings.ReplaceAll(ics, "\r\n ", "")
+	assert.Contains(t, unfolded, "SUMMARY:"+title+"\r\n")
+}
+
+func TestWriteEvent_ParseEvent(t *testing.T) {
+	task := calendarTasks()[1]
+	task.UpdatedAt = time.Date(2024, 5, 2, 10, 0, 0, 0, time.UTC)
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteEvent(&buf, task, time.Date(2024, 5, 2, 11, 0, 0, 0, time.UTC)))
+	ics := buf.String()
+	assert.NotContains(t, ics, "METHOD")
+	assert.NotContains(t, ics, "secret")
+	assert.Equal(t, 1, strings.Count(ics, "BEGIN:VEVENT\r\n"))
+
+	start, modified, err := ParseEvent(ics)
+	require.NoError(t, err)
+	assert.Equal(t, task.DueDate, start)
+	assert.Equal(t, task.UpdatedAt, modified)
+}
+
+func TestParseEvent(t *testing.T) {
+	start, _, err := ParseEvent("BEGIN:VCALENDAR\nBEGIN:VTIMEZONE\nDTSTART:19700101T000000\nEND:VTIMEZONE\n" +
+		"BEGIN:VEVENT\nDTSTART;TZID=\"Europe/Moscow\":2024\n 0601T090000\nEND:VEVENT\nEND:VCALENDAR\n")
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 6, 1, 6, 0, 0, 0, time.UTC), start)
+
+	start, modified, err := ParseEvent("BEGIN:VEVENT\r\nDTSTART;VALUE=DATE:20240601\r\nEND:VEVENT\r\n")
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC), start)
+	assert.True(t, modified.IsZero())
+
+	_, _, err = ParseEvent("BEGIN:VEVENT\r\nDTSTART;TZID=Mars/Olympus:20240601T090000\r\nEND:VEVENT\r\n")
+	assert.Error(t, err)
+	_, _, err = ParseEvent("BEGIN:VTODO\r\nDUE:20240601T090000Z\r\nEND:VTODO\r\n")
+	assert.Error(t, err)
+}
@@ -0,0 +1,101 @@
+package calendar
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ParseEvent начало и время последнего изменения первого VEVENT объекта календаря.
+// Начало без часового пояса и дата без времени считаются в UTC; время изменения нулевое,
+// если LAST-MODIFIED нет
+func ParseEvent(data string) (start, modified time.Time, err error) {
+	// продолжение строки начинается с пробела или табуляции после переноса
+	data = strings.NewReplacer("\r\n ", "", "\r\n\t", "", "\n ", "", "\n\t", "").Replace(data)
+
+	inEvent := false
+	for _, line := range strings.Split(data, "\n") {
+		line = strings.TrimRight(line, "\r")
+		name, params, value, ok := splitProperty(line)
+		if !ok {
+			continue
+		}
+
+		switch {
+		case name == "BEGIN" && strings.EqualFold(value, "VEVENT"):
+			inEvent = true
+		case !inEvent:
+		case name == "END" && strings.EqualFold(value, "VEVENT"):
+			if start.IsZero() {
+				return time.Time{}, time.Time{}, fmt.Errorf("event has no DTSTART")
+			}
+			return start, modified, nil
+		case name == "DTSTART":
+			if start, err = parseDateTime(value, params); err != nil {
+				return time.Time{}, time.Time{}, err
+			}
+		case name == "LAST-MODIFIED":
+			if modified, err = parseDateTime(value, nil); err != nil {
+				return time.Time{}, time.Time{}, err
+			}
+		}
+	}
+
+	return time.Time{}, time.Time{}, fmt.Errorf("calendar has no VEVENT")
+}
+
+// splitProperty разбирает строку содержимого NAME;PARAM=VALUE:value. Двоеточие внутри
+// значения параметра в кавычках не считается разделителем
+func splitProperty(line string) (name string, params map[string]string, value string, ok bool) {
+	quoted := false
+	colon := -1
+	for i, r := range line {
+		if r == '"' {
+			quoted = !quoted
+		} else if r == ':' && !quoted {
+			colon = i
+			break
+		}
+	}
+	if colon < 0 {
+		return "", nil, "", false
+	}
+
+	parts := strings.Split(line[:colon], ";")
+	name = strings.ToUpper(parts[0])
+	for _, param := range parts[1:] {
+		key, val, found := strings.Cut(param, "=")
+		if !found {
+			continue
+		}
+		if params == nil {
+			params = make(map[string]string)
+		}
+		params[strings.ToUpper(key)] = strings.Trim(val, `"`)
+	}
+
+	return name, params, line[colon+1:], true
+}
+
+// parseDateTime значение DATE или DATE-TIME: в UTC, в часовом поясе TZID или без пояса
+func parseDateTime(value string, params map[string]string) (time.Time, error) {
+	if params["VALUE"] == "DATE" || len(value) == len("20060102") {
+		return time.Parse("20060102", value)
+	}
+	if strings.HasSuffix(value, "Z") {
+		return time.Parse("20060102T150405Z", value)
+	}
+
+	loc := time.UTC
+	if tzid := params["TZID"]; tzid != "" {
+		var err error
+		if loc, err = time.LoadLocation(tzid); err != nil {
+			return time.Time{}, fmt.Errorf("unknown time zone %q", tzid)
+		}
+	}
+	t, err := time.ParseInLocation("20060102T150405", value, loc)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return t.UTC(), nil
+}
@@ -0,0 +1,28 @@
+package calendar
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+var (
+	// ErrEventNotFound события больше нет в календаре
+	ErrEventNotFound = errors.New("calendar event not found")
+	// ErrSyncTokenExpired календарь больше не выдает изменения с сохраненной позиции,
+	// синхронизацию нужно начать заново
+	ErrSyncTokenExpired = errors.New("calendar sync token expired")
+	// ErrUnauthorized календарь отклонил учетные данные подключения
+	ErrUnauthorized = errors.New("calendar rejected credentials")
+)
+
+// statusError ошибка по коду ответа календаря. Начало тела ответа попадает в текст ошибки,
+// потому что в нем календари объясняют причину отказа
+func statusError(resp *http.Response) error {
+	if resp.StatusCode == http.StatusUnauthorized {
+		return ErrUnauthorized
+	}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	return fmt.Errorf("calendar responded with status %d: %s", resp.StatusCode, body)
+}
@@ -0,0 +1,53 @@
+// Package clock источник текущего времени для сервисов, обработчиков, уведомлений и фонового воркера.
+// Сервисы получают время через Clock, а не time.Now, поэтому тесты подставляют Fake
+// и проверяют сроки по умолчанию, просрочку и аналитику на фиксированном моменте, в том числе на переходе часов
+package clock
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock источник текущего времени
+type Clock interface {
+	Now() time.Time
+}
+
+// System часы операционной системы
+var System Clock = systemClock{}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time { return time.Now() }
+
+// Fake часы для тестов: время стоит на месте, пока его не переведут Set или Advance
+type Fake struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+// NewFake создает часы, показывающие now
+func NewFake(now time.Time) *Fake {
+	return &Fake{now: now}
+}
+
+// Now текущее время часов
+func (f *Fake) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+// Set переводит часы на now
+func (f *Fake) Set(now time.Time) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.now = now
+}
+
+// Advance переводит часы вперед на d
+func (f *Fake) Advance(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.now = f.now.Add(d)
+}
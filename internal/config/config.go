@@ -1,22 +1,53 @@
 package config
 
 import (
+	"errors"
 	"fmt"
+	"net/url"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
 	"gopkg.in/yaml.v3"
 )
 
+// DefaultSigningKey ключ подписи JWT по умолчанию, годится только для разработки
+const DefaultSigningKey = "your-secret-key"
+
 // Config все параметры конфигурации приложения
 type Config struct {
-	Server   ServerConfig
-	Database DatabaseConfig
-	Redis    RedisConfig
-	Auth     AuthConfig
-	Logger   LoggerConfig
+	Server       ServerConfig
+	Database     DatabaseConfig
+	Redis        RedisConfig
+	Auth         AuthConfig
+	Logger       LoggerConfig
+	Crypto       CryptoConfig
+	Realtime     RealtimeConfig
+	Events       EventsConfig
+	Push         PushConfig
+	Calendar     CalendarConfig
+	SMTP         SMTPConfig
+	Telegram     TelegramConfig
+	Notification NotificationConfig
+	Analytics    AnalyticsConfig
+	Concurrency  ConcurrencyConfig
+	Shedding     SheddingConfig
+	Usage        UsageConfig
+	Quota        QuotaConfig
+	Recurrence   RecurrenceConfig
+	Archive      ArchiveConfig
+	ChangeFeed   ChangeFeedConfig
+	Hooks        HooksConfig
+	Idempotency  IdempotencyConfig
+	Import       ImportConfig
+	Integrations IntegrationsConfig
+	AI           AIConfig
+	Metrics      MetricsConfig
+	Tracing      TracingConfig
+	Features     FeaturesConfig
+	Redaction    RedactionConfig
 }
 
 // ServerConfig настройки HTTP-сервера
@@ -26,6 +57,38 @@ type ServerConfig struct {
 	ReadTimeout  time.Duration `yaml:"readTimeout"`
 	WriteTimeout time.Duration `yaml:"writeTimeout"`
 	IdleTimeout  time.Duration `yaml:"idleTimeout"`
+	// SPAEnabled отдавать встроенную сборку фронтенда из web/dist на маршруты вне API
+	SPAEnabled bool `yaml:"spaEnabled"`
+	// DefaultAPIVersion версия API для запросов без заголовка API-Version
+	DefaultAPIVersion int `yaml:"defaultApiVersion"`
+	// Listen адреса HTTP-сервера: host:port или unix:/path/to.sock. Пустой список — Host:Port
+	Listen []string `yaml:"listen"`
+	// SocketMode права на файлы Unix-сокетов в восьмеричной записи
+	SocketMode string `yaml:"socketMode"`
+	// TimingEnabled добавлять в ответы заголовок Server-Timing со временем запросов к базе, Redis и остальной обработки
+	TimingEnabled bool `yaml:"timingEnabled"`
+	// ReadinessTimeout время на проверку одной зависимости в /readyz, меньше таймаута пробы оркестратора
+	ReadinessTimeout time.Duration `yaml:"readinessTimeout"`
+}
+
+// UnixSocketPrefix префикс адреса Unix-сокета в SERVER_LISTEN
+const UnixSocketPrefix = "unix:"
+
+// Addresses адреса HTTP-сервера: Listen или Host:Port, если список пуст
+func (c ServerConfig) Addresses() []string {
+	if len(c.Listen) > 0 {
+		return c.Listen
+	}
+	return []string{fmt.Sprintf("%s:%d", c.Host, c.Port)}
+}
+
+// SocketFileMode права на файлы Unix-сокетов
+func (c ServerConfig) SocketFileMode() (os.FileMode, error) {
+	mode, err := strconv.ParseUint(c.SocketMode, 8, 32)
+	if err != nil || mode > 0o777 {
+		return 0, fmt.Errorf("invalid socket mode %q", c.SocketMode)
+	}
+	return os.FileMode(mode), nil
 }
 
 // DatabaseConfig настройки подключения к базе данных
@@ -36,19 +99,319 @@ type DatabaseConfig struct {
 	Password string `yaml:"password"`
 	DBName   string `yaml:"dbname"`
 	SSLMode  string `yaml:"sslmode"`
+	// MaxOpenConns размер пула соединений, по нему считается загрузка пула
+	MaxOpenConns int `yaml:"maxOpenConns"`
+	// MigrationsDir каталог миграций, по последней из них проверяется версия схемы при запуске
+	MigrationsDir string `yaml:"migrationsDir"`
+	// Storage хранилище задач и пользователей: StoragePostgres, StorageMemory или StorageSQLite
+	Storage string `yaml:"storage"`
+	// SQLitePath файл базы при STORAGE=sqlite, каталог должен существовать
+	SQLitePath string `yaml:"sqlitePath"`
 }
 
+// Хранилища задач и пользователей. В памяти данные живут до остановки приложения,
+// остальные подсистемы и в этом случае хранят данные в Postgres
+const (
+	StoragePostgres = "postgres"
+	StorageMemory   = "memory"
+	StorageSQLite   = "sqlite"
+)
+
 // RedisConfig настройки подключения к Redis
 type RedisConfig struct {
 	Host string `yaml:"host"`
 	Port string `yaml:"port"`
 	DB   int    `yaml:"db"`
+	// TaskCacheTTL время жизни задачи и списка задач в кэше чтения, 0 отключает кэш
+	TaskCacheTTL time.Duration `yaml:"taskCacheTTL"`
+	// ViewCacheTTL время жизни списков "Сегодня" и "Предстоящие" в кэше, 0 отключает кэш
+	ViewCacheTTL time.Duration `yaml:"viewCacheTTL"`
+	// UserStatusCacheTTL время жизни признака активности пользователя в кэше, 0 отключает кэш
+	UserStatusCacheTTL time.Duration `yaml:"userStatusCacheTTL"`
+	// BreakerThreshold после скольких ошибок Redis подряд кэш аналитики переходит на запасной, 0 — не переходит
+	BreakerThreshold int `yaml:"breakerThreshold"`
+	// BreakerCooldown через сколько после перехода на запасной кэш снова пробовать Redis
+	BreakerCooldown time.Duration `yaml:"breakerCooldown"`
 }
 
 // AuthConfig настройки аутентификации
 type AuthConfig struct {
 	SigningKey string        `yaml:"signingKey"`
 	TokenTTL   time.Duration `yaml:"tokenTTL"`
+	// AdminUserIDs пользователи, которым при запуске назначается роль admin. Прежний способ
+	// назначения администраторов, роль теперь хранится в БД
+	AdminUserIDs []string `yaml:"adminUserIds"`
+	// ImpersonationTTL время жизни токена имперсонации
+	ImpersonationTTL time.Duration `yaml:"impersonationTTL"`
+	// PasswordAlgorithm алгоритм новых хэшей паролей (bcrypt, argon2id); хэши другого алгоритма
+	// или стоимости пересчитываются при входе
+	PasswordAlgorithm string `yaml:"passwordAlgorithm"`
+	BcryptCost        int    `yaml:"bcryptCost"`
+	// Argon2Memory память Argon2id в KiB
+	Argon2Memory      int `yaml:"argon2Memory"`
+	Argon2Iterations  int `yaml:"argon2Iterations"`
+	Argon2Parallelism int `yaml:"argon2Parallelism"`
+	// PasswordMinLength минимальная длина пароля в символах
+	PasswordMinLength int `yaml:"passwordMinLength"`
+	// PasswordRequiredClasses классы символов, обязательные в пароле: lower, upper, digit, symbol
+	PasswordRequiredClasses []string `yaml:"passwordRequiredClasses"`
+	// PasswordBreachCheck проверять новые пароли по базе утечек Pwned Passwords
+	PasswordBreachCheck        bool          `yaml:"passwordBreachCheck"`
+	PasswordBreachCheckURL     string        `yaml:"passwordBreachCheckUrl"`
+	PasswordBreachCheckTimeout time.Duration `yaml:"passwordBreachCheckTimeout"`
+	// PasswordHistory сколько последних паролей нельзя использовать снова, 0 — без ограничения
+	PasswordHistory int `yaml:"passwordHistory"`
+}
+
+// CryptoConfig настройки шифрования приватных задач
+type CryptoConfig struct {
+	// MasterKey ключ шифрования ключей (KEK), из которого выводятся ключи пользователей
+	MasterKey string `yaml:"masterKey"`
+}
+
+// RealtimeConfig настройки доставки событий в реальном времени
+type RealtimeConfig struct {
+	// SendBuffer размер буфера событий одного подключения, при переполнении отбрасываются самые старые
+	SendBuffer int `yaml:"sendBuffer"`
+	// MaxConnectionsPerUser максимальное число одновременных подключений пользователя
+	MaxConnectionsPerUser int `yaml:"maxConnectionsPerUser"`
+	// ReplayWindow сколько хранятся последние события пользователя после отключения, чтобы
+	// переподключившийся клиент получил пропущенное; 0 — не хранятся
+	ReplayWindow time.Duration `yaml:"replayWindow"`
+}
+
+// EventsConfig настройки конвейера событий задач
+type EventsConfig struct {
+	// Backend memory — очередь внутри процесса, redis — поток Redis Streams, общий для всех экземпляров
+	Backend string `yaml:"backend"`
+	// Stream имя потока Redis
+	Stream string `yaml:"stream"`
+	// Group группа потребителей, через которую триггеры и уведомления получают событие один раз на все экземпляры
+	Group string `yaml:"group"`
+	// MaxLen примерная длина потока, более старые события вытесняются
+	MaxLen int `yaml:"maxLen"`
+	// ClaimIdle через сколько событие, не обработанное остановившимся экземпляром, забирает другой
+	ClaimIdle time.Duration `yaml:"claimIdle"`
+}
+
+// PushConfig настройки Web Push уведомлений
+type PushConfig struct {
+	// VAPIDPublicKey публичный ключ P-256 в base64url (несжатая точка, 65 байт)
+	VAPIDPublicKey string `yaml:"vapidPublicKey"`
+	// VAPIDPrivateKey приватный ключ P-256 в base64url (32 байта)
+	VAPIDPrivateKey string `yaml:"vapidPrivateKey"`
+	// Subject контакт отправителя для push-сервисов (mailto: или https:)
+	Subject string `yaml:"subject"`
+	// DueSoonWindow окно "скоро срок" по умолчанию, если пользователь не задал свое
+	DueSoonWindow time.Duration `yaml:"dueSoonWindow"`
+	// CheckInterval период проверки задач со скорым сроком
+	CheckInterval time.Duration `yaml:"checkInterval"`
+}
+
+// CalendarConfig синхронизация сроков задач с календарями. Календари Apple (CalDAV) доступны всегда,
+// Google — при заданных OAuth-клиенте GoogleClientID и GoogleClientSecret
+type CalendarConfig struct {
+	// GoogleAPIURL и GoogleTokenURL адреса Google Calendar API и выдачи токенов OAuth
+	GoogleAPIURL       string `yaml:"googleApiUrl"`
+	GoogleTokenURL     string `yaml:"googleTokenUrl"`
+	GoogleClientID     string `yaml:"googleClientId"`
+	GoogleClientSecret string `yaml:"googleClientSecret"`
+	// WebhookURL публичный адрес POST /api/integrations/calendars/google/webhook для
+	// уведомлений Google; пустой — календари Google только опрашиваются
+	WebhookURL string `yaml:"webhookUrl"`
+	// SyncInterval как часто опрашивается каждый подключенный календарь
+	SyncInterval time.Duration `yaml:"syncInterval"`
+}
+
+// SMTPConfig настройки отправки email, пустой Host отключает email
+type SMTPConfig struct {
+	Host     string `yaml:"host"`
+	Port     int    `yaml:"port"`
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+	// From адрес отправителя
+	From string `yaml:"from"`
+}
+
+// TelegramConfig настройки Telegram-бота, пустой BotToken отключает Telegram
+type TelegramConfig struct {
+	// BotToken токен бота от @BotFather
+	BotToken string `yaml:"botToken"`
+	// APIURL адрес Bot API
+	APIURL string `yaml:"apiUrl"`
+	// DigestCheckInterval период проверки, кому пора отправить ежедневную сводку
+	DigestCheckInterval time.Duration `yaml:"digestCheckInterval"`
+}
+
+// NotificationConfig общие настройки уведомлений
+type NotificationConfig struct {
+	// TemplatesDir каталог с переопределениями шаблонов уведомлений
+	TemplatesDir string `yaml:"templatesDir"`
+	// DigestWindow окно группировки уведомлений в дайджест по умолчанию, 0 — отправлять сразу
+	DigestWindow time.Duration `yaml:"digestWindow"`
+	// DigestFlushInterval период проверки дайджестов, готовых к отправке
+	DigestFlushInterval time.Duration `yaml:"digestFlushInterval"`
+}
+
+// AnalyticsConfig настройки исторической аналитики
+type AnalyticsConfig struct {
+	// SnapshotInterval период сохранения снимков аналитики, снимок за текущий день перезаписывается
+	SnapshotInterval time.Duration `yaml:"snapshotInterval"`
+}
+
+// ConcurrencyConfig ограничение одновременных запросов к тяжелым эндпоинтам (импорт, экспорт, аналитика).
+// Лимит действует отдельно для каждого эндпоинта
+type ConcurrencyConfig struct {
+	// Limit число одновременно обрабатываемых запросов, 0 отключает ограничение
+	Limit int `yaml:"limit"`
+	// QueueDepth сколько запросов может ждать свободного слота, остальные получают 503
+	QueueDepth int `yaml:"queueDepth"`
+	// QueueTimeout максимальное время ожидания в очереди, 0 — ждать, пока клиент не отключится
+	QueueTimeout time.Duration `yaml:"queueTimeout"`
+}
+
+// SheddingConfig адаптивный сброс нагрузки: при перегрузке низкоприоритетные запросы
+// (аналитика, экспорт) получают 503, а CRUD продолжает работать
+type SheddingConfig struct {
+	// LatencyThreshold порог p99 длительности запросов за последнюю минуту, 0 отключает проверку
+	LatencyThreshold time.Duration `yaml:"latencyThreshold"`
+	// PoolSaturation порог доли занятых соединений пула Postgres (0..1), 0 отключает проверку
+	PoolSaturation float64 `yaml:"poolSaturation"`
+}
+
+// UsageConfig учет запросов пользователей к API
+type UsageConfig struct {
+	// RollupInterval период переноса счетчиков запросов из Redis в Postgres
+	RollupInterval time.Duration `yaml:"rollupInterval"`
+}
+
+// QuotaConfig лимиты пользователя
+type QuotaConfig struct {
+	// MaxOpenTasks максимальное число открытых задач пользователя, 0 отключает лимит
+	MaxOpenTasks int `yaml:"maxOpenTasks"`
+	// WarnThreshold доля лимита (0..1), начиная с которой изменяющие запросы возвращают предупреждение, 0 отключает предупреждения
+	WarnThreshold float64 `yaml:"warnThreshold"`
+}
+
+// RecurrenceConfig повторяющиеся задачи
+type RecurrenceConfig struct {
+	// CheckInterval период поиска выполненных и просроченных экземпляров, для которых пора создать следующий
+	CheckInterval time.Duration `yaml:"checkInterval"`
+}
+
+// ArchiveConfig перенос давно выполненных задач в архивную таблицу
+type ArchiveConfig struct {
+	// AfterMonths через сколько месяцев после выполнения задача переносится в архив, 0 отключает перенос
+	AfterMonths int `yaml:"afterMonths"`
+	// Interval период запуска переноса
+	Interval time.Duration `yaml:"interval"`
+	// BatchSize сколько задач переносится одним запросом
+	BatchSize int `yaml:"batchSize"`
+}
+
+// ChangeFeedConfig журнал изменений задач для выгрузки в хранилища данных (CDC)
+type ChangeFeedConfig struct {
+	// Enabled записывать изменения задач в журнал
+	Enabled bool `yaml:"enabled"`
+	// Retention сколько хранятся записи журнала
+	Retention time.Duration `yaml:"retention"`
+	// CleanupInterval период удаления записей старше Retention
+	CleanupInterval time.Duration `yaml:"cleanupInterval"`
+}
+
+// HooksConfig входящие webhook
+type HooksConfig struct {
+	// RateLimit максимальное число запросов на один webhook за RateWindow, 0 отключает ограничение
+	RateLimit  int           `yaml:"rateLimit"`
+	RateWindow time.Duration `yaml:"rateWindow"`
+}
+
+// IdempotencyConfig повторы создающих запросов с заголовком Idempotency-Key
+type IdempotencyConfig struct {
+	// TTL сколько хранится ответ для повторов
+	TTL time.Duration `yaml:"ttl"`
+	// LockTimeout сколько ключ занят незавершенным запросом; должен превышать время обработки самого долгого запроса
+	LockTimeout time.Duration `yaml:"lockTimeout"`
+	// MaxBodyBytes наибольший размер тела запроса с ключом и сохраняемого ответа
+	MaxBodyBytes int64 `yaml:"maxBodyBytes"`
+}
+
+// ImportConfig ограничения импорта задач из файла
+type ImportConfig struct {
+	// MaxBytes наибольший размер тела запроса импорта и предпросмотра
+	MaxBytes int64 `yaml:"maxBytes"`
+	// MaxRows наибольшее число строк в файле
+	MaxRows int `yaml:"maxRows"`
+}
+
+// IntegrationsConfig внешние трекеры задач. GitHub доступен всегда (без токена — только
+// публичные репозитории), Jira — при заданном JiraBaseURL
+type IntegrationsConfig struct {
+	GitHubAPIURL string `yaml:"githubApiUrl"`
+	GitHubToken  string `yaml:"githubToken"`
+	JiraBaseURL  string `yaml:"jiraBaseUrl"`
+	JiraEmail    string `yaml:"jiraEmail"`
+	JiraAPIToken string `yaml:"jiraApiToken"`
+	// PollInterval как часто обновляется состояние каждой связанной внешней задачи
+	PollInterval time.Duration `yaml:"pollInterval"`
+}
+
+// AIConfig языковая модель AI-ассистента с OpenAI-совместимым API, пустой BaseURL отключает ассистента
+type AIConfig struct {
+	// BaseURL адрес API вместе с версией, например https://api.openai.com/v1
+	BaseURL string `yaml:"baseUrl"`
+	APIKey  string `yaml:"apiKey"`
+	Model   string `yaml:"model"`
+	// Timeout максимальное время одного запроса к модели, меньше WriteTimeout сервера
+	Timeout time.Duration `yaml:"timeout"`
+	// EmbeddingModel модель векторных представлений для поиска похожих задач, пустая отключает поиск
+	EmbeddingModel string `yaml:"embeddingModel"`
+	// SimilarityMinScore наименьшая косинусная близость (0..1), с которой задача считается похожей
+	SimilarityMinScore float64 `yaml:"similarityMinScore"`
+	// EmbeddingBackfillInterval период построения векторов задач, у которых их нет
+	EmbeddingBackfillInterval time.Duration `yaml:"embeddingBackfillInterval"`
+}
+
+// MetricsConfig разбиение бизнес-метрик по пользователям (метка tenant)
+type MetricsConfig struct {
+	// TenantMode off — без разбиения, hash — корзины по хэшу ID пользователя, allowlist — только пользователи из списка
+	TenantMode      string   `yaml:"tenantMode"`
+	TenantBuckets   int      `yaml:"tenantBuckets"`
+	TenantAllowlist []string `yaml:"tenantAllowlist"`
+}
+
+// FeaturesConfig группы маршрутов, отключенные в этой установке
+type FeaturesConfig struct {
+	// Disabled названия групп из validFeatures, их маршруты отвечают 404
+	Disabled []string `yaml:"disabled"`
+}
+
+// RedactionConfig профили редактирования данных, уходящих наружу: экспорт задач и административные ответы
+type RedactionConfig struct {
+	// Profiles именованные профили вида "name:rule+rule", правила — descriptions и emails
+	Profiles []string `yaml:"profiles"`
+	// ExportProfile профиль, который всегда применяется к экспорту задач, пустой — без редактирования
+	ExportProfile string `yaml:"exportProfile"`
+	// AdminProfile профиль, который всегда применяется к спискам пользователей в админке
+	AdminProfile string `yaml:"adminProfile"`
+}
+
+// Enabled включена ли группа маршрутов
+func (f FeaturesConfig) Enabled(feature string) bool {
+	for _, disabled := range f.Disabled {
+		if disabled == feature {
+			return false
+		}
+	}
+	return true
+}
+
+// TracingConfig трассировка OpenTelemetry
+type TracingConfig struct {
+	// Endpoint адрес OTLP/HTTP коллектора, например http://otel-collector:4318; пустой отключает трассировку
+	Endpoint string `yaml:"endpoint"`
+	// SampleRatio доля трассируемых новых запросов от 0 до 1; запросы с traceparent следуют решению вызывающего
+	SampleRatio float64 `yaml:"sampleRatio"`
 }
 
 // LoggerConfig настройки логирования
@@ -87,23 +450,56 @@ func Load() (*Config, error) {
 			ReadTimeout:  getDurationEnv("SERVER_READ_TIMEOUT", 10*time.Second),
 			WriteTimeout: getDurationEnv("SERVER_WRITE_TIMEOUT", 10*time.Second),
 			IdleTimeout:  getDurationEnv("SERVER_IDLE_TIMEOUT", 10*time.Second),
+			SPAEnabled:   getBoolEnv("SPA_ENABLED", false),
+			// первая версия по умолчанию, пока клиенты не перешли на вторую
+			DefaultAPIVersion: getIntEnv("API_DEFAULT_VERSION", 1),
+			TimingEnabled:     getBoolEnv("SERVER_TIMING_ENABLED", false),
+			Listen:            getListEnv("SERVER_LISTEN"),
+			// чтение и запись владельцу и группе, в которой обычно работает обратный прокси
+			SocketMode:       getEnv("SERVER_SOCKET_MODE", "0660"),
+			ReadinessTimeout: getDurationEnv("READINESS_TIMEOUT", 2*time.Second),
 		},
 		Database: DatabaseConfig{
-			Host:     getEnv("DB_HOST", "localhost"),
-			Port:     getEnv("DB_PORT", "5432"),
-			User:     getEnv("DB_USER", "postgres"),
-			Password: getEnv("DB_PASSWORD", "postgres"),
-			DBName:   getEnv("DB_NAME", "taskmanager"),
-			SSLMode:  getEnv("DB_SSLMODE", "disable"),
+			Host:          getEnv("DB_HOST", "localhost"),
+			Port:          getEnv("DB_PORT", "5432"),
+			User:          getEnv("DB_USER", "postgres"),
+			Password:      getEnv("DB_PASSWORD", "postgres"),
+			DBName:        getEnv("DB_NAME", "taskmanager"),
+			SSLMode:       getEnv("DB_SSLMODE", "disable"),
+			MaxOpenConns:  getIntEnv("DB_MAX_OPEN_CONNS", 25),
+			MigrationsDir: getEnv("DB_MIGRATIONS_DIR", "migrations"),
+			Storage:       getEnv("STORAGE", StoragePostgres),
+			SQLitePath:    getEnv("SQLITE_PATH", "data/tasks.db"),
 		},
 		Redis: RedisConfig{
-			Host: getEnv("REDIS_HOST", "localhost"),
-			Port: getEnv("REDIS_PORT", "6379"),
-			DB:   getIntEnv("REDIS_DB", 0),
+			Host:         getEnv("REDIS_HOST", "localhost"),
+			Port:         getEnv("REDIS_PORT", "6379"),
+			DB:           getIntEnv("REDIS_DB", 0),
+			TaskCacheTTL: getDurationEnv("TASK_CACHE_TTL", 30*time.Second),
+			ViewCacheTTL: getDurationEnv("TASK_VIEW_CACHE_TTL", 30*time.Second),
+
+			UserStatusCacheTTL: getDurationEnv("USER_STATUS_CACHE_TTL", time.Minute),
+			BreakerThreshold:   getIntEnv("REDIS_BREAKER_THRESHOLD", 5),
+			BreakerCooldown:    getDurationEnv("REDIS_BREAKER_COOLDOWN", 30*time.Second),
 		},
 		Auth: AuthConfig{
-			SigningKey: getEnv("JWT_SECRET", "your-secret-key"),
-			TokenTTL:   getDurationEnv("JWT_EXPIRES", 24*time.Hour),
+			SigningKey:       getEnv("JWT_SECRET", DefaultSigningKey),
+			TokenTTL:         getDurationEnv("JWT_EXPIRES", 24*time.Hour),
+			AdminUserIDs:     getListEnv("ADMIN_USER_IDS"),
+			ImpersonationTTL: getDurationEnv("IMPERSONATION_TTL", 30*time.Minute),
+			// параметры Argon2id по умолчанию — рекомендация OWASP
+			PasswordAlgorithm: getEnv("PASSWORD_HASH_ALGORITHM", "bcrypt"),
+			BcryptCost:        getIntEnv("BCRYPT_COST", 10),
+			Argon2Memory:      getIntEnv("ARGON2_MEMORY_KB", 19456),
+			Argon2Iterations:  getIntEnv("ARGON2_ITERATIONS", 2),
+			Argon2Parallelism: getIntEnv("ARGON2_PARALLELISM", 1),
+
+			PasswordMinLength:          getIntEnv("PASSWORD_MIN_LENGTH", 6),
+			PasswordRequiredClasses:    getListEnv("PASSWORD_REQUIRED_CLASSES"),
+			PasswordBreachCheck:        getBoolEnv("PASSWORD_BREACH_CHECK", false),
+			PasswordBreachCheckURL:     getEnv("PASSWORD_BREACH_CHECK_URL", "https://api.pwnedpasswords.com"),
+			PasswordBreachCheckTimeout: getDurationEnv("PASSWORD_BREACH_CHECK_TIMEOUT", 3*time.Second),
+			PasswordHistory:            getIntEnv("PASSWORD_HISTORY", 0),
 		},
 		Logger: LoggerConfig{
 			Level:       getEnv("LOG_LEVEL", "info"),
@@ -112,9 +508,257 @@ func Load() (*Config, error) {
 			ServiceName: getEnv("SERVICE_NAME", "task-manager"),
 			Environment: getEnv("ENVIRONMENT", "development"),
 		},
+		Crypto: CryptoConfig{
+			MasterKey: getEnv("TASK_ENCRYPTION_KEY", ""),
+		},
+		Realtime: RealtimeConfig{
+			SendBuffer:            getIntEnv("REALTIME_SEND_BUFFER", 64),
+			MaxConnectionsPerUser: getIntEnv("REALTIME_MAX_CONNECTIONS_PER_USER", 5),
+			ReplayWindow:          getDurationEnv("REALTIME_REPLAY_WINDOW", 2*time.Minute),
+		},
+		Events: EventsConfig{
+			Backend:   getEnv("EVENTS_BACKEND", "memory"),
+			Stream:    getEnv("EVENTS_STREAM", "taskmanager:events"),
+			Group:     getEnv("EVENTS_GROUP", "taskmanager"),
+			MaxLen:    getIntEnv("EVENTS_STREAM_MAX_LEN", 100000),
+			ClaimIdle: getDurationEnv("EVENTS_CLAIM_IDLE", time.Minute),
+		},
+		Push: PushConfig{
+			VAPIDPublicKey:  getEnv("VAPID_PUBLIC_KEY", ""),
+			VAPIDPrivateKey: getEnv("VAPID_PRIVATE_KEY", ""),
+			Subject:         getEnv("VAPID_SUBJECT", "mailto:support@example.com"),
+			DueSoonWindow:   getDurationEnv("PUSH_DUE_SOON_WINDOW", time.Hour),
+			CheckInterval:   getDurationEnv("PUSH_CHECK_INTERVAL", 5*time.Minute),
+		},
+		Calendar: CalendarConfig{
+			GoogleAPIURL:       getEnv("GOOGLE_CALENDAR_API_URL", "https://www.googleapis.com/calendar/v3"),
+			GoogleTokenURL:     getEnv("GOOGLE_CALENDAR_TOKEN_URL", "https://oauth2.googleapis.com/token"),
+			GoogleClientID:     getEnv("GOOGLE_CALENDAR_CLIENT_ID", ""),
+			GoogleClientSecret: getEnv("GOOGLE_CALENDAR_CLIENT_SECRET", ""),
+			WebhookURL:         getEnv("CALENDAR_WEBHOOK_URL", ""),
+			SyncInterval:       getDurationEnv("CALENDAR_SYNC_INTERVAL", 5*time.Minute),
+		},
+		SMTP: SMTPConfig{
+			Host:     getEnv("SMTP_HOST", ""),
+			Port:     getIntEnv("SMTP_PORT", 587),
+			Username: getEnv("SMTP_USERNAME", ""),
+			Password: getEnv("SMTP_PASSWORD", ""),
+			From:     getEnv("SMTP_FROM", "noreply@example.com"),
+		},
+		Telegram: TelegramConfig{
+			BotToken:            getEnv("TELEGRAM_BOT_TOKEN", ""),
+			APIURL:              getEnv("TELEGRAM_API_URL", "https://api.telegram.org"),
+			DigestCheckInterval: getDurationEnv("TELEGRAM_DIGEST_CHECK_INTERVAL", 5*time.Minute),
+		},
+		Notification: NotificationConfig{
+			TemplatesDir:        getEnv("NOTIFICATION_TEMPLATES_DIR", ""),
+			DigestWindow:        getDurationEnv("NOTIFICATION_DIGEST_WINDOW", 15*time.Minute),
+			DigestFlushInterval: getDurationEnv("NOTIFICATION_DIGEST_FLUSH_INTERVAL", time.Minute),
+		},
+		Analytics: AnalyticsConfig{
+			SnapshotInterval: getDurationEnv("ANALYTICS_SNAPSHOT_INTERVAL", time.Hour),
+		},
+		Concurrency: ConcurrencyConfig{
+			Limit:        getIntEnv("CONCURRENCY_LIMIT", 4),
+			QueueDepth:   getIntEnv("CONCURRENCY_QUEUE_DEPTH", 16),
+			QueueTimeout: getDurationEnv("CONCURRENCY_QUEUE_TIMEOUT", 5*time.Second),
+		},
+		Shedding: SheddingConfig{
+			LatencyThreshold: getDurationEnv("SHEDDING_P99_THRESHOLD", 2*time.Second),
+			PoolSaturation:   getFloatEnv("SHEDDING_DB_POOL_SATURATION", 0.9),
+		},
+		Usage: UsageConfig{
+			RollupInterval: getDurationEnv("USAGE_ROLLUP_INTERVAL", 10*time.Minute),
+		},
+		Quota: QuotaConfig{
+			MaxOpenTasks:  getIntEnv("QUOTA_MAX_OPEN_TASKS", 1000),
+			WarnThreshold: getFloatEnv("QUOTA_WARN_THRESHOLD", 0.9),
+		},
+		Recurrence: RecurrenceConfig{
+			CheckInterval: getDurationEnv("RECURRENCE_CHECK_INTERVAL", time.Minute),
+		},
+		Archive: ArchiveConfig{
+			AfterMonths: getIntEnv("TASK_ARCHIVE_AFTER_MONTHS", 0),
+			Interval:    getDurationEnv("TASK_ARCHIVE_INTERVAL", time.Hour),
+			BatchSize:   getIntEnv("TASK_ARCHIVE_BATCH_SIZE", 1000),
+		},
+		ChangeFeed: ChangeFeedConfig{
+			Enabled:         getBoolEnv("CDC_ENABLED", false),
+			Retention:       getDurationEnv("CDC_RETENTION", 7*24*time.Hour),
+			CleanupInterval: getDurationEnv("CDC_CLEANUP_INTERVAL", time.Hour),
+		},
+		Hooks: HooksConfig{
+			RateLimit:  getIntEnv("HOOK_RATE_LIMIT", 60),
+			RateWindow: getDurationEnv("HOOK_RATE_WINDOW", time.Minute),
+		},
+		Idempotency: IdempotencyConfig{
+			TTL:          getDurationEnv("IDEMPOTENCY_TTL", 24*time.Hour),
+			LockTimeout:  getDurationEnv("IDEMPOTENCY_LOCK_TIMEOUT", 5*time.Minute),
+			MaxBodyBytes: int64(getIntEnv("IDEMPOTENCY_MAX_BODY_BYTES", 32<<20)),
+		},
+		Import: ImportConfig{
+			MaxBytes: int64(getIntEnv("IMPORT_MAX_BYTES", 10<<20)),
+			MaxRows:  getIntEnv("IMPORT_MAX_ROWS", 10000),
+		},
+		Integrations: IntegrationsConfig{
+			GitHubAPIURL: getEnv("GITHUB_API_URL", "https://api.github.com"),
+			GitHubToken:  getEnv("GITHUB_TOKEN", ""),
+			JiraBaseURL:  getEnv("JIRA_BASE_URL", ""),
+			JiraEmail:    getEnv("JIRA_EMAIL", ""),
+			JiraAPIToken: getEnv("JIRA_API_TOKEN", ""),
+			PollInterval: getDurationEnv("INTEGRATION_POLL_INTERVAL", 5*time.Minute),
+		},
+		AI: AIConfig{
+			BaseURL:                   getEnv("AI_BASE_URL", ""),
+			APIKey:                    getEnv("AI_API_KEY", ""),
+			Model:                     getEnv("AI_MODEL", "gpt-4o-mini"),
+			Timeout:                   getDurationEnv("AI_TIMEOUT", 8*time.Second),
+			EmbeddingModel:            getEnv("AI_EMBEDDING_MODEL", "text-embedding-3-small"),
+			SimilarityMinScore:        getFloatEnv("AI_SIMILARITY_MIN_SCORE", 0.75),
+			EmbeddingBackfillInterval: getDurationEnv("AI_EMBEDDING_BACKFILL_INTERVAL", 5*time.Minute),
+		},
+		Metrics: MetricsConfig{
+			TenantMode:      getEnv("METRICS_TENANT_MODE", "off"),
+			TenantBuckets:   getIntEnv("METRICS_TENANT_BUCKETS", 16),
+			TenantAllowlist: getListEnv("METRICS_TENANT_ALLOWLIST"),
+		},
+		Tracing: TracingConfig{
+			Endpoint:    getEnv("OTEL_EXPORTER_OTLP_ENDPOINT", ""),
+			SampleRatio: getFloatEnv("OTEL_TRACES_SAMPLE_RATIO", 1),
+		},
+		Features: FeaturesConfig{
+			Disabled: getListEnv("DISABLED_FEATURES"),
+		},
+		Redaction: RedactionConfig{
+			Profiles:      getListEnv("REDACTION_PROFILES"),
+			ExportProfile: getEnv("EXPORT_REDACTION_PROFILE", ""),
+			AdminProfile:  getEnv("ADMIN_REDACTION_PROFILE", ""),
+		},
 	}, nil
 }
 
+// Validate проверяет значения конфигурации и возвращает все найденные ошибки
+func (c *Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(c.Server.Port > 0 && c.Server.Port < 65536, "SERVER_PORT %d is out of range", c.Server.Port)
+	for _, addr := range c.Server.Listen {
+		check(addr != UnixSocketPrefix, "SERVER_LISTEN: unix socket path is empty")
+	}
+	_, err := c.Server.SocketFileMode()
+	check(err == nil, "SERVER_SOCKET_MODE %q must be an octal file mode such as 0660", c.Server.SocketMode)
+	check(c.Server.DefaultAPIVersion >= 1 && c.Server.DefaultAPIVersion <= 4, "API_DEFAULT_VERSION must be between 1 and 4")
+	check(c.Database.Host != "", "DB_HOST is empty")
+	check(c.Database.DBName != "", "DB_NAME is empty")
+	check(c.Database.MaxOpenConns >= 0, "DB_MAX_OPEN_CONNS must not be negative")
+	check(c.Database.Storage == StoragePostgres || c.Database.Storage == StorageMemory || c.Database.Storage == StorageSQLite,
+		"STORAGE %q is unknown", c.Database.Storage)
+	check(c.Database.Storage != StorageSQLite || c.Database.SQLitePath != "", "SQLITE_PATH is required when STORAGE is sqlite")
+	check(c.Redis.Host != "", "REDIS_HOST is empty")
+	check(c.Redis.TaskCacheTTL >= 0, "TASK_CACHE_TTL must not be negative")
+	check(c.Redis.UserStatusCacheTTL >= 0, "USER_STATUS_CACHE_TTL must not be negative")
+	check(c.Redis.BreakerThreshold >= 0, "REDIS_BREAKER_THRESHOLD must not be negative")
+	if c.Redis.BreakerThreshold > 0 {
+		check(c.Redis.BreakerCooldown > 0, "REDIS_BREAKER_COOLDOWN must be positive")
+	}
+	check(c.Redis.ViewCacheTTL >= 0, "TASK_VIEW_CACHE_TTL must not be negative")
+	check(c.Auth.SigningKey != "", "JWT_SECRET is empty")
+	check(c.Auth.TokenTTL > 0, "JWT_EXPIRES must be positive")
+	check(c.Auth.ImpersonationTTL > 0, "IMPERSONATION_TTL must be positive")
+	check(c.Auth.PasswordAlgorithm == "bcrypt" || c.Auth.PasswordAlgorithm == "argon2id", "PASSWORD_HASH_ALGORITHM %q is unknown", c.Auth.PasswordAlgorithm)
+	check(c.Auth.BcryptCost >= 4 && c.Auth.BcryptCost <= 31, "BCRYPT_COST must be between 4 and 31")
+	check(c.Auth.Argon2Memory >= 8*c.Auth.Argon2Parallelism, "ARGON2_MEMORY_KB must be at least 8 KiB per thread")
+	check(c.Auth.Argon2Iterations >= 1, "ARGON2_ITERATIONS must be positive")
+	check(c.Auth.Argon2Parallelism >= 1 && c.Auth.Argon2Parallelism <= 255, "ARGON2_PARALLELISM must be between 1 and 255")
+	check(c.Auth.PasswordMinLength >= 1 && c.Auth.PasswordMinLength <= 72, "PASSWORD_MIN_LENGTH must be between 1 and 72")
+	for _, class := range c.Auth.PasswordRequiredClasses {
+		check(class == "lower" || class == "upper" || class == "digit" || class == "symbol", "PASSWORD_REQUIRED_CLASSES: unknown class %q", class)
+	}
+	check(!c.Auth.PasswordBreachCheck || c.Auth.PasswordBreachCheckURL != "", "PASSWORD_BREACH_CHECK_URL is required when PASSWORD_BREACH_CHECK is enabled")
+	check(c.Auth.PasswordBreachCheckTimeout > 0, "PASSWORD_BREACH_CHECK_TIMEOUT must be positive")
+	check(c.Auth.PasswordHistory >= 0, "PASSWORD_HISTORY must not be negative")
+	check(validLogLevels[c.Logger.Level], "LOG_LEVEL %q is unknown", c.Logger.Level)
+	check(c.Logger.Format == "text" || c.Logger.Format == "json", "LOG_FORMAT %q is unknown", c.Logger.Format)
+	// интервалы фоновых задач: нулевой период ticker не допускает
+	check(c.Realtime.ReplayWindow >= 0, "REALTIME_REPLAY_WINDOW must not be negative")
+	check(c.Events.Backend == "memory" || c.Events.Backend == "redis", "EVENTS_BACKEND %q is unknown", c.Events.Backend)
+	if c.Events.Backend == "redis" {
+		check(c.Events.Stream != "" && c.Events.Group != "", "EVENTS_STREAM and EVENTS_GROUP are required when EVENTS_BACKEND is redis")
+		check(c.Events.MaxLen > 0, "EVENTS_STREAM_MAX_LEN must be positive")
+		check(c.Events.ClaimIdle > 0, "EVENTS_CLAIM_IDLE must be positive")
+	}
+	check(c.Push.CheckInterval > 0, "PUSH_CHECK_INTERVAL must be positive")
+	check(c.Calendar.SyncInterval > 0, "CALENDAR_SYNC_INTERVAL must be positive")
+	if c.Telegram.BotToken != "" {
+		check(c.Telegram.APIURL != "", "TELEGRAM_API_URL is required when TELEGRAM_BOT_TOKEN is set")
+		check(c.Telegram.DigestCheckInterval > 0, "TELEGRAM_DIGEST_CHECK_INTERVAL must be positive")
+	}
+	check(c.Notification.DigestWindow >= 0, "NOTIFICATION_DIGEST_WINDOW must not be negative")
+	check(c.Notification.DigestFlushInterval > 0, "NOTIFICATION_DIGEST_FLUSH_INTERVAL must be positive")
+	check(c.Analytics.SnapshotInterval > 0, "ANALYTICS_SNAPSHOT_INTERVAL must be positive")
+	check(c.Usage.RollupInterval > 0, "USAGE_ROLLUP_INTERVAL must be positive")
+	check(c.Quota.MaxOpenTasks >= 0, "QUOTA_MAX_OPEN_TASKS must not be negative")
+	check(c.Quota.WarnThreshold >= 0 && c.Quota.WarnThreshold <= 1, "QUOTA_WARN_THRESHOLD must be between 0 and 1")
+	check(c.Recurrence.CheckInterval > 0, "RECURRENCE_CHECK_INTERVAL must be positive")
+	check(c.Archive.AfterMonths >= 0, "TASK_ARCHIVE_AFTER_MONTHS must not be negative")
+	if c.Archive.AfterMonths > 0 {
+		check(c.Archive.Interval > 0, "TASK_ARCHIVE_INTERVAL must be positive")
+		check(c.Archive.BatchSize > 0, "TASK_ARCHIVE_BATCH_SIZE must be positive")
+	}
+	if c.ChangeFeed.Enabled {
+		check(c.ChangeFeed.Retention > 0, "CDC_RETENTION must be positive")
+		check(c.ChangeFeed.CleanupInterval > 0, "CDC_CLEANUP_INTERVAL must be positive")
+	}
+	check(c.Hooks.RateLimit >= 0, "HOOK_RATE_LIMIT must not be negative")
+	check(c.Hooks.RateWindow > 0, "HOOK_RATE_WINDOW must be positive")
+	check(c.Server.ReadinessTimeout > 0, "READINESS_TIMEOUT must be positive")
+	check(c.Idempotency.TTL > 0, "IDEMPOTENCY_TTL must be positive")
+	check(c.Idempotency.LockTimeout > 0, "IDEMPOTENCY_LOCK_TIMEOUT must be positive")
+	check(c.Idempotency.MaxBodyBytes > 0, "IDEMPOTENCY_MAX_BODY_BYTES must be positive")
+	check(c.Import.MaxBytes > 0, "IMPORT_MAX_BYTES must be positive")
+	check(c.Import.MaxRows > 0, "IMPORT_MAX_ROWS must be positive")
+	check(c.Integrations.PollInterval > 0, "INTEGRATION_POLL_INTERVAL must be positive")
+	check(c.AI.BaseURL == "" || c.AI.Model != "", "AI_MODEL is required when AI_BASE_URL is set")
+	// ответ модели должен успеть уйти клиенту до SERVER_WRITE_TIMEOUT
+	check(c.AI.Timeout > 0 && (c.Server.WriteTimeout <= 0 || c.AI.Timeout < c.Server.WriteTimeout),
+		"AI_TIMEOUT must be positive and less than SERVER_WRITE_TIMEOUT")
+	check(c.AI.SimilarityMinScore >= 0 && c.AI.SimilarityMinScore <= 1, "AI_SIMILARITY_MIN_SCORE must be between 0 and 1")
+	check(c.AI.EmbeddingBackfillInterval > 0, "AI_EMBEDDING_BACKFILL_INTERVAL must be positive")
+	check(validTenantModes[c.Metrics.TenantMode], "METRICS_TENANT_MODE %q is unknown", c.Metrics.TenantMode)
+	check(c.Metrics.TenantBuckets >= 1 && c.Metrics.TenantBuckets <= 256, "METRICS_TENANT_BUCKETS must be between 1 and 256")
+	if c.Tracing.Endpoint != "" {
+		endpoint, err := url.Parse(c.Tracing.Endpoint)
+		check(err == nil && (endpoint.Scheme == "http" || endpoint.Scheme == "https") && endpoint.Host != "",
+			"OTEL_EXPORTER_OTLP_ENDPOINT %q must be an http(s) URL", c.Tracing.Endpoint)
+	}
+	check(c.Tracing.SampleRatio >= 0 && c.Tracing.SampleRatio <= 1, "OTEL_TRACES_SAMPLE_RATIO must be between 0 and 1")
+	for _, feature := range c.Features.Disabled {
+		check(validFeatures[feature], "DISABLED_FEATURES: unknown feature %q", feature)
+	}
+	check(c.Concurrency.Limit >= 0, "CONCURRENCY_LIMIT must not be negative")
+	check(c.Concurrency.QueueDepth >= 0, "CONCURRENCY_QUEUE_DEPTH must not be negative")
+	check(c.Concurrency.QueueTimeout >= 0, "CONCURRENCY_QUEUE_TIMEOUT must not be negative")
+	check(c.Shedding.LatencyThreshold >= 0, "SHEDDING_P99_THRESHOLD must not be negative")
+	check(c.Shedding.PoolSaturation >= 0 && c.Shedding.PoolSaturation <= 1, "SHEDDING_DB_POOL_SATURATION must be between 0 and 1")
+
+	return errors.Join(errs...)
+}
+
+var validLogLevels = map[string]bool{"debug": true, "info": true, "warn": true, "error": true}
+
+var validTenantModes = map[string]bool{"off": true, "hash": true, "allowlist": true}
+
+var validFeatures = map[string]bool{
+	"import": true, "export": true, "analytics": true, "notifications": true, "triggers": true,
+	"tagging": true, "ai": true, "similarity": true, "hooks": true, "integrations": true, "projects": true,
+	"searches": true, "quickadd": true, "recurrences": true, "calendar": true, "events": true, "admin": true, "swagger": true,
+}
+
 // ConnectionString возвращает строку подключения к PostgreSQL
 func (c *DatabaseConfig) ConnectionString() string {
 	return fmt.Sprintf(
@@ -132,6 +776,17 @@ func getEnv(key, defaultValue string) string {
 	return value
 }
 
+// getListEnv возвращает значение переменной окружения как список через запятую
+func getListEnv(key string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
 // getIntEnv возвращает значение переменной окружения как int
 func getIntEnv(key string, defaultValue int) int {
 	valueStr := os.Getenv(key)
@@ -145,6 +800,19 @@ func getIntEnv(key string, defaultValue int) int {
 	return value
 }
 
+// getBoolEnv возвращает значение переменной окружения как bool
+func getBoolEnv(key string, defaultValue bool) bool {
+	valueStr := os.Getenv(key)
+	if valueStr == "" {
+		return defaultValue
+	}
+	value, err := strconv.ParseBool(valueStr)
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
 // getDurationEnv возвращает значение переменной окружения как time.Duration
 func getDurationEnv(key string, defaultValue time.Duration) time.Duration {
 	valueStr := os.Getenv(key)
@@ -157,3 +825,16 @@ func getDurationEnv(key string, defaultValue time.Duration) time.Duration {
 	}
 	return value
 }
+
+// getFloatEnv возвращает значение переменной окружения как float64
+func getFloatEnv(key string, defaultValue float64) float64 {
+	valueStr := os.Getenv(key)
+	if valueStr == "" {
+		return defaultValue
+	}
+	value, err := strconv.ParseFloat(valueStr, 64)
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
@@ -0,0 +1,157 @@
+package crypto
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jmoloko/taskmange/internal/metrics"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// PasswordAlgorithm алгоритм хэширования паролей
+type PasswordAlgorithm string
+
+const (
+	PasswordBcrypt   PasswordAlgorithm = "bcrypt"
+	PasswordArgon2id PasswordAlgorithm = "argon2id"
+)
+
+const (
+	argon2SaltSize = 16
+	argon2KeySize  = 32
+)
+
+var (
+	// ErrInvalidPasswordHasher возвращается при неизвестном алгоритме или параметрах вне допустимых границ
+	ErrInvalidPasswordHasher = errors.New("invalid password hashing settings")
+	// ErrUnknownPasswordHash хэш в базе не относится ни к одному из поддерживаемых алгоритмов
+	ErrUnknownPasswordHash = errors.New("unknown password hash format")
+)
+
+// Argon2Params параметры Argon2id: память в KiB, число проходов и потоков
+type Argon2Params struct {
+	Memory      uint32
+	Iterations  uint32
+	Parallelism uint8
+}
+
+// PasswordHasher хэширует пароли выбранным алгоритмом и проверяет хэши обоих алгоритмов,
+// чтобы смена алгоритма или стоимости не ломала вход пользователей со старыми хэшами
+type PasswordHasher struct {
+	algorithm  PasswordAlgorithm
+	bcryptCost int
+	argon2     Argon2Params
+}
+
+// NewPasswordHasher создает новый экземпляр PasswordHasher
+func NewPasswordHasher(algorithm PasswordAlgorithm, bcryptCost int, argon2Params Argon2Params) (*PasswordHasher, error) {
+	switch algorithm {
+	case PasswordBcrypt:
+		if bcryptCost < bcrypt.MinCost || bcryptCost > bcrypt.MaxCost {
+			return nil, fmt.Errorf("%w: bcrypt cost must be between %d and %d", ErrInvalidPasswordHasher, bcrypt.MinCost, bcrypt.MaxCost)
+		}
+	case PasswordArgon2id:
+		if argon2Params.Memory < 8*uint32(argon2Params.Parallelism) || argon2Params.Iterations < 1 || argon2Params.Parallelism < 1 {
+			return nil, fmt.Errorf("%w: argon2id needs at least 1 iteration, 1 thread and 8 KiB of memory per thread", ErrInvalidPasswordHasher)
+		}
+	default:
+		return nil, fmt.Errorf("%w: unknown algorithm %q", ErrInvalidPasswordHasher, algorithm)
+	}
+
+	return &PasswordHasher{algorithm: algorithm, bcryptCost: bcryptCost, argon2: argon2Params}, nil
+}
+
+// Hash хэширует пароль текущим алгоритмом
+func (h *PasswordHasher) Hash(password string) (string, error) {
+	defer observePasswordHash(h.algorithm, "hash", time.Now())
+
+	if h.algorithm == PasswordBcrypt {
+		hash, err := bcrypt.GenerateFromPassword([]byte(password), h.bcryptCost)
+		if err != nil {
+			return "", fmt.Errorf("failed to hash password: %w", err)
+		}
+		return string(hash), nil
+	}
+
+	salt := make([]byte, argon2SaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("failed to generate salt: %w", err)
+	}
+	key := argon2.IDKey([]byte(password), salt, h.argon2.Iterations, h.argon2.Memory, h.argon2.Parallelism, argon2KeySize)
+
+	// формат PHC, как у эталонной реализации argon2
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s", argon2.Version,
+		h.argon2.Memory, h.argon2.Iterations, h.argon2.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+// Verify проверяет пароль по хэшу любого поддерживаемого алгоритма. rehash — хэш сделан
+// другим алгоритмом или с другими параметрами и после успешного входа его стоит пересчитать
+func (h *PasswordHasher) Verify(hash, password string) (ok bool, rehash bool, err error) {
+	if strings.HasPrefix(hash, "$argon2id$") {
+		defer observePasswordHash(PasswordArgon2id, "verify", time.Now())
+
+		params, salt, key, err := parseArgon2Hash(hash)
+		if err != nil {
+			return false, false, err
+		}
+		actual := argon2.IDKey([]byte(password), salt, params.Iterations, params.Memory, params.Parallelism, uint32(len(key)))
+		if subtle.ConstantTimeCompare(actual, key) != 1 {
+			return false, false, nil
+		}
+		return true, h.algorithm != PasswordArgon2id || params != h.argon2, nil
+	}
+
+	defer observePasswordHash(PasswordBcrypt, "verify", time.Now())
+
+	cost, err := bcrypt.Cost([]byte(hash))
+	if err != nil {
+		return false, false, ErrUnknownPasswordHash
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)); err != nil {
+		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+			return false, false, nil
+		}
+		return false, false, err
+	}
+	return true, h.algorithm != PasswordBcrypt || cost != h.bcryptCost, nil
+}
+
+// parseArgon2Hash разбирает хэш вида $argon2id$v=19$m=65536,t=3,p=2$<salt>$<key>
+func parseArgon2Hash(hash string) (Argon2Params, []byte, []byte, error) {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 {
+		return Argon2Params{}, nil, nil, ErrUnknownPasswordHash
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return Argon2Params{}, nil, nil, ErrUnknownPasswordHash
+	}
+
+	var params Argon2Params
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Iterations, &params.Parallelism); err != nil {
+		return Argon2Params{}, nil, nil, ErrUnknownPasswordHash
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return Argon2Params{}, nil, nil, ErrUnknownPasswordHash
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return Argon2Params{}, nil, nil, ErrUnknownPasswordHash
+	}
+
+	return params, salt, key, nil
+}
+
+func observePasswordHash(algorithm PasswordAlgorithm, operation string, started time.Time) {
+	metrics.PasswordHashDuration.WithLabelValues(string(algorithm), operation).Observe(time.Since(started).Seconds())
+}
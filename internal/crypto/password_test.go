@@ -0,0 +1,63 @@
+package crypto
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestPasswordHasher(t *testing.T) {
+	params := Argon2Params{Memory: 64, Iterations: 1, Parallelism: 1}
+	bcryptHasher, err := NewPasswordHasher(PasswordBcrypt, bcrypt.MinCost, params)
+	require.NoError(t, err)
+	argonHasher, err := NewPasswordHasher(PasswordArgon2id, bcrypt.MinCost, params)
+	require.NoError(t, err)
+
+	bcryptHash, err := bcryptHasher.Hash("secret")
+	require.NoError(t, err)
+	argonHash, err := argonHasher.Hash("secret")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(argonHash, "$argon2id$v=19$m=64,t=1,p=1$"))
+
+	ok, rehash, err := bcryptHasher.Verify(bcryptHash, "secret")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.False(t, rehash)
+
+	ok, _, err = argonHasher.Verify(argonHash, "wrong")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	// после смены алгоритма старые хэши проверяются и помечаются на пересчет
+	ok, rehash, err = argonHasher.Verify(bcryptHash, "secret")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.True(t, rehash)
+
+	ok, rehash, err = bcryptHasher.Verify(argonHash, "secret")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.True(t, rehash)
+
+	// другие параметры того же алгоритма тоже требуют пересчета
+	stronger, err := NewPasswordHasher(PasswordBcrypt, bcrypt.MinCost+1, params)
+	require.NoError(t, err)
+	_, rehash, err = stronger.Verify(bcryptHash, "secret")
+	require.NoError(t, err)
+	assert.True(t, rehash)
+
+	_, _, err = argonHasher.Verify("plaintext", "secret")
+	assert.ErrorIs(t, err, ErrUnknownPasswordHash)
+	_, _, err = argonHasher.Verify("$argon2id$v=19$m=64$broken", "secret")
+	assert.ErrorIs(t, err, ErrUnknownPasswordHash)
+
+	_, err = NewPasswordHasher(PasswordBcrypt, 3, params)
+	assert.ErrorIs(t, err, ErrInvalidPasswordHasher)
+	_, err = NewPasswordHasher(PasswordArgon2id, 10, Argon2Params{Memory: 64, Iterations: 0, Parallelism: 1})
+	assert.ErrorIs(t, err, ErrInvalidPasswordHasher)
+	_, err = NewPasswordHasher("scrypt", 10, params)
+	assert.ErrorIs(t, err, ErrInvalidPasswordHasher)
+}
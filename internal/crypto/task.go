@@ -0,0 +1,94 @@
+package crypto
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+)
+
+const (
+	// информация для вывода ключа, чтобы ключи приватных задач не пересекались с другими назначениями KEK
+	taskKeyInfo = "taskmanager:private-task:v1"
+	keySize     = 32
+	minKEKSize  = 32
+)
+
+var (
+	// ErrInvalidMasterKey возвращается, если KEK не задан или слишком короткий
+	ErrInvalidMasterKey = errors.New("encryption master key must be at least 32 bytes")
+	// ErrDecrypt возвращается, если шифртекст поврежден или зашифрован другим ключом
+	ErrDecrypt = errors.New("failed to decrypt value")
+)
+
+// TaskEncryptor шифрует поля приватных задач ключом пользователя,
+// выведенным из общего KEK через HKDF-SHA256
+type TaskEncryptor struct {
+	kek []byte
+}
+
+// NewTaskEncryptor создает новый экземпляр TaskEncryptor
+func NewTaskEncryptor(masterKey string) (*TaskEncryptor, error) {
+	if len(masterKey) < minKEKSize {
+		return nil, ErrInvalidMasterKey
+	}
+
+	return &TaskEncryptor{kek: []byte(masterKey)}, nil
+}
+
+// Encrypt шифрует строку ключом пользователя (AES-256-GCM), результат в base64
+func (e *TaskEncryptor) Encrypt(userID, plaintext string) (string, error) {
+	aead, err := e.userCipher(userID)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	// userID передается как associated data, чтобы шифртекст нельзя было перенести на другого пользователя
+	sealed := aead.Seal(nonce, nonce, []byte(plaintext), []byte(userID))
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt расшифровывает строку, зашифрованную Encrypt
+func (e *TaskEncryptor) Decrypt(userID, ciphertext string) (string, error) {
+	aead, err := e.userCipher(userID)
+	if err != nil {
+		return "", err
+	}
+
+	data, err := base64.StdEncoding.DecodeString(ciphertext)
+	if err != nil || len(data) < aead.NonceSize() {
+		return "", ErrDecrypt
+	}
+
+	nonce, sealed := data[:aead.NonceSize()], data[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, sealed, []byte(userID))
+	if err != nil {
+		return "", ErrDecrypt
+	}
+
+	return string(plaintext), nil
+}
+
+// userCipher выводит ключ пользователя и возвращает AEAD
+func (e *TaskEncryptor) userCipher(userID string) (cipher.AEAD, error) {
+	key, err := hkdf.Key(sha256.New, e.kek, []byte(userID), taskKeyInfo, keySize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive user key: %w", err)
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// AIAction действие AI-ассистента над задачей
+type AIAction string
+
+const (
+	AIActionSummary  AIAction = "summary"
+	AIActionSubtasks AIAction = "subtasks"
+)
+
+// AIResult результат действия AI-ассистента, сохраняется на задаче
+type AIResult struct {
+	Action AIAction `json:"action" example:"summary"`
+	// Summary краткое содержание задачи (действие summary)
+	Summary string `json:"summary,omitempty"`
+	// Subtasks предложенные заголовки подзадач (действие subtasks), сами задачи не создаются
+	Subtasks []string `json:"subtasks,omitempty"`
+	// Model модель, сгенерировавшая результат
+	Model       string    `json:"model" example:"gpt-4o-mini"`
+	GeneratedAt time.Time `json:"generated_at"`
+	// Cached результат взят из сохраненного на задаче, модель не вызывалась
+	Cached bool `json:"cached"`
+	// SourceHash хэш заголовка и описания, по которым получен результат
+	SourceHash string `json:"-"`
+}
@@ -0,0 +1,77 @@
+package models
+
+import "time"
+
+// AuditAction действие, записанное в журнал аудита
+type AuditAction string
+
+const (
+	AuditImpersonationStarted AuditAction = "impersonation.started"
+	AuditImpersonationRevoked AuditAction = "impersonation.revoked"
+	// AuditImpersonatedRequest запрос, выполненный по токену имперсонации
+	AuditImpersonatedRequest AuditAction = "impersonation.request"
+	AuditUserDeactivated     AuditAction = "user.deactivated"
+	AuditUserReactivated     AuditAction = "user.reactivated"
+	AuditUserRoleChanged     AuditAction = "user.role_changed"
+	// AuditTaskDeletedByAdmin администратор удалил задачу другого пользователя
+	AuditTaskDeletedByAdmin AuditAction = "task.deleted_by_admin"
+)
+
+// AuditEvent запись журнала аудита
+type AuditEvent struct {
+	ID     int64       `json:"id" example:"42"`
+	Action AuditAction `json:"action" example:"impersonation.request"`
+	// ActorID пользователь, от имени которого выполнено действие
+	ActorID string `json:"actor_id" example:"3f1c2b4e-8d9a-4c1e-9f2b-7a6d5e4c3b2a"`
+	// ImpersonatorID администратор, действовавший от имени ActorID
+	ImpersonatorID string `json:"impersonator_id,omitempty" example:"9b2d7c1a-4e5f-4a3b-8c2d-1e0f9a8b7c6d"`
+	// Target объект действия: ID имперсонации или метод и путь запроса
+	Target    string            `json:"target,omitempty" example:"PUT /api/tasks/7f0c2a4e-1b9d-4c55-9f8e-3a2d1e6b5c4f"`
+	Details   map[string]string `json:"details,omitempty"`
+	CreatedAt time.Time         `json:"created_at"`
+}
+
+// AuditFilter выборка журнала аудита
+type AuditFilter struct {
+	// ActorID и ImpersonatorID ограничивают выборку, пустые не учитываются
+	ActorID        string
+	ImpersonatorID string
+	Limit          int
+}
+
+// DeactivateUserRequest запрос деактивации пользователя
+type DeactivateUserRequest struct {
+	Reason string `json:"reason" binding:"required" example:"Account compromised, ticket #4321"`
+}
+
+// Impersonation сессия поддержки: администратор действует от имени пользователя
+type Impersonation struct {
+	ID        string     `json:"id" example:"5d4c3b2a-1f0e-4d9c-8b7a-6f5e4d3c2b1a"`
+	AdminID   string     `json:"admin_id" example:"9b2d7c1a-4e5f-4a3b-8c2d-1e0f9a8b7c6d"`
+	UserID    string     `json:"user_id" example:"3f1c2b4e-8d9a-4c1e-9f2b-7a6d5e4c3b2a"`
+	Reason    string     `json:"reason" example:"Ticket #1234: tasks are not shown"`
+	CreatedAt time.Time  `json:"created_at"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+}
+
+// ImpersonateRequest запрос токена имперсонации
+type ImpersonateRequest struct {
+	Reason string `json:"reason" binding:"required" example:"Ticket #1234: tasks are not shown"`
+}
+
+// ImpersonationToken токен имперсонации
+type ImpersonationToken struct {
+	Token         string        `json:"token"`
+	Impersonation Impersonation `json:"impersonation"`
+}
+
+// TokenClaims проверенные утверждения токена доступа.
+// Для токена имперсонации заполнены ImpersonatorID и ImpersonationID
+type TokenClaims struct {
+	UserID string
+	// Role роль на момент выдачи токена; у токена имперсонации всегда RoleUser
+	Role            string
+	ImpersonatorID  string
+	ImpersonationID string
+}
@@ -0,0 +1,52 @@
+package models
+
+import "time"
+
+// BackupFormatVersion версия формата резервной копии пользователя
+const BackupFormatVersion = 1
+
+// BackupRecordType тип записи резервной копии
+type BackupRecordType string
+
+const (
+	BackupRecordAccount     BackupRecordType = "account"
+	BackupRecordTask        BackupRecordType = "task"
+	BackupRecordRelation    BackupRecordType = "relation"
+	BackupRecordPreferences BackupRecordType = "preferences"
+)
+
+// BackupRecord строка резервной копии пользователя: файл — поток JSON-записей по одной на строку
+// в порядке account, task, relation, preferences; заполнено поле, соответствующее Type
+type BackupRecord struct {
+	Type        BackupRecordType         `json:"type"`
+	Account     *BackupAccount           `json:"account,omitempty"`
+	Task        *Task                    `json:"task,omitempty"`
+	Relation    *BackupRelation          `json:"relation,omitempty"`
+	Preferences *NotificationPreferences `json:"preferences,omitempty"`
+}
+
+// BackupAccount учетная запись пользователя, первая запись копии. Хэш пароля переносится,
+// чтобы пользователь входил со старым паролем
+type BackupAccount struct {
+	Version      int       `json:"version"`
+	ID           string    `json:"id"`
+	Email        string    `json:"email"`
+	PasswordHash string    `json:"password_hash"`
+	CreatedAt    time.Time `json:"created_at"`
+	ExportedAt   time.Time `json:"exported_at"`
+}
+
+// BackupRelation связь "related to" между задачами пользователя
+type BackupRelation struct {
+	TaskID        string `json:"task_id"`
+	RelatedTaskID string `json:"related_task_id"`
+}
+
+// BackupSummary число перенесенных записей
+type BackupSummary struct {
+	UserID      string `json:"user_id"`
+	Email       string `json:"email"`
+	Tasks       int    `json:"tasks"`
+	Relations   int    `json:"relations"`
+	Preferences bool   `json:"preferences"`
+}
@@ -0,0 +1,112 @@
+package models
+
+import "time"
+
+// CalendarFeed ссылка подписки календаря на задачи пользователя. Календарь запрашивает
+// GET /api/tasks/calendar.ics?token=... без JWT; токен дает право только на чтение календаря
+type CalendarFeed struct {
+	Token string `json:"token" example:"dXNlcjEuMQ.Kq0Xb3..."`
+	// URL путь ленты вместе с токеном, клиент дополняет его адресом сервера
+	URL       string    `json:"url" example:"/api/tasks/calendar.ics?token=dXNlcjEuMQ.Kq0Xb3..."`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// CalendarProvider внешний календарь для двусторонней синхронизации сроков задач
+type CalendarProvider string
+
+const (
+	// CalendarGoogle Google Calendar API, изменения приходят push-уведомлениями
+	CalendarGoogle CalendarProvider = "google"
+	// CalendarApple календарь iCloud по CalDAV, изменения опрашиваются
+	CalendarApple CalendarProvider = "apple"
+)
+
+// CalendarConflictPolicy чей срок сохраняется, если с последней синхронизации он изменился
+// и в задаче, и в событии календаря
+type CalendarConflictPolicy string
+
+const (
+	// ConflictLatest побеждает более позднее изменение
+	ConflictLatest CalendarConflictPolicy = "latest"
+	// ConflictTask срок задачи снова отправляется в календарь
+	ConflictTask CalendarConflictPolicy = "task"
+	// ConflictCalendar срок события переносится в задачу
+	ConflictCalendar CalendarConflictPolicy = "calendar"
+)
+
+// IsValid проверяет, что политика известна
+func (p CalendarConflictPolicy) IsValid() bool {
+	switch p {
+	case ConflictLatest, ConflictTask, ConflictCalendar:
+		return true
+	}
+	return false
+}
+
+// CalendarLink календарь, подключенный пользователем для синхронизации сроков: задачи со сроком
+// становятся событиями, а перенос события меняет срок задачи
+type CalendarLink struct {
+	ID       string           `json:"id" db:"id"`
+	UserID   string           `json:"-" db:"user_id"`
+	Provider CalendarProvider `json:"provider" db:"provider" example:"google"`
+	// CalendarID ID календаря Google или адрес коллекции CalDAV
+	CalendarID string `json:"calendar_id" db:"calendar_id" example:"primary"`
+	// Username Apple ID для CalDAV
+	Username string `json:"username,omitempty" db:"username"`
+	// Secret refresh token Google или пароль приложения Apple, в ответах не возвращается
+	Secret         string                 `json:"-" db:"secret"`
+	ConflictPolicy CalendarConflictPolicy `json:"conflict_policy" db:"conflict_policy" example:"latest"`
+	// SyncToken позиция в журнале изменений календаря, с которой читаются следующие изменения
+	SyncToken string `json:"-" db:"sync_token"`
+	// Channel канал push-уведомлений Google, пустой ID — канала нет
+	Channel  CalendarChannel `json:"-"`
+	SyncedAt *time.Time      `json:"synced_at,omitempty" db:"synced_at"`
+	// LastError ошибка последней синхронизации, пустая после успешной
+	LastError string    `json:"last_error,omitempty" db:"last_error"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// CalendarChannel канал уведомлений об изменениях календаря. Token приходит в каждом уведомлении
+// и подтверждает, что его отправил календарь
+type CalendarChannel struct {
+	ID         string     `db:"channel_id"`
+	Token      string     `db:"channel_token"`
+	ResourceID string     `db:"channel_resource_id"`
+	ExpiresAt  *time.Time `db:"channel_expires_at"`
+}
+
+// CalendarLinkRequest запрос на подключение календаря
+type CalendarLinkRequest struct {
+	Provider CalendarProvider `json:"provider" binding:"required" example:"google"`
+	// CalendarID для Google ID календаря, по умолчанию primary; для Apple адрес коллекции CalDAV
+	CalendarID string `json:"calendar_id" example:"primary"`
+	// RefreshToken OAuth refresh token Google с доступом к календарю, выданный клиенту сервера
+	RefreshToken string `json:"refresh_token"`
+	// Username и Password Apple ID и пароль приложения для iCloud
+	Username string `json:"username"`
+	Password string `json:"password"`
+	// ConflictPolicy по умолчанию latest
+	ConflictPolicy CalendarConflictPolicy `json:"conflict_policy" example:"latest"`
+}
+
+// CalendarEvent событие календаря в журнале изменений
+type CalendarEvent struct {
+	ID string
+	// Start начало события, оно же срок задачи
+	Start time.Time
+	// Updated время последнего изменения события
+	Updated time.Time
+	// Deleted событие удалено из календаря
+	Deleted bool
+}
+
+// CalendarSyncState последнее согласованное состояние задачи и ее события: после синхронизации
+// срок задачи и начало события совпадают с DueDate. Изменение только одной стороны переносится
+// на другую, изменение обеих — конфликт
+type CalendarSyncState struct {
+	LinkID   string    `db:"link_id"`
+	TaskID   string    `db:"task_id"`
+	EventID  string    `db:"event_id"`
+	DueDate  time.Time `db:"due_date"`
+	SyncedAt time.Time `db:"synced_at"`
+}
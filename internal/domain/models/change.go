@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// TaskChange запись журнала изменений задач (CDC).
+// Seq общий для всех задач и возрастает в порядке записи, Version — номер изменения одной задачи
+type TaskChange struct {
+	Seq     int64     `json:"seq"`
+	TaskID  string    `json:"task_id"`
+	UserID  string    `json:"user_id"`
+	Version int64     `json:"version"`
+	Type    EventType `json:"type"`
+	Task    Task      `json:"task"`
+	// Changes измененные поля для записей task.updated
+	Changes    []FieldChange `json:"changes,omitempty"`
+	OccurredAt time.Time     `json:"occurred_at"`
+}
+
+// TaskChangeFeed страница журнала изменений; Next передается в after следующего запроса
+type TaskChangeFeed struct {
+	Changes []TaskChange `json:"changes"`
+	Next    int64        `json:"next"`
+}
@@ -0,0 +1,144 @@
+package models
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// EventType тип события задачи
+type EventType string
+
+const (
+	EventTaskCreated   EventType = "task.created"
+	EventTaskUpdated   EventType = "task.updated"
+	EventTaskCompleted EventType = "task.completed"
+	EventTaskDeleted   EventType = "task.deleted"
+	// EventTasksCompleted одно событие на пакетное выполнение задач, задачи в Tasks
+	EventTasksCompleted EventType = "tasks.completed"
+	// EventTasksDeleted одно событие на пачку массового удаления, задачи в Tasks
+	EventTasksDeleted EventType = "tasks.deleted"
+)
+
+// IsValid проверяет, что тип события известен
+func (e EventType) IsValid() bool {
+	switch e {
+	case EventTaskCreated, EventTaskUpdated, EventTaskCompleted, EventTaskDeleted, EventTasksCompleted, EventTasksDeleted:
+		return true
+	}
+	return false
+}
+
+// TaskEvent событие изменения задачи.
+// Для приватных задач Task содержит заблокированную копию без заголовка и описания.
+// Пакетные события заполняют Tasks вместо Task, события task.updated — Changes
+type TaskEvent struct {
+	Type       EventType     `json:"type"`
+	UserID     string        `json:"user_id"`
+	Task       Task          `json:"task"`
+	Tasks      []Task        `json:"tasks,omitempty"`
+	Changes    []FieldChange `json:"changes,omitempty"`
+	OccurredAt time.Time     `json:"occurred_at"`
+}
+
+// FieldChange изменение одного поля задачи: Field — имя поля в JSON задачи.
+// У приватной задачи значения title, description и notes не передаются, только факт изменения
+type FieldChange struct {
+	Field string      `json:"field" example:"status"`
+	From  interface{} `json:"from,omitempty" swaggertype:"string" example:"pending"`
+	To    interface{} `json:"to,omitempty" swaggertype:"string" example:"done"`
+}
+
+// String описание изменения, например: status changed from pending to done
+func (c FieldChange) String() string {
+	switch {
+	case c.From == nil && c.To == nil:
+		return c.Field + " changed"
+	case c.From == nil:
+		return fmt.Sprintf("%s set to %s", c.Field, changeValue(c.To))
+	case c.To == nil:
+		return fmt.Sprintf("%s cleared (was %s)", c.Field, changeValue(c.From))
+	}
+	return fmt.Sprintf("%s changed from %s to %s", c.Field, changeValue(c.From), changeValue(c.To))
+}
+
+// changeValue значение поля для описания изменения
+func changeValue(value interface{}) string {
+	switch v := value.(type) {
+	case time.Time:
+		return v.Format(time.RFC3339)
+	case []string:
+		return strings.Join(v, ", ")
+	case []TaskLink:
+		urls := make([]string, len(v))
+		for i, link := range v {
+			urls[i] = link.URL
+		}
+		return strings.Join(urls, ", ")
+	}
+	return fmt.Sprint(value)
+}
+
+// secretFields поля, которые у приватной задачи хранятся зашифрованными
+var secretFields = map[string]bool{"title": true, "description": true, "notes": true}
+
+// DiffTasks изменения полей задачи, которые задает пользователь. Служебные поля
+// (updated_at, completed_at) не сравниваются; пустые значения передаются как отсутствующие
+func DiffTasks(before, after Task) []FieldChange {
+	fields := []struct {
+		name     string
+		from, to interface{}
+	}{
+		{"title", before.Title, after.Title},
+		{"description", before.Description, after.Description},
+		{"notes", optional(before.Notes), optional(after.Notes)},
+		{"links", before.Links, after.Links},
+		{"tags", before.Tags, after.Tags},
+		{"status", before.Status, after.Status},
+		{"priority", before.Priority, after.Priority},
+		{"due_date", before.DueDate.UTC(), after.DueDate.UTC()},
+		{"parent_id", optional(before.ParentID), optional(after.ParentID)},
+		{"project_id", optional(before.ProjectID), optional(after.ProjectID)},
+		{"assignee_id", optional(before.AssigneeID), optional(after.AssigneeID)},
+		{"private", before.Private, after.Private},
+	}
+
+	private := before.Private || after.Private
+	var changes []FieldChange
+	for _, field := range fields {
+		from, to := emptyToNil(field.from), emptyToNil(field.to)
+		if reflect.DeepEqual(from, to) {
+			continue
+		}
+		if private && secretFields[field.name] {
+			from, to = nil, nil
+		}
+		changes = append(changes, FieldChange{Field: field.name, From: from, To: to})
+	}
+
+	return changes
+}
+
+// optional значение необязательного поля, nil для отсутствующего
+func optional(value *string) interface{} {
+	if value == nil {
+		return nil
+	}
+	return *value
+}
+
+// emptyToNil приводит пустые строки и списки к nil, чтобы они не отличались от отсутствующих
+func emptyToNil(value interface{}) interface{} {
+	if value == nil {
+		return nil
+	}
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.String, reflect.Slice:
+		if v.Len() == 0 {
+			return nil
+		}
+	}
+	return value
+}
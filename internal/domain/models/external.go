@@ -0,0 +1,43 @@
+package models
+
+import "time"
+
+// ExternalProvider внешний трекер задач
+type ExternalProvider string
+
+const (
+	ProviderGitHub ExternalProvider = "github"
+	ProviderJira   ExternalProvider = "jira"
+)
+
+// ExternalIssue состояние задачи во внешнем трекере
+type ExternalIssue struct {
+	Title  string `json:"title"`
+	Status string `json:"status"`
+	URL    string `json:"url"`
+	// Closed задача закрыта: issue в состоянии closed, тикет Jira в категории статусов done
+	Closed bool `json:"closed"`
+}
+
+// ExternalRef связь задачи с задачей внешнего трекера
+type ExternalRef struct {
+	ID       string           `json:"id" db:"id"`
+	TaskID   string           `json:"task_id" db:"task_id"`
+	UserID   string           `json:"-" db:"user_id"`
+	Provider ExternalProvider `json:"provider" db:"provider" example:"github"`
+	// Key канонический ключ: owner/repo#123 для GitHub, PROJ-42 для Jira
+	Key string `json:"key" db:"external_key" example:"jmoloko/task-management#42"`
+	ExternalIssue
+	// MirrorCompletion закрытие внешней задачи переводит задачу в статус done
+	MirrorCompletion bool       `json:"mirror_completion" db:"mirror_completion"`
+	CheckedAt        *time.Time `json:"checked_at,omitempty" db:"checked_at"`
+	CreatedAt        time.Time  `json:"created_at" db:"created_at"`
+}
+
+// LinkExternalRequest запрос на связывание задачи с внешней задачей
+type LinkExternalRequest struct {
+	Provider ExternalProvider `json:"provider" binding:"required" example:"github"`
+	// Ref ключ (owner/repo#123, PROJ-42) или ссылка на задачу трекера
+	Ref              string `json:"ref" binding:"required" example:"https://github.com/jmoloko/task-management/issues/42"`
+	MirrorCompletion bool   `json:"mirror_completion"`
+}
@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+// TaskRefPrefix префикс ссылки на задачу в сообщении коммита: TM-<первые 8 символов ID задачи>
+const TaskRefPrefix = "TM-"
+
+// GitHubRepoLink репозиторий GitHub, push-события которого закрывают задачи пользователя.
+// Сообщение коммита вида "closes TM-1a2b3c4d" в основной ветке переводит задачу в статус done
+type GitHubRepoLink struct {
+	ID     string `json:"id" db:"id"`
+	UserID string `json:"-" db:"user_id"`
+	// Repository полное имя репозитория owner/repo
+	Repository string `json:"repository" db:"repository" example:"acme/api"`
+	// Secret секрет webhook в настройках репозитория, возвращается только при подключении
+	Secret string `json:"secret,omitempty" db:"secret"`
+	// WebhookPath путь, который указывается как Payload URL webhook в GitHub
+	WebhookPath string    `json:"webhook_path" db:"-" example:"/api/integrations/github/webhook/123e4567-e89b-12d3-a456-426614174000"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+}
+
+// GitHubRepoLinkRequest запрос на подключение репозитория
+type GitHubRepoLinkRequest struct {
+	Repository string `json:"repository" binding:"required" example:"acme/api"`
+}
+
+// CommitAutomationResult результат обработки push-события
+type CommitAutomationResult struct {
+	// Completed ID задач, выполненных по сообщениям коммитов
+	Completed []string `json:"completed"`
+	// Unresolved ссылки, которым не соответствует ровно одна задача пользователя
+	Unresolved []string `json:"unresolved"`
+}
@@ -0,0 +1,48 @@
+package models
+
+import "time"
+
+// IncomingHook входящий webhook: внешняя система (алерты мониторинга, формы) создает задачи
+// пользователя запросом POST /api/hooks/{token} без JWT. Токен дает право только на создание задач
+type IncomingHook struct {
+	ID      string      `json:"id" db:"id"`
+	UserID  string      `json:"user_id" db:"user_id"`
+	Name    string      `json:"name" db:"name" example:"Grafana alerts"`
+	Mapping HookMapping `json:"mapping" db:"mapping"`
+	// Token секрет URL, возвращается только при создании, в базе хранится его SHA-256
+	Token      string     `json:"token,omitempty" db:"-" example:"hk_9f8e7d6c5b4a39281706f5e4d3c2b1a0"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty" db:"last_used_at"`
+}
+
+// HookMapping откуда брать поля задачи в JSON входящего запроса. Значения — пути
+// через точку (например, "alert.labels.severity"), индексы массивов — числа ("alerts.0.summary").
+// Пустой путь — поле не заполняется из запроса
+type HookMapping struct {
+	Title       string `json:"title" example:"alert.name"`
+	Description string `json:"description,omitempty" example:"alert.message"`
+	Priority    string `json:"priority,omitempty" example:"alert.severity"`
+	DueDate     string `json:"due_date,omitempty"`
+	// DefaultPriority приоритет, если в запросе его нет или он не low/medium/high
+	DefaultPriority Priority `json:"default_priority,omitempty" example:"high"`
+}
+
+// DefaultHookMapping сопоставление по умолчанию: одноименные поля верхнего уровня
+var DefaultHookMapping = HookMapping{
+	Title:       "title",
+	Description: "description",
+	Priority:    "priority",
+	DueDate:     "due_date",
+}
+
+// IncomingHookRequest запрос на создание входящего webhook
+type IncomingHookRequest struct {
+	Name string `json:"name" binding:"required" example:"Grafana alerts"`
+	// Mapping если не задан, используется DefaultHookMapping
+	Mapping *HookMapping `json:"mapping,omitempty"`
+}
+
+// HookDelivery ответ на входящий запрос: только ID созданной задачи, токен не дает читать задачи
+type HookDelivery struct {
+	TaskID string `json:"task_id" example:"123e4567-e89b-12d3-a456-426614174000"`
+}
@@ -0,0 +1,12 @@
+package models
+
+// IdempotencyRecord запрос с заголовком Idempotency-Key. Пока первый запрос выполняется, Completed = false
+// и повторы с тем же ключом отклоняются; после ответа запись хранит его для повторов
+type IdempotencyRecord struct {
+	Completed bool `json:"completed"`
+	// Fingerprint SHA-256 тела первого запроса: повтор с другим телом — ошибка клиента
+	Fingerprint string `json:"fingerprint,omitempty"`
+	Status      int    `json:"status,omitempty"`
+	ContentType string `json:"content_type,omitempty"`
+	Body        []byte `json:"body,omitempty"`
+}
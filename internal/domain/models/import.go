@@ -0,0 +1,53 @@
+package models
+
+// ImportRow разобранная строка импорта
+type ImportRow struct {
+	// Row номер строки в исходном файле (для JSON — номер элемента массива, с 1)
+	Row  int
+	Task Task
+}
+
+// ImportBatch результат разбора файла импорта
+type ImportBatch struct {
+	Rows []ImportRow
+	// Errors строки, которые не удалось разобрать
+	Errors []ImportRowError
+	// Mapping соответствие колонок файла полям задачи, для CSV и XLSX
+	Mapping map[string]string
+}
+
+// ImportRowError ошибка строки импорта
+type ImportRowError struct {
+	Row     int    `json:"row" example:"3"`
+	Field   string `json:"field,omitempty" example:"priority"`
+	Message string `json:"message" example:"unknown priority"`
+}
+
+// ImportPreview результат импорта без записи в базу
+type ImportPreview struct {
+	// Total число строк в файле
+	Total int `json:"total"`
+	// Create число задач, которые будут созданы
+	Create int `json:"create"`
+	// Duplicates число строк, совпадающих с существующей задачей или строкой выше
+	Duplicates int `json:"duplicates"`
+	// Invalid число строк с ошибками
+	Invalid int `json:"invalid"`
+	// Mapping соответствие колонок файла полям задачи, пустое значение — колонка не используется
+	Mapping map[string]string `json:"mapping,omitempty"`
+	// DuplicateRows номера строк-дубликатов
+	DuplicateRows []int            `json:"duplicate_rows"`
+	Errors        []ImportRowError `json:"errors"`
+}
+
+// ImportReport результат импорта файла
+type ImportReport struct {
+	// Total число строк с данными в файле
+	Total int `json:"total" example:"3"`
+	// Imported число созданных задач
+	Imported int `json:"imported" example:"2"`
+	// Invalid число строк с ошибками
+	Invalid int `json:"invalid" example:"1"`
+	// Errors ошибки строк, не больше первой тысячи
+	Errors []ImportRowError `json:"errors"`
+}
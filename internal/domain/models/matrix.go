@@ -0,0 +1,24 @@
+package models
+
+// MatrixSettings пороги матрицы Эйзенхауэра пользователя
+type MatrixSettings struct {
+	UserID string `json:"-" db:"user_id"`
+	// UrgentWithinHours задача срочная, если до ее срока не больше стольких часов; просроченные срочные всегда
+	UrgentWithinHours int `json:"urgent_within_hours" db:"urgent_within_hours" example:"48"`
+	// ImportantPriority наименьший приоритет важной задачи
+	ImportantPriority Priority `json:"important_priority" db:"important_priority" example:"high"`
+}
+
+// TaskMatrix открытые задачи по квадрантам матрицы Эйзенхауэра, в каждом — в порядке smart
+type TaskMatrix struct {
+	// DoFirst срочные и важные
+	DoFirst []Task `json:"do_first"`
+	// Schedule важные, но не срочные
+	Schedule []Task `json:"schedule"`
+	// Delegate срочные, но не важные
+	Delegate []Task `json:"delegate"`
+	// Eliminate не срочные и не важные
+	Eliminate []Task `json:"eliminate"`
+	// Settings пороги, по которым построена матрица
+	Settings MatrixSettings `json:"settings"`
+}
@@ -0,0 +1,142 @@
+package models
+
+import "time"
+
+// Notification сообщение, доставляемое пользователю через каналы уведомлений
+type Notification struct {
+	Event  string `json:"event"`
+	Title  string `json:"title"`
+	Body   string `json:"body"`
+	TaskID string `json:"task_id,omitempty"`
+}
+
+// PushSubscription подписка браузера на Web Push
+type PushSubscription struct {
+	ID        string    `json:"id" db:"id"`
+	UserID    string    `json:"user_id" db:"user_id"`
+	Endpoint  string    `json:"endpoint" db:"endpoint"`
+	P256dh    string    `json:"p256dh" db:"p256dh"`
+	Auth      string    `json:"auth" db:"auth"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// PushSubscriptionRequest подписка в формате PushSubscription.toJSON() браузера
+type PushSubscriptionRequest struct {
+	Endpoint string `json:"endpoint"`
+	Keys     struct {
+		P256dh string `json:"p256dh"`
+		Auth   string `json:"auth"`
+	} `json:"keys"`
+}
+
+// PushUnsubscribeRequest запрос на удаление подписки
+type PushUnsubscribeRequest struct {
+	Endpoint string `json:"endpoint"`
+}
+
+// NotificationChannel канал доставки уведомлений пользователю
+type NotificationChannel string
+
+const (
+	ChannelPush  NotificationChannel = "push"
+	ChannelEmail NotificationChannel = "email"
+	ChannelSlack NotificationChannel = "slack"
+	// ChannelTelegram уведомления ботом в чат, привязанный через /api/notifications/telegram
+	ChannelTelegram NotificationChannel = "telegram"
+)
+
+// IsValid проверяет, что канал известен
+func (c NotificationChannel) IsValid() bool {
+	return c == ChannelPush || c == ChannelEmail || c == ChannelSlack || c == ChannelTelegram
+}
+
+// NotificationEventDueSoon событие напоминания о приближающемся сроке
+const NotificationEventDueSoon = "task.due_soon"
+
+// NotificationEventQuotaWarning событие приближения к лимиту пользователя
+const NotificationEventQuotaWarning = "quota.warning"
+
+// NotificationEventOverdue событие задачи, срок которой прошел, а задача не выполнена
+const NotificationEventOverdue = "task.overdue"
+
+// TelegramLink чат Telegram, в который бот отправляет уведомления пользователя
+type TelegramLink struct {
+	UserID string `json:"-" db:"user_id"`
+	ChatID int64  `json:"chat_id" db:"chat_id" example:"123456789"`
+	// DailyDigestAt время ежедневной сводки задач на сегодня (HH:MM) в часовом поясе настроек уведомлений,
+	// пустое значение отключает сводку
+	DailyDigestAt string    `json:"daily_digest_at,omitempty" db:"daily_digest_at" example:"08:00"`
+	LinkedAt      time.Time `json:"linked_at" db:"linked_at"`
+	// Timezone и LastDigestOn нужны фоновой отправке сводок
+	Timezone     string     `json:"-" db:"timezone"`
+	LastDigestOn *time.Time `json:"-" db:"last_digest_on"`
+}
+
+// TelegramLinkRequest запрос на привязку чата Telegram
+type TelegramLinkRequest struct {
+	ChatID        int64  `json:"chat_id" binding:"required" example:"123456789"`
+	DailyDigestAt string `json:"daily_digest_at" example:"08:00"`
+}
+
+// NotificationPreferences настройки уведомлений пользователя
+type NotificationPreferences struct {
+	UserID string `json:"-" db:"user_id"`
+	// Channels каналы доставки, пустой список отключает уведомления
+	Channels []NotificationChannel `json:"channels" db:"channels" example:"push,email"`
+	// EventTypes события, о которых уведомлять; пустой список — все события
+	EventTypes []string `json:"event_types" db:"event_types" example:"task.due_soon"`
+	// DueSoonWindowMinutes за сколько минут до срока напоминать о задаче
+	DueSoonWindowMinutes int `json:"due_soon_window_minutes" db:"due_soon_window_minutes"`
+	// DigestWindowMinutes за сколько минут собирать уведомления в один дайджест, 0 — отправлять сразу
+	DigestWindowMinutes int `json:"digest_window_minutes" db:"digest_window_minutes"`
+	// QuietHoursStart и QuietHoursEnd тихие часы в формате HH:MM в часовом поясе Timezone.
+	// Уведомления в тихие часы откладываются до их окончания; пустые значения отключают тихие часы
+	QuietHoursStart string `json:"quiet_hours_start" db:"quiet_hours_start" example:"22:00"`
+	QuietHoursEnd   string `json:"quiet_hours_end" db:"quiet_hours_end" example:"08:00"`
+	// Timezone часовой пояс IANA, например Europe/Moscow
+	Timezone string `json:"timezone" db:"timezone" example:"UTC"`
+	// SlackWebhookURL Slack incoming webhook, в который уходят уведомления канала slack
+	SlackWebhookURL string `json:"slack_webhook_url,omitempty" db:"slack_webhook_url" example:"https://hooks.slack.com/services/T000/B000/XXXX"`
+}
+
+// ExplicitlyAllows подписан ли пользователь на событие явно. Уведомления о собственных
+// действиях пользователя (выполнение задач) отправляются только по явной подписке
+func (p NotificationPreferences) ExplicitlyAllows(event string) bool {
+	for _, e := range p.EventTypes {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// HasChannel проверяет, включен ли канал
+func (p NotificationPreferences) HasChannel(channel NotificationChannel) bool {
+	for _, c := range p.Channels {
+		if c == channel {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowsEvent проверяет, подписан ли пользователь на событие
+func (p NotificationPreferences) AllowsEvent(event string) bool {
+	if len(p.EventTypes) == 0 {
+		return true
+	}
+	for _, e := range p.EventTypes {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// NotificationPreviewRequest запрос на предпросмотр шаблона уведомления.
+// Если Event не задан, используется пример события
+type NotificationPreviewRequest struct {
+	Channel  string     `json:"channel" example:"email"`
+	Template string     `json:"template" example:"task_event"`
+	Event    *TaskEvent `json:"event,omitempty"`
+}
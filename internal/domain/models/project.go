@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// Project проект пользователя, объединяющий задачи. Задача входит не более чем в один проект
+type Project struct {
+	ID          string    `json:"id" db:"id"`
+	UserID      string    `json:"user_id" db:"user_id"`
+	Name        string    `json:"name" db:"name" example:"Website redesign"`
+	Description string    `json:"description,omitempty" db:"description"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// ProjectRequest запрос на создание и изменение проекта; название уникально среди проектов пользователя
+type ProjectRequest struct {
+	Name        string `json:"name" binding:"required,max=100" example:"Website redesign"`
+	Description string `json:"description,omitempty"`
+}
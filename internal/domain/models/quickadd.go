@@ -0,0 +1,10 @@
+package models
+
+// QuickAddRequest строка быстрого добавления задачи
+type QuickAddRequest struct {
+	Text string `json:"text" binding:"required,max=500" example:"Pay rent friday high priority #finance"`
+	// Timezone часовой пояс IANA для сроков в строке, пустой берется из настроек уведомлений
+	Timezone string `json:"timezone,omitempty" example:"Europe/Moscow"`
+	// Create создать задачу сразу; иначе задача только разбирается и возвращается для подтверждения
+	Create bool `json:"create"`
+}
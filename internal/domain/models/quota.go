@@ -0,0 +1,15 @@
+package models
+
+// имена лимитов пользователя
+const (
+	QuotaOpenTasks = "open_tasks"
+	QuotaTriggers  = "triggers"
+)
+
+// Warning предупреждение в ответе на изменяющий запрос, например о приближении к лимиту
+type Warning struct {
+	Code    string `json:"code" example:"quota.open_tasks"`
+	Message string `json:"message" example:"900 of 1000 open tasks used"`
+	Used    int    `json:"used,omitempty" example:"900"`
+	Limit   int    `json:"limit,omitempty" example:"1000"`
+}
@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// Recurrence серия повторяющейся задачи
+type Recurrence struct {
+	ID     string `json:"id"`
+	UserID string `json:"-"`
+	// Rule правило повторения в каноническом виде RRULE
+	Rule   string `json:"rule" example:"FREQ=WEEKLY;BYDAY=MO"`
+	Paused bool   `json:"paused"`
+	// Occurrences сколько экземпляров создано, включая первый
+	Occurrences int `json:"occurrences"`
+	// LastTaskID последний экземпляр, из него создается следующий; пустой — серия завершена
+	LastTaskID string    `json:"last_task_id,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// DueOccurrence последний экземпляр активной серии, который выполнен или просрочен
+type DueOccurrence struct {
+	Task        Task
+	Rule        string
+	Occurrences int
+}
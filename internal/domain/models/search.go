@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+// NotificationEventSearchMatch событие новой задачи, подходящей под сохраненный поиск
+const NotificationEventSearchMatch = "search.match"
+
+// SavedSearch сохраненный запрос к списку задач на языке параметра q. С включенным оповещением
+// пользователь получает уведомление, когда созданная или измененная задача начинает подходить под запрос
+type SavedSearch struct {
+	ID     string `json:"id" db:"id"`
+	UserID string `json:"-" db:"user_id"`
+	Name   string `json:"name" db:"name" example:"Overdue work"`
+	Query  string `json:"query" db:"query" example:"is:overdue #work"`
+	// Timezone часовой пояс IANA, в котором читаются даты запроса (today, due<2024-07-01)
+	Timezone string `json:"timezone" db:"timezone" example:"Europe/Moscow"`
+	// Alert уведомлять о новых совпадениях
+	Alert bool `json:"alert" db:"alert"`
+	// AlertIntervalMinutes не чаще одного уведомления за столько минут, 0 — о каждом совпадении.
+	// Совпадения внутри интервала не теряются: их число приходит в следующем уведомлении
+	AlertIntervalMinutes int        `json:"alert_interval_minutes" db:"alert_interval_minutes" example:"60"`
+	LastAlertAt          *time.Time `json:"last_alert_at,omitempty" db:"last_alert_at"`
+	CreatedAt            time.Time  `json:"created_at" db:"created_at"`
+}
+
+// SavedSearchRequest запрос на создание или изменение сохраненного поиска
+type SavedSearchRequest struct {
+	Name  string `json:"name" binding:"required" example:"Overdue work"`
+	Query string `json:"query" binding:"required" example:"is:overdue #work"`
+	// Timezone по умолчанию UTC
+	Timezone string `json:"timezone,omitempty" example:"Europe/Moscow"`
+	Alert    bool   `json:"alert"`
+	// AlertIntervalMinutes по умолчанию 60
+	AlertIntervalMinutes *int `json:"alert_interval_minutes,omitempty" example:"60"`
+}
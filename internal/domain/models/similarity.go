@@ -0,0 +1,18 @@
+package models
+
+// TaskEmbedding векторное представление заголовка и описания задачи
+type TaskEmbedding struct {
+	TaskID string
+	UserID string
+	// Model модель, построившая вектор; сравниваются только векторы одной модели
+	Model string
+	// SourceHash хэш текста, по которому построен вектор
+	SourceHash string
+	Vector     []float32
+}
+
+// SimilarTask похожая задача и близость к исходной: 1 — тексты совпадают по смыслу
+type SimilarTask struct {
+	Task  Task    `json:"task"`
+	Score float64 `json:"score" example:"0.87"`
+}
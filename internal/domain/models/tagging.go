@@ -0,0 +1,62 @@
+package models
+
+import "time"
+
+// TaggingMatch способ сопоставления правила с текстом задачи
+type TaggingMatch string
+
+const (
+	// TaggingMatchKeyword любое из ключевых слов через запятую встречается в тексте, без учета регистра
+	TaggingMatchKeyword TaggingMatch = "keyword"
+	// TaggingMatchRegex регулярное выражение в синтаксисе Go (RE2)
+	TaggingMatchRegex TaggingMatch = "regex"
+)
+
+// TaggingField поле задачи, с которым сопоставляется правило
+type TaggingField string
+
+const (
+	TaggingFieldTitle       TaggingField = "title"
+	TaggingFieldDescription TaggingField = "description"
+	TaggingFieldAny         TaggingField = "any"
+)
+
+// TaggingRule правило автоматической расстановки тегов: если заголовок или описание задачи
+// подходят под шаблон, при создании и изменении задачи к ней добавляются теги правила
+type TaggingRule struct {
+	ID      string       `json:"id" db:"id"`
+	UserID  string       `json:"user_id" db:"user_id"`
+	Name    string       `json:"name" db:"name" example:"Invoices"`
+	Match   TaggingMatch `json:"match" db:"match_type" example:"keyword"`
+	Field   TaggingField `json:"field" db:"field" example:"any"`
+	Pattern string       `json:"pattern" db:"pattern" example:"invoice, bill"`
+	Tags    []string     `json:"tags" db:"tags" example:"finance"`
+	Enabled bool         `json:"enabled" db:"enabled"`
+	// CreatedAt правила применяются в порядке создания
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// TaggingRuleRequest запрос на создание правила
+type TaggingRuleRequest struct {
+	Name  string       `json:"name" binding:"required" example:"Invoices"`
+	Match TaggingMatch `json:"match" example:"keyword"`
+	// Field по умолчанию any — заголовок и описание
+	Field   TaggingField `json:"field,omitempty" example:"any"`
+	Pattern string       `json:"pattern" example:"invoice, bill"`
+	Tags    []string     `json:"tags" example:"finance"`
+	Enabled *bool        `json:"enabled,omitempty"`
+}
+
+// TaggingTestRequest текст задачи для пробного применения правил
+type TaggingTestRequest struct {
+	Title       string `json:"title" example:"Pay the invoice from ACME"`
+	Description string `json:"description,omitempty"`
+	// Rule если задано, проверяется только это правило, а не сохраненные
+	Rule *TaggingRuleRequest `json:"rule,omitempty"`
+}
+
+// TaggingTestResult результат пробного применения: задача не создается и не меняется
+type TaggingTestResult struct {
+	Tags    []string      `json:"tags"`
+	Matched []TaggingRule `json:"matched"`
+}
@@ -2,7 +2,9 @@ package models
 
 import (
 	"database/sql/driver"
+	"encoding/base64"
 	"fmt"
+	"strings"
 	"time"
 )
 
@@ -17,6 +19,10 @@ const (
 	StatusPending    Status = "pending"
 	StatusInProgress Status = "in_progress"
 	StatusDone       Status = "done"
+
+	// StatusOverdue псевдостатус фильтра списка задач: невыполненные задачи с прошедшим сроком.
+	// Задача в этом статусе не хранится
+	StatusOverdue Status = "overdue"
 )
 
 // Константы для приоритетов задач
@@ -26,6 +32,19 @@ const (
 	PriorityHigh   Priority = "high"
 )
 
+// Rank порядок приоритета: low — 1, medium — 2, high — 3, неизвестный — 0
+func (p Priority) Rank() int {
+	switch p {
+	case PriorityLow:
+		return 1
+	case PriorityMedium:
+		return 2
+	case PriorityHigh:
+		return 3
+	}
+	return 0
+}
+
 // Value реализует интерфейс driver.Valuer для типа Priority
 func (p Priority) Value() (driver.Value, error) {
 	return string(p), nil
@@ -74,16 +93,114 @@ func (s *Status) Scan(value interface{}) error {
 
 // Task представляет модель задачи
 type Task struct {
-	ID          string     `json:"id" db:"id"`
-	Title       string     `json:"title" db:"title"`
-	Description string     `json:"description" db:"description"`
-	Status      Status     `json:"status" db:"status"`
-	Priority    Priority   `json:"priority" db:"priority"`
-	UserID      string     `json:"user_id" db:"user_id"`
-	DueDate     time.Time  `json:"due_date" db:"due_date"`
-	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
-	UpdatedAt   time.Time  `json:"updated_at" db:"updated_at"`
-	CompletedAt *time.Time `json:"completed_at,omitempty" db:"completed_at"`
+	ID    string `json:"id" db:"id"`
+	Title string `json:"title" db:"title"`
+	// Description описание задачи, пустое значение хранится в БД как NULL
+	Description string `json:"description" db:"description"`
+	// Notes подробные заметки; nil при обновлении оставляет заметки без изменений, пустая строка удаляет их
+	Notes *string `json:"notes,omitempty" db:"notes"`
+	// Links ссылки задачи; nil при обновлении оставляет ссылки без изменений, пустой список удаляет их
+	Links []TaskLink `json:"links,omitempty" db:"links"`
+	// Tags теги задачи в нижнем регистре; nil при обновлении оставляет теги без изменений, пустой список удаляет их.
+	// Правила автотегирования только добавляют теги, поставленные вручную не снимаются
+	Tags     []string `json:"tags,omitempty" db:"tags" example:"finance"`
+	Status   Status   `json:"status" db:"status"`
+	Priority Priority `json:"priority" db:"priority"`
+	UserID   string   `json:"user_id" db:"user_id"`
+	// ParentID родительская задача того же пользователя; nil при обновлении оставляет родителя без изменений,
+	// пустая строка делает задачу задачей верхнего уровня
+	ParentID *string `json:"parent_id,omitempty" db:"parent_id"`
+	// ProjectID проект пользователя; nil при обновлении оставляет проект без изменений,
+	// пустая строка убирает задачу из проекта
+	ProjectID *string `json:"project_id,omitempty" db:"project_id"`
+	// AssigneeID исполнитель, которому владелец назначил задачу; меняется только через назначение
+	AssigneeID *string `json:"assignee_id,omitempty" db:"assignee_id"`
+	// Recurrence правило повторения (RRULE), задается при создании и копируется в следующие экземпляры
+	Recurrence string `json:"recurrence,omitempty" db:"recurrence" example:"FREQ=WEEKLY;BYDAY=MO"`
+	// RecurrenceID серия, к которой относится задача; назначается сервером
+	RecurrenceID *string    `json:"recurrence_id,omitempty" db:"recurrence_id"`
+	DueDate      time.Time  `json:"due_date" db:"due_date"`
+	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at" db:"updated_at"`
+	CompletedAt  *time.Time `json:"completed_at,omitempty" db:"completed_at"`
+	// Private заголовок и описание хранятся зашифрованными ключом владельца
+	Private bool `json:"private" db:"private"`
+	// Locked приватная задача отдана без расшифровки, для просмотра нужен unlock
+	Locked bool `json:"locked,omitempty" db:"-"`
+	// IsOverdue задача не выполнена и срок прошел; сервис отмечает его по своим часам при отдаче задачи,
+	// в запросах игнорируется
+	IsOverdue bool `json:"is_overdue" db:"-"`
+	// Related связанные задачи, заполняется только при expand=links
+	Related []TaskSummary `json:"related,omitempty" db:"-"`
+}
+
+// Overdue задача просрочена на момент now: не выполнена и срок прошел. То же условие проверяют
+// фильтр status=overdue и счетчик просроченных задач аналитики в запросах к БД
+func (t Task) Overdue(now time.Time) bool {
+	return t.Status != StatusDone && t.DueDate.Before(now)
+}
+
+// UpdateTaskRequest частичное обновление задачи через PATCH: меняются только переданные поля.
+// Отсутствующее поле и null оставляют значение как есть; пустая строка в description и notes
+// очищает их, пустой список links удаляет ссылки
+type UpdateTaskRequest struct {
+	Title       *string     `json:"title,omitempty" example:"Write report"`
+	Description *string     `json:"description,omitempty"`
+	Notes       *string     `json:"notes,omitempty"`
+	Links       *[]TaskLink `json:"links,omitempty"`
+	Tags        *[]string   `json:"tags,omitempty"`
+	Status      *Status     `json:"status,omitempty" example:"in_progress"`
+	Priority    *Priority   `json:"priority,omitempty" example:"high"`
+	DueDate     *time.Time  `json:"due_date,omitempty"`
+	// Private включает шифрование; снять его с приватной задачи нельзя
+	Private *bool `json:"private,omitempty"`
+	// ParentID переносит задачу под другую родительскую, пустая строка отвязывает ее от родителя
+	ParentID *string `json:"parent_id,omitempty"`
+	// ProjectID переносит задачу в другой проект, пустая строка убирает ее из проекта
+	ProjectID *string `json:"project_id,omitempty"`
+	// CompleteSubtasks при переводе в done выполняет и все открытые подзадачи; без него
+	// задачу с открытыми подзадачами выполнить нельзя
+	CompleteSubtasks bool `json:"complete_subtasks,omitempty"`
+}
+
+// TaskSummary краткое представление связанной задачи
+type TaskSummary struct {
+	ID       string    `json:"id"`
+	Title    string    `json:"title"`
+	Status   Status    `json:"status"`
+	Priority Priority  `json:"priority"`
+	DueDate  time.Time `json:"due_date"`
+	Private  bool      `json:"private"`
+	Locked   bool      `json:"locked,omitempty"`
+}
+
+// AssignTaskRequest запрос на назначение задачи исполнителю
+type AssignTaskRequest struct {
+	AssigneeID string `json:"assignee_id" binding:"required" example:"3c1e8f2a-9b4d-4e7a-a5c6-2d8f0b1e4a7c"`
+}
+
+// RelateTaskRequest запрос на связывание задач
+type RelateTaskRequest struct {
+	TaskID string `json:"task_id" binding:"required" example:"7f0c2a4e-1b9d-4c55-9f8e-3a2d1e6b5c4f"`
+}
+
+// Summary краткое представление задачи
+func (t Task) Summary() TaskSummary {
+	return TaskSummary{
+		ID:       t.ID,
+		Title:    t.Title,
+		Status:   t.Status,
+		Priority: t.Priority,
+		DueDate:  t.DueDate,
+		Private:  t.Private,
+		Locked:   t.Locked,
+	}
+}
+
+// TaskLink ссылка, прикрепленная к задаче
+type TaskLink struct {
+	URL   string `json:"url" example:"https://example.com/spec"`
+	Title string `json:"title,omitempty" example:"Specification"`
 }
 
 // TaskFilters представляет фильтры для запросов к задачам
@@ -93,6 +210,83 @@ type TaskFilters struct {
 	DueDate  *time.Time
 	UserID   string
 	Search   string
+	// Terms подстроки, каждая из которых должна встретиться в названии или описании
+	Terms []string
+	// Tag только задачи с этим тегом
+	Tag  string
+	Sort TaskSort
+	// ProjectID только задачи проекта
+	ProjectID string
+	// DueFrom и DueBefore окно срока [DueFrom, DueBefore), любая из границ может отсутствовать
+	DueFrom   *time.Time
+	DueBefore *time.Time
+	// Open только невыполненные задачи
+	Open bool
+	// Limit и Offset страница списка, Limit 0 — без ограничения. Count их не учитывает
+	Limit  int
+	Offset int
+	// After курсор постраничной выдачи в порядке SortDue: только задачи после него. Count его не учитывает
+	After *TaskCursor
+	// Assigned задачи других владельцев, назначенные пользователю UserID, вместо его собственных
+	Assigned bool
+	// Overdue только просроченные задачи (status=overdue), условие вычисляется в запросе к БД
+	Overdue bool
+}
+
+// TaskCursor позиция в списке, упорядоченном по сроку и ID. В отличие от смещения не сдвигается,
+// когда перед ней добавляются или удаляются задачи
+type TaskCursor struct {
+	DueDate time.Time
+	ID      string
+}
+
+// Encode курсор для параметра cursor. Клиент передает значение как есть, не разбирая его
+func (c TaskCursor) Encode() string {
+	return base64.RawURLEncoding.EncodeToString([]byte(c.DueDate.UTC().Format(time.RFC3339Nano) + " " + c.ID))
+}
+
+// ParseTaskCursor разбирает курсор, полученный от Encode
+func ParseTaskCursor(value string) (TaskCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return TaskCursor{}, fmt.Errorf("invalid task cursor: %w", err)
+	}
+	due, id, ok := strings.Cut(string(data), " ")
+	if !ok || id == "" {
+		return TaskCursor{}, fmt.Errorf("invalid task cursor")
+	}
+	dueDate, err := time.Parse(time.RFC3339Nano, due)
+	if err != nil {
+		return TaskCursor{}, fmt.Errorf("invalid task cursor: %w", err)
+	}
+
+	return TaskCursor{DueDate: dueDate, ID: id}, nil
+}
+
+// TaskPage страница списка задач: ответ GET /api/tasks начиная с третьей версии API
+type TaskPage struct {
+	Tasks []Task `json:"tasks"`
+	// Total число задач, подходящих под фильтры, на всех страницах
+	Total int `json:"total"`
+	// NextCursor значение cursor для следующей страницы, у последней страницы отсутствует
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// TaskSort порядок выдачи списка задач
+type TaskSort string
+
+const (
+	// SortDefault по сроку, затем по приоритету
+	SortDefault TaskSort = ""
+	// SortSmart по оценке срочности: приоритет, близость срока и возраст задачи
+	SortSmart TaskSort = "smart"
+	// SortDue по сроку, затем по ID — порядок постраничной выдачи по курсору
+	SortDue TaskSort = "due"
+)
+
+// Valid проверяет, что порядок сортировки поддерживается
+func (s TaskSort) Valid() bool {
+	return s == SortDefault || s == SortSmart || s == SortDue
 }
 
 // Analytics представляет аналитические данные по задачам
@@ -112,9 +306,100 @@ type Analytics struct {
 	// Текущее количество просроченных задач
 	OverdueTasks int `json:"overdue_tasks"`
 
-	// Период, за который собрана аналитика
+	// Период, за который собрана аналитика: day, week, month или custom для окна from–to
 	Period string `json:"period"`
 
+	// Границы окна [from, to), только для period=custom
+	From *time.Time `json:"from,omitempty"`
+	To   *time.Time `json:"to,omitempty"`
+
 	// Дата и время формирования отчета
 	GeneratedAt time.Time `json:"generated_at"`
 }
+
+// TaskStats агрегаты задач пользователя за окно аналитики [from, to), которые хранилище считает
+// без загрузки задач
+type TaskStats struct {
+	// StatusCount и PriorityCount задачи, созданные в окне
+	StatusCount   map[Status]int
+	PriorityCount map[Priority]int
+	// Completed задачи, выполненные в окне, CompletedOnTime — из них выполненные до срока
+	Completed       int
+	CompletedOnTime int
+	// AvgCompletionHours среднее время от создания до выполнения задач Completed в часах
+	AvgCompletionHours float64
+	// Overdue задачи, просроченные на текущий момент, независимо от окна
+	Overdue int
+}
+
+// AnalyticsSnapshot снимок аналитики пользователя за день
+type AnalyticsSnapshot struct {
+	UserID string `json:"-" db:"user_id"`
+
+	// День снимка, снимок за текущий день обновляется в течение дня
+	Date time.Time `json:"date" db:"snapshot_date"`
+
+	Analytics Analytics `json:"analytics" db:"analytics"`
+}
+
+// Dashboard сводные счетчики задач пользователя
+type Dashboard struct {
+	// Общее количество задач
+	Total int `json:"total"`
+
+	// Количество задач по статусам
+	StatusCount map[Status]int `json:"status_count"`
+
+	// Количество задач со сроком на сегодня
+	DueToday int `json:"due_today"`
+
+	// Количество просроченных задач
+	Overdue int `json:"overdue"`
+
+	// Дата и время формирования сводки
+	GeneratedAt time.Time `json:"generated_at"`
+}
+
+// CompleteTasksRequest запрос пакетного выполнения задач
+type CompleteTasksRequest struct {
+	IDs []string `json:"ids" binding:"required" example:"7f0c2a4e-1b9d-4c55-9f8e-3a2d1e6b5c4f"`
+}
+
+// CompleteTasksResult результат пакетного выполнения задач
+type CompleteTasksResult struct {
+	// Задачи, переведенные в статус done
+	Completed []Task `json:"completed"`
+
+	// ID задач, которые уже были выполнены
+	Skipped []string `json:"skipped"`
+}
+
+// TaskDeleteFilter условия массового удаления задач пользователя UserID, в том числе архивных.
+// Пустые поля не ограничивают выборку
+type TaskDeleteFilter struct {
+	UserID    string
+	Status    Status
+	Priority  Priority
+	ProjectID string
+	// CompletedBefore только задачи, выполненные раньше этого момента
+	CompletedBefore *time.Time
+	// CreatedBefore только задачи, созданные раньше этого момента
+	CreatedBefore *time.Time
+}
+
+// Empty кроме владельца не задано ни одного условия: такой фильтр удалил бы все задачи
+func (f TaskDeleteFilter) Empty() bool {
+	return f.Status == "" && f.Priority == "" && f.ProjectID == "" && f.CompletedBefore == nil && f.CreatedBefore == nil
+}
+
+// BulkDeleteProgress ход массового удаления задач: отправляется после каждой пачки и в конце
+type BulkDeleteProgress struct {
+	// Deleted сколько задач удалено с начала запроса
+	Deleted int `json:"deleted" example:"1500"`
+	// Batches сколько пачек удалено
+	Batches int `json:"batches" example:"3"`
+	// Done удаление завершено, подходящих задач не осталось
+	Done bool `json:"done"`
+	// Error причина остановки удаления в потоке NDJSON; удаленные до нее задачи не восстанавливаются
+	Error string `json:"error,omitempty"`
+}
@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+// TransferDirection направление переноса данных
+type TransferDirection string
+
+const (
+	TransferImport TransferDirection = "import"
+	TransferExport TransferDirection = "export"
+)
+
+// TransferStatus результат переноса данных
+type TransferStatus string
+
+const (
+	TransferCompleted TransferStatus = "completed"
+	TransferFailed    TransferStatus = "failed"
+)
+
+// DataTransfer запись истории импорта или экспорта задач
+type DataTransfer struct {
+	ID        string            `json:"id" db:"id"`
+	UserID    string            `json:"-" db:"user_id"`
+	Direction TransferDirection `json:"direction" db:"direction" example:"import"`
+	Format    string            `json:"format" db:"format" example:"json"`
+	Status    TransferStatus    `json:"status" db:"status" example:"completed"`
+	// Items число задач в запросе импорта или в выгрузке
+	Items int `json:"items" db:"item_count"`
+	// DurationMs длительность операции в миллисекундах
+	DurationMs int64 `json:"duration_ms" db:"duration_ms"`
+	// Error текст ошибки неудачной операции
+	Error     string    `json:"error,omitempty" db:"error"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
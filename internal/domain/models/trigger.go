@@ -0,0 +1,68 @@
+package models
+
+import "time"
+
+// TriggerActionType тип действия триггера
+type TriggerActionType string
+
+const (
+	TriggerActionWebhook TriggerActionType = "webhook"
+	TriggerActionSlack   TriggerActionType = "slack"
+	TriggerActionEmail   TriggerActionType = "email"
+)
+
+// WebhookSecretGrace сколько после ротации запросы подписываются и предыдущим секретом
+const WebhookSecretGrace = 24 * time.Hour
+
+// TriggerAction действие, выполняемое при срабатывании триггера.
+// Target — URL для webhook и Slack incoming webhook, адрес получателя для email
+type TriggerAction struct {
+	Type   TriggerActionType `json:"type" example:"webhook"`
+	Target string            `json:"target" example:"https://example.com/hooks/tasks"`
+}
+
+// Trigger пользовательское правило автоматизации: событие, фильтр и действие
+type Trigger struct {
+	ID     string    `json:"id" db:"id"`
+	UserID string    `json:"user_id" db:"user_id"`
+	On     EventType `json:"on" db:"event" example:"task.completed"`
+	// Filter выражение вида `priority == high && title ~ "report"`, пустой фильтр пропускает все события
+	Filter    string        `json:"filter" db:"filter" example:"priority == high"`
+	Action    TriggerAction `json:"action"`
+	Enabled   bool          `json:"enabled" db:"enabled"`
+	CreatedAt time.Time     `json:"created_at" db:"created_at"`
+
+	// Secret ключ HMAC-подписи webhook, возвращается только при создании триггера
+	Secret string `json:"secret,omitempty" db:"secret" example:"whsec_3f1c2b4e8d9a4c1e9f2b7a6d5e4c3b2a"`
+	// PreviousSecret ключ до последней ротации, действует WebhookSecretGrace после SecretRotatedAt
+	PreviousSecret  string     `json:"-" db:"previous_secret"`
+	SecretRotatedAt *time.Time `json:"secret_rotated_at,omitempty" db:"secret_rotated_at"`
+}
+
+// SigningSecrets действующие ключи подписи webhook на момент now: текущий и, в течение
+// WebhookSecretGrace после ротации, предыдущий
+func (t Trigger) SigningSecrets(now time.Time) []string {
+	if t.Secret == "" {
+		return nil
+	}
+	secrets := []string{t.Secret}
+	if t.PreviousSecret != "" && t.SecretRotatedAt != nil && now.Before(t.SecretRotatedAt.Add(WebhookSecretGrace)) {
+		secrets = append(secrets, t.PreviousSecret)
+	}
+	return secrets
+}
+
+// WebhookSecret новый ключ подписи после ротации
+type WebhookSecret struct {
+	Secret string `json:"secret" example:"whsec_3f1c2b4e8d9a4c1e9f2b7a6d5e4c3b2a"`
+	// PreviousSecretExpiresAt до этого момента запросы подписываются и предыдущим ключом
+	PreviousSecretExpiresAt time.Time `json:"previous_secret_expires_at"`
+}
+
+// TriggerRequest запрос на создание триггера
+type TriggerRequest struct {
+	On      EventType     `json:"on" example:"task.completed"`
+	Filter  string        `json:"filter" example:"priority == high"`
+	Action  TriggerAction `json:"action"`
+	Enabled *bool         `json:"enabled,omitempty"`
+}
@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// UsageCount число запросов пользователя за день
+type UsageCount struct {
+	UserID string    `json:"user_id" db:"user_id"`
+	Day    time.Time `json:"day" db:"usage_date"`
+	// Requests все запросы, Errors — завершившиеся ответом 4xx или 5xx
+	Requests int64 `json:"requests" db:"requests"`
+	Errors   int64 `json:"errors" db:"errors"`
+}
+
+// UserUsage суммарное использование API пользователем за период
+type UserUsage struct {
+	UserID   string `json:"user_id" example:"3f1c2b4e-8d9a-4c1e-9f2b-7a6d5e4c3b2a"`
+	Email    string `json:"email" example:"user@example.com"`
+	Requests int64  `json:"requests" example:"12840"`
+	Errors   int64  `json:"errors" example:"312"`
+	// ErrorRate доля ответов с ошибкой, от 0 до 1
+	ErrorRate float64 `json:"error_rate" example:"0.024"`
+}
+
+// UsageReport самые активные пользователи API за последние Days дней
+type UsageReport struct {
+	Days  int         `json:"days" example:"7"`
+	Users []UserUsage `json:"users"`
+}
@@ -3,11 +3,16 @@ package models
 import "time"
 
 type User struct {
-	ID           string    `json:"id" db:"id"`
-	Email        string    `json:"email" db:"email"`
-	PasswordHash string    `json:"-" db:"password_hash"`
-	CreatedAt    time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at" db:"updated_at"`
+	ID           string `json:"id" db:"id"`
+	Email        string `json:"email" db:"email"`
+	PasswordHash string `json:"-" db:"password_hash"`
+	// Role RoleUser или RoleAdmin, попадает в токен доступа
+	Role string `json:"role" db:"role" example:"user"`
+	// Active false — учетная запись деактивирована администратором: вход и токены отклоняются
+	Active        bool       `json:"active" db:"active"`
+	DeactivatedAt *time.Time `json:"deactivated_at,omitempty" db:"deactivated_at"`
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at" db:"updated_at"`
 }
 
 type LoginRequest struct {
@@ -19,3 +24,55 @@ type RegisterRequest struct {
 	Email    string `json:"email" validate:"required,email"`
 	Password string `json:"password" validate:"required,min=6"`
 }
+
+// ChangePasswordRequest смена пароля пользователем
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"required"`
+}
+
+// TokenTypeBearer тип токена доступа в ответе на вход
+const TokenTypeBearer = "Bearer"
+
+// UserProfile минимальные сведения о пользователе для клиента
+type UserProfile struct {
+	ID        string    `json:"id" example:"7f3c2a9e-1b4d-4c8a-9e2f-5a6b7c8d9e0f"`
+	Email     string    `json:"email" example:"user@example.com"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// LoginResponse ответ на успешный вход. Срок действия токена отдается явно,
+// чтобы клиенту не приходилось разбирать JWT. RefreshToken пока не выдается
+type LoginResponse struct {
+	Token        string      `json:"token"`
+	TokenType    string      `json:"token_type" example:"Bearer"`
+	ExpiresAt    time.Time   `json:"expires_at"`
+	ExpiresIn    int         `json:"expires_in" example:"900"`
+	RefreshToken string      `json:"refresh_token,omitempty"`
+	User         UserProfile `json:"user"`
+}
+
+// Роли пользователя. RoleAdmin дает доступ к административному API
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
+// ValidRole проверяет, что роль известна
+func ValidRole(role string) bool {
+	return role == RoleUser || role == RoleAdmin
+}
+
+// SetRoleRequest смена роли пользователя администратором
+type SetRoleRequest struct {
+	Role string `json:"role" binding:"required" example:"admin"`
+}
+
+// WhoAmI сведения о текущем пользователе для начальной загрузки клиента
+type WhoAmI struct {
+	ID             string    `json:"id" example:"7f3c2a9e-1b4d-4c8a-9e2f-5a6b7c8d9e0f"`
+	Email          string    `json:"email" example:"user@example.com"`
+	Roles          []string  `json:"roles" example:"user,admin"`
+	ImpersonatorID string    `json:"impersonator_id,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+}
@@ -0,0 +1,49 @@
+package repository
+
+// Capability возможность хранилища задач, которая есть не у всех реализаций.
+// По ним сборка приложения отключает зависящие функции заранее, а не ошибками во время запросов
+type Capability string
+
+const (
+	// CapabilityForeignKeys задачи лежат в таблице tasks Postgres, на которую внешними ключами ссылаются
+	// таблицы подсистем: результаты AI, эмбеддинги, ссылки на внешние трекеры
+	CapabilityForeignKeys Capability = "foreign_keys"
+	// CapabilityVectorSearch поиск похожих задач по эмбеддингам (pgvector)
+	CapabilityVectorSearch Capability = "vector_search"
+	// CapabilityArchive перенос давно выполненных задач в архивную таблицу
+	CapabilityArchive Capability = "archive"
+	// CapabilityChangeNotifications об изменениях задач узнают все экземпляры приложения (LISTEN/NOTIFY),
+	// поэтому хранилище может быть общим для нескольких экземпляров
+	CapabilityChangeNotifications Capability = "change_notifications"
+)
+
+// Capabilities все возможности, которые проверяет приложение
+var Capabilities = []Capability{
+	CapabilityForeignKeys,
+	CapabilityVectorSearch,
+	CapabilityArchive,
+	CapabilityChangeNotifications,
+}
+
+// CapabilityReporter хранилище, сообщающее о своих возможностях
+type CapabilityReporter interface {
+	Supports(capability Capability) bool
+}
+
+// Supports проверяет возможность хранилища. Хранилище без CapabilityReporter (например, мок в тестах)
+// считается поддерживающим все
+func Supports(repo interface{}, capability Capability) bool {
+	reporter, ok := repo.(CapabilityReporter)
+	return !ok || reporter.Supports(capability)
+}
+
+// Unsupported возможности из Capabilities, которых у хранилища нет
+func Unsupported(repo interface{}) []Capability {
+	var missing []Capability
+	for _, capability := range Capabilities {
+		if !Supports(repo, capability) {
+			missing = append(missing, capability)
+		}
+	}
+	return missing
+}
@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type archiveOnly struct{}
+
+func (archiveOnly) Supports(capability Capability) bool { return capability == CapabilityArchive }
+
+func TestSupports(t *testing.T) {
+	assert.True(t, Supports(archiveOnly{}, CapabilityArchive))
+	assert.False(t, Supports(archiveOnly{}, CapabilityVectorSearch))
+	// хранилище, не сообщающее о возможностях, поддерживает все
+	assert.True(t, Supports(struct{}{}, CapabilityVectorSearch))
+
+	assert.Equal(t, []Capability{CapabilityForeignKeys, CapabilityVectorSearch, CapabilityChangeNotifications}, Unsupported(archiveOnly{}))
+	assert.Empty(t, Unsupported(struct{}{}))
+}
@@ -2,14 +2,49 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"github.com/jmoloko/taskmange/internal/domain/models"
 )
 
+// ErrNotFound возвращается репозиториями, если запись не найдена
+var ErrNotFound = errors.New("not found")
+
+// ErrAlreadyExists возвращается репозиториями, если запись с таким уникальным ключом уже есть
+var ErrAlreadyExists = errors.New("already exists")
+
+// ErrInvalidReference запись ссылается на несуществующую запись другой таблицы
+var ErrInvalidReference = errors.New("referenced record does not exist")
+
+// ErrInvalidValue значение не проходит ограничение базы: CHECK, NOT NULL, перечисление или длина
+var ErrInvalidValue = errors.New("invalid value")
+
+// ConstraintError нарушение ограничения базы данных. Err — ErrAlreadyExists, ErrInvalidReference
+// или ErrInvalidValue, поэтому errors.Is работает как с обычными ошибками репозитория.
+// Field — поле модели, к которому относится ограничение, если его удалось определить
+type ConstraintError struct {
+	Err        error
+	Constraint string
+	Field      string
+}
+
+func (e *ConstraintError) Error() string {
+	if e.Field == "" {
+		return e.Err.Error()
+	}
+	return e.Field + ": " + e.Err.Error()
+}
+
+func (e *ConstraintError) Unwrap() error {
+	return e.Err
+}
+
 // TaskCreator создание задач
 type TaskCreator interface {
 	Create(ctx context.Context, task *models.Task) error
+	// CreateBatch создает задачи одной транзакцией, для импорта больших файлов
+	CreateBatch(ctx context.Context, tasks []models.Task) error
 }
 
 // TaskReader чтение задач
@@ -18,22 +53,98 @@ type TaskReader interface {
 	GetAll(ctx context.Context, filters models.TaskFilters) ([]models.Task, error)
 }
 
+// TaskActivityReader выборка пользователей по активности задач
+type TaskActivityReader interface {
+	// GetUsersWithTasksUpdatedSince пользователи, у которых задачи создавались или менялись начиная с t
+	GetUsersWithTasksUpdatedSince(ctx context.Context, t time.Time) ([]string, error)
+}
+
+// TaskCounter подсчет задач без загрузки строк
+type TaskCounter interface {
+	Count(ctx context.Context, filters models.TaskFilters) (int, error)
+}
+
+// TaskStatsReader агрегаты задач для аналитики
+type TaskStatsReader interface {
+	// GetTaskStats агрегаты задач пользователя за окно [from, to): счетчики по статусам и приоритетам
+	// созданных задач, время выполнения выполненных и число просроченных. Непустой projectID
+	// ограничивает агрегаты задачами проекта
+	GetTaskStats(ctx context.Context, userID, projectID string, from, to time.Time) (models.TaskStats, error)
+}
+
 // TaskUpdater обновление задач
 type TaskUpdater interface {
 	Update(ctx context.Context, task *models.Task) error
+	// CompleteTasks переводит задачи пользователя в статус done одним UPDATE в транзакции и возвращает
+	// измененные задачи, уже выполненные не меняются. ErrNotFound, если хотя бы одной задачи у пользователя нет
+	CompleteTasks(ctx context.Context, userID string, ids []string) ([]models.Task, error)
+	// SetAssignee назначает задачу исполнителю, nil снимает назначение
+	SetAssignee(ctx context.Context, taskID string, assigneeID *string) error
 }
 
 // TaskDeleter удаление задач
 type TaskDeleter interface {
 	Delete(ctx context.Context, id string) error
+	// DeleteUserTasks удаляет все задачи пользователя и возвращает их ID
+	DeleteUserTasks(ctx context.Context, userID string) ([]string, error)
+	// DeleteTasks удаляет до limit задач, подходящих под фильтр, и возвращает удаленные задачи.
+	// Меньше limit задач значит, что подходящих задач больше нет
+	DeleteTasks(ctx context.Context, filter models.TaskDeleteFilter, limit int) ([]models.Task, error)
+}
+
+// TaskRelationRepository связи "related to" между задачами.
+// Связь симметрична: порядок taskID и relatedID не важен
+type TaskRelationRepository interface {
+	AddRelation(ctx context.Context, taskID, relatedID string) error
+	// RemoveRelation возвращает ErrNotFound, если связи нет
+	RemoveRelation(ctx context.Context, taskID, relatedID string) error
+	// GetRelated связанные задачи для каждой из taskIDs
+	GetRelated(ctx context.Context, taskIDs []string) (map[string][]models.Task, error)
+}
+
+// TaskHierarchyRepository подзадачи: задача ссылается на родительскую через ParentID
+type TaskHierarchyRepository interface {
+	// GetSubtasks прямые подзадачи, открытые первыми
+	GetSubtasks(ctx context.Context, parentID string) ([]models.Task, error)
+	// GetAncestorIDs ID предков задачи от родителя к корню
+	GetAncestorIDs(ctx context.Context, taskID string) ([]string, error)
+	// GetOpenDescendantIDs ID невыполненных подзадач всех уровней для любой из taskIDs
+	GetOpenDescendantIDs(ctx context.Context, taskIDs []string) ([]string, error)
+}
+
+// TaskRecurrenceRepository серии повторяющихся задач. Серия создается вместе с первым экземпляром в Create
+type TaskRecurrenceRepository interface {
+	// GetRecurrence возвращает ErrNotFound, если серии нет
+	GetRecurrence(ctx context.Context, id string) (*models.Recurrence, error)
+	SetRecurrencePaused(ctx context.Context, id string, paused bool) error
+	// GetDueOccurrences последние экземпляры активных серий, выполненные или со сроком раньше now
+	GetDueOccurrences(ctx context.Context, now time.Time, limit int) ([]models.DueOccurrence, error)
+	// CreateOccurrence создает next копией sourceID с новыми ID и сроком и делает next последним экземпляром.
+	// ErrNotFound — sourceID уже не последний экземпляр (следующий создан другим экземпляром приложения)
+	CreateOccurrence(ctx context.Context, sourceID string, next *models.Task) error
+	// EndRecurrence завершает серию: новые экземпляры больше не создаются
+	EndRecurrence(ctx context.Context, id string) error
+}
+
+// ProjectReader чтение проекта по ID, для проверки проекта задачи
+type ProjectReader interface {
+	// GetProject возвращает ErrNotFound, если проекта нет
+	GetProject(ctx context.Context, id string) (*models.Project, error)
 }
 
 // TaskRepository объединяет все операции с задачами (для обратной совместимости)
 type TaskRepository interface {
 	TaskCreator
 	TaskReader
+	TaskActivityReader
+	TaskCounter
+	TaskStatsReader
 	TaskUpdater
 	TaskDeleter
+	TaskRelationRepository
+	TaskHierarchyRepository
+	TaskRecurrenceRepository
+	ProjectReader
 }
 
 // UserCreator создание пользователя
@@ -47,10 +158,302 @@ type UserReader interface {
 	GetByEmail(ctx context.Context, email string) (*models.User, error)
 }
 
+// UserPasswordUpdater смена пароля пользователя
+type UserPasswordUpdater interface {
+	UpdatePassword(ctx context.Context, id, passwordHash string) error
+}
+
+// UserPasswordHistory прежние пароли пользователя для запрета их повторного использования
+type UserPasswordHistory interface {
+	// PasswordHistory последние limit прежних хэшей пароля, от новых к старым
+	PasswordHistory(ctx context.Context, userID string, limit int) ([]string, error)
+	// ChangePassword меняет хэш пароля, переносит прежний в историю и оставляет в ней keep последних записей
+	ChangePassword(ctx context.Context, userID, passwordHash string, keep int) error
+}
+
+// UserStatusUpdater деактивация и повторная активация учетной записи
+type UserStatusUpdater interface {
+	// SetActive меняет признак активности, ErrNotFound — пользователя нет
+	SetActive(ctx context.Context, id string, active bool) error
+}
+
+// UserRoleUpdater смена роли пользователя
+type UserRoleUpdater interface {
+	// SetRole меняет роль, ErrNotFound — пользователя нет
+	SetRole(ctx context.Context, id, role string) error
+}
+
+// UserLister список пользователей для администратора
+type UserLister interface {
+	// ListUsers пользователи по дате регистрации; limit 0 — без ограничения
+	ListUsers(ctx context.Context, limit, offset int) ([]models.User, error)
+	CountUsers(ctx context.Context) (int, error)
+}
+
 // UserRepository объединяет все операции с пользователями (для обратной совместимости)
 type UserRepository interface {
 	UserCreator
 	UserReader
+	UserPasswordUpdater
+	UserPasswordHistory
+	UserStatusUpdater
+	UserRoleUpdater
+	UserLister
+}
+
+// PushSubscriptionRepository хранение подписок Web Push
+type PushSubscriptionRepository interface {
+	SavePushSubscription(ctx context.Context, sub *models.PushSubscription) error
+	DeletePushSubscription(ctx context.Context, userID, endpoint string) error
+	GetPushSubscriptions(ctx context.Context, userID string) ([]models.PushSubscription, error)
+}
+
+// NotificationPreferencesRepository хранение настроек уведомлений.
+// GetNotificationPreferences возвращает nil, если пользователь ничего не настраивал
+type NotificationPreferencesRepository interface {
+	GetNotificationPreferences(ctx context.Context, userID string) (*models.NotificationPreferences, error)
+	SaveNotificationPreferences(ctx context.Context, prefs *models.NotificationPreferences) error
+}
+
+// TaskReminderRepository выборка задач для напоминаний и учет отправленных
+type TaskReminderRepository interface {
+	GetDueSoonTasks(ctx context.Context, defaultWindow time.Duration) ([]models.Task, error)
+	MarkReminderSent(ctx context.Context, taskID string, dueDate time.Time) error
+	// GetOverdueTasks незавершенные задачи, срок которых прошел за последние сутки, без отправленного уведомления
+	GetOverdueTasks(ctx context.Context) ([]models.Task, error)
+	MarkOverdueAlertSent(ctx context.Context, taskID string, dueDate time.Time) error
+}
+
+// CalendarSyncRepository подключенные календари и согласованные сроки их событий
+type CalendarSyncRepository interface {
+	// CreateCalendarLink возвращает ErrAlreadyExists, если календарь уже подключен пользователем
+	CreateCalendarLink(ctx context.Context, link *models.CalendarLink) error
+	// GetCalendarLink возвращает ErrNotFound, если подключения нет
+	GetCalendarLink(ctx context.Context, id string) (*models.CalendarLink, error)
+	// GetCalendarLinkByChannel подключение по ID канала уведомлений, ErrNotFound — канала нет
+	GetCalendarLinkByChannel(ctx context.Context, channelID string) (*models.CalendarLink, error)
+	GetCalendarLinks(ctx context.Context, userID string) ([]models.CalendarLink, error)
+	DeleteCalendarLink(ctx context.Context, userID, id string) error
+	// GetCalendarLinksToSync подключения, не синхронизированные с syncedBefore, сначала самые давние
+	GetCalendarLinksToSync(ctx context.Context, syncedBefore time.Time, limit int) ([]models.CalendarLink, error)
+	// UpdateCalendarSync сохраняет позицию в журнале изменений и результат синхронизации
+	UpdateCalendarSync(ctx context.Context, linkID, syncToken string, syncedAt time.Time, lastError string) error
+	UpdateCalendarChannel(ctx context.Context, linkID string, channel models.CalendarChannel) error
+	// GetCalendarSyncState возвращает ErrNotFound, если задача не связана с событием календаря
+	GetCalendarSyncState(ctx context.Context, linkID, taskID string) (*models.CalendarSyncState, error)
+	// GetCalendarSyncStateByEvent возвращает ErrNotFound, если событие создано не синхронизацией
+	GetCalendarSyncStateByEvent(ctx context.Context, linkID, eventID string) (*models.CalendarSyncState, error)
+	// SaveCalendarSyncState создает или заменяет состояние задачи
+	SaveCalendarSyncState(ctx context.Context, state models.CalendarSyncState) error
+	DeleteCalendarSyncState(ctx context.Context, linkID, taskID string) error
+}
+
+// TelegramLinkRepository привязки чатов Telegram
+type TelegramLinkRepository interface {
+	// SaveTelegramLink привязывает чат, повторная привязка заменяет чат и время сводки
+	SaveTelegramLink(ctx context.Context, link *models.TelegramLink) error
+	// GetTelegramLink возвращает nil, если чат не привязан
+	GetTelegramLink(ctx context.Context, userID string) (*models.TelegramLink, error)
+	// DeleteTelegramLink возвращает ErrNotFound, если чат не привязан
+	DeleteTelegramLink(ctx context.Context, userID string) error
+	// GetTelegramDigestLinks привязки с включенной сводкой вместе с часовым поясом пользователя
+	GetTelegramDigestLinks(ctx context.Context) ([]models.TelegramLink, error)
+	// MarkTelegramDigestSent запоминает локальную дату отправленной сводки
+	MarkTelegramDigestSent(ctx context.Context, userID string, day time.Time) error
+}
+
+// CalendarFeedRepository версии ссылок подписки календаря
+type CalendarFeedRepository interface {
+	// RotateCalendarFeed создает подписку или увеличивает ее версию и возвращает новую версию
+	RotateCalendarFeed(ctx context.Context, userID string) (int, time.Time, error)
+	// GetCalendarFeedVersion возвращает 0, если подписки нет
+	GetCalendarFeedVersion(ctx context.Context, userID string) (int, error)
+	// DeleteCalendarFeed возвращает ErrNotFound, если подписки нет
+	DeleteCalendarFeed(ctx context.Context, userID string) error
+}
+
+// NotificationBuffer буфер уведомлений, собираемых в дайджест
+type NotificationBuffer interface {
+	// AddNotification добавляет уведомление; flushAt задает конец окна только для первого уведомления в окне
+	AddNotification(ctx context.Context, userID string, notification models.Notification, flushAt time.Time) error
+	// DueNotificationUsers пользователи, окно которых закончилось к моменту now
+	DueNotificationUsers(ctx context.Context, now time.Time) ([]string, error)
+	// PopNotifications забирает накопленные уведомления пользователя и закрывает окно
+	PopNotifications(ctx context.Context, userID string) ([]models.Notification, error)
+	// NotificationBacklog число пользователей с открытым окном и из них тех, чье окно закончилось к моменту now
+	NotificationBacklog(ctx context.Context, now time.Time) (pending, overdue int64, err error)
+}
+
+// TriggerRepository хранение пользовательских триггеров
+type TriggerRepository interface {
+	CreateTrigger(ctx context.Context, trigger *models.Trigger) error
+	DeleteTrigger(ctx context.Context, userID, triggerID string) error
+	GetTriggers(ctx context.Context, userID string) ([]models.Trigger, error)
+	// GetEnabledTriggers включенные триггеры пользователя на событие
+	GetEnabledTriggers(ctx context.Context, userID string, event models.EventType) ([]models.Trigger, error)
+	// RotateTriggerSecret заменяет секрет webhook-триггера, текущий секрет становится предыдущим
+	RotateTriggerSecret(ctx context.Context, userID, triggerID, secret string, rotatedAt time.Time) error
+}
+
+// TaggingRuleRepository хранение правил автотегирования
+type TaggingRuleRepository interface {
+	CreateTaggingRule(ctx context.Context, rule *models.TaggingRule) error
+	DeleteTaggingRule(ctx context.Context, userID, ruleID string) error
+	// GetTaggingRules правила пользователя в порядке создания
+	GetTaggingRules(ctx context.Context, userID string) ([]models.TaggingRule, error)
+}
+
+// SavedSearchRepository хранение сохраненных поисков и их совпадений
+type SavedSearchRepository interface {
+	CreateSavedSearch(ctx context.Context, search *models.SavedSearch) error
+	// UpdateSavedSearch меняет поиск пользователя, ErrNotFound — поиска нет или он чужой.
+	// Смена запроса забывает прежние совпадения
+	UpdateSavedSearch(ctx context.Context, search *models.SavedSearch) error
+	DeleteSavedSearch(ctx context.Context, userID, searchID string) error
+	// GetSavedSearches поиски пользователя в порядке создания
+	GetSavedSearches(ctx context.Context, userID string) ([]models.SavedSearch, error)
+	// GetAlertSearches поиски пользователя с включенным оповещением
+	GetAlertSearches(ctx context.Context, userID string) ([]models.SavedSearch, error)
+	// RecordSearchMatch запоминает, что задача подошла под поиск; false — это уже было
+	RecordSearchMatch(ctx context.Context, searchID, taskID string) (bool, error)
+	// ClaimSearchAlert отмечает отправку оповещения, если с прошлой прошло не меньше interval, и возвращает
+	// число совпадений, накопленных с прошлой отправки. Иначе увеличивает это число и возвращает false
+	ClaimSearchAlert(ctx context.Context, searchID string, interval time.Duration, now time.Time) (claimed bool, suppressed int, err error)
+}
+
+// TaskAIRepository результаты AI-ассистента, сохраненные на задаче
+type TaskAIRepository interface {
+	// GetAIResult возвращает ErrNotFound, если результата действия нет
+	GetAIResult(ctx context.Context, taskID string, action models.AIAction) (*models.AIResult, error)
+	// SaveAIResult заменяет результат того же действия
+	SaveAIResult(ctx context.Context, taskID string, result *models.AIResult) error
+}
+
+// AnalyticsSnapshotRepository хранение ежедневных снимков аналитики
+type AnalyticsSnapshotRepository interface {
+	// SaveAnalyticsSnapshot сохраняет снимок, снимок за тот же день перезаписывается
+	SaveAnalyticsSnapshot(ctx context.Context, snapshot *models.AnalyticsSnapshot) error
+	// GetAnalyticsSnapshots снимки пользователя с from по to включительно, по возрастанию даты
+	GetAnalyticsSnapshots(ctx context.Context, userID string, from, to time.Time) ([]models.AnalyticsSnapshot, error)
+}
+
+// TransferRepository история импорта и экспорта задач
+type TransferRepository interface {
+	SaveTransfer(ctx context.Context, transfer *models.DataTransfer) error
+	// GetTransfers последние limit записей пользователя, новые первыми
+	GetTransfers(ctx context.Context, userID string, limit int) ([]models.DataTransfer, error)
+}
+
+// UsageCounter счетчики запросов пользователей за текущие сутки (UTC)
+type UsageCounter interface {
+	// IncrementUsage учитывает запрос пользователя, failed — ответ с ошибкой
+	IncrementUsage(ctx context.Context, userID string, day time.Time, failed bool) error
+	// GetUsage счетчики всех пользователей за день
+	GetUsage(ctx context.Context, day time.Time) ([]models.UsageCount, error)
+}
+
+// UsageRepository ежедневная статистика использования API
+type UsageRepository interface {
+	// SaveUsage сохраняет счетчики, значения за тот же день перезаписываются
+	SaveUsage(ctx context.Context, usage []models.UsageCount) error
+	// GetTopUsage пользователи с наибольшим числом запросов начиная с дня since
+	GetTopUsage(ctx context.Context, since time.Time, limit int) ([]models.UserUsage, error)
+}
+
+// TaskArchiveRepository перенос давно выполненных задач в архивную таблицу.
+// Остальные методы TaskRepository читают и меняют архивные задачи прозрачно
+type TaskArchiveRepository interface {
+	// ArchiveTasks переносит до limit задач, выполненных раньше before, и возвращает их число
+	ArchiveTasks(ctx context.Context, before time.Time, limit int) (int, error)
+}
+
+// TaskChangeRepository журнал изменений задач для CDC
+type TaskChangeRepository interface {
+	// AppendTaskChanges записывает изменения, назначая им Seq и Version
+	AppendTaskChanges(ctx context.Context, changes []models.TaskChange) error
+	// GetTaskChanges до limit записей с Seq больше after по возрастанию Seq
+	GetTaskChanges(ctx context.Context, after int64, limit int) ([]models.TaskChange, error)
+	// DeleteTaskChanges удаляет записи, сделанные раньше before, и возвращает их число
+	DeleteTaskChanges(ctx context.Context, before time.Time) (int64, error)
+}
+
+// ImpersonationRepository сессии имперсонации
+type ImpersonationRepository interface {
+	CreateImpersonation(ctx context.Context, impersonation *models.Impersonation) error
+	// GetImpersonation возвращает ErrNotFound, если сессии нет
+	GetImpersonation(ctx context.Context, id string) (*models.Impersonation, error)
+	// RevokeImpersonation отзывает сессию, ErrNotFound — сессии нет или она уже отозвана
+	RevokeImpersonation(ctx context.Context, id string, revokedAt time.Time) error
+}
+
+// AuditRepository журнал аудита
+type AuditRepository interface {
+	SaveAuditEvent(ctx context.Context, event *models.AuditEvent) error
+	// GetAuditEvents последние записи по фильтру, новые первыми
+	GetAuditEvents(ctx context.Context, filter models.AuditFilter) ([]models.AuditEvent, error)
+}
+
+// IncomingHookRepository входящие webhook пользователей
+type IncomingHookRepository interface {
+	CreateIncomingHook(ctx context.Context, hook *models.IncomingHook, tokenHash string) error
+	GetIncomingHooks(ctx context.Context, userID string) ([]models.IncomingHook, error)
+	// GetIncomingHookByToken возвращает ErrNotFound, если webhook с таким хешем токена нет
+	GetIncomingHookByToken(ctx context.Context, tokenHash string) (*models.IncomingHook, error)
+	DeleteIncomingHook(ctx context.Context, userID, hookID string) error
+	TouchIncomingHook(ctx context.Context, hookID string, usedAt time.Time) error
+}
+
+// HookRateLimiter ограничивает число входящих запросов на webhook за окно
+type HookRateLimiter interface {
+	// AllowHook засчитывает запрос и возвращает false, если лимит окна исчерпан
+	AllowHook(ctx context.Context, hookID string, limit int, window time.Duration) (bool, error)
+}
+
+// ExternalRefRepository связи задач с задачами внешних трекеров
+type ExternalRefRepository interface {
+	// SaveExternalRef создает связь, повторная связь с той же внешней задачей обновляет MirrorCompletion
+	SaveExternalRef(ctx context.Context, ref *models.ExternalRef) error
+	GetExternalRefs(ctx context.Context, taskID string) ([]models.ExternalRef, error)
+	DeleteExternalRef(ctx context.Context, taskID, refID string) error
+	// GetExternalRefsToCheck связи, не проверявшиеся с checkedBefore, сначала самые давние
+	GetExternalRefsToCheck(ctx context.Context, checkedBefore time.Time, limit int) ([]models.ExternalRef, error)
+	UpdateExternalIssue(ctx context.Context, refID string, issue models.ExternalIssue, checkedAt time.Time) error
+}
+
+// GitHubRepoLinkRepository подключенные репозитории GitHub
+type GitHubRepoLinkRepository interface {
+	// CreateRepoLink возвращает ErrAlreadyExists, если репозиторий уже подключен пользователем
+	CreateRepoLink(ctx context.Context, link *models.GitHubRepoLink) error
+	// GetRepoLink возвращает ErrNotFound, если подключения нет
+	GetRepoLink(ctx context.Context, id string) (*models.GitHubRepoLink, error)
+	GetRepoLinks(ctx context.Context, userID string) ([]models.GitHubRepoLink, error)
+	DeleteRepoLink(ctx context.Context, userID, id string) error
+}
+
+// TaskRefResolver поиск задач пользователя по началу ID, для коротких ссылок вида TM-1a2b3c4d
+type TaskRefResolver interface {
+	FindTaskIDsByPrefix(ctx context.Context, userID, prefix string) ([]string, error)
+}
+
+// UserBackupRepository резервное копирование и восстановление одного пользователя
+type UserBackupRepository interface {
+	// ExportUser передает записи пользователя в emit по мере чтения из одного снимка базы.
+	// Возвращает ErrNotFound, если пользователя нет
+	ExportUser(ctx context.Context, userID string, emit func(models.BackupRecord) error) error
+	// BeginUserImport открывает транзакцию восстановления пользователя
+	BeginUserImport(ctx context.Context) (UserImport, error)
+}
+
+// UserImport транзакция восстановления пользователя, записи видны только после Commit
+type UserImport interface {
+	// CreateAccount возвращает ErrAlreadyExists, если пользователь с таким ID или email уже есть
+	CreateAccount(ctx context.Context, account models.BackupAccount) error
+	// CreateTask сохраняет задачу как есть: с ID, временными метками и зашифрованными полями
+	CreateTask(ctx context.Context, task models.Task) error
+	CreateRelation(ctx context.Context, relation models.BackupRelation) error
+	SavePreferences(ctx context.Context, prefs models.NotificationPreferences) error
+	Commit() error
+	Rollback() error
 }
 
 // AnalyticsReader чтение аналитики из кэша
@@ -75,6 +478,55 @@ type AnalyticsCache interface {
 	AnalyticsInvalidator
 }
 
+// TaskCache кэш задач по ID и списков задач по фильтрам. Задачи хранятся в том виде,
+// в каком лежат в БД (у приватных задач поля зашифрованы)
+type TaskCache interface {
+	// GetTask возвращает nil без ошибки, если задачи нет в кэше
+	GetTask(ctx context.Context, taskID string) (*models.Task, error)
+	SetTask(ctx context.Context, task models.Task) error
+	InvalidateTask(ctx context.Context, taskID string) error
+	// GetTaskList возвращает found = false без ошибки, если списка с такими фильтрами нет в кэше.
+	// Списки хранятся по пользователю filters.UserID. generation — поколение списков на момент
+	// чтения, список из БД после промаха сохраняется через SetTaskList под ним
+	GetTaskList(ctx context.Context, filters models.TaskFilters) (tasks []models.Task, generation int64, found bool, err error)
+	SetTaskList(ctx context.Context, filters models.TaskFilters, generation int64, tasks []models.Task) error
+	// InvalidateTaskLists удаляет все списки пользователя
+	InvalidateTaskLists(ctx context.Context, userID string) error
+}
+
+// UserStatusCache кэш признака активности пользователя, который проверяется на каждом запросе
+type UserStatusCache interface {
+	// GetUserActive возвращает found = false без ошибки, если записи нет в кэше
+	GetUserActive(ctx context.Context, userID string) (active bool, found bool, err error)
+	SetUserActive(ctx context.Context, userID string, active bool) error
+}
+
+// MatrixSettingsRepository хранение порогов матрицы Эйзенхауэра.
+// GetMatrixSettings возвращает nil, если пользователь ничего не настраивал
+type MatrixSettingsRepository interface {
+	GetMatrixSettings(ctx context.Context, userID string) (*models.MatrixSettings, error)
+	SaveMatrixSettings(ctx context.Context, settings *models.MatrixSettings) error
+}
+
+// ProjectRepository проекты пользователя. UpdateProject и DeleteProject меняют только проект
+// пользователя и возвращают ErrNotFound, если его нет
+type ProjectRepository interface {
+	ProjectReader
+	CreateProject(ctx context.Context, project *models.Project) error
+	GetProjects(ctx context.Context, userID string) ([]models.Project, error)
+	UpdateProject(ctx context.Context, project *models.Project) error
+	DeleteProject(ctx context.Context, userID, id string) error
+}
+
+// TaskViewCache кэш виртуальных списков задач пользователя ("Сегодня", "Предстоящие").
+// key описывает окно выборки, InvalidateTaskViews сбрасывает все списки пользователя
+type TaskViewCache interface {
+	// GetTaskView возвращает false без ошибки, если списка нет в кэше
+	GetTaskView(ctx context.Context, userID, key string) ([]models.Task, bool, error)
+	SetTaskView(ctx context.Context, userID, key string, tasks []models.Task) error
+	InvalidateTaskViews(ctx context.Context, userID string) error
+}
+
 // CachedAnalytics представляет данные аналитики в кэше
 type CachedAnalytics struct {
 	UserID    string           `json:"user_id"`
@@ -89,3 +541,16 @@ type Repositories struct {
 	Users     UserRepository
 	Analytics AnalyticsCache
 }
+
+// TaskEmbeddingRepository векторные представления задач для поиска похожих
+type TaskEmbeddingRepository interface {
+	// GetEmbeddingHash хэш текста, по которому построен вектор задачи моделью model; ErrNotFound — вектора нет
+	GetEmbeddingHash(ctx context.Context, taskID, model string) (string, error)
+	// SaveEmbedding заменяет вектор задачи; вектор удаленной задачи не сохраняется
+	SaveEmbedding(ctx context.Context, embedding models.TaskEmbedding) error
+	DeleteEmbedding(ctx context.Context, taskID string) error
+	// GetTasksWithoutEmbedding неприватные задачи без вектора модели model
+	GetTasksWithoutEmbedding(ctx context.Context, model string, limit int) ([]models.Task, error)
+	// FindSimilarTasks задачи того же пользователя, близость которых к taskID не меньше minScore, по убыванию близости
+	FindSimilarTasks(ctx context.Context, taskID, model string, minScore float64, limit int) ([]models.SimilarTask, error)
+}
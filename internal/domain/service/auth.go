@@ -0,0 +1,23 @@
+package service
+
+import "context"
+
+// PasswordHasher хэширование паролей пользователей
+type PasswordHasher interface {
+	Hash(password string) (string, error)
+	// Verify проверяет пароль; rehash — хэш устарел (другой алгоритм или стоимость) и его стоит пересчитать
+	Verify(hash, password string) (ok bool, rehash bool, err error)
+}
+
+// PasswordPolicy требования к новым паролям
+type PasswordPolicy interface {
+	// Check возвращает ошибку с перечнем нарушенных требований, если пароль им не соответствует
+	Check(ctx context.Context, password string) error
+	// History сколько последних паролей пользователя нельзя использовать снова
+	History() int
+}
+
+// UserStatusChecker проверка, что учетная запись не деактивирована администратором
+type UserStatusChecker interface {
+	Active(ctx context.Context, userID string) (bool, error)
+}
@@ -0,0 +1,28 @@
+package service
+
+import (
+	"context"
+
+	"github.com/jmoloko/taskmange/internal/domain/models"
+)
+
+// CalendarClient внешний календарь, с событиями которого синхронизируются сроки задач
+type CalendarClient interface {
+	// PutEvent создает событие задачи (пустой eventID) или обновляет его и возвращает ID события.
+	// calendar.ErrEventNotFound — события больше нет в календаре
+	PutEvent(ctx context.Context, link models.CalendarLink, eventID string, task models.Task) (string, error)
+	// DeleteEvent удаляет событие, уже удаленное событие не ошибка
+	DeleteEvent(ctx context.Context, link models.CalendarLink, eventID string) error
+	// Changes события, измененные с позиции link.SyncToken, и новая позиция. С пустой позицией
+	// возвращает только начальную; calendar.ErrSyncTokenExpired — позиция устарела
+	Changes(ctx context.Context, link models.CalendarLink) ([]models.CalendarEvent, string, error)
+}
+
+// CalendarWatcher календарь, который сообщает об изменениях событий запросом на webhook
+type CalendarWatcher interface {
+	// Watch открывает канал channel с заданными ID и Token и возвращает его с ресурсом
+	// и временем окончания
+	Watch(ctx context.Context, link models.CalendarLink, channel models.CalendarChannel, address string) (models.CalendarChannel, error)
+	// StopWatch закрывает канал link.Channel
+	StopWatch(ctx context.Context, link models.CalendarLink) error
+}
@@ -0,0 +1,13 @@
+package service
+
+import (
+	"context"
+
+	"github.com/jmoloko/taskmange/internal/domain/models"
+)
+
+// EventPublisher публикует события задач в конвейер событий.
+// Publish не должен блокировать вызывающего: обработка выполняется асинхронно
+type EventPublisher interface {
+	Publish(ctx context.Context, event models.TaskEvent)
+}
@@ -0,0 +1,31 @@
+package service
+
+import (
+	"context"
+
+	"github.com/jmoloko/taskmange/internal/domain/models"
+)
+
+// IssueTracker внешний трекер задач, с задачами которого связываются задачи пользователя
+type IssueTracker interface {
+	// ParseRef приводит ключ или ссылку на задачу трекера к каноническому ключу
+	ParseRef(ref string) (string, error)
+	// FetchIssue текущее состояние задачи трекера по каноническому ключу
+	FetchIssue(ctx context.Context, key string) (models.ExternalIssue, error)
+}
+
+// LanguageModel языковая модель AI-ассистента
+type LanguageModel interface {
+	// Complete ответ модели на prompt по системной инструкции system
+	Complete(ctx context.Context, system, prompt string) (string, error)
+	// Name название модели, сохраняется вместе с результатом
+	Name() string
+}
+
+// TextEmbedder модель векторных представлений текста для поиска похожих задач
+type TextEmbedder interface {
+	// Embed вектор текста; векторы одной модели имеют одинаковую размерность
+	Embed(ctx context.Context, text string) ([]float32, error)
+	// Name название модели, сохраняется вместе с вектором
+	Name() string
+}
@@ -0,0 +1,22 @@
+package service
+
+import (
+	"context"
+
+	"github.com/jmoloko/taskmange/internal/domain/models"
+)
+
+// Notifier доставка уведомлений пользователю по одному каналу
+type Notifier interface {
+	Notify(ctx context.Context, userID string, notification models.Notification) error
+}
+
+// TelegramBot отправляет текстовое сообщение в чат Telegram
+type TelegramBot interface {
+	SendMessage(ctx context.Context, chatID int64, text string) error
+}
+
+// TriggerActionSender выполняет действие триггера, доставляя событие по адресу trigger.Action.Target
+type TriggerActionSender interface {
+	Send(ctx context.Context, trigger models.Trigger, event models.TaskEvent) error
+}
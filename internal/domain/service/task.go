@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"time"
 
 	"github.com/jmoloko/taskmange/internal/domain/models"
 )
@@ -14,25 +15,74 @@ type TaskCreator interface {
 // TaskReader чтение задачи
 type TaskReader interface {
 	GetUserTask(ctx context.Context, userID, taskID string) (models.Task, error)
+	UnlockUserTask(ctx context.Context, userID, taskID string) (models.Task, error)
 	GetUserTasks(ctx context.Context, userID string, filters models.TaskFilters) ([]models.Task, error)
 	GetAll(ctx context.Context, userID string, filters models.TaskFilters) ([]models.Task, error)
+	// CountUserTasks число задач по фильтрам без учета Limit и Offset
+	CountUserTasks(ctx context.Context, userID string, filters models.TaskFilters) (int, error)
 	GetActiveUsers(ctx context.Context) ([]string, error)
+	GetUsersWithTasksUpdatedSince(ctx context.Context, since time.Time) ([]string, error)
 }
 
 // TaskUpdater обновление задачи
 type TaskUpdater interface {
 	UpdateUserTask(ctx context.Context, userID string, task models.Task) (models.Task, error)
+	// PatchUserTask меняет только поля, переданные в запросе
+	PatchUserTask(ctx context.Context, userID, taskID string, req models.UpdateTaskRequest) (models.Task, error)
+	// CompleteTasks выполняет задачи пакетом, ErrTaskNotFound отменяет весь пакет
+	CompleteTasks(ctx context.Context, userID string, ids []string) (models.CompleteTasksResult, error)
 }
 
 // TaskDeleter удаление задачи
 type TaskDeleter interface {
 	DeleteUserTask(ctx context.Context, userID, taskID string) error
 	Delete(ctx context.Context, taskID, userID string) error
+	// PurgeUserTasks удаляет все задачи пользователя и возвращает их число
+	PurgeUserTasks(ctx context.Context, userID string) (int, error)
+	// DeleteTasks удаляет задачи пользователя по фильтру пачками; progress вызывается после каждой пачки,
+	// кроме последней, которая возвращается итогом. ErrInvalidTaskData — в фильтре нет ни одного условия
+	DeleteTasks(ctx context.Context, userID string, filter models.TaskDeleteFilter, progress func(models.BulkDeleteProgress) error) (models.BulkDeleteProgress, error)
+}
+
+// TaskRelations связи между задачами: "related to" и подзадачи
+type TaskRelations interface {
+	RelateTasks(ctx context.Context, userID, taskID, relatedID string) error
+	UnrelateTasks(ctx context.Context, userID, taskID, relatedID string) error
+	// ExpandRelated заполняет Related у задач пользователя
+	ExpandRelated(ctx context.Context, userID string, tasks []models.Task) ([]models.Task, error)
+	// GetSubtasks прямые подзадачи задачи пользователя
+	GetSubtasks(ctx context.Context, userID, taskID string) ([]models.Task, error)
+}
+
+// TaskAssignments назначение задач исполнителям
+type TaskAssignments interface {
+	// AssignTask назначает задачу владельца userID исполнителю
+	AssignTask(ctx context.Context, userID, taskID, assigneeID string) (models.Task, error)
+	// UnassignTask снимает назначение; снять его может владелец или сам исполнитель
+	UnassignTask(ctx context.Context, userID, taskID string) (models.Task, error)
+}
+
+// TaskRecurrences серии повторяющихся задач
+type TaskRecurrences interface {
+	GetRecurrence(ctx context.Context, userID, recurrenceID string) (models.Recurrence, error)
+	PauseRecurrence(ctx context.Context, userID, recurrenceID string) (models.Recurrence, error)
+	ResumeRecurrence(ctx context.Context, userID, recurrenceID string) (models.Recurrence, error)
+	// MaterializeRecurrences создает следующие экземпляры серий, последний экземпляр которых выполнен или просрочен
+	MaterializeRecurrences(ctx context.Context) error
+}
+
+// ImportRowSource построчный источник задач для импорта файла
+type ImportRowSource interface {
+	// Next возвращает следующую строку или ошибку ее разбора в rowErr; io.EOF — строки закончились
+	Next() (row models.ImportRow, rowErr *models.ImportRowError, err error)
 }
 
 // TaskImporter импорт задачи
 type TaskImporter interface {
 	ImportTasks(ctx context.Context, userID string, tasks []models.Task) error
+	// ImportTaskFile импорт загруженного файла с отчетом об ошибках строк
+	ImportTaskFile(ctx context.Context, userID string, rows ImportRowSource, skipInvalid bool) (models.ImportReport, error)
+	PreviewImport(ctx context.Context, userID string, batch models.ImportBatch) (models.ImportPreview, error)
 }
 
 // TaskExporter экспорт задачи
@@ -44,6 +94,26 @@ type TaskExporter interface {
 type TaskAnalytics interface {
 	GetUserAnalytics(ctx context.Context, userID string, period string) (models.Analytics, error)
 	GetAnalytics(ctx context.Context, userID string, period string) (models.Analytics, error)
+	// GetUserAnalyticsRange аналитика за произвольное окно [from, to)
+	GetUserAnalyticsRange(ctx context.Context, userID string, from, to time.Time) (models.Analytics, error)
+	// GetProjectAnalytics аналитика задач проекта пользователя за период
+	GetProjectAnalytics(ctx context.Context, userID, projectID, period string) (models.Analytics, error)
+}
+
+// TaskDashboard сводка по задачам
+type TaskDashboard interface {
+	GetDashboard(ctx context.Context, userID string) (models.Dashboard, error)
+}
+
+// TaskEncryptor шифрование полей приватных задач
+type TaskEncryptor interface {
+	Encrypt(userID, plaintext string) (string, error)
+	Decrypt(userID, ciphertext string) (string, error)
+}
+
+// TaskTagger теги, которые правила автотегирования пользователя ставят задаче с таким текстом
+type TaskTagger interface {
+	AutoTags(ctx context.Context, userID, title, description string) ([]string, error)
 }
 
 // TaskManager объединяет основные операции с задачами
@@ -52,6 +122,9 @@ type TaskManager interface {
 	TaskReader
 	TaskUpdater
 	TaskDeleter
+	TaskRelations
+	TaskRecurrences
+	TaskAssignments
 }
 
 // TaskDataProcessor объединяет операции обработки данных задач
@@ -59,6 +132,7 @@ type TaskDataProcessor interface {
 	TaskImporter
 	TaskExporter
 	TaskAnalytics
+	TaskDashboard
 }
 
 // TaskService объединяет все операции с задачами (для обратной совместимости)
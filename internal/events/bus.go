@@ -0,0 +1,147 @@
+package events
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	domainService "github.com/jmoloko/taskmange/internal/domain/service"
+	"github.com/jmoloko/taskmange/internal/logger"
+	"github.com/jmoloko/taskmange/internal/metrics"
+)
+
+const (
+	defaultQueueSize = 1024
+	// время на обработку одного события всеми подписчиками
+	handleTimeout = 30 * time.Second
+)
+
+// Handler обработчик событий задач
+type Handler func(ctx context.Context, event models.TaskEvent) error
+
+// Broker конвейер событий задач: сервисы публикуют в него события, подписчики получают их асинхронно.
+// Реализации: Bus внутри процесса и RedisStream, общий для всех экземпляров приложения
+type Broker interface {
+	domainService.EventPublisher
+	// Subscribe регистрирует обработчик, который получает каждое событие один раз на все экземпляры
+	// (триггеры, уведомления); вызывается до Start
+	Subscribe(name string, handler Handler)
+	// SubscribeLocal регистрирует обработчик, который получает каждое событие в каждом экземпляре
+	// (доставка своим подключениям); вызывается до Start
+	SubscribeLocal(name string, handler Handler)
+	// Start запускает доставку событий подписчикам
+	Start()
+	// Stop прекращает прием событий и дожидается обработки принятых
+	Stop()
+}
+
+// Bus конвейер событий задач внутри процесса.
+// Publish кладет событие в очередь и не ждет подписчиков, при переполнении событие отбрасывается
+type Bus struct {
+	queue    chan models.TaskEvent
+	handlers []namedHandler
+	logger   logger.Logger
+	wg       sync.WaitGroup
+
+	mu     sync.RWMutex
+	closed bool
+}
+
+var (
+	_ Broker = (*Bus)(nil)
+	_ Broker = (*RedisStream)(nil)
+)
+
+type namedHandler struct {
+	name    string
+	handler Handler
+}
+
+// NewBus создает новый экземпляр Bus
+func NewBus(queueSize int, logger logger.Logger) *Bus {
+	if queueSize <= 0 {
+		queueSize = defaultQueueSize
+	}
+
+	return &Bus{
+		queue:  make(chan models.TaskEvent, queueSize),
+		logger: logger,
+	}
+}
+
+// Subscribe регистрирует обработчик, вызывается до Start
+func (b *Bus) Subscribe(name string, handler Handler) {
+	b.handlers = append(b.handlers, namedHandler{name: name, handler: handler})
+}
+
+// SubscribeLocal то же, что Subscribe: у Bus один экземпляр
+func (b *Bus) SubscribeLocal(name string, handler Handler) {
+	b.Subscribe(name, handler)
+}
+
+// Publish ставит событие в очередь
+func (b *Bus) Publish(ctx context.Context, event models.TaskEvent) {
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = time.Now()
+	}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	if b.closed {
+		return
+	}
+
+	select {
+	case b.queue <- event:
+		metrics.EventsQueued.Set(float64(len(b.queue)))
+	default:
+		metrics.EventsDroppedTotal.Inc()
+		b.logger.Warn("Event bus queue is full, dropping event", map[string]interface{}{
+			"type":    event.Type,
+			"task_id": event.Task.ID,
+		})
+	}
+}
+
+// Start запускает доставку событий подписчикам
+func (b *Bus) Start() {
+	b.wg.Add(1)
+	go func() {
+		defer b.wg.Done()
+		for event := range b.queue {
+			metrics.EventsQueued.Set(float64(len(b.queue)))
+			dispatch(b.handlers, event, b.logger)
+		}
+	}()
+}
+
+// Stop прекращает прием событий и дожидается обработки очереди
+func (b *Bus) Stop() {
+	b.mu.Lock()
+	if !b.closed {
+		b.closed = true
+		close(b.queue)
+	}
+	b.mu.Unlock()
+
+	b.wg.Wait()
+}
+
+// dispatch передает событие обработчикам по очереди, ошибка одного не мешает остальным
+func dispatch(handlers []namedHandler, event models.TaskEvent, logger logger.Logger) {
+	ctx, cancel := context.WithTimeout(context.Background(), handleTimeout)
+	defer cancel()
+
+	for _, h := range handlers {
+		if err := h.handler(ctx, event); err != nil {
+			logger.Error("Event handler failed", map[string]interface{}{
+				"handler": h.name,
+				"type":    event.Type,
+				"task_id": event.Task.ID,
+				"error":   err.Error(),
+			})
+		}
+	}
+}
@@ -0,0 +1,370 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jmoloko/taskmange/internal/config"
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/jmoloko/taskmange/internal/logger"
+	"github.com/jmoloko/taskmange/internal/metrics"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// streamField поле записи потока с событием в JSON
+	streamField = "event"
+	// readBlock сколько чтение ждет новых записей; столько же Stop может ждать завершения чтения
+	readBlock  = 2 * time.Second
+	readCount  = 100
+	retryDelay = time.Second
+	// redisTimeout ограничивает запись события и подтверждение обработки
+	redisTimeout = 5 * time.Second
+)
+
+// RedisStream конвейер событий задач поверх Redis Streams, общий для всех экземпляров приложения.
+// Publish кладет событие в локальную очередь, из которой оно добавляется в поток (XADD).
+// Обработчики Subscribe читают поток через группу потребителей: событие получает один экземпляр,
+// а необработанные события остановившегося экземпляра через ClaimIdle забирает другой.
+// Обработчики SubscribeLocal читают поток целиком в каждом экземпляре, начиная с момента запуска
+type RedisStream struct {
+	client    *redis.Client
+	stream    string
+	group     string
+	consumer  string
+	maxLen    int64
+	claimIdle time.Duration
+	logger    logger.Logger
+
+	outbox chan models.TaskEvent
+	shared []namedHandler
+	local  []namedHandler
+
+	ctx     context.Context
+	cancel  context.CancelFunc
+	writer  sync.WaitGroup
+	readers sync.WaitGroup
+
+	mu     sync.RWMutex
+	closed bool
+}
+
+// NewRedisStream создает новый экземпляр RedisStream
+func NewRedisStream(client *redis.Client, cfg config.EventsConfig, logger logger.Logger) *RedisStream {
+	// имя потребителя уникально для процесса, чтобы его незавершенные события можно было отличить от чужих
+	host, _ := os.Hostname()
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &RedisStream{
+		client:    client,
+		stream:    cfg.Stream,
+		group:     cfg.Group,
+		consumer:  fmt.Sprintf("%s-%d", host, os.Getpid()),
+		maxLen:    int64(cfg.MaxLen),
+		claimIdle: cfg.ClaimIdle,
+		logger:    logger,
+		outbox:    make(chan models.TaskEvent, defaultQueueSize),
+		ctx:       ctx,
+		cancel:    cancel,
+	}
+}
+
+// Subscribe регистрирует обработчик, который получает событие в одном из экземпляров, вызывается до Start
+func (s *RedisStream) Subscribe(name string, handler Handler) {
+	s.shared = append(s.shared, namedHandler{name: name, handler: handler})
+}
+
+// SubscribeLocal регистрирует обработчик, который получает событие в каждом экземпляре, вызывается до Start
+func (s *RedisStream) SubscribeLocal(name string, handler Handler) {
+	s.local = append(s.local, namedHandler{name: name, handler: handler})
+}
+
+// Publish ставит событие в очередь на запись в поток
+func (s *RedisStream) Publish(ctx context.Context, event models.TaskEvent) {
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = time.Now()
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.closed {
+		return
+	}
+
+	select {
+	case s.outbox <- event:
+		metrics.EventsQueued.Set(float64(len(s.outbox)))
+	default:
+		metrics.EventsDroppedTotal.Inc()
+		s.logger.Warn("Event stream queue is full, dropping event", map[string]interface{}{
+			"type":    event.Type,
+			"task_id": event.Task.ID,
+		})
+	}
+}
+
+// Start запускает запись событий в поток и чтение потока подписчиками
+func (s *RedisStream) Start() {
+	// группа и позиция чтения определяются до первой записи, иначе события,
+	// опубликованные сразу после запуска, прошли бы мимо подписчиков
+	groupReady := len(s.shared) == 0 || s.createGroup() == nil
+	var lastID string
+	if len(s.local) > 0 {
+		// при ошибке позиция определяется уже при чтении
+		lastID, _ = s.lastStreamID()
+	}
+
+	s.writer.Add(1)
+	go s.write()
+
+	if len(s.shared) > 0 {
+		s.readers.Add(1)
+		go s.consumeGroup(groupReady)
+	}
+	if len(s.local) > 0 {
+		s.readers.Add(1)
+		go s.consumeAll(lastID)
+	}
+}
+
+// Stop прекращает прием событий, дописывает очередь в поток и останавливает чтение
+func (s *RedisStream) Stop() {
+	s.mu.Lock()
+	if !s.closed {
+		s.closed = true
+		close(s.outbox)
+	}
+	s.mu.Unlock()
+
+	s.writer.Wait()
+	s.cancel()
+	s.readers.Wait()
+}
+
+func (s *RedisStream) write() {
+	defer s.writer.Done()
+
+	for event := range s.outbox {
+		metrics.EventsQueued.Set(float64(len(s.outbox)))
+		if err := s.add(event); err != nil {
+			metrics.EventsDroppedTotal.Inc()
+			s.logger.Error("Failed to add event to Redis stream", map[string]interface{}{
+				"type":    event.Type,
+				"task_id": event.Task.ID,
+				"error":   err.Error(),
+			})
+		}
+	}
+}
+
+// add записывает событие в поток, поток обрезается примерно до MaxLen записей
+func (s *RedisStream) add(event models.TaskEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+
+	return s.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: s.stream,
+		MaxLen: s.maxLen,
+		Approx: true,
+		Values: map[string]interface{}{streamField: data},
+	}).Err()
+}
+
+// consumeGroup читает новые события группы потребителей и периодически забирает
+// незавершенные события других экземпляров
+func (s *RedisStream) consumeGroup(ready bool) {
+	defer s.readers.Done()
+
+	var lastClaim time.Time
+	for s.ctx.Err() == nil {
+		if !ready {
+			if err := s.createGroup(); err != nil {
+				s.retry("Failed to create event stream consumer group", err)
+				continue
+			}
+			ready = true
+		}
+
+		if time.Since(lastClaim) >= s.claimIdle {
+			s.claim()
+			lastClaim = time.Now()
+		}
+
+		streams, err := s.client.XReadGroup(s.ctx, &redis.XReadGroupArgs{
+			Group:    s.group,
+			Consumer: s.consumer,
+			Streams:  []string{s.stream, ">"},
+			Count:    readCount,
+			Block:    readBlock,
+		}).Result()
+		if errors.Is(err, redis.Nil) {
+			continue
+		}
+		if err != nil {
+			// группа пропадает вместе с потоком, например после перезапуска Redis без сохранения
+			ready = !strings.HasPrefix(err.Error(), "NOGROUP")
+			s.retry("Failed to read event stream", err)
+			continue
+		}
+
+		for _, stream := range streams {
+			s.handleGroup(stream.Messages)
+		}
+	}
+}
+
+// createGroup создает группу потребителей, если ее еще нет; группа читает события, добавленные после создания
+func (s *RedisStream) createGroup() error {
+	ctx, cancel := context.WithTimeout(s.ctx, redisTimeout)
+	defer cancel()
+
+	err := s.client.XGroupCreateMkStream(ctx, s.stream, s.group, "$").Err()
+	if err != nil && strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return nil
+	}
+	return err
+}
+
+// claim забирает события, которые другой потребитель получил, но не подтвердил за ClaimIdle
+func (s *RedisStream) claim() {
+	start := "0-0"
+	for s.ctx.Err() == nil {
+		messages, next, err := s.client.XAutoClaim(s.ctx, &redis.XAutoClaimArgs{
+			Stream:   s.stream,
+			Group:    s.group,
+			Consumer: s.consumer,
+			MinIdle:  s.claimIdle,
+			Start:    start,
+			Count:    readCount,
+		}).Result()
+		if err != nil {
+			if s.ctx.Err() == nil {
+				s.logger.Warn("Failed to claim pending stream events", map[string]interface{}{
+					"error": err.Error(),
+				})
+			}
+			return
+		}
+
+		s.handleGroup(messages)
+		if next == "0-0" {
+			return
+		}
+		start = next
+	}
+}
+
+// handleGroup передает события обработчикам Subscribe и подтверждает их. Ошибки обработчиков
+// только записываются в лог, как и у Bus: повторная доставка задела бы остальных подписчиков
+func (s *RedisStream) handleGroup(messages []redis.XMessage) {
+	for _, message := range messages {
+		if event, ok := s.decode(message); ok {
+			dispatch(s.shared, event, s.logger)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+		err := s.client.XAck(ctx, s.stream, s.group, message.ID).Err()
+		cancel()
+		if err != nil {
+			s.logger.Warn("Failed to acknowledge stream event", map[string]interface{}{
+				"id":    message.ID,
+				"error": err.Error(),
+			})
+		}
+	}
+}
+
+// consumeAll читает весь поток после lastID и передает события обработчикам SubscribeLocal
+func (s *RedisStream) consumeAll(lastID string) {
+	defer s.readers.Done()
+
+	for s.ctx.Err() == nil {
+		if lastID == "" {
+			id, err := s.lastStreamID()
+			if err != nil {
+				s.retry("Failed to read event stream position", err)
+				continue
+			}
+			lastID = id
+		}
+
+		streams, err := s.client.XRead(s.ctx, &redis.XReadArgs{
+			Streams: []string{s.stream, lastID},
+			Count:   readCount,
+			Block:   readBlock,
+		}).Result()
+		if errors.Is(err, redis.Nil) {
+			continue
+		}
+		if err != nil {
+			s.retry("Failed to read event stream", err)
+			continue
+		}
+
+		for _, stream := range streams {
+			for _, message := range stream.Messages {
+				lastID = message.ID
+				if event, ok := s.decode(message); ok {
+					dispatch(s.local, event, s.logger)
+				}
+			}
+		}
+	}
+}
+
+// lastStreamID ID последней записи потока или 0-0 для пустого потока
+func (s *RedisStream) lastStreamID() (string, error) {
+	ctx, cancel := context.WithTimeout(s.ctx, redisTimeout)
+	defer cancel()
+
+	messages, err := s.client.XRevRangeN(ctx, s.stream, "+", "-", 1).Result()
+	if err != nil {
+		return "", err
+	}
+	if len(messages) == 0 {
+		return "0-0", nil
+	}
+	return messages[0].ID, nil
+}
+
+// decode читает событие из записи потока; записи, обрезанные по MaxLen, приходят без полей
+func (s *RedisStream) decode(message redis.XMessage) (models.TaskEvent, bool) {
+	var event models.TaskEvent
+	data, ok := message.Values[streamField].(string)
+	if !ok || json.Unmarshal([]byte(data), &event) != nil {
+		s.logger.Error("Malformed event in Redis stream", map[string]interface{}{
+			"id": message.ID,
+		})
+		return event, false
+	}
+	return event, true
+}
+
+// retry записывает ошибку и ждет перед следующей попыткой, если поток не остановлен
+func (s *RedisStream) retry(msg string, err error) {
+	if s.ctx.Err() != nil {
+		return
+	}
+
+	s.logger.Error(msg, map[string]interface{}{
+		"stream": s.stream,
+		"error":  err.Error(),
+	})
+
+	select {
+	case <-s.ctx.Done():
+	case <-time.After(retryDelay):
+	}
+}
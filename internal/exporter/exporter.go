@@ -0,0 +1,202 @@
+// Package exporter записывает задачи в файлы экспорта в форматах JSON, CSV и XLSX.
+// Колонки таблиц совпадают с колонками, которые понимает importer, поэтому экспорт можно загрузить обратно
+package exporter
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/jmoloko/taskmange/internal/importer"
+	"github.com/xuri/excelize/v2"
+)
+
+// Форматы экспорта совпадают с форматами импорта
+const (
+	FormatJSON = importer.FormatJSON
+	FormatCSV  = importer.FormatCSV
+	FormatXLSX = importer.FormatXLSX
+)
+
+// columns заголовок таблицы экспорта
+var columns = []string{
+	"id", "title", "description", "status", "priority", "due_date", "private", "notes", "links", "tags",
+	"project_id", "parent_id", "created_at", "updated_at", "completed_at",
+}
+
+// csvFlushRows через сколько строк CSV отправляется клиенту
+const csvFlushRows = 100
+
+// ParseFormat формат по параметру format, пустой — JSON
+func ParseFormat(value string) (importer.Format, error) {
+	switch format := importer.Format(strings.ToLower(value)); format {
+	case "":
+		return FormatJSON, nil
+	case FormatJSON, FormatCSV, FormatXLSX:
+		return format, nil
+	default:
+		return "", importer.ErrUnsupportedFormat
+	}
+}
+
+// ContentType тип содержимого файла экспорта
+func ContentType(format importer.Format) string {
+	switch format {
+	case FormatCSV:
+		return "text/csv; charset=utf-8"
+	case FormatXLSX:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	default:
+		return "application/json; charset=utf-8"
+	}
+}
+
+// FileName имя файла экспорта с датой выгрузки, например tasks-2024-05-01.csv
+func FileName(format importer.Format, now time.Time) string {
+	return fmt.Sprintf("tasks-%s.%s", now.Format("2006-01-02"), format)
+}
+
+// Write записывает задачи в w. CSV и JSON пишутся по мере кодирования строк, XLSX — архив,
+// поэтому он собирается потоковым писателем excelize и отправляется целиком в конце
+func Write(format importer.Format, w io.Writer, tasks []models.Task) error {
+	switch format {
+	case FormatJSON:
+		return writeJSON(w, tasks)
+	case FormatCSV:
+		return writeCSV(w, tasks)
+	case FormatXLSX:
+		return writeXLSX(w, tasks)
+	default:
+		return importer.ErrUnsupportedFormat
+	}
+}
+
+func writeJSON(w io.Writer, tasks []models.Task) error {
+	if _, err := io.WriteString(w, "["); err != nil {
+		return err
+	}
+	for i, task := range tasks {
+		if i > 0 {
+			if _, err := io.WriteString(w, ","); err != nil {
+				return err
+			}
+		}
+		data, err := json.Marshal(task)
+		if err != nil {
+			return fmt.Errorf("failed to encode task %s: %w", task.ID, err)
+		}
+		if _, err := w.Write(data); err != nil {
+			return err
+		}
+	}
+	_, err := io.WriteString(w, "]")
+	return err
+}
+
+func writeCSV(w io.Writer, tasks []models.Task) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(columns); err != nil {
+		return err
+	}
+	for i, task := range tasks {
+		if err := writer.Write(record(task)); err != nil {
+			return err
+		}
+		if (i+1)%csvFlushRows == 0 {
+			writer.Flush()
+			if err := writer.Error(); err != nil {
+				return err
+			}
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+func writeXLSX(w io.Writer, tasks []models.Task) error {
+	file := excelize.NewFile()
+	defer file.Close()
+
+	sheet := file.GetSheetName(0)
+	if err := file.SetSheetName(sheet, "Tasks"); err != nil {
+		return err
+	}
+	stream, err := file.NewStreamWriter("Tasks")
+	if err != nil {
+		return err
+	}
+
+	for i, values := range append([][]string{columns}, records(tasks)...) {
+		row := make([]interface{}, len(values))
+		for j, value := range values {
+			row[j] = value
+		}
+		cell, err := excelize.CoordinatesToCellName(1, i+1)
+		if err != nil {
+			return err
+		}
+		if err := stream.SetRow(cell, row); err != nil {
+			return err
+		}
+	}
+	if err := stream.Flush(); err != nil {
+		return err
+	}
+
+	_, err = file.WriteTo(w)
+	return err
+}
+
+func records(tasks []models.Task) [][]string {
+	rows := make([][]string, 0, len(tasks))
+	for _, task := range tasks {
+		rows = append(rows, record(task))
+	}
+	return rows
+}
+
+// record значения колонок задачи: ссылки через пробел, теги через запятую, как их читает importer
+func record(task models.Task) []string {
+	urls := make([]string, 0, len(task.Links))
+	for _, link := range task.Links {
+		urls = append(urls, link.URL)
+	}
+
+	return []string{
+		task.ID,
+		task.Title,
+		task.Description,
+		string(task.Status),
+		string(task.Priority),
+		formatTime(&task.DueDate),
+		strconv.FormatBool(task.Private),
+		value(task.Notes),
+		strings.Join(urls, " "),
+		strings.Join(task.Tags, ", "),
+		value(task.ProjectID),
+		value(task.ParentID),
+		formatTime(&task.CreatedAt),
+		formatTime(&task.UpdatedAt),
+		formatTime(task.CompletedAt),
+	}
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil || t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+func value(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
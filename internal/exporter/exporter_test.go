@@ -0,0 +1,92 @@
+package exporter
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/jmoloko/taskmange/internal/importer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func exportTasks() []models.Task {
+	notes := "call back"
+	completed := time.Date(2024, 5, 2, 8, 0, 0, 0, time.UTC)
+	return []models.Task{
+		{
+			ID:          "task1",
+			Title:       "Report, final",
+			Description: "Quarterly \"numbers\"",
+			Status:      models.StatusDone,
+			Priority:    models.PriorityHigh,
+			DueDate:     time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+			Notes:       &notes,
+			Links:       []models.TaskLink{{URL: "https://example.com/a"}, {URL: "https://example.com/b"}},
+			Tags:        []string{"work", "q2"},
+			CompletedAt: &completed,
+		},
+		{ID: "task2", Title: "Call", Status: models.StatusPending, Priority: models.PriorityLow, Private: true},
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	format, err := ParseFormat("")
+	require.NoError(t, err)
+	assert.Equal(t, FormatJSON, format)
+
+	format, err = ParseFormat("XLSX")
+	require.NoError(t, err)
+	assert.Equal(t, FormatXLSX, format)
+
+	_, err = ParseFormat("pdf")
+	assert.Equal(t, importer.ErrUnsupportedFormat, err)
+	assert.Equal(t, "tasks-2024-05-01.csv", FileName(FormatCSV, time.Date(2024, 5, 1, 23, 0, 0, 0, time.UTC)))
+}
+
+func TestWrite_CSV(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, Write(FormatCSV, &buf, exportTasks()))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 3)
+	assert.Equal(t, "id,title,description,status,priority,due_date,private,notes,links,tags,project_id,parent_id,created_at,updated_at,completed_at", lines[0])
+	assert.Equal(t, `task1,"Report, final","Quarterly ""numbers""",done,high,2024-05-01T12:00:00Z,false,call back,https://example.com/a https://example.com/b,"work, q2",,,,,2024-05-02T08:00:00Z`, lines[1])
+
+	// экспорт читается импортом обратно
+	batch, err := importer.Parse(importer.FormatCSV, &buf, 0)
+	require.NoError(t, err)
+	require.Len(t, batch.Rows, 2)
+	task := batch.Rows[0].Task
+	assert.Equal(t, "Report, final", task.Title)
+	assert.Equal(t, []string{"work", "q2"}, task.Tags)
+	assert.Len(t, task.Links, 2)
+	assert.True(t, batch.Rows[1].Task.Private)
+}
+
+func TestWrite_XLSX(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, Write(FormatXLSX, &buf, exportTasks()))
+
+	batch, err := importer.Parse(importer.FormatXLSX, &buf, 0)
+	require.NoError(t, err)
+	require.Len(t, batch.Rows, 2)
+	assert.Equal(t, "Quarterly \"numbers\"", batch.Rows[0].Task.Description)
+	assert.Equal(t, time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC), batch.Rows[0].Task.DueDate)
+	assert.Equal(t, models.PriorityLow, batch.Rows[1].Task.Priority)
+}
+
+func TestWrite_JSON(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, Write(FormatJSON, &buf, nil))
+	assert.Equal(t, "[]", buf.String())
+
+	buf.Reset()
+	require.NoError(t, Write(FormatJSON, &buf, exportTasks()))
+	var tasks []models.Task
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &tasks))
+	assert.Len(t, tasks, 2)
+}
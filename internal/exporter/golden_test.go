@@ -0,0 +1,99 @@
+package exporter
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/jmoloko/taskmange/internal/importer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xuri/excelize/v2"
+)
+
+// update перезаписывает эталонные файлы в testdata: go test ./internal/exporter -update
+var update = flag.Bool("update", false, "rewrite golden files in testdata")
+
+// goldenTasks задачи для эталонных файлов: юникод, кавычки и переводы строк в тексте,
+// задача без срока и без completed_at, задача со всеми полями
+func goldenTasks() []models.Task {
+	notes := "перезвонить после 15:00"
+	projectID, parentID := "project1", "task1"
+	created := time.Date(2024, 4, 20, 9, 15, 0, 0, time.UTC)
+	updated := time.Date(2024, 4, 21, 18, 30, 0, 0, time.FixedZone("MSK", 3*3600))
+	completed := time.Date(2024, 5, 2, 8, 0, 0, 0, time.UTC)
+	return []models.Task{
+		{
+			ID:          "task1",
+			UserID:      "user1",
+			Title:       "Отчёт за квартал, финал 📊",
+			Description: "Проверить \"цифры\"\nи отправить",
+			Status:      models.StatusDone,
+			Priority:    models.PriorityHigh,
+			DueDate:     time.Date(2024, 5, 1, 12, 0, 0, 0, time.FixedZone("MSK", 3*3600)),
+			Notes:       &notes,
+			Links:       []models.TaskLink{{URL: "https://example.com/a"}, {URL: "https://example.com/b?x=1,2"}},
+			Tags:        []string{"работа", "q2"},
+			CreatedAt:   created,
+			UpdatedAt:   updated,
+			CompletedAt: &completed,
+		},
+		{
+			ID:        "task2",
+			UserID:    "user1",
+			Title:     "Купить молоко; 2 л",
+			Status:    models.StatusInProgress,
+			Priority:  models.PriorityMedium,
+			DueDate:   time.Date(2024, 5, 3, 9, 30, 0, 0, time.UTC),
+			ProjectID: &projectID,
+			ParentID:  &parentID,
+			CreatedAt: created,
+			UpdatedAt: created,
+		},
+		{ID: "task3", UserID: "user1", Title: "Без срока", Status: models.StatusPending, Priority: models.PriorityLow, Private: true},
+	}
+}
+
+// golden сравнивает результат с testdata/name, с флагом -update перезаписывает файл
+func golden(t *testing.T, name string, got []byte) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *update {
+		require.NoError(t, os.WriteFile(path, got, 0o644))
+	}
+	want, err := os.ReadFile(path)
+	require.NoError(t, err, "golden file is missing, run go test with -update")
+	assert.Equal(t, string(want), string(got), "output differs from %s, run go test with -update if the change is intended", path)
+}
+
+func TestWrite_Golden(t *testing.T) {
+	for _, format := range []importer.Format{FormatJSON, FormatCSV} {
+		t.Run(string(format), func(t *testing.T) {
+			var buf bytes.Buffer
+			require.NoError(t, Write(format, &buf, goldenTasks()))
+			golden(t, "tasks."+string(format), buf.Bytes())
+		})
+	}
+
+	// XLSX — zip-архив, байты которого зависят от версии excelize, поэтому сравниваются значения ячеек
+	t.Run("xlsx", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, Write(FormatXLSX, &buf, goldenTasks()))
+
+		file, err := excelize.OpenReader(&buf)
+		require.NoError(t, err)
+		defer file.Close()
+		assert.Equal(t, []string{"Tasks"}, file.GetSheetList())
+		rows, err := file.GetRows("Tasks")
+		require.NoError(t, err)
+
+		data, err := json.MarshalIndent(rows, "", "  ")
+		require.NoError(t, err)
+		golden(t, "tasks.xlsx.json", append(data, '\n'))
+	})
+}
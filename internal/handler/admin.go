@@ -0,0 +1,163 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/jmoloko/taskmange/internal/logger"
+	"github.com/jmoloko/taskmange/internal/redact"
+	"github.com/jmoloko/taskmange/internal/service"
+)
+
+// размер страницы списка пользователей: по умолчанию и наибольший
+const (
+	defaultUsersPageSize = 100
+	maxUsersPageSize     = 500
+)
+
+// AdminHandler обрабатывает административные HTTP-запросы: пользователи, роли, удаление задач
+type AdminHandler struct {
+	service   *service.AdminService
+	redaction *redact.Policy
+	logger    logger.Logger
+}
+
+// NewAdminHandler создает новый экземпляр AdminHandler
+func NewAdminHandler(service *service.AdminService, redaction *redact.Policy, logger logger.Logger) *AdminHandler {
+	return &AdminHandler{
+		service:   service,
+		redaction: redaction,
+		logger:    logger,
+	}
+}
+
+// ListUsers список пользователей
+// @Summary List users
+// @Description Get a page of all user accounts ordered by registration date. X-Total-Count holds the total number of users. The redaction profile configured for admin views is always applied, redact adds more profiles (emails replaces addresses with stable pseudonyms)
+// @Tags admin
+// @Produce json
+// @Param limit query int false "Page size, 1-500" default(100)
+// @Param offset query int false "Number of users to skip"
+// @Param redact query string false "Comma-separated redaction profiles, e.g. emails"
+// @Security BearerAuth
+// @Success 200 {array} models.User
+// @Header 200 {integer} X-Total-Count "Total number of users"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Forbidden"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /admin/users [get]
+func (h *AdminHandler) ListUsers(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultUsersPageSize)))
+	if err != nil || limit < 1 || limit > maxUsersPageSize {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid limit, must be between 1 and %d", maxUsersPageSize)})
+		return
+	}
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid offset, must be a non-negative integer"})
+		return
+	}
+	profile, err := h.redaction.Admin(c.Query("redact"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown redaction profile"})
+		return
+	}
+
+	users, total, err := h.service.ListUsers(c.Request.Context(), limit, offset)
+	if err != nil {
+		h.logger.Error("Failed to list users: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list users"})
+		return
+	}
+	for i := range users {
+		users[i].Email = profile.Email(users[i].Email)
+	}
+
+	c.Header("X-Total-Count", strconv.Itoa(total))
+	c.JSON(http.StatusOK, users)
+}
+
+// SetUserRole смена роли пользователя
+// @Summary Set user role
+// @Description Set the role of a user: user or admin. The new role is put into tokens issued after the change, so it takes effect on the user's next login (access tokens live 15 minutes). Admins cannot change their own role. The change is written to the audit log
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param request body models.SetRoleRequest true "New role"
+// @Security BearerAuth
+// @Success 200 {object} models.User
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Forbidden"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /admin/users/{id}/role [put]
+func (h *AdminHandler) SetUserRole(c *gin.Context) {
+	adminID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var req models.SetRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "role is required"})
+		return
+	}
+
+	user, err := h.service.SetRole(c.Request.Context(), adminID.(string), c.Param("id"), req.Role)
+	if err != nil {
+		switch err {
+		case service.ErrInvalidRole:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "role must be user or admin"})
+		case service.ErrSelfRoleChange:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Cannot change your own role"})
+		case service.ErrUserNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		default:
+			h.logger.Error("Failed to set user role: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to set user role"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+// DeleteTask удаление задачи любого пользователя
+// @Summary Delete any task
+// @Description Delete a task of any user, e.g. abusive content. The deletion triggers the same task.deleted event as a deletion by the owner and is written to the audit log
+// @Tags admin
+// @Produce json
+// @Param id path string true "Task ID"
+// @Security BearerAuth
+// @Success 204 "No Content"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Forbidden"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /admin/tasks/{id} [delete]
+func (h *AdminHandler) DeleteTask(c *gin.Context) {
+	adminID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	if err := h.service.DeleteTask(c.Request.Context(), adminID.(string), c.Param("id")); err != nil {
+		if err == service.ErrTaskNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+			return
+		}
+		h.logger.Error("Failed to delete task: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete task"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
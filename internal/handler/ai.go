@@ -0,0 +1,158 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/jmoloko/taskmange/internal/logger"
+	"github.com/jmoloko/taskmange/internal/service"
+)
+
+// AIHandler обрабатывает HTTP-запросы к AI-ассистенту задач и поиску похожих задач
+type AIHandler struct {
+	service    *service.AIService
+	similarity *service.SimilarityService
+	logger     logger.Logger
+}
+
+// NewAIHandler создает новый экземпляр AIHandler
+func NewAIHandler(service *service.AIService, similarity *service.SimilarityService, logger logger.Logger) *AIHandler {
+	return &AIHandler{
+		service:    service,
+		similarity: similarity,
+		logger:     logger,
+	}
+}
+
+// SummarizeTask краткое содержание задачи
+// @Summary Summarize a task
+// @Description Get a short summary of the task generated by the configured language model. The result is stored with the task and returned with cached=true until the title, description or notes change. Private tasks are never sent to the model
+// @Tags tasks
+// @Produce json
+// @Param id path string true "Task ID"
+// @Param refresh query bool false "Generate a new result instead of the stored one"
+// @Security BearerAuth
+// @Success 200 {object} models.AIResult
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Forbidden"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 502 {object} map[string]string "AI assistant is unavailable"
+// @Failure 503 {object} map[string]string "AI assistant is not configured"
+// @Failure 504 {object} map[string]string "AI assistant timed out"
+// @Router /tasks/{id}/summarize [post]
+func (h *AIHandler) SummarizeTask(c *gin.Context) {
+	h.run(c, h.service.Summarize)
+}
+
+// SuggestSubtasks предложение подзадач
+// @Summary Suggest subtasks
+// @Description Get subtask titles for the task suggested by the configured language model. Subtasks are not created: create the chosen ones with parent_id. The result is stored with the task like the summary
+// @Tags tasks
+// @Produce json
+// @Param id path string true "Task ID"
+// @Param refresh query bool false "Generate a new result instead of the stored one"
+// @Security BearerAuth
+// @Success 200 {object} models.AIResult
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Forbidden"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 502 {object} map[string]string "AI assistant is unavailable"
+// @Failure 503 {object} map[string]string "AI assistant is not configured"
+// @Failure 504 {object} map[string]string "AI assistant timed out"
+// @Router /tasks/{id}/suggest-subtasks [post]
+func (h *AIHandler) SuggestSubtasks(c *gin.Context) {
+	h.run(c, h.service.SuggestSubtasks)
+}
+
+// run общая часть действий ассистента: параметры запроса и ответ на ошибки
+func (h *AIHandler) run(c *gin.Context, action func(ctx context.Context, userID, taskID string, refresh bool) (*models.AIResult, error)) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	result, err := action(c.Request.Context(), userID.(string), c.Param("id"), c.Query("refresh") == "true")
+	if err != nil {
+		switch err {
+		case service.ErrTaskNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+		case service.ErrAccessDenied:
+			c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+		case service.ErrAIPrivateTask:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "AI assistant is not available for private tasks"})
+		case service.ErrAIDisabled:
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "AI assistant is not configured"})
+		case service.ErrAITimeout:
+			c.JSON(http.StatusGatewayTimeout, gin.H{"error": "AI assistant timed out"})
+		case service.ErrAIUnavailable:
+			c.JSON(http.StatusBadGateway, gin.H{"error": "AI assistant is unavailable"})
+		default:
+			h.logger.Error("Failed to run AI assistant: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to run AI assistant"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// SimilarTasks похожие задачи
+// @Summary List similar tasks
+// @Description Get tasks of the user similar in meaning to the given one, e.g. possible duplicates, ordered by score (1 — same meaning). Title and description are compared using embeddings; private tasks are never sent to the model and are not suggested
+// @Tags tasks
+// @Produce json
+// @Param id path string true "Task ID"
+// @Param limit query int false "Maximum number of tasks (1-20)" default(5)
+// @Security BearerAuth
+// @Success 200 {array} models.SimilarTask
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Forbidden"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 502 {object} map[string]string "Embedding model is unavailable"
+// @Failure 503 {object} map[string]string "Similar tasks are not configured"
+// @Failure 504 {object} map[string]string "Embedding model timed out"
+// @Router /tasks/{id}/similar [get]
+func (h *AIHandler) SimilarTasks(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(service.DefaultSimilarLimit)))
+	if err != nil || limit < 1 || limit > service.MaxSimilarLimit {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be a number between 1 and 20"})
+		return
+	}
+
+	similar, err := h.similarity.Similar(c.Request.Context(), userID.(string), c.Param("id"), limit)
+	if err != nil {
+		switch err {
+		case service.ErrTaskNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+		case service.ErrAccessDenied:
+			c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+		case service.ErrAIPrivateTask:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Similar tasks are not available for private tasks"})
+		case service.ErrSimilarityDisabled:
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Similar tasks are not configured"})
+		case service.ErrAITimeout:
+			c.JSON(http.StatusGatewayTimeout, gin.H{"error": "Embedding model timed out"})
+		case service.ErrAIUnavailable:
+			c.JSON(http.StatusBadGateway, gin.H{"error": "Embedding model is unavailable"})
+		default:
+			h.logger.Error("Failed to find similar tasks: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to find similar tasks"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, similar)
+}
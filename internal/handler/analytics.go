@@ -0,0 +1,64 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoloko/taskmange/internal/logger"
+	"github.com/jmoloko/taskmange/internal/service"
+)
+
+// AnalyticsHandler обрабатывает HTTP-запросы исторической аналитики
+type AnalyticsHandler struct {
+	service *service.AnalyticsHistoryService
+	logger  logger.Logger
+}
+
+// NewAnalyticsHandler создает новый экземпляр AnalyticsHandler
+func NewAnalyticsHandler(service *service.AnalyticsHistoryService, logger logger.Logger) *AnalyticsHandler {
+	return &AnalyticsHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// GetAnalyticsHistory история аналитики
+// @Summary Get analytics history
+// @Description Get daily analytics snapshots of the current user for trend charts
+// @Tags tasks
+// @Produce json
+// @Param days query int false "Number of days including today (1-365)" default(30)
+// @Security BearerAuth
+// @Success 200 {array} models.AnalyticsSnapshot
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 503 {object} map[string]string "Server is busy"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /task-analytics/history [get]
+func (h *AnalyticsHandler) GetAnalyticsHistory(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	days, err := strconv.Atoi(c.DefaultQuery("days", "30"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "days must be a number"})
+		return
+	}
+
+	snapshots, err := h.service.History(c.Request.Context(), userID.(string), days)
+	if err != nil {
+		if err == service.ErrInvalidHistoryRange {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "days must be between 1 and 365"})
+			return
+		}
+		h.logger.Error("Failed to get analytics history: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get analytics history"})
+		return
+	}
+
+	c.JSON(http.StatusOK, snapshots)
+}
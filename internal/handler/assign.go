@@ -0,0 +1,96 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/jmoloko/taskmange/internal/service"
+)
+
+// AssignTask назначение задачи исполнителю
+// @Summary Assign a task
+// @Description Assign a task of the current user to another user. The assignee sees it with assignee=me in the task list and can read and update it, but cannot delete or reassign it. Private tasks cannot be assigned
+// @Tags tasks
+// @Accept json
+// @Produce json
+// @Param id path string true "Task ID"
+// @Param assignment body models.AssignTaskRequest true "Assignee"
+// @Security BearerAuth
+// @Success 200 {object} models.Task
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Forbidden"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /tasks/{id}/assignee [put]
+func (h *TaskHandler) AssignTask(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var req models.AssignTaskRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	task, err := h.service.AssignTask(c.Request.Context(), userID.(string), c.Param("id"), req.AssigneeID)
+	if err != nil {
+		h.assignError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, task)
+}
+
+// UnassignTask снятие назначения задачи
+// @Summary Unassign a task
+// @Description Remove the assignee of a task. Allowed for the task owner and for the assignee
+// @Tags tasks
+// @Produce json
+// @Param id path string true "Task ID"
+// @Security BearerAuth
+// @Success 200 {object} models.Task
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Forbidden"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /tasks/{id}/assignee [delete]
+func (h *TaskHandler) UnassignTask(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	task, err := h.service.UnassignTask(c.Request.Context(), userID.(string), c.Param("id"))
+	if err != nil {
+		h.assignError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, task)
+}
+
+// assignError ответ на ошибку назначения задачи
+func (h *TaskHandler) assignError(c *gin.Context, err error) {
+	switch err {
+	case service.ErrTaskNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+	case service.ErrAccessDenied:
+		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+	case service.ErrInvalidAssignee:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Assignee must be another user"})
+	case service.ErrPrivateTaskAssignment:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Private tasks cannot be assigned"})
+	default:
+		if constraintError(c, err) {
+			return
+		}
+		h.logger.Error("Failed to assign task: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to assign task"})
+	}
+}
@@ -1,11 +1,13 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 	"github.com/jmoloko/taskmange/internal/domain/models"
 	"github.com/jmoloko/taskmange/internal/logger"
+	"github.com/jmoloko/taskmange/internal/passwordpolicy"
 	"github.com/jmoloko/taskmange/internal/service"
 )
 
@@ -44,14 +46,18 @@ func (h *AuthHandler) Register(c *gin.Context) {
 	}
 
 	if err := h.service.Register(c.Request.Context(), req); err != nil {
+		if passwordViolation(c, err) {
+			return
+		}
 		switch err {
 		case service.ErrUserExists:
 			c.JSON(http.StatusConflict, gin.H{"error": "User already exists"})
 		case service.ErrInvalidEmail:
 			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid email format"})
-		case service.ErrInvalidPassword:
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Password must be at least 6 characters"})
 		default:
+			if constraintError(c, err) {
+				return
+			}
 			h.logger.Error("Failed to register user: %v", err)
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to register user"})
 		}
@@ -64,17 +70,74 @@ func (h *AuthHandler) Register(c *gin.Context) {
 	})
 }
 
+// ChangePassword смена пароля текущего пользователя
+// @Summary Change password
+// @Description Change the authenticated user's password. The new password must satisfy the password policy; violated requirements are listed in reasons. Not available with an impersonation token
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.ChangePasswordRequest true "Current and new password"
+// @Success 204 "Password changed"
+// @Failure 400 {object} map[string]interface{} "Bad Request or policy violation"
+// @Failure 401 {object} map[string]string "Unauthorized - Invalid current password"
+// @Failure 403 {object} map[string]string "Forbidden under impersonation"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /auth/password [put]
+func (h *AuthHandler) ChangePassword(c *gin.Context) {
+	// администратор под имперсонацией не должен менять пароль чужой учетной записи
+	if c.GetString("impersonator_id") != "" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Password change is not allowed during impersonation"})
+		return
+	}
+
+	var req models.ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	if err := h.service.ChangePassword(c.Request.Context(), c.GetString("user_id"), req); err != nil {
+		if passwordViolation(c, err) {
+			return
+		}
+		switch err {
+		case service.ErrInvalidCredentials:
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid current password"})
+		case service.ErrUserNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		default:
+			h.logger.Error("Failed to change password: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to change password"})
+		}
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// passwordViolation отвечает 400 со списком нарушенных требований, если пароль не прошел политику
+func passwordViolation(c *gin.Context, err error) bool {
+	var violation *passwordpolicy.Violation
+	if !errors.As(err, &violation) {
+		return false
+	}
+	c.JSON(http.StatusBadRequest, gin.H{"error": "Password does not meet the policy", "reasons": violation.Reasons})
+	return true
+}
+
 // Login аутентификация пользователя
 // Login handles user authentication
 // @Summary Login user
-// @Description Authenticate user and return JWT token
+// @Description Authenticate user and return JWT token with its expiry and a minimal user profile
 // @Tags auth
 // @Accept json
 // @Produce json
 // @Param credentials body models.LoginRequest true "User login credentials"
-// @Success 200 {object} map[string]string "Token"
+// @Success 200 {object} models.LoginResponse "Token"
 // @Failure 400 {object} map[string]string "Bad Request"
 // @Failure 401 {object} map[string]string "Unauthorized - Invalid credentials"
+// @Failure 403 {object} map[string]string "Account is deactivated"
 // @Failure 500 {object} map[string]string "Internal Server Error"
 // @Router /auth/login [post]
 func (h *AuthHandler) Login(c *gin.Context) {
@@ -85,18 +148,54 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
-	token, err := h.service.Login(c.Request.Context(), req)
+	resp, err := h.service.Login(c.Request.Context(), req)
 	if err != nil {
 		if err == service.ErrInvalidCredentials {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
 			return
 		}
+		if err == service.ErrUserDeactivated {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Account is deactivated"})
+			return
+		}
 		h.logger.Error("Failed to login user: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to login user"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"token": token})
+	c.JSON(http.StatusOK, resp)
+}
+
+// Me профиль текущего пользователя
+// @Summary Current user
+// @Description Return the authenticated user's id, email and roles for client bootstrap. With an impersonation token impersonator_id is set and the admin role is never reported
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} models.WhoAmI
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 404 {object} map[string]string "User not found"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /auth/me [get]
+func (h *AuthHandler) Me(c *gin.Context) {
+	claims := models.TokenClaims{
+		UserID:          c.GetString("user_id"),
+		Role:            c.GetString("role"),
+		ImpersonatorID:  c.GetString("impersonator_id"),
+		ImpersonationID: c.GetString("impersonation_id"),
+	}
+	me, err := h.service.WhoAmI(c.Request.Context(), claims)
+	if err != nil {
+		if err == service.ErrUserNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			return
+		}
+		h.logger.Error("Failed to get current user: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get current user"})
+		return
+	}
+
+	c.JSON(http.StatusOK, me)
 }
 
 // GetService возвращает сервис аутентификации
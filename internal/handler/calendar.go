@@ -0,0 +1,133 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoloko/taskmange/internal/calendar"
+	"github.com/jmoloko/taskmange/internal/clock"
+	"github.com/jmoloko/taskmange/internal/logger"
+	"github.com/jmoloko/taskmange/internal/service"
+)
+
+// CalendarHandler обрабатывает HTTP-запросы ленты календаря
+type CalendarHandler struct {
+	service *service.CalendarService
+	clock   clock.Clock
+	logger  logger.Logger
+}
+
+// NewCalendarHandler создает новый экземпляр CalendarHandler
+func NewCalendarHandler(service *service.CalendarService, logger logger.Logger) *CalendarHandler {
+	return &CalendarHandler{
+		service: service,
+		clock:   clock.System,
+		logger:  logger,
+	}
+}
+
+// GetService возвращает сервис ленты календаря
+func (h *CalendarHandler) GetService() *service.CalendarService {
+	return h.service
+}
+
+// GetCalendar лента задач со сроком в формате iCalendar
+// @Summary Get tasks as an iCalendar feed
+// @Description Get the user's tasks with a due date from 90 days ago onwards as an iCalendar (RFC 5545) feed. Calendar apps cannot send a Bearer header, so the feed also accepts the subscription token from POST /tasks/calendar/token in the token parameter. By default every task is a VEVENT at its due time; kind=todo returns VTODO entries with the due date and completion status instead. Private tasks appear as "Private task" without a description
+// @Tags tasks
+// @Produce text/calendar
+// @Param token query string false "Calendar subscription token, replaces the Bearer header"
+// @Param kind query string false "Entry type: event or todo" default(event)
+// @Security BearerAuth
+// @Success 200 {string} string "iCalendar feed"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Account is deactivated"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /tasks/calendar.ics [get]
+func (h *CalendarHandler) GetCalendar(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	kind, err := calendar.ParseKind(c.Query("kind"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid kind, supported values: event, todo"})
+		return
+	}
+
+	tasks, err := h.service.Tasks(c.Request.Context(), userID.(string))
+	if err != nil {
+		h.logger.Error("Failed to get calendar tasks: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get calendar"})
+		return
+	}
+
+	c.Header("Content-Type", calendar.ContentType)
+	c.Header("Content-Disposition", `inline; filename="tasks.ics"`)
+	c.Status(http.StatusOK)
+	feed := calendar.Feed{Name: "Tasks", Kind: kind, Now: h.clock.Now()}
+	if err := calendar.Write(c.Writer, feed, tasks); err != nil {
+		// заголовки уже отправлены, клиент получит оборванную ленту
+		h.logger.Error("Failed to write calendar: %v", err)
+	}
+}
+
+// CreateCalendarFeed выпуск ссылки подписки календаря
+// @Summary Create a calendar subscription link
+// @Description Create a signed link to GET /tasks/calendar.ics that calendar apps can subscribe to without a Bearer header. The link only gives read access to the feed. Creating a link again revokes the previous one
+// @Tags tasks
+// @Produce json
+// @Security BearerAuth
+// @Success 201 {object} models.CalendarFeed
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /tasks/calendar/token [post]
+func (h *CalendarHandler) CreateCalendarFeed(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	feed, err := h.service.CreateFeed(c.Request.Context(), userID.(string))
+	if err != nil {
+		h.logger.Error("Failed to create calendar feed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create calendar link"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, feed)
+}
+
+// DeleteCalendarFeed отзыв ссылки подписки календаря
+// @Summary Revoke the calendar subscription link
+// @Description Revoke the calendar subscription link; subscribed calendars stop receiving updates
+// @Tags tasks
+// @Security BearerAuth
+// @Success 204 "No Content"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 404 {object} map[string]string "Calendar link is not created"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /tasks/calendar/token [delete]
+func (h *CalendarHandler) DeleteCalendarFeed(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	if err := h.service.DeleteFeed(c.Request.Context(), userID.(string)); err != nil {
+		if err == service.ErrCalendarFeedNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Calendar link is not created"})
+			return
+		}
+		h.logger.Error("Failed to delete calendar feed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke calendar link"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,167 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/jmoloko/taskmange/internal/logger"
+	"github.com/jmoloko/taskmange/internal/service"
+)
+
+// CalendarSyncHandler обрабатывает HTTP-запросы синхронизации с календарями Google и Apple
+type CalendarSyncHandler struct {
+	service *service.CalendarSyncService
+	logger  logger.Logger
+}
+
+// NewCalendarSyncHandler создает новый экземпляр CalendarSyncHandler
+func NewCalendarSyncHandler(service *service.CalendarSyncService, logger logger.Logger) *CalendarSyncHandler {
+	return &CalendarSyncHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// ListCalendarLinks подключенные календари
+// @Summary List connected calendars
+// @Description Get Google and Apple calendars whose events are kept in sync with the due dates of the current user's tasks. last_error is the error of the last sync, empty after a successful one
+// @Tags calendars
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} models.CalendarLink
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /integrations/calendars [get]
+func (h *CalendarSyncHandler) ListCalendarLinks(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	links, err := h.service.List(c.Request.Context(), userID.(string))
+	if err != nil {
+		h.logger.Error("Failed to get calendar links: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get calendars"})
+		return
+	}
+
+	c.JSON(http.StatusOK, links)
+}
+
+// ConnectCalendar подключение календаря
+// @Summary Connect a calendar
+// @Description Connect a Google or Apple calendar for two-way due date sync. Open tasks with an upcoming due date become events at their due time; moving such an event in the calendar changes the task's due date. Google needs a refresh token issued to the server's OAuth client with the calendar.events scope, calendar_id defaults to primary. Apple needs the Apple ID, an app-specific password and the https CalDAV collection URL of the calendar in calendar_id; addresses in loopback, private and link-local networks are rejected. When both the task and its event were rescheduled since the last sync, conflict_policy decides: latest (default) keeps the later change, task or calendar always keeps that side. Private tasks appear as "Private task" without a description
+// @Tags calendars
+// @Accept json
+// @Produce json
+// @Param request body models.CalendarLinkRequest true "Calendar"
+// @Security BearerAuth
+// @Success 201 {object} models.CalendarLink
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 409 {object} map[string]string "Conflict"
+// @Failure 422 {object} map[string]string "Calendar rejected credentials"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Failure 503 {object} map[string]string "Calendar provider is not configured"
+// @Router /integrations/calendars [post]
+func (h *CalendarSyncHandler) ConnectCalendar(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var req models.CalendarLinkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	link, err := h.service.Connect(c.Request.Context(), userID.(string), req)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidCalendarLink):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, service.ErrCalendarLinkExists):
+			c.JSON(http.StatusConflict, gin.H{"error": "Calendar is already connected"})
+		case errors.Is(err, service.ErrCalendarAuth):
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "Calendar rejected credentials"})
+		case errors.Is(err, service.ErrCalendarUnavailable):
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Calendar provider is not configured"})
+		default:
+			h.logger.Error("Failed to connect calendar: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to connect calendar"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, link)
+}
+
+// DisconnectCalendar отключение календаря
+// @Summary Disconnect a calendar
+// @Description Stop syncing a calendar. Events created by the sync stay in the calendar
+// @Tags calendars
+// @Produce json
+// @Param id path string true "Calendar link ID"
+// @Security BearerAuth
+// @Success 204 "No Content"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /integrations/calendars/{id} [delete]
+func (h *CalendarSyncHandler) DisconnectCalendar(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	if err := h.service.Disconnect(c.Request.Context(), userID.(string), c.Param("id")); err != nil {
+		if err == service.ErrCalendarLinkNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Calendar link not found"})
+			return
+		}
+		h.logger.Error("Failed to disconnect calendar: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to disconnect calendar"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ReceiveGoogleNotification уведомление Google об изменении календаря
+// @Summary Receive a Google Calendar notification
+// @Description Endpoint for Google Calendar push notifications of connected calendars. The channel token header is verified against the token issued when the channel was opened; changed events are pulled and their start times applied to the tasks' due dates
+// @Tags calendars
+// @Param X-Goog-Channel-ID header string true "Notification channel ID"
+// @Param X-Goog-Channel-Token header string true "Notification channel token"
+// @Param X-Goog-Resource-State header string true "sync or exists"
+// @Success 200 "OK"
+// @Failure 403 {object} map[string]string "Forbidden"
+// @Failure 404 {object} map[string]string "Unknown channel"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /integrations/calendars/google/webhook [post]
+func (h *CalendarSyncHandler) ReceiveGoogleNotification(c *gin.Context) {
+	err := h.service.HandleGoogleNotification(c.Request.Context(), c.GetHeader("X-Goog-Channel-ID"),
+		c.GetHeader("X-Goog-Channel-Token"), c.GetHeader("X-Goog-Resource-State"))
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidCalendarChannel) {
+			// Google перестает слать уведомления в канал, на которые отвечают 404
+			c.JSON(http.StatusNotFound, gin.H{"error": "Unknown channel"})
+			return
+		}
+		if errors.Is(err, service.ErrUserDeactivated) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Account is deactivated"})
+			return
+		}
+		h.logger.Error("Failed to handle calendar notification: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to sync calendar"})
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
@@ -0,0 +1,200 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/jmoloko/taskmange/internal/logger"
+	"github.com/jmoloko/taskmange/internal/redact"
+	"github.com/jmoloko/taskmange/internal/service"
+)
+
+// changeFeedPoll как часто поток журнала изменений проверяет новые записи. Журнал читается из базы,
+// поэтому поток одинаково получает изменения, записанные любым экземпляром
+const changeFeedPoll = time.Second
+
+// changeFeedPage сколько записей поток читает за один запрос к журналу
+const changeFeedPage = 100
+
+// ChangeFeedHandler обрабатывает HTTP-запросы журнала изменений задач (CDC)
+type ChangeFeedHandler struct {
+	service   *service.ChangeFeedService
+	redaction *redact.Policy
+	logger    logger.Logger
+}
+
+// NewChangeFeedHandler создает новый экземпляр ChangeFeedHandler
+func NewChangeFeedHandler(service *service.ChangeFeedService, redaction *redact.Policy, logger logger.Logger) *ChangeFeedHandler {
+	return &ChangeFeedHandler{
+		service:   service,
+		redaction: redaction,
+		logger:    logger,
+	}
+}
+
+// GetTaskChanges страница журнала изменений задач
+// @Summary Get the task change feed
+// @Description Get task changes of all users after the given sequence number, in the order they were recorded, for replication into a data warehouse. Every change has a global seq and a per-task version; apply a change only if its version is greater than the stored one. Pass next as after in the following request; an empty page returns the same next. Private tasks are returned without title and description, the export redaction profile is applied. Changes are kept for CDC_RETENTION
+// @Tags admin
+// @Produce json
+// @Param after query int false "Sequence number of the last received change" default(0)
+// @Param limit query int false "Number of changes (1-1000)" default(100)
+// @Param redact query string false "Comma-separated redaction profiles, e.g. descriptions"
+// @Security BearerAuth
+// @Success 200 {object} models.TaskChangeFeed
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Forbidden"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Failure 503 {object} map[string]string "Change feed is not enabled"
+// @Router /admin/changes [get]
+func (h *ChangeFeedHandler) GetTaskChanges(c *gin.Context) {
+	after, err := strconv.ParseInt(c.DefaultQuery("after", "0"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "after must be a number"})
+		return
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "0"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be a number"})
+		return
+	}
+	profile, err := h.redaction.Export(c.Query("redact"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown redaction profile"})
+		return
+	}
+
+	feed, err := h.service.Changes(c.Request.Context(), after, limit)
+	if err != nil {
+		h.changeFeedError(c, err)
+		return
+	}
+
+	redactChanges(profile, feed.Changes)
+	c.JSON(http.StatusOK, feed)
+}
+
+// StreamTaskChanges поток журнала изменений задач
+// @Summary Stream the task change feed
+// @Description Stream task changes of all users as Server-Sent Events of type change, starting after the after parameter or the Last-Event-ID header (EventSource sends it after a reconnect). The event id is the change seq, data is the change as in GET /admin/changes. New changes are checked every second, comment lines are sent every 15 seconds to keep the connection open
+// @Tags admin
+// @Produce text/event-stream
+// @Param after query int false "Sequence number of the last received change" default(0)
+// @Param Last-Event-ID header string false "Sequence number of the last received change, takes precedence over after"
+// @Param redact query string false "Comma-separated redaction profiles, e.g. descriptions"
+// @Security BearerAuth
+// @Success 200 {string} string "Change stream"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Forbidden"
+// @Failure 503 {object} map[string]string "Change feed is not enabled"
+// @Router /admin/changes/stream [get]
+func (h *ChangeFeedHandler) StreamTaskChanges(c *gin.Context) {
+	cursor := c.GetHeader("Last-Event-ID")
+	if cursor == "" {
+		cursor = c.DefaultQuery("after", "0")
+	}
+	after, err := strconv.ParseInt(cursor, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "after must be a number"})
+		return
+	}
+	profile, err := h.redaction.Export(c.Query("redact"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown redaction profile"})
+		return
+	}
+
+	// первая страница читается до заголовков потока, чтобы ошибки пришли обычным ответом
+	ctx := c.Request.Context()
+	feed, err := h.service.Changes(ctx, after, changeFeedPage)
+	if err != nil {
+		h.changeFeedError(c, err)
+		return
+	}
+
+	// поток живет дольше WriteTimeout сервера; без поддержки дедлайнов (в тестах) действует обычный
+	_ = http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{})
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	fmt.Fprintf(c.Writer, "retry: %d\n\n", sseRetry)
+
+	poll := time.NewTicker(changeFeedPoll)
+	defer poll.Stop()
+	lastWrite := time.Now()
+
+	for {
+		redactChanges(profile, feed.Changes)
+		for _, change := range feed.Changes {
+			if err := writeChange(c.Writer, change); err != nil {
+				return
+			}
+			lastWrite = time.Now()
+		}
+		if time.Since(lastWrite) >= sseHeartbeat {
+			if _, err := io.WriteString(c.Writer, ": ping\n\n"); err != nil {
+				return
+			}
+			lastWrite = time.Now()
+		}
+		c.Writer.Flush()
+
+		// полная страница значит, что записи еще есть: следующая читается сразу
+		if len(feed.Changes) < changeFeedPage {
+			select {
+			case <-ctx.Done():
+				return
+			case <-poll.C:
+			}
+		}
+
+		feed, err = h.service.Changes(ctx, feed.Next, changeFeedPage)
+		if err != nil {
+			if ctx.Err() == nil {
+				h.logger.Error("Failed to read task changes: %v", err)
+			}
+			return
+		}
+	}
+}
+
+func (h *ChangeFeedHandler) changeFeedError(c *gin.Context, err error) {
+	switch err {
+	case service.ErrChangeFeedDisabled:
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Change feed is not enabled"})
+	case service.ErrInvalidChangeFeedQuery:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "after must not be negative and limit must be between 1 and 1000"})
+	default:
+		h.logger.Error("Failed to get task changes: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get task changes"})
+	}
+}
+
+// redactChanges применяет профиль экспорта к задачам записей журнала
+func redactChanges(profile redact.Profile, changes []models.TaskChange) {
+	for i := range changes {
+		changes[i].Task = profile.Task(changes[i].Task)
+		changes[i].Changes = profile.Changes(changes[i].Changes)
+	}
+}
+
+// writeChange запись журнала в формате SSE, id — seq записи
+func writeChange(w io.Writer, change models.TaskChange) error {
+	data, err := json.Marshal(change)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: change\ndata: %s\n\n", change.Seq, data)
+	return err
+}
@@ -0,0 +1,31 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoloko/taskmange/internal/domain/repository"
+)
+
+// constraintError отвечает клиенту, если err — нарушение ограничения базы: повторяющееся
+// уникальное значение дает 409, ссылка на несуществующую запись и недопустимое значение — 400.
+// Возвращает false для остальных ошибок, их обработчик отвечает как раньше
+func constraintError(c *gin.Context, err error) bool {
+	var violation *repository.ConstraintError
+	if !errors.As(err, &violation) {
+		return false
+	}
+
+	status := http.StatusBadRequest
+	if errors.Is(violation, repository.ErrAlreadyExists) {
+		status = http.StatusConflict
+	}
+
+	body := gin.H{"error": violation.Error()}
+	if violation.Field != "" {
+		body["field"] = violation.Field
+	}
+	c.JSON(status, body)
+	return true
+}
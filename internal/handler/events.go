@@ -0,0 +1,143 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoloko/taskmange/internal/logger"
+	"github.com/jmoloko/taskmange/internal/realtime"
+)
+
+// sseHeartbeat период комментария-пинга в потоке: держит соединение открытым через прокси
+// и быстрее обнаруживает отключившихся клиентов
+const sseHeartbeat = 15 * time.Second
+
+// sseRetry через сколько миллисекунд EventSource переподключается после обрыва
+const sseRetry = 3000
+
+// EventsHandler поток событий задач через Server-Sent Events для клиентов без WebSocket
+type EventsHandler struct {
+	hub    *realtime.Hub
+	logger logger.Logger
+}
+
+// NewEventsHandler создает новый экземпляр EventsHandler
+func NewEventsHandler(hub *realtime.Hub, logger logger.Logger) *EventsHandler {
+	return &EventsHandler{
+		hub:    hub,
+		logger: logger,
+	}
+}
+
+// StreamTaskEvents поток событий задач
+// @Summary Stream task events
+// @Description Stream task change events (task.created, task.updated, task.completed, task.deleted, tasks.completed, tasks.deleted) of the current user and of tasks assigned to them as Server-Sent Events. Each event has an id; after a reconnect send it in the Last-Event-ID header (EventSource does this itself) to get the missed events. If they are no longer kept, a resync event is sent first and the client should reload its tasks. Comment lines are sent every 15 seconds to keep the connection open
+// @Tags tasks
+// @Produce text/event-stream
+// @Param Last-Event-ID header string false "ID of the last received event"
+// @Security BearerAuth
+// @Success 200 {string} string "Event stream"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 429 {object} map[string]string "Too many connections"
+// @Failure 503 {object} map[string]string "Server is shutting down"
+// @Router /tasks/events [get]
+func (h *EventsHandler) StreamTaskEvents(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var (
+		client   *realtime.Client
+		complete = true
+		err      error
+	)
+	if lastEventID := c.GetHeader("Last-Event-ID"); lastEventID != "" {
+		// нечитаемый ID равносилен потерянным событиям
+		id, _ := strconv.ParseUint(lastEventID, 10, 64)
+		client, complete, err = h.hub.Resume(userID.(string), id)
+	} else {
+		client, err = h.hub.Subscribe(userID.(string))
+	}
+	switch err {
+	case nil:
+	case realtime.ErrTooManyConnections:
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": "Too many event stream connections"})
+		return
+	case realtime.ErrHubClosed:
+		c.Header("Retry-After", "5")
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Server is shutting down"})
+		return
+	default:
+		h.logger.Error("Failed to subscribe to task events: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to subscribe to task events"})
+		return
+	}
+	defer h.hub.Unsubscribe(client)
+
+	// поток живет дольше WriteTimeout сервера; без поддержки дедлайнов (в тестах) действует обычный
+	_ = http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{})
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	// nginx иначе буферизует ответ и события приходят пачками
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	fmt.Fprintf(c.Writer, "retry: %d\n\n", sseRetry)
+	if !complete {
+		writeResync(c.Writer)
+	}
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(sseHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case <-client.Done():
+			return
+		case <-heartbeat.C:
+			if _, err := io.WriteString(c.Writer, ": ping\n\n"); err != nil {
+				return
+			}
+		case <-client.Notify():
+			events := client.Drain()
+			// вытесненные из буфера события не восстановить, клиент перечитывает данные
+			if client.Overflowed() {
+				writeResync(c.Writer)
+			}
+			for _, event := range events {
+				if err := writeEvent(c.Writer, event); err != nil {
+					h.logger.Error("Failed to write task event: %v", err)
+					return
+				}
+			}
+		}
+		c.Writer.Flush()
+	}
+}
+
+// writeEvent событие в формате SSE: id, тип и JSON в одной строке data
+func writeEvent(w io.Writer, event realtime.Event) error {
+	data, err := json.Marshal(event.Data)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+	return err
+}
+
+// writeResync событие resync: часть событий потеряна, клиенту нужно перечитать задачи
+func writeResync(w io.Writer) {
+	io.WriteString(w, "event: resync\ndata: {}\n\n")
+}
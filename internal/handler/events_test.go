@@ -0,0 +1,78 @@
+package handler
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoloko/taskmange/internal/config"
+	"github.com/jmoloko/taskmange/internal/realtime"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStreamTaskEvents(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	hub := realtime.NewHub(config.RealtimeConfig{ReplayWindow: time.Minute})
+	handler := NewEventsHandler(hub, new(MockLogger))
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("user_id", "test_user")
+		c.Next()
+	})
+	router.GET("/tasks/events", handler.StreamTaskEvents)
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	// читает поток до первого события с данными и возвращает его поля
+	read := func(lastEventID string) map[string]string {
+		req, err := http.NewRequest(http.MethodGet, server.URL+"/tasks/events", nil)
+		require.NoError(t, err)
+		if lastEventID != "" {
+			req.Header.Set("Last-Event-ID", lastEventID)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+		fields := map[string]string{}
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			line := scanner.Text()
+			if line == "" && fields["data"] != "" {
+				return fields
+			}
+			if name, value, ok := strings.Cut(line, ": "); ok {
+				fields[name] = value
+			}
+			if strings.HasPrefix(line, "retry: ") && lastEventID == "" {
+				// подключение установлено, событие публикуется после подписки
+				go hub.Publish("test_user", realtime.Event{Type: "task.created", Data: map[string]string{"id": "1"}})
+			}
+		}
+		t.Fatal("stream ended without an event")
+		return nil
+	}
+
+	first := read("")
+	assert.Equal(t, "task.created", first["event"])
+	assert.JSONEq(t, `{"id":"1"}`, first["data"])
+
+	hub.Publish("test_user", realtime.Event{Type: "task.deleted", Data: map[string]string{"id": "1"}})
+	resumed := read(first["id"])
+	assert.Equal(t, "task.deleted", resumed["event"])
+
+	id, err := strconv.ParseUint(resumed["id"], 10, 64)
+	require.NoError(t, err)
+	assert.Greater(t, id, uint64(0))
+
+	// неизвестный ID: события могли быть потеряны
+	assert.Equal(t, "resync", read("garbage")["event"])
+}
@@ -0,0 +1,144 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/jmoloko/taskmange/internal/logger"
+	"github.com/jmoloko/taskmange/internal/service"
+)
+
+// ExternalRefHandler обрабатывает HTTP-запросы связей задач с GitHub и Jira
+type ExternalRefHandler struct {
+	service *service.ExternalRefService
+	logger  logger.Logger
+}
+
+// NewExternalRefHandler создает новый экземпляр ExternalRefHandler
+func NewExternalRefHandler(service *service.ExternalRefService, logger logger.Logger) *ExternalRefHandler {
+	return &ExternalRefHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// ListExternalRefs связи задачи с внешними трекерами
+// @Summary List external links of a task
+// @Description Get GitHub issues and Jira tickets linked to the task with their last polled status
+// @Tags tasks
+// @Produce json
+// @Param id path string true "Task ID"
+// @Security BearerAuth
+// @Success 200 {array} models.ExternalRef
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Forbidden"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /tasks/{id}/external [get]
+func (h *ExternalRefHandler) ListExternalRefs(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	refs, err := h.service.List(c.Request.Context(), userID.(string), c.Param("id"))
+	if err != nil {
+		h.handleError(c, err, "Failed to get external links")
+		return
+	}
+
+	c.JSON(http.StatusOK, refs)
+}
+
+// LinkExternal связывание задачи с внешней задачей
+// @Summary Link a task to a GitHub issue or Jira ticket
+// @Description Link the task to an external issue by key (owner/repo#123, PROJ-42) or URL. The external status is polled periodically; with mirror_completion the task is completed when the external issue is closed
+// @Tags tasks
+// @Accept json
+// @Produce json
+// @Param id path string true "Task ID"
+// @Param request body models.LinkExternalRequest true "External issue"
+// @Security BearerAuth
+// @Success 201 {object} models.ExternalRef
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Forbidden"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /tasks/{id}/external [post]
+func (h *ExternalRefHandler) LinkExternal(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var req models.LinkExternalRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	ref, err := h.service.Link(c.Request.Context(), userID.(string), c.Param("id"), req)
+	if err != nil {
+		h.handleError(c, err, "Failed to link external issue")
+		return
+	}
+
+	c.JSON(http.StatusCreated, ref)
+}
+
+// UnlinkExternal удаление связи с внешней задачей
+// @Summary Unlink an external issue
+// @Description Remove the link between the task and an external issue
+// @Tags tasks
+// @Produce json
+// @Param id path string true "Task ID"
+// @Param ref_id path string true "External link ID"
+// @Security BearerAuth
+// @Success 204 "No Content"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Forbidden"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /tasks/{id}/external/{ref_id} [delete]
+func (h *ExternalRefHandler) UnlinkExternal(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	if err := h.service.Unlink(c.Request.Context(), userID.(string), c.Param("id"), c.Param("ref_id")); err != nil {
+		h.handleError(c, err, "Failed to unlink external issue")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *ExternalRefHandler) handleError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, service.ErrTaskNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+	case errors.Is(err, service.ErrAccessDenied):
+		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+	case errors.Is(err, service.ErrExternalRefNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "External link not found"})
+	case errors.Is(err, service.ErrInvalidExternalRef):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrTrackerUnavailable):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "External tracker is not configured"})
+	case errors.Is(err, service.ErrExternalIssueNotFound):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "External issue not found"})
+	default:
+		if constraintError(c, err) {
+			return
+		}
+		h.logger.Error(message+": %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
@@ -0,0 +1,194 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/jmoloko/taskmange/internal/logger"
+	"github.com/jmoloko/taskmange/internal/service"
+)
+
+// максимальный размер push-события GitHub
+const maxGitHubPayloadBytes = 5 << 20
+
+// GitHubHandler обрабатывает HTTP-запросы подключения репозиториев GitHub и их webhook
+type GitHubHandler struct {
+	service *service.CommitService
+	logger  logger.Logger
+}
+
+// NewGitHubHandler создает новый экземпляр GitHubHandler
+func NewGitHubHandler(service *service.CommitService, logger logger.Logger) *GitHubHandler {
+	return &GitHubHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// ListRepos подключенные репозитории
+// @Summary List connected GitHub repositories
+// @Description Get GitHub repositories whose commits can complete tasks of the current user
+// @Tags integrations
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} models.GitHubRepoLink
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /integrations/github/repos [get]
+func (h *GitHubHandler) ListRepos(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	links, err := h.service.List(c.Request.Context(), userID.(string))
+	if err != nil {
+		h.logger.Error("Failed to get repositories: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get repositories"})
+		return
+	}
+
+	c.JSON(http.StatusOK, links)
+}
+
+// ConnectRepo подключение репозитория
+// @Summary Connect a GitHub repository
+// @Description Connect a repository so that commits like "closes TM-1a2b3c4d" pushed to its default branch complete the referenced task (TM- followed by the first 8 characters of the task ID). Add a webhook in the repository settings with the returned path, content type application/json and the returned secret; the secret is shown only in this response
+// @Tags integrations
+// @Accept json
+// @Produce json
+// @Param request body models.GitHubRepoLinkRequest true "Repository"
+// @Security BearerAuth
+// @Success 201 {object} models.GitHubRepoLink
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 409 {object} map[string]string "Conflict"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /integrations/github/repos [post]
+func (h *GitHubHandler) ConnectRepo(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var req models.GitHubRepoLinkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	link, err := h.service.Connect(c.Request.Context(), userID.(string), req.Repository)
+	if err != nil {
+		switch err {
+		case service.ErrInvalidRepository:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Repository must be owner/repo"})
+		case service.ErrRepoLinkExists:
+			c.JSON(http.StatusConflict, gin.H{"error": "Repository is already connected"})
+		default:
+			if constraintError(c, err) {
+				return
+			}
+			h.logger.Error("Failed to connect repository: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to connect repository"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, link)
+}
+
+// DisconnectRepo отключение репозитория
+// @Summary Disconnect a GitHub repository
+// @Description Disconnect a repository, further webhook deliveries for it are rejected
+// @Tags integrations
+// @Produce json
+// @Param id path string true "Repository link ID"
+// @Security BearerAuth
+// @Success 204 "No Content"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /integrations/github/repos/{id} [delete]
+func (h *GitHubHandler) DisconnectRepo(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	if err := h.service.Disconnect(c.Request.Context(), userID.(string), c.Param("id")); err != nil {
+		if err == service.ErrRepoLinkNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Repository link not found"})
+			return
+		}
+		h.logger.Error("Failed to disconnect repository: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to disconnect repository"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ReceiveWebhook событие GitHub
+// @Summary Receive a GitHub webhook
+// @Description Endpoint for GitHub webhook deliveries of a connected repository. The X-Hub-Signature-256 header is verified with the repository secret; push events to the default branch complete tasks referenced by closing keywords, other events are acknowledged
+// @Tags integrations
+// @Accept json
+// @Produce json
+// @Param id path string true "Repository link ID"
+// @Param X-GitHub-Event header string true "Event name"
+// @Param X-Hub-Signature-256 header string true "HMAC-SHA256 signature"
+// @Success 200 {object} models.CommitAutomationResult
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Forbidden"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 409 {object} map[string]string "Task has open subtasks"
+// @Failure 413 {object} map[string]string "Request Entity Too Large"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /integrations/github/webhook/{id} [post]
+func (h *GitHubHandler) ReceiveWebhook(c *gin.Context) {
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxGitHubPayloadBytes))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Payload is too large"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body"})
+		return
+	}
+
+	result, err := h.service.HandlePush(c.Request.Context(), c.Param("id"),
+		c.GetHeader("X-GitHub-Event"), c.GetHeader("X-Hub-Signature-256"), body)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrRepoLinkNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Repository link not found"})
+		case errors.Is(err, service.ErrInvalidSignature):
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid signature"})
+		case errors.Is(err, service.ErrUserDeactivated):
+			c.JSON(http.StatusForbidden, gin.H{"error": "Account is deactivated"})
+		case errors.Is(err, service.ErrInvalidPushEvent):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid push event"})
+		case errors.Is(err, service.ErrRepositoryMismatch):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Push event is for another repository"})
+		case errors.Is(err, service.ErrOpenSubtasks):
+			c.JSON(http.StatusConflict, gin.H{"error": "Task has open subtasks"})
+		default:
+			if constraintError(c, err) {
+				return
+			}
+			h.logger.Error("Failed to handle GitHub webhook: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to handle webhook"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
@@ -2,14 +2,60 @@ package handler
 
 // Handler объединяет все обработчики
 type Handler struct {
-	Auth *AuthHandler
-	Task *TaskHandler
+	Auth          *AuthHandler
+	Task          *TaskHandler
+	Notification  *NotificationHandler
+	CalendarSync  *CalendarSyncHandler
+	Trigger       *TriggerHandler
+	Analytics     *AnalyticsHandler
+	Transfer      *TransferHandler
+	Health        *HealthHandler
+	Usage         *UsageHandler
+	View          *ViewHandler
+	Impersonation *ImpersonationHandler
+	Hook          *HookHandler
+	External      *ExternalRefHandler
+	GitHub        *GitHubHandler
+	User          *UserHandler
+	Tagging       *TaggingHandler
+	AI            *AIHandler
+	QuickAdd      *QuickAddHandler
+	Admin         *AdminHandler
+	Project       *ProjectHandler
+	Events        *EventsHandler
+	Search        *SavedSearchHandler
+	ChangeFeed    *ChangeFeedHandler
+	Telegram      *TelegramHandler
+	Calendar      *CalendarHandler
 }
 
 // NewHandler создает новый экземпляр Handler
-func NewHandler(auth *AuthHandler, task *TaskHandler) *Handler {
+func NewHandler(auth *AuthHandler, task *TaskHandler, notification *NotificationHandler, calendarSync *CalendarSyncHandler, trigger *TriggerHandler, analytics *AnalyticsHandler, transfer *TransferHandler, health *HealthHandler, usage *UsageHandler, view *ViewHandler, impersonation *ImpersonationHandler, hook *HookHandler, external *ExternalRefHandler, github *GitHubHandler, user *UserHandler, tagging *TaggingHandler, ai *AIHandler, quickAdd *QuickAddHandler, admin *AdminHandler, project *ProjectHandler, events *EventsHandler, search *SavedSearchHandler, changeFeed *ChangeFeedHandler, telegram *TelegramHandler, calendar *CalendarHandler) *Handler {
 	return &Handler{
-		Auth: auth,
-		Task: task,
+		Auth:          auth,
+		Task:          task,
+		Notification:  notification,
+		CalendarSync:  calendarSync,
+		Trigger:       trigger,
+		Analytics:     analytics,
+		Transfer:      transfer,
+		Health:        health,
+		Usage:         usage,
+		View:          view,
+		Impersonation: impersonation,
+		Hook:          hook,
+		External:      external,
+		GitHub:        github,
+		User:          user,
+		Tagging:       tagging,
+		AI:            ai,
+		QuickAdd:      quickAdd,
+		Admin:         admin,
+		Project:       project,
+		Events:        events,
+		Search:        search,
+		ChangeFeed:    changeFeed,
+		Telegram:      telegram,
+		Calendar:      calendar,
 	}
 }
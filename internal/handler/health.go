@@ -0,0 +1,66 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoloko/taskmange/internal/selfcheck"
+)
+
+// HealthHandler отдает живость и готовность сервиса и отчет проверки при запуске
+type HealthHandler struct {
+	checker *selfcheck.Checker
+}
+
+// NewHealthHandler создает новый экземпляр HealthHandler
+func NewHealthHandler(checker *selfcheck.Checker) *HealthHandler {
+	return &HealthHandler{checker: checker}
+}
+
+// LiveResponse ответ /healthz
+type LiveResponse struct {
+	Status selfcheck.Status `json:"status"`
+}
+
+// Live живость процесса
+// @Summary Liveness check
+// @Description Report that the process is running and serving HTTP. Dependencies are not checked, so an outage of Postgres or Redis does not restart the service
+// @Tags health
+// @Produce json
+// @Success 200 {object} LiveResponse
+// @Router /healthz [get]
+func (h *HealthHandler) Live(c *gin.Context) {
+	c.JSON(http.StatusOK, LiveResponse{Status: selfcheck.StatusOK})
+}
+
+// ReadyResponse ответ /readyz
+type ReadyResponse struct {
+	Status  selfcheck.Status   `json:"status"`
+	Checks  []selfcheck.Result `json:"checks"`
+	Startup *selfcheck.Report  `json:"startup,omitempty"`
+}
+
+// Ready готовность сервиса
+// @Summary Readiness check
+// @Description Check that Postgres and Redis are reachable, each within READINESS_TIMEOUT, and report the status of every dependency. With verbose=1 the response also contains the startup self-check report (config, schema version, Redis latency, clock skew, log file)
+// @Tags health
+// @Produce json
+// @Param verbose query int false "Include the startup report (1)"
+// @Success 200 {object} ReadyResponse
+// @Failure 503 {object} ReadyResponse "Service Unavailable"
+// @Router /readyz [get]
+func (h *HealthHandler) Ready(c *gin.Context) {
+	report := h.checker.Ready(c.Request.Context())
+
+	code := http.StatusOK
+	if report.Status == selfcheck.StatusFail {
+		code = http.StatusServiceUnavailable
+	}
+
+	response := ReadyResponse{Status: report.Status, Checks: report.Checks}
+	if c.Query("verbose") == "1" {
+		response.Startup = h.checker.StartupReport()
+	}
+
+	c.JSON(code, response)
+}
@@ -0,0 +1,190 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/jmoloko/taskmange/internal/logger"
+	"github.com/jmoloko/taskmange/internal/service"
+)
+
+// максимальный размер тела входящего webhook
+const maxHookPayloadBytes = 64 << 10
+
+// HookHandler обрабатывает HTTP-запросы входящих webhook
+type HookHandler struct {
+	service *service.HookService
+	logger  logger.Logger
+}
+
+// NewHookHandler создает новый экземпляр HookHandler
+func NewHookHandler(service *service.HookService, logger logger.Logger) *HookHandler {
+	return &HookHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// ListHooks список входящих webhook
+// @Summary List incoming webhooks
+// @Description Get incoming webhooks of the current user, tokens are not returned
+// @Tags hooks
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} models.IncomingHook
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /inbound-hooks [get]
+func (h *HookHandler) ListHooks(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	hooks, err := h.service.List(c.Request.Context(), userID.(string))
+	if err != nil {
+		h.logger.Error("Failed to get incoming hooks: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get incoming hooks"})
+		return
+	}
+
+	c.JSON(http.StatusOK, hooks)
+}
+
+// CreateHook создание входящего webhook
+// @Summary Create an incoming webhook
+// @Description Create a URL token that lets an external system create tasks for the current user via POST /api/hooks/{token}. The token is returned only in this response. Mapping holds dot paths into the request JSON for title, description, priority and due_date
+// @Tags hooks
+// @Accept json
+// @Produce json
+// @Param hook body models.IncomingHookRequest true "Incoming webhook"
+// @Security BearerAuth
+// @Success 201 {object} models.IncomingHook
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /inbound-hooks [post]
+func (h *HookHandler) CreateHook(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var req models.IncomingHookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	hook, err := h.service.Create(c.Request.Context(), userID.(string), req)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidHook):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, service.ErrTooManyHooks):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Too many incoming hooks"})
+		default:
+			if constraintError(c, err) {
+				return
+			}
+			h.logger.Error("Failed to create incoming hook: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create incoming hook"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, hook)
+}
+
+// DeleteHook удаление входящего webhook
+// @Summary Delete an incoming webhook
+// @Description Delete an incoming webhook, its token stops working immediately
+// @Tags hooks
+// @Produce json
+// @Param id path string true "Incoming webhook ID"
+// @Security BearerAuth
+// @Success 204 "No Content"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /inbound-hooks/{id} [delete]
+func (h *HookHandler) DeleteHook(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	if err := h.service.Delete(c.Request.Context(), userID.(string), c.Param("id")); err != nil {
+		if err == service.ErrHookNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Incoming hook not found"})
+			return
+		}
+		h.logger.Error("Failed to delete incoming hook: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete incoming hook"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ReceiveHook входящий запрос внешней системы
+// @Summary Create a task from an external system
+// @Description Create a task for the owner of the webhook token without a JWT. Task fields are taken from the JSON body according to the webhook mapping. Requests are rate limited per webhook
+// @Tags hooks
+// @Accept json
+// @Produce json
+// @Param token path string true "Incoming webhook token"
+// @Param payload body object true "Arbitrary JSON"
+// @Success 201 {object} models.HookDelivery
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 403 {object} map[string]string "Forbidden"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 413 {object} map[string]string "Request Entity Too Large"
+// @Failure 429 {object} map[string]string "Too Many Requests"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /hooks/{token} [post]
+func (h *HookHandler) ReceiveHook(c *gin.Context) {
+	payload, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxHookPayloadBytes))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Payload is too large"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body"})
+		return
+	}
+
+	delivery, err := h.service.Receive(c.Request.Context(), c.Param("token"), payload)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrHookNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Incoming hook not found"})
+		case errors.Is(err, service.ErrUserDeactivated):
+			c.JSON(http.StatusForbidden, gin.H{"error": "Account is deactivated"})
+		case errors.Is(err, service.ErrHookRateLimited):
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": "Rate limit exceeded"})
+		case errors.Is(err, service.ErrInvalidHookPayload):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, service.ErrInvalidTaskData):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task data"})
+		case errors.Is(err, service.ErrTaskQuotaExceeded):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Open task limit reached"})
+		default:
+			if constraintError(c, err) {
+				return
+			}
+			h.logger.Error("Failed to handle incoming hook: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create task"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, delivery)
+}
@@ -0,0 +1,151 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/jmoloko/taskmange/internal/logger"
+	"github.com/jmoloko/taskmange/internal/service"
+)
+
+// ImpersonationHandler обрабатывает HTTP-запросы имперсонации и журнала аудита
+type ImpersonationHandler struct {
+	service *service.ImpersonationService
+	audit   *service.AuditService
+	logger  logger.Logger
+}
+
+// NewImpersonationHandler создает новый экземпляр ImpersonationHandler
+func NewImpersonationHandler(service *service.ImpersonationService, audit *service.AuditService, logger logger.Logger) *ImpersonationHandler {
+	return &ImpersonationHandler{
+		service: service,
+		audit:   audit,
+		logger:  logger,
+	}
+}
+
+// Impersonate выдача токена имперсонации
+// @Summary Impersonate a user
+// @Description Issue a short-lived token that acts as the user for support purposes. The token carries impersonator_id and impersonation_id claims, cannot access the admin API, and every request made with it is written to the audit log
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param userID path string true "User ID"
+// @Param request body models.ImpersonateRequest true "Reason, e.g. a support ticket"
+// @Security BearerAuth
+// @Success 201 {object} models.ImpersonationToken
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Forbidden"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /admin/impersonate/{userID} [post]
+func (h *ImpersonationHandler) Impersonate(c *gin.Context) {
+	adminID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var req models.ImpersonateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "reason is required"})
+		return
+	}
+
+	token, err := h.service.Start(c.Request.Context(), adminID.(string), c.Param("userID"), req.Reason)
+	if err != nil {
+		switch err {
+		case service.ErrImpersonationReason:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "reason is required"})
+		case service.ErrSelfImpersonation:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Cannot impersonate yourself"})
+		case service.ErrUserNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		default:
+			if constraintError(c, err) {
+				return
+			}
+			h.logger.Error("Failed to start impersonation: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start impersonation"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, token)
+}
+
+// RevokeImpersonation отзыв токена имперсонации
+// @Summary Revoke an impersonation
+// @Description Revoke an impersonation session, its token is rejected immediately
+// @Tags admin
+// @Produce json
+// @Param id path string true "Impersonation ID"
+// @Security BearerAuth
+// @Success 204 "No Content"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Forbidden"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /admin/impersonations/{id} [delete]
+func (h *ImpersonationHandler) RevokeImpersonation(c *gin.Context) {
+	adminID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	if err := h.service.Revoke(c.Request.Context(), adminID.(string), c.Param("id")); err != nil {
+		if err == service.ErrImpersonationNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Active impersonation not found"})
+			return
+		}
+		h.logger.Error("Failed to revoke impersonation: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke impersonation"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// GetAuditEvents журнал аудита
+// @Summary Get audit log
+// @Description Get recent audit events: impersonation starts and revocations, and requests made with impersonation tokens
+// @Tags admin
+// @Produce json
+// @Param actor_id query string false "User the action was performed as"
+// @Param impersonator_id query string false "Admin who impersonated the user"
+// @Param limit query int false "Number of events (1-500)" default(100)
+// @Security BearerAuth
+// @Success 200 {array} models.AuditEvent
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Forbidden"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /admin/audit [get]
+func (h *ImpersonationHandler) GetAuditEvents(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be a number"})
+		return
+	}
+
+	events, err := h.audit.Events(c.Request.Context(), models.AuditFilter{
+		ActorID:        c.Query("actor_id"),
+		ImpersonatorID: c.Query("impersonator_id"),
+		Limit:          limit,
+	})
+	if err != nil {
+		if err == service.ErrInvalidAuditQuery {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 500"})
+			return
+		}
+		h.logger.Error("Failed to get audit events: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get audit events"})
+		return
+	}
+
+	c.JSON(http.StatusOK, events)
+}
@@ -0,0 +1,237 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/jmoloko/taskmange/internal/logger"
+	"github.com/jmoloko/taskmange/internal/service"
+)
+
+// NotificationHandler обрабатывает HTTP-запросы подписок и настроек уведомлений
+type NotificationHandler struct {
+	service *service.NotificationService
+	logger  logger.Logger
+}
+
+// NewNotificationHandler создает новый экземпляр NotificationHandler
+func NewNotificationHandler(service *service.NotificationService, logger logger.Logger) *NotificationHandler {
+	return &NotificationHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// GetVAPIDKey публичный ключ VAPID
+// @Summary Get VAPID public key
+// @Description Get the application server key for PushManager.subscribe
+// @Tags notifications
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]string "Public key"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 503 {object} map[string]string "Push notifications are disabled"
+// @Router /notifications/push/vapid-key [get]
+func (h *NotificationHandler) GetVAPIDKey(c *gin.Context) {
+	key, err := h.service.VAPIDPublicKey()
+	if err != nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Push notifications are disabled"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"public_key": key})
+}
+
+// Subscribe подписка браузера на push-уведомления
+// @Summary Subscribe to push notifications
+// @Description Save a browser push subscription (PushSubscription.toJSON())
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Param subscription body models.PushSubscriptionRequest true "Push subscription"
+// @Security BearerAuth
+// @Success 201 {object} models.PushSubscription
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 503 {object} map[string]string "Push notifications are disabled"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /notifications/push/subscriptions [post]
+func (h *NotificationHandler) Subscribe(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var req models.PushSubscriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	sub, err := h.service.Subscribe(c.Request.Context(), userID.(string), req)
+	if err != nil {
+		switch err {
+		case service.ErrInvalidSubscription:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid push subscription"})
+		case service.ErrPushDisabled:
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Push notifications are disabled"})
+		default:
+			if constraintError(c, err) {
+				return
+			}
+			h.logger.Error("Failed to save push subscription: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save push subscription"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, sub)
+}
+
+// Unsubscribe удаление подписки браузера
+// @Summary Unsubscribe from push notifications
+// @Description Remove a browser push subscription by endpoint
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Param subscription body models.PushUnsubscribeRequest true "Push subscription endpoint"
+// @Security BearerAuth
+// @Success 204 "No Content"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /notifications/push/subscriptions [delete]
+func (h *NotificationHandler) Unsubscribe(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var req models.PushUnsubscribeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	if err := h.service.Unsubscribe(c.Request.Context(), userID.(string), req.Endpoint); err != nil {
+		if err == service.ErrInvalidSubscription {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Endpoint is required"})
+			return
+		}
+		h.logger.Error("Failed to delete push subscription: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete push subscription"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// GetPreferences настройки уведомлений
+// @Summary Get notification preferences
+// @Description Get notification preferences of the current user
+// @Tags notifications
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} models.NotificationPreferences
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /notifications/preferences [get]
+func (h *NotificationHandler) GetPreferences(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	prefs, err := h.service.GetPreferences(c.Request.Context(), userID.(string))
+	if err != nil {
+		h.logger.Error("Failed to get notification preferences: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get notification preferences"})
+		return
+	}
+
+	c.JSON(http.StatusOK, prefs)
+}
+
+// UpdatePreferences обновление настроек уведомлений
+// @Summary Update notification preferences
+// @Description Update notification channels (push, email, slack, telegram), event types, digest window, quiet hours and Slack incoming webhook of the current user. The slack channel requires slack_webhook_url; task.completed and tasks.completed notifications are sent only when listed in event_types explicitly
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Param preferences body models.NotificationPreferences true "Notification preferences"
+// @Security BearerAuth
+// @Success 200 {object} models.NotificationPreferences
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /notifications/preferences [put]
+func (h *NotificationHandler) UpdatePreferences(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var req models.NotificationPreferences
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	prefs, err := h.service.UpdatePreferences(c.Request.Context(), userID.(string), req)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidPreferences) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if constraintError(c, err) {
+			return
+		}
+		h.logger.Error("Failed to update notification preferences: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update notification preferences"})
+		return
+	}
+
+	c.JSON(http.StatusOK, prefs)
+}
+
+// PreviewTemplate предпросмотр шаблона уведомления
+// @Summary Preview a notification template
+// @Description Render an email or Slack notification template with a sample or provided task event (admin only)
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param preview body models.NotificationPreviewRequest true "Preview request"
+// @Security BearerAuth
+// @Success 200 {object} notification.Message
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Forbidden"
+// @Failure 404 {object} map[string]string "Template not found"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /admin/notifications/preview [post]
+func (h *NotificationHandler) PreviewTemplate(c *gin.Context) {
+	var req models.NotificationPreviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	msg, err := h.service.PreviewTemplate(req)
+	if err != nil {
+		if err == service.ErrTemplateNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Template not found"})
+			return
+		}
+		h.logger.Error("Failed to render notification template: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to render notification template"})
+		return
+	}
+
+	c.JSON(http.StatusOK, msg)
+}
@@ -0,0 +1,277 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/jmoloko/taskmange/internal/logger"
+	"github.com/jmoloko/taskmange/internal/service"
+)
+
+// ProjectHandler обрабатывает HTTP-запросы проектов
+type ProjectHandler struct {
+	service *service.ProjectService
+	logger  logger.Logger
+}
+
+// NewProjectHandler создает новый экземпляр ProjectHandler
+func NewProjectHandler(service *service.ProjectService, logger logger.Logger) *ProjectHandler {
+	return &ProjectHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// ListProjects список проектов
+// @Summary List projects
+// @Description Get projects of the current user ordered by name
+// @Tags projects
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} models.Project
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /projects [get]
+func (h *ProjectHandler) ListProjects(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	projects, err := h.service.List(c.Request.Context(), userID.(string))
+	if err != nil {
+		h.logger.Error("Failed to get projects: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get projects"})
+		return
+	}
+
+	c.JSON(http.StatusOK, projects)
+}
+
+// CreateProject создание проекта
+// @Summary Create a project
+// @Description Create a project to group tasks. Project names are unique per user. Tasks are added to the project with project_id on create or update
+// @Tags projects
+// @Accept json
+// @Produce json
+// @Param project body models.ProjectRequest true "Project"
+// @Security BearerAuth
+// @Success 201 {object} models.Project
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 409 {object} map[string]string "Project name already exists"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /projects [post]
+func (h *ProjectHandler) CreateProject(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var req models.ProjectRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	project, err := h.service.Create(c.Request.Context(), userID.(string), req)
+	if err != nil {
+		h.handleError(c, err, "Failed to create project")
+		return
+	}
+
+	c.JSON(http.StatusCreated, project)
+}
+
+// GetProject получение проекта
+// @Summary Get a project
+// @Description Get a project of the current user by ID
+// @Tags projects
+// @Produce json
+// @Param id path string true "Project ID"
+// @Security BearerAuth
+// @Success 200 {object} models.Project
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /projects/{id} [get]
+func (h *ProjectHandler) GetProject(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	project, err := h.service.Get(c.Request.Context(), userID.(string), c.Param("id"))
+	if err != nil {
+		h.handleError(c, err, "Failed to get project")
+		return
+	}
+
+	c.JSON(http.StatusOK, project)
+}
+
+// UpdateProject изменение проекта
+// @Summary Update a project
+// @Description Rename a project and replace its description
+// @Tags projects
+// @Accept json
+// @Produce json
+// @Param id path string true "Project ID"
+// @Param project body models.ProjectRequest true "Project"
+// @Security BearerAuth
+// @Success 200 {object} models.Project
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 409 {object} map[string]string "Project name already exists"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /projects/{id} [put]
+func (h *ProjectHandler) UpdateProject(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var req models.ProjectRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	project, err := h.service.Update(c.Request.Context(), userID.(string), c.Param("id"), req)
+	if err != nil {
+		h.handleError(c, err, "Failed to update project")
+		return
+	}
+
+	c.JSON(http.StatusOK, project)
+}
+
+// DeleteProject удаление проекта
+// @Summary Delete a project
+// @Description Delete a project. Its tasks are kept and no longer belong to any project
+// @Tags projects
+// @Produce json
+// @Param id path string true "Project ID"
+// @Security BearerAuth
+// @Success 204 "No Content"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /projects/{id} [delete]
+func (h *ProjectHandler) DeleteProject(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	if err := h.service.Delete(c.Request.Context(), userID.(string), c.Param("id")); err != nil {
+		h.handleError(c, err, "Failed to delete project")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// GetProjectTasks задачи проекта
+// @Summary Get project tasks
+// @Description Get tasks of a project with optional filtering, ordered as the task list
+// @Tags projects
+// @Produce json
+// @Param id path string true "Project ID"
+// @Param status query string false "Filter by status"
+// @Param priority query string false "Filter by priority"
+// @Param tag query string false "Filter by tag"
+// @Param sort query string false "Set to smart to order by priority, due date proximity and age, or to due to order by due date and ID"
+// @Security BearerAuth
+// @Success 200 {array} models.Task
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /projects/{id}/tasks [get]
+func (h *ProjectHandler) GetProjectTasks(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	filters := models.TaskFilters{
+		Status:   models.Status(c.Query("status")),
+		Priority: models.Priority(c.Query("priority")),
+		Tag:      strings.ToLower(strings.TrimSpace(c.Query("tag"))),
+		Sort:     models.TaskSort(c.Query("sort")),
+	}
+	if !filters.Sort.Valid() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid sort, supported values: smart, due"})
+		return
+	}
+
+	tasks, err := h.service.Tasks(c.Request.Context(), userID.(string), c.Param("id"), filters)
+	if err != nil {
+		h.handleError(c, err, "Failed to get project tasks")
+		return
+	}
+
+	c.JSON(http.StatusOK, tasks)
+}
+
+// GetProjectAnalytics аналитика проекта
+// @Summary Get project analytics
+// @Description Get analytics for the tasks of a project
+// @Tags projects
+// @Produce json
+// @Param id path string true "Project ID"
+// @Param period query string false "Analytics period (day/week/month)" default(week)
+// @Security BearerAuth
+// @Success 200 {object} models.Analytics
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /projects/{id}/analytics [get]
+func (h *ProjectHandler) GetProjectAnalytics(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	period := c.DefaultQuery("period", "week")
+	if !isValidPeriod(period) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid period"})
+		return
+	}
+
+	analytics, err := h.service.Analytics(c.Request.Context(), userID.(string), c.Param("id"), period)
+	if err != nil {
+		h.handleError(c, err, "Failed to get project analytics")
+		return
+	}
+
+	c.JSON(http.StatusOK, analytics)
+}
+
+// handleError ответ на ошибку сервиса проектов; message — ответ на непредвиденную ошибку
+func (h *ProjectHandler) handleError(c *gin.Context, err error, message string) {
+	switch err {
+	case service.ErrProjectNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
+	case service.ErrInvalidProjectData:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Project name must not be empty"})
+	default:
+		if constraintError(c, err) {
+			return
+		}
+		h.logger.Error(message+": %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/jmoloko/taskmange/internal/logger"
+	"github.com/jmoloko/taskmange/internal/service"
+)
+
+// QuickAddHandler обрабатывает HTTP-запросы быстрого добавления задач
+type QuickAddHandler struct {
+	service *service.QuickAddService
+	logger  logger.Logger
+}
+
+// NewQuickAddHandler создает новый экземпляр QuickAddHandler
+func NewQuickAddHandler(service *service.QuickAddService, logger logger.Logger) *QuickAddHandler {
+	return &QuickAddHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// QuickAdd быстрое добавление задачи из строки
+// @Summary Quick-add a task
+// @Description Parse a single free-text line such as "Pay rent friday high priority #finance" into a task: #tags, priority ("high priority", "priority low", !high), due date (today, tomorrow, friday, next friday, next week, "in 3 days", 2026-01-31, 31.01) and time ("at 5pm", 17:30, noon). Text in double quotes is kept in the title as is. Dates are read in the given timezone, otherwise in the notification preferences timezone. Without create the parsed task is returned for confirmation and nothing is saved
+// @Tags tasks
+// @Accept json
+// @Produce json
+// @Param request body models.QuickAddRequest true "Quick-add line"
+// @Security BearerAuth
+// @Success 200 {object} models.Task "Parsed task, not saved"
+// @Success 201 {object} models.Task "Created task"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /tasks/quick-add [post]
+func (h *QuickAddHandler) QuickAdd(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var req models.QuickAddRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	if !req.Create {
+		task, err := h.service.Parse(c.Request.Context(), userID.(string), req)
+		if err != nil {
+			h.handleError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, task)
+		return
+	}
+
+	task, err := h.service.Create(c.Request.Context(), userID.(string), req)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, task)
+}
+
+func (h *QuickAddHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrQuickAddNoTitle):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Task title is missing"})
+	case errors.Is(err, service.ErrInvalidTimezone):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown timezone"})
+	default:
+		if createError(c, err) {
+			return
+		}
+		h.logger.Error("Failed to quick-add task: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create task"})
+	}
+}
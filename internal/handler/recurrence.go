@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/jmoloko/taskmange/internal/service"
+)
+
+// GetRecurrence серия повторяющейся задачи
+// @Summary Get a recurrence series
+// @Description Get a series of a recurring task: its rule, whether it is paused and how many occurrences were created. An empty last_task_id means the series has ended
+// @Tags recurrences
+// @Produce json
+// @Param id path string true "Recurrence ID (recurrence_id of a task)"
+// @Security BearerAuth
+// @Success 200 {object} models.Recurrence
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /recurrences/{id} [get]
+func (h *TaskHandler) GetRecurrence(c *gin.Context) {
+	h.recurrence(c, h.service.GetRecurrence)
+}
+
+// PauseRecurrence приостановка серии
+// @Summary Pause a recurrence series
+// @Description Stop creating new occurrences of a recurring task. Existing occurrences are not changed
+// @Tags recurrences
+// @Produce json
+// @Param id path string true "Recurrence ID (recurrence_id of a task)"
+// @Security BearerAuth
+// @Success 200 {object} models.Recurrence
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /recurrences/{id}/pause [post]
+func (h *TaskHandler) PauseRecurrence(c *gin.Context) {
+	h.recurrence(c, h.service.PauseRecurrence)
+}
+
+// ResumeRecurrence возобновление серии
+// @Summary Resume a recurrence series
+// @Description Resume a paused series. Occurrences missed while paused are skipped, the next one gets the nearest due date after resuming
+// @Tags recurrences
+// @Produce json
+// @Param id path string true "Recurrence ID (recurrence_id of a task)"
+// @Security BearerAuth
+// @Success 200 {object} models.Recurrence
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /recurrences/{id}/resume [post]
+func (h *TaskHandler) ResumeRecurrence(c *gin.Context) {
+	h.recurrence(c, h.service.ResumeRecurrence)
+}
+
+// recurrence общий обработчик запросов к серии
+func (h *TaskHandler) recurrence(c *gin.Context, action func(ctx context.Context, userID, recurrenceID string) (models.Recurrence, error)) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	series, err := action(c.Request.Context(), userID.(string), c.Param("id"))
+	if err != nil {
+		if err == service.ErrRecurrenceNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Recurrence not found"})
+			return
+		}
+		h.logger.Error("Failed to process recurrence: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to process recurrence"})
+		return
+	}
+
+	c.JSON(http.StatusOK, series)
+}
@@ -0,0 +1,159 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/jmoloko/taskmange/internal/service"
+)
+
+// RelateTask связывание задач
+// @Summary Relate tasks
+// @Description Add a "related to" link between two tasks of the current user. The link is symmetric and has no blocking semantics
+// @Tags tasks
+// @Accept json
+// @Produce json
+// @Param id path string true "Task ID"
+// @Param relation body models.RelateTaskRequest true "Related task"
+// @Security BearerAuth
+// @Success 204 "No Content"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Forbidden"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /tasks/{id}/related [post]
+func (h *TaskHandler) RelateTask(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var req models.RelateTaskRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	if err := h.service.RelateTasks(c.Request.Context(), userID.(string), c.Param("id"), req.TaskID); err != nil {
+		switch err {
+		case service.ErrSelfRelation:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Task cannot be related to itself"})
+		case service.ErrTaskNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+		case service.ErrAccessDenied:
+			c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+		default:
+			if constraintError(c, err) {
+				return
+			}
+			h.logger.Error("Failed to relate tasks: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to relate tasks"})
+		}
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// UnrelateTask удаление связи между задачами
+// @Summary Remove a task relation
+// @Description Remove the "related to" link between two tasks of the current user
+// @Tags tasks
+// @Produce json
+// @Param id path string true "Task ID"
+// @Param related_id path string true "Related task ID"
+// @Security BearerAuth
+// @Success 204 "No Content"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Forbidden"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /tasks/{id}/related/{related_id} [delete]
+func (h *TaskHandler) UnrelateTask(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	if err := h.service.UnrelateTasks(c.Request.Context(), userID.(string), c.Param("id"), c.Param("related_id")); err != nil {
+		switch err {
+		case service.ErrTaskNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+		case service.ErrRelationNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": "Tasks are not related"})
+		case service.ErrAccessDenied:
+			c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+		default:
+			h.logger.Error("Failed to remove task relation: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove task relation"})
+		}
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// GetSubtasks список подзадач
+// @Summary List subtasks
+// @Description Get direct subtasks of a task: open ones first, then by due date
+// @Tags tasks
+// @Produce json
+// @Param id path string true "Task ID"
+// @Security BearerAuth
+// @Success 200 {array} models.Task
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Forbidden"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /tasks/{id}/subtasks [get]
+func (h *TaskHandler) GetSubtasks(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	subtasks, err := h.service.GetSubtasks(c.Request.Context(), userID.(string), c.Param("id"))
+	if err != nil {
+		switch err {
+		case service.ErrTaskNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+		case service.ErrAccessDenied:
+			c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+		default:
+			h.logger.Error("Failed to get subtasks: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get subtasks"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, subtasks)
+}
+
+// parentError текст ответа на ошибку проверки родительской задачи
+func parentError(err error) (string, bool) {
+	switch err {
+	case service.ErrInvalidParent:
+		return "Parent task not found", true
+	case service.ErrSubtaskCycle:
+		return "Task cannot be a subtask of itself or of its subtasks", true
+	case service.ErrSubtasksTooDeep:
+		return "Subtasks are nested too deep", true
+	}
+	return "", false
+}
+
+// expandsLinks проверяет, запрошено ли expand=links (значения через запятую)
+func expandsLinks(c *gin.Context) bool {
+	for _, value := range strings.Split(c.Query("expand"), ",") {
+		if strings.TrimSpace(value) == "links" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,178 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/jmoloko/taskmange/internal/logger"
+	"github.com/jmoloko/taskmange/internal/service"
+)
+
+// SavedSearchHandler обрабатывает HTTP-запросы сохраненных поисков
+type SavedSearchHandler struct {
+	service *service.SavedSearchService
+	logger  logger.Logger
+}
+
+// NewSavedSearchHandler создает новый экземпляр SavedSearchHandler
+func NewSavedSearchHandler(service *service.SavedSearchService, logger logger.Logger) *SavedSearchHandler {
+	return &SavedSearchHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// ListSavedSearches список сохраненных поисков
+// @Summary List saved searches
+// @Description Get saved searches of the current user in the order they were created
+// @Tags saved-searches
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} models.SavedSearch
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /saved-searches [get]
+func (h *SavedSearchHandler) ListSavedSearches(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	searches, err := h.service.List(c.Request.Context(), userID.(string))
+	if err != nil {
+		h.logger.Error("Failed to get saved searches: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get saved searches"})
+		return
+	}
+
+	c.JSON(http.StatusOK, searches)
+}
+
+// CreateSavedSearch создание сохраненного поиска
+// @Summary Create a saved search
+// @Description Save a task list query in the q parameter syntax (e.g. is:overdue #work). With alert enabled, a search.match notification is sent when a created or changed task starts to match the query, once per task and at most once per alert_interval_minutes; matches within the interval are counted in the next notification
+// @Tags saved-searches
+// @Accept json
+// @Produce json
+// @Param search body models.SavedSearchRequest true "Saved search"
+// @Security BearerAuth
+// @Success 201 {object} models.SavedSearch
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /saved-searches [post]
+func (h *SavedSearchHandler) CreateSavedSearch(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var req models.SavedSearchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	saved, err := h.service.Create(c.Request.Context(), userID.(string), req)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidSavedSearch):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, service.ErrTooManySavedSearches):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Too many saved searches"})
+		default:
+			if constraintError(c, err) {
+				return
+			}
+			h.logger.Error("Failed to create saved search: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create saved search"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, saved)
+}
+
+// UpdateSavedSearch изменение сохраненного поиска
+// @Summary Update a saved search
+// @Description Replace name, query, timezone and alert settings of a saved search. After the query changes, tasks that matched the old query can be reported again
+// @Tags saved-searches
+// @Accept json
+// @Produce json
+// @Param id path string true "Saved search ID"
+// @Param search body models.SavedSearchRequest true "Saved search"
+// @Security BearerAuth
+// @Success 200 {object} models.SavedSearch
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /saved-searches/{id} [put]
+func (h *SavedSearchHandler) UpdateSavedSearch(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var req models.SavedSearchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	saved, err := h.service.Update(c.Request.Context(), userID.(string), c.Param("id"), req)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidSavedSearch):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, service.ErrSavedSearchNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Saved search not found"})
+		default:
+			if constraintError(c, err) {
+				return
+			}
+			h.logger.Error("Failed to update saved search: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update saved search"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, saved)
+}
+
+// DeleteSavedSearch удаление сохраненного поиска
+// @Summary Delete a saved search
+// @Description Delete a saved search by ID, its alerts stop
+// @Tags saved-searches
+// @Produce json
+// @Param id path string true "Saved search ID"
+// @Security BearerAuth
+// @Success 204 "No Content"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /saved-searches/{id} [delete]
+func (h *SavedSearchHandler) DeleteSavedSearch(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	if err := h.service.Delete(c.Request.Context(), userID.(string), c.Param("id")); err != nil {
+		if err == service.ErrSavedSearchNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Saved search not found"})
+			return
+		}
+		h.logger.Error("Failed to delete saved search: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete saved search"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
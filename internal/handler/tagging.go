@@ -0,0 +1,170 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/jmoloko/taskmange/internal/logger"
+	"github.com/jmoloko/taskmange/internal/service"
+)
+
+// TaggingHandler обрабатывает HTTP-запросы правил автотегирования
+type TaggingHandler struct {
+	service *service.TaggingService
+	logger  logger.Logger
+}
+
+// NewTaggingHandler создает новый экземпляр TaggingHandler
+func NewTaggingHandler(service *service.TaggingService, logger logger.Logger) *TaggingHandler {
+	return &TaggingHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// ListTaggingRules список правил автотегирования
+// @Summary List tagging rules
+// @Description Get auto-tagging rules of the current user in the order they are applied
+// @Tags tagging
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} models.TaggingRule
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /tagging-rules [get]
+func (h *TaggingHandler) ListTaggingRules(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	rules, err := h.service.List(c.Request.Context(), userID.(string))
+	if err != nil {
+		h.logger.Error("Failed to get tagging rules: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get tagging rules"})
+		return
+	}
+
+	c.JSON(http.StatusOK, rules)
+}
+
+// CreateTaggingRule создание правила автотегирования
+// @Summary Create a tagging rule
+// @Description Create an auto-tagging rule: when the title or description of a task matches the pattern (comma-separated keywords or a Go regular expression), the rule tags are added to the task on create and update. Private tasks are not tagged
+// @Tags tagging
+// @Accept json
+// @Produce json
+// @Param rule body models.TaggingRuleRequest true "Tagging rule"
+// @Security BearerAuth
+// @Success 201 {object} models.TaggingRule
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /tagging-rules [post]
+func (h *TaggingHandler) CreateTaggingRule(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var req models.TaggingRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	rule, err := h.service.Create(c.Request.Context(), userID.(string), req)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidTaggingRule):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, service.ErrTooManyTaggingRules):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Too many tagging rules"})
+		default:
+			if constraintError(c, err) {
+				return
+			}
+			h.logger.Error("Failed to create tagging rule: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create tagging rule"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, rule)
+}
+
+// DeleteTaggingRule удаление правила автотегирования
+// @Summary Delete a tagging rule
+// @Description Delete an auto-tagging rule by ID. Tags already added by the rule stay on the tasks
+// @Tags tagging
+// @Produce json
+// @Param id path string true "Rule ID"
+// @Security BearerAuth
+// @Success 204 "No Content"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /tagging-rules/{id} [delete]
+func (h *TaggingHandler) DeleteTaggingRule(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	if err := h.service.Delete(c.Request.Context(), userID.(string), c.Param("id")); err != nil {
+		if err == service.ErrTaggingRuleNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Tagging rule not found"})
+			return
+		}
+		h.logger.Error("Failed to delete tagging rule: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete tagging rule"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// TestTaggingRules пробное применение правил
+// @Summary Dry-run tagging rules
+// @Description Show which tags the enabled rules would add to a task with the given title and description, without creating or changing tasks. With rule in the body only that unsaved rule is checked
+// @Tags tagging
+// @Accept json
+// @Produce json
+// @Param request body models.TaggingTestRequest true "Task text and optional draft rule"
+// @Security BearerAuth
+// @Success 200 {object} models.TaggingTestResult
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /tagging-rules/test [post]
+func (h *TaggingHandler) TestTaggingRules(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var req models.TaggingTestRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	result, err := h.service.Test(c.Request.Context(), userID.(string), req)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidTaggingRule) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		h.logger.Error("Failed to test tagging rules: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to test tagging rules"})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
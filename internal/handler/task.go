@@ -1,43 +1,92 @@
 package handler
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/jmoloko/taskmange/internal/clock"
 	"github.com/jmoloko/taskmange/internal/domain/models"
 	domainService "github.com/jmoloko/taskmange/internal/domain/service"
+	"github.com/jmoloko/taskmange/internal/exporter"
+	"github.com/jmoloko/taskmange/internal/importer"
 	"github.com/jmoloko/taskmange/internal/logger"
+	"github.com/jmoloko/taskmange/internal/middleware"
+	"github.com/jmoloko/taskmange/internal/redact"
+	"github.com/jmoloko/taskmange/internal/search"
 	"github.com/jmoloko/taskmange/internal/service"
 )
 
 // TaskHandler обрабатывает HTTP-запросы для задач
 type TaskHandler struct {
-	service domainService.TaskService
-	logger  logger.Logger
+	service   domainService.TaskService
+	transfers *service.TransferService
+	redaction *redact.Policy
+	imports   importer.Limits
+	clock     clock.Clock
+	logger    logger.Logger
 }
 
-// NewTaskHandler создаёт новый обработчик для задач
-func NewTaskHandler(service domainService.TaskService, logger logger.Logger) *TaskHandler {
+// NewTaskHandler создаёт новый обработчик для задач.
+// transfers может быть nil, тогда история импорта и экспорта не ведется;
+// redaction может быть nil, тогда экспорт редактируется только по профилю из запроса;
+// imports ограничивает размер и число строк импорта и предпросмотра
+func NewTaskHandler(service domainService.TaskService, transfers *service.TransferService, redaction *redact.Policy, imports importer.Limits, logger logger.Logger) *TaskHandler {
 	return &TaskHandler{
-		service: service,
-		logger:  logger,
+		service:   service,
+		transfers: transfers,
+		redaction: redaction,
+		imports:   imports,
+		clock:     clock.System,
+		logger:    logger,
 	}
 }
 
+// log логгер запроса с request_id
+func (h *TaskHandler) log(c *gin.Context) logger.Logger {
+	return logger.FromContext(c.Request.Context(), h.logger)
+}
+
+// maxTasksPageSize наибольший допустимый limit списка задач
+const maxTasksPageSize = 500
+
 // GetTasks получение списка задач
 // @Summary Get all tasks
-// @Description Get all tasks with optional filtering
+// @Description Get all tasks with optional filtering. With limit and offset only one page is returned; X-Total-Count holds the number of tasks matching the filters across all pages. For infinite scroll use sort=due with limit and pass the X-Next-Cursor header of a full page as cursor to get the next one (X-Next-After-Id and X-Next-After-Due as after_id and after_due work too): unlike offset, the cursor does not shift when tasks are added or removed. With API-Version: 3 the response is a models.TaskPage object with tasks, total and next_cursor instead of an array
 // @Tags tasks
 // @Accept json
 // @Produce json
-// @Param status query string false "Filter by status"
+// @Param status query string false "Filter by status; overdue selects open tasks whose due date has passed, the same tasks that have is_overdue set"
 // @Param priority query string false "Filter by priority"
 // @Param due_date query string false "Filter by due date (RFC3339 format)"
 // @Param search query string false "Search in title and description"
+// @Param q query string false "Search query, e.g. status:done priority:high due<2024-07-01 \"quarterly report\". Conditions: status, priority, tag (or #tag), project, is:open, is:overdue, due with :, <, <=, >, >= and a YYYY-MM-DD date, today, tomorrow or yesterday; other words and quoted phrases must all occur in the title or description. Conditions override the matching parameters"
+// @Param tz query string false "IANA timezone for dates in q, UTC by default"
+// @Param tag query string false "Filter by tag"
+// @Param project_id query string false "Filter by project"
+// @Param assignee query string false "Set to me to list tasks of other users assigned to the current user instead of own tasks"
+// @Param sort query string false "Set to smart to order by priority, due date proximity and age, or to due to order by due date and ID for cursor pagination"
+// @Param expand query string false "Set to links to include related task summaries"
+// @Param limit query int false "Page size, 1-500; without it all matching tasks are returned"
+// @Param offset query int false "Number of tasks to skip"
+// @Param cursor query string false "Cursor: next_cursor or X-Next-Cursor of the previous page, implies sort=due"
+// @Param after_id query string false "Cursor: ID of the last task of the previous page, together with after_due"
+// @Param after_due query string false "Cursor: due date of the last task of the previous page (RFC3339), together with after_id"
 // @Security BearerAuth
-// @Success 200 {array} models.Task
+// @Success 200 {object} models.TaskPage "API version 3 or later; earlier versions return an array of models.Task"
+// @Header 200 {integer} X-Total-Count "Number of tasks matching the filters"
+// @Header 200 {string} X-Next-Cursor "Cursor of the next page, only for a full page with sort=due"
+// @Header 200 {string} X-Next-After-Id "Cursor of the next page, only for a full page with sort=due"
+// @Header 200 {string} X-Next-After-Due "Cursor of the next page, only for a full page with sort=due"
+// @Failure 400 {object} map[string]string "Bad Request"
 // @Failure 401 {object} map[string]string "Unauthorized"
 // @Failure 500 {object} map[string]string "Internal Server Error"
 // @Router /tasks [get]
@@ -49,30 +98,155 @@ func (h *TaskHandler) GetTasks(c *gin.Context) {
 	}
 
 	filters := models.TaskFilters{
-		Status:   models.Status(c.Query("status")),
-		Priority: models.Priority(c.Query("priority")),
-		UserID:   userID.(string),
-		Search:   c.Query("search"),
+		Status:    models.Status(c.Query("status")),
+		Priority:  models.Priority(c.Query("priority")),
+		UserID:    userID.(string),
+		Search:    c.Query("search"),
+		Tag:       strings.ToLower(strings.TrimSpace(c.Query("tag"))),
+		Sort:      models.TaskSort(c.Query("sort")),
+		ProjectID: c.Query("project_id"),
+	}
+
+	if !filters.Sort.Valid() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid sort, supported values: smart, due"})
+		return
+	}
+
+	if q := c.Query("q"); q != "" {
+		loc, err := time.LoadLocation(c.DefaultQuery("tz", "UTC"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown timezone"})
+			return
+		}
+		query, err := search.Parse(q, h.clock.Now().In(loc))
+		if err != nil {
+			var syntaxErr *search.SyntaxError
+			if errors.As(err, &syntaxErr) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query " + syntaxErr.Error(), "position": syntaxErr.Pos})
+				return
+			}
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query"})
+			return
+		}
+		query.Apply(&filters)
+	}
+
+	// overdue не хранится в задачах: это условие на статус и срок, которое проверяет запрос к БД
+	if filters.Status == models.StatusOverdue {
+		filters.Status, filters.Overdue = "", true
+	}
+
+	switch c.Query("assignee") {
+	case "":
+	case "me":
+		filters.Assigned = true
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid assignee, supported values: me"})
+		return
+	}
+
+	afterID, afterDue := c.Query("after_id"), c.Query("after_due")
+	if encoded := c.Query("cursor"); encoded != "" {
+		if afterID != "" || afterDue != "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "cursor can not be combined with after_id and after_due"})
+			return
+		}
+		cursor, err := models.ParseTaskCursor(encoded)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid cursor"})
+			return
+		}
+		filters.After = &cursor
+	} else if afterID != "" || afterDue != "" {
+		due, err := time.Parse(time.RFC3339Nano, afterDue)
+		if afterID == "" || err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "after_id and after_due (RFC3339) must be passed together"})
+			return
+		}
+		filters.After = &models.TaskCursor{DueDate: due, ID: afterID}
+	}
+	if filters.After != nil {
+		if filters.Sort == models.SortDefault {
+			filters.Sort = models.SortDue
+		}
+		if filters.Sort != models.SortDue || c.Query("offset") != "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Cursor pagination supports only sort=due without offset"})
+			return
+		}
 	}
 
 	if dueDateStr := c.Query("due_date"); dueDateStr != "" {
 		dueDate, err := time.Parse(time.RFC3339, dueDateStr)
 		if err != nil {
-			h.logger.Error("Invalid due_date format: %v", err)
+			h.log(c).Error("Invalid due_date format: %v", err)
 			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid due_date format"})
 			return
 		}
 		filters.DueDate = &dueDate
 	}
 
+	if limit := c.Query("limit"); limit != "" {
+		value, err := strconv.Atoi(limit)
+		if err != nil || value < 1 || value > maxTasksPageSize {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid limit, must be between 1 and %d", maxTasksPageSize)})
+			return
+		}
+		filters.Limit = value
+	}
+	if offset := c.Query("offset"); offset != "" {
+		value, err := strconv.Atoi(offset)
+		if err != nil || value < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid offset, must be a non-negative integer"})
+			return
+		}
+		filters.Offset = value
+	}
+
 	tasks, err := h.service.GetUserTasks(c.Request.Context(), userID.(string), filters)
 	if err != nil {
-		h.logger.Error("Failed to get tasks: %v", err)
+		h.log(c).Error("Failed to get tasks: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get tasks"})
 		return
 	}
 
-	c.JSON(http.StatusOK, tasks)
+	// без страницы в ответе все подходящие задачи, отдельный подсчет не нужен
+	total := len(tasks)
+	if filters.Limit > 0 || filters.Offset > 0 || filters.After != nil {
+		if total, err = h.service.CountUserTasks(c.Request.Context(), userID.(string), filters); err != nil {
+			h.log(c).Error("Failed to count tasks: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get tasks"})
+			return
+		}
+	}
+	c.Header("X-Total-Count", strconv.Itoa(total))
+
+	// неполная страница последняя, курсор следующей отдается только для полной
+	var next string
+	if filters.Sort == models.SortDue && filters.Limit > 0 && len(tasks) == filters.Limit {
+		last := tasks[len(tasks)-1]
+		next = models.TaskCursor{DueDate: last.DueDate, ID: last.ID}.Encode()
+		c.Header("X-Next-Cursor", next)
+		c.Header("X-Next-After-Id", last.ID)
+		c.Header("X-Next-After-Due", last.DueDate.UTC().Format(time.RFC3339Nano))
+	}
+
+	if expandsLinks(c) {
+		if tasks, err = h.service.ExpandRelated(c.Request.Context(), userID.(string), tasks); err != nil {
+			h.log(c).Error("Failed to get related tasks: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get tasks"})
+			return
+		}
+	}
+
+	// до третьей версии API тело ответа — массив задач, страница описывается заголовками
+	if middleware.APIVersion(c) < middleware.APIVersion3 {
+		c.JSON(http.StatusOK, tasks)
+		return
+	}
+	if tasks == nil {
+		tasks = []models.Task{}
+	}
+	c.JSON(http.StatusOK, models.TaskPage{Tasks: tasks, Total: total, NextCursor: next})
 }
 
 // GetTask получение задачи по ID
@@ -82,6 +256,7 @@ func (h *TaskHandler) GetTasks(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param id path string true "Task ID"
+// @Param expand query string false "Set to links to include related task summaries"
 // @Security BearerAuth
 // @Success 200 {object} models.Task
 // @Failure 400 {object} map[string]string "Bad Request"
@@ -112,25 +287,86 @@ func (h *TaskHandler) GetTask(c *gin.Context) {
 			c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
 			return
 		}
-		h.logger.Error("Failed to get task: %v", err)
+		h.log(c).Error("Failed to get task: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get task"})
 		return
 	}
 
+	if expandsLinks(c) {
+		expanded, err := h.service.ExpandRelated(c.Request.Context(), userID.(string), []models.Task{task})
+		if err != nil {
+			h.log(c).Error("Failed to get related tasks: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get task"})
+			return
+		}
+		task = expanded[0]
+	}
+
+	c.JSON(http.StatusOK, task)
+}
+
+// UnlockTask расшифровка приватной задачи
+// @Summary Unlock a private task
+// @Description Get a private task with decrypted title and description
+// @Tags tasks
+// @Accept json
+// @Produce json
+// @Param id path string true "Task ID"
+// @Security BearerAuth
+// @Success 200 {object} models.Task
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Forbidden"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /tasks/{id}/unlock [post]
+func (h *TaskHandler) UnlockTask(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	taskID := c.Param("id")
+	if taskID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Task ID is required"})
+		return
+	}
+
+	task, err := h.service.UnlockUserTask(c.Request.Context(), userID.(string), taskID)
+	if err != nil {
+		switch err {
+		case service.ErrTaskNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+		case service.ErrAccessDenied:
+			c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+		case service.ErrPrivateTasksDisabled:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Private tasks are disabled"})
+		default:
+			h.log(c).Error("Failed to unlock task: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unlock task"})
+		}
+		return
+	}
+
 	c.JSON(http.StatusOK, task)
 }
 
 // CreateTask создание новой задачи
 // @Summary Create a new task
-// @Description Create a new task
+// @Description Create a new task. Open tasks are limited per user; near the limit the response contains a warnings array. A task with a recurrence rule (RRULE subset: FREQ, INTERVAL, BYDAY, COUNT, UNTIL) starts a series: the next occurrence is created when the task is done or overdue
 // @Tags tasks
 // @Accept json
 // @Produce json
 // @Param task body models.Task true "Task object to create"
+// @Param Idempotency-Key header string false "Repeated requests with the same key and body return the original response"
 // @Security BearerAuth
 // @Success 201 {object} models.Task
 // @Failure 400 {object} map[string]string "Bad Request"
 // @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 409 {object} map[string]string "A request with the same Idempotency-Key is in progress"
+// @Failure 413 {object} map[string]string "Request body with Idempotency-Key is too large"
+// @Failure 422 {object} map[string]string "Idempotency-Key reused with a different request"
 // @Failure 500 {object} map[string]string "Internal Server Error"
 // @Router /tasks [post]
 func (h *TaskHandler) CreateTask(c *gin.Context) {
@@ -142,27 +378,22 @@ func (h *TaskHandler) CreateTask(c *gin.Context) {
 
 	var task models.Task
 	if err := c.ShouldBindJSON(&task); err != nil {
-		h.logger.Error("Failed to parse task: %v", err)
+		h.log(c).Error("Failed to parse task: %v", err)
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
 		return
 	}
 
-	// инициализация полей задачи
+	// инициализация полей задачи, временные метки проставляет БД
 	if task.ID == "" {
 		task.ID = uuid.New().String()
 	}
 
-	now := time.Now()
-	task.CreatedAt = now
-	task.UpdatedAt = now
-
 	createdTask, err := h.service.CreateTask(c.Request.Context(), userID.(string), task)
 	if err != nil {
-		if err == service.ErrInvalidTaskData {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task data"})
+		if createError(c, err) {
 			return
 		}
-		h.logger.Error("Failed to create task: %v", err)
+		h.log(c).Error("Failed to create task: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create task"})
 		return
 	}
@@ -170,9 +401,36 @@ func (h *TaskHandler) CreateTask(c *gin.Context) {
 	c.JSON(http.StatusCreated, createdTask)
 }
 
+// createError отвечает на ошибки проверки новой задачи; false — ошибка не из их числа
+func createError(c *gin.Context, err error) bool {
+	switch err {
+	case service.ErrInvalidTaskData:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task data"})
+	case service.ErrInvalidLink:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Links must be http(s) URLs, at most 20 per task"})
+	case service.ErrInvalidTag:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Tags must be 1-50 characters without commas, at most 20 per task"})
+	case service.ErrInvalidRecurrence:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid recurrence rule"})
+	case service.ErrPrivateTasksDisabled:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Private tasks are disabled"})
+	case service.ErrTaskQuotaExceeded:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Open task limit reached"})
+	case service.ErrInvalidProject:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Project not found"})
+	default:
+		if msg, ok := parentError(err); ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+			return true
+		}
+		return constraintError(c, err)
+	}
+	return true
+}
+
 // UpdateTask обновление задачи
 // @Summary Update a task
-// @Description Update an existing task
+// @Description Update an existing task. A task with open subtasks cannot be marked as done
 // @Tags tasks
 // @Accept json
 // @Produce json
@@ -184,6 +442,7 @@ func (h *TaskHandler) CreateTask(c *gin.Context) {
 // @Failure 401 {object} map[string]string "Unauthorized"
 // @Failure 403 {object} map[string]string "Forbidden"
 // @Failure 404 {object} map[string]string "Not Found"
+// @Failure 409 {object} map[string]string "Task has open subtasks"
 // @Failure 500 {object} map[string]string "Internal Server Error"
 // @Router /tasks/{id} [put]
 func (h *TaskHandler) UpdateTask(c *gin.Context) {
@@ -201,41 +460,161 @@ func (h *TaskHandler) UpdateTask(c *gin.Context) {
 
 	var task models.Task
 	if err := c.ShouldBindJSON(&task); err != nil {
-		h.logger.Error("Failed to parse task: %v", err)
+		h.log(c).Error("Failed to parse task: %v", err)
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
 		return
 	}
 
 	task.ID = taskID
-	task.UpdatedAt = time.Now()
 
 	updatedTask, err := h.service.UpdateUserTask(c.Request.Context(), userID.(string), task)
 	if err != nil {
+		h.updateError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, updatedTask)
+}
+
+// PatchTask частичное обновление задачи
+// @Summary Partially update a task
+// @Description Change only the fields present in the body. Unlike PUT, an empty description or notes clears the field. Missing and null fields are left unchanged. An empty parent_id detaches the task from its parent. Marking a task with open subtasks as done fails unless complete_subtasks is true
+// @Tags tasks
+// @Accept json
+// @Produce json
+// @Param id path string true "Task ID"
+// @Param task body models.UpdateTaskRequest true "Fields to change"
+// @Security BearerAuth
+// @Success 200 {object} models.Task
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Forbidden"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 409 {object} map[string]string "Task has open subtasks"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /tasks/{id} [patch]
+func (h *TaskHandler) PatchTask(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var req models.UpdateTaskRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.log(c).Error("Failed to parse task patch: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	updatedTask, err := h.service.PatchUserTask(c.Request.Context(), userID.(string), c.Param("id"), req)
+	if err != nil {
+		h.updateError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, updatedTask)
+}
+
+// updateError ответ на ошибку обновления задачи, общий для PUT и PATCH
+func (h *TaskHandler) updateError(c *gin.Context, err error) {
+	switch {
+	case err == service.ErrTaskNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+	case err == service.ErrAccessDenied:
+		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+	case err == service.ErrInvalidTaskData:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task data"})
+	case err == service.ErrPrivateTasksDisabled:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Private tasks are disabled"})
+	case err == service.ErrInvalidLink:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Links must be http(s) URLs, at most 20 per task"})
+	case err == service.ErrInvalidTag:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Tags must be 1-50 characters without commas, at most 20 per task"})
+	case err == service.ErrOpenSubtasks:
+		c.JSON(http.StatusConflict, gin.H{"error": "Task has open subtasks"})
+	case err == service.ErrInvalidProject:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Project not found"})
+	case err == service.ErrPrivateTaskAssignment:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Assigned tasks cannot be private"})
+	default:
+		if msg, ok := parentError(err); ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+			return
+		}
+		if constraintError(c, err) {
+			return
+		}
+		h.log(c).Error("Failed to update task: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update task"})
+	}
+}
+
+// CompleteTasks пакетное выполнение задач
+// @Summary Complete tasks in batch
+// @Description Mark up to 500 tasks as done in one transaction. If any task is not found or has open subtasks outside the batch, nothing is changed. Already completed tasks are returned in skipped. One tasks.completed event is published for the whole batch
+// @Tags tasks
+// @Accept json
+// @Produce json
+// @Param request body models.CompleteTasksRequest true "Task IDs"
+// @Security BearerAuth
+// @Success 200 {object} models.CompleteTasksResult
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 409 {object} map[string]string "Task has open subtasks"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /tasks/complete [post]
+func (h *TaskHandler) CompleteTasks(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var req models.CompleteTasksRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.log(c).Error("Failed to parse complete request: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	result, err := h.service.CompleteTasks(c.Request.Context(), userID.(string), req.IDs)
+	if err != nil {
+		if err == service.ErrInvalidTaskData {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "ids must contain 1-500 non-empty task IDs"})
+			return
+		}
 		if err == service.ErrTaskNotFound {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
 			return
 		}
-		if err == service.ErrAccessDenied {
-			c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+		if err == service.ErrOpenSubtasks {
+			c.JSON(http.StatusConflict, gin.H{"error": "Task has open subtasks"})
 			return
 		}
-		h.logger.Error("Failed to update task: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update task"})
+		if constraintError(c, err) {
+			return
+		}
+		h.log(c).Error("Failed to complete tasks: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to complete tasks"})
 		return
 	}
 
-	c.JSON(http.StatusOK, updatedTask)
+	c.JSON(http.StatusOK, result)
 }
 
 // DeleteTask удаление задачи
 // @Summary Delete a task
-// @Description Delete a task by ID
+// @Description Delete a task by ID. With API-Version: 2 or later the response is 204 without a body; version 1 (the default unless API_DEFAULT_VERSION says otherwise) keeps the old 200 response with a message
 // @Tags tasks
 // @Accept json
 // @Produce json
 // @Param id path string true "Task ID"
+// @Param API-Version header int false "API version, 1 or 2"
 // @Security BearerAuth
-// @Success 204 "No Content"
+// @Success 200 {object} map[string]string "Deleted (API version 1)"
+// @Success 204 "No Content (API version 2)"
 // @Failure 400 {object} map[string]string "Bad Request"
 // @Failure 401 {object} map[string]string "Unauthorized"
 // @Failure 403 {object} map[string]string "Forbidden"
@@ -264,27 +643,166 @@ func (h *TaskHandler) DeleteTask(c *gin.Context) {
 			c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
 			return
 		}
-		h.logger.Error("Failed to delete task: %v", err)
+		h.log(c).Error("Failed to delete task: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete task"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Task deleted successfully"})
+	// до второй версии API удаление отвечало 200 с сообщением, старые клиенты на это рассчитывают
+	if middleware.APIVersion(c) < middleware.APIVersion2 {
+		c.JSON(http.StatusOK, gin.H{"message": "Task deleted successfully"})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// ndjsonContentType поток JSON-строк, по одному объекту на строку
+const ndjsonContentType = "application/x-ndjson"
+
+// DeleteTasks массовое удаление задач по фильтру
+// @Summary Delete tasks by filter
+// @Description Delete the current user's tasks, archived ones included, that match all given filters, e.g. status=done&completed_before=2023-01-01. At least one filter is required. Tasks are deleted in batches of 500, each batch in its own transaction with a short pause between batches; every batch publishes one tasks.deleted event.
+// @Description If the request is interrupted, tasks of finished batches stay deleted and the request can be repeated. With Accept: application/x-ndjson the progress is streamed as one JSON line per batch and the last line has done=true or error; otherwise the response is the final progress
+// @Tags tasks
+// @Produce json,application/x-ndjson
+// @Param status query string false "Filter by status"
+// @Param priority query string false "Filter by priority"
+// @Param project_id query string false "Filter by project"
+// @Param completed_before query string false "Only tasks completed before this time (RFC3339 or YYYY-MM-DD, UTC)"
+// @Param created_before query string false "Only tasks created before this time (RFC3339 or YYYY-MM-DD, UTC)"
+// @Security BearerAuth
+// @Success 200 {object} models.BulkDeleteProgress
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Failure 503 {object} map[string]string "Server is overloaded"
+// @Router /tasks [delete]
+func (h *TaskHandler) DeleteTasks(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	filter := models.TaskDeleteFilter{
+		Status:    models.Status(c.Query("status")),
+		Priority:  models.Priority(c.Query("priority")),
+		ProjectID: c.Query("project_id"),
+	}
+	switch filter.Status {
+	case "", models.StatusPending, models.StatusInProgress, models.StatusDone:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid status"})
+		return
+	}
+	if filter.Priority != "" && filter.Priority.Rank() == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid priority"})
+		return
+	}
+	for _, bound := range []struct {
+		param string
+		dest  **time.Time
+	}{
+		{"completed_before", &filter.CompletedBefore},
+		{"created_before", &filter.CreatedBefore},
+	} {
+		param, dest := bound.param, bound.dest
+		value := c.Query(param)
+		if value == "" {
+			continue
+		}
+		parsed, err := parseTimeParam(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid " + param + ", expected RFC3339 or YYYY-MM-DD"})
+			return
+		}
+		*dest = &parsed
+	}
+	if filter.Empty() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "At least one filter is required"})
+		return
+	}
+
+	// в потоке каждая пачка отправляется сразу; медленный клиент задерживает запись,
+	// а с ней и следующую пачку, поэтому удаление идет не быстрее, чем клиент читает ход
+	stream := strings.Contains(c.GetHeader("Accept"), ndjsonContentType)
+	started := false
+	progress := func(p models.BulkDeleteProgress) error {
+		if !stream {
+			return nil
+		}
+		if !started {
+			started = true
+			_ = http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{})
+			c.Header("Content-Type", ndjsonContentType)
+			c.Header("Cache-Control", "no-cache")
+			c.Header("X-Accel-Buffering", "no")
+			c.Status(http.StatusOK)
+		}
+		if err := json.NewEncoder(c.Writer).Encode(p); err != nil {
+			return err
+		}
+		c.Writer.Flush()
+		return nil
+	}
+
+	result, err := h.service.DeleteTasks(c.Request.Context(), userID.(string), filter, progress)
+	if started {
+		if err != nil {
+			h.log(c).Error("Failed to delete tasks: %v", err)
+			result.Error = "Failed to delete tasks"
+		}
+		progress(result)
+		return
+	}
+	if err != nil {
+		if err == service.ErrInvalidTaskData {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "At least one filter is required"})
+			return
+		}
+		h.log(c).Error("Failed to delete tasks: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete tasks", "deleted": result.Deleted})
+		return
+	}
+
+	if stream {
+		progress(result)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// parseTimeParam время из параметра запроса в RFC3339 или дата YYYY-MM-DD (полночь UTC)
+func parseTimeParam(value string) (time.Time, error) {
+	if parsed, err := time.Parse(time.RFC3339, value); err == nil {
+		return parsed, nil
+	}
+	return time.Parse(time.DateOnly, value)
 }
 
 // ImportTasks импортируем задачи из файла
 // @Summary Import tasks
-// @Description Import tasks from a JSON file
+// @Description Import tasks from a JSON array in the request body, or from a JSON, CSV or XLSX file uploaded as multipart/form-data in the "file" field.
+// @Description A file is read row by row and created in one batch; the response is an import report. If any row is invalid nothing is created and the report comes with 422, unless skip_invalid=true.
+// @Description Open tasks are limited per user; near the limit the response contains a warnings array
 // @Tags tasks
-// @Accept json
+// @Accept json,mpfd
 // @Produce json
 // @Param tasks body []models.Task true "Array of tasks to import"
+// @Param skip_invalid query bool false "Import valid rows of an uploaded file and report the rest"
+// @Param Idempotency-Key header string false "Repeated requests with the same key and body return the original response"
 // @Security BearerAuth
+// @Success 200 {object} models.ImportReport "Import report for an uploaded file"
 // @Success 201 {object} map[string]string "Tasks imported successfully"
 // @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 409 {object} map[string]string "A request with the same Idempotency-Key is in progress"
+// @Failure 413 {object} map[string]string "Request body is larger than IMPORT_MAX_BYTES or the Idempotency-Key limit"
+// @Failure 415 {object} map[string]string "Unsupported Media Type"
+// @Failure 422 {object} models.ImportReport "Invalid rows, nothing imported; more than IMPORT_MAX_ROWS rows; or Idempotency-Key reused with a different request"
 // @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 503 {object} map[string]string "Server is busy"
 // @Failure 500 {object} map[string]string "Internal Server Error"
-// @Router /tasks/import [post]
+// @Router /task-imports [post]
 func (h *TaskHandler) ImportTasks(c *gin.Context) {
 	userID, exists := c.Get("user_id")
 	if !exists {
@@ -292,15 +810,49 @@ func (h *TaskHandler) ImportTasks(c *gin.Context) {
 		return
 	}
 
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.imports.MaxBytes)
+	if c.ContentType() == "multipart/form-data" {
+		h.importFile(c, userID.(string))
+		return
+	}
+
+	started := time.Now()
 	var tasks []models.Task
 	if err := c.ShouldBindJSON(&tasks); err != nil {
-		h.logger.Error("Failed to parse tasks: %v", err)
+		h.recordTransfer(c, userID.(string), models.TransferImport, importer.FormatJSON, 0, started, err)
+		if h.importLimitExceeded(c, err) {
+			return
+		}
+		h.log(c).Error("Failed to parse tasks: %v", err)
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
 		return
 	}
+	if h.imports.MaxRows > 0 && len(tasks) > h.imports.MaxRows {
+		err := fmt.Errorf("%w: %d tasks", importer.ErrTooManyRows, len(tasks))
+		h.recordTransfer(c, userID.(string), models.TransferImport, importer.FormatJSON, 0, started, err)
+		h.importLimitExceeded(c, err)
+		return
+	}
 
-	if err := h.service.ImportTasks(c.Request.Context(), userID.(string), tasks); err != nil {
-		h.logger.Error("Failed to import tasks: %v", err)
+	err := h.service.ImportTasks(c.Request.Context(), userID.(string), tasks)
+	h.recordTransfer(c, userID.(string), models.TransferImport, importer.FormatJSON, len(tasks), started, err)
+	if err != nil {
+		if err == service.ErrInvalidLink {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Links must be http(s) URLs, at most 20 per task"})
+			return
+		}
+		if err == service.ErrInvalidTag {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Tags must be 1-50 characters without commas, at most 20 per task"})
+			return
+		}
+		if err == service.ErrTaskQuotaExceeded {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Open task limit reached"})
+			return
+		}
+		if constraintError(c, err) {
+			return
+		}
+		h.log(c).Error("Failed to import tasks: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to import tasks"})
 		return
 	}
@@ -308,17 +860,161 @@ func (h *TaskHandler) ImportTasks(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Tasks imported successfully"})
 }
 
+// importFile импорт файла из multipart-запроса. Части читаются потоком, файл не сохраняется
+// ни в памяти, ни на диске целиком
+func (h *TaskHandler) importFile(c *gin.Context, userID string) {
+	skipInvalid, _ := strconv.ParseBool(c.Query("skip_invalid"))
+
+	parts, err := c.Request.MultipartReader()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid multipart body"})
+		return
+	}
+
+	var file *multipart.Part
+	for {
+		part, err := parts.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			if !h.importLimitExceeded(c, err) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid multipart body"})
+			}
+			return
+		}
+		if part.FormName() == "file" {
+			file = part
+			break
+		}
+	}
+	if file == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "File is required in the file field"})
+		return
+	}
+
+	format, err := importer.DetectFileFormat(file.FileName(), file.Header.Get("Content-Type"))
+	if err != nil {
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": "Supported formats: JSON, CSV, XLSX"})
+		return
+	}
+
+	started := time.Now()
+	rows, err := importer.NewReader(format, file, h.imports.MaxRows)
+	if err != nil {
+		h.recordTransfer(c, userID, models.TransferImport, format, 0, started, err)
+		if !h.importLimitExceeded(c, err) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		}
+		return
+	}
+	defer rows.Close()
+
+	report, err := h.service.ImportTaskFile(c.Request.Context(), userID, rows, skipInvalid)
+	h.recordTransfer(c, userID, models.TransferImport, format, report.Imported, started, err)
+	if err != nil {
+		if h.importLimitExceeded(c, err) {
+			return
+		}
+		switch {
+		case errors.Is(err, service.ErrImportInvalidRows):
+			c.JSON(http.StatusUnprocessableEntity, report)
+		case errors.Is(err, importer.ErrInvalidFile):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, service.ErrTaskQuotaExceeded):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Open task limit reached"})
+		default:
+			if constraintError(c, err) {
+				return
+			}
+			h.log(c).Error("Failed to import tasks: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to import tasks"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// importLimitExceeded отвечает 413 на тело больше imports.MaxBytes и 422 на файл длиннее
+// imports.MaxRows строк; false — ошибка не связана с ограничениями импорта
+func (h *TaskHandler) importLimitExceeded(c *gin.Context, err error) bool {
+	var tooLarge *http.MaxBytesError
+	switch {
+	case errors.As(err, &tooLarge):
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": fmt.Sprintf("Import is limited to %d bytes", h.imports.MaxBytes)})
+	case errors.Is(err, importer.ErrTooManyRows):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": fmt.Sprintf("Import is limited to %d rows", h.imports.MaxRows)})
+	default:
+		return false
+	}
+	return true
+}
+
+// PreviewImport предпросмотр импорта задач
+// @Summary Preview task import
+// @Description Parse a JSON array, CSV or XLSX file, validate rows and detect duplicates without creating tasks.
+// @Description CSV and XLSX files need a header row with columns title, description, status, priority, due_date, private
+// @Tags tasks
+// @Accept json,text/csv,application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Produce json
+// @Param tasks body []models.Task true "Tasks to import"
+// @Security BearerAuth
+// @Success 200 {object} models.ImportPreview
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 413 {object} map[string]string "Request body is larger than IMPORT_MAX_BYTES"
+// @Failure 415 {object} map[string]string "Unsupported Media Type"
+// @Failure 422 {object} map[string]string "More than IMPORT_MAX_ROWS rows"
+// @Failure 503 {object} map[string]string "Server is busy"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /task-imports/preview [post]
+func (h *TaskHandler) PreviewImport(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	format, err := importer.DetectFormat(c.ContentType())
+	if err != nil {
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": "Supported formats: JSON, CSV, XLSX"})
+		return
+	}
+
+	batch, err := importer.Parse(format, http.MaxBytesReader(c.Writer, c.Request.Body, h.imports.MaxBytes), h.imports.MaxRows)
+	if err != nil {
+		if !h.importLimitExceeded(c, err) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	preview, err := h.service.PreviewImport(c.Request.Context(), userID.(string), batch)
+	if err != nil {
+		h.log(c).Error("Failed to preview import: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to preview import"})
+		return
+	}
+
+	c.JSON(http.StatusOK, preview)
+}
+
 // ExportTasks экспортируем задачи в файл
 // @Summary Export tasks
-// @Description Export all user's tasks as JSON
+// @Description Export all user's tasks as JSON, CSV or XLSX. CSV and XLSX come as a file download (Content-Disposition: attachment) with the same columns the import accepts, so an export can be imported back; links are separated by spaces and tags by commas. The redaction profile configured for exports is always applied; redact adds more profiles: descriptions strips task descriptions, emails replaces email addresses in titles and descriptions with stable pseudonyms, and installations may define named combinations
 // @Tags tasks
 // @Accept json
-// @Produce json
+// @Produce json,text/csv,application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param format query string false "Export format: json, csv or xlsx" default(json)
+// @Param redact query string false "Comma-separated redaction profiles, e.g. descriptions,emails"
 // @Security BearerAuth
 // @Success 200 {array} models.Task
+// @Failure 400 {object} map[string]string "Unknown format or redaction profile"
 // @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 503 {object} map[string]string "Server is busy"
 // @Failure 500 {object} map[string]string "Internal Server Error"
-// @Router /tasks/export [get]
+// @Router /task-exports [get]
 func (h *TaskHandler) ExportTasks(c *gin.Context) {
 	userID, exists := c.Get("user_id")
 	if !exists {
@@ -326,29 +1022,55 @@ func (h *TaskHandler) ExportTasks(c *gin.Context) {
 		return
 	}
 
+	format, err := exporter.ParseFormat(c.Query("format"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid format, supported values: json, csv, xlsx"})
+		return
+	}
+	profile, err := h.redaction.Export(c.Query("redact"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown redaction profile"})
+		return
+	}
+
+	started := time.Now()
 	tasks, err := h.service.ExportUserTasks(c.Request.Context(), userID.(string))
+	h.recordTransfer(c, userID.(string), models.TransferExport, format, len(tasks), started, err)
 	if err != nil {
-		h.logger.Error("Failed to export tasks: %v", err)
+		h.log(c).Error("Failed to export tasks: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export tasks"})
 		return
 	}
 
-	c.JSON(http.StatusOK, tasks)
+	// таблицы браузер сохраняет файлом, JSON остается ответом API
+	if format != exporter.FormatJSON {
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, exporter.FileName(format, started)))
+	}
+	c.Header("Content-Type", exporter.ContentType(format))
+	c.Status(http.StatusOK)
+	// заголовки уже отправлены, ошибку записи остается только залогировать
+	if err := exporter.Write(format, c.Writer, profile.Tasks(tasks)); err != nil {
+		h.log(c).Error("Failed to write task export: %v", err)
+	}
 }
 
 // GetAnalytics получаем аналитику
 // @Summary Get task analytics
-// @Description Get analytics for user's tasks
+// @Description Get analytics for user's tasks over the last day, week or month: status and priority counts of tasks created in the period, completion time and on-time rate of tasks completed in it, and open tasks that became overdue in it
+// @Description Instead of period an arbitrary window, e.g. a sprint, can be given with from and to; the response then has period=custom and the window bounds. The window must not be empty or longer than a year
 // @Tags analytics
 // @Accept json
 // @Produce json
-// @Param period query string true "Analytics period (day/week/month)"
+// @Param period query string false "Analytics period (day/week/month)" default(week)
+// @Param from query string false "Window start, inclusive (RFC3339 or YYYY-MM-DD, UTC); requires to"
+// @Param to query string false "Window end, exclusive (RFC3339 or YYYY-MM-DD, UTC); requires from"
 // @Security BearerAuth
 // @Success 200 {object} models.Analytics
 // @Failure 400 {object} map[string]string "Bad Request"
 // @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 503 {object} map[string]string "Server is busy"
 // @Failure 500 {object} map[string]string "Internal Server Error"
-// @Router /analytics [get]
+// @Router /task-analytics [get]
 func (h *TaskHandler) GetAnalytics(c *gin.Context) {
 	userID, exists := c.Get("user_id")
 	if !exists {
@@ -357,6 +1079,11 @@ func (h *TaskHandler) GetAnalytics(c *gin.Context) {
 	}
 
 	period := c.Query("period")
+	fromParam, toParam := c.Query("from"), c.Query("to")
+	if fromParam != "" || toParam != "" {
+		h.getAnalyticsRange(c, userID.(string), period, fromParam, toParam)
+		return
+	}
 	if period == "" {
 		period = "week"
 	}
@@ -368,7 +1095,42 @@ func (h *TaskHandler) GetAnalytics(c *gin.Context) {
 
 	analytics, err := h.service.GetUserAnalytics(c.Request.Context(), userID.(string), period)
 	if err != nil {
-		h.logger.Error("Failed to get analytics: %v", err)
+		h.log(c).Error("Failed to get analytics: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get analytics"})
+		return
+	}
+
+	c.JSON(http.StatusOK, analytics)
+}
+
+// getAnalyticsRange аналитика за окно из параметров from и to, period с ними не сочетается
+func (h *TaskHandler) getAnalyticsRange(c *gin.Context, userID, period, fromParam, toParam string) {
+	if period != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "period can not be combined with from and to"})
+		return
+	}
+	if fromParam == "" || toParam == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from and to must be given together"})
+		return
+	}
+	from, err := parseTimeParam(fromParam)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid from, expected RFC3339 or YYYY-MM-DD"})
+		return
+	}
+	to, err := parseTimeParam(toParam)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid to, expected RFC3339 or YYYY-MM-DD"})
+		return
+	}
+
+	analytics, err := h.service.GetUserAnalyticsRange(c.Request.Context(), userID, from, to)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidAnalyticsRange) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid range, from must be before to and the window at most a year long"})
+			return
+		}
+		h.log(c).Error("Failed to get analytics: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get analytics"})
 		return
 	}
@@ -376,6 +1138,34 @@ func (h *TaskHandler) GetAnalytics(c *gin.Context) {
 	c.JSON(http.StatusOK, analytics)
 }
 
+// GetDashboard получаем сводку по задачам
+// @Summary Get task dashboard
+// @Description Get task counters for the dashboard without loading tasks
+// @Tags tasks
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} models.Dashboard
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /tasks/dashboard [get]
+func (h *TaskHandler) GetDashboard(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	dashboard, err := h.service.GetDashboard(c.Request.Context(), userID.(string))
+	if err != nil {
+		h.log(c).Error("Failed to get dashboard: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get dashboard"})
+		return
+	}
+
+	c.JSON(http.StatusOK, dashboard)
+}
+
 // isValidPeriod проверяем валидность периода
 func isValidPeriod(period string) bool {
 	validPeriods := map[string]bool{
@@ -385,3 +1175,11 @@ func isValidPeriod(period string) bool {
 	}
 	return validPeriods[period]
 }
+
+// recordTransfer сохраняет операцию импорта или экспорта в историю пользователя
+func (h *TaskHandler) recordTransfer(c *gin.Context, userID string, direction models.TransferDirection, format importer.Format, items int, started time.Time, err error) {
+	if h.transfers == nil {
+		return
+	}
+	h.transfers.Record(c.Request.Context(), userID, direction, string(format), items, started, err)
+}
@@ -5,6 +5,8 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -12,8 +14,13 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/jmoloko/taskmange/internal/clock"
 	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/jmoloko/taskmange/internal/domain/repository"
+	domainService "github.com/jmoloko/taskmange/internal/domain/service"
+	"github.com/jmoloko/taskmange/internal/importer"
 	"github.com/jmoloko/taskmange/internal/logger"
+	"github.com/jmoloko/taskmange/internal/middleware"
 	"github.com/jmoloko/taskmange/internal/service"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -35,26 +42,112 @@ func (m *MockTaskService) GetUserTask(ctx context.Context, userID, taskID string
 	return args.Get(0).(models.Task), args.Error(1)
 }
 
+func (m *MockTaskService) UnlockUserTask(ctx context.Context, userID, taskID string) (models.Task, error) {
+	args := m.Called(ctx, userID, taskID)
+	return args.Get(0).(models.Task), args.Error(1)
+}
+
 func (m *MockTaskService) GetUserTasks(ctx context.Context, userID string, filters models.TaskFilters) ([]models.Task, error) {
 	args := m.Called(ctx, userID, filters)
 	return args.Get(0).([]models.Task), args.Error(1)
 }
 
+func (m *MockTaskService) CountUserTasks(ctx context.Context, userID string, filters models.TaskFilters) (int, error) {
+	args := m.Called(ctx, userID, filters)
+	return args.Int(0), args.Error(1)
+}
+
 func (m *MockTaskService) UpdateUserTask(ctx context.Context, userID string, task models.Task) (models.Task, error) {
 	args := m.Called(ctx, userID, task)
 	return args.Get(0).(models.Task), args.Error(1)
 }
 
+func (m *MockTaskService) PatchUserTask(ctx context.Context, userID, taskID string, req models.UpdateTaskRequest) (models.Task, error) {
+	args := m.Called(ctx, userID, taskID, req)
+	return args.Get(0).(models.Task), args.Error(1)
+}
+
+func (m *MockTaskService) CompleteTasks(ctx context.Context, userID string, ids []string) (models.CompleteTasksResult, error) {
+	args := m.Called(ctx, userID, ids)
+	return args.Get(0).(models.CompleteTasksResult), args.Error(1)
+}
+
 func (m *MockTaskService) DeleteUserTask(ctx context.Context, userID, taskID string) error {
 	args := m.Called(ctx, userID, taskID)
 	return args.Error(0)
 }
 
+func (m *MockTaskService) RelateTasks(ctx context.Context, userID, taskID, relatedID string) error {
+	args := m.Called(ctx, userID, taskID, relatedID)
+	return args.Error(0)
+}
+
+func (m *MockTaskService) UnrelateTasks(ctx context.Context, userID, taskID, relatedID string) error {
+	args := m.Called(ctx, userID, taskID, relatedID)
+	return args.Error(0)
+}
+
+func (m *MockTaskService) ExpandRelated(ctx context.Context, userID string, tasks []models.Task) ([]models.Task, error) {
+	args := m.Called(ctx, userID, tasks)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Task), args.Error(1)
+}
+
+func (m *MockTaskService) GetSubtasks(ctx context.Context, userID, taskID string) ([]models.Task, error) {
+	args := m.Called(ctx, userID, taskID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Task), args.Error(1)
+}
+
+func (m *MockTaskService) AssignTask(ctx context.Context, userID, taskID, assigneeID string) (models.Task, error) {
+	args := m.Called(ctx, userID, taskID, assigneeID)
+	return args.Get(0).(models.Task), args.Error(1)
+}
+
+func (m *MockTaskService) UnassignTask(ctx context.Context, userID, taskID string) (models.Task, error) {
+	args := m.Called(ctx, userID, taskID)
+	return args.Get(0).(models.Task), args.Error(1)
+}
+
+func (m *MockTaskService) GetRecurrence(ctx context.Context, userID, recurrenceID string) (models.Recurrence, error) {
+	args := m.Called(ctx, userID, recurrenceID)
+	return args.Get(0).(models.Recurrence), args.Error(1)
+}
+
+func (m *MockTaskService) PauseRecurrence(ctx context.Context, userID, recurrenceID string) (models.Recurrence, error) {
+	args := m.Called(ctx, userID, recurrenceID)
+	return args.Get(0).(models.Recurrence), args.Error(1)
+}
+
+func (m *MockTaskService) ResumeRecurrence(ctx context.Context, userID, recurrenceID string) (models.Recurrence, error) {
+	args := m.Called(ctx, userID, recurrenceID)
+	return args.Get(0).(models.Recurrence), args.Error(1)
+}
+
+func (m *MockTaskService) MaterializeRecurrences(ctx context.Context) error {
+	args := m.Called(ctx)
+	return args.Error(0)
+}
+
 func (m *MockTaskService) ImportTasks(ctx context.Context, userID string, tasks []models.Task) error {
 	args := m.Called(ctx, userID, tasks)
 	return args.Error(0)
 }
 
+func (m *MockTaskService) ImportTaskFile(ctx context.Context, userID string, rows domainService.ImportRowSource, skipInvalid bool) (models.ImportReport, error) {
+	args := m.Called(ctx, userID, rows, skipInvalid)
+	return args.Get(0).(models.ImportReport), args.Error(1)
+}
+
+func (m *MockTaskService) PreviewImport(ctx context.Context, userID string, batch models.ImportBatch) (models.ImportPreview, error) {
+	args := m.Called(ctx, userID, batch)
+	return args.Get(0).(models.ImportPreview), args.Error(1)
+}
+
 func (m *MockTaskService) ExportUserTasks(ctx context.Context, userID string) ([]models.Task, error) {
 	args := m.Called(ctx, userID)
 	return args.Get(0).([]models.Task), args.Error(1)
@@ -70,11 +163,26 @@ func (m *MockTaskService) Delete(ctx context.Context, taskID, userID string) err
 	return args.Error(0)
 }
 
+func (m *MockTaskService) PurgeUserTasks(ctx context.Context, userID string) (int, error) {
+	args := m.Called(ctx, userID)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockTaskService) DeleteTasks(ctx context.Context, userID string, filter models.TaskDeleteFilter, progress func(models.BulkDeleteProgress) error) (models.BulkDeleteProgress, error) {
+	args := m.Called(ctx, userID, filter, progress)
+	return args.Get(0).(models.BulkDeleteProgress), args.Error(1)
+}
+
 func (m *MockTaskService) GetActiveUsers(ctx context.Context) ([]string, error) {
 	args := m.Called(ctx)
 	return args.Get(0).([]string), args.Error(1)
 }
 
+func (m *MockTaskService) GetUsersWithTasksUpdatedSince(ctx context.Context, since time.Time) ([]string, error) {
+	args := m.Called(ctx, since)
+	return args.Get(0).([]string), args.Error(1)
+}
+
 func (m *MockTaskService) GetAll(ctx context.Context, userID string, filters models.TaskFilters) ([]models.Task, error) {
 	args := m.Called(ctx, userID, filters)
 	return args.Get(0).([]models.Task), args.Error(1)
@@ -85,6 +193,21 @@ func (m *MockTaskService) GetAnalytics(ctx context.Context, userID string, perio
 	return args.Get(0).(models.Analytics), args.Error(1)
 }
 
+func (m *MockTaskService) GetUserAnalyticsRange(ctx context.Context, userID string, from, to time.Time) (models.Analytics, error) {
+	args := m.Called(ctx, userID, from, to)
+	return args.Get(0).(models.Analytics), args.Error(1)
+}
+
+func (m *MockTaskService) GetProjectAnalytics(ctx context.Context, userID, projectID, period string) (models.Analytics, error) {
+	args := m.Called(ctx, userID, projectID, period)
+	return args.Get(0).(models.Analytics), args.Error(1)
+}
+
+func (m *MockTaskService) GetDashboard(ctx context.Context, userID string) (models.Dashboard, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(models.Dashboard), args.Error(1)
+}
+
 // MockLogger реализует интерфейс Logger для тестов
 type MockLogger struct {
 	mock.Mock
@@ -120,13 +243,16 @@ func (m *MockLogger) Close() error {
 	return args.Error(0)
 }
 
+// testImportLimits ограничения импорта в тестах обработчика
+var testImportLimits = importer.Limits{MaxBytes: 4 << 10, MaxRows: 3}
+
 func setupTest() (*gin.Engine, *MockTaskService, *MockLogger) {
 	gin.SetMode(gin.TestMode)
 	engine := gin.New()
 
 	mockService := new(MockTaskService)
 	mockLogger := new(MockLogger)
-	handler := NewTaskHandler(mockService, mockLogger)
+	handler := NewTaskHandler(mockService, nil, nil, testImportLimits, mockLogger)
 
 	// Add middleware to set user_id in context
 	engine.Use(func(c *gin.Context) {
@@ -152,7 +278,7 @@ func setupTest() (*gin.Engine, *MockTaskService, *MockLogger) {
 func TestCreateTask(t *testing.T) {
 	mockService := new(MockTaskService)
 	mockLogger := new(MockLogger)
-	handler := NewTaskHandler(mockService, mockLogger)
+	handler := NewTaskHandler(mockService, nil, nil, testImportLimits, mockLogger)
 
 	dueDate := time.Now().Add(24 * time.Hour)
 	dueDateStr := dueDate.Format(time.RFC3339Nano)
@@ -254,6 +380,26 @@ func TestCreateTask(t *testing.T) {
 				"error": "Failed to create task",
 			},
 		},
+		{
+			name: "Constraint_Violation",
+			requestBody: models.Task{
+				Title:    "Orphan Task",
+				Priority: models.PriorityLow,
+				Status:   "pending",
+			},
+			setupMocks: func() {
+				mockService.On("CreateTask", mock.Anything, "test_user", mock.MatchedBy(func(task models.Task) bool {
+					return task.Title == "Orphan Task"
+				})).Return(models.Task{}, fmt.Errorf("failed to create task: %w", &repository.ConstraintError{
+					Err: repository.ErrInvalidReference, Constraint: "tasks_user_id_fkey", Field: "user_id",
+				}))
+			},
+			checkStatus: http.StatusBadRequest,
+			checkBody: gin.H{
+				"error": "user_id: referenced record does not exist",
+				"field": "user_id",
+			},
+		},
 		{
 			name: "Unauthorized",
 			requestBody: models.Task{
@@ -338,7 +484,7 @@ func TestCreateTask(t *testing.T) {
 func TestGetTask(t *testing.T) {
 	mockService := new(MockTaskService)
 	mockLogger := new(MockLogger)
-	handler := NewTaskHandler(mockService, mockLogger)
+	handler := NewTaskHandler(mockService, nil, nil, testImportLimits, mockLogger)
 
 	tests := []struct {
 		name        string
@@ -478,7 +624,9 @@ func TestGetTask(t *testing.T) {
 func TestGetTasks(t *testing.T) {
 	mockService := new(MockTaskService)
 	mockLogger := new(MockLogger)
-	handler := NewTaskHandler(mockService, mockLogger)
+	handler := NewTaskHandler(mockService, nil, nil, testImportLimits, mockLogger)
+	// в UTC еще 13 июня, в Москве уже 14-е
+	handler.clock = clock.NewFake(time.Date(2024, 6, 13, 22, 30, 0, 0, time.UTC))
 
 	dueDate := time.Now().Add(24 * time.Hour)
 	tasks := []models.Task{
@@ -508,6 +656,8 @@ func TestGetTasks(t *testing.T) {
 		setupMocks   func()
 		checkStatus  int
 		checkBody    interface{}
+		checkTotal   string
+		checkNext    string
 		isAuthorized bool
 	}{
 		{
@@ -521,6 +671,69 @@ func TestGetTasks(t *testing.T) {
 			},
 			checkStatus: http.StatusOK,
 			checkBody:   tasks,
+			checkTotal:  "2",
+		},
+		{
+			name: "Get_Tasks_Page",
+			queryParams: map[string]string{
+				"status": "pending",
+				"limit":  "1",
+				"offset": "1",
+			},
+			isAuthorized: true,
+			setupMocks: func() {
+				filters := models.TaskFilters{
+					Status: models.Status("pending"),
+					UserID: "test_user",
+					Limit:  1,
+					Offset: 1,
+				}
+				mockService.On("GetUserTasks", mock.Anything, "test_user", filters).Return([]models.Task{tasks[1]}, nil)
+				mockService.On("CountUserTasks", mock.Anything, "test_user", filters).Return(42, nil)
+			},
+			checkStatus: http.StatusOK,
+			checkBody:   []models.Task{tasks[1]},
+			checkTotal:  "42",
+		},
+		{
+			name: "Get_Overdue_Tasks",
+			queryParams: map[string]string{
+				"status": "overdue",
+			},
+			isAuthorized: true,
+			setupMocks: func() {
+				mockService.On("GetUserTasks", mock.Anything, "test_user", models.TaskFilters{
+					UserID:  "test_user",
+					Overdue: true,
+				}).Return([]models.Task{tasks[0]}, nil)
+			},
+			checkStatus: http.StatusOK,
+			checkBody:   []models.Task{tasks[0]},
+			checkTotal:  "1",
+		},
+		{
+			name: "Get_Tasks_With_Invalid_Limit",
+			queryParams: map[string]string{
+				"limit": "501",
+			},
+			isAuthorized: true,
+			setupMocks:   func() {},
+			checkStatus:  http.StatusBadRequest,
+			checkBody: gin.H{
+				"error": "Invalid limit, must be between 1 and 500",
+			},
+		},
+		{
+			name: "Get_Tasks_With_Invalid_Offset",
+			queryParams: map[string]string{
+				"offset": "-1",
+			},
+			isAuthorized: true,
+			setupMocks:   func() {},
+			checkStatus:  http.StatusBadRequest,
+			checkBody: gin.H{
+				"error": "Invalid offset, must be a non-negative integer",
+			},
 		},
 		{
 			name: "Get_Tasks_With_Filters",
@@ -539,6 +752,202 @@ func TestGetTasks(t *testing.T) {
 			checkStatus: http.StatusOK,
 			checkBody:   []models.Task{tasks[0]},
 		},
+		{
+			name: "Get_Tasks_Smart_Sort",
+			queryParams: map[string]string{
+				"sort": "smart",
+			},
+			isAuthorized: true,
+			setupMocks: func() {
+				mockService.On("GetUserTasks", mock.Anything, "test_user", models.TaskFilters{
+					UserID: "test_user",
+					Sort:   models.SortSmart,
+				}).Return(tasks, nil)
+			},
+			checkStatus: http.StatusOK,
+			checkBody:   tasks,
+		},
+		{
+			name: "Get_Tasks_With_Invalid_Sort",
+			queryParams: map[string]string{
+				"sort": "random",
+			},
+			isAuthorized: true,
+			setupMocks:   func() {},
+			checkStatus:  http.StatusBadRequest,
+			checkBody: gin.H{
+				"error": "Invalid sort, supported values: smart, due",
+			},
+		},
+		{
+			name: "Get_Tasks_With_Query",
+			queryParams: map[string]string{
+				"q":        `status:done #work "quarterly report"`,
+				"priority": "low",
+			},
+			isAuthorized: true,
+			setupMocks: func() {
+				filters := models.TaskFilters{
+					UserID:   "test_user",
+					Status:   models.StatusDone,
+					Priority: models.PriorityLow,
+					Tag:      "work",
+					Terms:    []string{"quarterly report"},
+				}
+				mockService.On("GetUserTasks", mock.Anything, "test_user", filters).Return(tasks, nil)
+			},
+			checkStatus: http.StatusOK,
+			checkBody:   tasks,
+		},
+		{
+			name: "Get_Tasks_With_Query_Due_Today",
+			queryParams: map[string]string{
+				"q":  "due:today",
+				"tz": "Europe/Moscow",
+			},
+			isAuthorized: true,
+			setupMocks: func() {
+				mockService.On("GetUserTasks", mock.Anything, "test_user", mock.MatchedBy(func(f models.TaskFilters) bool {
+					return f.DueFrom != nil && f.DueFrom.Equal(time.Date(2024, 6, 13, 21, 0, 0, 0, time.UTC)) &&
+						f.DueBefore != nil && f.DueBefore.Equal(time.Date(2024, 6, 14, 21, 0, 0, 0, time.UTC))
+				})).Return(tasks, nil)
+			},
+			checkStatus: http.StatusOK,
+			checkBody:   tasks,
+		},
+		{
+			name: "Get_Tasks_With_Invalid_Query",
+			queryParams: map[string]string{
+				"q": "report stat:done",
+			},
+			isAuthorized: true,
+			setupMocks:   func() {},
+			checkStatus:  http.StatusBadRequest,
+			checkBody: gin.H{
+				"error":    `Invalid query at column 8: unknown field "stat", supported: status, priority, tag, project, is, due; put text in double quotes to search for it as is`,
+				"position": float64(8),
+			},
+		},
+		{
+			name: "Get_Tasks_Assigned_To_Me",
+			queryParams: map[string]string{
+				"assignee": "me",
+			},
+			isAuthorized: true,
+			setupMocks: func() {
+				mockService.On("GetUserTasks", mock.Anything, "test_user", models.TaskFilters{UserID: "test_user", Assigned: true}).Return(tasks, nil)
+			},
+			checkStatus: http.StatusOK,
+			checkBody:   tasks,
+		},
+		{
+			name: "Get_Tasks_With_Invalid_Assignee",
+			queryParams: map[string]string{
+				"assignee": "user2",
+			},
+			isAuthorized: true,
+			setupMocks:   func() {},
+			checkStatus:  http.StatusBadRequest,
+			checkBody: gin.H{
+				"error": "Invalid assignee, supported values: me",
+			},
+		},
+		{
+			name: "Get_Tasks_After_Cursor",
+			queryParams: map[string]string{
+				"after_id":  "task0",
+				"after_due": "2024-05-01T12:00:00.5Z",
+				"limit":     "1",
+			},
+			isAuthorized: true,
+			setupMocks: func() {
+				filters := models.TaskFilters{
+					UserID: "test_user",
+					Sort:   models.SortDue,
+					Limit:  1,
+					After:  &models.TaskCursor{DueDate: time.Date(2024, 5, 1, 12, 0, 0, 500000000, time.UTC), ID: "task0"},
+				}
+				mockService.On("GetUserTasks", mock.Anything, "test_user", filters).Return([]models.Task{tasks[0]}, nil)
+				mockService.On("CountUserTasks", mock.Anything, "test_user", filters).Return(2, nil)
+			},
+			checkStatus: http.StatusOK,
+			checkBody:   []models.Task{tasks[0]},
+			checkTotal:  "2",
+			checkNext:   "task1",
+		},
+		{
+			name: "Get_Tasks_With_Encoded_Cursor",
+			queryParams: map[string]string{
+				"cursor": models.TaskCursor{DueDate: time.Date(2024, 5, 1, 12, 0, 0, 500000000, time.UTC), ID: "task0"}.Encode(),
+				"limit":  "2",
+			},
+			isAuthorized: true,
+			setupMocks: func() {
+				filters := models.TaskFilters{
+					UserID: "test_user",
+					Sort:   models.SortDue,
+					Limit:  2,
+					After:  &models.TaskCursor{DueDate: time.Date(2024, 5, 1, 12, 0, 0, 500000000, time.UTC), ID: "task0"},
+				}
+				mockService.On("GetUserTasks", mock.Anything, "test_user", filters).Return(tasks, nil)
+				mockService.On("CountUserTasks", mock.Anything, "test_user", filters).Return(3, nil)
+			},
+			checkStatus: http.StatusOK,
+			checkBody:   tasks,
+			checkTotal:  "3",
+			checkNext:   "task2",
+		},
+		{
+			name: "Get_Tasks_With_Invalid_Cursor",
+			queryParams: map[string]string{
+				"cursor": "not a cursor",
+			},
+			isAuthorized: true,
+			setupMocks:   func() {},
+			checkStatus:  http.StatusBadRequest,
+			checkBody: gin.H{
+				"error": "Invalid cursor",
+			},
+		},
+		{
+			name: "Get_Tasks_Cursor_With_After_Id",
+			queryParams: map[string]string{
+				"cursor":   models.TaskCursor{DueDate: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC), ID: "task0"}.Encode(),
+				"after_id": "task1",
+			},
+			isAuthorized: true,
+			setupMocks:   func() {},
+			checkStatus:  http.StatusBadRequest,
+			checkBody: gin.H{
+				"error": "cursor can not be combined with after_id and after_due",
+			},
+		},
+		{
+			name: "Get_Tasks_With_Incomplete_Cursor",
+			queryParams: map[string]string{
+				"after_id": "task1",
+			},
+			isAuthorized: true,
+			setupMocks:   func() {},
+			checkStatus:  http.StatusBadRequest,
+			checkBody: gin.H{
+				"error": "after_id and after_due (RFC3339) must be passed together",
+			},
+		},
+		{
+			name: "Get_Tasks_Cursor_With_Smart_Sort",
+			queryParams: map[string]string{
+				"after_id":  "task1",
+				"after_due": "2024-05-01T12:00:00Z",
+				"sort":      "smart",
+			},
+			isAuthorized: true,
+			setupMocks:   func() {},
+			checkStatus:  http.StatusBadRequest,
+			checkBody: gin.H{
+				"error": "Cursor pagination supports only sort=due without offset",
+			},
+		},
 		{
 			name: "Get_Tasks_With_Invalid_Due_Date",
 			queryParams: map[string]string{
@@ -613,6 +1022,10 @@ func TestGetTasks(t *testing.T) {
 			router.ServeHTTP(w, req)
 
 			assert.Equal(t, tt.checkStatus, w.Code)
+			if tt.checkTotal != "" {
+				assert.Equal(t, tt.checkTotal, w.Header().Get("X-Total-Count"))
+			}
+			assert.Equal(t, tt.checkNext, w.Header().Get("X-Next-After-Id"))
 
 			var response interface{}
 			err := json.Unmarshal(w.Body.Bytes(), &response)
@@ -645,6 +1058,60 @@ func TestGetTasks(t *testing.T) {
 	}
 }
 
+func TestGetTasks_Page(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockTaskService)
+	handler := NewTaskHandler(mockService, nil, nil, testImportLimits, new(MockLogger))
+
+	dueDate := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	tasks := []models.Task{
+		{ID: "task1", Title: "Task 1", UserID: "test_user", DueDate: dueDate},
+		{ID: "task2", Title: "Task 2", UserID: "test_user", DueDate: dueDate.Add(time.Hour)},
+	}
+	first := models.TaskFilters{UserID: "test_user", Sort: models.SortDue, Limit: 2}
+	second := first
+	second.After = &models.TaskCursor{DueDate: tasks[1].DueDate, ID: "task2"}
+	mockService.On("GetUserTasks", mock.Anything, "test_user", first).Return(tasks, nil)
+	mockService.On("CountUserTasks", mock.Anything, "test_user", first).Return(3, nil)
+	mockService.On("GetUserTasks", mock.Anything, "test_user", second).Return([]models.Task(nil), nil)
+	mockService.On("CountUserTasks", mock.Anything, "test_user", second).Return(3, nil)
+
+	router := gin.New()
+	router.Use(middleware.APIVersionMiddleware(middleware.APIVersion1))
+	router.Use(func(c *gin.Context) {
+		c.Set("user_id", "test_user")
+		c.Next()
+	})
+	router.GET("/tasks", handler.GetTasks)
+
+	serve := func(query string) models.TaskPage {
+		req := httptest.NewRequest(http.MethodGet, "/tasks?"+query, nil)
+		req.Header.Set(middleware.APIVersionHeader, "3")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var page models.TaskPage
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+		return page
+	}
+
+	page := serve("sort=due&limit=2")
+	assert.Len(t, page.Tasks, 2)
+	assert.Equal(t, 3, page.Total)
+	require.NotEmpty(t, page.NextCursor)
+
+	cursor, err := models.ParseTaskCursor(page.NextCursor)
+	require.NoError(t, err)
+	assert.Equal(t, *second.After, cursor)
+
+	// у последней страницы курсора нет, а задачи — пустой массив, а не null
+	page = serve("limit=2&cursor=" + page.NextCursor)
+	assert.NotNil(t, page.Tasks)
+	assert.Empty(t, page.Tasks)
+	assert.Empty(t, page.NextCursor)
+}
+
 func TestUpdateTask(t *testing.T) {
 	tests := []struct {
 		name       string
@@ -784,7 +1251,7 @@ func TestUpdateTask(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			mockService := new(MockTaskService)
 			mockLogger := new(MockLogger)
-			handler := NewTaskHandler(mockService, mockLogger)
+			handler := NewTaskHandler(mockService, nil, nil, testImportLimits, mockLogger)
 
 			gin.SetMode(gin.TestMode)
 			router := gin.New()
@@ -841,18 +1308,286 @@ func TestUpdateTask(t *testing.T) {
 	}
 }
 
-func TestDeleteTask(t *testing.T) {
+func TestPatchTask(t *testing.T) {
+	empty := ""
 	tests := []struct {
 		name       string
-		taskID     string
-		userID     string
+		body       string
 		setupMock  func(s *MockTaskService, l *MockLogger)
-		checkBody  gin.H
 		wantStatus int
+		wantBody   string
 	}{
 		{
-			name:   "Success",
-			taskID: "test_task",
+			name: "Clear_Description",
+			body: `{"description":""}`,
+			setupMock: func(s *MockTaskService, l *MockLogger) {
+				s.On("PatchUserTask", mock.Anything, "test_user", "test_task", models.UpdateTaskRequest{Description: &empty}).
+					Return(models.Task{ID: "test_task", Title: "Task"}, nil)
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   `"description":""`,
+		},
+		{
+			name: "Invalid_Task_Data",
+			body: `{"title":""}`,
+			setupMock: func(s *MockTaskService, l *MockLogger) {
+				s.On("PatchUserTask", mock.Anything, "test_user", "test_task", models.UpdateTaskRequest{Title: &empty}).
+					Return(models.Task{}, service.ErrInvalidTaskData)
+			},
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `{"error":"Invalid task data"}`,
+		},
+		{
+			name: "Not_Found",
+			body: `{"description":""}`,
+			setupMock: func(s *MockTaskService, l *MockLogger) {
+				s.On("PatchUserTask", mock.Anything, "test_user", "test_task", models.UpdateTaskRequest{Description: &empty}).
+					Return(models.Task{}, service.ErrTaskNotFound)
+			},
+			wantStatus: http.StatusNotFound,
+			wantBody:   `{"error":"Task not found"}`,
+		},
+		{
+			name: "Subtask_Cycle",
+			body: `{"parent_id":"child_task"}`,
+			setupMock: func(s *MockTaskService, l *MockLogger) {
+				parent := "child_task"
+				s.On("PatchUserTask", mock.Anything, "test_user", "test_task", models.UpdateTaskRequest{ParentID: &parent}).
+					Return(models.Task{}, service.ErrSubtaskCycle)
+			},
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `{"error":"Task cannot be a subtask of itself or of its subtasks"}`,
+		},
+		{
+			name: "Open_Subtasks",
+			body: `{"status":"done"}`,
+			setupMock: func(s *MockTaskService, l *MockLogger) {
+				done := models.StatusDone
+				s.On("PatchUserTask", mock.Anything, "test_user", "test_task", models.UpdateTaskRequest{Status: &done}).
+					Return(models.Task{}, service.ErrOpenSubtasks)
+			},
+			wantStatus: http.StatusConflict,
+			wantBody:   `{"error":"Task has open subtasks"}`,
+		},
+		{
+			name: "Invalid_Body",
+			body: `{"due_date":"tomorrow"}`,
+			setupMock: func(s *MockTaskService, l *MockLogger) {
+				l.On("Error", "Failed to parse task patch: %v", mock.Anything).Return()
+			},
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `{"error":"Invalid request body"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockTaskService)
+			mockLogger := new(MockLogger)
+			handler := NewTaskHandler(mockService, nil, nil, testImportLimits, mockLogger)
+			tt.setupMock(mockService, mockLogger)
+
+			router := gin.New()
+			router.Use(func(c *gin.Context) {
+				c.Set("user_id", "test_user")
+				c.Next()
+			})
+			router.PATCH("/tasks/:id", handler.PatchTask)
+
+			req := httptest.NewRequest(http.MethodPatch, "/tasks/test_task", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tt.wantBody)
+			mockService.AssertExpectations(t)
+			mockLogger.AssertExpectations(t)
+		})
+	}
+}
+
+func TestCompleteTasks(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		setupMock  func(s *MockTaskService, l *MockLogger)
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name: "Success",
+			body: `{"ids":["a","b"]}`,
+			setupMock: func(s *MockTaskService, l *MockLogger) {
+				s.On("CompleteTasks", mock.Anything, "test_user", []string{"a", "b"}).Return(models.CompleteTasksResult{
+					Completed: []models.Task{{ID: "a", Status: models.StatusDone}},
+					Skipped:   []string{"b"},
+				}, nil)
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   `"skipped":["b"]`,
+		},
+		{
+			name: "Task_Not_Found",
+			body: `{"ids":["a","foreign"]}`,
+			setupMock: func(s *MockTaskService, l *MockLogger) {
+				s.On("CompleteTasks", mock.Anything, "test_user", []string{"a", "foreign"}).
+					Return(models.CompleteTasksResult{}, service.ErrTaskNotFound)
+			},
+			wantStatus: http.StatusNotFound,
+			wantBody:   `{"error":"Task not found"}`,
+		},
+		{
+			name: "Empty_List",
+			body: `{"ids":[]}`,
+			setupMock: func(s *MockTaskService, l *MockLogger) {
+				s.On("CompleteTasks", mock.Anything, "test_user", []string{}).
+					Return(models.CompleteTasksResult{}, service.ErrInvalidTaskData)
+			},
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `{"error":"ids must contain 1-500 non-empty task IDs"}`,
+		},
+		{
+			name: "Invalid_Body",
+			body: `{"ids":"a"}`,
+			setupMock: func(s *MockTaskService, l *MockLogger) {
+				l.On("Error", "Failed to parse complete request: %v", mock.Anything).Return()
+			},
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `{"error":"Invalid request body"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockTaskService)
+			mockLogger := new(MockLogger)
+			handler := NewTaskHandler(mockService, nil, nil, testImportLimits, mockLogger)
+			tt.setupMock(mockService, mockLogger)
+
+			router := gin.New()
+			router.Use(func(c *gin.Context) {
+				c.Set("user_id", "test_user")
+				c.Next()
+			})
+			router.POST("/tasks/complete", handler.CompleteTasks)
+
+			req := httptest.NewRequest(http.MethodPost, "/tasks/complete", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tt.wantBody)
+			mockService.AssertExpectations(t)
+			mockLogger.AssertExpectations(t)
+		})
+	}
+}
+
+func TestDeleteTasks(t *testing.T) {
+	before := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	doneBefore := models.TaskDeleteFilter{Status: models.StatusDone, CompletedBefore: &before}
+
+	tests := []struct {
+		name       string
+		query      string
+		accept     string
+		setupMock  func(s *MockTaskService, l *MockLogger)
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:  "Success",
+			query: "?status=done&completed_before=2023-01-01",
+			setupMock: func(s *MockTaskService, l *MockLogger) {
+				s.On("DeleteTasks", mock.Anything, "test_user", doneBefore, mock.Anything).
+					Return(models.BulkDeleteProgress{Deleted: 700, Batches: 2, Done: true}, nil)
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   `{"deleted":700,"batches":2,"done":true}`,
+		},
+		{
+			name:   "Stream_Progress",
+			query:  "?status=done&completed_before=2023-01-01T00:00:00Z",
+			accept: "application/x-ndjson",
+			setupMock: func(s *MockTaskService, l *MockLogger) {
+				s.On("DeleteTasks", mock.Anything, "test_user", doneBefore, mock.Anything).
+					Run(func(args mock.Arguments) {
+						progress := args.Get(3).(func(models.BulkDeleteProgress) error)
+						progress(models.BulkDeleteProgress{Deleted: 500, Batches: 1})
+					}).
+					Return(models.BulkDeleteProgress{}, errors.New("connection reset"))
+				l.On("Error", "Failed to delete tasks: %v", mock.Anything).Return()
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   "{\"deleted\":500,\"batches\":1,\"done\":false}\n{\"deleted\":0,\"batches\":0,\"done\":false,\"error\":\"Failed to delete tasks\"}\n",
+		},
+		{
+			name:       "No_Filter",
+			query:      "",
+			setupMock:  func(s *MockTaskService, l *MockLogger) {},
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `{"error":"At least one filter is required"}`,
+		},
+		{
+			name:       "Invalid_Status",
+			query:      "?status=archived",
+			setupMock:  func(s *MockTaskService, l *MockLogger) {},
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `{"error":"Invalid status"}`,
+		},
+		{
+			name:       "Invalid_Date",
+			query:      "?status=done&completed_before=yesterday",
+			setupMock:  func(s *MockTaskService, l *MockLogger) {},
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `{"error":"Invalid completed_before, expected RFC3339 or YYYY-MM-DD"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockTaskService)
+			mockLogger := new(MockLogger)
+			handler := NewTaskHandler(mockService, nil, nil, testImportLimits, mockLogger)
+			tt.setupMock(mockService, mockLogger)
+
+			router := gin.New()
+			router.Use(func(c *gin.Context) {
+				c.Set("user_id", "test_user")
+				c.Next()
+			})
+			router.DELETE("/tasks", handler.DeleteTasks)
+
+			req := httptest.NewRequest(http.MethodDelete, "/tasks"+tt.query, nil)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			assert.Equal(t, tt.wantBody, w.Body.String())
+			mockService.AssertExpectations(t)
+			mockLogger.AssertExpectations(t)
+		})
+	}
+}
+
+func TestDeleteTask(t *testing.T) {
+	tests := []struct {
+		name       string
+		taskID     string
+		userID     string
+		apiVersion string
+		setupMock  func(s *MockTaskService, l *MockLogger)
+		checkBody  gin.H
+		wantStatus int
+	}{
+		{
+			name:   "Success",
+			taskID: "test_task",
 			userID: "test_user",
 			setupMock: func(s *MockTaskService, l *MockLogger) {
 				s.On("DeleteUserTask", mock.Anything, "test_user", "test_task").Return(nil)
@@ -862,6 +1597,42 @@ func TestDeleteTask(t *testing.T) {
 			},
 			wantStatus: http.StatusOK,
 		},
+		{
+			name:       "Success_API_V1",
+			taskID:     "test_task",
+			userID:     "test_user",
+			apiVersion: "1",
+			setupMock: func(s *MockTaskService, l *MockLogger) {
+				s.On("DeleteUserTask", mock.Anything, "test_user", "test_task").Return(nil)
+			},
+			checkBody: gin.H{
+				"message": "Task deleted successfully",
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "Success_API_V2",
+			taskID:     "test_task",
+			userID:     "test_user",
+			apiVersion: "2",
+			setupMock: func(s *MockTaskService, l *MockLogger) {
+				s.On("DeleteUserTask", mock.Anything, "test_user", "test_task").Return(nil)
+			},
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			name:       "Not_Found_API_V2",
+			taskID:     "nonexistent_task",
+			userID:     "test_user",
+			apiVersion: "2",
+			setupMock: func(s *MockTaskService, l *MockLogger) {
+				s.On("DeleteUserTask", mock.Anything, "test_user", "nonexistent_task").Return(service.ErrTaskNotFound)
+			},
+			checkBody: gin.H{
+				"error": "Task not found",
+			},
+			wantStatus: http.StatusNotFound,
+		},
 		{
 			name:   "Task_Not_Found",
 			taskID: "nonexistent_task",
@@ -915,7 +1686,7 @@ func TestDeleteTask(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			mockService := new(MockTaskService)
 			mockLogger := new(MockLogger)
-			handler := NewTaskHandler(mockService, mockLogger)
+			handler := NewTaskHandler(mockService, nil, nil, testImportLimits, mockLogger)
 
 			gin.SetMode(gin.TestMode)
 			router := gin.New()
@@ -925,6 +1696,7 @@ func TestDeleteTask(t *testing.T) {
 				}
 				c.Next()
 			})
+			router.Use(middleware.APIVersionMiddleware(middleware.APIVersion1))
 			router.DELETE("/tasks/:id", handler.DeleteTask)
 
 			tt.setupMock(mockService, mockLogger)
@@ -933,11 +1705,19 @@ func TestDeleteTask(t *testing.T) {
 			if tt.userID != "" {
 				req.Header.Set("X-User-ID", tt.userID)
 			}
+			if tt.apiVersion != "" {
+				req.Header.Set(middleware.APIVersionHeader, tt.apiVersion)
+			}
 
 			w := httptest.NewRecorder()
 			router.ServeHTTP(w, req)
 
 			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantStatus == http.StatusNoContent {
+				assert.Empty(t, w.Body.Bytes())
+				mockService.AssertExpectations(t)
+				return
+			}
 
 			var got gin.H
 			err := json.Unmarshal(w.Body.Bytes(), &got)
@@ -950,6 +1730,287 @@ func TestDeleteTask(t *testing.T) {
 	}
 }
 
+func TestImportTasks_File(t *testing.T) {
+	upload := func(filename, content string) (*bytes.Buffer, string) {
+		body := new(bytes.Buffer)
+		writer := multipart.NewWriter(body)
+		writer.WriteField("comment", "weekly export")
+		part, err := writer.CreateFormFile("file", filename)
+		require.NoError(t, err)
+		part.Write([]byte(content))
+		require.NoError(t, writer.Close())
+		return body, writer.FormDataContentType()
+	}
+	// читает строки так же, как сервис, чтобы проверить, что до него дошел разобранный файл
+	drain := func(titles *[]string) func(mock.Arguments) {
+		return func(args mock.Arguments) {
+			rows := args.Get(2).(domainService.ImportRowSource)
+			for {
+				row, _, err := rows.Next()
+				if err != nil {
+					return
+				}
+				*titles = append(*titles, row.Task.Title)
+			}
+		}
+	}
+
+	t.Run("CSV", func(t *testing.T) {
+		router, mockService, _ := setupTest()
+		var titles []string
+		report := models.ImportReport{Total: 2, Imported: 2, Errors: []models.ImportRowError{}}
+		mockService.On("ImportTaskFile", mock.Anything, "test_user", mock.Anything, true).
+			Run(drain(&titles)).Return(report, nil).Once()
+
+		body, contentType := upload("tasks.csv", "title,priority\nReport,high\nCall,low\n")
+		req := httptest.NewRequest(http.MethodPost, "/tasks/import?skip_invalid=true", body)
+		req.Header.Set("Content-Type", contentType)
+		req.Header.Set("X-User-ID", "test_user")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, []string{"Report", "Call"}, titles)
+		var got models.ImportReport
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+		assert.Equal(t, report, got)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Invalid_Rows", func(t *testing.T) {
+		router, mockService, _ := setupTest()
+		report := models.ImportReport{Total: 1, Invalid: 1, Errors: []models.ImportRowError{{Row: 1, Field: "title", Message: "title is required"}}}
+		mockService.On("ImportTaskFile", mock.Anything, "test_user", mock.Anything, false).
+			Return(report, service.ErrImportInvalidRows).Once()
+
+		body, contentType := upload("tasks.json", `[{"title":""}]`)
+		req := httptest.NewRequest(http.MethodPost, "/tasks/import", body)
+		req.Header.Set("Content-Type", contentType)
+		req.Header.Set("X-User-ID", "test_user")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+		var got models.ImportReport
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+		assert.Equal(t, report, got)
+	})
+
+	t.Run("Unsupported_Format", func(t *testing.T) {
+		router, mockService, _ := setupTest()
+
+		body, contentType := upload("tasks.txt", "Report")
+		req := httptest.NewRequest(http.MethodPost, "/tasks/import", body)
+		req.Header.Set("Content-Type", contentType)
+		req.Header.Set("X-User-ID", "test_user")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+		mockService.AssertNotCalled(t, "ImportTaskFile", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Too_Large", func(t *testing.T) {
+		router, mockService, _ := setupTest()
+		var readErr error
+		mockService.On("ImportTaskFile", mock.Anything, "test_user", mock.Anything, false).
+			Run(func(args mock.Arguments) {
+				rows := args.Get(2).(domainService.ImportRowSource)
+				for readErr == nil {
+					_, _, readErr = rows.Next()
+				}
+			}).Return(models.ImportReport{}, &http.MaxBytesError{Limit: testImportLimits.MaxBytes}).Maybe()
+
+		body, contentType := upload("tasks.csv", "title\n"+strings.Repeat("a", int(testImportLimits.MaxBytes))+"\n")
+		req := httptest.NewRequest(http.MethodPost, "/tasks/import", body)
+		req.Header.Set("Content-Type", contentType)
+		req.Header.Set("X-User-ID", "test_user")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+		if readErr != nil {
+			var tooLarge *http.MaxBytesError
+			assert.ErrorAs(t, readErr, &tooLarge)
+		}
+	})
+
+	t.Run("Too_Many_Rows", func(t *testing.T) {
+		router, mockService, _ := setupTest()
+		var readErr error
+		mockService.On("ImportTaskFile", mock.Anything, "test_user", mock.Anything, false).
+			Run(func(args mock.Arguments) {
+				rows := args.Get(2).(domainService.ImportRowSource)
+				for readErr == nil {
+					_, _, readErr = rows.Next()
+				}
+			}).Return(models.ImportReport{}, importer.ErrTooManyRows).Once()
+
+		body, contentType := upload("tasks.csv", "title\nA\nB\nC\nD\n")
+		req := httptest.NewRequest(http.MethodPost, "/tasks/import", body)
+		req.Header.Set("Content-Type", contentType)
+		req.Header.Set("X-User-ID", "test_user")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+		assert.ErrorIs(t, readErr, importer.ErrTooManyRows)
+		assert.JSONEq(t, `{"error":"Import is limited to 3 rows"}`, w.Body.String())
+	})
+
+	t.Run("JSON_Too_Many_Rows", func(t *testing.T) {
+		router, mockService, _ := setupTest()
+
+		req := httptest.NewRequest(http.MethodPost, "/tasks/import", strings.NewReader(`[{"title":"A"},{"title":"B"},{"title":"C"},{"title":"D"}]`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-User-ID", "test_user")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+		mockService.AssertNotCalled(t, "ImportTasks", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestExportTasks_Redact(t *testing.T) {
+	router, mockService, _ := setupTest()
+	tasks := []models.Task{{ID: "1", Title: "Call anna@example.com", Description: "Secret"}}
+	mockService.On("ExportUserTasks", mock.Anything, "test_user").Return(tasks, nil).Once()
+
+	req := httptest.NewRequest(http.MethodGet, "/tasks/export?redact=descriptions,emails", nil)
+	req.Header.Set("X-User-ID", "test_user")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var got []models.Task
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+	require.Len(t, got, 1)
+	assert.Empty(t, got[0].Description)
+	assert.NotContains(t, got[0].Title, "anna@example.com")
+
+	req = httptest.NewRequest(http.MethodGet, "/tasks/export?redact=attachments", nil)
+	req.Header.Set("X-User-ID", "test_user")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, `{"error":"Unknown redaction profile"}`, w.Body.String())
+	mockService.AssertExpectations(t)
+}
+
+func TestExportTasks_Format(t *testing.T) {
+	router, mockService, _ := setupTest()
+	tasks := []models.Task{{ID: "1", Title: "Report", Status: models.StatusPending}}
+	mockService.On("ExportUserTasks", mock.Anything, "test_user").Return(tasks, nil).Once()
+
+	req := httptest.NewRequest(http.MethodGet, "/tasks/export?format=csv", nil)
+	req.Header.Set("X-User-ID", "test_user")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Regexp(t, `^attachment; filename="tasks-\d{4}-\d{2}-\d{2}\.csv"$`, w.Header().Get("Content-Disposition"))
+	assert.True(t, strings.HasPrefix(w.Body.String(), "id,title,description,status"))
+	assert.Contains(t, w.Body.String(), "1,Report,,pending,")
+
+	req = httptest.NewRequest(http.MethodGet, "/tasks/export?format=pdf", nil)
+	req.Header.Set("X-User-ID", "test_user")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, `{"error":"Invalid format, supported values: json, csv, xlsx"}`, w.Body.String())
+	mockService.AssertExpectations(t)
+}
+
+func TestRelateTask(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		setupMock  func(s *MockTaskService, l *MockLogger)
+		checkBody  gin.H
+		wantStatus int
+	}{
+		{
+			name: "Success",
+			body: `{"task_id":"other_task"}`,
+			setupMock: func(s *MockTaskService, l *MockLogger) {
+				s.On("RelateTasks", mock.Anything, "test_user", "test_task", "other_task").Return(nil)
+			},
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			name: "Self_Relation",
+			body: `{"task_id":"test_task"}`,
+			setupMock: func(s *MockTaskService, l *MockLogger) {
+				s.On("RelateTasks", mock.Anything, "test_user", "test_task", "test_task").Return(service.ErrSelfRelation)
+			},
+			checkBody: gin.H{
+				"error": "Task cannot be related to itself",
+			},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "Related_Task_Not_Found",
+			body: `{"task_id":"missing_task"}`,
+			setupMock: func(s *MockTaskService, l *MockLogger) {
+				s.On("RelateTasks", mock.Anything, "test_user", "test_task", "missing_task").Return(service.ErrTaskNotFound)
+			},
+			checkBody: gin.H{
+				"error": "Task not found",
+			},
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:      "Missing_Task_ID",
+			body:      `{}`,
+			setupMock: func(s *MockTaskService, l *MockLogger) {},
+			checkBody: gin.H{
+				"error": "Invalid request body",
+			},
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockTaskService)
+			mockLogger := new(MockLogger)
+			handler := NewTaskHandler(mockService, nil, nil, testImportLimits, mockLogger)
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.Use(func(c *gin.Context) {
+				c.Set("user_id", "test_user")
+				c.Next()
+			})
+			router.POST("/tasks/:id/related", handler.RelateTask)
+
+			tt.setupMock(mockService, mockLogger)
+
+			req := httptest.NewRequest(http.MethodPost, "/tasks/test_task/related", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+
+			if tt.checkBody != nil {
+				var got gin.H
+				err := json.Unmarshal(w.Body.Bytes(), &got)
+				require.NoError(t, err)
+				require.Equal(t, tt.checkBody, got)
+			}
+
+			mockService.AssertExpectations(t)
+			mockLogger.AssertExpectations(t)
+		})
+	}
+}
+
 func TestGetAnalytics(t *testing.T) {
 	tests := []struct {
 		name       string
@@ -1036,7 +2097,7 @@ func TestGetAnalytics(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			mockService := new(MockTaskService)
 			mockLogger := new(MockLogger)
-			handler := NewTaskHandler(mockService, mockLogger)
+			handler := NewTaskHandler(mockService, nil, nil, testImportLimits, mockLogger)
 
 			gin.SetMode(gin.TestMode)
 			router := gin.New()
@@ -1085,3 +2146,88 @@ func TestGetAnalytics(t *testing.T) {
 		})
 	}
 }
+
+func TestGetAnalytics_Range(t *testing.T) {
+	from := time.Date(2024, 6, 3, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 6, 17, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		query      string
+		setupMock  func(s *MockTaskService)
+		checkBody  gin.H
+		wantStatus int
+	}{
+		{
+			name:  "Sprint",
+			query: "?from=2024-06-03&to=2024-06-17T00:00:00Z",
+			setupMock: func(s *MockTaskService) {
+				s.On("GetUserAnalyticsRange", mock.Anything, "test_user", from, to).Return(models.Analytics{
+					OverdueTasks: 2, Period: "custom", From: &from, To: &to,
+				}, nil)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "Missing_To",
+			query:      "?from=2024-06-03",
+			setupMock:  func(s *MockTaskService) {},
+			checkBody:  gin.H{"error": "from and to must be given together"},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "Combined_With_Period",
+			query:      "?period=week&from=2024-06-03&to=2024-06-17",
+			setupMock:  func(s *MockTaskService) {},
+			checkBody:  gin.H{"error": "period can not be combined with from and to"},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "Invalid_From",
+			query:      "?from=june&to=2024-06-17",
+			setupMock:  func(s *MockTaskService) {},
+			checkBody:  gin.H{"error": "Invalid from, expected RFC3339 or YYYY-MM-DD"},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:  "Reversed_Range",
+			query: "?from=2024-06-17&to=2024-06-03",
+			setupMock: func(s *MockTaskService) {
+				s.On("GetUserAnalyticsRange", mock.Anything, "test_user", to, from).Return(models.Analytics{}, service.ErrInvalidAnalyticsRange)
+			},
+			checkBody:  gin.H{"error": "Invalid range, from must be before to and the window at most a year long"},
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockTaskService)
+			handler := NewTaskHandler(mockService, nil, nil, testImportLimits, new(MockLogger))
+			tt.setupMock(mockService)
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.Use(func(c *gin.Context) {
+				c.Set("user_id", "test_user")
+				c.Next()
+			})
+			router.GET("/tasks/analytics", handler.GetAnalytics)
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/tasks/analytics"+tt.query, nil))
+			assert.Equal(t, tt.wantStatus, w.Code)
+
+			var got gin.H
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+			if tt.checkBody != nil {
+				assert.Equal(t, tt.checkBody, got)
+			} else {
+				assert.Equal(t, "custom", got["period"])
+				assert.Equal(t, "2024-06-03T00:00:00Z", got["from"])
+				assert.Equal(t, "2024-06-17T00:00:00Z", got["to"])
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/jmoloko/taskmange/internal/logger"
+	"github.com/jmoloko/taskmange/internal/service"
+)
+
+// TelegramHandler обрабатывает HTTP-запросы привязки чата Telegram
+type TelegramHandler struct {
+	service *service.TelegramService
+	logger  logger.Logger
+}
+
+// NewTelegramHandler создает новый экземпляр TelegramHandler
+func NewTelegramHandler(service *service.TelegramService, logger logger.Logger) *TelegramHandler {
+	return &TelegramHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// GetTelegramLink привязанный чат Telegram
+// @Summary Get the linked Telegram chat
+// @Description Get the Telegram chat the bot sends notifications of the current user to
+// @Tags notifications
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} models.TelegramLink
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 404 {object} map[string]string "Telegram chat is not linked"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /notifications/telegram [get]
+func (h *TelegramHandler) GetTelegramLink(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	link, err := h.service.GetLink(c.Request.Context(), userID.(string))
+	if err != nil {
+		if err == service.ErrTelegramNotLinked {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Telegram chat is not linked"})
+			return
+		}
+		h.logger.Error("Failed to get telegram link: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get telegram link"})
+		return
+	}
+
+	c.JSON(http.StatusOK, link)
+}
+
+// LinkTelegram привязка чата Telegram
+// @Summary Link a Telegram chat
+// @Description Link a Telegram chat for notifications of the current user. Send /start to the bot first, then pass the chat ID; the bot confirms the link with a message. Add telegram to the notification channels to receive due-soon reminders there. daily_digest_at (HH:MM, in the timezone of the notification preferences) enables a daily digest of tasks due today, empty disables it. Linking again replaces the chat
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Param link body models.TelegramLinkRequest true "Telegram chat"
+// @Security BearerAuth
+// @Success 200 {object} models.TelegramLink
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Failure 503 {object} map[string]string "Telegram bot is not configured"
+// @Router /notifications/telegram [put]
+func (h *TelegramHandler) LinkTelegram(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var req models.TelegramLinkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	link, err := h.service.Link(c.Request.Context(), userID.(string), req)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidTelegramLink):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, service.ErrTelegramDisabled):
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Telegram bot is not configured"})
+		default:
+			if constraintError(c, err) {
+				return
+			}
+			h.logger.Error("Failed to link telegram chat: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to link telegram chat"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, link)
+}
+
+// UnlinkTelegram отвязка чата Telegram
+// @Summary Unlink the Telegram chat
+// @Description Stop sending notifications and digests of the current user to Telegram
+// @Tags notifications
+// @Security BearerAuth
+// @Success 204 "No Content"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 404 {object} map[string]string "Telegram chat is not linked"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /notifications/telegram [delete]
+func (h *TelegramHandler) UnlinkTelegram(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	if err := h.service.Unlink(c.Request.Context(), userID.(string)); err != nil {
+		if err == service.ErrTelegramNotLinked {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Telegram chat is not linked"})
+			return
+		}
+		h.logger.Error("Failed to unlink telegram chat: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unlink telegram chat"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
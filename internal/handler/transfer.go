@@ -0,0 +1,63 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoloko/taskmange/internal/logger"
+	"github.com/jmoloko/taskmange/internal/service"
+)
+
+// TransferHandler обрабатывает HTTP-запросы истории импорта и экспорта
+type TransferHandler struct {
+	service *service.TransferService
+	logger  logger.Logger
+}
+
+// NewTransferHandler создает новый экземпляр TransferHandler
+func NewTransferHandler(service *service.TransferService, logger logger.Logger) *TransferHandler {
+	return &TransferHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// GetTransfers история импорта и экспорта
+// @Summary Get import/export history
+// @Description Get recent task imports and exports of the current user: when, direction, format, item count, duration and error
+// @Tags users
+// @Produce json
+// @Param limit query int false "Number of records (1-200)" default(50)
+// @Security BearerAuth
+// @Success 200 {array} models.DataTransfer
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /users/me/transfers [get]
+func (h *TransferHandler) GetTransfers(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be a number"})
+		return
+	}
+
+	transfers, err := h.service.History(c.Request.Context(), userID.(string), limit)
+	if err != nil {
+		if err == service.ErrInvalidTransferLimit {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 200"})
+			return
+		}
+		h.logger.Error("Failed to get data transfers: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get import/export history"})
+		return
+	}
+
+	c.JSON(http.StatusOK, transfers)
+}
@@ -0,0 +1,169 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/jmoloko/taskmange/internal/logger"
+	"github.com/jmoloko/taskmange/internal/service"
+)
+
+// TriggerHandler обрабатывает HTTP-запросы пользовательских триггеров
+type TriggerHandler struct {
+	service *service.TriggerService
+	logger  logger.Logger
+}
+
+// NewTriggerHandler создает новый экземпляр TriggerHandler
+func NewTriggerHandler(service *service.TriggerService, logger logger.Logger) *TriggerHandler {
+	return &TriggerHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// ListTriggers список триггеров
+// @Summary List triggers
+// @Description Get automation triggers of the current user
+// @Tags triggers
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} models.Trigger
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /triggers [get]
+func (h *TriggerHandler) ListTriggers(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	triggers, err := h.service.List(c.Request.Context(), userID.(string))
+	if err != nil {
+		h.logger.Error("Failed to get triggers: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get triggers"})
+		return
+	}
+
+	c.JSON(http.StatusOK, triggers)
+}
+
+// CreateTrigger создание триггера
+// @Summary Create a trigger
+// @Description Create an automation trigger: event (task.created, task.updated, task.completed, task.deleted), filter expression and action (webhook, slack, email). Webhook triggers get a signing secret that is returned only in this response
+// @Tags triggers
+// @Accept json
+// @Produce json
+// @Param trigger body models.TriggerRequest true "Trigger"
+// @Security BearerAuth
+// @Success 201 {object} models.Trigger
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /triggers [post]
+func (h *TriggerHandler) CreateTrigger(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var req models.TriggerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	trigger, err := h.service.Create(c.Request.Context(), userID.(string), req)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidTrigger):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, service.ErrActionUnavailable):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Trigger action is not available"})
+		case errors.Is(err, service.ErrTooManyTriggers):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Too many triggers"})
+		default:
+			if constraintError(c, err) {
+				return
+			}
+			h.logger.Error("Failed to create trigger: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create trigger"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, trigger)
+}
+
+// DeleteTrigger удаление триггера
+// @Summary Delete a trigger
+// @Description Delete an automation trigger by ID
+// @Tags triggers
+// @Produce json
+// @Param id path string true "Trigger ID"
+// @Security BearerAuth
+// @Success 204 "No Content"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /triggers/{id} [delete]
+func (h *TriggerHandler) DeleteTrigger(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	if err := h.service.Delete(c.Request.Context(), userID.(string), c.Param("id")); err != nil {
+		if err == service.ErrTriggerNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Trigger not found"})
+			return
+		}
+		h.logger.Error("Failed to delete trigger: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete trigger"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// RotateTriggerSecret ротация секрета подписи webhook
+// @Summary Rotate a webhook signing secret
+// @Description Issue a new signing secret for a webhook trigger. For 24 hours after rotation requests are signed with both the new and the previous secret, so the receiver can switch keys without dropping deliveries
+// @Tags triggers
+// @Produce json
+// @Param id path string true "Trigger ID"
+// @Security BearerAuth
+// @Success 200 {object} models.WebhookSecret
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /triggers/{id}/secret/rotate [post]
+func (h *TriggerHandler) RotateTriggerSecret(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	secret, err := h.service.RotateSecret(c.Request.Context(), userID.(string), c.Param("id"))
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrTriggerNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Trigger not found"})
+		case errors.Is(err, service.ErrTriggerHasNoSecret):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Only webhook triggers have a signing secret"})
+		default:
+			h.logger.Error("Failed to rotate trigger secret: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to rotate trigger secret"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, secret)
+}
@@ -0,0 +1,78 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoloko/taskmange/internal/logger"
+	"github.com/jmoloko/taskmange/internal/redact"
+	"github.com/jmoloko/taskmange/internal/service"
+)
+
+// UsageHandler обрабатывает HTTP-запросы статистики использования API
+type UsageHandler struct {
+	service   *service.UsageService
+	redaction *redact.Policy
+	logger    logger.Logger
+}
+
+// NewUsageHandler создает новый экземпляр UsageHandler
+func NewUsageHandler(service *service.UsageService, redaction *redact.Policy, logger logger.Logger) *UsageHandler {
+	return &UsageHandler{
+		service:   service,
+		redaction: redaction,
+		logger:    logger,
+	}
+}
+
+// GetUsage самые активные пользователи API
+// @Summary Get API usage by user
+// @Description Get users with the most API requests over the last days (including today) with their error counts and error rates. Counters are moved from Redis to Postgres by a background job, so the latest requests may not be included yet. Emails are redacted as in the user list
+// @Tags admin
+// @Produce json
+// @Param days query int false "Number of days (1-90)" default(7)
+// @Param limit query int false "Number of users (1-100)" default(20)
+// @Param redact query string false "Comma-separated redaction profiles, e.g. emails"
+// @Security BearerAuth
+// @Success 200 {object} models.UsageReport
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Forbidden"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /admin/usage [get]
+func (h *UsageHandler) GetUsage(c *gin.Context) {
+	days, err := strconv.Atoi(c.DefaultQuery("days", "7"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "days must be a number"})
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be a number"})
+		return
+	}
+	profile, err := h.redaction.Admin(c.Query("redact"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown redaction profile"})
+		return
+	}
+
+	report, err := h.service.TopConsumers(c.Request.Context(), days, limit)
+	if err != nil {
+		if err == service.ErrInvalidUsageQuery {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "days must be between 1 and 90 and limit between 1 and 100"})
+			return
+		}
+		h.logger.Error("Failed to get API usage: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get API usage"})
+		return
+	}
+
+	for i := range report.Users {
+		report.Users[i].Email = profile.Email(report.Users[i].Email)
+	}
+
+	c.JSON(http.StatusOK, report)
+}
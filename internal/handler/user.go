@@ -0,0 +1,116 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/jmoloko/taskmange/internal/logger"
+	"github.com/jmoloko/taskmange/internal/service"
+)
+
+// UserHandler обрабатывает административные HTTP-запросы к учетным записям пользователей
+type UserHandler struct {
+	service *service.UserStatusService
+	logger  logger.Logger
+}
+
+// NewUserHandler создает новый экземпляр UserHandler
+func NewUserHandler(service *service.UserStatusService, logger logger.Logger) *UserHandler {
+	return &UserHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// DeactivateUser деактивация пользователя
+// @Summary Deactivate a user
+// @Description Deactivate a user account: login is refused and already issued tokens are rejected with 403. Tasks and other data are kept. The action is written to the audit log
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param request body models.DeactivateUserRequest true "Reason, e.g. a support ticket"
+// @Security BearerAuth
+// @Success 204 "No Content"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Forbidden"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 409 {object} map[string]string "User is already deactivated"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /admin/users/{id}/deactivate [post]
+func (h *UserHandler) DeactivateUser(c *gin.Context) {
+	adminID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var req models.DeactivateUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "reason is required"})
+		return
+	}
+
+	if err := h.service.Deactivate(c.Request.Context(), adminID.(string), c.Param("id"), req.Reason); err != nil {
+		switch err {
+		case service.ErrDeactivationReason:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "reason is required"})
+		case service.ErrSelfDeactivation:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Cannot deactivate yourself"})
+		case service.ErrUserNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		case service.ErrUserAlreadyInStatus:
+			c.JSON(http.StatusConflict, gin.H{"error": "User is already deactivated"})
+		default:
+			h.logger.Error("Failed to deactivate user: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to deactivate user"})
+		}
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ReactivateUser повторная активация пользователя
+// @Summary Reactivate a user
+// @Description Reactivate a deactivated user account, the user can log in again. The action is written to the audit log
+// @Tags admin
+// @Produce json
+// @Param id path string true "User ID"
+// @Security BearerAuth
+// @Success 204 "No Content"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Forbidden"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 409 {object} map[string]string "User is already active"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /admin/users/{id}/reactivate [post]
+func (h *UserHandler) ReactivateUser(c *gin.Context) {
+	adminID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	if err := h.service.Reactivate(c.Request.Context(), adminID.(string), c.Param("id")); err != nil {
+		switch err {
+		case service.ErrUserNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		case service.ErrUserAlreadyInStatus:
+			c.JSON(http.StatusConflict, gin.H{"error": "User is already active"})
+		default:
+			h.logger.Error("Failed to reactivate user: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reactivate user"})
+		}
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// GetService возвращает сервис статуса пользователей
+func (h *UserHandler) GetService() *service.UserStatusService {
+	return h.service
+}
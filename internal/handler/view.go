@@ -0,0 +1,190 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/jmoloko/taskmange/internal/logger"
+	"github.com/jmoloko/taskmange/internal/service"
+)
+
+// ViewHandler обрабатывает HTTP-запросы списков "Сегодня" и "Предстоящие" и матрицы Эйзенхауэра
+type ViewHandler struct {
+	service *service.TaskViewService
+	logger  logger.Logger
+}
+
+// NewViewHandler создает новый экземпляр ViewHandler
+func NewViewHandler(service *service.TaskViewService, logger logger.Logger) *ViewHandler {
+	return &ViewHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// GetToday задачи на сегодня
+// @Summary Get today's tasks
+// @Description Get open tasks due by the end of today, including overdue ones, in smart order. Day boundaries use the tz parameter or the timezone from notification preferences
+// @Tags tasks
+// @Produce json
+// @Param tz query string false "IANA timezone, e.g. Europe/Moscow"
+// @Security BearerAuth
+// @Success 200 {array} models.Task
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /tasks/today [get]
+func (h *ViewHandler) GetToday(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	tasks, err := h.service.Today(c.Request.Context(), userID.(string), c.Query("tz"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, tasks)
+}
+
+// GetUpcoming задачи на ближайшие дни
+// @Summary Get upcoming tasks
+// @Description Get open tasks due from the start of today within the given number of days, in smart order. Day boundaries use the tz parameter or the timezone from notification preferences
+// @Tags tasks
+// @Produce json
+// @Param days query int false "Number of days (1-30)" default(7)
+// @Param tz query string false "IANA timezone, e.g. Europe/Moscow"
+// @Security BearerAuth
+// @Success 200 {array} models.Task
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /tasks/upcoming [get]
+func (h *ViewHandler) GetUpcoming(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	days, err := strconv.Atoi(c.DefaultQuery("days", "7"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "days must be a number"})
+		return
+	}
+
+	tasks, err := h.service.Upcoming(c.Request.Context(), userID.(string), days, c.Query("tz"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, tasks)
+}
+
+// GetMatrix матрица Эйзенхауэра
+// @Summary Get the priority matrix
+// @Description Partition open tasks into Eisenhower quadrants: do_first (urgent and important), schedule (important), delegate (urgent) and eliminate. A task is urgent when it is overdue or due within urgent_within_hours, and important when its priority is at least important_priority. Thresholds come from the user's matrix settings (defaults: 48 hours, high). Tasks in each quadrant are in smart order
+// @Tags tasks
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} models.TaskMatrix
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /tasks/matrix [get]
+func (h *ViewHandler) GetMatrix(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	matrix, err := h.service.Matrix(c.Request.Context(), userID.(string))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, matrix)
+}
+
+// GetMatrixSettings пороги матрицы Эйзенхауэра
+// @Summary Get priority matrix settings
+// @Description Get the urgency and importance thresholds of the priority matrix; defaults are returned if the user has not changed them
+// @Tags tasks
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} models.MatrixSettings
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /tasks/matrix/settings [get]
+func (h *ViewHandler) GetMatrixSettings(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	settings, err := h.service.MatrixSettings(c.Request.Context(), userID.(string))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, settings)
+}
+
+// UpdateMatrixSettings изменение порогов матрицы Эйзенхауэра
+// @Summary Update priority matrix settings
+// @Description Replace the thresholds of the priority matrix: urgent_within_hours from 1 to 720 and important_priority (low, medium or high)
+// @Tags tasks
+// @Accept json
+// @Produce json
+// @Param settings body models.MatrixSettings true "Matrix thresholds"
+// @Security BearerAuth
+// @Success 200 {object} models.MatrixSettings
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /tasks/matrix/settings [put]
+func (h *ViewHandler) UpdateMatrixSettings(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var req models.MatrixSettings
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	settings, err := h.service.SaveMatrixSettings(c.Request.Context(), userID.(string), req)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, settings)
+}
+
+func (h *ViewHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidTimezone):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown timezone"})
+	case errors.Is(err, service.ErrInvalidUpcomingRange):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "days must be between 1 and 30"})
+	case errors.Is(err, service.ErrInvalidMatrixSettings):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "urgent_within_hours must be between 1 and 720, important_priority one of low, medium, high"})
+	default:
+		h.logger.Error("Failed to get task view: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get tasks"})
+	}
+}
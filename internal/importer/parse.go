@@ -0,0 +1,195 @@
+// Package importer разбирает файлы импорта задач в форматах JSON, CSV и XLSX
+package importer
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jmoloko/taskmange/internal/domain/models"
+)
+
+// Format формат файла импорта
+type Format string
+
+const (
+	FormatJSON Format = "json"
+	FormatCSV  Format = "csv"
+	FormatXLSX Format = "xlsx"
+)
+
+var (
+	ErrUnsupportedFormat = errors.New("unsupported import format")
+	ErrInvalidFile       = errors.New("invalid import file")
+	ErrTooManyRows       = errors.New("too many rows in import file")
+)
+
+// Limits ограничения загружаемого файла импорта
+type Limits struct {
+	// MaxBytes наибольший размер тела запроса
+	MaxBytes int64
+	// MaxRows наибольшее число строк файла, 0 — без ограничения
+	MaxRows int
+}
+
+// поля задачи и принимаемые названия колонок
+var columnAliases = map[string]string{
+	"title":       "title",
+	"name":        "title",
+	"description": "description",
+	"status":      "status",
+	"priority":    "priority",
+	"due_date":    "due_date",
+	"due date":    "due_date",
+	"due":         "due_date",
+	"private":     "private",
+	"notes":       "notes",
+	"links":       "links",
+	"url":         "links",
+	"tags":        "tags",
+}
+
+// форматы срока в табличных файлах
+var dueDateLayouts = []string{time.RFC3339, "2006-01-02 15:04", "2006-01-02"}
+
+// DetectFormat определяет формат по Content-Type запроса
+func DetectFormat(contentType string) (Format, error) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return "", ErrUnsupportedFormat
+	}
+
+	switch mediaType {
+	case "application/json":
+		return FormatJSON, nil
+	case "text/csv":
+		return FormatCSV, nil
+	case "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet":
+		return FormatXLSX, nil
+	default:
+		return "", ErrUnsupportedFormat
+	}
+}
+
+// DetectFileFormat определяет формат загруженного файла по расширению имени, а если оно
+// неизвестно — по Content-Type части multipart: браузеры часто отдают CSV как application/vnd.ms-excel
+func DetectFileFormat(filename, contentType string) (Format, error) {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".json":
+		return FormatJSON, nil
+	case ".csv":
+		return FormatCSV, nil
+	case ".xlsx":
+		return FormatXLSX, nil
+	}
+	return DetectFormat(contentType)
+}
+
+// Parse разбирает файл импорта целиком. Ошибки отдельных строк попадают в ImportBatch.Errors,
+// ошибка возвращается, только если файл не удалось прочитать целиком или в нем больше maxRows строк
+func Parse(format Format, r io.Reader, maxRows int) (models.ImportBatch, error) {
+	reader, err := NewReader(format, r, maxRows)
+	if err != nil {
+		return models.ImportBatch{}, err
+	}
+	defer reader.Close()
+
+	batch := models.ImportBatch{Mapping: reader.Mapping()}
+	for {
+		row, rowErr, err := reader.Next()
+		if err == io.EOF {
+			return batch, nil
+		}
+		if err != nil {
+			return models.ImportBatch{}, err
+		}
+		if rowErr != nil {
+			batch.Errors = append(batch.Errors, *rowErr)
+			continue
+		}
+		batch.Rows = append(batch.Rows, row)
+	}
+}
+
+// parseRecord заполняет задачу значениями колонок
+func parseRecord(fields, record []string) (models.Task, *models.ImportRowError) {
+	var task models.Task
+	for i, value := range record {
+		if i >= len(fields) {
+			break
+		}
+		value = strings.TrimSpace(value)
+
+		switch fields[i] {
+		case "title":
+			task.Title = value
+		case "description":
+			task.Description = value
+		case "notes":
+			if value != "" {
+				notes := value
+				task.Notes = &notes
+			}
+		case "links":
+			// несколько ссылок в ячейке разделяются пробелами
+			for _, u := range strings.Fields(value) {
+				task.Links = append(task.Links, models.TaskLink{URL: u})
+			}
+		case "tags":
+			// теги в ячейке разделяются запятыми
+			for _, tag := range strings.Split(value, ",") {
+				if tag = strings.TrimSpace(tag); tag != "" {
+					task.Tags = append(task.Tags, tag)
+				}
+			}
+		case "status":
+			task.Status = models.Status(strings.ToLower(value))
+		case "priority":
+			task.Priority = models.Priority(strings.ToLower(value))
+		case "due_date":
+			if value == "" {
+				continue
+			}
+			dueDate, err := parseDueDate(value)
+			if err != nil {
+				return models.Task{}, &models.ImportRowError{Field: "due_date", Message: "invalid date, expected YYYY-MM-DD or RFC 3339"}
+			}
+			task.DueDate = dueDate
+		case "private":
+			if value == "" {
+				continue
+			}
+			private, err := strconv.ParseBool(value)
+			if err != nil {
+				return models.Task{}, &models.ImportRowError{Field: "private", Message: "invalid boolean"}
+			}
+			task.Private = private
+		}
+	}
+
+	return task, nil
+}
+
+func parseDueDate(value string) (time.Time, error) {
+	var err error
+	for _, layout := range dueDateLayouts {
+		var t time.Time
+		if t, err = time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, err
+}
+
+func isBlank(record []string) bool {
+	for _, value := range record {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,89 @@
+package importer
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xuri/excelize/v2"
+)
+
+func TestDetectFormat(t *testing.T) {
+	format, err := DetectFormat("text/csv; charset=utf-8")
+	require.NoError(t, err)
+	assert.Equal(t, FormatCSV, format)
+
+	_, err = DetectFormat("text/plain")
+	assert.Equal(t, ErrUnsupportedFormat, err)
+}
+
+func TestParse_JSON(t *testing.T) {
+	batch, err := Parse(FormatJSON, strings.NewReader(`[{"title":"A"},{"title":1},{"title":"B","private":true}]`), 0)
+	require.NoError(t, err)
+	require.Len(t, batch.Rows, 2)
+	assert.Equal(t, 3, batch.Rows[1].Row)
+	assert.True(t, batch.Rows[1].Task.Private)
+	require.Len(t, batch.Errors, 1)
+	assert.Equal(t, 2, batch.Errors[0].Row)
+
+	_, err = Parse(FormatJSON, strings.NewReader(`{"title":"A"}`), 0)
+	assert.ErrorIs(t, err, ErrInvalidFile)
+}
+
+func TestParse_CSV(t *testing.T) {
+	data := "Title,Priority,Due Date,Color\n" +
+		"Report,HIGH,2024-05-01,red\n" +
+		",,,\n" +
+		"Call,low,tomorrow,\n"
+
+	batch, err := Parse(FormatCSV, strings.NewReader(data), 0)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"Title": "title", "Priority": "priority", "Due Date": "due_date", "Color": ""}, batch.Mapping)
+
+	require.Len(t, batch.Rows, 1)
+	assert.Equal(t, 2, batch.Rows[0].Row)
+	assert.Equal(t, models.PriorityHigh, batch.Rows[0].Task.Priority)
+	assert.Equal(t, time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), batch.Rows[0].Task.DueDate)
+
+	require.Len(t, batch.Errors, 1)
+	assert.Equal(t, models.ImportRowError{Row: 4, Field: "due_date", Message: "invalid date, expected YYYY-MM-DD or RFC 3339"}, batch.Errors[0])
+}
+
+func TestParse_CSVDetails(t *testing.T) {
+	data := "title,notes,links,tags\n" +
+		"Spec,\"long notes\",https://example.com/a https://example.com/b,\"docs, Q3\"\n" +
+		"Empty,,,\n"
+
+	batch, err := Parse(FormatCSV, strings.NewReader(data), 0)
+	require.NoError(t, err)
+	require.Len(t, batch.Rows, 2)
+
+	spec := batch.Rows[0].Task
+	require.NotNil(t, spec.Notes)
+	assert.Equal(t, "long notes", *spec.Notes)
+	assert.Equal(t, []models.TaskLink{{URL: "https://example.com/a"}, {URL: "https://example.com/b"}}, spec.Links)
+	assert.Equal(t, []string{"docs", "Q3"}, spec.Tags)
+
+	assert.Nil(t, batch.Rows[1].Task.Notes)
+	assert.Nil(t, batch.Rows[1].Task.Links)
+	assert.Nil(t, batch.Rows[1].Task.Tags)
+}
+
+func TestParse_XLSX(t *testing.T) {
+	file := excelize.NewFile()
+	sheet := file.GetSheetName(0)
+	require.NoError(t, file.SetSheetRow(sheet, "A1", &[]interface{}{"title", "status", "private"}))
+	require.NoError(t, file.SetSheetRow(sheet, "A2", &[]interface{}{"Report", "done", "true"}))
+
+	var buf bytes.Buffer
+	require.NoError(t, file.Write(&buf))
+
+	batch, err := Parse(FormatXLSX, &buf, 0)
+	require.NoError(t, err)
+	require.Len(t, batch.Rows, 1)
+	assert.Equal(t, models.Task{Title: "Report", Status: models.StatusDone, Private: true}, batch.Rows[0].Task)
+}
@@ -0,0 +1,201 @@
+package importer
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/xuri/excelize/v2"
+)
+
+// RowReader читает файл импорта по одной строке, не загружая разобранный файл в память целиком.
+// XLSX — архив, поэтому excelize все равно держит файл в памяти, построчно читаются только ячейки
+type RowReader struct {
+	row int
+
+	// JSON: массив задач
+	decoder *json.Decoder
+
+	// CSV и XLSX: таблица с заголовком в первой строке
+	records func() ([]string, error)
+	fields  []string
+	mapping map[string]string
+
+	closer io.Closer
+
+	// maxRows наибольшее число строк файла, 0 — без ограничения; read прочитано строк
+	maxRows int
+	read    int
+}
+
+// xlsxUnzipSizeLimit наибольший размер распакованного XLSX. excelize держит распакованные части
+// книги в памяти, а степень сжатия не зависит от размера загруженного файла
+const xlsxUnzipSizeLimit = 256 << 20
+
+// NewReader открывает файл импорта и читает заголовок таблицы или начало JSON-массива.
+// Файл длиннее maxRows строк дочитывается с ошибкой ErrTooManyRows, 0 — без ограничения
+func NewReader(format Format, r io.Reader, maxRows int) (*RowReader, error) {
+	reader, err := openReader(format, r)
+	if err != nil {
+		return nil, err
+	}
+	reader.maxRows = maxRows
+	return reader, nil
+}
+
+func openReader(format Format, r io.Reader) (*RowReader, error) {
+	switch format {
+	case FormatJSON:
+		decoder := json.NewDecoder(r)
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidFile, err)
+		}
+		if delim, ok := token.(json.Delim); !ok || delim != '[' {
+			return nil, fmt.Errorf("%w: expected an array of tasks", ErrInvalidFile)
+		}
+		return &RowReader{decoder: decoder}, nil
+	case FormatCSV:
+		reader := csv.NewReader(r)
+		reader.FieldsPerRecord = -1
+		reader.TrimLeadingSpace = true
+		return newTableReader(reader.Read, nil)
+	case FormatXLSX:
+		file, err := excelize.OpenReader(r, excelize.Options{UnzipSizeLimit: xlsxUnzipSizeLimit})
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidFile, err)
+		}
+		rows, err := file.Rows(file.GetSheetName(0))
+		if err != nil {
+			file.Close()
+			return nil, fmt.Errorf("%w: %w", ErrInvalidFile, err)
+		}
+		next := func() ([]string, error) {
+			if !rows.Next() {
+				if err := rows.Error(); err != nil {
+					return nil, err
+				}
+				return nil, io.EOF
+			}
+			return rows.Columns()
+		}
+		return newTableReader(next, closerFunc(func() error {
+			rows.Close()
+			return file.Close()
+		}))
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+}
+
+func newTableReader(records func() ([]string, error), closer io.Closer) (*RowReader, error) {
+	header, err := records()
+	if err == io.EOF {
+		err = fmt.Errorf("%w: header row is missing", ErrInvalidFile)
+	} else if err != nil {
+		err = fmt.Errorf("%w: %w", ErrInvalidFile, err)
+	}
+	if err != nil {
+		if closer != nil {
+			closer.Close()
+		}
+		return nil, err
+	}
+
+	reader := &RowReader{
+		row:     1,
+		records: records,
+		fields:  make([]string, len(header)),
+		mapping: make(map[string]string, len(header)),
+		closer:  closer,
+	}
+	for i, column := range header {
+		reader.fields[i] = columnAliases[strings.ToLower(strings.TrimSpace(column))]
+		reader.mapping[column] = reader.fields[i]
+	}
+	return reader, nil
+}
+
+// Next возвращает следующую строку. Ошибка разбора строки возвращается в rowErr и не прерывает
+// чтение; err — файл не удалось дочитать или в нем больше maxRows строк, io.EOF — строки закончились
+func (r *RowReader) Next() (row models.ImportRow, rowErr *models.ImportRowError, err error) {
+	row, rowErr, err = r.next()
+	if err != nil {
+		return models.ImportRow{}, nil, err
+	}
+	r.read++
+	if r.maxRows > 0 && r.read > r.maxRows {
+		return models.ImportRow{}, nil, fmt.Errorf("%w: at most %d rows are allowed", ErrTooManyRows, r.maxRows)
+	}
+	return row, rowErr, nil
+}
+
+func (r *RowReader) next() (models.ImportRow, *models.ImportRowError, error) {
+	if r.decoder != nil {
+		return r.nextJSON()
+	}
+
+	for {
+		record, err := r.records()
+		if err == io.EOF {
+			return models.ImportRow{}, nil, io.EOF
+		}
+		if err != nil {
+			return models.ImportRow{}, nil, fmt.Errorf("%w: %w", ErrInvalidFile, err)
+		}
+		r.row++
+		if isBlank(record) {
+			continue
+		}
+
+		task, rowErr := parseRecord(r.fields, record)
+		if rowErr != nil {
+			rowErr.Row = r.row
+			return models.ImportRow{}, rowErr, nil
+		}
+		return models.ImportRow{Row: r.row, Task: task}, nil, nil
+	}
+}
+
+func (r *RowReader) nextJSON() (models.ImportRow, *models.ImportRowError, error) {
+	if !r.decoder.More() {
+		if _, err := r.decoder.Token(); err != nil {
+			return models.ImportRow{}, nil, fmt.Errorf("%w: %w", ErrInvalidFile, err)
+		}
+		return models.ImportRow{}, nil, io.EOF
+	}
+
+	var item json.RawMessage
+	if err := r.decoder.Decode(&item); err != nil {
+		return models.ImportRow{}, nil, fmt.Errorf("%w: %w", ErrInvalidFile, err)
+	}
+	r.row++
+
+	var task models.Task
+	if err := json.Unmarshal(item, &task); err != nil {
+		return models.ImportRow{}, &models.ImportRowError{Row: r.row, Message: err.Error()}, nil
+	}
+	return models.ImportRow{Row: r.row, Task: task}, nil, nil
+}
+
+// Mapping соответствие колонок файла полям задачи, для JSON — nil
+func (r *RowReader) Mapping() map[string]string {
+	return r.mapping
+}
+
+// Close освобождает ресурсы XLSX-файла, для остальных форматов ничего не делает
+func (r *RowReader) Close() error {
+	if r.closer == nil {
+		return nil
+	}
+	return r.closer.Close()
+}
+
+type closerFunc func() error
+
+func (f closerFunc) Close() error {
+	return f()
+}
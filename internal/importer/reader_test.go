@@ -0,0 +1,92 @@
+package importer
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRowReader_CSV(t *testing.T) {
+	// строки отдаются по мере чтения: ошибка синтаксиса ниже не мешает получить первые строки
+	data := "title,status\n" +
+		"Report,done\n" +
+		",\n" +
+		"Call,pending\n" +
+		"\"broken,done\n"
+
+	rows, err := NewReader(FormatCSV, strings.NewReader(data), 0)
+	require.NoError(t, err)
+	defer rows.Close()
+	assert.Equal(t, map[string]string{"title": "title", "status": "status"}, rows.Mapping())
+
+	row, rowErr, err := rows.Next()
+	require.NoError(t, err)
+	assert.Nil(t, rowErr)
+	assert.Equal(t, 2, row.Row)
+	assert.Equal(t, "Report", row.Task.Title)
+
+	row, _, err = rows.Next()
+	require.NoError(t, err)
+	assert.Equal(t, 4, row.Row)
+
+	_, _, err = rows.Next()
+	assert.ErrorIs(t, err, ErrInvalidFile)
+
+	_, err = NewReader(FormatCSV, strings.NewReader(""), 0)
+	assert.ErrorIs(t, err, ErrInvalidFile)
+}
+
+func TestRowReader_JSON(t *testing.T) {
+	rows, err := NewReader(FormatJSON, strings.NewReader(`[{"title":"A"}, {"title":1}]`), 0)
+	require.NoError(t, err)
+
+	row, rowErr, err := rows.Next()
+	require.NoError(t, err)
+	assert.Nil(t, rowErr)
+	assert.Equal(t, "A", row.Task.Title)
+
+	_, rowErr, err = rows.Next()
+	require.NoError(t, err)
+	require.NotNil(t, rowErr)
+	assert.Equal(t, 2, rowErr.Row)
+
+	_, _, err = rows.Next()
+	assert.Equal(t, io.EOF, err)
+	assert.Nil(t, rows.Mapping())
+}
+
+func TestDetectFileFormat(t *testing.T) {
+	format, err := DetectFileFormat("tasks.CSV", "application/vnd.ms-excel")
+	require.NoError(t, err)
+	assert.Equal(t, FormatCSV, format)
+
+	format, err = DetectFileFormat("export", "application/json")
+	require.NoError(t, err)
+	assert.Equal(t, FormatJSON, format)
+
+	_, err = DetectFileFormat("tasks.txt", "text/plain")
+	assert.Equal(t, ErrUnsupportedFormat, err)
+}
+
+func TestRowReader_MaxRows(t *testing.T) {
+	// ошибочные строки тоже считаются, пустые — нет
+	rows, err := NewReader(FormatCSV, strings.NewReader("title,due\nA,\n\nB,tomorrow\nC,\n"), 2)
+	require.NoError(t, err)
+
+	_, _, err = rows.Next()
+	require.NoError(t, err)
+	_, rowErr, err := rows.Next()
+	require.NoError(t, err)
+	require.NotNil(t, rowErr)
+	_, _, err = rows.Next()
+	assert.ErrorIs(t, err, ErrTooManyRows)
+
+	_, err = Parse(FormatJSON, strings.NewReader(`[{"title":"A"},{"title":"B"},{"title":"C"}]`), 2)
+	assert.ErrorIs(t, err, ErrTooManyRows)
+	batch, err := Parse(FormatJSON, strings.NewReader(`[{"title":"A"},{"title":"B"}]`), 2)
+	require.NoError(t, err)
+	assert.Len(t, batch.Rows, 2)
+}
@@ -0,0 +1,61 @@
+package integration
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"regexp"
+	"strings"
+)
+
+// close, closes, closed, fix, fixes, fixed, resolve, resolves, resolved и ссылка TM-<8 hex>,
+// как ключевые слова закрытия issues в GitHub
+var closingRefPattern = regexp.MustCompile(`(?i)\b(?:close[sd]?|fix(?:e[sd])?|resolve[sd]?):?\s+TM-([0-9a-f]{8})\b`)
+
+// GitHubPushEvent поля push-события GitHub, нужные для закрытия задач
+type GitHubPushEvent struct {
+	Ref        string `json:"ref"`
+	Repository struct {
+		FullName      string `json:"full_name"`
+		DefaultBranch string `json:"default_branch"`
+	} `json:"repository"`
+	Commits []struct {
+		ID      string `json:"id"`
+		Message string `json:"message"`
+	} `json:"commits"`
+}
+
+// ToDefaultBranch push в основную ветку репозитория
+func (e GitHubPushEvent) ToDefaultBranch() bool {
+	return e.Ref == "refs/heads/"+e.Repository.DefaultBranch
+}
+
+// ParseClosingRefs начала ID задач из ключевых слов закрытия в сообщении коммита, без повторов
+func ParseClosingRefs(message string) []string {
+	var refs []string
+	seen := make(map[string]bool)
+	for _, m := range closingRefPattern.FindAllStringSubmatch(message, -1) {
+		ref := strings.ToLower(m[1])
+		if !seen[ref] {
+			seen[ref] = true
+			refs = append(refs, ref)
+		}
+	}
+	return refs
+}
+
+// VerifyGitHubSignature проверяет заголовок X-Hub-Signature-256: sha256=<hex HMAC-SHA256(secret, body)>
+func VerifyGitHubSignature(secret string, body []byte, signature string) bool {
+	sig, ok := strings.CutPrefix(signature, "sha256=")
+	if !ok {
+		return false
+	}
+	expected, err := hex.DecodeString(sig)
+	if err != nil {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hmac.Equal(mac.Sum(nil), expected)
+}
@@ -0,0 +1,90 @@
+package integration
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/jmoloko/taskmange/internal/domain/models"
+)
+
+var (
+	// owner/repo#123
+	gitHubKeyPattern = regexp.MustCompile(`^([A-Za-z0-9_.-]+)/([A-Za-z0-9_.-]+)#([1-9][0-9]*)$`)
+	// https://github.com/owner/repo/issues/123 и .../pull/123
+	gitHubPathPattern = regexp.MustCompile(`^/([A-Za-z0-9_.-]+)/([A-Za-z0-9_.-]+)/(?:issues|pull)/([1-9][0-9]*)/?$`)
+)
+
+// GitHubTracker состояние issues и pull requests через REST API GitHub
+type GitHubTracker struct {
+	client *http.Client
+	apiURL string
+	token  string
+}
+
+// NewGitHubTracker создает новый экземпляр GitHubTracker.
+// apiURL — адрес API (для GitHub Enterprise https://host/api/v3), token может быть пустым
+// для публичных репозиториев
+func NewGitHubTracker(apiURL, token string) *GitHubTracker {
+	return &GitHubTracker{
+		client: &http.Client{Timeout: 10 * time.Second},
+		apiURL: strings.TrimRight(apiURL, "/"),
+		token:  token,
+	}
+}
+
+// ParseRef принимает owner/repo#123 или ссылку на issue или pull request
+func (t *GitHubTracker) ParseRef(ref string) (string, error) {
+	ref = strings.TrimSpace(ref)
+	if m := gitHubKeyPattern.FindStringSubmatch(ref); m != nil {
+		return ref, nil
+	}
+
+	u, err := url.Parse(ref)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") {
+		return "", ErrInvalidRef
+	}
+	m := gitHubPathPattern.FindStringSubmatch(u.Path)
+	if m == nil {
+		return "", ErrInvalidRef
+	}
+
+	return fmt.Sprintf("%s/%s#%s", m[1], m[2], m[3]), nil
+}
+
+// FetchIssue состояние issue: open или closed. Pull request — тоже issue с тем же номером
+func (t *GitHubTracker) FetchIssue(ctx context.Context, key string) (models.ExternalIssue, error) {
+	m := gitHubKeyPattern.FindStringSubmatch(key)
+	if m == nil {
+		return models.ExternalIssue{}, ErrInvalidRef
+	}
+
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/repos/%s/%s/issues/%s", t.apiURL, m[1], m[2], m[3]), nil)
+	if err != nil {
+		return models.ExternalIssue{}, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	if t.token != "" {
+		req.Header.Set("Authorization", "Bearer "+t.token)
+	}
+
+	var issue struct {
+		Title   string `json:"title"`
+		State   string `json:"state"`
+		HTMLURL string `json:"html_url"`
+	}
+	if err := getJSON(ctx, t.client, req, &issue); err != nil {
+		return models.ExternalIssue{}, err
+	}
+
+	return models.ExternalIssue{
+		Title:  issue.Title,
+		Status: issue.State,
+		URL:    issue.HTMLURL,
+		Closed: issue.State == "closed",
+	}, nil
+}
@@ -0,0 +1,39 @@
+// Package integration клиенты внешних трекеров задач (GitHub, Jira)
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+var (
+	// ErrInvalidRef ключ или ссылка не относится к трекеру
+	ErrInvalidRef = errors.New("invalid external reference")
+	// ErrIssueNotFound трекер не нашел задачу или у учетных данных нет к ней доступа
+	ErrIssueNotFound = errors.New("external issue not found")
+)
+
+// getJSON выполняет GET и декодирует JSON-ответ, 404 и 410 считаются ErrIssueNotFound
+func getJSON(ctx context.Context, client *http.Client, req *http.Request, out interface{}) error {
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+		return ErrIssueNotFound
+	case resp.StatusCode < 200 || resp.StatusCode >= 300:
+		return fmt.Errorf("tracker responded with status %d", resp.StatusCode)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode tracker response: %w", err)
+	}
+
+	return nil
+}
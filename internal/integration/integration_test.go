@@ -0,0 +1,93 @@
+package integration
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGitHubTracker(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer gh-token", r.Header.Get("Authorization"))
+		switch r.URL.Path {
+		case "/repos/acme/api/issues/42":
+			w.Write([]byte(`{"title":"Fix login","state":"closed","html_url":"https://github.com/acme/api/issues/42"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	tracker := NewGitHubTracker(server.URL, "gh-token")
+
+	for _, ref := range []string{"acme/api#42", "https://github.com/acme/api/issues/42", "https://github.com/acme/api/pull/42/"} {
+		key, err := tracker.ParseRef(ref)
+		require.NoError(t, err, ref)
+		assert.Equal(t, "acme/api#42", key)
+	}
+	_, err := tracker.ParseRef("acme/api")
+	assert.ErrorIs(t, err, ErrInvalidRef)
+
+	issue, err := tracker.FetchIssue(context.Background(), "acme/api#42")
+	require.NoError(t, err)
+	assert.Equal(t, "Fix login", issue.Title)
+	assert.True(t, issue.Closed)
+
+	_, err = tracker.FetchIssue(context.Background(), "acme/api#7")
+	assert.ErrorIs(t, err, ErrIssueNotFound)
+}
+
+func TestJiraTracker(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, token, ok := r.BasicAuth()
+		assert.True(t, ok)
+		assert.Equal(t, "bot@example.com", user)
+		assert.Equal(t, "jira-token", token)
+		assert.Equal(t, "/rest/api/2/issue/OPS-7", r.URL.Path)
+		w.Write([]byte(`{"fields":{"summary":"Rotate certs","status":{"name":"In Review","statusCategory":{"key":"indeterminate"}}}}`))
+	}))
+	defer server.Close()
+
+	tracker := NewJiraTracker(server.URL, "bot@example.com", "jira-token")
+
+	key, err := tracker.ParseRef("https://acme.atlassian.net/browse/OPS-7")
+	require.NoError(t, err)
+	assert.Equal(t, "OPS-7", key)
+	_, err = tracker.ParseRef("ops-7")
+	assert.ErrorIs(t, err, ErrInvalidRef)
+
+	issue, err := tracker.FetchIssue(context.Background(), "OPS-7")
+	require.NoError(t, err)
+	assert.Equal(t, "In Review", issue.Status)
+	assert.False(t, issue.Closed)
+	assert.Equal(t, server.URL+"/browse/OPS-7", issue.URL)
+}
+
+func TestParseClosingRefs(t *testing.T) {
+	refs := ParseClosingRefs("Fix login redirect\n\nCloses TM-1A2B3C4D, fixes: tm-00ff00ff\nrefs TM-deadbeef\ncloses TM-1a2b3c4d")
+	assert.Equal(t, []string{"1a2b3c4d", "00ff00ff"}, refs)
+	assert.Empty(t, ParseClosingRefs("enclosed TM-1a2b3c4d"))
+}
+
+func TestVerifyGitHubSignature(t *testing.T) {
+	body := []byte(`{"zen":"Keep it logically awesome."}`)
+	signature := "sha256=" + hmacHex("secret", body)
+
+	assert.True(t, VerifyGitHubSignature("secret", body, signature))
+	assert.False(t, VerifyGitHubSignature("other", body, signature))
+	assert.False(t, VerifyGitHubSignature("secret", body, strings.TrimPrefix(signature, "sha256=")))
+}
+
+func hmacHex(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
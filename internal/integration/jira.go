@@ -0,0 +1,96 @@
+package integration
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/jmoloko/taskmange/internal/domain/models"
+)
+
+var (
+	// PROJ-42
+	jiraKeyPattern = regexp.MustCompile(`^[A-Z][A-Z0-9_]+-[1-9][0-9]*$`)
+	// https://company.atlassian.net/browse/PROJ-42
+	jiraPathPattern = regexp.MustCompile(`/browse/([A-Z][A-Z0-9_]+-[1-9][0-9]*)/?$`)
+)
+
+// JiraTracker состояние тикетов через REST API Jira
+type JiraTracker struct {
+	client   *http.Client
+	baseURL  string
+	email    string
+	apiToken string
+}
+
+// NewJiraTracker создает новый экземпляр JiraTracker. Запросы авторизуются
+// email пользователя Jira и его API-токеном
+func NewJiraTracker(baseURL, email, apiToken string) *JiraTracker {
+	return &JiraTracker{
+		client:   &http.Client{Timeout: 10 * time.Second},
+		baseURL:  strings.TrimRight(baseURL, "/"),
+		email:    email,
+		apiToken: apiToken,
+	}
+}
+
+// ParseRef принимает ключ тикета (PROJ-42) или ссылку .../browse/PROJ-42
+func (t *JiraTracker) ParseRef(ref string) (string, error) {
+	ref = strings.TrimSpace(ref)
+	if jiraKeyPattern.MatchString(ref) {
+		return ref, nil
+	}
+
+	u, err := url.Parse(ref)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") {
+		return "", ErrInvalidRef
+	}
+	m := jiraPathPattern.FindStringSubmatch(u.Path)
+	if m == nil {
+		return "", ErrInvalidRef
+	}
+
+	return m[1], nil
+}
+
+// FetchIssue состояние тикета: название статуса, закрыт — статус в категории done
+func (t *JiraTracker) FetchIssue(ctx context.Context, key string) (models.ExternalIssue, error) {
+	if !jiraKeyPattern.MatchString(key) {
+		return models.ExternalIssue{}, ErrInvalidRef
+	}
+
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/rest/api/2/issue/%s?fields=summary,status", t.baseURL, key), nil)
+	if err != nil {
+		return models.ExternalIssue{}, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if t.email != "" {
+		req.SetBasicAuth(t.email, t.apiToken)
+	}
+
+	var issue struct {
+		Fields struct {
+			Summary string `json:"summary"`
+			Status  struct {
+				Name           string `json:"name"`
+				StatusCategory struct {
+					Key string `json:"key"`
+				} `json:"statusCategory"`
+			} `json:"status"`
+		} `json:"fields"`
+	}
+	if err := getJSON(ctx, t.client, req, &issue); err != nil {
+		return models.ExternalIssue{}, err
+	}
+
+	return models.ExternalIssue{
+		Title:  issue.Fields.Summary,
+		Status: issue.Fields.Status.Name,
+		URL:    t.baseURL + "/browse/" + key,
+		Closed: issue.Fields.Status.StatusCategory.Key == "done",
+	}, nil
+}
@@ -0,0 +1,96 @@
+// Package lifecycle запускает и останавливает компоненты приложения: соединения, конвейер событий,
+// фоновые задачи и HTTP-сервер. Компоненты регистрируются при сборке приложения, запускаются
+// в порядке регистрации и останавливаются в обратном, так что зависимые компоненты
+// останавливаются раньше тех, от которых зависят
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// Hook запуск и остановка компонента. Любая из функций может отсутствовать:
+// Hook без Start описывает уже открытый ресурс, например соединение с базой, которое нужно только закрыть
+type Hook struct {
+	Name  string
+	Start func(ctx context.Context) error
+	Stop  func(ctx context.Context) error
+}
+
+type entry struct {
+	hook    Hook
+	started bool
+}
+
+// Lifecycle упорядоченный набор компонентов приложения
+type Lifecycle struct {
+	mu      sync.Mutex
+	entries []*entry
+}
+
+// New создает пустой Lifecycle
+func New() *Lifecycle {
+	return &Lifecycle{}
+}
+
+// Append регистрирует компонент. Компонент без Start считается запущенным сразу,
+// поэтому Stop закроет его, даже если сборка приложения прервалась до Start
+func (l *Lifecycle) Append(hook Hook) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.entries = append(l.entries, &entry{hook: hook, started: hook.Start == nil})
+}
+
+// Start запускает компоненты в порядке регистрации. Если запуск компонента не удался,
+// уже запущенные останавливаются в обратном порядке, а возвращается ошибка запуска
+func (l *Lifecycle) Start(ctx context.Context) error {
+	l.mu.Lock()
+	entries := append([]*entry(nil), l.entries...)
+	l.mu.Unlock()
+
+	for _, e := range entries {
+		if e.started {
+			continue
+		}
+		if err := e.hook.Start(ctx); err != nil {
+			err = fmt.Errorf("failed to start %s: %w", e.hook.Name, err)
+			if stopErr := l.Stop(ctx); stopErr != nil {
+				err = errors.Join(err, stopErr)
+			}
+			return err
+		}
+
+		l.mu.Lock()
+		e.started = true
+		l.mu.Unlock()
+	}
+
+	return nil
+}
+
+// Stop останавливает запущенные компоненты в обратном порядке. Ошибка одного компонента
+// не мешает остановке остальных, все ошибки возвращаются вместе. Повторный вызов ничего не делает
+func (l *Lifecycle) Stop(ctx context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var errs []error
+	for i := len(l.entries) - 1; i >= 0; i-- {
+		e := l.entries[i]
+		if !e.started {
+			continue
+		}
+		e.started = false
+		if e.hook.Stop == nil {
+			continue
+		}
+		if err := e.hook.Stop(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("failed to stop %s: %w", e.hook.Name, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
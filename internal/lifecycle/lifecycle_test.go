@@ -0,0 +1,70 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLifecycle(t *testing.T) {
+	var calls []string
+	hook := func(name string, startErr error) Hook {
+		return Hook{
+			Name: name,
+			Start: func(ctx context.Context) error {
+				calls = append(calls, "start "+name)
+				return startErr
+			},
+			Stop: func(ctx context.Context) error {
+				calls = append(calls, "stop "+name)
+				return nil
+			},
+		}
+	}
+	resource := Hook{Name: "db", Stop: func(ctx context.Context) error {
+		calls = append(calls, "stop db")
+		return errors.New("already closed")
+	}}
+
+	t.Run("Start_And_Stop", func(t *testing.T) {
+		calls = nil
+		lc := New()
+		lc.Append(resource)
+		lc.Append(hook("bus", nil))
+		lc.Append(hook("server", nil))
+
+		require.NoError(t, lc.Start(context.Background()))
+		err := lc.Stop(context.Background())
+		assert.EqualError(t, err, "failed to stop db: already closed")
+		assert.Equal(t, []string{"start bus", "start server", "stop server", "stop bus", "stop db"}, calls)
+
+		// повторная остановка ничего не делает
+		assert.NoError(t, lc.Stop(context.Background()))
+		assert.Len(t, calls, 5)
+	})
+
+	t.Run("Start_Failure_Stops_Started", func(t *testing.T) {
+		calls = nil
+		lc := New()
+		lc.Append(hook("bus", nil))
+		lc.Append(hook("worker", errors.New("boom")))
+		lc.Append(hook("server", nil))
+
+		err := lc.Start(context.Background())
+		assert.EqualError(t, err, "failed to start worker: boom")
+		assert.Equal(t, []string{"start bus", "start worker", "stop bus"}, calls)
+	})
+
+	t.Run("Stop_Before_Start_Closes_Resources", func(t *testing.T) {
+		calls = nil
+		lc := New()
+		lc.Append(resource)
+		lc.Append(hook("bus", nil))
+
+		assert.Error(t, lc.Stop(context.Background()))
+		assert.Equal(t, []string{"stop db"}, calls)
+	})
+}
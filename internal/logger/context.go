@@ -0,0 +1,18 @@
+package logger
+
+import "context"
+
+type contextKey struct{}
+
+// NewContext возвращает контекст с логгером запроса, например с полем request_id
+func NewContext(ctx context.Context, l Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, l)
+}
+
+// FromContext логгер запроса из контекста или fallback, если контекст создан вне запроса
+func FromContext(ctx context.Context, fallback Logger) Logger {
+	if l, ok := ctx.Value(contextKey{}).(Logger); ok {
+		return l
+	}
+	return fallback
+}
@@ -26,20 +26,22 @@ var (
 		[]string{"method", "endpoint"},
 	)
 
-	TasksCreatedTotal = prometheus.NewCounter(
+	TasksCreatedTotal = prometheus.NewCounterVec(
 		prometheus.CounterOpts{
 			Namespace: "taskmanager",
 			Name:      "tasks_created_total",
-			Help:      "Total number of created tasks",
+			Help:      "Total number of created tasks by tenant",
 		},
+		[]string{"tenant"},
 	)
 
-	TasksCompletedTotal = prometheus.NewCounter(
+	TasksCompletedTotal = prometheus.NewCounterVec(
 		prometheus.CounterOpts{
 			Namespace: "taskmanager",
 			Name:      "tasks_completed_total",
-			Help:      "Total number of completed tasks",
+			Help:      "Total number of completed tasks by tenant",
 		},
+		[]string{"tenant"},
 	)
 
 	TasksByStatus = prometheus.NewGaugeVec(
@@ -50,6 +52,248 @@ var (
 		},
 		[]string{"status"},
 	)
+
+	RealtimeConnections = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: "taskmanager",
+			Name:      "realtime_connections",
+			Help:      "Number of open realtime connections",
+		},
+	)
+
+	RealtimeRejectedConnectionsTotal = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: "taskmanager",
+			Name:      "realtime_rejected_connections_total",
+			Help:      "Total number of realtime connections rejected by the per-user limit",
+		},
+	)
+
+	RealtimeDroppedEventsTotal = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: "taskmanager",
+			Name:      "realtime_dropped_events_total",
+			Help:      "Total number of realtime events dropped because a client buffer was full",
+		},
+	)
+
+	EventsDroppedTotal = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: "taskmanager",
+			Name:      "events_dropped_total",
+			Help:      "Total number of task events dropped because the event bus queue was full",
+		},
+	)
+
+	EventsQueued = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: "taskmanager",
+			Name:      "events_queued",
+			Help:      "Number of task events waiting in the event bus queue for webhook, trigger and notification delivery",
+		},
+	)
+
+	TriggerDeliveriesInFlight = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "taskmanager",
+			Name:      "trigger_deliveries_in_flight",
+			Help:      "Number of trigger action deliveries (webhook, slack, email) being sent",
+		},
+		[]string{"action"},
+	)
+
+	TriggerExecutionsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "taskmanager",
+			Name:      "trigger_executions_total",
+			Help:      "Total number of executed trigger actions by tenant",
+		},
+		[]string{"action", "result", "tenant"},
+	)
+
+	NotificationsBufferedTotal = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: "taskmanager",
+			Name:      "notifications_buffered_total",
+			Help:      "Total number of notifications buffered for a digest",
+		},
+	)
+
+	NotificationDigestsSentTotal = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: "taskmanager",
+			Name:      "notification_digests_sent_total",
+			Help:      "Total number of digest notifications sent",
+		},
+	)
+
+	NotificationDigestBacklog = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "taskmanager",
+			Name:      "notification_digest_backlog",
+			Help:      "Number of users with buffered digest notifications by state (pending, overdue)",
+		},
+		[]string{"state"},
+	)
+
+	WorkerJobsRunning = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: "taskmanager",
+			Name:      "worker_jobs_running",
+			Help:      "Number of scheduled background job loops running",
+		},
+	)
+
+	WorkerJobPanicsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "taskmanager",
+			Name:      "worker_job_panics_total",
+			Help:      "Total number of background job runs that panicked by job",
+		},
+		[]string{"job"},
+	)
+
+	WorkerJobsInFlight = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "taskmanager",
+			Name:      "worker_job_in_flight",
+			Help:      "Number of background job runs in progress by job",
+		},
+		[]string{"job"},
+	)
+
+	CacheInvalidationsTotal = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: "taskmanager",
+			Name:      "cache_invalidations_total",
+			Help:      "Total number of per-user cache invalidations triggered by task table changes",
+		},
+	)
+
+	ConcurrencyInFlight = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "taskmanager",
+			Name:      "concurrency_in_flight_requests",
+			Help:      "Number of requests being served by a concurrency-limited endpoint",
+		},
+		[]string{"endpoint"},
+	)
+
+	ConcurrencyQueued = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "taskmanager",
+			Name:      "concurrency_queued_requests",
+			Help:      "Number of requests waiting for a slot of a concurrency-limited endpoint",
+		},
+		[]string{"endpoint"},
+	)
+
+	ConcurrencyRejectedTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "taskmanager",
+			Name:      "concurrency_rejected_requests_total",
+			Help:      "Total number of requests rejected because the endpoint queue was full or the wait timed out",
+		},
+		[]string{"endpoint"},
+	)
+
+	TaskCacheRequestsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "taskmanager",
+			Name:      "task_cache_requests_total",
+			Help:      "Total number of single-task cache lookups by result (hit, miss)",
+		},
+		[]string{"result"},
+	)
+
+	TaskListCacheRequestsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "taskmanager",
+			Name:      "task_list_cache_requests_total",
+			Help:      "Total number of task list cache lookups by result (hit, miss)",
+		},
+		[]string{"result"},
+	)
+
+	AnalyticsCacheRequestsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "taskmanager",
+			Name:      "analytics_cache_requests_total",
+			Help:      "Total number of analytics cache lookups by result (hit, stale, miss, error)",
+		},
+		[]string{"result"},
+	)
+
+	AnalyticsCacheLookupDuration = prometheus.NewHistogram(
+		prometheus.HistogramOpts{
+			Namespace: "taskmanager",
+			Name:      "analytics_cache_lookup_duration_seconds",
+			Help:      "Analytics cache lookup duration in seconds",
+			Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25},
+		},
+	)
+
+	AnalyticsComputeDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: "taskmanager",
+			Name:      "analytics_compute_duration_seconds",
+			Help:      "Duration of computing analytics from the database on a cache miss, in seconds",
+			Buckets:   []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10},
+		},
+		[]string{"period"},
+	)
+
+	SheddingActive = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: "taskmanager",
+			Name:      "load_shedding_active",
+			Help:      "Whether low-priority requests are being shed (1) or not (0)",
+		},
+	)
+
+	RequestsShedTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "taskmanager",
+			Name:      "load_shedding_rejected_requests_total",
+			Help:      "Total number of low-priority requests rejected by load shedding",
+		},
+		[]string{"endpoint"},
+	)
+
+	RequestLatencyP99 = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: "taskmanager",
+			Name:      "load_shedding_latency_p99_seconds",
+			Help:      "p99 HTTP request latency over the last minute as seen by load shedding",
+		},
+	)
+
+	PasswordHashDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: "taskmanager",
+			Name:      "password_hash_duration_seconds",
+			Help:      "Password hashing and verification duration in seconds by algorithm and operation (hash, verify)",
+			Buckets:   []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+		},
+		[]string{"algorithm", "operation"},
+	)
+
+	CacheCircuitOpen = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "taskmanager",
+			Name:      "cache_circuit_open",
+			Help:      "Whether the circuit breaker in front of Redis is open (1) and requests go to the fallback cache, by cache",
+		},
+		[]string{"cache"},
+	)
+
+	DBPoolSaturation = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: "taskmanager",
+			Name:      "db_pool_saturation",
+			Help:      "Share of Postgres pool connections in use",
+		},
+	)
 )
 
 func init() {
@@ -58,6 +302,34 @@ func init() {
 	Registry.MustRegister(TasksCreatedTotal)
 	Registry.MustRegister(TasksCompletedTotal)
 	Registry.MustRegister(TasksByStatus)
+	Registry.MustRegister(RealtimeConnections)
+	Registry.MustRegister(RealtimeRejectedConnectionsTotal)
+	Registry.MustRegister(RealtimeDroppedEventsTotal)
+	Registry.MustRegister(EventsDroppedTotal)
+	Registry.MustRegister(EventsQueued)
+	Registry.MustRegister(TriggerDeliveriesInFlight)
+	Registry.MustRegister(TriggerExecutionsTotal)
+	Registry.MustRegister(NotificationsBufferedTotal)
+	Registry.MustRegister(NotificationDigestsSentTotal)
+	Registry.MustRegister(NotificationDigestBacklog)
+	Registry.MustRegister(WorkerJobsRunning)
+	Registry.MustRegister(WorkerJobsInFlight)
+	Registry.MustRegister(WorkerJobPanicsTotal)
+	Registry.MustRegister(CacheInvalidationsTotal)
+	Registry.MustRegister(ConcurrencyInFlight)
+	Registry.MustRegister(ConcurrencyQueued)
+	Registry.MustRegister(ConcurrencyRejectedTotal)
+	Registry.MustRegister(TaskCacheRequestsTotal)
+	Registry.MustRegister(TaskListCacheRequestsTotal)
+	Registry.MustRegister(AnalyticsCacheRequestsTotal)
+	Registry.MustRegister(AnalyticsCacheLookupDuration)
+	Registry.MustRegister(AnalyticsComputeDuration)
+	Registry.MustRegister(SheddingActive)
+	Registry.MustRegister(RequestsShedTotal)
+	Registry.MustRegister(RequestLatencyP99)
+	Registry.MustRegister(DBPoolSaturation)
+	Registry.MustRegister(CacheCircuitOpen)
+	Registry.MustRegister(PasswordHashDuration)
 
 	Registry.MustRegister(prometheus.NewBuildInfoCollector())
 	Registry.MustRegister(prometheus.NewGoCollector())
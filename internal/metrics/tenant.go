@@ -0,0 +1,84 @@
+package metrics
+
+import (
+	"fmt"
+	"hash/fnv"
+	"sync"
+)
+
+// TenantMode способ получения значения метки tenant из ID пользователя
+type TenantMode string
+
+const (
+	// TenantModeOff метка tenant пустая, ряды метрик не делятся по пользователям
+	TenantModeOff TenantMode = "off"
+	// TenantModeHash пользователь попадает в одну из фиксированного числа корзин по хэшу ID
+	TenantModeHash TenantMode = "hash"
+	// TenantModeAllowlist пользователи из списка получают свою метку, остальные — TenantOther
+	TenantModeAllowlist TenantMode = "allowlist"
+)
+
+// TenantOther метка tenant пользователей вне списка в режиме allowlist
+const TenantOther = "other"
+
+// MaxTenantBuckets верхняя граница числа корзин, чтобы не раздувать число рядов
+const MaxTenantBuckets = 256
+
+// tenantLabels разбиение бизнес-метрик по пользователям, задается при запуске
+type tenantLabels struct {
+	mode      TenantMode
+	buckets   uint32
+	allowlist map[string]bool
+}
+
+var (
+	tenantsMu sync.RWMutex
+	tenants   = tenantLabels{mode: TenantModeOff}
+)
+
+// ConfigureTenants задает режим метки tenant. Число возможных значений метки ограничено:
+// buckets в режиме hash и размером списка плюс TenantOther в режиме allowlist
+func ConfigureTenants(mode TenantMode, buckets int, allowlist []string) error {
+	labels := tenantLabels{mode: mode}
+	switch mode {
+	case TenantModeOff:
+	case TenantModeHash:
+		if buckets < 1 || buckets > MaxTenantBuckets {
+			return fmt.Errorf("tenant buckets must be between 1 and %d", MaxTenantBuckets)
+		}
+		labels.buckets = uint32(buckets)
+	case TenantModeAllowlist:
+		labels.allowlist = make(map[string]bool, len(allowlist))
+		for _, id := range allowlist {
+			labels.allowlist[id] = true
+		}
+	default:
+		return fmt.Errorf("unknown tenant mode %q", mode)
+	}
+
+	tenantsMu.Lock()
+	tenants = labels
+	tenantsMu.Unlock()
+	return nil
+}
+
+// Tenant значение метки tenant для пользователя
+func Tenant(userID string) string {
+	tenantsMu.RLock()
+	labels := tenants
+	tenantsMu.RUnlock()
+
+	switch labels.mode {
+	case TenantModeHash:
+		h := fnv.New32a()
+		h.Write([]byte(userID))
+		return fmt.Sprintf("bucket-%03d", h.Sum32()%labels.buckets)
+	case TenantModeAllowlist:
+		if labels.allowlist[userID] {
+			return userID
+		}
+		return TenantOther
+	default:
+		return ""
+	}
+}
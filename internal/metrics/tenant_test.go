@@ -0,0 +1,33 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTenant(t *testing.T) {
+	t.Cleanup(func() { _ = ConfigureTenants(TenantModeOff, 0, nil) })
+
+	assert.Equal(t, "", Tenant("user1"))
+
+	require.NoError(t, ConfigureTenants(TenantModeHash, 4, nil))
+	seen := map[string]bool{}
+	for _, id := range []string{"user1", "user2", "user3", "user4", "user5", "user6", "user7", "user8"} {
+		seen[Tenant(id)] = true
+	}
+	assert.LessOrEqual(t, len(seen), 4)
+	assert.Equal(t, Tenant("user1"), Tenant("user1"))
+	assert.Regexp(t, `^bucket-00[0-3]$`, Tenant("user1"))
+
+	require.NoError(t, ConfigureTenants(TenantModeAllowlist, 0, []string{"acme"}))
+	assert.Equal(t, "acme", Tenant("acme"))
+	assert.Equal(t, TenantOther, Tenant("user1"))
+
+	assert.Error(t, ConfigureTenants(TenantModeHash, 0, nil))
+	assert.Error(t, ConfigureTenants(TenantModeHash, MaxTenantBuckets+1, nil))
+	assert.Error(t, ConfigureTenants("region", 16, nil))
+	// ошибочные настройки не меняют текущий режим
+	assert.Equal(t, TenantOther, Tenant("user1"))
+}
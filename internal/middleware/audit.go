@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoloko/taskmange/internal/domain/models"
+)
+
+// AuditRecorder журнал аудита
+type AuditRecorder interface {
+	Record(ctx context.Context, event models.AuditEvent)
+}
+
+// AuditMiddleware записывает в журнал аудита каждый запрос, выполненный по токену имперсонации:
+// пользователя, администратора, маршрут и код ответа. Подключается глобально, как UsageMiddleware
+func AuditMiddleware(recorder AuditRecorder) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		impersonatorID := c.GetString("impersonator_id")
+		if impersonatorID == "" {
+			return
+		}
+
+		recorder.Record(context.WithoutCancel(c.Request.Context()), models.AuditEvent{
+			Action:         models.AuditImpersonatedRequest,
+			ActorID:        c.GetString("user_id"),
+			ImpersonatorID: impersonatorID,
+			Target:         c.Request.Method + " " + c.Request.URL.Path,
+			Details: map[string]string{
+				"impersonation_id": c.GetString("impersonation_id"),
+				"status":           strconv.Itoa(c.Writer.Status()),
+			},
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingAudit struct {
+	events []models.AuditEvent
+}
+
+func (r *recordingAudit) Record(ctx context.Context, event models.AuditEvent) {
+	r.events = append(r.events, event)
+}
+
+type staticAuth struct {
+	claims models.TokenClaims
+}
+
+func (a staticAuth) ParseToken(ctx context.Context, token string) (models.TokenClaims, error) {
+	return a.claims, nil
+}
+
+func TestAuditMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	recorder := &recordingAudit{}
+	impersonated := staticAuth{claims: models.TokenClaims{UserID: "user1", Role: models.RoleAdmin, ImpersonatorID: "admin1", ImpersonationID: "imp1"}}
+
+	router := gin.New()
+	router.Use(AuditMiddleware(recorder))
+	router.PUT("/tasks/:id", AuthMiddleware(impersonated, nil), func(c *gin.Context) { c.Status(http.StatusOK) })
+	router.GET("/own", AuthMiddleware(staticAuth{claims: models.TokenClaims{UserID: "user1"}}, nil), func(c *gin.Context) { c.Status(http.StatusOK) })
+	router.GET("/admin", AuthMiddleware(impersonated, nil), RequireRole(models.RoleAdmin), func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	send := func(method, path string) int {
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("Authorization", "Bearer token")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusOK, send(http.MethodPut, "/tasks/t1"))
+	assert.Equal(t, http.StatusOK, send(http.MethodGet, "/own"))
+	// административный API недоступен по токену имперсонации
+	assert.Equal(t, http.StatusForbidden, send(http.MethodGet, "/admin"))
+
+	require.Len(t, recorder.events, 2)
+	assert.Equal(t, models.AuditEvent{
+		Action:         models.AuditImpersonatedRequest,
+		ActorID:        "user1",
+		ImpersonatorID: "admin1",
+		Target:         "PUT /tasks/t1",
+		Details:        map[string]string{"impersonation_id": "imp1", "status": "200"},
+	}, recorder.events[0])
+	assert.Equal(t, "GET /admin", recorder.events[1].Target)
+	assert.Equal(t, "403", recorder.events[1].Details["status"])
+}
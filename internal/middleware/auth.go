@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/jmoloko/taskmange/internal/domain/models"
 	"github.com/jmoloko/taskmange/internal/logger"
 )
 
@@ -15,11 +16,18 @@ type userIDKey struct{}
 
 // AuthService интерфейс для аутентификации
 type AuthService interface {
-	ValidateToken(token string) (string, error)
+	ParseToken(ctx context.Context, token string) (models.TokenClaims, error)
 }
 
-// AuthMiddleware проверка JWT
-func AuthMiddleware(authService AuthService) gin.HandlerFunc {
+// UserStatusChecker проверка, что учетная запись не деактивирована администратором
+type UserStatusChecker interface {
+	Active(ctx context.Context, userID string) (bool, error)
+}
+
+// AuthMiddleware проверка JWT. Для токена имперсонации в контекст дополнительно
+// попадают impersonator_id и impersonation_id. statuses может быть nil,
+// тогда деактивация учетных записей не проверяется
+func AuthMiddleware(authService AuthService, statuses UserStatusChecker) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
@@ -39,15 +47,35 @@ func AuthMiddleware(authService AuthService) gin.HandlerFunc {
 		token := parts[1]
 
 		// валидация токена
-		userID, err := authService.ValidateToken(token)
+		claims, err := authService.ParseToken(c.Request.Context(), token)
 		if err != nil {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
 			c.Abort()
 			return
 		}
 
-		// добавление ID user в контекст
-		c.Set("user_id", userID)
+		// токены деактивированного пользователя отклоняются до истечения срока
+		if statuses != nil {
+			active, err := statuses.Active(c.Request.Context(), claims.UserID)
+			if err != nil {
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+				c.Abort()
+				return
+			}
+			if !active {
+				c.JSON(http.StatusForbidden, gin.H{"error": "Account is deactivated"})
+				c.Abort()
+				return
+			}
+		}
+
+		// добавление ID user и роли в контекст
+		c.Set("user_id", claims.UserID)
+		c.Set("role", claims.Role)
+		if claims.ImpersonatorID != "" {
+			c.Set("impersonator_id", claims.ImpersonatorID)
+			c.Set("impersonation_id", claims.ImpersonationID)
+		}
 		c.Next()
 	}
 }
@@ -65,7 +93,7 @@ func Auth(authService AuthService, logger logger.Logger) func(http.Handler) http
 			}
 
 			// Validate token
-			userID, err := authService.ValidateToken(tokenString)
+			claims, err := authService.ParseToken(r.Context(), tokenString)
 			if err != nil {
 				logger.Error("Token validation failed: %v", err)
 				http.Error(w, "Unauthorized", http.StatusUnauthorized)
@@ -73,7 +101,7 @@ func Auth(authService AuthService, logger logger.Logger) func(http.Handler) http
 			}
 
 			// Add user ID to request context
-			ctx := context.WithValue(r.Context(), userIDKey{}, userID)
+			ctx := context.WithValue(r.Context(), userIDKey{}, claims.UserID)
 			next.ServeHTTP(w, r.WithContext(ctx))
 		})
 	}
@@ -102,3 +130,29 @@ func extractTokenFromHeader(r *http.Request) (string, error) {
 
 	return parts[1], nil
 }
+
+// RequireRole пропускает только пользователей, роль которых в токене входит в roles.
+// Токен имперсонации не дает доступа к таким маршрутам, даже если выдан администратором.
+// Должен стоять после AuthMiddleware
+func RequireRole(roles ...string) gin.HandlerFunc {
+	allowed := make(map[string]struct{}, len(roles))
+	for _, role := range roles {
+		allowed[role] = struct{}{}
+	}
+
+	return func(c *gin.Context) {
+		if c.GetString("impersonator_id") != "" {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Not available with an impersonation token"})
+			c.Abort()
+			return
+		}
+
+		if _, ok := allowed[c.GetString("role")]; !ok {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient role"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,58 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/stretchr/testify/assert"
+)
+
+type staticStatuses map[string]bool
+
+func (s staticStatuses) Active(ctx context.Context, userID string) (bool, error) {
+	return s[userID], nil
+}
+
+func TestAuthMiddleware_Deactivated(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	statuses := staticStatuses{"active": true, "blocked": false}
+
+	send := func(userID string) int {
+		router := gin.New()
+		router.GET("/tasks", AuthMiddleware(staticAuth{claims: models.TokenClaims{UserID: userID}}, statuses), func(c *gin.Context) {
+			c.Status(http.StatusOK)
+		})
+		req := httptest.NewRequest(http.MethodGet, "/tasks", nil)
+		req.Header.Set("Authorization", "Bearer token")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusOK, send("active"))
+	assert.Equal(t, http.StatusForbidden, send("blocked"))
+}
+
+func TestRequireRole(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	send := func(claims models.TokenClaims) int {
+		router := gin.New()
+		router.GET("/admin", AuthMiddleware(staticAuth{claims: claims}, nil), RequireRole(models.RoleAdmin), func(c *gin.Context) {
+			c.Status(http.StatusOK)
+		})
+		req := httptest.NewRequest(http.MethodGet, "/admin", nil)
+		req.Header.Set("Authorization", "Bearer token")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusOK, send(models.TokenClaims{UserID: "admin1", Role: models.RoleAdmin}))
+	assert.Equal(t, http.StatusForbidden, send(models.TokenClaims{UserID: "user1", Role: models.RoleUser}))
+	assert.Equal(t, http.StatusForbidden, send(models.TokenClaims{UserID: "user1"}))
+}
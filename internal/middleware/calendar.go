@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CalendarTokenAuthenticator проверка токена подписки календаря
+type CalendarTokenAuthenticator interface {
+	Authenticate(ctx context.Context, token string) (string, error)
+}
+
+// CalendarTokenMiddleware авторизует запрос ленты календаря токеном из параметра token:
+// календари не умеют передавать заголовок Authorization. Без параметра запрос проверяется
+// обычным authenticate. Токен дает только user_id, роль и имперсонация в контекст не попадают
+func CalendarTokenMiddleware(feeds CalendarTokenAuthenticator, statuses UserStatusChecker, authenticate gin.HandlerFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := c.Query("token")
+		if token == "" {
+			authenticate(c)
+			return
+		}
+
+		userID, err := feeds.Authenticate(c.Request.Context(), token)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid calendar token"})
+			c.Abort()
+			return
+		}
+
+		if statuses != nil {
+			active, err := statuses.Active(c.Request.Context(), userID)
+			if err != nil {
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid calendar token"})
+				c.Abort()
+				return
+			}
+			if !active {
+				c.JSON(http.StatusForbidden, gin.H{"error": "Account is deactivated"})
+				c.Abort()
+				return
+			}
+		}
+
+		c.Set("user_id", userID)
+		c.Next()
+	}
+}
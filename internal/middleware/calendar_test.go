@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/stretchr/testify/assert"
+)
+
+type staticCalendarTokens map[string]string
+
+func (s staticCalendarTokens) Authenticate(ctx context.Context, token string) (string, error) {
+	userID, ok := s[token]
+	if !ok {
+		return "", errors.New("invalid calendar token")
+	}
+	return userID, nil
+}
+
+func TestCalendarTokenMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tokens := staticCalendarTokens{"feed1": "user1", "feed2": "blocked"}
+	statuses := staticStatuses{"user1": true, "jwt-user": true}
+	authenticate := AuthMiddleware(staticAuth{claims: models.TokenClaims{UserID: "jwt-user"}}, statuses)
+
+	send := func(target, authorization string) (int, string) {
+		router := gin.New()
+		router.GET("/calendar.ics", CalendarTokenMiddleware(tokens, statuses, authenticate), func(c *gin.Context) {
+			c.String(http.StatusOK, c.GetString("user_id"))
+		})
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code, w.Body.String()
+	}
+
+	code, body := send("/calendar.ics?token=feed1", "")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "user1", body)
+
+	code, _ = send("/calendar.ics?token=unknown", "")
+	assert.Equal(t, http.StatusUnauthorized, code)
+
+	code, _ = send("/calendar.ics?token=feed2", "")
+	assert.Equal(t, http.StatusForbidden, code)
+
+	// без токена запрос проверяется по JWT
+	code, body = send("/calendar.ics", "Bearer token")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "jwt-user", body)
+
+	code, _ = send("/calendar.ics", "")
+	assert.Equal(t, http.StatusUnauthorized, code)
+}
@@ -0,0 +1,85 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoloko/taskmange/internal/metrics"
+)
+
+// ConcurrencyLimitMiddleware ограничивает число одновременно обрабатываемых запросов эндпоинта.
+// Сверх limit запросы ждут в очереди глубиной queueDepth не дольше queueTimeout
+// (0 — пока клиент не отключится); при переполненной очереди или по таймауту отвечаем 503.
+// name — метка эндпоинта в метриках, limit <= 0 отключает ограничение
+func ConcurrencyLimitMiddleware(name string, limit, queueDepth int, queueTimeout time.Duration) gin.HandlerFunc {
+	if limit <= 0 {
+		return func(c *gin.Context) {
+			c.Next()
+		}
+	}
+
+	if queueDepth < 0 {
+		queueDepth = 0
+	}
+
+	slots := make(chan struct{}, limit)
+	// admitted ограничивает общее число запросов: обрабатываемые и ожидающие
+	admitted := make(chan struct{}, limit+queueDepth)
+
+	return func(c *gin.Context) {
+		select {
+		case admitted <- struct{}{}:
+		default:
+			rejectBusy(c, name)
+			return
+		}
+		defer func() { <-admitted }()
+
+		if !acquireSlot(c, name, slots, queueTimeout) {
+			return
+		}
+		defer func() { <-slots }()
+
+		metrics.ConcurrencyInFlight.WithLabelValues(name).Inc()
+		defer metrics.ConcurrencyInFlight.WithLabelValues(name).Dec()
+
+		c.Next()
+	}
+}
+
+// acquireSlot ждет свободный слот, false — запрос уже завершен ответом 503 или отменен клиентом
+func acquireSlot(c *gin.Context, name string, slots chan struct{}, timeout time.Duration) bool {
+	select {
+	case slots <- struct{}{}:
+		return true
+	default:
+	}
+
+	metrics.ConcurrencyQueued.WithLabelValues(name).Inc()
+	defer metrics.ConcurrencyQueued.WithLabelValues(name).Dec()
+
+	var expired <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		expired = timer.C
+	}
+
+	select {
+	case slots <- struct{}{}:
+		return true
+	case <-expired:
+		rejectBusy(c, name)
+		return false
+	case <-c.Request.Context().Done():
+		c.Abort()
+		return false
+	}
+}
+
+func rejectBusy(c *gin.Context, name string) {
+	metrics.ConcurrencyRejectedTotal.WithLabelValues(name).Inc()
+	c.Header("Retry-After", "1")
+	c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"error": "Server is busy, try again later"})
+}
@@ -0,0 +1,89 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoloko/taskmange/internal/metrics"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConcurrencyLimitMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	started := make(chan struct{}, 2)
+	release := make(chan struct{})
+
+	router := gin.New()
+	router.GET("/slow", ConcurrencyLimitMiddleware("test", 1, 1, time.Second), func(c *gin.Context) {
+		started <- struct{}{}
+		<-release
+		c.Status(http.StatusOK)
+	})
+
+	serve := func() int {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/slow", nil))
+		return w.Code
+	}
+
+	var wg sync.WaitGroup
+	codes := make([]int, 2)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		codes[0] = serve()
+	}()
+	<-started
+
+	// второй запрос ждет в очереди
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		codes[1] = serve()
+	}()
+	assert.Eventually(t, func() bool {
+		return testutil.ToFloat64(metrics.ConcurrencyQueued.WithLabelValues("test")) == 1
+	}, time.Second, 5*time.Millisecond)
+
+	// очередь заполнена, третий запрос отклоняется сразу
+	assert.Equal(t, http.StatusServiceUnavailable, serve())
+
+	close(release)
+	wg.Wait()
+	assert.Equal(t, []int{http.StatusOK, http.StatusOK}, codes)
+}
+
+func TestConcurrencyLimitMiddleware_QueueTimeout(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	router := gin.New()
+	router.GET("/slow", ConcurrencyLimitMiddleware("test_timeout", 1, 1, 20*time.Millisecond), func(c *gin.Context) {
+		close(started)
+		<-release
+		c.Status(http.StatusOK)
+	})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/slow", nil))
+	}()
+	<-started
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/slow", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, "1", w.Header().Get("Retry-After"))
+
+	close(release)
+	<-done
+}
@@ -0,0 +1,26 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DisabledRoutesMiddleware отвечает 404 на маршруты отключенных в конфигурации групп.
+// Сравнивается шаблон маршрута (c.FullPath), поэтому /tasks/export не попадает под /tasks/:id.
+// Проверка выполняется до авторизации, отключенная группа не отличается от несуществующей
+func DisabledRoutesMiddleware(prefixes []string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		route := c.FullPath()
+		for _, prefix := range prefixes {
+			if route == prefix || strings.HasPrefix(route, prefix+"/") {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Not found"})
+				c.Abort()
+				return
+			}
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDisabledRoutesMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(DisabledRoutesMiddleware([]string{"/api/tasks/import", "/api/admin"}))
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	router.GET("/api/tasks/:id", ok)
+	router.POST("/api/tasks/import", ok)
+	router.POST("/api/tasks/import/preview", ok)
+	router.GET("/api/tasks/imports", ok)
+	router.GET("/api/admin/usage", ok)
+
+	serve := func(method, path string) int {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusNotFound, serve(http.MethodPost, "/api/tasks/import"))
+	assert.Equal(t, http.StatusNotFound, serve(http.MethodPost, "/api/tasks/import/preview"))
+	assert.Equal(t, http.StatusNotFound, serve(http.MethodGet, "/api/admin/usage"))
+	// совпадение только по целому сегменту пути
+	assert.Equal(t, http.StatusOK, serve(http.MethodGet, "/api/tasks/imports"))
+	assert.Equal(t, http.StatusOK, serve(http.MethodGet, "/api/tasks/import-1"))
+}
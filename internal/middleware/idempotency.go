@@ -0,0 +1,269 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/jmoloko/taskmange/internal/logger"
+)
+
+const (
+	// IdempotencyKeyHeader ключ, по которому повтор запроса получает ответ первого
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// IdempotentReplayedHeader выставляется в ответах, взятых из сохраненных
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+
+	maxIdempotencyKeyLength = 255
+)
+
+// IdempotencyStore хранилище ответов на запросы с Idempotency-Key
+type IdempotencyStore interface {
+	// Begin занимает ключ на lockTTL или возвращает запись, если ключ уже занят
+	Begin(ctx context.Context, key string, lockTTL time.Duration) (*models.IdempotencyRecord, error)
+	Complete(ctx context.Context, key string, record models.IdempotencyRecord, ttl time.Duration) error
+	Release(ctx context.Context, key string) error
+}
+
+// IdempotencyMiddleware делает создающие запросы с заголовком Idempotency-Key идемпотентными:
+// повтор с тем же ключом и телом получает сохраненный ответ первого запроса, а не создает данные заново.
+// Ключи отдельные у каждого пользователя и маршрута, подключается после AuthMiddleware.
+// Ответы 5xx не сохраняются, чтобы запрос можно было повторить. Если хранилище недоступно,
+// запрос выполняется как без заголовка. Тело запроса с ключом ограничено maxBody байтами,
+// ответ больше maxBody отдается клиенту, но не сохраняется
+func IdempotencyMiddleware(store IdempotencyStore, ttl, lockTTL time.Duration, maxBody int64, log logger.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		if key == "" {
+			c.Next()
+			return
+		}
+		if !validIdempotencyKey(key) {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Idempotency-Key must be 1-255 printable ASCII characters"})
+			return
+		}
+
+		storeKey := idempotencyStoreKey(c.GetString("user_id"), c.Request.Method, c.FullPath(), key)
+		existing, err := store.Begin(c.Request.Context(), storeKey, lockTTL)
+		if err != nil {
+			log.Warn("Idempotency store is unavailable: %v", err)
+			c.Next()
+			return
+		}
+
+		if existing != nil && !existing.Completed {
+			c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": "A request with this Idempotency-Key is still in progress"})
+			return
+		}
+
+		body := http.MaxBytesReader(c.Writer, c.Request.Body, maxBody)
+		fingerprint := newPayloadFingerprint(c.GetHeader("Content-Type"))
+		if existing != nil {
+			if _, err := io.Copy(fingerprint, body); err != nil {
+				fingerprint.Sum()
+				abortBodyError(c, err)
+				return
+			}
+			if fingerprint.Sum() != existing.Fingerprint {
+				c.AbortWithStatusJSON(http.StatusUnprocessableEntity, gin.H{"error": "Idempotency-Key was already used with a different request"})
+				return
+			}
+
+			c.Header(IdempotentReplayedHeader, "true")
+			c.Data(existing.Status, existing.ContentType, existing.Body)
+			c.Abort()
+			return
+		}
+
+		// отпечаток тела считается по мере чтения обработчиком, непрочитанный остаток дочитывается после
+		c.Request.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.TeeReader(body, fingerprint), body}
+
+		ctx := context.WithoutCancel(c.Request.Context())
+		writer := &recordingWriter{ResponseWriter: c.Writer, limit: maxBody}
+		c.Writer = writer
+		handled := false
+		defer func() {
+			c.Writer = writer.ResponseWriter
+			// при панике обработчика ключ освобождается, иначе повторы получали бы 409 до истечения lockTTL
+			if !handled {
+				fingerprint.Sum()
+				store.Release(ctx, storeKey)
+			}
+		}()
+		c.Next()
+		handled = true
+		c.Writer = writer.ResponseWriter
+
+		_, drainErr := io.Copy(io.Discard, c.Request.Body)
+		sum := fingerprint.Sum()
+		status := writer.Status()
+		if status >= http.StatusInternalServerError || drainErr != nil || writer.truncated {
+			if drainErr != nil {
+				log.Warn("Failed to read request body for idempotency key: %v", drainErr)
+			}
+			if writer.truncated {
+				log.Warn("Idempotent response exceeds %d bytes and is not saved", maxBody)
+			}
+			if err := store.Release(ctx, storeKey); err != nil {
+				log.Warn("Failed to release idempotency key: %v", err)
+			}
+			return
+		}
+
+		record := models.IdempotencyRecord{
+			Fingerprint: sum,
+			Status:      status,
+			ContentType: writer.Header().Get("Content-Type"),
+			Body:        writer.body.Bytes(),
+		}
+		if err := store.Complete(ctx, storeKey, record, ttl); err != nil {
+			log.Warn("Failed to save idempotent response: %v", err)
+		}
+	}
+}
+
+// abortBodyError 413 для тела больше лимита, 400 для остальных ошибок чтения
+func abortBodyError(c *gin.Context, err error) {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Request body is too large"})
+		return
+	}
+	c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body"})
+}
+
+// payloadFingerprint отпечаток содержимого запроса: тело пишется в него по мере чтения, Sum
+// возвращает SHA-256 в hex и должен быть вызван один раз
+type payloadFingerprint interface {
+	io.Writer
+	Sum() string
+}
+
+// newPayloadFingerprint отпечаток по типу тела. Multipart разбирается на части: граница
+// случайна у каждой отправки, и повтор с тем же файлом дал бы другой хэш сырого тела
+func newPayloadFingerprint(contentType string) payloadFingerprint {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err == nil && mediaType == "multipart/form-data" && params["boundary"] != "" {
+		return newMultipartFingerprint(params["boundary"])
+	}
+	return &rawFingerprint{hash: sha256.New()}
+}
+
+// rawFingerprint хэш тела как есть
+type rawFingerprint struct {
+	hash hash.Hash
+}
+
+func (f *rawFingerprint) Write(p []byte) (int, error) {
+	return f.hash.Write(p)
+}
+
+func (f *rawFingerprint) Sum() string {
+	return hex.EncodeToString(f.hash.Sum(nil))
+}
+
+// multipartFingerprint разбирает поток multipart в отдельной горутине и хэширует имя поля,
+// имя файла и SHA-256 содержимого каждой части. Части читаются потоком, файл в памяти не копится
+type multipartFingerprint struct {
+	pipe *io.PipeWriter
+	sum  chan string
+}
+
+func newMultipartFingerprint(boundary string) *multipartFingerprint {
+	reader, writer := io.Pipe()
+	f := &multipartFingerprint{pipe: writer, sum: make(chan string, 1)}
+	go func() {
+		digest := sha256.New()
+		parts := multipart.NewReader(reader, boundary)
+		for {
+			part, err := parts.NextPart()
+			if err != nil {
+				if err != io.EOF {
+					// тело не разбирается: отпечаток такого запроса не совпадет ни с одним корректным
+					digest.Write([]byte("invalid multipart body"))
+				}
+				break
+			}
+			content := sha256.New()
+			io.Copy(content, part)
+			fmt.Fprintf(digest, "%q %q %x\n", part.FormName(), part.FileName(), content.Sum(nil))
+		}
+		// остаток после последней части или ошибки разбора, иначе запись в pipe зависнет
+		io.Copy(io.Discard, reader)
+		f.sum <- hex.EncodeToString(digest.Sum(nil))
+	}()
+	return f
+}
+
+func (f *multipartFingerprint) Write(p []byte) (int, error) {
+	return f.pipe.Write(p)
+}
+
+func (f *multipartFingerprint) Sum() string {
+	f.pipe.Close()
+	return <-f.sum
+}
+
+// validIdempotencyKey ключ из 1-255 печатных символов ASCII
+func validIdempotencyKey(key string) bool {
+	if len(key) > maxIdempotencyKeyLength {
+		return false
+	}
+	for i := 0; i < len(key); i++ {
+		if key[i] < 0x20 || key[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// idempotencyStoreKey ключ хранилища: {userID}:{SHA-256 метода, маршрута и ключа клиента}
+func idempotencyStoreKey(userID, method, route, key string) string {
+	sum := sha256.Sum256([]byte(method + " " + route + " " + key))
+	return userID + ":" + hex.EncodeToString(sum[:])
+}
+
+// recordingWriter отправляет ответ клиенту и копирует тело для сохранения. Ответ больше limit
+// не копируется дальше, truncated отмечает, что сохранять его нельзя
+type recordingWriter struct {
+	gin.ResponseWriter
+	body      bytes.Buffer
+	limit     int64
+	truncated bool
+}
+
+func (w *recordingWriter) record(data []byte) {
+	if w.truncated {
+		return
+	}
+	if int64(w.body.Len()+len(data)) > w.limit {
+		w.truncated = true
+		w.body.Reset()
+		return
+	}
+	w.body.Write(data)
+}
+
+func (w *recordingWriter) Write(data []byte) (int, error) {
+	w.record(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *recordingWriter) WriteString(s string) (int, error) {
+	w.record([]byte(s))
+	return w.ResponseWriter.WriteString(s)
+}
@@ -0,0 +1,209 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoloko/taskmange/internal/config"
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/jmoloko/taskmange/internal/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memoryIdempotencyStore struct {
+	records map[string]models.IdempotencyRecord
+	err     error
+}
+
+func (s *memoryIdempotencyStore) Begin(ctx context.Context, key string, lockTTL time.Duration) (*models.IdempotencyRecord, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	if record, ok := s.records[key]; ok {
+		return &record, nil
+	}
+	s.records[key] = models.IdempotencyRecord{}
+	return nil, nil
+}
+
+func (s *memoryIdempotencyStore) Complete(ctx context.Context, key string, record models.IdempotencyRecord, ttl time.Duration) error {
+	record.Completed = true
+	s.records[key] = record
+	return nil
+}
+
+func (s *memoryIdempotencyStore) Release(ctx context.Context, key string) error {
+	delete(s.records, key)
+	return nil
+}
+
+func newIdempotencyRouter(store IdempotencyStore, created *int) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	authed := func(c *gin.Context) { c.Set("user_id", c.GetHeader("X-User")) }
+	idempotent := IdempotencyMiddleware(store, time.Hour, time.Minute, testMaxIdempotentBody, logger.NewSLogLogger(config.LoggerConfig{Level: "error"}))
+	router.POST("/tasks", authed, idempotent, func(c *gin.Context) {
+		if c.Query("fail") != "" {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed"})
+			return
+		}
+		*created++
+		if c.Query("large") != "" {
+			c.String(http.StatusCreated, strings.Repeat("x", testMaxIdempotentBody+1))
+			return
+		}
+		c.JSON(http.StatusCreated, gin.H{"id": *created})
+	})
+	// импорт читает multipart потоком, как обработчик импорта файлов
+	router.POST("/imports", authed, idempotent, func(c *gin.Context) {
+		parts, err := c.Request.MultipartReader()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid multipart body"})
+			return
+		}
+		part, err := parts.NextPart()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid multipart body"})
+			return
+		}
+		content, _ := io.ReadAll(part)
+		*created++
+		c.JSON(http.StatusOK, gin.H{"id": *created, "size": len(content)})
+	})
+	return router
+}
+
+const testMaxIdempotentBody = 1 << 10
+
+// postFile отправляет файл в multipart; граница у каждого запроса своя, как у браузера
+func postFile(t *testing.T, router *gin.Engine, key, content string) *httptest.ResponseRecorder {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	require.NoError(t, form.WriteField("source", "todoist"))
+	file, err := form.CreateFormFile("file", "tasks.csv")
+	require.NoError(t, err)
+	_, err = file.Write([]byte(content))
+	require.NoError(t, err)
+	require.NoError(t, form.Close())
+
+	req := httptest.NewRequest(http.MethodPost, "/imports", &body)
+	req.Header.Set("X-User", "user1")
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	req.Header.Set(IdempotencyKeyHeader, key)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func postTask(router *gin.Engine, user, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/tasks", strings.NewReader(body))
+	req.Header.Set("X-User", user)
+	if key != "" {
+		req.Header.Set(IdempotencyKeyHeader, key)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestIdempotencyMiddleware_Replay(t *testing.T) {
+	store := &memoryIdempotencyStore{records: make(map[string]models.IdempotencyRecord)}
+	var created int
+	router := newIdempotencyRouter(store, &created)
+
+	first := postTask(router, "user1", "key-1", `{"title":"a"}`)
+	require.Equal(t, http.StatusCreated, first.Code)
+
+	replay := postTask(router, "user1", "key-1", `{"title":"a"}`)
+	assert.Equal(t, http.StatusCreated, replay.Code)
+	assert.Equal(t, first.Body.String(), replay.Body.String())
+	assert.Equal(t, "true", replay.Header().Get(IdempotentReplayedHeader))
+	assert.Equal(t, first.Header().Get("Content-Type"), replay.Header().Get("Content-Type"))
+	assert.Equal(t, 1, created)
+
+	// ключ другого пользователя и запросы без ключа не связаны с сохраненным ответом
+	assert.Equal(t, `{"id":2}`, postTask(router, "user2", "key-1", `{"title":"a"}`).Body.String())
+	assert.Equal(t, `{"id":3}`, postTask(router, "user1", "", `{"title":"a"}`).Body.String())
+
+	assert.Equal(t, http.StatusUnprocessableEntity, postTask(router, "user1", "key-1", `{"title":"b"}`).Code)
+	assert.Equal(t, http.StatusBadRequest, postTask(router, "user1", strings.Repeat("k", 256), `{}`).Code)
+	assert.Equal(t, http.StatusBadRequest, postTask(router, "user1", "ключ", `{}`).Code)
+	assert.Equal(t, 3, created)
+}
+
+func TestIdempotencyMiddleware_PendingAndFailures(t *testing.T) {
+	store := &memoryIdempotencyStore{records: make(map[string]models.IdempotencyRecord)}
+	var created int
+	router := newIdempotencyRouter(store, &created)
+
+	// первый запрос с ключом еще выполняется
+	store.records[idempotencyStoreKey("user1", http.MethodPost, "/tasks", "busy")] = models.IdempotencyRecord{}
+	assert.Equal(t, http.StatusConflict, postTask(router, "user1", "busy", `{}`).Code)
+
+	// ответ 5xx не сохраняется, повтор выполняется заново
+	req := httptest.NewRequest(http.MethodPost, "/tasks?fail=1", strings.NewReader(`{}`))
+	req.Header.Set("X-User", "user1")
+	req.Header.Set(IdempotencyKeyHeader, "retry")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Equal(t, http.StatusCreated, postTask(router, "user1", "retry", `{}`).Code)
+	assert.Equal(t, 1, created)
+
+	// без хранилища запросы выполняются как без ключа
+	store.err = errors.New("redis is unavailable")
+	assert.Equal(t, http.StatusCreated, postTask(router, "user1", "retry", `{}`).Code)
+	assert.Equal(t, 2, created)
+}
+
+func TestIdempotencyMiddleware_MultipartReplay(t *testing.T) {
+	store := &memoryIdempotencyStore{records: make(map[string]models.IdempotencyRecord)}
+	var created int
+	router := newIdempotencyRouter(store, &created)
+
+	first := postFile(t, router, "import-1", "title\nReport\n")
+	require.Equal(t, http.StatusOK, first.Code)
+
+	// повтор с тем же файлом приходит с другой границей частей, но получает первый ответ
+	replay := postFile(t, router, "import-1", "title\nReport\n")
+	assert.Equal(t, http.StatusOK, replay.Code)
+	assert.Equal(t, "true", replay.Header().Get(IdempotentReplayedHeader))
+	assert.Equal(t, first.Body.String(), replay.Body.String())
+	assert.Equal(t, 1, created)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, postFile(t, router, "import-1", "title\nInvoice\n").Code)
+	assert.Equal(t, 1, created)
+}
+
+func TestIdempotencyMiddleware_Limits(t *testing.T) {
+	store := &memoryIdempotencyStore{records: make(map[string]models.IdempotencyRecord)}
+	var created int
+	router := newIdempotencyRouter(store, &created)
+	large := `{"title":"` + strings.Repeat("a", testMaxIdempotentBody) + `"}`
+
+	// тело больше лимита не сохраняется, а его повтор отклоняется до хэширования остатка
+	postTask(router, "user1", "large-body", large)
+	assert.NotContains(t, store.records, idempotencyStoreKey("user1", http.MethodPost, "/tasks", "large-body"))
+	require.Equal(t, http.StatusCreated, postTask(router, "user1", "body", `{}`).Code)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, postTask(router, "user1", "body", large).Code)
+
+	// ответ больше лимита отдается клиенту, но не сохраняется: повтор выполняется заново
+	req := httptest.NewRequest(http.MethodPost, "/tasks?large=1", strings.NewReader(`{}`))
+	req.Header.Set("X-User", "user1")
+	req.Header.Set(IdempotencyKeyHeader, "large-response")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Len(t, w.Body.String(), testMaxIdempotentBody+1)
+	assert.NotContains(t, store.records, idempotencyStoreKey("user1", http.MethodPost, "/tasks", "large-response"))
+}
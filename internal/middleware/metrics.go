@@ -24,6 +24,10 @@ func MetricsMiddleware() gin.HandlerFunc {
 			status,
 		).Inc()
 
+		// длительность потока событий — время подключения, в гистограмме она бы исказила квантили
+		if isStream(c) {
+			return
+		}
 		metrics.HttpRequestDuration.WithLabelValues(
 			c.Request.Method,
 			c.FullPath(),
@@ -2,6 +2,7 @@ package middleware
 
 import (
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -16,23 +17,33 @@ func LoggerMiddleware(log logger.Logger) gin.HandlerFunc {
 		// Вызываем следующий обработчик
 		c.Next()
 
-		// Логируем запрос
+		// Логируем запрос логгером запроса, чтобы запись содержала request_id
 		duration := time.Since(start)
-		log.Info("HTTP %s %s %d %s",
+		logger.FromContext(c.Request.Context(), log).Info("HTTP %s %s %d %s",
 			c.Request.Method,
-			c.Request.URL.Path,
+			redactedPath(c),
 			c.Writer.Status(),
 			duration,
 		)
 	}
 }
 
+// redactedPath путь запроса без секретов: значение параметра :token заменяется на "***"
+func redactedPath(c *gin.Context) string {
+	path := c.Request.URL.Path
+	if token := c.Param("token"); token != "" {
+		path = strings.Replace(path, token, "***", 1)
+	}
+	return path
+}
+
 // CORSMiddleware создает middleware для установки CORS-заголовков
 func CORSMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
-		c.Writer.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		c.Writer.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
+		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, "+APIVersionHeader+", "+IdempotencyKeyHeader+", "+RequestIDHeader)
+		c.Writer.Header().Set("Access-Control-Expose-Headers", APIVersionHeader+", X-Total-Count, X-Next-Cursor, X-Next-After-Id, X-Next-After-Due, "+IdempotentReplayedHeader+", "+RequestIDHeader)
 
 		if c.Request.Method == http.MethodOptions {
 			c.AbortWithStatus(http.StatusOK)
@@ -48,7 +59,7 @@ func RecoveryMiddleware(log logger.Logger) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		defer func() {
 			if err := recover(); err != nil {
-				log.Error("Panic recovered: %v", err)
+				logger.FromContext(c.Request.Context(), log).Error("Panic recovered: %v", err)
 				c.AbortWithStatus(http.StatusInternalServerError)
 			}
 		}()
@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCORSMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(CORSMiddleware())
+	router.GET("/tasks", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	// preflight отвечает сразу, до обработчика
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodOptions, "/tasks", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "*", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Contains(t, w.Header().Get("Access-Control-Allow-Headers"), IdempotencyKeyHeader)
+
+	// заголовки ответа, которые читает браузерный клиент, иначе они скрыты от JavaScript
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/tasks", nil))
+	exposed := strings.Split(w.Header().Get("Access-Control-Expose-Headers"), ", ")
+	for _, header := range []string{APIVersionHeader, "X-Total-Count", "X-Next-Cursor", "X-Next-After-Id", "X-Next-After-Due",
+		IdempotentReplayedHeader, RequestIDHeader} {
+		assert.Contains(t, exposed, header)
+	}
+}
@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MovedRouteMiddleware прежний путь ресурса, перенесенного в target. С версии API since
+// запрос перенаправляется на target с 308, который сохраняет метод и тело; в более ранних версиях
+// обрабатывается как раньше, а заголовки Deprecation и Link сообщают клиенту новый путь
+func MovedRouteMiddleware(target string, since int) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		location := target
+		if query := c.Request.URL.RawQuery; query != "" {
+			location += "?" + query
+		}
+
+		if APIVersion(c) >= since {
+			c.Redirect(http.StatusPermanentRedirect, location)
+			c.Abort()
+			return
+		}
+
+		c.Header("Deprecation", "true")
+		c.Header("Link", fmt.Sprintf("<%s>; rel=\"successor-version\"", target))
+		c.Next()
+	}
+}
@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMovedRouteMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(APIVersionMiddleware(APIVersion1))
+	router.POST("/api/tasks/import", MovedRouteMiddleware("/api/task-imports", APIVersion4), func(c *gin.Context) {
+		c.String(http.StatusOK, "imported")
+	})
+
+	serve := func(version int) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/tasks/import?skip_invalid=true", strings.NewReader("[]"))
+		req.Header.Set(APIVersionHeader, strconv.Itoa(version))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	// старые версии обрабатываются по прежнему пути и узнают новый из заголовков
+	w := serve(APIVersion3)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "imported", w.Body.String())
+	assert.Equal(t, "true", w.Header().Get("Deprecation"))
+	assert.Equal(t, `</api/task-imports>; rel="successor-version"`, w.Header().Get("Link"))
+
+	w = serve(APIVersion4)
+	assert.Equal(t, http.StatusPermanentRedirect, w.Code)
+	assert.Equal(t, "/api/task-imports?skip_invalid=true", w.Header().Get("Location"))
+	assert.NotContains(t, w.Body.String(), "imported")
+}
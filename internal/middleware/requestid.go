@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/jmoloko/taskmange/internal/logger"
+	"github.com/jmoloko/taskmange/internal/requestid"
+)
+
+// RequestIDHeader идентификатор запроса в запросе и ответе
+const RequestIDHeader = "X-Request-ID"
+
+// RequestIDMiddleware присваивает запросу идентификатор: берет корректный X-Request-ID клиента
+// или прокси, иначе создает новый. Идентификатор возвращается в ответе, доступен в gin-контексте
+// как request_id, а в контексте запроса — через requestid.FromContext и логгер logger.FromContext
+// с полем request_id. Подключается первым, чтобы его видели все middleware
+func RequestIDMiddleware(log logger.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if !requestid.Valid(id) {
+			id = requestid.New()
+		}
+
+		c.Set("request_id", id)
+		c.Header(RequestIDHeader, id)
+
+		ctx := requestid.NewContext(c.Request.Context(), id)
+		ctx = logger.NewContext(ctx, log.WithFields(map[string]interface{}{"request_id": id}))
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+	}
+}
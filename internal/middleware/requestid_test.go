@@ -0,0 +1,65 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoloko/taskmange/internal/logger"
+	"github.com/jmoloko/taskmange/internal/requestid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fieldsLogger запоминает поля, добавленные через WithFields
+type fieldsLogger struct {
+	logger.MockLogger
+	fields map[string]interface{}
+}
+
+func (l *fieldsLogger) WithFields(fields map[string]interface{}) logger.Logger {
+	return &fieldsLogger{fields: fields}
+}
+
+func TestRequestIDMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var ginID, contextID string
+	var requestLogger logger.Logger
+	router := gin.New()
+	router.Use(RequestIDMiddleware(&fieldsLogger{}))
+	router.GET("/", func(c *gin.Context) {
+		ginID = c.GetString("request_id")
+		contextID = requestid.FromContext(c.Request.Context())
+		requestLogger = logger.FromContext(c.Request.Context(), nil)
+		c.Status(http.StatusOK)
+	})
+
+	serve := func(header string) string {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		if header != "" {
+			req.Header.Set(RequestIDHeader, header)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Header().Get(RequestIDHeader)
+	}
+
+	// идентификатор клиента сохраняется
+	assert.Equal(t, "edge-42", serve("edge-42"))
+	assert.Equal(t, "edge-42", ginID)
+	assert.Equal(t, "edge-42", contextID)
+	require.IsType(t, &fieldsLogger{}, requestLogger)
+	assert.Equal(t, map[string]interface{}{"request_id": "edge-42"}, requestLogger.(*fieldsLogger).fields)
+
+	// без заголовка и с некорректным значением создается новый
+	generated := serve("")
+	assert.True(t, requestid.Valid(generated))
+	assert.Equal(t, generated, contextID)
+
+	replaced := serve("bad id\r\n")
+	assert.NotEqual(t, "bad id\r\n", replaced)
+	assert.True(t, requestid.Valid(replaced))
+	assert.NotEqual(t, generated, replaced)
+}
@@ -0,0 +1,157 @@
+package middleware
+
+import (
+	"database/sql"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoloko/taskmange/internal/metrics"
+)
+
+const (
+	// sheddingWindow за какой период считается p99
+	sheddingWindow = time.Minute
+	// sheddingMaxSamples сколько последних запросов хранится для p99
+	sheddingMaxSamples = 2048
+	// sheddingMinSamples меньше этого числа запросов p99 не считается
+	sheddingMinSamples = 50
+	// sheddingEvalInterval как часто пересчитывается состояние перегрузки
+	sheddingEvalInterval = time.Second
+)
+
+type latencySample struct {
+	at       time.Time
+	duration time.Duration
+}
+
+// LoadShedder определяет перегрузку по p99 длительности запросов и загрузке пула Postgres
+// и при перегрузке отклоняет низкоприоритетные запросы
+type LoadShedder struct {
+	latencyThreshold time.Duration
+	poolThreshold    float64
+	poolStats        func() sql.DBStats
+
+	mu          sync.Mutex
+	samples     []latencySample
+	next        int
+	evaluatedAt time.Time
+	overloaded  bool
+	now         func() time.Time
+}
+
+// NewLoadShedder создает новый экземпляр LoadShedder.
+// Нулевой порог отключает соответствующую проверку, poolStats может быть nil
+func NewLoadShedder(latencyThreshold time.Duration, poolThreshold float64, poolStats func() sql.DBStats) *LoadShedder {
+	return &LoadShedder{
+		latencyThreshold: latencyThreshold,
+		poolThreshold:    poolThreshold,
+		poolStats:        poolStats,
+		samples:          make([]latencySample, 0, sheddingMaxSamples),
+		now:              time.Now,
+	}
+}
+
+// Observe middleware, которое учитывает длительность всех обработанных запросов.
+// Отклоненные запросы не учитываются, чтобы не занижать p99, потоки событий — чтобы не завышать
+func (s *LoadShedder) Observe() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := s.now()
+
+		c.Next()
+
+		if c.IsAborted() && c.Writer.Status() == http.StatusServiceUnavailable || isStream(c) {
+			return
+		}
+		s.record(start, s.now().Sub(start))
+	}
+}
+
+// Shed middleware для низкоприоритетного эндпоинта: при перегрузке отвечает 503.
+// name — метка эндпоинта в метриках
+func (s *LoadShedder) Shed(name string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !s.Overloaded() {
+			c.Next()
+			return
+		}
+
+		metrics.RequestsShedTotal.WithLabelValues(name).Inc()
+		c.Header("Retry-After", "5")
+		c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"error": "Server is overloaded, try again later"})
+	}
+}
+
+// Overloaded сообщает, превышен ли хотя бы один порог. Состояние пересчитывается не чаще раза в секунду
+func (s *LoadShedder) Overloaded() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if now.Sub(s.evaluatedAt) < sheddingEvalInterval {
+		return s.overloaded
+	}
+	s.evaluatedAt = now
+
+	p99 := s.p99(now)
+	metrics.RequestLatencyP99.Set(p99.Seconds())
+
+	saturation := s.poolSaturation()
+	metrics.DBPoolSaturation.Set(saturation)
+
+	s.overloaded = (s.latencyThreshold > 0 && p99 >= s.latencyThreshold) ||
+		(s.poolThreshold > 0 && saturation >= s.poolThreshold)
+	if s.overloaded {
+		metrics.SheddingActive.Set(1)
+	} else {
+		metrics.SheddingActive.Set(0)
+	}
+
+	return s.overloaded
+}
+
+func (s *LoadShedder) record(at time.Time, duration time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sample := latencySample{at: at, duration: duration}
+	if len(s.samples) < sheddingMaxSamples {
+		s.samples = append(s.samples, sample)
+		return
+	}
+	s.samples[s.next] = sample
+	s.next = (s.next + 1) % sheddingMaxSamples
+}
+
+// p99 по запросам за последнюю минуту, вызывается под s.mu
+func (s *LoadShedder) p99(now time.Time) time.Duration {
+	durations := make([]time.Duration, 0, len(s.samples))
+	for _, sample := range s.samples {
+		if now.Sub(sample.at) <= sheddingWindow {
+			durations = append(durations, sample.duration)
+		}
+	}
+
+	if len(durations) < sheddingMinSamples {
+		return 0
+	}
+
+	sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+	return durations[(len(durations)*99-1)/100]
+}
+
+// poolSaturation доля занятых соединений пула, 0 — если размер пула не ограничен
+func (s *LoadShedder) poolSaturation() float64 {
+	if s.poolStats == nil {
+		return 0
+	}
+
+	stats := s.poolStats()
+	if stats.MaxOpenConnections <= 0 {
+		return 0
+	}
+
+	return float64(stats.InUse) / float64(stats.MaxOpenConnections)
+}
@@ -0,0 +1,71 @@
+package middleware
+
+import (
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadShedder_Latency(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	shedder := NewLoadShedder(time.Second, 0, nil)
+	shedder.now = func() time.Time { return now }
+
+	for i := 0; i < sheddingMinSamples; i++ {
+		shedder.record(now, 10*time.Millisecond)
+	}
+	assert.False(t, shedder.Overloaded())
+
+	for i := 0; i < sheddingMinSamples; i++ {
+		shedder.record(now, 2*time.Second)
+	}
+	// состояние пересчитывается не чаще раза в секунду
+	assert.False(t, shedder.Overloaded())
+
+	now = now.Add(sheddingEvalInterval)
+	assert.True(t, shedder.Overloaded())
+
+	// медленные запросы выходят из окна
+	now = now.Add(sheddingWindow + time.Second)
+	assert.False(t, shedder.Overloaded())
+}
+
+func TestLoadShedder_Shed(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	stats := sql.DBStats{MaxOpenConnections: 10, InUse: 5}
+	shedder := NewLoadShedder(0, 0.9, func() sql.DBStats { return stats })
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	shedder.now = func() time.Time { return now }
+
+	router := gin.New()
+	router.Use(shedder.Observe())
+	router.GET("/analytics", shedder.Shed("analytics"), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	router.GET("/tasks", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	serve := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w
+	}
+
+	assert.Equal(t, http.StatusOK, serve("/analytics").Code)
+
+	stats.InUse = 9
+	now = now.Add(sheddingEvalInterval)
+	w := serve("/analytics")
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, "5", w.Header().Get("Retry-After"))
+
+	// CRUD не сбрасывается
+	assert.Equal(t, http.StatusOK, serve("/tasks").Code)
+}
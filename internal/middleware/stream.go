@@ -0,0 +1,21 @@
+package middleware
+
+import "github.com/gin-gonic/gin"
+
+// streamKey ключ контекста gin, которым помечены потоковые маршруты
+const streamKey = "stream"
+
+// StreamMiddleware помечает маршрут как длительный поток событий (SSE). Длительность такого запроса —
+// время подключения клиента, а не обработки, поэтому она не попадает ни в p99 сброса нагрузки,
+// ни в гистограмму длительности запросов
+func StreamMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(streamKey, true)
+		c.Next()
+	}
+}
+
+// isStream помечен ли запрос как поток
+func isStream(c *gin.Context) bool {
+	return c.GetBool(streamKey)
+}
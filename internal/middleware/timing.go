@@ -0,0 +1,95 @@
+package middleware
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoloko/taskmange/internal/timing"
+)
+
+// ServerTimingHeader заголовок с разбивкой времени обработки запроса
+const ServerTimingHeader = "Server-Timing"
+
+// ServerTimingMiddleware добавляет в ответ заголовок Server-Timing: время запросов к Postgres (db),
+// команд Redis (cache), остальной обработки (service) и общее время (total). Заголовок отправляется
+// вместе с первыми байтами ответа, поэтому у потоковых ответов учитывает только время до них
+func ServerTimingMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := timing.NewContext(c.Request.Context())
+		c.Request = c.Request.WithContext(ctx)
+
+		writer := &timingWriter{ResponseWriter: c.Writer, start: time.Now()}
+		writer.header = func() string { return serverTiming(timing.FromContext(ctx), time.Since(writer.start)) }
+		c.Writer = writer
+		c.Writer.Header().Set("Timing-Allow-Origin", "*")
+
+		defer func() { c.Writer = writer.ResponseWriter }()
+		c.Next()
+		// ответ без тела (204, c.Status) заголовки еще не отправил
+		writer.setHeader()
+	}
+}
+
+// serverTiming значение заголовка: db;dur=12.3;desc="4 queries", cache;dur=0.8;desc="2 commands", service;dur=5.1, total;dur=18.2
+func serverTiming(entries []timing.Entry, total time.Duration) string {
+	parts := make([]string, 0, len(entries)+2)
+	self := total
+	for _, entry := range entries {
+		unit := "queries"
+		if entry.Name == timing.Cache {
+			unit = "commands"
+		}
+		parts = append(parts, fmt.Sprintf(`%s;dur=%s;desc="%d %s"`, entry.Name, millis(entry.Duration), entry.Count, unit))
+		self -= entry.Duration
+	}
+	// операции могут идти параллельно, и их сумма превышает общее время
+	if self < 0 {
+		self = 0
+	}
+	parts = append(parts, "service;dur="+millis(self), "total;dur="+millis(total))
+
+	return strings.Join(parts, ", ")
+}
+
+func millis(d time.Duration) string {
+	return fmt.Sprintf("%.1f", float64(d)/float64(time.Millisecond))
+}
+
+// timingWriter выставляет Server-Timing перед отправкой заголовков ответа
+type timingWriter struct {
+	gin.ResponseWriter
+	start  time.Time
+	header func() string
+	once   sync.Once
+}
+
+func (w *timingWriter) setHeader() {
+	w.once.Do(func() {
+		if !w.ResponseWriter.Written() {
+			w.ResponseWriter.Header().Set(ServerTimingHeader, w.header())
+		}
+	})
+}
+
+func (w *timingWriter) WriteHeaderNow() {
+	w.setHeader()
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+func (w *timingWriter) Write(data []byte) (int, error) {
+	w.setHeader()
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *timingWriter) WriteString(s string) (int, error) {
+	w.setHeader()
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *timingWriter) Flush() {
+	w.setHeader()
+	w.ResponseWriter.Flush()
+}
@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoloko/taskmange/internal/timing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServerTimingMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(ServerTimingMiddleware())
+	router.GET("/tasks", func(c *gin.Context) {
+		timing.Add(c.Request.Context(), timing.DB, 12*time.Millisecond)
+		timing.Add(c.Request.Context(), timing.DB, 3*time.Millisecond)
+		timing.Add(c.Request.Context(), timing.Cache, 500*time.Microsecond)
+		c.JSON(http.StatusOK, gin.H{"id": "task1"})
+	})
+	router.DELETE("/tasks", func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/tasks", nil))
+	header := w.Header().Get(ServerTimingHeader)
+	assert.Contains(t, header, `db;dur=15.0;desc="2 queries", cache;dur=0.5;desc="1 commands", service;dur=`)
+	assert.Contains(t, header, ", total;dur=")
+	assert.Equal(t, "*", w.Header().Get("Timing-Allow-Origin"))
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/tasks", nil))
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Regexp(t, `^service;dur=\d+\.\d, total;dur=\d+\.\d$`, w.Header().Get(ServerTimingHeader))
+}
+
+func TestServerTiming(t *testing.T) {
+	entries := []timing.Entry{{Name: timing.DB, Duration: 30 * time.Millisecond, Count: 4}}
+
+	assert.Equal(t, `db;dur=30.0;desc="4 queries", service;dur=10.0, total;dur=40.0`, serverTiming(entries, 40*time.Millisecond))
+	// параллельные запросы дольше общего времени
+	assert.Equal(t, `db;dur=30.0;desc="4 queries", service;dur=0.0, total;dur=20.0`, serverTiming(entries, 20*time.Millisecond))
+}
@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoloko/taskmange/internal/logger"
+	"github.com/jmoloko/taskmange/internal/requestid"
+	"github.com/jmoloko/taskmange/internal/tracing"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TracingMiddleware начинает span HTTP-запроса, продолжая трассу из заголовка traceparent.
+// Span сервисов, Postgres и Redis вкладываются в него через контекст запроса, а логгер запроса
+// получает поле trace_id. Подключается после RequestIDMiddleware
+func TracingMiddleware(log logger.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := otel.GetTextMapPropagator().Extract(c.Request.Context(), propagation.HeaderCarrier(c.Request.Header))
+
+		name := c.Request.Method
+		route := c.FullPath()
+		if route != "" {
+			name += " " + route
+		}
+		ctx, span := tracing.Start(ctx, name, trace.WithSpanKind(trace.SpanKindServer), trace.WithAttributes(
+			attribute.String("http.request.method", c.Request.Method),
+			attribute.String("http.route", route),
+			attribute.String("url.path", redactedPath(c)),
+			attribute.String("request.id", requestid.FromContext(ctx)),
+		))
+		defer span.End()
+
+		if spanContext := span.SpanContext(); spanContext.IsValid() {
+			requestLogger := logger.FromContext(ctx, log).WithFields(map[string]interface{}{"trace_id": spanContext.TraceID().String()})
+			ctx = logger.NewContext(ctx, requestLogger)
+		}
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+
+		status := c.Writer.Status()
+		span.SetAttributes(attribute.Int("http.response.status_code", status))
+		if status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(status))
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// UsageRecorder учет запросов пользователей
+type UsageRecorder interface {
+	Record(ctx context.Context, userID string, failed bool)
+}
+
+// UsageMiddleware учитывает запросы аутентифицированных пользователей после обработки.
+// Подключается глобально: user_id выставляет AuthMiddleware группы маршрутов, ошибка — ответ 4xx или 5xx
+func UsageMiddleware(recorder UsageRecorder) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		userID := c.GetString("user_id")
+		if userID == "" {
+			return
+		}
+
+		recorder.Record(context.WithoutCancel(c.Request.Context()), userID, c.Writer.Status() >= http.StatusBadRequest)
+	}
+}
@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+type usageCall struct {
+	userID string
+	failed bool
+}
+
+type recordingUsage struct {
+	calls []usageCall
+}
+
+func (r *recordingUsage) Record(ctx context.Context, userID string, failed bool) {
+	r.calls = append(r.calls, usageCall{userID: userID, failed: failed})
+}
+
+func TestUsageMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	recorder := &recordingUsage{}
+
+	router := gin.New()
+	router.Use(UsageMiddleware(recorder))
+	authed := func(c *gin.Context) { c.Set("user_id", "user1") }
+	router.GET("/ok", authed, func(c *gin.Context) { c.Status(http.StatusOK) })
+	router.GET("/missing", authed, func(c *gin.Context) { c.Status(http.StatusNotFound) })
+	router.GET("/public", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	for _, path := range []string{"/ok", "/missing", "/public"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	assert.Equal(t, []usageCall{{userID: "user1"}, {userID: "user1", failed: true}}, recorder.calls)
+}
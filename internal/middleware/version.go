@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// APIVersionHeader заголовок, которым клиент выбирает версию API; в ответе в нем же
+// возвращается версия, по которой обработан запрос
+const APIVersionHeader = "API-Version"
+
+// Версии API. Новая версия меняет только поведение, несовместимое со старыми клиентами:
+//   - 2: DELETE /api/tasks/{id} отвечает 204 без тела вместо 200 с сообщением
+//   - 3: GET /api/tasks отвечает страницей {tasks, total, next_cursor} вместо массива задач
+//   - 4: /api/tasks/import, /api/tasks/export и /api/tasks/analytics перенаправляются с 308
+//     на /api/task-imports, /api/task-exports и /api/task-analytics
+const (
+	APIVersion1      = 1
+	APIVersion2      = 2
+	APIVersion3      = 3
+	APIVersion4      = 4
+	LatestAPIVersion = APIVersion4
+)
+
+// APIVersionMiddleware определяет версию API запроса по заголовку API-Version.
+// Без заголовка используется defaultVersion, неизвестная версия отклоняется с 400
+func APIVersionMiddleware(defaultVersion int) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		version := defaultVersion
+		if header := strings.TrimSpace(c.GetHeader(APIVersionHeader)); header != "" {
+			parsed, err := strconv.Atoi(header)
+			if err != nil || parsed < APIVersion1 || parsed > LatestAPIVersion {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Unsupported API version"})
+				c.Abort()
+				return
+			}
+			version = parsed
+		}
+
+		c.Set("api_version", version)
+		c.Header(APIVersionHeader, strconv.Itoa(version))
+		c.Next()
+	}
+}
+
+// APIVersion версия API текущего запроса; без APIVersionMiddleware — первая
+func APIVersion(c *gin.Context) int {
+	if version := c.GetInt("api_version"); version > 0 {
+		return version
+	}
+	return APIVersion1
+}
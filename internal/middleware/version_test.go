@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIVersionMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(APIVersionMiddleware(APIVersion1))
+	router.GET("/api/version", func(c *gin.Context) {
+		c.String(http.StatusOK, strconv.Itoa(APIVersion(c)))
+	})
+
+	serve := func(header string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/version", nil)
+		if header != "" {
+			req.Header.Set(APIVersionHeader, header)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := serve("")
+	assert.Equal(t, "1", w.Body.String())
+	assert.Equal(t, "1", w.Header().Get(APIVersionHeader))
+
+	w = serve("2")
+	assert.Equal(t, "2", w.Body.String())
+	assert.Equal(t, "2", w.Header().Get(APIVersionHeader))
+
+	for _, header := range []string{"0", "5", "v2"} {
+		assert.Equal(t, http.StatusBadRequest, serve(header).Code, header)
+	}
+}
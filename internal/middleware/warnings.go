@@ -0,0 +1,82 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/jmoloko/taskmange/internal/warnings"
+)
+
+// WarningsMiddleware добавляет предупреждения сервисов в ответы изменяющих запросов.
+// Ответ буферизуется; если он JSON-объект и сервисы добавили предупреждения, в него дописывается поле warnings
+func WarningsMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			c.Next()
+			return
+		}
+
+		ctx := warnings.NewContext(c.Request.Context())
+		c.Request = c.Request.WithContext(ctx)
+
+		writer := &bufferedWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+		// при панике RecoveryMiddleware пишет ответ уже в исходный writer
+		defer func() { c.Writer = writer.ResponseWriter }()
+		c.Next()
+		c.Writer = writer.ResponseWriter
+
+		body := writer.body.Bytes()
+		if list := warnings.FromContext(ctx); len(list) > 0 &&
+			strings.HasPrefix(c.Writer.Header().Get("Content-Type"), "application/json") {
+			body = withWarnings(body, list)
+		}
+
+		if len(body) > 0 {
+			c.Writer.Write(body)
+		}
+	}
+}
+
+// withWarnings дописывает поле warnings в JSON-объект, остальные ответы не меняет
+func withWarnings(body []byte, list []models.Warning) []byte {
+	trimmed := bytes.TrimRight(body, " \r\n\t")
+	if len(trimmed) < 2 || trimmed[0] != '{' || trimmed[len(trimmed)-1] != '}' {
+		return body
+	}
+
+	data, err := json.Marshal(list)
+	if err != nil {
+		return body
+	}
+
+	var out bytes.Buffer
+	out.Write(trimmed[:len(trimmed)-1])
+	if len(bytes.TrimSpace(trimmed[1:len(trimmed)-1])) > 0 {
+		out.WriteByte(',')
+	}
+	out.WriteString(`"warnings":`)
+	out.Write(data)
+	out.WriteByte('}')
+
+	return out.Bytes()
+}
+
+// bufferedWriter копит тело ответа до окончания обработки запроса
+type bufferedWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *bufferedWriter) Write(data []byte) (int, error) {
+	return w.body.Write(data)
+}
+
+func (w *bufferedWriter) WriteString(s string) (int, error) {
+	return w.body.WriteString(s)
+}
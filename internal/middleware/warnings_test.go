@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/jmoloko/taskmange/internal/warnings"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWarningsMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	warn := models.Warning{Code: "quota.open_tasks", Message: "9 of 10 open tasks used", Used: 9, Limit: 10}
+
+	router := gin.New()
+	router.Use(WarningsMiddleware())
+	router.POST("/tasks", func(c *gin.Context) {
+		warnings.Add(c.Request.Context(), warn)
+		c.JSON(http.StatusCreated, gin.H{"id": "task1"})
+	})
+	router.POST("/empty", func(c *gin.Context) {
+		warnings.Add(c.Request.Context(), warn)
+		c.JSON(http.StatusOK, gin.H{})
+	})
+	router.POST("/plain", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"id": "task1"})
+	})
+	router.GET("/tasks", func(c *gin.Context) {
+		warnings.Add(c.Request.Context(), warn)
+		c.JSON(http.StatusOK, gin.H{"id": "task1"})
+	})
+
+	serve := func(method, path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(method, path, strings.NewReader("")))
+		return w
+	}
+
+	w := serve(http.MethodPost, "/tasks")
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.JSONEq(t, `{"id":"task1","warnings":[{"code":"quota.open_tasks","message":"9 of 10 open tasks used","used":9,"limit":10}]}`, w.Body.String())
+
+	w = serve(http.MethodPost, "/empty")
+	assert.JSONEq(t, `{"warnings":[{"code":"quota.open_tasks","message":"9 of 10 open tasks used","used":9,"limit":10}]}`, w.Body.String())
+
+	w = serve(http.MethodPost, "/plain")
+	assert.JSONEq(t, `{"id":"task1"}`, w.Body.String())
+
+	w = serve(http.MethodGet, "/tasks")
+	assert.JSONEq(t, `{"id":"task1"}`, w.Body.String())
+}
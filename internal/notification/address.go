@@ -0,0 +1,68 @@
+package notification
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// ErrForbiddenTarget адрес получателя указывает на сам сервер или во внутреннюю сеть
+var ErrForbiddenTarget = errors.New("target address is not allowed")
+
+// ValidateTarget проверяет URL получателя при регистрации: схема http(s), хост не localhost
+// и не внутренний IP. Имена хостов не резолвятся, адрес после резолва проверяет dialer
+// при каждой отправке, так что смена DNS-записи после регистрации ничего не дает
+func ValidateTarget(raw string) error {
+	target, err := url.Parse(raw)
+	if err != nil || target.Hostname() == "" || (target.Scheme != "https" && target.Scheme != "http") {
+		return fmt.Errorf("%w: %q is not an http(s) URL", ErrForbiddenTarget, raw)
+	}
+
+	host := strings.TrimSuffix(strings.ToLower(target.Hostname()), ".")
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return fmt.Errorf("%w: %s", ErrForbiddenTarget, host)
+	}
+	if ip, err := netip.ParseAddr(host); err == nil && forbiddenIP(ip) {
+		return fmt.Errorf("%w: %s", ErrForbiddenTarget, ip)
+	}
+
+	return nil
+}
+
+// forbiddenIP loopback, частные (RFC 1918, IPv6 ULA), link-local, в том числе адрес
+// метаданных облака 169.254.169.254, и unspecified адреса
+func forbiddenIP(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() || ip.IsUnspecified()
+}
+
+// NewTargetClient HTTP-клиент для адресов, заданных пользователями. Адрес проверяется
+// в момент соединения, после резолва, поэтому редиректы и rebinding DNS не уводят запрос
+// во внутреннюю сеть. Прокси из окружения не используется: через него проверка теряет смысл
+func NewTargetClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{Timeout: timeout, Control: checkDialAddress}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{Timeout: timeout, Transport: transport}
+}
+
+// checkDialAddress отклоняет соединение с запрещенным адресом
+func checkDialAddress(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrForbiddenTarget, address)
+	}
+	ip, err := netip.ParseAddr(host)
+	if err != nil || forbiddenIP(ip) {
+		return fmt.Errorf("%w: %s", ErrForbiddenTarget, host)
+	}
+	return nil
+}
@@ -0,0 +1,254 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jmoloko/taskmange/internal/clock"
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/jmoloko/taskmange/internal/domain/repository"
+	domainService "github.com/jmoloko/taskmange/internal/domain/service"
+	"github.com/jmoloko/taskmange/internal/logger"
+	"github.com/jmoloko/taskmange/internal/metrics"
+)
+
+const (
+	// событие дайджеста
+	eventDigest = "digest"
+	// сколько уведомлений перечислять в тексте дайджеста
+	digestMaxLines = 5
+	// формат времени тихих часов
+	clockLayout = "15:04"
+)
+
+// Dispatcher единая точка доставки уведомлений пользователю.
+// Применяет настройки пользователя: отбрасывает события, на которые он не подписан,
+// откладывает уведомления в буфер на окно дайджеста и до окончания тихих часов,
+// и рассылает их по включенным каналам. Каналы без зарегистрированного Notifier пропускаются
+type Dispatcher struct {
+	channels map[models.NotificationChannel]domainService.Notifier
+	buffer   repository.NotificationBuffer
+	prefs    repository.NotificationPreferencesRepository
+	defaults models.NotificationPreferences
+	clock    clock.Clock
+	logger   logger.Logger
+}
+
+// NewDispatcher создает новый экземпляр Dispatcher.
+// defaults — настройки пользователей, которые ничего не настраивали
+func NewDispatcher(channels map[models.NotificationChannel]domainService.Notifier, buffer repository.NotificationBuffer, prefs repository.NotificationPreferencesRepository, defaults models.NotificationPreferences, logger logger.Logger) *Dispatcher {
+	return &Dispatcher{
+		channels: channels,
+		buffer:   buffer,
+		prefs:    prefs,
+		defaults: defaults,
+		clock:    clock.System,
+		logger:   logger,
+	}
+}
+
+// Notify отправляет уведомление сразу или откладывает его в дайджест
+func (d *Dispatcher) Notify(ctx context.Context, userID string, notification models.Notification) error {
+	prefs, err := d.preferences(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if !prefs.AllowsEvent(notification.Event) || len(prefs.Channels) == 0 {
+		return nil
+	}
+
+	now := d.clock.Now()
+	flushAt := now.Add(time.Duration(prefs.DigestWindowMinutes) * time.Minute)
+	if end, ok := QuietHoursEnd(prefs, now); ok && end.After(flushAt) {
+		flushAt = end
+	}
+
+	if !flushAt.After(now) {
+		return d.deliver(ctx, userID, prefs, notification)
+	}
+
+	if err := d.buffer.AddNotification(ctx, userID, notification, flushAt); err != nil {
+		return err
+	}
+	metrics.NotificationsBufferedTotal.Inc()
+
+	return nil
+}
+
+// Flush отправляет дайджесты, окно которых закончилось. Вызывается фоновым воркером
+func (d *Dispatcher) Flush(ctx context.Context) error {
+	now := d.clock.Now()
+	users, err := d.buffer.DueNotificationUsers(ctx, now)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, userID := range users {
+		if err := d.flushUser(ctx, userID, now); err != nil {
+			d.logger.Error("Failed to send notification digest", map[string]interface{}{
+				"user_id": userID,
+				"error":   err.Error(),
+			})
+			errs = append(errs, err)
+		}
+	}
+	d.reportBacklog(ctx)
+
+	return errors.Join(errs...)
+}
+
+// reportBacklog обновляет метрики буфера после отправки: просроченные окна остаются,
+// если доставка не удалась или не успевает за потоком уведомлений
+func (d *Dispatcher) reportBacklog(ctx context.Context) {
+	pending, overdue, err := d.buffer.NotificationBacklog(ctx, d.clock.Now())
+	if err != nil {
+		d.logger.Error("Failed to get notification backlog", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	metrics.NotificationDigestBacklog.WithLabelValues("pending").Set(float64(pending))
+	metrics.NotificationDigestBacklog.WithLabelValues("overdue").Set(float64(overdue))
+}
+
+// flushUser отправляет дайджест пользователя, в тихие часы переносит его на их окончание
+func (d *Dispatcher) flushUser(ctx context.Context, userID string, now time.Time) error {
+	prefs, err := d.preferences(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	notifications, err := d.buffer.PopNotifications(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if len(notifications) == 0 {
+		return nil
+	}
+
+	// тихие часы могли начаться или измениться, пока уведомления ждали в буфере
+	if end, ok := QuietHoursEnd(prefs, now); ok {
+		for _, n := range notifications {
+			if err := d.buffer.AddNotification(ctx, userID, n, end); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if err := d.deliver(ctx, userID, prefs, buildDigest(notifications)); err != nil {
+		return err
+	}
+
+	if len(notifications) > 1 {
+		metrics.NotificationDigestsSentTotal.Inc()
+	}
+
+	return nil
+}
+
+// deliver рассылает уведомление по включенным каналам пользователя
+func (d *Dispatcher) deliver(ctx context.Context, userID string, prefs models.NotificationPreferences, notification models.Notification) error {
+	var errs []error
+	for _, channel := range prefs.Channels {
+		notifier, ok := d.channels[channel]
+		if !ok {
+			continue
+		}
+
+		if err := notifier.Notify(ctx, userID, notification); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", channel, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// preferences настройки пользователя или настройки по умолчанию
+func (d *Dispatcher) preferences(ctx context.Context, userID string) (models.NotificationPreferences, error) {
+	prefs, err := d.prefs.GetNotificationPreferences(ctx, userID)
+	if err != nil {
+		return models.NotificationPreferences{}, err
+	}
+
+	if prefs == nil {
+		defaults := d.defaults
+		defaults.UserID = userID
+		return defaults, nil
+	}
+
+	return *prefs, nil
+}
+
+// QuietHoursEnd возвращает окончание тихих часов пользователя, если момент now в них попадает.
+// Тихие часы задаются в часовом поясе пользователя и могут переходить через полночь (22:00–08:00)
+func QuietHoursEnd(prefs models.NotificationPreferences, now time.Time) (time.Time, bool) {
+	start, err := time.Parse(clockLayout, prefs.QuietHoursStart)
+	if err != nil {
+		return time.Time{}, false
+	}
+	end, err := time.Parse(clockLayout, prefs.QuietHoursEnd)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	startMinutes := start.Hour()*60 + start.Minute()
+	endMinutes := end.Hour()*60 + end.Minute()
+	if startMinutes == endMinutes {
+		return time.Time{}, false
+	}
+
+	loc, err := time.LoadLocation(prefs.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+
+	local := now.In(loc)
+	minutes := local.Hour()*60 + local.Minute()
+	endAt := time.Date(local.Year(), local.Month(), local.Day(), end.Hour(), end.Minute(), 0, 0, loc)
+
+	if startMinutes < endMinutes {
+		if minutes >= startMinutes && minutes < endMinutes {
+			return endAt, true
+		}
+		return time.Time{}, false
+	}
+
+	// интервал через полночь: вечером тихие часы заканчиваются завтра
+	if minutes >= startMinutes {
+		return endAt.AddDate(0, 0, 1), true
+	}
+	if minutes < endMinutes {
+		return endAt, true
+	}
+	return time.Time{}, false
+}
+
+// buildDigest одно уведомление отправляется как есть, несколько — одним сообщением со списком
+func buildDigest(notifications []models.Notification) models.Notification {
+	if len(notifications) == 1 {
+		return notifications[0]
+	}
+
+	lines := make([]string, 0, digestMaxLines+1)
+	for i, item := range notifications {
+		if i == digestMaxLines {
+			lines = append(lines, fmt.Sprintf("and %d more", len(notifications)-digestMaxLines))
+			break
+		}
+		lines = append(lines, fmt.Sprintf("%s: %s", item.Title, item.Body))
+	}
+
+	return models.Notification{
+		Event: eventDigest,
+		Title: fmt.Sprintf("%d notifications", len(notifications)),
+		Body:  strings.Join(lines, "\n"),
+	}
+}
@@ -0,0 +1,182 @@
+package notification
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jmoloko/taskmange/internal/config"
+	"github.com/jmoloko/taskmange/internal/clock"
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	domainService "github.com/jmoloko/taskmange/internal/domain/service"
+	"github.com/jmoloko/taskmange/internal/logger"
+	"github.com/jmoloko/taskmange/internal/metrics"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memoryBuffer struct {
+	items   map[string][]models.Notification
+	flushAt map[string]time.Time
+}
+
+func (b *memoryBuffer) AddNotification(ctx context.Context, userID string, n models.Notification, flushAt time.Time) error {
+	b.items[userID] = append(b.items[userID], n)
+	if _, ok := b.flushAt[userID]; !ok {
+		b.flushAt[userID] = flushAt
+	}
+	return nil
+}
+
+func (b *memoryBuffer) DueNotificationUsers(ctx context.Context, now time.Time) ([]string, error) {
+	var users []string
+	for userID, at := range b.flushAt {
+		if !at.After(now) {
+			users = append(users, userID)
+		}
+	}
+	return users, nil
+}
+
+func (b *memoryBuffer) PopNotifications(ctx context.Context, userID string) ([]models.Notification, error) {
+	items := b.items[userID]
+	delete(b.items, userID)
+	delete(b.flushAt, userID)
+	return items, nil
+}
+
+func (b *memoryBuffer) NotificationBacklog(ctx context.Context, now time.Time) (int64, int64, error) {
+	var overdue int64
+	for _, at := range b.flushAt {
+		if !at.After(now) {
+			overdue++
+		}
+	}
+	return int64(len(b.flushAt)), overdue, nil
+}
+
+type staticPreferences struct {
+	prefs *models.NotificationPreferences
+}
+
+func (p staticPreferences) GetNotificationPreferences(ctx context.Context, userID string) (*models.NotificationPreferences, error) {
+	return p.prefs, nil
+}
+
+func (p staticPreferences) SaveNotificationPreferences(ctx context.Context, prefs *models.NotificationPreferences) error {
+	return nil
+}
+
+type recordingNotifier struct {
+	sent []models.Notification
+}
+
+func (n *recordingNotifier) Notify(ctx context.Context, userID string, notification models.Notification) error {
+	n.sent = append(n.sent, notification)
+	return nil
+}
+
+func newTestDispatcher(prefs *models.NotificationPreferences) (*Dispatcher, *memoryBuffer, *recordingNotifier) {
+	buffer := &memoryBuffer{items: map[string][]models.Notification{}, flushAt: map[string]time.Time{}}
+	push := &recordingNotifier{}
+	defaults := models.NotificationPreferences{
+		Channels: []models.NotificationChannel{models.ChannelPush},
+		Timezone: "UTC",
+	}
+	dispatcher := NewDispatcher(
+		map[models.NotificationChannel]domainService.Notifier{models.ChannelPush: push},
+		buffer, staticPreferences{prefs: prefs}, defaults,
+		logger.NewSLogLogger(config.LoggerConfig{Level: "error"}))
+	return dispatcher, buffer, push
+}
+
+func TestDispatcher_Coalesces(t *testing.T) {
+	dispatcher, buffer, push := newTestDispatcher(nil)
+
+	ctx := context.Background()
+	require.NoError(t, dispatcher.Notify(ctx, "user1", models.Notification{Title: "a", Body: "1"}))
+	assert.Len(t, push.sent, 1, "zero window sends immediately")
+
+	dispatcher.defaults.DigestWindowMinutes = 15
+	for i := 0; i < 3; i++ {
+		require.NoError(t, dispatcher.Notify(ctx, "user1", models.Notification{Title: "due", Body: "task"}))
+	}
+	assert.Len(t, push.sent, 1)
+
+	buffer.flushAt["user1"] = time.Now().Add(-time.Second)
+	require.NoError(t, dispatcher.Flush(ctx))
+	require.Len(t, push.sent, 2)
+	assert.Equal(t, "digest", push.sent[1].Event)
+	assert.Equal(t, "3 notifications", push.sent[1].Title)
+
+	require.NoError(t, dispatcher.Flush(ctx))
+	assert.Len(t, push.sent, 2, "buffer is emptied after flush")
+}
+
+func TestDispatcher_ChannelsAndEventTypes(t *testing.T) {
+	prefs := &models.NotificationPreferences{
+		Channels:   []models.NotificationChannel{models.ChannelPush, models.ChannelSlack},
+		EventTypes: []string{models.NotificationEventDueSoon},
+		Timezone:   "UTC",
+	}
+	dispatcher, buffer, push := newTestDispatcher(prefs)
+
+	ctx := context.Background()
+	require.NoError(t, dispatcher.Notify(ctx, "user1", models.Notification{Event: "task.created"}))
+	assert.Empty(t, push.sent, "unsubscribed event is dropped")
+
+	require.NoError(t, dispatcher.Notify(ctx, "user1", models.Notification{Event: models.NotificationEventDueSoon}))
+	assert.Len(t, push.sent, 1, "channel without notifier is skipped")
+
+	prefs.Channels = []models.NotificationChannel{}
+	require.NoError(t, dispatcher.Notify(ctx, "user1", models.Notification{Event: models.NotificationEventDueSoon}))
+	assert.Len(t, push.sent, 1)
+	assert.Empty(t, buffer.items)
+}
+
+func TestDispatcher_QuietHours(t *testing.T) {
+	// тихие часы через полночь: с 23:30 до 01:00
+	now := time.Date(2024, 6, 10, 0, 10, 0, 0, time.UTC)
+	prefs := &models.NotificationPreferences{
+		Channels:        []models.NotificationChannel{models.ChannelPush},
+		QuietHoursStart: "23:30",
+		QuietHoursEnd:   "01:00",
+		Timezone:        "UTC",
+	}
+	dispatcher, buffer, push := newTestDispatcher(prefs)
+	dispatcher.clock = clock.NewFake(now)
+
+	ctx := context.Background()
+	require.NoError(t, dispatcher.Notify(ctx, "user1", models.Notification{Title: "a"}))
+	assert.Empty(t, push.sent)
+	assert.Equal(t, now.Add(50*time.Minute), buffer.flushAt["user1"], "deferred until quiet hours end")
+
+	// окно истекло, но тихие часы еще идут — уведомления остаются в буфере
+	buffer.flushAt["user1"] = now.Add(-time.Second)
+	require.NoError(t, dispatcher.Flush(ctx))
+	assert.Empty(t, push.sent)
+	assert.Len(t, buffer.items["user1"], 1)
+	assert.Equal(t, float64(1), testutil.ToFloat64(metrics.NotificationDigestBacklog.WithLabelValues("pending")))
+	assert.Equal(t, float64(0), testutil.ToFloat64(metrics.NotificationDigestBacklog.WithLabelValues("overdue")))
+}
+
+func TestQuietHoursEnd(t *testing.T) {
+	prefs := models.NotificationPreferences{QuietHoursStart: "22:00", QuietHoursEnd: "08:00", Timezone: "Europe/Moscow"}
+	loc, err := time.LoadLocation("Europe/Moscow")
+	require.NoError(t, err)
+
+	end, ok := QuietHoursEnd(prefs, time.Date(2024, 3, 10, 23, 30, 0, 0, loc))
+	assert.True(t, ok)
+	assert.Equal(t, time.Date(2024, 3, 11, 8, 0, 0, 0, loc), end)
+
+	end, ok = QuietHoursEnd(prefs, time.Date(2024, 3, 11, 7, 0, 0, 0, loc))
+	assert.True(t, ok)
+	assert.Equal(t, time.Date(2024, 3, 11, 8, 0, 0, 0, loc), end)
+
+	_, ok = QuietHoursEnd(prefs, time.Date(2024, 3, 11, 12, 0, 0, 0, loc))
+	assert.False(t, ok)
+
+	_, ok = QuietHoursEnd(models.NotificationPreferences{}, time.Now())
+	assert.False(t, ok, "disabled without quiet hours")
+}
@@ -0,0 +1,154 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+
+	"github.com/jmoloko/taskmange/internal/config"
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/jmoloko/taskmange/internal/domain/repository"
+)
+
+// ErrSMTPNotConfigured возвращается, если SMTP-сервер не задан
+var ErrSMTPNotConfigured = errors.New("smtp is not configured")
+
+// EmailSender отправляет письмо о событии через SMTP
+type EmailSender struct {
+	cfg      config.SMTPConfig
+	renderer *Renderer
+}
+
+// NewEmailSender создает новый экземпляр EmailSender
+func NewEmailSender(cfg config.SMTPConfig, renderer *Renderer) (*EmailSender, error) {
+	if cfg.Host == "" {
+		return nil, ErrSMTPNotConfigured
+	}
+
+	return &EmailSender{cfg: cfg, renderer: renderer}, nil
+}
+
+// Send отправляет письмо о событии на адрес триггера
+func (s *EmailSender) Send(ctx context.Context, trigger models.Trigger, event models.TaskEvent) error {
+	msg, err := s.renderer.Render(ChannelEmail, TemplateTaskEvent, TemplateData{Event: event})
+	if err != nil {
+		return err
+	}
+
+	return s.SendMail(ctx, trigger.Action.Target, msg)
+}
+
+// EmailNotifier доставляет уведомления пользователю на email его учетной записи
+type EmailNotifier struct {
+	sender *EmailSender
+	users  repository.UserReader
+}
+
+// NewEmailNotifier создает новый экземпляр EmailNotifier
+func NewEmailNotifier(sender *EmailSender, users repository.UserReader) *EmailNotifier {
+	return &EmailNotifier{sender: sender, users: users}
+}
+
+// Notify отправляет уведомление письмом
+func (n *EmailNotifier) Notify(ctx context.Context, userID string, notification models.Notification) error {
+	user, err := n.users.GetByID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	msg, err := n.sender.renderer.Render(ChannelEmail, TemplateNotification, TemplateData{Notification: notification})
+	if err != nil {
+		return err
+	}
+
+	return n.sender.SendMail(ctx, user.Email, msg)
+}
+
+// SendMail отправляет письмо с текстовой и HTML частями
+func (s *EmailSender) SendMail(ctx context.Context, to string, msg Message) error {
+	if _, err := mail.ParseAddress(to); err != nil {
+		return fmt.Errorf("invalid recipient: %w", err)
+	}
+
+	body, err := buildMessage(s.cfg.From, to, msg)
+	if err != nil {
+		return err
+	}
+
+	var auth smtp.Auth
+	if s.cfg.Username != "" {
+		auth = smtp.PlainAuth("", s.cfg.Username, s.cfg.Password, s.cfg.Host)
+	}
+
+	addr := net.JoinHostPort(s.cfg.Host, strconv.Itoa(s.cfg.Port))
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- smtp.SendMail(addr, auth, s.cfg.From, []string{to}, body)
+	}()
+
+	// net/smtp не принимает контекст, поэтому ждем либо отправки, либо отмены
+	select {
+	case err := <-errCh:
+		if err != nil {
+			return fmt.Errorf("failed to send email: %w", err)
+		}
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// buildMessage собирает письмо multipart/alternative
+func buildMessage(from, to string, msg Message) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+
+	fmt.Fprintf(&buf, "From: %s\r\n", from)
+	fmt.Fprintf(&buf, "To: %s\r\n", to)
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("UTF-8", msg.Subject))
+	fmt.Fprintf(&buf, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&buf, "Content-Type: multipart/alternative; boundary=%s\r\n\r\n", writer.Boundary())
+
+	parts := []struct {
+		contentType string
+		body        string
+	}{
+		{contentType: "text/plain; charset=UTF-8", body: msg.Text},
+		{contentType: "text/html; charset=UTF-8", body: msg.HTML},
+	}
+
+	for _, p := range parts {
+		if p.body == "" {
+			continue
+		}
+		part, err := writer.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {p.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to build email: %w", err)
+		}
+		qp := quotedprintable.NewWriter(part)
+		if _, err := qp.Write([]byte(p.body)); err != nil {
+			return nil, fmt.Errorf("failed to build email: %w", err)
+		}
+		if err := qp.Close(); err != nil {
+			return nil, fmt.Errorf("failed to build email: %w", err)
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("failed to build email: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,221 @@
+package notification
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	texttemplate "text/template"
+	"time"
+
+	"github.com/jmoloko/taskmange/internal/domain/models"
+)
+
+//go:embed templates
+var defaultTemplates embed.FS
+
+// Channel канал, для которого рендерится сообщение
+type Channel string
+
+const (
+	ChannelEmail    Channel = "email"
+	ChannelSlack    Channel = "slack"
+	ChannelTelegram Channel = "telegram"
+)
+
+const (
+	// TemplateTaskEvent шаблон сообщения о событии задачи
+	TemplateTaskEvent = "task_event"
+	// TemplateNotification шаблон уведомления пользователю
+	TemplateNotification = "notification"
+)
+
+// ErrTemplateNotFound возвращается, если для канала нет шаблона с таким именем
+var ErrTemplateNotFound = errors.New("notification template not found")
+
+// TemplateData данные, доступные в шаблонах
+type TemplateData struct {
+	Event        models.TaskEvent
+	Notification models.Notification
+}
+
+// Message результат рендеринга. Для email заполнены все поля, для Slack и Telegram только Text
+type Message struct {
+	Subject string `json:"subject,omitempty"`
+	Text    string `json:"text"`
+	HTML    string `json:"html,omitempty"`
+}
+
+// Renderer рендерит исходящие email, Slack и Telegram сообщения по шаблонам.
+// Шаблоны встроены в бинарник (templates/<канал>/<имя>.<часть>.tmpl) и могут быть
+// переопределены файлами с тем же относительным путем в каталоге развертывания.
+// HTML части рендерятся через html/template, текстовые — через text/template
+type Renderer struct {
+	text map[string]*texttemplate.Template
+	html map[string]*htmltemplate.Template
+}
+
+// NewRenderer загружает встроенные шаблоны и переопределения из overrideDir (может быть пустым)
+func NewRenderer(overrideDir string) (*Renderer, error) {
+	r := &Renderer{
+		text: make(map[string]*texttemplate.Template),
+		html: make(map[string]*htmltemplate.Template),
+	}
+
+	err := fs.WalkDir(defaultTemplates, "templates", func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+
+		rel := strings.TrimPrefix(p, "templates/")
+		content, err := readTemplate(overrideDir, rel)
+		if err != nil {
+			return err
+		}
+
+		key := strings.TrimSuffix(rel, ".tmpl")
+		if strings.HasSuffix(key, ".html") {
+			tmpl, err := htmltemplate.New(key).Funcs(htmltemplate.FuncMap(templateFuncs)).Parse(string(content))
+			if err != nil {
+				return fmt.Errorf("failed to parse template %s: %w", rel, err)
+			}
+			r.html[key] = tmpl
+			return nil
+		}
+
+		tmpl, err := texttemplate.New(key).Funcs(templateFuncs).Parse(string(content))
+		if err != nil {
+			return fmt.Errorf("failed to parse template %s: %w", rel, err)
+		}
+		r.text[key] = tmpl
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// Render рендерит шаблон name для канала
+func (r *Renderer) Render(channel Channel, name string, data TemplateData) (Message, error) {
+	prefix := path.Join(string(channel), name)
+
+	var msg Message
+	var err error
+	switch channel {
+	case ChannelEmail:
+		if msg.Subject, err = r.execText(prefix+".subject", data); err != nil {
+			return Message{}, err
+		}
+		// тема письма не может содержать переводы строк
+		msg.Subject = strings.Join(strings.Fields(msg.Subject), " ")
+
+		if msg.Text, err = r.execText(prefix+".txt", data); err != nil {
+			return Message{}, err
+		}
+		if msg.HTML, err = r.execHTML(prefix+".html", data); err != nil {
+			return Message{}, err
+		}
+	case ChannelSlack, ChannelTelegram:
+		if msg.Text, err = r.execText(prefix+".txt", data); err != nil {
+			return Message{}, err
+		}
+	default:
+		return Message{}, ErrTemplateNotFound
+	}
+
+	return msg, nil
+}
+
+func (r *Renderer) execText(key string, data TemplateData) (string, error) {
+	tmpl, ok := r.text[key]
+	if !ok {
+		return "", ErrTemplateNotFound
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to render template %s: %w", key, err)
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
+
+func (r *Renderer) execHTML(key string, data TemplateData) (string, error) {
+	tmpl, ok := r.html[key]
+	if !ok {
+		return "", ErrTemplateNotFound
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to render template %s: %w", key, err)
+	}
+	return buf.String(), nil
+}
+
+// readTemplate читает переопределение из overrideDir, если оно есть, иначе встроенный шаблон
+func readTemplate(overrideDir, rel string) ([]byte, error) {
+	if overrideDir != "" {
+		content, err := os.ReadFile(filepath.Join(overrideDir, filepath.FromSlash(rel)))
+		if err == nil {
+			return content, nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("failed to read template override %s: %w", rel, err)
+		}
+	}
+
+	return defaultTemplates.ReadFile("templates/" + rel)
+}
+
+// templateFuncs функции, доступные в шаблонах
+var templateFuncs = texttemplate.FuncMap{
+	"eventSubject": eventSubject,
+	"taskTitle":    taskTitle,
+	"formatTime": func(t time.Time) string {
+		return t.Format("2006-01-02 15:04 MST")
+	},
+	// slack экранирует управляющие символы разметки Slack
+	"slack": func(s string) string {
+		return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+	},
+}
+
+// eventSubject короткое описание события
+func eventSubject(event models.TaskEvent) string {
+	switch event.Type {
+	case models.EventTaskCreated:
+		return "Task created"
+	case models.EventTaskCompleted:
+		return "Task completed"
+	case models.EventTasksCompleted:
+		if len(event.Tasks) == 1 {
+			return "1 task completed"
+		}
+		return fmt.Sprintf("%d tasks completed", len(event.Tasks))
+	case models.EventTaskDeleted:
+		return "Task deleted"
+	case models.EventTasksDeleted:
+		if len(event.Tasks) == 1 {
+			return "1 task deleted"
+		}
+		return fmt.Sprintf("%d tasks deleted", len(event.Tasks))
+	default:
+		return "Task updated"
+	}
+}
+
+// taskTitle заголовок задачи, заголовок приватной задачи не раскрывается
+func taskTitle(task models.Task) string {
+	if task.Locked || task.Private {
+		return "Private task"
+	}
+	return task.Title
+}
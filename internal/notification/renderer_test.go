@@ -0,0 +1,105 @@
+package notification
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderer_Defaults(t *testing.T) {
+	renderer, err := NewRenderer("")
+	require.NoError(t, err)
+
+	data := TemplateData{Event: models.TaskEvent{
+		Type: models.EventTaskCompleted,
+		Task: models.Task{Title: "<b>Report</b> & more", Status: models.StatusDone, Priority: models.PriorityHigh},
+	}}
+
+	email, err := renderer.Render(ChannelEmail, TemplateTaskEvent, data)
+	require.NoError(t, err)
+	assert.Equal(t, "Task completed: <b>Report</b> & more", email.Subject)
+	assert.Contains(t, email.HTML, "&lt;b&gt;Report&lt;/b&gt; &amp; more")
+
+	slack, err := renderer.Render(ChannelSlack, TemplateTaskEvent, data)
+	require.NoError(t, err)
+	assert.Contains(t, slack.Text, "&lt;b&gt;Report&lt;/b&gt; &amp; more")
+
+	_, err = renderer.Render(ChannelSlack, "unknown", data)
+	assert.Equal(t, ErrTemplateNotFound, err)
+}
+
+func TestRenderer_PrivateTaskTitleHidden(t *testing.T) {
+	renderer, err := NewRenderer("")
+	require.NoError(t, err)
+
+	msg, err := renderer.Render(ChannelSlack, TemplateTaskEvent, TemplateData{Event: models.TaskEvent{
+		Type: models.EventTaskCreated,
+		Task: models.Task{Private: true, Locked: true},
+	}})
+	require.NoError(t, err)
+	assert.Contains(t, msg.Text, "Private task")
+}
+
+func TestRenderer_BatchEvent(t *testing.T) {
+	renderer, err := NewRenderer("")
+	require.NoError(t, err)
+
+	data := TemplateData{Event: models.TaskEvent{
+		Type: models.EventTasksCompleted,
+		Tasks: []models.Task{
+			{Title: "Report", Priority: models.PriorityHigh},
+			{Private: true, Locked: true, Priority: models.PriorityLow},
+		},
+	}}
+
+	email, err := renderer.Render(ChannelEmail, TemplateTaskEvent, data)
+	require.NoError(t, err)
+	assert.Equal(t, "2 tasks completed", email.Subject)
+	assert.Contains(t, email.Text, "- Report (priority: high")
+	assert.Contains(t, email.HTML, "Private task")
+
+	slack, err := renderer.Render(ChannelSlack, TemplateTaskEvent, data)
+	require.NoError(t, err)
+	assert.Contains(t, slack.Text, "*2 tasks completed*")
+	assert.Contains(t, slack.Text, "• Private task (priority: low")
+}
+
+func TestRenderer_FieldChanges(t *testing.T) {
+	renderer, err := NewRenderer("")
+	require.NoError(t, err)
+
+	data := TemplateData{Event: models.TaskEvent{
+		Type:    models.EventTaskUpdated,
+		Task:    models.Task{Title: "Report", Status: models.StatusDone},
+		Changes: []models.FieldChange{{Field: "status", From: models.StatusPending, To: models.StatusDone}},
+	}}
+
+	email, err := renderer.Render(ChannelEmail, TemplateTaskEvent, data)
+	require.NoError(t, err)
+	assert.Contains(t, email.Text, "- status changed from pending to done")
+	assert.Contains(t, email.HTML, "<li>status changed from pending to done</li>")
+
+	slack, err := renderer.Render(ChannelSlack, TemplateTaskEvent, data)
+	require.NoError(t, err)
+	assert.Contains(t, slack.Text, "• status changed from pending to done")
+}
+
+func TestRenderer_Override(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "slack"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "slack", "task_event.txt.tmpl"),
+		[]byte("custom: {{taskTitle .Event.Task}}"), 0o644))
+
+	renderer, err := NewRenderer(dir)
+	require.NoError(t, err)
+
+	msg, err := renderer.Render(ChannelSlack, TemplateTaskEvent, TemplateData{Event: models.TaskEvent{
+		Task: models.Task{Title: "Report"},
+	}})
+	require.NoError(t, err)
+	assert.Equal(t, "custom: Report", msg.Text)
+}
@@ -0,0 +1,75 @@
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/jmoloko/taskmange/internal/domain/repository"
+)
+
+// SlackSender публикует сообщение о событии через Slack incoming webhook
+type SlackSender struct {
+	client   *http.Client
+	renderer *Renderer
+}
+
+// NewSlackSender создает новый экземпляр SlackSender
+func NewSlackSender(renderer *Renderer) *SlackSender {
+	return &SlackSender{
+		client:   NewTargetClient(10 * time.Second),
+		renderer: renderer,
+	}
+}
+
+// Send публикует сообщение в incoming webhook триггера
+func (s *SlackSender) Send(ctx context.Context, trigger models.Trigger, event models.TaskEvent) error {
+	msg, err := s.renderer.Render(ChannelSlack, TemplateTaskEvent, TemplateData{Event: event})
+	if err != nil {
+		return err
+	}
+
+	return s.post(ctx, trigger.Action.Target, msg)
+}
+
+// post публикует текст сообщения в incoming webhook
+func (s *SlackSender) post(ctx context.Context, webhookURL string, msg Message) error {
+	body, err := json.Marshal(map[string]string{"text": msg.Text})
+	if err != nil {
+		return fmt.Errorf("failed to marshal slack message: %w", err)
+	}
+
+	return postJSON(ctx, s.client, webhookURL, body, nil)
+}
+
+// SlackNotifier доставляет уведомления в Slack через incoming webhook из настроек пользователя
+type SlackNotifier struct {
+	sender *SlackSender
+	prefs  repository.NotificationPreferencesRepository
+}
+
+// NewSlackNotifier создает новый экземпляр SlackNotifier
+func NewSlackNotifier(sender *SlackSender, prefs repository.NotificationPreferencesRepository) *SlackNotifier {
+	return &SlackNotifier{sender: sender, prefs: prefs}
+}
+
+// Notify публикует уведомление в webhook пользователя; без webhook уведомление пропускается
+func (n *SlackNotifier) Notify(ctx context.Context, userID string, notification models.Notification) error {
+	prefs, err := n.prefs.GetNotificationPreferences(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if prefs == nil || prefs.SlackWebhookURL == "" {
+		return nil
+	}
+
+	msg, err := n.sender.renderer.Render(ChannelSlack, TemplateNotification, TemplateData{Notification: notification})
+	if err != nil {
+		return err
+	}
+
+	return n.sender.post(ctx, prefs.SlackWebhookURL, msg)
+}
@@ -0,0 +1,121 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/jmoloko/taskmange/internal/config"
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/jmoloko/taskmange/internal/domain/repository"
+	"github.com/jmoloko/taskmange/internal/logger"
+)
+
+var (
+	// ErrTelegramNotConfigured возвращается, если токен бота не задан
+	ErrTelegramNotConfigured = errors.New("telegram bot is not configured")
+	// ErrTelegramChatUnavailable возвращается, если чата нет или пользователь заблокировал бота
+	ErrTelegramChatUnavailable = errors.New("telegram chat is unavailable")
+)
+
+// TelegramNotifier отправляет уведомления в привязанный Telegram-чат пользователя через Bot API
+type TelegramNotifier struct {
+	links    repository.TelegramLinkRepository
+	renderer *Renderer
+	client   *http.Client
+	apiURL   string
+	token    string
+	logger   logger.Logger
+}
+
+// NewTelegramNotifier создает новый экземпляр TelegramNotifier
+func NewTelegramNotifier(cfg config.TelegramConfig, links repository.TelegramLinkRepository, renderer *Renderer, logger logger.Logger) (*TelegramNotifier, error) {
+	if cfg.BotToken == "" {
+		return nil, ErrTelegramNotConfigured
+	}
+
+	return &TelegramNotifier{
+		links:    links,
+		renderer: renderer,
+		client:   &http.Client{Timeout: 10 * time.Second},
+		apiURL:   strings.TrimSuffix(cfg.APIURL, "/"),
+		token:    cfg.BotToken,
+		logger:   logger,
+	}, nil
+}
+
+// Notify отправляет уведомление в чат пользователя. Без привязанного чата уведомление пропускается,
+// чат, недоступный боту, отвязывается
+func (n *TelegramNotifier) Notify(ctx context.Context, userID string, notification models.Notification) error {
+	link, err := n.links.GetTelegramLink(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if link == nil {
+		return nil
+	}
+
+	msg, err := n.renderer.Render(ChannelTelegram, TemplateNotification, TemplateData{Notification: notification})
+	if err != nil {
+		return err
+	}
+
+	err = n.SendMessage(ctx, link.ChatID, msg.Text)
+	if errors.Is(err, ErrTelegramChatUnavailable) {
+		n.logger.Info("Removing unavailable telegram chat", map[string]interface{}{
+			"user_id": userID,
+			"chat_id": link.ChatID,
+		})
+		return n.links.DeleteTelegramLink(ctx, userID)
+	}
+
+	return err
+}
+
+// SendMessage отправляет текстовое сообщение в чат методом sendMessage
+func (n *TelegramNotifier) SendMessage(ctx context.Context, chatID int64, text string) error {
+	body, err := json.Marshal(map[string]interface{}{
+		"chat_id":                  chatID,
+		"text":                     text,
+		"disable_web_page_preview": true,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal telegram message: %w", err)
+	}
+
+	// токен входит в путь запроса, поэтому в ошибки попадает только метод
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.apiURL+"/bot"+n.token+"/sendMessage", bytes.NewReader(body))
+	if err != nil {
+		return errors.New("failed to create telegram request")
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return errors.New("failed to send telegram request")
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		OK          bool   `json:"ok"`
+		Description string `json:"description"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("telegram responded with status %d", resp.StatusCode)
+	}
+
+	switch {
+	case result.OK:
+		return nil
+	case resp.StatusCode == http.StatusForbidden,
+		resp.StatusCode == http.StatusBadRequest && strings.Contains(result.Description, "chat not found"):
+		return ErrTelegramChatUnavailable
+	default:
+		return fmt.Errorf("telegram responded with status %d: %s", resp.StatusCode, result.Description)
+	}
+}
@@ -0,0 +1,93 @@
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jmoloko/taskmange/internal/config"
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/jmoloko/taskmange/internal/domain/repository"
+	"github.com/jmoloko/taskmange/internal/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memoryTelegramLinks struct {
+	links map[string]*models.TelegramLink
+}
+
+func (m *memoryTelegramLinks) SaveTelegramLink(ctx context.Context, link *models.TelegramLink) error {
+	m.links[link.UserID] = link
+	return nil
+}
+
+func (m *memoryTelegramLinks) GetTelegramLink(ctx context.Context, userID string) (*models.TelegramLink, error) {
+	return m.links[userID], nil
+}
+
+func (m *memoryTelegramLinks) DeleteTelegramLink(ctx context.Context, userID string) error {
+	if _, ok := m.links[userID]; !ok {
+		return repository.ErrNotFound
+	}
+	delete(m.links, userID)
+	return nil
+}
+
+func (m *memoryTelegramLinks) GetTelegramDigestLinks(ctx context.Context) ([]models.TelegramLink, error) {
+	return nil, nil
+}
+
+func (m *memoryTelegramLinks) MarkTelegramDigestSent(ctx context.Context, userID string, day time.Time) error {
+	return nil
+}
+
+func TestTelegramNotifier_Notify(t *testing.T) {
+	var path string
+	var sent map[string]interface{}
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&sent))
+		w.WriteHeader(status)
+		if status == http.StatusOK {
+			w.Write([]byte(`{"ok":true,"result":{}}`))
+			return
+		}
+		w.Write([]byte(`{"ok":false,"description":"Forbidden: bot was blocked by the user"}`))
+	}))
+	defer server.Close()
+
+	renderer, err := NewRenderer("")
+	require.NoError(t, err)
+	links := &memoryTelegramLinks{links: map[string]*models.TelegramLink{
+		"user1": {UserID: "user1", ChatID: 42},
+	}}
+	notifier, err := NewTelegramNotifier(config.TelegramConfig{BotToken: "123:abc", APIURL: server.URL}, links, renderer,
+		logger.NewSLogLogger(config.LoggerConfig{Level: "error"}))
+	require.NoError(t, err)
+
+	notification := models.Notification{Title: "Task due soon", Body: "Write report"}
+	require.NoError(t, notifier.Notify(context.Background(), "user1", notification))
+	assert.Equal(t, "/bot123:abc/sendMessage", path)
+	assert.Equal(t, float64(42), sent["chat_id"])
+	assert.Equal(t, "Task due soon\n\nWrite report", sent["text"])
+
+	// без привязанного чата уведомление пропускается
+	path = ""
+	require.NoError(t, notifier.Notify(context.Background(), "user2", notification))
+	assert.Empty(t, path)
+
+	// заблокировавший бота пользователь отвязывается
+	status = http.StatusForbidden
+	require.NoError(t, notifier.Notify(context.Background(), "user1", notification))
+	assert.NotContains(t, links.links, "user1")
+}
+
+func TestNewTelegramNotifier_NotConfigured(t *testing.T) {
+	_, err := NewTelegramNotifier(config.TelegramConfig{}, nil, nil, nil)
+	assert.ErrorIs(t, err, ErrTelegramNotConfigured)
+}
@@ -0,0 +1,102 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoloko/taskmange/internal/clock"
+	"github.com/jmoloko/taskmange/internal/domain/models"
+)
+
+// Заголовки подписанного webhook. Получатель проверяет подпись и отклоняет запросы
+// со слишком старой меткой времени, защищаясь от повторной отправки
+const (
+	HeaderWebhookID        = "X-Webhook-Id"
+	HeaderWebhookTimestamp = "X-Webhook-Timestamp"
+	HeaderWebhookSignature = "X-Webhook-Signature"
+)
+
+// WebhookSender отправляет событие задачи JSON-запросом POST на URL получателя
+type WebhookSender struct {
+	client *http.Client
+	clock  clock.Clock
+}
+
+// NewWebhookSender создает новый экземпляр WebhookSender
+func NewWebhookSender() *WebhookSender {
+	return &WebhookSender{
+		client: NewTargetClient(10 * time.Second),
+		clock:  clock.System,
+	}
+}
+
+// Send отправляет событие на URL триггера. Запрос подписывается секретом триггера,
+// после ротации в течение models.WebhookSecretGrace добавляется подпись предыдущим секретом
+func (s *WebhookSender) Send(ctx context.Context, trigger models.Trigger, event models.TaskEvent) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal webhook payload: %w", err)
+	}
+
+	now := s.clock.Now()
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+
+	headers := http.Header{}
+	headers.Set(HeaderWebhookID, uuid.New().String())
+	headers.Set(HeaderWebhookTimestamp, timestamp)
+
+	secrets := trigger.SigningSecrets(now)
+	if len(secrets) > 0 {
+		signatures := make([]string, 0, len(secrets))
+		for _, secret := range secrets {
+			signatures = append(signatures, "v1="+WebhookSignature(secret, timestamp, body))
+		}
+		headers.Set(HeaderWebhookSignature, strings.Join(signatures, ","))
+	}
+
+	return postJSON(ctx, s.client, trigger.Action.Target, body, headers)
+}
+
+// WebhookSignature HMAC-SHA256 от "timestamp.body" в hex
+func WebhookSignature(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// postJSON отправляет JSON и считает ошибкой любой ответ кроме 2xx
+func postJSON(ctx context.Context, client *http.Client, url string, body []byte, headers http.Header) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	for name, values := range headers {
+		req.Header[name] = values
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "taskmanager-webhook/1.0")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("receiver responded with status %d", resp.StatusCode)
+	}
+
+	return nil
+}
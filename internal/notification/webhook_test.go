@@ -0,0 +1,122 @@
+package notification
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jmoloko/taskmange/internal/clock"
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookSender_Signature(t *testing.T) {
+	var header http.Header
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Clone()
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	now := time.Unix(1710000000, 0)
+	sender := NewWebhookSender()
+	sender.clock = clock.NewFake(now)
+	// тестовый сервер слушает loopback, куда клиент по умолчанию не ходит
+	sender.client = server.Client()
+
+	rotatedAt := now.Add(-time.Hour)
+	trigger := models.Trigger{
+		Action:          models.TriggerAction{Type: models.TriggerActionWebhook, Target: server.URL},
+		Secret:          "whsec_new",
+		PreviousSecret:  "whsec_old",
+		SecretRotatedAt: &rotatedAt,
+	}
+	event := models.TaskEvent{Type: models.EventTaskCreated, UserID: "user1", Task: models.Task{ID: "1"}}
+
+	require.NoError(t, sender.Send(context.Background(), trigger, event))
+	assert.Equal(t, "1710000000", header.Get(HeaderWebhookTimestamp))
+	assert.NotEmpty(t, header.Get(HeaderWebhookID))
+	// в период ротации запрос подписан обоими секретами
+	assert.Equal(t,
+		"v1="+WebhookSignature("whsec_new", "1710000000", body)+",v1="+WebhookSignature("whsec_old", "1710000000", body),
+		header.Get(HeaderWebhookSignature))
+
+	rotatedAt = now.Add(-models.WebhookSecretGrace)
+	require.NoError(t, sender.Send(context.Background(), trigger, event))
+	assert.Equal(t, "v1="+WebhookSignature("whsec_new", "1710000000", body), header.Get(HeaderWebhookSignature))
+}
+
+func TestSlackNotifier_UserWebhook(t *testing.T) {
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	renderer, err := NewRenderer("")
+	require.NoError(t, err)
+	notification := models.Notification{Title: "Task overdue", Body: "Pay <rent>"}
+
+	sender := NewSlackSender(renderer)
+	sender.client = server.Client()
+
+	// без webhook в настройках уведомление пропускается
+	notifier := NewSlackNotifier(sender, staticPreferences{})
+	require.NoError(t, notifier.Notify(context.Background(), "user1", notification))
+	assert.Nil(t, body)
+
+	notifier = NewSlackNotifier(sender, staticPreferences{prefs: &models.NotificationPreferences{SlackWebhookURL: server.URL}})
+	require.NoError(t, notifier.Notify(context.Background(), "user1", notification))
+	assert.JSONEq(t, `{"text":"*Task overdue*\nPay &lt;rent&gt;"}`, string(body))
+}
+
+func TestWebhookSender_RejectsInternalAddress(t *testing.T) {
+	var called bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	trigger := models.Trigger{Action: models.TriggerAction{Type: models.TriggerActionWebhook, Target: server.URL}}
+	event := models.TaskEvent{Type: models.EventTaskCreated, UserID: "user1", Task: models.Task{ID: "1"}}
+
+	// адрес проверяется при соединении, loopback отклоняется до отправки запроса
+	err := NewWebhookSender().Send(context.Background(), trigger, event)
+	assert.ErrorIs(t, err, ErrForbiddenTarget)
+	assert.False(t, called)
+}
+
+func TestValidateTarget(t *testing.T) {
+	allowed := []string{"https://hooks.slack.com/services/T000", "http://example.com:8080/hook", "https://8.8.8.8/hook"}
+	for _, target := range allowed {
+		assert.NoError(t, ValidateTarget(target), target)
+	}
+
+	forbidden := []string{
+		"ftp://example.com",
+		"https://",
+		"http://localhost:8080/hook",
+		"http://api.localhost/hook",
+		"http://127.0.0.1/hook",
+		"http://10.0.0.5/hook",
+		"http://172.16.3.4/hook",
+		"http://192.168.1.1/hook",
+		"http://169.254.169.254/latest/meta-data",
+		"http://0.0.0.0/hook",
+		"http://[::1]/hook",
+		"http://[fd00::1]/hook",
+		"http://[fe80::1]/hook",
+		"http://[::ffff:127.0.0.1]/hook",
+	}
+	for _, target := range forbidden {
+		assert.ErrorIs(t, ValidateTarget(target), ErrForbiddenTarget, target)
+	}
+}
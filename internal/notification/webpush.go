@@ -0,0 +1,278 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/jmoloko/taskmange/internal/clock"
+	"github.com/jmoloko/taskmange/internal/config"
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/jmoloko/taskmange/internal/domain/repository"
+	"github.com/jmoloko/taskmange/internal/logger"
+)
+
+const (
+	// время жизни сообщения в push-сервисе, если браузер оффлайн
+	pushTTL = 12 * time.Hour
+	// срок действия VAPID JWT, push-сервисы принимают не более 24 часов
+	vapidTokenTTL = 12 * time.Hour
+	// размер записи aes128gcm, одно сообщение всегда помещается в одну запись
+	recordSize = 4096
+)
+
+// ErrVAPIDNotConfigured возвращается, если ключи VAPID не заданы
+var ErrVAPIDNotConfigured = errors.New("vapid keys are not configured")
+
+// WebPushNotifier отправляет уведомления во все браузеры пользователя через Web Push (RFC 8030/8291/8292).
+// Адрес подписки присылает клиент, поэтому запросы идут клиентом с проверкой адреса соединения
+type WebPushNotifier struct {
+	subscriptions repository.PushSubscriptionRepository
+	client        *http.Client
+	publicKey     string
+	privateKey    *ecdsa.PrivateKey
+	subject       string
+	clock         clock.Clock
+	logger        logger.Logger
+}
+
+// NewWebPushNotifier создает новый экземпляр WebPushNotifier
+func NewWebPushNotifier(cfg config.PushConfig, subscriptions repository.PushSubscriptionRepository, logger logger.Logger) (*WebPushNotifier, error) {
+	if cfg.VAPIDPublicKey == "" || cfg.VAPIDPrivateKey == "" {
+		return nil, ErrVAPIDNotConfigured
+	}
+
+	privateKey, err := parseVAPIDPrivateKey(cfg.VAPIDPrivateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &WebPushNotifier{
+		subscriptions: subscriptions,
+		client:        NewTargetClient(10 * time.Second),
+		publicKey:     cfg.VAPIDPublicKey,
+		privateKey:    privateKey,
+		subject:       cfg.Subject,
+		clock:         clock.System,
+		logger:        logger,
+	}, nil
+}
+
+// PublicKey возвращает публичный VAPID-ключ для pushManager.subscribe на клиенте
+func (n *WebPushNotifier) PublicKey() string {
+	return n.publicKey
+}
+
+// Notify отправляет уведомление во все подписки пользователя.
+// Подписки, которые push-сервис считает удаленными (404/410), удаляются
+func (n *WebPushNotifier) Notify(ctx context.Context, userID string, notification models.Notification) error {
+	subs, err := n.subscriptions.GetPushSubscriptions(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	payload, err := json.Marshal(notification)
+	if err != nil {
+		return fmt.Errorf("failed to marshal push payload: %w", err)
+	}
+
+	var errs []error
+	for _, sub := range subs {
+		status, err := n.send(ctx, sub, payload)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		switch {
+		case status == http.StatusNotFound || status == http.StatusGone:
+			n.logger.Info("Removing expired push subscription", map[string]interface{}{
+				"user_id":  userID,
+				"endpoint": sub.Endpoint,
+			})
+			if err := n.subscriptions.DeletePushSubscription(ctx, userID, sub.Endpoint); err != nil {
+				errs = append(errs, err)
+			}
+		case status >= http.StatusBadRequest:
+			errs = append(errs, fmt.Errorf("push service responded with status %d", status))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// send шифрует payload для подписки и отправляет его в push-сервис
+func (n *WebPushNotifier) send(ctx context.Context, sub models.PushSubscription, payload []byte) (int, error) {
+	body, err := encryptPayload(sub, payload)
+	if err != nil {
+		return 0, err
+	}
+
+	endpoint, err := url.Parse(sub.Endpoint)
+	if err != nil {
+		return 0, fmt.Errorf("invalid push endpoint: %w", err)
+	}
+
+	token, err := n.vapidToken(endpoint.Scheme + "://" + endpoint.Host)
+	if err != nil {
+		return 0, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create push request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("Content-Encoding", "aes128gcm")
+	req.Header.Set("TTL", fmt.Sprintf("%d", int(pushTTL.Seconds())))
+	req.Header.Set("Urgency", "high")
+	req.Header.Set("Authorization", fmt.Sprintf("vapid t=%s, k=%s", token, n.publicKey))
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to send push: %w", err)
+	}
+	defer resp.Body.Close()
+
+	return resp.StatusCode, nil
+}
+
+// vapidToken подписывает JWT для push-сервиса (RFC 8292)
+func (n *WebPushNotifier) vapidToken(audience string) (string, error) {
+	claims := jwt.MapClaims{
+		"aud": audience,
+		"exp": n.clock.Now().Add(vapidTokenTTL).Unix(),
+		"sub": n.subject,
+	}
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodES256, claims).SignedString(n.privateKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign vapid token: %w", err)
+	}
+
+	return token, nil
+}
+
+// encryptPayload шифрует сообщение по схеме aes128gcm (RFC 8291)
+func encryptPayload(sub models.PushSubscription, payload []byte) ([]byte, error) {
+	uaPublicBytes, err := decodeBase64URL(sub.P256dh)
+	if err != nil {
+		return nil, fmt.Errorf("invalid p256dh key: %w", err)
+	}
+
+	authSecret, err := decodeBase64URL(sub.Auth)
+	if err != nil {
+		return nil, fmt.Errorf("invalid auth secret: %w", err)
+	}
+
+	uaPublic, err := ecdh.P256().NewPublicKey(uaPublicBytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid p256dh key: %w", err)
+	}
+
+	asPrivate, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate ephemeral key: %w", err)
+	}
+	asPublicBytes := asPrivate.PublicKey().Bytes()
+
+	sharedSecret, err := asPrivate.ECDH(uaPublic)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute shared secret: %w", err)
+	}
+
+	keyInfo := append([]byte("WebPush: info\x00"), uaPublicBytes...)
+	keyInfo = append(keyInfo, asPublicBytes...)
+	ikm, err := hkdf.Key(sha256.New, sharedSecret, authSecret, string(keyInfo), 32)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive input key: %w", err)
+	}
+
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("failed to generate salt: %w", err)
+	}
+
+	cek, err := hkdf.Key(sha256.New, ikm, salt, "Content-Encoding: aes128gcm\x00", 16)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive content key: %w", err)
+	}
+
+	nonce, err := hkdf.Key(sha256.New, ikm, salt, "Content-Encoding: nonce\x00", 12)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive nonce: %w", err)
+	}
+
+	block, err := aes.NewCipher(cek)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create gcm: %w", err)
+	}
+
+	// 0x02 — разделитель последней записи
+	plaintext := append(append([]byte{}, payload...), 0x02)
+	if len(plaintext)+aead.Overhead() > recordSize {
+		return nil, errors.New("push payload is too large")
+	}
+
+	header := make([]byte, 0, 16+4+1+len(asPublicBytes))
+	header = append(header, salt...)
+	header = binary.BigEndian.AppendUint32(header, recordSize)
+	header = append(header, byte(len(asPublicBytes)))
+	header = append(header, asPublicBytes...)
+
+	return aead.Seal(header, nonce, plaintext, nil), nil
+}
+
+// parseVAPIDPrivateKey восстанавливает ключ ECDSA P-256 из 32 байт скаляра в base64url
+func parseVAPIDPrivateKey(encoded string) (*ecdsa.PrivateKey, error) {
+	raw, err := decodeBase64URL(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("invalid vapid private key: %w", err)
+	}
+
+	key, err := ecdh.P256().NewPrivateKey(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid vapid private key: %w", err)
+	}
+
+	// несжатая точка: 0x04 || X || Y
+	public := key.PublicKey().Bytes()
+	return &ecdsa.PrivateKey{
+		PublicKey: ecdsa.PublicKey{
+			Curve: elliptic.P256(),
+			X:     new(big.Int).SetBytes(public[1:33]),
+			Y:     new(big.Int).SetBytes(public[33:]),
+		},
+		D: new(big.Int).SetBytes(raw),
+	}, nil
+}
+
+// decodeBase64URL декодирует base64url с паддингом и без
+func decodeBase64URL(s string) ([]byte, error) {
+	if data, err := base64.RawURLEncoding.DecodeString(s); err == nil {
+		return data, nil
+	}
+	return base64.URLEncoding.DecodeString(s)
+}
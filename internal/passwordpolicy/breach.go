@@ -0,0 +1,70 @@
+package passwordpolicy
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// BreachChecker проверка пароля по базе утечек Pwned Passwords с k-анонимностью:
+// в сервис уходят первые 5 символов SHA-1 пароля, совпадение суффикса ищется локально
+type BreachChecker struct {
+	client *http.Client
+	apiURL string
+}
+
+// NewBreachChecker создает новый экземпляр BreachChecker. apiURL — адрес API,
+// по умолчанию https://api.pwnedpasswords.com
+func NewBreachChecker(apiURL string, timeout time.Duration) *BreachChecker {
+	return &BreachChecker{
+		client: &http.Client{Timeout: timeout},
+		apiURL: strings.TrimRight(apiURL, "/"),
+	}
+}
+
+// Breached сообщает, встречался ли пароль в утечках
+func (b *BreachChecker) Breached(ctx context.Context, password string) (bool, error) {
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	prefix, suffix := hash[:5], hash[5:]
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, b.apiURL+"/range/"+prefix, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to create request: %w", err)
+	}
+	// дополнение ответа фиктивными суффиксами скрывает от наблюдателя размер выборки
+	req.Header.Set("Add-Padding", "true")
+	req.Header.Set("User-Agent", "taskmanager-password-policy")
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return false, fmt.Errorf("failed to query breached passwords: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("breached passwords API returned %d", resp.StatusCode)
+	}
+
+	// строки вида SUFFIX:COUNT, у фиктивных суффиксов COUNT равен 0
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		candidate, count, ok := strings.Cut(strings.TrimSpace(scanner.Text()), ":")
+		if !ok || !strings.EqualFold(candidate, suffix) {
+			continue
+		}
+		n, err := strconv.Atoi(count)
+		return err == nil && n > 0, nil
+	}
+	if err := scanner.Err(); err != nil {
+		return false, fmt.Errorf("failed to read breached passwords: %w", err)
+	}
+
+	return false, nil
+}
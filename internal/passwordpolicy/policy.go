@@ -0,0 +1,138 @@
+package passwordpolicy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/jmoloko/taskmange/internal/logger"
+)
+
+// CharClass класс символов, который должен встретиться в пароле
+type CharClass string
+
+const (
+	ClassLower  CharClass = "lower"
+	ClassUpper  CharClass = "upper"
+	ClassDigit  CharClass = "digit"
+	ClassSymbol CharClass = "symbol"
+)
+
+// MaxLength предел длины пароля в байтах: bcrypt не принимает пароли длиннее
+const MaxLength = 72
+
+// ErrInvalidPolicy параметры политики вне допустимых границ
+var ErrInvalidPolicy = errors.New("invalid password policy")
+
+// Violation пароль не соответствует политике; Reasons — нарушенные требования для ответа клиенту
+type Violation struct {
+	Reasons []string
+}
+
+func (v *Violation) Error() string {
+	return "password " + strings.Join(v.Reasons, ", ")
+}
+
+// ReuseViolation новый пароль совпал с одним из history последних паролей пользователя
+func ReuseViolation(history int) *Violation {
+	if history == 1 {
+		return &Violation{Reasons: []string{"must differ from the current password"}}
+	}
+	return &Violation{Reasons: []string{fmt.Sprintf("must differ from the last %d passwords", history)}}
+}
+
+// Config требования к паролям
+type Config struct {
+	MinLength int
+	// RequiredClasses классы символов, каждый из которых должен быть в пароле
+	RequiredClasses []CharClass
+	// BreachCheckURL адрес API Pwned Passwords; пустой — проверка по утечкам выключена
+	BreachCheckURL     string
+	BreachCheckTimeout time.Duration
+	// History сколько последних паролей пользователя нельзя использовать снова, 0 — без ограничения
+	History int
+}
+
+// Policy проверяет новые пароли: длина, классы символов и, если включено, отсутствие в утечках
+type Policy struct {
+	cfg      Config
+	breaches *BreachChecker
+	logger   logger.Logger
+}
+
+// New создает новый экземпляр Policy
+func New(cfg Config, logger logger.Logger) (*Policy, error) {
+	if cfg.MinLength < 1 || cfg.MinLength > MaxLength {
+		return nil, fmt.Errorf("%w: minimum length must be between 1 and %d", ErrInvalidPolicy, MaxLength)
+	}
+	if cfg.History < 0 {
+		return nil, fmt.Errorf("%w: history must not be negative", ErrInvalidPolicy)
+	}
+	for _, class := range cfg.RequiredClasses {
+		if _, ok := classes[class]; !ok {
+			return nil, fmt.Errorf("%w: unknown character class %q", ErrInvalidPolicy, class)
+		}
+	}
+
+	policy := &Policy{cfg: cfg, logger: logger}
+	if cfg.BreachCheckURL != "" {
+		policy.breaches = NewBreachChecker(cfg.BreachCheckURL, cfg.BreachCheckTimeout)
+	}
+	return policy, nil
+}
+
+// History сколько последних паролей нельзя использовать снова
+func (p *Policy) History() int {
+	return p.cfg.History
+}
+
+// Check возвращает *Violation, если пароль не соответствует политике. Если сервис утечек
+// недоступен, проверка по утечкам пропускается с предупреждением в журнале: сбой внешнего
+// API не должен блокировать регистрацию
+func (p *Policy) Check(ctx context.Context, password string) error {
+	var reasons []string
+
+	if utf8.RuneCountInString(password) < p.cfg.MinLength {
+		reasons = append(reasons, fmt.Sprintf("must be at least %d characters", p.cfg.MinLength))
+	}
+	if len(password) > MaxLength {
+		reasons = append(reasons, fmt.Sprintf("must be at most %d bytes", MaxLength))
+	}
+	for _, class := range p.cfg.RequiredClasses {
+		if !strings.ContainsFunc(password, classes[class].match) {
+			reasons = append(reasons, "must contain "+classes[class].name)
+		}
+	}
+
+	// в сервис утечек отправляются только пароли, прошедшие остальные проверки
+	if len(reasons) == 0 && p.breaches != nil {
+		breached, err := p.breaches.Breached(ctx, password)
+		switch {
+		case err != nil:
+			p.logger.Warn("Password breach check failed", map[string]interface{}{
+				"error": err.Error(),
+			})
+		case breached:
+			reasons = append(reasons, "has appeared in a data breach, choose another one")
+		}
+	}
+
+	if len(reasons) > 0 {
+		return &Violation{Reasons: reasons}
+	}
+	return nil
+}
+
+var classes = map[CharClass]struct {
+	name  string
+	match func(rune) bool
+}{
+	ClassLower:  {"a lowercase letter", unicode.IsLower},
+	ClassUpper:  {"an uppercase letter", unicode.IsUpper},
+	ClassDigit:  {"a digit", unicode.IsDigit},
+	ClassSymbol: {"a symbol", func(r rune) bool { return unicode.IsPunct(r) || unicode.IsSymbol(r) }},
+}
@@ -0,0 +1,66 @@
+package passwordpolicy
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jmoloko/taskmange/internal/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheck(t *testing.T) {
+	policy, err := New(Config{MinLength: 8, RequiredClasses: []CharClass{ClassUpper, ClassDigit, ClassSymbol}}, &logger.MockLogger{})
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	var violation *Violation
+	err = policy.Check(ctx, "short")
+	require.ErrorAs(t, err, &violation)
+	assert.Equal(t, []string{
+		"must be at least 8 characters",
+		"must contain an uppercase letter",
+		"must contain a digit",
+		"must contain a symbol",
+	}, violation.Reasons)
+
+	// длина считается в символах, а не в байтах
+	err = policy.Check(ctx, "Пароль1!")
+	assert.NoError(t, err)
+
+	assert.NoError(t, policy.Check(ctx, "Correct-Horse-42"))
+
+	_, err = New(Config{MinLength: 0}, &logger.MockLogger{})
+	assert.ErrorIs(t, err, ErrInvalidPolicy)
+	_, err = New(Config{MinLength: 6, RequiredClasses: []CharClass{"emoji"}}, &logger.MockLogger{})
+	assert.ErrorIs(t, err, ErrInvalidPolicy)
+}
+
+func TestBreachCheck(t *testing.T) {
+	// SHA-1 от "password" — 5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8
+	var requested string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = r.URL.Path
+		assert.Equal(t, "true", r.Header.Get("Add-Padding"))
+		w.Write([]byte("0018A45C4D1DEF81644B54AB7F969B88D65:0\r\n1E4C9B93F3F0682250B6CF8331B7EE68FD8:9659365\r\n"))
+	}))
+	defer server.Close()
+
+	policy, err := New(Config{MinLength: 6, BreachCheckURL: server.URL + "/", BreachCheckTimeout: time.Second}, &logger.MockLogger{})
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	var violation *Violation
+	require.ErrorAs(t, policy.Check(ctx, "password"), &violation)
+	assert.Equal(t, "/range/5BAA6", requested)
+	assert.Equal(t, []string{"has appeared in a data breach, choose another one"}, violation.Reasons)
+
+	assert.NoError(t, policy.Check(ctx, "not-in-the-list"))
+
+	// недоступность сервиса не блокирует смену пароля
+	server.Close()
+	assert.NoError(t, policy.Check(ctx, "password"))
+}
@@ -0,0 +1,323 @@
+package quickadd
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/jmoloko/taskmange/internal/domain/models"
+)
+
+// срок без указанного времени — конец дня
+const (
+	defaultHour   = 23
+	defaultMinute = 59
+)
+
+// Result разобранная строка. Распознаются первые срок и приоритет, повторные остаются в заголовке
+type Result struct {
+	Title string
+	// DueDate срок в часовом поясе now, nil — срок не указан
+	DueDate *time.Time
+	// Priority пустой — приоритет не указан
+	Priority models.Priority
+	Tags     []string
+}
+
+var priorities = map[string]models.Priority{
+	"high":   models.PriorityHigh,
+	"medium": models.PriorityMedium,
+	"normal": models.PriorityMedium,
+	"low":    models.PriorityLow,
+}
+
+var weekdays = map[string]time.Weekday{
+	"sunday": time.Sunday, "monday": time.Monday, "tuesday": time.Tuesday, "wednesday": time.Wednesday,
+	"thursday": time.Thursday, "friday": time.Friday, "saturday": time.Saturday,
+}
+
+var (
+	isoDate   = regexp.MustCompile(`^(\d{4})-(\d{2})-(\d{2})$`)
+	dottedDay = regexp.MustCompile(`^(\d{1,2})\.(\d{1,2})(?:\.(\d{4}))?$`)
+	clockTime = regexp.MustCompile(`^(\d{1,2})(?::(\d{2}))?(am|pm)?$`)
+)
+
+// parser состояние разбора одной строки
+type parser struct {
+	tokens []Token
+	now    time.Time
+
+	date     *time.Time
+	hour     int
+	minute   int
+	timeSet  bool
+	priority models.Priority
+	tags     []string
+}
+
+// Parse разбирает строку относительно now; часовой пояс now — пояс пользователя.
+// Поддерживаются:
+//   - теги: #finance
+//   - приоритет: "high priority", "priority low", !high
+//   - срок: today, tomorrow, friday, next friday, next week, "in 3 days", 2026-01-31, 31.01.2026, 31.01
+//     с необязательными on/by/due перед ним
+//   - время: "at 5pm", 17:30, noon
+func Parse(line string, now time.Time) Result {
+	p := &parser{tokens: Tokenize(line), now: now}
+
+	var title []string
+	for i := 0; i < len(p.tokens); {
+		if n := p.match(i); n > 0 {
+			i += n
+			continue
+		}
+		title = append(title, p.tokens[i].Text)
+		i++
+	}
+
+	return Result{
+		Title:    strings.Join(title, " "),
+		DueDate:  p.dueDate(),
+		Priority: p.priority,
+		Tags:     p.tags,
+	}
+}
+
+// match распознает конструкцию, начинающуюся с i-го слова, и возвращает число занятых ею слов
+func (p *parser) match(i int) int {
+	if p.tokens[i].Quoted {
+		return 0
+	}
+	if n := p.matchTag(i); n > 0 {
+		return n
+	}
+	if n := p.matchPriority(i); n > 0 {
+		return n
+	}
+	if n := p.matchDate(i); n > 0 {
+		return n
+	}
+	return p.matchTime(i)
+}
+
+func (p *parser) matchTag(i int) int {
+	text := p.tokens[i].Text
+	if !strings.HasPrefix(text, "#") {
+		return 0
+	}
+	tag := strings.TrimRightFunc(text[1:], unicode.IsPunct)
+	if tag == "" {
+		return 0
+	}
+	p.tags = append(p.tags, tag)
+	return 1
+}
+
+func (p *parser) matchPriority(i int) int {
+	if p.priority != "" {
+		return 0
+	}
+
+	word := p.norm(i)
+	if level, ok := priorities[strings.TrimPrefix(word, "!")]; ok && strings.HasPrefix(word, "!") {
+		p.priority = level
+		return 1
+	}
+	if level, ok := priorities[word]; ok && p.norm(i+1) == "priority" {
+		p.priority = level
+		return 2
+	}
+	if level, ok := priorities[p.norm(i+1)]; ok && word == "priority" {
+		p.priority = level
+		return 2
+	}
+	return 0
+}
+
+// matchDate дата с необязательным предлогом и временем после нее
+func (p *parser) matchDate(i int) int {
+	if p.date != nil {
+		return 0
+	}
+
+	start := i
+	switch p.norm(i) {
+	case "on", "by", "due":
+		i++
+	}
+
+	date, n := p.parseDate(i)
+	if n == 0 {
+		return 0
+	}
+	p.date = &date
+	i += n
+
+	return i - start + p.matchTime(i)
+}
+
+// parseDate дата, начинающаяся с i-го слова, и число ее слов
+func (p *parser) parseDate(i int) (time.Time, int) {
+	today := startOfDay(p.now)
+	word := p.norm(i)
+
+	switch word {
+	case "today":
+		return today, 1
+	case "tomorrow":
+		return today.AddDate(0, 0, 1), 1
+	case "next":
+		if p.norm(i+1) == "week" {
+			return nextWeekday(today, time.Monday), 2
+		}
+		if day, ok := weekdays[p.norm(i+1)]; ok {
+			return nextWeekday(today, day), 2
+		}
+		return time.Time{}, 0
+	case "in":
+		count, err := strconv.Atoi(p.norm(i + 1))
+		if err != nil || count < 1 || count > 365 {
+			return time.Time{}, 0
+		}
+		switch strings.TrimSuffix(p.norm(i+2), "s") {
+		case "day":
+			return today.AddDate(0, 0, count), 3
+		case "week":
+			return today.AddDate(0, 0, 7*count), 3
+		case "month":
+			return today.AddDate(0, count, 0), 3
+		}
+		return time.Time{}, 0
+	}
+
+	if day, ok := weekdays[word]; ok {
+		return nextWeekday(today, day), 1
+	}
+	if m := isoDate.FindStringSubmatch(word); m != nil {
+		return p.calendarDate(m[1], m[2], m[3])
+	}
+	if m := dottedDay.FindStringSubmatch(word); m != nil {
+		date, n := p.calendarDate(m[3], m[2], m[1])
+		// без года — ближайшая такая дата, уже прошедшая переносится на следующий год
+		if n > 0 && m[3] == "" && date.Before(today) {
+			date, n = p.calendarDate(strconv.Itoa(today.Year()+1), m[2], m[1])
+		}
+		return date, n
+	}
+	return time.Time{}, 0
+}
+
+// calendarDate дата из строк года, месяца и дня; пустой год — текущий. Несуществующая дата не распознается
+func (p *parser) calendarDate(year, month, day string) (time.Time, int) {
+	y := p.now.Year()
+	if year != "" {
+		y, _ = strconv.Atoi(year)
+	}
+	m, _ := strconv.Atoi(month)
+	d, _ := strconv.Atoi(day)
+
+	date := time.Date(y, time.Month(m), d, 0, 0, 0, 0, p.now.Location())
+	if date.Year() != y || int(date.Month()) != m || date.Day() != d {
+		return time.Time{}, 0
+	}
+	return date, 1
+}
+
+// matchTime время с необязательным "at" перед ним
+func (p *parser) matchTime(i int) int {
+	if p.timeSet {
+		return 0
+	}
+
+	start := i
+	if p.norm(i) == "at" {
+		i++
+	}
+
+	word := p.norm(i)
+	if word == "noon" {
+		p.hour, p.minute, p.timeSet = 12, 0, true
+		return i - start + 1
+	}
+
+	m := clockTime.FindStringSubmatch(word)
+	if m == nil {
+		return 0
+	}
+	n := 1
+	suffix := m[3]
+	if suffix == "" && (p.norm(i+1) == "am" || p.norm(i+1) == "pm") {
+		suffix = p.norm(i + 1)
+		n++
+	}
+	// просто число — не время: "5" в "Buy 5 apples"
+	if m[2] == "" && suffix == "" {
+		return 0
+	}
+
+	hour, _ := strconv.Atoi(m[1])
+	minute := 0
+	if m[2] != "" {
+		minute, _ = strconv.Atoi(m[2])
+	}
+	if suffix != "" {
+		if hour < 1 || hour > 12 {
+			return 0
+		}
+		hour %= 12
+		if suffix == "pm" {
+			hour += 12
+		}
+	}
+	if hour > 23 || minute > 59 {
+		return 0
+	}
+
+	p.hour, p.minute, p.timeSet = hour, minute, true
+	return i - start + n
+}
+
+// dueDate срок из распознанных даты и времени. Время без даты — сегодня, а если оно уже прошло, завтра
+func (p *parser) dueDate() *time.Time {
+	if p.date == nil && !p.timeSet {
+		return nil
+	}
+
+	hour, minute := defaultHour, defaultMinute
+	if p.timeSet {
+		hour, minute = p.hour, p.minute
+	}
+
+	day := startOfDay(p.now)
+	if p.date != nil {
+		day = *p.date
+	}
+	due := time.Date(day.Year(), day.Month(), day.Day(), hour, minute, 0, 0, p.now.Location())
+	if p.date == nil && !due.After(p.now) {
+		due = due.AddDate(0, 0, 1)
+	}
+	return &due
+}
+
+// norm нормализованное i-е слово; за концом строки и для текста в кавычках — пустая строка
+func (p *parser) norm(i int) string {
+	if i >= len(p.tokens) || p.tokens[i].Quoted {
+		return ""
+	}
+	return p.tokens[i].Norm
+}
+
+// nextWeekday ближайший день недели day после today
+func nextWeekday(today time.Time, day time.Weekday) time.Time {
+	days := (int(day) - int(today.Weekday()) + 7) % 7
+	if days == 0 {
+		days = 7
+	}
+	return today.AddDate(0, 0, days)
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,68 @@
+package quickadd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	// среда, 10:00 в часовом поясе пользователя
+	loc := time.FixedZone("UTC+3", 3*60*60)
+	now := time.Date(2026, 1, 14, 10, 0, 0, 0, loc)
+	at := func(month time.Month, day, hour, minute int) *time.Time {
+		due := time.Date(2026, month, day, hour, minute, 0, 0, loc)
+		return &due
+	}
+
+	for _, tc := range []struct {
+		line     string
+		title    string
+		due      *time.Time
+		priority models.Priority
+		tags     []string
+	}{
+		{"Pay rent friday high priority #finance", "Pay rent", at(1, 16, 23, 59), models.PriorityHigh, []string{"finance"}},
+		{"Call mom tomorrow at 5pm", "Call mom", at(1, 15, 17, 0), "", nil},
+		// время уже прошло — завтра
+		{"Standup at 9:30", "Standup", at(1, 15, 9, 30), "", nil},
+		{"Lunch noon", "Lunch", at(1, 14, 12, 0), "", nil},
+		{"Send report by 31.01, !low", "Send report", at(1, 31, 23, 59), models.PriorityLow, nil},
+		{"Renew passport in 2 weeks priority medium", "Renew passport", at(1, 28, 23, 59), models.PriorityMedium, nil},
+		{"Review on 2026-02-03 at 14:15 #work #q1", "Review", at(2, 3, 14, 15), "", []string{"work", "q1"}},
+		{"Retro next week 11 am", "Retro", at(1, 19, 11, 0), "", nil},
+		// текст в кавычках не разбирается
+		{`"Friday review" next friday`, "Friday review", at(1, 16, 23, 59), "", nil},
+		// просто число не время, повторный срок остается в заголовке
+		{"Buy 5 apples", "Buy 5 apples", nil, "", nil},
+		{"Move friday meeting to monday", "Move meeting to monday", at(1, 16, 23, 59), "", nil},
+		{"Plan 31.02 party", "Plan 31.02 party", nil, "", nil},
+	} {
+		result := Parse(tc.line, now)
+		assert.Equal(t, tc.title, result.Title, tc.line)
+		assert.Equal(t, tc.priority, result.Priority, tc.line)
+		assert.Equal(t, tc.tags, result.Tags, tc.line)
+		if tc.due == nil {
+			assert.Nil(t, result.DueDate, tc.line)
+		} else if assert.NotNil(t, result.DueDate, tc.line) {
+			assert.True(t, tc.due.Equal(*result.DueDate), "%s: %s", tc.line, result.DueDate)
+		}
+	}
+
+	// дата без года, уже прошедшая, переносится на следующий год
+	result := Parse("Trip 10.01", now)
+	require.NotNil(t, result.DueDate)
+	assert.Equal(t, 2027, result.DueDate.Year())
+}
+
+func TestTokenize(t *testing.T) {
+	tokens := Tokenize(`Call  "Bob Smith" tomorrow, "unclosed quote`)
+	require.Len(t, tokens, 4)
+	assert.Equal(t, Token{Text: "Call", Norm: "call"}, tokens[0])
+	assert.Equal(t, Token{Text: "Bob Smith", Quoted: true}, tokens[1])
+	assert.Equal(t, "tomorrow", tokens[2].Norm)
+	assert.Equal(t, Token{Text: "unclosed quote", Quoted: true}, tokens[3])
+}
@@ -0,0 +1,65 @@
+// Package quickadd разбор строки быстрого добавления задачи, например
+// "Pay rent friday high priority #finance": заголовок, срок, приоритет и теги
+package quickadd
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Token слово строки быстрого добавления
+type Token struct {
+	// Text слово как в исходной строке
+	Text string
+	// Norm слово в нижнем регистре без знаков препинания по краям, по нему распознаются срок и приоритет
+	Norm string
+	// Quoted текст из двойных кавычек: всегда остается в заголовке как есть
+	Quoted bool
+}
+
+// Tokenize делит строку на слова по пробелам. Текст в двойных кавычках — одно слово, которое
+// не разбирается; незакрытая кавычка действует до конца строки
+func Tokenize(line string) []Token {
+	var tokens []Token
+	var word strings.Builder
+	quoted := false
+
+	flush := func() {
+		if word.Len() == 0 && !quoted {
+			return
+		}
+		text := word.String()
+		word.Reset()
+		if quoted {
+			if text != "" {
+				tokens = append(tokens, Token{Text: text, Quoted: true})
+			}
+			return
+		}
+		tokens = append(tokens, Token{Text: text, Norm: normalize(text)})
+	}
+
+	for _, r := range line {
+		switch {
+		case r == '"':
+			flush()
+			quoted = !quoted
+		case unicode.IsSpace(r) && !quoted:
+			flush()
+		default:
+			word.WriteRune(r)
+		}
+	}
+	flush()
+
+	return tokens
+}
+
+// normalize нижний регистр без знаков препинания по краям; "#" и "!" в начале сохраняются,
+// по ним распознаются теги и приоритет
+func normalize(word string) string {
+	word = strings.ToLower(word)
+	return strings.TrimFunc(word, func(r rune) bool {
+		return unicode.IsPunct(r) && r != '#' && r != '!'
+	})
+}
@@ -0,0 +1,371 @@
+package realtime
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/jmoloko/taskmange/internal/config"
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/jmoloko/taskmange/internal/metrics"
+)
+
+const (
+	defaultSendBuffer            = 64
+	defaultMaxConnectionsPerUser = 5
+)
+
+var (
+	// ErrTooManyConnections возвращается, если у пользователя превышен лимит подключений
+	ErrTooManyConnections = errors.New("too many realtime connections")
+	// ErrHubClosed возвращается после Close, когда сервер останавливается
+	ErrHubClosed = errors.New("realtime hub is closed")
+)
+
+// Event событие, доставляемое клиентам в реальном времени
+type Event struct {
+	// ID порядковый номер события в Hub, по нему клиент продолжает поток после переподключения
+	ID   uint64      `json:"id"`
+	Type string      `json:"type"`
+	Data interface{} `json:"data"`
+}
+
+// Hub распределяет события по подключениям пользователей.
+// Hub не зависит от транспорта: WebSocket/SSE обработчики подписываются через Subscribe
+// и вычитывают события из своего Client.
+// Последние события пользователя хранятся, пока он подключен, и еще ReplayWindow после отключения,
+// чтобы переподключившийся клиент получил пропущенное (Resume)
+type Hub struct {
+	mu                    sync.Mutex
+	clients               map[string]map[*Client]struct{}
+	history               map[string]*userHistory
+	lastID                uint64
+	closed                bool
+	sendBuffer            int
+	maxConnectionsPerUser int
+	replayWindow          time.Duration
+	now                   func() time.Time
+}
+
+// userHistory последние события пользователя по возрастанию ID, не больше sendBuffer
+type userHistory struct {
+	events []Event
+	// evicted наибольший ID, после которого события пользователя могли быть потеряны:
+	// вытесненные из истории или опубликованные до ее появления
+	evicted uint64
+	// disconnectedAt когда отключилось последнее подключение, нулевое, пока подключения есть
+	disconnectedAt time.Time
+}
+
+// NewHub создает новый экземпляр Hub
+func NewHub(cfg config.RealtimeConfig) *Hub {
+	sendBuffer := cfg.SendBuffer
+	if sendBuffer <= 0 {
+		sendBuffer = defaultSendBuffer
+	}
+
+	maxConnections := cfg.MaxConnectionsPerUser
+	if maxConnections <= 0 {
+		maxConnections = defaultMaxConnectionsPerUser
+	}
+
+	// номера продолжают расти после перезапуска, поэтому ID прежнего процесса
+	// не совпадет с новым событием и приведет к полной пересинхронизации клиента
+	return &Hub{
+		clients:               make(map[string]map[*Client]struct{}),
+		history:               make(map[string]*userHistory),
+		lastID:                uint64(time.Now().UnixMicro()),
+		sendBuffer:            sendBuffer,
+		maxConnectionsPerUser: maxConnections,
+		replayWindow:          cfg.ReplayWindow,
+		now:                   time.Now,
+	}
+}
+
+// Subscribe регистрирует новое подключение пользователя
+func (h *Hub) Subscribe(userID string) (*Client, error) {
+	client, _, err := h.subscribe(userID, 0, false)
+	return client, err
+}
+
+// Resume регистрирует подключение, которое продолжает поток после события lastEventID:
+// события пользователя после него сразу попадают в буфер Client. complete false, если часть
+// событий после lastEventID уже не сохранилась, тогда клиенту нужно перечитать данные целиком
+func (h *Hub) Resume(userID string, lastEventID uint64) (client *Client, complete bool, err error) {
+	return h.subscribe(userID, lastEventID, true)
+}
+
+func (h *Hub) subscribe(userID string, lastEventID uint64, resume bool) (*Client, bool, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed {
+		return nil, false, ErrHubClosed
+	}
+
+	userClients := h.clients[userID]
+	if len(userClients) >= h.maxConnectionsPerUser {
+		metrics.RealtimeRejectedConnectionsTotal.Inc()
+		return nil, false, ErrTooManyConnections
+	}
+
+	if userClients == nil {
+		userClients = make(map[*Client]struct{})
+		h.clients[userID] = userClients
+	}
+
+	history := h.userHistory(userID)
+	if history == nil {
+		history = &userHistory{evicted: h.lastID}
+		h.history[userID] = history
+	}
+	history.disconnectedAt = time.Time{}
+
+	client := newClient(userID, h.sendBuffer)
+	complete := true
+	if resume {
+		complete = lastEventID >= history.evicted && lastEventID <= h.lastID
+		for _, event := range history.events {
+			if event.ID > lastEventID {
+				client.push(event)
+			}
+		}
+	}
+
+	userClients[client] = struct{}{}
+	metrics.RealtimeConnections.Inc()
+
+	return client, complete, nil
+}
+
+// Unsubscribe удаляет подключение и закрывает его
+func (h *Hub) Unsubscribe(client *Client) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	userClients, ok := h.clients[client.userID]
+	if !ok {
+		return
+	}
+
+	if _, ok := userClients[client]; !ok {
+		return
+	}
+
+	delete(userClients, client)
+	if len(userClients) == 0 {
+		delete(h.clients, client.userID)
+		if history := h.history[client.userID]; history != nil {
+			history.disconnectedAt = h.now()
+		}
+		h.expireHistory()
+	}
+
+	client.close()
+	metrics.RealtimeConnections.Dec()
+}
+
+// Publish отправляет событие во все подключения пользователя и присваивает ему ID.
+// Publish никогда не блокируется: медленный клиент теряет самые старые события
+func (h *Hub) Publish(userID string, event Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed {
+		return
+	}
+
+	h.lastID++
+	event.ID = h.lastID
+
+	if history := h.userHistory(userID); history != nil {
+		history.events = append(history.events, event)
+		if len(history.events) > h.sendBuffer {
+			history.evicted = history.events[0].ID
+			history.events = append(history.events[:0], history.events[1:]...)
+		}
+	}
+
+	for client := range h.clients[userID] {
+		if client.push(event) {
+			metrics.RealtimeDroppedEventsTotal.Inc()
+		}
+	}
+}
+
+// HandleEvent обработчик шины событий: событие задачи уходит владельцу и исполнителю
+func (h *Hub) HandleEvent(ctx context.Context, event models.TaskEvent) error {
+	recipients := []string{event.UserID}
+	tasks := append([]models.Task{event.Task}, event.Tasks...)
+	for _, task := range tasks {
+		if task.AssigneeID == nil || *task.AssigneeID == event.UserID {
+			continue
+		}
+		duplicate := false
+		for _, recipient := range recipients {
+			duplicate = duplicate || recipient == *task.AssigneeID
+		}
+		if !duplicate {
+			recipients = append(recipients, *task.AssigneeID)
+		}
+	}
+
+	for _, userID := range recipients {
+		h.Publish(userID, Event{Type: string(event.Type), Data: event})
+	}
+
+	return nil
+}
+
+// Connections возвращает число подключений пользователя
+func (h *Hub) Connections(userID string) int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return len(h.clients[userID])
+}
+
+// Close закрывает все подключения и перестает принимать новые; вызывается при остановке
+// сервера, чтобы потоки событий не задерживали ее
+func (h *Hub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed {
+		return
+	}
+	h.closed = true
+
+	for userID, userClients := range h.clients {
+		for client := range userClients {
+			client.close()
+			metrics.RealtimeConnections.Dec()
+		}
+		delete(h.clients, userID)
+	}
+	h.history = make(map[string]*userHistory)
+}
+
+// userHistory история пользователя или nil, если ее нет или она устарела; вызывается под h.mu
+func (h *Hub) userHistory(userID string) *userHistory {
+	history := h.history[userID]
+	if history != nil && len(h.clients[userID]) == 0 && h.now().Sub(history.disconnectedAt) > h.replayWindow {
+		delete(h.history, userID)
+		return nil
+	}
+	return history
+}
+
+// expireHistory удаляет истории пользователей, отключившихся дольше ReplayWindow назад; вызывается под h.mu
+func (h *Hub) expireHistory() {
+	for userID := range h.history {
+		h.userHistory(userID)
+	}
+}
+
+// Client одно подключение пользователя с ограниченным буфером отправки
+type Client struct {
+	userID string
+
+	mu      sync.Mutex
+	buffer  []Event
+	head    int
+	size    int
+	dropped bool
+	closed  bool
+
+	notify chan struct{}
+	done   chan struct{}
+}
+
+func newClient(userID string, bufferSize int) *Client {
+	return &Client{
+		userID: userID,
+		buffer: make([]Event, bufferSize),
+		notify: make(chan struct{}, 1),
+		done:   make(chan struct{}),
+	}
+}
+
+// UserID возвращает ID владельца подключения
+func (c *Client) UserID() string {
+	return c.userID
+}
+
+// Notify сигнализирует о появлении новых событий в буфере
+func (c *Client) Notify() <-chan struct{} {
+	return c.notify
+}
+
+// Done закрывается, когда подключение удалено из Hub
+func (c *Client) Done() <-chan struct{} {
+	return c.done
+}
+
+// Drain забирает все накопленные события в порядке поступления
+func (c *Client) Drain() []Event {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	events := make([]Event, 0, c.size)
+	for c.size > 0 {
+		events = append(events, c.buffer[c.head])
+		c.buffer[c.head] = Event{}
+		c.head = (c.head + 1) % len(c.buffer)
+		c.size--
+	}
+
+	return events
+}
+
+// Overflowed сообщает, были ли вытеснены события с прошлого вызова
+func (c *Client) Overflowed() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	dropped := c.dropped
+	c.dropped = false
+	return dropped
+}
+
+// push добавляет событие в кольцевой буфер, вытесняя самое старое при переполнении.
+// Возвращает true, если событие было отброшено
+func (c *Client) push(event Event) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return false
+	}
+
+	dropped := false
+	if c.size == len(c.buffer) {
+		c.head = (c.head + 1) % len(c.buffer)
+		c.size--
+		dropped = true
+		c.dropped = true
+	}
+
+	c.buffer[(c.head+c.size)%len(c.buffer)] = event
+	c.size++
+
+	select {
+	case c.notify <- struct{}{}:
+	default:
+	}
+
+	return dropped
+}
+
+func (c *Client) close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return
+	}
+
+	c.closed = true
+	close(c.done)
+}
@@ -0,0 +1,124 @@
+package realtime
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jmoloko/taskmange/internal/config"
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHub_DropOldest(t *testing.T) {
+	hub := NewHub(config.RealtimeConfig{SendBuffer: 2, MaxConnectionsPerUser: 1})
+
+	client, err := hub.Subscribe("user1")
+	require.NoError(t, err)
+
+	hub.Publish("user1", Event{Type: "1"})
+	hub.Publish("user1", Event{Type: "2"})
+	hub.Publish("user1", Event{Type: "3"})
+
+	events := client.Drain()
+	require.Len(t, events, 2)
+	assert.Equal(t, "2", events[0].Type)
+	assert.Equal(t, "3", events[1].Type)
+	assert.Empty(t, client.Drain())
+}
+
+func TestHub_MaxConnectionsPerUser(t *testing.T) {
+	hub := NewHub(config.RealtimeConfig{SendBuffer: 2, MaxConnectionsPerUser: 1})
+
+	client, err := hub.Subscribe("user1")
+	require.NoError(t, err)
+
+	_, err = hub.Subscribe("user1")
+	assert.Equal(t, ErrTooManyConnections, err)
+
+	hub.Unsubscribe(client)
+	assert.Equal(t, 0, hub.Connections("user1"))
+
+	select {
+	case <-client.Done():
+	default:
+		t.Fatal("client should be closed after unsubscribe")
+	}
+
+	_, err = hub.Subscribe("user1")
+	assert.NoError(t, err)
+}
+
+func TestHub_Resume(t *testing.T) {
+	hub := NewHub(config.RealtimeConfig{SendBuffer: 2, MaxConnectionsPerUser: 1, ReplayWindow: time.Minute})
+	now := time.Now()
+	hub.now = func() time.Time { return now }
+
+	client, err := hub.Subscribe("user1")
+	require.NoError(t, err)
+	hub.Publish("user1", Event{Type: "1"})
+	hub.Publish("user1", Event{Type: "2"})
+	hub.Publish("user1", Event{Type: "3"})
+	events := client.Drain()
+	require.Len(t, events, 2)
+	assert.True(t, client.Overflowed())
+	assert.False(t, client.Overflowed())
+	hub.Unsubscribe(client)
+
+	// пока клиент отключен, события сохраняются в пределах буфера
+	hub.Publish("user1", Event{Type: "4"})
+
+	client, complete, err := hub.Resume("user1", events[0].ID)
+	require.NoError(t, err)
+	assert.True(t, complete)
+	resumed := client.Drain()
+	require.Len(t, resumed, 2)
+	assert.Equal(t, "3", resumed[0].Type)
+	assert.Equal(t, "4", resumed[1].Type)
+	hub.Unsubscribe(client)
+
+	// событие "2" вытеснено из истории, клиенту нужна полная пересинхронизация
+	client, complete, err = hub.Resume("user1", events[0].ID-1)
+	require.NoError(t, err)
+	assert.False(t, complete)
+	hub.Unsubscribe(client)
+
+	// после окна хранения история удаляется
+	now = now.Add(2 * time.Minute)
+	client, complete, err = hub.Resume("user1", resumed[1].ID)
+	require.NoError(t, err)
+	assert.True(t, complete, "nothing was published since the last event")
+	assert.Empty(t, client.Drain())
+	hub.Unsubscribe(client)
+
+	now = now.Add(2 * time.Minute)
+	hub.Publish("user1", Event{Type: "5"})
+	_, complete, err = hub.Resume("user1", resumed[1].ID)
+	require.NoError(t, err)
+	assert.False(t, complete)
+}
+
+func TestHub_HandleEvent(t *testing.T) {
+	hub := NewHub(config.RealtimeConfig{})
+	owner, err := hub.Subscribe("owner")
+	require.NoError(t, err)
+	assignee, err := hub.Subscribe("assignee")
+	require.NoError(t, err)
+
+	assigneeID := "assignee"
+	event := models.TaskEvent{Type: models.EventTaskUpdated, UserID: "owner", Task: models.Task{ID: "1", AssigneeID: &assigneeID}}
+	require.NoError(t, hub.HandleEvent(context.Background(), event))
+
+	for _, client := range []*Client{owner, assignee} {
+		events := client.Drain()
+		require.Len(t, events, 1)
+		assert.Equal(t, "task.updated", events[0].Type)
+		assert.Equal(t, event, events[0].Data)
+	}
+
+	hub.Close()
+	<-owner.Done()
+	_, err = hub.Subscribe("owner")
+	assert.Equal(t, ErrHubClosed, err)
+}
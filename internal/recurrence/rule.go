@@ -0,0 +1,245 @@
+// Package recurrence правила повторения задач — подмножество RRULE из RFC 5545
+package recurrence
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Frequency период повторения
+type Frequency string
+
+const (
+	Daily   Frequency = "DAILY"
+	Weekly  Frequency = "WEEKLY"
+	Monthly Frequency = "MONTHLY"
+	Yearly  Frequency = "YEARLY"
+)
+
+const (
+	// MaxRuleLength наибольшая длина правила
+	MaxRuleLength = 200
+	maxInterval   = 1000
+	maxMonthSteps = 1000
+	untilLayout   = "20060102T150405Z"
+	untilDate     = "20060102"
+)
+
+// ErrInvalidRule правило не разобрано или использует неподдерживаемые части RRULE
+var ErrInvalidRule = errors.New("invalid recurrence rule")
+
+var weekdays = map[string]time.Weekday{
+	"MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday, "TH": time.Thursday,
+	"FR": time.Friday, "SA": time.Saturday, "SU": time.Sunday,
+}
+
+var weekdayCodes = [...]string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"}
+
+// Rule правило повторения. Повторы отсчитываются от срока предыдущего экземпляра и сохраняют
+// его время суток; дни недели и месяца считаются в UTC
+type Rule struct {
+	Freq     Frequency
+	Interval int
+	// ByDay дни недели, только для WEEKLY
+	ByDay []time.Weekday
+	// Count число экземпляров серии вместе с первым, 0 — без ограничения
+	Count int
+	// Until последний допустимый срок экземпляра, nil — без ограничения
+	Until *time.Time
+}
+
+// Parse разбирает правило вида FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH;COUNT=10 (префикс RRULE: допускается).
+// Поддерживаются FREQ, INTERVAL, BYDAY (для WEEKLY), COUNT и UNTIL; COUNT и UNTIL взаимоисключающие
+func Parse(s string) (*Rule, error) {
+	s = strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(s)), "RRULE:")
+	if s == "" || len(s) > MaxRuleLength {
+		return nil, ErrInvalidRule
+	}
+
+	rule := &Rule{Interval: 1}
+	seen := make(map[string]bool)
+	for _, part := range strings.Split(s, ";") {
+		key, value, ok := strings.Cut(part, "=")
+		if !ok || value == "" || seen[key] {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidRule, part)
+		}
+		seen[key] = true
+
+		switch key {
+		case "FREQ":
+			rule.Freq = Frequency(value)
+			if rule.Freq != Daily && rule.Freq != Weekly && rule.Freq != Monthly && rule.Freq != Yearly {
+				return nil, fmt.Errorf("%w: unsupported FREQ %s", ErrInvalidRule, value)
+			}
+		case "INTERVAL":
+			interval, err := strconv.Atoi(value)
+			if err != nil || interval < 1 || interval > maxInterval {
+				return nil, fmt.Errorf("%w: INTERVAL must be between 1 and %d", ErrInvalidRule, maxInterval)
+			}
+			rule.Interval = interval
+		case "BYDAY":
+			for _, code := range strings.Split(value, ",") {
+				day, ok := weekdays[code]
+				if !ok {
+					return nil, fmt.Errorf("%w: unsupported BYDAY %s", ErrInvalidRule, code)
+				}
+				rule.ByDay = append(rule.ByDay, day)
+			}
+		case "COUNT":
+			count, err := strconv.Atoi(value)
+			if err != nil || count < 1 {
+				return nil, fmt.Errorf("%w: COUNT must be positive", ErrInvalidRule)
+			}
+			rule.Count = count
+		case "UNTIL":
+			until, err := parseUntil(value)
+			if err != nil {
+				return nil, err
+			}
+			rule.Until = &until
+		default:
+			return nil, fmt.Errorf("%w: unsupported part %s", ErrInvalidRule, key)
+		}
+	}
+
+	switch {
+	case rule.Freq == "":
+		return nil, fmt.Errorf("%w: FREQ is required", ErrInvalidRule)
+	case len(rule.ByDay) > 0 && rule.Freq != Weekly:
+		return nil, fmt.Errorf("%w: BYDAY is supported only with FREQ=WEEKLY", ErrInvalidRule)
+	case rule.Count > 0 && rule.Until != nil:
+		return nil, fmt.Errorf("%w: COUNT and UNTIL cannot be combined", ErrInvalidRule)
+	}
+
+	return rule, nil
+}
+
+// UNTIL допускается датой (до конца дня в UTC) или моментом в UTC
+func parseUntil(value string) (time.Time, error) {
+	if until, err := time.Parse(untilLayout, value); err == nil {
+		return until, nil
+	}
+	until, err := time.Parse(untilDate, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: UNTIL must be YYYYMMDD or YYYYMMDDTHHMMSSZ", ErrInvalidRule)
+	}
+	return until.Add(24*time.Hour - time.Second), nil
+}
+
+// String правило в каноническом виде, в нем оно хранится у задач
+func (r *Rule) String() string {
+	parts := []string{"FREQ=" + string(r.Freq)}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if len(r.ByDay) > 0 {
+		codes := make([]string, len(r.ByDay))
+		for i, day := range r.ByDay {
+			codes[i] = weekdayCodes[day]
+		}
+		parts = append(parts, "BYDAY="+strings.Join(codes, ","))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	if r.Until != nil {
+		parts = append(parts, "UNTIL="+r.Until.UTC().Format(untilLayout))
+	}
+	return strings.Join(parts, ";")
+}
+
+// Exhausted серия из occurrences экземпляров достигла COUNT
+func (r *Rule) Exhausted(occurrences int) bool {
+	return r.Count > 0 && occurrences >= r.Count
+}
+
+// Next первый срок серии позже after; anchor — срок предыдущего экземпляра, от него отсчитывается
+// INTERVAL. ok = false, если следующий срок позже UNTIL
+func (r *Rule) Next(anchor, after time.Time) (time.Time, bool) {
+	anchor = anchor.UTC()
+	if after.Before(anchor) {
+		after = anchor
+	}
+
+	next, ok := time.Time{}, true
+	switch r.Freq {
+	case Daily:
+		next = r.nextByDays(anchor, after, r.Interval)
+	case Weekly:
+		if len(r.ByDay) == 0 {
+			next = r.nextByDays(anchor, after, 7*r.Interval)
+		} else {
+			next = r.nextWeekday(anchor, after)
+		}
+	case Monthly:
+		next, ok = r.nextByMonths(anchor, after, r.Interval)
+	case Yearly:
+		next, ok = r.nextByMonths(anchor, after, 12*r.Interval)
+	}
+
+	if !ok || (r.Until != nil && next.After(*r.Until)) {
+		return time.Time{}, false
+	}
+	return next, true
+}
+
+// nextByDays повтор каждые step дней
+func (r *Rule) nextByDays(anchor, after time.Time, step int) time.Time {
+	days := int(after.Sub(anchor).Hours()/24) / step * step
+	next := anchor.AddDate(0, 0, days)
+	for !next.After(after) {
+		next = next.AddDate(0, 0, step)
+	}
+	return next
+}
+
+// nextWeekday ближайший из дней BYDAY в неделях, отстоящих от недели anchor на кратное INTERVAL
+func (r *Rule) nextWeekday(anchor, after time.Time) time.Time {
+	weekStart := mondayOf(anchor)
+	// сразу переходим к неделе серии, в которую попадает after
+	weeks := int(after.Sub(weekStart).Hours()/24) / 7 / r.Interval * r.Interval
+	day := weekStart.AddDate(0, 0, 7*weeks)
+	clock := anchor.Sub(time.Date(anchor.Year(), anchor.Month(), anchor.Day(), 0, 0, 0, 0, time.UTC))
+
+	for {
+		for i := 0; i < 7; i++ {
+			candidate := day.AddDate(0, 0, i).Add(clock)
+			if candidate.After(after) && r.hasDay(candidate.Weekday()) {
+				return candidate
+			}
+		}
+		day = day.AddDate(0, 0, 7*r.Interval)
+	}
+}
+
+// nextByMonths повтор каждые step месяцев в тот же день месяца; месяцы без этого дня пропускаются.
+// Перебор ограничен: 29 февраля с большим INTERVAL может не повториться никогда
+func (r *Rule) nextByMonths(anchor, after time.Time, step int) (time.Time, bool) {
+	months := ((after.Year()-anchor.Year())*12 + int(after.Month()-anchor.Month())) / step * step
+	for i := 0; i < maxMonthSteps; i, months = i+1, months+step {
+		candidate := time.Date(anchor.Year(), anchor.Month()+time.Month(months), anchor.Day(),
+			anchor.Hour(), anchor.Minute(), anchor.Second(), anchor.Nanosecond(), time.UTC)
+		if candidate.Day() == anchor.Day() && candidate.After(after) {
+			return candidate, true
+		}
+	}
+	return time.Time{}, false
+}
+
+func (r *Rule) hasDay(day time.Weekday) bool {
+	for _, d := range r.ByDay {
+		if d == day {
+			return true
+		}
+	}
+	return false
+}
+
+// mondayOf полночь понедельника недели t
+func mondayOf(t time.Time) time.Time {
+	midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	return midnight.AddDate(0, 0, -int(t.Weekday()+6)%7)
+}
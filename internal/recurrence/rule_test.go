@@ -0,0 +1,78 @@
+package recurrence
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func date(s string) time.Time {
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestParse(t *testing.T) {
+	rule, err := Parse("rrule:freq=weekly;byday=mo,th;interval=2;count=10")
+	require.NoError(t, err)
+	assert.Equal(t, "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH;COUNT=10", rule.String())
+	assert.False(t, rule.Exhausted(9))
+	assert.True(t, rule.Exhausted(10))
+
+	rule, err = Parse("FREQ=DAILY;UNTIL=20260131")
+	require.NoError(t, err)
+	assert.Equal(t, "FREQ=DAILY;UNTIL=20260131T235959Z", rule.String())
+
+	for _, invalid := range []string{
+		"",
+		"INTERVAL=2",
+		"FREQ=HOURLY",
+		"FREQ=DAILY;INTERVAL=0",
+		"FREQ=DAILY;BYDAY=MO",
+		"FREQ=WEEKLY;BYDAY=XX",
+		"FREQ=DAILY;COUNT=3;UNTIL=20260101",
+		"FREQ=DAILY;FREQ=WEEKLY",
+		"FREQ=DAILY;BYMONTHDAY=1",
+	} {
+		_, err := Parse(invalid)
+		assert.ErrorIs(t, err, ErrInvalidRule, invalid)
+	}
+}
+
+func TestNext(t *testing.T) {
+	tests := []struct {
+		rule   string
+		anchor string
+		after  string
+		want   string
+	}{
+		// выполнена раньше срока: следующий срок считается от срока, а не от момента выполнения
+		{"FREQ=DAILY", "2026-03-10T09:00:00Z", "2026-03-09T18:00:00Z", "2026-03-11T09:00:00Z"},
+		// просрочена на несколько периодов: пропущенные сроки не создаются
+		{"FREQ=DAILY;INTERVAL=3", "2026-03-10T09:00:00Z", "2026-03-20T12:00:00Z", "2026-03-22T09:00:00Z"},
+		{"FREQ=WEEKLY", "2026-03-10T09:00:00Z", "2026-03-10T09:00:00Z", "2026-03-17T09:00:00Z"},
+		// вторник -> четверг той же недели, затем понедельник через неделю
+		{"FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH", "2026-03-10T09:00:00Z", "2026-03-10T09:00:00Z", "2026-03-12T09:00:00Z"},
+		{"FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH", "2026-03-12T09:00:00Z", "2026-03-12T09:00:00Z", "2026-03-23T09:00:00Z"},
+		{"FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH", "2026-03-12T09:00:00Z", "2026-04-01T12:00:00Z", "2026-04-06T09:00:00Z"},
+		// 31-е: месяцы без 31 числа пропускаются
+		{"FREQ=MONTHLY", "2026-01-31T09:00:00Z", "2026-01-31T09:00:00Z", "2026-03-31T09:00:00Z"},
+		{"FREQ=YEARLY", "2024-02-29T09:00:00Z", "2024-02-29T09:00:00Z", "2028-02-29T09:00:00Z"},
+	}
+	for _, tt := range tests {
+		rule, err := Parse(tt.rule)
+		require.NoError(t, err)
+		next, ok := rule.Next(date(tt.anchor), date(tt.after))
+		require.True(t, ok, tt.rule)
+		assert.Equal(t, date(tt.want), next, "%s from %s", tt.rule, tt.anchor)
+	}
+
+	rule, err := Parse("FREQ=WEEKLY;UNTIL=20260315")
+	require.NoError(t, err)
+	_, ok := rule.Next(date("2026-03-10T09:00:00Z"), date("2026-03-10T09:00:00Z"))
+	assert.False(t, ok)
+}
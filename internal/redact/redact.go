@@ -0,0 +1,227 @@
+package redact
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/jmoloko/taskmange/internal/domain/models"
+)
+
+// Rule правило редактирования, из правил состоят профили
+type Rule string
+
+const (
+	// RuleDescriptions убирает описания задач
+	RuleDescriptions Rule = "descriptions"
+	// RuleEmails заменяет адреса email псевдонимами: в полях пользователей, названиях и описаниях задач
+	RuleEmails Rule = "emails"
+)
+
+var ErrUnknownProfile = errors.New("unknown redaction profile")
+
+// emailPattern адрес email внутри произвольного текста
+var emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+
+// Profile набор правил, применяемых к данным перед отдачей наружу. Нулевой профиль ничего не меняет
+type Profile struct {
+	StripDescriptions bool
+	AnonymizeEmails   bool
+}
+
+// ParseProfile разбирает правила профиля, перечисленные через "+", например "descriptions+emails"
+func ParseProfile(rules string) (Profile, error) {
+	var profile Profile
+	for _, rule := range strings.Split(rules, "+") {
+		switch Rule(strings.TrimSpace(rule)) {
+		case RuleDescriptions:
+			profile.StripDescriptions = true
+		case RuleEmails:
+			profile.AnonymizeEmails = true
+		default:
+			return Profile{}, fmt.Errorf("unknown redaction rule %q", rule)
+		}
+	}
+	return profile, nil
+}
+
+// Union профиль с правилами обоих профилей
+func (p Profile) Union(other Profile) Profile {
+	return Profile{
+		StripDescriptions: p.StripDescriptions || other.StripDescriptions,
+		AnonymizeEmails:   p.AnonymizeEmails || other.AnonymizeEmails,
+	}
+}
+
+// Task копия задачи с примененными правилами
+func (p Profile) Task(task models.Task) models.Task {
+	if p.StripDescriptions {
+		task.Description = ""
+	}
+	if p.AnonymizeEmails {
+		task.Title = p.Text(task.Title)
+		task.Description = p.Text(task.Description)
+	}
+	return task
+}
+
+// Changes копия измененных полей задачи с примененными правилами: к значениям title и description
+// применяются те же правила, что к задаче
+func (p Profile) Changes(changes []models.FieldChange) []models.FieldChange {
+	if p == (Profile{}) || len(changes) == 0 {
+		return changes
+	}
+
+	redacted := make([]models.FieldChange, len(changes))
+	for i, change := range changes {
+		switch {
+		case change.Field == "description" && p.StripDescriptions:
+			change.From, change.To = nil, nil
+		case change.Field == "title" || change.Field == "description":
+			change.From, change.To = p.textValue(change.From), p.textValue(change.To)
+		}
+		redacted[i] = change
+	}
+	return redacted
+}
+
+// textValue текстовое значение изменения с замененными адресами email
+func (p Profile) textValue(value interface{}) interface{} {
+	if text, ok := value.(string); ok {
+		return p.Text(text)
+	}
+	return value
+}
+
+// Tasks копии задач с примененными правилами, исходный срез не меняется
+func (p Profile) Tasks(tasks []models.Task) []models.Task {
+	if p == (Profile{}) {
+		return tasks
+	}
+
+	redacted := make([]models.Task, len(tasks))
+	for i, task := range tasks {
+		redacted[i] = p.Task(task)
+	}
+	return redacted
+}
+
+// Email адрес пользователя или его псевдоним
+func (p Profile) Email(email string) string {
+	if !p.AnonymizeEmails || email == "" {
+		return email
+	}
+	return pseudonym(email)
+}
+
+// Text текст с замененными адресами email
+func (p Profile) Text(text string) string {
+	if !p.AnonymizeEmails {
+		return text
+	}
+	return emailPattern.ReplaceAllStringFunc(text, pseudonym)
+}
+
+// pseudonym постоянный псевдоним адреса: один адрес во всех записях выглядит одинаково,
+// и строки одного пользователя можно сопоставить, не зная адреса
+func pseudonym(email string) string {
+	sum := sha256.Sum256([]byte(strings.ToLower(email)))
+	return "user-" + hex.EncodeToString(sum[:4]) + "@redacted.invalid"
+}
+
+// Policy профили установки: встроенные по названию правила и заданные в конфигурации, а также
+// профили, которые применяются к экспорту и административным ответам всегда.
+// Профиль из запроса только добавляет правила к профилю по умолчанию. Нулевой Policy без
+// профилей по умолчанию, методы допускают nil
+type Policy struct {
+	profiles map[string]Profile
+	export   Profile
+	admin    Profile
+}
+
+// NewPolicy разбирает профили вида "name:rule+rule"; export и admin — названия профилей
+// по умолчанию для экспорта задач и административных ответов, пустое название — без редактирования
+func NewPolicy(specs []string, export, admin string) (*Policy, error) {
+	policy := &Policy{profiles: builtinProfiles()}
+	for _, spec := range specs {
+		name, rules, ok := strings.Cut(spec, ":")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("redaction profile %q must look like name:rule+rule", spec)
+		}
+		if _, exists := policy.profiles[name]; exists {
+			return nil, fmt.Errorf("redaction profile %q is defined twice", name)
+		}
+		profile, err := ParseProfile(rules)
+		if err != nil {
+			return nil, fmt.Errorf("redaction profile %q: %w", name, err)
+		}
+		policy.profiles[name] = profile
+	}
+
+	var err error
+	if policy.export, err = policy.lookup(export); err != nil {
+		return nil, fmt.Errorf("export redaction profile: %w", err)
+	}
+	if policy.admin, err = policy.lookup(admin); err != nil {
+		return nil, fmt.Errorf("admin redaction profile: %w", err)
+	}
+
+	return policy, nil
+}
+
+// Export профиль экспорта задач: профиль по умолчанию плюс профили из запроса через запятую
+func (p *Policy) Export(names string) (Profile, error) {
+	if p == nil {
+		p = &Policy{}
+	}
+	return p.resolve(p.export, names)
+}
+
+// Admin профиль административных ответов со списками пользователей
+func (p *Policy) Admin(names string) (Profile, error) {
+	if p == nil {
+		p = &Policy{}
+	}
+	return p.resolve(p.admin, names)
+}
+
+func (p *Policy) resolve(base Profile, names string) (Profile, error) {
+	for _, name := range strings.Split(names, ",") {
+		profile, err := p.lookup(name)
+		if err != nil {
+			return Profile{}, err
+		}
+		base = base.Union(profile)
+	}
+	return base, nil
+}
+
+// lookup профиль по названию, пустое название — пустой профиль
+func (p *Policy) lookup(name string) (Profile, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return Profile{}, nil
+	}
+
+	profiles := p.profiles
+	if profiles == nil {
+		profiles = builtinProfiles()
+	}
+	profile, ok := profiles[name]
+	if !ok {
+		return Profile{}, ErrUnknownProfile
+	}
+	return profile, nil
+}
+
+// builtinProfiles профили из одного правила с его названием
+func builtinProfiles() map[string]Profile {
+	return map[string]Profile{
+		string(RuleDescriptions): {StripDescriptions: true},
+		string(RuleEmails):       {AnonymizeEmails: true},
+	}
+}
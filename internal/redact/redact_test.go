@@ -0,0 +1,85 @@
+package redact
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProfileTasks(t *testing.T) {
+	tasks := []models.Task{{
+		ID:          "1",
+		Title:       "Call Anna@example.com",
+		Description: "cc boss@example.org",
+	}}
+
+	profile, err := ParseProfile("emails")
+	require.NoError(t, err)
+	redacted := profile.Tasks(tasks)
+	assert.NotContains(t, redacted[0].Title, "example.com")
+	assert.True(t, strings.HasPrefix(redacted[0].Title, "Call user-"))
+	assert.NotContains(t, redacted[0].Description, "boss@")
+	// псевдоним не зависит от регистра и совпадает в тексте и в полях пользователей
+	assert.Equal(t, profile.Email("anna@example.com"), strings.TrimPrefix(redacted[0].Title, "Call "))
+	assert.Equal(t, "Call Anna@example.com", tasks[0].Title, "source tasks must not change")
+
+	profile, err = ParseProfile("descriptions+emails")
+	require.NoError(t, err)
+	assert.Empty(t, profile.Tasks(tasks)[0].Description)
+
+	_, err = ParseProfile("attachments")
+	assert.Error(t, err)
+
+	assert.Equal(t, "a@b.io", Profile{}.Email("a@b.io"))
+}
+
+func TestProfileChanges(t *testing.T) {
+	changes := []models.FieldChange{
+		{Field: "title", From: "Call anna@example.com", To: "Call Anna"},
+		{Field: "description", From: "draft", To: "final"},
+		{Field: "status", From: "pending", To: "done"},
+	}
+
+	profile, err := ParseProfile("descriptions+emails")
+	require.NoError(t, err)
+	redacted := profile.Changes(changes)
+	assert.Equal(t, profile.Email("anna@example.com"), strings.TrimPrefix(redacted[0].From.(string), "Call "))
+	assert.Equal(t, models.FieldChange{Field: "description"}, redacted[1])
+	assert.Equal(t, changes[2], redacted[2])
+	assert.Equal(t, "draft", changes[1].From, "source changes must not change")
+}
+
+func TestPolicy(t *testing.T) {
+	_, err := NewPolicy([]string{"public"}, "", "")
+	assert.Error(t, err)
+	_, err = NewPolicy([]string{"emails:descriptions"}, "", "")
+	assert.Error(t, err)
+	_, err = NewPolicy(nil, "missing", "")
+	assert.ErrorIs(t, err, ErrUnknownProfile)
+
+	policy, err := NewPolicy([]string{"public:descriptions+emails"}, "emails", "")
+	require.NoError(t, err)
+
+	// профиль из запроса добавляет правила к профилю по умолчанию, но не снимает его
+	profile, err := policy.Export("")
+	require.NoError(t, err)
+	assert.Equal(t, Profile{AnonymizeEmails: true}, profile)
+	profile, err = policy.Export("descriptions")
+	require.NoError(t, err)
+	assert.Equal(t, Profile{StripDescriptions: true, AnonymizeEmails: true}, profile)
+
+	profile, err = policy.Admin("public")
+	require.NoError(t, err)
+	assert.Equal(t, Profile{StripDescriptions: true, AnonymizeEmails: true}, profile)
+	_, err = policy.Admin("unknown")
+	assert.Equal(t, ErrUnknownProfile, err)
+
+	// без настроек доступны встроенные профили
+	var none *Policy
+	profile, err = none.Export("emails")
+	require.NoError(t, err)
+	assert.Equal(t, Profile{AnonymizeEmails: true}, profile)
+}
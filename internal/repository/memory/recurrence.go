@@ -0,0 +1,117 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/jmoloko/taskmange/internal/domain/repository"
+)
+
+// серия повторяющейся задачи
+func (r *TaskRepository) GetRecurrence(ctx context.Context, id string) (*models.Recurrence, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	recurrence, ok := r.recurrences[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+
+	return &recurrence, nil
+}
+
+// приостанавливаем или возобновляем серию
+func (r *TaskRepository) SetRecurrencePaused(ctx context.Context, id string, paused bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	recurrence, ok := r.recurrences[id]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	recurrence.Paused, recurrence.UpdatedAt = paused, r.now()
+	r.recurrences[id] = recurrence
+
+	return nil
+}
+
+// последние экземпляры активных серий, которые выполнены или просрочены
+func (r *TaskRepository) GetDueOccurrences(ctx context.Context, now time.Time, limit int) ([]models.DueOccurrence, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var occurrences []models.DueOccurrence
+	for _, recurrence := range r.recurrences {
+		if recurrence.LastTaskID == "" || recurrence.Paused {
+			continue
+		}
+		task, ok := r.tasks[recurrence.LastTaskID]
+		if !ok || (task.Status != models.StatusDone && !task.DueDate.Before(now)) {
+			continue
+		}
+		occurrences = append(occurrences, models.DueOccurrence{
+			Task:        cloneTask(task),
+			Rule:        recurrence.Rule,
+			Occurrences: recurrence.Occurrences,
+		})
+	}
+	sort.Slice(occurrences, func(i, j int) bool {
+		return occurrences[i].Task.DueDate.Before(occurrences[j].Task.DueDate)
+	})
+	if limit > 0 && len(occurrences) > limit {
+		occurrences = occurrences[:limit]
+	}
+
+	return occurrences, nil
+}
+
+// создаём следующий экземпляр серии копией sourceID; ErrNotFound, если sourceID уже не последний
+// экземпляр или серия приостановлена. Родитель копируется, только если он еще не выполнен
+func (r *TaskRepository) CreateOccurrence(ctx context.Context, sourceID string, next *models.Task) error {
+	r.mu.Lock()
+	source, ok := r.tasks[sourceID]
+	if !ok || source.RecurrenceID == nil {
+		r.mu.Unlock()
+		return repository.ErrNotFound
+	}
+	recurrence, ok := r.recurrences[*source.RecurrenceID]
+	if !ok || recurrence.LastTaskID != sourceID || recurrence.Paused {
+		r.mu.Unlock()
+		return repository.ErrNotFound
+	}
+
+	now := r.now()
+	task := cloneTask(source)
+	task.ID, task.DueDate, task.Status = next.ID, next.DueDate, models.StatusPending
+	task.CreatedAt, task.UpdatedAt, task.CompletedAt = now, now, nil
+	if task.ParentID != nil {
+		if parent, ok := r.tasks[*task.ParentID]; !ok || parent.Status == models.StatusDone {
+			task.ParentID = nil
+		}
+	}
+	r.tasks[task.ID] = task
+
+	recurrence.LastTaskID, recurrence.UpdatedAt = task.ID, now
+	recurrence.Occurrences++
+	r.recurrences[recurrence.ID] = recurrence
+	r.mu.Unlock()
+
+	*next = cloneTask(task)
+	r.changed(ctx, task.UserID)
+	return nil
+}
+
+// завершаем серию, экземпляры остаются обычными задачами
+func (r *TaskRepository) EndRecurrence(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if recurrence, ok := r.recurrences[id]; ok {
+		recurrence.LastTaskID, recurrence.UpdatedAt = "", r.now()
+		r.recurrences[id] = recurrence
+	}
+
+	return nil
+}
@@ -0,0 +1,78 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/jmoloko/taskmange/internal/domain/repository"
+)
+
+// связываем задачи, повторное связывание ничего не меняет
+func (r *TaskRepository) AddRelation(ctx context.Context, taskID, relatedID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if taskID == relatedID {
+		return &repository.ConstraintError{Err: repository.ErrInvalidValue, Constraint: "task_relations_check", Field: "related_task_id"}
+	}
+	if _, ok := r.tasks[taskID]; !ok {
+		return invalidReference("task_relations_task_id_fkey", "task_id")
+	}
+	if _, ok := r.tasks[relatedID]; !ok {
+		return invalidReference("task_relations_related_task_id_fkey", "related_task_id")
+	}
+
+	r.relations[relationPair(taskID, relatedID)] = struct{}{}
+	return nil
+}
+
+// удаляем связь между задачами
+func (r *TaskRepository) RemoveRelation(ctx context.Context, taskID, relatedID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	pair := relationPair(taskID, relatedID)
+	if _, ok := r.relations[pair]; !ok {
+		return repository.ErrNotFound
+	}
+	delete(r.relations, pair)
+	return nil
+}
+
+// связанные задачи для нескольких задач, в порядке создания
+func (r *TaskRepository) GetRelated(ctx context.Context, taskIDs []string) (map[string][]models.Task, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	related := make(map[string][]models.Task, len(taskIDs))
+	for _, id := range taskIDs {
+		for pair := range r.relations {
+			other := ""
+			switch id {
+			case pair[0]:
+				other = pair[1]
+			case pair[1]:
+				other = pair[0]
+			default:
+				continue
+			}
+			if task, ok := r.tasks[other]; ok {
+				related[id] = append(related[id], cloneTask(task))
+			}
+		}
+		sort.SliceStable(related[id], func(i, j int) bool {
+			return related[id][i].CreatedAt.Before(related[id][j].CreatedAt)
+		})
+	}
+
+	return related, nil
+}
+
+// relationPair связь симметрична, поэтому хранится упорядоченной парой
+func relationPair(taskID, relatedID string) [2]string {
+	if relatedID < taskID {
+		return [2]string{relatedID, taskID}
+	}
+	return [2]string{taskID, relatedID}
+}
@@ -0,0 +1,95 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"sort"
+
+	"github.com/jmoloko/taskmange/internal/domain/models"
+)
+
+// maxHierarchyDepth ограничение глубины обхода иерархии, как в рекурсивных запросах Postgres
+const maxHierarchyDepth = 100
+
+// прямые подзадачи, открытые первыми
+func (r *TaskRepository) GetSubtasks(ctx context.Context, parentID string) ([]models.Task, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var tasks []models.Task
+	for _, task := range r.tasks {
+		if task.ParentID != nil && *task.ParentID == parentID {
+			tasks = append(tasks, cloneTask(task))
+		}
+	}
+	sort.Slice(tasks, func(i, j int) bool {
+		a, b := tasks[i], tasks[j]
+		if doneA, doneB := a.Status == models.StatusDone, b.Status == models.StatusDone; doneA != doneB {
+			return doneB
+		}
+		if !a.DueDate.Equal(b.DueDate) {
+			return a.DueDate.Before(b.DueDate)
+		}
+		if !a.CreatedAt.Equal(b.CreatedAt) {
+			return a.CreatedAt.Before(b.CreatedAt)
+		}
+		return a.ID < b.ID
+	})
+
+	return tasks, nil
+}
+
+// цепочка предков задачи от родителя к корню; повтор задачи в цепочке останавливает обход
+func (r *TaskRepository) GetAncestorIDs(ctx context.Context, taskID string) ([]string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var ids []string
+	task, ok := r.tasks[taskID]
+	for ok && task.ParentID != nil && len(ids) < maxHierarchyDepth {
+		parentID := *task.ParentID
+		if parentID == taskID || slices.Contains(ids, parentID) {
+			break
+		}
+		if task, ok = r.tasks[parentID]; ok {
+			ids = append(ids, parentID)
+		}
+	}
+
+	return ids, nil
+}
+
+// открытые подзадачи всех уровней для каждой из задач
+func (r *TaskRepository) GetOpenDescendantIDs(ctx context.Context, taskIDs []string) ([]string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	children := make(map[string][]models.Task)
+	for _, task := range r.tasks {
+		if task.ParentID != nil {
+			children[*task.ParentID] = append(children[*task.ParentID], task)
+		}
+	}
+
+	seen := make(map[string]bool)
+	var ids []string
+	level := taskIDs
+	for depth := 0; depth < maxHierarchyDepth && len(level) > 0; depth++ {
+		var next []string
+		for _, id := range level {
+			for _, child := range children[id] {
+				if seen[child.ID] {
+					continue
+				}
+				seen[child.ID] = true
+				next = append(next, child.ID)
+				if child.Status != models.StatusDone {
+					ids = append(ids, child.ID)
+				}
+			}
+		}
+		level = next
+	}
+
+	return ids, nil
+}
@@ -0,0 +1,692 @@
+// Package memory хранит задачи и пользователей в памяти процесса. Хранилище выбирается STORAGE=memory
+// для демонстраций и подходит для быстрых тестов сервисов: данные пропадают при остановке приложения.
+// Поведение повторяет репозитории Postgres, включая то, что там делают триггеры и ограничения базы:
+// временные метки, completed_at, проверку ссылок на родителя и проект
+package memory
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/jmoloko/taskmange/internal/domain/repository"
+)
+
+// ChangeFunc вызывается после изменения задач пользователя, как слушатель изменений tasks в Postgres
+type ChangeFunc func(ctx context.Context, userID string) error
+
+// TaskRepository задачи, связи и серии повторений в памяти. Архива нет: выполненные задачи остаются в списке
+type TaskRepository struct {
+	mu          sync.RWMutex
+	tasks       map[string]models.Task
+	relations   map[[2]string]struct{}
+	recurrences map[string]models.Recurrence
+	projects    repository.ProjectReader
+	listeners   []ChangeFunc
+	now         func() time.Time
+}
+
+// NewTaskRepository создает пустое хранилище задач. projects проверяет проект задачи;
+// nil — проектов нет, и задача с проектом отклоняется как ссылка на несуществующую запись
+func NewTaskRepository(projects repository.ProjectReader) *TaskRepository {
+	return &TaskRepository{
+		tasks:       make(map[string]models.Task),
+		relations:   make(map[[2]string]struct{}),
+		recurrences: make(map[string]models.Recurrence),
+		projects:    projects,
+		now:         time.Now,
+	}
+}
+
+// OnChange добавляет слушателя изменений, например сброс кэшей пользователя
+func (r *TaskRepository) OnChange(listener ChangeFunc) {
+	r.listeners = append(r.listeners, listener)
+}
+
+// Supports задачи в памяти процесса не видны таблицам Postgres и другим экземплярам
+func (r *TaskRepository) Supports(capability repository.Capability) bool {
+	return false
+}
+
+// changed оповещает слушателей; вызывается без блокировки, ошибки сброса кэшей не отменяют изменение
+func (r *TaskRepository) changed(ctx context.Context, userIDs ...string) {
+	for _, userID := range slices.Compact(slices.Sorted(slices.Values(userIDs))) {
+		for _, listener := range r.listeners {
+			_ = listener(ctx, userID)
+		}
+	}
+}
+
+// создаём задачу; задача с RecurrenceID создается вместе с серией, первым экземпляром которой она становится
+func (r *TaskRepository) Create(ctx context.Context, task *models.Task) error {
+	if err := r.checkProject(ctx, task.ProjectID); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	if _, ok := r.tasks[task.ID]; ok {
+		r.mu.Unlock()
+		return &repository.ConstraintError{Err: repository.ErrAlreadyExists, Constraint: "tasks_pkey", Field: "id"}
+	}
+	if err := r.checkTask(task); err != nil {
+		r.mu.Unlock()
+		return err
+	}
+
+	now := r.now()
+	task.CreatedAt, task.UpdatedAt, task.CompletedAt = now, now, nil
+	if task.Status == models.StatusDone {
+		task.CompletedAt = &now
+	}
+	if task.RecurrenceID != nil && task.Recurrence != "" {
+		r.recurrences[*task.RecurrenceID] = models.Recurrence{
+			ID:          *task.RecurrenceID,
+			UserID:      task.UserID,
+			Rule:        task.Recurrence,
+			Occurrences: 1,
+			LastTaskID:  task.ID,
+			CreatedAt:   now,
+			UpdatedAt:   now,
+		}
+	}
+	r.tasks[task.ID] = cloneTask(*task)
+	r.mu.Unlock()
+
+	r.changed(ctx, task.UserID)
+	return nil
+}
+
+// CreateBatch создает задачи все или ни одной
+func (r *TaskRepository) CreateBatch(ctx context.Context, tasks []models.Task) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+	for _, task := range tasks {
+		if err := r.checkProject(ctx, task.ProjectID); err != nil {
+			return err
+		}
+	}
+
+	r.mu.Lock()
+	ids := make(map[string]bool, len(tasks))
+	for i := range tasks {
+		if _, ok := r.tasks[tasks[i].ID]; ok || ids[tasks[i].ID] {
+			r.mu.Unlock()
+			return &repository.ConstraintError{Err: repository.ErrAlreadyExists, Constraint: "tasks_pkey", Field: "id"}
+		}
+		ids[tasks[i].ID] = true
+	}
+	// родителем может быть задача того же пакета
+	for i := range tasks {
+		if err := r.checkValues(&tasks[i]); err != nil {
+			r.mu.Unlock()
+			return err
+		}
+		if parentID := tasks[i].ParentID; parentID != nil && *parentID != "" && !ids[*parentID] {
+			if _, ok := r.tasks[*parentID]; !ok {
+				r.mu.Unlock()
+				return invalidReference("tasks_parent_id_fkey", "parent_id")
+			}
+		}
+	}
+
+	now := r.now()
+	users := make([]string, 0, len(tasks))
+	for _, task := range tasks {
+		task.CreatedAt, task.UpdatedAt, task.CompletedAt = now, now, nil
+		if task.Status == models.StatusDone {
+			task.CompletedAt = &now
+		}
+		task.Recurrence, task.RecurrenceID = "", nil
+		r.tasks[task.ID] = cloneTask(task)
+		users = append(users, task.UserID)
+	}
+	r.mu.Unlock()
+
+	r.changed(ctx, users...)
+	return nil
+}
+
+// обновляем задачу владельца: updated_at меняется всегда, completed_at проставляется при переходе в done
+func (r *TaskRepository) Update(ctx context.Context, task *models.Task) error {
+	if err := r.checkProject(ctx, task.ProjectID); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	existing, ok := r.tasks[task.ID]
+	if !ok || existing.UserID != task.UserID {
+		r.mu.Unlock()
+		return errors.New("task not found or not owned by user")
+	}
+	if err := r.checkTask(task); err != nil {
+		r.mu.Unlock()
+		return err
+	}
+
+	updated := existing
+	updated.Title, updated.Description, updated.Notes = task.Title, task.Description, task.Notes
+	updated.Links, updated.Tags = task.Links, task.Tags
+	updated.Status, updated.Priority, updated.DueDate = task.Status, task.Priority, task.DueDate
+	updated.Private, updated.ParentID, updated.ProjectID = task.Private, task.ParentID, task.ProjectID
+	updated.UpdatedAt = r.now()
+	if updated.Status == models.StatusDone && updated.CompletedAt == nil {
+		completedAt := updated.UpdatedAt
+		updated.CompletedAt = &completedAt
+	}
+	r.tasks[task.ID] = cloneTask(updated)
+	r.mu.Unlock()
+
+	task.UpdatedAt, task.CompletedAt = updated.UpdatedAt, updated.CompletedAt
+	r.changed(ctx, task.UserID)
+	return nil
+}
+
+// переводим задачи пользователя в done; ErrNotFound, если хотя бы одной задачи у пользователя нет
+func (r *TaskRepository) CompleteTasks(ctx context.Context, userID string, ids []string) ([]models.Task, error) {
+	r.mu.Lock()
+	for _, id := range ids {
+		if task, ok := r.tasks[id]; !ok || task.UserID != userID {
+			r.mu.Unlock()
+			return nil, repository.ErrNotFound
+		}
+	}
+
+	now := r.now()
+	var completed []models.Task
+	for _, id := range slices.Compact(slices.Sorted(slices.Values(ids))) {
+		task := r.tasks[id]
+		if task.Status == models.StatusDone {
+			continue
+		}
+		task.Status, task.UpdatedAt = models.StatusDone, now
+		if task.CompletedAt == nil {
+			completedAt := now
+			task.CompletedAt = &completedAt
+		}
+		r.tasks[id] = task
+		completed = append(completed, cloneTask(task))
+	}
+	r.mu.Unlock()
+
+	if len(completed) > 0 {
+		r.changed(ctx, userID)
+	}
+	return completed, nil
+}
+
+// назначаем задачу исполнителю, nil снимает назначение
+func (r *TaskRepository) SetAssignee(ctx context.Context, taskID string, assigneeID *string) error {
+	r.mu.Lock()
+	task, ok := r.tasks[taskID]
+	if !ok {
+		r.mu.Unlock()
+		return repository.ErrNotFound
+	}
+	task.AssigneeID = cloneString(assigneeID)
+	task.UpdatedAt = r.now()
+	r.tasks[taskID] = task
+	r.mu.Unlock()
+
+	r.changed(ctx, task.UserID)
+	return nil
+}
+
+// удаляем задачу: подзадачи становятся задачами верхнего уровня, связи удаляются, серия завершается
+func (r *TaskRepository) Delete(ctx context.Context, id string) error {
+	r.mu.Lock()
+	task, ok := r.tasks[id]
+	if !ok {
+		r.mu.Unlock()
+		return errors.New("task not found")
+	}
+	r.remove(id)
+	r.mu.Unlock()
+
+	r.changed(ctx, task.UserID)
+	return nil
+}
+
+// удаляем все задачи пользователя и возвращаем их ID
+func (r *TaskRepository) DeleteUserTasks(ctx context.Context, userID string) ([]string, error) {
+	r.mu.Lock()
+	var ids []string
+	for id, task := range r.tasks {
+		if task.UserID == userID {
+			ids = append(ids, id)
+		}
+	}
+	for _, id := range ids {
+		r.remove(id)
+	}
+	r.mu.Unlock()
+
+	if len(ids) > 0 {
+		r.changed(ctx, userID)
+	}
+	return ids, nil
+}
+
+// удаляем до limit задач пользователя по фильтру, самые старые первыми
+func (r *TaskRepository) DeleteTasks(ctx context.Context, filter models.TaskDeleteFilter, limit int) ([]models.Task, error) {
+	r.mu.Lock()
+	var tasks []models.Task
+	for _, task := range r.tasks {
+		if matchesDelete(task, filter) {
+			tasks = append(tasks, cloneTask(task))
+		}
+	}
+	sort.Slice(tasks, func(i, j int) bool {
+		if !tasks[i].CreatedAt.Equal(tasks[j].CreatedAt) {
+			return tasks[i].CreatedAt.Before(tasks[j].CreatedAt)
+		}
+		return tasks[i].ID < tasks[j].ID
+	})
+	if len(tasks) > limit {
+		tasks = tasks[:limit]
+	}
+	for _, task := range tasks {
+		r.remove(task.ID)
+	}
+	r.mu.Unlock()
+
+	if len(tasks) > 0 {
+		r.changed(ctx, filter.UserID)
+	}
+	return tasks, nil
+}
+
+// matchesDelete проверяет задачу на условия массового удаления
+func matchesDelete(task models.Task, filter models.TaskDeleteFilter) bool {
+	switch {
+	case task.UserID != filter.UserID:
+		return false
+	case filter.Status != "" && task.Status != filter.Status:
+		return false
+	case filter.Priority != "" && task.Priority != filter.Priority:
+		return false
+	case filter.ProjectID != "" && (task.ProjectID == nil || *task.ProjectID != filter.ProjectID):
+		return false
+	case filter.CompletedBefore != nil && (task.CompletedAt == nil || !task.CompletedAt.Before(*filter.CompletedBefore)):
+		return false
+	case filter.CreatedBefore != nil && !task.CreatedAt.Before(*filter.CreatedBefore):
+		return false
+	}
+	return true
+}
+
+// remove удаляет задачу и ссылки на нее так же, как внешние ключи tasks; вызывается под блокировкой
+func (r *TaskRepository) remove(id string) {
+	delete(r.tasks, id)
+	for key, child := range r.tasks {
+		if child.ParentID != nil && *child.ParentID == id {
+			child.ParentID = nil
+			r.tasks[key] = child
+		}
+	}
+	for pair := range r.relations {
+		if pair[0] == id || pair[1] == id {
+			delete(r.relations, pair)
+		}
+	}
+	for key, recurrence := range r.recurrences {
+		if recurrence.LastTaskID == id {
+			recurrence.LastTaskID = ""
+			r.recurrences[key] = recurrence
+		}
+	}
+}
+
+// получаем задачу по ID
+func (r *TaskRepository) GetByID(ctx context.Context, id string) (*models.Task, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	task, ok := r.tasks[id]
+	if !ok {
+		return nil, errors.New("task not found")
+	}
+
+	task = cloneTask(task)
+	return &task, nil
+}
+
+// список задач с применением фильтров, порядок и страницы как в Postgres
+func (r *TaskRepository) GetAll(ctx context.Context, filters models.TaskFilters) ([]models.Task, error) {
+	r.mu.RLock()
+	tasks := r.filter(filters)
+	r.mu.RUnlock()
+
+	if filters.After != nil {
+		after := *filters.After
+		tasks = slices.DeleteFunc(tasks, func(task models.Task) bool {
+			return !afterCursor(task, after)
+		})
+	}
+
+	sortTasks(tasks, filters.Sort, r.now())
+
+	if filters.Offset > 0 {
+		tasks = tasks[min(filters.Offset, len(tasks)):]
+	}
+	if filters.Limit > 0 && len(tasks) > filters.Limit {
+		tasks = tasks[:filters.Limit]
+	}
+	if len(tasks) == 0 {
+		return nil, nil
+	}
+
+	return tasks, nil
+}
+
+// количество задач, подходящих под фильтры
+func (r *TaskRepository) Count(ctx context.Context, filters models.TaskFilters) (int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return len(r.filter(filters)), nil
+}
+
+// GetTaskStats агрегаты задач пользователя (или его проекта) за окно [from, to)
+func (r *TaskRepository) GetTaskStats(ctx context.Context, userID, projectID string, from, to time.Time) (models.TaskStats, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	stats := models.TaskStats{
+		StatusCount:   make(map[models.Status]int),
+		PriorityCount: make(map[models.Priority]int),
+	}
+	within := func(t time.Time) bool { return !t.Before(from) && t.Before(to) }
+	now := r.now()
+
+	var completionHours float64
+	for _, task := range r.tasks {
+		if task.UserID != userID || (projectID != "" && (task.ProjectID == nil || *task.ProjectID != projectID)) {
+			continue
+		}
+		if within(task.CreatedAt) {
+			stats.StatusCount[task.Status]++
+			stats.PriorityCount[task.Priority]++
+		}
+		if task.Status == models.StatusDone && task.CompletedAt != nil && within(*task.CompletedAt) {
+			stats.Completed++
+			completionHours += task.CompletedAt.Sub(task.CreatedAt).Hours()
+			if task.CompletedAt.Before(task.DueDate) {
+				stats.CompletedOnTime++
+			}
+		}
+		if task.Overdue(now) {
+			stats.Overdue++
+		}
+	}
+	if stats.Completed > 0 {
+		stats.AvgCompletionHours = completionHours / float64(stats.Completed)
+	}
+
+	return stats, nil
+}
+
+// пользователи, у которых задачи создавались или менялись начиная с t
+func (r *TaskRepository) GetUsersWithTasksUpdatedSince(ctx context.Context, t time.Time) ([]string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	seen := make(map[string]bool)
+	var users []string
+	for _, task := range r.tasks {
+		if !task.UpdatedAt.Before(t) && !seen[task.UserID] {
+			seen[task.UserID] = true
+			users = append(users, task.UserID)
+		}
+	}
+	sort.Strings(users)
+
+	return users, nil
+}
+
+// проект задачи
+func (r *TaskRepository) GetProject(ctx context.Context, id string) (*models.Project, error) {
+	if r.projects == nil {
+		return nil, repository.ErrNotFound
+	}
+	return r.projects.GetProject(ctx, id)
+}
+
+// filter копии задач, подходящих под фильтры; вызывается под блокировкой
+func (r *TaskRepository) filter(filters models.TaskFilters) []models.Task {
+	terms := filters.Terms
+	if filters.Search != "" {
+		terms = append([]string{filters.Search}, terms...)
+	}
+
+	now := r.now()
+	var tasks []models.Task
+	for _, task := range r.tasks {
+		if matches(task, filters, terms, now) {
+			tasks = append(tasks, cloneTask(task))
+		}
+	}
+	return tasks
+}
+
+// matches условия buildTaskFilters репозитория Postgres, now — время для условия просрочки
+func matches(task models.Task, filters models.TaskFilters, terms []string, now time.Time) bool {
+	if filters.Assigned {
+		if task.AssigneeID == nil || *task.AssigneeID != filters.UserID {
+			return false
+		}
+	} else if task.UserID != filters.UserID {
+		return false
+	}
+
+	if filters.Status != "" && task.Status != filters.Status {
+		return false
+	}
+	if filters.Priority != "" && task.Priority != filters.Priority {
+		return false
+	}
+	if filters.DueDate != nil {
+		y1, m1, d1 := task.DueDate.UTC().Date()
+		y2, m2, d2 := filters.DueDate.UTC().Date()
+		if y1 != y2 || m1 != m2 || d1 != d2 {
+			return false
+		}
+	}
+	if filters.DueFrom != nil && task.DueDate.Before(*filters.DueFrom) {
+		return false
+	}
+	if filters.DueBefore != nil && !task.DueDate.Before(*filters.DueBefore) {
+		return false
+	}
+	if filters.Open && task.Status == models.StatusDone {
+		return false
+	}
+	if filters.Overdue && !task.Overdue(now) {
+		return false
+	}
+	if filters.Tag != "" && !slices.Contains(task.Tags, filters.Tag) {
+		return false
+	}
+	if filters.ProjectID != "" && (task.ProjectID == nil || *task.ProjectID != filters.ProjectID) {
+		return false
+	}
+
+	// приватные задачи зашифрованы и не участвуют в поиске
+	for _, term := range terms {
+		if task.Private {
+			return false
+		}
+		term = strings.ToLower(term)
+		if !strings.Contains(strings.ToLower(task.Title), term) && !strings.Contains(strings.ToLower(task.Description), term) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// afterCursor задача идет после курсора в порядке SortDue
+func afterCursor(task models.Task, cursor models.TaskCursor) bool {
+	if !task.DueDate.Equal(cursor.DueDate) {
+		return task.DueDate.After(cursor.DueDate)
+	}
+	return task.ID > cursor.ID
+}
+
+// priorityRank порядок перечисления task_priority
+var priorityRank = map[models.Priority]int{
+	models.PriorityLow:    1,
+	models.PriorityMedium: 2,
+	models.PriorityHigh:   3,
+}
+
+// sortTasks порядок выдачи GetAll репозитория Postgres, id в конце делает его однозначным
+func sortTasks(tasks []models.Task, order models.TaskSort, now time.Time) {
+	switch order {
+	case models.SortSmart:
+		sort.SliceStable(tasks, func(i, j int) bool {
+			a, b := tasks[i], tasks[j]
+			if doneA, doneB := a.Status == models.StatusDone, b.Status == models.StatusDone; doneA != doneB {
+				return doneB
+			}
+			if scoreA, scoreB := smartScore(a, now), smartScore(b, now); scoreA != scoreB {
+				return scoreA > scoreB
+			}
+			if !a.DueDate.Equal(b.DueDate) {
+				return a.DueDate.Before(b.DueDate)
+			}
+			if !a.CreatedAt.Equal(b.CreatedAt) {
+				return a.CreatedAt.Before(b.CreatedAt)
+			}
+			return a.ID < b.ID
+		})
+	case models.SortDue:
+		sort.SliceStable(tasks, func(i, j int) bool {
+			return afterCursor(tasks[j], models.TaskCursor{DueDate: tasks[i].DueDate, ID: tasks[i].ID})
+		})
+	default:
+		sort.SliceStable(tasks, func(i, j int) bool {
+			a, b := tasks[i], tasks[j]
+			if !a.DueDate.Equal(b.DueDate) {
+				return a.DueDate.Before(b.DueDate)
+			}
+			if priorityRank[a.Priority] != priorityRank[b.Priority] {
+				return priorityRank[a.Priority] > priorityRank[b.Priority]
+			}
+			if !a.CreatedAt.Equal(b.CreatedAt) {
+				return a.CreatedAt.After(b.CreatedAt)
+			}
+			return a.ID < b.ID
+		})
+	}
+}
+
+// smartScore оценка срочности для sort=smart, та же формула, что в запросе Postgres
+func smartScore(task models.Task, now time.Time) float64 {
+	score := float64(max(priorityRank[task.Priority], 1))
+	if !task.DueDate.After(now) {
+		score += 4
+	} else {
+		score += 4 / (1 + task.DueDate.Sub(now).Hours()/24)
+	}
+	score += min(now.Sub(task.CreatedAt).Hours()/(30*24), 1)
+	return score
+}
+
+// checkTask проверяет значения и ссылку на родителя; вызывается под блокировкой
+func (r *TaskRepository) checkTask(task *models.Task) error {
+	if err := r.checkValues(task); err != nil {
+		return err
+	}
+	if task.ParentID != nil && *task.ParentID != "" {
+		if _, ok := r.tasks[*task.ParentID]; !ok {
+			return invalidReference("tasks_parent_id_fkey", "parent_id")
+		}
+	}
+	return nil
+}
+
+// checkValues ограничения перечислений task_status и task_priority
+func (r *TaskRepository) checkValues(task *models.Task) error {
+	switch task.Status {
+	case models.StatusPending, models.StatusInProgress, models.StatusDone:
+	default:
+		return &repository.ConstraintError{Err: repository.ErrInvalidValue, Constraint: "task_status", Field: "status"}
+	}
+	if _, ok := priorityRank[task.Priority]; !ok {
+		return &repository.ConstraintError{Err: repository.ErrInvalidValue, Constraint: "task_priority", Field: "priority"}
+	}
+	return nil
+}
+
+// checkProject проверяет, что проект задачи существует
+func (r *TaskRepository) checkProject(ctx context.Context, projectID *string) error {
+	if projectID == nil || *projectID == "" {
+		return nil
+	}
+	if _, err := r.GetProject(ctx, *projectID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return invalidReference("tasks_project_id_fkey", "project_id")
+		}
+		return err
+	}
+	return nil
+}
+
+func invalidReference(constraint, field string) error {
+	return &repository.ConstraintError{Err: repository.ErrInvalidReference, Constraint: constraint, Field: field}
+}
+
+// cloneTask копия задачи без общих срезов и указателей с хранилищем
+func cloneTask(task models.Task) models.Task {
+	task.Notes = cloneString(task.Notes)
+	task.ParentID = cloneString(task.ParentID)
+	task.ProjectID = cloneString(task.ProjectID)
+	task.AssigneeID = cloneString(task.AssigneeID)
+	task.RecurrenceID = cloneString(task.RecurrenceID)
+	if task.CompletedAt != nil {
+		completedAt := *task.CompletedAt
+		task.CompletedAt = &completedAt
+	}
+	task.Links = slices.Clone(task.Links)
+	task.Tags = slices.Clone(task.Tags)
+	task.Related = nil
+	task.Locked = false
+	// пустая строка в ссылках хранится как NULL
+	if task.ParentID != nil && *task.ParentID == "" {
+		task.ParentID = nil
+	}
+	if task.ProjectID != nil && *task.ProjectID == "" {
+		task.ProjectID = nil
+	}
+	return task
+}
+
+func cloneString(s *string) *string {
+	if s == nil {
+		return nil
+	}
+	value := *s
+	return &value
+}
+
+// ID задач пользователя, начинающиеся с prefix; двух ID достаточно, чтобы отличить однозначную ссылку
+func (r *TaskRepository) FindTaskIDsByPrefix(ctx context.Context, userID, prefix string) ([]string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var ids []string
+	for id, task := range r.tasks {
+		if task.UserID == userID && strings.HasPrefix(id, prefix) {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+
+	return ids[:min(len(ids), 2)], nil
+}
@@ -0,0 +1,324 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/jmoloko/taskmange/internal/domain/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var day = time.Date(2024, 6, 10, 12, 0, 0, 0, time.UTC)
+
+func newTask(id, userID string, due time.Time) models.Task {
+	return models.Task{ID: id, UserID: userID, Title: "Task " + id, Status: models.StatusPending, Priority: models.PriorityMedium, DueDate: due}
+}
+
+func createTasks(t *testing.T, repo repository.TaskRepository, tasks ...models.Task) {
+	t.Helper()
+	for _, task := range tasks {
+		require.NoError(t, repo.Create(context.Background(), &task))
+	}
+}
+
+func ids(tasks []models.Task) []string {
+	result := make([]string, 0, len(tasks))
+	for _, task := range tasks {
+		result = append(result, task.ID)
+	}
+	return result
+}
+
+func TestTaskRepository_CreateAndUpdate(t *testing.T) {
+	repo := NewTaskRepository(nil)
+	repo.now = func() time.Time { return day }
+	ctx := context.Background()
+
+	task := newTask("a", "user1", day)
+	task.Tags = []string{"work"}
+	require.NoError(t, repo.Create(ctx, &task))
+	assert.Equal(t, day, task.CreatedAt)
+	assert.Nil(t, task.CompletedAt)
+
+	// задача в хранилище не меняется вместе с переданной
+	task.Tags[0] = "home"
+	stored, err := repo.GetByID(ctx, "a")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"work"}, stored.Tags)
+
+	err = repo.Create(ctx, &task)
+	assert.ErrorIs(t, err, repository.ErrAlreadyExists)
+
+	task.Status = models.StatusDone
+	repo.now = func() time.Time { return day.Add(time.Hour) }
+	require.NoError(t, repo.Update(ctx, &task))
+	require.NotNil(t, task.CompletedAt)
+	assert.Equal(t, day.Add(time.Hour), *task.CompletedAt)
+
+	other := task
+	other.UserID = "user2"
+	assert.Error(t, repo.Update(ctx, &other))
+
+	task.Priority = "urgent"
+	var constraint *repository.ConstraintError
+	require.ErrorAs(t, repo.Update(ctx, &task), &constraint)
+	assert.Equal(t, "priority", constraint.Field)
+
+	parent := "missing"
+	orphan := newTask("b", "user1", day)
+	orphan.ParentID = &parent
+	assert.ErrorIs(t, repo.Create(ctx, &orphan), repository.ErrInvalidReference)
+
+	project := "p1"
+	orphan.ParentID, orphan.ProjectID = nil, &project
+	assert.ErrorIs(t, repo.Create(ctx, &orphan), repository.ErrInvalidReference)
+}
+
+func TestTaskRepository_GetAllFilters(t *testing.T) {
+	repo := NewTaskRepository(nil)
+	ctx := context.Background()
+
+	done := newTask("done", "user1", day.Add(-24*time.Hour))
+	done.Status = models.StatusDone
+	tagged := newTask("tagged", "user1", day)
+	tagged.Tags = []string{"home"}
+	tagged.Description = "Buy MILK"
+	private := newTask("private", "user1", day.Add(2*time.Hour))
+	private.Title = "milk"
+	private.Private = true
+	high := newTask("high", "user1", day.Add(48*time.Hour))
+	high.Priority = models.PriorityHigh
+	assignee := "user1"
+	assigned := newTask("assigned", "user2", day)
+	assigned.AssigneeID = &assignee
+	createTasks(t, repo, done, tagged, private, high, assigned)
+
+	query := func(filters models.TaskFilters) []string {
+		filters.UserID = "user1"
+		tasks, err := repo.GetAll(ctx, filters)
+		require.NoError(t, err)
+		count, err := repo.Count(ctx, filters)
+		require.NoError(t, err)
+		if filters.Limit == 0 && filters.After == nil {
+			assert.Equal(t, len(tasks), count)
+		}
+		return ids(tasks)
+	}
+
+	assert.Equal(t, []string{"done", "tagged", "private", "high"}, query(models.TaskFilters{}))
+	assert.Equal(t, []string{"tagged", "private", "high"}, query(models.TaskFilters{Open: true}))
+	assert.Equal(t, []string{"done"}, query(models.TaskFilters{Status: models.StatusDone}))
+	// сроки всех задач в прошлом, выполненная задача не просрочена
+	assert.Equal(t, []string{"tagged", "private", "high"}, query(models.TaskFilters{Overdue: true}))
+	assert.Equal(t, []string{"high"}, query(models.TaskFilters{Priority: models.PriorityHigh}))
+	assert.Equal(t, []string{"tagged"}, query(models.TaskFilters{Tag: "home"}))
+	assert.Equal(t, []string{"tagged"}, query(models.TaskFilters{Search: "milk"}))
+	assert.Equal(t, []string{"tagged", "private"}, query(models.TaskFilters{DueDate: &day}))
+
+	from, before := day, day.Add(24*time.Hour)
+	assert.Equal(t, []string{"tagged", "private"}, query(models.TaskFilters{DueFrom: &from, DueBefore: &before}))
+	assert.Equal(t, []string{"assigned"}, query(models.TaskFilters{Assigned: true}))
+
+	assert.Equal(t, []string{"tagged", "private"}, query(models.TaskFilters{Limit: 2, Offset: 1}))
+	cursor := models.TaskCursor{DueDate: day, ID: "tagged"}
+	assert.Equal(t, []string{"private", "high"}, query(models.TaskFilters{Sort: models.SortDue, After: &cursor}))
+
+	none, err := repo.GetAll(ctx, models.TaskFilters{UserID: "nobody"})
+	require.NoError(t, err)
+	assert.Nil(t, none)
+}
+
+func TestTaskRepository_SortOrders(t *testing.T) {
+	repo := NewTaskRepository(nil)
+	repo.now = func() time.Time { return day }
+	ctx := context.Background()
+
+	low := newTask("low", "user1", day)
+	low.Priority = models.PriorityLow
+	high := newTask("high", "user1", day)
+	high.Priority = models.PriorityHigh
+	later := newTask("later", "user1", day.Add(7*24*time.Hour))
+	later.Priority = models.PriorityHigh
+	done := newTask("done", "user1", day.Add(-time.Hour))
+	done.Status = models.StatusDone
+	createTasks(t, repo, low, high, later, done)
+
+	get := func(order models.TaskSort) []string {
+		tasks, err := repo.GetAll(ctx, models.TaskFilters{UserID: "user1", Sort: order})
+		require.NoError(t, err)
+		return ids(tasks)
+	}
+
+	assert.Equal(t, []string{"done", "high", "low", "later"}, get(models.SortDefault))
+	assert.Equal(t, []string{"done", "high", "low", "later"}, get(models.SortDue))
+	assert.Equal(t, []string{"high", "low", "later", "done"}, get(models.SortSmart))
+}
+
+func TestTaskRepository_CompleteAndDelete(t *testing.T) {
+	repo := NewTaskRepository(nil)
+	ctx := context.Background()
+	var changed []string
+	repo.OnChange(func(ctx context.Context, userID string) error {
+		changed = append(changed, userID)
+		return errors.New("cache is unavailable")
+	})
+
+	parentID := "parent"
+	child := newTask("child", "user1", day)
+	child.ParentID = &parentID
+	createTasks(t, repo, newTask("parent", "user1", day), child, newTask("other", "user2", day))
+	require.NoError(t, repo.AddRelation(ctx, "child", "parent"))
+
+	_, err := repo.CompleteTasks(ctx, "user1", []string{"parent", "other"})
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+
+	completed, err := repo.CompleteTasks(ctx, "user1", []string{"parent"})
+	require.NoError(t, err)
+	require.Len(t, completed, 1)
+	assert.NotNil(t, completed[0].CompletedAt)
+	completed, err = repo.CompleteTasks(ctx, "user1", []string{"parent"})
+	require.NoError(t, err)
+	assert.Empty(t, completed)
+
+	require.NoError(t, repo.Delete(ctx, "parent"))
+	stored, err := repo.GetByID(ctx, "child")
+	require.NoError(t, err)
+	assert.Nil(t, stored.ParentID)
+	related, err := repo.GetRelated(ctx, []string{"child"})
+	require.NoError(t, err)
+	assert.Empty(t, related["child"])
+
+	deleted, err := repo.DeleteUserTasks(ctx, "user1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"child"}, deleted)
+	assert.Equal(t, []string{"user1", "user1", "user2", "user1", "user1", "user1"}, changed)
+}
+
+func TestTaskRepository_Hierarchy(t *testing.T) {
+	repo := NewTaskRepository(nil)
+	ctx := context.Background()
+
+	root, middle := "root", "middle"
+	mid := newTask("middle", "user1", day)
+	mid.ParentID = &root
+	leaf := newTask("leaf", "user1", day)
+	leaf.ParentID = &middle
+	closed := newTask("closed", "user1", day)
+	closed.ParentID = &middle
+	closed.Status = models.StatusDone
+	createTasks(t, repo, newTask("root", "user1", day), mid, leaf, closed)
+
+	ancestors, err := repo.GetAncestorIDs(ctx, "leaf")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"middle", "root"}, ancestors)
+
+	subtasks, err := repo.GetSubtasks(ctx, "middle")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"leaf", "closed"}, ids(subtasks))
+
+	open, err := repo.GetOpenDescendantIDs(ctx, []string{"root"})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"middle", "leaf"}, open)
+}
+
+func TestTaskRepository_Recurrence(t *testing.T) {
+	repo := NewTaskRepository(nil)
+	ctx := context.Background()
+
+	seriesID := "series"
+	first := newTask("first", "user1", day)
+	first.Recurrence, first.RecurrenceID = "FREQ=DAILY", &seriesID
+	createTasks(t, repo, first)
+
+	due, err := repo.GetDueOccurrences(ctx, day, 10)
+	require.NoError(t, err)
+	assert.Empty(t, due)
+
+	due, err = repo.GetDueOccurrences(ctx, day.Add(time.Minute), 10)
+	require.NoError(t, err)
+	require.Len(t, due, 1)
+	assert.Equal(t, "FREQ=DAILY", due[0].Rule)
+
+	next := models.Task{ID: "second", DueDate: day.Add(24 * time.Hour)}
+	require.NoError(t, repo.CreateOccurrence(ctx, "first", &next))
+	assert.Equal(t, "Task first", next.Title)
+	assert.Equal(t, models.StatusPending, next.Status)
+
+	// из прежнего экземпляра следующий больше не создается
+	assert.ErrorIs(t, repo.CreateOccurrence(ctx, "first", &models.Task{ID: "third"}), repository.ErrNotFound)
+
+	recurrence, err := repo.GetRecurrence(ctx, seriesID)
+	require.NoError(t, err)
+	assert.Equal(t, 2, recurrence.Occurrences)
+	assert.Equal(t, "second", recurrence.LastTaskID)
+
+	require.NoError(t, repo.SetRecurrencePaused(ctx, seriesID, true))
+	assert.ErrorIs(t, repo.CreateOccurrence(ctx, "second", &models.Task{ID: "third"}), repository.ErrNotFound)
+	assert.ErrorIs(t, repo.SetRecurrencePaused(ctx, "missing", true), repository.ErrNotFound)
+}
+
+func TestTaskRepository_GetTaskStats(t *testing.T) {
+	repo := NewTaskRepository(nil)
+	ctx := context.Background()
+
+	// выполнена в срок через 2 часа после создания
+	repo.now = func() time.Time { return day.Add(-4 * time.Hour) }
+	done := newTask("done", "user1", day.Add(24*time.Hour))
+	done.Priority = models.PriorityHigh
+	createTasks(t, repo, done, newTask("before", "user1", day.Add(-72*time.Hour)))
+	repo.now = func() time.Time { return day.Add(-2 * time.Hour) }
+	_, err := repo.CompleteTasks(ctx, "user1", []string{"done"})
+	require.NoError(t, err)
+
+	repo.now = func() time.Time { return day }
+	createTasks(t, repo, newTask("overdue", "user1", day.Add(-time.Hour)), newTask("other", "user2", day.Add(-time.Hour)))
+
+	stats, err := repo.GetTaskStats(ctx, "user1", "", day.Add(-24*time.Hour), day.Add(time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, map[models.Status]int{models.StatusDone: 1, models.StatusPending: 2}, stats.StatusCount)
+	assert.Equal(t, map[models.Priority]int{models.PriorityHigh: 1, models.PriorityMedium: 2}, stats.PriorityCount)
+	assert.Equal(t, 1, stats.Completed)
+	assert.Equal(t, 1, stats.CompletedOnTime)
+	assert.Equal(t, 2.0, stats.AvgCompletionHours)
+	// просроченные считаются на текущий момент, срок before раньше окна
+	assert.Equal(t, 2, stats.Overdue)
+}
+
+func TestTaskRepository_DeleteTasks(t *testing.T) {
+	repo := NewTaskRepository(nil)
+	ctx := context.Background()
+
+	repo.now = func() time.Time { return day.AddDate(-1, 0, 0) }
+	createTasks(t, repo, newTask("old-done", "user1", day), newTask("old-done-2", "user1", day),
+		newTask("old-open", "user1", day), newTask("foreign", "user2", day))
+	_, err := repo.CompleteTasks(ctx, "user1", []string{"old-done", "old-done-2"})
+	require.NoError(t, err)
+	_, err = repo.CompleteTasks(ctx, "user2", []string{"foreign"})
+	require.NoError(t, err)
+
+	repo.now = func() time.Time { return day }
+	createTasks(t, repo, newTask("recent", "user1", day))
+	_, err = repo.CompleteTasks(ctx, "user1", []string{"recent"})
+	require.NoError(t, err)
+
+	before := day.AddDate(0, -1, 0)
+	filter := models.TaskDeleteFilter{UserID: "user1", Status: models.StatusDone, CompletedBefore: &before}
+
+	// самые старые первыми, пачками по limit
+	deleted, err := repo.DeleteTasks(ctx, filter, 1)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"old-done"}, ids(deleted))
+	deleted, err = repo.DeleteTasks(ctx, filter, 5)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"old-done-2"}, ids(deleted))
+
+	remaining, err := repo.GetAll(ctx, models.TaskFilters{UserID: "user1"})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"old-open", "recent"}, ids(remaining))
+	_, err = repo.GetByID(ctx, "foreign")
+	assert.NoError(t, err)
+}
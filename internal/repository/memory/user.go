@@ -0,0 +1,204 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/jmoloko/taskmange/internal/domain/repository"
+)
+
+// UserRepository пользователи и история паролей в памяти
+type UserRepository struct {
+	mu        sync.RWMutex
+	users     map[string]models.User
+	passwords map[string][]string
+	now       func() time.Time
+}
+
+// NewUserRepository создает пустое хранилище пользователей
+func NewUserRepository() *UserRepository {
+	return &UserRepository{
+		users:     make(map[string]models.User),
+		passwords: make(map[string][]string),
+		now:       time.Now,
+	}
+}
+
+// создаём пользователя; без роли — роль по умолчанию, как в схеме
+func (r *UserRepository) Create(ctx context.Context, user *models.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.users[user.ID]; ok {
+		return &repository.ConstraintError{Err: repository.ErrAlreadyExists, Constraint: "users_pkey", Field: "id"}
+	}
+	for _, existing := range r.users {
+		if existing.Email == user.Email {
+			return &repository.ConstraintError{Err: repository.ErrAlreadyExists, Constraint: "users_email_key", Field: "email"}
+		}
+	}
+
+	if user.Role == "" {
+		user.Role = models.RoleUser
+	}
+	now := r.now()
+	user.Active, user.DeactivatedAt = true, nil
+	user.CreatedAt, user.UpdatedAt = now, now
+	r.users[user.ID] = *user
+
+	return nil
+}
+
+func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*models.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, user := range r.users {
+		if user.Email == email {
+			return cloneUser(user), nil
+		}
+	}
+	return nil, repository.ErrNotFound
+}
+
+func (r *UserRepository) GetByID(ctx context.Context, id string) (*models.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	user, ok := r.users[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	return cloneUser(user), nil
+}
+
+// UpdatePassword меняет хэш пароля пользователя
+func (r *UserRepository) UpdatePassword(ctx context.Context, id, passwordHash string) error {
+	return r.update(id, func(user *models.User) {
+		user.PasswordHash = passwordHash
+	})
+}
+
+// SetActive деактивирует или снова активирует пользователя
+func (r *UserRepository) SetActive(ctx context.Context, id string, active bool) error {
+	now := r.now()
+	return r.update(id, func(user *models.User) {
+		user.Active = active
+		if active {
+			user.DeactivatedAt = nil
+		} else if user.DeactivatedAt == nil {
+			user.DeactivatedAt = &now
+		}
+	})
+}
+
+// SetRole меняет роль пользователя
+func (r *UserRepository) SetRole(ctx context.Context, id, role string) error {
+	if role != models.RoleUser && role != models.RoleAdmin {
+		return &repository.ConstraintError{Err: repository.ErrInvalidValue, Constraint: "users_role_check", Field: "role"}
+	}
+	return r.update(id, func(user *models.User) {
+		user.Role = role
+	})
+}
+
+// ListUsers возвращает пользователей по дате регистрации; limit 0 — всех
+func (r *UserRepository) ListUsers(ctx context.Context, limit, offset int) ([]models.User, error) {
+	r.mu.RLock()
+	users := make([]models.User, 0, len(r.users))
+	for _, user := range r.users {
+		users = append(users, *cloneUser(user))
+	}
+	r.mu.RUnlock()
+
+	sort.Slice(users, func(i, j int) bool {
+		if !users[i].CreatedAt.Equal(users[j].CreatedAt) {
+			return users[i].CreatedAt.Before(users[j].CreatedAt)
+		}
+		return users[i].ID < users[j].ID
+	})
+
+	users = users[min(offset, len(users)):]
+	if limit > 0 && len(users) > limit {
+		users = users[:limit]
+	}
+	return users, nil
+}
+
+// CountUsers возвращает число пользователей
+func (r *UserRepository) CountUsers(ctx context.Context) (int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return len(r.users), nil
+}
+
+// PasswordHistory возвращает последние limit прежних хэшей пароля, от новых к старым
+func (r *UserRepository) PasswordHistory(ctx context.Context, userID string, limit int) ([]string, error) {
+	if limit <= 0 {
+		return nil, nil
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	history := r.passwords[userID]
+	hashes := make([]string, 0, min(limit, len(history)))
+	for i := len(history) - 1; i >= 0 && len(hashes) < limit; i-- {
+		hashes = append(hashes, history[i])
+	}
+	return hashes, nil
+}
+
+// ChangePassword меняет хэш пароля и переносит прежний в историю, в истории остаются keep последних записей
+func (r *UserRepository) ChangePassword(ctx context.Context, userID, passwordHash string, keep int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user, ok := r.users[userID]
+	if !ok {
+		return repository.ErrNotFound
+	}
+
+	history := r.passwords[userID]
+	if keep > 0 {
+		history = append(history, user.PasswordHash)
+	}
+	history = history[max(len(history)-keep, 0):]
+	if len(history) == 0 {
+		delete(r.passwords, userID)
+	} else {
+		r.passwords[userID] = history
+	}
+
+	user.PasswordHash, user.UpdatedAt = passwordHash, r.now()
+	r.users[userID] = user
+	return nil
+}
+
+// update меняет пользователя под блокировкой и обновляет updated_at
+func (r *UserRepository) update(id string, change func(user *models.User)) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user, ok := r.users[id]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	change(&user)
+	user.UpdatedAt = r.now()
+	r.users[id] = user
+	return nil
+}
+
+// cloneUser копия пользователя без общих указателей с хранилищем
+func cloneUser(user models.User) *models.User {
+	if user.DeactivatedAt != nil {
+		deactivatedAt := *user.DeactivatedAt
+		user.DeactivatedAt = &deactivatedAt
+	}
+	return &user
+}
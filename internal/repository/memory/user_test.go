@@ -0,0 +1,71 @@
+package memory
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/jmoloko/taskmange/internal/domain/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserRepository(t *testing.T) {
+	var repo repository.UserRepository = NewUserRepository()
+	ctx := context.Background()
+
+	user := &models.User{ID: "user1", Email: "user@example.com", PasswordHash: "hash1"}
+	require.NoError(t, repo.Create(ctx, user))
+	assert.Equal(t, models.RoleUser, user.Role)
+	assert.True(t, user.Active)
+
+	err := repo.Create(ctx, &models.User{ID: "user2", Email: "user@example.com"})
+	var constraint *repository.ConstraintError
+	require.ErrorAs(t, err, &constraint)
+	assert.Equal(t, "email", constraint.Field)
+
+	found, err := repo.GetByEmail(ctx, "user@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, "user1", found.ID)
+	_, err = repo.GetByEmail(ctx, "missing@example.com")
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+
+	require.NoError(t, repo.SetActive(ctx, "user1", false))
+	found, err = repo.GetByID(ctx, "user1")
+	require.NoError(t, err)
+	assert.False(t, found.Active)
+	assert.NotNil(t, found.DeactivatedAt)
+	require.NoError(t, repo.SetActive(ctx, "user1", true))
+
+	require.NoError(t, repo.SetRole(ctx, "user1", models.RoleAdmin))
+	assert.ErrorIs(t, repo.SetRole(ctx, "user1", "owner"), repository.ErrInvalidValue)
+	assert.ErrorIs(t, repo.SetRole(ctx, "missing", models.RoleAdmin), repository.ErrNotFound)
+
+	require.NoError(t, repo.Create(ctx, &models.User{ID: "user2", Email: "second@example.com"}))
+	users, err := repo.ListUsers(ctx, 1, 1)
+	require.NoError(t, err)
+	require.Len(t, users, 1)
+	count, err := repo.CountUsers(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+}
+
+func TestUserRepository_PasswordHistory(t *testing.T) {
+	repo := NewUserRepository()
+	ctx := context.Background()
+	require.NoError(t, repo.Create(ctx, &models.User{ID: "user1", Email: "user@example.com", PasswordHash: "hash1"}))
+
+	require.NoError(t, repo.ChangePassword(ctx, "user1", "hash2", 2))
+	require.NoError(t, repo.ChangePassword(ctx, "user1", "hash3", 2))
+	require.NoError(t, repo.ChangePassword(ctx, "user1", "hash4", 2))
+
+	history, err := repo.PasswordHistory(ctx, "user1", 5)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"hash3", "hash2"}, history)
+
+	user, err := repo.GetByID(ctx, "user1")
+	require.NoError(t, err)
+	assert.Equal(t, "hash4", user.PasswordHash)
+
+	assert.ErrorIs(t, repo.ChangePassword(ctx, "missing", "hash", 2), repository.ErrNotFound)
+}
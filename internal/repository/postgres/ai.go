@@ -0,0 +1,53 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/jmoloko/taskmange/internal/domain/repository"
+	"github.com/lib/pq"
+)
+
+// результат действия AI-ассистента, сохраненный для задачи
+func (r *TaskRepository) GetAIResult(ctx context.Context, taskID string, action models.AIAction) (*models.AIResult, error) {
+	query := `
+		SELECT action, summary, subtasks, model, source_hash, generated_at
+		FROM task_ai_results
+		WHERE task_id = $1 AND action = $2
+	`
+	var result models.AIResult
+	err := r.db.QueryRowContext(ctx, query, taskID, action).Scan(
+		&result.Action, &result.Summary, pq.Array(&result.Subtasks), &result.Model, &result.SourceHash, &result.GeneratedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, repository.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get AI result: %w", err)
+	}
+
+	return &result, nil
+}
+
+// сохраняем результат действия, предыдущий результат того же действия заменяется
+func (r *TaskRepository) SaveAIResult(ctx context.Context, taskID string, result *models.AIResult) error {
+	query := `
+		INSERT INTO task_ai_results (task_id, action, summary, subtasks, model, source_hash, generated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (task_id, action) DO UPDATE SET
+			summary = EXCLUDED.summary,
+			subtasks = EXCLUDED.subtasks,
+			model = EXCLUDED.model,
+			source_hash = EXCLUDED.source_hash,
+			generated_at = EXCLUDED.generated_at
+	`
+	_, err := r.db.ExecContext(ctx, query, taskID, result.Action, result.Summary, pq.Array(tagList(result.Subtasks)),
+		result.Model, result.SourceHash, result.GeneratedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save AI result: %w", translateError(err))
+	}
+
+	return nil
+}
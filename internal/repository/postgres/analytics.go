@@ -0,0 +1,74 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jmoloko/taskmange/internal/domain/models"
+)
+
+type AnalyticsRepository struct {
+	db *sql.DB
+}
+
+func NewAnalyticsRepository(db *sql.DB) *AnalyticsRepository {
+	return &AnalyticsRepository{db: db}
+}
+
+// сохраняем снимок аналитики, снимок за тот же день перезаписывается
+func (r *AnalyticsRepository) SaveAnalyticsSnapshot(ctx context.Context, snapshot *models.AnalyticsSnapshot) error {
+	data, err := json.Marshal(snapshot.Analytics)
+	if err != nil {
+		return fmt.Errorf("failed to marshal analytics snapshot: %w", err)
+	}
+
+	query := `
+		INSERT INTO analytics_snapshots (user_id, snapshot_date, analytics)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (user_id, snapshot_date) DO UPDATE
+		SET analytics = EXCLUDED.analytics, created_at = now()
+	`
+	if _, err := r.db.ExecContext(ctx, query,
+		snapshot.UserID, snapshot.Date.Format("2006-01-02"), data); err != nil {
+		return fmt.Errorf("failed to save analytics snapshot: %w", err)
+	}
+
+	return nil
+}
+
+// снимки пользователя за период, по возрастанию даты
+func (r *AnalyticsRepository) GetAnalyticsSnapshots(ctx context.Context, userID string, from, to time.Time) ([]models.AnalyticsSnapshot, error) {
+	query := `
+		SELECT user_id, snapshot_date, analytics
+		FROM analytics_snapshots
+		WHERE user_id = $1 AND snapshot_date BETWEEN $2 AND $3
+		ORDER BY snapshot_date ASC
+	`
+	rows, err := r.db.QueryContext(ctx, query, userID, from.Format("2006-01-02"), to.Format("2006-01-02"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query analytics snapshots: %w", err)
+	}
+	defer rows.Close()
+
+	var snapshots []models.AnalyticsSnapshot
+	for rows.Next() {
+		var snapshot models.AnalyticsSnapshot
+		var data []byte
+		if err := rows.Scan(&snapshot.UserID, &snapshot.Date, &data); err != nil {
+			return nil, fmt.Errorf("failed to scan analytics snapshot: %w", err)
+		}
+		if err := json.Unmarshal(data, &snapshot.Analytics); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal analytics snapshot: %w", err)
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating analytics snapshots: %w", err)
+	}
+
+	return snapshots, nil
+}
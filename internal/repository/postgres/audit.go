@@ -0,0 +1,91 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/jmoloko/taskmange/internal/domain/models"
+)
+
+type AuditRepository struct {
+	db *sql.DB
+}
+
+func NewAuditRepository(db *sql.DB) *AuditRepository {
+	return &AuditRepository{db: db}
+}
+
+// сохраняем запись аудита, ID и created_at назначает БД
+func (r *AuditRepository) SaveAuditEvent(ctx context.Context, event *models.AuditEvent) error {
+	details, err := json.Marshal(event.Details)
+	if err != nil {
+		return fmt.Errorf("failed to marshal audit details: %w", err)
+	}
+	if event.Details == nil {
+		details = []byte("{}")
+	}
+
+	query := `
+		INSERT INTO audit_events (action, actor_id, impersonator_id, target, details)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at
+	`
+	err = r.db.QueryRowContext(ctx, query,
+		event.Action, event.ActorID, nullString(event.ImpersonatorID), event.Target, details).
+		Scan(&event.ID, &event.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save audit event: %w", err)
+	}
+
+	return nil
+}
+
+// последние записи аудита по фильтру
+func (r *AuditRepository) GetAuditEvents(ctx context.Context, filter models.AuditFilter) ([]models.AuditEvent, error) {
+	query := `
+		SELECT id, action, actor_id, COALESCE(impersonator_id::text, ''), target, details, created_at
+		FROM audit_events
+		WHERE TRUE
+	`
+	var args []interface{}
+	if filter.ActorID != "" {
+		args = append(args, filter.ActorID)
+		query += ` AND actor_id = $` + strconv.Itoa(len(args))
+	}
+	if filter.ImpersonatorID != "" {
+		args = append(args, filter.ImpersonatorID)
+		query += ` AND impersonator_id = $` + strconv.Itoa(len(args))
+	}
+	args = append(args, filter.Limit)
+	query += ` ORDER BY created_at DESC, id DESC LIMIT $` + strconv.Itoa(len(args))
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query audit events: %w", err)
+	}
+	defer rows.Close()
+
+	events := []models.AuditEvent{}
+	for rows.Next() {
+		var (
+			e       models.AuditEvent
+			details []byte
+		)
+		if err := rows.Scan(&e.ID, &e.Action, &e.ActorID, &e.ImpersonatorID, &e.Target, &details, &e.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan audit event: %w", err)
+		}
+		if err := json.Unmarshal(details, &e.Details); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal audit details: %w", err)
+		}
+		events = append(events, e)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate audit events: %w", err)
+	}
+
+	return events, nil
+}
@@ -0,0 +1,210 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/jmoloko/taskmange/internal/domain/repository"
+	"github.com/lib/pq"
+)
+
+// BackupRepository резервное копирование и восстановление пользователя
+type BackupRepository struct {
+	db *sql.DB
+}
+
+// NewBackupRepository создает новый экземпляр BackupRepository
+func NewBackupRepository(db *sql.DB) *BackupRepository {
+	return &BackupRepository{db: db}
+}
+
+// ExportUser читает учетную запись, задачи, связи и настройки уведомлений пользователя
+// в одной транзакции REPEATABLE READ, чтобы копия соответствовала одному моменту времени
+func (r *BackupRepository) ExportUser(ctx context.Context, userID string, emit func(models.BackupRecord) error) error {
+	tx, err := r.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return fmt.Errorf("failed to begin export: %w", err)
+	}
+	defer tx.Rollback()
+
+	account := models.BackupAccount{Version: models.BackupFormatVersion}
+	err = tx.QueryRowContext(ctx,
+		`SELECT id, email, password_hash, created_at, now() FROM users WHERE id = $1`, userID,
+	).Scan(&account.ID, &account.Email, &account.PasswordHash, &account.CreatedAt, &account.ExportedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return repository.ErrNotFound
+		}
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	if err := emit(models.BackupRecord{Type: models.BackupRecordAccount, Account: &account}); err != nil {
+		return err
+	}
+
+	if err := r.exportTasks(ctx, tx, userID, emit); err != nil {
+		return err
+	}
+	if err := r.exportRelations(ctx, tx, userID, emit); err != nil {
+		return err
+	}
+
+	var prefs models.NotificationPreferences
+	var channels []string
+	err = tx.QueryRowContext(ctx, `
+		SELECT user_id, channels, event_types, due_soon_window_minutes, digest_window_minutes,
+			quiet_hours_start, quiet_hours_end, timezone
+		FROM notification_preferences
+		WHERE user_id = $1
+	`, userID).Scan(
+		&prefs.UserID, pq.Array(&channels), pq.Array(&prefs.EventTypes),
+		&prefs.DueSoonWindowMinutes, &prefs.DigestWindowMinutes,
+		&prefs.QuietHoursStart, &prefs.QuietHoursEnd, &prefs.Timezone)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return nil
+	case err != nil:
+		return fmt.Errorf("failed to get notification preferences: %w", err)
+	}
+	for _, c := range channels {
+		prefs.Channels = append(prefs.Channels, models.NotificationChannel(c))
+	}
+
+	return emit(models.BackupRecord{Type: models.BackupRecordPreferences, Preferences: &prefs})
+}
+
+func (r *BackupRepository) exportTasks(ctx context.Context, tx *sql.Tx, userID string, emit func(models.BackupRecord) error) error {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT `+taskColumns+`
+		FROM `+allTasks+`
+		WHERE user_id = $1
+		ORDER BY created_at, id
+	`, userID)
+	if err != nil {
+		return fmt.Errorf("failed to query tasks: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		task, err := scanTask(rows)
+		if err != nil {
+			return fmt.Errorf("failed to scan task: %w", err)
+		}
+		if err := emit(models.BackupRecord{Type: models.BackupRecordTask, Task: &task}); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+func (r *BackupRepository) exportRelations(ctx context.Context, tx *sql.Tx, userID string, emit func(models.BackupRecord) error) error {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT rel.task_id, rel.related_task_id
+		FROM task_relations rel
+		JOIN tasks t ON t.id = rel.task_id
+		WHERE t.user_id = $1
+		ORDER BY rel.task_id, rel.related_task_id
+	`, userID)
+	if err != nil {
+		return fmt.Errorf("failed to query task relations: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var rel models.BackupRelation
+		if err := rows.Scan(&rel.TaskID, &rel.RelatedTaskID); err != nil {
+			return fmt.Errorf("failed to scan task relation: %w", err)
+		}
+		if err := emit(models.BackupRecord{Type: models.BackupRecordRelation, Relation: &rel}); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+// BeginUserImport открывает транзакцию восстановления
+func (r *BackupRepository) BeginUserImport(ctx context.Context) (repository.UserImport, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin import: %w", err)
+	}
+	return &userImport{tx: tx}, nil
+}
+
+// userImport записи восстановления в одной транзакции
+type userImport struct {
+	tx *sql.Tx
+}
+
+func (i *userImport) CreateAccount(ctx context.Context, account models.BackupAccount) error {
+	_, err := i.tx.ExecContext(ctx,
+		`INSERT INTO users (id, email, password_hash, created_at) VALUES ($1, $2, $3, $4)`,
+		account.ID, account.Email, account.PasswordHash, account.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create user: %w", translateError(err))
+	}
+	return nil
+}
+
+func (i *userImport) CreateTask(ctx context.Context, task models.Task) error {
+	links, err := marshalLinks(task.Links)
+	if err != nil {
+		return err
+	}
+
+	_, err = i.tx.ExecContext(ctx, `
+		INSERT INTO tasks (id, title, description, notes, links, tags, status, priority, user_id, due_date,
+			created_at, updated_at, completed_at, private, parent_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+	`, task.ID, task.Title, nullString(task.Description), nullStringPtr(task.Notes), links, pq.Array(tagList(task.Tags)),
+		task.Status, task.Priority, task.UserID, task.DueDate,
+		task.CreatedAt, task.UpdatedAt, task.CompletedAt, task.Private, nullStringPtr(task.ParentID))
+	if err != nil {
+		return fmt.Errorf("failed to create task %s: %w", task.ID, translateError(err))
+	}
+	return nil
+}
+
+func (i *userImport) CreateRelation(ctx context.Context, relation models.BackupRelation) error {
+	_, err := i.tx.ExecContext(ctx,
+		`INSERT INTO task_relations (task_id, related_task_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`,
+		relation.TaskID, relation.RelatedTaskID)
+	if err != nil {
+		return fmt.Errorf("failed to create task relation: %w", translateError(err))
+	}
+	return nil
+}
+
+func (i *userImport) SavePreferences(ctx context.Context, prefs models.NotificationPreferences) error {
+	channels := make([]string, 0, len(prefs.Channels))
+	for _, c := range prefs.Channels {
+		channels = append(channels, string(c))
+	}
+	eventTypes := prefs.EventTypes
+	if eventTypes == nil {
+		eventTypes = []string{}
+	}
+
+	_, err := i.tx.ExecContext(ctx, `
+		INSERT INTO notification_preferences (user_id, channels, event_types, due_soon_window_minutes,
+			digest_window_minutes, quiet_hours_start, quiet_hours_end, timezone)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`, prefs.UserID, pq.Array(channels), pq.Array(eventTypes), prefs.DueSoonWindowMinutes,
+		prefs.DigestWindowMinutes, prefs.QuietHoursStart, prefs.QuietHoursEnd, prefs.Timezone)
+	if err != nil {
+		return fmt.Errorf("failed to save notification preferences: %w", err)
+	}
+	return nil
+}
+
+func (i *userImport) Commit() error {
+	return i.tx.Commit()
+}
+
+func (i *userImport) Rollback() error {
+	return i.tx.Rollback()
+}
@@ -0,0 +1,70 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jmoloko/taskmange/internal/domain/repository"
+)
+
+type CalendarFeedRepository struct {
+	db *sql.DB
+}
+
+func NewCalendarFeedRepository(db *sql.DB) *CalendarFeedRepository {
+	return &CalendarFeedRepository{db: db}
+}
+
+// создаем подписку или увеличиваем ее версию, прежние ссылки перестают действовать
+func (r *CalendarFeedRepository) RotateCalendarFeed(ctx context.Context, userID string) (int, time.Time, error) {
+	var version int
+	var updatedAt time.Time
+	err := r.db.QueryRowContext(ctx, `
+		INSERT INTO calendar_feeds (user_id)
+		VALUES ($1)
+		ON CONFLICT (user_id) DO UPDATE
+		SET version = calendar_feeds.version + 1, updated_at = now()
+		RETURNING version, updated_at
+	`, userID).Scan(&version, &updatedAt)
+	if err != nil {
+		return 0, time.Time{}, fmt.Errorf("failed to rotate calendar feed: %w", translateError(err))
+	}
+
+	return version, updatedAt, nil
+}
+
+// текущая версия подписки
+func (r *CalendarFeedRepository) GetCalendarFeedVersion(ctx context.Context, userID string) (int, error) {
+	var version int
+	err := r.db.QueryRowContext(ctx, `SELECT version FROM calendar_feeds WHERE user_id = $1`, userID).Scan(&version)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("failed to get calendar feed: %w", err)
+	}
+
+	return version, nil
+}
+
+// отзываем подписку
+func (r *CalendarFeedRepository) DeleteCalendarFeed(ctx context.Context, userID string) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM calendar_feeds WHERE user_id = $1`, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete calendar feed: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return repository.ErrNotFound
+	}
+
+	return nil
+}
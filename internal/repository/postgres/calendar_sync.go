@@ -0,0 +1,223 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/jmoloko/taskmange/internal/domain/repository"
+)
+
+type CalendarSyncRepository struct {
+	db *sql.DB
+}
+
+func NewCalendarSyncRepository(db *sql.DB) *CalendarSyncRepository {
+	return &CalendarSyncRepository{db: db}
+}
+
+const calendarLinkColumns = `id, user_id, provider, calendar_id, username, secret, conflict_policy, sync_token,
+	channel_id, channel_token, channel_resource_id, channel_expires_at, synced_at, last_error, created_at`
+
+// подключаем календарь, created_at назначает БД
+func (r *CalendarSyncRepository) CreateCalendarLink(ctx context.Context, link *models.CalendarLink) error {
+	query := `
+		INSERT INTO calendar_links (id, user_id, provider, calendar_id, username, secret, conflict_policy)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING created_at
+	`
+	err := r.db.QueryRowContext(ctx, query, link.ID, link.UserID, link.Provider, link.CalendarID, link.Username,
+		link.Secret, link.ConflictPolicy).Scan(&link.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create calendar link: %w", translateError(err))
+	}
+
+	return nil
+}
+
+// подключение по ID вместе с учетными данными
+func (r *CalendarSyncRepository) GetCalendarLink(ctx context.Context, id string) (*models.CalendarLink, error) {
+	query := `SELECT ` + calendarLinkColumns + ` FROM calendar_links WHERE id = $1`
+	return r.getCalendarLink(ctx, query, id)
+}
+
+// подключение, которому принадлежит канал уведомлений
+func (r *CalendarSyncRepository) GetCalendarLinkByChannel(ctx context.Context, channelID string) (*models.CalendarLink, error) {
+	query := `SELECT ` + calendarLinkColumns + ` FROM calendar_links WHERE channel_id = $1`
+	return r.getCalendarLink(ctx, query, channelID)
+}
+
+// подключенные календари пользователя
+func (r *CalendarSyncRepository) GetCalendarLinks(ctx context.Context, userID string) ([]models.CalendarLink, error) {
+	query := `SELECT ` + calendarLinkColumns + ` FROM calendar_links WHERE user_id = $1 ORDER BY created_at ASC`
+	return r.queryCalendarLinks(ctx, query, userID)
+}
+
+// отключаем календарь пользователя, состояния событий удаляются каскадно
+func (r *CalendarSyncRepository) DeleteCalendarLink(ctx context.Context, userID, id string) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM calendar_links WHERE id = $1 AND user_id = $2`, id, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete calendar link: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return repository.ErrNotFound
+	}
+
+	return nil
+}
+
+// подключения для опроса календарей: несинхронизированные и синхронизированные раньше syncedBefore
+func (r *CalendarSyncRepository) GetCalendarLinksToSync(ctx context.Context, syncedBefore time.Time, limit int) ([]models.CalendarLink, error) {
+	query := `
+		SELECT ` + calendarLinkColumns + `
+		FROM calendar_links
+		WHERE synced_at IS NULL OR synced_at < $1
+		ORDER BY synced_at ASC NULLS FIRST
+		LIMIT $2
+	`
+	return r.queryCalendarLinks(ctx, query, syncedBefore, limit)
+}
+
+// сохраняем позицию в журнале изменений и результат синхронизации
+func (r *CalendarSyncRepository) UpdateCalendarSync(ctx context.Context, linkID, syncToken string, syncedAt time.Time, lastError string) error {
+	query := `UPDATE calendar_links SET sync_token = $2, synced_at = $3, last_error = $4 WHERE id = $1`
+	if _, err := r.db.ExecContext(ctx, query, linkID, syncToken, syncedAt, lastError); err != nil {
+		return fmt.Errorf("failed to update calendar sync: %w", err)
+	}
+	return nil
+}
+
+// сохраняем канал уведомлений, пустой ID удаляет канал
+func (r *CalendarSyncRepository) UpdateCalendarChannel(ctx context.Context, linkID string, channel models.CalendarChannel) error {
+	query := `
+		UPDATE calendar_links
+		SET channel_id = $2, channel_token = $3, channel_resource_id = $4, channel_expires_at = $5
+		WHERE id = $1
+	`
+	_, err := r.db.ExecContext(ctx, query, linkID, nullString(channel.ID), nullString(channel.Token),
+		nullString(channel.ResourceID), channel.ExpiresAt)
+	if err != nil {
+		return fmt.Errorf("failed to update calendar channel: %w", translateError(err))
+	}
+	return nil
+}
+
+// согласованный срок задачи
+func (r *CalendarSyncRepository) GetCalendarSyncState(ctx context.Context, linkID, taskID string) (*models.CalendarSyncState, error) {
+	query := `SELECT link_id, task_id, event_id, due_date, synced_at FROM calendar_sync_state WHERE link_id = $1 AND task_id = $2`
+	return r.getCalendarSyncState(ctx, query, linkID, taskID)
+}
+
+// согласованный срок задачи по ID ее события
+func (r *CalendarSyncRepository) GetCalendarSyncStateByEvent(ctx context.Context, linkID, eventID string) (*models.CalendarSyncState, error) {
+	query := `SELECT link_id, task_id, event_id, due_date, synced_at FROM calendar_sync_state WHERE link_id = $1 AND event_id = $2`
+	return r.getCalendarSyncState(ctx, query, linkID, eventID)
+}
+
+// создаем или заменяем состояние задачи; событие, пересозданное в календаре, получает новый ID
+func (r *CalendarSyncRepository) SaveCalendarSyncState(ctx context.Context, state models.CalendarSyncState) error {
+	query := `
+		INSERT INTO calendar_sync_state (link_id, task_id, event_id, due_date, synced_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (link_id, task_id) DO UPDATE
+		SET event_id = EXCLUDED.event_id, due_date = EXCLUDED.due_date, synced_at = EXCLUDED.synced_at
+	`
+	_, err := r.db.ExecContext(ctx, query, state.LinkID, state.TaskID, state.EventID, state.DueDate, state.SyncedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save calendar sync state: %w", translateError(err))
+	}
+	return nil
+}
+
+// удаляем связь задачи с событием
+func (r *CalendarSyncRepository) DeleteCalendarSyncState(ctx context.Context, linkID, taskID string) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM calendar_sync_state WHERE link_id = $1 AND task_id = $2`, linkID, taskID)
+	if err != nil {
+		return fmt.Errorf("failed to delete calendar sync state: %w", err)
+	}
+	return nil
+}
+
+func (r *CalendarSyncRepository) getCalendarLink(ctx context.Context, query string, args ...interface{}) (*models.CalendarLink, error) {
+	link, err := scanCalendarLink(r.db.QueryRowContext(ctx, query, args...))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, repository.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get calendar link: %w", err)
+	}
+
+	return &link, nil
+}
+
+func (r *CalendarSyncRepository) queryCalendarLinks(ctx context.Context, query string, args ...interface{}) ([]models.CalendarLink, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query calendar links: %w", err)
+	}
+	defer rows.Close()
+
+	var links []models.CalendarLink
+	for rows.Next() {
+		link, err := scanCalendarLink(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan calendar link: %w", err)
+		}
+		links = append(links, link)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating calendar links: %w", err)
+	}
+
+	return links, nil
+}
+
+func (r *CalendarSyncRepository) getCalendarSyncState(ctx context.Context, query string, args ...interface{}) (*models.CalendarSyncState, error) {
+	var state models.CalendarSyncState
+	err := r.db.QueryRowContext(ctx, query, args...).Scan(&state.LinkID, &state.TaskID, &state.EventID, &state.DueDate, &state.SyncedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, repository.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get calendar sync state: %w", err)
+	}
+
+	return &state, nil
+}
+
+func scanCalendarLink(row rowScanner) (models.CalendarLink, error) {
+	var (
+		link                                       models.CalendarLink
+		channelID, channelToken, channelResourceID sql.NullString
+		channelExpiresAt, syncedAt                 sql.NullTime
+	)
+	err := row.Scan(&link.ID, &link.UserID, &link.Provider, &link.CalendarID, &link.Username, &link.Secret,
+		&link.ConflictPolicy, &link.SyncToken, &channelID, &channelToken, &channelResourceID, &channelExpiresAt,
+		&syncedAt, &link.LastError, &link.CreatedAt)
+	if err != nil {
+		return models.CalendarLink{}, err
+	}
+	link.Channel = models.CalendarChannel{
+		ID:         channelID.String,
+		Token:      channelToken.String,
+		ResourceID: channelResourceID.String,
+	}
+	if channelExpiresAt.Valid {
+		link.Channel.ExpiresAt = &channelExpiresAt.Time
+	}
+	if syncedAt.Valid {
+		link.SyncedAt = &syncedAt.Time
+	}
+
+	return link, nil
+}
@@ -0,0 +1,121 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jmoloko/taskmange/internal/domain/models"
+)
+
+// taskChangesLock ключ advisory-блокировки записи журнала изменений. Записи разных экземпляров
+// идут по очереди, поэтому seq становятся видны читателям в порядке возрастания. Значение — "cdc" в ASCII
+const taskChangesLock = 0x636463
+
+type TaskChangeRepository struct {
+	db *sql.DB
+}
+
+func NewTaskChangeRepository(db *sql.DB) *TaskChangeRepository {
+	return &TaskChangeRepository{db: db}
+}
+
+// записываем изменения одной транзакцией, номер изменения задачи увеличивается в task_change_versions
+func (r *TaskChangeRepository) AppendTaskChanges(ctx context.Context, changes []models.TaskChange) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin task changes: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock($1)`, taskChangesLock); err != nil {
+		return fmt.Errorf("failed to lock task changes: %w", err)
+	}
+
+	for i := range changes {
+		change := &changes[i]
+		task, err := json.Marshal(change.Task)
+		if err != nil {
+			return fmt.Errorf("failed to encode task change: %w", err)
+		}
+		var fields []byte
+		if len(change.Changes) > 0 {
+			if fields, err = json.Marshal(change.Changes); err != nil {
+				return fmt.Errorf("failed to encode task change fields: %w", err)
+			}
+		}
+
+		err = tx.QueryRowContext(ctx, `
+			WITH version AS (
+				INSERT INTO task_change_versions (task_id, version)
+				VALUES ($1, 1)
+				ON CONFLICT (task_id) DO UPDATE SET version = task_change_versions.version + 1
+				RETURNING version
+			)
+			INSERT INTO task_changes (task_id, user_id, version, type, task, changes, occurred_at)
+			SELECT $1, $2, version, $3, $4, $5, $6 FROM version
+			RETURNING seq, version
+		`, change.TaskID, change.UserID, change.Type, task, fields, change.OccurredAt).Scan(&change.Seq, &change.Version)
+		if err != nil {
+			return fmt.Errorf("failed to record task change: %w", err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// записи журнала после after
+func (r *TaskChangeRepository) GetTaskChanges(ctx context.Context, after int64, limit int) ([]models.TaskChange, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT seq, task_id, user_id, version, type, task, changes, occurred_at
+		FROM task_changes
+		WHERE seq > $1
+		ORDER BY seq
+		LIMIT $2
+	`, after, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query task changes: %w", err)
+	}
+	defer rows.Close()
+
+	var changes []models.TaskChange
+	for rows.Next() {
+		var change models.TaskChange
+		var task, fields []byte
+		if err := rows.Scan(&change.Seq, &change.TaskID, &change.UserID, &change.Version, &change.Type, &task, &fields, &change.OccurredAt); err != nil {
+			return nil, fmt.Errorf("failed to scan task change: %w", err)
+		}
+		if err := json.Unmarshal(task, &change.Task); err != nil {
+			return nil, fmt.Errorf("failed to decode task change %d: %w", change.Seq, err)
+		}
+		if fields != nil {
+			if err := json.Unmarshal(fields, &change.Changes); err != nil {
+				return nil, fmt.Errorf("failed to decode task change %d: %w", change.Seq, err)
+			}
+		}
+		changes = append(changes, change)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating task changes: %w", err)
+	}
+
+	return changes, nil
+}
+
+// удаляем записи старше срока хранения
+func (r *TaskChangeRepository) DeleteTaskChanges(ctx context.Context, before time.Time) (int64, error) {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM task_changes WHERE recorded_at < $1`, before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete task changes: %w", err)
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return deleted, nil
+}
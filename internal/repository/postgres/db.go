@@ -5,7 +5,7 @@ import (
 	"fmt"
 
 	"github.com/jmoloko/taskmange/internal/config"
-	_ "github.com/lib/pq" // Import PostgreSQL driver
+	"github.com/lib/pq"
 )
 
 func NewPostgresDB(cfg config.DatabaseConfig) (*sql.DB, error) {
@@ -14,10 +14,16 @@ func NewPostgresDB(cfg config.DatabaseConfig) (*sql.DB, error) {
 		cfg.Host, cfg.Port, cfg.User, cfg.Password, cfg.DBName, cfg.SSLMode,
 	)
 
-	db, err := sql.Open("postgres", connStr)
+	connector, err := pq.NewConnector(connStr)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database connection: %w", err)
 	}
+	// время запросов учитывается в заголовке Server-Timing
+	db := sql.OpenDB(timedConnector{connector})
+
+	if cfg.MaxOpenConns > 0 {
+		db.SetMaxOpenConns(cfg.MaxOpenConns)
+	}
 
 	if err := db.Ping(); err != nil {
 		db.Close()
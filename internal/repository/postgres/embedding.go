@@ -0,0 +1,147 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/jmoloko/taskmange/internal/domain/repository"
+)
+
+// EmbeddingsInstalled проверяет, что миграция эмбеддингов создала таблицу task_embeddings.
+// Без расширения pgvector в образе Postgres миграция ее пропускает
+func (r *TaskRepository) EmbeddingsInstalled(ctx context.Context) (bool, error) {
+	var installed bool
+	if err := r.db.QueryRowContext(ctx, `SELECT to_regclass('task_embeddings') IS NOT NULL`).Scan(&installed); err != nil {
+		return false, fmt.Errorf("failed to check task_embeddings table: %w", err)
+	}
+
+	return installed, nil
+}
+
+// хэш текста, по которому построен вектор задачи
+func (r *TaskRepository) GetEmbeddingHash(ctx context.Context, taskID, model string) (string, error) {
+	var hash string
+	err := r.db.QueryRowContext(ctx, `
+		SELECT source_hash FROM task_embeddings WHERE task_id = $1 AND model = $2
+	`, taskID, model).Scan(&hash)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", repository.ErrNotFound
+		}
+		return "", fmt.Errorf("failed to get embedding: %w", err)
+	}
+
+	return hash, nil
+}
+
+// сохраняем вектор задачи. Событие об изменении могло прийти после удаления задачи,
+// поэтому вектор пишется, только если задача еще существует
+func (r *TaskRepository) SaveEmbedding(ctx context.Context, embedding models.TaskEmbedding) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO task_embeddings (task_id, user_id, model, source_hash, embedding)
+		SELECT $1, $2, $3, $4, $5::vector
+		WHERE EXISTS (SELECT 1 FROM tasks WHERE id = $1)
+		ON CONFLICT (task_id) DO UPDATE SET
+			model = EXCLUDED.model,
+			source_hash = EXCLUDED.source_hash,
+			embedding = EXCLUDED.embedding,
+			updated_at = now()
+	`, embedding.TaskID, embedding.UserID, embedding.Model, embedding.SourceHash, vectorLiteral(embedding.Vector))
+	if err != nil {
+		return fmt.Errorf("failed to save embedding: %w", translateError(err))
+	}
+
+	return nil
+}
+
+// удаляем вектор задачи, например когда она стала приватной
+func (r *TaskRepository) DeleteEmbedding(ctx context.Context, taskID string) error {
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM task_embeddings WHERE task_id = $1`, taskID); err != nil {
+		return fmt.Errorf("failed to delete embedding: %w", err)
+	}
+
+	return nil
+}
+
+// неприватные задачи без вектора модели, начиная с новых
+func (r *TaskRepository) GetTasksWithoutEmbedding(ctx context.Context, model string, limit int) ([]models.Task, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+taskColumns+`
+		FROM tasks t
+		WHERE NOT private
+			AND NOT EXISTS (SELECT 1 FROM task_embeddings e WHERE e.task_id = t.id AND e.model = $1)
+		ORDER BY created_at DESC
+		LIMIT $2
+	`, model, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tasks without embedding: %w", err)
+	}
+	defer rows.Close()
+
+	var tasks []models.Task
+	for rows.Next() {
+		task, err := scanTask(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan task: %w", err)
+		}
+		tasks = append(tasks, task)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating tasks: %w", err)
+	}
+
+	return tasks, nil
+}
+
+// похожие задачи пользователя по косинусной близости векторов (оператор <=> pgvector — косинусное расстояние).
+// Подзапрос нужен, чтобы колонки векторов не пересекались с колонками задачи
+func (r *TaskRepository) FindSimilarTasks(ctx context.Context, taskID, model string, minScore float64, limit int) ([]models.SimilarTask, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT score, `+taskColumns+`
+		FROM (
+			SELECT t.*, 1 - (e.embedding <=> src.embedding) AS score
+			FROM task_embeddings src
+			JOIN task_embeddings e ON e.user_id = src.user_id AND e.model = src.model AND e.task_id <> src.task_id
+			JOIN tasks t ON t.id = e.task_id
+			WHERE src.task_id = $1 AND src.model = $2 AND NOT t.private
+		) similar
+		WHERE score >= $3
+		ORDER BY score DESC, created_at DESC
+		LIMIT $4
+	`, taskID, model, minScore, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query similar tasks: %w", err)
+	}
+	defer rows.Close()
+
+	var similar []models.SimilarTask
+	for rows.Next() {
+		var score float64
+		task, err := scanTask(prefixScanner{row: rows, dest: []interface{}{&score}})
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan similar task: %w", err)
+		}
+		similar = append(similar, models.SimilarTask{Task: task, Score: score})
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating similar tasks: %w", err)
+	}
+
+	return similar, nil
+}
+
+// vectorLiteral вектор в текстовом формате pgvector: [0.1,0.2,...]
+func vectorLiteral(vector []float32) string {
+	parts := make([]string, len(vector))
+	for i, v := range vector {
+		parts[i] = strconv.FormatFloat(float64(v), 'g', -1, 32)
+	}
+	return "[" + strings.Join(parts, ",") + "]"
+}
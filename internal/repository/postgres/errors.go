@@ -0,0 +1,72 @@
+package postgres
+
+import (
+	"errors"
+	"regexp"
+
+	"github.com/jmoloko/taskmange/internal/domain/repository"
+	"github.com/lib/pq"
+)
+
+// constraintFields поля моделей по именам ограничений и перечислений базы.
+// Имена ограничений — те, что Postgres назначает по умолчанию в migrations
+var constraintFields = map[string]string{
+	"users_pkey":                          "id",
+	"users_email_key":                     "email",
+	"tasks_pkey":                          "id",
+	"tasks_user_id_fkey":                  "user_id",
+	"task_status":                         "status",
+	"task_priority":                       "priority",
+	"push_subscriptions_endpoint_key":     "endpoint",
+	"triggers_action_type_check":          "action_type",
+	"task_relations_check":                "related_task_id",
+	"task_relations_task_id_fkey":         "task_id",
+	"task_relations_related_task_id_fkey": "related_task_id",
+	"incoming_hooks_token_hash_key":       "token",
+	"task_external_refs_task_id_provider_external_key_key": "external_key",
+	"github_repo_links_user_id_repository_key":             "repository",
+	"projects_user_id_name_key":                            "name",
+	"tasks_project_id_fkey":                                "project_id",
+	"tasks_assignee_id_fkey":                               "assignee_id",
+}
+
+// enumValueMessage сообщение Postgres о значении вне перечисления, единственное место, где есть имя типа
+var enumValueMessage = regexp.MustCompile(`invalid input value for enum (\w+)`)
+
+// translateError переводит нарушение ограничения из ошибки драйвера в *repository.ConstraintError,
+// остальные ошибки возвращает без изменений
+func translateError(err error) error {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return err
+	}
+
+	constraint := pqErr.Constraint
+	var kind error
+	switch pqErr.Code {
+	case "23505": // unique_violation
+		kind = repository.ErrAlreadyExists
+	case "23503": // foreign_key_violation
+		kind = repository.ErrInvalidReference
+	case "23514": // check_violation
+		kind = repository.ErrInvalidValue
+	case "23502": // not_null_violation
+		return &repository.ConstraintError{Err: repository.ErrInvalidValue, Field: pqErr.Column}
+	case "22001": // string_data_right_truncation
+		kind = repository.ErrInvalidValue
+	case "22P02": // invalid_text_representation, в том числе значение вне перечисления
+		match := enumValueMessage.FindStringSubmatch(pqErr.Message)
+		if match == nil {
+			return err
+		}
+		kind, constraint = repository.ErrInvalidValue, match[1]
+	default:
+		return err
+	}
+
+	return &repository.ConstraintError{
+		Err:        kind,
+		Constraint: constraint,
+		Field:      constraintFields[constraint],
+	}
+}
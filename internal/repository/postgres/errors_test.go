@@ -0,0 +1,45 @@
+package postgres
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/jmoloko/taskmange/internal/domain/repository"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTranslateError(t *testing.T) {
+	tests := []struct {
+		name  string
+		err   *pq.Error
+		kind  error
+		field string
+	}{
+		{"duplicate email", &pq.Error{Code: "23505", Constraint: "users_email_key"}, repository.ErrAlreadyExists, "email"},
+		{"missing user", &pq.Error{Code: "23503", Constraint: "tasks_user_id_fkey"}, repository.ErrInvalidReference, "user_id"},
+		{"check", &pq.Error{Code: "23514", Constraint: "triggers_action_type_check"}, repository.ErrInvalidValue, "action_type"},
+		{"not null", &pq.Error{Code: "23502", Column: "title"}, repository.ErrInvalidValue, "title"},
+		{"bad status", &pq.Error{Code: "22P02", Message: `invalid input value for enum task_status: "archived"`}, repository.ErrInvalidValue, "status"},
+		{"unknown constraint", &pq.Error{Code: "23505", Constraint: "something_key"}, repository.ErrAlreadyExists, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := translateError(fmt.Errorf("query failed: %w", tt.err))
+
+			var violation *repository.ConstraintError
+			require.True(t, errors.As(err, &violation))
+			assert.True(t, errors.Is(err, tt.kind))
+			assert.Equal(t, tt.field, violation.Field)
+		})
+	}
+
+	// прочие ошибки не меняются
+	other := &pq.Error{Code: "22P02", Message: `invalid input syntax for type uuid: "x"`}
+	assert.Same(t, other, translateError(other))
+	plain := errors.New("connection refused")
+	assert.Equal(t, plain, translateError(plain))
+}
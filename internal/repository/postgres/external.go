@@ -0,0 +1,132 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/jmoloko/taskmange/internal/domain/repository"
+)
+
+type ExternalRefRepository struct {
+	db *sql.DB
+}
+
+func NewExternalRefRepository(db *sql.DB) *ExternalRefRepository {
+	return &ExternalRefRepository{db: db}
+}
+
+const externalRefColumns = `id, task_id, user_id, provider, external_key, url, title, status, closed,
+	mirror_completion, checked_at, created_at`
+
+// сохраняем связь; при повторной связи с той же внешней задачей возвращаем существующую
+func (r *ExternalRefRepository) SaveExternalRef(ctx context.Context, ref *models.ExternalRef) error {
+	query := `
+		INSERT INTO task_external_refs (id, task_id, user_id, provider, external_key, url, title, status, closed,
+			mirror_completion, checked_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		ON CONFLICT (task_id, provider, external_key) DO UPDATE SET mirror_completion = EXCLUDED.mirror_completion
+		RETURNING ` + externalRefColumns
+	row := r.db.QueryRowContext(ctx, query,
+		ref.ID, ref.TaskID, ref.UserID, ref.Provider, ref.Key, ref.URL, ref.Title, ref.Status, ref.Closed,
+		ref.MirrorCompletion, ref.CheckedAt)
+	saved, err := scanExternalRef(row)
+	if err != nil {
+		return fmt.Errorf("failed to save external ref: %w", translateError(err))
+	}
+	*ref = saved
+
+	return nil
+}
+
+// связи задачи
+func (r *ExternalRefRepository) GetExternalRefs(ctx context.Context, taskID string) ([]models.ExternalRef, error) {
+	query := `SELECT ` + externalRefColumns + ` FROM task_external_refs WHERE task_id = $1 ORDER BY created_at ASC`
+	return r.queryExternalRefs(ctx, query, taskID)
+}
+
+// удаляем связь задачи
+func (r *ExternalRefRepository) DeleteExternalRef(ctx context.Context, taskID, refID string) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM task_external_refs WHERE id = $1 AND task_id = $2`, refID, taskID)
+	if err != nil {
+		return fmt.Errorf("failed to delete external ref: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return repository.ErrNotFound
+	}
+
+	return nil
+}
+
+// связи для опроса трекеров: непроверенные и проверенные раньше checkedBefore
+func (r *ExternalRefRepository) GetExternalRefsToCheck(ctx context.Context, checkedBefore time.Time, limit int) ([]models.ExternalRef, error) {
+	query := `
+		SELECT ` + externalRefColumns + `
+		FROM task_external_refs
+		WHERE checked_at IS NULL OR checked_at < $1
+		ORDER BY checked_at ASC NULLS FIRST
+		LIMIT $2
+	`
+	return r.queryExternalRefs(ctx, query, checkedBefore, limit)
+}
+
+// сохраняем состояние внешней задачи
+func (r *ExternalRefRepository) UpdateExternalIssue(ctx context.Context, refID string, issue models.ExternalIssue, checkedAt time.Time) error {
+	query := `
+		UPDATE task_external_refs
+		SET url = $2, title = $3, status = $4, closed = $5, checked_at = $6
+		WHERE id = $1
+	`
+	if _, err := r.db.ExecContext(ctx, query, refID, issue.URL, issue.Title, issue.Status, issue.Closed, checkedAt); err != nil {
+		return fmt.Errorf("failed to update external issue: %w", err)
+	}
+	return nil
+}
+
+func (r *ExternalRefRepository) queryExternalRefs(ctx context.Context, query string, args ...interface{}) ([]models.ExternalRef, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query external refs: %w", err)
+	}
+	defer rows.Close()
+
+	var refs []models.ExternalRef
+	for rows.Next() {
+		ref, err := scanExternalRef(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan external ref: %w", err)
+		}
+		refs = append(refs, ref)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating external refs: %w", err)
+	}
+
+	return refs, nil
+}
+
+func scanExternalRef(row rowScanner) (models.ExternalRef, error) {
+	var (
+		ref       models.ExternalRef
+		checkedAt sql.NullTime
+	)
+	err := row.Scan(&ref.ID, &ref.TaskID, &ref.UserID, &ref.Provider, &ref.Key, &ref.URL, &ref.Title, &ref.Status,
+		&ref.Closed, &ref.MirrorCompletion, &checkedAt, &ref.CreatedAt)
+	if err != nil {
+		return models.ExternalRef{}, err
+	}
+	if checkedAt.Valid {
+		ref.CheckedAt = &checkedAt.Time
+	}
+
+	return ref, nil
+}
@@ -0,0 +1,93 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/jmoloko/taskmange/internal/domain/repository"
+)
+
+type GitHubRepoLinkRepository struct {
+	db *sql.DB
+}
+
+func NewGitHubRepoLinkRepository(db *sql.DB) *GitHubRepoLinkRepository {
+	return &GitHubRepoLinkRepository{db: db}
+}
+
+// подключаем репозиторий, created_at назначает БД
+func (r *GitHubRepoLinkRepository) CreateRepoLink(ctx context.Context, link *models.GitHubRepoLink) error {
+	query := `
+		INSERT INTO github_repo_links (id, user_id, repository, secret)
+		VALUES ($1, $2, $3, $4)
+		RETURNING created_at
+	`
+	err := r.db.QueryRowContext(ctx, query, link.ID, link.UserID, link.Repository, link.Secret).Scan(&link.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create repo link: %w", translateError(err))
+	}
+
+	return nil
+}
+
+// подключение по ID вместе с секретом для проверки подписи
+func (r *GitHubRepoLinkRepository) GetRepoLink(ctx context.Context, id string) (*models.GitHubRepoLink, error) {
+	query := `SELECT id, user_id, repository, secret, created_at FROM github_repo_links WHERE id = $1`
+	var link models.GitHubRepoLink
+	err := r.db.QueryRowContext(ctx, query, id).Scan(&link.ID, &link.UserID, &link.Repository, &link.Secret, &link.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, repository.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get repo link: %w", err)
+	}
+
+	return &link, nil
+}
+
+// подключенные репозитории пользователя без секретов
+func (r *GitHubRepoLinkRepository) GetRepoLinks(ctx context.Context, userID string) ([]models.GitHubRepoLink, error) {
+	query := `SELECT id, user_id, repository, created_at FROM github_repo_links WHERE user_id = $1 ORDER BY repository ASC`
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query repo links: %w", err)
+	}
+	defer rows.Close()
+
+	var links []models.GitHubRepoLink
+	for rows.Next() {
+		var link models.GitHubRepoLink
+		if err := rows.Scan(&link.ID, &link.UserID, &link.Repository, &link.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan repo link: %w", err)
+		}
+		links = append(links, link)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating repo links: %w", err)
+	}
+
+	return links, nil
+}
+
+// отключаем репозиторий пользователя
+func (r *GitHubRepoLinkRepository) DeleteRepoLink(ctx context.Context, userID, id string) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM github_repo_links WHERE id = $1 AND user_id = $2`, id, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete repo link: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return repository.ErrNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,131 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/jmoloko/taskmange/internal/domain/repository"
+)
+
+type IncomingHookRepository struct {
+	db *sql.DB
+}
+
+func NewIncomingHookRepository(db *sql.DB) *IncomingHookRepository {
+	return &IncomingHookRepository{db: db}
+}
+
+const incomingHookColumns = `id, user_id, name, mapping, created_at, last_used_at`
+
+// сохраняем входящий webhook с хешем токена, created_at назначает БД
+func (r *IncomingHookRepository) CreateIncomingHook(ctx context.Context, hook *models.IncomingHook, tokenHash string) error {
+	mapping, err := json.Marshal(hook.Mapping)
+	if err != nil {
+		return fmt.Errorf("failed to marshal hook mapping: %w", err)
+	}
+
+	query := `
+		INSERT INTO incoming_hooks (id, user_id, name, token_hash, mapping)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING created_at
+	`
+	err = r.db.QueryRowContext(ctx, query, hook.ID, hook.UserID, hook.Name, tokenHash, mapping).Scan(&hook.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create incoming hook: %w", translateError(err))
+	}
+
+	return nil
+}
+
+// входящие webhook пользователя
+func (r *IncomingHookRepository) GetIncomingHooks(ctx context.Context, userID string) ([]models.IncomingHook, error) {
+	query := `SELECT ` + incomingHookColumns + ` FROM incoming_hooks WHERE user_id = $1 ORDER BY created_at ASC`
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query incoming hooks: %w", err)
+	}
+	defer rows.Close()
+
+	var hooks []models.IncomingHook
+	for rows.Next() {
+		hook, err := scanIncomingHook(rows)
+		if err != nil {
+			return nil, err
+		}
+		hooks = append(hooks, *hook)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating incoming hooks: %w", err)
+	}
+
+	return hooks, nil
+}
+
+// входящий webhook по хешу токена
+func (r *IncomingHookRepository) GetIncomingHookByToken(ctx context.Context, tokenHash string) (*models.IncomingHook, error) {
+	query := `SELECT ` + incomingHookColumns + ` FROM incoming_hooks WHERE token_hash = $1`
+	hook, err := scanIncomingHook(r.db.QueryRowContext(ctx, query, tokenHash))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, repository.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return hook, nil
+}
+
+// удаляем входящий webhook пользователя
+func (r *IncomingHookRepository) DeleteIncomingHook(ctx context.Context, userID, hookID string) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM incoming_hooks WHERE id = $1 AND user_id = $2`, hookID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete incoming hook: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return repository.ErrNotFound
+	}
+
+	return nil
+}
+
+// отмечаем время последнего входящего запроса
+func (r *IncomingHookRepository) TouchIncomingHook(ctx context.Context, hookID string, usedAt time.Time) error {
+	if _, err := r.db.ExecContext(ctx, `UPDATE incoming_hooks SET last_used_at = $2 WHERE id = $1`, hookID, usedAt); err != nil {
+		return fmt.Errorf("failed to touch incoming hook: %w", err)
+	}
+	return nil
+}
+
+func scanIncomingHook(row rowScanner) (*models.IncomingHook, error) {
+	var (
+		hook       models.IncomingHook
+		mapping    []byte
+		lastUsedAt sql.NullTime
+	)
+	if err := row.Scan(&hook.ID, &hook.UserID, &hook.Name, &mapping, &hook.CreatedAt, &lastUsedAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to scan incoming hook: %w", err)
+	}
+	if err := json.Unmarshal(mapping, &hook.Mapping); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal hook mapping: %w", err)
+	}
+	if lastUsedAt.Valid {
+		hook.LastUsedAt = &lastUsedAt.Time
+	}
+
+	return &hook, nil
+}
@@ -0,0 +1,82 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/jmoloko/taskmange/internal/domain/repository"
+)
+
+type ImpersonationRepository struct {
+	db *sql.DB
+}
+
+func NewImpersonationRepository(db *sql.DB) *ImpersonationRepository {
+	return &ImpersonationRepository{db: db}
+}
+
+// сохраняем сессию имперсонации, created_at назначает БД
+func (r *ImpersonationRepository) CreateImpersonation(ctx context.Context, impersonation *models.Impersonation) error {
+	query := `
+		INSERT INTO impersonations (id, admin_id, user_id, reason, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING created_at
+	`
+	err := r.db.QueryRowContext(ctx, query,
+		impersonation.ID, impersonation.AdminID, impersonation.UserID, impersonation.Reason, impersonation.ExpiresAt).
+		Scan(&impersonation.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save impersonation: %w", err)
+	}
+
+	return nil
+}
+
+// сессия имперсонации по ID
+func (r *ImpersonationRepository) GetImpersonation(ctx context.Context, id string) (*models.Impersonation, error) {
+	query := `
+		SELECT id, admin_id, user_id, reason, created_at, expires_at, revoked_at
+		FROM impersonations
+		WHERE id = $1
+	`
+	var (
+		imp       models.Impersonation
+		revokedAt sql.NullTime
+	)
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
+		&imp.ID, &imp.AdminID, &imp.UserID, &imp.Reason, &imp.CreatedAt, &imp.ExpiresAt, &revokedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, repository.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get impersonation: %w", err)
+	}
+	if revokedAt.Valid {
+		imp.RevokedAt = &revokedAt.Time
+	}
+
+	return &imp, nil
+}
+
+// отзываем действующую сессию имперсонации
+func (r *ImpersonationRepository) RevokeImpersonation(ctx context.Context, id string, revokedAt time.Time) error {
+	result, err := r.db.ExecContext(ctx,
+		`UPDATE impersonations SET revoked_at = $2 WHERE id = $1 AND revoked_at IS NULL`, id, revokedAt)
+	if err != nil {
+		return fmt.Errorf("failed to revoke impersonation: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if rows == 0 {
+		return repository.ErrNotFound
+	}
+
+	return nil
+}
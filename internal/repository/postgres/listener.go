@@ -0,0 +1,109 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/jmoloko/taskmange/internal/config"
+	"github.com/jmoloko/taskmange/internal/logger"
+	"github.com/jmoloko/taskmange/internal/metrics"
+	"github.com/lib/pq"
+)
+
+const (
+	// канал, в который триггер tasks_notify_change публикует ID владельца задачи
+	taskChangesChannel = "task_changes"
+	// время на сброс кэша одного пользователя
+	invalidateTimeout = 5 * time.Second
+)
+
+// InvalidateFunc сбрасывает один из кэшей пользователя
+type InvalidateFunc func(ctx context.Context, userID string) error
+
+// TaskChangeListener слушает изменения таблицы tasks через LISTEN/NOTIFY и сбрасывает
+// кэши владельца задачи. Изменения видны всем экземплярам приложения, включая правки
+// в обход API, поэтому кэш не зависит от того, какой экземпляр выполнил запись
+type TaskChangeListener struct {
+	listener     *pq.Listener
+	invalidators []InvalidateFunc
+	logger       logger.Logger
+}
+
+// NewTaskChangeListener создает слушателя с отдельным соединением к базе.
+// invalidators вызываются для владельца каждой измененной задачи
+func NewTaskChangeListener(cfg config.DatabaseConfig, logger logger.Logger, invalidators ...InvalidateFunc) *TaskChangeListener {
+	l := &TaskChangeListener{
+		invalidators: invalidators,
+		logger:       logger,
+	}
+	l.listener = pq.NewListener(cfg.ConnectionString(), 10*time.Second, time.Minute, l.onEvent)
+	return l
+}
+
+// Run подписывается на канал и обрабатывает уведомления до отмены контекста
+func (l *TaskChangeListener) Run(ctx context.Context) error {
+	if err := l.listener.Listen(taskChangesChannel); err != nil {
+		return err
+	}
+	defer l.listener.Close()
+
+	for {
+		select {
+		case n := <-l.listener.Notify:
+			// nil приходит после переподключения
+			if n == nil {
+				continue
+			}
+			l.invalidate(ctx, n.Extra)
+		case <-time.After(90 * time.Second):
+			// проверяем соединение, если уведомлений давно не было
+			go l.listener.Ping()
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// invalidate сбрасывает кэши пользователя
+func (l *TaskChangeListener) invalidate(ctx context.Context, userID string) {
+	ctx, cancel := context.WithTimeout(ctx, invalidateTimeout)
+	defer cancel()
+
+	failed := false
+	for _, invalidate := range l.invalidators {
+		if err := invalidate(ctx, userID); err != nil {
+			l.logger.Error("Failed to invalidate user cache", map[string]interface{}{
+				"user_id": userID,
+				"error":   err.Error(),
+			})
+			failed = true
+		}
+	}
+	if !failed {
+		metrics.CacheInvalidationsTotal.Inc()
+	}
+}
+
+// onEvent логирует состояние соединения слушателя
+func (l *TaskChangeListener) onEvent(event pq.ListenerEventType, err error) {
+	switch event {
+	case pq.ListenerEventDisconnected:
+		l.logger.Warn("Task change listener disconnected", map[string]interface{}{
+			"error": errString(err),
+		})
+	case pq.ListenerEventReconnected:
+		// уведомления за время разрыва потеряны, такие кэши истекут по TTL
+		l.logger.Warn("Task change listener reconnected, changes during the outage were not observed")
+	case pq.ListenerEventConnectionAttemptFailed:
+		l.logger.Error("Task change listener failed to connect", map[string]interface{}{
+			"error": errString(err),
+		})
+	}
+}
+
+func errString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
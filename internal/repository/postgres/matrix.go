@@ -0,0 +1,50 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/jmoloko/taskmange/internal/domain/models"
+)
+
+type MatrixSettingsRepository struct {
+	db *sql.DB
+}
+
+func NewMatrixSettingsRepository(db *sql.DB) *MatrixSettingsRepository {
+	return &MatrixSettingsRepository{db: db}
+}
+
+// GetMatrixSettings пороги матрицы пользователя, nil — не настраивались
+func (r *MatrixSettingsRepository) GetMatrixSettings(ctx context.Context, userID string) (*models.MatrixSettings, error) {
+	settings := &models.MatrixSettings{}
+	err := r.db.QueryRowContext(ctx, `
+		SELECT user_id, urgent_within_hours, important_priority
+		FROM matrix_settings WHERE user_id = $1`, userID).Scan(
+		&settings.UserID, &settings.UrgentWithinHours, &settings.ImportantPriority)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get matrix settings: %w", err)
+	}
+	return settings, nil
+}
+
+// SaveMatrixSettings создает или заменяет пороги матрицы пользователя
+func (r *MatrixSettingsRepository) SaveMatrixSettings(ctx context.Context, settings *models.MatrixSettings) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO matrix_settings (user_id, urgent_within_hours, important_priority)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (user_id) DO UPDATE
+		SET urgent_within_hours = EXCLUDED.urgent_within_hours,
+			important_priority = EXCLUDED.important_priority,
+			updated_at = now()`,
+		settings.UserID, settings.UrgentWithinHours, settings.ImportantPriority)
+	if err != nil {
+		return fmt.Errorf("failed to save matrix settings: %w", translateError(err))
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+
+	"github.com/lib/pq"
+)
+
+var migrationFileName = regexp.MustCompile(`^(\d{3})_.+\.sql$`)
+
+// Migration файл миграции из каталога migrations
+type Migration struct {
+	Version int
+	Name    string
+}
+
+// ApplyMigrations применяет миграции из dir новее записанной в schema_migrations версии
+// и записывает номер последней. Каждый файл выполняется в своей транзакции, так что
+// при ошибке версия указывает на последнюю полностью примененную миграцию
+func ApplyMigrations(ctx context.Context, db *sql.DB, dir string) ([]Migration, error) {
+	migrations, err := listMigrations(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := schemaVersion(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	var applied []Migration
+	for _, m := range migrations {
+		if m.Version <= current {
+			continue
+		}
+
+		script, err := os.ReadFile(filepath.Join(dir, m.Name))
+		if err != nil {
+			return applied, fmt.Errorf("failed to read migration %s: %w", m.Name, err)
+		}
+
+		if err := applyMigration(ctx, db, m, string(script)); err != nil {
+			return applied, err
+		}
+		applied = append(applied, m)
+	}
+
+	return applied, nil
+}
+
+func applyMigration(ctx context.Context, db *sql.DB, m Migration, script string) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin migration %s: %w", m.Name, err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, script); err != nil {
+		return fmt.Errorf("failed to apply migration %s: %w", m.Name, err)
+	}
+
+	// таблица schema_migrations создается миграцией 016, до нее версию записывать некуда
+	if m.Version >= 16 {
+		if _, err := tx.ExecContext(ctx,
+			`INSERT INTO schema_migrations (version) VALUES ($1) ON CONFLICT DO NOTHING`, m.Version); err != nil {
+			return fmt.Errorf("failed to record migration %s: %w", m.Name, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit migration %s: %w", m.Name, err)
+	}
+	return nil
+}
+
+// schemaVersion последняя записанная версия схемы, 0 — версия еще не записывалась
+func schemaVersion(ctx context.Context, db *sql.DB) (int, error) {
+	var version int
+	err := db.QueryRowContext(ctx, `SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&version)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "42P01" {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("failed to get schema version: %w", err)
+	}
+	return version, nil
+}
+
+// listMigrations файлы миграций каталога по возрастанию номера
+func listMigrations(dir string) ([]Migration, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var migrations []Migration
+	for _, entry := range entries {
+		match := migrationFileName.FindStringSubmatch(entry.Name())
+		if match == nil || entry.IsDir() {
+			continue
+		}
+		version, _ := strconv.Atoi(match[1])
+		migrations = append(migrations, Migration{Version: version, Name: entry.Name()})
+	}
+
+	if len(migrations) == 0 {
+		return nil, fmt.Errorf("no migrations in %s", dir)
+	}
+
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
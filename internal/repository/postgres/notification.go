@@ -0,0 +1,258 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/lib/pq"
+)
+
+type NotificationRepository struct {
+	db *sql.DB
+}
+
+func NewNotificationRepository(db *sql.DB) *NotificationRepository {
+	return &NotificationRepository{db: db}
+}
+
+// сохраняем подписку, повторная подписка того же endpoint обновляет ключи
+func (r *NotificationRepository) SavePushSubscription(ctx context.Context, sub *models.PushSubscription) error {
+	if sub.ID == "" {
+		sub.ID = uuid.New().String()
+	}
+
+	query := `
+		INSERT INTO push_subscriptions (id, user_id, endpoint, p256dh, auth)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (endpoint) DO UPDATE
+		SET user_id = EXCLUDED.user_id, p256dh = EXCLUDED.p256dh, auth = EXCLUDED.auth
+		RETURNING id, created_at
+	`
+	err := r.db.QueryRowContext(ctx, query,
+		sub.ID, sub.UserID, sub.Endpoint, sub.P256dh, sub.Auth).Scan(&sub.ID, &sub.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save push subscription: %w", translateError(err))
+	}
+
+	return nil
+}
+
+// удаляем подписку пользователя
+func (r *NotificationRepository) DeletePushSubscription(ctx context.Context, userID, endpoint string) error {
+	query := `DELETE FROM push_subscriptions WHERE user_id = $1 AND endpoint = $2`
+	if _, err := r.db.ExecContext(ctx, query, userID, endpoint); err != nil {
+		return fmt.Errorf("failed to delete push subscription: %w", err)
+	}
+
+	return nil
+}
+
+// подписки пользователя
+func (r *NotificationRepository) GetPushSubscriptions(ctx context.Context, userID string) ([]models.PushSubscription, error) {
+	query := `
+		SELECT id, user_id, endpoint, p256dh, auth, created_at
+		FROM push_subscriptions
+		WHERE user_id = $1
+	`
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query push subscriptions: %w", err)
+	}
+	defer rows.Close()
+
+	var subs []models.PushSubscription
+	for rows.Next() {
+		var sub models.PushSubscription
+		if err := rows.Scan(&sub.ID, &sub.UserID, &sub.Endpoint, &sub.P256dh, &sub.Auth, &sub.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan push subscription: %w", err)
+		}
+		subs = append(subs, sub)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating push subscriptions: %w", err)
+	}
+
+	return subs, nil
+}
+
+// настройки уведомлений пользователя, nil если не заданы
+func (r *NotificationRepository) GetNotificationPreferences(ctx context.Context, userID string) (*models.NotificationPreferences, error) {
+	query := `
+		SELECT user_id, channels, event_types, due_soon_window_minutes, digest_window_minutes,
+			quiet_hours_start, quiet_hours_end, timezone, slack_webhook_url
+		FROM notification_preferences
+		WHERE user_id = $1
+	`
+	var prefs models.NotificationPreferences
+	var channels []string
+	err := r.db.QueryRowContext(ctx, query, userID).Scan(
+		&prefs.UserID, pq.Array(&channels), pq.Array(&prefs.EventTypes),
+		&prefs.DueSoonWindowMinutes, &prefs.DigestWindowMinutes,
+		&prefs.QuietHoursStart, &prefs.QuietHoursEnd, &prefs.Timezone, &prefs.SlackWebhookURL)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get notification preferences: %w", err)
+	}
+
+	prefs.Channels = make([]models.NotificationChannel, 0, len(channels))
+	for _, c := range channels {
+		prefs.Channels = append(prefs.Channels, models.NotificationChannel(c))
+	}
+	if prefs.EventTypes == nil {
+		prefs.EventTypes = []string{}
+	}
+
+	return &prefs, nil
+}
+
+// сохраняем настройки уведомлений пользователя
+func (r *NotificationRepository) SaveNotificationPreferences(ctx context.Context, prefs *models.NotificationPreferences) error {
+	channels := make([]string, 0, len(prefs.Channels))
+	for _, c := range prefs.Channels {
+		channels = append(channels, string(c))
+	}
+	eventTypes := prefs.EventTypes
+	if eventTypes == nil {
+		eventTypes = []string{}
+	}
+
+	query := `
+		INSERT INTO notification_preferences (user_id, channels, event_types, due_soon_window_minutes,
+			digest_window_minutes, quiet_hours_start, quiet_hours_end, timezone, slack_webhook_url)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (user_id) DO UPDATE
+		SET channels = EXCLUDED.channels,
+			event_types = EXCLUDED.event_types,
+			due_soon_window_minutes = EXCLUDED.due_soon_window_minutes,
+			digest_window_minutes = EXCLUDED.digest_window_minutes,
+			quiet_hours_start = EXCLUDED.quiet_hours_start,
+			quiet_hours_end = EXCLUDED.quiet_hours_end,
+			timezone = EXCLUDED.timezone,
+			slack_webhook_url = EXCLUDED.slack_webhook_url,
+			updated_at = now()
+	`
+	if _, err := r.db.ExecContext(ctx, query,
+		prefs.UserID, pq.Array(channels), pq.Array(eventTypes), prefs.DueSoonWindowMinutes,
+		prefs.DigestWindowMinutes, prefs.QuietHoursStart, prefs.QuietHoursEnd, prefs.Timezone, prefs.SlackWebhookURL); err != nil {
+		return fmt.Errorf("failed to save notification preferences: %w", translateError(err))
+	}
+
+	return nil
+}
+
+// незавершенные задачи, срок которых наступает в окне пользователя и о которых еще не напоминали.
+// Каналы и типы событий пользователя применяет диспетчер уведомлений, здесь отсекаются только
+// пользователи, отключившие все каналы.
+// Напоминание учитывается по паре (задача, срок), поэтому перенос срока дает новое напоминание
+func (r *NotificationRepository) GetDueSoonTasks(ctx context.Context, defaultWindow time.Duration) ([]models.Task, error) {
+	query := `
+		SELECT t.id, t.title, t.status, t.priority, t.user_id, t.due_date, t.private
+		FROM tasks t
+		LEFT JOIN notification_preferences p ON p.user_id = t.user_id
+		WHERE t.status <> 'done'
+			AND COALESCE(cardinality(p.channels), 1) > 0
+			AND t.due_date > now()
+			AND t.due_date <= now() + make_interval(mins => COALESCE(p.due_soon_window_minutes, $1))
+			AND NOT EXISTS (
+				SELECT 1 FROM task_reminders r
+				WHERE r.task_id = t.id AND r.due_date = t.due_date
+			)
+		ORDER BY t.due_date ASC
+	`
+	rows, err := r.db.QueryContext(ctx, query, int(defaultWindow.Minutes()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query due soon tasks: %w", err)
+	}
+	defer rows.Close()
+
+	var tasks []models.Task
+	for rows.Next() {
+		var task models.Task
+		if err := rows.Scan(&task.ID, &task.Title, &task.Status, &task.Priority,
+			&task.UserID, &task.DueDate, &task.Private); err != nil {
+			return nil, fmt.Errorf("failed to scan due soon task: %w", err)
+		}
+		tasks = append(tasks, task)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating due soon tasks: %w", err)
+	}
+
+	return tasks, nil
+}
+
+// отмечаем, что напоминание по задаче с этим сроком отправлено
+func (r *NotificationRepository) MarkReminderSent(ctx context.Context, taskID string, dueDate time.Time) error {
+	query := `
+		INSERT INTO task_reminders (task_id, due_date)
+		VALUES ($1, $2)
+		ON CONFLICT DO NOTHING
+	`
+	if _, err := r.db.ExecContext(ctx, query, taskID, dueDate); err != nil {
+		return fmt.Errorf("failed to mark reminder sent: %w", err)
+	}
+
+	return nil
+}
+
+// незавершенные задачи, срок которых прошел за последние сутки и о которых еще не уведомляли.
+// Окно в сутки не дает разослать уведомления о давно просроченных задачах при первом запуске
+func (r *NotificationRepository) GetOverdueTasks(ctx context.Context) ([]models.Task, error) {
+	query := `
+		SELECT t.id, t.title, t.status, t.priority, t.user_id, t.due_date, t.private
+		FROM tasks t
+		LEFT JOIN notification_preferences p ON p.user_id = t.user_id
+		WHERE t.status <> 'done'
+			AND COALESCE(cardinality(p.channels), 1) > 0
+			AND t.due_date <= now()
+			AND t.due_date > now() - interval '1 day'
+			AND NOT EXISTS (
+				SELECT 1 FROM task_overdue_alerts a
+				WHERE a.task_id = t.id AND a.due_date = t.due_date
+			)
+		ORDER BY t.due_date ASC
+	`
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query overdue tasks: %w", err)
+	}
+	defer rows.Close()
+
+	var tasks []models.Task
+	for rows.Next() {
+		var task models.Task
+		if err := rows.Scan(&task.ID, &task.Title, &task.Status, &task.Priority,
+			&task.UserID, &task.DueDate, &task.Private); err != nil {
+			return nil, fmt.Errorf("failed to scan overdue task: %w", err)
+		}
+		tasks = append(tasks, task)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating overdue tasks: %w", err)
+	}
+
+	return tasks, nil
+}
+
+// отмечаем, что уведомление о просрочке задачи с этим сроком отправлено
+func (r *NotificationRepository) MarkOverdueAlertSent(ctx context.Context, taskID string, dueDate time.Time) error {
+	query := `
+		INSERT INTO task_overdue_alerts (task_id, due_date)
+		VALUES ($1, $2)
+		ON CONFLICT DO NOTHING
+	`
+	if _, err := r.db.ExecContext(ctx, query, taskID, dueDate); err != nil {
+		return fmt.Errorf("failed to mark overdue alert sent: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,39 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// Секционирование tasks по хэшу user_id необязательно и включается оператором (миграция 037).
+// Запросы TaskRepository работают с обеими схемами, но с секциями читают одну секцию, только если
+// содержат условие user_id = $n: выборки по владельцу, Update, CompleteTasks, FindTaskIDsByPrefix.
+// Поиск по одному ID (GetByID, Delete, SetAssignee) и списки назначенных задач (assignee_id = $1)
+// проверяют индекс каждой секции, поэтому число секций стоит держать небольшим (8-64).
+// Внешние ключи на tasks(id) после секционирования заменены триггерами с теми же именами,
+// так что translateError по-прежнему возвращает ErrInvalidReference для ссылки на удаленную задачу
+
+// Partitions число секций таблицы tasks, 0 — таблица не секционирована
+func (r *TaskRepository) Partitions(ctx context.Context) (int, error) {
+	var partitions int
+	err := r.db.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM pg_inherits
+		WHERE inhparent = 'tasks'::regclass
+			AND EXISTS (SELECT 1 FROM pg_partitioned_table WHERE partrelid = 'tasks'::regclass)
+	`).Scan(&partitions)
+	if err != nil {
+		return 0, fmt.Errorf("failed to detect tasks partitions: %w", err)
+	}
+
+	return partitions, nil
+}
+
+// PartitionTasks переносит tasks в секционированную по хэшу user_id таблицу из partitions секций.
+// Таблица заблокирована на все время переноса
+func PartitionTasks(ctx context.Context, db *sql.DB, partitions int) error {
+	if _, err := db.ExecContext(ctx, `SELECT partition_tasks($1)`, partitions); err != nil {
+		return fmt.Errorf("failed to partition tasks: %w", err)
+	}
+	return nil
+}
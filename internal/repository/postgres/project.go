@@ -0,0 +1,143 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/jmoloko/taskmange/internal/domain/repository"
+)
+
+type ProjectRepository struct {
+	db *sql.DB
+}
+
+func NewProjectRepository(db *sql.DB) *ProjectRepository {
+	return &ProjectRepository{db: db}
+}
+
+// projectColumns колонки проекта в порядке, ожидаемом scanProject
+const projectColumns = `id, user_id, name, description, created_at, updated_at`
+
+// scanProject читает проект из строки с колонками projectColumns
+func scanProject(row rowScanner) (models.Project, error) {
+	var project models.Project
+	var description sql.NullString
+
+	err := row.Scan(&project.ID, &project.UserID, &project.Name, &description, &project.CreatedAt, &project.UpdatedAt)
+	if err != nil {
+		return models.Project{}, err
+	}
+	project.Description = description.String
+
+	return project, nil
+}
+
+// getProject проект по ID, общий для репозиториев проектов и задач
+func getProject(ctx context.Context, db *sql.DB, id string) (*models.Project, error) {
+	project, err := scanProject(db.QueryRowContext(ctx, `SELECT `+projectColumns+` FROM projects WHERE id = $1`, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, repository.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get project: %w", err)
+	}
+
+	return &project, nil
+}
+
+// создаём проект, временные метки назначает БД
+func (r *ProjectRepository) CreateProject(ctx context.Context, project *models.Project) error {
+	query := `
+		INSERT INTO projects (id, user_id, name, description)
+		VALUES ($1, $2, $3, $4)
+		RETURNING created_at, updated_at
+	`
+	err := r.db.QueryRowContext(ctx, query,
+		project.ID, project.UserID, project.Name, nullString(project.Description)).Scan(&project.CreatedAt, &project.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create project: %w", translateError(err))
+	}
+
+	return nil
+}
+
+// получаем проект по ID
+func (r *ProjectRepository) GetProject(ctx context.Context, id string) (*models.Project, error) {
+	return getProject(ctx, r.db, id)
+}
+
+// все проекты пользователя по названию
+func (r *ProjectRepository) GetProjects(ctx context.Context, userID string) ([]models.Project, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+projectColumns+`
+		FROM projects
+		WHERE user_id = $1
+		ORDER BY name, id
+	`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query projects: %w", err)
+	}
+	defer rows.Close()
+
+	var projects []models.Project
+	for rows.Next() {
+		project, err := scanProject(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan project: %w", err)
+		}
+		projects = append(projects, project)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating projects: %w", err)
+	}
+
+	return projects, nil
+}
+
+// обновляем название и описание проекта пользователя, updated_at выставляет триггер БД
+func (r *ProjectRepository) UpdateProject(ctx context.Context, project *models.Project) error {
+	query := `
+		UPDATE projects
+		SET name = $1, description = $2
+		WHERE id = $3 AND user_id = $4
+		RETURNING created_at, updated_at
+	`
+	err := r.db.QueryRowContext(ctx, query,
+		project.Name, nullString(project.Description), project.ID, project.UserID).Scan(&project.CreatedAt, &project.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return repository.ErrNotFound
+		}
+		return fmt.Errorf("failed to update project: %w", translateError(err))
+	}
+
+	return nil
+}
+
+// удаляем проект пользователя, его задачи остаются без проекта
+func (r *ProjectRepository) DeleteProject(ctx context.Context, userID, id string) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM projects WHERE id = $1 AND user_id = $2`, id, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete project: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return repository.ErrNotFound
+	}
+
+	return nil
+}
+
+// проект по ID для проверки проекта задачи
+func (r *TaskRepository) GetProject(ctx context.Context, id string) (*models.Project, error) {
+	return getProject(ctx, r.db, id)
+}
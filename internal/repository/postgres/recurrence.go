@@ -0,0 +1,155 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/jmoloko/taskmange/internal/domain/repository"
+)
+
+// серия повторяющейся задачи
+func (r *TaskRepository) GetRecurrence(ctx context.Context, id string) (*models.Recurrence, error) {
+	query := `
+		SELECT id, user_id, rule, paused, occurrences, last_task_id, created_at, updated_at
+		FROM task_recurrences
+		WHERE id = $1
+	`
+	var recurrence models.Recurrence
+	var lastTaskID sql.NullString
+	err := r.db.QueryRowContext(ctx, query, id).Scan(&recurrence.ID, &recurrence.UserID, &recurrence.Rule,
+		&recurrence.Paused, &recurrence.Occurrences, &lastTaskID, &recurrence.CreatedAt, &recurrence.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, repository.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get recurrence: %w", translateError(err))
+	}
+	recurrence.LastTaskID = lastTaskID.String
+
+	return &recurrence, nil
+}
+
+// приостанавливаем или возобновляем серию
+func (r *TaskRepository) SetRecurrencePaused(ctx context.Context, id string, paused bool) error {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE task_recurrences SET paused = $2, updated_at = now() WHERE id = $1
+	`, id, paused)
+	if err != nil {
+		return fmt.Errorf("failed to update recurrence: %w", translateError(err))
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if affected == 0 {
+		return repository.ErrNotFound
+	}
+
+	return nil
+}
+
+// последние экземпляры активных серий, которые выполнены или просрочены.
+// Подзапрос нужен, чтобы колонки серии не пересекались с колонками задачи
+func (r *TaskRepository) GetDueOccurrences(ctx context.Context, now time.Time, limit int) ([]models.DueOccurrence, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT rule, occurrences, `+taskColumns+`
+		FROM (
+			SELECT t.*, r.rule, r.occurrences
+			FROM task_recurrences r
+			JOIN tasks t ON t.id = r.last_task_id
+			WHERE r.last_task_id IS NOT NULL AND NOT r.paused
+				AND (t.status = 'done' OR t.due_date < $1)
+		) due
+		ORDER BY due_date ASC
+		LIMIT $2
+	`, now, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query due occurrences: %w", err)
+	}
+	defer rows.Close()
+
+	var occurrences []models.DueOccurrence
+	for rows.Next() {
+		var occurrence models.DueOccurrence
+		task, err := scanTask(prefixScanner{row: rows, dest: []interface{}{&occurrence.Rule, &occurrence.Occurrences}})
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan due occurrence: %w", err)
+		}
+		occurrence.Task = task
+		occurrences = append(occurrences, occurrence)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating due occurrences: %w", err)
+	}
+
+	return occurrences, nil
+}
+
+// создаём следующий экземпляр серии копией sourceID. Обновление серии с условием
+// last_task_id = sourceID не дает двум экземплярам приложения создать одно и то же повторение.
+// Родитель копируется, только если он еще не выполнен
+func (r *TaskRepository) CreateOccurrence(ctx context.Context, sourceID string, next *models.Task) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `
+		UPDATE task_recurrences
+		SET last_task_id = $1, occurrences = occurrences + 1, updated_at = now()
+		WHERE id = (SELECT recurrence_id FROM tasks WHERE id = $2) AND last_task_id = $2 AND NOT paused
+	`, next.ID, sourceID)
+	if err != nil {
+		return fmt.Errorf("failed to advance recurrence: %w", translateError(err))
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if affected == 0 {
+		return repository.ErrNotFound
+	}
+
+	task, err := scanTask(tx.QueryRowContext(ctx, `
+		INSERT INTO tasks (id, title, description, notes, links, tags, status, priority, user_id, parent_id, due_date, private,
+			recurrence, recurrence_id, project_id, assignee_id)
+		SELECT $1, t.title, t.description, t.notes, t.links, t.tags, 'pending', t.priority, t.user_id,
+			CASE WHEN parent.status <> 'done' THEN t.parent_id END, $3, t.private, t.recurrence, t.recurrence_id,
+			t.project_id, t.assignee_id
+		FROM tasks t
+		LEFT JOIN tasks parent ON parent.id = t.parent_id
+		WHERE t.id = $2
+		RETURNING `+taskColumns, next.ID, sourceID, next.DueDate))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return repository.ErrNotFound
+		}
+		return fmt.Errorf("failed to create occurrence: %w", translateError(err))
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit occurrence: %w", translateError(err))
+	}
+
+	*next = task
+	return nil
+}
+
+// завершаем серию, экземпляры остаются обычными задачами
+func (r *TaskRepository) EndRecurrence(ctx context.Context, id string) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE task_recurrences SET last_task_id = NULL, updated_at = now() WHERE id = $1
+	`, id)
+	if err != nil {
+		return fmt.Errorf("failed to end recurrence: %w", translateError(err))
+	}
+
+	return nil
+}
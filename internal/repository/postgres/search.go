@@ -0,0 +1,189 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/jmoloko/taskmange/internal/domain/repository"
+)
+
+type SavedSearchRepository struct {
+	db *sql.DB
+}
+
+func NewSavedSearchRepository(db *sql.DB) *SavedSearchRepository {
+	return &SavedSearchRepository{db: db}
+}
+
+const savedSearchColumns = `id, user_id, name, query, timezone, alert, alert_interval_minutes, last_alert_at, created_at`
+
+// создаём поиск, created_at назначает БД
+func (r *SavedSearchRepository) CreateSavedSearch(ctx context.Context, search *models.SavedSearch) error {
+	query := `
+		INSERT INTO saved_searches (id, user_id, name, query, timezone, alert, alert_interval_minutes)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING created_at
+	`
+	err := r.db.QueryRowContext(ctx, query,
+		search.ID, search.UserID, search.Name, search.Query, search.Timezone, search.Alert,
+		search.AlertIntervalMinutes).Scan(&search.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create saved search: %w", translateError(err))
+	}
+
+	return nil
+}
+
+// меняем поиск пользователя; при смене запроса прежние совпадения и накопленный счетчик сбрасываются
+func (r *SavedSearchRepository) UpdateSavedSearch(ctx context.Context, search *models.SavedSearch) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin saved search update: %w", err)
+	}
+	defer tx.Rollback()
+
+	var previousQuery string
+	err = tx.QueryRowContext(ctx,
+		`SELECT query FROM saved_searches WHERE id = $1 AND user_id = $2 FOR UPDATE`, search.ID, search.UserID,
+	).Scan(&previousQuery)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return repository.ErrNotFound
+		}
+		return fmt.Errorf("failed to get saved search: %w", err)
+	}
+
+	queryChanged := previousQuery != search.Query
+	err = tx.QueryRowContext(ctx, `
+		UPDATE saved_searches
+		SET name = $2, query = $3, timezone = $4, alert = $5, alert_interval_minutes = $6,
+			suppressed_matches = CASE WHEN $7 THEN 0 ELSE suppressed_matches END
+		WHERE id = $1
+		RETURNING last_alert_at, created_at
+	`, search.ID, search.Name, search.Query, search.Timezone, search.Alert, search.AlertIntervalMinutes, queryChanged,
+	).Scan(&search.LastAlertAt, &search.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to update saved search: %w", translateError(err))
+	}
+
+	if queryChanged {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM saved_search_matches WHERE search_id = $1`, search.ID); err != nil {
+			return fmt.Errorf("failed to reset saved search matches: %w", err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// удаляем поиск пользователя вместе с совпадениями
+func (r *SavedSearchRepository) DeleteSavedSearch(ctx context.Context, userID, searchID string) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM saved_searches WHERE id = $1 AND user_id = $2`, searchID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete saved search: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return repository.ErrNotFound
+	}
+
+	return nil
+}
+
+// все поиски пользователя
+func (r *SavedSearchRepository) GetSavedSearches(ctx context.Context, userID string) ([]models.SavedSearch, error) {
+	return r.querySearches(ctx, `
+		SELECT `+savedSearchColumns+`
+		FROM saved_searches
+		WHERE user_id = $1
+		ORDER BY created_at ASC, id
+	`, userID)
+}
+
+// поиски пользователя с включенным оповещением
+func (r *SavedSearchRepository) GetAlertSearches(ctx context.Context, userID string) ([]models.SavedSearch, error) {
+	return r.querySearches(ctx, `
+		SELECT `+savedSearchColumns+`
+		FROM saved_searches
+		WHERE user_id = $1 AND alert
+	`, userID)
+}
+
+func (r *SavedSearchRepository) querySearches(ctx context.Context, query string, args ...interface{}) ([]models.SavedSearch, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query saved searches: %w", err)
+	}
+	defer rows.Close()
+
+	var searches []models.SavedSearch
+	for rows.Next() {
+		var search models.SavedSearch
+		if err := rows.Scan(&search.ID, &search.UserID, &search.Name, &search.Query, &search.Timezone, &search.Alert,
+			&search.AlertIntervalMinutes, &search.LastAlertAt, &search.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan saved search: %w", err)
+		}
+		searches = append(searches, search)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating saved searches: %w", err)
+	}
+
+	return searches, nil
+}
+
+// запоминаем совпадение; повторная вставка ничего не меняет, и тогда совпадение не новое
+func (r *SavedSearchRepository) RecordSearchMatch(ctx context.Context, searchID, taskID string) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `
+		INSERT INTO saved_search_matches (search_id, task_id)
+		VALUES ($1, $2)
+		ON CONFLICT DO NOTHING
+	`, searchID, taskID)
+	if err != nil {
+		return false, fmt.Errorf("failed to record saved search match: %w", translateError(err))
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rowsAffected > 0, nil
+}
+
+// занимаем отправку оповещения одним запросом, чтобы два экземпляра не отправили его одновременно.
+// old — строка до обновления: из нее берется число накопленных совпадений
+func (r *SavedSearchRepository) ClaimSearchAlert(ctx context.Context, searchID string, interval time.Duration, now time.Time) (bool, int, error) {
+	var suppressed int
+	err := r.db.QueryRowContext(ctx, `
+		UPDATE saved_searches s
+		SET last_alert_at = $2, suppressed_matches = 0
+		FROM saved_searches old
+		WHERE s.id = $1 AND old.id = s.id
+			AND (s.last_alert_at IS NULL OR s.last_alert_at <= $2 - make_interval(secs => $3))
+		RETURNING old.suppressed_matches
+	`, searchID, now, interval.Seconds()).Scan(&suppressed)
+	if err == nil {
+		return true, suppressed, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return false, 0, fmt.Errorf("failed to claim saved search alert: %w", err)
+	}
+
+	if _, err := r.db.ExecContext(ctx,
+		`UPDATE saved_searches SET suppressed_matches = suppressed_matches + 1 WHERE id = $1`, searchID,
+	); err != nil {
+		return false, 0, fmt.Errorf("failed to count suppressed saved search match: %w", err)
+	}
+
+	return false, 0, nil
+}
@@ -0,0 +1,107 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/lib/pq"
+)
+
+// maxHierarchyDepth ограничение глубины рекурсивных запросов по иерархии задач
+const maxHierarchyDepth = 100
+
+// прямые подзадачи задачи
+func (r *TaskRepository) GetSubtasks(ctx context.Context, parentID string) ([]models.Task, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+taskColumns+`
+		FROM `+allTasks+`
+		WHERE parent_id = $1
+		ORDER BY status = 'done', due_date ASC, created_at ASC, id
+	`, parentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query subtasks: %w", err)
+	}
+	defer rows.Close()
+
+	var tasks []models.Task
+	for rows.Next() {
+		task, err := scanTask(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan subtask: %w", err)
+		}
+		tasks = append(tasks, task)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating subtasks: %w", err)
+	}
+
+	return tasks, nil
+}
+
+// цепочка предков задачи от родителя к корню. UNION вместо UNION ALL останавливает
+// рекурсию, даже если в данных оказался цикл
+func (r *TaskRepository) GetAncestorIDs(ctx context.Context, taskID string) ([]string, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		WITH RECURSIVE ancestors (id, parent_id, depth) AS (
+			SELECT t.id, t.parent_id, 1
+			FROM tasks t
+			WHERE t.id = (SELECT parent_id FROM tasks WHERE id = $1)
+			UNION
+			SELECT t.id, t.parent_id, a.depth + 1
+			FROM tasks t
+			JOIN ancestors a ON t.id = a.parent_id
+			WHERE a.depth < $2
+		)
+		SELECT id FROM ancestors ORDER BY depth
+	`, taskID, maxHierarchyDepth)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query task ancestors: %w", err)
+	}
+
+	return scanIDs(rows)
+}
+
+// открытые подзадачи всех уровней для каждой из задач
+func (r *TaskRepository) GetOpenDescendantIDs(ctx context.Context, taskIDs []string) ([]string, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		WITH RECURSIVE descendants (id, status, depth) AS (
+			SELECT t.id, t.status, 1
+			FROM tasks t
+			WHERE t.parent_id::text = ANY($1)
+			UNION
+			SELECT t.id, t.status, d.depth + 1
+			FROM tasks t
+			JOIN descendants d ON t.parent_id = d.id
+			WHERE d.depth < $2
+		)
+		SELECT DISTINCT id FROM descendants WHERE status <> 'done'
+	`, pq.Array(taskIDs), maxHierarchyDepth)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query open subtasks: %w", err)
+	}
+
+	return scanIDs(rows)
+}
+
+// scanIDs читает один столбец id и закрывает rows
+func scanIDs(rows *sql.Rows) ([]string, error) {
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan task id: %w", err)
+		}
+		ids = append(ids, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating task ids: %w", err)
+	}
+
+	return ids, nil
+}
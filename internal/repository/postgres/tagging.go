@@ -0,0 +1,85 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/jmoloko/taskmange/internal/domain/repository"
+	"github.com/lib/pq"
+)
+
+type TaggingRuleRepository struct {
+	db *sql.DB
+}
+
+func NewTaggingRuleRepository(db *sql.DB) *TaggingRuleRepository {
+	return &TaggingRuleRepository{db: db}
+}
+
+// создаём правило, created_at назначает БД
+func (r *TaggingRuleRepository) CreateTaggingRule(ctx context.Context, rule *models.TaggingRule) error {
+	query := `
+		INSERT INTO tagging_rules (id, user_id, name, match_type, field, pattern, tags, enabled)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING created_at
+	`
+	err := r.db.QueryRowContext(ctx, query,
+		rule.ID, rule.UserID, rule.Name, rule.Match, rule.Field, rule.Pattern,
+		pq.Array(rule.Tags), rule.Enabled).Scan(&rule.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create tagging rule: %w", translateError(err))
+	}
+
+	return nil
+}
+
+// удаляем правило пользователя
+func (r *TaggingRuleRepository) DeleteTaggingRule(ctx context.Context, userID, ruleID string) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM tagging_rules WHERE id = $1 AND user_id = $2`, ruleID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete tagging rule: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return repository.ErrNotFound
+	}
+
+	return nil
+}
+
+// все правила пользователя
+func (r *TaggingRuleRepository) GetTaggingRules(ctx context.Context, userID string) ([]models.TaggingRule, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, user_id, name, match_type, field, pattern, tags, enabled, created_at
+		FROM tagging_rules
+		WHERE user_id = $1
+		ORDER BY created_at ASC, id
+	`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tagging rules: %w", err)
+	}
+	defer rows.Close()
+
+	var rules []models.TaggingRule
+	for rows.Next() {
+		var rule models.TaggingRule
+		if err := rows.Scan(&rule.ID, &rule.UserID, &rule.Name, &rule.Match, &rule.Field, &rule.Pattern,
+			pq.Array(&rule.Tags), &rule.Enabled, &rule.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan tagging rule: %w", err)
+		}
+		rules = append(rules, rule)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating tagging rules: %w", err)
+	}
+
+	return rules, nil
+}
@@ -3,12 +3,15 @@ package postgres
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"log/slog"
 	"strconv"
+	"time"
 
 	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/jmoloko/taskmange/internal/domain/repository"
 	"github.com/lib/pq"
 )
 
@@ -20,11 +23,23 @@ func NewTaskRepository(db *sql.DB) *TaskRepository {
 	return &TaskRepository{db: db}
 }
 
-// создаём новую задачу
+// Supports Postgres поддерживает все возможности хранилища задач
+func (r *TaskRepository) Supports(capability repository.Capability) bool {
+	return true
+}
+
+// создаём новую задачу, временные метки назначает БД и возвращает через RETURNING.
+// Задача с RecurrenceID создается вместе с серией, первым экземпляром которой она становится
 func (r *TaskRepository) Create(ctx context.Context, task *models.Task) error {
 	query := `
-		INSERT INTO tasks (id, title, description, status, priority, user_id, due_date, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		WITH series AS (
+			INSERT INTO task_recurrences (id, user_id, rule, last_task_id)
+			SELECT $14, $9, $13, $1 WHERE $13::text IS NOT NULL
+		)
+		INSERT INTO tasks (id, title, description, notes, links, tags, status, priority, user_id, parent_id, due_date, private,
+			recurrence, recurrence_id, project_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+		RETURNING created_at, updated_at, completed_at
 	`
 	slog.Info("Creating task in database",
 		"task_id", task.ID,
@@ -34,9 +49,16 @@ func (r *TaskRepository) Create(ctx context.Context, task *models.Task) error {
 		"priority", task.Priority,
 		"due_date", task.DueDate)
 
-	result, err := r.db.ExecContext(ctx, query,
-		task.ID, task.Title, task.Description, task.Status, task.Priority,
-		task.UserID, task.DueDate, task.CreatedAt, task.UpdatedAt)
+	links, err := marshalLinks(task.Links)
+	if err != nil {
+		return err
+	}
+
+	var completedAt sql.NullTime
+	err = r.db.QueryRowContext(ctx, query,
+		task.ID, task.Title, nullString(task.Description), nullStringPtr(task.Notes), links, pq.Array(tagList(task.Tags)),
+		task.Status, task.Priority, task.UserID, nullStringPtr(task.ParentID), task.DueDate, task.Private,
+		nullString(task.Recurrence), nullStringPtr(task.RecurrenceID), nullStringPtr(task.ProjectID)).Scan(&task.CreatedAt, &task.UpdatedAt, &completedAt)
 	if err != nil {
 		slog.Error("Failed to create task in database",
 			"error", err,
@@ -51,60 +73,176 @@ func (r *TaskRepository) Create(ctx context.Context, task *models.Task) error {
 				"detail", pqErr.Detail,
 				"message", pqErr.Message)
 		}
-		return fmt.Errorf("failed to create task: %w", err)
+		return fmt.Errorf("failed to create task: %w", translateError(err))
 	}
 
-	rows, err := result.RowsAffected()
+	task.CompletedAt = nil
+	if completedAt.Valid {
+		task.CompletedAt = &completedAt.Time
+	}
+
+	return nil
+}
+
+// taskBatchSize строк в одном INSERT пакетной вставки: 13 параметров на строку при пределе 65535
+const taskBatchSize = 1000
+
+// CreateBatch создает задачи многострочными INSERT в одной транзакции: либо все, либо ни одной.
+// Временные метки назначает БД, в tasks они не возвращаются
+func (r *TaskRepository) CreateBatch(ctx context.Context, tasks []models.Task) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
-		slog.Error("Failed to get affected rows",
-			"error", err,
-			"task_id", task.ID,
-			"user_id", task.UserID)
-		return fmt.Errorf("failed to get affected rows: %w", err)
+		return fmt.Errorf("failed to begin batch insert: %w", err)
 	}
+	defer tx.Rollback()
+
+	for start := 0; start < len(tasks); start += taskBatchSize {
+		chunk := tasks[start:min(start+taskBatchSize, len(tasks))]
+
+		query := make([]byte, 0, 64+len(chunk)*48)
+		query = append(query, "INSERT INTO tasks (id, title, description, notes, links, tags, status, priority, user_id, parent_id, due_date, private, project_id) VALUES "...)
+		args := make([]interface{}, 0, len(chunk)*13)
+		for i, task := range chunk {
+			links, err := marshalLinks(task.Links)
+			if err != nil {
+				return err
+			}
+
+			if i > 0 {
+				query = append(query, ',')
+			}
+			n := len(args)
+			query = fmt.Appendf(query, "($%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d)",
+				n+1, n+2, n+3, n+4, n+5, n+6, n+7, n+8, n+9, n+10, n+11, n+12, n+13)
+			args = append(args, task.ID, task.Title, nullString(task.Description), nullStringPtr(task.Notes), links,
+				pq.Array(tagList(task.Tags)), task.Status, task.Priority, task.UserID, nullStringPtr(task.ParentID),
+				task.DueDate, task.Private, nullStringPtr(task.ProjectID))
+		}
 
-	if rows == 0 {
-		slog.Error("No rows affected",
-			"task_id", task.ID,
-			"user_id", task.UserID)
-		return fmt.Errorf("no rows affected")
+		if _, err := tx.ExecContext(ctx, string(query), args...); err != nil {
+			return fmt.Errorf("failed to create tasks: %w", translateError(err))
+		}
 	}
 
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit batch insert: %w", err)
+	}
 	return nil
 }
 
-// обновляем существующую задачу
+// обновляем существующую задачу, updated_at и completed_at выставляют триггеры БД.
+// Архивная задача сначала возвращается в tasks
 func (r *TaskRepository) Update(ctx context.Context, task *models.Task) error {
+	if err := r.restoreArchived(ctx, task.ID); err != nil {
+		return err
+	}
+
 	query := `
 		UPDATE tasks
-		SET title = $1, description = $2, status = $3, priority = $4, due_date = $5, updated_at = $6
-		WHERE id = $7 AND user_id = $8
+		SET title = $1, description = $2, notes = $3, links = $4, tags = $5, status = $6, priority = $7, due_date = $8,
+			private = $9, parent_id = $10, project_id = $11
+		WHERE id = $12 AND user_id = $13
+		RETURNING updated_at, completed_at
 	`
-	result, err := r.db.ExecContext(ctx, query,
-		task.Title, task.Description, task.Status, task.Priority,
-		task.DueDate, task.UpdatedAt, task.ID, task.UserID)
+	links, err := marshalLinks(task.Links)
 	if err != nil {
-		return fmt.Errorf("failed to update task: %w", err)
+		return err
 	}
 
-	rowsAffected, err := result.RowsAffected()
+	var completedAt sql.NullTime
+	err = r.db.QueryRowContext(ctx, query,
+		task.Title, nullString(task.Description), nullStringPtr(task.Notes), links, pq.Array(tagList(task.Tags)),
+		task.Status, task.Priority, task.DueDate, task.Private, nullStringPtr(task.ParentID), nullStringPtr(task.ProjectID), task.ID,
+		task.UserID).Scan(&task.UpdatedAt, &completedAt)
 	if err != nil {
-		return fmt.Errorf("failed to get rows affected: %w", err)
+		if err == sql.ErrNoRows {
+			return errors.New("task not found or not owned by user")
+		}
+		return fmt.Errorf("failed to update task: %w", translateError(err))
 	}
 
-	if rowsAffected == 0 {
-		return errors.New("task not found or not owned by user")
+	task.CompletedAt = nil
+	if completedAt.Valid {
+		task.CompletedAt = &completedAt.Time
 	}
 
 	return nil
 }
 
-// удаляет задачу по ID
-func (r *TaskRepository) Delete(ctx context.Context, id string) error {
-	query := `DELETE FROM tasks WHERE id = $1`
-	result, err := r.db.ExecContext(ctx, query, id)
+// переводит задачи пользователя в статус done в одной транзакции.
+// Триггер tasks_notify_change срабатывает на каждую строку, но одинаковые уведомления
+// транзакции Postgres доставляет один раз, поэтому кэш пользователя сбрасывается однократно
+func (r *TaskRepository) CompleteTasks(ctx context.Context, userID string, ids []string) ([]models.Task, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// блокируем задачи и проверяем, что все они принадлежат пользователю
+	var found int
+	err = tx.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM (
+			SELECT id FROM tasks WHERE user_id = $1 AND id = ANY($2) FOR UPDATE
+		) owned
+	`, userID, pq.Array(ids)).Scan(&found)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock tasks: %w", err)
+	}
+	// архивные задачи уже выполнены и пропускаются, но принадлежат пользователю
+	var archived int
+	err = tx.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM tasks_archive WHERE user_id = $1 AND id = ANY($2)`, userID, pq.Array(ids),
+	).Scan(&archived)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count archived tasks: %w", err)
+	}
+	if found+archived != len(ids) {
+		return nil, repository.ErrNotFound
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+		UPDATE tasks
+		SET status = 'done'
+		WHERE user_id = $1 AND id = ANY($2) AND status <> 'done'
+		RETURNING `+taskColumns, userID, pq.Array(ids))
+	if err != nil {
+		return nil, fmt.Errorf("failed to complete tasks: %w", err)
+	}
+	defer rows.Close()
+
+	var tasks []models.Task
+	for rows.Next() {
+		task, err := scanTask(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan task: %w", err)
+		}
+		tasks = append(tasks, task)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating tasks: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit completed tasks: %w", err)
+	}
+
+	return tasks, nil
+}
+
+// назначает задачу исполнителю, updated_at выставляет триггер БД
+func (r *TaskRepository) SetAssignee(ctx context.Context, taskID string, assigneeID *string) error {
+	if err := r.restoreArchived(ctx, taskID); err != nil {
+		return err
+	}
+
+	result, err := r.db.ExecContext(ctx, `UPDATE tasks SET assignee_id = $1 WHERE id = $2`, nullStringPtr(assigneeID), taskID)
 	if err != nil {
-		return fmt.Errorf("failed to delete task: %w", err)
+		return fmt.Errorf("failed to set task assignee: %w", translateError(err))
 	}
 
 	rowsAffected, err := result.RowsAffected()
@@ -113,26 +251,135 @@ func (r *TaskRepository) Delete(ctx context.Context, id string) error {
 	}
 
 	if rowsAffected == 0 {
-		return errors.New("task not found")
+		return repository.ErrNotFound
 	}
 
 	return nil
 }
 
-// получаем задачу по ID
+// удаляет задачу по ID из оперативной таблицы или из архива
+func (r *TaskRepository) Delete(ctx context.Context, id string) error {
+	for _, table := range []string{"tasks", "tasks_archive"} {
+		result, err := r.db.ExecContext(ctx, `DELETE FROM `+table+` WHERE id = $1`, id)
+		if err != nil {
+			return fmt.Errorf("failed to delete task: %w", err)
+		}
+
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to get rows affected: %w", err)
+		}
+
+		if rowsAffected > 0 {
+			return nil
+		}
+	}
+
+	return errors.New("task not found")
+}
+
+// DeleteUserTasks удаляет все задачи пользователя вместе с архивными, связи и внешние ссылки удаляются каскадно
+func (r *TaskRepository) DeleteUserTasks(ctx context.Context, userID string) ([]string, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		WITH archived AS (DELETE FROM tasks_archive WHERE user_id = $1 RETURNING id)
+		DELETE FROM tasks WHERE user_id = $1 RETURNING id
+		UNION ALL
+		SELECT id FROM archived
+	`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete user tasks: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan deleted task id: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating deleted tasks: %w", err)
+	}
+
+	return ids, nil
+}
+
+// DeleteTasks удаляет до limit задач пользователя по фильтру: сначала из tasks, затем из архива.
+// Каждая пачка — отдельный запрос, чтобы массовое удаление не держало блокировки долго
+func (r *TaskRepository) DeleteTasks(ctx context.Context, filter models.TaskDeleteFilter, limit int) ([]models.Task, error) {
+	where, args := buildDeleteFilter(filter)
+	limitArg := `$` + strconv.Itoa(len(args)+1)
+
+	var tasks []models.Task
+	for _, table := range []string{"tasks", "tasks_archive"} {
+		if len(tasks) >= limit {
+			break
+		}
+
+		rows, err := r.db.QueryContext(ctx, `
+			DELETE FROM `+table+`
+			WHERE user_id = $1 AND id IN (SELECT id FROM `+table+` `+where+` LIMIT `+limitArg+`)
+			RETURNING `+taskColumns, append(args, limit-len(tasks))...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to delete tasks: %w", err)
+		}
+		for rows.Next() {
+			task, err := scanTask(rows)
+			if err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("failed to scan deleted task: %w", err)
+			}
+			tasks = append(tasks, task)
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return nil, fmt.Errorf("error iterating deleted tasks: %w", err)
+		}
+	}
+
+	return tasks, nil
+}
+
+// buildDeleteFilter WHERE-условие массового удаления, user_id всегда первый аргумент
+func buildDeleteFilter(filter models.TaskDeleteFilter) (string, []interface{}) {
+	query := `WHERE user_id = $1`
+	args := []interface{}{filter.UserID}
+
+	add := func(condition string, value interface{}) {
+		args = append(args, value)
+		query += ` AND ` + condition + ` $` + strconv.Itoa(len(args))
+	}
+	if filter.Status != "" {
+		add(`status =`, filter.Status)
+	}
+	if filter.Priority != "" {
+		add(`priority =`, filter.Priority)
+	}
+	if filter.ProjectID != "" {
+		add(`project_id::text =`, filter.ProjectID)
+	}
+	if filter.CompletedBefore != nil {
+		add(`completed_at <`, filter.CompletedBefore.UTC())
+	}
+	if filter.CreatedBefore != nil {
+		add(`created_at <`, filter.CreatedBefore.UTC())
+	}
+
+	return query, args
+}
+
+// получаем задачу по ID, в том числе из архива
 func (r *TaskRepository) GetByID(ctx context.Context, id string) (*models.Task, error) {
 	query := `
-		SELECT id, title, description, status, priority, user_id, due_date, created_at, updated_at, completed_at
-		FROM tasks
-		WHERE id = $1
+		SELECT ` + taskColumns + ` FROM tasks WHERE id = $1
+		UNION ALL
+		SELECT ` + taskColumns + ` FROM tasks_archive WHERE id = $1
+		LIMIT 1
 	`
-	var task models.Task
-	var completedAt sql.NullTime
-
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&task.ID, &task.Title, &task.Description, &task.Status, &task.Priority,
-		&task.UserID, &task.DueDate, &task.CreatedAt, &task.UpdatedAt, &completedAt)
-
+	task, err := scanTask(r.db.QueryRowContext(ctx, query, id))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, errors.New("task not found")
@@ -140,20 +387,345 @@ func (r *TaskRepository) GetByID(ctx context.Context, id string) (*models.Task,
 		return nil, fmt.Errorf("failed to get task: %w", err)
 	}
 
-	if completedAt.Valid {
-		task.CompletedAt = &completedAt.Time
-	}
-
 	return &task, nil
 }
 
 // список задач с применением фильтров
 func (r *TaskRepository) GetAll(ctx context.Context, filters models.TaskFilters) ([]models.Task, error) {
+	where, args := buildTaskFilters(filters)
 	query := `
-		SELECT id, title, description, status, priority, user_id, due_date, created_at, updated_at, completed_at
-		FROM tasks
-		WHERE user_id = $1
-	`
+		SELECT ` + taskColumns + `
+		FROM ` + taskSource(filters) + `
+	` + where
+
+	// курсор сравнивается со строкой целиком, поэтому страница читается по индексу (user_id, due_date, id)
+	// без просмотра предыдущих страниц
+	if filters.After != nil {
+		query += ` AND (due_date, id) > ($` + strconv.Itoa(len(args)+1) + `, $` + strconv.Itoa(len(args)+2) + `)`
+		args = append(args, filters.After.DueDate.UTC(), filters.After.ID)
+	}
+
+	// id в конце порядка делает его однозначным, иначе страницы могут пересекаться
+	switch filters.Sort {
+	case models.SortSmart:
+		query += ` ORDER BY status = 'done', ` + smartScore + ` DESC, due_date ASC, created_at ASC, id`
+	case models.SortDue:
+		query += ` ORDER BY due_date ASC, id ASC`
+	default:
+		query += ` ORDER BY due_date ASC, priority DESC, created_at DESC, id`
+	}
+
+	if filters.Limit > 0 {
+		query += ` LIMIT $` + strconv.Itoa(len(args)+1)
+		args = append(args, filters.Limit)
+	}
+	if filters.Offset > 0 {
+		query += ` OFFSET $` + strconv.Itoa(len(args)+1)
+		args = append(args, filters.Offset)
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tasks: %w", err)
+	}
+	defer rows.Close()
+
+	var tasks []models.Task
+	for rows.Next() {
+		task, err := scanTask(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan task: %w", err)
+		}
+
+		tasks = append(tasks, task)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating tasks: %w", err)
+	}
+
+	return tasks, nil
+}
+
+// количество задач, подходящих под фильтры, без загрузки строк
+func (r *TaskRepository) Count(ctx context.Context, filters models.TaskFilters) (int, error) {
+	where, args := buildTaskFilters(filters)
+	query := `SELECT COUNT(*) FROM ` + taskSource(filters) + ` ` + where
+
+	var count int
+	if err := r.db.QueryRowContext(ctx, query, args...).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count tasks: %w", err)
+	}
+
+	return count, nil
+}
+
+// GetTaskStats агрегаты задач пользователя (или его проекта) за окно [from, to), включая архивные задачи
+func (r *TaskRepository) GetTaskStats(ctx context.Context, userID, projectID string, from, to time.Time) (models.TaskStats, error) {
+	stats := models.TaskStats{
+		StatusCount:   make(map[models.Status]int),
+		PriorityCount: make(map[models.Priority]int),
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT status, priority, COUNT(*) FROM `+allTasks+`
+		WHERE user_id = $1 AND created_at >= $2 AND created_at < $3 AND ($4 = '' OR project_id::text = $4)
+		GROUP BY status, priority`, userID, from, to, projectID)
+	if err != nil {
+		return models.TaskStats{}, fmt.Errorf("failed to count tasks by status and priority: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var status models.Status
+		var priority models.Priority
+		var count int
+		if err := rows.Scan(&status, &priority, &count); err != nil {
+			return models.TaskStats{}, fmt.Errorf("failed to scan task counts: %w", err)
+		}
+		stats.StatusCount[status] += count
+		stats.PriorityCount[priority] += count
+	}
+	if err := rows.Err(); err != nil {
+		return models.TaskStats{}, fmt.Errorf("failed to iterate task counts: %w", err)
+	}
+
+	err = r.db.QueryRowContext(ctx, `
+		SELECT
+			COUNT(*) FILTER (WHERE completed),
+			COUNT(*) FILTER (WHERE completed AND completed_at < due_date),
+			COALESCE(AVG(EXTRACT(EPOCH FROM completed_at - created_at)) FILTER (WHERE completed), 0) / 3600,
+			COUNT(*) FILTER (WHERE `+overdueCondition+`)
+		FROM (
+			SELECT *, status = 'done' AND completed_at >= $2 AND completed_at < $3 AS completed
+			FROM `+allTasks+`
+			WHERE user_id = $1 AND ($4 = '' OR project_id::text = $4)
+		) t`, userID, from, to, projectID).Scan(&stats.Completed, &stats.CompletedOnTime, &stats.AvgCompletionHours, &stats.Overdue)
+	if err != nil {
+		return models.TaskStats{}, fmt.Errorf("failed to aggregate task completion: %w", err)
+	}
+
+	return stats, nil
+}
+
+// пользователи, у которых задачи создавались или менялись начиная с t
+func (r *TaskRepository) GetUsersWithTasksUpdatedSince(ctx context.Context, t time.Time) ([]string, error) {
+	query := `SELECT DISTINCT user_id FROM tasks WHERE updated_at >= $1`
+	rows, err := r.db.QueryContext(ctx, query, t)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query recently updated users: %w", err)
+	}
+	defer rows.Close()
+
+	var users []string
+	for rows.Next() {
+		var userID string
+		if err := rows.Scan(&userID); err != nil {
+			return nil, fmt.Errorf("failed to scan user id: %w", err)
+		}
+		users = append(users, userID)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate recently updated users: %w", err)
+	}
+
+	return users, nil
+}
+
+// taskColumns колонки задачи в порядке, ожидаемом scanTask
+const taskColumns = `id, title, description, notes, links, tags, status, priority, user_id, due_date, created_at, updated_at, completed_at, private, parent_id,
+	recurrence, recurrence_id, project_id, assignee_id`
+
+// allTasks оперативные и архивные задачи под именем tasks: подставляется во FROM запросов на чтение,
+// которые могут вернуть выполненные задачи. Условия на колонки Postgres применяет к каждой таблице
+// отдельно, поэтому индексы обеих таблиц используются
+const allTasks = `(SELECT ` + taskColumns + ` FROM tasks UNION ALL SELECT ` + taskColumns + ` FROM tasks_archive) tasks`
+
+// taskSource таблица для выборки по фильтрам: в архиве только выполненные задачи,
+// поэтому списки открытых задач и задач в другом статусе читают только tasks
+func taskSource(filters models.TaskFilters) string {
+	if filters.Open || (filters.Status != "" && filters.Status != models.StatusDone) {
+		return "tasks"
+	}
+	return allTasks
+}
+
+// archiveBlockers условия, при которых выполненная задача t остается в tasks: на нее ссылаются подзадачи,
+// связи, серия повторений или внешние ссылки, которые при переносе были бы потеряны
+const archiveBlockers = `
+	EXISTS (SELECT 1 FROM tasks c WHERE c.parent_id = t.id)
+	OR EXISTS (SELECT 1 FROM task_relations rel WHERE rel.task_id = t.id OR rel.related_task_id = t.id)
+	OR EXISTS (SELECT 1 FROM task_recurrences s WHERE s.last_task_id = t.id)
+	OR EXISTS (SELECT 1 FROM task_external_refs e WHERE e.task_id = t.id)`
+
+// ArchiveTasks переносит в tasks_archive до limit задач, выполненных раньше before, и возвращает их число.
+// Производные данные задачи (эмбеддинги, результаты AI, отметки напоминаний) удаляются каскадно.
+// Заблокированные другим экземпляром строки пропускаются, поэтому задачу можно запускать параллельно
+func (r *TaskRepository) ArchiveTasks(ctx context.Context, before time.Time, limit int) (int, error) {
+	result, err := r.db.ExecContext(ctx, `
+		WITH moved AS (
+			DELETE FROM tasks
+			WHERE id IN (
+				SELECT t.id FROM tasks t
+				WHERE t.status = 'done' AND t.completed_at < $1 AND NOT (`+archiveBlockers+`)
+				ORDER BY t.completed_at
+				LIMIT $2
+				FOR UPDATE SKIP LOCKED
+			)
+			RETURNING `+taskColumns+`
+		)
+		INSERT INTO tasks_archive (`+taskColumns+`)
+		SELECT `+taskColumns+` FROM moved
+	`, before, limit)
+	if err != nil {
+		return 0, fmt.Errorf("failed to archive tasks: %w", err)
+	}
+
+	moved, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return int(moved), nil
+}
+
+// restoreArchived возвращает архивную задачу в tasks вместе с архивными предками, иначе ссылка
+// на родителя не прошла бы проверку. Ссылка на удаленного родителя сбрасывается.
+// Для задачи не из архива ничего не делает
+func (r *TaskRepository) restoreArchived(ctx context.Context, id string) error {
+	_, err := r.db.ExecContext(ctx, `
+		WITH RECURSIVE chain AS (
+			SELECT id, parent_id, 1 AS depth FROM tasks_archive WHERE id = $1
+			UNION
+			SELECT a.id, a.parent_id, c.depth + 1
+			FROM tasks_archive a
+			JOIN chain c ON a.id = c.parent_id
+			WHERE c.depth < $2
+		), restored AS (
+			DELETE FROM tasks_archive WHERE id IN (SELECT id FROM chain)
+			RETURNING `+taskColumns+`
+		)
+		INSERT INTO tasks (`+taskColumns+`)
+		SELECT id, title, description, notes, links, tags, status, priority, user_id, due_date, created_at, updated_at,
+			completed_at, private,
+			CASE WHEN parent_id IN (SELECT id FROM tasks) OR parent_id IN (SELECT id FROM restored) THEN parent_id END,
+			recurrence, recurrence_id, project_id, assignee_id
+		FROM restored
+	`, id, maxHierarchyDepth)
+	if err != nil {
+		return fmt.Errorf("failed to restore archived task: %w", translateError(err))
+	}
+
+	return nil
+}
+
+// rowScanner общий интерфейс *sql.Row и *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanTask читает задачу из строки с колонками taskColumns
+func scanTask(row rowScanner) (models.Task, error) {
+	var task models.Task
+	var description, notes, parentID, recurrence, recurrenceID, projectID, assigneeID sql.NullString
+	var links []byte
+	var completedAt sql.NullTime
+
+	err := row.Scan(
+		&task.ID, &task.Title, &description, &notes, &links, pq.Array(&task.Tags), &task.Status, &task.Priority,
+		&task.UserID, &task.DueDate, &task.CreatedAt, &task.UpdatedAt, &completedAt, &task.Private, &parentID,
+		&recurrence, &recurrenceID, &projectID, &assigneeID)
+	if err != nil {
+		return models.Task{}, err
+	}
+
+	task.Description = description.String
+	if notes.Valid {
+		task.Notes = &notes.String
+	}
+	if completedAt.Valid {
+		task.CompletedAt = &completedAt.Time
+	}
+	if parentID.Valid {
+		task.ParentID = &parentID.String
+	}
+	task.Recurrence = recurrence.String
+	if recurrenceID.Valid {
+		task.RecurrenceID = &recurrenceID.String
+	}
+	if projectID.Valid {
+		task.ProjectID = &projectID.String
+	}
+	if assigneeID.Valid {
+		task.AssigneeID = &assigneeID.String
+	}
+	if len(links) > 0 {
+		if err := json.Unmarshal(links, &task.Links); err != nil {
+			return models.Task{}, fmt.Errorf("failed to unmarshal task links: %w", err)
+		}
+	}
+
+	return task, nil
+}
+
+// marshalLinks ссылки задачи в JSON, отсутствие ссылок хранится как пустой массив
+func marshalLinks(links []models.TaskLink) ([]byte, error) {
+	if links == nil {
+		links = []models.TaskLink{}
+	}
+
+	data, err := json.Marshal(links)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal task links: %w", err)
+	}
+
+	return data, nil
+}
+
+// tagList отсутствие тегов хранится как пустой массив
+func tagList(tags []string) []string {
+	if tags == nil {
+		return []string{}
+	}
+	return tags
+}
+
+// nullString пустая строка хранится как NULL
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
+
+// nullStringPtr отсутствующая или пустая строка хранится как NULL
+func nullStringPtr(s *string) sql.NullString {
+	if s == nil {
+		return sql.NullString{}
+	}
+	return nullString(*s)
+}
+
+// smartScore оценка срочности задачи для ?sort=smart, выполненные задачи идут в конце списка.
+// Приоритет дает от 1 до 3 баллов. Срок дает до 4 баллов: просроченная задача — 4,
+// задача со сроком через сутки — 2, через неделю — 0.5. Возраст добавляет до 1 балла
+// за 30 дней, чтобы давно созданные задачи не терялись среди новых
+const smartScore = `(
+	CASE priority WHEN 'high' THEN 3 WHEN 'medium' THEN 2 ELSE 1 END
+	+ CASE
+		WHEN due_date <= NOW() THEN 4
+		ELSE 4 / (1 + EXTRACT(EPOCH FROM due_date - NOW()) / 86400)
+	END
+	+ LEAST(EXTRACT(EPOCH FROM NOW() - created_at) / (30 * 86400), 1)
+)`
+
+// overdueCondition задача просрочена: не выполнена и срок прошел, то же условие, что models.Task.Overdue
+const overdueCondition = `(status <> 'done' AND due_date < NOW())`
+
+// buildTaskFilters формирует WHERE-условие и аргументы по фильтрам задач
+func buildTaskFilters(filters models.TaskFilters) (string, []interface{}) {
+	query := `WHERE user_id = $1`
+	if filters.Assigned {
+		query = `WHERE assignee_id = $1`
+	}
 	args := []interface{}{filters.UserID}
 	argCount := 2
 
@@ -176,42 +748,70 @@ func (r *TaskRepository) GetAll(ctx context.Context, filters models.TaskFilters)
 		argCount++
 	}
 
-	if filters.Search != "" {
-		query += ` AND (title ILIKE $` + strconv.Itoa(argCount) + ` OR description ILIKE $` + strconv.Itoa(argCount) + `)`
-		args = append(args, "%"+filters.Search+"%")
+	if filters.DueFrom != nil {
+		query += ` AND due_date >= $` + strconv.Itoa(argCount)
+		args = append(args, filters.DueFrom.UTC())
 		argCount++
 	}
 
-	query += ` ORDER BY due_date ASC, priority DESC, created_at DESC`
+	if filters.DueBefore != nil {
+		query += ` AND due_date < $` + strconv.Itoa(argCount)
+		args = append(args, filters.DueBefore.UTC())
+		argCount++
+	}
 
-	rows, err := r.db.QueryContext(ctx, query, args...)
-	if err != nil {
-		return nil, fmt.Errorf("failed to query tasks: %w", err)
+	if filters.Open {
+		query += ` AND status <> 'done'`
 	}
-	defer rows.Close()
 
-	var tasks []models.Task
-	for rows.Next() {
-		var task models.Task
-		var completedAt sql.NullTime
+	if filters.Overdue {
+		query += ` AND ` + overdueCondition
+	}
 
-		err := rows.Scan(
-			&task.ID, &task.Title, &task.Description, &task.Status, &task.Priority,
-			&task.UserID, &task.DueDate, &task.CreatedAt, &task.UpdatedAt, &completedAt)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan task: %w", err)
-		}
+	if filters.Tag != "" {
+		query += ` AND $` + strconv.Itoa(argCount) + ` = ANY(tags)`
+		args = append(args, filters.Tag)
+		argCount++
+	}
 
-		if completedAt.Valid {
-			task.CompletedAt = &completedAt.Time
-		}
+	if filters.ProjectID != "" {
+		query += ` AND project_id::text = $` + strconv.Itoa(argCount)
+		args = append(args, filters.ProjectID)
+		argCount++
+	}
 
-		tasks = append(tasks, task)
+	terms := filters.Terms
+	if filters.Search != "" {
+		terms = append([]string{filters.Search}, terms...)
+	}
+	for _, term := range terms {
+		// приватные задачи зашифрованы и не участвуют в поиске
+		query += ` AND NOT private AND (title ILIKE $` + strconv.Itoa(argCount) + ` OR description ILIKE $` + strconv.Itoa(argCount) + `)`
+		args = append(args, "%"+term+"%")
+		argCount++
 	}
 
-	if err = rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating tasks: %w", err)
+	return query, args
+}
+
+// ID задач пользователя, начинающиеся с prefix; prefix содержит только hex-символы.
+// Двух строк достаточно, чтобы отличить однозначную ссылку от неоднозначной
+func (r *TaskRepository) FindTaskIDsByPrefix(ctx context.Context, userID, prefix string) ([]string, error) {
+	query := `SELECT id FROM tasks WHERE user_id = $1 AND id::text LIKE $2 || '%' LIMIT 2`
+	rows, err := r.db.QueryContext(ctx, query, userID, prefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find tasks by prefix: %w", err)
 	}
+	defer rows.Close()
 
-	return tasks, nil
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan task id: %w", err)
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
 }
@@ -0,0 +1,108 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/jmoloko/taskmange/internal/domain/repository"
+)
+
+// привязываем чат; при смене чата или времени сводки дата последней сводки сбрасывается
+func (r *NotificationRepository) SaveTelegramLink(ctx context.Context, link *models.TelegramLink) error {
+	query := `
+		INSERT INTO telegram_links (user_id, chat_id, daily_digest_at)
+		VALUES ($1, $2, NULLIF($3, ''))
+		ON CONFLICT (user_id) DO UPDATE
+		SET chat_id = EXCLUDED.chat_id, daily_digest_at = EXCLUDED.daily_digest_at, last_digest_on = NULL, linked_at = now()
+		RETURNING linked_at
+	`
+	err := r.db.QueryRowContext(ctx, query, link.UserID, link.ChatID, link.DailyDigestAt).Scan(&link.LinkedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save telegram link: %w", translateError(err))
+	}
+
+	return nil
+}
+
+// привязанный чат пользователя
+func (r *NotificationRepository) GetTelegramLink(ctx context.Context, userID string) (*models.TelegramLink, error) {
+	link := models.TelegramLink{UserID: userID}
+	var digestAt sql.NullString
+	err := r.db.QueryRowContext(ctx, `
+		SELECT chat_id, daily_digest_at, last_digest_on, linked_at
+		FROM telegram_links
+		WHERE user_id = $1
+	`, userID).Scan(&link.ChatID, &digestAt, &link.LastDigestOn, &link.LinkedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get telegram link: %w", err)
+	}
+	link.DailyDigestAt = digestAt.String
+
+	return &link, nil
+}
+
+// отвязываем чат пользователя
+func (r *NotificationRepository) DeleteTelegramLink(ctx context.Context, userID string) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM telegram_links WHERE user_id = $1`, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete telegram link: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return repository.ErrNotFound
+	}
+
+	return nil
+}
+
+// привязки со сводкой; часовой пояс берется из настроек уведомлений, без настроек — UTC
+func (r *NotificationRepository) GetTelegramDigestLinks(ctx context.Context) ([]models.TelegramLink, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT l.user_id, l.chat_id, l.daily_digest_at, l.last_digest_on, l.linked_at, COALESCE(p.timezone, 'UTC')
+		FROM telegram_links l
+		LEFT JOIN notification_preferences p ON p.user_id = l.user_id
+		WHERE l.daily_digest_at IS NOT NULL
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query telegram digest links: %w", err)
+	}
+	defer rows.Close()
+
+	var links []models.TelegramLink
+	for rows.Next() {
+		var link models.TelegramLink
+		if err := rows.Scan(&link.UserID, &link.ChatID, &link.DailyDigestAt, &link.LastDigestOn, &link.LinkedAt, &link.Timezone); err != nil {
+			return nil, fmt.Errorf("failed to scan telegram link: %w", err)
+		}
+		links = append(links, link)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating telegram links: %w", err)
+	}
+
+	return links, nil
+}
+
+// запоминаем дату отправленной сводки
+func (r *NotificationRepository) MarkTelegramDigestSent(ctx context.Context, userID string, day time.Time) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE telegram_links SET last_digest_on = $2 WHERE user_id = $1`, userID, day.Format(time.DateOnly))
+	if err != nil {
+		return fmt.Errorf("failed to mark telegram digest sent: %w", err)
+	}
+
+	return nil
+}
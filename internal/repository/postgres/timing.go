@@ -0,0 +1,103 @@
+package postgres
+
+import (
+	"context"
+	"database/sql/driver"
+	"strings"
+	"unicode"
+
+	"github.com/jmoloko/taskmange/internal/timing"
+	"github.com/jmoloko/taskmange/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// timedConnector подключения к Postgres, которые учитывают время запросов в контексте запроса
+// (timing.DB) и записывают span запросов внутри трассы. Без контекста замера и трассы обертка
+// только передает вызовы драйверу
+type timedConnector struct {
+	driver.Connector
+}
+
+func (c timedConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &timedConn{conn: conn}, nil
+}
+
+// timedConn повторяет интерфейсы подключения lib/pq, которыми пользуется database/sql
+type timedConn struct {
+	conn driver.Conn
+}
+
+func (c *timedConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (rows driver.Rows, err error) {
+	ctx, done := observe(ctx, query)
+	defer func() { done(err) }()
+	return c.conn.(driver.QueryerContext).QueryContext(ctx, query, args)
+}
+
+func (c *timedConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (result driver.Result, err error) {
+	ctx, done := observe(ctx, query)
+	defer func() { done(err) }()
+	return c.conn.(driver.ExecerContext).ExecContext(ctx, query, args)
+}
+
+func (c *timedConn) BeginTx(ctx context.Context, opts driver.TxOptions) (tx driver.Tx, err error) {
+	ctx, done := observe(ctx, "BEGIN")
+	defer func() { done(err) }()
+	return c.conn.(driver.ConnBeginTx).BeginTx(ctx, opts)
+}
+
+// maxStatementLength длина текста запроса в span, значения параметров в текст не попадают
+const maxStatementLength = 2000
+
+// observe начинает замер времени и span запроса, возвращенная функция их завершает
+func observe(ctx context.Context, query string) (context.Context, func(error)) {
+	stop := timing.Start(ctx, timing.DB)
+
+	statement := strings.TrimSpace(query)
+	operation := statement
+	if end := strings.IndexFunc(statement, unicode.IsSpace); end >= 0 {
+		operation = statement[:end]
+	}
+	if len(statement) > maxStatementLength {
+		statement = statement[:maxStatementLength]
+	}
+	ctx, span := tracing.StartChild(ctx, "postgres "+strings.ToUpper(operation), trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.String("db.system", "postgresql"), attribute.String("db.statement", statement)))
+
+	return ctx, func(err error) {
+		stop()
+		tracing.End(span, err)
+	}
+}
+
+func (c *timedConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	return c.conn.(driver.ConnPrepareContext).PrepareContext(ctx, query)
+}
+
+func (c *timedConn) Prepare(query string) (driver.Stmt, error) {
+	return c.conn.Prepare(query)
+}
+
+func (c *timedConn) Begin() (driver.Tx, error) {
+	return c.conn.Begin()
+}
+
+func (c *timedConn) Close() error {
+	return c.conn.Close()
+}
+
+func (c *timedConn) Ping(ctx context.Context) error {
+	return c.conn.(driver.Pinger).Ping(ctx)
+}
+
+func (c *timedConn) ResetSession(ctx context.Context) error {
+	return c.conn.(driver.SessionResetter).ResetSession(ctx)
+}
+
+func (c *timedConn) IsValid() bool {
+	return c.conn.(driver.Validator).IsValid()
+}
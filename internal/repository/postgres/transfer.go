@@ -0,0 +1,66 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/jmoloko/taskmange/internal/domain/models"
+)
+
+type TransferRepository struct {
+	db *sql.DB
+}
+
+func NewTransferRepository(db *sql.DB) *TransferRepository {
+	return &TransferRepository{db: db}
+}
+
+// сохраняем запись истории, created_at назначает БД
+func (r *TransferRepository) SaveTransfer(ctx context.Context, transfer *models.DataTransfer) error {
+	query := `
+		INSERT INTO data_transfers (id, user_id, direction, format, status, item_count, duration_ms, error)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING created_at
+	`
+	err := r.db.QueryRowContext(ctx, query,
+		transfer.ID, transfer.UserID, transfer.Direction, transfer.Format, transfer.Status,
+		transfer.Items, transfer.DurationMs, transfer.Error).Scan(&transfer.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save data transfer: %w", err)
+	}
+
+	return nil
+}
+
+// последние записи истории пользователя
+func (r *TransferRepository) GetTransfers(ctx context.Context, userID string, limit int) ([]models.DataTransfer, error) {
+	query := `
+		SELECT id, user_id, direction, format, status, item_count, duration_ms, error, created_at
+		FROM data_transfers
+		WHERE user_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2
+	`
+	rows, err := r.db.QueryContext(ctx, query, userID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query data transfers: %w", err)
+	}
+	defer rows.Close()
+
+	transfers := []models.DataTransfer{}
+	for rows.Next() {
+		var t models.DataTransfer
+		if err := rows.Scan(&t.ID, &t.UserID, &t.Direction, &t.Format, &t.Status,
+			&t.Items, &t.DurationMs, &t.Error, &t.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan data transfer: %w", err)
+		}
+		transfers = append(transfers, t)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate data transfers: %w", err)
+	}
+
+	return transfers, nil
+}
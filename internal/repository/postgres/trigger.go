@@ -0,0 +1,129 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/jmoloko/taskmange/internal/domain/repository"
+)
+
+type TriggerRepository struct {
+	db *sql.DB
+}
+
+func NewTriggerRepository(db *sql.DB) *TriggerRepository {
+	return &TriggerRepository{db: db}
+}
+
+// создаём триггер, created_at назначает БД
+func (r *TriggerRepository) CreateTrigger(ctx context.Context, trigger *models.Trigger) error {
+	query := `
+		INSERT INTO triggers (id, user_id, event, filter, action_type, action_target, enabled, secret)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING created_at
+	`
+	err := r.db.QueryRowContext(ctx, query,
+		trigger.ID, trigger.UserID, trigger.On, trigger.Filter,
+		trigger.Action.Type, trigger.Action.Target, trigger.Enabled, trigger.Secret).Scan(&trigger.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create trigger: %w", translateError(err))
+	}
+
+	return nil
+}
+
+// удаляем триггер пользователя
+func (r *TriggerRepository) DeleteTrigger(ctx context.Context, userID, triggerID string) error {
+	query := `DELETE FROM triggers WHERE id = $1 AND user_id = $2`
+	result, err := r.db.ExecContext(ctx, query, triggerID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete trigger: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return repository.ErrNotFound
+	}
+
+	return nil
+}
+
+// все триггеры пользователя
+func (r *TriggerRepository) GetTriggers(ctx context.Context, userID string) ([]models.Trigger, error) {
+	query := `
+		SELECT id, user_id, event, filter, action_type, action_target, enabled, created_at,
+			secret, previous_secret, secret_rotated_at
+		FROM triggers
+		WHERE user_id = $1
+		ORDER BY created_at ASC
+	`
+	return r.queryTriggers(ctx, query, userID)
+}
+
+// включенные триггеры пользователя на событие
+func (r *TriggerRepository) GetEnabledTriggers(ctx context.Context, userID string, event models.EventType) ([]models.Trigger, error) {
+	query := `
+		SELECT id, user_id, event, filter, action_type, action_target, enabled, created_at,
+			secret, previous_secret, secret_rotated_at
+		FROM triggers
+		WHERE user_id = $1 AND event = $2 AND enabled
+		ORDER BY created_at ASC
+	`
+	return r.queryTriggers(ctx, query, userID, event)
+}
+
+// меняем секрет webhook-триггера, текущий сохраняем как предыдущий
+func (r *TriggerRepository) RotateTriggerSecret(ctx context.Context, userID, triggerID, secret string, rotatedAt time.Time) error {
+	query := `
+		UPDATE triggers
+		SET previous_secret = secret, secret = $3, secret_rotated_at = $4
+		WHERE id = $1 AND user_id = $2 AND action_type = 'webhook'
+	`
+	result, err := r.db.ExecContext(ctx, query, triggerID, userID, secret, rotatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to rotate trigger secret: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return repository.ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *TriggerRepository) queryTriggers(ctx context.Context, query string, args ...interface{}) ([]models.Trigger, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query triggers: %w", err)
+	}
+	defer rows.Close()
+
+	var triggers []models.Trigger
+	for rows.Next() {
+		var t models.Trigger
+		if err := rows.Scan(&t.ID, &t.UserID, &t.On, &t.Filter,
+			&t.Action.Type, &t.Action.Target, &t.Enabled, &t.CreatedAt,
+			&t.Secret, &t.PreviousSecret, &t.SecretRotatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan trigger: %w", err)
+		}
+		triggers = append(triggers, t)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating triggers: %w", err)
+	}
+
+	return triggers, nil
+}
@@ -0,0 +1,81 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/jmoloko/taskmange/internal/domain/models"
+)
+
+type UsageRepository struct {
+	db *sql.DB
+}
+
+func NewUsageRepository(db *sql.DB) *UsageRepository {
+	return &UsageRepository{db: db}
+}
+
+// сохраняем дневные счетчики в одной транзакции, счетчики за тот же день перезаписываются
+func (r *UsageRepository) SaveUsage(ctx context.Context, usage []models.UsageCount) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO api_usage (user_id, usage_date, requests, errors)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (user_id, usage_date) DO UPDATE
+		SET requests = EXCLUDED.requests, errors = EXCLUDED.errors, updated_at = now()
+	`
+	for _, u := range usage {
+		if _, err := tx.ExecContext(ctx, query, u.UserID, u.Day.Format("2006-01-02"), u.Requests, u.Errors); err != nil {
+			return fmt.Errorf("failed to save usage: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit usage: %w", err)
+	}
+
+	return nil
+}
+
+// пользователи с наибольшим числом запросов начиная с дня since
+func (r *UsageRepository) GetTopUsage(ctx context.Context, since time.Time, limit int) ([]models.UserUsage, error) {
+	query := `
+		SELECT u.user_id, COALESCE(us.email, ''), SUM(u.requests) AS requests, SUM(u.errors)
+		FROM api_usage u
+		LEFT JOIN users us ON us.id::text = u.user_id::text
+		WHERE u.usage_date >= $1
+		GROUP BY u.user_id, us.email
+		ORDER BY requests DESC, u.user_id
+		LIMIT $2
+	`
+	rows, err := r.db.QueryContext(ctx, query, since.Format("2006-01-02"), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query usage: %w", err)
+	}
+	defer rows.Close()
+
+	usage := []models.UserUsage{}
+	for rows.Next() {
+		var u models.UserUsage
+		if err := rows.Scan(&u.UserID, &u.Email, &u.Requests, &u.Errors); err != nil {
+			return nil, fmt.Errorf("failed to scan usage: %w", err)
+		}
+		if u.Requests > 0 {
+			u.ErrorRate = float64(u.Errors) / float64(u.Requests)
+		}
+		usage = append(usage, u)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate usage: %w", err)
+	}
+
+	return usage, nil
+}
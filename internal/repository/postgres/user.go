@@ -3,10 +3,16 @@ package postgres
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
 
 	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/jmoloko/taskmange/internal/domain/repository"
 )
 
+const userColumns = `id, email, password_hash, role, active, deactivated_at, created_at, updated_at`
+
 type UserRepository struct {
 	db *sql.DB
 }
@@ -16,23 +22,22 @@ func NewUserRepository(db *sql.DB) *UserRepository {
 }
 
 func (r *UserRepository) Create(ctx context.Context, user *models.User) error {
+	// без роли — роль по умолчанию из схемы
 	query := `
-		INSERT INTO users (id, email, password_hash, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5)
+		INSERT INTO users (id, email, password_hash, role)
+		VALUES ($1, $2, $3, COALESCE(NULLIF($4, ''), 'user'))
+		RETURNING role, active, created_at, updated_at
 	`
-	_, err := r.db.ExecContext(ctx, query,
-		user.ID, user.Email, user.PasswordHash, user.CreatedAt, user.UpdatedAt)
-	return err
+	err := r.db.QueryRowContext(ctx, query,
+		user.ID, user.Email, user.PasswordHash, user.Role).Scan(&user.Role, &user.Active, &user.CreatedAt, &user.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create user: %w", translateError(err))
+	}
+	return nil
 }
 
 func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*models.User, error) {
-	user := &models.User{}
-	query := `
-		SELECT id, email, password_hash, created_at, updated_at
-		FROM users WHERE email = $1
-	`
-	err := r.db.QueryRowContext(ctx, query, email).Scan(
-		&user.ID, &user.Email, &user.PasswordHash, &user.CreatedAt, &user.UpdatedAt)
+	user, err := scanUser(r.db.QueryRowContext(ctx, `SELECT `+userColumns+` FROM users WHERE email = $1`, email))
 	if err != nil {
 		return nil, err
 	}
@@ -40,13 +45,184 @@ func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*models.
 }
 
 func (r *UserRepository) GetByID(ctx context.Context, id string) (*models.User, error) {
+	user, err := scanUser(r.db.QueryRowContext(ctx, `SELECT `+userColumns+` FROM users WHERE id = $1`, id))
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// UpdatePassword меняет хэш пароля пользователя
+func (r *UserRepository) UpdatePassword(ctx context.Context, id, passwordHash string) error {
+	result, err := r.db.ExecContext(ctx,
+		`UPDATE users SET password_hash = $2, updated_at = NOW() WHERE id = $1`, id, passwordHash)
+	if err != nil {
+		return fmt.Errorf("failed to update password: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+// SetActive деактивирует или снова активирует пользователя, время деактивации назначает БД
+func (r *UserRepository) SetActive(ctx context.Context, id string, active bool) error {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE users
+		SET active = $2, deactivated_at = CASE WHEN $2 THEN NULL ELSE COALESCE(deactivated_at, NOW()) END, updated_at = NOW()
+		WHERE id = $1`, id, active)
+	if err != nil {
+		return fmt.Errorf("failed to update user status: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+// SetRole меняет роль пользователя
+func (r *UserRepository) SetRole(ctx context.Context, id, role string) error {
+	result, err := r.db.ExecContext(ctx,
+		`UPDATE users SET role = $2, updated_at = NOW() WHERE id = $1`, id, role)
+	if err != nil {
+		return fmt.Errorf("failed to update user role: %w", translateError(err))
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+// ListUsers возвращает пользователей по дате регистрации; limit 0 — всех
+func (r *UserRepository) ListUsers(ctx context.Context, limit, offset int) ([]models.User, error) {
+	query := `SELECT ` + userColumns + ` FROM users ORDER BY created_at, id`
+	var args []interface{}
+	if limit > 0 {
+		query += ` LIMIT $` + strconv.Itoa(len(args)+1)
+		args = append(args, limit)
+	}
+	if offset > 0 {
+		query += ` OFFSET $` + strconv.Itoa(len(args)+1)
+		args = append(args, offset)
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query users: %w", err)
+	}
+	defer rows.Close()
+
+	users := []models.User{}
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan user: %w", err)
+		}
+		users = append(users, *user)
+	}
+	return users, rows.Err()
+}
+
+// CountUsers возвращает число пользователей
+func (r *UserRepository) CountUsers(ctx context.Context) (int, error) {
+	var count int
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM users`).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count users: %w", err)
+	}
+	return count, nil
+}
+
+// PasswordHistory возвращает последние limit прежних хэшей пароля, от новых к старым
+func (r *UserRepository) PasswordHistory(ctx context.Context, userID string, limit int) ([]string, error) {
+	if limit <= 0 {
+		return nil, nil
+	}
+
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT password_hash FROM password_history WHERE user_id = $1 ORDER BY id DESC LIMIT $2`, userID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get password history: %w", err)
+	}
+	defer rows.Close()
+
+	var hashes []string
+	for rows.Next() {
+		var hash string
+		if err := rows.Scan(&hash); err != nil {
+			return nil, fmt.Errorf("failed to scan password history: %w", err)
+		}
+		hashes = append(hashes, hash)
+	}
+	return hashes, rows.Err()
+}
+
+// ChangePassword меняет хэш пароля и переносит прежний в историю одной транзакцией,
+// в истории остаются keep последних записей
+func (r *UserRepository) ChangePassword(ctx context.Context, userID, passwordHash string, keep int) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin password change: %w", err)
+	}
+	defer tx.Rollback()
+
+	// FOR UPDATE: параллельная смена пароля не должна потерять прежний хэш
+	var previous string
+	err = tx.QueryRowContext(ctx,
+		`SELECT password_hash FROM users WHERE id = $1 FOR UPDATE`, userID).Scan(&previous)
+	if errors.Is(err, sql.ErrNoRows) {
+		return repository.ErrNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get password: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx,
+		`UPDATE users SET password_hash = $2, updated_at = NOW() WHERE id = $1`, userID, passwordHash); err != nil {
+		return fmt.Errorf("failed to update password: %w", err)
+	}
+
+	if keep > 0 {
+		if _, err := tx.ExecContext(ctx,
+			`INSERT INTO password_history (user_id, password_hash) VALUES ($1, $2)`, userID, previous); err != nil {
+			return fmt.Errorf("failed to save password history: %w", err)
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		DELETE FROM password_history
+		WHERE user_id = $1 AND id NOT IN (
+			SELECT id FROM password_history WHERE user_id = $1 ORDER BY id DESC LIMIT $2
+		)`, userID, keep)
+	if err != nil {
+		return fmt.Errorf("failed to prune password history: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit password change: %w", err)
+	}
+	return nil
+}
+
+// scanUser читает пользователя из строки с колонками userColumns
+func scanUser(row rowScanner) (*models.User, error) {
 	user := &models.User{}
-	query := `
-		SELECT id, email, password_hash, created_at, updated_at
-		FROM users WHERE id = $1
-	`
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&user.ID, &user.Email, &user.PasswordHash, &user.CreatedAt, &user.UpdatedAt)
+	err := row.Scan(&user.ID, &user.Email, &user.PasswordHash, &user.Role, &user.Active,
+		&user.DeactivatedAt, &user.CreatedAt, &user.UpdatedAt)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,238 @@
+// Package sqlite хранит задачи и пользователей в файле SQLite (драйвер modernc.org/sqlite без cgo).
+// Хранилище выбирается STORAGE=sqlite, чтобы задачи жили в одном файле рядом с бинарником.
+// Схема создается встроенными миграциями при открытии базы
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jmoloko/taskmange/internal/domain/repository"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+//go:embed migrations/*.sql
+var migrations embed.FS
+
+var migrationFileName = regexp.MustCompile(`^(\d{3})_.+\.sql$`)
+
+func init() {
+	// lower() в SQLite меняет регистр только латиницы, поиск по задачам должен находить и кириллицу
+	if err := sqlite.RegisterDeterministicScalarFunction("unicode_lower", 1, unicodeLower); err != nil {
+		panic(err)
+	}
+}
+
+// Open открывает базу по пути к файлу (":memory:" — база в памяти) и применяет миграции.
+// Соединение одно: SQLite допускает одного писателя, а база в памяти у каждого соединения своя
+func Open(ctx context.Context, path string) (*sql.DB, error) {
+	dsn := "file:" + path + "?" + url.Values{
+		"_pragma": {"foreign_keys(1)", "busy_timeout(5000)", "journal_mode(WAL)"},
+	}.Encode()
+
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open sqlite database: %w", err)
+	}
+	db.SetMaxOpenConns(1)
+
+	if err := migrate(ctx, db); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return db, nil
+}
+
+// migrate применяет встроенные миграции новее записанной в schema_migrations версии,
+// каждую в своей транзакции
+func migrate(ctx context.Context, db *sql.DB) error {
+	names, err := fs.Glob(migrations, "migrations/*.sql")
+	if err != nil {
+		return fmt.Errorf("failed to list sqlite migrations: %w", err)
+	}
+	sort.Strings(names)
+
+	var current int
+	err = db.QueryRowContext(ctx, `SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&current)
+	if err != nil && !strings.Contains(err.Error(), "no such table") {
+		return fmt.Errorf("failed to get schema version: %w", err)
+	}
+
+	for _, name := range names {
+		match := migrationFileName.FindStringSubmatch(name[len("migrations/"):])
+		if match == nil {
+			continue
+		}
+		version, _ := strconv.Atoi(match[1])
+		if version <= current {
+			continue
+		}
+
+		script, err := migrations.ReadFile(name)
+		if err != nil {
+			return fmt.Errorf("failed to read migration %s: %w", name, err)
+		}
+		if err := applyMigration(ctx, db, version, string(script)); err != nil {
+			return fmt.Errorf("failed to apply migration %s: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+func applyMigration(ctx context.Context, db *sql.DB, version int, script string) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, script); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `INSERT INTO schema_migrations (version) VALUES (?)`, version); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// timeLayout формат временных меток в базе, совпадает с strftime('%Y-%m-%dT%H:%M:%fZ').
+// Строки одной длины в UTC, поэтому сравниваются и сортируются как время
+const timeLayout = "2006-01-02T15:04:05.000Z"
+
+// now текущее время средствами SQLite в формате timeLayout
+const now = `strftime('%Y-%m-%dT%H:%M:%fZ', 'now')`
+
+// formatTime время для сравнения с колонками базы
+func formatTime(t time.Time) string {
+	return t.UTC().Format(timeLayout)
+}
+
+// timeValue читает временную метку из TEXT; NULL оставляет nil в Null-варианте
+type timeValue struct {
+	dest     *time.Time
+	nullDest **time.Time
+}
+
+func (v timeValue) Scan(src interface{}) error {
+	var text string
+	switch value := src.(type) {
+	case nil:
+		if v.nullDest != nil {
+			*v.nullDest = nil
+			return nil
+		}
+		return errors.New("unexpected NULL timestamp")
+	case string:
+		text = value
+	case []byte:
+		text = string(value)
+	case time.Time:
+		text = formatTime(value)
+	default:
+		return fmt.Errorf("unsupported timestamp type %T", src)
+	}
+
+	t, err := time.Parse(timeLayout, text)
+	if err != nil {
+		return fmt.Errorf("invalid timestamp %q: %w", text, err)
+	}
+	if v.nullDest != nil {
+		*v.nullDest = &t
+	} else {
+		*v.dest = t
+	}
+	return nil
+}
+
+func scanTime(dest *time.Time) timeValue      { return timeValue{dest: dest} }
+func scanNullTime(dest **time.Time) timeValue { return timeValue{nullDest: dest} }
+
+// unicodeLower lower() с учетом Unicode
+func unicodeLower(ctx *sqlite.FunctionContext, args []driver.Value) (driver.Value, error) {
+	switch value := args[0].(type) {
+	case string:
+		return strings.ToLower(value), nil
+	case []byte:
+		return strings.ToLower(string(value)), nil
+	default:
+		return value, nil
+	}
+}
+
+// constraintMessage имя ограничения из сообщения вида "UNIQUE constraint failed: users.email":
+// для UNIQUE, PRIMARY KEY и NOT NULL — таблица.колонка, для CHECK — имя из CONSTRAINT в схеме
+var constraintMessage = regexp.MustCompile(`[A-Z ]+ constraint failed: ([\w.]+)`)
+
+// constraintNames имена ограничений Postgres для колонок уникальных ключей,
+// чтобы ошибки обоих хранилищ выглядели одинаково
+var constraintNames = map[string]string{
+	"users.id":                       "users_pkey",
+	"users.email":                    "users_email_key",
+	"tasks.id":                       "tasks_pkey",
+	"task_recurrences.id":            "task_recurrences_pkey",
+	"task_relations.task_id":         "task_relations_pkey",
+	"task_relations.related_task_id": "task_relations_pkey",
+}
+
+// constraintFields поля моделей по именам ограничений
+var constraintFields = map[string]string{
+	"users_pkey":           "id",
+	"users_email_key":      "email",
+	"users_role_check":     "role",
+	"tasks_pkey":           "id",
+	"task_status":          "status",
+	"task_priority":        "priority",
+	"task_relations_check": "related_task_id",
+}
+
+// translateError переводит нарушение ограничения из ошибки драйвера в *repository.ConstraintError,
+// остальные ошибки возвращает без изменений. Для внешних ключей SQLite не сообщает имя ограничения
+func translateError(err error) error {
+	var sqliteErr *sqlite.Error
+	if !errors.As(err, &sqliteErr) {
+		return err
+	}
+
+	var kind error
+	switch sqliteErr.Code() {
+	case sqlite3.SQLITE_CONSTRAINT_UNIQUE, sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY:
+		kind = repository.ErrAlreadyExists
+	case sqlite3.SQLITE_CONSTRAINT_FOREIGNKEY:
+		return &repository.ConstraintError{Err: repository.ErrInvalidReference}
+	case sqlite3.SQLITE_CONSTRAINT_CHECK, sqlite3.SQLITE_CONSTRAINT_NOTNULL:
+		kind = repository.ErrInvalidValue
+	default:
+		return err
+	}
+
+	var constraint string
+	if match := constraintMessage.FindStringSubmatch(sqliteErr.Error()); match != nil {
+		constraint = match[1]
+		if name, ok := constraintNames[constraint]; ok {
+			constraint = name
+		}
+	}
+	field := constraintFields[constraint]
+	if sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_NOTNULL {
+		// для NOT NULL в сообщении таблица.колонка
+		_, field, _ = strings.Cut(constraint, ".")
+		constraint = ""
+	}
+
+	return &repository.ConstraintError{Err: kind, Constraint: constraint, Field: field}
+}
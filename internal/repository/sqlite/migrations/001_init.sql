@@ -0,0 +1,80 @@
+-- Схема SQLite для задач и пользователей. Повторяет таблицы Postgres, кроме архива:
+-- перечисления заменены проверками, массивы и JSONB хранятся как JSON в TEXT,
+-- временные метки — как TEXT в UTC с миллисекундами, такие строки сортируются как время
+CREATE TABLE IF NOT EXISTS schema_migrations (
+    version INTEGER PRIMARY KEY,
+    applied_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ', 'now'))
+);
+
+CREATE TABLE IF NOT EXISTS users (
+    id TEXT PRIMARY KEY,
+    email TEXT NOT NULL UNIQUE,
+    password_hash TEXT NOT NULL,
+    role TEXT NOT NULL DEFAULT 'user' CONSTRAINT users_role_check CHECK (role IN ('user', 'admin')),
+    active INTEGER NOT NULL DEFAULT 1,
+    deactivated_at TEXT,
+    created_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ', 'now')),
+    updated_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ', 'now'))
+);
+
+CREATE TABLE IF NOT EXISTS password_history (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    password_hash TEXT NOT NULL,
+    created_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ', 'now'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_password_history_user_id ON password_history(user_id, id DESC);
+
+CREATE TABLE IF NOT EXISTS task_recurrences (
+    id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    rule TEXT NOT NULL,
+    paused INTEGER NOT NULL DEFAULT 0,
+    occurrences INTEGER NOT NULL DEFAULT 1,
+    -- серия и первый экземпляр создаются одной транзакцией, поэтому проверка ссылки отложена
+    last_task_id TEXT REFERENCES tasks(id) ON DELETE SET NULL DEFERRABLE INITIALLY DEFERRED,
+    created_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ', 'now')),
+    updated_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ', 'now'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_task_recurrences_active ON task_recurrences(last_task_id)
+    WHERE last_task_id IS NOT NULL AND NOT paused;
+
+-- project_id без внешнего ключа: проекты хранятся в Postgres, их проверяет репозиторий
+CREATE TABLE IF NOT EXISTS tasks (
+    id TEXT PRIMARY KEY,
+    title TEXT NOT NULL,
+    description TEXT,
+    notes TEXT,
+    links TEXT NOT NULL DEFAULT '[]',
+    tags TEXT NOT NULL DEFAULT '[]',
+    status TEXT NOT NULL DEFAULT 'pending' CONSTRAINT task_status CHECK (status IN ('pending', 'in_progress', 'done')),
+    priority TEXT NOT NULL DEFAULT 'medium' CONSTRAINT task_priority CHECK (priority IN ('low', 'medium', 'high')),
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    due_date TEXT NOT NULL,
+    created_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ', 'now')),
+    updated_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ', 'now')),
+    completed_at TEXT,
+    private INTEGER NOT NULL DEFAULT 0,
+    parent_id TEXT REFERENCES tasks(id) ON DELETE SET NULL,
+    recurrence TEXT,
+    recurrence_id TEXT REFERENCES task_recurrences(id) ON DELETE SET NULL,
+    project_id TEXT,
+    assignee_id TEXT REFERENCES users(id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_tasks_user_due_id ON tasks(user_id, due_date, id);
+CREATE INDEX IF NOT EXISTS idx_tasks_assignee_id ON tasks(assignee_id, due_date) WHERE assignee_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_tasks_parent_id ON tasks(parent_id) WHERE parent_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_tasks_updated_at ON tasks(updated_at);
+
+CREATE TABLE IF NOT EXISTS task_relations (
+    task_id TEXT NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    related_task_id TEXT NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    created_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ', 'now')),
+    PRIMARY KEY (task_id, related_task_id),
+    CONSTRAINT task_relations_check CHECK (task_id < related_task_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_task_relations_related ON task_relations(related_task_id);
@@ -0,0 +1,104 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/jmoloko/taskmange/internal/domain/repository"
+)
+
+// связываем задачи, повторное связывание ничего не меняет
+func (r *TaskRepository) AddRelation(ctx context.Context, taskID, relatedID string) error {
+	first, second := relationPair(taskID, relatedID)
+	query := `INSERT INTO task_relations (task_id, related_task_id) VALUES (?, ?) ON CONFLICT DO NOTHING`
+	if _, err := r.db.ExecContext(ctx, query, first, second); err != nil {
+		return fmt.Errorf("failed to add task relation: %w", translateError(err))
+	}
+
+	return nil
+}
+
+// удаляем связь между задачами
+func (r *TaskRepository) RemoveRelation(ctx context.Context, taskID, relatedID string) error {
+	first, second := relationPair(taskID, relatedID)
+	result, err := r.db.ExecContext(ctx, `DELETE FROM task_relations WHERE task_id = ? AND related_task_id = ?`, first, second)
+	if err != nil {
+		return fmt.Errorf("failed to remove task relation: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return repository.ErrNotFound
+	}
+
+	return nil
+}
+
+// связанные задачи для нескольких задач одним запросом
+func (r *TaskRepository) GetRelated(ctx context.Context, taskIDs []string) (map[string][]models.Task, error) {
+	related := make(map[string][]models.Task, len(taskIDs))
+	if len(taskIDs) == 0 {
+		return related, nil
+	}
+
+	list, err := marshalJSON(taskIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	// связь хранится одной строкой, поэтому смотрим обе стороны
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT rel.source_id, `+taskColumns+`
+		FROM (
+			SELECT task_id AS source_id, related_task_id AS target_id
+			FROM task_relations WHERE task_id IN (SELECT value FROM json_each(?1))
+			UNION ALL
+			SELECT related_task_id, task_id
+			FROM task_relations WHERE related_task_id IN (SELECT value FROM json_each(?1))
+		) rel
+		JOIN tasks ON tasks.id = rel.target_id
+		ORDER BY tasks.created_at ASC, tasks.id
+	`, list)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query related tasks: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var sourceID string
+		task, err := scanTask(prefixScanner{row: rows, dest: []interface{}{&sourceID}})
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan related task: %w", err)
+		}
+		related[sourceID] = append(related[sourceID], task)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate related tasks: %w", err)
+	}
+
+	return related, nil
+}
+
+// relationPair упорядочивает пару задач так, как связь хранится в task_relations
+func relationPair(taskID, relatedID string) (string, string) {
+	if relatedID < taskID {
+		return relatedID, taskID
+	}
+	return taskID, relatedID
+}
+
+// prefixScanner читает дополнительные колонки перед колонками задачи
+type prefixScanner struct {
+	row  rowScanner
+	dest []interface{}
+}
+
+func (s prefixScanner) Scan(dest ...interface{}) error {
+	return s.row.Scan(append(s.dest, dest...)...)
+}
@@ -0,0 +1,692 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/jmoloko/taskmange/internal/domain/repository"
+)
+
+// ChangeFunc вызывается после изменения задач пользователя, как слушатель изменений tasks в Postgres
+type ChangeFunc func(ctx context.Context, userID string) error
+
+// TaskRepository задачи, связи и серии повторений в SQLite. Архива нет: выполненные задачи остаются в tasks
+type TaskRepository struct {
+	db        *sql.DB
+	projects  repository.ProjectReader
+	listeners []ChangeFunc
+}
+
+// NewTaskRepository репозиторий задач в базе из Open. projects проверяет проект задачи;
+// nil — проектов нет, и задача с проектом отклоняется как ссылка на несуществующую запись
+func NewTaskRepository(db *sql.DB, projects repository.ProjectReader) *TaskRepository {
+	return &TaskRepository{db: db, projects: projects}
+}
+
+// OnChange добавляет слушателя изменений, например сброс кэшей пользователя
+func (r *TaskRepository) OnChange(listener ChangeFunc) {
+	r.listeners = append(r.listeners, listener)
+}
+
+// Supports задачи в файле SQLite не видны таблицам Postgres и другим экземплярам
+func (r *TaskRepository) Supports(capability repository.Capability) bool {
+	return false
+}
+
+// changed оповещает слушателей, ошибки сброса кэшей не отменяют изменение
+func (r *TaskRepository) changed(ctx context.Context, userID string) {
+	for _, listener := range r.listeners {
+		_ = listener(ctx, userID)
+	}
+}
+
+// taskColumns колонки задачи в порядке, ожидаемом scanTask
+const taskColumns = `id, title, description, notes, links, tags, status, priority, user_id, due_date, created_at, updated_at, completed_at, private, parent_id,
+	recurrence, recurrence_id, project_id, assignee_id`
+
+// insertTask вставка задачи; completed_at для выполненной задачи проставляется сразу, как триггером в Postgres
+const insertTask = `
+	INSERT INTO tasks (id, title, description, notes, links, tags, status, priority, user_id, parent_id, due_date, private,
+		recurrence, recurrence_id, project_id, completed_at)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, CASE WHEN ?7 = 'done' THEN ` + now + ` END)
+`
+
+// создаём задачу; задача с RecurrenceID создается в одной транзакции с серией, первым экземпляром которой она становится
+func (r *TaskRepository) Create(ctx context.Context, task *models.Task) error {
+	if err := r.checkProject(ctx, task.ProjectID); err != nil {
+		return err
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if task.RecurrenceID != nil && task.Recurrence != "" {
+		_, err := tx.ExecContext(ctx, `INSERT INTO task_recurrences (id, user_id, rule, last_task_id) VALUES (?, ?, ?, ?)`,
+			*task.RecurrenceID, task.UserID, task.Recurrence, task.ID)
+		if err != nil {
+			return fmt.Errorf("failed to create recurrence: %w", translateError(err))
+		}
+	}
+
+	args, err := taskArgs(task)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, insertTask, args...); err != nil {
+		return fmt.Errorf("failed to create task: %w", translateError(err))
+	}
+
+	stored, err := scanTask(tx.QueryRowContext(ctx, `SELECT `+taskColumns+` FROM tasks WHERE id = ?`, task.ID))
+	if err != nil {
+		return fmt.Errorf("failed to read created task: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit task: %w", translateError(err))
+	}
+
+	task.CreatedAt, task.UpdatedAt, task.CompletedAt = stored.CreatedAt, stored.UpdatedAt, stored.CompletedAt
+	r.changed(ctx, task.UserID)
+	return nil
+}
+
+// CreateBatch создает задачи в одной транзакции: либо все, либо ни одной.
+// Временные метки назначает база, в tasks они не возвращаются
+func (r *TaskRepository) CreateBatch(ctx context.Context, tasks []models.Task) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+	for _, task := range tasks {
+		if err := r.checkProject(ctx, task.ProjectID); err != nil {
+			return err
+		}
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin batch insert: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, insertTask)
+	if err != nil {
+		return fmt.Errorf("failed to prepare batch insert: %w", err)
+	}
+	defer stmt.Close()
+
+	users := make(map[string]bool)
+	for _, task := range tasks {
+		// серии создает только Create, как и в пакетной вставке Postgres
+		task.Recurrence, task.RecurrenceID = "", nil
+		args, err := taskArgs(&task)
+		if err != nil {
+			return err
+		}
+		if _, err := stmt.ExecContext(ctx, args...); err != nil {
+			return fmt.Errorf("failed to create tasks: %w", translateError(err))
+		}
+		users[task.UserID] = true
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit batch insert: %w", translateError(err))
+	}
+	for userID := range users {
+		r.changed(ctx, userID)
+	}
+	return nil
+}
+
+// обновляем задачу владельца: updated_at меняется всегда, completed_at проставляется при переходе в done
+func (r *TaskRepository) Update(ctx context.Context, task *models.Task) error {
+	if err := r.checkProject(ctx, task.ProjectID); err != nil {
+		return err
+	}
+
+	links, err := marshalJSON(task.Links)
+	if err != nil {
+		return err
+	}
+	tags, err := marshalJSON(tagList(task.Tags))
+	if err != nil {
+		return err
+	}
+
+	var updatedAt time.Time
+	var completedAt *time.Time
+	err = r.db.QueryRowContext(ctx, `
+		UPDATE tasks
+		SET title = ?, description = ?, notes = ?, links = ?, tags = ?, status = ?6, priority = ?, due_date = ?,
+			private = ?, parent_id = ?, project_id = ?, updated_at = `+now+`,
+			completed_at = CASE WHEN ?6 = 'done' THEN COALESCE(completed_at, `+now+`) ELSE completed_at END
+		WHERE id = ? AND user_id = ?
+		RETURNING updated_at, completed_at
+	`, task.Title, nullString(task.Description), nullStringPtr(task.Notes), links, tags, task.Status, task.Priority,
+		formatTime(task.DueDate), task.Private, nullStringPtr(task.ParentID), nullStringPtr(task.ProjectID), task.ID,
+		task.UserID).Scan(scanTime(&updatedAt), scanNullTime(&completedAt))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return errors.New("task not found or not owned by user")
+		}
+		return fmt.Errorf("failed to update task: %w", translateError(err))
+	}
+
+	task.UpdatedAt, task.CompletedAt = updatedAt, completedAt
+	r.changed(ctx, task.UserID)
+	return nil
+}
+
+// переводим задачи пользователя в done в одной транзакции; ErrNotFound, если хотя бы одной задачи у пользователя нет
+func (r *TaskRepository) CompleteTasks(ctx context.Context, userID string, ids []string) ([]models.Task, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	list, err := marshalJSON(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	var found, distinct int
+	err = tx.QueryRowContext(ctx, `
+		SELECT (SELECT COUNT(*) FROM tasks WHERE user_id = ? AND id IN (SELECT value FROM json_each(?2))),
+			(SELECT COUNT(DISTINCT value) FROM json_each(?2))
+	`, userID, list).Scan(&found, &distinct)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count tasks: %w", err)
+	}
+	if found != distinct {
+		return nil, repository.ErrNotFound
+	}
+
+	tasks, err := queryTasks(ctx, tx, `
+		UPDATE tasks
+		SET status = 'done', updated_at = `+now+`, completed_at = COALESCE(completed_at, `+now+`)
+		WHERE user_id = ? AND id IN (SELECT value FROM json_each(?)) AND status <> 'done'
+		RETURNING `+taskColumns, userID, list)
+	if err != nil {
+		return nil, fmt.Errorf("failed to complete tasks: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit completed tasks: %w", err)
+	}
+
+	if len(tasks) > 0 {
+		r.changed(ctx, userID)
+	}
+	return tasks, nil
+}
+
+// назначаем задачу исполнителю, nil снимает назначение
+func (r *TaskRepository) SetAssignee(ctx context.Context, taskID string, assigneeID *string) error {
+	var userID string
+	err := r.db.QueryRowContext(ctx, `
+		UPDATE tasks SET assignee_id = ?, updated_at = `+now+` WHERE id = ? RETURNING user_id
+	`, nullStringPtr(assigneeID), taskID).Scan(&userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return repository.ErrNotFound
+		}
+		return fmt.Errorf("failed to set task assignee: %w", translateError(err))
+	}
+
+	r.changed(ctx, userID)
+	return nil
+}
+
+// удаляем задачу: подзадачи становятся задачами верхнего уровня, связи удаляются, серия завершается
+func (r *TaskRepository) Delete(ctx context.Context, id string) error {
+	var userID string
+	err := r.db.QueryRowContext(ctx, `DELETE FROM tasks WHERE id = ? RETURNING user_id`, id).Scan(&userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return errors.New("task not found")
+		}
+		return fmt.Errorf("failed to delete task: %w", err)
+	}
+
+	r.changed(ctx, userID)
+	return nil
+}
+
+// DeleteUserTasks удаляет все задачи пользователя, связи удаляются каскадно
+func (r *TaskRepository) DeleteUserTasks(ctx context.Context, userID string) ([]string, error) {
+	rows, err := r.db.QueryContext(ctx, `DELETE FROM tasks WHERE user_id = ? RETURNING id`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete user tasks: %w", err)
+	}
+
+	ids, err := scanIDs(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) > 0 {
+		r.changed(ctx, userID)
+	}
+	return ids, nil
+}
+
+// DeleteTasks удаляет до limit задач пользователя по фильтру и возвращает удаленные задачи
+func (r *TaskRepository) DeleteTasks(ctx context.Context, filter models.TaskDeleteFilter, limit int) ([]models.Task, error) {
+	where, args := buildDeleteFilter(filter)
+	tasks, err := queryTasks(ctx, r.db, `
+		DELETE FROM tasks WHERE id IN (SELECT id FROM tasks `+where+` LIMIT ?)
+		RETURNING `+taskColumns, append(args, limit)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete tasks: %w", err)
+	}
+
+	if len(tasks) > 0 {
+		r.changed(ctx, filter.UserID)
+	}
+	return tasks, nil
+}
+
+// buildDeleteFilter WHERE-условие массового удаления
+func buildDeleteFilter(filter models.TaskDeleteFilter) (string, []interface{}) {
+	query := `WHERE user_id = ?`
+	args := []interface{}{filter.UserID}
+
+	if filter.Status != "" {
+		query += ` AND status = ?`
+		args = append(args, filter.Status)
+	}
+	if filter.Priority != "" {
+		query += ` AND priority = ?`
+		args = append(args, filter.Priority)
+	}
+	if filter.ProjectID != "" {
+		query += ` AND project_id = ?`
+		args = append(args, filter.ProjectID)
+	}
+	if filter.CompletedBefore != nil {
+		query += ` AND completed_at < ?`
+		args = append(args, formatTime(*filter.CompletedBefore))
+	}
+	if filter.CreatedBefore != nil {
+		query += ` AND created_at < ?`
+		args = append(args, formatTime(*filter.CreatedBefore))
+	}
+
+	return query, args
+}
+
+// получаем задачу по ID
+func (r *TaskRepository) GetByID(ctx context.Context, id string) (*models.Task, error) {
+	task, err := scanTask(r.db.QueryRowContext(ctx, `SELECT `+taskColumns+` FROM tasks WHERE id = ?`, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errors.New("task not found")
+		}
+		return nil, fmt.Errorf("failed to get task: %w", err)
+	}
+
+	return &task, nil
+}
+
+// overdueCondition задача просрочена: не выполнена и срок прошел, то же условие, что models.Task.Overdue
+const overdueCondition = `(status <> 'done' AND due_date < ` + now + `)`
+
+// smartScore оценка срочности для sort=smart, та же формула, что в запросе Postgres:
+// приоритет от 1 до 3 баллов, срок до 4 баллов, возраст до 1 балла за 30 дней
+const smartScore = `(
+	` + priorityRank + `
+	+ CASE
+		WHEN due_date <= ` + now + ` THEN 4
+		ELSE 4 / (1 + julianday(due_date) - julianday('now'))
+	END
+	+ MIN((julianday('now') - julianday(created_at)) / 30, 1)
+)`
+
+// список задач с применением фильтров, порядок и страницы как в Postgres
+func (r *TaskRepository) GetAll(ctx context.Context, filters models.TaskFilters) ([]models.Task, error) {
+	where, args := buildTaskFilters(filters)
+	query := `SELECT ` + taskColumns + ` FROM tasks ` + where
+
+	if filters.After != nil {
+		query += ` AND (due_date, id) > (?, ?)`
+		args = append(args, formatTime(filters.After.DueDate), filters.After.ID)
+	}
+
+	// id в конце порядка делает его однозначным, иначе страницы могут пересекаться
+	switch filters.Sort {
+	case models.SortSmart:
+		query += ` ORDER BY status = 'done', ` + smartScore + ` DESC, due_date ASC, created_at ASC, id`
+	case models.SortDue:
+		query += ` ORDER BY due_date ASC, id ASC`
+	default:
+		query += ` ORDER BY due_date ASC, ` + priorityRank + ` DESC, created_at DESC, id`
+	}
+
+	// OFFSET в SQLite пишется только вместе с LIMIT, -1 — без ограничения
+	if filters.Limit > 0 || filters.Offset > 0 {
+		limit := -1
+		if filters.Limit > 0 {
+			limit = filters.Limit
+		}
+		query += ` LIMIT ? OFFSET ?`
+		args = append(args, limit, filters.Offset)
+	}
+
+	tasks, err := queryTasks(ctx, r.db, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tasks: %w", err)
+	}
+	return tasks, nil
+}
+
+// количество задач, подходящих под фильтры, без загрузки строк
+func (r *TaskRepository) Count(ctx context.Context, filters models.TaskFilters) (int, error) {
+	where, args := buildTaskFilters(filters)
+
+	var count int
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM tasks `+where, args...).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count tasks: %w", err)
+	}
+
+	return count, nil
+}
+
+// GetTaskStats агрегаты задач пользователя (или его проекта) за окно [from, to)
+func (r *TaskRepository) GetTaskStats(ctx context.Context, userID, projectID string, from, to time.Time) (models.TaskStats, error) {
+	stats := models.TaskStats{
+		StatusCount:   make(map[models.Status]int),
+		PriorityCount: make(map[models.Priority]int),
+	}
+	start, end := formatTime(from), formatTime(to)
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT status, priority, COUNT(*) FROM tasks
+		WHERE user_id = ?1 AND created_at >= ?2 AND created_at < ?3 AND (?4 = '' OR project_id = ?4)
+		GROUP BY status, priority`, userID, start, end, projectID)
+	if err != nil {
+		return models.TaskStats{}, fmt.Errorf("failed to count tasks by status and priority: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var status models.Status
+		var priority models.Priority
+		var count int
+		if err := rows.Scan(&status, &priority, &count); err != nil {
+			return models.TaskStats{}, fmt.Errorf("failed to scan task counts: %w", err)
+		}
+		stats.StatusCount[status] += count
+		stats.PriorityCount[priority] += count
+	}
+	if err := rows.Err(); err != nil {
+		return models.TaskStats{}, fmt.Errorf("failed to iterate task counts: %w", err)
+	}
+
+	// временные метки одной длины в UTC сравниваются как строки, julianday считает разницу в днях
+	err = r.db.QueryRowContext(ctx, `
+		SELECT
+			COUNT(*) FILTER (WHERE completed),
+			COUNT(*) FILTER (WHERE completed AND completed_at < due_date),
+			COALESCE(AVG((julianday(completed_at) - julianday(created_at)) * 24) FILTER (WHERE completed), 0),
+			COUNT(*) FILTER (WHERE `+overdueCondition+`)
+		FROM (
+			SELECT *, status = 'done' AND completed_at >= ?2 AND completed_at < ?3 AS completed
+			FROM tasks
+			WHERE user_id = ?1 AND (?4 = '' OR project_id = ?4)
+		)`, userID, start, end, projectID).Scan(&stats.Completed, &stats.CompletedOnTime, &stats.AvgCompletionHours, &stats.Overdue)
+	if err != nil {
+		return models.TaskStats{}, fmt.Errorf("failed to aggregate task completion: %w", err)
+	}
+
+	return stats, nil
+}
+
+// пользователи, у которых задачи создавались или менялись начиная с t
+func (r *TaskRepository) GetUsersWithTasksUpdatedSince(ctx context.Context, t time.Time) ([]string, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT DISTINCT user_id FROM tasks WHERE updated_at >= ?`, formatTime(t))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query recently updated users: %w", err)
+	}
+
+	return scanIDs(rows)
+}
+
+// проект задачи
+func (r *TaskRepository) GetProject(ctx context.Context, id string) (*models.Project, error) {
+	if r.projects == nil {
+		return nil, repository.ErrNotFound
+	}
+	return r.projects.GetProject(ctx, id)
+}
+
+// ID задач пользователя, начинающиеся с prefix; двух ID достаточно, чтобы отличить однозначную ссылку
+func (r *TaskRepository) FindTaskIDsByPrefix(ctx context.Context, userID, prefix string) ([]string, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT id FROM tasks WHERE user_id = ? AND substr(id, 1, length(?2)) = ?2 ORDER BY id LIMIT 2`, userID, prefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find tasks by prefix: %w", err)
+	}
+
+	return scanIDs(rows)
+}
+
+// priorityRank порядок приоритетов, в Postgres его задает перечисление task_priority
+const priorityRank = `CASE priority WHEN 'high' THEN 3 WHEN 'medium' THEN 2 ELSE 1 END`
+
+// buildTaskFilters формирует WHERE-условие и аргументы по фильтрам задач
+func buildTaskFilters(filters models.TaskFilters) (string, []interface{}) {
+	query := `WHERE user_id = ?`
+	if filters.Assigned {
+		query = `WHERE assignee_id = ?`
+	}
+	args := []interface{}{filters.UserID}
+
+	if filters.Status != "" {
+		query += ` AND status = ?`
+		args = append(args, filters.Status)
+	}
+	if filters.Priority != "" {
+		query += ` AND priority = ?`
+		args = append(args, filters.Priority)
+	}
+	if filters.DueDate != nil {
+		query += ` AND date(due_date) = date(?)`
+		args = append(args, formatTime(*filters.DueDate))
+	}
+	if filters.DueFrom != nil {
+		query += ` AND due_date >= ?`
+		args = append(args, formatTime(*filters.DueFrom))
+	}
+	if filters.DueBefore != nil {
+		query += ` AND due_date < ?`
+		args = append(args, formatTime(*filters.DueBefore))
+	}
+	if filters.Open {
+		query += ` AND status <> 'done'`
+	}
+	if filters.Overdue {
+		query += ` AND ` + overdueCondition
+	}
+	if filters.Tag != "" {
+		query += ` AND EXISTS (SELECT 1 FROM json_each(tags) WHERE value = ?)`
+		args = append(args, filters.Tag)
+	}
+	if filters.ProjectID != "" {
+		query += ` AND project_id = ?`
+		args = append(args, filters.ProjectID)
+	}
+
+	terms := filters.Terms
+	if filters.Search != "" {
+		terms = append([]string{filters.Search}, terms...)
+	}
+	for _, term := range terms {
+		// приватные задачи зашифрованы и не участвуют в поиске
+		query += ` AND NOT private AND (instr(unicode_lower(title), ?) > 0 OR instr(unicode_lower(COALESCE(description, '')), ?) > 0)`
+		term = strings.ToLower(term)
+		args = append(args, term, term)
+	}
+
+	return query, args
+}
+
+// checkProject проверяет, что проект задачи существует
+func (r *TaskRepository) checkProject(ctx context.Context, projectID *string) error {
+	if projectID == nil || *projectID == "" {
+		return nil
+	}
+	if _, err := r.GetProject(ctx, *projectID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return &repository.ConstraintError{Err: repository.ErrInvalidReference, Constraint: "tasks_project_id_fkey", Field: "project_id"}
+		}
+		return err
+	}
+	return nil
+}
+
+// taskArgs аргументы insertTask
+func taskArgs(task *models.Task) ([]interface{}, error) {
+	links, err := marshalJSON(task.Links)
+	if err != nil {
+		return nil, err
+	}
+	tags, err := marshalJSON(tagList(task.Tags))
+	if err != nil {
+		return nil, err
+	}
+
+	return []interface{}{
+		task.ID, task.Title, nullString(task.Description), nullStringPtr(task.Notes), links, tags, task.Status,
+		task.Priority, task.UserID, nullStringPtr(task.ParentID), formatTime(task.DueDate), task.Private,
+		nullString(task.Recurrence), nullStringPtr(task.RecurrenceID), nullStringPtr(task.ProjectID),
+	}, nil
+}
+
+// querier общий интерфейс *sql.DB и *sql.Tx
+type querier interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// queryTasks выполняет запрос с колонками taskColumns
+func queryTasks(ctx context.Context, db querier, query string, args ...interface{}) ([]models.Task, error) {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	defer rows.Close()
+
+	var tasks []models.Task
+	for rows.Next() {
+		task, err := scanTask(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan task: %w", err)
+		}
+		tasks = append(tasks, task)
+	}
+
+	return tasks, rows.Err()
+}
+
+// rowScanner общий интерфейс *sql.Row и *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanTask читает задачу из строки с колонками taskColumns
+func scanTask(row rowScanner) (models.Task, error) {
+	var task models.Task
+	var description, notes, parentID, recurrence, recurrenceID, projectID, assigneeID sql.NullString
+	var links, tags string
+
+	err := row.Scan(
+		&task.ID, &task.Title, &description, &notes, &links, &tags, &task.Status, &task.Priority,
+		&task.UserID, scanTime(&task.DueDate), scanTime(&task.CreatedAt), scanTime(&task.UpdatedAt),
+		scanNullTime(&task.CompletedAt), &task.Private, &parentID, &recurrence, &recurrenceID, &projectID, &assigneeID)
+	if err != nil {
+		return models.Task{}, err
+	}
+
+	task.Description = description.String
+	task.Notes = stringPtr(notes)
+	task.ParentID = stringPtr(parentID)
+	task.Recurrence = recurrence.String
+	task.RecurrenceID = stringPtr(recurrenceID)
+	task.ProjectID = stringPtr(projectID)
+	task.AssigneeID = stringPtr(assigneeID)
+	if err := json.Unmarshal([]byte(links), &task.Links); err != nil {
+		return models.Task{}, fmt.Errorf("failed to unmarshal task links: %w", err)
+	}
+	if err := json.Unmarshal([]byte(tags), &task.Tags); err != nil {
+		return models.Task{}, fmt.Errorf("failed to unmarshal task tags: %w", err)
+	}
+
+	return task, nil
+}
+
+// scanIDs читает один столбец id и закрывает rows
+func scanIDs(rows *sql.Rows) ([]string, error) {
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan id: %w", err)
+		}
+		ids = append(ids, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating ids: %w", err)
+	}
+
+	return ids, nil
+}
+
+// marshalJSON ссылки и теги хранятся как JSON, отсутствие ссылок — пустой массив
+func marshalJSON(value interface{}) (string, error) {
+	if links, ok := value.([]models.TaskLink); ok && links == nil {
+		value = []models.TaskLink{}
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal task field: %w", err)
+	}
+	return string(data), nil
+}
+
+// tagList отсутствие тегов хранится как пустой массив
+func tagList(tags []string) []string {
+	if tags == nil {
+		return []string{}
+	}
+	return tags
+}
+
+// nullString пустая строка хранится как NULL
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
+
+// nullStringPtr отсутствующая или пустая строка хранится как NULL
+func nullStringPtr(s *string) sql.NullString {
+	if s == nil {
+		return sql.NullString{}
+	}
+	return nullString(*s)
+}
+
+func stringPtr(s sql.NullString) *string {
+	if !s.Valid {
+		return nil
+	}
+	return &s.String
+}
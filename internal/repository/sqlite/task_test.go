@@ -0,0 +1,388 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/jmoloko/taskmange/internal/domain/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var day = time.Date(2024, 6, 10, 12, 0, 0, 0, time.UTC)
+
+// openTestDB база в памяти с пользователями user1 и user2
+func openTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := Open(context.Background(), ":memory:")
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	users := NewUserRepository(db)
+	for _, id := range []string{"user1", "user2"} {
+		require.NoError(t, users.Create(context.Background(), &models.User{ID: id, Email: id + "@example.com", PasswordHash: "hash"}))
+	}
+	return db
+}
+
+func newTask(id, userID string, due time.Time) models.Task {
+	return models.Task{ID: id, UserID: userID, Title: "Task " + id, Status: models.StatusPending, Priority: models.PriorityMedium, DueDate: due}
+}
+
+func createTasks(t *testing.T, repo *TaskRepository, tasks ...models.Task) {
+	t.Helper()
+	for _, task := range tasks {
+		require.NoError(t, repo.Create(context.Background(), &task))
+	}
+}
+
+func ids(tasks []models.Task) []string {
+	result := make([]string, 0, len(tasks))
+	for _, task := range tasks {
+		result = append(result, task.ID)
+	}
+	return result
+}
+
+func TestOpen_MigratesOnce(t *testing.T) {
+	db := openTestDB(t)
+
+	// повторный запуск миграций ничего не применяет
+	require.NoError(t, migrate(context.Background(), db))
+	var versions int
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM schema_migrations`).Scan(&versions))
+	assert.Equal(t, 1, versions)
+}
+
+func TestTaskRepository_CreateAndUpdate(t *testing.T) {
+	repo := NewTaskRepository(openTestDB(t), nil)
+	ctx := context.Background()
+
+	task := newTask("a", "user1", day)
+	task.Tags = []string{"work"}
+	task.Links = []models.TaskLink{{URL: "https://example.com"}}
+	require.NoError(t, repo.Create(ctx, &task))
+	assert.False(t, task.CreatedAt.IsZero())
+	assert.Nil(t, task.CompletedAt)
+
+	stored, err := repo.GetByID(ctx, "a")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"work"}, stored.Tags)
+	assert.Equal(t, task.Links, stored.Links)
+	assert.Equal(t, day, stored.DueDate)
+
+	assert.ErrorIs(t, repo.Create(ctx, &task), repository.ErrAlreadyExists)
+
+	task.Status = models.StatusDone
+	require.NoError(t, repo.Update(ctx, &task))
+	require.NotNil(t, task.CompletedAt)
+
+	other := task
+	other.UserID = "user2"
+	assert.Error(t, repo.Update(ctx, &other))
+
+	task.Priority = "urgent"
+	var constraint *repository.ConstraintError
+	require.ErrorAs(t, repo.Update(ctx, &task), &constraint)
+	assert.Equal(t, "priority", constraint.Field)
+
+	parent := "missing"
+	orphan := newTask("b", "user1", day)
+	orphan.ParentID = &parent
+	assert.ErrorIs(t, repo.Create(ctx, &orphan), repository.ErrInvalidReference)
+
+	project := "p1"
+	orphan.ParentID, orphan.ProjectID = nil, &project
+	assert.ErrorIs(t, repo.Create(ctx, &orphan), repository.ErrInvalidReference)
+
+	// пакет вставляется целиком или не вставляется вовсе
+	err = repo.CreateBatch(ctx, []models.Task{newTask("c", "user1", day), newTask("a", "user1", day)})
+	assert.ErrorIs(t, err, repository.ErrAlreadyExists)
+	_, err = repo.GetByID(ctx, "c")
+	assert.Error(t, err)
+}
+
+func TestTaskRepository_GetAllFilters(t *testing.T) {
+	repo := NewTaskRepository(openTestDB(t), nil)
+	ctx := context.Background()
+
+	done := newTask("done", "user1", day.Add(-24*time.Hour))
+	done.Status = models.StatusDone
+	tagged := newTask("tagged", "user1", day)
+	tagged.Tags = []string{"home"}
+	tagged.Description = "Купить МОЛОКО"
+	private := newTask("private", "user1", day.Add(2*time.Hour))
+	private.Title = "молоко"
+	private.Private = true
+	high := newTask("high", "user1", day.Add(48*time.Hour))
+	high.Priority = models.PriorityHigh
+	assignee := "user1"
+	assigned := newTask("assigned", "user2", day)
+	assigned.AssigneeID = &assignee
+	createTasks(t, repo, done, tagged, private, high, assigned)
+	require.NoError(t, repo.SetAssignee(ctx, "assigned", &assignee))
+
+	query := func(filters models.TaskFilters) []string {
+		filters.UserID = "user1"
+		tasks, err := repo.GetAll(ctx, filters)
+		require.NoError(t, err)
+		count, err := repo.Count(ctx, filters)
+		require.NoError(t, err)
+		if filters.Limit == 0 && filters.Offset == 0 && filters.After == nil {
+			assert.Equal(t, len(tasks), count)
+		}
+		return ids(tasks)
+	}
+
+	assert.Equal(t, []string{"done", "tagged", "private", "high"}, query(models.TaskFilters{}))
+	assert.Equal(t, []string{"tagged", "private", "high"}, query(models.TaskFilters{Open: true}))
+	assert.Equal(t, []string{"done"}, query(models.TaskFilters{Status: models.StatusDone}))
+	// сроки всех задач в прошлом, выполненная задача не просрочена
+	assert.Equal(t, []string{"tagged", "private", "high"}, query(models.TaskFilters{Overdue: true}))
+	assert.Equal(t, []string{"high"}, query(models.TaskFilters{Priority: models.PriorityHigh}))
+	assert.Equal(t, []string{"tagged"}, query(models.TaskFilters{Tag: "home"}))
+	assert.Equal(t, []string{"tagged"}, query(models.TaskFilters{Search: "молоко"}))
+	assert.Equal(t, []string{"tagged", "private"}, query(models.TaskFilters{DueDate: &day}))
+
+	from, before := day, day.Add(24*time.Hour)
+	assert.Equal(t, []string{"tagged", "private"}, query(models.TaskFilters{DueFrom: &from, DueBefore: &before}))
+	assert.Equal(t, []string{"assigned"}, query(models.TaskFilters{Assigned: true}))
+
+	assert.Equal(t, []string{"tagged", "private"}, query(models.TaskFilters{Limit: 2, Offset: 1}))
+	assert.Equal(t, []string{"private", "high"}, query(models.TaskFilters{Offset: 2}))
+	cursor := models.TaskCursor{DueDate: day, ID: "tagged"}
+	assert.Equal(t, []string{"private", "high"}, query(models.TaskFilters{Sort: models.SortDue, After: &cursor}))
+	// выполненные задачи в конце, просроченная задача с высоким приоритетом первой
+	assert.Equal(t, "done", query(models.TaskFilters{Sort: models.SortSmart})[3])
+
+	none, err := repo.GetAll(ctx, models.TaskFilters{UserID: "nobody"})
+	require.NoError(t, err)
+	assert.Nil(t, none)
+
+	found, err := repo.FindTaskIDsByPrefix(ctx, "user1", "ta")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"tagged"}, found)
+}
+
+func TestTaskRepository_CompleteAndDelete(t *testing.T) {
+	repo := NewTaskRepository(openTestDB(t), nil)
+	ctx := context.Background()
+	var changed []string
+	repo.OnChange(func(ctx context.Context, userID string) error {
+		changed = append(changed, userID)
+		return nil
+	})
+
+	parentID := "parent"
+	child := newTask("child", "user1", day)
+	child.ParentID = &parentID
+	createTasks(t, repo, newTask("parent", "user1", day), child, newTask("other", "user2", day))
+	require.NoError(t, repo.AddRelation(ctx, "parent", "child"))
+	require.NoError(t, repo.AddRelation(ctx, "child", "parent"))
+
+	related, err := repo.GetRelated(ctx, []string{"child", "parent"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"parent"}, ids(related["child"]))
+	assert.Equal(t, []string{"child"}, ids(related["parent"]))
+
+	_, err = repo.CompleteTasks(ctx, "user1", []string{"parent", "other"})
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+
+	completed, err := repo.CompleteTasks(ctx, "user1", []string{"parent", "parent"})
+	require.NoError(t, err)
+	require.Len(t, completed, 1)
+	assert.NotNil(t, completed[0].CompletedAt)
+	completed, err = repo.CompleteTasks(ctx, "user1", []string{"parent"})
+	require.NoError(t, err)
+	assert.Empty(t, completed)
+
+	require.NoError(t, repo.Delete(ctx, "parent"))
+	stored, err := repo.GetByID(ctx, "child")
+	require.NoError(t, err)
+	assert.Nil(t, stored.ParentID)
+	related, err = repo.GetRelated(ctx, []string{"child"})
+	require.NoError(t, err)
+	assert.Empty(t, related["child"])
+	assert.ErrorIs(t, repo.RemoveRelation(ctx, "child", "parent"), repository.ErrNotFound)
+
+	deleted, err := repo.DeleteUserTasks(ctx, "user1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"child"}, deleted)
+	assert.Equal(t, []string{"user1", "user1", "user2", "user1", "user1", "user1"}, changed)
+}
+
+func TestTaskRepository_Hierarchy(t *testing.T) {
+	repo := NewTaskRepository(openTestDB(t), nil)
+	ctx := context.Background()
+
+	root, middle := "root", "middle"
+	mid := newTask("middle", "user1", day)
+	mid.ParentID = &root
+	leaf := newTask("leaf", "user1", day)
+	leaf.ParentID = &middle
+	closed := newTask("closed", "user1", day.Add(-time.Hour))
+	closed.ParentID = &middle
+	closed.Status = models.StatusDone
+	createTasks(t, repo, newTask("root", "user1", day), mid, leaf, closed)
+
+	ancestors, err := repo.GetAncestorIDs(ctx, "leaf")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"middle", "root"}, ancestors)
+
+	subtasks, err := repo.GetSubtasks(ctx, "middle")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"leaf", "closed"}, ids(subtasks))
+
+	open, err := repo.GetOpenDescendantIDs(ctx, []string{"root"})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"middle", "leaf"}, open)
+}
+
+func TestTaskRepository_Recurrence(t *testing.T) {
+	repo := NewTaskRepository(openTestDB(t), nil)
+	ctx := context.Background()
+
+	seriesID := "series"
+	first := newTask("first", "user1", day)
+	first.Recurrence, first.RecurrenceID = "FREQ=DAILY", &seriesID
+	createTasks(t, repo, first)
+
+	due, err := repo.GetDueOccurrences(ctx, day, 10)
+	require.NoError(t, err)
+	assert.Empty(t, due)
+
+	due, err = repo.GetDueOccurrences(ctx, day.Add(time.Minute), 10)
+	require.NoError(t, err)
+	require.Len(t, due, 1)
+	assert.Equal(t, "FREQ=DAILY", due[0].Rule)
+
+	next := models.Task{ID: "second", DueDate: day.Add(24 * time.Hour)}
+	require.NoError(t, repo.CreateOccurrence(ctx, "first", &next))
+	assert.Equal(t, "Task first", next.Title)
+	assert.Equal(t, models.StatusPending, next.Status)
+
+	// из прежнего экземпляра следующий больше не создается
+	assert.ErrorIs(t, repo.CreateOccurrence(ctx, "first", &models.Task{ID: "third"}), repository.ErrNotFound)
+
+	recurrence, err := repo.GetRecurrence(ctx, seriesID)
+	require.NoError(t, err)
+	assert.Equal(t, 2, recurrence.Occurrences)
+	assert.Equal(t, "second", recurrence.LastTaskID)
+
+	require.NoError(t, repo.SetRecurrencePaused(ctx, seriesID, true))
+	assert.ErrorIs(t, repo.CreateOccurrence(ctx, "second", &models.Task{ID: "third"}), repository.ErrNotFound)
+	assert.ErrorIs(t, repo.SetRecurrencePaused(ctx, "missing", true), repository.ErrNotFound)
+
+	require.NoError(t, repo.EndRecurrence(ctx, seriesID))
+	recurrence, err = repo.GetRecurrence(ctx, seriesID)
+	require.NoError(t, err)
+	assert.Empty(t, recurrence.LastTaskID)
+}
+
+func TestTaskRepository_GetTaskStats(t *testing.T) {
+	db := openTestDB(t)
+	repo := NewTaskRepository(db, nil)
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	done := newTask("done", "user1", now.Add(24*time.Hour))
+	done.Status, done.Priority = models.StatusDone, models.PriorityHigh
+	late := newTask("late", "user1", now.Add(-2*time.Hour))
+	late.Status = models.StatusDone
+	overdue := newTask("overdue", "user1", now.Add(-time.Hour))
+	createTasks(t, repo, done, late, overdue,
+		newTask("upcoming", "user1", now.Add(48*time.Hour)),
+		newTask("old-overdue", "user1", now.Add(-72*time.Hour)),
+		newTask("other", "user2", now.Add(-time.Hour)))
+
+	// created_at и completed_at проставляет база, сдвигаем их в прошлое
+	setTime := func(id, column string, value time.Time) {
+		_, err := db.ExecContext(ctx, `UPDATE tasks SET `+column+` = ? WHERE id = ?`, formatTime(value), id)
+		require.NoError(t, err)
+	}
+	setTime("done", "created_at", now.Add(-4*time.Hour))
+	setTime("done", "completed_at", now.Add(-2*time.Hour))
+	setTime("late", "created_at", now.Add(-6*time.Hour))
+	setTime("late", "completed_at", now.Add(-time.Hour))
+	setTime("old-overdue", "created_at", now.Add(-96*time.Hour))
+
+	stats, err := repo.GetTaskStats(ctx, "user1", "", now.Add(-24*time.Hour), now.Add(time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, map[models.Status]int{models.StatusDone: 2, models.StatusPending: 2}, stats.StatusCount)
+	assert.Equal(t, map[models.Priority]int{models.PriorityHigh: 1, models.PriorityMedium: 3}, stats.PriorityCount)
+	assert.Equal(t, 2, stats.Completed)
+	assert.Equal(t, 1, stats.CompletedOnTime)
+	assert.InDelta(t, 3.5, stats.AvgCompletionHours, 0.01)
+	// просроченные считаются на текущий момент, срок old-overdue раньше окна
+	assert.Equal(t, 2, stats.Overdue)
+
+	empty, err := repo.GetTaskStats(ctx, "user1", "", now.Add(-240*time.Hour), now.Add(-200*time.Hour))
+	require.NoError(t, err)
+	assert.Empty(t, empty.StatusCount)
+	assert.Zero(t, empty.AvgCompletionHours)
+
+	// таблицы проектов в SQLite нет, проект проставляем напрямую
+	_, err = db.ExecContext(ctx, `UPDATE tasks SET project_id = 'p1' WHERE id IN ('late', 'overdue')`)
+	require.NoError(t, err)
+	project, err := repo.GetTaskStats(ctx, "user1", "p1", now.Add(-24*time.Hour), now.Add(time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, map[models.Status]int{models.StatusDone: 1, models.StatusPending: 1}, project.StatusCount)
+	assert.Equal(t, 1, project.Completed)
+	assert.Zero(t, project.CompletedOnTime)
+	assert.Equal(t, 1, project.Overdue)
+}
+
+func TestTaskRepository_DeleteTasks(t *testing.T) {
+	db := openTestDB(t)
+	repo := NewTaskRepository(db, nil)
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	var changed []string
+	repo.OnChange(func(ctx context.Context, userID string) error {
+		changed = append(changed, userID)
+		return nil
+	})
+
+	oldDone := newTask("old-done", "user1", now)
+	oldDone.Status = models.StatusDone
+	oldDone2 := newTask("old-done-2", "user1", now)
+	oldDone2.Status = models.StatusDone
+	recentDone := newTask("recent-done", "user1", now)
+	recentDone.Status = models.StatusDone
+	foreign := newTask("foreign", "user2", now)
+	foreign.Status = models.StatusDone
+	createTasks(t, repo, oldDone, oldDone2, recentDone, foreign, newTask("open", "user1", now))
+	changed = nil
+
+	for _, id := range []string{"old-done", "old-done-2", "foreign"} {
+		_, err := db.ExecContext(ctx, `UPDATE tasks SET completed_at = ? WHERE id = ?`, formatTime(now.AddDate(-1, 0, 0)), id)
+		require.NoError(t, err)
+	}
+
+	before := now.AddDate(0, -1, 0)
+	filter := models.TaskDeleteFilter{UserID: "user1", Status: models.StatusDone, CompletedBefore: &before}
+
+	first, err := repo.DeleteTasks(ctx, filter, 1)
+	require.NoError(t, err)
+	require.Len(t, first, 1)
+	second, err := repo.DeleteTasks(ctx, filter, 1)
+	require.NoError(t, err)
+	require.Len(t, second, 1)
+	assert.ElementsMatch(t, []string{"old-done", "old-done-2"}, []string{first[0].ID, second[0].ID})
+
+	// подходящих задач не осталось
+	rest, err := repo.DeleteTasks(ctx, filter, 1)
+	require.NoError(t, err)
+	assert.Empty(t, rest)
+	assert.Equal(t, []string{"user1", "user1"}, changed)
+
+	remaining, err := repo.GetAll(ctx, models.TaskFilters{UserID: "user1"})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"recent-done", "open"}, ids(remaining))
+	_, err = repo.GetByID(ctx, "foreign")
+	assert.NoError(t, err)
+}
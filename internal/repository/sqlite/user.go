@@ -0,0 +1,195 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/jmoloko/taskmange/internal/domain/repository"
+)
+
+const userColumns = `id, email, password_hash, role, active, deactivated_at, created_at, updated_at`
+
+// UserRepository пользователи и история паролей в SQLite
+type UserRepository struct {
+	db *sql.DB
+}
+
+// NewUserRepository репозиторий пользователей в базе из Open
+func NewUserRepository(db *sql.DB) *UserRepository {
+	return &UserRepository{db: db}
+}
+
+// создаём пользователя; без роли — роль по умолчанию из схемы
+func (r *UserRepository) Create(ctx context.Context, user *models.User) error {
+	stored, err := scanUser(r.db.QueryRowContext(ctx, `
+		INSERT INTO users (id, email, password_hash, role)
+		VALUES (?, ?, ?, COALESCE(NULLIF(?, ''), 'user'))
+		RETURNING `+userColumns,
+		user.ID, user.Email, user.PasswordHash, user.Role))
+	if err != nil {
+		return fmt.Errorf("failed to create user: %w", translateError(err))
+	}
+
+	user.Role, user.Active, user.DeactivatedAt = stored.Role, stored.Active, nil
+	user.CreatedAt, user.UpdatedAt = stored.CreatedAt, stored.UpdatedAt
+	return nil
+}
+
+func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*models.User, error) {
+	return r.get(ctx, `email = ?`, email)
+}
+
+func (r *UserRepository) GetByID(ctx context.Context, id string) (*models.User, error) {
+	return r.get(ctx, `id = ?`, id)
+}
+
+func (r *UserRepository) get(ctx context.Context, condition string, value string) (*models.User, error) {
+	user, err := scanUser(r.db.QueryRowContext(ctx, `SELECT `+userColumns+` FROM users WHERE `+condition, value))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, repository.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	return user, nil
+}
+
+// UpdatePassword меняет хэш пароля пользователя
+func (r *UserRepository) UpdatePassword(ctx context.Context, id, passwordHash string) error {
+	return r.update(ctx, `password_hash = ?`, passwordHash, id)
+}
+
+// SetActive деактивирует или снова активирует пользователя, время деактивации назначает БД
+func (r *UserRepository) SetActive(ctx context.Context, id string, active bool) error {
+	return r.update(ctx,
+		`active = ?1, deactivated_at = CASE WHEN ?1 THEN NULL ELSE COALESCE(deactivated_at, `+now+`) END`, active, id)
+}
+
+// SetRole меняет роль пользователя
+func (r *UserRepository) SetRole(ctx context.Context, id, role string) error {
+	return r.update(ctx, `role = ?`, role, id)
+}
+
+// update меняет колонки из set (параметр ?1) и updated_at, ErrNotFound — пользователя нет
+func (r *UserRepository) update(ctx context.Context, set string, value interface{}, id string) error {
+	result, err := r.db.ExecContext(ctx, `UPDATE users SET `+set+`, updated_at = `+now+` WHERE id = ?2`, value, id)
+	if err != nil {
+		return fmt.Errorf("failed to update user: %w", translateError(err))
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+// ListUsers возвращает пользователей по дате регистрации; limit 0 — всех
+func (r *UserRepository) ListUsers(ctx context.Context, limit, offset int) ([]models.User, error) {
+	if limit <= 0 {
+		limit = -1
+	}
+
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT `+userColumns+` FROM users ORDER BY created_at, id LIMIT ? OFFSET ?`, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query users: %w", err)
+	}
+	defer rows.Close()
+
+	users := []models.User{}
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan user: %w", err)
+		}
+		users = append(users, *user)
+	}
+	return users, rows.Err()
+}
+
+// CountUsers возвращает число пользователей
+func (r *UserRepository) CountUsers(ctx context.Context) (int, error) {
+	var count int
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM users`).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count users: %w", err)
+	}
+	return count, nil
+}
+
+// PasswordHistory возвращает последние limit прежних хэшей пароля, от новых к старым
+func (r *UserRepository) PasswordHistory(ctx context.Context, userID string, limit int) ([]string, error) {
+	if limit <= 0 {
+		return nil, nil
+	}
+
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT password_hash FROM password_history WHERE user_id = ? ORDER BY id DESC LIMIT ?`, userID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get password history: %w", err)
+	}
+
+	return scanIDs(rows)
+}
+
+// ChangePassword меняет хэш пароля и переносит прежний в историю одной транзакцией,
+// в истории остаются keep последних записей
+func (r *UserRepository) ChangePassword(ctx context.Context, userID, passwordHash string, keep int) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin password change: %w", err)
+	}
+	defer tx.Rollback()
+
+	var previous string
+	err = tx.QueryRowContext(ctx, `SELECT password_hash FROM users WHERE id = ?`, userID).Scan(&previous)
+	if errors.Is(err, sql.ErrNoRows) {
+		return repository.ErrNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get password: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx,
+		`UPDATE users SET password_hash = ?, updated_at = `+now+` WHERE id = ?`, passwordHash, userID); err != nil {
+		return fmt.Errorf("failed to update password: %w", err)
+	}
+
+	if keep > 0 {
+		if _, err := tx.ExecContext(ctx,
+			`INSERT INTO password_history (user_id, password_hash) VALUES (?, ?)`, userID, previous); err != nil {
+			return fmt.Errorf("failed to save password history: %w", err)
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		DELETE FROM password_history
+		WHERE user_id = ?1 AND id NOT IN (
+			SELECT id FROM password_history WHERE user_id = ?1 ORDER BY id DESC LIMIT ?2
+		)`, userID, keep)
+	if err != nil {
+		return fmt.Errorf("failed to prune password history: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit password change: %w", err)
+	}
+	return nil
+}
+
+// scanUser читает пользователя из строки с колонками userColumns
+func scanUser(row rowScanner) (*models.User, error) {
+	user := &models.User{}
+	err := row.Scan(&user.ID, &user.Email, &user.PasswordHash, &user.Role, &user.Active,
+		scanNullTime(&user.DeactivatedAt), scanTime(&user.CreatedAt), scanTime(&user.UpdatedAt))
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
@@ -0,0 +1,36 @@
+// Package requestid передает идентификатор HTTP-запроса через контекст, чтобы записи лога
+// обработчиков, сервисов и хранилищ одного запроса можно было связать
+package requestid
+
+import (
+	"context"
+	"regexp"
+
+	"github.com/google/uuid"
+)
+
+type contextKey struct{}
+
+// validID идентификатор от клиента или прокси: до 128 символов без пробелов и управляющих символов
+var validID = regexp.MustCompile(`^[A-Za-z0-9._:/+=-]{1,128}$`)
+
+// New новый идентификатор запроса
+func New() string {
+	return uuid.New().String()
+}
+
+// Valid проверяет идентификатор, пришедший в заголовке: он попадает в лог и в ответ как есть
+func Valid(id string) bool {
+	return validID.MatchString(id)
+}
+
+// NewContext возвращает контекст с идентификатором запроса
+func NewContext(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// FromContext идентификатор запроса из контекста, пустая строка — вне запроса
+func FromContext(ctx context.Context) string {
+	id, _ := ctx.Value(contextKey{}).(string)
+	return id
+}
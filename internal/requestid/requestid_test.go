@@ -0,0 +1,23 @@
+package requestid
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValid(t *testing.T) {
+	assert.True(t, Valid(New()))
+	assert.True(t, Valid("req-42/edge:1"))
+	assert.False(t, Valid(""))
+	assert.False(t, Valid("with space"))
+	assert.False(t, Valid("line\nbreak"))
+	assert.False(t, Valid(strings.Repeat("a", 129)))
+}
+
+func TestContext(t *testing.T) {
+	assert.Empty(t, FromContext(context.Background()))
+	assert.Equal(t, "req-1", FromContext(NewContext(context.Background(), "req-1")))
+}
@@ -0,0 +1,53 @@
+package search
+
+import (
+	"strings"
+
+	"github.com/jmoloko/taskmange/internal/domain/models"
+)
+
+// Match проверяет задачу на условия запроса без обращения к базе, так же как фильтры списка задач:
+// текст ищется без учета регистра, приватные задачи под текстовые части не подходят
+func (q Query) Match(task models.Task) bool {
+	if q.Status != "" && task.Status != q.Status {
+		return false
+	}
+	if q.Priority != "" && task.Priority != q.Priority {
+		return false
+	}
+	if q.Open && task.Status == models.StatusDone {
+		return false
+	}
+	if q.ProjectID != "" && (task.ProjectID == nil || *task.ProjectID != q.ProjectID) {
+		return false
+	}
+	if q.DueFrom != nil && task.DueDate.Before(*q.DueFrom) {
+		return false
+	}
+	if q.DueBefore != nil && !task.DueDate.Before(*q.DueBefore) {
+		return false
+	}
+
+	if q.Tag != "" {
+		tagged := false
+		for _, tag := range task.Tags {
+			tagged = tagged || tag == q.Tag
+		}
+		if !tagged {
+			return false
+		}
+	}
+
+	if len(q.Terms) > 0 && task.Private {
+		return false
+	}
+	title, description := strings.ToLower(task.Title), strings.ToLower(task.Description)
+	for _, term := range q.Terms {
+		term = strings.ToLower(term)
+		if !strings.Contains(title, term) && !strings.Contains(description, term) {
+			return false
+		}
+	}
+
+	return true
+}
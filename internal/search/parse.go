@@ -0,0 +1,266 @@
+package search
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/jmoloko/taskmange/internal/domain/models"
+)
+
+// ограничения запроса: длина в символах и число текстовых частей, каждая из которых — отдельное условие в SQL
+const (
+	MaxQueryLength = 500
+	MaxTerms       = 10
+)
+
+// fields поля условий в порядке, в котором они перечисляются в сообщениях об ошибках
+var fields = []string{"status", "priority", "tag", "project", "is", "due"}
+
+var (
+	// condition поле, оператор и значение: status:done, due<2024-07-01
+	condition = regexp.MustCompile(`^([A-Za-z_]+)(:|<=|>=|<|>)(.*)$`)
+	isoDate   = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
+)
+
+// Query разобранный запрос. Все условия и текстовые части должны выполняться одновременно
+type Query struct {
+	Status    models.Status
+	Priority  models.Priority
+	Tag       string
+	ProjectID string
+	// Open только невыполненные задачи (is:open, is:overdue)
+	Open bool
+	// DueFrom и DueBefore окно срока [DueFrom, DueBefore), любая из границ может отсутствовать
+	DueFrom   *time.Time
+	DueBefore *time.Time
+	// Terms текст, который должен встретиться в названии или описании, каждая часть отдельно
+	Terms []string
+}
+
+// Apply переносит условия запроса в фильтры списка задач; условия запроса заменяют уже заданные
+func (q Query) Apply(filters *models.TaskFilters) {
+	if q.Status != "" {
+		filters.Status = q.Status
+	}
+	if q.Priority != "" {
+		filters.Priority = q.Priority
+	}
+	if q.Tag != "" {
+		filters.Tag = q.Tag
+	}
+	if q.ProjectID != "" {
+		filters.ProjectID = q.ProjectID
+	}
+	if q.Open {
+		filters.Open = true
+	}
+	if q.DueFrom != nil {
+		filters.DueFrom = q.DueFrom
+	}
+	if q.DueBefore != nil {
+		filters.DueBefore = q.DueBefore
+	}
+	filters.Terms = append(filters.Terms, q.Terms...)
+}
+
+// parser состояние разбора одного запроса
+type parser struct {
+	query Query
+	now   time.Time
+}
+
+// Parse разбирает запрос относительно now; даты читаются в часовом поясе now.
+// Поддерживаются:
+//   - status:pending|in_progress|done, priority:low|medium|high
+//   - tag:work или #work, project:<ID проекта>
+//   - is:open — невыполненные, is:overdue — невыполненные с прошедшим сроком
+//   - due:2024-07-01 — срок в этот день, due<, due<=, due>, due>= — сравнение с днем;
+//     вместо даты можно написать today, tomorrow или yesterday
+//   - остальные слова и текст в двойных кавычках ищутся в названии и описании
+//
+// Каждое поле можно указать один раз, у срока — по одной границе с каждой стороны
+func Parse(input string, now time.Time) (Query, error) {
+	if n := len([]rune(input)); n > MaxQueryLength {
+		return Query{}, &SyntaxError{Pos: MaxQueryLength + 1, Msg: fmt.Sprintf("query is longer than %d characters", MaxQueryLength)}
+	}
+
+	tokens, err := Tokenize(input)
+	if err != nil {
+		return Query{}, err
+	}
+
+	p := &parser{now: now}
+	for _, token := range tokens {
+		if err := p.token(token); err != nil {
+			return Query{}, err
+		}
+	}
+
+	return p.query, nil
+}
+
+func (p *parser) token(token Token) error {
+	if token.Quoted {
+		return p.term(token)
+	}
+
+	if strings.HasPrefix(token.Text, "#") && len(token.Text) > 1 {
+		return p.condition(token, "tag", ":", token.Text[1:])
+	}
+
+	m := condition.FindStringSubmatch(token.Text)
+	if m == nil {
+		return p.term(token)
+	}
+	return p.condition(token, strings.ToLower(m[1]), m[2], m[3])
+}
+
+func (p *parser) term(token Token) error {
+	if len(p.query.Terms) == MaxTerms {
+		return &SyntaxError{Pos: token.Pos, Msg: fmt.Sprintf("too many search words, at most %d; quote phrases to search for them as a whole", MaxTerms)}
+	}
+	p.query.Terms = append(p.query.Terms, token.Text)
+	return nil
+}
+
+func (p *parser) condition(token Token, field, op, value string) error {
+	fail := func(format string, args ...interface{}) error {
+		return &SyntaxError{Pos: token.Pos, Msg: fmt.Sprintf(format, args...)}
+	}
+
+	known := false
+	for _, name := range fields {
+		known = known || name == field
+	}
+	if !known {
+		return fail("unknown field %q, supported: %s; put text in double quotes to search for it as is",
+			field, strings.Join(fields, ", "))
+	}
+	if value == "" {
+		return fail("%s needs a value, e.g. %s", field, example(field))
+	}
+	if op != ":" && field != "due" {
+		return fail("%s supports only \":\", e.g. %s", field, example(field))
+	}
+
+	switch field {
+	case "status":
+		status := models.Status(strings.ToLower(value))
+		if status != models.StatusPending && status != models.StatusInProgress && status != models.StatusDone {
+			return fail("invalid status %q, supported: pending, in_progress, done", value)
+		}
+		if p.query.Status != "" {
+			return fail("status is given twice")
+		}
+		p.query.Status = status
+	case "priority":
+		priority := models.Priority(strings.ToLower(value))
+		if priority != models.PriorityLow && priority != models.PriorityMedium && priority != models.PriorityHigh {
+			return fail("invalid priority %q, supported: low, medium, high", value)
+		}
+		if p.query.Priority != "" {
+			return fail("priority is given twice")
+		}
+		p.query.Priority = priority
+	case "tag":
+		if p.query.Tag != "" {
+			return fail("tag is given twice, a task list can be filtered by one tag")
+		}
+		p.query.Tag = strings.ToLower(value)
+	case "project":
+		if p.query.ProjectID != "" {
+			return fail("project is given twice")
+		}
+		p.query.ProjectID = value
+	case "is":
+		switch strings.ToLower(value) {
+		case "open":
+			p.query.Open = true
+		case "overdue":
+			p.query.Open = true
+			return p.dueBound(fail, "<", p.now)
+		default:
+			return fail("invalid value %q for is, supported: open, overdue", value)
+		}
+	case "due":
+		day, ok := p.date(value)
+		if !ok {
+			return fail("invalid date %q for due, use YYYY-MM-DD, today, tomorrow or yesterday", value)
+		}
+		if op == ":" {
+			if err := p.dueBound(fail, ">=", day); err != nil {
+				return err
+			}
+			return p.dueBound(fail, "<", day.AddDate(0, 0, 1))
+		}
+		return p.dueBound(fail, op, day)
+	}
+
+	return nil
+}
+
+// dueBound добавляет границу срока; границы "<=" и ">" относятся к целому дню at
+func (p *parser) dueBound(fail func(string, ...interface{}) error, op string, at time.Time) error {
+	switch op {
+	case "<=":
+		op, at = "<", at.AddDate(0, 0, 1)
+	case ">":
+		op, at = ">=", at.AddDate(0, 0, 1)
+	}
+
+	bound := &p.query.DueBefore
+	if op == ">=" {
+		bound = &p.query.DueFrom
+	}
+	if *bound != nil {
+		return fail("due date bound is given twice")
+	}
+	*bound = &at
+
+	if p.query.DueFrom != nil && p.query.DueBefore != nil && !p.query.DueFrom.Before(*p.query.DueBefore) {
+		return fail("due date range is empty")
+	}
+	return nil
+}
+
+// date начало дня в часовом поясе now
+func (p *parser) date(value string) (time.Time, bool) {
+	today := time.Date(p.now.Year(), p.now.Month(), p.now.Day(), 0, 0, 0, 0, p.now.Location())
+	switch strings.ToLower(value) {
+	case "today":
+		return today, true
+	case "tomorrow":
+		return today.AddDate(0, 0, 1), true
+	case "yesterday":
+		return today.AddDate(0, 0, -1), true
+	}
+
+	if !isoDate.MatchString(value) {
+		return time.Time{}, false
+	}
+	day, err := time.ParseInLocation("2006-01-02", value, p.now.Location())
+	if err != nil {
+		return time.Time{}, false
+	}
+	return day, true
+}
+
+// example пример условия для сообщения об ошибке
+func example(field string) string {
+	switch field {
+	case "status":
+		return "status:done"
+	case "priority":
+		return "priority:high"
+	case "tag":
+		return "tag:work"
+	case "project":
+		return "project:6f1c0d2e-5b7a-4c3d-9e8f-1a2b3c4d5e6f"
+	case "is":
+		return "is:open"
+	default:
+		return "due<2024-07-01"
+	}
+}
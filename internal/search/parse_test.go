@@ -0,0 +1,126 @@
+package search
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	loc := time.FixedZone("UTC+3", 3*60*60)
+	now := time.Date(2024, 6, 12, 15, 0, 0, 0, loc)
+	day := func(month time.Month, d int) *time.Time {
+		at := time.Date(2024, month, d, 0, 0, 0, 0, loc)
+		return &at
+	}
+
+	for _, tc := range []struct {
+		query string
+		want  Query
+	}{
+		{`status:done priority:high due<2024-07-01 "quarterly report"`, Query{
+			Status: models.StatusDone, Priority: models.PriorityHigh, DueBefore: day(7, 1), Terms: []string{"quarterly report"},
+		}},
+		{`Status:IN_PROGRESS #Work budget`, Query{Status: models.StatusInProgress, Tag: "work", Terms: []string{"budget"}}},
+		{`due:2024-06-20`, Query{DueFrom: day(6, 20), DueBefore: day(6, 21)}},
+		{`due>today due<=2024-06-30`, Query{DueFrom: day(6, 13), DueBefore: day(7, 1)}},
+		{`is:overdue tag:home`, Query{Open: true, DueBefore: &now, Tag: "home"}},
+		// текст в кавычках не разбирается как условие
+		{`"status:done" is:open`, Query{Open: true, Terms: []string{"status:done"}}},
+		{``, Query{}},
+	} {
+		t.Run(tc.query, func(t *testing.T) {
+			got, err := Parse(tc.query, now)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	now := time.Date(2024, 6, 12, 15, 0, 0, 0, time.UTC)
+
+	for _, tc := range []struct {
+		query string
+		pos   int
+		msg   string
+	}{
+		{`report stat:done`, 8, `unknown field "stat", supported: status, priority, tag, project, is, due`},
+		{`status:finished`, 1, `invalid status "finished"`},
+		{`priority>high`, 1, `priority supports only ":"`},
+		{`due<07/01/2024`, 1, `use YYYY-MM-DD`},
+		{`due<2024-02-30`, 1, `invalid date`},
+		{`status: done`, 1, `status needs a value, e.g. status:done`},
+		{`status:done status:pending`, 13, `status is given twice`},
+		{`due>2024-07-01 due<2024-07-01`, 16, `due date range is empty`},
+		{`due:today due<2024-07-01`, 11, `due date bound is given twice`},
+		{`is:closed`, 1, `supported: open, overdue`},
+		{`"quarterly report`, 1, `unterminated quote`},
+		{strings.Repeat("a ", 11), 21, `too many search words`},
+	} {
+		t.Run(tc.query, func(t *testing.T) {
+			_, err := Parse(tc.query, now)
+			var syntaxErr *SyntaxError
+			require.ErrorAs(t, err, &syntaxErr)
+			assert.Equal(t, tc.pos, syntaxErr.Pos)
+			assert.Contains(t, syntaxErr.Msg, tc.msg)
+		})
+	}
+
+	_, err := Parse(strings.Repeat("a", MaxQueryLength+1), now)
+	assert.Error(t, err)
+}
+
+func TestQueryApply(t *testing.T) {
+	query, err := Parse(`status:done budget`, time.Now())
+	require.NoError(t, err)
+
+	filters := models.TaskFilters{UserID: "user1", Status: models.StatusPending, Priority: models.PriorityLow}
+	query.Apply(&filters)
+	assert.Equal(t, models.TaskFilters{
+		UserID: "user1", Status: models.StatusDone, Priority: models.PriorityLow, Terms: []string{"budget"},
+	}, filters)
+}
+
+func TestQueryMatch(t *testing.T) {
+	now := time.Date(2024, 6, 12, 15, 0, 0, 0, time.UTC)
+	project := "p1"
+	task := models.Task{
+		Title:       "Quarterly Report",
+		Description: "numbers for the budget",
+		Status:      models.StatusInProgress,
+		Priority:    models.PriorityHigh,
+		Tags:        []string{"work"},
+		ProjectID:   &project,
+		DueDate:     now.Add(-time.Hour),
+	}
+
+	for query, want := range map[string]bool{
+		`report budget`:                 true,
+		`"quarterly report" #work`:      true,
+		`is:overdue priority:high`:      true,
+		`due:today project:p1`:          true,
+		`status:done`:                   false,
+		`tag:home`:                      false,
+		`due>today`:                     false,
+		`report invoice`:                false,
+		`project:p2 status:in_progress`: false,
+	} {
+		parsed, err := Parse(query, now)
+		require.NoError(t, err)
+		assert.Equal(t, want, parsed.Match(task), query)
+	}
+
+	// приватная задача не подходит под текстовые части, но подходит под условия
+	task.Private = true
+	parsed, err := Parse(`report`, now)
+	require.NoError(t, err)
+	assert.False(t, parsed.Match(task))
+	parsed, err = Parse(`#work`, now)
+	require.NoError(t, err)
+	assert.True(t, parsed.Match(task))
+}
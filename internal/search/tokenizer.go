@@ -0,0 +1,69 @@
+// Package search язык запросов поиска задач, например
+// `status:done priority:high due<2024-07-01 "quarterly report"`: условия на поля и текст для поиска
+package search
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// SyntaxError ошибка в запросе. Pos — номер символа (с 1), с которого начинается ошибочная часть
+type SyntaxError struct {
+	Pos int
+	Msg string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("at column %d: %s", e.Pos, e.Msg)
+}
+
+// Token слово запроса
+type Token struct {
+	// Text слово без кавычек
+	Text string
+	// Pos номер первого символа слова в запросе, с 1
+	Pos int
+	// Quoted текст из двойных кавычек: всегда ищется как есть, условием не бывает
+	Quoted bool
+}
+
+// Tokenize делит запрос на слова по пробелам. Текст в двойных кавычках — одно слово;
+// незакрытая кавычка — ошибка
+func Tokenize(query string) ([]Token, error) {
+	var tokens []Token
+	var word strings.Builder
+	start, quoteStart := 0, 0
+	quoted := false
+
+	flush := func() {
+		if word.Len() > 0 {
+			tokens = append(tokens, Token{Text: word.String(), Pos: start, Quoted: quoted})
+			word.Reset()
+		}
+	}
+
+	pos := 0
+	for _, r := range query {
+		pos++
+		switch {
+		case r == '"':
+			flush()
+			quoted = !quoted
+			quoteStart, start = pos, pos+1
+		case unicode.IsSpace(r) && !quoted:
+			flush()
+		default:
+			if word.Len() == 0 && !quoted {
+				start = pos
+			}
+			word.WriteRune(r)
+		}
+	}
+	if quoted {
+		return nil, &SyntaxError{Pos: quoteStart, Msg: "unterminated quote, close it with \""}
+	}
+	flush()
+
+	return tokens, nil
+}
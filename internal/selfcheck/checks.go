@@ -0,0 +1,213 @@
+package selfcheck
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/jmoloko/taskmange/internal/config"
+	"github.com/lib/pq"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// задержка Redis, выше которой проверка предупреждает
+	redisLatencyWarn = 50 * time.Millisecond
+	// расхождение часов с БД, выше которого проверка предупреждает
+	clockSkewWarn = time.Second
+	// расхождение, при котором ломается выборка измененных задач для пересчета аналитики
+	clockSkewFail = time.Minute
+)
+
+// имя файла миграции: 001_init.sql
+var migrationFile = regexp.MustCompile(`^(\d{3})_.+\.sql$`)
+
+// ConfigCheck проверяет значения конфигурации
+func ConfigCheck(cfg *config.Config) Check {
+	return Check{
+		Name: "config",
+		Run: func(ctx context.Context) (Status, string) {
+			if err := cfg.Validate(); err != nil {
+				return StatusFail, err.Error()
+			}
+			if cfg.Auth.SigningKey == config.DefaultSigningKey {
+				return StatusWarn, "JWT_SECRET is not set, the default signing key is used"
+			}
+			return StatusOK, ""
+		},
+	}
+}
+
+// SchemaCheck сравнивает версию схемы в БД с последней миграцией в каталоге dir.
+// Версию записывает entrypoint после применения миграций
+func SchemaCheck(db *sql.DB, dir string) Check {
+	return Check{
+		Name: "schema_version",
+		Run: func(ctx context.Context) (Status, string) {
+			var version int
+			err := db.QueryRowContext(ctx, `SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&version)
+			if err != nil {
+				var pqErr *pq.Error
+				if errors.As(err, &pqErr) && pqErr.Code == "42P01" {
+					return StatusWarn, "schema version is not recorded, migrations were applied without the entrypoint"
+				}
+				return StatusFail, fmt.Sprintf("failed to get schema version: %v", err)
+			}
+
+			expected, err := LatestMigration(dir)
+			if err != nil {
+				return StatusWarn, fmt.Sprintf("schema version %d, migrations are not available: %v", version, err)
+			}
+
+			switch {
+			case version < expected:
+				return StatusFail, fmt.Sprintf("schema version %d is behind migration %d", version, expected)
+			case version > expected:
+				return StatusWarn, fmt.Sprintf("schema version %d is ahead of migration %d, the binary may be outdated", version, expected)
+			}
+			return StatusOK, fmt.Sprintf("schema version %d", version)
+		},
+	}
+}
+
+// LatestMigration номер последней миграции в каталоге dir
+func LatestMigration(dir string) (int, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return 0, err
+	}
+
+	latest := 0
+	for _, entry := range entries {
+		match := migrationFile.FindStringSubmatch(entry.Name())
+		if match == nil {
+			continue
+		}
+		if n, _ := strconv.Atoi(match[1]); n > latest {
+			latest = n
+		}
+	}
+
+	if latest == 0 {
+		return 0, fmt.Errorf("no migrations in %s", dir)
+	}
+
+	return latest, nil
+}
+
+// RedisCheck проверяет доступность и задержку Redis
+func RedisCheck(client *redis.Client) Check {
+	return Check{
+		Name: "redis",
+		Run: func(ctx context.Context) (Status, string) {
+			started := time.Now()
+			if err := client.Ping(ctx).Err(); err != nil {
+				return StatusFail, err.Error()
+			}
+
+			latency := time.Since(started)
+			message := fmt.Sprintf("latency %s", latency.Round(time.Microsecond))
+			if latency > redisLatencyWarn {
+				return StatusWarn, message
+			}
+			return StatusOK, message
+		},
+	}
+}
+
+// Optional проверка необязательной зависимости: провал проверки, в том числе по таймауту, — предупреждение
+func Optional(check Check) Check {
+	check.Optional = true
+	return check
+}
+
+// DatabaseCheck проверяет доступность Postgres
+func DatabaseCheck(db *sql.DB) Check {
+	return Check{
+		Name: "database",
+		Run: func(ctx context.Context) (Status, string) {
+			if err := db.PingContext(ctx); err != nil {
+				return StatusFail, err.Error()
+			}
+			return StatusOK, ""
+		},
+	}
+}
+
+// PartitionCheck сообщает, секционирована ли таблица задач; partitions — определение по репозиторию
+func PartitionCheck(partitions func(ctx context.Context) (int, error)) Check {
+	return Check{
+		Name: "tasks_partitioning",
+		Run: func(ctx context.Context) (Status, string) {
+			count, err := partitions(ctx)
+			if err != nil {
+				return StatusWarn, err.Error()
+			}
+			if count == 0 {
+				return StatusOK, "tasks table is not partitioned"
+			}
+			return StatusOK, fmt.Sprintf("tasks table is hash-partitioned by user_id into %d partitions", count)
+		},
+	}
+}
+
+// ClockSkewCheck сравнивает часы приложения с часами БД.
+// Время запроса вычитается: берется середина между отправкой запроса и получением ответа
+func ClockSkewCheck(db *sql.DB) Check {
+	return Check{
+		Name: "clock_skew",
+		Run: func(ctx context.Context) (Status, string) {
+			sent := time.Now()
+			var dbNow time.Time
+			if err := db.QueryRowContext(ctx, `SELECT NOW()`).Scan(&dbNow); err != nil {
+				return StatusFail, fmt.Sprintf("failed to get database time: %v", err)
+			}
+			received := time.Now()
+
+			local := sent.Add(received.Sub(sent) / 2)
+			return skewStatus(local.Sub(dbNow))
+		},
+	}
+}
+
+func skewStatus(skew time.Duration) (Status, string) {
+	message := fmt.Sprintf("application clock is %s ahead of the database", skew.Round(time.Millisecond))
+	if skew < 0 {
+		skew = -skew
+		message = fmt.Sprintf("application clock is %s behind the database", skew.Round(time.Millisecond))
+	}
+
+	switch {
+	case skew > clockSkewFail:
+		return StatusFail, message
+	case skew > clockSkewWarn:
+		return StatusWarn, message
+	}
+	return StatusOK, message
+}
+
+// LogFileCheck проверяет, что в файл лога можно писать. Без файла лог пишется только в stdout
+func LogFileCheck(path string) Check {
+	return Check{
+		Name: "log_file",
+		Run: func(ctx context.Context) (Status, string) {
+			if path == "" {
+				return StatusOK, "logging to stdout"
+			}
+
+			file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
+			if err != nil {
+				return StatusFail, fmt.Sprintf("log file is not writable, logging to stdout only: %v", err)
+			}
+			file.Close()
+
+			return StatusOK, filepath.Clean(path)
+		},
+	}
+}
@@ -0,0 +1,167 @@
+package selfcheck
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// время на выполнение одной проверки
+const checkTimeout = 5 * time.Second
+
+// Status результат проверки
+type Status string
+
+const (
+	StatusOK   Status = "ok"
+	StatusWarn Status = "warn"
+	StatusFail Status = "fail"
+)
+
+// Check проверка окружения. Run возвращает статус и пояснение для отчета
+type Check struct {
+	Name string
+	Run  func(ctx context.Context) (Status, string)
+	// Optional провал проверки понижается до предупреждения: без зависимости сервис работает хуже, но работает
+	Optional bool
+}
+
+// Result результат одной проверки
+type Result struct {
+	Name       string `json:"name"`
+	Status     Status `json:"status"`
+	Message    string `json:"message,omitempty"`
+	DurationMs int64  `json:"duration_ms"`
+}
+
+// Report отчет проверок, Status — худший из статусов проверок
+type Report struct {
+	Status    Status    `json:"status"`
+	CheckedAt time.Time `json:"checked_at"`
+	Checks    []Result  `json:"checks"`
+}
+
+// Run выполняет проверки одновременно, каждая ограничена checkTimeout
+func Run(ctx context.Context, checks []Check) Report {
+	return RunTimeout(ctx, checks, checkTimeout)
+}
+
+// RunTimeout выполняет проверки одновременно, каждая ограничена timeout, поэтому отчет
+// готов не позже чем через timeout. Порядок результатов совпадает с порядком проверок
+func RunTimeout(ctx context.Context, checks []Check, timeout time.Duration) Report {
+	report := Report{
+		Status:    StatusOK,
+		CheckedAt: time.Now(),
+		Checks:    make([]Result, len(checks)),
+	}
+
+	var wg sync.WaitGroup
+	for i, check := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			report.Checks[i] = runCheck(ctx, check, timeout)
+		}()
+	}
+	wg.Wait()
+
+	for _, result := range report.Checks {
+		if severity(result.Status) > severity(report.Status) {
+			report.Status = result.Status
+		}
+	}
+
+	return report
+}
+
+// runCheck выполняет проверку; не уложившаяся в timeout проверка считается проваленной,
+// даже если Run не следит за отменой контекста
+func runCheck(ctx context.Context, check Check, timeout time.Duration) Result {
+	checkCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	type outcome struct {
+		status  Status
+		message string
+	}
+	done := make(chan outcome, 1)
+	started := time.Now()
+	go func() {
+		status, message := check.Run(checkCtx)
+		done <- outcome{status, message}
+	}()
+
+	result := Result{Name: check.Name}
+	select {
+	case out := <-done:
+		result.Status, result.Message = out.status, out.message
+	case <-checkCtx.Done():
+		result.Status, result.Message = StatusFail, fmt.Sprintf("check timed out after %s", timeout)
+	}
+	if check.Optional && result.Status == StatusFail {
+		result.Status = StatusWarn
+	}
+	result.DurationMs = time.Since(started).Milliseconds()
+	return result
+}
+
+func severity(status Status) int {
+	switch status {
+	case StatusWarn:
+		return 1
+	case StatusFail:
+		return 2
+	default:
+		return 0
+	}
+}
+
+// Checker хранит отчет проверки при запуске и выполняет проверки готовности по запросу
+type Checker struct {
+	startup   []Check
+	readiness []Check
+	// timeout время на одну проверку готовности
+	timeout time.Duration
+
+	mu     sync.RWMutex
+	report *Report
+}
+
+// NewChecker создает новый экземпляр Checker.
+// startup выполняются один раз при запуске, readiness — при каждом запросе /readyz
+// не дольше timeout каждая; timeout 0 — checkTimeout
+func NewChecker(startup, readiness []Check, timeout time.Duration) *Checker {
+	if timeout <= 0 {
+		timeout = checkTimeout
+	}
+	return &Checker{
+		startup:   startup,
+		readiness: readiness,
+		timeout:   timeout,
+	}
+}
+
+// Startup выполняет проверки запуска и сохраняет отчет
+func (c *Checker) Startup(ctx context.Context) Report {
+	report := Run(ctx, c.startup)
+
+	c.mu.Lock()
+	c.report = &report
+	c.mu.Unlock()
+
+	return report
+}
+
+// StartupReport отчет проверки при запуске, nil — проверка еще не выполнялась
+func (c *Checker) StartupReport() *Report {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.report
+}
+
+// Ready выполняет проверки готовности
+func (c *Checker) Ready(ctx context.Context) Report {
+	return RunTimeout(ctx, c.readiness, c.timeout)
+}
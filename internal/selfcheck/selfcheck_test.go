@@ -0,0 +1,141 @@
+package selfcheck
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jmoloko/taskmange/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func static(name string, status Status) Check {
+	return Check{Name: name, Run: func(ctx context.Context) (Status, string) { return status, name }}
+}
+
+func TestRun_WorstStatus(t *testing.T) {
+	report := Run(context.Background(), []Check{static("a", StatusOK), static("b", StatusWarn), static("c", StatusOK)})
+	assert.Equal(t, StatusWarn, report.Status)
+	require.Len(t, report.Checks, 3)
+	assert.Equal(t, "b", report.Checks[1].Name)
+
+	report = Run(context.Background(), []Check{static("a", StatusFail), static("b", StatusWarn)})
+	assert.Equal(t, StatusFail, report.Status)
+
+	assert.Equal(t, StatusOK, Run(context.Background(), nil).Status)
+}
+
+func TestChecker(t *testing.T) {
+	checker := NewChecker([]Check{static("config", StatusWarn)}, []Check{static("redis", StatusOK)}, 0)
+	assert.Nil(t, checker.StartupReport())
+
+	checker.Startup(context.Background())
+	require.NotNil(t, checker.StartupReport())
+	assert.Equal(t, StatusWarn, checker.StartupReport().Status)
+	assert.Equal(t, StatusOK, checker.Ready(context.Background()).Status)
+}
+
+func TestRunTimeout(t *testing.T) {
+	// проверка, не следящая за контекстом, не задерживает отчет дольше timeout
+	hanging := Check{Name: "redis", Run: func(ctx context.Context) (Status, string) {
+		time.Sleep(time.Second)
+		return StatusOK, ""
+	}}
+	respectful := Check{Name: "database", Run: func(ctx context.Context) (Status, string) {
+		<-ctx.Done()
+		return StatusFail, ctx.Err().Error()
+	}}
+
+	started := time.Now()
+	report := RunTimeout(context.Background(), []Check{hanging, respectful, static("config", StatusOK)}, 50*time.Millisecond)
+	assert.Less(t, time.Since(started), 500*time.Millisecond)
+
+	assert.Equal(t, StatusFail, report.Status)
+	require.Len(t, report.Checks, 3)
+	assert.Equal(t, "redis", report.Checks[0].Name)
+	assert.Equal(t, StatusFail, report.Checks[0].Status)
+	assert.Contains(t, report.Checks[0].Message, "timed out")
+	assert.Equal(t, StatusFail, report.Checks[1].Status)
+	assert.Equal(t, StatusOK, report.Checks[2].Status)
+
+	// провал необязательной зависимости не делает сервис неготовым
+	report = RunTimeout(context.Background(), []Check{Optional(hanging), static("database", StatusOK)}, 50*time.Millisecond)
+	assert.Equal(t, StatusWarn, report.Status)
+	assert.Equal(t, StatusWarn, report.Checks[0].Status)
+}
+
+func TestLatestMigration(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"001_init.sql", "015_schema_migrations.sql", "009_analytics.sql", "000001_init.up.sql", "README.md"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), nil, 0644))
+	}
+
+	latest, err := LatestMigration(dir)
+	require.NoError(t, err)
+	assert.Equal(t, 15, latest)
+
+	_, err = LatestMigration(t.TempDir())
+	assert.Error(t, err)
+	_, err = LatestMigration(filepath.Join(dir, "missing"))
+	assert.Error(t, err)
+}
+
+func TestSkewStatus(t *testing.T) {
+	status, _ := skewStatus(200 * time.Millisecond)
+	assert.Equal(t, StatusOK, status)
+
+	status, message := skewStatus(-3 * time.Second)
+	assert.Equal(t, StatusWarn, status)
+	assert.Contains(t, message, "behind")
+
+	status, _ = skewStatus(2 * time.Minute)
+	assert.Equal(t, StatusFail, status)
+}
+
+func TestLogFileCheck(t *testing.T) {
+	status, _ := LogFileCheck("").Run(context.Background())
+	assert.Equal(t, StatusOK, status)
+
+	status, _ = LogFileCheck(filepath.Join(t.TempDir(), "app.log")).Run(context.Background())
+	assert.Equal(t, StatusOK, status)
+
+	status, _ = LogFileCheck(filepath.Join(t.TempDir(), "missing", "app.log")).Run(context.Background())
+	assert.Equal(t, StatusFail, status)
+}
+
+func TestConfigCheck(t *testing.T) {
+	cfg, err := config.Load()
+	require.NoError(t, err)
+	cfg.Auth.SigningKey = "secret"
+	cfg.Logger.Level = "info"
+	cfg.Logger.Format = "text"
+
+	status, _ := ConfigCheck(cfg).Run(context.Background())
+	assert.Equal(t, StatusOK, status)
+
+	cfg.Auth.SigningKey = config.DefaultSigningKey
+	status, _ = ConfigCheck(cfg).Run(context.Background())
+	assert.Equal(t, StatusWarn, status)
+
+	cfg.Shedding.PoolSaturation = 1.5
+	cfg.Analytics.SnapshotInterval = 0
+	status, message := ConfigCheck(cfg).Run(context.Background())
+	assert.Equal(t, StatusFail, status)
+	assert.Contains(t, message, "SHEDDING_DB_POOL_SATURATION")
+	assert.Contains(t, message, "ANALYTICS_SNAPSHOT_INTERVAL")
+}
+
+func TestPartitionCheck(t *testing.T) {
+	status, message := PartitionCheck(func(ctx context.Context) (int, error) { return 0, nil }).Run(context.Background())
+	assert.Equal(t, StatusOK, status)
+	assert.Equal(t, "tasks table is not partitioned", message)
+
+	_, message = PartitionCheck(func(ctx context.Context) (int, error) { return 16, nil }).Run(context.Background())
+	assert.Contains(t, message, "16 partitions")
+
+	status, _ = PartitionCheck(func(ctx context.Context) (int, error) { return 0, assert.AnError }).Run(context.Background())
+	assert.Equal(t, StatusWarn, status)
+}
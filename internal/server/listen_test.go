@@ -0,0 +1,54 @@
+package server
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServerListen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "taskmanager.sock")
+	// файл сокета от прошлого запуска не мешает открыть новый
+	require.NoError(t, os.WriteFile(path, nil, 0o600))
+
+	s := &Server{socketMode: 0o600}
+	listener, err := s.listen("unix:" + path)
+	require.NoError(t, err)
+	defer listener.Close()
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+	assert.NotZero(t, info.Mode()&os.ModeSocket)
+
+	srv := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "ok")
+	})}
+	go srv.Serve(listener)
+	defer srv.Close()
+
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", path)
+		},
+	}}
+	resp, err := client.Get("http://taskmanager/readyz")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	assert.Equal(t, "ok", string(body))
+
+	tcp, err := s.listen("127.0.0.1:0")
+	require.NoError(t, err)
+	tcp.Close()
+
+	_, err = s.listen("unix:" + filepath.Join(t.TempDir(), "missing", "app.sock"))
+	assert.Error(t, err)
+}
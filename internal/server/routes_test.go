@@ -0,0 +1,83 @@
+package server
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoloko/taskmange/internal/config"
+	"github.com/jmoloko/taskmange/internal/handler"
+	"github.com/jmoloko/taskmange/internal/middleware"
+	"github.com/stretchr/testify/assert"
+)
+
+// coreRoutes маршруты, которые не отключаются: пробы, авторизация, задачи и их представления.
+// Новый маршрут должен попасть либо сюда, либо в группу featureRoutes
+var coreRoutes = map[string]bool{
+	"/healthz":                           true,
+	"/readyz":                            true,
+	"/api/auth/register":                 true,
+	"/api/auth/login":                    true,
+	"/api/auth/me":                       true,
+	"/api/auth/password":                 true,
+	"/api/tasks":                         true,
+	"/api/tasks/:id":                     true,
+	"/api/tasks/:id/unlock":              true,
+	"/api/tasks/:id/related":             true,
+	"/api/tasks/:id/related/:related_id": true,
+	"/api/tasks/:id/subtasks":            true,
+	"/api/tasks/:id/assignee":            true,
+	"/api/tasks/complete":                true,
+	"/api/tasks/dashboard":               true,
+	"/api/tasks/today":                   true,
+	"/api/tasks/upcoming":                true,
+	"/api/tasks/matrix":                  true,
+	"/api/tasks/matrix/settings":         true,
+	"/api/users/me/transfers":            true,
+}
+
+// TestFeatureRoutes каждый маршрут сервера относится к отключаемой группе или к ядру,
+// каждая группа известна конфигурации и закрывает хотя бы один маршрут
+func TestFeatureRoutes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	handlers := &handler.Handler{Auth: &handler.AuthHandler{}, User: &handler.UserHandler{}, Calendar: &handler.CalendarHandler{}}
+	s := NewServer(&config.Config{}, handlers, middleware.NewLoadShedder(0, 0, nil), nil, nil, nil, nil)
+	routes := s.httpServer.Handler.(*gin.Engine).Routes()
+
+	inGroup := func(route string, prefixes []string) bool {
+		for _, prefix := range prefixes {
+			if route == prefix || strings.HasPrefix(route, prefix+"/") {
+				return true
+			}
+		}
+		return false
+	}
+
+	covered := make(map[string]bool)
+	for _, route := range routes {
+		if coreRoutes[route.Path] {
+			continue
+		}
+		var groups []string
+		for feature, prefixes := range featureRoutes {
+			if inGroup(route.Path, prefixes) {
+				groups = append(groups, feature)
+				covered[feature] = true
+			}
+		}
+		assert.NotEmpty(t, groups, "%s %s is neither a core route nor in featureRoutes", route.Method, route.Path)
+	}
+
+	features := make([]string, 0, len(featureRoutes))
+	for feature := range featureRoutes {
+		assert.True(t, covered[feature], "feature %q matches no route", feature)
+		features = append(features, feature)
+	}
+	sort.Strings(features)
+
+	// названия групп принимает DISABLED_FEATURES
+	cfg := config.Config{Features: config.FeaturesConfig{Disabled: features}}
+	assert.NotContains(t, fmt.Sprint(cfg.Validate()), "DISABLED_FEATURES")
+}
@@ -2,16 +2,22 @@ package server
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"net"
 	"net/http"
+	"os"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/jmoloko/taskmange/internal/config"
+	"github.com/jmoloko/taskmange/internal/domain/models"
 	"github.com/jmoloko/taskmange/internal/handler"
 	"github.com/jmoloko/taskmange/internal/logger"
 	"github.com/jmoloko/taskmange/internal/metrics"
 	"github.com/jmoloko/taskmange/internal/middleware"
+	"github.com/jmoloko/taskmange/web"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
@@ -20,21 +26,39 @@ import (
 type Server struct {
 	httpServer    *http.Server
 	metricsServer *http.Server
+	// addresses адреса HTTP-сервера, см. config.ServerConfig.Addresses
+	addresses  []string
+	socketMode os.FileMode
 }
 
 // NewServer новый экземпляр сервера
-func NewServer(cfg *config.Config, handlers *handler.Handler, logger logger.Logger) *Server {
+func NewServer(cfg *config.Config, handlers *handler.Handler, shedder *middleware.LoadShedder, usage middleware.UsageRecorder, audit middleware.AuditRecorder, idempotency middleware.IdempotencyStore, logger logger.Logger) *Server {
 	router := gin.New()
 
+	router.Use(middleware.RequestIDMiddleware(logger))
+	if cfg.Tracing.Endpoint != "" {
+		router.Use(middleware.TracingMiddleware(logger))
+	}
 	router.Use(middleware.LoggerMiddleware(logger))
 	router.Use(middleware.CORSMiddleware())
 	router.Use(middleware.RecoveryMiddleware(logger))
+	if cfg.Server.TimingEnabled {
+		router.Use(middleware.ServerTimingMiddleware())
+	}
 
 	// отдельный маршрутизатор для метрик
 	metricsRouter := gin.New()
 	metricsRouter.GET("/metrics", gin.WrapH(promhttp.HandlerFor(metrics.Registry, promhttp.HandlerOpts{})))
+	// пробы Kubernetes можно направить на порт метрик, не открывая основной
+	metricsRouter.GET("/healthz", handlers.Health.Live)
+	metricsRouter.GET("/readyz", handlers.Health.Ready)
 
 	router.Use(middleware.MetricsMiddleware())
+	router.Use(middleware.DisabledRoutesMiddleware(disabledRoutes(cfg.Features)))
+	router.Use(shedder.Observe())
+	router.Use(middleware.UsageMiddleware(usage))
+	router.Use(middleware.AuditMiddleware(audit))
+	router.Use(middleware.WarningsMiddleware())
 
 	// документация Swagger
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler,
@@ -44,32 +68,233 @@ func NewServer(cfg *config.Config, handlers *handler.Handler, logger logger.Logg
 	// статические файлы Swagger
 	router.Static("/docs", "./docs")
 
+	// живость процесса и готовность зависимостей, verbose=1 добавляет отчет проверки при запуске
+	router.GET("/healthz", handlers.Health.Live)
+	router.GET("/readyz", handlers.Health.Ready)
+
+	// встроенный фронтенд на всех маршрутах вне API
+	if cfg.Server.SPAEnabled {
+		router.NoRoute(spaHandler(web.Dist()))
+	}
+
 	// настройка маршрутов
 	api := router.Group("/api")
+	api.Use(middleware.APIVersionMiddleware(cfg.Server.DefaultAPIVersion))
+	authenticate := middleware.AuthMiddleware(handlers.Auth.GetService(), handlers.User.GetService())
 	{
 		auth := api.Group("/auth")
 		{
 			auth.POST("/register", handlers.Auth.Register)
 			auth.POST("/login", handlers.Auth.Login)
+			auth.GET("/me", authenticate, handlers.Auth.Me)
+			auth.PUT("/password", authenticate, handlers.Auth.ChangePassword)
 		}
 
+		// тяжелые эндпоинты ограничены по числу одновременных запросов, чтобы не перегружать Postgres
+		limit := func(name string) gin.HandlerFunc {
+			return middleware.ConcurrencyLimitMiddleware(name,
+				cfg.Concurrency.Limit, cfg.Concurrency.QueueDepth, cfg.Concurrency.QueueTimeout)
+		}
+		importLimit := limit("import")
+		exportLimit := limit("export")
+		analyticsLimit := limit("analytics")
+		// запросы к модели долгие, их число ограничено так же, чтобы не исчерпать квоту API
+		aiLimit := limit("ai")
+		// повтор создания с тем же Idempotency-Key возвращает первый ответ
+		idempotent := middleware.IdempotencyMiddleware(idempotency, cfg.Idempotency.TTL, cfg.Idempotency.LockTimeout, cfg.Idempotency.MaxBodyBytes, logger)
+		moved := func(target string) gin.HandlerFunc {
+			return middleware.MovedRouteMiddleware(target, middleware.APIVersion4)
+		}
+
+		// календари подписываются на ленту по ссылке с токеном, заголовок Authorization они не передают
+		api.GET("/tasks/calendar.ics", middleware.CalendarTokenMiddleware(handlers.Calendar.GetService(), handlers.User.GetService(), authenticate), handlers.Calendar.GetCalendar)
+
 		tasks := api.Group("/tasks")
-		tasks.Use(middleware.AuthMiddleware(handlers.Auth.GetService()))
+		tasks.Use(authenticate)
 		{
-			tasks.POST("", handlers.Task.CreateTask)
+			tasks.POST("", idempotent, handlers.Task.CreateTask)
 			tasks.GET("", handlers.Task.GetTasks)
+			tasks.DELETE("", shedder.Shed("bulk_delete"), limit("bulk_delete"), handlers.Task.DeleteTasks)
+			tasks.GET("/events", middleware.StreamMiddleware(), handlers.Events.StreamTaskEvents)
+			tasks.POST("/complete", handlers.Task.CompleteTasks)
+			tasks.POST("/quick-add", handlers.QuickAdd.QuickAdd)
+			tasks.POST("/calendar/token", handlers.Calendar.CreateCalendarFeed)
+			tasks.DELETE("/calendar/token", handlers.Calendar.DeleteCalendarFeed)
 			tasks.GET("/:id", handlers.Task.GetTask)
+			tasks.POST("/:id/unlock", handlers.Task.UnlockTask)
+			tasks.POST("/:id/related", handlers.Task.RelateTask)
+			tasks.DELETE("/:id/related/:related_id", handlers.Task.UnrelateTask)
+			tasks.GET("/:id/subtasks", handlers.Task.GetSubtasks)
+			tasks.PUT("/:id/assignee", handlers.Task.AssignTask)
+			tasks.DELETE("/:id/assignee", handlers.Task.UnassignTask)
+			tasks.POST("/:id/summarize", aiLimit, handlers.AI.SummarizeTask)
+			tasks.POST("/:id/suggest-subtasks", aiLimit, handlers.AI.SuggestSubtasks)
+			tasks.GET("/:id/similar", aiLimit, handlers.AI.SimilarTasks)
+			tasks.GET("/:id/external", handlers.External.ListExternalRefs)
+			tasks.POST("/:id/external", handlers.External.LinkExternal)
+			tasks.DELETE("/:id/external/:ref_id", handlers.External.UnlinkExternal)
 			tasks.PUT("/:id", handlers.Task.UpdateTask)
+			tasks.PATCH("/:id", handlers.Task.PatchTask)
 			tasks.DELETE("/:id", handlers.Task.DeleteTask)
-			tasks.POST("/import", handlers.Task.ImportTasks)
-			tasks.GET("/export", handlers.Task.ExportTasks)
-			tasks.GET("/analytics", handlers.Task.GetAnalytics)
+			// прежние пути импорта, экспорта и аналитики, с API-Version: 4 перенаправляются на отдельные ресурсы
+			tasks.POST("/import", moved("/api/task-imports"), idempotent, importLimit, handlers.Task.ImportTasks)
+			tasks.POST("/import/preview", moved("/api/task-imports/preview"), importLimit, handlers.Task.PreviewImport)
+			tasks.GET("/export", moved("/api/task-exports"), shedder.Shed("export"), exportLimit, handlers.Task.ExportTasks)
+			tasks.GET("/analytics", moved("/api/task-analytics"), shedder.Shed("analytics"), analyticsLimit, handlers.Task.GetAnalytics)
+			tasks.GET("/analytics/history", moved("/api/task-analytics/history"), shedder.Shed("analytics"), analyticsLimit, handlers.Analytics.GetAnalyticsHistory)
+			tasks.GET("/dashboard", handlers.Task.GetDashboard)
+			tasks.GET("/today", handlers.View.GetToday)
+			tasks.GET("/upcoming", handlers.View.GetUpcoming)
+			tasks.GET("/matrix", handlers.View.GetMatrix)
+			tasks.GET("/matrix/settings", handlers.View.GetMatrixSettings)
+			tasks.PUT("/matrix/settings", handlers.View.UpdateMatrixSettings)
+		}
+
+		// импорт, экспорт и аналитика — отдельные ресурсы, а не сегменты /tasks, чтобы новые операции
+		// над коллекцией не делили пространство имен с /tasks/:id
+		taskImports := api.Group("/task-imports")
+		taskImports.Use(authenticate)
+		{
+			taskImports.POST("", idempotent, importLimit, handlers.Task.ImportTasks)
+			taskImports.POST("/preview", importLimit, handlers.Task.PreviewImport)
+		}
+
+		// при перегрузке экспорт и аналитика отклоняются раньше очереди, CRUD продолжает работать
+		api.GET("/task-exports", authenticate, shedder.Shed("export"), exportLimit, handlers.Task.ExportTasks)
+
+		taskAnalytics := api.Group("/task-analytics")
+		taskAnalytics.Use(authenticate)
+		{
+			taskAnalytics.GET("", shedder.Shed("analytics"), analyticsLimit, handlers.Task.GetAnalytics)
+			taskAnalytics.GET("/history", shedder.Shed("analytics"), analyticsLimit, handlers.Analytics.GetAnalyticsHistory)
+		}
+
+		// серии повторяющихся задач, экземпляры создает фоновый worker
+		recurrences := api.Group("/recurrences")
+		recurrences.Use(authenticate)
+		{
+			recurrences.GET("/:id", handlers.Task.GetRecurrence)
+			recurrences.POST("/:id/pause", handlers.Task.PauseRecurrence)
+			recurrences.POST("/:id/resume", handlers.Task.ResumeRecurrence)
 		}
+
+		users := api.Group("/users")
+		users.Use(authenticate)
+		{
+			users.GET("/me/transfers", handlers.Transfer.GetTransfers)
+		}
+
+		notifications := api.Group("/notifications")
+		notifications.Use(authenticate)
+		{
+			notifications.GET("/push/vapid-key", handlers.Notification.GetVAPIDKey)
+			notifications.POST("/push/subscriptions", handlers.Notification.Subscribe)
+			notifications.DELETE("/push/subscriptions", handlers.Notification.Unsubscribe)
+			notifications.GET("/preferences", handlers.Notification.GetPreferences)
+			notifications.PUT("/preferences", handlers.Notification.UpdatePreferences)
+			notifications.GET("/telegram", handlers.Telegram.GetTelegramLink)
+			notifications.PUT("/telegram", handlers.Telegram.LinkTelegram)
+			notifications.DELETE("/telegram", handlers.Telegram.UnlinkTelegram)
+		}
+
+		// синхронизация сроков с календарями; уведомления Google авторизуются токеном канала
+		calendars := api.Group("/integrations/calendars")
+		calendars.Use(authenticate)
+		{
+			calendars.GET("", handlers.CalendarSync.ListCalendarLinks)
+			calendars.POST("", handlers.CalendarSync.ConnectCalendar)
+			calendars.DELETE("/:id", handlers.CalendarSync.DisconnectCalendar)
+		}
+		api.POST("/integrations/calendars/google/webhook", handlers.CalendarSync.ReceiveGoogleNotification)
+
+		triggers := api.Group("/triggers")
+		triggers.Use(authenticate)
+		{
+			triggers.GET("", handlers.Trigger.ListTriggers)
+			triggers.POST("", handlers.Trigger.CreateTrigger)
+			triggers.DELETE("/:id", handlers.Trigger.DeleteTrigger)
+			triggers.POST("/:id/secret/rotate", handlers.Trigger.RotateTriggerSecret)
+		}
+
+		projects := api.Group("/projects")
+		projects.Use(authenticate)
+		{
+			projects.GET("", handlers.Project.ListProjects)
+			projects.POST("", handlers.Project.CreateProject)
+			projects.GET("/:id", handlers.Project.GetProject)
+			projects.PUT("/:id", handlers.Project.UpdateProject)
+			projects.DELETE("/:id", handlers.Project.DeleteProject)
+			projects.GET("/:id/tasks", handlers.Project.GetProjectTasks)
+			projects.GET("/:id/analytics", shedder.Shed("analytics"), analyticsLimit, handlers.Project.GetProjectAnalytics)
+		}
+
+		taggingRules := api.Group("/tagging-rules")
+		taggingRules.Use(authenticate)
+		{
+			taggingRules.GET("", handlers.Tagging.ListTaggingRules)
+			taggingRules.POST("", handlers.Tagging.CreateTaggingRule)
+			taggingRules.POST("/test", handlers.Tagging.TestTaggingRules)
+			taggingRules.DELETE("/:id", handlers.Tagging.DeleteTaggingRule)
+		}
+
+		savedSearches := api.Group("/saved-searches")
+		savedSearches.Use(authenticate)
+		{
+			savedSearches.GET("", handlers.Search.ListSavedSearches)
+			savedSearches.POST("", handlers.Search.CreateSavedSearch)
+			savedSearches.PUT("/:id", handlers.Search.UpdateSavedSearch)
+			savedSearches.DELETE("/:id", handlers.Search.DeleteSavedSearch)
+		}
+
+		// управление входящими webhook; сами запросы внешних систем авторизуются токеном в URL
+		inboundHooks := api.Group("/inbound-hooks")
+		inboundHooks.Use(authenticate)
+		{
+			inboundHooks.GET("", handlers.Hook.ListHooks)
+			inboundHooks.POST("", handlers.Hook.CreateHook)
+			inboundHooks.DELETE("/:id", handlers.Hook.DeleteHook)
+		}
+		api.POST("/hooks/:token", handlers.Hook.ReceiveHook)
+
+		// подключение репозиториев GitHub; события GitHub авторизуются подписью с секретом репозитория
+		githubRepos := api.Group("/integrations/github/repos")
+		githubRepos.Use(authenticate)
+		{
+			githubRepos.GET("", handlers.GitHub.ListRepos)
+			githubRepos.POST("", handlers.GitHub.ConnectRepo)
+			githubRepos.DELETE("/:id", handlers.GitHub.DisconnectRepo)
+		}
+		api.POST("/integrations/github/webhook/:id", handlers.GitHub.ReceiveWebhook)
+
+		admin := api.Group("/admin")
+		admin.Use(authenticate)
+		admin.Use(middleware.RequireRole(models.RoleAdmin))
+		{
+			admin.POST("/notifications/preview", handlers.Notification.PreviewTemplate)
+			admin.GET("/usage", handlers.Usage.GetUsage)
+			admin.POST("/impersonate/:userID", handlers.Impersonation.Impersonate)
+			admin.DELETE("/impersonations/:id", handlers.Impersonation.RevokeImpersonation)
+			admin.GET("/audit", handlers.Impersonation.GetAuditEvents)
+			admin.GET("/users", handlers.Admin.ListUsers)
+			admin.PUT("/users/:id/role", handlers.Admin.SetUserRole)
+			admin.POST("/users/:id/deactivate", handlers.User.DeactivateUser)
+			admin.POST("/users/:id/reactivate", handlers.User.ReactivateUser)
+			admin.DELETE("/tasks/:id", handlers.Admin.DeleteTask)
+			admin.GET("/changes", handlers.ChangeFeed.GetTaskChanges)
+			admin.GET("/changes/stream", handlers.ChangeFeed.StreamTaskChanges)
+		}
+	}
+
+	// неверные права сокета отмечает проверка конфигурации при запуске, сокет тогда получает права по умолчанию
+	socketMode, err := cfg.Server.SocketFileMode()
+	if err != nil {
+		socketMode = 0o660
 	}
 
 	return &Server{
+		addresses:  cfg.Server.Addresses(),
+		socketMode: socketMode,
 		httpServer: &http.Server{
-			Addr:           fmt.Sprintf("%s:%d", cfg.Server.Host, cfg.Server.Port),
 			Handler:        router,
 			ReadTimeout:    cfg.Server.ReadTimeout,
 			WriteTimeout:   cfg.Server.WriteTimeout,
@@ -82,7 +307,41 @@ func NewServer(cfg *config.Config, handlers *handler.Handler, logger logger.Logg
 	}
 }
 
-// запускаем HTTP-сервер
+// featureRoutes шаблоны маршрутов каждой отключаемой группы
+var featureRoutes = map[string][]string{
+	"import":        {"/api/tasks/import", "/api/task-imports"},
+	"export":        {"/api/tasks/export", "/api/task-exports"},
+	"analytics":     {"/api/tasks/analytics", "/api/task-analytics", "/api/projects/:id/analytics"},
+	"notifications": {"/api/notifications", "/api/admin/notifications"},
+	"triggers":      {"/api/triggers"},
+	"tagging":       {"/api/tagging-rules"},
+	"ai":            {"/api/tasks/:id/summarize", "/api/tasks/:id/suggest-subtasks"},
+	"similarity":    {"/api/tasks/:id/similar"},
+	"hooks":         {"/api/inbound-hooks", "/api/hooks"},
+	"integrations":  {"/api/tasks/:id/external", "/api/integrations"},
+	"projects":      {"/api/projects"},
+	"searches":      {"/api/saved-searches"},
+	"quickadd":      {"/api/tasks/quick-add"},
+	"recurrences":   {"/api/recurrences"},
+	"calendar":      {"/api/tasks/calendar.ics", "/api/tasks/calendar"},
+	"events":        {"/api/tasks/events"},
+	"admin":         {"/api/admin"},
+	"swagger":       {"/swagger", "/docs"},
+}
+
+// disabledRoutes шаблоны маршрутов отключенных групп
+func disabledRoutes(features config.FeaturesConfig) []string {
+	var routes []string
+	for feature, prefixes := range featureRoutes {
+		if !features.Enabled(feature) {
+			routes = append(routes, prefixes...)
+		}
+	}
+	return routes
+}
+
+// запускаем HTTP-сервер на всех адресах SERVER_LISTEN. Возвращает первую ошибку
+// любого из адресов; после Shutdown — http.ErrServerClosed
 func (s *Server) Run() error {
 	// Start metrics server
 	go func() {
@@ -91,7 +350,65 @@ func (s *Server) Run() error {
 		}
 	}()
 
-	return s.httpServer.ListenAndServe()
+	listeners := make([]net.Listener, 0, len(s.addresses))
+	for _, addr := range s.addresses {
+		listener, err := s.listen(addr)
+		if err != nil {
+			for _, l := range listeners {
+				l.Close()
+			}
+			return err
+		}
+		listeners = append(listeners, listener)
+	}
+
+	// один http.Server обслуживает все адреса, Shutdown закрывает их вместе
+	errs := make(chan error, len(listeners))
+	for _, listener := range listeners {
+		go func(listener net.Listener) {
+			errs <- s.httpServer.Serve(listener)
+		}(listener)
+	}
+
+	return <-errs
+}
+
+// listen открывает адрес host:port или unix:/path. Оставшийся от прошлого запуска файл сокета
+// удаляется, права на новый задает SERVER_SOCKET_MODE
+func (s *Server) listen(addr string) (net.Listener, error) {
+	path, ok := strings.CutPrefix(addr, config.UnixSocketPrefix)
+	if !ok {
+		listener, err := net.Listen("tcp", addr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to listen on %s: %w", addr, err)
+		}
+		return listener, nil
+	}
+
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to remove stale socket %s: %w", path, err)
+	}
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+	if err := os.Chmod(path, s.socketMode); err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("failed to set permissions of socket %s: %w", path, err)
+	}
+
+	return listener, nil
+}
+
+// Handler маршрутизатор API без прослушивания порта
+func (s *Server) Handler() http.Handler {
+	return s.httpServer.Handler
+}
+
+// RegisterOnShutdown регистрирует функцию, которая вызывается в начале Shutdown,
+// например чтобы закрыть длительные потоки событий
+func (s *Server) RegisterOnShutdown(f func()) {
+	s.httpServer.RegisterOnShutdown(f)
 }
 
 // Shutdown gracefully останавливаем сервер
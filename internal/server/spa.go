@@ -0,0 +1,63 @@
+package server
+
+import (
+	"errors"
+	"io/fs"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// файлы с хэшем в имени из каталога assets не меняются, кэшируются надолго
+	spaAssetsCacheControl = "public, max-age=31536000, immutable"
+	// index.html и остальные файлы браузер перепроверяет при каждом открытии
+	spaCacheControl = "no-cache"
+)
+
+// spaHandler отдает файлы SPA на маршруты, не занятые API. Пути без расширения файла,
+// которых нет в сборке, получают index.html — маршрутизацию в history-режиме выполняет фронтенд
+func spaHandler(files fs.FS) gin.HandlerFunc {
+	fileServer := http.FileServer(http.FS(files))
+
+	return func(c *gin.Context) {
+		urlPath := c.Request.URL.Path
+		if (c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead) ||
+			urlPath == "/api" || strings.HasPrefix(urlPath, "/api/") {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Not found"})
+			return
+		}
+
+		name := strings.TrimPrefix(path.Clean(urlPath), "/")
+		if name != "" && name != "index.html" {
+			info, err := fs.Stat(files, name)
+			switch {
+			case err == nil && !info.IsDir():
+				if strings.HasPrefix(name, "assets/") {
+					c.Header("Cache-Control", spaAssetsCacheControl)
+				} else {
+					c.Header("Cache-Control", spaCacheControl)
+				}
+				fileServer.ServeHTTP(c.Writer, c.Request)
+				return
+			case err != nil && !errors.Is(err, fs.ErrNotExist):
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read frontend files"})
+				return
+			case path.Ext(name) != "":
+				// отсутствующий файл, а не маршрут фронтенда
+				c.JSON(http.StatusNotFound, gin.H{"error": "Not found"})
+				return
+			}
+		}
+
+		index, err := fs.ReadFile(files, "index.html")
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Not found"})
+			return
+		}
+		c.Header("Cache-Control", spaCacheControl)
+		c.Data(http.StatusOK, "text/html; charset=utf-8", index)
+	}
+}
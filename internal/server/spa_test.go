@@ -0,0 +1,51 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSPAHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	files := fstest.MapFS{
+		"index.html":           {Data: []byte("<html>app</html>")},
+		"favicon.ico":          {Data: []byte("icon")},
+		"assets/app-3f2a1c.js": {Data: []byte("console.log(1)")},
+	}
+	router := gin.New()
+	router.GET("/api/tasks", func(c *gin.Context) { c.Status(http.StatusOK) })
+	router.NoRoute(spaHandler(files))
+
+	serve := func(method, path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+		return w
+	}
+
+	for _, path := range []string{"/", "/index.html", "/tasks/42", "/settings/profile"} {
+		w := serve(http.MethodGet, path)
+		assert.Equal(t, http.StatusOK, w.Code, path)
+		assert.Equal(t, "<html>app</html>", w.Body.String(), path)
+		assert.Equal(t, "no-cache", w.Header().Get("Cache-Control"), path)
+	}
+
+	w := serve(http.MethodGet, "/assets/app-3f2a1c.js")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "console.log(1)", w.Body.String())
+	assert.Equal(t, "public, max-age=31536000, immutable", w.Header().Get("Cache-Control"))
+
+	w = serve(http.MethodGet, "/favicon.ico")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "no-cache", w.Header().Get("Cache-Control"))
+
+	// отсутствующие файлы и неизвестные маршруты API не подменяются index.html
+	assert.Equal(t, http.StatusNotFound, serve(http.MethodGet, "/assets/missing.js").Code)
+	assert.Equal(t, http.StatusNotFound, serve(http.MethodGet, "/api/unknown").Code)
+	assert.Equal(t, http.StatusNotFound, serve(http.MethodPost, "/tasks/42").Code)
+}
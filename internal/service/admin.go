@@ -0,0 +1,146 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/jmoloko/taskmange/internal/domain/repository"
+	domainService "github.com/jmoloko/taskmange/internal/domain/service"
+	"github.com/jmoloko/taskmange/internal/logger"
+)
+
+var (
+	ErrInvalidRole    = errors.New("invalid role")
+	ErrSelfRoleChange = errors.New("cannot change your own role")
+)
+
+// AdminService административные операции: список пользователей, роли, удаление любых задач.
+// Доступ к ним проверяет RequireRole, изменения записываются в журнал аудита
+type AdminService struct {
+	users  repository.UserRepository
+	tasks  repository.TaskReader
+	delete domainService.TaskDeleter
+	audit  *AuditService
+	logger logger.Logger
+}
+
+// NewAdminService создает новый экземпляр AdminService
+func NewAdminService(users repository.UserRepository, tasks repository.TaskReader, deleter domainService.TaskDeleter, audit *AuditService, logger logger.Logger) *AdminService {
+	return &AdminService{
+		users:  users,
+		tasks:  tasks,
+		delete: deleter,
+		audit:  audit,
+		logger: logger,
+	}
+}
+
+// ListUsers страница пользователей по дате регистрации и их общее число
+func (s *AdminService) ListUsers(ctx context.Context, limit, offset int) ([]models.User, int, error) {
+	users, err := s.users.ListUsers(ctx, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	total, err := s.users.CountUsers(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return users, total, nil
+}
+
+// SetRole назначает пользователю userID роль от имени администратора adminID. Свою роль менять
+// нельзя, чтобы не остаться без администратора. Новая роль действует со следующего входа
+func (s *AdminService) SetRole(ctx context.Context, adminID, userID, role string) (models.User, error) {
+	if !models.ValidRole(role) {
+		return models.User{}, ErrInvalidRole
+	}
+	if adminID == userID {
+		return models.User{}, ErrSelfRoleChange
+	}
+
+	user, err := s.users.GetByID(ctx, userID)
+	if err != nil {
+		return models.User{}, ErrUserNotFound
+	}
+	if user.Role == role {
+		return *user, nil
+	}
+
+	if err := s.users.SetRole(ctx, userID, role); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return models.User{}, ErrUserNotFound
+		}
+		return models.User{}, err
+	}
+
+	s.audit.Record(ctx, models.AuditEvent{
+		Action:  models.AuditUserRoleChanged,
+		ActorID: adminID,
+		Target:  userID,
+		Details: map[string]string{"from": user.Role, "to": role},
+	})
+	s.logger.Info("User role changed", map[string]interface{}{
+		"user_id":  userID,
+		"admin_id": adminID,
+		"role":     role,
+	})
+
+	user.Role = role
+	return *user, nil
+}
+
+// PromoteAdmins назначает роль администратора пользователям из списка. Используется при запуске
+// для ADMIN_USER_IDS; неизвестные ID пропускаются
+func (s *AdminService) PromoteAdmins(ctx context.Context, userIDs []string) error {
+	for _, id := range userIDs {
+		user, err := s.users.GetByID(ctx, id)
+		if err != nil {
+			s.logger.Warn("Admin user not found", map[string]interface{}{
+				"user_id": id,
+			})
+			continue
+		}
+		if user.Role == models.RoleAdmin {
+			continue
+		}
+
+		if err := s.users.SetRole(ctx, id, models.RoleAdmin); err != nil {
+			return err
+		}
+		s.logger.Info("User promoted to admin", map[string]interface{}{
+			"user_id": id,
+		})
+	}
+
+	return nil
+}
+
+// DeleteTask удаляет задачу любого пользователя. Удаление проходит как удаление владельцем:
+// с событием task.deleted и сбросом кэша
+func (s *AdminService) DeleteTask(ctx context.Context, adminID, taskID string) error {
+	task, err := s.tasks.GetByID(ctx, taskID)
+	if err != nil {
+		return ErrTaskNotFound
+	}
+
+	if err := s.delete.Delete(ctx, taskID, task.UserID); err != nil {
+		return err
+	}
+
+	s.audit.Record(ctx, models.AuditEvent{
+		Action:  models.AuditTaskDeletedByAdmin,
+		ActorID: adminID,
+		Target:  taskID,
+		Details: map[string]string{"owner_id": task.UserID},
+	})
+	s.logger.Info("Task deleted by admin", map[string]interface{}{
+		"task_id":  taskID,
+		"owner_id": task.UserID,
+		"admin_id": adminID,
+	})
+
+	return nil
+}
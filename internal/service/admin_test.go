@@ -0,0 +1,68 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdminSetRole(t *testing.T) {
+	mockLogger = new(MockLogger)
+	mockLogger.On("Info", mock.Anything, mock.Anything).Return()
+	users := new(MockUserRepository)
+	audit := &memoryAudit{}
+	service := NewAdminService(users, nil, nil, NewAuditService(audit, mockLogger), mockLogger)
+	ctx := context.Background()
+
+	_, err := service.SetRole(ctx, "admin1", "user1", "root")
+	assert.Equal(t, ErrInvalidRole, err)
+	_, err = service.SetRole(ctx, "admin1", "admin1", models.RoleUser)
+	assert.Equal(t, ErrSelfRoleChange, err)
+
+	users.On("GetByID", mock.Anything, "missing").Return(nil, errors.New("not found")).Once()
+	_, err = service.SetRole(ctx, "admin1", "missing", models.RoleAdmin)
+	assert.Equal(t, ErrUserNotFound, err)
+
+	users.On("GetByID", mock.Anything, "user1").Return(&models.User{ID: "user1", Role: models.RoleUser}, nil).Once()
+	users.On("SetRole", mock.Anything, "user1", models.RoleAdmin).Return(nil).Once()
+	user, err := service.SetRole(ctx, "admin1", "user1", models.RoleAdmin)
+	require.NoError(t, err)
+	assert.Equal(t, models.RoleAdmin, user.Role)
+	require.Len(t, audit.events, 1)
+	assert.Equal(t, models.AuditUserRoleChanged, audit.events[0].Action)
+	assert.Equal(t, map[string]string{"from": models.RoleUser, "to": models.RoleAdmin}, audit.events[0].Details)
+
+	// та же роль ничего не меняет и в журнал не пишется
+	users.On("GetByID", mock.Anything, "user1").Return(&models.User{ID: "user1", Role: models.RoleAdmin}, nil).Once()
+	_, err = service.SetRole(ctx, "admin1", "user1", models.RoleAdmin)
+	require.NoError(t, err)
+	assert.Len(t, audit.events, 1)
+	users.AssertExpectations(t)
+}
+
+func TestAdminDeleteTask(t *testing.T) {
+	mockRepo = new(MockTaskRepository)
+	mockLogger = new(MockLogger)
+	mockLogger.On("Info", mock.Anything, mock.Anything).Return()
+	audit := &memoryAudit{}
+	tasks := NewTaskService(mockRepo, nil, nil, nil, nil, nil, nil, mockLogger)
+	service := NewAdminService(nil, mockRepo, tasks, NewAuditService(audit, mockLogger), mockLogger)
+	ctx := context.Background()
+
+	mockRepo.On("GetByID", mock.Anything, "task1").Return(&models.Task{ID: "task1", UserID: "user1"}, nil)
+	mockRepo.On("GetByID", mock.Anything, "missing").Return(nil, errors.New("not found"))
+	mockRepo.On("Delete", mock.Anything, "task1").Return(nil).Once()
+
+	require.NoError(t, service.DeleteTask(ctx, "admin1", "task1"))
+	require.Len(t, audit.events, 1)
+	assert.Equal(t, models.AuditTaskDeletedByAdmin, audit.events[0].Action)
+	assert.Equal(t, "user1", audit.events[0].Details["owner_id"])
+
+	assert.Equal(t, ErrTaskNotFound, service.DeleteTask(ctx, "admin1", "missing"))
+	mockRepo.AssertExpectations(t)
+}
@@ -0,0 +1,187 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/jmoloko/taskmange/internal/clock"
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/jmoloko/taskmange/internal/domain/repository"
+	domainService "github.com/jmoloko/taskmange/internal/domain/service"
+	"github.com/jmoloko/taskmange/internal/logger"
+)
+
+const (
+	// текст задачи длиннее обрезается перед отправкой модели
+	maxAIPromptRunes = 4000
+	// наибольшее число предложенных подзадач, длина заголовка — как у задачи
+	maxAISubtasks     = 10
+	maxAISubtaskRunes = 255
+	// краткое содержание длиннее обрезается
+	maxAISummaryRunes = 1000
+)
+
+var (
+	ErrAIDisabled    = errors.New("AI assistant is not configured")
+	ErrAIPrivateTask = errors.New("AI assistant is not available for private tasks")
+	ErrAIUnavailable = errors.New("AI assistant is unavailable")
+	ErrAITimeout     = errors.New("AI assistant timed out")
+)
+
+// системные инструкции модели для каждого действия
+var aiInstructions = map[models.AIAction]string{
+	models.AIActionSummary: "You summarize tasks from a task manager. Reply with a concise summary of the task " +
+		"in 1-3 sentences, in the language of the task. Reply with the summary only.",
+	models.AIActionSubtasks: "You split tasks from a task manager into subtasks. Reply with 3 to 7 short, actionable " +
+		"subtask titles, one per line, without numbering or any other text, in the language of the task.",
+}
+
+// маркеры списков, которыми модель может начинать строки вопреки инструкции: "-", "*", "1.", "2)"
+var aiListMarker = regexp.MustCompile(`^\s*(?:[-*•]|\d+[.)])\s*`)
+
+// AIService AI-ассистент задач: краткое содержание и предложение подзадач через языковую модель.
+// Результат сохраняется для задачи и отдается повторно, пока не изменился ее текст
+type AIService struct {
+	repo   repository.TaskAIRepository
+	tasks  domainService.TaskReader
+	model  domainService.LanguageModel
+	logger logger.Logger
+	clock  clock.Clock
+}
+
+// NewAIService создает новый экземпляр AIService. model может быть nil, тогда ассистент отключен
+func NewAIService(repo repository.TaskAIRepository, tasks domainService.TaskReader, model domainService.LanguageModel, logger logger.Logger) *AIService {
+	return &AIService{
+		repo:   repo,
+		tasks:  tasks,
+		model:  model,
+		logger: logger,
+		clock:  clock.System,
+	}
+}
+
+// Summarize краткое содержание задачи пользователя; refresh — не использовать сохраненный результат
+func (s *AIService) Summarize(ctx context.Context, userID, taskID string, refresh bool) (*models.AIResult, error) {
+	return s.run(ctx, userID, taskID, models.AIActionSummary, refresh)
+}
+
+// SuggestSubtasks заголовки подзадач для задачи пользователя, сами подзадачи не создаются
+func (s *AIService) SuggestSubtasks(ctx context.Context, userID, taskID string, refresh bool) (*models.AIResult, error) {
+	return s.run(ctx, userID, taskID, models.AIActionSubtasks, refresh)
+}
+
+func (s *AIService) run(ctx context.Context, userID, taskID string, action models.AIAction, refresh bool) (*models.AIResult, error) {
+	if s.model == nil {
+		return nil, ErrAIDisabled
+	}
+
+	task, err := s.tasks.GetUserTask(ctx, userID, taskID)
+	if err != nil {
+		return nil, err
+	}
+	// текст приватной задачи не должен покидать сервис
+	if task.Private {
+		return nil, ErrAIPrivateTask
+	}
+
+	hash := aiSourceHash(task)
+	if !refresh {
+		cached, err := s.repo.GetAIResult(ctx, taskID, action)
+		switch {
+		case err == nil && cached.SourceHash == hash && cached.Model == s.model.Name():
+			cached.Cached = true
+			return cached, nil
+		case err != nil && !errors.Is(err, repository.ErrNotFound):
+			s.logger.Error("Failed to get AI result", map[string]interface{}{
+				"task_id": taskID,
+				"error":   err.Error(),
+			})
+		}
+	}
+
+	text, err := s.model.Complete(ctx, aiInstructions[action], aiPrompt(task))
+	if err != nil {
+		s.logger.Warn("AI assistant request failed", map[string]interface{}{
+			"task_id": taskID,
+			"action":  action,
+			"error":   err.Error(),
+		})
+		if errors.Is(err, context.DeadlineExceeded) {
+			return nil, ErrAITimeout
+		}
+		return nil, ErrAIUnavailable
+	}
+
+	result := &models.AIResult{
+		Action:      action,
+		Model:       s.model.Name(),
+		GeneratedAt: s.clock.Now().UTC(),
+		SourceHash:  hash,
+	}
+	if action == models.AIActionSummary {
+		result.Summary = truncateRunes(text, maxAISummaryRunes)
+	} else {
+		result.Subtasks = parseAISubtasks(text)
+	}
+
+	// результат отдается и при ошибке сохранения, следующий запрос просто сгенерирует его заново
+	if err := s.repo.SaveAIResult(ctx, taskID, result); err != nil {
+		s.logger.Error("Failed to save AI result", map[string]interface{}{
+			"task_id": taskID,
+			"error":   err.Error(),
+		})
+	}
+
+	return result, nil
+}
+
+// aiSourceHash хэш текста задачи, который видит модель
+func aiSourceHash(task models.Task) string {
+	sum := sha256.Sum256([]byte(aiPrompt(task)))
+	return hex.EncodeToString(sum[:])
+}
+
+// aiPrompt текст задачи для модели: заголовок, описание и заметки
+func aiPrompt(task models.Task) string {
+	var b strings.Builder
+	b.WriteString("Title: " + task.Title)
+	if task.Description != "" {
+		b.WriteString("\nDescription: " + task.Description)
+	}
+	if task.Notes != nil && *task.Notes != "" {
+		b.WriteString("\nNotes: " + *task.Notes)
+	}
+	return truncateRunes(b.String(), maxAIPromptRunes)
+}
+
+// parseAISubtasks заголовки подзадач из ответа модели, по одному на строку
+func parseAISubtasks(text string) []string {
+	subtasks := []string{}
+	seen := make(map[string]bool)
+	scanner := bufio.NewScanner(strings.NewReader(text))
+	for scanner.Scan() && len(subtasks) < maxAISubtasks {
+		title := strings.TrimSpace(aiListMarker.ReplaceAllString(scanner.Text(), ""))
+		title = truncateRunes(title, maxAISubtaskRunes)
+		key := strings.ToLower(title)
+		if title == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		subtasks = append(subtasks, title)
+	}
+	return subtasks
+}
+
+// truncateRunes обрезает строку до limit символов
+func truncateRunes(s string, limit int) string {
+	if utf8.RuneCountInString(s) <= limit {
+		return s
+	}
+	return string([]rune(s)[:limit])
+}
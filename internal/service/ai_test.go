@@ -0,0 +1,101 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/jmoloko/taskmange/internal/domain/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryAIResults implements repository.TaskAIRepository
+type memoryAIResults map[string]models.AIResult
+
+func (r memoryAIResults) GetAIResult(ctx context.Context, taskID string, action models.AIAction) (*models.AIResult, error) {
+	result, ok := r[taskID+"/"+string(action)]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	return &result, nil
+}
+
+func (r memoryAIResults) SaveAIResult(ctx context.Context, taskID string, result *models.AIResult) error {
+	r[taskID+"/"+string(result.Action)] = *result
+	return nil
+}
+
+// fakeModel implements domainService.LanguageModel
+type fakeModel struct {
+	reply   string
+	err     error
+	prompts []string
+}
+
+func (m *fakeModel) Complete(ctx context.Context, system, prompt string) (string, error) {
+	m.prompts = append(m.prompts, prompt)
+	return m.reply, m.err
+}
+
+func (m *fakeModel) Name() string {
+	return "test-model"
+}
+
+func TestAISummarize(t *testing.T) {
+	mockRepo = new(MockTaskRepository)
+	mockLogger = new(MockLogger)
+	mockLogger.On("Warn", mock.Anything, mock.Anything).Return()
+	tasks := NewTaskService(mockRepo, nil, nil, nil, nil, nil, nil, mockLogger)
+	model := &fakeModel{reply: "Prepare the Q3 report."}
+	results := memoryAIResults{}
+	service := NewAIService(results, tasks, model, mockLogger)
+	ctx := context.Background()
+
+	task := &models.Task{ID: "task1", UserID: "user1", Title: "Q3 report", Description: "Collect numbers"}
+	mockRepo.On("GetByID", mock.Anything, "task1").Return(task, nil)
+	mockRepo.On("GetByID", mock.Anything, "private").Return(&models.Task{ID: "private", UserID: "user1", Private: true}, nil)
+
+	result, err := service.Summarize(ctx, "user1", "task1", false)
+	require.NoError(t, err)
+	assert.Equal(t, "Prepare the Q3 report.", result.Summary)
+	assert.False(t, result.Cached)
+
+	// повторный запрос без изменений задачи не вызывает модель
+	result, err = service.Summarize(ctx, "user1", "task1", false)
+	require.NoError(t, err)
+	assert.True(t, result.Cached)
+	assert.Len(t, model.prompts, 1)
+
+	// правка задачи или refresh делают сохраненный результат устаревшим
+	task.Description = "Collect numbers from finance"
+	_, err = service.Summarize(ctx, "user1", "task1", false)
+	require.NoError(t, err)
+	_, err = service.Summarize(ctx, "user1", "task1", true)
+	require.NoError(t, err)
+	assert.Len(t, model.prompts, 3)
+
+	_, err = service.Summarize(ctx, "user2", "task1", false)
+	assert.Equal(t, ErrAccessDenied, err)
+	_, err = service.Summarize(ctx, "user1", "private", false)
+	assert.Equal(t, ErrAIPrivateTask, err)
+
+	model.err = fmt.Errorf("failed to send request: %w", context.DeadlineExceeded)
+	_, err = service.Summarize(ctx, "user1", "task1", true)
+	assert.Equal(t, ErrAITimeout, err)
+	model.err = errors.New("model responded with status 500")
+	_, err = service.Summarize(ctx, "user1", "task1", true)
+	assert.Equal(t, ErrAIUnavailable, err)
+
+	_, err = NewAIService(results, tasks, nil, mockLogger).Summarize(ctx, "user1", "task1", false)
+	assert.Equal(t, ErrAIDisabled, err)
+}
+
+func TestParseAISubtasks(t *testing.T) {
+	subtasks := parseAISubtasks("1. Collect numbers\n- Draft report\n\n* draft report\n2) Send to finance  ")
+	assert.Equal(t, []string{"Collect numbers", "Draft report", "Send to finance"}, subtasks)
+	assert.Equal(t, []string{}, parseAISubtasks(" \n "))
+}
@@ -0,0 +1,93 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jmoloko/taskmange/internal/clock"
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/jmoloko/taskmange/internal/domain/repository"
+	domainService "github.com/jmoloko/taskmange/internal/domain/service"
+	"github.com/jmoloko/taskmange/internal/logger"
+)
+
+// максимальная глубина истории аналитики — год
+const maxAnalyticsHistoryDays = 365
+
+var ErrInvalidHistoryRange = errors.New("invalid analytics history range")
+
+// Сервис исторической аналитики: ежедневные снимки в Postgres
+type AnalyticsHistoryService struct {
+	tasks  domainService.TaskService
+	repo   repository.AnalyticsSnapshotRepository
+	clock  clock.Clock
+	logger logger.Logger
+}
+
+// NewAnalyticsHistoryService создает новый экземпляр AnalyticsHistoryService
+func NewAnalyticsHistoryService(tasks domainService.TaskService, repo repository.AnalyticsSnapshotRepository, logger logger.Logger) *AnalyticsHistoryService {
+	return &AnalyticsHistoryService{
+		tasks:  tasks,
+		repo:   repo,
+		clock:  clock.System,
+		logger: logger,
+	}
+}
+
+// SnapshotAll сохраняет снимок аналитики за текущий день (UTC) для всех активных пользователей.
+// Вызывается фоновым воркером по расписанию, повторный запуск в тот же день обновляет снимок
+func (s *AnalyticsHistoryService) SnapshotAll(ctx context.Context) error {
+	users, err := s.tasks.GetActiveUsers(ctx)
+	if err != nil {
+		return err
+	}
+
+	today := s.clock.Now().UTC().Truncate(24 * time.Hour)
+	var errs []error
+	for _, userID := range users {
+		analytics, err := s.tasks.GetUserAnalytics(ctx, userID, "day")
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		if err := s.repo.SaveAnalyticsSnapshot(ctx, &models.AnalyticsSnapshot{
+			UserID:    userID,
+			Date:      today,
+			Analytics: analytics,
+		}); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if len(users) > 0 {
+		s.logger.Info("Analytics snapshots saved", map[string]interface{}{
+			"users":  len(users),
+			"failed": len(errs),
+		})
+	}
+
+	return errors.Join(errs...)
+}
+
+// History снимки аналитики пользователя за последние days дней, включая текущий
+func (s *AnalyticsHistoryService) History(ctx context.Context, userID string, days int) ([]models.AnalyticsSnapshot, error) {
+	if days < 1 || days > maxAnalyticsHistoryDays {
+		return nil, ErrInvalidHistoryRange
+	}
+
+	to := s.clock.Now().UTC().Truncate(24 * time.Hour)
+	from := to.AddDate(0, 0, -(days - 1))
+
+	snapshots, err := s.repo.GetAnalyticsSnapshots(ctx, userID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	if snapshots == nil {
+		snapshots = []models.AnalyticsSnapshot{}
+	}
+
+	return snapshots, nil
+}
@@ -0,0 +1,73 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jmoloko/taskmange/internal/clock"
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/jmoloko/taskmange/internal/domain/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockAnalyticsSnapshotRepository implements repository.AnalyticsSnapshotRepository
+type MockAnalyticsSnapshotRepository struct {
+	mock.Mock
+}
+
+func (m *MockAnalyticsSnapshotRepository) SaveAnalyticsSnapshot(ctx context.Context, snapshot *models.AnalyticsSnapshot) error {
+	args := m.Called(ctx, snapshot)
+	return args.Error(0)
+}
+
+func (m *MockAnalyticsSnapshotRepository) GetAnalyticsSnapshots(ctx context.Context, userID string, from, to time.Time) ([]models.AnalyticsSnapshot, error) {
+	args := m.Called(ctx, userID, from, to)
+	return args.Get(0).([]models.AnalyticsSnapshot), args.Error(1)
+}
+
+func TestAnalyticsSnapshotAll(t *testing.T) {
+	mockRepo = new(MockTaskRepository)
+	mockLogger = new(MockLogger)
+	mockCache = new(MockCache)
+	mockSnapshots := new(MockAnalyticsSnapshotRepository)
+	service := NewAnalyticsHistoryService(NewTaskService(mockRepo, mockCache, nil, nil, nil, nil, nil, mockLogger), mockSnapshots, mockLogger)
+	// снимок за день по UTC, даже если по местному времени уже наступили следующие сутки
+	service.clock = clock.NewFake(time.Date(2024, 6, 10, 23, 59, 0, 0, time.FixedZone("MSK", 3*3600)))
+
+	tasks := []models.Task{{ID: "1", UserID: "user1", Status: models.StatusDone, Priority: models.PriorityHigh}}
+	mockRepo.On("GetAll", mock.Anything, models.TaskFilters{}).Return(tasks, nil).Once()
+	mockCache.On("GetUserAnalytics", mock.Anything, "user1", "day").Return(&repository.CachedAnalytics{
+		Analytics: models.Analytics{StatusCount: map[models.Status]int{models.StatusDone: 1}},
+	}, nil).Once()
+	mockSnapshots.On("SaveAnalyticsSnapshot", mock.Anything, mock.MatchedBy(func(s *models.AnalyticsSnapshot) bool {
+		return s.UserID == "user1" && s.Date.Equal(time.Date(2024, 6, 10, 0, 0, 0, 0, time.UTC)) &&
+			s.Analytics.StatusCount[models.StatusDone] == 1
+	})).Return(nil).Once()
+	mockLogger.On("Info", mock.Anything, mock.Anything).Return()
+
+	assert.NoError(t, service.SnapshotAll(context.Background()))
+
+	mockSnapshots.AssertExpectations(t)
+}
+
+func TestAnalyticsHistory(t *testing.T) {
+	mockSnapshots := new(MockAnalyticsSnapshotRepository)
+	service := NewAnalyticsHistoryService(nil, mockSnapshots, new(MockLogger))
+	service.clock = clock.NewFake(time.Date(2024, 6, 10, 0, 5, 0, 0, time.UTC))
+
+	_, err := service.History(context.Background(), "user1", 0)
+	assert.Equal(t, ErrInvalidHistoryRange, err)
+
+	today := time.Date(2024, 6, 10, 0, 0, 0, 0, time.UTC)
+	mockSnapshots.On("GetAnalyticsSnapshots", mock.Anything, "user1", today.AddDate(0, 0, -6), today).
+		Return([]models.AnalyticsSnapshot(nil), nil).Once()
+
+	snapshots, err := service.History(context.Background(), "user1", 7)
+	assert.NoError(t, err)
+	assert.NotNil(t, snapshots)
+	assert.Empty(t, snapshots)
+
+	mockSnapshots.AssertExpectations(t)
+}
@@ -0,0 +1,57 @@
+package service
+
+import (
+	"context"
+
+	"github.com/jmoloko/taskmange/internal/clock"
+	"github.com/jmoloko/taskmange/internal/domain/repository"
+	"github.com/jmoloko/taskmange/internal/logger"
+)
+
+// ArchiveService переносит задачи, выполненные больше заданного числа месяцев назад, в архивную таблицу,
+// чтобы оперативная таблица и ее индексы не росли вместе с историей
+type ArchiveService struct {
+	repo        repository.TaskArchiveRepository
+	afterMonths int
+	batchSize   int
+	logger      logger.Logger
+	clock       clock.Clock
+}
+
+// NewArchiveService создает новый экземпляр ArchiveService
+func NewArchiveService(repo repository.TaskArchiveRepository, afterMonths, batchSize int, logger logger.Logger) *ArchiveService {
+	return &ArchiveService{
+		repo:        repo,
+		afterMonths: afterMonths,
+		batchSize:   batchSize,
+		logger:      logger,
+		clock:       clock.System,
+	}
+}
+
+// Run переносит подходящие задачи пачками, пока очередная пачка не окажется неполной.
+// Вызывается фоновым воркером; прерванный перенос продолжится при следующем запуске
+func (s *ArchiveService) Run(ctx context.Context) error {
+	before := s.clock.Now().UTC().AddDate(0, -s.afterMonths, 0)
+
+	total := 0
+	for ctx.Err() == nil {
+		moved, err := s.repo.ArchiveTasks(ctx, before, s.batchSize)
+		if err != nil {
+			return err
+		}
+		total += moved
+		if moved < s.batchSize {
+			break
+		}
+	}
+
+	if total > 0 {
+		s.logger.Info("Archived completed tasks", map[string]interface{}{
+			"count":  total,
+			"before": before,
+		})
+	}
+
+	return ctx.Err()
+}
@@ -0,0 +1,45 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jmoloko/taskmange/internal/clock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// batchArchive implements repository.TaskArchiveRepository
+type batchArchive struct {
+	pending int
+	befores []time.Time
+}
+
+func (r *batchArchive) ArchiveTasks(ctx context.Context, before time.Time, limit int) (int, error) {
+	r.befores = append(r.befores, before)
+	moved := min(r.pending, limit)
+	r.pending -= moved
+	return moved, nil
+}
+
+func TestArchiveRun(t *testing.T) {
+	repo := &batchArchive{pending: 5}
+	log := new(MockLogger)
+	log.On("Info", "Archived completed tasks", mock.Anything).Return().Once()
+
+	service := NewArchiveService(repo, 6, 2, log)
+	service.clock = clock.NewFake(time.Date(2024, 8, 31, 10, 0, 0, 0, time.UTC))
+
+	require.NoError(t, service.Run(context.Background()))
+	assert.Equal(t, 0, repo.pending)
+	// пачки 2, 2 и неполная 1
+	require.Len(t, repo.befores, 3)
+	assert.Equal(t, time.Date(2024, 3, 2, 10, 0, 0, 0, time.UTC), repo.befores[0])
+
+	// нечего переносить — одна пустая пачка и без записи в лог
+	require.NoError(t, service.Run(context.Background()))
+	assert.Len(t, repo.befores, 4)
+	log.AssertExpectations(t)
+}
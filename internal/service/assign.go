@@ -0,0 +1,69 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/jmoloko/taskmange/internal/domain/repository"
+)
+
+// AssignTask назначает задачу владельца userID исполнителю assigneeID. Исполнитель видит задачу
+// в списке с фильтром Assigned и может ее менять, но не удалять и не переназначать
+func (s *TaskServiceImpl) AssignTask(ctx context.Context, userID, taskID, assigneeID string) (models.Task, error) {
+	if _, err := uuid.Parse(assigneeID); err != nil || assigneeID == userID {
+		return models.Task{}, ErrInvalidAssignee
+	}
+
+	task, err := s.repo.GetByID(ctx, taskID)
+	if err != nil {
+		return models.Task{}, ErrTaskNotFound
+	}
+	if task.UserID != userID {
+		return models.Task{}, ErrAccessDenied
+	}
+	if task.Private {
+		return models.Task{}, ErrPrivateTaskAssignment
+	}
+
+	return s.setAssignee(ctx, task, &assigneeID)
+}
+
+// UnassignTask снимает назначение задачи; снять его может владелец или сам исполнитель
+func (s *TaskServiceImpl) UnassignTask(ctx context.Context, userID, taskID string) (models.Task, error) {
+	task, err := s.repo.GetByID(ctx, taskID)
+	if err != nil {
+		return models.Task{}, ErrTaskNotFound
+	}
+	if !isMember(task, userID) {
+		return models.Task{}, ErrAccessDenied
+	}
+	if task.AssigneeID == nil {
+		return s.withOverdue(lockTask(*task)), nil
+	}
+
+	return s.setAssignee(ctx, task, nil)
+}
+
+// setAssignee сохраняет исполнителя задачи и публикует изменение задачи
+func (s *TaskServiceImpl) setAssignee(ctx context.Context, task *models.Task, assigneeID *string) (models.Task, error) {
+	if err := s.repo.SetAssignee(ctx, task.ID, assigneeID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return models.Task{}, ErrTaskNotFound
+		}
+		return models.Task{}, err
+	}
+	s.invalidateTask(ctx, task.UserID, task.ID)
+
+	before := *task
+	task.AssigneeID = assigneeID
+	s.publishUpdate(ctx, *task, models.DiffTasks(before, *task))
+
+	return s.withOverdue(lockTask(*task)), nil
+}
+
+// isMember может ли пользователь читать и менять задачу: владелец или исполнитель
+func isMember(task *models.Task, userID string) bool {
+	return task.UserID == userID || (task.AssigneeID != nil && *task.AssigneeID == userID)
+}
@@ -0,0 +1,72 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAssignTask(t *testing.T) {
+	mockRepo = new(MockTaskRepository)
+	mockLogger = new(MockLogger)
+	mockLogger.On("Info", mock.Anything, mock.Anything).Return()
+	service := NewTaskService(mockRepo, nil, nil, nil, nil, nil, nil, mockLogger)
+	ctx := context.Background()
+
+	owner := "9d2f4a61-3c8e-4b7a-8e15-6f0a2b9c7d34"
+	assignee := "3c1e8f2a-9b4d-4e7a-a5c6-2d8f0b1e4a7c"
+	mockRepo.On("GetByID", mock.Anything, "private").Return(&models.Task{ID: "private", UserID: owner, Private: true}, nil)
+	mockRepo.On("GetByID", mock.Anything, "task").Return(&models.Task{ID: "task", UserID: owner, Status: models.StatusPending}, nil).Once()
+
+	_, err := service.AssignTask(ctx, owner, "task", owner)
+	assert.Equal(t, ErrInvalidAssignee, err)
+	_, err = service.AssignTask(ctx, owner, "task", "not-a-uuid")
+	assert.Equal(t, ErrInvalidAssignee, err)
+	_, err = service.AssignTask(ctx, owner, "private", assignee)
+	assert.Equal(t, ErrPrivateTaskAssignment, err)
+
+	mockRepo.On("SetAssignee", mock.Anything, "task", &assignee).Return(nil).Once()
+	task, err := service.AssignTask(ctx, owner, "task", assignee)
+	require.NoError(t, err)
+	assert.Equal(t, &assignee, task.AssigneeID)
+
+	assigned := func() *models.Task {
+		return &models.Task{ID: "task", UserID: owner, Status: models.StatusPending, AssigneeID: &assignee}
+	}
+	mockRepo.On("GetByID", mock.Anything, "task").Return(assigned(), nil).Once()
+	mockRepo.On("GetByID", mock.Anything, "task").Return(assigned(), nil).Once()
+	mockRepo.On("GetByID", mock.Anything, "task").Return(assigned(), nil).Once()
+	mockRepo.On("GetByID", mock.Anything, "task").Return(assigned(), nil).Once()
+	mockRepo.On("GetByID", mock.Anything, "task").Return(assigned(), nil).Once()
+
+	// исполнитель видит и меняет задачу, но не удаляет и не переназначает ее
+	_, err = service.GetUserTask(ctx, assignee, "task")
+	require.NoError(t, err)
+
+	status := models.StatusInProgress
+	mockRepo.On("Update", mock.Anything, mock.MatchedBy(func(task *models.Task) bool {
+		return task.UserID == owner && task.Status == models.StatusInProgress
+	})).Return(nil).Once()
+	_, err = service.PatchUserTask(ctx, assignee, "task", models.UpdateTaskRequest{Status: &status})
+	require.NoError(t, err)
+
+	assert.Equal(t, ErrAccessDenied, service.DeleteUserTask(ctx, assignee, "task"))
+
+	_, err = service.AssignTask(ctx, assignee, "task", "5b0c7e3d-2a1f-4d6e-9c8b-7a6f5e4d3c2b")
+	assert.Equal(t, ErrAccessDenied, err)
+
+	// сам исполнитель может отказаться от задачи
+	mockRepo.On("SetAssignee", mock.Anything, "task", (*string)(nil)).Return(nil).Once()
+	task, err = service.UnassignTask(ctx, assignee, "task")
+	require.NoError(t, err)
+	assert.Nil(t, task.AssigneeID)
+
+	_, err = service.GetUserTask(ctx, "5b0c7e3d-2a1f-4d6e-9c8b-7a6f5e4d3c2b", "private")
+	assert.Equal(t, ErrAccessDenied, err)
+
+	mockRepo.AssertExpectations(t)
+}
@@ -0,0 +1,52 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/jmoloko/taskmange/internal/domain/repository"
+	"github.com/jmoloko/taskmange/internal/logger"
+)
+
+// максимальное число записей аудита в одном ответе
+const maxAuditEvents = 500
+
+var ErrInvalidAuditQuery = errors.New("invalid audit query")
+
+// AuditService журнал аудита административных действий и запросов под имперсонацией
+type AuditService struct {
+	repo   repository.AuditRepository
+	logger logger.Logger
+}
+
+// NewAuditService создает новый экземпляр AuditService
+func NewAuditService(repo repository.AuditRepository, logger logger.Logger) *AuditService {
+	return &AuditService{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+// Record сохраняет запись аудита. Ошибка записи логируется вместе с событием,
+// чтобы действие не потерялось бесследно
+func (s *AuditService) Record(ctx context.Context, event models.AuditEvent) {
+	if err := s.repo.SaveAuditEvent(ctx, &event); err != nil {
+		s.logger.Error("Failed to save audit event", map[string]interface{}{
+			"action":          event.Action,
+			"actor_id":        event.ActorID,
+			"impersonator_id": event.ImpersonatorID,
+			"target":          event.Target,
+			"error":           err.Error(),
+		})
+	}
+}
+
+// Events последние записи аудита по фильтру
+func (s *AuditService) Events(ctx context.Context, filter models.AuditFilter) ([]models.AuditEvent, error) {
+	if filter.Limit < 1 || filter.Limit > maxAuditEvents {
+		return nil, ErrInvalidAuditQuery
+	}
+
+	return s.repo.GetAuditEvents(ctx, filter)
+}
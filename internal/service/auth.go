@@ -11,9 +11,13 @@ import (
 	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
 
+	"github.com/jmoloko/taskmange/internal/clock"
+	"github.com/jmoloko/taskmange/internal/crypto"
 	"github.com/jmoloko/taskmange/internal/domain/models"
 	"github.com/jmoloko/taskmange/internal/domain/repository"
+	domainService "github.com/jmoloko/taskmange/internal/domain/service"
 	"github.com/jmoloko/taskmange/internal/logger"
+	"github.com/jmoloko/taskmange/internal/passwordpolicy"
 )
 
 var (
@@ -21,118 +25,352 @@ var (
 	ErrInvalidCredentials = errors.New("invalid credentials")
 	ErrInvalidToken       = errors.New("invalid token")
 	ErrUserNotFound       = errors.New("user not found")
+	ErrUserDeactivated    = errors.New("user is deactivated")
 	ErrInvalidEmail       = errors.New("invalid email format")
-	ErrInvalidPassword    = errors.New("invalid password: must be at least 6 characters")
 )
 
 // Сервис аутентификации
 type AuthService struct {
-	repo   repository.UserRepository
-	logger logger.Logger
-	secret string
+	repo           repository.UserRepository
+	impersonations repository.ImpersonationRepository
+	passwords      domainService.PasswordHasher
+	policy         domainService.PasswordPolicy
+	clock          clock.Clock
+	logger         logger.Logger
+	secret         string
 }
 
-func NewAuthService(repo repository.UserRepository, logger logger.Logger, secret string) *AuthService {
+// impersonations может быть nil, тогда токены имперсонации отклоняются;
+// passwords может быть nil, тогда пароли хэшируются bcrypt со стоимостью по умолчанию;
+// policy может быть nil, тогда от пароля требуется только длина не меньше 6 символов
+func NewAuthService(repo repository.UserRepository, impersonations repository.ImpersonationRepository, passwords domainService.PasswordHasher, policy domainService.PasswordPolicy, logger logger.Logger, secret string) *AuthService {
+	if passwords == nil {
+		passwords, _ = crypto.NewPasswordHasher(crypto.PasswordBcrypt, bcrypt.DefaultCost, crypto.Argon2Params{})
+	}
+	if policy == nil {
+		policy, _ = passwordpolicy.New(passwordpolicy.Config{MinLength: 6}, logger)
+	}
+
 	return &AuthService{
-		repo:   repo,
-		logger: logger,
-		secret: secret,
+		repo:           repo,
+		impersonations: impersonations,
+		passwords:      passwords,
+		policy:         policy,
+		clock:          clock.System,
+		logger:         logger,
+		secret:         secret,
 	}
 }
 
 // регистрация нового пользователя
 func (s *AuthService) Register(ctx context.Context, req models.RegisterRequest) error {
+	_, err := s.CreateUser(ctx, req)
+	return err
+}
+
+// CreateUser создает пользователя и возвращает его, используется регистрацией и командами CLI
+func (s *AuthService) CreateUser(ctx context.Context, req models.RegisterRequest) (*models.User, error) {
 	// валидация email
 	if _, err := mail.ParseAddress(req.Email); err != nil {
-		return ErrInvalidEmail
+		return nil, ErrInvalidEmail
 	}
 
 	// валидация пароля
-	if len(req.Password) < 6 {
-		return ErrInvalidPassword
+	if err := s.policy.Check(ctx, req.Password); err != nil {
+		return nil, err
 	}
 
 	// проверка на существование пользователя в базе
 	existingUser, _ := s.repo.GetByEmail(ctx, req.Email)
 	if existingUser != nil {
-		return ErrUserExists
+		return nil, ErrUserExists
 	}
 
 	// хэшируем пароль
-	passwordHash, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	passwordHash, err := s.passwords.Hash(req.Password)
 	if err != nil {
-		return fmt.Errorf("failed to hash password: %w", err)
+		return nil, err
 	}
 
 	user := &models.User{
 		ID:           generateUUID(),
 		Email:        req.Email,
-		PasswordHash: string(passwordHash),
-		CreatedAt:    time.Now(),
-		UpdatedAt:    time.Now(),
+		PasswordHash: passwordHash,
+		Role:         models.RoleUser,
+	}
+
+	// параллельная регистрация с тем же email проходит проверку выше и упирается в уникальный индекс
+	if err := s.repo.Create(ctx, user); err != nil {
+		if errors.Is(err, repository.ErrAlreadyExists) {
+			return nil, ErrUserExists
+		}
+		return nil, err
+	}
+	return user, nil
+}
+
+// ResetPassword задает пользователю новый пароль без проверки старого, доступно только из CLI
+func (s *AuthService) ResetPassword(ctx context.Context, email, password string) (*models.User, error) {
+	if err := s.policy.Check(ctx, password); err != nil {
+		return nil, err
 	}
 
-	return s.repo.Create(ctx, user)
+	user, err := s.repo.GetByEmail(ctx, email)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+
+	if err := s.setPassword(ctx, user, password); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Password reset", map[string]interface{}{
+		"user_id": user.ID,
+	})
+	return user, nil
 }
 
-// аутентификация пользователя и возврат токена
-func (s *AuthService) Login(ctx context.Context, req models.LoginRequest) (string, error) {
+// ChangePassword меняет пароль пользователя после проверки текущего
+func (s *AuthService) ChangePassword(ctx context.Context, userID string, req models.ChangePasswordRequest) error {
+	user, err := s.repo.GetByID(ctx, userID)
+	if err != nil {
+		return ErrUserNotFound
+	}
+
+	ok, _, err := s.passwords.Verify(user.PasswordHash, req.CurrentPassword)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrInvalidCredentials
+	}
+
+	if err := s.policy.Check(ctx, req.NewPassword); err != nil {
+		return err
+	}
+	if err := s.setPassword(ctx, user, req.NewPassword); err != nil {
+		return err
+	}
+
+	s.logger.Info("Password changed", map[string]interface{}{
+		"user_id": user.ID,
+	})
+	return nil
+}
+
+// setPassword сохраняет новый пароль, если он не совпадает ни с одним из последних
+// policy.History() паролей пользователя, включая текущий
+func (s *AuthService) setPassword(ctx context.Context, user *models.User, password string) error {
+	keep := max(s.policy.History()-1, 0)
+
+	if s.policy.History() > 0 {
+		previous, err := s.repo.PasswordHistory(ctx, user.ID, keep)
+		if err != nil {
+			return err
+		}
+		for _, hash := range append([]string{user.PasswordHash}, previous...) {
+			// хэши прежних алгоритмов могут не разбираться, такие просто пропускаются
+			if reused, _, _ := s.passwords.Verify(hash, password); reused {
+				return passwordpolicy.ReuseViolation(s.policy.History())
+			}
+		}
+	}
+
+	passwordHash, err := s.passwords.Hash(password)
+	if err != nil {
+		return err
+	}
+	return s.repo.ChangePassword(ctx, user.ID, passwordHash, keep)
+}
+
+// аутентификация пользователя: токен, срок его действия и профиль пользователя
+func (s *AuthService) Login(ctx context.Context, req models.LoginRequest) (models.LoginResponse, error) {
 	// Find user by email
 	user, err := s.repo.GetByEmail(ctx, req.Email)
 	if err != nil {
-		return "", ErrInvalidCredentials
+		return models.LoginResponse{}, ErrInvalidCredentials
 	}
 
 	// проверка пароля
-	err = bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.Password))
+	ok, rehash, err := s.passwords.Verify(user.PasswordHash, req.Password)
 	if err != nil {
-		return "", ErrInvalidCredentials
+		s.logger.Error("Failed to verify password", map[string]interface{}{
+			"user_id": user.ID,
+			"error":   err.Error(),
+		})
+		return models.LoginResponse{}, ErrInvalidCredentials
+	}
+	if !ok {
+		return models.LoginResponse{}, ErrInvalidCredentials
+	}
+	// о деактивации сообщаем только после проверки пароля, чтобы не раскрывать статус учетной записи
+	if !user.Active {
+		return models.LoginResponse{}, ErrUserDeactivated
+	}
+
+	// хэш старого алгоритма или стоимости пересчитывается, пока известен открытый пароль
+	if rehash {
+		s.rehashPassword(ctx, user.ID, req.Password)
 	}
 
 	// создание токена
-	token, err := s.generateToken(user.ID)
+	token, expiresAt, err := s.generateToken(user.ID, user.Role)
 	if err != nil {
-		return "", fmt.Errorf("failed to generate token: %w", err)
+		return models.LoginResponse{}, fmt.Errorf("failed to generate token: %w", err)
 	}
 
-	return token, nil
+	return models.LoginResponse{
+		Token:     token,
+		TokenType: models.TokenTypeBearer,
+		ExpiresAt: expiresAt,
+		ExpiresIn: int(time.Until(expiresAt).Round(time.Second).Seconds()),
+		User: models.UserProfile{
+			ID:        user.ID,
+			Email:     user.Email,
+			CreatedAt: user.CreatedAt,
+		},
+	}, nil
+}
+
+// rehashPassword сохраняет хэш пароля с текущими настройками, ошибка не мешает входу
+func (s *AuthService) rehashPassword(ctx context.Context, userID, password string) {
+	passwordHash, err := s.passwords.Hash(password)
+	if err == nil {
+		err = s.repo.UpdatePassword(ctx, userID, passwordHash)
+	}
+	if err != nil {
+		s.logger.Warn("Failed to rehash password", map[string]interface{}{
+			"user_id": userID,
+			"error":   err.Error(),
+		})
+	}
 }
 
 // валидируем токен и возвращаем Id пользователя
 func (s *AuthService) ValidateToken(tokenString string) (string, error) {
+	claims, err := s.ParseToken(context.Background(), tokenString)
+	if err != nil {
+		return "", err
+	}
+	return claims.UserID, nil
+}
+
+// ParseToken проверяет токен и возвращает его утверждения.
+// Токен имперсонации дополнительно сверяется с сессией: отозванная или истекшая сессия отклоняется
+func (s *AuthService) ParseToken(ctx context.Context, tokenString string) (models.TokenClaims, error) {
 	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
 
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 		}
 		return []byte(s.secret), nil
-	})
+	}, jwt.WithTimeFunc(s.clock.Now))
 
 	if err != nil {
-		return "", ErrInvalidToken
+		return models.TokenClaims{}, ErrInvalidToken
 	}
 
 	if !token.Valid {
-		return "", ErrInvalidToken
+		return models.TokenClaims{}, ErrInvalidToken
 	}
 
 	claims, ok := token.Claims.(jwt.MapClaims)
 	if !ok {
-		return "", ErrInvalidToken
+		return models.TokenClaims{}, ErrInvalidToken
 	}
 
 	if exp, ok := claims["exp"].(float64); ok {
-		if time.Now().Unix() > int64(exp) {
-			return "", ErrInvalidToken
+		if s.clock.Now().Unix() > int64(exp) {
+			return models.TokenClaims{}, ErrInvalidToken
 		}
 	}
 
 	userID, ok := claims["user_id"].(string)
 	if !ok {
-		return "", ErrInvalidToken
+		return models.TokenClaims{}, ErrInvalidToken
+	}
+
+	// токены, выданные до появления ролей, роли не содержат
+	role, _ := claims["role"].(string)
+	if !models.ValidRole(role) {
+		role = models.RoleUser
+	}
+
+	result := models.TokenClaims{UserID: userID, Role: role}
+	if impersonationID, ok := claims["impersonation_id"].(string); ok {
+		impersonatorID, _ := claims["impersonator_id"].(string)
+		if err := s.checkImpersonation(ctx, impersonationID, impersonatorID, userID); err != nil {
+			return models.TokenClaims{}, err
+		}
+		result.ImpersonatorID = impersonatorID
+		result.ImpersonationID = impersonationID
+		result.Role = models.RoleUser
+	}
+
+	return result, nil
+}
+
+// checkImpersonation проверяет, что сессия имперсонации действует и выдана на этих пользователей
+func (s *AuthService) checkImpersonation(ctx context.Context, id, adminID, userID string) error {
+	if s.impersonations == nil {
+		return ErrInvalidToken
+	}
+
+	imp, err := s.impersonations.GetImpersonation(ctx, id)
+	if err != nil {
+		if !errors.Is(err, repository.ErrNotFound) {
+			s.logger.Error("Failed to get impersonation", map[string]interface{}{
+				"impersonation_id": id,
+				"error":            err.Error(),
+			})
+		}
+		return ErrInvalidToken
+	}
+
+	if imp.RevokedAt != nil || s.clock.Now().After(imp.ExpiresAt) || imp.AdminID != adminID || imp.UserID != userID {
+		return ErrInvalidToken
+	}
+
+	// сессия держится на правах выдавшего ее администратора: после деактивации или снятия роли
+	// токен перестает действовать, не дожидаясь отзыва или истечения
+	admin, err := s.repo.GetByID(ctx, adminID)
+	if err != nil {
+		if !errors.Is(err, repository.ErrNotFound) {
+			s.logger.Error("Failed to get impersonating admin", map[string]interface{}{
+				"impersonation_id": id,
+				"admin_id":         adminID,
+				"error":            err.Error(),
+			})
+		}
+		return ErrInvalidToken
+	}
+	if !admin.Active || admin.Role != models.RoleAdmin {
+		return ErrInvalidToken
 	}
 
-	return userID, nil
+	return nil
+}
+
+// WhoAmI профиль владельца токена. Роли берутся из токена; под токеном имперсонации роль
+// администратора не выдается, как и доступ к административному API
+func (s *AuthService) WhoAmI(ctx context.Context, claims models.TokenClaims) (models.WhoAmI, error) {
+	user, err := s.GetUserByID(ctx, claims.UserID)
+	if err != nil {
+		return models.WhoAmI{}, err
+	}
+
+	roles := []string{models.RoleUser}
+	if claims.Role == models.RoleAdmin && claims.ImpersonatorID == "" {
+		roles = append(roles, models.RoleAdmin)
+	}
+
+	return models.WhoAmI{
+		ID:             user.ID,
+		Email:          user.Email,
+		Roles:          roles,
+		ImpersonatorID: claims.ImpersonatorID,
+		CreatedAt:      user.CreatedAt,
+	}, nil
 }
 
 // получаем пользователя по ID
@@ -144,15 +382,34 @@ func (s *AuthService) GetUserByID(ctx context.Context, id string) (*models.User,
 	return user, nil
 }
 
-// генерация токена
-func (s *AuthService) generateToken(userID string) (string, error) {
-	// Create token claims
-	expirationTime := time.Now().Add(time.Minute * 15)
+// генерация токена; роль в токене действует до его истечения
+func (s *AuthService) generateToken(userID, role string) (string, time.Time, error) {
+	// Create token claims; exp в JWT хранится с точностью до секунды
+	expirationTime := s.clock.Now().Add(time.Minute * 15).Truncate(time.Second)
 	claims := jwt.MapClaims{
 		"user_id": userID,
+		"role":    role,
 		"exp":     expirationTime.Unix(),
 	}
 
+	token, err := s.signToken(claims)
+	return token, expirationTime, err
+}
+
+// генерация токена имперсонации: user_id — пользователь, от имени которого действует администратор
+func (s *AuthService) generateImpersonationToken(imp models.Impersonation) (string, error) {
+	claims := jwt.MapClaims{
+		"user_id":          imp.UserID,
+		"impersonator_id":  imp.AdminID,
+		"impersonation_id": imp.ID,
+		"exp":              imp.ExpiresAt.Unix(),
+	}
+
+	return s.signToken(claims)
+}
+
+// подпись токена
+func (s *AuthService) signToken(claims jwt.MapClaims) (string, error) {
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 
 	tokenString, err := token.SignedString([]byte(s.secret))
@@ -0,0 +1,215 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/jmoloko/taskmange/internal/crypto"
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/jmoloko/taskmange/internal/passwordpolicy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestResetPassword(t *testing.T) {
+	users := new(MockUserRepository)
+	logger := new(MockLogger)
+	auth := NewAuthService(users, nil, nil, nil, logger, "secret")
+	ctx := context.Background()
+
+	_, err := auth.ResetPassword(ctx, "user@example.com", "123")
+	var violation *passwordpolicy.Violation
+	assert.ErrorAs(t, err, &violation)
+
+	users.On("GetByEmail", mock.Anything, "missing@example.com").Return(nil, errors.New("no rows")).Once()
+	_, err = auth.ResetPassword(ctx, "missing@example.com", "new-password")
+	assert.Equal(t, ErrUserNotFound, err)
+
+	users.On("GetByEmail", mock.Anything, "user@example.com").
+		Return(&models.User{ID: "user1", Email: "user@example.com"}, nil).Once()
+	users.On("ChangePassword", mock.Anything, "user1", mock.MatchedBy(func(hash string) bool {
+		return bcrypt.CompareHashAndPassword([]byte(hash), []byte("new-password")) == nil
+	}), 0).Return(nil).Once()
+	logger.On("Info", "Password reset", mock.Anything).Return().Once()
+
+	user, err := auth.ResetPassword(ctx, "user@example.com", "new-password")
+	require.NoError(t, err)
+	assert.Equal(t, "user1", user.ID)
+
+	users.AssertExpectations(t)
+}
+
+func TestChangePassword(t *testing.T) {
+	users := new(MockUserRepository)
+	logger := new(MockLogger)
+	policy, err := passwordpolicy.New(passwordpolicy.Config{MinLength: 8, History: 3}, logger)
+	require.NoError(t, err)
+	auth := NewAuthService(users, nil, nil, policy, logger, "secret")
+	ctx := context.Background()
+
+	hash := func(password string) string {
+		h, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+		require.NoError(t, err)
+		return string(h)
+	}
+	user := &models.User{ID: "user1", PasswordHash: hash("current-password")}
+	users.On("GetByID", mock.Anything, "user1").Return(user, nil)
+	users.On("PasswordHistory", mock.Anything, "user1", 2).Return([]string{hash("previous-password")}, nil)
+
+	err = auth.ChangePassword(ctx, "user1", models.ChangePasswordRequest{CurrentPassword: "wrong", NewPassword: "another-password"})
+	assert.Equal(t, ErrInvalidCredentials, err)
+
+	var violation *passwordpolicy.Violation
+	err = auth.ChangePassword(ctx, "user1", models.ChangePasswordRequest{CurrentPassword: "current-password", NewPassword: "short"})
+	require.ErrorAs(t, err, &violation)
+	assert.Equal(t, []string{"must be at least 8 characters"}, violation.Reasons)
+
+	// совпадение с текущим и с прежним паролем из истории
+	for _, reused := range []string{"current-password", "previous-password"} {
+		err = auth.ChangePassword(ctx, "user1", models.ChangePasswordRequest{CurrentPassword: "current-password", NewPassword: reused})
+		require.ErrorAs(t, err, &violation)
+		assert.Equal(t, []string{"must differ from the last 3 passwords"}, violation.Reasons)
+	}
+
+	users.On("ChangePassword", mock.Anything, "user1", mock.MatchedBy(func(h string) bool {
+		return bcrypt.CompareHashAndPassword([]byte(h), []byte("another-password")) == nil
+	}), 2).Return(nil).Once()
+	logger.On("Info", "Password changed", mock.Anything).Return().Once()
+
+	err = auth.ChangePassword(ctx, "user1", models.ChangePasswordRequest{CurrentPassword: "current-password", NewPassword: "another-password"})
+	require.NoError(t, err)
+	users.AssertExpectations(t)
+}
+
+func TestCreateUser(t *testing.T) {
+	users := new(MockUserRepository)
+	auth := NewAuthService(users, nil, nil, nil, new(MockLogger), "secret")
+	ctx := context.Background()
+
+	users.On("GetByEmail", mock.Anything, "admin@example.com").Return(nil, errors.New("no rows")).Once()
+	users.On("Create", mock.Anything, mock.MatchedBy(func(u *models.User) bool {
+		return u.ID != "" && u.Email == "admin@example.com"
+	})).Return(nil).Once()
+
+	user, err := auth.CreateUser(ctx, models.RegisterRequest{Email: "admin@example.com", Password: "password"})
+	require.NoError(t, err)
+	assert.NotEmpty(t, user.ID)
+
+	users.On("GetByEmail", mock.Anything, "admin@example.com").Return(user, nil).Once()
+	_, err = auth.CreateUser(ctx, models.RegisterRequest{Email: "admin@example.com", Password: "password"})
+	assert.Equal(t, ErrUserExists, err)
+
+	users.AssertExpectations(t)
+}
+
+func TestLoginRehashesOutdatedPassword(t *testing.T) {
+	users := new(MockUserRepository)
+	logger := new(MockLogger)
+	argon, err := crypto.NewPasswordHasher(crypto.PasswordArgon2id, bcrypt.MinCost, crypto.Argon2Params{Memory: 64, Iterations: 1, Parallelism: 1})
+	require.NoError(t, err)
+	auth := NewAuthService(users, nil, argon, nil, logger, "secret")
+	ctx := context.Background()
+
+	legacy, err := bcrypt.GenerateFromPassword([]byte("password"), bcrypt.MinCost)
+	require.NoError(t, err)
+	user := &models.User{ID: "user1", Email: "user@example.com", PasswordHash: string(legacy), Active: true}
+
+	users.On("GetByEmail", mock.Anything, "user@example.com").Return(user, nil).Twice()
+	users.On("UpdatePassword", mock.Anything, "user1", mock.MatchedBy(func(hash string) bool {
+		return strings.HasPrefix(hash, "$argon2id$")
+	})).Return(nil).Once()
+
+	resp, err := auth.Login(ctx, models.LoginRequest{Email: "user@example.com", Password: "password"})
+	require.NoError(t, err)
+	assert.NotEmpty(t, resp.Token)
+
+	// неверный пароль не пересчитывает хэш
+	_, err = auth.Login(ctx, models.LoginRequest{Email: "user@example.com", Password: "wrong"})
+	assert.Equal(t, ErrInvalidCredentials, err)
+
+	users.AssertExpectations(t)
+}
+
+func TestLoginResponse(t *testing.T) {
+	users := new(MockUserRepository)
+	auth := NewAuthService(users, nil, nil, nil, new(MockLogger), "secret")
+	ctx := context.Background()
+
+	hash, err := bcrypt.GenerateFromPassword([]byte("password"), bcrypt.DefaultCost)
+	require.NoError(t, err)
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	users.On("GetByEmail", mock.Anything, "user@example.com").
+		Return(&models.User{ID: "user1", Email: "user@example.com", PasswordHash: string(hash), Role: models.RoleUser, Active: true, CreatedAt: created}, nil)
+
+	resp, err := auth.Login(ctx, models.LoginRequest{Email: "user@example.com", Password: "password"})
+	require.NoError(t, err)
+	assert.Equal(t, models.TokenTypeBearer, resp.TokenType)
+	assert.Equal(t, models.UserProfile{ID: "user1", Email: "user@example.com", CreatedAt: created}, resp.User)
+	assert.Empty(t, resp.RefreshToken)
+	assert.InDelta(t, 15*60, resp.ExpiresIn, 1)
+
+	// срок в ответе совпадает с exp внутри токена
+	claims := jwt.MapClaims{}
+	_, err = jwt.ParseWithClaims(resp.Token, claims, func(*jwt.Token) (interface{}, error) { return []byte("secret"), nil })
+	require.NoError(t, err)
+	exp, err := claims.GetExpirationTime()
+	require.NoError(t, err)
+	assert.True(t, exp.Time.Equal(resp.ExpiresAt))
+	assert.Equal(t, models.RoleUser, claims["role"])
+}
+
+func TestTokenRole(t *testing.T) {
+	auth := NewAuthService(nil, nil, nil, nil, new(MockLogger), "secret")
+	ctx := context.Background()
+
+	token, _, err := auth.generateToken("admin1", models.RoleAdmin)
+	require.NoError(t, err)
+	claims, err := auth.ParseToken(ctx, token)
+	require.NoError(t, err)
+	assert.Equal(t, models.RoleAdmin, claims.Role)
+
+	// токен без роли, выданный до ее появления, и неизвестная роль — обычный пользователь
+	for _, role := range []interface{}{nil, "root"} {
+		mapClaims := jwt.MapClaims{"user_id": "user1", "exp": time.Now().Add(time.Minute).Unix()}
+		if role != nil {
+			mapClaims["role"] = role
+		}
+		token, err := auth.signToken(mapClaims)
+		require.NoError(t, err)
+		claims, err := auth.ParseToken(ctx, token)
+		require.NoError(t, err)
+		assert.Equal(t, models.RoleUser, claims.Role)
+	}
+}
+
+func TestWhoAmI(t *testing.T) {
+	users := new(MockUserRepository)
+	auth := NewAuthService(users, nil, nil, nil, new(MockLogger), "secret")
+	ctx := context.Background()
+
+	users.On("GetByID", mock.Anything, "user1").Return(&models.User{ID: "user1", Email: "user@example.com"}, nil)
+	users.On("GetByID", mock.Anything, "missing").Return(nil, errors.New("not found"))
+
+	me, err := auth.WhoAmI(ctx, models.TokenClaims{UserID: "user1", Role: models.RoleUser})
+	require.NoError(t, err)
+	assert.Equal(t, []string{models.RoleUser}, me.Roles)
+
+	me, err = auth.WhoAmI(ctx, models.TokenClaims{UserID: "user1", Role: models.RoleAdmin})
+	require.NoError(t, err)
+	assert.Equal(t, []string{models.RoleUser, models.RoleAdmin}, me.Roles)
+
+	// под токеном имперсонации роль администратора не отдается
+	me, err = auth.WhoAmI(ctx, models.TokenClaims{UserID: "user1", Role: models.RoleAdmin, ImpersonatorID: "admin1", ImpersonationID: "imp1"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{models.RoleUser}, me.Roles)
+	assert.Equal(t, "admin1", me.ImpersonatorID)
+
+	_, err = auth.WhoAmI(ctx, models.TokenClaims{UserID: "missing"})
+	assert.Equal(t, ErrUserNotFound, err)
+}
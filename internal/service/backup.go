@@ -0,0 +1,175 @@
+package service
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/jmoloko/taskmange/internal/domain/repository"
+	"github.com/jmoloko/taskmange/internal/logger"
+)
+
+// ErrInvalidBackup файл не является резервной копией пользователя поддерживаемой версии
+var ErrInvalidBackup = errors.New("invalid user backup")
+
+// BackupService перенос одного пользователя между установками: резервная копия —
+// gzip-поток JSON-записей models.BackupRecord по одной на строку
+type BackupService struct {
+	repo   repository.UserBackupRepository
+	logger logger.Logger
+}
+
+// NewBackupService создает новый экземпляр BackupService
+func NewBackupService(repo repository.UserBackupRepository, logger logger.Logger) *BackupService {
+	return &BackupService{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+// ExportUser пишет резервную копию пользователя в w. Записи пишутся по мере чтения из базы,
+// копия целиком в памяти не держится
+func (s *BackupService) ExportUser(ctx context.Context, userID string, w io.Writer) (models.BackupSummary, error) {
+	zw := gzip.NewWriter(w)
+	encoder := json.NewEncoder(zw)
+
+	summary := models.BackupSummary{UserID: userID}
+	err := s.repo.ExportUser(ctx, userID, func(record models.BackupRecord) error {
+		switch record.Type {
+		case models.BackupRecordAccount:
+			summary.Email = record.Account.Email
+		case models.BackupRecordTask:
+			summary.Tasks++
+		case models.BackupRecordRelation:
+			summary.Relations++
+		case models.BackupRecordPreferences:
+			summary.Preferences = true
+		}
+		return encoder.Encode(record)
+	})
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return models.BackupSummary{}, ErrUserNotFound
+		}
+		return models.BackupSummary{}, err
+	}
+
+	if err := zw.Close(); err != nil {
+		return models.BackupSummary{}, fmt.Errorf("failed to finish backup: %w", err)
+	}
+
+	s.logger.Info("User exported", map[string]interface{}{
+		"user_id": userID,
+		"tasks":   summary.Tasks,
+	})
+	return summary, nil
+}
+
+// ImportUser восстанавливает пользователя из резервной копии в одной транзакции: при любой
+// ошибке в базе ничего не остается. Пользователь с тем же ID или email не должен существовать
+func (s *BackupService) ImportUser(ctx context.Context, r io.Reader) (models.BackupSummary, error) {
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return models.BackupSummary{}, fmt.Errorf("%w: %v", ErrInvalidBackup, err)
+	}
+	defer zr.Close()
+	decoder := json.NewDecoder(zr)
+
+	tx, err := s.repo.BeginUserImport(ctx)
+	if err != nil {
+		return models.BackupSummary{}, err
+	}
+	defer tx.Rollback()
+
+	var summary models.BackupSummary
+	for {
+		var record models.BackupRecord
+		if err := decoder.Decode(&record); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return models.BackupSummary{}, fmt.Errorf("%w: %v", ErrInvalidBackup, err)
+		}
+
+		if err := s.importRecord(ctx, tx, record, &summary); err != nil {
+			return models.BackupSummary{}, err
+		}
+	}
+
+	if summary.UserID == "" {
+		return models.BackupSummary{}, fmt.Errorf("%w: no account record", ErrInvalidBackup)
+	}
+	if err := tx.Commit(); err != nil {
+		return models.BackupSummary{}, fmt.Errorf("failed to commit import: %w", err)
+	}
+
+	s.logger.Info("User imported", map[string]interface{}{
+		"user_id": summary.UserID,
+		"tasks":   summary.Tasks,
+	})
+	return summary, nil
+}
+
+// importRecord проверяет запись и сохраняет ее. Учетная запись должна идти первой,
+// задачи и настройки — принадлежать ей
+func (s *BackupService) importRecord(ctx context.Context, tx repository.UserImport, record models.BackupRecord, summary *models.BackupSummary) error {
+	if record.Type != models.BackupRecordAccount && summary.UserID == "" {
+		return fmt.Errorf("%w: %s record before account", ErrInvalidBackup, record.Type)
+	}
+
+	switch {
+	case record.Type == models.BackupRecordAccount && record.Account != nil:
+		account := record.Account
+		if summary.UserID != "" {
+			return fmt.Errorf("%w: more than one account record", ErrInvalidBackup)
+		}
+		if account.Version != models.BackupFormatVersion {
+			return fmt.Errorf("%w: unsupported version %d", ErrInvalidBackup, account.Version)
+		}
+		if account.ID == "" || account.Email == "" || account.PasswordHash == "" {
+			return fmt.Errorf("%w: incomplete account record", ErrInvalidBackup)
+		}
+		if err := tx.CreateAccount(ctx, *account); err != nil {
+			if errors.Is(err, repository.ErrAlreadyExists) {
+				return ErrUserExists
+			}
+			return err
+		}
+		summary.UserID, summary.Email = account.ID, account.Email
+
+	case record.Type == models.BackupRecordTask && record.Task != nil:
+		if record.Task.ID == "" || record.Task.UserID != summary.UserID {
+			return fmt.Errorf("%w: task %q does not belong to the account", ErrInvalidBackup, record.Task.ID)
+		}
+		if err := tx.CreateTask(ctx, *record.Task); err != nil {
+			if errors.Is(err, repository.ErrAlreadyExists) {
+				return fmt.Errorf("task %s already exists: %w", record.Task.ID, ErrInvalidBackup)
+			}
+			return err
+		}
+		summary.Tasks++
+
+	case record.Type == models.BackupRecordRelation && record.Relation != nil:
+		if err := tx.CreateRelation(ctx, *record.Relation); err != nil {
+			return err
+		}
+		summary.Relations++
+
+	case record.Type == models.BackupRecordPreferences && record.Preferences != nil:
+		prefs := *record.Preferences
+		prefs.UserID = summary.UserID
+		if err := tx.SavePreferences(ctx, prefs); err != nil {
+			return err
+		}
+		summary.Preferences = true
+
+	default:
+		return fmt.Errorf("%w: unknown or empty %q record", ErrInvalidBackup, record.Type)
+	}
+
+	return nil
+}
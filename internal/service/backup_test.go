@@ -0,0 +1,158 @@
+package service
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/jmoloko/taskmange/internal/domain/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryBackups implements repository.UserBackupRepository
+type memoryBackups struct {
+	records   map[string][]models.BackupRecord
+	committed []models.BackupRecord
+	emails    map[string]bool
+}
+
+func (r *memoryBackups) ExportUser(ctx context.Context, userID string, emit func(models.BackupRecord) error) error {
+	records, ok := r.records[userID]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	for _, record := range records {
+		if err := emit(record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *memoryBackups) BeginUserImport(ctx context.Context) (repository.UserImport, error) {
+	return &memoryUserImport{repo: r}, nil
+}
+
+// memoryUserImport implements repository.UserImport
+type memoryUserImport struct {
+	repo    *memoryBackups
+	pending []models.BackupRecord
+}
+
+func (i *memoryUserImport) CreateAccount(ctx context.Context, account models.BackupAccount) error {
+	if i.repo.emails[account.Email] {
+		return repository.ErrAlreadyExists
+	}
+	i.pending = append(i.pending, models.BackupRecord{Type: models.BackupRecordAccount, Account: &account})
+	return nil
+}
+
+func (i *memoryUserImport) CreateTask(ctx context.Context, task models.Task) error {
+	i.pending = append(i.pending, models.BackupRecord{Type: models.BackupRecordTask, Task: &task})
+	return nil
+}
+
+func (i *memoryUserImport) CreateRelation(ctx context.Context, relation models.BackupRelation) error {
+	i.pending = append(i.pending, models.BackupRecord{Type: models.BackupRecordRelation, Relation: &relation})
+	return nil
+}
+
+func (i *memoryUserImport) SavePreferences(ctx context.Context, prefs models.NotificationPreferences) error {
+	i.pending = append(i.pending, models.BackupRecord{Type: models.BackupRecordPreferences, Preferences: &prefs})
+	return nil
+}
+
+func (i *memoryUserImport) Commit() error {
+	i.repo.committed = append(i.repo.committed, i.pending...)
+	i.pending = nil
+	return nil
+}
+
+func (i *memoryUserImport) Rollback() error {
+	i.pending = nil
+	return nil
+}
+
+func TestBackupRoundTrip(t *testing.T) {
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	completed := created.Add(time.Hour)
+	records := []models.BackupRecord{
+		{Type: models.BackupRecordAccount, Account: &models.BackupAccount{
+			Version: models.BackupFormatVersion, ID: "user1", Email: "user@example.com", PasswordHash: "hash", CreatedAt: created,
+		}},
+		{Type: models.BackupRecordTask, Task: &models.Task{
+			ID: "a", UserID: "user1", Title: "A", Status: models.StatusDone, CreatedAt: created, CompletedAt: &completed,
+		}},
+		{Type: models.BackupRecordTask, Task: &models.Task{ID: "b", UserID: "user1", Title: "ciphertext", Private: true}},
+		{Type: models.BackupRecordRelation, Relation: &models.BackupRelation{TaskID: "a", RelatedTaskID: "b"}},
+		{Type: models.BackupRecordPreferences, Preferences: &models.NotificationPreferences{
+			UserID: "user1", Channels: []models.NotificationChannel{models.ChannelEmail}, Timezone: "Europe/Moscow",
+		}},
+	}
+	repo := &memoryBackups{records: map[string][]models.BackupRecord{"user1": records}, emails: map[string]bool{}}
+	logger := new(MockLogger)
+	logger.On("Info", mock.Anything, mock.Anything).Return()
+	service := NewBackupService(repo, logger)
+	ctx := context.Background()
+
+	_, err := service.ExportUser(ctx, "missing", &bytes.Buffer{})
+	assert.Equal(t, ErrUserNotFound, err)
+
+	var backup bytes.Buffer
+	summary, err := service.ExportUser(ctx, "user1", &backup)
+	require.NoError(t, err)
+	assert.Equal(t, models.BackupSummary{UserID: "user1", Email: "user@example.com", Tasks: 2, Relations: 1, Preferences: true}, summary)
+
+	imported, err := service.ImportUser(ctx, bytes.NewReader(backup.Bytes()))
+	require.NoError(t, err)
+	assert.Equal(t, summary, imported)
+	require.Len(t, repo.committed, len(records))
+	assert.Equal(t, "hash", repo.committed[0].Account.PasswordHash)
+	assert.Equal(t, completed, *repo.committed[1].Task.CompletedAt)
+	assert.True(t, repo.committed[2].Task.Private)
+	assert.Equal(t, "user1", repo.committed[4].Preferences.UserID)
+
+	// пользователь уже есть: транзакция откатывается
+	repo.committed = nil
+	repo.emails["user@example.com"] = true
+	_, err = service.ImportUser(ctx, bytes.NewReader(backup.Bytes()))
+	assert.Equal(t, ErrUserExists, err)
+	assert.Empty(t, repo.committed)
+}
+
+func TestBackupImportInvalid(t *testing.T) {
+	repo := &memoryBackups{emails: map[string]bool{}}
+	service := NewBackupService(repo, new(MockLogger))
+	ctx := context.Background()
+
+	gzipped := func(lines string) *bytes.Reader {
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		zw.Write([]byte(lines))
+		zw.Close()
+		return bytes.NewReader(buf.Bytes())
+	}
+
+	tests := map[string]*bytes.Reader{
+		"not gzip":      bytes.NewReader([]byte(`{"type":"account"}`)),
+		"empty":         gzipped(""),
+		"task first":    gzipped(`{"type":"task","task":{"id":"a","user_id":"user1"}}`),
+		"old version":   gzipped(`{"type":"account","account":{"version":0,"id":"user1","email":"e","password_hash":"h"}}`),
+		"foreign task":  gzipped(`{"type":"account","account":{"version":1,"id":"user1","email":"e","password_hash":"h"}}` + "\n" + `{"type":"task","task":{"id":"a","user_id":"user2"}}`),
+		"unknown type":  gzipped(`{"type":"account","account":{"version":1,"id":"user1","email":"e","password_hash":"h"}}` + "\n" + `{"type":"comment"}`),
+		"broken record": gzipped(`{"type":"account","account":{"version":1,"id":"user1","email":"e","password_hash":"h"}}` + "\n" + `{"type":`),
+	}
+	for name, r := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := service.ImportUser(ctx, r)
+			assert.True(t, errors.Is(err, ErrInvalidBackup), err)
+		})
+	}
+	assert.Empty(t, repo.committed)
+}
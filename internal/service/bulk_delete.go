@@ -0,0 +1,83 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/jmoloko/taskmange/internal/tracing"
+)
+
+// DeleteTasks удаляет задачи пользователя по фильтру пачками, каждая пачка — отдельный запрос к БД.
+// После каждой пачки сбрасывается кэш, публикуется одно событие tasks.deleted и вызывается progress;
+// ошибка progress (клиент перестал читать ответ) и отмена ctx останавливают удаление между пачками,
+// удаленные задачи при этом не восстанавливаются. Фильтр без условий — ErrInvalidTaskData.
+// Возвращает итог, Done — подходящих задач не осталось
+func (s *TaskServiceImpl) DeleteTasks(ctx context.Context, userID string, filter models.TaskDeleteFilter, progress func(models.BulkDeleteProgress) error) (models.BulkDeleteProgress, error) {
+	ctx, span := tracing.Start(ctx, "TaskService.DeleteTasks")
+	defer span.End()
+
+	filter.UserID = userID
+	if filter.Empty() {
+		return models.BulkDeleteProgress{}, ErrInvalidTaskData
+	}
+
+	var result models.BulkDeleteProgress
+	for {
+		tasks, err := s.repo.DeleteTasks(ctx, filter, s.deleteBatch)
+		if err != nil {
+			s.log(ctx).Error("Failed to delete tasks", map[string]interface{}{
+				"user_id": userID,
+				"deleted": result.Deleted,
+				"error":   err.Error(),
+			})
+			return result, err
+		}
+
+		if len(tasks) > 0 {
+			ids := make([]string, len(tasks))
+			for i, task := range tasks {
+				ids[i] = task.ID
+			}
+			s.invalidateTask(ctx, userID, ids...)
+			s.emit(ctx, models.TaskEvent{
+				Type:   models.EventTasksDeleted,
+				UserID: userID,
+				Tasks:  lockTasks(tasks),
+			})
+			result.Deleted += len(tasks)
+			result.Batches++
+		}
+
+		if len(tasks) < s.deleteBatch {
+			result.Done = true
+			break
+		}
+		if err := progress(result); err != nil {
+			return result, err
+		}
+		if err := pause(ctx, s.deletePause); err != nil {
+			return result, err
+		}
+	}
+
+	s.log(ctx).Info("Tasks deleted by filter", map[string]interface{}{
+		"user_id": userID,
+		"deleted": result.Deleted,
+		"batches": result.Batches,
+	})
+	return result, nil
+}
+
+// pause ждет delay или отмены ctx
+func pause(ctx context.Context, delay time.Duration) error {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
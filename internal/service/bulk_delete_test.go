@@ -0,0 +1,72 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeleteTasks(t *testing.T) {
+	mockRepo = new(MockTaskRepository)
+	mockLogger = new(MockLogger)
+	publisher := &recordingPublisher{}
+	service := NewTaskService(mockRepo, nil, nil, publisher, nil, nil, nil, mockLogger).(*TaskServiceImpl)
+	service.deleteBatch, service.deletePause = 2, time.Millisecond
+	ctx := context.Background()
+
+	// без условий фильтр удалил бы все задачи
+	_, err := service.DeleteTasks(ctx, "user1", models.TaskDeleteFilter{}, nil)
+	assert.Equal(t, ErrInvalidTaskData, err)
+
+	before := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	filter := models.TaskDeleteFilter{UserID: "user1", Status: models.StatusDone, CompletedBefore: &before}
+	mockRepo.On("DeleteTasks", mock.Anything, filter, 2).Return([]models.Task{
+		{ID: "a", UserID: "user1"}, {ID: "b", UserID: "user1", Title: "secret", Private: true},
+	}, nil).Once()
+	mockRepo.On("DeleteTasks", mock.Anything, filter, 2).Return([]models.Task{{ID: "c", UserID: "user1"}}, nil).Once()
+	mockLogger.On("Info", "Tasks deleted by filter", mock.Anything).Return().Once()
+
+	var reported []models.BulkDeleteProgress
+	result, err := service.DeleteTasks(ctx, "user1", models.TaskDeleteFilter{Status: models.StatusDone, CompletedBefore: &before},
+		func(p models.BulkDeleteProgress) error {
+			reported = append(reported, p)
+			return nil
+		})
+	require.NoError(t, err)
+	assert.Equal(t, models.BulkDeleteProgress{Deleted: 3, Batches: 2, Done: true}, result)
+	assert.Equal(t, []models.BulkDeleteProgress{{Deleted: 2, Batches: 1}}, reported)
+
+	// одно событие на пачку, приватные задачи заблокированы
+	require.Len(t, publisher.events, 2)
+	assert.Equal(t, models.EventTasksDeleted, publisher.events[0].Type)
+	require.Len(t, publisher.events[0].Tasks, 2)
+	assert.True(t, publisher.events[0].Tasks[1].Locked)
+	assert.Len(t, publisher.events[1].Tasks, 1)
+
+	mockRepo.AssertExpectations(t)
+	mockLogger.AssertExpectations(t)
+}
+
+func TestDeleteTasks_StopsWhenProgressFails(t *testing.T) {
+	mockRepo = new(MockTaskRepository)
+	service := NewTaskService(mockRepo, nil, nil, nil, nil, nil, nil, new(MockLogger)).(*TaskServiceImpl)
+	service.deleteBatch = 1
+
+	filter := models.TaskDeleteFilter{UserID: "user1", Status: models.StatusDone}
+	mockRepo.On("DeleteTasks", mock.Anything, filter, 1).Return([]models.Task{{ID: "a", UserID: "user1"}}, nil).Once()
+
+	// клиент перестал читать ход удаления: следующая пачка не удаляется
+	gone := errors.New("connection closed")
+	result, err := service.DeleteTasks(context.Background(), "user1", filter, func(models.BulkDeleteProgress) error {
+		return gone
+	})
+	assert.Equal(t, gone, err)
+	assert.Equal(t, models.BulkDeleteProgress{Deleted: 1, Batches: 1}, result)
+	mockRepo.AssertExpectations(t)
+}
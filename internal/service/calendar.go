@@ -0,0 +1,149 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jmoloko/taskmange/internal/clock"
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/jmoloko/taskmange/internal/domain/repository"
+	domainService "github.com/jmoloko/taskmange/internal/domain/service"
+	"github.com/jmoloko/taskmange/internal/logger"
+)
+
+// calendarFeedPast насколько в прошлое лента включает задачи: календари хранят ленту целиком,
+// поэтому давно прошедшие сроки в нее не попадают
+const calendarFeedPast = 90 * 24 * time.Hour
+
+// CalendarFeedPath путь ленты календаря, к нему добавляется токен подписки
+const CalendarFeedPath = "/api/tasks/calendar.ics"
+
+var (
+	ErrCalendarFeedNotFound = errors.New("calendar feed is not enabled")
+	ErrInvalidCalendarToken = errors.New("invalid calendar token")
+)
+
+// CalendarService лента задач со сроком для календарей и ссылки подписки на нее.
+// Токен ссылки подписан ключом сервера и содержит пользователя и версию подписки,
+// поэтому в базе хранится только версия: перевыпуск и удаление подписки отзывают прежние ссылки
+type CalendarService struct {
+	feeds  repository.CalendarFeedRepository
+	tasks  domainService.TaskService
+	key    []byte
+	logger logger.Logger
+	clock  clock.Clock
+}
+
+// NewCalendarService создает новый экземпляр CalendarService. signingKey — ключ подписи JWT,
+// токены подписки подписываются им же с отдельным префиксом
+func NewCalendarService(feeds repository.CalendarFeedRepository, tasks domainService.TaskService, signingKey string, logger logger.Logger) *CalendarService {
+	return &CalendarService{
+		feeds:  feeds,
+		tasks:  tasks,
+		key:    []byte(signingKey),
+		logger: logger,
+		clock:  clock.System,
+	}
+}
+
+// CreateFeed выпускает ссылку подписки; повторный выпуск отзывает прежнюю ссылку
+func (s *CalendarService) CreateFeed(ctx context.Context, userID string) (models.CalendarFeed, error) {
+	version, updatedAt, err := s.feeds.RotateCalendarFeed(ctx, userID)
+	if err != nil {
+		return models.CalendarFeed{}, err
+	}
+
+	token := s.sign(userID, version)
+	return models.CalendarFeed{
+		Token:     token,
+		URL:       CalendarFeedPath + "?token=" + token,
+		UpdatedAt: updatedAt,
+	}, nil
+}
+
+// DeleteFeed отзывает ссылку подписки
+func (s *CalendarService) DeleteFeed(ctx context.Context, userID string) error {
+	err := s.feeds.DeleteCalendarFeed(ctx, userID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return ErrCalendarFeedNotFound
+	}
+	return err
+}
+
+// Authenticate возвращает пользователя по токену подписки. Токен действует, пока версия
+// подписки в нем совпадает с текущей
+func (s *CalendarService) Authenticate(ctx context.Context, token string) (string, error) {
+	userID, version, err := s.verify(token)
+	if err != nil {
+		return "", err
+	}
+
+	current, err := s.feeds.GetCalendarFeedVersion(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+	if current == 0 || current != version {
+		return "", ErrInvalidCalendarToken
+	}
+
+	return userID, nil
+}
+
+// Tasks задачи ленты: со сроком не раньше calendarFeedPast назад, в порядке срока.
+// Приватные задачи возвращаются заблокированными
+func (s *CalendarService) Tasks(ctx context.Context, userID string) ([]models.Task, error) {
+	from := s.clock.Now().Add(-calendarFeedPast)
+	return s.tasks.GetUserTasks(ctx, userID, models.TaskFilters{
+		UserID:  userID,
+		DueFrom: &from,
+		Sort:    models.SortDue,
+	})
+}
+
+// sign токен подписки: base64url("userID.version") и подпись через точку
+func (s *CalendarService) sign(userID string, version int) string {
+	payload := userID + "." + strconv.Itoa(version)
+	return base64.RawURLEncoding.EncodeToString([]byte(payload)) + "." +
+		base64.RawURLEncoding.EncodeToString(s.mac(payload))
+}
+
+// verify проверяет подпись токена и возвращает пользователя и версию
+func (s *CalendarService) verify(token string) (string, int, error) {
+	encoded, signature, ok := strings.Cut(token, ".")
+	if !ok {
+		return "", 0, ErrInvalidCalendarToken
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", 0, ErrInvalidCalendarToken
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(mac, s.mac(string(payload))) {
+		return "", 0, ErrInvalidCalendarToken
+	}
+
+	userID, versionText, ok := strings.Cut(string(payload), ".")
+	if !ok || userID == "" {
+		return "", 0, ErrInvalidCalendarToken
+	}
+	version, err := strconv.Atoi(versionText)
+	if err != nil || version < 1 {
+		return "", 0, ErrInvalidCalendarToken
+	}
+
+	return userID, version, nil
+}
+
+// mac HMAC-SHA256 полезной нагрузки; префикс отделяет подписи календаря от других подписей тем же ключом
+func (s *CalendarService) mac(payload string) []byte {
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write([]byte("calendar."))
+	mac.Write([]byte(payload))
+	return mac.Sum(nil)
+}
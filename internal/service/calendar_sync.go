@@ -0,0 +1,530 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoloko/taskmange/internal/calendar"
+	"github.com/jmoloko/taskmange/internal/clock"
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/jmoloko/taskmange/internal/domain/repository"
+	domainService "github.com/jmoloko/taskmange/internal/domain/service"
+	"github.com/jmoloko/taskmange/internal/logger"
+	"github.com/jmoloko/taskmange/internal/notification"
+)
+
+const (
+	// сколько календарей опрашивается за один запуск
+	calendarPollBatch = 50
+	// сколько ближайших задач переносится в календарь при подключении
+	calendarBackfillLimit = 100
+	// за сколько до окончания канал уведомлений Google открывается заново
+	calendarChannelRenewal = 24 * time.Hour
+)
+
+var (
+	ErrCalendarLinkNotFound   = errors.New("calendar link not found")
+	ErrCalendarLinkExists     = errors.New("calendar is already connected")
+	ErrCalendarUnavailable    = errors.New("calendar provider is not configured")
+	ErrInvalidCalendarLink    = errors.New("invalid calendar link")
+	ErrCalendarAuth           = errors.New("calendar rejected credentials")
+	ErrInvalidCalendarChannel = errors.New("invalid calendar channel")
+)
+
+// CalendarSyncService двусторонняя синхронизация сроков задач с календарями Google и Apple.
+// Задача со сроком становится событием в момент срока, перенос события меняет срок задачи.
+// Для каждой пары задача-событие хранится последний согласованный срок: изменилась только
+// одна сторона — она переносится на другую, обе — конфликт решает политика подключения
+type CalendarSyncService struct {
+	repo     repository.CalendarSyncRepository
+	tasks    domainService.TaskManager
+	statuses domainService.UserStatusChecker
+	clients  map[models.CalendarProvider]domainService.CalendarClient
+	// webhookURL адрес для уведомлений Google, пустой — календари только опрашиваются
+	webhookURL string
+	// interval как часто опрашивается каждый календарь
+	interval time.Duration
+	logger   logger.Logger
+	clock    clock.Clock
+}
+
+// NewCalendarSyncService создает новый экземпляр CalendarSyncService.
+// clients — настроенные в развертывании календари, подключить остальные нельзя.
+// statuses nil — уведомления Google меняют задачи и деактивированных владельцев
+func NewCalendarSyncService(repo repository.CalendarSyncRepository, tasks domainService.TaskManager, statuses domainService.UserStatusChecker, clients map[models.CalendarProvider]domainService.CalendarClient, webhookURL string, interval time.Duration, logger logger.Logger) *CalendarSyncService {
+	return &CalendarSyncService{
+		repo:       repo,
+		tasks:      tasks,
+		statuses:   statuses,
+		clients:    clients,
+		webhookURL: webhookURL,
+		interval:   interval,
+		logger:     logger,
+		clock:      clock.System,
+	}
+}
+
+// Connect подключает календарь пользователя. Учетные данные проверяются запросом начальной
+// позиции журнала изменений, затем в календарь переносятся ближайшие задачи со сроком
+func (s *CalendarSyncService) Connect(ctx context.Context, userID string, req models.CalendarLinkRequest) (*models.CalendarLink, error) {
+	client, ok := s.clients[req.Provider]
+	if !ok {
+		return nil, ErrCalendarUnavailable
+	}
+
+	link := &models.CalendarLink{
+		ID:             uuid.New().String(),
+		UserID:         userID,
+		Provider:       req.Provider,
+		CalendarID:     strings.TrimSpace(req.CalendarID),
+		ConflictPolicy: req.ConflictPolicy,
+	}
+	if link.ConflictPolicy == "" {
+		link.ConflictPolicy = models.ConflictLatest
+	}
+	if !link.ConflictPolicy.IsValid() {
+		return nil, fmt.Errorf("%w: unknown conflict policy %q", ErrInvalidCalendarLink, req.ConflictPolicy)
+	}
+
+	switch req.Provider {
+	case models.CalendarGoogle:
+		if req.RefreshToken == "" {
+			return nil, fmt.Errorf("%w: refresh_token is required", ErrInvalidCalendarLink)
+		}
+		if link.CalendarID == "" {
+			link.CalendarID = "primary"
+		}
+		link.Secret = req.RefreshToken
+	case models.CalendarApple:
+		if req.Username == "" || req.Password == "" {
+			return nil, fmt.Errorf("%w: username and password are required", ErrInvalidCalendarLink)
+		}
+		u, err := url.Parse(link.CalendarID)
+		if err != nil || u.Scheme != "https" || u.Host == "" {
+			return nil, fmt.Errorf("%w: calendar_id must be the https URL of the CalDAV collection", ErrInvalidCalendarLink)
+		}
+		if err := notification.ValidateTarget(link.CalendarID); err != nil {
+			return nil, fmt.Errorf("%w: calendar_id: %v", ErrInvalidCalendarLink, err)
+		}
+		link.Username, link.Secret = req.Username, req.Password
+	}
+
+	if err := s.repo.CreateCalendarLink(ctx, link); err != nil {
+		if errors.Is(err, repository.ErrAlreadyExists) {
+			return nil, ErrCalendarLinkExists
+		}
+		return nil, err
+	}
+
+	_, token, err := client.Changes(ctx, *link)
+	if err != nil {
+		if deleteErr := s.repo.DeleteCalendarLink(ctx, userID, link.ID); deleteErr != nil {
+			return nil, deleteErr
+		}
+		switch {
+		case errors.Is(err, calendar.ErrUnauthorized):
+			return nil, ErrCalendarAuth
+		case errors.Is(err, calendar.ErrEventNotFound):
+			return nil, fmt.Errorf("%w: calendar not found", ErrInvalidCalendarLink)
+		}
+		return nil, err
+	}
+
+	now := s.clock.Now()
+	if err := s.repo.UpdateCalendarSync(ctx, link.ID, token, now, ""); err != nil {
+		return nil, err
+	}
+	link.SyncToken, link.SyncedAt = token, &now
+
+	s.watch(ctx, client, link)
+	s.backfill(ctx, client, *link)
+
+	return link, nil
+}
+
+// List подключенные календари пользователя
+func (s *CalendarSyncService) List(ctx context.Context, userID string) ([]models.CalendarLink, error) {
+	links, err := s.repo.GetCalendarLinks(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if links == nil {
+		links = []models.CalendarLink{}
+	}
+
+	return links, nil
+}
+
+// Disconnect отключает календарь. Созданные события остаются в календаре
+func (s *CalendarSyncService) Disconnect(ctx context.Context, userID, id string) error {
+	link, err := s.repo.GetCalendarLink(ctx, id)
+	if errors.Is(err, repository.ErrNotFound) || (err == nil && link.UserID != userID) {
+		return ErrCalendarLinkNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	if watcher, ok := s.clients[link.Provider].(domainService.CalendarWatcher); ok && link.Channel.ID != "" {
+		if err := watcher.StopWatch(ctx, *link); err != nil {
+			s.logger.Warn("Failed to stop calendar channel", map[string]interface{}{
+				"link_id": link.ID,
+				"error":   err.Error(),
+			})
+		}
+	}
+
+	if err := s.repo.DeleteCalendarLink(ctx, userID, id); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrCalendarLinkNotFound
+		}
+		return err
+	}
+
+	return nil
+}
+
+// HandleEvent переносит в подключенные календари пользователя созданные, измененные
+// и удаленные задачи. Задача без срока в календаре не хранится
+func (s *CalendarSyncService) HandleEvent(ctx context.Context, event models.TaskEvent) error {
+	var tasks []models.Task
+	deleted := false
+	switch event.Type {
+	case models.EventTaskCreated, models.EventTaskUpdated, models.EventTaskCompleted:
+		tasks = []models.Task{event.Task}
+	case models.EventTaskDeleted:
+		tasks, deleted = []models.Task{event.Task}, true
+	case models.EventTasksDeleted:
+		tasks, deleted = event.Tasks, true
+	default:
+		return nil
+	}
+	if len(s.clients) == 0 {
+		return nil
+	}
+
+	links, err := s.repo.GetCalendarLinks(ctx, event.UserID)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, link := range links {
+		client, ok := s.clients[link.Provider]
+		if !ok {
+			continue
+		}
+		for _, task := range tasks {
+			if err := s.syncTask(ctx, client, link, task, deleted, event.Changes); err != nil {
+				errs = append(errs, fmt.Errorf("calendar %s, task %s: %w", link.ID, task.ID, err))
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// HandleGoogleNotification забирает изменения календаря по уведомлению Google. Канал
+// подтверждается токеном, выданным при его открытии; первое уведомление канала (sync) пустое
+func (s *CalendarSyncService) HandleGoogleNotification(ctx context.Context, channelID, token, resourceState string) error {
+	link, err := s.repo.GetCalendarLinkByChannel(ctx, channelID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return ErrInvalidCalendarChannel
+	}
+	if err != nil {
+		return err
+	}
+	if !hmac.Equal([]byte(token), []byte(link.Channel.Token)) {
+		return ErrInvalidCalendarChannel
+	}
+	if err := checkUserActive(ctx, s.statuses, link.UserID); err != nil {
+		return err
+	}
+
+	client, ok := s.clients[link.Provider]
+	if !ok || resourceState == "sync" {
+		return nil
+	}
+
+	return s.pull(ctx, client, *link)
+}
+
+// Poll забирает изменения календарей, не синхронизированных дольше интервала опроса, и продлевает
+// истекающие каналы уведомлений. Календари с каналом тоже опрашиваются: так подхватываются
+// изменения из потерянных уведомлений
+func (s *CalendarSyncService) Poll(ctx context.Context) error {
+	if len(s.clients) == 0 {
+		return nil
+	}
+
+	links, err := s.repo.GetCalendarLinksToSync(ctx, s.clock.Now().Add(-s.interval), calendarPollBatch)
+	if err != nil {
+		return err
+	}
+
+	for _, link := range links {
+		client, ok := s.clients[link.Provider]
+		if !ok {
+			continue
+		}
+
+		s.watch(ctx, client, &link)
+		if err := s.pull(ctx, client, link); err != nil {
+			s.logger.Warn("Failed to sync calendar", map[string]interface{}{
+				"link_id":  link.ID,
+				"provider": link.Provider,
+				"error":    err.Error(),
+			})
+		}
+	}
+
+	return nil
+}
+
+// syncTask переносит срок, заголовок и описание задачи в событие или удаляет событие
+// удаленной задачи и задачи без срока
+func (s *CalendarSyncService) syncTask(ctx context.Context, client domainService.CalendarClient, link models.CalendarLink, task models.Task, deleted bool, changes []models.FieldChange) error {
+	state, err := s.repo.GetCalendarSyncState(ctx, link.ID, task.ID)
+	if errors.Is(err, repository.ErrNotFound) {
+		state = nil
+	} else if err != nil {
+		return err
+	}
+
+	if deleted || task.DueDate.IsZero() {
+		if state == nil {
+			return nil
+		}
+		if err := client.DeleteEvent(ctx, link, state.EventID); err != nil {
+			return err
+		}
+		return s.repo.DeleteCalendarSyncState(ctx, link.ID, task.ID)
+	}
+
+	if state != nil && state.DueDate.Equal(syncedDueDate(task)) && !eventFieldsChanged(changes) {
+		return nil
+	}
+
+	return s.push(ctx, client, link, task, state)
+}
+
+// push создает или обновляет событие задачи и запоминает согласованный срок. Событие,
+// удаленное в календаре, создается заново
+func (s *CalendarSyncService) push(ctx context.Context, client domainService.CalendarClient, link models.CalendarLink, task models.Task, state *models.CalendarSyncState) error {
+	eventID := ""
+	if state != nil {
+		eventID = state.EventID
+	}
+
+	id, err := client.PutEvent(ctx, link, eventID, task)
+	if errors.Is(err, calendar.ErrEventNotFound) && eventID != "" {
+		id, err = client.PutEvent(ctx, link, "", task)
+	}
+	if err != nil {
+		return err
+	}
+
+	return s.repo.SaveCalendarSyncState(ctx, models.CalendarSyncState{
+		LinkID:   link.ID,
+		TaskID:   task.ID,
+		EventID:  id,
+		DueDate:  syncedDueDate(task),
+		SyncedAt: s.clock.Now(),
+	})
+}
+
+// pull переносит в задачи изменения событий календаря с сохраненной позиции. Ошибка календаря
+// сохраняется в подключении и позиция не двигается; ошибки отдельных событий тоже сохраняются,
+// но позиция двигается, чтобы одно событие не останавливало синхронизацию
+func (s *CalendarSyncService) pull(ctx context.Context, client domainService.CalendarClient, link models.CalendarLink) error {
+	events, token, err := client.Changes(ctx, link)
+	if errors.Is(err, calendar.ErrSyncTokenExpired) {
+		// журнал начинается заново с текущей позиции: изменения событий с прошлой синхронизации
+		// теряются, следующие переносятся как обычно
+		link.SyncToken = ""
+		events, token, err = client.Changes(ctx, link)
+	}
+	if err != nil {
+		if updateErr := s.repo.UpdateCalendarSync(ctx, link.ID, link.SyncToken, s.clock.Now(), err.Error()); updateErr != nil {
+			return updateErr
+		}
+		return err
+	}
+
+	var errs []error
+	for _, event := range events {
+		if err := s.applyEvent(ctx, client, link, event); err != nil {
+			errs = append(errs, fmt.Errorf("event %s: %w", event.ID, err))
+		}
+	}
+
+	lastError := ""
+	if err := errors.Join(errs...); err != nil {
+		lastError = err.Error()
+		s.logger.Warn("Failed to apply calendar changes", map[string]interface{}{
+			"link_id": link.ID,
+			"error":   lastError,
+		})
+	}
+
+	return s.repo.UpdateCalendarSync(ctx, link.ID, token, s.clock.Now(), lastError)
+}
+
+// applyEvent переносит начало события в срок задачи. События, созданные не синхронизацией,
+// пропускаются; удаление события только отвязывает задачу, и следующее изменение задачи
+// создаст событие заново
+func (s *CalendarSyncService) applyEvent(ctx context.Context, client domainService.CalendarClient, link models.CalendarLink, event models.CalendarEvent) error {
+	state, err := s.repo.GetCalendarSyncStateByEvent(ctx, link.ID, event.ID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if event.Deleted {
+		return s.repo.DeleteCalendarSyncState(ctx, link.ID, state.TaskID)
+	}
+
+	start := event.Start.UTC().Truncate(time.Second)
+	// начало не менялось: это эхо нашего же изменения или изменение других полей события
+	if start.Equal(state.DueDate) {
+		return nil
+	}
+
+	task, err := s.tasks.GetUserTask(ctx, link.UserID, state.TaskID)
+	if errors.Is(err, ErrTaskNotFound) {
+		// событие удалит обработчик удаления задачи
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if !syncedDueDate(task).Equal(state.DueDate) && !calendarWins(link.ConflictPolicy, event, task) {
+		return s.push(ctx, client, link, task, state)
+	}
+
+	// состояние сохраняется до изменения задачи, чтобы ее событие task.updated не отправило
+	// срок обратно в календарь
+	state.DueDate, state.SyncedAt = start, s.clock.Now()
+	if err := s.repo.SaveCalendarSyncState(ctx, *state); err != nil {
+		return err
+	}
+
+	_, err = s.tasks.PatchUserTask(ctx, link.UserID, task.ID, models.UpdateTaskRequest{DueDate: &start})
+	return err
+}
+
+// watch открывает канал уведомлений Google или продлевает истекающий. Без канала календарь
+// синхронизируется опросом, поэтому ошибки только записываются в журнал
+func (s *CalendarSyncService) watch(ctx context.Context, client domainService.CalendarClient, link *models.CalendarLink) {
+	watcher, ok := client.(domainService.CalendarWatcher)
+	if !ok || s.webhookURL == "" {
+		return
+	}
+	if link.Channel.ID != "" && (link.Channel.ExpiresAt == nil || link.Channel.ExpiresAt.After(s.clock.Now().Add(calendarChannelRenewal))) {
+		return
+	}
+
+	if link.Channel.ID != "" {
+		if err := watcher.StopWatch(ctx, *link); err != nil {
+			s.logger.Warn("Failed to stop calendar channel", map[string]interface{}{
+				"link_id": link.ID,
+				"error":   err.Error(),
+			})
+		}
+	}
+
+	secret := make([]byte, 16)
+	if _, err := rand.Read(secret); err != nil {
+		s.logger.Warn("Failed to generate calendar channel token", map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	channel, err := watcher.Watch(ctx, *link, models.CalendarChannel{
+		ID:    uuid.New().String(),
+		Token: hex.EncodeToString(secret),
+	}, s.webhookURL)
+	if err == nil {
+		err = s.repo.UpdateCalendarChannel(ctx, link.ID, channel)
+	}
+	if err != nil {
+		s.logger.Warn("Failed to watch calendar", map[string]interface{}{
+			"link_id": link.ID,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	link.Channel = channel
+}
+
+// backfill переносит в только что подключенный календарь ближайшие задачи со сроком
+func (s *CalendarSyncService) backfill(ctx context.Context, client domainService.CalendarClient, link models.CalendarLink) {
+	from := s.clock.Now()
+	tasks, err := s.tasks.GetUserTasks(ctx, link.UserID, models.TaskFilters{
+		UserID:  link.UserID,
+		DueFrom: &from,
+		Sort:    models.SortDue,
+		Limit:   calendarBackfillLimit,
+	})
+	if err != nil {
+		s.logger.Warn("Failed to load tasks for calendar", map[string]interface{}{
+			"link_id": link.ID,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	for _, task := range tasks {
+		if task.Status == models.StatusDone {
+			continue
+		}
+		if err := s.push(ctx, client, link, task, nil); err != nil {
+			s.logger.Warn("Failed to add task to calendar", map[string]interface{}{
+				"link_id": link.ID,
+				"task_id": task.ID,
+				"error":   err.Error(),
+			})
+			// календарь недоступен: остальные задачи попадут в него при следующем изменении
+			return
+		}
+	}
+}
+
+// calendarWins решает конфликт: срок изменился и в задаче, и в событии
+func calendarWins(policy models.CalendarConflictPolicy, event models.CalendarEvent, task models.Task) bool {
+	switch policy {
+	case models.ConflictCalendar:
+		return true
+	case models.ConflictTask:
+		return false
+	}
+	return event.Updated.After(task.UpdatedAt)
+}
+
+// syncedDueDate срок задачи с точностью календарей
+func syncedDueDate(task models.Task) time.Time {
+	return task.DueDate.UTC().Truncate(time.Second)
+}
+
+// eventFieldsChanged изменились поля задачи, которые показывает событие, кроме срока
+func eventFieldsChanged(changes []models.FieldChange) bool {
+	for _, change := range changes {
+		switch change.Field {
+		case "title", "description", "private":
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,317 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jmoloko/taskmange/internal/calendar"
+	"github.com/jmoloko/taskmange/internal/clock"
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/jmoloko/taskmange/internal/domain/repository"
+	domainService "github.com/jmoloko/taskmange/internal/domain/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryCalendarSync implements repository.CalendarSyncRepository
+type memoryCalendarSync struct {
+	links  []models.CalendarLink
+	states map[string]models.CalendarSyncState
+}
+
+func (r *memoryCalendarSync) CreateCalendarLink(ctx context.Context, link *models.CalendarLink) error {
+	for _, existing := range r.links {
+		if existing.UserID == link.UserID && existing.Provider == link.Provider && existing.CalendarID == link.CalendarID {
+			return repository.ErrAlreadyExists
+		}
+	}
+	r.links = append(r.links, *link)
+	return nil
+}
+
+func (r *memoryCalendarSync) GetCalendarLink(ctx context.Context, id string) (*models.CalendarLink, error) {
+	for _, link := range r.links {
+		if link.ID == id {
+			return &link, nil
+		}
+	}
+	return nil, repository.ErrNotFound
+}
+
+func (r *memoryCalendarSync) GetCalendarLinkByChannel(ctx context.Context, channelID string) (*models.CalendarLink, error) {
+	for _, link := range r.links {
+		if link.Channel.ID == channelID {
+			return &link, nil
+		}
+	}
+	return nil, repository.ErrNotFound
+}
+
+func (r *memoryCalendarSync) GetCalendarLinks(ctx context.Context, userID string) ([]models.CalendarLink, error) {
+	var links []models.CalendarLink
+	for _, link := range r.links {
+		if link.UserID == userID {
+			links = append(links, link)
+		}
+	}
+	return links, nil
+}
+
+func (r *memoryCalendarSync) DeleteCalendarLink(ctx context.Context, userID, id string) error {
+	for i, link := range r.links {
+		if link.ID == id && link.UserID == userID {
+			r.links = append(r.links[:i], r.links[i+1:]...)
+			return nil
+		}
+	}
+	return repository.ErrNotFound
+}
+
+func (r *memoryCalendarSync) GetCalendarLinksToSync(ctx context.Context, syncedBefore time.Time, limit int) ([]models.CalendarLink, error) {
+	var links []models.CalendarLink
+	for _, link := range r.links {
+		if link.SyncedAt == nil || link.SyncedAt.Before(syncedBefore) {
+			links = append(links, link)
+		}
+	}
+	return links, nil
+}
+
+func (r *memoryCalendarSync) UpdateCalendarSync(ctx context.Context, linkID, syncToken string, syncedAt time.Time, lastError string) error {
+	for i := range r.links {
+		if r.links[i].ID == linkID {
+			r.links[i].SyncToken, r.links[i].SyncedAt, r.links[i].LastError = syncToken, &syncedAt, lastError
+		}
+	}
+	return nil
+}
+
+func (r *memoryCalendarSync) UpdateCalendarChannel(ctx context.Context, linkID string, channel models.CalendarChannel) error {
+	for i := range r.links {
+		if r.links[i].ID == linkID {
+			r.links[i].Channel = channel
+		}
+	}
+	return nil
+}
+
+func (r *memoryCalendarSync) GetCalendarSyncState(ctx context.Context, linkID, taskID string) (*models.CalendarSyncState, error) {
+	state, ok := r.states[linkID+"/"+taskID]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	return &state, nil
+}
+
+func (r *memoryCalendarSync) GetCalendarSyncStateByEvent(ctx context.Context, linkID, eventID string) (*models.CalendarSyncState, error) {
+	for _, state := range r.states {
+		if state.LinkID == linkID && state.EventID == eventID {
+			return &state, nil
+		}
+	}
+	return nil, repository.ErrNotFound
+}
+
+func (r *memoryCalendarSync) SaveCalendarSyncState(ctx context.Context, state models.CalendarSyncState) error {
+	r.states[state.LinkID+"/"+state.TaskID] = state
+	return nil
+}
+
+func (r *memoryCalendarSync) DeleteCalendarSyncState(ctx context.Context, linkID, taskID string) error {
+	delete(r.states, linkID+"/"+taskID)
+	return nil
+}
+
+// stubCalendar implements domainService.CalendarClient and domainService.CalendarWatcher
+type stubCalendar struct {
+	// events начало событий по ID
+	events  map[string]time.Time
+	changes []models.CalendarEvent
+	puts    int
+	watched int
+}
+
+func (c *stubCalendar) PutEvent(ctx context.Context, link models.CalendarLink, eventID string, task models.Task) (string, error) {
+	c.puts++
+	if eventID == "" {
+		eventID = "evt-" + task.ID
+	}
+	c.events[eventID] = task.DueDate
+	return eventID, nil
+}
+
+func (c *stubCalendar) DeleteEvent(ctx context.Context, link models.CalendarLink, eventID string) error {
+	delete(c.events, eventID)
+	return nil
+}
+
+func (c *stubCalendar) Changes(ctx context.Context, link models.CalendarLink) ([]models.CalendarEvent, string, error) {
+	if link.Secret == "revoked" {
+		return nil, "", calendar.ErrUnauthorized
+	}
+	if link.SyncToken == "" {
+		return nil, "t1", nil
+	}
+	changes := c.changes
+	c.changes = nil
+	return changes, link.SyncToken + "+", nil
+}
+
+func (c *stubCalendar) Watch(ctx context.Context, link models.CalendarLink, channel models.CalendarChannel, address string) (models.CalendarChannel, error) {
+	c.watched++
+	expiresAt := time.Date(2024, 3, 17, 9, 0, 0, 0, time.UTC)
+	channel.ExpiresAt = &expiresAt
+	return channel, nil
+}
+
+func (c *stubCalendar) StopWatch(ctx context.Context, link models.CalendarLink) error {
+	return nil
+}
+
+func TestCalendarSync(t *testing.T) {
+	repo := new(MockTaskRepository)
+	logger := new(MockLogger)
+	logger.On("Info", mock.Anything, mock.Anything).Return()
+	links := &memoryCalendarSync{states: map[string]models.CalendarSyncState{}}
+	google := &stubCalendar{events: map[string]time.Time{}}
+	service := NewCalendarSyncService(links, NewTaskService(repo, nil, nil, nil, nil, nil, nil, logger), nil,
+		map[models.CalendarProvider]domainService.CalendarClient{models.CalendarGoogle: google},
+		"https://tasks.example.com/api/integrations/calendars/google/webhook", 5*time.Minute, logger)
+	now := time.Date(2024, 3, 10, 9, 0, 0, 0, time.UTC)
+	fake := clock.NewFake(now)
+	service.clock = fake
+	ctx := context.Background()
+
+	_, err := service.Connect(ctx, "user1", models.CalendarLinkRequest{Provider: models.CalendarApple})
+	assert.Equal(t, ErrCalendarUnavailable, err)
+	_, err = service.Connect(ctx, "user1", models.CalendarLinkRequest{Provider: models.CalendarGoogle})
+	assert.True(t, errors.Is(err, ErrInvalidCalendarLink))
+	_, err = service.Connect(ctx, "user1", models.CalendarLinkRequest{Provider: models.CalendarGoogle, RefreshToken: "revoked"})
+	assert.Equal(t, ErrCalendarAuth, err)
+	assert.Empty(t, links.links)
+
+	// при подключении в календарь попадают открытые задачи со сроком
+	due := time.Date(2024, 3, 12, 15, 0, 0, 0, time.UTC)
+	task := models.Task{ID: "task1", UserID: "user1", Title: "Report", DueDate: due, UpdatedAt: now}
+	repo.On("GetAll", mock.Anything, mock.MatchedBy(func(f models.TaskFilters) bool {
+		return f.UserID == "user1" && f.DueFrom != nil && f.Limit == calendarBackfillLimit
+	})).Return([]models.Task{task, {ID: "task2", UserID: "user1", Status: models.StatusDone, DueDate: due}}, nil).Once()
+
+	link, err := service.Connect(ctx, "user1", models.CalendarLinkRequest{Provider: models.CalendarGoogle, RefreshToken: "refresh"})
+	require.NoError(t, err)
+	assert.Equal(t, "primary", link.CalendarID)
+	assert.Equal(t, models.ConflictLatest, link.ConflictPolicy)
+	assert.Equal(t, "t1", links.links[0].SyncToken)
+	assert.Equal(t, 1, google.watched)
+	assert.Equal(t, map[string]time.Time{"evt-task1": due}, google.events)
+
+	_, err = service.Connect(ctx, "user1", models.CalendarLinkRequest{Provider: models.CalendarGoogle, RefreshToken: "refresh"})
+	assert.Equal(t, ErrCalendarLinkExists, err)
+
+	// событие обновляется, только если изменилось то, что в нем показано
+	require.NoError(t, service.HandleEvent(ctx, models.TaskEvent{Type: models.EventTaskCompleted, UserID: "user1", Task: task}))
+	assert.Equal(t, 1, google.puts)
+	require.NoError(t, service.HandleEvent(ctx, models.TaskEvent{Type: models.EventTaskUpdated, UserID: "user1", Task: task,
+		Changes: []models.FieldChange{{Field: "title", From: "Draft", To: "Report"}}}))
+	assert.Equal(t, 2, google.puts)
+
+	channel := links.links[0].Channel
+	assert.Equal(t, ErrInvalidCalendarChannel, service.HandleGoogleNotification(ctx, channel.ID, "forged", "exists"))
+	require.NoError(t, service.HandleGoogleNotification(ctx, channel.ID, channel.Token, "sync"))
+
+	// событие перенесли в календаре: срок задачи переносится за ним
+	moved := due.Add(2 * time.Hour)
+	google.changes = []models.CalendarEvent{{ID: "evt-task1", Start: moved, Updated: now.Add(time.Minute)}, {ID: "foreign", Start: moved}}
+	repo.On("GetByID", mock.Anything, "task1").Return(&models.Task{ID: "task1", UserID: "user1", Title: "Report",
+		Status: models.StatusPending, DueDate: due, UpdatedAt: now}, nil).Twice()
+	repo.On("Update", mock.Anything, mock.MatchedBy(func(task *models.Task) bool {
+		return task.ID == "task1" && task.DueDate.Equal(moved)
+	})).Return(nil).Once()
+	require.NoError(t, service.HandleGoogleNotification(ctx, channel.ID, channel.Token, "exists"))
+	assert.Equal(t, moved, links.states[link.ID+"/task1"].DueDate)
+	assert.Equal(t, "t1+", links.links[0].SyncToken)
+	assert.Empty(t, links.links[0].LastError)
+
+	// срок изменился и в задаче, и в событии: задача изменена позже и ее срок возвращается в календарь
+	later := moved.Add(24 * time.Hour)
+	google.changes = []models.CalendarEvent{{ID: "evt-task1", Start: due, Updated: now}}
+	repo.On("GetByID", mock.Anything, "task1").Return(&models.Task{ID: "task1", UserID: "user1", Title: "Report",
+		DueDate: later, UpdatedAt: now.Add(time.Hour)}, nil).Once()
+	now = now.Add(10 * time.Minute)
+	fake.Set(now)
+	require.NoError(t, service.Poll(ctx))
+	assert.Equal(t, later, google.events["evt-task1"])
+	assert.Equal(t, later, links.states[link.ID+"/task1"].DueDate)
+
+	// удаление события только отвязывает задачу
+	google.changes = []models.CalendarEvent{{ID: "evt-task1", Deleted: true}}
+	now = now.Add(10 * time.Minute)
+	fake.Set(now)
+	require.NoError(t, service.Poll(ctx))
+	assert.Empty(t, links.states)
+
+	require.NoError(t, service.HandleEvent(ctx, models.TaskEvent{Type: models.EventTaskUpdated, UserID: "user1", Task: task}))
+	require.NoError(t, service.HandleEvent(ctx, models.TaskEvent{Type: models.EventTaskDeleted, UserID: "user1", Task: task}))
+	assert.Empty(t, google.events)
+	assert.Empty(t, links.states)
+
+	// массовое удаление убирает события всех задач пачки
+	require.NoError(t, service.HandleEvent(ctx, models.TaskEvent{Type: models.EventTaskUpdated, UserID: "user1", Task: task}))
+	require.NotEmpty(t, google.events)
+	require.NoError(t, service.HandleEvent(ctx, models.TaskEvent{Type: models.EventTasksDeleted, UserID: "user1", Tasks: []models.Task{task}}))
+	assert.Empty(t, google.events)
+	assert.Empty(t, links.states)
+
+	assert.Equal(t, ErrCalendarLinkNotFound, service.Disconnect(ctx, "user2", link.ID))
+	require.NoError(t, service.Disconnect(ctx, "user1", link.ID))
+	list, err := service.List(ctx, "user1")
+	require.NoError(t, err)
+	assert.Empty(t, list)
+
+	repo.AssertExpectations(t)
+}
+
+func TestCalendarSync_GoogleNotificationDeactivatedOwner(t *testing.T) {
+	logger := new(MockLogger)
+	links := &memoryCalendarSync{
+		links: []models.CalendarLink{{ID: "cal1", UserID: "user1", Provider: models.CalendarGoogle, SyncToken: "t1",
+			Channel: models.CalendarChannel{ID: "channel1", Token: "token1"}}},
+		states: map[string]models.CalendarSyncState{},
+	}
+	google := &stubCalendar{events: map[string]time.Time{},
+		changes: []models.CalendarEvent{{ID: "evt-task1", Start: time.Now()}}}
+	statuses := NewUserStatusService(nil, memoryUserStatuses{"user1": false}, nil, logger)
+	service := NewCalendarSyncService(links, NewTaskService(new(MockTaskRepository), nil, nil, nil, nil, nil, nil, logger), statuses,
+		map[models.CalendarProvider]domainService.CalendarClient{models.CalendarGoogle: google}, "", 5*time.Minute, logger)
+
+	// изменения календаря деактивированного владельца не забираются и задачи не меняют
+	err := service.HandleGoogleNotification(context.Background(), "channel1", "token1", "exists")
+	assert.Equal(t, ErrUserDeactivated, err)
+	assert.Len(t, google.changes, 1)
+}
+
+func TestCalendarSync_CalDAVCollectionURL(t *testing.T) {
+	logger := new(MockLogger)
+	links := &memoryCalendarSync{states: map[string]models.CalendarSyncState{}}
+	apple := &stubCalendar{events: map[string]time.Time{}}
+	service := NewCalendarSyncService(links, NewTaskService(new(MockTaskRepository), nil, nil, nil, nil, nil, nil, logger), nil,
+		map[models.CalendarProvider]domainService.CalendarClient{models.CalendarApple: apple}, "", 5*time.Minute, logger)
+
+	// адрес коллекции задает пользователь: только https и не во внутреннюю сеть
+	for _, calendarID := range []string{
+		"caldav.icloud.com",
+		"http://p42-caldav.icloud.com/1234567/calendars/work/",
+		"https://localhost/calendars/work/",
+		"https://169.254.169.254/latest/meta-data/",
+		"https://10.0.0.5/calendars/work/",
+	} {
+		_, err := service.Connect(context.Background(), "user1", models.CalendarLinkRequest{
+			Provider: models.CalendarApple, CalendarID: calendarID, Username: "me@icloud.com", Password: "app-password",
+		})
+		assert.ErrorIs(t, err, ErrInvalidCalendarLink, calendarID)
+	}
+	assert.Empty(t, links.links)
+}
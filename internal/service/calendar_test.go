@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jmoloko/taskmange/internal/clock"
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/jmoloko/taskmange/internal/domain/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryCalendarFeeds implements repository.CalendarFeedRepository
+type memoryCalendarFeeds struct {
+	versions map[string]int
+}
+
+func (r *memoryCalendarFeeds) RotateCalendarFeed(ctx context.Context, userID string) (int, time.Time, error) {
+	r.versions[userID]++
+	return r.versions[userID], time.Now(), nil
+}
+
+func (r *memoryCalendarFeeds) GetCalendarFeedVersion(ctx context.Context, userID string) (int, error) {
+	return r.versions[userID], nil
+}
+
+func (r *memoryCalendarFeeds) DeleteCalendarFeed(ctx context.Context, userID string) error {
+	if _, ok := r.versions[userID]; !ok {
+		return repository.ErrNotFound
+	}
+	delete(r.versions, userID)
+	return nil
+}
+
+func TestCalendarService_Tokens(t *testing.T) {
+	feeds := &memoryCalendarFeeds{versions: map[string]int{}}
+	service := NewCalendarService(feeds, nil, "secret", new(MockLogger))
+	ctx := context.Background()
+
+	_, err := service.Authenticate(ctx, "garbage")
+	assert.ErrorIs(t, err, ErrInvalidCalendarToken)
+
+	feed, err := service.CreateFeed(ctx, "user1")
+	require.NoError(t, err)
+	assert.Equal(t, CalendarFeedPath+"?token="+feed.Token, feed.URL)
+
+	userID, err := service.Authenticate(ctx, feed.Token)
+	require.NoError(t, err)
+	assert.Equal(t, "user1", userID)
+
+	// токен, подписанный другим ключом, не принимается
+	other := NewCalendarService(feeds, nil, "other", new(MockLogger))
+	_, err = other.Authenticate(ctx, feed.Token)
+	assert.ErrorIs(t, err, ErrInvalidCalendarToken)
+
+	// подмена пользователя в токене ломает подпись
+	_, signature, _ := strings.Cut(feed.Token, ".")
+	payload, _, _ := strings.Cut(service.sign("user2", 1), ".")
+	_, err = service.Authenticate(ctx, payload+"."+signature)
+	assert.ErrorIs(t, err, ErrInvalidCalendarToken)
+
+	// перевыпуск отзывает прежнюю ссылку
+	rotated, err := service.CreateFeed(ctx, "user1")
+	require.NoError(t, err)
+	_, err = service.Authenticate(ctx, feed.Token)
+	assert.ErrorIs(t, err, ErrInvalidCalendarToken)
+	_, err = service.Authenticate(ctx, rotated.Token)
+	require.NoError(t, err)
+
+	require.NoError(t, service.DeleteFeed(ctx, "user1"))
+	_, err = service.Authenticate(ctx, rotated.Token)
+	assert.ErrorIs(t, err, ErrInvalidCalendarToken)
+	assert.ErrorIs(t, service.DeleteFeed(ctx, "user1"), ErrCalendarFeedNotFound)
+}
+
+func TestCalendarService_Tasks(t *testing.T) {
+	mockRepo = new(MockTaskRepository)
+	mockLogger = new(MockLogger)
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	service := NewCalendarService(&memoryCalendarFeeds{versions: map[string]int{}},
+		NewTaskService(mockRepo, nil, nil, nil, nil, nil, nil, mockLogger), "secret", mockLogger)
+	service.clock = clock.NewFake(now)
+
+	mockRepo.On("GetAll", mock.Anything, mock.MatchedBy(func(f models.TaskFilters) bool {
+		return f.UserID == "user1" && f.Sort == models.SortDue && f.DueFrom != nil && f.DueFrom.Equal(now.Add(-calendarFeedPast))
+	})).Return([]models.Task{{ID: "1", Title: "Pay rent", DueDate: now}}, nil)
+
+	tasks, err := service.Tasks(context.Background(), "user1")
+	require.NoError(t, err)
+	assert.Len(t, tasks, 1)
+	mockRepo.AssertExpectations(t)
+}
@@ -0,0 +1,124 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jmoloko/taskmange/internal/clock"
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/jmoloko/taskmange/internal/domain/repository"
+	"github.com/jmoloko/taskmange/internal/logger"
+)
+
+const (
+	defaultChangeFeedLimit = 100
+	maxChangeFeedLimit     = 1000
+)
+
+var (
+	ErrChangeFeedDisabled     = errors.New("change feed is not enabled")
+	ErrInvalidChangeFeedQuery = errors.New("invalid change feed query")
+)
+
+// ChangeFeedService журнал изменений задач для выгрузки в хранилища данных (CDC).
+// Записи формируются из конвейера событий: пакетное событие дает запись на каждую задачу
+type ChangeFeedService struct {
+	repo      repository.TaskChangeRepository
+	enabled   bool
+	retention time.Duration
+	logger    logger.Logger
+	clock     clock.Clock
+}
+
+// NewChangeFeedService создает новый экземпляр ChangeFeedService.
+// Выключенный журнал не записывает события, а чтение возвращает ErrChangeFeedDisabled
+func NewChangeFeedService(repo repository.TaskChangeRepository, enabled bool, retention time.Duration, logger logger.Logger) *ChangeFeedService {
+	return &ChangeFeedService{
+		repo:      repo,
+		enabled:   enabled,
+		retention: retention,
+		logger:    logger,
+		clock:     clock.System,
+	}
+}
+
+// HandleEvent записывает событие задач в журнал. Подписывается на конвейер событий
+func (s *ChangeFeedService) HandleEvent(ctx context.Context, event models.TaskEvent) error {
+	if !s.enabled {
+		return nil
+	}
+
+	changeType := event.Type
+	tasks := event.Tasks
+	if len(tasks) == 0 {
+		tasks = []models.Task{event.Task}
+	} else {
+		// потребителю журнала не важно, как задачи выполнялись и удалялись: пакетом или по одной
+		switch changeType {
+		case models.EventTasksCompleted:
+			changeType = models.EventTaskCompleted
+		case models.EventTasksDeleted:
+			changeType = models.EventTaskDeleted
+		}
+	}
+
+	changes := make([]models.TaskChange, 0, len(tasks))
+	for _, task := range tasks {
+		changes = append(changes, models.TaskChange{
+			TaskID:     task.ID,
+			UserID:     task.UserID,
+			Type:       changeType,
+			Task:       task,
+			Changes:    event.Changes,
+			OccurredAt: event.OccurredAt,
+		})
+	}
+
+	return s.repo.AppendTaskChanges(ctx, changes)
+}
+
+// Changes страница журнала после seq after. Пустая страница возвращает Next = after,
+// чтобы потребитель повторил запрос с тем же курсором
+func (s *ChangeFeedService) Changes(ctx context.Context, after int64, limit int) (models.TaskChangeFeed, error) {
+	if !s.enabled {
+		return models.TaskChangeFeed{}, ErrChangeFeedDisabled
+	}
+	if limit == 0 {
+		limit = defaultChangeFeedLimit
+	}
+	if after < 0 || limit < 1 || limit > maxChangeFeedLimit {
+		return models.TaskChangeFeed{}, ErrInvalidChangeFeedQuery
+	}
+
+	changes, err := s.repo.GetTaskChanges(ctx, after, limit)
+	if err != nil {
+		return models.TaskChangeFeed{}, err
+	}
+
+	feed := models.TaskChangeFeed{Changes: changes, Next: after}
+	if feed.Changes == nil {
+		feed.Changes = []models.TaskChange{}
+	}
+	if len(changes) > 0 {
+		feed.Next = changes[len(changes)-1].Seq
+	}
+
+	return feed, nil
+}
+
+// Cleanup удаляет записи старше срока хранения. Вызывается фоновым воркером
+func (s *ChangeFeedService) Cleanup(ctx context.Context) error {
+	deleted, err := s.repo.DeleteTaskChanges(ctx, s.clock.Now().Add(-s.retention))
+	if err != nil {
+		return err
+	}
+
+	if deleted > 0 {
+		s.logger.Info("Deleted expired task changes", map[string]interface{}{
+			"count": deleted,
+		})
+	}
+
+	return nil
+}
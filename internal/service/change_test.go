@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryTaskChanges implements repository.TaskChangeRepository
+type memoryTaskChanges struct {
+	changes  []models.TaskChange
+	versions map[string]int64
+}
+
+func (r *memoryTaskChanges) AppendTaskChanges(ctx context.Context, changes []models.TaskChange) error {
+	for _, change := range changes {
+		r.versions[change.TaskID]++
+		change.Seq = int64(len(r.changes) + 1)
+		change.Version = r.versions[change.TaskID]
+		r.changes = append(r.changes, change)
+	}
+	return nil
+}
+
+func (r *memoryTaskChanges) GetTaskChanges(ctx context.Context, after int64, limit int) ([]models.TaskChange, error) {
+	var changes []models.TaskChange
+	for _, change := range r.changes {
+		if change.Seq > after && len(changes) < limit {
+			changes = append(changes, change)
+		}
+	}
+	return changes, nil
+}
+
+func (r *memoryTaskChanges) DeleteTaskChanges(ctx context.Context, before time.Time) (int64, error) {
+	return 0, nil
+}
+
+func TestChangeFeed(t *testing.T) {
+	repo := &memoryTaskChanges{versions: map[string]int64{}}
+	service := NewChangeFeedService(repo, true, time.Hour, new(MockLogger))
+	ctx := context.Background()
+
+	task := models.Task{ID: "t1", UserID: "user1", Title: "Report"}
+	require.NoError(t, service.HandleEvent(ctx, models.TaskEvent{Type: models.EventTaskCreated, UserID: "user1", Task: task}))
+	require.NoError(t, service.HandleEvent(ctx, models.TaskEvent{
+		Type:   models.EventTasksCompleted,
+		UserID: "user1",
+		Tasks:  []models.Task{task, {ID: "t2", UserID: "user1"}},
+	}))
+
+	feed, err := service.Changes(ctx, 0, 2)
+	require.NoError(t, err)
+	require.Len(t, feed.Changes, 2)
+	assert.Equal(t, int64(2), feed.Next)
+	// пакетное выполнение раскладывается на записи по задачам со своими номерами изменений
+	assert.Equal(t, models.EventTaskCompleted, feed.Changes[1].Type)
+	assert.Equal(t, int64(2), feed.Changes[1].Version)
+
+	feed, err = service.Changes(ctx, feed.Next, 0)
+	require.NoError(t, err)
+	require.Len(t, feed.Changes, 1)
+	assert.Equal(t, "t2", feed.Changes[0].TaskID)
+	assert.Equal(t, int64(1), feed.Changes[0].Version)
+
+	feed, err = service.Changes(ctx, feed.Next, 0)
+	require.NoError(t, err)
+	assert.Empty(t, feed.Changes)
+	assert.Equal(t, int64(3), feed.Next)
+
+	_, err = service.Changes(ctx, -1, 0)
+	assert.ErrorIs(t, err, ErrInvalidChangeFeedQuery)
+	_, err = service.Changes(ctx, 0, maxChangeFeedLimit+1)
+	assert.ErrorIs(t, err, ErrInvalidChangeFeedQuery)
+
+	disabled := NewChangeFeedService(repo, false, time.Hour, new(MockLogger))
+	require.NoError(t, disabled.HandleEvent(ctx, models.TaskEvent{Type: models.EventTaskDeleted, Task: task}))
+	assert.Len(t, repo.changes, 3)
+	_, err = disabled.Changes(ctx, 0, 0)
+	assert.ErrorIs(t, err, ErrChangeFeedDisabled)
+}
@@ -0,0 +1,193 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/jmoloko/taskmange/internal/domain/repository"
+	domainService "github.com/jmoloko/taskmange/internal/domain/service"
+	"github.com/jmoloko/taskmange/internal/integration"
+	"github.com/jmoloko/taskmange/internal/logger"
+)
+
+// githubWebhookPath путь приема push-событий, к нему добавляется ID подключения
+const githubWebhookPath = "/api/integrations/github/webhook/"
+
+var githubRepositoryPattern = regexp.MustCompile(`^[A-Za-z0-9_.-]+/[A-Za-z0-9_.-]+$`)
+
+var (
+	ErrInvalidRepository  = errors.New("repository must be owner/repo")
+	ErrRepoLinkExists     = errors.New("repository is already connected")
+	ErrRepoLinkNotFound   = errors.New("repository link not found")
+	ErrInvalidSignature   = errors.New("invalid webhook signature")
+	ErrRepositoryMismatch = errors.New("push event is for another repository")
+	ErrInvalidPushEvent   = errors.New("invalid push event")
+)
+
+// CommitService закрытие задач по сообщениям коммитов в подключенных репозиториях GitHub
+type CommitService struct {
+	links    repository.GitHubRepoLinkRepository
+	refs     repository.TaskRefResolver
+	tasks    domainService.TaskUpdater
+	statuses domainService.UserStatusChecker
+	logger   logger.Logger
+}
+
+// NewCommitService создает новый экземпляр CommitService.
+// statuses nil — коммиты закрывают задачи и деактивированных владельцев
+func NewCommitService(links repository.GitHubRepoLinkRepository, refs repository.TaskRefResolver, tasks domainService.TaskUpdater, statuses domainService.UserStatusChecker, logger logger.Logger) *CommitService {
+	return &CommitService{
+		links:    links,
+		refs:     refs,
+		tasks:    tasks,
+		statuses: statuses,
+		logger:   logger,
+	}
+}
+
+// Connect подключает репозиторий пользователя. Секрет и путь webhook указываются в настройках
+// репозитория GitHub, секрет возвращается только в этом ответе
+func (s *CommitService) Connect(ctx context.Context, userID, repo string) (*models.GitHubRepoLink, error) {
+	repo = strings.TrimSpace(repo)
+	if !githubRepositoryPattern.MatchString(repo) {
+		return nil, ErrInvalidRepository
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, fmt.Errorf("failed to generate webhook secret: %w", err)
+	}
+
+	link := &models.GitHubRepoLink{
+		ID:         uuid.New().String(),
+		UserID:     userID,
+		Repository: strings.ToLower(repo),
+		Secret:     hex.EncodeToString(secret),
+	}
+	if err := s.links.CreateRepoLink(ctx, link); err != nil {
+		if errors.Is(err, repository.ErrAlreadyExists) {
+			return nil, ErrRepoLinkExists
+		}
+		return nil, err
+	}
+	link.WebhookPath = githubWebhookPath + link.ID
+
+	return link, nil
+}
+
+// List подключенные репозитории пользователя
+func (s *CommitService) List(ctx context.Context, userID string) ([]models.GitHubRepoLink, error) {
+	links, err := s.links.GetRepoLinks(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if links == nil {
+		links = []models.GitHubRepoLink{}
+	}
+	for i := range links {
+		links[i].WebhookPath = githubWebhookPath + links[i].ID
+	}
+
+	return links, nil
+}
+
+// Disconnect отключает репозиторий, дальнейшие события по нему отклоняются
+func (s *CommitService) Disconnect(ctx context.Context, userID, linkID string) error {
+	if err := s.links.DeleteRepoLink(ctx, userID, linkID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrRepoLinkNotFound
+		}
+		return err
+	}
+
+	return nil
+}
+
+// HandlePush обрабатывает событие GitHub: проверяет подпись и для push в основную ветку
+// выполняет задачи, на которые ссылаются коммиты ("closes TM-1a2b3c4d").
+// Остальные события (ping и другие) подтверждаются без действий
+func (s *CommitService) HandlePush(ctx context.Context, linkID, event, signature string, body []byte) (models.CommitAutomationResult, error) {
+	result := models.CommitAutomationResult{Completed: []string{}, Unresolved: []string{}}
+
+	link, err := s.links.GetRepoLink(ctx, linkID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return result, ErrRepoLinkNotFound
+		}
+		return result, err
+	}
+
+	if !integration.VerifyGitHubSignature(link.Secret, body, signature) {
+		return result, ErrInvalidSignature
+	}
+	if err := checkUserActive(ctx, s.statuses, link.UserID); err != nil {
+		return result, err
+	}
+
+	if event != "push" {
+		return result, nil
+	}
+
+	var push integration.GitHubPushEvent
+	if err := json.Unmarshal(body, &push); err != nil {
+		return result, ErrInvalidPushEvent
+	}
+	if !strings.EqualFold(push.Repository.FullName, link.Repository) {
+		return result, ErrRepositoryMismatch
+	}
+	// как и ключевые слова GitHub, ссылки действуют только после попадания в основную ветку
+	if !push.ToDefaultBranch() {
+		return result, nil
+	}
+
+	var ids []string
+	seen := make(map[string]bool)
+	for _, commit := range push.Commits {
+		for _, ref := range integration.ParseClosingRefs(commit.Message) {
+			if seen[ref] {
+				continue
+			}
+			seen[ref] = true
+
+			matches, err := s.refs.FindTaskIDsByPrefix(ctx, link.UserID, ref)
+			if err != nil {
+				return result, err
+			}
+			if len(matches) != 1 {
+				result.Unresolved = append(result.Unresolved, models.TaskRefPrefix+ref)
+				continue
+			}
+			ids = append(ids, matches[0])
+		}
+	}
+
+	if len(ids) == 0 {
+		return result, nil
+	}
+
+	completed, err := s.tasks.CompleteTasks(ctx, link.UserID, ids)
+	if err != nil {
+		return result, err
+	}
+	for _, task := range completed.Completed {
+		result.Completed = append(result.Completed, task.ID)
+	}
+
+	s.logger.Info("Tasks completed by commits", map[string]interface{}{
+		"user_id":    link.UserID,
+		"repository": link.Repository,
+		"completed":  len(result.Completed),
+		"unresolved": len(result.Unresolved),
+	})
+
+	return result, nil
+}
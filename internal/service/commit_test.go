@@ -0,0 +1,152 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/jmoloko/taskmange/internal/domain/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryRepoLinks implements repository.GitHubRepoLinkRepository
+type memoryRepoLinks struct {
+	links map[string]models.GitHubRepoLink
+}
+
+func (r *memoryRepoLinks) CreateRepoLink(ctx context.Context, link *models.GitHubRepoLink) error {
+	for _, l := range r.links {
+		if l.UserID == link.UserID && l.Repository == link.Repository {
+			return repository.ErrAlreadyExists
+		}
+	}
+	r.links[link.ID] = *link
+	return nil
+}
+
+func (r *memoryRepoLinks) GetRepoLink(ctx context.Context, id string) (*models.GitHubRepoLink, error) {
+	link, ok := r.links[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	return &link, nil
+}
+
+func (r *memoryRepoLinks) GetRepoLinks(ctx context.Context, userID string) ([]models.GitHubRepoLink, error) {
+	var links []models.GitHubRepoLink
+	for _, link := range r.links {
+		if link.UserID == userID {
+			link.Secret = ""
+			links = append(links, link)
+		}
+	}
+	return links, nil
+}
+
+func (r *memoryRepoLinks) DeleteRepoLink(ctx context.Context, userID, id string) error {
+	if link, ok := r.links[id]; !ok || link.UserID != userID {
+		return repository.ErrNotFound
+	}
+	delete(r.links, id)
+	return nil
+}
+
+// prefixResolver implements repository.TaskRefResolver
+type prefixResolver map[string][]string
+
+func (r prefixResolver) FindTaskIDsByPrefix(ctx context.Context, userID, prefix string) ([]string, error) {
+	return r[userID+":"+prefix], nil
+}
+
+func signGitHub(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestCommitServiceHandlePush(t *testing.T) {
+	repo := new(MockTaskRepository)
+	logger := new(MockLogger)
+	links := &memoryRepoLinks{links: map[string]models.GitHubRepoLink{}}
+	resolver := prefixResolver{
+		"user1:1a2b3c4d": {"1a2b3c4d-0000-0000-0000-000000000001"},
+		"user1:00ff00ff": {"00ff00ff-0000-0000-0000-000000000001", "00ff00ff-0000-0000-0000-000000000002"},
+	}
+	service := NewCommitService(links, resolver, NewTaskService(repo, nil, nil, nil, nil, nil, nil, logger), nil, logger)
+	ctx := context.Background()
+
+	_, err := service.Connect(ctx, "user1", "not a repo")
+	assert.Equal(t, ErrInvalidRepository, err)
+
+	link, err := service.Connect(ctx, "user1", "Acme/API")
+	require.NoError(t, err)
+	assert.Equal(t, "acme/api", link.Repository)
+	assert.Equal(t, "/api/integrations/github/webhook/"+link.ID, link.WebhookPath)
+	_, err = service.Connect(ctx, "user1", "acme/api")
+	assert.Equal(t, ErrRepoLinkExists, err)
+
+	body := []byte(`{"ref":"refs/heads/main","repository":{"full_name":"Acme/API","default_branch":"main"},
+		"commits":[{"id":"a1","message":"Fix redirect\n\ncloses TM-1a2b3c4d"},{"id":"a2","message":"fixes TM-00ff00ff, fixes TM-99999999"}]}`)
+
+	_, err = service.HandlePush(ctx, link.ID, "push", signGitHub("wrong", body), body)
+	assert.Equal(t, ErrInvalidSignature, err)
+
+	// ping подтверждается без действий
+	result, err := service.HandlePush(ctx, link.ID, "ping", signGitHub(link.Secret, []byte(`{}`)), []byte(`{}`))
+	require.NoError(t, err)
+	assert.Empty(t, result.Completed)
+
+	repo.On("GetOpenDescendantIDs", mock.Anything, mock.Anything).Return([]string(nil), nil)
+	repo.On("CompleteTasks", mock.Anything, "user1", []string{"1a2b3c4d-0000-0000-0000-000000000001"}).
+		Return([]models.Task{{ID: "1a2b3c4d-0000-0000-0000-000000000001", UserID: "user1", Status: models.StatusDone}}, nil).Once()
+	logger.On("Info", mock.Anything, mock.Anything).Return()
+
+	result, err = service.HandlePush(ctx, link.ID, "push", signGitHub(link.Secret, body), body)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1a2b3c4d-0000-0000-0000-000000000001"}, result.Completed)
+	// неоднозначная и несуществующая ссылки
+	assert.Equal(t, []string{"TM-00ff00ff", "TM-99999999"}, result.Unresolved)
+
+	// push в другую ветку не закрывает задачи
+	branch := []byte(`{"ref":"refs/heads/feature","repository":{"full_name":"acme/api","default_branch":"main"},"commits":[{"message":"closes TM-1a2b3c4d"}]}`)
+	result, err = service.HandlePush(ctx, link.ID, "push", signGitHub(link.Secret, branch), branch)
+	require.NoError(t, err)
+	assert.Empty(t, result.Completed)
+
+	other := []byte(`{"ref":"refs/heads/main","repository":{"full_name":"acme/web","default_branch":"main"}}`)
+	_, err = service.HandlePush(ctx, link.ID, "push", signGitHub(link.Secret, other), other)
+	assert.Equal(t, ErrRepositoryMismatch, err)
+
+	require.NoError(t, service.Disconnect(ctx, "user1", link.ID))
+	_, err = service.HandlePush(ctx, link.ID, "push", signGitHub(link.Secret, body), body)
+	assert.Equal(t, ErrRepoLinkNotFound, err)
+
+	repo.AssertExpectations(t)
+}
+
+func TestCommitServiceHandlePush_DeactivatedOwner(t *testing.T) {
+	repo := new(MockTaskRepository)
+	logger := new(MockLogger)
+	links := &memoryRepoLinks{links: map[string]models.GitHubRepoLink{}}
+	resolver := prefixResolver{"user1:1a2b3c4d": {"1a2b3c4d-0000-0000-0000-000000000001"}}
+	statuses := NewUserStatusService(nil, memoryUserStatuses{"user1": false}, nil, logger)
+	service := NewCommitService(links, resolver, NewTaskService(repo, nil, nil, nil, nil, nil, nil, logger), statuses, logger)
+	ctx := context.Background()
+
+	link, err := service.Connect(ctx, "user1", "acme/api")
+	require.NoError(t, err)
+
+	body := []byte(`{"ref":"refs/heads/main","repository":{"full_name":"acme/api","default_branch":"main"},"commits":[{"message":"closes TM-1a2b3c4d"}]}`)
+	// подпись проверяется раньше статуса: без секрета нельзя узнать, деактивирован ли владелец
+	_, err = service.HandlePush(ctx, link.ID, "push", signGitHub("wrong", body), body)
+	assert.Equal(t, ErrInvalidSignature, err)
+
+	_, err = service.HandlePush(ctx, link.ID, "push", signGitHub(link.Secret, body), body)
+	assert.Equal(t, ErrUserDeactivated, err)
+	repo.AssertNotCalled(t, "CompleteTasks", mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,112 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/jmoloko/taskmange/internal/domain/repository"
+	"github.com/jmoloko/taskmange/internal/metrics"
+	"github.com/jmoloko/taskmange/internal/tracing"
+)
+
+// максимальное число задач в одном пакетном выполнении
+const maxCompleteBatch = 500
+
+// CompleteTasks переводит задачи пользователя в статус done одним запросом в транзакции.
+// Чужая или несуществующая задача отменяет весь пакет, уже выполненные задачи пропускаются.
+// Открытые подзадачи, не вошедшие в пакет, отменяют его с ErrOpenSubtasks.
+// На пакет публикуется одно событие tasks.completed
+func (s *TaskServiceImpl) CompleteTasks(ctx context.Context, userID string, ids []string) (models.CompleteTasksResult, error) {
+	ctx, span := tracing.Start(ctx, "TaskService.CompleteTasks")
+	defer span.End()
+
+	ids, ok := uniqueTaskIDs(ids)
+	if !ok || len(ids) == 0 || len(ids) > maxCompleteBatch {
+		return models.CompleteTasksResult{}, ErrInvalidTaskData
+	}
+
+	// задачу с открытыми подзадачами можно выполнить, только если они в том же пакете
+	open, err := s.repo.GetOpenDescendantIDs(ctx, ids)
+	if err != nil {
+		return models.CompleteTasksResult{}, err
+	}
+	inBatch := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		inBatch[id] = true
+	}
+	for _, id := range open {
+		if !inBatch[id] {
+			return models.CompleteTasksResult{}, ErrOpenSubtasks
+		}
+	}
+
+	completed, err := s.repo.CompleteTasks(ctx, userID, ids)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return models.CompleteTasksResult{}, ErrTaskNotFound
+		}
+		s.logger.Error("Failed to complete tasks", map[string]interface{}{
+			"user_id": userID,
+			"count":   len(ids),
+			"error":   err.Error(),
+		})
+		return models.CompleteTasksResult{}, err
+	}
+
+	result := models.CompleteTasksResult{
+		Completed: markOverdue(lockTasks(completed), s.clock.Now()),
+		Skipped:   []string{},
+	}
+	if result.Completed == nil {
+		result.Completed = []models.Task{}
+	}
+
+	done := make(map[string]bool, len(completed))
+	completedIDs := make([]string, 0, len(completed))
+	for _, task := range completed {
+		done[task.ID] = true
+		completedIDs = append(completedIDs, task.ID)
+	}
+	if len(completedIDs) > 0 {
+		s.invalidateTask(ctx, userID, completedIDs...)
+	}
+	for _, id := range ids {
+		if !done[id] {
+			result.Skipped = append(result.Skipped, id)
+		}
+	}
+
+	metrics.TasksCompletedTotal.WithLabelValues(metrics.Tenant(userID)).Add(float64(len(completed)))
+	s.logger.Info("Tasks completed", map[string]interface{}{
+		"user_id":   userID,
+		"completed": len(completed),
+		"skipped":   len(result.Skipped),
+	})
+
+	if len(completed) > 0 {
+		s.emit(ctx, models.TaskEvent{
+			Type:   models.EventTasksCompleted,
+			UserID: userID,
+			Tasks:  result.Completed,
+		})
+	}
+
+	return result, nil
+}
+
+// uniqueTaskIDs убирает повторы с сохранением порядка, false — в списке есть пустой ID
+func uniqueTaskIDs(ids []string) ([]string, bool) {
+	seen := make(map[string]bool, len(ids))
+	unique := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if id == "" {
+			return nil, false
+		}
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique, true
+}
@@ -0,0 +1,83 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/jmoloko/taskmange/internal/domain/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingPublisher implements domainService.EventPublisher
+type recordingPublisher struct {
+	events []models.TaskEvent
+}
+
+func (p *recordingPublisher) Publish(ctx context.Context, event models.TaskEvent) {
+	p.events = append(p.events, event)
+}
+
+func TestCompleteTasks(t *testing.T) {
+	mockRepo = new(MockTaskRepository)
+	mockLogger = new(MockLogger)
+	publisher := &recordingPublisher{}
+	service := NewTaskService(mockRepo, nil, nil, publisher, nil, nil, nil, mockLogger)
+	ctx := context.Background()
+
+	_, err := service.CompleteTasks(ctx, "user1", nil)
+	assert.Equal(t, ErrInvalidTaskData, err)
+	_, err = service.CompleteTasks(ctx, "user1", []string{"a", ""})
+	assert.Equal(t, ErrInvalidTaskData, err)
+
+	completed := []models.Task{
+		{ID: "a", UserID: "user1", Title: "A", Status: models.StatusDone},
+		{ID: "b", UserID: "user1", Title: "secret", Private: true, Status: models.StatusDone},
+	}
+	mockRepo.On("GetOpenDescendantIDs", mock.Anything, mock.Anything).Return([]string(nil), nil)
+	mockRepo.On("CompleteTasks", mock.Anything, "user1", []string{"a", "b", "c"}).Return(completed, nil).Once()
+	mockLogger.On("Info", "Tasks completed", mock.Anything).Return().Once()
+
+	result, err := service.CompleteTasks(ctx, "user1", []string{"a", "b", "a", "c"})
+	require.NoError(t, err)
+	require.Len(t, result.Completed, 2)
+	assert.True(t, result.Completed[1].Locked)
+	assert.Equal(t, []string{"c"}, result.Skipped)
+
+	// одно событие на весь пакет
+	require.Len(t, publisher.events, 1)
+	assert.Equal(t, models.EventTasksCompleted, publisher.events[0].Type)
+	assert.Len(t, publisher.events[0].Tasks, 2)
+
+	mockRepo.On("CompleteTasks", mock.Anything, "user1", []string{"a", "foreign"}).Return([]models.Task(nil), repository.ErrNotFound).Once()
+	_, err = service.CompleteTasks(ctx, "user1", []string{"a", "foreign"})
+	assert.Equal(t, ErrTaskNotFound, err)
+
+	// все задачи уже выполнены: событие не публикуется
+	mockRepo.On("CompleteTasks", mock.Anything, "user1", []string{"a"}).Return([]models.Task(nil), nil).Once()
+	mockLogger.On("Info", "Tasks completed", mock.Anything).Return().Once()
+	result, err = service.CompleteTasks(ctx, "user1", []string{"a"})
+	require.NoError(t, err)
+	assert.Empty(t, result.Completed)
+	assert.NotNil(t, result.Completed)
+	assert.Equal(t, []string{"a"}, result.Skipped)
+	assert.Len(t, publisher.events, 1)
+
+	// открытая подзадача вне пакета отменяет его, вместе с подзадачей пакет выполняется
+	mockRepo = new(MockTaskRepository)
+	service = NewTaskService(mockRepo, nil, nil, publisher, nil, nil, nil, mockLogger)
+	mockRepo.On("GetOpenDescendantIDs", mock.Anything, []string{"parent"}).Return([]string{"child"}, nil).Once()
+	_, err = service.CompleteTasks(ctx, "user1", []string{"parent"})
+	assert.Equal(t, ErrOpenSubtasks, err)
+
+	mockRepo.On("GetOpenDescendantIDs", mock.Anything, []string{"parent", "child"}).Return([]string{"child"}, nil).Once()
+	mockRepo.On("CompleteTasks", mock.Anything, "user1", []string{"parent", "child"}).Return([]models.Task(nil), nil).Once()
+	mockLogger.On("Info", "Tasks completed", mock.Anything).Return().Once()
+	_, err = service.CompleteTasks(ctx, "user1", []string{"parent", "child"})
+	require.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+
+	mockRepo.AssertExpectations(t)
+}
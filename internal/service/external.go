@@ -0,0 +1,191 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoloko/taskmange/internal/clock"
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/jmoloko/taskmange/internal/domain/repository"
+	domainService "github.com/jmoloko/taskmange/internal/domain/service"
+	"github.com/jmoloko/taskmange/internal/integration"
+	"github.com/jmoloko/taskmange/internal/logger"
+)
+
+// сколько связей проверяется за один запуск опроса трекеров
+const externalPollBatch = 100
+
+var (
+	ErrExternalRefNotFound   = errors.New("external ref not found")
+	ErrInvalidExternalRef    = errors.New("invalid external reference")
+	ErrTrackerUnavailable    = errors.New("external tracker is not configured")
+	ErrExternalIssueNotFound = errors.New("external issue not found")
+)
+
+// ExternalRefService связи задач с задачами GitHub и Jira
+type ExternalRefService struct {
+	repo     repository.ExternalRefRepository
+	tasks    domainService.TaskManager
+	trackers map[models.ExternalProvider]domainService.IssueTracker
+	// interval как часто обновляется состояние каждой внешней задачи
+	interval time.Duration
+	logger   logger.Logger
+	clock    clock.Clock
+}
+
+// NewExternalRefService создает новый экземпляр ExternalRefService.
+// trackers — настроенные в развертывании трекеры, связи с остальными создать нельзя
+func NewExternalRefService(repo repository.ExternalRefRepository, tasks domainService.TaskManager, trackers map[models.ExternalProvider]domainService.IssueTracker, interval time.Duration, logger logger.Logger) *ExternalRefService {
+	return &ExternalRefService{
+		repo:     repo,
+		tasks:    tasks,
+		trackers: trackers,
+		interval: interval,
+		logger:   logger,
+		clock:    clock.System,
+	}
+}
+
+// Link связывает задачу пользователя с внешней задачей и сразу запрашивает ее состояние.
+// Если трекер недоступен, связь сохраняется и состояние заполнит следующий опрос
+func (s *ExternalRefService) Link(ctx context.Context, userID, taskID string, req models.LinkExternalRequest) (*models.ExternalRef, error) {
+	tracker, ok := s.trackers[req.Provider]
+	if !ok {
+		return nil, ErrTrackerUnavailable
+	}
+
+	key, err := tracker.ParseRef(req.Ref)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidExternalRef, req.Ref)
+	}
+
+	if _, err := s.tasks.GetUserTask(ctx, userID, taskID); err != nil {
+		return nil, err
+	}
+
+	ref := &models.ExternalRef{
+		ID:               uuid.New().String(),
+		TaskID:           taskID,
+		UserID:           userID,
+		Provider:         req.Provider,
+		Key:              key,
+		MirrorCompletion: req.MirrorCompletion,
+	}
+
+	issue, err := tracker.FetchIssue(ctx, key)
+	switch {
+	case errors.Is(err, integration.ErrIssueNotFound):
+		return nil, ErrExternalIssueNotFound
+	case err != nil:
+		s.logger.Warn("Failed to fetch external issue", map[string]interface{}{
+			"provider": req.Provider,
+			"key":      key,
+			"error":    err.Error(),
+		})
+	default:
+		now := s.clock.Now()
+		ref.ExternalIssue = issue
+		ref.CheckedAt = &now
+	}
+
+	if err := s.repo.SaveExternalRef(ctx, ref); err != nil {
+		return nil, err
+	}
+
+	return ref, nil
+}
+
+// List связи задачи пользователя
+func (s *ExternalRefService) List(ctx context.Context, userID, taskID string) ([]models.ExternalRef, error) {
+	if _, err := s.tasks.GetUserTask(ctx, userID, taskID); err != nil {
+		return nil, err
+	}
+
+	refs, err := s.repo.GetExternalRefs(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	if refs == nil {
+		refs = []models.ExternalRef{}
+	}
+
+	return refs, nil
+}
+
+// Unlink удаляет связь задачи пользователя
+func (s *ExternalRefService) Unlink(ctx context.Context, userID, taskID, refID string) error {
+	if _, err := s.tasks.GetUserTask(ctx, userID, taskID); err != nil {
+		return err
+	}
+
+	if err := s.repo.DeleteExternalRef(ctx, taskID, refID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrExternalRefNotFound
+		}
+		return err
+	}
+
+	return nil
+}
+
+// Poll обновляет состояние внешних задач, не проверявшихся дольше интервала опроса.
+// Когда внешняя задача закрывается, связанная задача с MirrorCompletion переводится в done
+func (s *ExternalRefService) Poll(ctx context.Context) error {
+	now := s.clock.Now()
+	refs, err := s.repo.GetExternalRefsToCheck(ctx, now.Add(-s.interval), externalPollBatch)
+	if err != nil {
+		return err
+	}
+
+	for _, ref := range refs {
+		tracker, ok := s.trackers[ref.Provider]
+		if !ok {
+			continue
+		}
+
+		issue, err := tracker.FetchIssue(ctx, ref.Key)
+		if err != nil {
+			s.logger.Warn("Failed to fetch external issue", map[string]interface{}{
+				"ref_id":   ref.ID,
+				"provider": ref.Provider,
+				"key":      ref.Key,
+				"error":    err.Error(),
+			})
+			// откладываем повторную проверку до следующего интервала, чтобы недоступная
+			// задача не занимала пакет опроса
+			issue = ref.ExternalIssue
+		}
+
+		if err := s.repo.UpdateExternalIssue(ctx, ref.ID, issue, now); err != nil {
+			return err
+		}
+
+		if issue.Closed && !ref.Closed && ref.MirrorCompletion {
+			s.mirrorCompletion(ctx, ref)
+		}
+	}
+
+	return nil
+}
+
+// mirrorCompletion выполняет задачу, связанную с закрытой внешней задачей
+func (s *ExternalRefService) mirrorCompletion(ctx context.Context, ref models.ExternalRef) {
+	if _, err := s.tasks.CompleteTasks(ctx, ref.UserID, []string{ref.TaskID}); err != nil {
+		s.logger.Warn("Failed to mirror external issue completion", map[string]interface{}{
+			"ref_id":  ref.ID,
+			"task_id": ref.TaskID,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	s.logger.Info("Task completed by external issue", map[string]interface{}{
+		"task_id":  ref.TaskID,
+		"provider": ref.Provider,
+		"key":      ref.Key,
+	})
+}
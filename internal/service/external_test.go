@@ -0,0 +1,147 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jmoloko/taskmange/internal/clock"
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/jmoloko/taskmange/internal/domain/repository"
+	domainService "github.com/jmoloko/taskmange/internal/domain/service"
+	"github.com/jmoloko/taskmange/internal/integration"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryExternalRefs implements repository.ExternalRefRepository
+type memoryExternalRefs struct {
+	refs []models.ExternalRef
+}
+
+func (r *memoryExternalRefs) SaveExternalRef(ctx context.Context, ref *models.ExternalRef) error {
+	r.refs = append(r.refs, *ref)
+	return nil
+}
+
+func (r *memoryExternalRefs) GetExternalRefs(ctx context.Context, taskID string) ([]models.ExternalRef, error) {
+	var refs []models.ExternalRef
+	for _, ref := range r.refs {
+		if ref.TaskID == taskID {
+			refs = append(refs, ref)
+		}
+	}
+	return refs, nil
+}
+
+func (r *memoryExternalRefs) DeleteExternalRef(ctx context.Context, taskID, refID string) error {
+	for i, ref := range r.refs {
+		if ref.ID == refID && ref.TaskID == taskID {
+			r.refs = append(r.refs[:i], r.refs[i+1:]...)
+			return nil
+		}
+	}
+	return repository.ErrNotFound
+}
+
+func (r *memoryExternalRefs) GetExternalRefsToCheck(ctx context.Context, checkedBefore time.Time, limit int) ([]models.ExternalRef, error) {
+	var refs []models.ExternalRef
+	for _, ref := range r.refs {
+		if ref.CheckedAt == nil || ref.CheckedAt.Before(checkedBefore) {
+			refs = append(refs, ref)
+		}
+	}
+	return refs, nil
+}
+
+func (r *memoryExternalRefs) UpdateExternalIssue(ctx context.Context, refID string, issue models.ExternalIssue, checkedAt time.Time) error {
+	for i := range r.refs {
+		if r.refs[i].ID == refID {
+			r.refs[i].ExternalIssue = issue
+			r.refs[i].CheckedAt = &checkedAt
+		}
+	}
+	return nil
+}
+
+// stubTracker implements domainService.IssueTracker
+type stubTracker struct {
+	issues map[string]models.ExternalIssue
+}
+
+func (t *stubTracker) ParseRef(ref string) (string, error) {
+	if ref == "" {
+		return "", integration.ErrInvalidRef
+	}
+	return ref, nil
+}
+
+func (t *stubTracker) FetchIssue(ctx context.Context, key string) (models.ExternalIssue, error) {
+	issue, ok := t.issues[key]
+	if !ok {
+		return models.ExternalIssue{}, integration.ErrIssueNotFound
+	}
+	return issue, nil
+}
+
+func TestExternalRefLinkAndPoll(t *testing.T) {
+	repo := new(MockTaskRepository)
+	logger := new(MockLogger)
+	refs := &memoryExternalRefs{}
+	tracker := &stubTracker{issues: map[string]models.ExternalIssue{
+		"acme/api#1": {Title: "Fix login", Status: "open"},
+	}}
+	service := NewExternalRefService(refs, NewTaskService(repo, nil, nil, nil, nil, nil, nil, logger),
+		map[models.ExternalProvider]domainService.IssueTracker{models.ProviderGitHub: tracker}, 5*time.Minute, logger)
+	now := time.Date(2024, 3, 10, 9, 0, 0, 0, time.UTC)
+	fake := clock.NewFake(now)
+	service.clock = fake
+	ctx := context.Background()
+
+	repo.On("GetByID", mock.Anything, "task1").Return(&models.Task{ID: "task1", UserID: "user1"}, nil)
+
+	_, err := service.Link(ctx, "user1", "task1", models.LinkExternalRequest{Provider: models.ProviderJira, Ref: "OPS-1"})
+	assert.Equal(t, ErrTrackerUnavailable, err)
+	_, err = service.Link(ctx, "user1", "task1", models.LinkExternalRequest{Provider: models.ProviderGitHub, Ref: "acme/api#404"})
+	assert.Equal(t, ErrExternalIssueNotFound, err)
+	_, err = service.Link(ctx, "user2", "task1", models.LinkExternalRequest{Provider: models.ProviderGitHub, Ref: "acme/api#1"})
+	assert.Equal(t, ErrAccessDenied, err)
+
+	ref, err := service.Link(ctx, "user1", "task1", models.LinkExternalRequest{
+		Provider: models.ProviderGitHub, Ref: "acme/api#1", MirrorCompletion: true,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "open", ref.Status)
+	assert.Equal(t, now, *ref.CheckedAt)
+
+	// внутри интервала опроса трекер не запрашивается
+	tracker.issues["acme/api#1"] = models.ExternalIssue{Title: "Fix login", Status: "closed", Closed: true}
+	require.NoError(t, service.Poll(ctx))
+	assert.False(t, refs.refs[0].Closed)
+
+	// внешняя задача закрыта: задача выполняется один раз
+	now = now.Add(10 * time.Minute)
+	fake.Set(now)
+	repo.On("GetOpenDescendantIDs", mock.Anything, []string{"task1"}).Return([]string(nil), nil)
+	repo.On("CompleteTasks", mock.Anything, "user1", []string{"task1"}).
+		Return([]models.Task{{ID: "task1", UserID: "user1", Status: models.StatusDone}}, nil).Once()
+	logger.On("Info", mock.Anything, mock.Anything).Return()
+	require.NoError(t, service.Poll(ctx))
+	assert.True(t, refs.refs[0].Closed)
+
+	now = now.Add(10 * time.Minute)
+	fake.Set(now)
+	require.NoError(t, service.Poll(ctx))
+
+	list, err := service.List(ctx, "user1", "task1")
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, "closed", list[0].Status)
+
+	require.NoError(t, service.Unlink(ctx, "user1", "task1", ref.ID))
+	assert.True(t, errors.Is(service.Unlink(ctx, "user1", "task1", ref.ID), ErrExternalRefNotFound))
+
+	repo.AssertExpectations(t)
+}
@@ -0,0 +1,274 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoloko/taskmange/internal/clock"
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/jmoloko/taskmange/internal/domain/repository"
+	domainService "github.com/jmoloko/taskmange/internal/domain/service"
+	"github.com/jmoloko/taskmange/internal/logger"
+)
+
+const (
+	// максимальное число входящих webhook у одного пользователя
+	maxHooksPerUser = 20
+	// максимальная длина заголовка задачи из входящего запроса, длинные заголовки обрезаются
+	maxHookTitleLength = 255
+)
+
+var (
+	ErrInvalidHook        = errors.New("invalid incoming hook")
+	ErrHookNotFound       = errors.New("incoming hook not found")
+	ErrTooManyHooks       = errors.New("too many incoming hooks")
+	ErrHookRateLimited    = errors.New("incoming hook rate limit exceeded")
+	ErrInvalidHookPayload = errors.New("invalid incoming hook payload")
+)
+
+// HookService входящие webhook: внешние системы создают задачи пользователя по секретному токену
+type HookService struct {
+	repo     repository.IncomingHookRepository
+	tasks    domainService.TaskCreator
+	statuses domainService.UserStatusChecker
+	limiter  repository.HookRateLimiter
+	limit    int
+	window   time.Duration
+	logger   logger.Logger
+	clock    clock.Clock
+}
+
+// NewHookService создает новый экземпляр HookService.
+// limit запросов за window на один webhook, limiter nil или limit 0 отключают ограничение.
+// statuses nil — задачи создаются и для деактивированных владельцев
+func NewHookService(repo repository.IncomingHookRepository, tasks domainService.TaskCreator, statuses domainService.UserStatusChecker, limiter repository.HookRateLimiter, limit int, window time.Duration, logger logger.Logger) *HookService {
+	return &HookService{
+		repo:     repo,
+		tasks:    tasks,
+		statuses: statuses,
+		limiter:  limiter,
+		limit:    limit,
+		window:   window,
+		logger:   logger,
+		clock:    clock.System,
+	}
+}
+
+// Create создает входящий webhook, токен возвращается только в этом ответе
+func (s *HookService) Create(ctx context.Context, userID string, req models.IncomingHookRequest) (*models.IncomingHook, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" || len([]rune(name)) > 100 {
+		return nil, fmt.Errorf("%w: name must be 1-100 characters", ErrInvalidHook)
+	}
+
+	mapping := models.DefaultHookMapping
+	if req.Mapping != nil {
+		mapping = *req.Mapping
+	}
+	if mapping.Title == "" {
+		return nil, fmt.Errorf("%w: mapping.title is required", ErrInvalidHook)
+	}
+	switch mapping.DefaultPriority {
+	case "", models.PriorityLow, models.PriorityMedium, models.PriorityHigh:
+	default:
+		return nil, fmt.Errorf("%w: unknown default_priority %q", ErrInvalidHook, mapping.DefaultPriority)
+	}
+
+	existing, err := s.repo.GetIncomingHooks(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) >= maxHooksPerUser {
+		return nil, ErrTooManyHooks
+	}
+
+	token, err := newHookToken()
+	if err != nil {
+		return nil, err
+	}
+
+	hook := &models.IncomingHook{
+		ID:      uuid.New().String(),
+		UserID:  userID,
+		Name:    name,
+		Mapping: mapping,
+	}
+	if err := s.repo.CreateIncomingHook(ctx, hook, hashHookToken(token)); err != nil {
+		return nil, err
+	}
+	hook.Token = token
+
+	return hook, nil
+}
+
+// List входящие webhook пользователя без токенов
+func (s *HookService) List(ctx context.Context, userID string) ([]models.IncomingHook, error) {
+	hooks, err := s.repo.GetIncomingHooks(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if hooks == nil {
+		hooks = []models.IncomingHook{}
+	}
+
+	return hooks, nil
+}
+
+// Delete удаляет входящий webhook, его токен сразу перестает действовать
+func (s *HookService) Delete(ctx context.Context, userID, hookID string) error {
+	if err := s.repo.DeleteIncomingHook(ctx, userID, hookID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrHookNotFound
+		}
+		return err
+	}
+
+	return nil
+}
+
+// Receive создает задачу владельца webhook по JSON-запросу внешней системы
+func (s *HookService) Receive(ctx context.Context, token string, payload []byte) (models.HookDelivery, error) {
+	hook, err := s.repo.GetIncomingHookByToken(ctx, hashHookToken(token))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return models.HookDelivery{}, ErrHookNotFound
+		}
+		return models.HookDelivery{}, err
+	}
+	// токен действует от имени владельца, поэтому, как и JWT, отклоняется после деактивации
+	if err := checkUserActive(ctx, s.statuses, hook.UserID); err != nil {
+		return models.HookDelivery{}, err
+	}
+
+	if s.limiter != nil && s.limit > 0 {
+		allowed, err := s.limiter.AllowHook(ctx, hook.ID, s.limit, s.window)
+		if err != nil {
+			// без Redis запросы не ограничиваем, чтобы не терять алерты
+			s.logger.Warn("Failed to check hook rate limit", map[string]interface{}{
+				"hook_id": hook.ID,
+				"error":   err.Error(),
+			})
+		} else if !allowed {
+			return models.HookDelivery{}, ErrHookRateLimited
+		}
+	}
+
+	var data interface{}
+	if err := json.Unmarshal(payload, &data); err != nil {
+		return models.HookDelivery{}, fmt.Errorf("%w: body must be JSON", ErrInvalidHookPayload)
+	}
+
+	task, err := mapHookPayload(hook.Mapping, data)
+	if err != nil {
+		return models.HookDelivery{}, err
+	}
+
+	created, err := s.tasks.CreateTask(ctx, hook.UserID, task)
+	if err != nil {
+		return models.HookDelivery{}, err
+	}
+
+	if err := s.repo.TouchIncomingHook(ctx, hook.ID, s.clock.Now()); err != nil {
+		s.logger.Warn("Failed to update hook last use", map[string]interface{}{
+			"hook_id": hook.ID,
+			"error":   err.Error(),
+		})
+	}
+	s.logger.Info("Task created by incoming hook", map[string]interface{}{
+		"hook_id": hook.ID,
+		"user_id": hook.UserID,
+		"task_id": created.ID,
+	})
+
+	return models.HookDelivery{TaskID: created.ID}, nil
+}
+
+// mapHookPayload собирает задачу из полей запроса по сопоставлению
+func mapHookPayload(mapping models.HookMapping, data interface{}) (models.Task, error) {
+	title := strings.TrimSpace(lookupHookValue(data, mapping.Title))
+	if title == "" {
+		return models.Task{}, fmt.Errorf("%w: %s is required", ErrInvalidHookPayload, mapping.Title)
+	}
+	if runes := []rune(title); len(runes) > maxHookTitleLength {
+		title = string(runes[:maxHookTitleLength])
+	}
+
+	task := models.Task{
+		Title:       title,
+		Description: lookupHookValue(data, mapping.Description),
+		Priority:    mapping.DefaultPriority,
+	}
+
+	switch priority := models.Priority(strings.ToLower(lookupHookValue(data, mapping.Priority))); priority {
+	case models.PriorityLow, models.PriorityMedium, models.PriorityHigh:
+		task.Priority = priority
+	}
+
+	if due := lookupHookValue(data, mapping.DueDate); due != "" {
+		dueDate, err := time.Parse(time.RFC3339, due)
+		if err != nil {
+			return models.Task{}, fmt.Errorf("%w: %s must be an RFC 3339 timestamp", ErrInvalidHookPayload, mapping.DueDate)
+		}
+		task.DueDate = dueDate
+	}
+
+	return task, nil
+}
+
+// lookupHookValue значение по пути через точку в виде строки, "" — пути нет или значение не скаляр
+func lookupHookValue(data interface{}, path string) string {
+	if path == "" {
+		return ""
+	}
+
+	for _, key := range strings.Split(path, ".") {
+		switch node := data.(type) {
+		case map[string]interface{}:
+			data = node[key]
+		case []interface{}:
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 || i >= len(node) {
+				return ""
+			}
+			data = node[i]
+		default:
+			return ""
+		}
+	}
+
+	switch v := data.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		return ""
+	}
+}
+
+// newHookToken случайный токен входящего webhook с префиксом hk_
+func newHookToken() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate hook token: %w", err)
+	}
+	return "hk_" + hex.EncodeToString(b), nil
+}
+
+// hashHookToken в базе хранится только SHA-256 токена
+func hashHookToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
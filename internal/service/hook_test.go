@@ -0,0 +1,174 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/jmoloko/taskmange/internal/domain/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryHooks implements repository.IncomingHookRepository
+type memoryHooks struct {
+	hooks  map[string]models.IncomingHook
+	tokens map[string]string
+}
+
+func newMemoryHooks() *memoryHooks {
+	return &memoryHooks{hooks: map[string]models.IncomingHook{}, tokens: map[string]string{}}
+}
+
+func (r *memoryHooks) CreateIncomingHook(ctx context.Context, hook *models.IncomingHook, tokenHash string) error {
+	r.hooks[hook.ID] = *hook
+	r.tokens[tokenHash] = hook.ID
+	return nil
+}
+
+func (r *memoryHooks) GetIncomingHooks(ctx context.Context, userID string) ([]models.IncomingHook, error) {
+	var hooks []models.IncomingHook
+	for _, hook := range r.hooks {
+		if hook.UserID == userID {
+			hooks = append(hooks, hook)
+		}
+	}
+	return hooks, nil
+}
+
+func (r *memoryHooks) GetIncomingHookByToken(ctx context.Context, tokenHash string) (*models.IncomingHook, error) {
+	hook, ok := r.hooks[r.tokens[tokenHash]]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	return &hook, nil
+}
+
+func (r *memoryHooks) DeleteIncomingHook(ctx context.Context, userID, hookID string) error {
+	if hook, ok := r.hooks[hookID]; !ok || hook.UserID != userID {
+		return repository.ErrNotFound
+	}
+	delete(r.hooks, hookID)
+	return nil
+}
+
+func (r *memoryHooks) TouchIncomingHook(ctx context.Context, hookID string, usedAt time.Time) error {
+	hook := r.hooks[hookID]
+	hook.LastUsedAt = &usedAt
+	r.hooks[hookID] = hook
+	return nil
+}
+
+// countingLimiter implements repository.HookRateLimiter
+type countingLimiter struct {
+	counts map[string]int
+}
+
+func (l *countingLimiter) AllowHook(ctx context.Context, hookID string, limit int, window time.Duration) (bool, error) {
+	l.counts[hookID]++
+	return l.counts[hookID] <= limit, nil
+}
+
+// MockTaskCreator implements domainService.TaskCreator
+type MockTaskCreator struct {
+	mock.Mock
+}
+
+func (m *MockTaskCreator) CreateTask(ctx context.Context, userID string, task models.Task) (models.Task, error) {
+	args := m.Called(ctx, userID, task)
+	return args.Get(0).(models.Task), args.Error(1)
+}
+
+func TestHookReceive(t *testing.T) {
+	repo := newMemoryHooks()
+	tasks := new(MockTaskCreator)
+	logger := new(MockLogger)
+	service := NewHookService(repo, tasks, nil, &countingLimiter{counts: map[string]int{}}, 2, time.Minute, logger)
+	ctx := context.Background()
+
+	hook, err := service.Create(ctx, "user1", models.IncomingHookRequest{
+		Name: "Alerts",
+		Mapping: &models.HookMapping{
+			Title:           "alerts.0.labels.alertname",
+			Description:     "alerts.0.annotations.summary",
+			Priority:        "alerts.0.labels.severity",
+			DefaultPriority: models.PriorityHigh,
+		},
+	})
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(hook.Token, "hk_"))
+	// в базе хранится только хеш токена
+	assert.NotContains(t, repo.tokens, hook.Token)
+
+	payload := []byte(`{"alerts":[{"labels":{"alertname":"DiskFull","severity":"critical"},"annotations":{"summary":"/ is 95% full"}}]}`)
+	tasks.On("CreateTask", mock.Anything, "user1", models.Task{
+		Title:       "DiskFull",
+		Description: "/ is 95% full",
+		Priority:    models.PriorityHigh,
+	}).Return(models.Task{ID: "task1"}, nil).Once()
+	logger.On("Info", "Task created by incoming hook", mock.Anything).Return().Once()
+
+	delivery, err := service.Receive(ctx, hook.Token, payload)
+	require.NoError(t, err)
+	assert.Equal(t, "task1", delivery.TaskID)
+	assert.NotNil(t, repo.hooks[hook.ID].LastUsedAt)
+
+	_, err = service.Receive(ctx, hook.Token, []byte(`{"alerts":[]}`))
+	assert.True(t, errors.Is(err, ErrInvalidHookPayload))
+
+	// лимит 2 запроса в окно
+	_, err = service.Receive(ctx, hook.Token, payload)
+	assert.Equal(t, ErrHookRateLimited, err)
+
+	_, err = service.Receive(ctx, "hk_unknown", payload)
+	assert.Equal(t, ErrHookNotFound, err)
+
+	require.NoError(t, service.Delete(ctx, "user1", hook.ID))
+	_, err = service.Receive(ctx, hook.Token, payload)
+	assert.Equal(t, ErrHookNotFound, err)
+
+	tasks.AssertExpectations(t)
+}
+
+func TestHookReceive_DeactivatedOwner(t *testing.T) {
+	repo := newMemoryHooks()
+	tasks := new(MockTaskCreator)
+	logger := new(MockLogger)
+	statuses := NewUserStatusService(nil, memoryUserStatuses{"user1": false}, nil, logger)
+	service := NewHookService(repo, tasks, statuses, nil, 0, time.Minute, logger)
+	ctx := context.Background()
+
+	hook, err := service.Create(ctx, "user1", models.IncomingHookRequest{Name: "Alerts"})
+	require.NoError(t, err)
+
+	// токен действует от имени владельца и после деактивации отклоняется, как и его JWT
+	_, err = service.Receive(ctx, hook.Token, []byte(`{"title":"DiskFull"}`))
+	assert.Equal(t, ErrUserDeactivated, err)
+	tasks.AssertNotCalled(t, "CreateTask", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestMapHookPayload(t *testing.T) {
+	var data interface{} = map[string]interface{}{
+		"title":    "Call back",
+		"priority": "LOW",
+		"due_date": "2024-03-10T12:00:00Z",
+		"count":    float64(3),
+	}
+
+	task, err := mapHookPayload(models.DefaultHookMapping, data)
+	require.NoError(t, err)
+	assert.Equal(t, "Call back", task.Title)
+	assert.Equal(t, models.PriorityLow, task.Priority)
+	assert.Equal(t, time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC), task.DueDate)
+
+	task, err = mapHookPayload(models.HookMapping{Title: "count"}, data)
+	require.NoError(t, err)
+	assert.Equal(t, "3", task.Title)
+
+	_, err = mapHookPayload(models.HookMapping{Title: "title", DueDate: "title"}, data)
+	assert.True(t, errors.Is(err, ErrInvalidHookPayload))
+}
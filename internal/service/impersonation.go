@@ -0,0 +1,127 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/jmoloko/taskmange/internal/clock"
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/jmoloko/taskmange/internal/domain/repository"
+	"github.com/jmoloko/taskmange/internal/logger"
+)
+
+var (
+	ErrSelfImpersonation     = errors.New("cannot impersonate yourself")
+	ErrImpersonationReason   = errors.New("impersonation reason is required")
+	ErrImpersonationNotFound = errors.New("impersonation not found")
+)
+
+// ImpersonationService выдает администраторам поддержки токены от имени пользователя.
+// Каждая выдача и отзыв записываются в журнал аудита, токен можно отозвать до истечения
+type ImpersonationService struct {
+	auth   *AuthService
+	users  repository.UserRepository
+	repo   repository.ImpersonationRepository
+	audit  *AuditService
+	ttl    time.Duration
+	clock  clock.Clock
+	logger logger.Logger
+}
+
+// NewImpersonationService создает новый экземпляр ImpersonationService, ttl — время жизни токена
+func NewImpersonationService(auth *AuthService, users repository.UserRepository, repo repository.ImpersonationRepository, audit *AuditService, ttl time.Duration, logger logger.Logger) *ImpersonationService {
+	return &ImpersonationService{
+		auth:   auth,
+		users:  users,
+		repo:   repo,
+		audit:  audit,
+		ttl:    ttl,
+		clock:  clock.System,
+		logger: logger,
+	}
+}
+
+// Start выдает администратору adminID токен от имени пользователя userID
+func (s *ImpersonationService) Start(ctx context.Context, adminID, userID, reason string) (models.ImpersonationToken, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return models.ImpersonationToken{}, ErrImpersonationReason
+	}
+	if adminID == userID {
+		return models.ImpersonationToken{}, ErrSelfImpersonation
+	}
+
+	if _, err := s.users.GetByID(ctx, userID); err != nil {
+		return models.ImpersonationToken{}, ErrUserNotFound
+	}
+
+	imp := models.Impersonation{
+		ID:        generateUUID(),
+		AdminID:   adminID,
+		UserID:    userID,
+		Reason:    reason,
+		ExpiresAt: s.clock.Now().Add(s.ttl),
+	}
+	if err := s.repo.CreateImpersonation(ctx, &imp); err != nil {
+		return models.ImpersonationToken{}, err
+	}
+
+	token, err := s.auth.generateImpersonationToken(imp)
+	if err != nil {
+		return models.ImpersonationToken{}, err
+	}
+
+	s.audit.Record(ctx, models.AuditEvent{
+		Action:  models.AuditImpersonationStarted,
+		ActorID: adminID,
+		Target:  userID,
+		Details: map[string]string{
+			"impersonation_id": imp.ID,
+			"reason":           reason,
+			"expires_at":       imp.ExpiresAt.UTC().Format(time.RFC3339),
+		},
+	})
+	s.logger.Info("Impersonation started", map[string]interface{}{
+		"impersonation_id": imp.ID,
+		"admin_id":         adminID,
+		"user_id":          userID,
+	})
+
+	return models.ImpersonationToken{Token: token, Impersonation: imp}, nil
+}
+
+// Revoke отзывает сессию имперсонации, токен перестает приниматься сразу
+func (s *ImpersonationService) Revoke(ctx context.Context, adminID, impersonationID string) error {
+	imp, err := s.repo.GetImpersonation(ctx, impersonationID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrImpersonationNotFound
+		}
+		return err
+	}
+
+	if err := s.repo.RevokeImpersonation(ctx, impersonationID, s.clock.Now()); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrImpersonationNotFound
+		}
+		return err
+	}
+
+	s.audit.Record(ctx, models.AuditEvent{
+		Action:  models.AuditImpersonationRevoked,
+		ActorID: adminID,
+		Target:  imp.UserID,
+		Details: map[string]string{
+			"impersonation_id": imp.ID,
+			"admin_id":         imp.AdminID,
+		},
+	})
+	s.logger.Info("Impersonation revoked", map[string]interface{}{
+		"impersonation_id": imp.ID,
+		"revoked_by":       adminID,
+	})
+
+	return nil
+}
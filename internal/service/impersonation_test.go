@@ -0,0 +1,201 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jmoloko/taskmange/internal/clock"
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/jmoloko/taskmange/internal/domain/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockUserRepository implements repository.UserRepository
+type MockUserRepository struct {
+	mock.Mock
+}
+
+func (m *MockUserRepository) Create(ctx context.Context, user *models.User) error {
+	args := m.Called(ctx, user)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) GetByID(ctx context.Context, id string) (*models.User, error) {
+	args := m.Called(ctx, id)
+	user, _ := args.Get(0).(*models.User)
+	return user, args.Error(1)
+}
+
+func (m *MockUserRepository) GetByEmail(ctx context.Context, email string) (*models.User, error) {
+	args := m.Called(ctx, email)
+	user, _ := args.Get(0).(*models.User)
+	return user, args.Error(1)
+}
+
+func (m *MockUserRepository) UpdatePassword(ctx context.Context, id, passwordHash string) error {
+	args := m.Called(ctx, id, passwordHash)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) PasswordHistory(ctx context.Context, userID string, limit int) ([]string, error) {
+	args := m.Called(ctx, userID, limit)
+	hashes, _ := args.Get(0).([]string)
+	return hashes, args.Error(1)
+}
+
+func (m *MockUserRepository) ChangePassword(ctx context.Context, userID, passwordHash string, keep int) error {
+	args := m.Called(ctx, userID, passwordHash, keep)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) SetActive(ctx context.Context, id string, active bool) error {
+	args := m.Called(ctx, id, active)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) SetRole(ctx context.Context, id, role string) error {
+	args := m.Called(ctx, id, role)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) ListUsers(ctx context.Context, limit, offset int) ([]models.User, error) {
+	args := m.Called(ctx, limit, offset)
+	users, _ := args.Get(0).([]models.User)
+	return users, args.Error(1)
+}
+
+func (m *MockUserRepository) CountUsers(ctx context.Context) (int, error) {
+	args := m.Called(ctx)
+	return args.Int(0), args.Error(1)
+}
+
+// memoryImpersonations implements repository.ImpersonationRepository
+type memoryImpersonations struct {
+	items map[string]*models.Impersonation
+}
+
+func (r *memoryImpersonations) CreateImpersonation(ctx context.Context, imp *models.Impersonation) error {
+	imp.CreatedAt = time.Now()
+	saved := *imp
+	r.items[imp.ID] = &saved
+	return nil
+}
+
+func (r *memoryImpersonations) GetImpersonation(ctx context.Context, id string) (*models.Impersonation, error) {
+	imp, ok := r.items[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	copied := *imp
+	return &copied, nil
+}
+
+func (r *memoryImpersonations) RevokeImpersonation(ctx context.Context, id string, revokedAt time.Time) error {
+	imp, ok := r.items[id]
+	if !ok || imp.RevokedAt != nil {
+		return repository.ErrNotFound
+	}
+	imp.RevokedAt = &revokedAt
+	return nil
+}
+
+// memoryAudit implements repository.AuditRepository
+type memoryAudit struct {
+	events []models.AuditEvent
+}
+
+func (r *memoryAudit) SaveAuditEvent(ctx context.Context, event *models.AuditEvent) error {
+	r.events = append(r.events, *event)
+	return nil
+}
+
+func (r *memoryAudit) GetAuditEvents(ctx context.Context, filter models.AuditFilter) ([]models.AuditEvent, error) {
+	return r.events, nil
+}
+
+func TestImpersonation(t *testing.T) {
+	mockLogger = new(MockLogger)
+	mockLogger.On("Info", mock.Anything, mock.Anything).Return()
+	users := new(MockUserRepository)
+	impersonations := &memoryImpersonations{items: map[string]*models.Impersonation{}}
+	audit := &memoryAudit{}
+	auth := NewAuthService(users, impersonations, nil, nil, mockLogger, "secret")
+	service := NewImpersonationService(auth, users, impersonations, NewAuditService(audit, mockLogger), time.Hour, mockLogger)
+	// срок токена и сессии отсчитываются по часам сервисов, а не по системным
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	fake := clock.NewFake(now)
+	auth.clock, service.clock = fake, fake
+	ctx := context.Background()
+
+	_, err := service.Start(ctx, "admin1", "user1", "  ")
+	assert.Equal(t, ErrImpersonationReason, err)
+	_, err = service.Start(ctx, "admin1", "admin1", "ticket")
+	assert.Equal(t, ErrSelfImpersonation, err)
+
+	users.On("GetByID", mock.Anything, "missing").Return(nil, errors.New("not found")).Once()
+	_, err = service.Start(ctx, "admin1", "missing", "ticket")
+	assert.Equal(t, ErrUserNotFound, err)
+
+	users.On("GetByID", mock.Anything, "user1").Return(&models.User{ID: "user1"}, nil).Once()
+	token, err := service.Start(ctx, "admin1", "user1", "Ticket #1234")
+	require.NoError(t, err)
+	assert.Equal(t, "user1", token.Impersonation.UserID)
+	require.Len(t, audit.events, 1)
+	assert.Equal(t, models.AuditImpersonationStarted, audit.events[0].Action)
+	assert.Equal(t, "admin1", audit.events[0].ActorID)
+	assert.Equal(t, token.Impersonation.ID, audit.events[0].Details["impersonation_id"])
+
+	// токен действует от имени пользователя и помечен администратором
+	users.On("GetByID", mock.Anything, "admin1").Return(&models.User{ID: "admin1", Role: models.RoleAdmin, Active: true}, nil).Once()
+	claims, err := auth.ParseToken(ctx, token.Token)
+	require.NoError(t, err)
+	assert.Equal(t, models.TokenClaims{UserID: "user1", Role: models.RoleUser, ImpersonatorID: "admin1", ImpersonationID: token.Impersonation.ID}, claims)
+
+	// администратор, которого деактивировали или лишили роли, больше не действует через выданный токен
+	users.On("GetByID", mock.Anything, "admin1").Return(&models.User{ID: "admin1", Role: models.RoleAdmin, Active: false}, nil).Once()
+	_, err = auth.ParseToken(ctx, token.Token)
+	assert.Equal(t, ErrInvalidToken, err)
+	users.On("GetByID", mock.Anything, "admin1").Return(&models.User{ID: "admin1", Role: models.RoleUser, Active: true}, nil).Once()
+	_, err = auth.ParseToken(ctx, token.Token)
+	assert.Equal(t, ErrInvalidToken, err)
+
+	// по истечении ttl токен отклоняется
+	fake.Advance(time.Hour + time.Second)
+	_, err = auth.ParseToken(ctx, token.Token)
+	assert.Equal(t, ErrInvalidToken, err)
+	fake.Set(now)
+
+	require.NoError(t, service.Revoke(ctx, "admin2", token.Impersonation.ID))
+	assert.Equal(t, models.AuditImpersonationRevoked, audit.events[1].Action)
+	assert.Equal(t, "admin2", audit.events[1].ActorID)
+
+	// отозванный токен отклоняется сразу
+	_, err = auth.ParseToken(ctx, token.Token)
+	assert.Equal(t, ErrInvalidToken, err)
+	assert.Equal(t, ErrImpersonationNotFound, service.Revoke(ctx, "admin1", token.Impersonation.ID))
+	assert.Equal(t, ErrImpersonationNotFound, service.Revoke(ctx, "admin1", "unknown"))
+}
+
+func TestParseToken_ImpersonationWithoutRepository(t *testing.T) {
+	impersonations := &memoryImpersonations{items: map[string]*models.Impersonation{}}
+	issuer := NewAuthService(nil, impersonations, nil, nil, new(MockLogger), "secret")
+	token, err := issuer.generateImpersonationToken(models.Impersonation{
+		ID: "imp1", AdminID: "admin1", UserID: "user1", ExpiresAt: time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+
+	// без репозитория сессий токен имперсонации нельзя проверить на отзыв
+	_, err = NewAuthService(nil, nil, nil, nil, new(MockLogger), "secret").ParseToken(context.Background(), token)
+	assert.Equal(t, ErrInvalidToken, err)
+
+	// обычный токен по-прежнему принимается
+	plain, _, err := issuer.generateToken("user1", models.RoleUser)
+	require.NoError(t, err)
+	userID, err := issuer.ValidateToken(plain)
+	require.NoError(t, err)
+	assert.Equal(t, "user1", userID)
+}
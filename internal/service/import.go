@@ -0,0 +1,166 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	domainService "github.com/jmoloko/taskmange/internal/domain/service"
+)
+
+// ErrImportInvalidRows в файле импорта есть строки с ошибками, задачи не созданы
+var ErrImportInvalidRows = errors.New("import file has invalid rows")
+
+// maxReportedImportErrors сколько ошибок строк попадает в отчет импорта, остальные только считаются
+const maxReportedImportErrors = 1000
+
+// ImportTaskFile читает файл импорта построчно, проверяет каждую строку и создает задачи одной
+// пакетной вставкой. Если в файле есть строки с ошибками, ничего не создается и возвращается
+// ErrImportInvalidRows вместе с отчетом; со skipInvalid создаются только корректные строки
+func (s *TaskServiceImpl) ImportTaskFile(ctx context.Context, userID string, rows domainService.ImportRowSource, skipInvalid bool) (models.ImportReport, error) {
+	report := models.ImportReport{Errors: []models.ImportRowError{}}
+
+	var tasks []models.Task
+	for {
+		row, rowErr, err := rows.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return models.ImportReport{}, err
+		}
+		report.Total++
+
+		if rowErr == nil {
+			if rowErr = validateImportTask(row.Task); rowErr != nil {
+				rowErr.Row = row.Row
+			}
+		}
+		if rowErr != nil {
+			report.Invalid++
+			if len(report.Errors) < maxReportedImportErrors {
+				report.Errors = append(report.Errors, *rowErr)
+			}
+			continue
+		}
+
+		if err := s.prepareImportedTask(userID, &row.Task); err != nil {
+			return models.ImportReport{}, err
+		}
+		tasks = append(tasks, row.Task)
+	}
+
+	if report.Invalid > 0 && !skipInvalid {
+		return report, ErrImportInvalidRows
+	}
+
+	adding := openCount(tasks)
+	open, err := s.reserveOpenTasks(ctx, userID, adding)
+	if err != nil {
+		return models.ImportReport{}, err
+	}
+
+	if err := s.repo.CreateBatch(ctx, tasks); err != nil {
+		return models.ImportReport{}, err
+	}
+	s.invalidateTaskLists(ctx, userID)
+	s.observeOpenTasks(ctx, userID, open, adding)
+
+	report.Imported = len(tasks)
+	s.logger.Info("Tasks imported from file", map[string]interface{}{
+		"user_id":  userID,
+		"imported": report.Imported,
+		"invalid":  report.Invalid,
+	})
+	return report, nil
+}
+
+// PreviewImport проверяет разобранный файл импорта и считает, сколько задач будет создано,
+// ничего не записывая. Дубликатом считается строка с тем же заголовком и сроком,
+// что у существующей задачи пользователя или у строки выше в файле
+func (s *TaskServiceImpl) PreviewImport(ctx context.Context, userID string, batch models.ImportBatch) (models.ImportPreview, error) {
+	preview := models.ImportPreview{
+		Total:         len(batch.Rows) + len(batch.Errors),
+		Invalid:       len(batch.Errors),
+		Mapping:       batch.Mapping,
+		DuplicateRows: []int{},
+		Errors:        append([]models.ImportRowError{}, batch.Errors...),
+	}
+
+	existing, err := s.repo.GetAll(ctx, models.TaskFilters{UserID: userID})
+	if err != nil {
+		return models.ImportPreview{}, err
+	}
+
+	seen := make(map[string]bool, len(existing)+len(batch.Rows))
+	for i := range existing {
+		task := existing[i]
+		if task.Private {
+			// заголовок приватной задачи сравниваем в открытом виде
+			if err := s.openTask(&task); err != nil {
+				continue
+			}
+		}
+		seen[dedupKey(task)] = true
+	}
+
+	for _, row := range batch.Rows {
+		if rowErr := validateImportTask(row.Task); rowErr != nil {
+			rowErr.Row = row.Row
+			preview.Errors = append(preview.Errors, *rowErr)
+			preview.Invalid++
+			continue
+		}
+
+		key := dedupKey(row.Task)
+		if seen[key] {
+			preview.DuplicateRows = append(preview.DuplicateRows, row.Row)
+			preview.Duplicates++
+			continue
+		}
+		seen[key] = true
+		preview.Create++
+	}
+
+	return preview, nil
+}
+
+// validateImportTask проверяет обязательные поля и значения перечислений
+func validateImportTask(task models.Task) *models.ImportRowError {
+	if strings.TrimSpace(task.Title) == "" {
+		return &models.ImportRowError{Field: "title", Message: "title is required"}
+	}
+
+	switch task.Status {
+	case "", models.StatusPending, models.StatusInProgress, models.StatusDone:
+	default:
+		return &models.ImportRowError{Field: "status", Message: "unknown status " + string(task.Status)}
+	}
+
+	switch task.Priority {
+	case "", models.PriorityLow, models.PriorityMedium, models.PriorityHigh:
+	default:
+		return &models.ImportRowError{Field: "priority", Message: "unknown priority " + string(task.Priority)}
+	}
+
+	if err := validateLinks(task.Links); err != nil {
+		return &models.ImportRowError{Field: "links", Message: "links must be http(s) URLs"}
+	}
+
+	if _, err := normalizeTags(task.Tags); err != nil {
+		return &models.ImportRowError{Field: "tags", Message: "tags must be 1-50 characters, at most 20"}
+	}
+
+	return nil
+}
+
+// dedupKey ключ для поиска дубликатов: заголовок без учета регистра и дата срока
+func dedupKey(task models.Task) string {
+	key := strings.ToLower(strings.TrimSpace(task.Title))
+	if !task.DueDate.IsZero() {
+		key += "|" + task.DueDate.UTC().Format("2006-01-02")
+	}
+	return key
+}
@@ -0,0 +1,36 @@
+package service
+
+import (
+	"net/url"
+
+	"github.com/jmoloko/taskmange/internal/domain/models"
+)
+
+const (
+	// максимальное число ссылок у задачи
+	maxTaskLinks = 20
+	// максимальная длина URL ссылки
+	maxLinkURLLength = 2048
+	// максимальная длина подписи ссылки
+	maxLinkTitleLength = 255
+)
+
+// validateLinks проверяет, что ссылки задачи — абсолютные http(s) URL разумной длины
+func validateLinks(links []models.TaskLink) error {
+	if len(links) > maxTaskLinks {
+		return ErrInvalidLink
+	}
+
+	for _, link := range links {
+		if len(link.URL) > maxLinkURLLength || len(link.Title) > maxLinkTitleLength {
+			return ErrInvalidLink
+		}
+
+		u, err := url.Parse(link.URL)
+		if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+			return ErrInvalidLink
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,94 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jmoloko/taskmange/internal/domain/models"
+)
+
+const (
+	// пороги матрицы по умолчанию: срочно — срок в ближайшие двое суток, важно — высокий приоритет
+	defaultUrgentWithinHours = 48
+	defaultImportantPriority = models.PriorityHigh
+	maxUrgentWithinHours     = 30 * 24
+)
+
+var ErrInvalidMatrixSettings = errors.New("invalid matrix settings")
+
+// Matrix открытые задачи пользователя по квадрантам матрицы Эйзенхауэра. Срочность — близость
+// срока (просроченные срочные всегда), важность — приоритет; пороги берутся из настроек пользователя
+func (s *TaskViewService) Matrix(ctx context.Context, userID string) (models.TaskMatrix, error) {
+	settings, err := s.MatrixSettings(ctx, userID)
+	if err != nil {
+		return models.TaskMatrix{}, err
+	}
+
+	tasks, err := s.tasks.GetUserTasks(ctx, userID, models.TaskFilters{
+		UserID: userID,
+		Sort:   models.SortSmart,
+		Open:   true,
+	})
+	if err != nil {
+		return models.TaskMatrix{}, err
+	}
+
+	matrix := models.TaskMatrix{
+		DoFirst:   []models.Task{},
+		Schedule:  []models.Task{},
+		Delegate:  []models.Task{},
+		Eliminate: []models.Task{},
+		Settings:  settings,
+	}
+	urgentBefore := s.clock.Now().Add(time.Duration(settings.UrgentWithinHours) * time.Hour)
+	important := settings.ImportantPriority.Rank()
+
+	for _, task := range tasks {
+		urgent := !task.DueDate.After(urgentBefore)
+		switch {
+		case urgent && task.Priority.Rank() >= important:
+			matrix.DoFirst = append(matrix.DoFirst, task)
+		case task.Priority.Rank() >= important:
+			matrix.Schedule = append(matrix.Schedule, task)
+		case urgent:
+			matrix.Delegate = append(matrix.Delegate, task)
+		default:
+			matrix.Eliminate = append(matrix.Eliminate, task)
+		}
+	}
+
+	return matrix, nil
+}
+
+// MatrixSettings пороги матрицы пользователя или пороги по умолчанию, если он их не менял
+func (s *TaskViewService) MatrixSettings(ctx context.Context, userID string) (models.MatrixSettings, error) {
+	saved, err := s.matrix.GetMatrixSettings(ctx, userID)
+	if err != nil {
+		return models.MatrixSettings{}, err
+	}
+	if saved == nil {
+		return models.MatrixSettings{
+			UserID:            userID,
+			UrgentWithinHours: defaultUrgentWithinHours,
+			ImportantPriority: defaultImportantPriority,
+		}, nil
+	}
+	return *saved, nil
+}
+
+// SaveMatrixSettings сохраняет пороги матрицы: срочность от 1 часа до 30 дней, важность — приоритет
+func (s *TaskViewService) SaveMatrixSettings(ctx context.Context, userID string, settings models.MatrixSettings) (models.MatrixSettings, error) {
+	if settings.UrgentWithinHours < 1 || settings.UrgentWithinHours > maxUrgentWithinHours {
+		return models.MatrixSettings{}, ErrInvalidMatrixSettings
+	}
+	if settings.ImportantPriority.Rank() == 0 {
+		return models.MatrixSettings{}, ErrInvalidMatrixSettings
+	}
+
+	settings.UserID = userID
+	if err := s.matrix.SaveMatrixSettings(ctx, &settings); err != nil {
+		return models.MatrixSettings{}, err
+	}
+	return settings, nil
+}
@@ -0,0 +1,70 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryMatrixSettings implements repository.MatrixSettingsRepository
+type memoryMatrixSettings struct {
+	saved *models.MatrixSettings
+}
+
+func (r *memoryMatrixSettings) GetMatrixSettings(ctx context.Context, userID string) (*models.MatrixSettings, error) {
+	return r.saved, nil
+}
+
+func (r *memoryMatrixSettings) SaveMatrixSettings(ctx context.Context, settings *models.MatrixSettings) error {
+	saved := *settings
+	r.saved = &saved
+	return nil
+}
+
+func TestTaskMatrix(t *testing.T) {
+	now := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+	service, repo, _, _ := newTestViewService(now)
+	ctx := context.Background()
+
+	overdueLow := models.Task{ID: "1", UserID: "user1", Priority: models.PriorityLow, DueDate: now.Add(-time.Hour), IsOverdue: true}
+	soonHigh := models.Task{ID: "2", UserID: "user1", Priority: models.PriorityHigh, DueDate: now.Add(24 * time.Hour)}
+	laterHigh := models.Task{ID: "3", UserID: "user1", Priority: models.PriorityHigh, DueDate: now.Add(72 * time.Hour)}
+	laterMedium := models.Task{ID: "4", UserID: "user1", Priority: models.PriorityMedium, DueDate: now.Add(72 * time.Hour)}
+	tasks := []models.Task{overdueLow, soonHigh, laterHigh, laterMedium}
+	repo.On("GetAll", mock.Anything, mock.MatchedBy(func(f models.TaskFilters) bool {
+		return f.UserID == "user1" && f.Open && f.Sort == models.SortSmart
+	})).Return(tasks, nil)
+
+	// пороги по умолчанию: 48 часов и высокий приоритет
+	matrix, err := service.Matrix(ctx, "user1")
+	require.NoError(t, err)
+	assert.Equal(t, []models.Task{soonHigh}, matrix.DoFirst)
+	assert.Equal(t, []models.Task{laterHigh}, matrix.Schedule)
+	assert.Equal(t, []models.Task{overdueLow}, matrix.Delegate)
+	assert.Equal(t, []models.Task{laterMedium}, matrix.Eliminate)
+	assert.Equal(t, 48, matrix.Settings.UrgentWithinHours)
+
+	// с порогами пользователя средний приоритет важен, а срок через трое суток срочен
+	_, err = service.SaveMatrixSettings(ctx, "user1", models.MatrixSettings{UrgentWithinHours: 96, ImportantPriority: models.PriorityMedium})
+	require.NoError(t, err)
+	matrix, err = service.Matrix(ctx, "user1")
+	require.NoError(t, err)
+	assert.Equal(t, []models.Task{soonHigh, laterHigh, laterMedium}, matrix.DoFirst)
+	assert.Empty(t, matrix.Schedule)
+	assert.Equal(t, []models.Task{overdueLow}, matrix.Delegate)
+	assert.Empty(t, matrix.Eliminate)
+
+	for _, settings := range []models.MatrixSettings{
+		{UrgentWithinHours: 0, ImportantPriority: models.PriorityHigh},
+		{UrgentWithinHours: 31 * 24, ImportantPriority: models.PriorityHigh},
+		{UrgentWithinHours: 24, ImportantPriority: "urgent"},
+	} {
+		_, err = service.SaveMatrixSettings(ctx, "user1", settings)
+		assert.Equal(t, ErrInvalidMatrixSettings, err)
+	}
+}
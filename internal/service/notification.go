@@ -0,0 +1,384 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/jmoloko/taskmange/internal/clock"
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/jmoloko/taskmange/internal/domain/repository"
+	domainService "github.com/jmoloko/taskmange/internal/domain/service"
+	"github.com/jmoloko/taskmange/internal/logger"
+	"github.com/jmoloko/taskmange/internal/notification"
+)
+
+const (
+	// максимальное окно напоминания — неделя
+	maxDueSoonWindowMinutes = 7 * 24 * 60
+	// максимальное окно дайджеста — сутки
+	maxDigestWindowMinutes = 24 * 60
+)
+
+var (
+	ErrPushDisabled        = errors.New("push notifications are disabled")
+	ErrInvalidSubscription = errors.New("invalid push subscription")
+	ErrInvalidPreferences  = errors.New("invalid notification preferences")
+	ErrTemplateNotFound    = errors.New("notification template not found")
+)
+
+// NotificationRepository хранилище, необходимое сервису уведомлений
+type NotificationRepository interface {
+	repository.PushSubscriptionRepository
+	repository.NotificationPreferencesRepository
+	repository.TaskReminderRepository
+}
+
+// Сервис уведомлений
+type NotificationService struct {
+	repo     NotificationRepository
+	notifier domainService.Notifier
+	renderer *notification.Renderer
+	vapidKey string
+	defaults models.NotificationPreferences
+	clock    clock.Clock
+	logger   logger.Logger
+}
+
+// NewNotificationService создает новый экземпляр NotificationService.
+// notifier доставляет уведомления с учетом настроек пользователя и может быть nil.
+// vapidKey пуст, если VAPID-ключи не настроены и подписки браузеров не принимаются.
+// defaults — настройки пользователей, которые ничего не настраивали
+func NewNotificationService(repo NotificationRepository, notifier domainService.Notifier, renderer *notification.Renderer, vapidKey string, defaults models.NotificationPreferences, logger logger.Logger) *NotificationService {
+	return &NotificationService{
+		repo:     repo,
+		notifier: notifier,
+		renderer: renderer,
+		vapidKey: vapidKey,
+		defaults: defaults,
+		clock:    clock.System,
+		logger:   logger,
+	}
+}
+
+// публичный VAPID-ключ для подписки браузера
+func (s *NotificationService) VAPIDPublicKey() (string, error) {
+	if s.vapidKey == "" {
+		return "", ErrPushDisabled
+	}
+	return s.vapidKey, nil
+}
+
+// сохраняем подписку браузера
+func (s *NotificationService) Subscribe(ctx context.Context, userID string, req models.PushSubscriptionRequest) (*models.PushSubscription, error) {
+	if s.vapidKey == "" {
+		return nil, ErrPushDisabled
+	}
+
+	endpoint, err := url.Parse(req.Endpoint)
+	if err != nil || endpoint.Scheme != "https" || endpoint.Host == "" {
+		return nil, ErrInvalidSubscription
+	}
+	// адрес присылает клиент: push-сервис не может быть во внутренней сети
+	if err := notification.ValidateTarget(req.Endpoint); err != nil {
+		return nil, ErrInvalidSubscription
+	}
+
+	if req.Keys.P256dh == "" || req.Keys.Auth == "" {
+		return nil, ErrInvalidSubscription
+	}
+
+	sub := &models.PushSubscription{
+		UserID:   userID,
+		Endpoint: req.Endpoint,
+		P256dh:   req.Keys.P256dh,
+		Auth:     req.Keys.Auth,
+	}
+
+	if err := s.repo.SavePushSubscription(ctx, sub); err != nil {
+		return nil, err
+	}
+
+	return sub, nil
+}
+
+// удаляем подписку браузера
+func (s *NotificationService) Unsubscribe(ctx context.Context, userID, endpoint string) error {
+	if endpoint == "" {
+		return ErrInvalidSubscription
+	}
+	return s.repo.DeletePushSubscription(ctx, userID, endpoint)
+}
+
+// настройки уведомлений пользователя, по умолчанию — настройки из конфигурации
+func (s *NotificationService) GetPreferences(ctx context.Context, userID string) (models.NotificationPreferences, error) {
+	prefs, err := s.repo.GetNotificationPreferences(ctx, userID)
+	if err != nil {
+		return models.NotificationPreferences{}, err
+	}
+
+	if prefs == nil {
+		defaults := s.defaults
+		defaults.UserID = userID
+		return defaults, nil
+	}
+
+	return *prefs, nil
+}
+
+// обновляем настройки уведомлений пользователя
+func (s *NotificationService) UpdatePreferences(ctx context.Context, userID string, prefs models.NotificationPreferences) (models.NotificationPreferences, error) {
+	if prefs.Timezone == "" {
+		prefs.Timezone = "UTC"
+	}
+	if prefs.Channels == nil {
+		prefs.Channels = []models.NotificationChannel{}
+	}
+	if prefs.EventTypes == nil {
+		prefs.EventTypes = []string{}
+	}
+
+	if err := validatePreferences(prefs); err != nil {
+		return models.NotificationPreferences{}, err
+	}
+
+	prefs.UserID = userID
+	if err := s.repo.SaveNotificationPreferences(ctx, &prefs); err != nil {
+		return models.NotificationPreferences{}, err
+	}
+
+	return prefs, nil
+}
+
+// validatePreferences проверяет окна, каналы, типы событий и тихие часы
+func validatePreferences(prefs models.NotificationPreferences) error {
+	if prefs.DueSoonWindowMinutes < 1 || prefs.DueSoonWindowMinutes > maxDueSoonWindowMinutes {
+		return fmt.Errorf("%w: due_soon_window_minutes must be between 1 and %d", ErrInvalidPreferences, maxDueSoonWindowMinutes)
+	}
+
+	if prefs.DigestWindowMinutes < 0 || prefs.DigestWindowMinutes > maxDigestWindowMinutes {
+		return fmt.Errorf("%w: digest_window_minutes must be between 0 and %d", ErrInvalidPreferences, maxDigestWindowMinutes)
+	}
+
+	for _, channel := range prefs.Channels {
+		if !channel.IsValid() {
+			return fmt.Errorf("%w: unknown channel %q", ErrInvalidPreferences, channel)
+		}
+	}
+
+	if prefs.HasChannel(models.ChannelSlack) && prefs.SlackWebhookURL == "" {
+		return fmt.Errorf("%w: slack_webhook_url is required for the slack channel", ErrInvalidPreferences)
+	}
+	if prefs.SlackWebhookURL != "" {
+		webhook, err := url.Parse(prefs.SlackWebhookURL)
+		if err != nil || webhook.Scheme != "https" || webhook.Host == "" {
+			return fmt.Errorf("%w: slack_webhook_url must be an https URL", ErrInvalidPreferences)
+		}
+		if err := notification.ValidateTarget(prefs.SlackWebhookURL); err != nil {
+			return fmt.Errorf("%w: slack_webhook_url: %v", ErrInvalidPreferences, err)
+		}
+	}
+
+	for _, event := range prefs.EventTypes {
+		if event != models.NotificationEventDueSoon && event != models.NotificationEventOverdue &&
+			event != models.NotificationEventQuotaWarning && event != models.NotificationEventSearchMatch &&
+			!models.EventType(event).IsValid() {
+			return fmt.Errorf("%w: unknown event type %q", ErrInvalidPreferences, event)
+		}
+	}
+
+	if (prefs.QuietHoursStart == "") != (prefs.QuietHoursEnd == "") {
+		return fmt.Errorf("%w: quiet_hours_start and quiet_hours_end must be set together", ErrInvalidPreferences)
+	}
+	for _, clock := range []string{prefs.QuietHoursStart, prefs.QuietHoursEnd} {
+		if clock == "" {
+			continue
+		}
+		if _, err := time.Parse("15:04", clock); err != nil {
+			return fmt.Errorf("%w: quiet hours must be in HH:MM format", ErrInvalidPreferences)
+		}
+	}
+
+	if _, err := time.LoadLocation(prefs.Timezone); err != nil {
+		return fmt.Errorf("%w: unknown timezone %q", ErrInvalidPreferences, prefs.Timezone)
+	}
+
+	return nil
+}
+
+// предпросмотр шаблона уведомления на переданном или примерном событии
+func (s *NotificationService) PreviewTemplate(req models.NotificationPreviewRequest) (notification.Message, error) {
+	now := s.clock.Now()
+	event := models.TaskEvent{
+		Type:   models.EventTaskCompleted,
+		UserID: "00000000-0000-0000-0000-000000000000",
+		Task: models.Task{
+			ID:       "00000000-0000-0000-0000-000000000001",
+			Title:    "Prepare quarterly report",
+			Status:   models.StatusDone,
+			Priority: models.PriorityHigh,
+			DueDate:  now.Add(24 * time.Hour),
+		},
+		OccurredAt: now,
+	}
+	if req.Event != nil {
+		event = *req.Event
+	}
+
+	data := notification.TemplateData{
+		Event:        event,
+		Notification: dueSoonNotification(event.Task),
+	}
+
+	msg, err := s.renderer.Render(notification.Channel(req.Channel), req.Template, data)
+	if errors.Is(err, notification.ErrTemplateNotFound) {
+		return notification.Message{}, ErrTemplateNotFound
+	}
+
+	return msg, err
+}
+
+// SendDueSoonReminders отправляет напоминания о задачах, срок которых скоро наступит.
+// Вызывается фоновым воркером по расписанию
+func (s *NotificationService) SendDueSoonReminders(ctx context.Context) error {
+	if s.notifier == nil {
+		return nil
+	}
+
+	defaultWindow := time.Duration(s.defaults.DueSoonWindowMinutes) * time.Minute
+	tasks, err := s.repo.GetDueSoonTasks(ctx, defaultWindow)
+	if err != nil {
+		return err
+	}
+
+	for _, task := range tasks {
+		if err := s.notifier.Notify(ctx, task.UserID, dueSoonNotification(task)); err != nil {
+			s.logger.Error("Failed to send due soon reminder", map[string]interface{}{
+				"task_id": task.ID,
+				"user_id": task.UserID,
+				"error":   err.Error(),
+			})
+			continue
+		}
+
+		if err := s.repo.MarkReminderSent(ctx, task.ID, task.DueDate); err != nil {
+			return err
+		}
+	}
+
+	if len(tasks) > 0 {
+		s.logger.Info("Due soon reminders processed", map[string]interface{}{
+			"tasks": len(tasks),
+		})
+	}
+
+	return nil
+}
+
+// SendOverdueAlerts уведомляет о задачах, срок которых прошел, а задача не выполнена.
+// Вызывается фоновым воркером по расписанию
+func (s *NotificationService) SendOverdueAlerts(ctx context.Context) error {
+	if s.notifier == nil {
+		return nil
+	}
+
+	tasks, err := s.repo.GetOverdueTasks(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, task := range tasks {
+		if err := s.notifier.Notify(ctx, task.UserID, overdueNotification(task)); err != nil {
+			s.logger.Error("Failed to send overdue alert", map[string]interface{}{
+				"task_id": task.ID,
+				"user_id": task.UserID,
+				"error":   err.Error(),
+			})
+			continue
+		}
+
+		if err := s.repo.MarkOverdueAlertSent(ctx, task.ID, task.DueDate); err != nil {
+			return err
+		}
+	}
+
+	if len(tasks) > 0 {
+		s.logger.Info("Overdue alerts processed", map[string]interface{}{
+			"tasks": len(tasks),
+		})
+	}
+
+	return nil
+}
+
+// HandleEvent уведомляет владельца о выполнении задач. Пользователь сам выполняет свои задачи,
+// поэтому уведомление отправляется только при явной подписке на task.completed или tasks.completed.
+// Подписывается на конвейер событий
+func (s *NotificationService) HandleEvent(ctx context.Context, event models.TaskEvent) error {
+	if s.notifier == nil || (event.Type != models.EventTaskCompleted && event.Type != models.EventTasksCompleted) {
+		return nil
+	}
+
+	prefs, err := s.GetPreferences(ctx, event.UserID)
+	if err != nil {
+		return err
+	}
+	if !prefs.ExplicitlyAllows(string(event.Type)) {
+		return nil
+	}
+
+	return s.notifier.Notify(ctx, event.UserID, completedNotification(event))
+}
+
+// completedNotification текст уведомления о выполнении одной задачи или пакета
+func completedNotification(event models.TaskEvent) models.Notification {
+	if event.Type == models.EventTaskCompleted {
+		return models.Notification{
+			Event:  string(event.Type),
+			Title:  "Task completed",
+			Body:   notificationTitle(event.Task),
+			TaskID: event.Task.ID,
+		}
+	}
+
+	titles := make([]string, len(event.Tasks))
+	for i, task := range event.Tasks {
+		titles[i] = notificationTitle(task)
+	}
+	return models.Notification{
+		Event: string(event.Type),
+		Title: fmt.Sprintf("%d tasks completed", len(event.Tasks)),
+		Body:  strings.Join(titles, ", "),
+	}
+}
+
+// overdueNotification текст уведомления о просроченной задаче
+func overdueNotification(task models.Task) models.Notification {
+	return models.Notification{
+		Event:  models.NotificationEventOverdue,
+		Title:  "Task overdue",
+		Body:   notificationTitle(task),
+		TaskID: task.ID,
+	}
+}
+
+// notificationTitle заголовок задачи для уведомления; заголовок приватной задачи зашифрован и не раскрывается
+func notificationTitle(task models.Task) string {
+	if task.Private {
+		return "Private task"
+	}
+	return task.Title
+}
+
+// dueSoonNotification текст напоминания
+func dueSoonNotification(task models.Task) models.Notification {
+	return models.Notification{
+		Event:  models.NotificationEventDueSoon,
+		Title:  "Task due soon",
+		Body:   notificationTitle(task),
+		TaskID: task.ID,
+	}
+}
@@ -0,0 +1,258 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+var testNotificationDefaults = models.NotificationPreferences{
+	Channels:             []models.NotificationChannel{models.ChannelPush},
+	DueSoonWindowMinutes: 60,
+	DigestWindowMinutes:  15,
+	Timezone:             "UTC",
+}
+
+// MockNotificationRepository implements NotificationRepository
+type MockNotificationRepository struct {
+	mock.Mock
+}
+
+func (m *MockNotificationRepository) SavePushSubscription(ctx context.Context, sub *models.PushSubscription) error {
+	args := m.Called(ctx, sub)
+	return args.Error(0)
+}
+
+func (m *MockNotificationRepository) DeletePushSubscription(ctx context.Context, userID, endpoint string) error {
+	args := m.Called(ctx, userID, endpoint)
+	return args.Error(0)
+}
+
+func (m *MockNotificationRepository) GetPushSubscriptions(ctx context.Context, userID string) ([]models.PushSubscription, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]models.PushSubscription), args.Error(1)
+}
+
+func (m *MockNotificationRepository) GetNotificationPreferences(ctx context.Context, userID string) (*models.NotificationPreferences, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.NotificationPreferences), args.Error(1)
+}
+
+func (m *MockNotificationRepository) SaveNotificationPreferences(ctx context.Context, prefs *models.NotificationPreferences) error {
+	args := m.Called(ctx, prefs)
+	return args.Error(0)
+}
+
+func (m *MockNotificationRepository) GetDueSoonTasks(ctx context.Context, defaultWindow time.Duration) ([]models.Task, error) {
+	args := m.Called(ctx, defaultWindow)
+	return args.Get(0).([]models.Task), args.Error(1)
+}
+
+func (m *MockNotificationRepository) MarkReminderSent(ctx context.Context, taskID string, dueDate time.Time) error {
+	args := m.Called(ctx, taskID, dueDate)
+	return args.Error(0)
+}
+
+func (m *MockNotificationRepository) GetOverdueTasks(ctx context.Context) ([]models.Task, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]models.Task), args.Error(1)
+}
+
+func (m *MockNotificationRepository) MarkOverdueAlertSent(ctx context.Context, taskID string, dueDate time.Time) error {
+	args := m.Called(ctx, taskID, dueDate)
+	return args.Error(0)
+}
+
+// MockNotifier implements domainService.Notifier
+type MockNotifier struct {
+	mock.Mock
+}
+
+func (m *MockNotifier) Notify(ctx context.Context, userID string, notification models.Notification) error {
+	args := m.Called(ctx, userID, notification)
+	return args.Error(0)
+}
+
+func TestSendDueSoonReminders(t *testing.T) {
+	mockNotificationRepo := new(MockNotificationRepository)
+	mockNotifier := new(MockNotifier)
+	mockLogger = new(MockLogger)
+	service := NewNotificationService(mockNotificationRepo, mockNotifier, nil, "key", testNotificationDefaults, mockLogger)
+
+	dueDate := time.Now().Add(30 * time.Minute)
+	tasks := []models.Task{
+		{ID: "1", UserID: "user1", Title: "Public", DueDate: dueDate},
+		{ID: "2", UserID: "user1", Title: "ciphertext", DueDate: dueDate, Private: true},
+	}
+
+	mockNotificationRepo.On("GetDueSoonTasks", mock.Anything, time.Hour).Return(tasks, nil)
+	mockNotifier.On("Notify", mock.Anything, "user1", mock.MatchedBy(func(n models.Notification) bool {
+		return n.TaskID == "1" && n.Body == "Public"
+	})).Return(nil).Once()
+	mockNotifier.On("Notify", mock.Anything, "user1", mock.MatchedBy(func(n models.Notification) bool {
+		return n.TaskID == "2" && n.Body == "Private task"
+	})).Return(nil).Once()
+	mockNotificationRepo.On("MarkReminderSent", mock.Anything, "1", dueDate).Return(nil).Once()
+	mockNotificationRepo.On("MarkReminderSent", mock.Anything, "2", dueDate).Return(nil).Once()
+	mockLogger.On("Info", mock.Anything, mock.Anything).Return()
+
+	err := service.SendDueSoonReminders(context.Background())
+	assert.NoError(t, err)
+
+	mockNotificationRepo.AssertExpectations(t)
+	mockNotifier.AssertExpectations(t)
+}
+
+func TestSendOverdueAlerts(t *testing.T) {
+	mockNotificationRepo := new(MockNotificationRepository)
+	mockNotifier := new(MockNotifier)
+	mockLogger = new(MockLogger)
+	service := NewNotificationService(mockNotificationRepo, mockNotifier, nil, "key", testNotificationDefaults, mockLogger)
+
+	dueDate := time.Now().Add(-time.Hour)
+	mockNotificationRepo.On("GetOverdueTasks", mock.Anything).Return([]models.Task{
+		{ID: "1", UserID: "user1", Title: "Pay rent", DueDate: dueDate},
+		{ID: "2", UserID: "user2", Title: "Broken", DueDate: dueDate},
+	}, nil)
+	mockNotifier.On("Notify", mock.Anything, "user1", models.Notification{
+		Event: models.NotificationEventOverdue, Title: "Task overdue", Body: "Pay rent", TaskID: "1",
+	}).Return(nil).Once()
+	mockNotifier.On("Notify", mock.Anything, "user2", mock.Anything).Return(errors.New("slack is down")).Once()
+	mockNotificationRepo.On("MarkOverdueAlertSent", mock.Anything, "1", dueDate).Return(nil).Once()
+	mockLogger.On("Error", "Failed to send overdue alert", mock.Anything).Return().Once()
+	mockLogger.On("Info", "Overdue alerts processed", mock.Anything).Return()
+
+	// неотправленное уведомление не отмечается и повторяется при следующем запуске
+	require.NoError(t, service.SendOverdueAlerts(context.Background()))
+	mockNotificationRepo.AssertExpectations(t)
+	mockNotifier.AssertExpectations(t)
+}
+
+func TestNotificationService_HandleEvent(t *testing.T) {
+	mockNotificationRepo := new(MockNotificationRepository)
+	mockNotifier := new(MockNotifier)
+	service := NewNotificationService(mockNotificationRepo, mockNotifier, nil, "", testNotificationDefaults, new(MockLogger))
+	ctx := context.Background()
+
+	mockNotificationRepo.On("GetNotificationPreferences", mock.Anything, "user1").Return(nil, nil)
+	mockNotificationRepo.On("GetNotificationPreferences", mock.Anything, "user2").Return(&models.NotificationPreferences{
+		EventTypes: []string{"task.completed", "tasks.completed"},
+	}, nil)
+
+	// без явной подписки о собственных действиях не уведомляем
+	completed := models.TaskEvent{Type: models.EventTaskCompleted, UserID: "user1", Task: models.Task{ID: "1", Title: "Report"}}
+	require.NoError(t, service.HandleEvent(ctx, completed))
+
+	completed.UserID = "user2"
+	mockNotifier.On("Notify", mock.Anything, "user2", models.Notification{
+		Event: "task.completed", Title: "Task completed", Body: "Report", TaskID: "1",
+	}).Return(nil).Once()
+	require.NoError(t, service.HandleEvent(ctx, completed))
+
+	mockNotifier.On("Notify", mock.Anything, "user2", models.Notification{
+		Event: "tasks.completed", Title: "2 tasks completed", Body: "Report, Private task",
+	}).Return(nil).Once()
+	require.NoError(t, service.HandleEvent(ctx, models.TaskEvent{
+		Type:   models.EventTasksCompleted,
+		UserID: "user2",
+		Tasks:  []models.Task{{ID: "1", Title: "Report"}, {ID: "2", Private: true, Locked: true}},
+	}))
+
+	require.NoError(t, service.HandleEvent(ctx, models.TaskEvent{Type: models.EventTaskCreated, UserID: "user2"}))
+	mockNotifier.AssertExpectations(t)
+}
+
+func TestNotificationPreferences(t *testing.T) {
+	mockNotificationRepo := new(MockNotificationRepository)
+	mockLogger = new(MockLogger)
+	service := NewNotificationService(mockNotificationRepo, nil, nil, "", testNotificationDefaults, mockLogger)
+
+	t.Run("defaults when not set", func(t *testing.T) {
+		mockNotificationRepo.On("GetNotificationPreferences", mock.Anything, "user1").Return(nil, nil).Once()
+
+		prefs, err := service.GetPreferences(context.Background(), "user1")
+		assert.NoError(t, err)
+		assert.Equal(t, []models.NotificationChannel{models.ChannelPush}, prefs.Channels)
+		assert.Equal(t, 60, prefs.DueSoonWindowMinutes)
+		assert.Equal(t, 15, prefs.DigestWindowMinutes)
+		assert.Equal(t, "user1", prefs.UserID)
+	})
+
+	t.Run("invalid preferences", func(t *testing.T) {
+		invalid := []models.NotificationPreferences{
+			{DueSoonWindowMinutes: 0},
+			{DueSoonWindowMinutes: 60, DigestWindowMinutes: -1},
+			{DueSoonWindowMinutes: 60, Channels: []models.NotificationChannel{"sms"}},
+			{DueSoonWindowMinutes: 60, EventTypes: []string{"task.archived"}},
+			{DueSoonWindowMinutes: 60, QuietHoursStart: "22:00"},
+			{DueSoonWindowMinutes: 60, QuietHoursStart: "25:00", QuietHoursEnd: "08:00"},
+			{DueSoonWindowMinutes: 60, Timezone: "Mars/Olympus"},
+			{DueSoonWindowMinutes: 60, Channels: []models.NotificationChannel{models.ChannelSlack}},
+			{DueSoonWindowMinutes: 60, SlackWebhookURL: "http://hooks.slack.com/services/T000/B000/XXXX"},
+			{DueSoonWindowMinutes: 60, SlackWebhookURL: "https://127.0.0.1/services/T000/B000/XXXX"},
+		}
+		for _, prefs := range invalid {
+			_, err := service.UpdatePreferences(context.Background(), "user1", prefs)
+			assert.ErrorIs(t, err, ErrInvalidPreferences)
+		}
+	})
+
+	t.Run("valid preferences", func(t *testing.T) {
+		mockNotificationRepo.On("SaveNotificationPreferences", mock.Anything, mock.Anything).Return(nil).Once()
+
+		prefs, err := service.UpdatePreferences(context.Background(), "user1", models.NotificationPreferences{
+			Channels:             []models.NotificationChannel{models.ChannelEmail},
+			EventTypes:           []string{"task.due_soon", "task.completed"},
+			DueSoonWindowMinutes: 30,
+			QuietHoursStart:      "22:00",
+			QuietHoursEnd:        "08:00",
+			Timezone:             "Europe/Moscow",
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, "user1", prefs.UserID)
+	})
+
+	t.Run("push disabled without vapid keys", func(t *testing.T) {
+		_, err := service.VAPIDPublicKey()
+		assert.Equal(t, ErrPushDisabled, err)
+		assert.NoError(t, service.SendDueSoonReminders(context.Background()))
+	})
+
+	mockNotificationRepo.AssertExpectations(t)
+}
+
+func TestSubscribe_ValidatesEndpoint(t *testing.T) {
+	mockNotificationRepo := new(MockNotificationRepository)
+	service := NewNotificationService(mockNotificationRepo, nil, nil, "key", testNotificationDefaults, new(MockLogger))
+	request := func(endpoint string) models.PushSubscriptionRequest {
+		req := models.PushSubscriptionRequest{Endpoint: endpoint}
+		req.Keys.P256dh, req.Keys.Auth = "p256dh", "auth"
+		return req
+	}
+
+	// адрес подписки присылает клиент: только https и не во внутреннюю сеть
+	for _, endpoint := range []string{
+		"http://fcm.googleapis.com/fcm/send/abc",
+		"https://127.0.0.1/push",
+		"https://169.254.169.254/latest/meta-data",
+		"https://[fd00::1]/push",
+	} {
+		_, err := service.Subscribe(context.Background(), "user1", request(endpoint))
+		assert.Equal(t, ErrInvalidSubscription, err, endpoint)
+	}
+
+	mockNotificationRepo.On("SavePushSubscription", mock.Anything, mock.Anything).Return(nil).Once()
+	sub, err := service.Subscribe(context.Background(), "user1", request("https://fcm.googleapis.com/fcm/send/abc"))
+	require.NoError(t, err)
+	assert.Equal(t, "https://fcm.googleapis.com/fcm/send/abc", sub.Endpoint)
+	mockNotificationRepo.AssertExpectations(t)
+}
@@ -0,0 +1,82 @@
+package service
+
+import (
+	"github.com/jmoloko/taskmange/internal/domain/models"
+)
+
+// sealTask шифрует заголовок, описание и заметки приватной задачи ключом владельца.
+// Ссылки не шифруются
+func (s *TaskServiceImpl) sealTask(task *models.Task) error {
+	if s.encryptor == nil {
+		return ErrPrivateTasksDisabled
+	}
+
+	title, err := s.encryptor.Encrypt(task.UserID, task.Title)
+	if err != nil {
+		return err
+	}
+
+	description, err := s.encryptor.Encrypt(task.UserID, task.Description)
+	if err != nil {
+		return err
+	}
+
+	if task.Notes != nil {
+		notes, err := s.encryptor.Encrypt(task.UserID, *task.Notes)
+		if err != nil {
+			return err
+		}
+		task.Notes = &notes
+	}
+
+	task.Title, task.Description = title, description
+	return nil
+}
+
+// openTask расшифровывает заголовок, описание и заметки приватной задачи
+func (s *TaskServiceImpl) openTask(task *models.Task) error {
+	if s.encryptor == nil {
+		return ErrPrivateTasksDisabled
+	}
+
+	title, err := s.encryptor.Decrypt(task.UserID, task.Title)
+	if err != nil {
+		return err
+	}
+
+	description, err := s.encryptor.Decrypt(task.UserID, task.Description)
+	if err != nil {
+		return err
+	}
+
+	if task.Notes != nil {
+		notes, err := s.encryptor.Decrypt(task.UserID, *task.Notes)
+		if err != nil {
+			return err
+		}
+		task.Notes = &notes
+	}
+
+	task.Title, task.Description = title, description
+	task.Locked = false
+	return nil
+}
+
+// lockTask скрывает шифртекст приватной задачи, чтобы он не уходил клиентам
+func lockTask(task models.Task) models.Task {
+	if task.Private {
+		task.Title = ""
+		task.Description = ""
+		task.Notes = nil
+		task.Locked = true
+	}
+	return task
+}
+
+// lockTasks применяет lockTask к списку задач
+func lockTasks(tasks []models.Task) []models.Task {
+	for i := range tasks {
+		tasks[i] = lockTask(tasks[i])
+	}
+	return tasks
+}
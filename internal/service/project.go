@@ -0,0 +1,216 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/jmoloko/taskmange/internal/domain/repository"
+	domainService "github.com/jmoloko/taskmange/internal/domain/service"
+	"github.com/jmoloko/taskmange/internal/logger"
+)
+
+var (
+	ErrProjectNotFound    = errors.New("project not found")
+	ErrInvalidProjectData = errors.New("invalid project data")
+)
+
+// ProjectService проекты пользователя: группировка задач, список задач проекта и аналитика по нему
+type ProjectService struct {
+	projects repository.ProjectRepository
+	tasks    projectTasks
+	logger   logger.Logger
+}
+
+// projectTasks задачи проекта и аналитика по ним из сервиса задач
+type projectTasks interface {
+	domainService.TaskReader
+	domainService.TaskAnalytics
+}
+
+// NewProjectService создает новый экземпляр ProjectService
+func NewProjectService(projects repository.ProjectRepository, tasks projectTasks, logger logger.Logger) *ProjectService {
+	return &ProjectService{
+		projects: projects,
+		tasks:    tasks,
+		logger:   logger,
+	}
+}
+
+// создание проекта
+func (s *ProjectService) Create(ctx context.Context, userID string, req models.ProjectRequest) (*models.Project, error) {
+	name, err := projectName(req.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	project := &models.Project{
+		ID:          uuid.New().String(),
+		UserID:      userID,
+		Name:        name,
+		Description: req.Description,
+	}
+	if err := s.projects.CreateProject(ctx, project); err != nil {
+		return nil, err
+	}
+
+	return project, nil
+}
+
+// список проектов пользователя
+func (s *ProjectService) List(ctx context.Context, userID string) ([]models.Project, error) {
+	projects, err := s.projects.GetProjects(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if projects == nil {
+		projects = []models.Project{}
+	}
+
+	return projects, nil
+}
+
+// Get проект пользователя по ID
+func (s *ProjectService) Get(ctx context.Context, userID, projectID string) (*models.Project, error) {
+	return s.get(ctx, userID, projectID)
+}
+
+// изменение названия и описания проекта
+func (s *ProjectService) Update(ctx context.Context, userID, projectID string, req models.ProjectRequest) (*models.Project, error) {
+	name, err := projectName(req.Name)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := uuid.Parse(projectID); err != nil {
+		return nil, ErrProjectNotFound
+	}
+
+	project := &models.Project{
+		ID:          projectID,
+		UserID:      userID,
+		Name:        name,
+		Description: req.Description,
+	}
+	if err := s.projects.UpdateProject(ctx, project); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrProjectNotFound
+		}
+		return nil, err
+	}
+
+	return project, nil
+}
+
+// удаление проекта, задачи проекта остаются без проекта
+func (s *ProjectService) Delete(ctx context.Context, userID, projectID string) error {
+	if _, err := uuid.Parse(projectID); err != nil {
+		return ErrProjectNotFound
+	}
+
+	if err := s.projects.DeleteProject(ctx, userID, projectID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrProjectNotFound
+		}
+		return err
+	}
+
+	return nil
+}
+
+// Tasks задачи проекта с фильтрами списка задач; приватные задачи отдаются без расшифровки
+func (s *ProjectService) Tasks(ctx context.Context, userID, projectID string, filters models.TaskFilters) ([]models.Task, error) {
+	if _, err := s.get(ctx, userID, projectID); err != nil {
+		return nil, err
+	}
+
+	filters.UserID, filters.ProjectID = userID, projectID
+	tasks, err := s.tasks.GetUserTasks(ctx, userID, filters)
+	if err != nil {
+		return nil, err
+	}
+
+	if tasks == nil {
+		tasks = []models.Task{}
+	}
+
+	return tasks, nil
+}
+
+// Analytics аналитика по задачам проекта: агрегаты хранилища за период, кэшируется как аналитика пользователя
+func (s *ProjectService) Analytics(ctx context.Context, userID, projectID, period string) (models.Analytics, error) {
+	if _, err := s.get(ctx, userID, projectID); err != nil {
+		return models.Analytics{}, err
+	}
+
+	return s.tasks.GetProjectAnalytics(ctx, userID, projectID, period)
+}
+
+// get проект пользователя; чужой проект неотличим от несуществующего
+func (s *ProjectService) get(ctx context.Context, userID, projectID string) (*models.Project, error) {
+	if _, err := uuid.Parse(projectID); err != nil {
+		return nil, ErrProjectNotFound
+	}
+
+	project, err := s.projects.GetProject(ctx, projectID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrProjectNotFound
+		}
+		return nil, err
+	}
+	if project.UserID != userID {
+		return nil, ErrProjectNotFound
+	}
+
+	return project, nil
+}
+
+// projectName название проекта без пробелов по краям, пустое недопустимо
+func projectName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", ErrInvalidProjectData
+	}
+	return name, nil
+}
+
+// setProject переносит задачу в проект projectID, пустой projectID убирает ее из проекта
+func (s *TaskServiceImpl) setProject(ctx context.Context, userID string, task *models.Task, projectID string) error {
+	if projectID == "" {
+		task.ProjectID = nil
+		return nil
+	}
+	if task.ProjectID != nil && *task.ProjectID == projectID {
+		return nil
+	}
+
+	if err := s.checkProject(ctx, userID, projectID); err != nil {
+		return err
+	}
+	task.ProjectID = &projectID
+
+	return nil
+}
+
+// checkProject проверяет, что projectID — проект пользователя
+func (s *TaskServiceImpl) checkProject(ctx context.Context, userID, projectID string) error {
+	if _, err := uuid.Parse(projectID); err != nil {
+		return ErrInvalidProject
+	}
+
+	project, err := s.repo.GetProject(ctx, projectID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrInvalidProject
+		}
+		return err
+	}
+	if project.UserID != userID {
+		return ErrInvalidProject
+	}
+
+	return nil
+}
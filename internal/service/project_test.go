@@ -0,0 +1,172 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jmoloko/taskmange/internal/clock"
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/jmoloko/taskmange/internal/domain/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryProjects implements repository.ProjectRepository
+type memoryProjects struct {
+	projects map[string]models.Project
+}
+
+func (r *memoryProjects) GetProject(ctx context.Context, id string) (*models.Project, error) {
+	project, ok := r.projects[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	return &project, nil
+}
+
+func (r *memoryProjects) CreateProject(ctx context.Context, project *models.Project) error {
+	r.projects[project.ID] = *project
+	return nil
+}
+
+func (r *memoryProjects) GetProjects(ctx context.Context, userID string) ([]models.Project, error) {
+	var projects []models.Project
+	for _, project := range r.projects {
+		if project.UserID == userID {
+			projects = append(projects, project)
+		}
+	}
+	return projects, nil
+}
+
+func (r *memoryProjects) UpdateProject(ctx context.Context, project *models.Project) error {
+	if existing, ok := r.projects[project.ID]; !ok || existing.UserID != project.UserID {
+		return repository.ErrNotFound
+	}
+	r.projects[project.ID] = *project
+	return nil
+}
+
+func (r *memoryProjects) DeleteProject(ctx context.Context, userID, id string) error {
+	if existing, ok := r.projects[id]; !ok || existing.UserID != userID {
+		return repository.ErrNotFound
+	}
+	delete(r.projects, id)
+	return nil
+}
+
+const (
+	testProjectID    = "6f1c0d2e-5b7a-4c3d-9e8f-1a2b3c4d5e6f"
+	foreignProjectID = "0a9b8c7d-6e5f-4a3b-8c2d-1e0f9a8b7c6d"
+)
+
+func TestProjectService(t *testing.T) {
+	mockRepo = new(MockTaskRepository)
+	mockLogger = new(MockLogger)
+	projects := &memoryProjects{projects: map[string]models.Project{
+		foreignProjectID: {ID: foreignProjectID, UserID: "user2", Name: "Foreign"},
+	}}
+	mockCache = new(MockCache)
+	tasks := NewTaskService(mockRepo, mockCache, nil, nil, nil, nil, nil, mockLogger).(*TaskServiceImpl)
+	now := time.Now()
+	tasks.clock = clock.NewFake(now)
+	service := NewProjectService(projects, tasks, mockLogger)
+	ctx := context.Background()
+
+	_, err := service.Create(ctx, "user1", models.ProjectRequest{Name: "   "})
+	assert.Equal(t, ErrInvalidProjectData, err)
+
+	project, err := service.Create(ctx, "user1", models.ProjectRequest{Name: " Website "})
+	require.NoError(t, err)
+	assert.Equal(t, "Website", project.Name)
+
+	// чужой проект и некорректный ID неотличимы от несуществующего
+	_, err = service.Get(ctx, "user1", foreignProjectID)
+	assert.Equal(t, ErrProjectNotFound, err)
+	_, err = service.Get(ctx, "user1", "not-a-uuid")
+	assert.Equal(t, ErrProjectNotFound, err)
+	_, err = service.Tasks(ctx, "user1", foreignProjectID, models.TaskFilters{})
+	assert.Equal(t, ErrProjectNotFound, err)
+	assert.Equal(t, ErrProjectNotFound, service.Delete(ctx, "user1", foreignProjectID))
+
+	listedTasks := []models.Task{{ID: "1", UserID: "user1"}, {ID: "2", UserID: "user1"}}
+	mockRepo.On("GetAll", mock.Anything, mock.MatchedBy(func(f models.TaskFilters) bool {
+		return f.UserID == "user1" && f.ProjectID == project.ID
+	})).Return(listedTasks, nil)
+
+	listed, err := service.Tasks(ctx, "user1", project.ID, models.TaskFilters{UserID: "user2", ProjectID: foreignProjectID})
+	require.NoError(t, err)
+	assert.Len(t, listed, 2)
+
+	// аналитику считает хранилище по задачам проекта, результат кэшируется под ключом пользователя
+	key := "project:" + project.ID + ":week"
+	mockCache.On("GetUserAnalytics", mock.Anything, "user1", key).Return(nil, nil).Once()
+	mockRepo.On("GetTaskStats", mock.Anything, "user1", project.ID, now.AddDate(0, 0, -7), now).Return(models.TaskStats{
+		StatusCount:     map[models.Status]int{models.StatusDone: 1, models.StatusPending: 1},
+		Completed:       1,
+		CompletedOnTime: 1,
+		Overdue:         1,
+	}, nil).Once()
+	mockCache.On("SetUserAnalytics", mock.Anything, mock.MatchedBy(func(cached repository.CachedAnalytics) bool {
+		return cached.UserID == "user1" && cached.Period == key
+	})).Return(nil).Once()
+	mockLogger.On("Info", "Analytics retrieved from cache", mock.Anything).Return()
+
+	analytics, err := service.Analytics(ctx, "user1", project.ID, "week")
+	require.NoError(t, err)
+	assert.Equal(t, 1, analytics.StatusCount[models.StatusDone])
+	assert.Equal(t, 1, analytics.OverdueTasks)
+	assert.Equal(t, 100.0, analytics.OnTimeCompletionRate)
+	assert.Equal(t, "week", analytics.Period)
+
+	mockCache.On("GetUserAnalytics", mock.Anything, "user1", key).Return(&repository.CachedAnalytics{Analytics: analytics, CachedAt: now}, nil).Once()
+	cached, err := service.Analytics(ctx, "user1", project.ID, "week")
+	require.NoError(t, err)
+	assert.Equal(t, analytics, cached)
+
+	require.NoError(t, service.Delete(ctx, "user1", project.ID))
+	_, err = service.Get(ctx, "user1", project.ID)
+	assert.Equal(t, ErrProjectNotFound, err)
+
+	mockRepo.AssertExpectations(t)
+	mockCache.AssertExpectations(t)
+}
+
+func TestSetProject(t *testing.T) {
+	mockRepo = new(MockTaskRepository)
+	mockLogger = new(MockLogger)
+	mockLogger.On("Info", mock.Anything, mock.Anything).Return()
+	service := NewTaskService(mockRepo, nil, nil, nil, nil, nil, nil, mockLogger)
+	ctx := context.Background()
+
+	mockRepo.On("GetByID", mock.Anything, "a").Return(&models.Task{ID: "a", UserID: "user1", Status: models.StatusPending}, nil)
+	mockRepo.On("GetProject", mock.Anything, testProjectID).Return(&models.Project{ID: testProjectID, UserID: "user1"}, nil)
+	mockRepo.On("GetProject", mock.Anything, foreignProjectID).Return(&models.Project{ID: foreignProjectID, UserID: "user2"}, nil)
+
+	project := foreignProjectID
+	_, err := service.PatchUserTask(ctx, "user1", "a", models.UpdateTaskRequest{ProjectID: &project})
+	assert.Equal(t, ErrInvalidProject, err)
+
+	project = "not-a-uuid"
+	_, err = service.PatchUserTask(ctx, "user1", "a", models.UpdateTaskRequest{ProjectID: &project})
+	assert.Equal(t, ErrInvalidProject, err)
+
+	project = testProjectID
+	mockRepo.On("Update", mock.Anything, mock.MatchedBy(func(task *models.Task) bool {
+		return task.ID == "a" && task.ProjectID != nil && *task.ProjectID == testProjectID
+	})).Return(nil).Once()
+	_, err = service.PatchUserTask(ctx, "user1", "a", models.UpdateTaskRequest{ProjectID: &project})
+	require.NoError(t, err)
+
+	// пустой project_id убирает задачу из проекта без проверок
+	project = ""
+	mockRepo.On("Update", mock.Anything, mock.MatchedBy(func(task *models.Task) bool {
+		return task.ID == "a" && task.ProjectID == nil
+	})).Return(nil).Once()
+	_, err = service.PatchUserTask(ctx, "user1", "a", models.UpdateTaskRequest{ProjectID: &project})
+	require.NoError(t, err)
+
+	mockRepo.AssertExpectations(t)
+}
@@ -0,0 +1,84 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jmoloko/taskmange/internal/clock"
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/jmoloko/taskmange/internal/domain/repository"
+	domainService "github.com/jmoloko/taskmange/internal/domain/service"
+	"github.com/jmoloko/taskmange/internal/logger"
+	"github.com/jmoloko/taskmange/internal/quickadd"
+)
+
+// ErrQuickAddNoTitle возвращается, если после разбора строки не осталось заголовка
+var ErrQuickAddNoTitle = errors.New("quick-add text has no title")
+
+// QuickAddService создание задачи из одной строки свободного текста.
+// Сроки в строке понимаются в часовом поясе пользователя
+type QuickAddService struct {
+	tasks  domainService.TaskCreator
+	prefs  repository.NotificationPreferencesRepository
+	logger logger.Logger
+	clock  clock.Clock
+}
+
+// NewQuickAddService создает новый экземпляр QuickAddService
+func NewQuickAddService(tasks domainService.TaskCreator, prefs repository.NotificationPreferencesRepository, logger logger.Logger) *QuickAddService {
+	return &QuickAddService{
+		tasks:  tasks,
+		prefs:  prefs,
+		logger: logger,
+		clock:  clock.System,
+	}
+}
+
+// Parse разбирает строку в задачу, не сохраняя ее. Незаданные в строке поля заполняются
+// так же, как при создании задачи
+func (s *QuickAddService) Parse(ctx context.Context, userID string, req models.QuickAddRequest) (models.Task, error) {
+	loc, err := userLocation(ctx, s.prefs, s.logger, userID, req.Timezone)
+	if err != nil {
+		return models.Task{}, err
+	}
+
+	now := s.clock.Now().In(loc)
+	result := quickadd.Parse(req.Text, now)
+	if result.Title == "" {
+		return models.Task{}, ErrQuickAddNoTitle
+	}
+
+	tags, err := normalizeTags(result.Tags)
+	if err != nil {
+		return models.Task{}, err
+	}
+
+	task := models.Task{
+		Title:    result.Title,
+		Status:   models.StatusPending,
+		Priority: result.Priority,
+		Tags:     tags,
+		UserID:   userID,
+		DueDate:  now.AddDate(0, 0, 1),
+	}
+	if task.Priority == "" {
+		task.Priority = models.PriorityMedium
+	}
+	if result.DueDate != nil {
+		task.DueDate = *result.DueDate
+	}
+
+	return task, nil
+}
+
+// Create разбирает строку и создает задачу
+func (s *QuickAddService) Create(ctx context.Context, userID string, req models.QuickAddRequest) (models.Task, error) {
+	task, err := s.Parse(ctx, userID, req)
+	if err != nil {
+		return models.Task{}, err
+	}
+
+	task.ID = uuid.New().String()
+	return s.tasks.CreateTask(ctx, userID, task)
+}
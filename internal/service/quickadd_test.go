@@ -0,0 +1,62 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jmoloko/taskmange/internal/clock"
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeTaskCreator implements domainService.TaskCreator
+type fakeTaskCreator struct {
+	created []models.Task
+}
+
+func (c *fakeTaskCreator) CreateTask(ctx context.Context, userID string, task models.Task) (models.Task, error) {
+	task.UserID = userID
+	c.created = append(c.created, task)
+	return task, nil
+}
+
+func TestQuickAdd(t *testing.T) {
+	// 22:30 UTC — в Москве уже суббота, 16 марта
+	now := time.Date(2024, 3, 15, 22, 30, 0, 0, time.UTC)
+	prefs := new(MockNotificationRepository)
+	tasks := &fakeTaskCreator{}
+	service := NewQuickAddService(tasks, prefs, new(MockLogger))
+	service.clock = clock.NewFake(now)
+	ctx := context.Background()
+
+	prefs.On("GetNotificationPreferences", mock.Anything, "user1").
+		Return(&models.NotificationPreferences{Timezone: "Europe/Moscow"}, nil)
+
+	// разбор без создания: срок считается в поясе пользователя
+	task, err := service.Parse(ctx, "user1", models.QuickAddRequest{Text: "Pay rent friday high priority #Finance"})
+	require.NoError(t, err)
+	assert.Equal(t, "Pay rent", task.Title)
+	assert.Equal(t, models.PriorityHigh, task.Priority)
+	assert.Equal(t, models.StatusPending, task.Status)
+	assert.Equal(t, []string{"finance"}, task.Tags)
+	assert.Equal(t, time.Date(2024, 3, 22, 20, 59, 0, 0, time.UTC), task.DueDate.UTC())
+	assert.Empty(t, task.ID)
+	assert.Empty(t, tasks.created)
+
+	// без срока и приоритета — значения по умолчанию, пояс из запроса
+	task, err = service.Create(ctx, "user1", models.QuickAddRequest{Text: "Buy milk", Timezone: "UTC", Create: true})
+	require.NoError(t, err)
+	assert.NotEmpty(t, task.ID)
+	assert.Equal(t, models.PriorityMedium, task.Priority)
+	assert.Equal(t, now.AddDate(0, 0, 1), task.DueDate.UTC())
+	require.Len(t, tasks.created, 1)
+
+	_, err = service.Parse(ctx, "user1", models.QuickAddRequest{Text: "tomorrow #home !low"})
+	assert.Equal(t, ErrQuickAddNoTitle, err)
+
+	_, err = service.Parse(ctx, "user1", models.QuickAddRequest{Text: "Buy milk", Timezone: "Mars/Olympus"})
+	assert.ErrorIs(t, err, ErrInvalidTimezone)
+}
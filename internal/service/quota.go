@@ -0,0 +1,144 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	domainService "github.com/jmoloko/taskmange/internal/domain/service"
+	"github.com/jmoloko/taskmange/internal/logger"
+	"github.com/jmoloko/taskmange/internal/warnings"
+)
+
+// ErrTaskQuotaExceeded возвращается, если у пользователя достигнут лимит открытых задач
+var ErrTaskQuotaExceeded = errors.New("open task quota exceeded")
+
+// QuotaService лимиты пользователя и предупреждения о приближении к ним.
+// Пока использование не ниже порога, в ответ на изменяющий запрос добавляется предупреждение;
+// при пересечении порога пользователь один раз получает уведомление
+type QuotaService struct {
+	maxOpenTasks int
+	threshold    float64
+	notifier     domainService.Notifier
+	logger       logger.Logger
+}
+
+// NewQuotaService создает новый экземпляр QuotaService.
+// maxOpenTasks 0 отключает лимит открытых задач, threshold — доля лимита (0..1), с которой предупреждать.
+// notifier может быть nil, тогда предупреждения только добавляются в ответ
+func NewQuotaService(maxOpenTasks int, threshold float64, notifier domainService.Notifier, logger logger.Logger) *QuotaService {
+	return &QuotaService{
+		maxOpenTasks: maxOpenTasks,
+		threshold:    threshold,
+		notifier:     notifier,
+		logger:       logger,
+	}
+}
+
+// MaxOpenTasks лимит открытых задач пользователя, 0 — без лимита
+func (s *QuotaService) MaxOpenTasks() int {
+	if s == nil {
+		return 0
+	}
+	return s.maxOpenTasks
+}
+
+// Observe учитывает изменение использования лимита name с before до after
+func (s *QuotaService) Observe(ctx context.Context, userID, name string, before, after, limit int) {
+	if s == nil || limit <= 0 || s.threshold <= 0 {
+		return
+	}
+
+	warnAt := int(math.Ceil(s.threshold * float64(limit)))
+	if after < warnAt {
+		return
+	}
+
+	message := fmt.Sprintf("%d of %d %s used", after, limit, quotaTitle(name))
+	warnings.Add(ctx, models.Warning{
+		Code:    "quota." + name,
+		Message: message,
+		Used:    after,
+		Limit:   limit,
+	})
+
+	if before >= warnAt || s.notifier == nil {
+		return
+	}
+
+	if err := s.notifier.Notify(ctx, userID, models.Notification{
+		Event: models.NotificationEventQuotaWarning,
+		Title: "Approaching a limit",
+		Body:  message,
+	}); err != nil {
+		s.logger.Error("Failed to send quota warning", map[string]interface{}{
+			"user_id": userID,
+			"quota":   name,
+			"error":   err.Error(),
+		})
+	}
+}
+
+func quotaTitle(name string) string {
+	switch name {
+	case models.QuotaOpenTasks:
+		return "open tasks"
+	default:
+		return name
+	}
+}
+
+// openTasks число открытых (не выполненных) задач пользователя
+func (s *TaskServiceImpl) openTasks(ctx context.Context, userID string) (int, error) {
+	open := 0
+	for _, status := range []models.Status{models.StatusPending, models.StatusInProgress} {
+		count, err := s.repo.Count(ctx, models.TaskFilters{UserID: userID, Status: status})
+		if err != nil {
+			return 0, err
+		}
+		open += count
+	}
+	return open, nil
+}
+
+// reserveOpenTasks проверяет, что пользователь может открыть еще adding задач.
+// Возвращает текущее число открытых задач, без лимита не считает их
+func (s *TaskServiceImpl) reserveOpenTasks(ctx context.Context, userID string, adding int) (int, error) {
+	limit := s.quota.MaxOpenTasks()
+	if limit <= 0 || adding == 0 {
+		return 0, nil
+	}
+
+	open, err := s.openTasks(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+	if open+adding > limit {
+		return open, ErrTaskQuotaExceeded
+	}
+
+	return open, nil
+}
+
+// observeOpenTasks учитывает adding новых открытых задач пользователя после записи
+func (s *TaskServiceImpl) observeOpenTasks(ctx context.Context, userID string, open, adding int) {
+	limit := s.quota.MaxOpenTasks()
+	if limit <= 0 || adding == 0 {
+		return
+	}
+
+	s.quota.Observe(ctx, userID, models.QuotaOpenTasks, open, open+adding, limit)
+}
+
+// openCount число открытых задач среди новых; задачи без статуса создаются в статусе pending
+func openCount(tasks []models.Task) int {
+	count := 0
+	for _, task := range tasks {
+		if task.Status != models.StatusDone {
+			count++
+		}
+	}
+	return count
+}
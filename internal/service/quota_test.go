@@ -0,0 +1,66 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/jmoloko/taskmange/internal/warnings"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestQuotaObserve(t *testing.T) {
+	mockLogger = new(MockLogger)
+	notifier := new(MockNotifier)
+	quota := NewQuotaService(10, 0.9, notifier, mockLogger)
+
+	// ниже порога предупреждений нет
+	ctx := warnings.NewContext(context.Background())
+	quota.Observe(ctx, "user1", models.QuotaOpenTasks, 7, 8, 10)
+	assert.Empty(t, warnings.FromContext(ctx))
+
+	// пересечение порога: предупреждение и одно уведомление
+	notifier.On("Notify", mock.Anything, "user1", mock.MatchedBy(func(n models.Notification) bool {
+		return n.Event == models.NotificationEventQuotaWarning && n.Body == "9 of 10 open tasks used"
+	})).Return(nil).Once()
+	ctx = warnings.NewContext(context.Background())
+	quota.Observe(ctx, "user1", models.QuotaOpenTasks, 8, 9, 10)
+	assert.Equal(t, []models.Warning{{Code: "quota.open_tasks", Message: "9 of 10 open tasks used", Used: 9, Limit: 10}}, warnings.FromContext(ctx))
+
+	// выше порога: предупреждение без повторного уведомления
+	ctx = warnings.NewContext(context.Background())
+	quota.Observe(ctx, "user1", models.QuotaOpenTasks, 9, 10, 10)
+	assert.Len(t, warnings.FromContext(ctx), 1)
+
+	notifier.AssertExpectations(t)
+
+	// без лимита и без сервиса ничего не происходит
+	var disabled *QuotaService
+	disabled.Observe(ctx, "user1", models.QuotaOpenTasks, 9, 10, 10)
+	assert.Equal(t, 0, disabled.MaxOpenTasks())
+}
+
+func TestCreate_OpenTaskQuota(t *testing.T) {
+	mockRepo = new(MockTaskRepository)
+	mockLogger = new(MockLogger)
+	mockCache = new(MockCache)
+	quota := NewQuotaService(10, 0.9, nil, mockLogger)
+	service := NewTaskService(mockRepo, mockCache, nil, nil, nil, quota, nil, mockLogger)
+	mockLogger.On("Info", mock.Anything, mock.Anything).Return()
+
+	mockRepo.On("Count", mock.Anything, models.TaskFilters{UserID: "user1", Status: models.StatusPending}).Return(6, nil)
+	mockRepo.On("Count", mock.Anything, models.TaskFilters{UserID: "user1", Status: models.StatusInProgress}).Return(2, nil)
+	mockRepo.On("Create", mock.Anything, mock.AnythingOfType("*models.Task")).Return(nil).Once()
+
+	ctx := warnings.NewContext(context.Background())
+	_, err := service.CreateTask(ctx, "user1", models.Task{Title: "Ninth"})
+	assert.NoError(t, err)
+	assert.Equal(t, []models.Warning{{Code: "quota.open_tasks", Message: "9 of 10 open tasks used", Used: 9, Limit: 10}}, warnings.FromContext(ctx))
+
+	// импорт сверх лимита отклоняется целиком
+	err = service.ImportTasks(context.Background(), "user1", []models.Task{{Title: "a"}, {Title: "b"}, {Title: "c", Status: models.StatusDone}, {Title: "d"}})
+	assert.Equal(t, ErrTaskQuotaExceeded, err)
+
+	mockRepo.AssertExpectations(t)
+}
@@ -0,0 +1,188 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/jmoloko/taskmange/internal/domain/repository"
+	"github.com/jmoloko/taskmange/internal/metrics"
+	"github.com/jmoloko/taskmange/internal/recurrence"
+)
+
+var (
+	// ErrInvalidRecurrence возвращается при некорректном правиле повторения
+	ErrInvalidRecurrence = errors.New("invalid recurrence rule")
+	// ErrRecurrenceNotFound возвращается, если серии нет или она принадлежит другому пользователю
+	ErrRecurrenceNotFound = errors.New("recurrence not found")
+)
+
+// recurrenceBatchSize сколько серий обрабатывается за один запуск планировщика
+const recurrenceBatchSize = 100
+
+// prepareRecurrence проверяет правило повторения новой задачи и назначает ей новую серию.
+// Правило сохраняется в каноническом виде, чтобы в ответе и в копиях оно выглядело одинаково
+func prepareRecurrence(task *models.Task) error {
+	task.RecurrenceID = nil
+	if task.Recurrence == "" {
+		return nil
+	}
+
+	rule, err := recurrence.Parse(task.Recurrence)
+	if err != nil {
+		return ErrInvalidRecurrence
+	}
+	task.Recurrence = rule.String()
+	seriesID := uuid.New().String()
+	task.RecurrenceID = &seriesID
+
+	return nil
+}
+
+// GetRecurrence серия повторяющейся задачи пользователя
+func (s *TaskServiceImpl) GetRecurrence(ctx context.Context, userID, recurrenceID string) (models.Recurrence, error) {
+	series, err := s.ownRecurrence(ctx, userID, recurrenceID)
+	if err != nil {
+		return models.Recurrence{}, err
+	}
+
+	return *series, nil
+}
+
+// PauseRecurrence приостанавливает серию: пока она на паузе, новые экземпляры не создаются
+func (s *TaskServiceImpl) PauseRecurrence(ctx context.Context, userID, recurrenceID string) (models.Recurrence, error) {
+	return s.setRecurrencePaused(ctx, userID, recurrenceID, true)
+}
+
+// ResumeRecurrence возобновляет серию. Пропущенные за время паузы повторения не создаются,
+// следующий экземпляр получит ближайший срок после момента создания
+func (s *TaskServiceImpl) ResumeRecurrence(ctx context.Context, userID, recurrenceID string) (models.Recurrence, error) {
+	return s.setRecurrencePaused(ctx, userID, recurrenceID, false)
+}
+
+func (s *TaskServiceImpl) setRecurrencePaused(ctx context.Context, userID, recurrenceID string, paused bool) (models.Recurrence, error) {
+	series, err := s.ownRecurrence(ctx, userID, recurrenceID)
+	if err != nil {
+		return models.Recurrence{}, err
+	}
+	if series.Paused == paused {
+		return *series, nil
+	}
+
+	if err := s.repo.SetRecurrencePaused(ctx, recurrenceID, paused); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return models.Recurrence{}, ErrRecurrenceNotFound
+		}
+		return models.Recurrence{}, err
+	}
+	series.Paused = paused
+	series.UpdatedAt = s.clock.Now()
+
+	s.logger.Info("Recurrence status changed", map[string]interface{}{
+		"recurrence_id": recurrenceID,
+		"user_id":       userID,
+		"paused":        paused,
+	})
+
+	return *series, nil
+}
+
+// ownRecurrence серия пользователя; чужая серия неотличима от несуществующей
+func (s *TaskServiceImpl) ownRecurrence(ctx context.Context, userID, recurrenceID string) (*models.Recurrence, error) {
+	series, err := s.repo.GetRecurrence(ctx, recurrenceID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrRecurrenceNotFound
+		}
+		return nil, err
+	}
+	if series.UserID != userID {
+		return nil, ErrRecurrenceNotFound
+	}
+
+	return series, nil
+}
+
+// MaterializeRecurrences создает следующие экземпляры серий, последний экземпляр которых
+// выполнен или просрочен. Вызывается планировщиком фонового worker
+func (s *TaskServiceImpl) MaterializeRecurrences(ctx context.Context) error {
+	now := s.clock.Now()
+	due, err := s.repo.GetDueOccurrences(ctx, now, recurrenceBatchSize)
+	if err != nil {
+		return err
+	}
+
+	for _, occurrence := range due {
+		if err := s.materialize(ctx, occurrence, now); err != nil {
+			s.logger.Error("Failed to create next occurrence", map[string]interface{}{
+				"task_id": occurrence.Task.ID,
+				"error":   err.Error(),
+			})
+		}
+	}
+
+	return nil
+}
+
+// materialize создает следующий экземпляр серии или завершает серию, если повторений больше нет
+func (s *TaskServiceImpl) materialize(ctx context.Context, occurrence models.DueOccurrence, now time.Time) error {
+	source := occurrence.Task
+	if source.RecurrenceID == nil {
+		return nil
+	}
+	seriesID := *source.RecurrenceID
+
+	rule, err := recurrence.Parse(occurrence.Rule)
+	if err != nil {
+		s.logger.Error("Invalid recurrence rule, ending series", map[string]interface{}{
+			"recurrence_id": seriesID,
+			"rule":          occurrence.Rule,
+		})
+		return s.repo.EndRecurrence(ctx, seriesID)
+	}
+	if rule.Exhausted(occurrence.Occurrences) {
+		return s.repo.EndRecurrence(ctx, seriesID)
+	}
+	dueDate, ok := rule.Next(source.DueDate, now)
+	if !ok {
+		return s.repo.EndRecurrence(ctx, seriesID)
+	}
+
+	// при исчерпанном лимите серия ждет: экземпляр будет создан, когда пользователь закроет задачи
+	open, err := s.reserveOpenTasks(ctx, source.UserID, 1)
+	if err != nil {
+		if err == ErrTaskQuotaExceeded {
+			s.logger.Info("Open task limit reached, next occurrence postponed", map[string]interface{}{
+				"recurrence_id": seriesID,
+				"user_id":       source.UserID,
+			})
+			return nil
+		}
+		return err
+	}
+
+	next := models.Task{ID: uuid.New().String(), DueDate: dueDate}
+	if err := s.repo.CreateOccurrence(ctx, source.ID, &next); err != nil {
+		// следующий экземпляр уже создан другим экземпляром приложения или серия поставлена на паузу
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil
+		}
+		return err
+	}
+	s.invalidateTaskLists(ctx, source.UserID)
+
+	metrics.TasksCreatedTotal.WithLabelValues(metrics.Tenant(next.UserID)).Inc()
+	metrics.TasksByStatus.WithLabelValues(string(next.Status)).Inc()
+	s.logger.Info("Next occurrence created", map[string]interface{}{
+		"recurrence_id": seriesID,
+		"task_id":       next.ID,
+		"due_date":      next.DueDate,
+	})
+
+	s.observeOpenTasks(ctx, next.UserID, open, 1)
+	s.publish(ctx, models.EventTaskCreated, next)
+
+	return nil
+}
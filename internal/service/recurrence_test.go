@@ -0,0 +1,98 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/jmoloko/taskmange/internal/domain/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateRecurringTask(t *testing.T) {
+	mockRepo = new(MockTaskRepository)
+	mockLogger = new(MockLogger)
+	mockLogger.On("Info", mock.Anything, mock.Anything).Return()
+	service := NewTaskService(mockRepo, nil, nil, nil, nil, nil, nil, mockLogger)
+	ctx := context.Background()
+
+	_, err := service.CreateTask(ctx, "user1", models.Task{ID: "a", Title: "Report", Recurrence: "FREQ=HOURLY"})
+	assert.Equal(t, ErrInvalidRecurrence, err)
+
+	// правило сохраняется в каноническом виде, серия назначается сервером
+	foreign := "foreign-series"
+	mockRepo.On("Create", mock.Anything, mock.AnythingOfType("*models.Task")).Return(nil).Once()
+	task, err := service.CreateTask(ctx, "user1", models.Task{ID: "a", Title: "Report", Recurrence: "freq=weekly;byday=mo", RecurrenceID: &foreign})
+	require.NoError(t, err)
+	assert.Equal(t, "FREQ=WEEKLY;BYDAY=MO", task.Recurrence)
+	require.NotNil(t, task.RecurrenceID)
+	assert.NotEqual(t, foreign, *task.RecurrenceID)
+
+	// без правила задача не относится к серии
+	mockRepo.On("Create", mock.Anything, mock.AnythingOfType("*models.Task")).Return(nil).Once()
+	task, err = service.CreateTask(ctx, "user1", models.Task{ID: "b", Title: "Call", RecurrenceID: &foreign})
+	require.NoError(t, err)
+	assert.Nil(t, task.RecurrenceID)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestMaterializeRecurrences(t *testing.T) {
+	mockRepo = new(MockTaskRepository)
+	mockLogger = new(MockLogger)
+	mockLogger.On("Info", mock.Anything, mock.Anything).Return()
+	service := NewTaskService(mockRepo, nil, nil, nil, nil, nil, nil, mockLogger)
+
+	// выполнена досрочно: следующий срок отсчитывается от срока экземпляра, а не от текущего момента
+	dueDate := time.Now().UTC().Add(48 * time.Hour).Truncate(time.Second)
+	daily, exhausted, raced := "series-daily", "series-count", "series-raced"
+	mockRepo.On("GetDueOccurrences", mock.Anything, mock.Anything, recurrenceBatchSize).Return([]models.DueOccurrence{
+		{Task: models.Task{ID: "t1", UserID: "user1", Status: models.StatusDone, DueDate: dueDate, RecurrenceID: &daily}, Rule: "FREQ=DAILY", Occurrences: 1},
+		{Task: models.Task{ID: "t2", UserID: "user1", Status: models.StatusDone, DueDate: dueDate, RecurrenceID: &exhausted}, Rule: "FREQ=DAILY;COUNT=2", Occurrences: 2},
+		{Task: models.Task{ID: "t3", UserID: "user1", Status: models.StatusDone, DueDate: dueDate, RecurrenceID: &raced}, Rule: "FREQ=DAILY", Occurrences: 1},
+	}, nil)
+	mockRepo.On("CreateOccurrence", mock.Anything, "t1", mock.MatchedBy(func(next *models.Task) bool {
+		return next.ID != "" && next.DueDate.Equal(dueDate.AddDate(0, 0, 1))
+	})).Return(nil).Once()
+	mockRepo.On("EndRecurrence", mock.Anything, exhausted).Return(nil).Once()
+	// следующий экземпляр уже создан другим экземпляром приложения
+	mockRepo.On("CreateOccurrence", mock.Anything, "t3", mock.Anything).Return(repository.ErrNotFound).Once()
+
+	require.NoError(t, service.MaterializeRecurrences(context.Background()))
+	mockRepo.AssertExpectations(t)
+}
+
+func TestPauseRecurrence(t *testing.T) {
+	mockRepo = new(MockTaskRepository)
+	mockLogger = new(MockLogger)
+	mockLogger.On("Info", mock.Anything, mock.Anything).Return()
+	service := NewTaskService(mockRepo, nil, nil, nil, nil, nil, nil, mockLogger)
+	ctx := context.Background()
+
+	// сервис меняет полученную серию, поэтому на каждый вызов своя копия
+	for i := 0; i < 3; i++ {
+		mockRepo.On("GetRecurrence", mock.Anything, "series1").Return(&models.Recurrence{ID: "series1", UserID: "user1"}, nil).Once()
+	}
+	mockRepo.On("GetRecurrence", mock.Anything, "missing").Return(nil, repository.ErrNotFound)
+
+	// чужая серия неотличима от несуществующей
+	_, err := service.PauseRecurrence(ctx, "user2", "series1")
+	assert.Equal(t, ErrRecurrenceNotFound, err)
+	_, err = service.PauseRecurrence(ctx, "user1", "missing")
+	assert.Equal(t, ErrRecurrenceNotFound, err)
+
+	mockRepo.On("SetRecurrencePaused", mock.Anything, "series1", true).Return(nil).Once()
+	series, err := service.PauseRecurrence(ctx, "user1", "series1")
+	require.NoError(t, err)
+	assert.True(t, series.Paused)
+
+	// серия уже активна, повторное возобновление ничего не меняет
+	series, err = service.ResumeRecurrence(ctx, "user1", "series1")
+	require.NoError(t, err)
+	assert.False(t, series.Paused)
+
+	mockRepo.AssertExpectations(t)
+}
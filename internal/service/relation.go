@@ -0,0 +1,95 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/jmoloko/taskmange/internal/domain/repository"
+)
+
+// RelateTasks связывает две задачи пользователя. Повторное связывание не является ошибкой
+func (s *TaskServiceImpl) RelateTasks(ctx context.Context, userID, taskID, relatedID string) error {
+	if taskID == relatedID {
+		return ErrSelfRelation
+	}
+
+	for _, id := range []string{taskID, relatedID} {
+		if err := s.checkOwner(ctx, userID, id); err != nil {
+			return err
+		}
+	}
+
+	if err := s.repo.AddRelation(ctx, taskID, relatedID); err != nil {
+		s.logger.Error("Failed to relate tasks", map[string]interface{}{
+			"task_id":    taskID,
+			"related_id": relatedID,
+			"error":      err.Error(),
+		})
+		return err
+	}
+
+	return nil
+}
+
+// UnrelateTasks удаляет связь между задачами пользователя
+func (s *TaskServiceImpl) UnrelateTasks(ctx context.Context, userID, taskID, relatedID string) error {
+	if err := s.checkOwner(ctx, userID, taskID); err != nil {
+		return err
+	}
+
+	if err := s.repo.RemoveRelation(ctx, taskID, relatedID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrRelationNotFound
+		}
+		return err
+	}
+
+	return nil
+}
+
+// ExpandRelated добавляет к задачам краткое представление связанных задач.
+// Приватные связанные задачи отдаются без расшифровки
+func (s *TaskServiceImpl) ExpandRelated(ctx context.Context, userID string, tasks []models.Task) ([]models.Task, error) {
+	if len(tasks) == 0 {
+		return tasks, nil
+	}
+
+	ids := make([]string, len(tasks))
+	for i, task := range tasks {
+		ids[i] = task.ID
+	}
+
+	related, err := s.repo.GetRelated(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range tasks {
+		summaries := []models.TaskSummary{}
+		for _, task := range related[tasks[i].ID] {
+			// связи создаются только между задачами одного пользователя
+			if task.UserID != userID {
+				continue
+			}
+			summaries = append(summaries, lockTask(task).Summary())
+		}
+		tasks[i].Related = summaries
+	}
+
+	return tasks, nil
+}
+
+// checkOwner проверяет, что задача существует и принадлежит пользователю
+func (s *TaskServiceImpl) checkOwner(ctx context.Context, userID, taskID string) error {
+	task, err := s.repo.GetByID(ctx, taskID)
+	if err != nil {
+		return ErrTaskNotFound
+	}
+
+	if task.UserID != userID {
+		return ErrAccessDenied
+	}
+
+	return nil
+}
@@ -0,0 +1,59 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/jmoloko/taskmange/internal/domain/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestRelateTasks(t *testing.T) {
+	mockRepo = new(MockTaskRepository)
+	mockLogger = new(MockLogger)
+	mockCache = new(MockCache)
+	service := NewTaskService(mockRepo, mockCache, nil, nil, nil, nil, nil, mockLogger)
+	ctx := context.Background()
+
+	assert.Equal(t, ErrSelfRelation, service.RelateTasks(ctx, "user1", "a", "a"))
+
+	mockRepo.On("GetByID", mock.Anything, "a").Return(&models.Task{ID: "a", UserID: "user1"}, nil)
+	mockRepo.On("GetByID", mock.Anything, "b").Return(&models.Task{ID: "b", UserID: "user1"}, nil)
+	mockRepo.On("GetByID", mock.Anything, "foreign").Return(&models.Task{ID: "foreign", UserID: "user2"}, nil)
+	mockRepo.On("AddRelation", mock.Anything, "a", "b").Return(nil).Once()
+
+	assert.NoError(t, service.RelateTasks(ctx, "user1", "a", "b"))
+	assert.Equal(t, ErrAccessDenied, service.RelateTasks(ctx, "user1", "a", "foreign"))
+
+	mockRepo.On("RemoveRelation", mock.Anything, "a", "b").Return(repository.ErrNotFound).Once()
+	assert.Equal(t, ErrRelationNotFound, service.UnrelateTasks(ctx, "user1", "a", "b"))
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestExpandRelated(t *testing.T) {
+	mockRepo = new(MockTaskRepository)
+	mockLogger = new(MockLogger)
+	mockCache = new(MockCache)
+	service := NewTaskService(mockRepo, mockCache, nil, nil, nil, nil, nil, mockLogger)
+
+	tasks := []models.Task{{ID: "a", UserID: "user1"}, {ID: "b", UserID: "user1"}}
+	mockRepo.On("GetRelated", mock.Anything, []string{"a", "b"}).Return(map[string][]models.Task{
+		"a": {
+			{ID: "c", UserID: "user1", Title: "Spec", Status: models.StatusPending},
+			{ID: "d", UserID: "user1", Title: "sealed", Private: true},
+		},
+	}, nil).Once()
+
+	got, err := service.ExpandRelated(context.Background(), "user1", tasks)
+	assert.NoError(t, err)
+	assert.Equal(t, []models.TaskSummary{
+		{ID: "c", Title: "Spec", Status: models.StatusPending},
+		{ID: "d", Private: true, Locked: true},
+	}, got[0].Related)
+	assert.Empty(t, got[1].Related)
+
+	mockRepo.AssertExpectations(t)
+}
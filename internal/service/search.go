@@ -0,0 +1,257 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoloko/taskmange/internal/clock"
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/jmoloko/taskmange/internal/domain/repository"
+	domainService "github.com/jmoloko/taskmange/internal/domain/service"
+	"github.com/jmoloko/taskmange/internal/logger"
+	"github.com/jmoloko/taskmange/internal/search"
+)
+
+const (
+	// максимальное число сохраненных поисков у одного пользователя
+	maxSavedSearchesPerUser = 50
+	maxSavedSearchName      = 100
+	// интервал оповещений по умолчанию и наибольший — неделя
+	defaultAlertIntervalMinutes = 60
+	maxAlertIntervalMinutes     = 7 * 24 * 60
+)
+
+var (
+	ErrInvalidSavedSearch   = errors.New("invalid saved search")
+	ErrSavedSearchNotFound  = errors.New("saved search not found")
+	ErrTooManySavedSearches = errors.New("too many saved searches")
+)
+
+// SavedSearchService сохраненные поиски и оповещения о новых совпадениях.
+// Совпадения проверяются по событиям задач из конвейера событий
+type SavedSearchService struct {
+	repo     repository.SavedSearchRepository
+	notifier domainService.Notifier
+	logger   logger.Logger
+	clock    clock.Clock
+}
+
+// NewSavedSearchService создает новый экземпляр SavedSearchService.
+// notifier может быть nil, тогда оповещения не отправляются
+func NewSavedSearchService(repo repository.SavedSearchRepository, notifier domainService.Notifier, logger logger.Logger) *SavedSearchService {
+	return &SavedSearchService{
+		repo:     repo,
+		notifier: notifier,
+		logger:   logger,
+		clock:    clock.System,
+	}
+}
+
+// создание поиска
+func (s *SavedSearchService) Create(ctx context.Context, userID string, req models.SavedSearchRequest) (*models.SavedSearch, error) {
+	saved, err := newSavedSearch(userID, req, s.clock.Now())
+	if err != nil {
+		return nil, err
+	}
+	saved.ID = uuid.New().String()
+
+	existing, err := s.repo.GetSavedSearches(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) >= maxSavedSearchesPerUser {
+		return nil, ErrTooManySavedSearches
+	}
+
+	if err := s.repo.CreateSavedSearch(ctx, saved); err != nil {
+		return nil, err
+	}
+
+	return saved, nil
+}
+
+// изменение поиска пользователя
+func (s *SavedSearchService) Update(ctx context.Context, userID, searchID string, req models.SavedSearchRequest) (*models.SavedSearch, error) {
+	if _, err := uuid.Parse(searchID); err != nil {
+		return nil, ErrSavedSearchNotFound
+	}
+
+	saved, err := newSavedSearch(userID, req, s.clock.Now())
+	if err != nil {
+		return nil, err
+	}
+	saved.ID = searchID
+
+	if err := s.repo.UpdateSavedSearch(ctx, saved); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrSavedSearchNotFound
+		}
+		return nil, err
+	}
+
+	return saved, nil
+}
+
+// список поисков пользователя
+func (s *SavedSearchService) List(ctx context.Context, userID string) ([]models.SavedSearch, error) {
+	searches, err := s.repo.GetSavedSearches(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if searches == nil {
+		searches = []models.SavedSearch{}
+	}
+
+	return searches, nil
+}
+
+// удаление поиска пользователя
+func (s *SavedSearchService) Delete(ctx context.Context, userID, searchID string) error {
+	if _, err := uuid.Parse(searchID); err != nil {
+		return ErrSavedSearchNotFound
+	}
+
+	if err := s.repo.DeleteSavedSearch(ctx, userID, searchID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrSavedSearchNotFound
+		}
+		return err
+	}
+
+	return nil
+}
+
+// HandleEvent проверяет созданные и измененные задачи на поиски владельца с оповещением.
+// Уведомление приходит один раз на задачу: изменение задачи, которая уже подходила, его не повторяет.
+// Подписывается на конвейер событий задач
+func (s *SavedSearchService) HandleEvent(ctx context.Context, event models.TaskEvent) error {
+	if s.notifier == nil || event.Type == models.EventTaskDeleted || event.Type == models.EventTasksDeleted {
+		return nil
+	}
+
+	searches, err := s.repo.GetAlertSearches(ctx, event.UserID)
+	if err != nil || len(searches) == 0 {
+		return err
+	}
+
+	tasks := event.Tasks
+	if len(tasks) == 0 {
+		tasks = []models.Task{event.Task}
+	}
+
+	var errs []error
+	for _, saved := range searches {
+		query, err := s.parse(saved)
+		if err != nil {
+			// запрос проверялся при сохранении, но мог перестать разбираться после изменения языка
+			s.logger.Warn("Skipping invalid saved search", map[string]interface{}{
+				"search_id": saved.ID,
+				"error":     err.Error(),
+			})
+			continue
+		}
+
+		for _, task := range tasks {
+			if !query.Match(task) {
+				continue
+			}
+			if err := s.alert(ctx, saved, task); err != nil {
+				errs = append(errs, fmt.Errorf("saved search %s: %w", saved.ID, err))
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// alert уведомляет о новом совпадении, если интервал оповещений поиска прошел
+func (s *SavedSearchService) alert(ctx context.Context, saved models.SavedSearch, task models.Task) error {
+	matched, err := s.repo.RecordSearchMatch(ctx, saved.ID, task.ID)
+	if err != nil {
+		// задачу успели удалить
+		if errors.Is(err, repository.ErrInvalidReference) {
+			return nil
+		}
+		return err
+	}
+	if !matched {
+		return nil
+	}
+
+	interval := time.Duration(saved.AlertIntervalMinutes) * time.Minute
+	claimed, suppressed, err := s.repo.ClaimSearchAlert(ctx, saved.ID, interval, s.clock.Now())
+	if err != nil || !claimed {
+		return err
+	}
+
+	return s.notifier.Notify(ctx, saved.UserID, searchMatchNotification(saved, task, suppressed))
+}
+
+// parse разбирает запрос поиска относительно текущего времени в его часовом поясе
+func (s *SavedSearchService) parse(saved models.SavedSearch) (search.Query, error) {
+	loc, err := time.LoadLocation(saved.Timezone)
+	if err != nil {
+		return search.Query{}, err
+	}
+	return search.Parse(saved.Query, s.clock.Now().In(loc))
+}
+
+// newSavedSearch проверяет запрос на момент now и заполняет значения по умолчанию
+func newSavedSearch(userID string, req models.SavedSearchRequest, now time.Time) (*models.SavedSearch, error) {
+	saved := &models.SavedSearch{
+		UserID:               userID,
+		Name:                 strings.TrimSpace(req.Name),
+		Query:                strings.TrimSpace(req.Query),
+		Timezone:             req.Timezone,
+		Alert:                req.Alert,
+		AlertIntervalMinutes: defaultAlertIntervalMinutes,
+	}
+	if saved.Timezone == "" {
+		saved.Timezone = "UTC"
+	}
+	if req.AlertIntervalMinutes != nil {
+		saved.AlertIntervalMinutes = *req.AlertIntervalMinutes
+	}
+
+	if saved.Name == "" || len([]rune(saved.Name)) > maxSavedSearchName {
+		return nil, fmt.Errorf("%w: name must be 1 to %d characters", ErrInvalidSavedSearch, maxSavedSearchName)
+	}
+	if saved.Query == "" {
+		return nil, fmt.Errorf("%w: query is empty", ErrInvalidSavedSearch)
+	}
+	loc, err := time.LoadLocation(saved.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unknown timezone %q", ErrInvalidSavedSearch, saved.Timezone)
+	}
+	if _, err := search.Parse(saved.Query, now.In(loc)); err != nil {
+		return nil, fmt.Errorf("%w: invalid query %v", ErrInvalidSavedSearch, err)
+	}
+	if saved.AlertIntervalMinutes < 0 || saved.AlertIntervalMinutes > maxAlertIntervalMinutes {
+		return nil, fmt.Errorf("%w: alert_interval_minutes must be between 0 and %d", ErrInvalidSavedSearch, maxAlertIntervalMinutes)
+	}
+
+	return saved, nil
+}
+
+// searchMatchNotification текст оповещения; заголовок приватной задачи зашифрован и не раскрывается
+func searchMatchNotification(saved models.SavedSearch, task models.Task, suppressed int) models.Notification {
+	body := task.Title
+	if task.Private {
+		body = "Private task"
+	}
+	if suppressed > 0 {
+		body += fmt.Sprintf(" (and %d more since the last alert)", suppressed)
+	}
+
+	return models.Notification{
+		Event:  models.NotificationEventSearchMatch,
+		Title:  fmt.Sprintf("New task matches %q", saved.Name),
+		Body:   body,
+		TaskID: task.ID,
+	}
+}
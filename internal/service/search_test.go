@@ -0,0 +1,173 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jmoloko/taskmange/internal/clock"
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/jmoloko/taskmange/internal/domain/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// memorySavedSearches implements repository.SavedSearchRepository
+type memorySavedSearches struct {
+	searches   []models.SavedSearch
+	matches    map[string]bool
+	suppressed map[string]int
+}
+
+func (r *memorySavedSearches) CreateSavedSearch(ctx context.Context, search *models.SavedSearch) error {
+	r.searches = append(r.searches, *search)
+	return nil
+}
+
+func (r *memorySavedSearches) UpdateSavedSearch(ctx context.Context, search *models.SavedSearch) error {
+	for i, s := range r.searches {
+		if s.ID == search.ID && s.UserID == search.UserID {
+			r.searches[i] = *search
+			return nil
+		}
+	}
+	return repository.ErrNotFound
+}
+
+func (r *memorySavedSearches) DeleteSavedSearch(ctx context.Context, userID, searchID string) error {
+	for i, s := range r.searches {
+		if s.ID == searchID && s.UserID == userID {
+			r.searches = append(r.searches[:i], r.searches[i+1:]...)
+			return nil
+		}
+	}
+	return repository.ErrNotFound
+}
+
+func (r *memorySavedSearches) GetSavedSearches(ctx context.Context, userID string) ([]models.SavedSearch, error) {
+	var searches []models.SavedSearch
+	for _, s := range r.searches {
+		if s.UserID == userID {
+			searches = append(searches, s)
+		}
+	}
+	return searches, nil
+}
+
+func (r *memorySavedSearches) GetAlertSearches(ctx context.Context, userID string) ([]models.SavedSearch, error) {
+	var searches []models.SavedSearch
+	for _, s := range r.searches {
+		if s.UserID == userID && s.Alert {
+			searches = append(searches, s)
+		}
+	}
+	return searches, nil
+}
+
+func (r *memorySavedSearches) RecordSearchMatch(ctx context.Context, searchID, taskID string) (bool, error) {
+	key := searchID + "/" + taskID
+	if r.matches[key] {
+		return false, nil
+	}
+	r.matches[key] = true
+	return true, nil
+}
+
+func (r *memorySavedSearches) ClaimSearchAlert(ctx context.Context, searchID string, interval time.Duration, now time.Time) (bool, int, error) {
+	for i, s := range r.searches {
+		if s.ID != searchID {
+			continue
+		}
+		if s.LastAlertAt != nil && now.Sub(*s.LastAlertAt) < interval {
+			r.suppressed[searchID]++
+			return false, 0, nil
+		}
+		suppressed := r.suppressed[searchID]
+		r.suppressed[searchID] = 0
+		r.searches[i].LastAlertAt = &now
+		return true, suppressed, nil
+	}
+	return false, 0, repository.ErrNotFound
+}
+
+func TestSavedSearches(t *testing.T) {
+	repo := &memorySavedSearches{matches: map[string]bool{}, suppressed: map[string]int{}}
+	service := NewSavedSearchService(repo, nil, new(MockLogger))
+	ctx := context.Background()
+
+	_, err := service.Create(ctx, "user1", models.SavedSearchRequest{Name: "Bad", Query: "stat:done"})
+	assert.ErrorIs(t, err, ErrInvalidSavedSearch)
+	_, err = service.Create(ctx, "user1", models.SavedSearchRequest{Name: "Bad", Query: "#work", Timezone: "Mars/Olympus"})
+	assert.ErrorIs(t, err, ErrInvalidSavedSearch)
+	negative := -1
+	_, err = service.Create(ctx, "user1", models.SavedSearchRequest{Name: "Bad", Query: "#work", AlertIntervalMinutes: &negative})
+	assert.ErrorIs(t, err, ErrInvalidSavedSearch)
+
+	saved, err := service.Create(ctx, "user1", models.SavedSearchRequest{Name: " Work ", Query: "#work"})
+	require.NoError(t, err)
+	assert.Equal(t, "Work", saved.Name)
+	assert.Equal(t, "UTC", saved.Timezone)
+	assert.Equal(t, defaultAlertIntervalMinutes, saved.AlertIntervalMinutes)
+
+	updated, err := service.Update(ctx, "user1", saved.ID, models.SavedSearchRequest{Name: "Work", Query: "#work is:open", Alert: true})
+	require.NoError(t, err)
+	assert.True(t, updated.Alert)
+
+	_, err = service.Update(ctx, "user2", saved.ID, models.SavedSearchRequest{Name: "Work", Query: "#work"})
+	assert.ErrorIs(t, err, ErrSavedSearchNotFound)
+	assert.ErrorIs(t, service.Delete(ctx, "user2", saved.ID), ErrSavedSearchNotFound)
+
+	searches, err := service.List(ctx, "user2")
+	require.NoError(t, err)
+	assert.Empty(t, searches)
+
+	require.NoError(t, service.Delete(ctx, "user1", saved.ID))
+	assert.ErrorIs(t, service.Delete(ctx, "user1", "not-a-uuid"), ErrSavedSearchNotFound)
+}
+
+func TestSavedSearchAlerts(t *testing.T) {
+	repo := &memorySavedSearches{matches: map[string]bool{}, suppressed: map[string]int{}}
+	notifier := new(MockNotifier)
+	service := NewSavedSearchService(repo, notifier, new(MockLogger))
+	now := time.Date(2024, 6, 12, 15, 0, 0, 0, time.UTC)
+	fake := clock.NewFake(now)
+	service.clock = fake
+	ctx := context.Background()
+
+	_, err := service.Create(ctx, "user1", models.SavedSearchRequest{Name: "Work", Query: "#work budget", Alert: true})
+	require.NoError(t, err)
+	_, err = service.Create(ctx, "user1", models.SavedSearchRequest{Name: "Silent", Query: "#work"})
+	require.NoError(t, err)
+
+	event := func(eventType models.EventType, id, title string) models.TaskEvent {
+		return models.TaskEvent{
+			Type:   eventType,
+			UserID: "user1",
+			Task:   models.Task{ID: id, Title: title, Tags: []string{"work"}, UserID: "user1"},
+		}
+	}
+
+	notifier.On("Notify", mock.Anything, "user1", mock.MatchedBy(func(n models.Notification) bool {
+		return n.Event == models.NotificationEventSearchMatch && n.TaskID == "t1" && n.Body == "Budget review"
+	})).Return(nil).Once()
+
+	// неподходящая задача и удаление не уведомляют
+	require.NoError(t, service.HandleEvent(ctx, event(models.EventTaskCreated, "t0", "Lunch")))
+	require.NoError(t, service.HandleEvent(ctx, event(models.EventTaskDeleted, "t1", "Budget review")))
+	require.NoError(t, service.HandleEvent(ctx, event(models.EventTaskCreated, "t1", "Budget review")))
+
+	// изменение уже подходившей задачи не уведомляет повторно
+	require.NoError(t, service.HandleEvent(ctx, event(models.EventTaskUpdated, "t1", "Budget review v2")))
+
+	// внутри интервала совпадение копится и попадает в следующее уведомление
+	require.NoError(t, service.HandleEvent(ctx, event(models.EventTaskCreated, "t2", "Budget draft")))
+	now = now.Add(time.Hour)
+	fake.Set(now)
+	notifier.On("Notify", mock.Anything, "user1", mock.MatchedBy(func(n models.Notification) bool {
+		return n.TaskID == "t3" && n.Body == "Budget plan (and 1 more since the last alert)"
+	})).Return(nil).Once()
+	require.NoError(t, service.HandleEvent(ctx, event(models.EventTaskUpdated, "t3", "Budget plan")))
+
+	notifier.AssertExpectations(t)
+}
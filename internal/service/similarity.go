@@ -0,0 +1,168 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/jmoloko/taskmange/internal/domain/repository"
+	domainService "github.com/jmoloko/taskmange/internal/domain/service"
+	"github.com/jmoloko/taskmange/internal/logger"
+)
+
+const (
+	// текст задачи длиннее обрезается перед построением вектора
+	maxEmbeddingRunes = 2000
+	// сколько задач без вектора обрабатывается за один запуск фоновой задачи
+	embeddingBackfillBatch = 50
+	// DefaultSimilarLimit и MaxSimilarLimit число похожих задач в ответе
+	DefaultSimilarLimit = 5
+	MaxSimilarLimit     = 20
+)
+
+// ErrSimilarityDisabled возвращается, если модель эмбеддингов не настроена
+var ErrSimilarityDisabled = errors.New("similar tasks are not configured")
+
+// SimilarityService поиск похожих задач по векторным представлениям заголовка и описания.
+// Векторы обновляются асинхронно по событиям задач и фоновой задачей для задач без вектора.
+// Приватные задачи в модель не отправляются и в поиске не участвуют
+type SimilarityService struct {
+	repo     repository.TaskEmbeddingRepository
+	tasks    domainService.TaskReader
+	embedder domainService.TextEmbedder
+	minScore float64
+	logger   logger.Logger
+}
+
+// NewSimilarityService создает новый экземпляр SimilarityService.
+// embedder может быть nil, тогда поиск похожих задач отключен.
+// minScore — наименьшая близость (0..1), с которой задача считается похожей
+func NewSimilarityService(repo repository.TaskEmbeddingRepository, tasks domainService.TaskReader, embedder domainService.TextEmbedder, minScore float64, logger logger.Logger) *SimilarityService {
+	return &SimilarityService{
+		repo:     repo,
+		tasks:    tasks,
+		embedder: embedder,
+		minScore: minScore,
+		logger:   logger,
+	}
+}
+
+// Similar задачи пользователя, похожие на taskID, по убыванию близости. Если вектор задачи еще
+// не построен (событие не обработано), он строится в запросе
+func (s *SimilarityService) Similar(ctx context.Context, userID, taskID string, limit int) ([]models.SimilarTask, error) {
+	if s.embedder == nil {
+		return nil, ErrSimilarityDisabled
+	}
+
+	task, err := s.tasks.GetUserTask(ctx, userID, taskID)
+	if err != nil {
+		return nil, err
+	}
+	if task.Private {
+		return nil, ErrAIPrivateTask
+	}
+
+	if err := s.index(ctx, task); err != nil {
+		return nil, err
+	}
+
+	similar, err := s.repo.FindSimilarTasks(ctx, taskID, s.embedder.Name(), s.minScore, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	if similar == nil {
+		similar = []models.SimilarTask{}
+	}
+	for i := range similar {
+		similar[i].Task = lockTask(similar[i].Task)
+	}
+
+	return similar, nil
+}
+
+// HandleEvent обновляет вектор созданной или измененной задачи. Задача, ставшая приватной,
+// убирается из поиска; векторы удаленных задач удаляет каскад в БД
+func (s *SimilarityService) HandleEvent(ctx context.Context, event models.TaskEvent) error {
+	if s.embedder == nil {
+		return nil
+	}
+	if event.Type != models.EventTaskCreated && event.Type != models.EventTaskUpdated {
+		return nil
+	}
+
+	if event.Task.Private {
+		return s.repo.DeleteEmbedding(ctx, event.Task.ID)
+	}
+
+	return s.index(ctx, event.Task)
+}
+
+// Backfill строит векторы задач, у которых их нет: созданных до включения поиска или при
+// недоступной модели. Первая ошибка модели прерывает запуск, остальные задачи ждут следующего
+func (s *SimilarityService) Backfill(ctx context.Context) error {
+	if s.embedder == nil {
+		return nil
+	}
+
+	tasks, err := s.repo.GetTasksWithoutEmbedding(ctx, s.embedder.Name(), embeddingBackfillBatch)
+	if err != nil {
+		return err
+	}
+
+	for _, task := range tasks {
+		if err := s.index(ctx, task); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// index строит и сохраняет вектор задачи, если ее текст изменился с прошлого раза
+func (s *SimilarityService) index(ctx context.Context, task models.Task) error {
+	text := embeddingText(task)
+	sum := sha256.Sum256([]byte(text))
+	hash := hex.EncodeToString(sum[:])
+	model := s.embedder.Name()
+
+	stored, err := s.repo.GetEmbeddingHash(ctx, task.ID, model)
+	if err == nil && stored == hash {
+		return nil
+	}
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return err
+	}
+
+	vector, err := s.embedder.Embed(ctx, text)
+	if err != nil {
+		s.logger.Warn("Failed to embed task", map[string]interface{}{
+			"task_id": task.ID,
+			"error":   err.Error(),
+		})
+		if errors.Is(err, context.DeadlineExceeded) {
+			return ErrAITimeout
+		}
+		return ErrAIUnavailable
+	}
+
+	return s.repo.SaveEmbedding(ctx, models.TaskEmbedding{
+		TaskID:     task.ID,
+		UserID:     task.UserID,
+		Model:      model,
+		SourceHash: hash,
+		Vector:     vector,
+	})
+}
+
+// embeddingText текст задачи для вектора: заголовок и описание. Заметки не учитываются,
+// они описывают ход работы, а не саму задачу
+func embeddingText(task models.Task) string {
+	text := task.Title
+	if task.Description != "" {
+		text += "\n" + task.Description
+	}
+	return truncateRunes(text, maxEmbeddingRunes)
+}
@@ -0,0 +1,122 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/jmoloko/taskmange/internal/domain/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryEmbeddings implements repository.TaskEmbeddingRepository
+type memoryEmbeddings struct {
+	vectors map[string]models.TaskEmbedding
+	similar []models.SimilarTask
+	missing []models.Task
+}
+
+func (r *memoryEmbeddings) GetEmbeddingHash(ctx context.Context, taskID, model string) (string, error) {
+	embedding, ok := r.vectors[taskID]
+	if !ok || embedding.Model != model {
+		return "", repository.ErrNotFound
+	}
+	return embedding.SourceHash, nil
+}
+
+func (r *memoryEmbeddings) SaveEmbedding(ctx context.Context, embedding models.TaskEmbedding) error {
+	r.vectors[embedding.TaskID] = embedding
+	return nil
+}
+
+func (r *memoryEmbeddings) DeleteEmbedding(ctx context.Context, taskID string) error {
+	delete(r.vectors, taskID)
+	return nil
+}
+
+func (r *memoryEmbeddings) GetTasksWithoutEmbedding(ctx context.Context, model string, limit int) ([]models.Task, error) {
+	return r.missing, nil
+}
+
+func (r *memoryEmbeddings) FindSimilarTasks(ctx context.Context, taskID, model string, minScore float64, limit int) ([]models.SimilarTask, error) {
+	return r.similar, nil
+}
+
+// fakeEmbedder implements domainService.TextEmbedder
+type fakeEmbedder struct {
+	texts []string
+	err   error
+}
+
+func (e *fakeEmbedder) Embed(ctx context.Context, text string) ([]float32, error) {
+	e.texts = append(e.texts, text)
+	return []float32{1, 0}, e.err
+}
+
+func (e *fakeEmbedder) Name() string {
+	return "test-embedding"
+}
+
+func TestSimilarTasks(t *testing.T) {
+	mockRepo = new(MockTaskRepository)
+	mockLogger = new(MockLogger)
+	mockLogger.On("Warn", mock.Anything, mock.Anything).Return()
+	tasks := NewTaskService(mockRepo, nil, nil, nil, nil, nil, nil, mockLogger)
+	embedder := &fakeEmbedder{}
+	embeddings := &memoryEmbeddings{
+		vectors: map[string]models.TaskEmbedding{},
+		similar: []models.SimilarTask{{Task: models.Task{ID: "task2", Title: "Pay the rent"}, Score: 0.93}},
+	}
+	service := NewSimilarityService(embeddings, tasks, embedder, 0.75, mockLogger)
+	ctx := context.Background()
+
+	task := models.Task{ID: "task1", UserID: "user1", Title: "Pay rent", Description: "Before friday"}
+	mockRepo.On("GetByID", mock.Anything, "task1").Return(&task, nil)
+	mockRepo.On("GetByID", mock.Anything, "private").Return(&models.Task{ID: "private", UserID: "user1", Private: true}, nil)
+
+	// событие строит вектор, повторный запрос без изменений текста модель не вызывает
+	require.NoError(t, service.HandleEvent(ctx, models.TaskEvent{Type: models.EventTaskCreated, Task: task}))
+	similar, err := service.Similar(ctx, "user1", "task1", DefaultSimilarLimit)
+	require.NoError(t, err)
+	require.Len(t, similar, 1)
+	assert.Equal(t, "task2", similar[0].Task.ID)
+	assert.Equal(t, []string{"Pay rent\nBefore friday"}, embedder.texts)
+
+	_, err = service.Similar(ctx, "user2", "task1", DefaultSimilarLimit)
+	assert.Equal(t, ErrAccessDenied, err)
+
+	// текст приватной задачи не отправляется модели, ставшая приватной задача убирается из поиска
+	_, err = service.Similar(ctx, "user1", "private", DefaultSimilarLimit)
+	assert.Equal(t, ErrAIPrivateTask, err)
+	task.Private = true
+	require.NoError(t, service.HandleEvent(ctx, models.TaskEvent{Type: models.EventTaskUpdated, Task: task}))
+	assert.Empty(t, embeddings.vectors)
+	assert.Len(t, embedder.texts, 1)
+
+	disabled := NewSimilarityService(embeddings, tasks, nil, 0.75, mockLogger)
+	_, err = disabled.Similar(ctx, "user1", "task1", DefaultSimilarLimit)
+	assert.Equal(t, ErrSimilarityDisabled, err)
+}
+
+func TestSimilarityBackfill(t *testing.T) {
+	mockLogger = new(MockLogger)
+	mockLogger.On("Warn", mock.Anything, mock.Anything).Return()
+	embedder := &fakeEmbedder{}
+	embeddings := &memoryEmbeddings{
+		vectors: map[string]models.TaskEmbedding{},
+		missing: []models.Task{{ID: "a", UserID: "user1", Title: "A"}, {ID: "b", UserID: "user1", Title: "B"}},
+	}
+	service := NewSimilarityService(embeddings, nil, embedder, 0.75, mockLogger)
+
+	require.NoError(t, service.Backfill(context.Background()))
+	assert.Len(t, embeddings.vectors, 2)
+	assert.Equal(t, "test-embedding", embeddings.vectors["a"].Model)
+
+	// недоступная модель прерывает запуск на первой задаче
+	embeddings.vectors = map[string]models.TaskEmbedding{}
+	embedder.err = context.DeadlineExceeded
+	assert.Equal(t, ErrAITimeout, service.Backfill(context.Background()))
+	assert.Len(t, embedder.texts, 3)
+}
@@ -0,0 +1,92 @@
+package service
+
+import (
+	"context"
+
+	"github.com/jmoloko/taskmange/internal/domain/models"
+)
+
+// maxSubtaskDepth наибольшая глубина вложенности, на которую можно поместить задачу
+const maxSubtaskDepth = 50
+
+// GetSubtasks прямые подзадачи задачи пользователя. Приватные подзадачи отдаются без расшифровки
+func (s *TaskServiceImpl) GetSubtasks(ctx context.Context, userID, taskID string) ([]models.Task, error) {
+	if err := s.checkOwner(ctx, userID, taskID); err != nil {
+		return nil, err
+	}
+
+	subtasks, err := s.repo.GetSubtasks(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	if subtasks == nil {
+		subtasks = []models.Task{}
+	}
+
+	return markOverdue(lockTasks(subtasks), s.clock.Now()), nil
+}
+
+// setParent переносит задачу под parentID, пустой parentID делает ее задачей верхнего уровня
+func (s *TaskServiceImpl) setParent(ctx context.Context, userID string, task *models.Task, parentID string) error {
+	if parentID == "" {
+		task.ParentID = nil
+		return nil
+	}
+	if task.ParentID != nil && *task.ParentID == parentID {
+		return nil
+	}
+
+	if err := s.checkParent(ctx, userID, task.ID, parentID); err != nil {
+		return err
+	}
+	task.ParentID = &parentID
+
+	return nil
+}
+
+// checkParent проверяет, что parentID — задача пользователя, которая не совпадает с taskID
+// и не является ее подзадачей: цепочка предков родителя не должна содержать саму задачу
+func (s *TaskServiceImpl) checkParent(ctx context.Context, userID, taskID, parentID string) error {
+	if parentID == taskID {
+		return ErrSubtaskCycle
+	}
+
+	parent, err := s.repo.GetByID(ctx, parentID)
+	if err != nil || parent.UserID != userID {
+		return ErrInvalidParent
+	}
+
+	ancestors, err := s.repo.GetAncestorIDs(ctx, parentID)
+	if err != nil {
+		return err
+	}
+	if len(ancestors)+1 >= maxSubtaskDepth {
+		return ErrSubtasksTooDeep
+	}
+	for _, id := range ancestors {
+		if id == taskID {
+			return ErrSubtaskCycle
+		}
+	}
+
+	return nil
+}
+
+// completeSubtasks вызывается перед выполнением задачи: без cascade открытые подзадачи любого уровня
+// запрещают ее выполнить, с cascade выполняются первыми одним пакетом
+func (s *TaskServiceImpl) completeSubtasks(ctx context.Context, userID, taskID string, cascade bool) error {
+	open, err := s.repo.GetOpenDescendantIDs(ctx, []string{taskID})
+	if err != nil {
+		return err
+	}
+	if len(open) == 0 {
+		return nil
+	}
+	if !cascade {
+		return ErrOpenSubtasks
+	}
+
+	_, err = s.CompleteTasks(ctx, userID, open)
+	return err
+}
@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetParent(t *testing.T) {
+	mockRepo = new(MockTaskRepository)
+	mockLogger = new(MockLogger)
+	mockLogger.On("Info", mock.Anything, mock.Anything).Return()
+	service := NewTaskService(mockRepo, nil, nil, nil, nil, nil, nil, mockLogger)
+	ctx := context.Background()
+
+	// a -> b -> c: перенос a под c замкнул бы цепочку
+	mockRepo.On("GetByID", mock.Anything, "a").Return(&models.Task{ID: "a", UserID: "user1", Status: models.StatusPending}, nil)
+	mockRepo.On("GetByID", mock.Anything, "c").Return(&models.Task{ID: "c", UserID: "user1"}, nil)
+	mockRepo.On("GetByID", mock.Anything, "foreign").Return(&models.Task{ID: "foreign", UserID: "user2"}, nil)
+	mockRepo.On("GetAncestorIDs", mock.Anything, "c").Return([]string{"b", "a"}, nil)
+
+	parent := "a"
+	_, err := service.PatchUserTask(ctx, "user1", "a", models.UpdateTaskRequest{ParentID: &parent})
+	assert.Equal(t, ErrSubtaskCycle, err)
+
+	parent = "c"
+	_, err = service.PatchUserTask(ctx, "user1", "a", models.UpdateTaskRequest{ParentID: &parent})
+	assert.Equal(t, ErrSubtaskCycle, err)
+
+	parent = "foreign"
+	_, err = service.PatchUserTask(ctx, "user1", "a", models.UpdateTaskRequest{ParentID: &parent})
+	assert.Equal(t, ErrInvalidParent, err)
+
+	// пустой parent_id отвязывает задачу без проверок
+	parent = ""
+	mockRepo.On("Update", mock.Anything, mock.MatchedBy(func(task *models.Task) bool {
+		return task.ID == "a" && task.ParentID == nil
+	})).Return(nil).Once()
+	_, err = service.PatchUserTask(ctx, "user1", "a", models.UpdateTaskRequest{ParentID: &parent})
+	require.NoError(t, err)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCompleteWithSubtasks(t *testing.T) {
+	mockRepo = new(MockTaskRepository)
+	mockLogger = new(MockLogger)
+	mockLogger.On("Info", mock.Anything, mock.Anything).Return()
+	service := NewTaskService(mockRepo, nil, nil, nil, nil, nil, nil, mockLogger)
+	ctx := context.Background()
+
+	// modify меняет полученную задачу, поэтому на каждый вызов своя копия
+	for i := 0; i < 2; i++ {
+		mockRepo.On("GetByID", mock.Anything, "parent").Return(&models.Task{ID: "parent", UserID: "user1", Status: models.StatusPending}, nil).Once()
+	}
+	mockRepo.On("GetOpenDescendantIDs", mock.Anything, []string{"parent"}).Return([]string{"child", "grandchild"}, nil)
+
+	done := models.StatusDone
+	_, err := service.PatchUserTask(ctx, "user1", "parent", models.UpdateTaskRequest{Status: &done})
+	assert.Equal(t, ErrOpenSubtasks, err)
+
+	// с complete_subtasks подзадачи выполняются одним пакетом до родителя
+	mockRepo.On("GetOpenDescendantIDs", mock.Anything, []string{"child", "grandchild"}).Return([]string{"grandchild"}, nil).Once()
+	mockRepo.On("CompleteTasks", mock.Anything, "user1", []string{"child", "grandchild"}).
+		Return([]models.Task{{ID: "child", UserID: "user1"}, {ID: "grandchild", UserID: "user1"}}, nil).Once()
+	mockRepo.On("Update", mock.Anything, mock.MatchedBy(func(task *models.Task) bool {
+		return task.ID == "parent" && task.Status == models.StatusDone
+	})).Return(nil).Once()
+
+	task, err := service.PatchUserTask(ctx, "user1", "parent", models.UpdateTaskRequest{Status: &done, CompleteSubtasks: true})
+	require.NoError(t, err)
+	assert.Equal(t, models.StatusDone, task.Status)
+
+	mockRepo.AssertExpectations(t)
+}
@@ -0,0 +1,191 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/jmoloko/taskmange/internal/domain/repository"
+	"github.com/jmoloko/taskmange/internal/logger"
+	"github.com/jmoloko/taskmange/internal/tagging"
+)
+
+// максимальное число правил автотегирования у одного пользователя
+const maxTaggingRulesPerUser = 100
+
+var (
+	ErrInvalidTaggingRule  = errors.New("invalid tagging rule")
+	ErrTaggingRuleNotFound = errors.New("tagging rule not found")
+	ErrTooManyTaggingRules = errors.New("too many tagging rules")
+)
+
+// TaggingService правила автотегирования: по заголовку и описанию задачи при ее создании
+// и изменении добавляются теги. Реализует domainService.TaskTagger
+type TaggingService struct {
+	repo   repository.TaggingRuleRepository
+	logger logger.Logger
+}
+
+// NewTaggingService создает новый экземпляр TaggingService
+func NewTaggingService(repo repository.TaggingRuleRepository, logger logger.Logger) *TaggingService {
+	return &TaggingService{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+// создание правила
+func (s *TaggingService) Create(ctx context.Context, userID string, req models.TaggingRuleRequest) (*models.TaggingRule, error) {
+	compiled, err := newTaggingRule(userID, req)
+	if err != nil {
+		return nil, err
+	}
+	rule := &compiled.TaggingRule
+
+	existing, err := s.repo.GetTaggingRules(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) >= maxTaggingRulesPerUser {
+		return nil, ErrTooManyTaggingRules
+	}
+
+	if err := s.repo.CreateTaggingRule(ctx, rule); err != nil {
+		return nil, err
+	}
+
+	return rule, nil
+}
+
+// список правил пользователя
+func (s *TaggingService) List(ctx context.Context, userID string) ([]models.TaggingRule, error) {
+	rules, err := s.repo.GetTaggingRules(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if rules == nil {
+		rules = []models.TaggingRule{}
+	}
+
+	return rules, nil
+}
+
+// удаление правила пользователя
+func (s *TaggingService) Delete(ctx context.Context, userID, ruleID string) error {
+	if err := s.repo.DeleteTaggingRule(ctx, userID, ruleID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrTaggingRuleNotFound
+		}
+		return err
+	}
+
+	return nil
+}
+
+// Test пробное применение правил к тексту задачи без ее создания: сохраненных включенных правил
+// или, если в запросе есть rule, только этого правила, чтобы проверить шаблон до сохранения
+func (s *TaggingService) Test(ctx context.Context, userID string, req models.TaggingTestRequest) (models.TaggingTestResult, error) {
+	var rules []*tagging.Rule
+	if req.Rule != nil {
+		rule, err := newTaggingRule(userID, *req.Rule)
+		if err != nil {
+			return models.TaggingTestResult{}, err
+		}
+		rules = append(rules, rule)
+	} else {
+		var err error
+		if rules, err = s.enabledRules(ctx, userID); err != nil {
+			return models.TaggingTestResult{}, err
+		}
+	}
+
+	tags, matched := tagging.Apply(rules, req.Title, req.Description)
+	if tags == nil {
+		tags = []string{}
+	}
+	if matched == nil {
+		matched = []models.TaggingRule{}
+	}
+
+	return models.TaggingTestResult{Tags: tags, Matched: matched}, nil
+}
+
+// AutoTags теги включенных правил пользователя, подходящих под текст задачи
+func (s *TaggingService) AutoTags(ctx context.Context, userID, title, description string) ([]string, error) {
+	rules, err := s.enabledRules(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	tags, _ := tagging.Apply(rules, title, description)
+	return tags, nil
+}
+
+// enabledRules включенные правила пользователя; правило, которое перестало разбираться, пропускается
+func (s *TaggingService) enabledRules(ctx context.Context, userID string) ([]*tagging.Rule, error) {
+	stored, err := s.repo.GetTaggingRules(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	rules := make([]*tagging.Rule, 0, len(stored))
+	for _, r := range stored {
+		if !r.Enabled {
+			continue
+		}
+		rule, err := tagging.Compile(r)
+		if err != nil {
+			s.logger.Warn("Skipping invalid tagging rule", map[string]interface{}{
+				"rule_id": r.ID,
+				"error":   err.Error(),
+			})
+			continue
+		}
+		rules = append(rules, rule)
+	}
+
+	return rules, nil
+}
+
+// newTaggingRule проверяет запрос и собирает из него разобранное правило
+func newTaggingRule(userID string, req models.TaggingRuleRequest) (*tagging.Rule, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" || len(name) > 100 {
+		return nil, fmt.Errorf("%w: name must be 1-100 characters", ErrInvalidTaggingRule)
+	}
+
+	tags, err := normalizeTags(req.Tags)
+	if err != nil || len(tags) == 0 {
+		return nil, fmt.Errorf("%w: tags must be 1-50 characters, from 1 to %d per rule", ErrInvalidTaggingRule, maxTaskTags)
+	}
+
+	field := req.Field
+	if field == "" {
+		field = models.TaggingFieldAny
+	}
+
+	enabled := true
+	if req.Enabled != nil {
+		enabled = *req.Enabled
+	}
+
+	rule, err := tagging.Compile(models.TaggingRule{
+		ID:      uuid.New().String(),
+		UserID:  userID,
+		Name:    name,
+		Match:   req.Match,
+		Field:   field,
+		Pattern: req.Pattern,
+		Tags:    tags,
+		Enabled: enabled,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidTaggingRule, err)
+	}
+
+	return rule, nil
+}
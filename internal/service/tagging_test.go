@@ -0,0 +1,123 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/jmoloko/taskmange/internal/domain/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryTaggingRules implements repository.TaggingRuleRepository
+type memoryTaggingRules struct {
+	rules []models.TaggingRule
+}
+
+func (r *memoryTaggingRules) CreateTaggingRule(ctx context.Context, rule *models.TaggingRule) error {
+	r.rules = append(r.rules, *rule)
+	return nil
+}
+
+func (r *memoryTaggingRules) DeleteTaggingRule(ctx context.Context, userID, ruleID string) error {
+	for i, rule := range r.rules {
+		if rule.ID == ruleID && rule.UserID == userID {
+			r.rules = append(r.rules[:i], r.rules[i+1:]...)
+			return nil
+		}
+	}
+	return repository.ErrNotFound
+}
+
+func (r *memoryTaggingRules) GetTaggingRules(ctx context.Context, userID string) ([]models.TaggingRule, error) {
+	var rules []models.TaggingRule
+	for _, rule := range r.rules {
+		if rule.UserID == userID {
+			rules = append(rules, rule)
+		}
+	}
+	return rules, nil
+}
+
+func TestTaggingRules(t *testing.T) {
+	service := NewTaggingService(&memoryTaggingRules{}, new(MockLogger))
+	ctx := context.Background()
+
+	_, err := service.Create(ctx, "user1", models.TaggingRuleRequest{Name: "Bad", Match: models.TaggingMatchRegex, Pattern: "(", Tags: []string{"x"}})
+	assert.ErrorIs(t, err, ErrInvalidTaggingRule)
+	_, err = service.Create(ctx, "user1", models.TaggingRuleRequest{Name: "No tags", Match: models.TaggingMatchKeyword, Pattern: "x"})
+	assert.ErrorIs(t, err, ErrInvalidTaggingRule)
+
+	rule, err := service.Create(ctx, "user1", models.TaggingRuleRequest{
+		Name: "Invoices", Match: models.TaggingMatchKeyword, Pattern: "invoice, bill", Tags: []string{" Finance "},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, models.TaggingFieldAny, rule.Field)
+	assert.Equal(t, []string{"finance"}, rule.Tags)
+
+	disabled := false
+	_, err = service.Create(ctx, "user1", models.TaggingRuleRequest{
+		Name: "Off", Match: models.TaggingMatchKeyword, Pattern: "invoice", Tags: []string{"off"}, Enabled: &disabled,
+	})
+	require.NoError(t, err)
+
+	// пробный прогон сохраненных правил: выключенное правило не срабатывает
+	result, err := service.Test(ctx, "user1", models.TaggingTestRequest{Title: "Pay invoice"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"finance"}, result.Tags)
+	require.Len(t, result.Matched, 1)
+	assert.Equal(t, rule.ID, result.Matched[0].ID)
+
+	// черновик правила проверяется без сохранения
+	result, err = service.Test(ctx, "user1", models.TaggingTestRequest{
+		Title: "Pay invoice",
+		Rule:  &models.TaggingRuleRequest{Name: "Draft", Match: models.TaggingMatchRegex, Pattern: "^Pay", Tags: []string{"payments"}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"payments"}, result.Tags)
+	rules, err := service.List(ctx, "user1")
+	require.NoError(t, err)
+	assert.Len(t, rules, 2)
+
+	tags, err := service.AutoTags(ctx, "user2", "Pay invoice", "")
+	require.NoError(t, err)
+	assert.Empty(t, tags)
+
+	assert.Equal(t, ErrTaggingRuleNotFound, service.Delete(ctx, "user2", rule.ID))
+	require.NoError(t, service.Delete(ctx, "user1", rule.ID))
+}
+
+func TestCreate_AutoTags(t *testing.T) {
+	mockRepo = new(MockTaskRepository)
+	mockLogger = new(MockLogger)
+	mockCache = new(MockCache)
+	tagger := NewTaggingService(&memoryTaggingRules{rules: []models.TaggingRule{{
+		ID: "rule1", UserID: "user1", Name: "Invoices", Match: models.TaggingMatchKeyword,
+		Field: models.TaggingFieldAny, Pattern: "invoice", Tags: []string{"finance"}, Enabled: true,
+	}}}, mockLogger)
+	service := NewTaskService(mockRepo, mockCache, nil, nil, nil, nil, tagger, mockLogger)
+	mockLogger.On("Info", mock.Anything, mock.Anything).Return()
+	mockLogger.On("Info", mock.Anything).Return()
+
+	// ручные теги сохраняются, теги правил добавляются без повторов
+	mockRepo.On("Create", mock.Anything, mock.MatchedBy(func(task *models.Task) bool {
+		return assert.ObjectsAreEqual([]string{"urgent", "finance"}, task.Tags)
+	})).Return(nil).Once()
+	created, err := service.CreateTask(context.Background(), "user1", models.Task{Title: "Pay Invoice", Tags: []string{"Urgent", "FINANCE"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"urgent", "finance"}, created.Tags)
+
+	mockRepo.On("Create", mock.Anything, mock.MatchedBy(func(task *models.Task) bool {
+		return assert.ObjectsAreEqual([]string{"finance"}, task.Tags)
+	})).Return(nil).Once()
+	created, err = service.CreateTask(context.Background(), "user1", models.Task{Title: "Invoice for March"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"finance"}, created.Tags)
+
+	_, err = service.CreateTask(context.Background(), "user1", models.Task{Title: "Tagged", Tags: []string{" "}})
+	assert.Equal(t, ErrInvalidTag, err)
+
+	mockRepo.AssertExpectations(t)
+}
@@ -0,0 +1,59 @@
+package service
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// максимальное число тегов у задачи
+	maxTaskTags = 20
+	// максимальная длина тега в символах
+	maxTagLength = 50
+)
+
+// normalizeTags приводит теги к нижнему регистру, обрезает пробелы и убирает повторы.
+// nil остается nil, чтобы при обновлении отличать «не менять теги» от «удалить все»
+func normalizeTags(tags []string) ([]string, error) {
+	if tags == nil {
+		return nil, nil
+	}
+	if len(tags) > maxTaskTags {
+		return nil, ErrInvalidTag
+	}
+
+	normalized := make([]string, 0, len(tags))
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || utf8.RuneCountInString(tag) > maxTagLength || strings.Contains(tag, ",") {
+			return nil, ErrInvalidTag
+		}
+		if !seen[tag] {
+			seen[tag] = true
+			normalized = append(normalized, tag)
+		}
+	}
+
+	return normalized, nil
+}
+
+// mergeTags добавляет к тегам задачи недостающие из extra. Теги сверх maxTaskTags отбрасываются
+func mergeTags(tags, extra []string) []string {
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		seen[tag] = true
+	}
+
+	for _, tag := range extra {
+		if len(tags) >= maxTaskTags {
+			break
+		}
+		if !seen[tag] {
+			seen[tag] = true
+			tags = append(tags, tag)
+		}
+	}
+
+	return tags
+}
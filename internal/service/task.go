@@ -6,11 +6,13 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jmoloko/taskmange/internal/clock"
 	"github.com/jmoloko/taskmange/internal/domain/models"
 	"github.com/jmoloko/taskmange/internal/domain/repository"
 	domainService "github.com/jmoloko/taskmange/internal/domain/service"
 	"github.com/jmoloko/taskmange/internal/logger"
 	"github.com/jmoloko/taskmange/internal/metrics"
+	"github.com/jmoloko/taskmange/internal/tracing"
 )
 
 var (
@@ -20,27 +22,152 @@ var (
 	ErrInvalidTaskData = errors.New("invalid task data")
 	// ErrAccessDenied возвращается при попытке доступа к чужой задаче
 	ErrAccessDenied = errors.New("access denied")
+	// ErrPrivateTasksDisabled возвращается, если шифрование приватных задач не настроено
+	ErrPrivateTasksDisabled = errors.New("private tasks are disabled")
+	// ErrInvalidLink возвращается при некорректной ссылке задачи
+	ErrInvalidLink = errors.New("invalid task link")
+	// ErrInvalidTag возвращается при пустом, слишком длинном теге или слишком большом числе тегов
+	ErrInvalidTag = errors.New("invalid task tag")
+	// ErrSelfRelation возвращается при попытке связать задачу саму с собой
+	ErrSelfRelation = errors.New("task cannot be related to itself")
+	// ErrRelationNotFound возвращается, если задачи не связаны
+	ErrRelationNotFound = errors.New("task relation not found")
+	// ErrInvalidParent возвращается, если родительской задачи нет или она чужая
+	ErrInvalidParent = errors.New("invalid parent task")
+	// ErrSubtaskCycle возвращается при попытке сделать задачу подзадачей самой себя или своей подзадачи
+	ErrSubtaskCycle = errors.New("task cannot be a subtask of itself or of its subtask")
+	// ErrSubtasksTooDeep возвращается, если родительская задача вложена слишком глубоко
+	ErrSubtasksTooDeep = errors.New("subtasks are nested too deep")
+	// ErrOpenSubtasks возвращается при выполнении задачи, у которой есть невыполненные подзадачи
+	ErrOpenSubtasks = errors.New("task has open subtasks")
+	// ErrInvalidProject возвращается, если проекта задачи нет или он чужой
+	ErrInvalidProject = errors.New("invalid task project")
+	// ErrInvalidAssignee возвращается при назначении задачи ее владельцу или пользователю с некорректным ID
+	ErrInvalidAssignee = errors.New("invalid task assignee")
+	// ErrPrivateTaskAssignment возвращается при назначении приватной задачи: исполнитель не сможет ее расшифровать
+	ErrPrivateTaskAssignment = errors.New("private tasks cannot be assigned")
+	// ErrInvalidAnalyticsRange возвращается для пустого окна аналитики или окна длиннее maxAnalyticsRange
+	ErrInvalidAnalyticsRange = errors.New("invalid analytics range")
+)
+
+// возраст кэшированной аналитики, после которого попадание считается устаревшим (stale)
+const analyticsStaleAfter = time.Hour
+
+const (
+	// наибольшее произвольное окно аналитики — год
+	maxAnalyticsRange = 366 * 24 * time.Hour
+	// период аналитики за произвольное окно
+	customAnalyticsPeriod = "custom"
+	// формат границы произвольного окна в ключе кэша
+	analyticsRangeLayout = "20060102T150405Z"
+)
+
+const (
+	// размер пачки массового удаления: одна пачка — один запрос к БД
+	bulkDeleteBatch = 500
+	// пауза между пачками массового удаления, чтобы оно не вытесняло запросы других пользователей
+	bulkDeletePause = 50 * time.Millisecond
 )
 
 // TaskServiceImpl реализует интерфейс domainService.TaskService
 type TaskServiceImpl struct {
-	repo   repository.TaskRepository
-	cache  repository.AnalyticsCache
-	logger logger.Logger
+	repo      repository.TaskRepository
+	cache     repository.AnalyticsCache
+	tasks     repository.TaskCache
+	encryptor domainService.TaskEncryptor
+	events    domainService.EventPublisher
+	quota     *QuotaService
+	tagger    domainService.TaskTagger
+	clock     clock.Clock
+	logger    logger.Logger
+
+	deleteBatch int
+	deletePause time.Duration
 }
 
-// NewTaskService создает новый экземпляр TaskServiceImpl
-func NewTaskService(repo repository.TaskRepository, cache repository.AnalyticsCache, logger logger.Logger) domainService.TaskService {
+// NewTaskService создает новый экземпляр TaskServiceImpl.
+// encryptor может быть nil, тогда создание приватных задач запрещено.
+// events может быть nil, тогда события задач не публикуются.
+// tasks может быть nil, тогда задачи и списки задач всегда читаются из БД.
+// quota может быть nil, тогда лимит открытых задач не действует.
+// tagger может быть nil, тогда правила автотегирования не применяются
+func NewTaskService(repo repository.TaskRepository, cache repository.AnalyticsCache, encryptor domainService.TaskEncryptor, events domainService.EventPublisher, tasks repository.TaskCache, quota *QuotaService, tagger domainService.TaskTagger, logger logger.Logger) domainService.TaskService {
 	return &TaskServiceImpl{
-		repo:   repo,
-		cache:  cache,
-		logger: logger,
+		repo:      repo,
+		cache:     cache,
+		tasks:     tasks,
+		encryptor: encryptor,
+		events:    events,
+		quota:     quota,
+		tagger:    tagger,
+		clock:     clock.System,
+		logger:    logger,
+
+		deleteBatch: bulkDeleteBatch,
+		deletePause: bulkDeletePause,
+	}
+}
+
+// log логгер запроса с request_id, вне запроса — логгер сервиса
+func (s *TaskServiceImpl) log(ctx context.Context) logger.Logger {
+	return logger.FromContext(ctx, s.logger)
+}
+
+// publish отправляет событие задачи в конвейер; приватные задачи публикуются заблокированными
+func (s *TaskServiceImpl) publish(ctx context.Context, eventType models.EventType, task models.Task) {
+	s.emit(ctx, models.TaskEvent{
+		Type:   eventType,
+		UserID: task.UserID,
+		Task:   lockTask(task),
+	})
+}
+
+// publishUpdate отправляет событие task.updated со списком измененных полей
+func (s *TaskServiceImpl) publishUpdate(ctx context.Context, task models.Task, changes []models.FieldChange) {
+	s.emit(ctx, models.TaskEvent{
+		Type:    models.EventTaskUpdated,
+		UserID:  task.UserID,
+		Task:    lockTask(task),
+		Changes: changes,
+	})
+}
+
+// emit единственная точка, через которую события сервиса уходят в конвейер. Побочные эффекты
+// изменений (триггеры, уведомления, поток событий) подписываются на конвейер, а не вызываются отсюда
+func (s *TaskServiceImpl) emit(ctx context.Context, event models.TaskEvent) {
+	if s.events == nil {
+		return
+	}
+
+	event.OccurredAt = s.clock.Now()
+	if event.Task.ID != "" {
+		event.Task.IsOverdue = event.Task.Overdue(event.OccurredAt)
 	}
+	markOverdue(event.Tasks, event.OccurredAt)
+	s.events.Publish(ctx, event)
+}
+
+// markOverdue заполняет IsOverdue задач на момент now. Признак не хранится в БД и кэше,
+// поэтому сервисы отмечают его по своим часам при каждой отдаче задач
+func markOverdue(tasks []models.Task, now time.Time) []models.Task {
+	for i := range tasks {
+		tasks[i].IsOverdue = tasks[i].Overdue(now)
+	}
+	return tasks
+}
+
+// withOverdue отмечает просрочку задачи по часам сервиса
+func (s *TaskServiceImpl) withOverdue(task models.Task) models.Task {
+	task.IsOverdue = task.Overdue(s.clock.Now())
+	return task
 }
 
 // Create создает новую задачу
 func (s *TaskServiceImpl) Create(ctx context.Context, task models.Task) (models.Task, error) {
-	s.logger.Info("Creating new task", map[string]interface{}{
+	ctx, span := tracing.Start(ctx, "TaskService.Create")
+	defer span.End()
+
+	s.log(ctx).Info("Creating new task", map[string]interface{}{
 		"title":    task.Title,
 		"status":   task.Status,
 		"priority": task.Priority,
@@ -48,142 +175,374 @@ func (s *TaskServiceImpl) Create(ctx context.Context, task models.Task) (models.
 	})
 
 	if task.Title == "" {
-		s.logger.Error("Invalid task data: title is required")
+		s.log(ctx).Error("Invalid task data: title is required")
 		return models.Task{}, ErrInvalidTaskData
 	}
 
 	if task.Status == "" {
-		s.logger.Info("Setting default status: pending")
+		s.log(ctx).Info("Setting default status: pending")
 		task.Status = models.StatusPending
 	}
 
 	if task.Priority == "" {
-		s.logger.Info("Setting default priority: medium")
+		s.log(ctx).Info("Setting default priority: medium")
 		task.Priority = models.PriorityMedium
 	}
 
 	if task.DueDate.IsZero() {
-		tomorrow := time.Now().AddDate(0, 0, 1)
-		s.logger.Info("Setting default due date", map[string]interface{}{
+		tomorrow := s.clock.Now().AddDate(0, 0, 1)
+		s.log(ctx).Info("Setting default due date", map[string]interface{}{
 			"due_date": tomorrow,
 		})
 		task.DueDate = tomorrow
 	}
 
+	if err := validateLinks(task.Links); err != nil {
+		return models.Task{}, err
+	}
+
+	tags, err := normalizeTags(task.Tags)
+	if err != nil {
+		return models.Task{}, err
+	}
+	task.Tags = tags
+	s.autoTag(ctx, &task)
+
+	if task.ParentID != nil && *task.ParentID == "" {
+		task.ParentID = nil
+	}
+	if task.ParentID != nil {
+		if err := s.checkParent(ctx, task.UserID, task.ID, *task.ParentID); err != nil {
+			return models.Task{}, err
+		}
+	}
+
+	// исполнитель назначается только через AssignTask
+	task.AssigneeID = nil
+
+	if task.ProjectID != nil && *task.ProjectID == "" {
+		task.ProjectID = nil
+	}
+	if task.ProjectID != nil {
+		if err := s.checkProject(ctx, task.UserID, *task.ProjectID); err != nil {
+			return models.Task{}, err
+		}
+	}
+
+	if err := prepareRecurrence(&task); err != nil {
+		return models.Task{}, err
+	}
+
+	adding := openCount([]models.Task{task})
+	open, err := s.reserveOpenTasks(ctx, task.UserID, adding)
+	if err != nil {
+		return models.Task{}, err
+	}
+
+	title, description, notes := task.Title, task.Description, task.Notes
+	if task.Private {
+		if err := s.sealTask(&task); err != nil {
+			return models.Task{}, err
+		}
+	}
+
 	if err := s.repo.Create(ctx, &task); err != nil {
-		s.logger.Error("Failed to create task in repository", map[string]interface{}{
+		s.log(ctx).Error("Failed to create task in repository", map[string]interface{}{
 			"error": err.Error(),
 		})
 		return models.Task{}, err
 	}
+	s.invalidateTaskLists(ctx, task.UserID)
 
-	metrics.TasksCreatedTotal.Inc()
+	// владелец только что передал открытые данные, возвращаем их без блокировки
+	task.Title, task.Description, task.Notes = title, description, notes
+
+	metrics.TasksCreatedTotal.WithLabelValues(metrics.Tenant(task.UserID)).Inc()
 	metrics.TasksByStatus.WithLabelValues(string(task.Status)).Inc()
 
-	s.logger.Info("Task created successfully", map[string]interface{}{
+	s.log(ctx).Info("Task created successfully", map[string]interface{}{
 		"task_id": task.ID,
 	})
 
-	return task, nil
+	s.observeOpenTasks(ctx, task.UserID, open, adding)
+	s.publish(ctx, models.EventTaskCreated, task)
+
+	return s.withOverdue(task), nil
 }
 
-// GetByID возвращает задачу по ID
+// GetByID возвращает задачу по ID владельцу или исполнителю
 func (s *TaskServiceImpl) GetByID(ctx context.Context, id, userID string) (models.Task, error) {
-	task, err := s.repo.GetByID(ctx, id)
+	ctx, span := tracing.Start(ctx, "TaskService.GetByID")
+	defer span.End()
+
+	task, err := s.getTask(ctx, id)
 	if err != nil {
 		return models.Task{}, ErrTaskNotFound
 	}
 
-	if task.UserID != userID {
+	if !isMember(task, userID) {
 		return models.Task{}, ErrAccessDenied
 	}
 
-	return *task, nil
+	return s.withOverdue(lockTask(*task)), nil
 }
 
 // GetAll возвращает все задачи с применением фильтров
 func (s *TaskServiceImpl) GetAll(ctx context.Context, userID string, filters models.TaskFilters) ([]models.Task, error) {
-	return s.repo.GetAll(ctx, filters)
+	ctx, span := tracing.Start(ctx, "TaskService.GetAll")
+	defer span.End()
+
+	tasks, err := s.getTaskList(ctx, filters)
+	if err != nil {
+		return nil, err
+	}
+
+	return markOverdue(lockTasks(tasks), s.clock.Now()), nil
 }
 
-// Update обновляет существующую задачу
+// Update обновляет существующую задачу. Пустые поля task оставляют значения без изменений,
+// кроме описания: оно заменяется всегда
 func (s *TaskServiceImpl) Update(ctx context.Context, id, userID string, task models.Task) (models.Task, error) {
-	s.logger.Info("Updating task", map[string]interface{}{
+	ctx, span := tracing.Start(ctx, "TaskService.Update")
+	defer span.End()
+
+	return s.modify(ctx, id, userID, task.Status, false, func(existingTask *models.Task) error {
+		if task.Private {
+			existingTask.Private = true
+		}
+
+		if task.Title != "" {
+			existingTask.Title = task.Title
+		}
+
+		if task.Description != existingTask.Description {
+			existingTask.Description = task.Description
+		}
+
+		if task.Notes != nil {
+			existingTask.Notes = task.Notes
+		}
+
+		if task.Links != nil {
+			if err := validateLinks(task.Links); err != nil {
+				return err
+			}
+			existingTask.Links = task.Links
+		}
+
+		if task.Tags != nil {
+			tags, err := normalizeTags(task.Tags)
+			if err != nil {
+				return err
+			}
+			existingTask.Tags = tags
+		}
+
+		if task.ParentID != nil {
+			if err := s.setParent(ctx, existingTask.UserID, existingTask, *task.ParentID); err != nil {
+				return err
+			}
+		}
+
+		if task.ProjectID != nil {
+			if err := s.setProject(ctx, existingTask.UserID, existingTask, *task.ProjectID); err != nil {
+				return err
+			}
+		}
+
+		if task.Status != "" {
+			existingTask.Status = task.Status
+		}
+
+		if task.Priority != "" {
+			existingTask.Priority = task.Priority
+		}
+
+		if !task.DueDate.IsZero() {
+			existingTask.DueDate = task.DueDate
+		}
+
+		return nil
+	})
+}
+
+// PatchUserTask меняет только переданные в запросе поля задачи
+func (s *TaskServiceImpl) PatchUserTask(ctx context.Context, userID, taskID string, req models.UpdateTaskRequest) (models.Task, error) {
+	var status models.Status
+	if req.Status != nil {
+		status = *req.Status
+	}
+
+	return s.modify(ctx, taskID, userID, status, req.CompleteSubtasks, func(existingTask *models.Task) error {
+		if req.Title != nil {
+			if *req.Title == "" {
+				return ErrInvalidTaskData
+			}
+			existingTask.Title = *req.Title
+		}
+
+		if req.Description != nil {
+			existingTask.Description = *req.Description
+		}
+
+		if req.Notes != nil {
+			existingTask.Notes = req.Notes
+		}
+
+		if req.Links != nil {
+			if err := validateLinks(*req.Links); err != nil {
+				return err
+			}
+			existingTask.Links = *req.Links
+		}
+
+		if req.Tags != nil {
+			tags, err := normalizeTags(*req.Tags)
+			if err != nil {
+				return err
+			}
+			existingTask.Tags = tags
+		}
+
+		if req.ParentID != nil {
+			if err := s.setParent(ctx, existingTask.UserID, existingTask, *req.ParentID); err != nil {
+				return err
+			}
+		}
+
+		if req.ProjectID != nil {
+			if err := s.setProject(ctx, existingTask.UserID, existingTask, *req.ProjectID); err != nil {
+				return err
+			}
+		}
+
+		if req.Status != nil {
+			existingTask.Status = *req.Status
+		}
+
+		if req.Priority != nil {
+			existingTask.Priority = *req.Priority
+		}
+
+		if req.DueDate != nil {
+			existingTask.DueDate = *req.DueDate
+		}
+
+		// снять шифрование с приватной задачи нельзя, как и через PUT
+		if req.Private != nil {
+			if !*req.Private && existingTask.Private {
+				return ErrInvalidTaskData
+			}
+			existingTask.Private = *req.Private
+		}
+
+		return nil
+	})
+}
+
+// modify загружает задачу владельца или исполнителя, расшифровывает приватную, применяет apply и сохраняет.
+// status — статус из запроса, пустой, если клиент его не менял. completeSubtasks разрешает
+// выполнить задачу с открытыми подзадачами, выполнив их вместе с ней
+func (s *TaskServiceImpl) modify(ctx context.Context, id, userID string, status models.Status, completeSubtasks bool, apply func(*models.Task) error) (models.Task, error) {
+	s.log(ctx).Info("Updating task", map[string]interface{}{
 		"task_id": id,
 		"user_id": userID,
 	})
 
 	existingTask, err := s.repo.GetByID(ctx, id)
 	if err != nil {
-		s.logger.Error("Task not found", map[string]interface{}{
+		s.log(ctx).Error("Task not found", map[string]interface{}{
 			"task_id": id,
 			"error":   err.Error(),
 		})
 		return models.Task{}, ErrTaskNotFound
 	}
 
-	if existingTask.UserID != userID {
-		s.logger.Error("Access denied to task", map[string]interface{}{
+	if !isMember(existingTask, userID) {
+		s.log(ctx).Error("Access denied to task", map[string]interface{}{
 			"task_id": id,
 			"user_id": userID,
 		})
 		return models.Task{}, ErrAccessDenied
 	}
 
-	if task.Title != "" {
-		existingTask.Title = task.Title
+	if existingTask.Private {
+		if err := s.openTask(existingTask); err != nil {
+			return models.Task{}, err
+		}
 	}
 
-	if task.Description != existingTask.Description {
-		existingTask.Description = task.Description
-	}
+	// completed_at проставляет триггер БД при переходе задачи в статус done
+	wasCompleted := existingTask.CompletedAt != nil
+	wasDone := existingTask.Status == models.StatusDone
 
-	if task.Status != "" {
-		existingTask.Status = task.Status
+	// копия до изменений для списка измененных полей в событии; списки копируются, apply может менять их на месте
+	before := *existingTask
+	before.Links = append([]models.TaskLink(nil), existingTask.Links...)
+	before.Tags = append([]string(nil), existingTask.Tags...)
 
-		if task.Status == models.StatusDone && (existingTask.CompletedAt == nil || *existingTask.CompletedAt == time.Time{}) {
-			now := time.Now()
-			existingTask.CompletedAt = &now
-			s.logger.Info("Task marked as completed", map[string]interface{}{
-				"task_id":      id,
-				"completed_at": now,
-			})
-		}
+	if err := apply(existingTask); err != nil {
+		return models.Task{}, err
 	}
-
-	if task.Priority != "" {
-		existingTask.Priority = task.Priority
+	if existingTask.Private && existingTask.AssigneeID != nil {
+		return models.Task{}, ErrPrivateTaskAssignment
 	}
+	s.autoTag(ctx, existingTask)
 
-	if !task.DueDate.IsZero() {
-		existingTask.DueDate = task.DueDate
+	if !wasDone && existingTask.Status == models.StatusDone {
+		if err := s.completeSubtasks(ctx, existingTask.UserID, id, completeSubtasks); err != nil {
+			return models.Task{}, err
+		}
 	}
 
-	existingTask.UpdatedAt = time.Now()
+	title, description, notes := existingTask.Title, existingTask.Description, existingTask.Notes
+	if existingTask.Private {
+		if err := s.sealTask(existingTask); err != nil {
+			return models.Task{}, err
+		}
+	}
 
 	if err := s.repo.Update(ctx, existingTask); err != nil {
-		s.logger.Error("Failed to update task", map[string]interface{}{
+		s.log(ctx).Error("Failed to update task", map[string]interface{}{
 			"task_id": id,
 			"error":   err.Error(),
 		})
 		return models.Task{}, err
 	}
+	s.invalidateTask(ctx, existingTask.UserID, id)
+
+	existingTask.Title, existingTask.Description, existingTask.Notes = title, description, notes
 
-	if task.Status == models.StatusDone {
-		metrics.TasksCompletedTotal.Inc()
+	if !wasCompleted && existingTask.CompletedAt != nil {
+		s.log(ctx).Info("Task marked as completed", map[string]interface{}{
+			"task_id":      id,
+			"completed_at": *existingTask.CompletedAt,
+		})
 	}
 
-	metrics.TasksByStatus.WithLabelValues(string(task.Status)).Inc()
+	if status == models.StatusDone {
+		metrics.TasksCompletedTotal.WithLabelValues(metrics.Tenant(userID)).Inc()
+	}
+
+	metrics.TasksByStatus.WithLabelValues(string(status)).Inc()
 
-	s.logger.Info("Task updated successfully", map[string]interface{}{
+	s.log(ctx).Info("Task updated successfully", map[string]interface{}{
 		"task_id": id,
 	})
 
-	return *existingTask, nil
+	s.publishUpdate(ctx, *existingTask, models.DiffTasks(before, *existingTask))
+	if !wasCompleted && existingTask.CompletedAt != nil {
+		s.publish(ctx, models.EventTaskCompleted, *existingTask)
+	}
+
+	return s.withOverdue(*existingTask), nil
 }
 
 // Delete удаляет задачу
 func (s *TaskServiceImpl) Delete(ctx context.Context, taskID, userID string) error {
+	ctx, span := tracing.Start(ctx, "TaskService.Delete")
+	defer span.End()
+
 	// Проверяем существование задачи и права доступа
 	task, err := s.GetByID(ctx, taskID, userID)
 	if err != nil {
@@ -194,40 +553,135 @@ func (s *TaskServiceImpl) Delete(ctx context.Context, taskID, userID string) err
 		return ErrAccessDenied
 	}
 
-	return s.repo.Delete(ctx, taskID)
+	if err := s.repo.Delete(ctx, taskID); err != nil {
+		return err
+	}
+	s.invalidateTask(ctx, task.UserID, taskID)
+
+	s.publish(ctx, models.EventTaskDeleted, task)
+
+	return nil
+}
+
+// PurgeUserTasks удаляет все задачи пользователя. Используется операторами из CLI,
+// события task.deleted не публикуются, чтобы не запускать триггеры на каждую задачу
+func (s *TaskServiceImpl) PurgeUserTasks(ctx context.Context, userID string) (int, error) {
+	ids, err := s.repo.DeleteUserTasks(ctx, userID)
+	if err != nil {
+		s.log(ctx).Error("Failed to purge user tasks", map[string]interface{}{
+			"user_id": userID,
+			"error":   err.Error(),
+		})
+		return 0, err
+	}
+
+	s.invalidateTask(ctx, userID, ids...)
+
+	s.log(ctx).Info("User tasks purged", map[string]interface{}{
+		"user_id": userID,
+		"count":   len(ids),
+	})
+	return len(ids), nil
 }
 
 // Import импортирует список задач
 func (s *TaskServiceImpl) Import(ctx context.Context, userID string, tasks []models.Task) error {
-	for i := range tasks {
-		tasks[i].UserID = userID
-		tasks[i].ID = uuid.New().String()
-		tasks[i].CreatedAt = time.Now()
-		tasks[i].UpdatedAt = time.Now()
+	ctx, span := tracing.Start(ctx, "TaskService.Import")
+	defer span.End()
 
-		if tasks[i].Status == "" {
-			tasks[i].Status = models.StatusPending
+	// проверяем ссылки и теги до записи, чтобы не импортировать файл частично
+	for _, task := range tasks {
+		if err := validateLinks(task.Links); err != nil {
+			return err
 		}
-
-		if tasks[i].Priority == "" {
-			tasks[i].Priority = models.PriorityMedium
+		if _, err := normalizeTags(task.Tags); err != nil {
+			return err
 		}
+	}
 
-		if tasks[i].DueDate.IsZero() {
-			tasks[i].DueDate = time.Now().AddDate(0, 0, 1)
-		}
+	adding := openCount(tasks)
+	open, err := s.reserveOpenTasks(ctx, userID, adding)
+	if err != nil {
+		return err
+	}
 
-		if err := s.repo.Create(ctx, &tasks[i]); err != nil {
+	for i := range tasks {
+		if err := s.prepareImportedTask(userID, &tasks[i]); err != nil {
 			return err
 		}
 	}
 
+	// одна транзакция на весь импорт: при ошибке не остается частично загруженных задач
+	if err := s.repo.CreateBatch(ctx, tasks); err != nil {
+		return err
+	}
+	s.invalidateTaskLists(ctx, userID)
+
+	s.observeOpenTasks(ctx, userID, open, adding)
+
 	return nil
 }
 
+// prepareImportedTask назначает импортируемой задаче владельца, id и значения по умолчанию
+// и шифрует приватную задачу
+func (s *TaskServiceImpl) prepareImportedTask(userID string, task *models.Task) error {
+	task.UserID = userID
+	task.ID = uuid.New().String()
+	// задачи получают новые ID, поэтому ссылки на родителей и серии из файла не переносятся;
+	// проект из файла мог принадлежать другому пользователю
+	task.ParentID, task.ProjectID = nil, nil
+	task.Recurrence, task.RecurrenceID = "", nil
+
+	if task.Status == "" {
+		task.Status = models.StatusPending
+	}
+
+	if task.Priority == "" {
+		task.Priority = models.PriorityMedium
+	}
+
+	if task.DueDate.IsZero() {
+		task.DueDate = s.clock.Now().AddDate(0, 0, 1)
+	}
+
+	tags, err := normalizeTags(task.Tags)
+	if err != nil {
+		return err
+	}
+	task.Tags = tags
+
+	if task.Private {
+		return s.sealTask(task)
+	}
+	return nil
+}
+
+// autoTag добавляет задаче теги по правилам пользователя. Ошибка правил не мешает сохранить задачу.
+// Приватные задачи не размечаются: теги хранятся открыто и раскрывали бы зашифрованный текст
+func (s *TaskServiceImpl) autoTag(ctx context.Context, task *models.Task) {
+	if s.tagger == nil || task.Private {
+		return
+	}
+
+	tags, err := s.tagger.AutoTags(ctx, task.UserID, task.Title, task.Description)
+	if err != nil {
+		s.log(ctx).Error("Failed to apply tagging rules", map[string]interface{}{
+			"user_id": task.UserID,
+			"error":   err.Error(),
+		})
+		return
+	}
+	task.Tags = mergeTags(task.Tags, tags)
+}
+
 // Export экспортирует задачи пользователя
 func (s *TaskServiceImpl) Export(ctx context.Context, userID string) ([]models.Task, error) {
-	return s.repo.GetAll(ctx, models.TaskFilters{UserID: userID})
+	tasks, err := s.repo.GetAll(ctx, models.TaskFilters{UserID: userID})
+	if err != nil {
+		return nil, err
+	}
+
+	return markOverdue(lockTasks(tasks), s.clock.Now()), nil
 }
 
 // GetAnalytics возвращает аналитику по задачам (алиас для GetUserAnalytics)
@@ -246,11 +700,40 @@ func (s *TaskServiceImpl) GetUserTask(ctx context.Context, userID, taskID string
 	return s.GetByID(ctx, taskID, userID)
 }
 
+// UnlockUserTask возвращает приватную задачу с расшифрованными полями
+func (s *TaskServiceImpl) UnlockUserTask(ctx context.Context, userID, taskID string) (models.Task, error) {
+	task, err := s.getTask(ctx, taskID)
+	if err != nil {
+		return models.Task{}, ErrTaskNotFound
+	}
+
+	if task.UserID != userID {
+		return models.Task{}, ErrAccessDenied
+	}
+
+	if task.Private {
+		if err := s.openTask(task); err != nil {
+			s.log(ctx).Error("Failed to decrypt private task", map[string]interface{}{
+				"task_id": taskID,
+				"error":   err.Error(),
+			})
+			return models.Task{}, err
+		}
+	}
+
+	return s.withOverdue(*task), nil
+}
+
 // GetUserTasks возвращает задачи по фильтрам
 func (s *TaskServiceImpl) GetUserTasks(ctx context.Context, userID string, filters models.TaskFilters) ([]models.Task, error) {
 	return s.GetAll(ctx, userID, filters)
 }
 
+// CountUserTasks возвращает число задач по фильтрам, страница списка не учитывается
+func (s *TaskServiceImpl) CountUserTasks(ctx context.Context, userID string, filters models.TaskFilters) (int, error) {
+	return s.repo.Count(ctx, filters)
+}
+
 // UpdateUserTask обновляет существующую задачу
 func (s *TaskServiceImpl) UpdateUserTask(ctx context.Context, userID string, task models.Task) (models.Task, error) {
 	return s.Update(ctx, task.ID, userID, task)
@@ -273,93 +756,179 @@ func (s *TaskServiceImpl) ExportUserTasks(ctx context.Context, userID string) ([
 
 // GetUserAnalytics возвращает аналитику по задачам
 func (s *TaskServiceImpl) GetUserAnalytics(ctx context.Context, userID string, period string) (models.Analytics, error) {
+	ctx, span := tracing.Start(ctx, "TaskService.GetUserAnalytics")
+	defer span.End()
+
+	return s.userAnalytics(ctx, userID, "", period, period, func(now time.Time) (time.Time, time.Time) {
+		return periodStart(period, now), now
+	})
+}
+
+// GetProjectAnalytics аналитика задач проекта за период. Принадлежность проекта пользователю
+// проверяет вызывающий. Кэшируется под ключом пользователя, поэтому сбрасывается вместе с его аналитикой
+func (s *TaskServiceImpl) GetProjectAnalytics(ctx context.Context, userID, projectID, period string) (models.Analytics, error) {
+	ctx, span := tracing.Start(ctx, "TaskService.GetProjectAnalytics")
+	defer span.End()
+
+	return s.userAnalytics(ctx, userID, projectID, "project:"+projectID+":"+period, period, func(now time.Time) (time.Time, time.Time) {
+		return periodStart(period, now), now
+	})
+}
+
+// GetUserAnalyticsRange возвращает аналитику за произвольное окно [from, to), например за спринт.
+// Окно должно быть непустым и не длиннее года, иначе ErrInvalidAnalyticsRange. Аналитика каждого
+// окна кэшируется отдельно, ключ содержит его границы
+func (s *TaskServiceImpl) GetUserAnalyticsRange(ctx context.Context, userID string, from, to time.Time) (models.Analytics, error) {
+	ctx, span := tracing.Start(ctx, "TaskService.GetUserAnalyticsRange")
+	defer span.End()
+
+	if !from.Before(to) || to.Sub(from) > maxAnalyticsRange {
+		return models.Analytics{}, ErrInvalidAnalyticsRange
+	}
+	from, to = from.UTC(), to.UTC()
+
+	key := from.Format(analyticsRangeLayout) + "_" + to.Format(analyticsRangeLayout)
+	return s.userAnalytics(ctx, userID, "", key, customAnalyticsPeriod, func(time.Time) (time.Time, time.Time) {
+		return from, to
+	})
+}
+
+// userAnalytics аналитика из кэша по ключу key, при промахе — из агрегатов хранилища за окно,
+// которое window возвращает для текущего момента. Непустой projectID ограничивает агрегаты
+// задачами проекта. period попадает в ответ и в метрики
+func (s *TaskServiceImpl) userAnalytics(ctx context.Context, userID, projectID, key, period string, window func(now time.Time) (time.Time, time.Time)) (models.Analytics, error) {
 	// Пытаемся получить данные из кэша
-	cachedData, err := s.cache.GetUserAnalytics(ctx, userID, period)
+	lookupStarted := s.clock.Now()
+	cachedData, err := s.cache.GetUserAnalytics(ctx, userID, key)
+	metrics.AnalyticsCacheLookupDuration.Observe(s.clock.Now().Sub(lookupStarted).Seconds())
 	if err != nil {
-		s.logger.Error("Failed to get analytics from cache", map[string]interface{}{
+		metrics.AnalyticsCacheRequestsTotal.WithLabelValues("error").Inc()
+		s.log(ctx).Error("Failed to get analytics from cache", map[string]interface{}{
 			"error":   err.Error(),
 			"user_id": userID,
-			"period":  period,
+			"period":  key,
 		})
 	} else if cachedData != nil {
-		s.logger.Info("Analytics retrieved from cache", map[string]interface{}{
+		if s.clock.Now().Sub(cachedData.CachedAt) > analyticsStaleAfter {
+			metrics.AnalyticsCacheRequestsTotal.WithLabelValues("stale").Inc()
+		} else {
+			metrics.AnalyticsCacheRequestsTotal.WithLabelValues("hit").Inc()
+		}
+		s.log(ctx).Info("Analytics retrieved from cache", map[string]interface{}{
 			"user_id": userID,
-			"period":  period,
+			"period":  key,
 		})
 		return cachedData.Analytics, nil
+	} else {
+		metrics.AnalyticsCacheRequestsTotal.WithLabelValues("miss").Inc()
 	}
 
-	// Если данных в кэше нет или произошла ошибка, вычисляем аналитику
-	filters := models.TaskFilters{
-		UserID: userID,
-	}
-
-	tasks, err := s.repo.GetAll(ctx, filters)
+	// Если данных в кэше нет или произошла ошибка, агрегаты за период считает хранилище
+	now := s.clock.Now()
+	from, to := window(now)
+	stats, err := s.repo.GetTaskStats(ctx, userID, projectID, from, to)
 	if err != nil {
 		return models.Analytics{}, err
 	}
 
-	analytics := models.Analytics{
-		StatusCount:   make(map[models.Status]int),
-		PriorityCount: make(map[models.Priority]int),
-		Period:        period,
-		GeneratedAt:   time.Now(),
+	analytics := analyticsFromStats(stats, period, now)
+	if period == customAnalyticsPeriod {
+		analytics.From, analytics.To = &from, &to
 	}
+	metrics.AnalyticsComputeDuration.WithLabelValues(period).Observe(s.clock.Now().Sub(now).Seconds())
 
-	var completedTasks, overdueTasks, onTimeTasks int
-	var totalCompletionTime float64
+	// Сохраняем результаты в кэш
+	if err := s.cache.SetUserAnalytics(ctx, repository.CachedAnalytics{
+		UserID:    userID,
+		Period:    key,
+		Analytics: analytics,
+		CachedAt:  s.clock.Now(),
+	}); err != nil {
+		s.log(ctx).Error("Failed to cache analytics", map[string]interface{}{
+			"error":   err.Error(),
+			"user_id": userID,
+			"period":  key,
+		})
+	}
 
-	for _, task := range tasks {
-		// Подсчет по статусам
-		analytics.StatusCount[task.Status]++
+	return analytics, nil
+}
 
-		// Подсчет по приоритетам
-		analytics.PriorityCount[task.Priority]++
+// periodStart начало окна аналитики, которое заканчивается в now: сутки, неделя или месяц
+func periodStart(period string, now time.Time) time.Time {
+	switch period {
+	case "day":
+		return now.AddDate(0, 0, -1)
+	case "month":
+		return now.AddDate(0, -1, 0)
+	default:
+		return now.AddDate(0, 0, -7)
+	}
+}
 
-		// Анализ выполненных задач
-		if task.Status == models.StatusDone && task.CompletedAt != nil {
-			completedTasks++
-			completionTime := task.CompletedAt.Sub(task.CreatedAt).Hours()
-			totalCompletionTime += completionTime
+// analyticsFromStats аналитика из агрегатов хранилища на момент now
+func analyticsFromStats(stats models.TaskStats, period string, now time.Time) models.Analytics {
+	analytics := models.Analytics{
+		StatusCount:       stats.StatusCount,
+		PriorityCount:     stats.PriorityCount,
+		AvgCompletionTime: stats.AvgCompletionHours,
+		OverdueTasks:      stats.Overdue,
+		Period:            period,
+		GeneratedAt:       now,
+	}
+	if analytics.StatusCount == nil {
+		analytics.StatusCount = make(map[models.Status]int)
+	}
+	if analytics.PriorityCount == nil {
+		analytics.PriorityCount = make(map[models.Priority]int)
+	}
+	if stats.Completed > 0 {
+		analytics.OnTimeCompletionRate = float64(stats.CompletedOnTime) / float64(stats.Completed) * 100
+	}
 
-			if task.CompletedAt.Before(task.DueDate) {
-				onTimeTasks++
-			}
-		}
+	return analytics
+}
 
-		// Подсчет просроченных задач
-		if task.Status != models.StatusDone && time.Now().After(task.DueDate) {
-			overdueTasks++
-		}
+// GetDashboard возвращает сводные счетчики задач пользователя через COUNT-запросы
+func (s *TaskServiceImpl) GetDashboard(ctx context.Context, userID string) (models.Dashboard, error) {
+	dashboard := models.Dashboard{
+		StatusCount: make(map[models.Status]int),
+		GeneratedAt: s.clock.Now(),
 	}
 
-	// Вычисление среднего времени выполнения
-	if completedTasks > 0 {
-		analytics.AvgCompletionTime = totalCompletionTime / float64(completedTasks)
+	total, err := s.repo.Count(ctx, models.TaskFilters{UserID: userID})
+	if err != nil {
+		return models.Dashboard{}, err
 	}
+	dashboard.Total = total
 
-	// Вычисление процента выполнения в срок
-	if completedTasks > 0 {
-		analytics.OnTimeCompletionRate = float64(onTimeTasks) / float64(completedTasks) * 100
+	for _, status := range []models.Status{models.StatusPending, models.StatusInProgress, models.StatusDone} {
+		count, err := s.repo.Count(ctx, models.TaskFilters{UserID: userID, Status: status})
+		if err != nil {
+			return models.Dashboard{}, err
+		}
+		dashboard.StatusCount[status] = count
 	}
 
-	analytics.OverdueTasks = overdueTasks
+	today := s.clock.Now()
+	dueToday, err := s.repo.Count(ctx, models.TaskFilters{UserID: userID, DueDate: &today})
+	if err != nil {
+		return models.Dashboard{}, err
+	}
+	dashboard.DueToday = dueToday
 
-	// Сохраняем результаты в кэш
-	if err := s.cache.SetUserAnalytics(ctx, repository.CachedAnalytics{
-		UserID:    userID,
-		Period:    period,
-		Analytics: analytics,
-		CachedAt:  time.Now(),
-	}); err != nil {
-		s.logger.Error("Failed to cache analytics", map[string]interface{}{
-			"error":   err.Error(),
-			"user_id": userID,
-			"period":  period,
-		})
+	overdue, err := s.repo.Count(ctx, models.TaskFilters{UserID: userID, Overdue: true})
+	if err != nil {
+		return models.Dashboard{}, err
 	}
+	dashboard.Overdue = overdue
 
-	return analytics, nil
+	return dashboard, nil
+}
+
+// GetUsersWithTasksUpdatedSince возвращает ID пользователей, задачи которых менялись начиная с since
+func (s *TaskServiceImpl) GetUsersWithTasksUpdatedSince(ctx context.Context, since time.Time) ([]string, error) {
+	return s.repo.GetUsersWithTasksUpdatedSince(ctx, since)
 }
 
 // GetActiveUsers возвращает список ID пользователей с активными задачами
@@ -0,0 +1,113 @@
+package service
+
+import (
+	"context"
+
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/jmoloko/taskmange/internal/metrics"
+)
+
+// getTask читает задачу через кэш: при промахе берет из БД и кладет в кэш.
+// Ошибки кэша не мешают чтению, задача тогда берется из БД
+func (s *TaskServiceImpl) getTask(ctx context.Context, taskID string) (*models.Task, error) {
+	if s.tasks == nil {
+		return s.repo.GetByID(ctx, taskID)
+	}
+
+	cached, err := s.tasks.GetTask(ctx, taskID)
+	if err != nil {
+		s.logger.Error("Failed to get task from cache", map[string]interface{}{
+			"task_id": taskID,
+			"error":   err.Error(),
+		})
+	} else if cached != nil {
+		metrics.TaskCacheRequestsTotal.WithLabelValues("hit").Inc()
+		return cached, nil
+	}
+	metrics.TaskCacheRequestsTotal.WithLabelValues("miss").Inc()
+
+	task, err := s.repo.GetByID(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.tasks.SetTask(ctx, *task); err != nil {
+		s.logger.Error("Failed to cache task", map[string]interface{}{
+			"task_id": taskID,
+			"error":   err.Error(),
+		})
+	}
+
+	return task, nil
+}
+
+// getTaskList читает список задач через кэш. Списки назначенных задач не кэшируются:
+// их меняют другие владельцы, а кэш сбрасывается по владельцу задачи. Список просроченных
+// тоже: задача становится просроченной со временем, без изменения, которое сбросило бы кэш.
+// Список из БД сохраняется под поколением, прочитанным до запроса к БД; если кэш недоступен,
+// поколение неизвестно и список не сохраняется
+func (s *TaskServiceImpl) getTaskList(ctx context.Context, filters models.TaskFilters) ([]models.Task, error) {
+	if s.tasks == nil || filters.Assigned || filters.Overdue {
+		return s.repo.GetAll(ctx, filters)
+	}
+
+	cached, generation, found, cacheErr := s.tasks.GetTaskList(ctx, filters)
+	if cacheErr != nil {
+		s.logger.Error("Failed to get task list from cache", map[string]interface{}{
+			"user_id": filters.UserID,
+			"error":   cacheErr.Error(),
+		})
+	} else if found {
+		metrics.TaskListCacheRequestsTotal.WithLabelValues("hit").Inc()
+		return cached, nil
+	}
+	metrics.TaskListCacheRequestsTotal.WithLabelValues("miss").Inc()
+
+	tasks, err := s.repo.GetAll(ctx, filters)
+	if err != nil {
+		return nil, err
+	}
+	if cacheErr != nil {
+		return tasks, nil
+	}
+
+	if err := s.tasks.SetTaskList(ctx, filters, generation, tasks); err != nil {
+		s.logger.Error("Failed to cache task list", map[string]interface{}{
+			"user_id": filters.UserID,
+			"error":   err.Error(),
+		})
+	}
+
+	return tasks, nil
+}
+
+// invalidateTask удаляет задачи и списки задач их владельца из кэша после изменения
+func (s *TaskServiceImpl) invalidateTask(ctx context.Context, userID string, taskIDs ...string) {
+	if s.tasks == nil {
+		return
+	}
+
+	for _, taskID := range taskIDs {
+		if err := s.tasks.InvalidateTask(ctx, taskID); err != nil {
+			s.logger.Error("Failed to invalidate cached task", map[string]interface{}{
+				"task_id": taskID,
+				"error":   err.Error(),
+			})
+		}
+	}
+	s.invalidateTaskLists(ctx, userID)
+}
+
+// invalidateTaskLists удаляет из кэша списки задач пользователя
+func (s *TaskServiceImpl) invalidateTaskLists(ctx context.Context, userID string) {
+	if s.tasks == nil {
+		return
+	}
+
+	if err := s.tasks.InvalidateTaskLists(ctx, userID); err != nil {
+		s.logger.Error("Failed to invalidate cached task lists", map[string]interface{}{
+			"user_id": userID,
+			"error":   err.Error(),
+		})
+	}
+}
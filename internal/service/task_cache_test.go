@@ -0,0 +1,223 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jmoloko/taskmange/internal/clock"
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/jmoloko/taskmange/internal/importer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockTaskCache implements repository.TaskCache
+type MockTaskCache struct {
+	mock.Mock
+}
+
+func (m *MockTaskCache) GetTask(ctx context.Context, taskID string) (*models.Task, error) {
+	args := m.Called(ctx, taskID)
+	if task, ok := args.Get(0).(*models.Task); ok {
+		return task, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockTaskCache) SetTask(ctx context.Context, task models.Task) error {
+	args := m.Called(ctx, task)
+	return args.Error(0)
+}
+
+func (m *MockTaskCache) InvalidateTask(ctx context.Context, taskID string) error {
+	args := m.Called(ctx, taskID)
+	return args.Error(0)
+}
+
+func (m *MockTaskCache) GetTaskList(ctx context.Context, filters models.TaskFilters) ([]models.Task, int64, bool, error) {
+	args := m.Called(ctx, filters)
+	tasks, _ := args.Get(0).([]models.Task)
+	generation, _ := args.Get(1).(int64)
+	return tasks, generation, args.Bool(2), args.Error(3)
+}
+
+func (m *MockTaskCache) SetTaskList(ctx context.Context, filters models.TaskFilters, generation int64, tasks []models.Task) error {
+	args := m.Called(ctx, filters, generation, tasks)
+	return args.Error(0)
+}
+
+func (m *MockTaskCache) InvalidateTaskLists(ctx context.Context, userID string) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
+func TestGetUserTask_Cache(t *testing.T) {
+	mockRepo = new(MockTaskRepository)
+	mockLogger = new(MockLogger)
+	mockCache = new(MockCache)
+	mockTasks := new(MockTaskCache)
+	service := NewTaskService(mockRepo, mockCache, nil, nil, mockTasks, nil, nil, mockLogger).(*TaskServiceImpl)
+	now := time.Date(2024, 3, 10, 9, 0, 0, 0, time.UTC)
+	service.clock = clock.NewFake(now)
+	ctx := context.Background()
+
+	due := now.Add(24 * time.Hour)
+	task := models.Task{ID: "task1", UserID: "user1", Title: "Cached", DueDate: due}
+
+	// промах: задача читается из БД и попадает в кэш
+	mockTasks.On("GetTask", mock.Anything, "task1").Return(nil, nil).Once()
+	mockRepo.On("GetByID", mock.Anything, "task1").Return(&models.Task{ID: "task1", UserID: "user1", Title: "Cached", DueDate: due}, nil).Once()
+	mockTasks.On("SetTask", mock.Anything, task).Return(nil).Once()
+
+	got, err := service.GetUserTask(ctx, "user1", "task1")
+	assert.NoError(t, err)
+	assert.Equal(t, task, got)
+
+	// попадание: БД не читается, владелец все равно проверяется
+	mockTasks.On("GetTask", mock.Anything, "task1").Return(&models.Task{ID: "task1", UserID: "user1", Title: "Cached", DueDate: due}, nil).Twice()
+
+	got, err = service.GetUserTask(ctx, "user1", "task1")
+	assert.NoError(t, err)
+	assert.Equal(t, task, got)
+
+	_, err = service.GetUserTask(ctx, "user2", "task1")
+	assert.Equal(t, ErrAccessDenied, err)
+
+	mockRepo.AssertExpectations(t)
+	mockTasks.AssertExpectations(t)
+}
+
+func TestUpdate_InvalidatesTaskCache(t *testing.T) {
+	mockRepo = new(MockTaskRepository)
+	mockLogger = new(MockLogger)
+	mockCache = new(MockCache)
+	mockTasks := new(MockTaskCache)
+	service := NewTaskService(mockRepo, mockCache, nil, nil, mockTasks, nil, nil, mockLogger)
+
+	mockRepo.On("GetByID", mock.Anything, "task1").Return(&models.Task{ID: "task1", UserID: "user1", Title: "Old"}, nil).Once()
+	mockRepo.On("Update", mock.Anything, mock.AnythingOfType("*models.Task")).Return(nil).Once()
+	mockTasks.On("InvalidateTask", mock.Anything, "task1").Return(nil).Once()
+	mockTasks.On("InvalidateTaskLists", mock.Anything, "user1").Return(nil).Once()
+	mockLogger.On("Info", mock.Anything, mock.Anything).Return()
+
+	_, err := service.UpdateUserTask(context.Background(), "user1", models.Task{ID: "task1", Title: "New"})
+	assert.NoError(t, err)
+
+	mockRepo.AssertExpectations(t)
+	mockTasks.AssertExpectations(t)
+}
+
+func TestGetUserTasks_ListCache(t *testing.T) {
+	mockRepo = new(MockTaskRepository)
+	mockLogger = new(MockLogger)
+	mockTasks := new(MockTaskCache)
+	service := NewTaskService(mockRepo, nil, nil, nil, mockTasks, nil, nil, mockLogger)
+	ctx := context.Background()
+
+	filters := models.TaskFilters{UserID: "user1", Status: models.StatusPending}
+	tasks := []models.Task{{ID: "task1", UserID: "user1", Title: "Cached"}}
+
+	// промах: список читается из БД и попадает в кэш под поколением, прочитанным до запроса к БД
+	mockTasks.On("GetTaskList", mock.Anything, filters).Return(nil, int64(3), false, nil).Once()
+	mockRepo.On("GetAll", mock.Anything, filters).Return(tasks, nil).Once()
+	mockTasks.On("SetTaskList", mock.Anything, filters, int64(3), tasks).Return(nil).Once()
+
+	got, err := service.GetUserTasks(ctx, "user1", filters)
+	assert.NoError(t, err)
+	assert.Equal(t, tasks, got)
+
+	// попадание: БД не читается
+	mockTasks.On("GetTaskList", mock.Anything, filters).Return(tasks, int64(3), true, nil).Once()
+
+	got, err = service.GetUserTasks(ctx, "user1", filters)
+	assert.NoError(t, err)
+	assert.Equal(t, tasks, got)
+
+	// ошибка кэша не мешает чтению из БД; поколение неизвестно, и список не сохраняется
+	mockTasks.On("GetTaskList", mock.Anything, filters).Return(nil, int64(0), false, errors.New("redis down")).Once()
+	mockRepo.On("GetAll", mock.Anything, filters).Return(tasks, nil).Once()
+	mockLogger.On("Error", "Failed to get task list from cache", mock.Anything).Return().Once()
+
+	got, err = service.GetUserTasks(ctx, "user1", filters)
+	assert.NoError(t, err)
+	assert.Equal(t, tasks, got)
+
+	// назначенные задачи меняют другие владельцы, их список всегда читается из БД
+	assigned := models.TaskFilters{UserID: "user1", Assigned: true}
+	mockRepo.On("GetAll", mock.Anything, assigned).Return([]models.Task(nil), nil).Once()
+
+	_, err = service.GetUserTasks(ctx, "user1", assigned)
+	assert.NoError(t, err)
+
+	mockRepo.AssertExpectations(t)
+	mockTasks.AssertExpectations(t)
+}
+
+func TestCreateAndDelete_InvalidateTaskLists(t *testing.T) {
+	mockRepo = new(MockTaskRepository)
+	mockLogger = new(MockLogger)
+	mockTasks := new(MockTaskCache)
+	service := NewTaskService(mockRepo, nil, nil, nil, mockTasks, nil, nil, mockLogger)
+	ctx := context.Background()
+
+	mockRepo.On("Create", mock.Anything, mock.AnythingOfType("*models.Task")).Return(nil).Once()
+	mockTasks.On("InvalidateTaskLists", mock.Anything, "user1").Return(nil).Once()
+	mockLogger.On("Info", mock.Anything, mock.Anything).Return()
+
+	created, err := service.CreateTask(ctx, "user1", models.Task{UserID: "user1", Title: "New", Status: models.StatusPending, Priority: models.PriorityLow})
+	assert.NoError(t, err)
+
+	mockTasks.On("GetTask", mock.Anything, created.ID).Return(&created, nil).Once()
+	mockRepo.On("Delete", mock.Anything, created.ID).Return(nil).Once()
+	mockTasks.On("InvalidateTask", mock.Anything, created.ID).Return(nil).Once()
+	mockTasks.On("InvalidateTaskLists", mock.Anything, "user1").Return(nil).Once()
+
+	assert.NoError(t, service.DeleteUserTask(ctx, "user1", created.ID))
+
+	mockRepo.AssertExpectations(t)
+	mockTasks.AssertExpectations(t)
+}
+
+func TestImportTaskFile_InvalidatesTaskLists(t *testing.T) {
+	mockRepo = new(MockTaskRepository)
+	mockLogger = new(MockLogger)
+	mockTasks := new(MockTaskCache)
+	service := NewTaskService(mockRepo, nil, nil, nil, mockTasks, nil, nil, mockLogger)
+
+	rows, err := importer.NewReader(importer.FormatCSV, strings.NewReader("title\nReport\nDeploy\n"), 0)
+	require.NoError(t, err)
+	mockRepo.On("CreateBatch", mock.Anything, mock.Anything).Return(nil).Once()
+	mockTasks.On("InvalidateTaskLists", mock.Anything, "user1").Return(nil).Once()
+	mockLogger.On("Info", mock.Anything, mock.Anything).Return()
+
+	report, err := service.ImportTaskFile(context.Background(), "user1", rows, false)
+	require.NoError(t, err)
+	assert.Equal(t, 2, report.Imported)
+
+	mockRepo.AssertExpectations(t)
+	mockTasks.AssertExpectations(t)
+}
+
+func TestMaterializeRecurrences_InvalidatesTaskLists(t *testing.T) {
+	mockRepo = new(MockTaskRepository)
+	mockLogger = new(MockLogger)
+	mockTasks := new(MockTaskCache)
+	service := NewTaskService(mockRepo, nil, nil, nil, mockTasks, nil, nil, mockLogger)
+
+	series := "series1"
+	mockRepo.On("GetDueOccurrences", mock.Anything, mock.Anything, recurrenceBatchSize).Return([]models.DueOccurrence{
+		{Task: models.Task{ID: "t1", UserID: "user1", Status: models.StatusDone, DueDate: time.Now().Add(time.Hour), RecurrenceID: &series}, Rule: "FREQ=DAILY", Occurrences: 1},
+	}, nil)
+	mockRepo.On("CreateOccurrence", mock.Anything, "t1", mock.Anything).Return(nil).Once()
+	mockTasks.On("InvalidateTaskLists", mock.Anything, "user1").Return(nil).Once()
+	mockLogger.On("Info", mock.Anything, mock.Anything).Return()
+
+	require.NoError(t, service.MaterializeRecurrences(context.Background()))
+
+	mockRepo.AssertExpectations(t)
+	mockTasks.AssertExpectations(t)
+}
@@ -2,15 +2,25 @@ package service
 
 import (
 	"context"
+	"errors"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/jmoloko/taskmange/internal/clock"
+	"github.com/jmoloko/taskmange/internal/crypto"
 	"github.com/jmoloko/taskmange/internal/domain/models"
 	"github.com/jmoloko/taskmange/internal/domain/repository"
+	"github.com/jmoloko/taskmange/internal/importer"
 	"github.com/jmoloko/taskmange/internal/logger"
+	"github.com/jmoloko/taskmange/internal/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
 	"github.com/redis/go-redis/v9"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 var (
@@ -29,6 +39,11 @@ func (m *MockTaskRepository) Create(ctx context.Context, task *models.Task) erro
 	return args.Error(0)
 }
 
+func (m *MockTaskRepository) CreateBatch(ctx context.Context, tasks []models.Task) error {
+	args := m.Called(ctx, tasks)
+	return args.Error(0)
+}
+
 func (m *MockTaskRepository) GetByID(ctx context.Context, id string) (*models.Task, error) {
 	args := m.Called(ctx, id)
 	if task, ok := args.Get(0).(*models.Task); ok {
@@ -42,16 +57,134 @@ func (m *MockTaskRepository) GetAll(ctx context.Context, filters models.TaskFilt
 	return args.Get(0).([]models.Task), args.Error(1)
 }
 
+func (m *MockTaskRepository) GetUsersWithTasksUpdatedSince(ctx context.Context, t time.Time) ([]string, error) {
+	args := m.Called(ctx, t)
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockTaskRepository) Count(ctx context.Context, filters models.TaskFilters) (int, error) {
+	args := m.Called(ctx, filters)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockTaskRepository) GetTaskStats(ctx context.Context, userID, projectID string, from, to time.Time) (models.TaskStats, error) {
+	args := m.Called(ctx, userID, projectID, from, to)
+	return args.Get(0).(models.TaskStats), args.Error(1)
+}
+
 func (m *MockTaskRepository) Update(ctx context.Context, task *models.Task) error {
 	args := m.Called(ctx, task)
 	return args.Error(0)
 }
 
+func (m *MockTaskRepository) CompleteTasks(ctx context.Context, userID string, ids []string) ([]models.Task, error) {
+	args := m.Called(ctx, userID, ids)
+	return args.Get(0).([]models.Task), args.Error(1)
+}
+
+func (m *MockTaskRepository) SetAssignee(ctx context.Context, taskID string, assigneeID *string) error {
+	args := m.Called(ctx, taskID, assigneeID)
+	return args.Error(0)
+}
+
 func (m *MockTaskRepository) Delete(ctx context.Context, id string) error {
 	args := m.Called(ctx, id)
 	return args.Error(0)
 }
 
+func (m *MockTaskRepository) DeleteUserTasks(ctx context.Context, userID string) ([]string, error) {
+	args := m.Called(ctx, userID)
+	ids, _ := args.Get(0).([]string)
+	return ids, args.Error(1)
+}
+
+func (m *MockTaskRepository) DeleteTasks(ctx context.Context, filter models.TaskDeleteFilter, limit int) ([]models.Task, error) {
+	args := m.Called(ctx, filter, limit)
+	tasks, _ := args.Get(0).([]models.Task)
+	return tasks, args.Error(1)
+}
+
+func (m *MockTaskRepository) AddRelation(ctx context.Context, taskID, relatedID string) error {
+	args := m.Called(ctx, taskID, relatedID)
+	return args.Error(0)
+}
+
+func (m *MockTaskRepository) RemoveRelation(ctx context.Context, taskID, relatedID string) error {
+	args := m.Called(ctx, taskID, relatedID)
+	return args.Error(0)
+}
+
+func (m *MockTaskRepository) GetRelated(ctx context.Context, taskIDs []string) (map[string][]models.Task, error) {
+	args := m.Called(ctx, taskIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string][]models.Task), args.Error(1)
+}
+
+func (m *MockTaskRepository) GetSubtasks(ctx context.Context, parentID string) ([]models.Task, error) {
+	args := m.Called(ctx, parentID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Task), args.Error(1)
+}
+
+func (m *MockTaskRepository) GetRecurrence(ctx context.Context, id string) (*models.Recurrence, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Recurrence), args.Error(1)
+}
+
+func (m *MockTaskRepository) SetRecurrencePaused(ctx context.Context, id string, paused bool) error {
+	args := m.Called(ctx, id, paused)
+	return args.Error(0)
+}
+
+func (m *MockTaskRepository) GetDueOccurrences(ctx context.Context, now time.Time, limit int) ([]models.DueOccurrence, error) {
+	args := m.Called(ctx, now, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.DueOccurrence), args.Error(1)
+}
+
+func (m *MockTaskRepository) CreateOccurrence(ctx context.Context, sourceID string, next *models.Task) error {
+	args := m.Called(ctx, sourceID, next)
+	return args.Error(0)
+}
+
+func (m *MockTaskRepository) EndRecurrence(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockTaskRepository) GetAncestorIDs(ctx context.Context, taskID string) ([]string, error) {
+	args := m.Called(ctx, taskID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockTaskRepository) GetOpenDescendantIDs(ctx context.Context, taskIDs []string) ([]string, error) {
+	args := m.Called(ctx, taskIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockTaskRepository) GetProject(ctx context.Context, id string) (*models.Project, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Project), args.Error(1)
+}
+
 // MockLogger реализует интерфейс logger.Logger для тестов
 type MockLogger struct {
 	mock.Mock
@@ -183,7 +316,7 @@ func TestCreate(t *testing.T) {
 			mockCache = new(MockCache)
 			tt.setup()
 
-			service := NewTaskService(mockRepo, mockCache, mockLogger)
+			service := NewTaskService(mockRepo, mockCache, nil, nil, nil, nil, nil, mockLogger)
 			got, err := service.CreateTask(context.Background(), "user1", tt.task)
 
 			if tt.wantErr {
@@ -204,11 +337,37 @@ func TestCreate(t *testing.T) {
 	}
 }
 
+func TestCreate_DefaultDueDate(t *testing.T) {
+	mockRepo = new(MockTaskRepository)
+	mockLogger = new(MockLogger)
+	service := NewTaskService(mockRepo, nil, nil, nil, nil, nil, nil, mockLogger).(*TaskServiceImpl)
+	mockRepo.On("Create", mock.Anything, mock.AnythingOfType("*models.Task")).Return(nil)
+	mockLogger.On("Info", mock.Anything, mock.Anything).Return()
+
+	// срок по умолчанию — то же время на следующий день по часам сервиса
+	now := time.Date(2024, 6, 10, 23, 30, 0, 0, time.UTC)
+	service.clock = clock.NewFake(now)
+	got, err := service.CreateTask(context.Background(), "user1", models.Task{Title: "Test Task"})
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 6, 11, 23, 30, 0, 0, time.UTC), got.DueDate)
+
+	// накануне перехода на летнее время сутки короче 24 часов, а время на часах то же
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip("time zone database is unavailable")
+	}
+	service.clock = clock.NewFake(time.Date(2024, 3, 30, 9, 0, 0, 0, berlin))
+	got, err = service.CreateTask(context.Background(), "user1", models.Task{Title: "Test Task"})
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 3, 31, 9, 0, 0, 0, berlin), got.DueDate)
+	assert.Equal(t, 23*time.Hour, got.DueDate.Sub(time.Date(2024, 3, 30, 9, 0, 0, 0, berlin)))
+}
+
 func TestGetByID(t *testing.T) {
 	mockRepo = new(MockTaskRepository)
 	mockLogger = new(MockLogger)
 	mockCache = new(MockCache)
-	service := NewTaskService(mockRepo, mockCache, mockLogger)
+	service := NewTaskService(mockRepo, mockCache, nil, nil, nil, nil, nil, mockLogger)
 
 	taskID := "test-id"
 	userID := "user1"
@@ -292,7 +451,7 @@ func TestGetAll(t *testing.T) {
 	mockRepo = new(MockTaskRepository)
 	mockLogger = new(MockLogger)
 	mockCache = new(MockCache)
-	service := NewTaskService(mockRepo, mockCache, mockLogger)
+	service := NewTaskService(mockRepo, mockCache, nil, nil, nil, nil, nil, mockLogger)
 
 	userID := "user1"
 	tasks := []models.Task{
@@ -351,7 +510,7 @@ func TestUpdate(t *testing.T) {
 	mockRepo = new(MockTaskRepository)
 	mockLogger = new(MockLogger)
 	mockCache = new(MockCache)
-	service := NewTaskService(mockRepo, mockCache, mockLogger)
+	service := NewTaskService(mockRepo, mockCache, nil, nil, nil, nil, nil, mockLogger)
 
 	taskID := "test-id"
 	userID := "user1"
@@ -401,6 +560,21 @@ func TestUpdate(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name:   "Update - invalid link",
+			taskID: taskID,
+			userID: userID,
+			update: models.Task{
+				ID:    taskID,
+				Title: "New Title",
+				Links: []models.TaskLink{{URL: "javascript:alert(1)"}},
+			},
+			setup: func() {
+				mockRepo.On("GetByID", mock.Anything, taskID).Return(existingTask, nil).Once()
+				mockLogger.On("Info", "Updating task", mock.Anything).Return()
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -424,11 +598,55 @@ func TestUpdate(t *testing.T) {
 	}
 }
 
+func TestPatchUserTask(t *testing.T) {
+	mockRepo = new(MockTaskRepository)
+	mockLogger = new(MockLogger)
+	mockCache = new(MockCache)
+	service := NewTaskService(mockRepo, mockCache, nil, nil, nil, nil, nil, mockLogger)
+	ctx := context.Background()
+
+	notes := "notes"
+	due := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	existing := func() *models.Task {
+		return &models.Task{
+			ID: "test-id", UserID: "user1", Title: "Title", Description: "Description", Notes: &notes,
+			Status: models.StatusPending, Priority: models.PriorityLow, DueDate: due,
+		}
+	}
+	mockLogger.On("Info", mock.Anything, mock.Anything).Return()
+	mockLogger.On("Error", mock.Anything, mock.Anything).Return()
+
+	// пустая строка очищает описание, непереданные поля не меняются
+	empty, status := "", models.StatusInProgress
+	mockRepo.On("GetByID", mock.Anything, "test-id").Return(existing(), nil).Once()
+	mockRepo.On("Update", mock.Anything, mock.MatchedBy(func(task *models.Task) bool {
+		return task.Description == "" && task.Title == "Title" && *task.Notes == "notes" &&
+			task.Status == models.StatusInProgress && task.Priority == models.PriorityLow && task.DueDate.Equal(due)
+	})).Return(nil).Once()
+
+	got, err := service.PatchUserTask(ctx, "user1", "test-id", models.UpdateTaskRequest{Description: &empty, Status: &status})
+	assert.NoError(t, err)
+	assert.Equal(t, "", got.Description)
+	assert.Equal(t, "Title", got.Title)
+
+	// пустой заголовок недопустим
+	mockRepo.On("GetByID", mock.Anything, "test-id").Return(existing(), nil).Once()
+	_, err = service.PatchUserTask(ctx, "user1", "test-id", models.UpdateTaskRequest{Title: &empty})
+	assert.Equal(t, ErrInvalidTaskData, err)
+
+	// чужая задача
+	mockRepo.On("GetByID", mock.Anything, "test-id").Return(existing(), nil).Once()
+	_, err = service.PatchUserTask(ctx, "user2", "test-id", models.UpdateTaskRequest{Status: &status})
+	assert.Equal(t, ErrAccessDenied, err)
+
+	mockRepo.AssertExpectations(t)
+}
+
 func TestDelete(t *testing.T) {
 	mockRepo = new(MockTaskRepository)
 	mockLogger = new(MockLogger)
 	mockCache = new(MockCache)
-	service := NewTaskService(mockRepo, mockCache, mockLogger)
+	service := NewTaskService(mockRepo, mockCache, nil, nil, nil, nil, nil, mockLogger)
 
 	taskID := "test-id"
 	userID := "user1"
@@ -485,34 +703,48 @@ func TestDelete(t *testing.T) {
 	}
 }
 
+func TestPurgeUserTasks(t *testing.T) {
+	mockRepo = new(MockTaskRepository)
+	mockLogger = new(MockLogger)
+	taskCache := new(MockTaskCache)
+	service := NewTaskService(mockRepo, nil, nil, nil, taskCache, nil, nil, mockLogger)
+
+	mockRepo.On("DeleteUserTasks", mock.Anything, "user1").Return([]string{"a", "b"}, nil).Once()
+	taskCache.On("InvalidateTask", mock.Anything, "a").Return(nil).Once()
+	taskCache.On("InvalidateTask", mock.Anything, "b").Return(nil).Once()
+	taskCache.On("InvalidateTaskLists", mock.Anything, "user1").Return(nil).Once()
+	mockLogger.On("Info", "User tasks purged", mock.Anything).Return().Once()
+
+	count, err := service.PurgeUserTasks(context.Background(), "user1")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	mockRepo.On("DeleteUserTasks", mock.Anything, "user2").Return([]string(nil), errors.New("db down")).Once()
+	mockLogger.On("Error", "Failed to purge user tasks", mock.Anything).Return().Once()
+	_, err = service.PurgeUserTasks(context.Background(), "user2")
+	assert.Error(t, err)
+
+	mockRepo.AssertExpectations(t)
+	taskCache.AssertExpectations(t)
+}
+
 func TestGetAnalytics(t *testing.T) {
 	mockRepo = new(MockTaskRepository)
 	mockLogger = new(MockLogger)
 	mockCache = new(MockCache)
-	service := NewTaskService(mockRepo, mockCache, mockLogger)
+	service := NewTaskService(mockRepo, mockCache, nil, nil, nil, nil, nil, mockLogger).(*TaskServiceImpl)
+	now := time.Date(2024, 6, 10, 12, 0, 0, 0, time.UTC)
+	service.clock = clock.NewFake(now)
 
 	userID := "user1"
-	now := time.Now()
-	tasks := []models.Task{
-		{
-			ID:          "1",
-			Title:       "Task 1",
-			UserID:      userID,
-			Status:      models.StatusDone,
-			Priority:    models.PriorityHigh,
-			CreatedAt:   now.Add(-48 * time.Hour),
-			CompletedAt: &now,
-			DueDate:     now.Add(24 * time.Hour),
-		},
-		{
-			ID:        "2",
-			Title:     "Task 2",
-			UserID:    userID,
-			Status:    models.StatusPending,
-			Priority:  models.PriorityMedium,
-			CreatedAt: now.Add(-24 * time.Hour),
-			DueDate:   now.Add(-1 * time.Hour),
-		},
+	// агрегаты считает хранилище, сервис переводит их в аналитику
+	stats := models.TaskStats{
+		StatusCount:        map[models.Status]int{models.StatusDone: 1, models.StatusPending: 1},
+		PriorityCount:      map[models.Priority]int{models.PriorityHigh: 1, models.PriorityMedium: 1},
+		Completed:          2,
+		CompletedOnTime:    1,
+		AvgCompletionHours: 48,
+		Overdue:            1,
 	}
 
 	tests := []struct {
@@ -528,10 +760,7 @@ func TestGetAnalytics(t *testing.T) {
 			userID: userID,
 			period: "week",
 			setup: func() {
-				filters := models.TaskFilters{
-					UserID: userID,
-				}
-				mockRepo.On("GetAll", mock.Anything, filters).Return(tasks, nil).Once()
+				mockRepo.On("GetTaskStats", mock.Anything, userID, "", now.AddDate(0, 0, -7), now).Return(stats, nil).Once()
 				mockCache.On("GetUserAnalytics", mock.Anything, userID, "week").Return(nil, redis.Nil).Once()
 				mockCache.On("SetUserAnalytics", mock.Anything, mock.MatchedBy(func(analytics repository.CachedAnalytics) bool {
 					return analytics.UserID == userID && analytics.Period == "week"
@@ -548,7 +777,7 @@ func TestGetAnalytics(t *testing.T) {
 					models.PriorityMedium: 1,
 				},
 				AvgCompletionTime:    float64(48),
-				OnTimeCompletionRate: 1.0,
+				OnTimeCompletionRate: 50,
 				OverdueTasks:         1,
 				Period:               "week",
 				GeneratedAt:          now,
@@ -571,9 +800,348 @@ func TestGetAnalytics(t *testing.T) {
 				assert.Equal(t, tt.want.PriorityCount, got.PriorityCount)
 				assert.Equal(t, tt.want.OverdueTasks, got.OverdueTasks)
 				assert.Equal(t, tt.want.Period, got.Period)
-				assert.NotZero(t, got.AvgCompletionTime)
-				assert.NotZero(t, got.OnTimeCompletionRate)
+				assert.Equal(t, tt.want.AvgCompletionTime, got.AvgCompletionTime)
+				assert.Equal(t, tt.want.OnTimeCompletionRate, got.OnTimeCompletionRate)
+				assert.Equal(t, tt.want.GeneratedAt, got.GeneratedAt)
 			}
 		})
 	}
 }
+
+func TestGetAnalyticsRange(t *testing.T) {
+	mockRepo = new(MockTaskRepository)
+	mockLogger = new(MockLogger)
+	mockCache = new(MockCache)
+	service := NewTaskService(mockRepo, mockCache, nil, nil, nil, nil, nil, mockLogger).(*TaskServiceImpl)
+	now := time.Date(2024, 6, 10, 12, 0, 0, 0, time.UTC)
+	service.clock = clock.NewFake(now)
+	ctx := context.Background()
+
+	from := time.Date(2024, 6, 3, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, 14)
+
+	// пустое, перевернутое и слишком длинное окно
+	for _, window := range [][2]time.Time{{from, from}, {to, from}, {from, from.AddDate(2, 0, 0)}} {
+		_, err := service.GetUserAnalyticsRange(ctx, "user1", window[0], window[1])
+		assert.Equal(t, ErrInvalidAnalyticsRange, err)
+	}
+
+	// окно, которое еще не закончилось, считается до своей границы, ключ кэша содержит обе границы
+	key := "20240603T000000Z_20240617T000000Z"
+	mockCache.On("GetUserAnalytics", mock.Anything, "user1", key).Return(nil, nil).Once()
+	mockRepo.On("GetTaskStats", mock.Anything, "user1", "", from, to).Return(models.TaskStats{Overdue: 2}, nil).Once()
+	mockCache.On("SetUserAnalytics", mock.Anything, mock.MatchedBy(func(analytics repository.CachedAnalytics) bool {
+		return analytics.UserID == "user1" && analytics.Period == key
+	})).Return(nil).Once()
+
+	// границы в другом часовом поясе приводятся к UTC
+	msk := time.FixedZone("MSK", 3*60*60)
+	got, err := service.GetUserAnalyticsRange(ctx, "user1", from.In(msk), to.In(msk))
+	require.NoError(t, err)
+	assert.Equal(t, "custom", got.Period)
+	assert.Equal(t, 2, got.OverdueTasks)
+	assert.Equal(t, &from, got.From)
+	assert.Equal(t, &to, got.To)
+	assert.Equal(t, now, got.GeneratedAt)
+
+	mockRepo.AssertExpectations(t)
+	mockCache.AssertExpectations(t)
+}
+
+func TestGetAnalytics_CacheMetrics(t *testing.T) {
+	mockRepo = new(MockTaskRepository)
+	mockLogger = new(MockLogger)
+	mockCache = new(MockCache)
+	service := NewTaskService(mockRepo, mockCache, nil, nil, nil, nil, nil, mockLogger).(*TaskServiceImpl)
+	// возраст записи считается по часам сервиса
+	now := time.Date(2024, 3, 10, 9, 0, 0, 0, time.UTC)
+	service.clock = clock.NewFake(now)
+	ctx := context.Background()
+	mockLogger.On("Info", mock.Anything, mock.Anything).Return()
+
+	count := func(result string) float64 {
+		return testutil.ToFloat64(metrics.AnalyticsCacheRequestsTotal.WithLabelValues(result))
+	}
+	computed := func(period string) uint64 {
+		var m dto.Metric
+		_ = metrics.AnalyticsComputeDuration.WithLabelValues(period).(prometheus.Metric).Write(&m)
+		return m.GetHistogram().GetSampleCount()
+	}
+	hits, stale, misses, computedMonth := count("hit"), count("stale"), count("miss"), computed("month")
+
+	cached := models.Analytics{Period: "day", OverdueTasks: 3}
+	mockCache.On("GetUserAnalytics", mock.Anything, "user1", "day").Return(&repository.CachedAnalytics{
+		UserID: "user1", Period: "day", Analytics: cached, CachedAt: now,
+	}, nil).Once()
+	got, err := service.GetUserAnalytics(ctx, "user1", "day")
+	assert.NoError(t, err)
+	assert.Equal(t, cached, got)
+	assert.Equal(t, hits+1, count("hit"))
+
+	// запись старше порога отдается, но учитывается как устаревшая
+	mockCache.On("GetUserAnalytics", mock.Anything, "user1", "day").Return(&repository.CachedAnalytics{
+		UserID: "user1", Period: "day", Analytics: cached, CachedAt: now.Add(-2 * analyticsStaleAfter),
+	}, nil).Once()
+	_, err = service.GetUserAnalytics(ctx, "user1", "day")
+	assert.NoError(t, err)
+	assert.Equal(t, stale+1, count("stale"))
+	assert.Equal(t, hits+1, count("hit"))
+
+	// промах: аналитика считается по БД, время расчета попадает в гистограмму
+	mockCache.On("GetUserAnalytics", mock.Anything, "user1", "month").Return(nil, nil).Once()
+	mockRepo.On("GetTaskStats", mock.Anything, "user1", "", mock.Anything, mock.Anything).Return(models.TaskStats{}, nil).Once()
+	mockCache.On("SetUserAnalytics", mock.Anything, mock.Anything).Return(nil).Once()
+	_, err = service.GetUserAnalytics(ctx, "user1", "month")
+	assert.NoError(t, err)
+	assert.Equal(t, misses+1, count("miss"))
+	assert.Equal(t, computedMonth+1, computed("month"))
+
+	mockRepo.AssertExpectations(t)
+	mockCache.AssertExpectations(t)
+}
+
+func TestPrivateTask(t *testing.T) {
+	mockRepo = new(MockTaskRepository)
+	mockLogger = new(MockLogger)
+	mockCache = new(MockCache)
+
+	encryptor, err := crypto.NewTaskEncryptor("0123456789abcdef0123456789abcdef")
+	assert.NoError(t, err)
+	service := NewTaskService(mockRepo, mockCache, encryptor, nil, nil, nil, nil, mockLogger)
+
+	userID := "user1"
+	var stored models.Task
+
+	mockLogger.On("Info", mock.Anything, mock.Anything).Return()
+	mockRepo.On("Create", mock.Anything, mock.AnythingOfType("*models.Task")).Run(func(args mock.Arguments) {
+		stored = *args.Get(1).(*models.Task)
+	}).Return(nil).Once()
+
+	created, err := service.CreateTask(context.Background(), userID, models.Task{
+		ID:          "private-id",
+		Title:       "Secret",
+		Description: "Secret description",
+		Private:     true,
+		DueDate:     time.Now().Add(24 * time.Hour),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "Secret", created.Title)
+	assert.NotEqual(t, "Secret", stored.Title)
+	assert.NotEqual(t, "Secret description", stored.Description)
+
+	mockRepo.On("GetByID", mock.Anything, "private-id").Return(&stored, nil).Once()
+	locked, err := service.GetUserTask(context.Background(), userID, "private-id")
+	assert.NoError(t, err)
+	assert.True(t, locked.Locked)
+	assert.Empty(t, locked.Title)
+
+	storedCopy := stored
+	mockRepo.On("GetByID", mock.Anything, "private-id").Return(&storedCopy, nil).Once()
+	unlocked, err := service.UnlockUserTask(context.Background(), userID, "private-id")
+	assert.NoError(t, err)
+	assert.False(t, unlocked.Locked)
+	assert.Equal(t, "Secret", unlocked.Title)
+	assert.Equal(t, "Secret description", unlocked.Description)
+
+	disabled := NewTaskService(mockRepo, mockCache, nil, nil, nil, nil, nil, mockLogger)
+	_, err = disabled.CreateTask(context.Background(), userID, models.Task{Title: "Secret", Private: true})
+	assert.Equal(t, ErrPrivateTasksDisabled, err)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGetDashboard(t *testing.T) {
+	mockRepo = new(MockTaskRepository)
+	mockLogger = new(MockLogger)
+	mockCache = new(MockCache)
+	service := NewTaskService(mockRepo, mockCache, nil, nil, nil, nil, nil, mockLogger)
+
+	userID := "user1"
+	mockRepo.On("Count", mock.Anything, models.TaskFilters{UserID: userID}).Return(6, nil).Once()
+	mockRepo.On("Count", mock.Anything, models.TaskFilters{UserID: userID, Status: models.StatusPending}).Return(3, nil).Once()
+	mockRepo.On("Count", mock.Anything, models.TaskFilters{UserID: userID, Status: models.StatusInProgress}).Return(1, nil).Once()
+	mockRepo.On("Count", mock.Anything, models.TaskFilters{UserID: userID, Status: models.StatusDone}).Return(2, nil).Once()
+	mockRepo.On("Count", mock.Anything, mock.MatchedBy(func(filters models.TaskFilters) bool {
+		return filters.UserID == userID && filters.DueDate != nil
+	})).Return(1, nil).Once()
+	mockRepo.On("Count", mock.Anything, models.TaskFilters{UserID: userID, Overdue: true}).Return(2, nil).Once()
+
+	got, err := service.GetDashboard(context.Background(), userID)
+	assert.NoError(t, err)
+	assert.Equal(t, 6, got.Total)
+	assert.Equal(t, 1, got.DueToday)
+	assert.Equal(t, 2, got.Overdue)
+	assert.Equal(t, map[models.Status]int{
+		models.StatusPending:    3,
+		models.StatusInProgress: 1,
+		models.StatusDone:       2,
+	}, got.StatusCount)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestPreviewImport(t *testing.T) {
+	mockRepo = new(MockTaskRepository)
+	mockLogger = new(MockLogger)
+	mockCache = new(MockCache)
+	service := NewTaskService(mockRepo, mockCache, nil, nil, nil, nil, nil, mockLogger)
+
+	dueDate := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	mockRepo.On("GetAll", mock.Anything, models.TaskFilters{UserID: "user1"}).
+		Return([]models.Task{{ID: "1", UserID: "user1", Title: "Existing", DueDate: dueDate}}, nil)
+
+	batch := models.ImportBatch{
+		Rows: []models.ImportRow{
+			{Row: 1, Task: models.Task{Title: "existing", DueDate: dueDate.Add(time.Hour)}},
+			{Row: 2, Task: models.Task{Title: "New"}},
+			{Row: 3, Task: models.Task{Title: "new"}},
+			{Row: 4, Task: models.Task{Title: ""}},
+			{Row: 5, Task: models.Task{Title: "Other", Priority: "urgent"}},
+		},
+		Errors: []models.ImportRowError{{Row: 6, Message: "invalid json"}},
+	}
+
+	preview, err := service.PreviewImport(context.Background(), "user1", batch)
+	assert.NoError(t, err)
+	assert.Equal(t, 6, preview.Total)
+	assert.Equal(t, 1, preview.Create)
+	assert.Equal(t, 2, preview.Duplicates)
+	assert.Equal(t, []int{1, 3}, preview.DuplicateRows)
+	assert.Equal(t, 3, preview.Invalid)
+	assert.Len(t, preview.Errors, 3)
+
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestImportTasks_Batch(t *testing.T) {
+	mockRepo = new(MockTaskRepository)
+	mockLogger = new(MockLogger)
+	mockCache = new(MockCache)
+	service := NewTaskService(mockRepo, mockCache, nil, nil, nil, nil, nil, mockLogger)
+	ctx := context.Background()
+
+	// все задачи уходят в репозиторий одним вызовом, по одной не создаются
+	mockRepo.On("CreateBatch", mock.Anything, mock.MatchedBy(func(tasks []models.Task) bool {
+		return len(tasks) == 3 && tasks[0].UserID == "user1" && tasks[2].ID != "" &&
+			tasks[1].Status == models.StatusPending
+	})).Return(nil).Once()
+	err := service.ImportTasks(ctx, "user1", []models.Task{{Title: "a"}, {Title: "b"}, {Title: "c"}})
+	require.NoError(t, err)
+
+	failure := errors.New("insert failed")
+	mockRepo.On("CreateBatch", mock.Anything, mock.Anything).Return(failure).Once()
+	err = service.ImportTasks(ctx, "user1", []models.Task{{Title: "d"}})
+	assert.Equal(t, failure, err)
+
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestImportTaskFile(t *testing.T) {
+	mockRepo = new(MockTaskRepository)
+	mockLogger = new(MockLogger)
+	mockCache = new(MockCache)
+	service := NewTaskService(mockRepo, mockCache, nil, nil, nil, nil, nil, mockLogger)
+	ctx := context.Background()
+
+	data := "title,priority,due_date\n" +
+		"Report,high,2024-05-01\n" +
+		"Call,urgent,\n" +
+		"Review,,someday\n" +
+		"Deploy,,\n"
+	open := func() *importer.RowReader {
+		rows, err := importer.NewReader(importer.FormatCSV, strings.NewReader(data), 0)
+		require.NoError(t, err)
+		return rows
+	}
+
+	// ошибки строк без skipInvalid: отчет есть, задачи не созданы
+	report, err := service.ImportTaskFile(ctx, "user1", open(), false)
+	assert.ErrorIs(t, err, ErrImportInvalidRows)
+	assert.Equal(t, 4, report.Total)
+	assert.Equal(t, 0, report.Imported)
+	assert.Equal(t, 2, report.Invalid)
+	assert.Equal(t, []models.ImportRowError{
+		{Row: 3, Field: "priority", Message: "unknown priority urgent"},
+		{Row: 4, Field: "due_date", Message: "invalid date, expected YYYY-MM-DD or RFC 3339"},
+	}, report.Errors)
+	mockRepo.AssertNotCalled(t, "CreateBatch", mock.Anything, mock.Anything)
+
+	mockRepo.On("CreateBatch", mock.Anything, mock.MatchedBy(func(tasks []models.Task) bool {
+		return len(tasks) == 2 && tasks[0].Title == "Report" && tasks[1].Title == "Deploy" &&
+			tasks[0].UserID == "user1" && tasks[0].ID != "" &&
+			tasks[1].Status == models.StatusPending && tasks[1].Priority == models.PriorityMedium
+	})).Return(nil).Once()
+	mockLogger.On("Info", "Tasks imported from file", mock.Anything).Return().Once()
+
+	report, err = service.ImportTaskFile(ctx, "user1", open(), true)
+	require.NoError(t, err)
+	assert.Equal(t, 2, report.Imported)
+	assert.Equal(t, 2, report.Invalid)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestUpdate_PublishesFieldChanges(t *testing.T) {
+	mockRepo = new(MockTaskRepository)
+	mockLogger = new(MockLogger)
+	mockLogger.On("Info", mock.Anything, mock.Anything).Return()
+	publisher := &recordingPublisher{}
+	service := NewTaskService(mockRepo, nil, nil, publisher, nil, nil, nil, mockLogger)
+	ctx := context.Background()
+
+	mockRepo.On("GetByID", mock.Anything, "task").Return(&models.Task{
+		ID: "task", UserID: "user1", Title: "Report", Description: "Draft", Status: models.StatusPending, Priority: models.PriorityLow,
+	}, nil).Once()
+	mockRepo.On("Update", mock.Anything, mock.Anything).Return(nil).Once()
+
+	status := models.StatusInProgress
+	priority := models.PriorityHigh
+	_, err := service.PatchUserTask(ctx, "user1", "task", models.UpdateTaskRequest{Status: &status, Priority: &priority})
+	require.NoError(t, err)
+
+	require.Len(t, publisher.events, 1)
+	assert.Equal(t, models.EventTaskUpdated, publisher.events[0].Type)
+	assert.Equal(t, []models.FieldChange{
+		{Field: "status", From: models.StatusPending, To: models.StatusInProgress},
+		{Field: "priority", From: models.PriorityLow, To: models.PriorityHigh},
+	}, publisher.events[0].Changes)
+	assert.Equal(t, "status changed from pending to in_progress", publisher.events[0].Changes[0].String())
+}
+
+func TestDiffTasks_PrivateValuesHidden(t *testing.T) {
+	before := models.Task{Title: "Salary", Private: true, Status: models.StatusPending}
+	after := models.Task{Title: "Salary review", Private: true, Status: models.StatusPending, Tags: []string{}}
+
+	// пустой список тегов не отличается от отсутствующего, значения зашифрованных полей не раскрываются
+	changes := models.DiffTasks(before, after)
+	assert.Equal(t, []models.FieldChange{{Field: "title"}}, changes)
+	assert.Equal(t, "title changed", changes[0].String())
+}
+
+func TestGetUserTasks_MarksOverdueByClock(t *testing.T) {
+	mockRepo = new(MockTaskRepository)
+	mockLogger = new(MockLogger)
+	service := NewTaskService(mockRepo, nil, nil, nil, nil, nil, nil, mockLogger).(*TaskServiceImpl)
+	now := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+	fake := clock.NewFake(now)
+	service.clock = fake
+
+	due := now.Add(time.Hour)
+	mockRepo.On("GetAll", mock.Anything, models.TaskFilters{UserID: "user1"}).Return([]models.Task{
+		{ID: "open", UserID: "user1", Status: models.StatusPending, DueDate: due},
+		{ID: "done", UserID: "user1", Status: models.StatusDone, DueDate: due},
+	}, nil)
+
+	tasks, err := service.GetUserTasks(context.Background(), "user1", models.TaskFilters{UserID: "user1"})
+	require.NoError(t, err)
+	assert.False(t, tasks[0].IsOverdue)
+	assert.False(t, tasks[1].IsOverdue)
+
+	// признак считается по часам сервиса на момент отдачи, а не при сериализации
+	fake.Advance(2 * time.Hour)
+	tasks, err = service.GetUserTasks(context.Background(), "user1", models.TaskFilters{UserID: "user1"})
+	require.NoError(t, err)
+	assert.True(t, tasks[0].IsOverdue)
+	assert.False(t, tasks[1].IsOverdue)
+}
@@ -0,0 +1,205 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jmoloko/taskmange/internal/clock"
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/jmoloko/taskmange/internal/domain/repository"
+	domainService "github.com/jmoloko/taskmange/internal/domain/service"
+	"github.com/jmoloko/taskmange/internal/logger"
+	"github.com/jmoloko/taskmange/internal/notification"
+)
+
+// сколько задач перечисляется в ежедневной сводке
+const telegramDigestTasks = 10
+
+var (
+	ErrTelegramDisabled    = errors.New("telegram bot is not configured")
+	ErrTelegramNotLinked   = errors.New("telegram chat is not linked")
+	ErrInvalidTelegramLink = errors.New("invalid telegram link")
+)
+
+// TelegramService привязка чатов Telegram и ежедневные сводки задач.
+// Напоминания о сроках доставляет диспетчер уведомлений через канал telegram
+type TelegramService struct {
+	links  repository.TelegramLinkRepository
+	bot    domainService.TelegramBot
+	tasks  domainService.TaskService
+	logger logger.Logger
+	clock  clock.Clock
+}
+
+// NewTelegramService создает новый экземпляр TelegramService.
+// bot равен nil, если токен бота не задан: тогда привязка возвращает ErrTelegramDisabled
+func NewTelegramService(links repository.TelegramLinkRepository, bot domainService.TelegramBot, tasks domainService.TaskService, logger logger.Logger) *TelegramService {
+	return &TelegramService{
+		links:  links,
+		bot:    bot,
+		tasks:  tasks,
+		logger: logger,
+		clock:  clock.System,
+	}
+}
+
+// привязанный чат пользователя
+func (s *TelegramService) GetLink(ctx context.Context, userID string) (*models.TelegramLink, error) {
+	link, err := s.links.GetTelegramLink(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if link == nil {
+		return nil, ErrTelegramNotLinked
+	}
+
+	return link, nil
+}
+
+// привязываем чат; бот сначала отправляет в него подтверждение, поэтому пользователь
+// должен заранее написать боту /start
+func (s *TelegramService) Link(ctx context.Context, userID string, req models.TelegramLinkRequest) (*models.TelegramLink, error) {
+	if s.bot == nil {
+		return nil, ErrTelegramDisabled
+	}
+	if req.DailyDigestAt != "" {
+		if _, err := time.Parse("15:04", req.DailyDigestAt); err != nil {
+			return nil, fmt.Errorf("%w: daily_digest_at must be in HH:MM format", ErrInvalidTelegramLink)
+		}
+	}
+
+	err := s.bot.SendMessage(ctx, req.ChatID, "Task manager notifications are now linked to this chat.")
+	if errors.Is(err, notification.ErrTelegramChatUnavailable) {
+		return nil, fmt.Errorf("%w: the bot cannot write to this chat, send /start to the bot first", ErrInvalidTelegramLink)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	link := &models.TelegramLink{
+		UserID:        userID,
+		ChatID:        req.ChatID,
+		DailyDigestAt: req.DailyDigestAt,
+	}
+	if err := s.links.SaveTelegramLink(ctx, link); err != nil {
+		return nil, err
+	}
+
+	return link, nil
+}
+
+// отвязываем чат пользователя
+func (s *TelegramService) Unlink(ctx context.Context, userID string) error {
+	err := s.links.DeleteTelegramLink(ctx, userID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return ErrTelegramNotLinked
+	}
+	return err
+}
+
+// SendDailyDigests отправляет сводку задач на сегодня тем, у кого наступило время сводки
+// в их часовом поясе. Вызывается фоновым воркером по расписанию
+func (s *TelegramService) SendDailyDigests(ctx context.Context) error {
+	if s.bot == nil {
+		return nil
+	}
+
+	links, err := s.links.GetTelegramDigestLinks(ctx)
+	if err != nil {
+		return err
+	}
+
+	sent := 0
+	for _, link := range links {
+		loc, err := time.LoadLocation(link.Timezone)
+		if err != nil {
+			loc = time.UTC
+		}
+		now := s.clock.Now().In(loc)
+		today := now.Format(time.DateOnly)
+		if now.Format("15:04") < link.DailyDigestAt {
+			continue
+		}
+		if link.LastDigestOn != nil && link.LastDigestOn.Format(time.DateOnly) >= today {
+			continue
+		}
+
+		ok, err := s.sendDigest(ctx, link, startOfDay(now))
+		if err != nil {
+			s.logger.Error("Failed to send telegram digest", map[string]interface{}{
+				"user_id": link.UserID,
+				"error":   err.Error(),
+			})
+			continue
+		}
+		if ok {
+			sent++
+		}
+
+		// день отмечается и без задач, чтобы не проверять пользователя до завтра
+		if err := s.links.MarkTelegramDigestSent(ctx, link.UserID, startOfDay(now)); err != nil {
+			return err
+		}
+	}
+
+	if sent > 0 {
+		s.logger.Info("Telegram digests sent", map[string]interface{}{
+			"users": sent,
+		})
+	}
+
+	return nil
+}
+
+// sendDigest отправляет сводку одного пользователя, false — задач на сегодня нет
+func (s *TelegramService) sendDigest(ctx context.Context, link models.TelegramLink, start time.Time) (bool, error) {
+	end := start.AddDate(0, 0, 1)
+	tasks, err := s.tasks.GetUserTasks(ctx, link.UserID, models.TaskFilters{
+		UserID:    link.UserID,
+		Sort:      models.SortSmart,
+		Open:      true,
+		DueBefore: &end,
+	})
+	if err != nil {
+		return false, err
+	}
+	if len(tasks) == 0 {
+		return false, nil
+	}
+
+	err = s.bot.SendMessage(ctx, link.ChatID, telegramDigest(tasks, start))
+	if errors.Is(err, notification.ErrTelegramChatUnavailable) {
+		// пользователь заблокировал бота: сводки больше не отправляются
+		return false, s.links.DeleteTelegramLink(ctx, link.UserID)
+	}
+
+	return err == nil, err
+}
+
+// telegramDigest текст сводки; заголовки приватных задач зашифрованы и не раскрываются
+func telegramDigest(tasks []models.Task, day time.Time) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Tasks for %s: %d", day.Format(time.DateOnly), len(tasks))
+
+	for i, task := range tasks {
+		if i == telegramDigestTasks {
+			fmt.Fprintf(&b, "\n… and %d more", len(tasks)-telegramDigestTasks)
+			break
+		}
+
+		title := task.Title
+		if task.Private {
+			title = "Private task"
+		}
+		mark := ""
+		if task.DueDate.Before(day) {
+			mark = " (overdue)"
+		}
+		fmt.Fprintf(&b, "\n• %s%s", title, mark)
+	}
+
+	return b.String()
+}
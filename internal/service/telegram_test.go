@@ -0,0 +1,134 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jmoloko/taskmange/internal/clock"
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/jmoloko/taskmange/internal/domain/repository"
+	"github.com/jmoloko/taskmange/internal/notification"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryTelegramLinks implements repository.TelegramLinkRepository
+type memoryTelegramLinks struct {
+	links map[string]models.TelegramLink
+}
+
+func (r *memoryTelegramLinks) SaveTelegramLink(ctx context.Context, link *models.TelegramLink) error {
+	link.LinkedAt = time.Now()
+	r.links[link.UserID] = *link
+	return nil
+}
+
+func (r *memoryTelegramLinks) GetTelegramLink(ctx context.Context, userID string) (*models.TelegramLink, error) {
+	link, ok := r.links[userID]
+	if !ok {
+		return nil, nil
+	}
+	return &link, nil
+}
+
+func (r *memoryTelegramLinks) DeleteTelegramLink(ctx context.Context, userID string) error {
+	if _, ok := r.links[userID]; !ok {
+		return repository.ErrNotFound
+	}
+	delete(r.links, userID)
+	return nil
+}
+
+func (r *memoryTelegramLinks) GetTelegramDigestLinks(ctx context.Context) ([]models.TelegramLink, error) {
+	var links []models.TelegramLink
+	for _, link := range r.links {
+		if link.DailyDigestAt != "" {
+			links = append(links, link)
+		}
+	}
+	return links, nil
+}
+
+func (r *memoryTelegramLinks) MarkTelegramDigestSent(ctx context.Context, userID string, day time.Time) error {
+	link := r.links[userID]
+	link.LastDigestOn = &day
+	r.links[userID] = link
+	return nil
+}
+
+// fakeTelegramBot implements domainService.TelegramBot
+type fakeTelegramBot struct {
+	messages map[int64][]string
+	blocked  map[int64]bool
+}
+
+func (b *fakeTelegramBot) SendMessage(ctx context.Context, chatID int64, text string) error {
+	if b.blocked[chatID] {
+		return notification.ErrTelegramChatUnavailable
+	}
+	b.messages[chatID] = append(b.messages[chatID], text)
+	return nil
+}
+
+func TestTelegramService_Link(t *testing.T) {
+	links := &memoryTelegramLinks{links: map[string]models.TelegramLink{}}
+	bot := &fakeTelegramBot{messages: map[int64][]string{}, blocked: map[int64]bool{13: true}}
+	service := NewTelegramService(links, bot, nil, new(MockLogger))
+	ctx := context.Background()
+
+	_, err := service.GetLink(ctx, "user1")
+	assert.ErrorIs(t, err, ErrTelegramNotLinked)
+
+	_, err = service.Link(ctx, "user1", models.TelegramLinkRequest{ChatID: 42, DailyDigestAt: "8am"})
+	assert.ErrorIs(t, err, ErrInvalidTelegramLink)
+
+	// бот не может писать в чат, пока пользователь не отправил ему /start
+	_, err = service.Link(ctx, "user1", models.TelegramLinkRequest{ChatID: 13})
+	assert.ErrorIs(t, err, ErrInvalidTelegramLink)
+
+	link, err := service.Link(ctx, "user1", models.TelegramLinkRequest{ChatID: 42, DailyDigestAt: "08:00"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(42), link.ChatID)
+	assert.Len(t, bot.messages[42], 1)
+
+	require.NoError(t, service.Unlink(ctx, "user1"))
+	assert.ErrorIs(t, service.Unlink(ctx, "user1"), ErrTelegramNotLinked)
+
+	disabled := NewTelegramService(links, nil, nil, new(MockLogger))
+	_, err = disabled.Link(ctx, "user1", models.TelegramLinkRequest{ChatID: 42})
+	assert.ErrorIs(t, err, ErrTelegramDisabled)
+}
+
+func TestTelegramService_SendDailyDigests(t *testing.T) {
+	mockRepo = new(MockTaskRepository)
+	mockLogger = new(MockLogger)
+	mockLogger.On("Info", "Telegram digests sent", mock.Anything).Return()
+	links := &memoryTelegramLinks{links: map[string]models.TelegramLink{
+		"user1": {UserID: "user1", ChatID: 1, DailyDigestAt: "08:00", Timezone: "Europe/Moscow"},
+		"user2": {UserID: "user2", ChatID: 2, DailyDigestAt: "12:00", Timezone: "UTC"},
+		"user3": {UserID: "user3", ChatID: 3, DailyDigestAt: "07:00", Timezone: "UTC"},
+	}}
+	bot := &fakeTelegramBot{messages: map[int64][]string{}, blocked: map[int64]bool{}}
+	service := NewTelegramService(links, bot, NewTaskService(mockRepo, nil, nil, nil, nil, nil, nil, mockLogger), mockLogger)
+	// 09:30 в Москве, 06:30 UTC
+	service.clock = clock.NewFake(time.Date(2024, 3, 10, 6, 30, 0, 0, time.UTC))
+	ctx := context.Background()
+
+	mockRepo.On("GetAll", mock.Anything, mock.MatchedBy(func(f models.TaskFilters) bool { return f.UserID == "user1" })).Return([]models.Task{
+		{ID: "1", Title: "Pay rent", DueDate: time.Date(2024, 3, 9, 12, 0, 0, 0, time.UTC)},
+		{ID: "2", Title: "Secret", Private: true, DueDate: time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)},
+	}, nil)
+
+	require.NoError(t, service.SendDailyDigests(ctx))
+	require.Len(t, bot.messages[1], 1)
+	assert.Equal(t, "Tasks for 2024-03-10: 2\n• Pay rent (overdue)\n• Private task", bot.messages[1][0])
+	assert.Empty(t, bot.messages[2])
+	assert.Empty(t, bot.messages[3])
+
+	// повторная проверка в тот же день сводку не отправляет
+	require.NoError(t, service.SendDailyDigests(ctx))
+	assert.Len(t, bot.messages[1], 1)
+	mockRepo.AssertNumberOfCalls(t, "GetAll", 1)
+}
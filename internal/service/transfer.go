@@ -0,0 +1,67 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/jmoloko/taskmange/internal/domain/repository"
+	"github.com/jmoloko/taskmange/internal/logger"
+)
+
+// максимальное число записей истории в одном ответе
+const maxTransferHistory = 200
+
+var ErrInvalidTransferLimit = errors.New("invalid transfer history limit")
+
+// TransferService история импорта и экспорта задач
+type TransferService struct {
+	repo   repository.TransferRepository
+	logger logger.Logger
+}
+
+// NewTransferService создает новый экземпляр TransferService
+func NewTransferService(repo repository.TransferRepository, logger logger.Logger) *TransferService {
+	return &TransferService{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+// Record сохраняет операцию импорта или экспорта, начатую в started.
+// opErr — ошибка операции, nil для успешной. Ошибка записи истории только логируется,
+// чтобы не ломать сам импорт или экспорт; запись сохраняется и после отключения клиента
+func (s *TransferService) Record(ctx context.Context, userID string, direction models.TransferDirection, format string, items int, started time.Time, opErr error) {
+	transfer := models.DataTransfer{
+		ID:         uuid.New().String(),
+		UserID:     userID,
+		Direction:  direction,
+		Format:     format,
+		Status:     models.TransferCompleted,
+		Items:      items,
+		DurationMs: time.Since(started).Milliseconds(),
+	}
+	if opErr != nil {
+		transfer.Status = models.TransferFailed
+		transfer.Error = opErr.Error()
+	}
+
+	if err := s.repo.SaveTransfer(context.WithoutCancel(ctx), &transfer); err != nil {
+		s.logger.Error("Failed to save data transfer", map[string]interface{}{
+			"user_id":   userID,
+			"direction": direction,
+			"error":     err.Error(),
+		})
+	}
+}
+
+// History последние операции пользователя, limit от 1 до 200
+func (s *TransferService) History(ctx context.Context, userID string, limit int) ([]models.DataTransfer, error) {
+	if limit < 1 || limit > maxTransferHistory {
+		return nil, ErrInvalidTransferLimit
+	}
+
+	return s.repo.GetTransfers(ctx, userID, limit)
+}
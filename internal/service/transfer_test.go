@@ -0,0 +1,67 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockTransferRepository implements repository.TransferRepository
+type MockTransferRepository struct {
+	mock.Mock
+}
+
+func (m *MockTransferRepository) SaveTransfer(ctx context.Context, transfer *models.DataTransfer) error {
+	args := m.Called(ctx, transfer)
+	return args.Error(0)
+}
+
+func (m *MockTransferRepository) GetTransfers(ctx context.Context, userID string, limit int) ([]models.DataTransfer, error) {
+	args := m.Called(ctx, userID, limit)
+	return args.Get(0).([]models.DataTransfer), args.Error(1)
+}
+
+func TestTransferRecord(t *testing.T) {
+	mockLogger = new(MockLogger)
+	repo := new(MockTransferRepository)
+	service := NewTransferService(repo, mockLogger)
+
+	repo.On("SaveTransfer", mock.Anything, mock.MatchedBy(func(tr *models.DataTransfer) bool {
+		return tr.UserID == "user1" && tr.Direction == models.TransferImport && tr.Format == "json" &&
+			tr.Status == models.TransferCompleted && tr.Items == 3 && tr.Error == "" && tr.ID != ""
+	})).Return(nil).Once()
+	service.Record(context.Background(), "user1", models.TransferImport, "json", 3, time.Now(), nil)
+
+	repo.On("SaveTransfer", mock.Anything, mock.MatchedBy(func(tr *models.DataTransfer) bool {
+		return tr.Direction == models.TransferExport && tr.Status == models.TransferFailed && tr.Error == "db down"
+	})).Return(errors.New("insert failed")).Once()
+	mockLogger.On("Error", "Failed to save data transfer", mock.Anything).Return().Once()
+	service.Record(context.Background(), "user1", models.TransferExport, "json", 0, time.Now(), errors.New("db down"))
+
+	repo.AssertExpectations(t)
+	mockLogger.AssertExpectations(t)
+}
+
+func TestTransferHistory(t *testing.T) {
+	mockLogger = new(MockLogger)
+	repo := new(MockTransferRepository)
+	service := NewTransferService(repo, mockLogger)
+
+	_, err := service.History(context.Background(), "user1", 0)
+	assert.Equal(t, ErrInvalidTransferLimit, err)
+	_, err = service.History(context.Background(), "user1", 201)
+	assert.Equal(t, ErrInvalidTransferLimit, err)
+
+	expected := []models.DataTransfer{{ID: "t1", Direction: models.TransferExport, Items: 5}}
+	repo.On("GetTransfers", mock.Anything, "user1", 50).Return(expected, nil).Once()
+
+	got, err := service.History(context.Background(), "user1", 50)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, got)
+	repo.AssertExpectations(t)
+}
@@ -0,0 +1,274 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/mail"
+	"net/url"
+
+	"github.com/google/uuid"
+	"github.com/jmoloko/taskmange/internal/clock"
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/jmoloko/taskmange/internal/domain/repository"
+	domainService "github.com/jmoloko/taskmange/internal/domain/service"
+	"github.com/jmoloko/taskmange/internal/logger"
+	"github.com/jmoloko/taskmange/internal/metrics"
+	"github.com/jmoloko/taskmange/internal/notification"
+	"github.com/jmoloko/taskmange/internal/trigger"
+)
+
+// максимальное число триггеров у одного пользователя
+const maxTriggersPerUser = 50
+
+var (
+	ErrInvalidTrigger     = errors.New("invalid trigger")
+	ErrTriggerNotFound    = errors.New("trigger not found")
+	ErrActionUnavailable  = errors.New("trigger action is not available")
+	ErrTooManyTriggers    = errors.New("too many triggers")
+	ErrTriggerHasNoSecret = errors.New("only webhook triggers have a signing secret")
+)
+
+// Сервис пользовательских триггеров
+type TriggerService struct {
+	repo    repository.TriggerRepository
+	senders map[models.TriggerActionType]domainService.TriggerActionSender
+	quota   *QuotaService
+	clock   clock.Clock
+	logger  logger.Logger
+}
+
+// NewTriggerService создает новый экземпляр TriggerService.
+// senders — доступные в развертывании действия, триггеры с остальными действиями создать нельзя.
+// quota может быть nil, тогда о приближении к лимиту триггеров не предупреждаем
+func NewTriggerService(repo repository.TriggerRepository, senders map[models.TriggerActionType]domainService.TriggerActionSender, quota *QuotaService, logger logger.Logger) *TriggerService {
+	return &TriggerService{
+		repo:    repo,
+		senders: senders,
+		quota:   quota,
+		clock:   clock.System,
+		logger:  logger,
+	}
+}
+
+// создание триггера
+func (s *TriggerService) Create(ctx context.Context, userID string, req models.TriggerRequest) (*models.Trigger, error) {
+	if err := s.validate(req); err != nil {
+		return nil, err
+	}
+
+	existing, err := s.repo.GetTriggers(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) >= maxTriggersPerUser {
+		return nil, ErrTooManyTriggers
+	}
+
+	enabled := true
+	if req.Enabled != nil {
+		enabled = *req.Enabled
+	}
+
+	t := &models.Trigger{
+		ID:      uuid.New().String(),
+		UserID:  userID,
+		On:      req.On,
+		Filter:  req.Filter,
+		Action:  req.Action,
+		Enabled: enabled,
+	}
+	if t.Action.Type == models.TriggerActionWebhook {
+		if t.Secret, err = newWebhookSecret(); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.repo.CreateTrigger(ctx, t); err != nil {
+		return nil, err
+	}
+	s.quota.Observe(ctx, userID, models.QuotaTriggers, len(existing), len(existing)+1, maxTriggersPerUser)
+
+	return t, nil
+}
+
+// список триггеров пользователя
+func (s *TriggerService) List(ctx context.Context, userID string) ([]models.Trigger, error) {
+	triggers, err := s.repo.GetTriggers(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if triggers == nil {
+		triggers = []models.Trigger{}
+	}
+	// секрет показывается только при создании и ротации
+	for i := range triggers {
+		triggers[i].Secret = ""
+	}
+
+	return triggers, nil
+}
+
+// RotateSecret выдает webhook-триггеру новый секрет подписи. Предыдущий секрет
+// продолжает подписывать запросы models.WebhookSecretGrace, чтобы получатель успел обновить ключ
+func (s *TriggerService) RotateSecret(ctx context.Context, userID, triggerID string) (*models.WebhookSecret, error) {
+	triggers, err := s.repo.GetTriggers(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var found *models.Trigger
+	for i := range triggers {
+		if triggers[i].ID == triggerID {
+			found = &triggers[i]
+			break
+		}
+	}
+	if found == nil {
+		return nil, ErrTriggerNotFound
+	}
+	if found.Action.Type != models.TriggerActionWebhook {
+		return nil, ErrTriggerHasNoSecret
+	}
+
+	secret, err := newWebhookSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	rotatedAt := s.clock.Now()
+	if err := s.repo.RotateTriggerSecret(ctx, userID, triggerID, secret, rotatedAt); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrTriggerNotFound
+		}
+		return nil, err
+	}
+
+	s.logger.Info("Trigger secret rotated", map[string]interface{}{
+		"user_id":    userID,
+		"trigger_id": triggerID,
+	})
+
+	return &models.WebhookSecret{
+		Secret:                  secret,
+		PreviousSecretExpiresAt: rotatedAt.Add(models.WebhookSecretGrace),
+	}, nil
+}
+
+// удаление триггера пользователя
+func (s *TriggerService) Delete(ctx context.Context, userID, triggerID string) error {
+	if err := s.repo.DeleteTrigger(ctx, userID, triggerID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrTriggerNotFound
+		}
+		return err
+	}
+
+	return nil
+}
+
+// HandleEvent выполняет действия триггеров пользователя, подходящих под событие.
+// Подписывается на конвейер событий задач
+func (s *TriggerService) HandleEvent(ctx context.Context, event models.TaskEvent) error {
+	triggers, err := s.repo.GetEnabledTriggers(ctx, event.UserID, event.Type)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, t := range triggers {
+		filter, err := trigger.ParseFilter(t.Filter)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("trigger %s: %w", t.ID, err))
+			continue
+		}
+
+		// пакетное событие получает только задачи, подходящие под фильтр триггера
+		matched := event
+		if len(event.Tasks) > 0 {
+			matched.Tasks = nil
+			for _, task := range event.Tasks {
+				if filter.Match(task) {
+					matched.Tasks = append(matched.Tasks, task)
+				}
+			}
+			if len(matched.Tasks) == 0 {
+				continue
+			}
+		} else if !filter.Match(event.Task) {
+			continue
+		}
+
+		sender, ok := s.senders[t.Action.Type]
+		if !ok {
+			s.logger.Warn("Trigger action is not available", map[string]interface{}{
+				"trigger_id": t.ID,
+				"action":     t.Action.Type,
+			})
+			continue
+		}
+
+		inFlight := metrics.TriggerDeliveriesInFlight.WithLabelValues(string(t.Action.Type))
+		inFlight.Inc()
+		err = sender.Send(ctx, t, matched)
+		inFlight.Dec()
+		if err != nil {
+			metrics.TriggerExecutionsTotal.WithLabelValues(string(t.Action.Type), "error", metrics.Tenant(event.UserID)).Inc()
+			errs = append(errs, fmt.Errorf("trigger %s: %w", t.ID, err))
+			continue
+		}
+
+		metrics.TriggerExecutionsTotal.WithLabelValues(string(t.Action.Type), "success", metrics.Tenant(event.UserID)).Inc()
+	}
+
+	return errors.Join(errs...)
+}
+
+// validate проверяет событие, фильтр и адрес действия
+func (s *TriggerService) validate(req models.TriggerRequest) error {
+	if !req.On.IsValid() {
+		return fmt.Errorf("%w: unknown event %q", ErrInvalidTrigger, req.On)
+	}
+
+	if _, err := trigger.ParseFilter(req.Filter); err != nil {
+		return fmt.Errorf("%w: filter: %v", ErrInvalidTrigger, err)
+	}
+
+	switch req.Action.Type {
+	case models.TriggerActionWebhook, models.TriggerActionSlack:
+		target, err := url.Parse(req.Action.Target)
+		if err != nil || target.Host == "" || (target.Scheme != "https" && target.Scheme != "http") {
+			return fmt.Errorf("%w: action target must be an http(s) URL", ErrInvalidTrigger)
+		}
+		if req.Action.Type == models.TriggerActionSlack && target.Scheme != "https" {
+			return fmt.Errorf("%w: slack webhook URL must use https", ErrInvalidTrigger)
+		}
+		if err := notification.ValidateTarget(req.Action.Target); err != nil {
+			return fmt.Errorf("%w: action target: %v", ErrInvalidTrigger, err)
+		}
+	case models.TriggerActionEmail:
+		if _, err := mail.ParseAddress(req.Action.Target); err != nil {
+			return fmt.Errorf("%w: action target must be an email address", ErrInvalidTrigger)
+		}
+	default:
+		return fmt.Errorf("%w: unknown action %q", ErrInvalidTrigger, req.Action.Type)
+	}
+
+	if _, ok := s.senders[req.Action.Type]; !ok {
+		return ErrActionUnavailable
+	}
+
+	return nil
+}
+
+// newWebhookSecret случайный секрет подписи webhook с префиксом whsec_
+func newWebhookSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate webhook secret: %w", err)
+	}
+	return "whsec_" + hex.EncodeToString(b), nil
+}
@@ -0,0 +1,207 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	domainService "github.com/jmoloko/taskmange/internal/domain/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockTriggerRepository implements repository.TriggerRepository
+type MockTriggerRepository struct {
+	mock.Mock
+}
+
+func (m *MockTriggerRepository) CreateTrigger(ctx context.Context, trigger *models.Trigger) error {
+	args := m.Called(ctx, trigger)
+	return args.Error(0)
+}
+
+func (m *MockTriggerRepository) DeleteTrigger(ctx context.Context, userID, triggerID string) error {
+	args := m.Called(ctx, userID, triggerID)
+	return args.Error(0)
+}
+
+func (m *MockTriggerRepository) GetTriggers(ctx context.Context, userID string) ([]models.Trigger, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]models.Trigger), args.Error(1)
+}
+
+func (m *MockTriggerRepository) GetEnabledTriggers(ctx context.Context, userID string, event models.EventType) ([]models.Trigger, error) {
+	args := m.Called(ctx, userID, event)
+	return args.Get(0).([]models.Trigger), args.Error(1)
+}
+
+func (m *MockTriggerRepository) RotateTriggerSecret(ctx context.Context, userID, triggerID, secret string, rotatedAt time.Time) error {
+	args := m.Called(ctx, userID, triggerID, secret, rotatedAt)
+	return args.Error(0)
+}
+
+// MockActionSender implements domainService.TriggerActionSender
+type MockActionSender struct {
+	mock.Mock
+}
+
+func (m *MockActionSender) Send(ctx context.Context, trigger models.Trigger, event models.TaskEvent) error {
+	args := m.Called(ctx, trigger.ID, event)
+	return args.Error(0)
+}
+
+func TestTriggerHandleEvent(t *testing.T) {
+	mockTriggerRepo := new(MockTriggerRepository)
+	mockSender := new(MockActionSender)
+	mockLogger = new(MockLogger)
+	service := NewTriggerService(mockTriggerRepo, map[models.TriggerActionType]domainService.TriggerActionSender{
+		models.TriggerActionWebhook: mockSender,
+	}, nil, mockLogger)
+
+	event := models.TaskEvent{
+		Type:   models.EventTaskCompleted,
+		UserID: "user1",
+		Task:   models.Task{ID: "task1", Priority: models.PriorityHigh, Status: models.StatusDone},
+	}
+
+	mockTriggerRepo.On("GetEnabledTriggers", mock.Anything, "user1", models.EventTaskCompleted).Return([]models.Trigger{
+		{ID: "t1", Filter: "priority == high", Action: models.TriggerAction{Type: models.TriggerActionWebhook, Target: "https://a.example"}},
+		{ID: "t2", Filter: "priority == low", Action: models.TriggerAction{Type: models.TriggerActionWebhook, Target: "https://b.example"}},
+	}, nil)
+	mockSender.On("Send", mock.Anything, "t1", event).Return(nil).Once()
+
+	err := service.HandleEvent(context.Background(), event)
+	assert.NoError(t, err)
+
+	mockTriggerRepo.AssertExpectations(t)
+	mockSender.AssertExpectations(t)
+}
+
+func TestTriggerHandleBatchEvent(t *testing.T) {
+	mockTriggerRepo := new(MockTriggerRepository)
+	mockSender := new(MockActionSender)
+	mockLogger = new(MockLogger)
+	service := NewTriggerService(mockTriggerRepo, map[models.TriggerActionType]domainService.TriggerActionSender{
+		models.TriggerActionWebhook: mockSender,
+	}, nil, mockLogger)
+
+	high := models.Task{ID: "task1", Priority: models.PriorityHigh, Status: models.StatusDone}
+	low := models.Task{ID: "task2", Priority: models.PriorityLow, Status: models.StatusDone}
+	event := models.TaskEvent{
+		Type:   models.EventTasksCompleted,
+		UserID: "user1",
+		Tasks:  []models.Task{high, low},
+	}
+
+	mockTriggerRepo.On("GetEnabledTriggers", mock.Anything, "user1", models.EventTasksCompleted).Return([]models.Trigger{
+		{ID: "t1", Filter: "priority == high", Action: models.TriggerAction{Type: models.TriggerActionWebhook, Target: "https://a.example"}},
+		{ID: "t2", Filter: "status == pending", Action: models.TriggerAction{Type: models.TriggerActionWebhook, Target: "https://b.example"}},
+	}, nil)
+	mockSender.On("Send", mock.Anything, "t1", mock.MatchedBy(func(e models.TaskEvent) bool {
+		return len(e.Tasks) == 1 && e.Tasks[0].ID == "task1"
+	})).Return(nil).Once()
+
+	assert.NoError(t, service.HandleEvent(context.Background(), event))
+	// исходное событие не меняется
+	assert.Len(t, event.Tasks, 2)
+
+	mockSender.AssertExpectations(t)
+}
+
+func TestTriggerCreateValidation(t *testing.T) {
+	mockTriggerRepo := new(MockTriggerRepository)
+	mockLogger = new(MockLogger)
+	service := NewTriggerService(mockTriggerRepo, map[models.TriggerActionType]domainService.TriggerActionSender{
+		models.TriggerActionWebhook: new(MockActionSender),
+	}, nil, mockLogger)
+
+	tests := []struct {
+		name string
+		req  models.TriggerRequest
+		err  error
+	}{
+		{
+			name: "unknown event",
+			req:  models.TriggerRequest{On: "task.archived", Action: models.TriggerAction{Type: models.TriggerActionWebhook, Target: "https://a.example"}},
+			err:  ErrInvalidTrigger,
+		},
+		{
+			name: "bad filter",
+			req:  models.TriggerRequest{On: models.EventTaskCreated, Filter: "owner == me", Action: models.TriggerAction{Type: models.TriggerActionWebhook, Target: "https://a.example"}},
+			err:  ErrInvalidTrigger,
+		},
+		{
+			name: "bad target",
+			req:  models.TriggerRequest{On: models.EventTaskCreated, Action: models.TriggerAction{Type: models.TriggerActionWebhook, Target: "ftp://a.example"}},
+			err:  ErrInvalidTrigger,
+		},
+		{
+			name: "internal target",
+			req:  models.TriggerRequest{On: models.EventTaskCreated, Action: models.TriggerAction{Type: models.TriggerActionWebhook, Target: "http://169.254.169.254/latest/meta-data"}},
+			err:  ErrInvalidTrigger,
+		},
+		{
+			name: "action not configured",
+			req:  models.TriggerRequest{On: models.EventTaskCreated, Action: models.TriggerAction{Type: models.TriggerActionEmail, Target: "user@example.com"}},
+			err:  ErrActionUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.Create(context.Background(), "user1", tt.req)
+			assert.ErrorIs(t, err, tt.err)
+		})
+	}
+}
+
+func TestTriggerWebhookSecret(t *testing.T) {
+	mockTriggerRepo := new(MockTriggerRepository)
+	mockLogger = new(MockLogger)
+	service := NewTriggerService(mockTriggerRepo, map[models.TriggerActionType]domainService.TriggerActionSender{
+		models.TriggerActionWebhook: new(MockActionSender),
+		models.TriggerActionSlack:   new(MockActionSender),
+	}, nil, mockLogger)
+	ctx := context.Background()
+
+	mockTriggerRepo.On("GetTriggers", mock.Anything, "user1").Return([]models.Trigger{}, nil).Once()
+	mockTriggerRepo.On("CreateTrigger", mock.Anything, mock.AnythingOfType("*models.Trigger")).Return(nil).Once()
+	created, err := service.Create(ctx, "user1", models.TriggerRequest{
+		On:     models.EventTaskCreated,
+		Action: models.TriggerAction{Type: models.TriggerActionWebhook, Target: "https://a.example"},
+	})
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(created.Secret, "whsec_"))
+
+	stored := []models.Trigger{
+		{ID: "t1", Action: models.TriggerAction{Type: models.TriggerActionWebhook, Target: "https://a.example"}, Secret: created.Secret},
+		{ID: "t2", Action: models.TriggerAction{Type: models.TriggerActionSlack, Target: "https://hooks.slack.com/x"}},
+	}
+
+	// в списке секрет не показывается
+	mockTriggerRepo.On("GetTriggers", mock.Anything, "user1").Return(stored, nil).Once()
+	list, err := service.List(ctx, "user1")
+	require.NoError(t, err)
+	assert.Empty(t, list[0].Secret)
+
+	mockTriggerRepo.On("GetTriggers", mock.Anything, "user1").Return(stored, nil)
+	mockTriggerRepo.On("RotateTriggerSecret", mock.Anything, "user1", "t1", mock.MatchedBy(func(secret string) bool {
+		return strings.HasPrefix(secret, "whsec_") && secret != created.Secret
+	}), mock.Anything).Return(nil).Once()
+	mockLogger.On("Info", "Trigger secret rotated", mock.Anything).Return().Once()
+
+	rotated, err := service.RotateSecret(ctx, "user1", "t1")
+	require.NoError(t, err)
+	assert.NotEqual(t, created.Secret, rotated.Secret)
+	assert.WithinDuration(t, time.Now().Add(models.WebhookSecretGrace), rotated.PreviousSecretExpiresAt, time.Minute)
+
+	_, err = service.RotateSecret(ctx, "user1", "t2")
+	assert.ErrorIs(t, err, ErrTriggerHasNoSecret)
+	_, err = service.RotateSecret(ctx, "user1", "missing")
+	assert.ErrorIs(t, err, ErrTriggerNotFound)
+
+	mockTriggerRepo.AssertExpectations(t)
+}
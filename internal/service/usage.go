@@ -0,0 +1,93 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jmoloko/taskmange/internal/clock"
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/jmoloko/taskmange/internal/domain/repository"
+	"github.com/jmoloko/taskmange/internal/logger"
+)
+
+const (
+	// максимальный период отчета об использовании API — 90 дней
+	maxUsageDays = 90
+	// максимальное число пользователей в отчете
+	maxUsageUsers = 100
+)
+
+var ErrInvalidUsageQuery = errors.New("invalid usage query")
+
+// UsageService учет запросов пользователей к API: счетчики в Redis за текущие сутки
+// периодически переносятся в Postgres, откуда строится отчет для администраторов
+type UsageService struct {
+	counter repository.UsageCounter
+	repo    repository.UsageRepository
+	clock   clock.Clock
+	logger  logger.Logger
+}
+
+// NewUsageService создает новый экземпляр UsageService
+func NewUsageService(counter repository.UsageCounter, repo repository.UsageRepository, logger logger.Logger) *UsageService {
+	return &UsageService{
+		counter: counter,
+		repo:    repo,
+		clock:   clock.System,
+		logger:  logger,
+	}
+}
+
+// Record учитывает запрос пользователя. Ошибка счетчика только логируется, чтобы не ломать сам запрос
+func (s *UsageService) Record(ctx context.Context, userID string, failed bool) {
+	if err := s.counter.IncrementUsage(ctx, userID, s.clock.Now().UTC(), failed); err != nil {
+		s.logger.Error("Failed to record API usage", map[string]interface{}{
+			"user_id": userID,
+			"error":   err.Error(),
+		})
+	}
+}
+
+// Rollup переносит счетчики за текущий и предыдущий день (UTC) в Postgres.
+// Вызывается фоновым воркером; предыдущий день дописывается, чтобы не потерять запросы перед полуночью
+func (s *UsageService) Rollup(ctx context.Context) error {
+	today := s.clock.Now().UTC().Truncate(24 * time.Hour)
+
+	var errs []error
+	for _, day := range []time.Time{today.AddDate(0, 0, -1), today} {
+		usage, err := s.counter.GetUsage(ctx, day)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if len(usage) == 0 {
+			continue
+		}
+
+		if err := s.repo.SaveUsage(ctx, usage); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// TopConsumers пользователи с наибольшим числом запросов за последние days дней, включая текущий
+func (s *UsageService) TopConsumers(ctx context.Context, days, limit int) (models.UsageReport, error) {
+	if days < 1 || days > maxUsageDays || limit < 1 || limit > maxUsageUsers {
+		return models.UsageReport{}, ErrInvalidUsageQuery
+	}
+
+	since := s.clock.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, -(days - 1))
+	users, err := s.repo.GetTopUsage(ctx, since, limit)
+	if err != nil {
+		return models.UsageReport{}, err
+	}
+
+	if users == nil {
+		users = []models.UserUsage{}
+	}
+
+	return models.UsageReport{Days: days, Users: users}, nil
+}
@@ -0,0 +1,97 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockUsageCounter implements repository.UsageCounter
+type MockUsageCounter struct {
+	mock.Mock
+}
+
+func (m *MockUsageCounter) IncrementUsage(ctx context.Context, userID string, day time.Time, failed bool) error {
+	args := m.Called(ctx, userID, day, failed)
+	return args.Error(0)
+}
+
+func (m *MockUsageCounter) GetUsage(ctx context.Context, day time.Time) ([]models.UsageCount, error) {
+	args := m.Called(ctx, day)
+	return args.Get(0).([]models.UsageCount), args.Error(1)
+}
+
+// MockUsageRepository implements repository.UsageRepository
+type MockUsageRepository struct {
+	mock.Mock
+}
+
+func (m *MockUsageRepository) SaveUsage(ctx context.Context, usage []models.UsageCount) error {
+	args := m.Called(ctx, usage)
+	return args.Error(0)
+}
+
+func (m *MockUsageRepository) GetTopUsage(ctx context.Context, since time.Time, limit int) ([]models.UserUsage, error) {
+	args := m.Called(ctx, since, limit)
+	return args.Get(0).([]models.UserUsage), args.Error(1)
+}
+
+func TestUsageRecord(t *testing.T) {
+	mockLogger = new(MockLogger)
+	counter := new(MockUsageCounter)
+	service := NewUsageService(counter, new(MockUsageRepository), mockLogger)
+
+	counter.On("IncrementUsage", mock.Anything, "user1", mock.AnythingOfType("time.Time"), true).Return(errors.New("redis down")).Once()
+	mockLogger.On("Error", "Failed to record API usage", mock.Anything).Return().Once()
+
+	service.Record(context.Background(), "user1", true)
+
+	counter.AssertExpectations(t)
+	mockLogger.AssertExpectations(t)
+}
+
+func TestUsageRollup(t *testing.T) {
+	mockLogger = new(MockLogger)
+	counter := new(MockUsageCounter)
+	repo := new(MockUsageRepository)
+	service := NewUsageService(counter, repo, mockLogger)
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	yesterday := today.AddDate(0, 0, -1)
+	usage := []models.UsageCount{{UserID: "user1", Day: today, Requests: 10, Errors: 2}}
+
+	counter.On("GetUsage", mock.Anything, yesterday).Return([]models.UsageCount{}, nil).Once()
+	counter.On("GetUsage", mock.Anything, today).Return(usage, nil).Once()
+	repo.On("SaveUsage", mock.Anything, usage).Return(nil).Once()
+
+	assert.NoError(t, service.Rollup(context.Background()))
+
+	counter.AssertExpectations(t)
+	repo.AssertExpectations(t)
+}
+
+func TestUsageTopConsumers(t *testing.T) {
+	mockLogger = new(MockLogger)
+	repo := new(MockUsageRepository)
+	service := NewUsageService(new(MockUsageCounter), repo, mockLogger)
+	ctx := context.Background()
+
+	for _, q := range [][2]int{{0, 20}, {91, 20}, {7, 0}, {7, 101}} {
+		_, err := service.TopConsumers(ctx, q[0], q[1])
+		assert.Equal(t, ErrInvalidUsageQuery, err)
+	}
+
+	since := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, -6)
+	users := []models.UserUsage{{UserID: "user1", Requests: 100, Errors: 5, ErrorRate: 0.05}}
+	repo.On("GetTopUsage", mock.Anything, since, 20).Return(users, nil).Once()
+
+	report, err := service.TopConsumers(ctx, 7, 20)
+	assert.NoError(t, err)
+	assert.Equal(t, models.UsageReport{Days: 7, Users: users}, report)
+	repo.AssertExpectations(t)
+}
@@ -0,0 +1,149 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/jmoloko/taskmange/internal/domain/repository"
+	domainService "github.com/jmoloko/taskmange/internal/domain/service"
+	"github.com/jmoloko/taskmange/internal/logger"
+)
+
+var (
+	ErrSelfDeactivation    = errors.New("cannot deactivate yourself")
+	ErrDeactivationReason  = errors.New("deactivation reason is required")
+	ErrUserAlreadyInStatus = errors.New("user already has this status")
+)
+
+// UserStatusService деактивация учетных записей администратором. Деактивированный пользователь
+// не может войти, его токены отклоняются, задачи и остальные данные сохраняются
+type UserStatusService struct {
+	users  repository.UserRepository
+	cache  repository.UserStatusCache
+	audit  *AuditService
+	logger logger.Logger
+}
+
+// NewUserStatusService создает новый экземпляр UserStatusService.
+// cache может быть nil, тогда статус читается из БД на каждом запросе
+func NewUserStatusService(users repository.UserRepository, cache repository.UserStatusCache, audit *AuditService, logger logger.Logger) *UserStatusService {
+	return &UserStatusService{
+		users:  users,
+		cache:  cache,
+		audit:  audit,
+		logger: logger,
+	}
+}
+
+// Active проверяет, что учетная запись не деактивирована. Вызывается на каждом запросе,
+// поэтому читает через кэш; ошибки кэша не мешают проверке, статус тогда берется из БД
+func (s *UserStatusService) Active(ctx context.Context, userID string) (bool, error) {
+	if s.cache != nil {
+		active, found, err := s.cache.GetUserActive(ctx, userID)
+		if err != nil {
+			s.logger.Error("Failed to get user status from cache", map[string]interface{}{
+				"user_id": userID,
+				"error":   err.Error(),
+			})
+		} else if found {
+			return active, nil
+		}
+	}
+
+	user, err := s.users.GetByID(ctx, userID)
+	if err != nil {
+		return false, ErrUserNotFound
+	}
+
+	s.cacheStatus(ctx, userID, user.Active)
+	return user.Active, nil
+}
+
+// checkUserActive возвращает ErrUserDeactivated для деактивированной учетной записи.
+// Нужна запросам, которые действуют от имени пользователя без JWT (webhook по токену или подписи),
+// statuses nil — деактивация не проверяется
+func checkUserActive(ctx context.Context, statuses domainService.UserStatusChecker, userID string) error {
+	if statuses == nil {
+		return nil
+	}
+
+	active, err := statuses.Active(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !active {
+		return ErrUserDeactivated
+	}
+
+	return nil
+}
+
+// Deactivate деактивирует пользователя userID от имени администратора adminID
+func (s *UserStatusService) Deactivate(ctx context.Context, adminID, userID, reason string) error {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return ErrDeactivationReason
+	}
+	if adminID == userID {
+		return ErrSelfDeactivation
+	}
+
+	return s.setActive(ctx, adminID, userID, false, reason)
+}
+
+// Reactivate снимает деактивацию, пользователь снова может войти
+func (s *UserStatusService) Reactivate(ctx context.Context, adminID, userID string) error {
+	return s.setActive(ctx, adminID, userID, true, "")
+}
+
+func (s *UserStatusService) setActive(ctx context.Context, adminID, userID string, active bool, reason string) error {
+	user, err := s.users.GetByID(ctx, userID)
+	if err != nil {
+		return ErrUserNotFound
+	}
+	if user.Active == active {
+		return ErrUserAlreadyInStatus
+	}
+
+	if err := s.users.SetActive(ctx, userID, active); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrUserNotFound
+		}
+		return err
+	}
+	// новый статус сразу в кэш, чтобы токены перестали приниматься на всех экземплярах
+	s.cacheStatus(ctx, userID, active)
+
+	event := models.AuditEvent{
+		Action:  models.AuditUserReactivated,
+		ActorID: adminID,
+		Target:  userID,
+	}
+	if !active {
+		event.Action = models.AuditUserDeactivated
+		event.Details = map[string]string{"reason": reason}
+	}
+	s.audit.Record(ctx, event)
+	s.logger.Info("User status changed", map[string]interface{}{
+		"user_id":  userID,
+		"admin_id": adminID,
+		"active":   active,
+	})
+
+	return nil
+}
+
+func (s *UserStatusService) cacheStatus(ctx context.Context, userID string, active bool) {
+	if s.cache == nil {
+		return
+	}
+
+	if err := s.cache.SetUserActive(ctx, userID, active); err != nil {
+		s.logger.Error("Failed to cache user status", map[string]interface{}{
+			"user_id": userID,
+			"error":   err.Error(),
+		})
+	}
+}
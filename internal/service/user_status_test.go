@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// memoryUserStatuses implements repository.UserStatusCache
+type memoryUserStatuses map[string]bool
+
+func (c memoryUserStatuses) GetUserActive(ctx context.Context, userID string) (bool, bool, error) {
+	active, found := c[userID]
+	return active, found, nil
+}
+
+func (c memoryUserStatuses) SetUserActive(ctx context.Context, userID string, active bool) error {
+	c[userID] = active
+	return nil
+}
+
+func TestUserStatus(t *testing.T) {
+	logger := new(MockLogger)
+	logger.On("Info", mock.Anything, mock.Anything).Return()
+	users := new(MockUserRepository)
+	audit := &memoryAudit{}
+	statuses := memoryUserStatuses{}
+	service := NewUserStatusService(users, statuses, NewAuditService(audit, logger), logger)
+	ctx := context.Background()
+
+	// первый запрос читает БД, следующие — кэш
+	users.On("GetByID", mock.Anything, "user1").Return(&models.User{ID: "user1", Active: true}, nil).Once()
+	for i := 0; i < 2; i++ {
+		active, err := service.Active(ctx, "user1")
+		require.NoError(t, err)
+		assert.True(t, active)
+	}
+
+	assert.Equal(t, ErrDeactivationReason, service.Deactivate(ctx, "admin1", "user1", " "))
+	assert.Equal(t, ErrSelfDeactivation, service.Deactivate(ctx, "admin1", "admin1", "ticket"))
+
+	users.On("GetByID", mock.Anything, "user1").Return(&models.User{ID: "user1", Active: true}, nil).Once()
+	users.On("SetActive", mock.Anything, "user1", false).Return(nil).Once()
+	require.NoError(t, service.Deactivate(ctx, "admin1", "user1", "ticket #4321"))
+
+	// деактивация сразу видна через кэш, без повторного чтения БД
+	active, err := service.Active(ctx, "user1")
+	require.NoError(t, err)
+	assert.False(t, active)
+
+	users.On("GetByID", mock.Anything, "user1").Return(&models.User{ID: "user1"}, nil).Once()
+	assert.Equal(t, ErrUserAlreadyInStatus, service.Deactivate(ctx, "admin1", "user1", "again"))
+
+	users.On("GetByID", mock.Anything, "user1").Return(&models.User{ID: "user1"}, nil).Once()
+	users.On("SetActive", mock.Anything, "user1", true).Return(nil).Once()
+	require.NoError(t, service.Reactivate(ctx, "admin1", "user1"))
+	assert.True(t, statuses["user1"])
+
+	require.Len(t, audit.events, 2)
+	assert.Equal(t, models.AuditUserDeactivated, audit.events[0].Action)
+	assert.Equal(t, "user1", audit.events[0].Target)
+	assert.Equal(t, "ticket #4321", audit.events[0].Details["reason"])
+	assert.Equal(t, models.AuditUserReactivated, audit.events[1].Action)
+	users.AssertExpectations(t)
+}
+
+func TestLoginDeactivated(t *testing.T) {
+	users := new(MockUserRepository)
+	auth := NewAuthService(users, nil, nil, nil, new(MockLogger), "secret")
+	ctx := context.Background()
+
+	hash, err := bcrypt.GenerateFromPassword([]byte("password"), bcrypt.MinCost)
+	require.NoError(t, err)
+	users.On("GetByEmail", mock.Anything, "user@example.com").
+		Return(&models.User{ID: "user1", Email: "user@example.com", PasswordHash: string(hash)}, nil)
+
+	// неверный пароль не раскрывает, что учетная запись деактивирована
+	_, err = auth.Login(ctx, models.LoginRequest{Email: "user@example.com", Password: "wrong"})
+	assert.Equal(t, ErrInvalidCredentials, err)
+
+	_, err = auth.Login(ctx, models.LoginRequest{Email: "user@example.com", Password: "password"})
+	assert.Equal(t, ErrUserDeactivated, err)
+}
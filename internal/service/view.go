@@ -0,0 +1,165 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jmoloko/taskmange/internal/clock"
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/jmoloko/taskmange/internal/domain/repository"
+	domainService "github.com/jmoloko/taskmange/internal/domain/service"
+	"github.com/jmoloko/taskmange/internal/logger"
+)
+
+// максимальная длина окна списка "Предстоящие" в днях
+const maxUpcomingDays = 30
+
+var (
+	ErrInvalidTimezone      = errors.New("invalid timezone")
+	ErrInvalidUpcomingRange = errors.New("invalid upcoming range")
+)
+
+// TaskViewService виртуальные списки задач "Сегодня" и "Предстоящие" и матрица Эйзенхауэра.
+// Границы дней считаются в часовом поясе пользователя, задачи упорядочены по оценке срочности
+type TaskViewService struct {
+	tasks  domainService.TaskService
+	prefs  repository.NotificationPreferencesRepository
+	matrix repository.MatrixSettingsRepository
+	cache  repository.TaskViewCache
+	logger logger.Logger
+	clock  clock.Clock
+}
+
+// NewTaskViewService создает новый экземпляр TaskViewService. cache может быть nil — списки не кэшируются
+func NewTaskViewService(tasks domainService.TaskService, prefs repository.NotificationPreferencesRepository, matrix repository.MatrixSettingsRepository, cache repository.TaskViewCache, logger logger.Logger) *TaskViewService {
+	return &TaskViewService{
+		tasks:  tasks,
+		prefs:  prefs,
+		matrix: matrix,
+		cache:  cache,
+		logger: logger,
+		clock:  clock.System,
+	}
+}
+
+// Today невыполненные задачи со сроком до конца текущего дня, включая просроченные.
+// tz — часовой пояс IANA, пустой берется из настроек уведомлений пользователя
+func (s *TaskViewService) Today(ctx context.Context, userID, tz string) ([]models.Task, error) {
+	loc, err := s.location(ctx, userID, tz)
+	if err != nil {
+		return nil, err
+	}
+
+	start := startOfDay(s.clock.Now().In(loc))
+	end := start.AddDate(0, 0, 1)
+
+	key := fmt.Sprintf("today:%s:%s", start.Format(time.DateOnly), loc)
+	return s.view(ctx, userID, key, models.TaskFilters{
+		UserID:    userID,
+		Sort:      models.SortSmart,
+		Open:      true,
+		DueBefore: &end,
+	})
+}
+
+// Upcoming невыполненные задачи со сроком в ближайшие days дней, начиная с текущего
+func (s *TaskViewService) Upcoming(ctx context.Context, userID string, days int, tz string) ([]models.Task, error) {
+	if days < 1 || days > maxUpcomingDays {
+		return nil, ErrInvalidUpcomingRange
+	}
+
+	loc, err := s.location(ctx, userID, tz)
+	if err != nil {
+		return nil, err
+	}
+
+	start := startOfDay(s.clock.Now().In(loc))
+	end := start.AddDate(0, 0, days)
+
+	key := fmt.Sprintf("upcoming:%d:%s:%s", days, start.Format(time.DateOnly), loc)
+	return s.view(ctx, userID, key, models.TaskFilters{
+		UserID:    userID,
+		Sort:      models.SortSmart,
+		Open:      true,
+		DueFrom:   &start,
+		DueBefore: &end,
+	})
+}
+
+// view список из кэша или из базы. Ошибки кэша только логируются
+func (s *TaskViewService) view(ctx context.Context, userID, key string, filters models.TaskFilters) ([]models.Task, error) {
+	if s.cache != nil {
+		tasks, ok, err := s.cache.GetTaskView(ctx, userID, key)
+		if err != nil {
+			s.logger.Warn("Failed to get task view from cache", map[string]interface{}{
+				"user_id": userID,
+				"view":    key,
+				"error":   err.Error(),
+			})
+		} else if ok {
+			// список из кэша мог устареть по просрочке, признак отмечается заново
+			return markOverdue(tasks, s.clock.Now()), nil
+		}
+	}
+
+	tasks, err := s.tasks.GetUserTasks(ctx, userID, filters)
+	if err != nil {
+		return nil, err
+	}
+	if tasks == nil {
+		tasks = []models.Task{}
+	}
+
+	if s.cache != nil {
+		if err := s.cache.SetTaskView(ctx, userID, key, tasks); err != nil {
+			s.logger.Warn("Failed to cache task view", map[string]interface{}{
+				"user_id": userID,
+				"view":    key,
+				"error":   err.Error(),
+			})
+		}
+	}
+
+	return tasks, nil
+}
+
+// location часовой пояс из запроса, иначе из настроек уведомлений, иначе UTC
+func (s *TaskViewService) location(ctx context.Context, userID, tz string) (*time.Location, error) {
+	return userLocation(ctx, s.prefs, s.logger, userID, tz)
+}
+
+// userLocation часовой пояс пользователя: tz из запроса, иначе из настроек уведомлений, иначе UTC
+func userLocation(ctx context.Context, prefs repository.NotificationPreferencesRepository, logger logger.Logger, userID, tz string) (*time.Location, error) {
+	if tz != "" {
+		loc, err := time.LoadLocation(tz)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidTimezone, tz)
+		}
+		return loc, nil
+	}
+
+	saved, err := prefs.GetNotificationPreferences(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if saved == nil || saved.Timezone == "" {
+		return time.UTC, nil
+	}
+
+	loc, err := time.LoadLocation(saved.Timezone)
+	if err != nil {
+		// настройки проверяются при сохранении, пояс мог исчезнуть из базы tzdata
+		logger.Warn("Unknown timezone in notification preferences", map[string]interface{}{
+			"user_id":  userID,
+			"timezone": saved.Timezone,
+		})
+		return time.UTC, nil
+	}
+	return loc, nil
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
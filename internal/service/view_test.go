@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jmoloko/taskmange/internal/clock"
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockTaskViewCache implements repository.TaskViewCache
+type MockTaskViewCache struct {
+	mock.Mock
+}
+
+func (m *MockTaskViewCache) GetTaskView(ctx context.Context, userID, key string) ([]models.Task, bool, error) {
+	args := m.Called(ctx, userID, key)
+	tasks, _ := args.Get(0).([]models.Task)
+	return tasks, args.Bool(1), args.Error(2)
+}
+
+func (m *MockTaskViewCache) SetTaskView(ctx context.Context, userID, key string, tasks []models.Task) error {
+	args := m.Called(ctx, userID, key, tasks)
+	return args.Error(0)
+}
+
+func (m *MockTaskViewCache) InvalidateTaskViews(ctx context.Context, userID string) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
+func newTestViewService(now time.Time) (*TaskViewService, *MockTaskRepository, *MockNotificationRepository, *MockTaskViewCache) {
+	repo := new(MockTaskRepository)
+	prefs := new(MockNotificationRepository)
+	views := new(MockTaskViewCache)
+	tasks := NewTaskService(repo, nil, nil, nil, nil, nil, nil, new(MockLogger)).(*TaskServiceImpl)
+	tasks.clock = clock.NewFake(now)
+	service := NewTaskViewService(tasks, prefs, &memoryMatrixSettings{}, views, new(MockLogger))
+	service.clock = clock.NewFake(now)
+	return service, repo, prefs, views
+}
+
+func TestTaskViewToday(t *testing.T) {
+	// 22:30 UTC — в Москве уже следующий день
+	now := time.Date(2024, 3, 10, 22, 30, 0, 0, time.UTC)
+	service, repo, prefs, views := newTestViewService(now)
+
+	prefs.On("GetNotificationPreferences", mock.Anything, "user1").
+		Return(&models.NotificationPreferences{Timezone: "Europe/Moscow"}, nil).Once()
+	views.On("GetTaskView", mock.Anything, "user1", "today:2024-03-11:Europe/Moscow").Return(nil, false, nil).Once()
+
+	tasks := []models.Task{{ID: "1", UserID: "user1", Priority: models.PriorityHigh}}
+	repo.On("GetAll", mock.Anything, mock.MatchedBy(func(f models.TaskFilters) bool {
+		return f.UserID == "user1" && f.Sort == models.SortSmart && f.Open && f.DueFrom == nil &&
+			f.DueBefore != nil && f.DueBefore.Equal(time.Date(2024, 3, 11, 21, 0, 0, 0, time.UTC))
+	})).Return(tasks, nil).Once()
+	views.On("SetTaskView", mock.Anything, "user1", "today:2024-03-11:Europe/Moscow", tasks).Return(nil).Once()
+
+	got, err := service.Today(context.Background(), "user1", "")
+	require.NoError(t, err)
+	assert.Equal(t, tasks, got)
+
+	// повторный запрос отдается из кэша
+	views.On("GetTaskView", mock.Anything, "user1", "today:2024-03-10:UTC").Return(tasks, true, nil).Once()
+	got, err = service.Today(context.Background(), "user1", "UTC")
+	require.NoError(t, err)
+	assert.Equal(t, tasks, got)
+
+	_, err = service.Today(context.Background(), "user1", "Mars/Olympus")
+	assert.True(t, errors.Is(err, ErrInvalidTimezone))
+
+	repo.AssertExpectations(t)
+	prefs.AssertExpectations(t)
+	views.AssertExpectations(t)
+}
+
+func TestTaskViewUpcoming(t *testing.T) {
+	now := time.Date(2024, 3, 10, 9, 0, 0, 0, time.UTC)
+	service, repo, prefs, views := newTestViewService(now)
+
+	_, err := service.Upcoming(context.Background(), "user1", 0, "")
+	assert.Equal(t, ErrInvalidUpcomingRange, err)
+	_, err = service.Upcoming(context.Background(), "user1", 31, "")
+	assert.Equal(t, ErrInvalidUpcomingRange, err)
+
+	prefs.On("GetNotificationPreferences", mock.Anything, "user1").Return(nil, nil).Once()
+	views.On("GetTaskView", mock.Anything, "user1", "upcoming:7:2024-03-10:UTC").Return(nil, false, errors.New("redis down")).Once()
+	service.logger.(*MockLogger).On("Warn", "Failed to get task view from cache", mock.Anything).Return().Once()
+
+	repo.On("GetAll", mock.Anything, mock.MatchedBy(func(f models.TaskFilters) bool {
+		return f.Open && f.Sort == models.SortSmart &&
+			f.DueFrom.Equal(time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC)) &&
+			f.DueBefore.Equal(time.Date(2024, 3, 17, 0, 0, 0, 0, time.UTC))
+	})).Return([]models.Task(nil), nil).Once()
+	views.On("SetTaskView", mock.Anything, "user1", "upcoming:7:2024-03-10:UTC", []models.Task{}).Return(nil).Once()
+
+	got, err := service.Upcoming(context.Background(), "user1", 7, "")
+	require.NoError(t, err)
+	assert.NotNil(t, got)
+	assert.Empty(t, got)
+
+	repo.AssertExpectations(t)
+	views.AssertExpectations(t)
+}
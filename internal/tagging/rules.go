@@ -0,0 +1,113 @@
+package tagging
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/jmoloko/taskmange/internal/domain/models"
+)
+
+// MaxPatternLength максимальная длина шаблона правила
+const MaxPatternLength = 500
+
+// Rule разобранное правило автотегирования.
+// Ключевые слова перечисляются через запятую и ищутся как подстроки без учета регистра.
+// Регулярные выражения используют синтаксис Go (RE2): время проверки линейно по длине текста,
+// поэтому пользовательский шаблон не может подвесить создание задачи
+type Rule struct {
+	models.TaggingRule
+	keywords []string
+	re       *regexp.Regexp
+}
+
+// Compile проверяет и разбирает правило
+func Compile(rule models.TaggingRule) (*Rule, error) {
+	if strings.TrimSpace(rule.Pattern) == "" {
+		return nil, fmt.Errorf("pattern is required")
+	}
+	if len(rule.Pattern) > MaxPatternLength {
+		return nil, fmt.Errorf("pattern is longer than %d characters", MaxPatternLength)
+	}
+
+	switch rule.Field {
+	case models.TaggingFieldTitle, models.TaggingFieldDescription, models.TaggingFieldAny:
+	default:
+		return nil, fmt.Errorf("unknown field %q", rule.Field)
+	}
+
+	compiled := &Rule{TaggingRule: rule}
+	switch rule.Match {
+	case models.TaggingMatchKeyword:
+		for _, keyword := range strings.Split(rule.Pattern, ",") {
+			if keyword = strings.ToLower(strings.TrimSpace(keyword)); keyword != "" {
+				compiled.keywords = append(compiled.keywords, keyword)
+			}
+		}
+		if len(compiled.keywords) == 0 {
+			return nil, fmt.Errorf("pattern has no keywords")
+		}
+	case models.TaggingMatchRegex:
+		re, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regular expression: %v", err)
+		}
+		compiled.re = re
+	default:
+		return nil, fmt.Errorf("unknown match %q", rule.Match)
+	}
+
+	return compiled, nil
+}
+
+// Match проверяет, что заголовок или описание задачи (в зависимости от поля правила) подходят под шаблон
+func (r *Rule) Match(title, description string) bool {
+	switch r.Field {
+	case models.TaggingFieldTitle:
+		return r.matchText(title)
+	case models.TaggingFieldDescription:
+		return r.matchText(description)
+	default:
+		return r.matchText(title) || r.matchText(description)
+	}
+}
+
+func (r *Rule) matchText(text string) bool {
+	if text == "" {
+		return false
+	}
+	if r.re != nil {
+		return r.re.MatchString(text)
+	}
+
+	text = strings.ToLower(text)
+	for _, keyword := range r.keywords {
+		if strings.Contains(text, keyword) {
+			return true
+		}
+	}
+	return false
+}
+
+// Apply применяет правила по порядку и возвращает теги сработавших правил без повторов
+// вместе с самими сработавшими правилами
+func Apply(rules []*Rule, title, description string) ([]string, []models.TaggingRule) {
+	var tags []string
+	var matched []models.TaggingRule
+	seen := make(map[string]bool)
+
+	for _, rule := range rules {
+		if !rule.Match(title, description) {
+			continue
+		}
+		matched = append(matched, rule.TaggingRule)
+		for _, tag := range rule.Tags {
+			if !seen[tag] {
+				seen[tag] = true
+				tags = append(tags, tag)
+			}
+		}
+	}
+
+	return tags, matched
+}
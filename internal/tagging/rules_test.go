@@ -0,0 +1,81 @@
+package tagging
+
+import (
+	"testing"
+
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRuleMatch(t *testing.T) {
+	tests := []struct {
+		name        string
+		rule        models.TaggingRule
+		title       string
+		description string
+		match       bool
+	}{
+		{
+			name:  "keyword any case",
+			rule:  models.TaggingRule{Match: models.TaggingMatchKeyword, Field: models.TaggingFieldAny, Pattern: "invoice, bill"},
+			title: "Pay BILL for March",
+			match: true,
+		},
+		{
+			name:        "keyword in description only",
+			rule:        models.TaggingRule{Match: models.TaggingMatchKeyword, Field: models.TaggingFieldTitle, Pattern: "invoice"},
+			title:       "Pay",
+			description: "invoice attached",
+			match:       false,
+		},
+		{
+			name:        "regex description",
+			rule:        models.TaggingRule{Match: models.TaggingMatchRegex, Field: models.TaggingFieldDescription, Pattern: `(?i)\bjira-\d+`},
+			description: "see JIRA-42",
+			match:       true,
+		},
+		{
+			name:  "regex no match",
+			rule:  models.TaggingRule{Match: models.TaggingMatchRegex, Field: models.TaggingFieldAny, Pattern: `^urgent:`},
+			title: "not urgent: later",
+			match: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := Compile(tt.rule)
+			require.NoError(t, err)
+			assert.Equal(t, tt.match, rule.Match(tt.title, tt.description))
+		})
+	}
+}
+
+func TestCompile_Errors(t *testing.T) {
+	rules := []models.TaggingRule{
+		{Match: models.TaggingMatchKeyword, Field: models.TaggingFieldAny, Pattern: " , "},
+		{Match: models.TaggingMatchRegex, Field: models.TaggingFieldAny, Pattern: "(unclosed"},
+		{Match: "glob", Field: models.TaggingFieldAny, Pattern: "*"},
+		{Match: models.TaggingMatchKeyword, Field: "notes", Pattern: "x"},
+	}
+
+	for _, rule := range rules {
+		_, err := Compile(rule)
+		assert.Error(t, err, rule.Pattern)
+	}
+}
+
+func TestApply(t *testing.T) {
+	first, err := Compile(models.TaggingRule{ID: "1", Match: models.TaggingMatchKeyword, Field: models.TaggingFieldAny, Pattern: "invoice", Tags: []string{"finance", "todo"}})
+	require.NoError(t, err)
+	second, err := Compile(models.TaggingRule{ID: "2", Match: models.TaggingMatchRegex, Field: models.TaggingFieldTitle, Pattern: "ACME", Tags: []string{"acme", "finance"}})
+	require.NoError(t, err)
+	third, err := Compile(models.TaggingRule{ID: "3", Match: models.TaggingMatchKeyword, Field: models.TaggingFieldAny, Pattern: "deploy", Tags: []string{"ops"}})
+	require.NoError(t, err)
+
+	tags, matched := Apply([]*Rule{first, second, third}, "ACME invoice", "")
+	assert.Equal(t, []string{"finance", "todo", "acme"}, tags)
+	require.Len(t, matched, 2)
+	assert.Equal(t, "2", matched[1].ID)
+}
@@ -0,0 +1,83 @@
+// Package timing собирает время, которое запрос провел в базе данных и кэше.
+// Обертки драйвера Postgres и клиента Redis отмечают время через контекст,
+// middleware отдает его клиенту в заголовке Server-Timing
+package timing
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const (
+	// DB запросы к Postgres
+	DB = "db"
+	// Cache команды Redis
+	Cache = "cache"
+)
+
+type contextKey struct{}
+
+// Entry суммарное время и число операций одного вида
+type Entry struct {
+	Name     string
+	Duration time.Duration
+	Count    int
+}
+
+type recorder struct {
+	mu      sync.Mutex
+	entries []Entry
+}
+
+// NewContext возвращает контекст, в котором накапливается время операций
+func NewContext(ctx context.Context) context.Context {
+	return context.WithValue(ctx, contextKey{}, &recorder{})
+}
+
+// Add добавляет время операции name; без NewContext время отбрасывается
+func Add(ctx context.Context, name string, d time.Duration) {
+	r, ok := ctx.Value(contextKey{}).(*recorder)
+	if !ok {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i := range r.entries {
+		if r.entries[i].Name == name {
+			r.entries[i].Duration += d
+			r.entries[i].Count++
+			return
+		}
+	}
+	r.entries = append(r.entries, Entry{Name: name, Duration: d, Count: 1})
+}
+
+// Start начинает замер операции name, возвращенная функция его завершает:
+//
+//	defer timing.Start(ctx, timing.DB)()
+func Start(ctx context.Context, name string) func() {
+	if ctx.Value(contextKey{}) == nil {
+		return func() {}
+	}
+
+	start := time.Now()
+	return func() {
+		Add(ctx, name, time.Since(start))
+	}
+}
+
+// FromContext время, накопленное в контексте, в порядке первых операций
+func FromContext(ctx context.Context) []Entry {
+	r, ok := ctx.Value(contextKey{}).(*recorder)
+	if !ok {
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]Entry(nil), r.entries...)
+}
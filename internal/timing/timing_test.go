@@ -0,0 +1,28 @@
+package timing
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecorder(t *testing.T) {
+	// без контекста замера время отбрасывается
+	Add(context.Background(), DB, time.Second)
+	Start(context.Background(), DB)()
+	assert.Nil(t, FromContext(context.Background()))
+
+	ctx := NewContext(context.Background())
+	Add(ctx, Cache, time.Millisecond)
+	Add(ctx, DB, 2*time.Millisecond)
+	Add(ctx, DB, 3*time.Millisecond)
+	Start(ctx, Cache)()
+
+	entries := FromContext(ctx)
+	assert.Len(t, entries, 2)
+	assert.Equal(t, Entry{Name: DB, Duration: 5 * time.Millisecond, Count: 2}, entries[1])
+	assert.Equal(t, Cache, entries[0].Name)
+	assert.Equal(t, 2, entries[0].Count)
+}
@@ -0,0 +1,85 @@
+// Package tracing трассировка запросов OpenTelemetry: span HTTP-запроса, вложенные в него span сервисов,
+// запросов к Postgres и команд Redis. Без OTEL_EXPORTER_OTLP_ENDPOINT остается глобальный no-op провайдер,
+// и span ничего не записывают
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jmoloko/taskmange/internal/config"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/jmoloko/taskmange"
+
+// Setup настраивает глобальный провайдер с экспортом span по OTLP/HTTP и распространение контекста
+// в заголовках traceparent и baggage. Возвращенная функция отправляет накопленные span и останавливает
+// экспорт. Без endpoint трассировка выключена, функция ничего не делает
+func Setup(ctx context.Context, cfg config.TracingConfig, serviceName, environment string) (func(context.Context) error, error) {
+	if cfg.Endpoint == "" {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	// endpoint — базовый адрес коллектора, как в стандартной OTEL_EXPORTER_OTLP_ENDPOINT; заголовки
+	// авторизации и остальные параметры экспорта читаются из OTEL_EXPORTER_OTLP_*
+	endpoint := strings.TrimRight(cfg.Endpoint, "/") + "/v1/traces"
+	exporter, err := otlptracehttp.New(ctx, otlptracehttp.WithEndpointURL(endpoint))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create trace exporter: %w", err)
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(
+		attribute.String("service.name", serviceName),
+		attribute.String("deployment.environment", environment),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create trace resource: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		// решение вызывающего сервиса из traceparent соблюдается, доля действует для новых трасс
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	return provider.Shutdown, nil
+}
+
+// Start начинает span name, вложенный в span из контекста:
+//
+//	ctx, span := tracing.Start(ctx, "TaskService.Create")
+//	defer span.End()
+func Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentationName).Start(ctx, name, opts...)
+}
+
+// StartChild начинает span только внутри записываемого span. Запросы к хранилищам из фоновых задач
+// без трассы не порождают отдельных трасс из одной операции
+func StartChild(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	parent := trace.SpanFromContext(ctx)
+	if !parent.IsRecording() {
+		return ctx, trace.SpanFromContext(context.Background())
+	}
+	return Start(ctx, name, opts...)
+}
+
+// End завершает span, отмечая ошибку операции
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,38 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestStartChild(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	// без родительского span операция хранилища не трассируется
+	_, orphan := StartChild(context.Background(), "postgres query")
+	End(orphan, nil)
+	assert.Empty(t, recorder.Ended())
+
+	ctx, parent := Start(context.Background(), "GET /api/tasks")
+	_, child := StartChild(ctx, "postgres query")
+	End(child, errors.New("connection reset"))
+	parent.End()
+
+	ended := recorder.Ended()
+	require.Len(t, ended, 2)
+	assert.Equal(t, "postgres query", ended[0].Name())
+	assert.Equal(t, parent.SpanContext().SpanID(), ended[0].Parent().SpanID())
+	assert.Equal(t, codes.Error, ended[0].Status().Code)
+	assert.Equal(t, codes.Unset, ended[1].Status().Code)
+}
@@ -0,0 +1,171 @@
+package trigger
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/jmoloko/taskmange/internal/domain/models"
+)
+
+// поддерживаемые операторы сравнения
+const (
+	opEqual    = "=="
+	opNotEqual = "!="
+	opContains = "~"
+)
+
+// Filter разобранное выражение фильтра триггера.
+// Выражение — одно или несколько условий `поле оператор значение`, объединенных `&&`.
+// Поля: title, description, status, priority, private. Операторы: ==, !=, ~ (содержит, без учета регистра).
+// Значение — слово или строка в двойных кавычках
+type Filter struct {
+	conditions []condition
+}
+
+type condition struct {
+	field string
+	op    string
+	value string
+}
+
+// ParseFilter разбирает выражение фильтра, пустое выражение пропускает все задачи
+func ParseFilter(expr string) (*Filter, error) {
+	tokens, err := tokenize(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := &Filter{}
+	for i := 0; i < len(tokens); {
+		if len(tokens)-i < 3 {
+			return nil, fmt.Errorf("incomplete condition at position %d", i+1)
+		}
+
+		cond := condition{
+			field: tokens[i].text,
+			op:    tokens[i+1].text,
+			value: tokens[i+2].text,
+		}
+		if tokens[i].quoted || !isField(cond.field) {
+			return nil, fmt.Errorf("unknown field %q", cond.field)
+		}
+		if tokens[i+1].quoted || !isOperator(cond.op) {
+			return nil, fmt.Errorf("unknown operator %q", cond.op)
+		}
+		filter.conditions = append(filter.conditions, cond)
+		i += 3
+
+		if i < len(tokens) {
+			if tokens[i].text != "&&" || tokens[i].quoted {
+				return nil, fmt.Errorf("expected && but got %q", tokens[i].text)
+			}
+			i++
+			if i == len(tokens) {
+				return nil, fmt.Errorf("expression ends with &&")
+			}
+		}
+	}
+
+	return filter, nil
+}
+
+// Match проверяет, что задача удовлетворяет всем условиям фильтра
+func (f *Filter) Match(task models.Task) bool {
+	for _, cond := range f.conditions {
+		if !cond.match(task) {
+			return false
+		}
+	}
+	return true
+}
+
+func (c condition) match(task models.Task) bool {
+	var actual string
+	switch c.field {
+	case "title":
+		actual = task.Title
+	case "description":
+		actual = task.Description
+	case "status":
+		actual = string(task.Status)
+	case "priority":
+		actual = string(task.Priority)
+	case "private":
+		actual = strconv.FormatBool(task.Private)
+	}
+
+	switch c.op {
+	case opEqual:
+		return strings.EqualFold(actual, c.value)
+	case opNotEqual:
+		return !strings.EqualFold(actual, c.value)
+	case opContains:
+		return strings.Contains(strings.ToLower(actual), strings.ToLower(c.value))
+	}
+	return false
+}
+
+func isField(field string) bool {
+	switch field {
+	case "title", "description", "status", "priority", "private":
+		return true
+	}
+	return false
+}
+
+func isOperator(op string) bool {
+	return op == opEqual || op == opNotEqual || op == opContains
+}
+
+type token struct {
+	text   string
+	quoted bool
+}
+
+// tokenize разбивает выражение на слова, операторы и строки в кавычках
+func tokenize(expr string) ([]token, error) {
+	var tokens []token
+	runes := []rune(expr)
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '"':
+			var sb strings.Builder
+			i++
+			for ; i < len(runes) && runes[i] != '"'; i++ {
+				if runes[i] == '\\' && i+1 < len(runes) {
+					i++
+				}
+				sb.WriteRune(runes[i])
+			}
+			if i == len(runes) {
+				return nil, fmt.Errorf("unterminated string")
+			}
+			i++
+			tokens = append(tokens, token{text: sb.String(), quoted: true})
+		case r == '=' || r == '!' || r == '&':
+			if i+1 < len(runes) && (runes[i+1] == '=' || (r == '&' && runes[i+1] == '&')) {
+				tokens = append(tokens, token{text: string(runes[i : i+2])})
+				i += 2
+				continue
+			}
+			return nil, fmt.Errorf("unexpected %q at position %d", r, i+1)
+		case r == '~':
+			tokens = append(tokens, token{text: opContains})
+			i++
+		default:
+			start := i
+			for i < len(runes) && !unicode.IsSpace(runes[i]) && !strings.ContainsRune(`"=!&~`, runes[i]) {
+				i++
+			}
+			tokens = append(tokens, token{text: string(runes[start:i])})
+		}
+	}
+
+	return tokens, nil
+}
@@ -0,0 +1,52 @@
+package trigger
+
+import (
+	"testing"
+
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFilter(t *testing.T) {
+	task := models.Task{
+		Title:    "Quarterly report",
+		Status:   models.StatusDone,
+		Priority: models.PriorityHigh,
+	}
+
+	tests := []struct {
+		name  string
+		expr  string
+		match bool
+	}{
+		{name: "empty", expr: "", match: true},
+		{name: "equal", expr: "priority == high", match: true},
+		{name: "not equal", expr: "status != done", match: false},
+		{name: "contains quoted", expr: `title ~ "REPORT"`, match: true},
+		{name: "and", expr: `priority == high && title ~ "weekly"`, match: false},
+		{name: "private", expr: "private == false", match: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter, err := ParseFilter(tt.expr)
+			require.NoError(t, err)
+			assert.Equal(t, tt.match, filter.Match(task))
+		})
+	}
+}
+
+func TestParseFilter_Errors(t *testing.T) {
+	for _, expr := range []string{
+		"owner == me",
+		"priority = high",
+		"priority ==",
+		"priority == high &&",
+		"priority == high status == done",
+		`title ~ "unterminated`,
+	} {
+		_, err := ParseFilter(expr)
+		assert.Error(t, err, expr)
+	}
+}
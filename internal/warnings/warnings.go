@@ -0,0 +1,47 @@
+// Package warnings собирает предупреждения, возникшие при обработке запроса.
+// Сервисы добавляют их через контекст, middleware добавляет их в ответ
+package warnings
+
+import (
+	"context"
+	"sync"
+
+	"github.com/jmoloko/taskmange/internal/domain/models"
+)
+
+type contextKey struct{}
+
+type collector struct {
+	mu       sync.Mutex
+	warnings []models.Warning
+}
+
+// NewContext возвращает контекст, в котором накапливаются предупреждения
+func NewContext(ctx context.Context) context.Context {
+	return context.WithValue(ctx, contextKey{}, &collector{})
+}
+
+// Add добавляет предупреждение; без NewContext предупреждение отбрасывается
+func Add(ctx context.Context, warning models.Warning) {
+	c, ok := ctx.Value(contextKey{}).(*collector)
+	if !ok {
+		return
+	}
+
+	c.mu.Lock()
+	c.warnings = append(c.warnings, warning)
+	c.mu.Unlock()
+}
+
+// FromContext предупреждения, накопленные в контексте
+func FromContext(ctx context.Context) []models.Warning {
+	c, ok := ctx.Value(contextKey{}).(*collector)
+	if !ok {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return append([]models.Warning(nil), c.warnings...)
+}
@@ -2,91 +2,167 @@ package worker
 
 import (
 	"context"
+	"fmt"
+	"runtime/debug"
 	"sync"
 	"time"
 
+	"github.com/jmoloko/taskmange/internal/clock"
 	"github.com/jmoloko/taskmange/internal/domain/models"
 	"github.com/jmoloko/taskmange/internal/domain/repository"
 	domainService "github.com/jmoloko/taskmange/internal/domain/service"
 	"github.com/jmoloko/taskmange/internal/logger"
+	"github.com/jmoloko/taskmange/internal/metrics"
 )
 
-// BackgroundWorker фоновые задачи
+const (
+	// запас на расхождение часов приложения и БД при выборке измененных задач
+	analyticsSinceMargin = time.Minute
+
+	// имена встроенных задач в метриках
+	jobCleanupExpiredTasks = "cleanup_expired_tasks"
+	jobAnalytics           = "analytics"
+)
+
+// Job дополнительная периодическая задача, регистрируемая подсистемами через AddJob
+type Job struct {
+	Name     string
+	Interval time.Duration
+	// Timeout ограничение одного запуска, 0 — Interval, чтобы запуск не наползал на следующий
+	Timeout time.Duration
+	// Run получает контекст, отменяемый по Timeout и при остановке worker, и должен передавать его в репозитории
+	Run func(ctx context.Context) error
+}
+
+// BackgroundWorker фоновые задачи. Каждая задача выполняется в своей горутине по своему интервалу;
+// Stop отменяет контекст выполняющихся запусков и ждет их завершения
 type BackgroundWorker struct {
 	taskService domainService.TaskService
 	cache       repository.AnalyticsCache
+	clock       clock.Clock
 	logger      logger.Logger
-	stopChan    chan struct{}
-	wg          sync.WaitGroup
-	stopOnce    sync.Once
+
+	mu   sync.Mutex
+	jobs []Job
+	// lastAnalyticsRun начало последнего успешного пересчета аналитики, нулевое — пересчет еще не выполнялся.
+	// Меняется только горутиной задачи analytics
+	lastAnalyticsRun time.Time
+
+	ctx       context.Context
+	cancel    context.CancelFunc
+	wg        sync.WaitGroup
+	startOnce sync.Once
+	stopOnce  sync.Once
 }
 
 func NewBackgroundWorker(taskService domainService.TaskService, cache repository.AnalyticsCache, logger logger.Logger) *BackgroundWorker {
-	return &BackgroundWorker{
+	ctx, cancel := context.WithCancel(context.Background())
+	w := &BackgroundWorker{
 		taskService: taskService,
 		cache:       cache,
+		clock:       clock.System,
 		logger:      logger,
-		stopChan:    make(chan struct{}),
+		ctx:         ctx,
+		cancel:      cancel,
+	}
+	w.jobs = []Job{
+		{Name: jobCleanupExpiredTasks, Interval: 24 * time.Hour, Run: w.cleanupExpiredTasks},
+		{Name: jobAnalytics, Interval: 6 * time.Hour, Run: w.generateAnalytics},
 	}
+	return w
 }
 
-// запуск фоновых задач
+// AddJob регистрирует периодическую задачу. Задачи, добавленные после Start, не запускаются
+func (w *BackgroundWorker) AddJob(job Job) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.jobs = append(w.jobs, job)
+}
+
+// запуск фоновых задач, повторный вызов ничего не делает
 func (w *BackgroundWorker) Start() {
-	w.wg.Add(2)
-
-	// очистка просроченных задач
-	go func() {
-		defer w.wg.Done()
-		ticker := time.NewTicker(24 * time.Hour)
-		defer ticker.Stop()
-
-		for {
-			select {
-			case <-ticker.C:
-				if err := w.cleanupExpiredTasks(); err != nil {
-					w.logger.Error("Failed to cleanup expired tasks", map[string]interface{}{
-						"error": err.Error(),
-					})
-				}
-			case <-w.stopChan:
-				return
-			}
+	w.startOnce.Do(func() {
+		w.mu.Lock()
+		jobs := w.jobs
+		w.jobs = nil
+		w.mu.Unlock()
+
+		w.wg.Add(len(jobs))
+		for _, job := range jobs {
+			go w.runJob(job)
 		}
-	}()
+	})
+}
 
-	// генерация аналитики
-	go func() {
-		defer w.wg.Done()
-		ticker := time.NewTicker(6 * time.Hour)
-		defer ticker.Stop()
-
-		for {
-			select {
-			case <-ticker.C:
-				if err := w.generateAnalytics(); err != nil {
-					w.logger.Error("Failed to generate analytics", map[string]interface{}{
-						"error": err.Error(),
-					})
-				}
-			case <-w.stopChan:
-				return
+// runJob выполняет задачу с заданным интервалом до остановки worker
+func (w *BackgroundWorker) runJob(job Job) {
+	defer w.wg.Done()
+	metrics.WorkerJobsRunning.Inc()
+	defer metrics.WorkerJobsRunning.Dec()
+	ticker := time.NewTicker(job.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			err := w.run(job)
+			if err != nil && w.ctx.Err() != nil {
+				w.logger.Info("Background job interrupted by shutdown", map[string]interface{}{
+					"job": job.Name,
+				})
+			} else if err != nil {
+				w.logger.Error("Background job failed", map[string]interface{}{
+					"job":   job.Name,
+					"error": err.Error(),
+				})
 			}
+		case <-w.ctx.Done():
+			return
+		}
+	}
+}
+
+// run выполняет один запуск задачи с ограничением по времени, учитывая его в метрике выполняющихся запусков.
+// Паника в задаче превращается в ошибку запуска и не останавливает ее горутину
+func (w *BackgroundWorker) run(job Job) (err error) {
+	inFlight := metrics.WorkerJobsInFlight.WithLabelValues(job.Name)
+	inFlight.Inc()
+	defer inFlight.Dec()
+
+	timeout := job.Timeout
+	if timeout <= 0 {
+		timeout = job.Interval
+	}
+	ctx, cancel := context.WithTimeout(w.ctx, timeout)
+	defer cancel()
+
+	defer func() {
+		if r := recover(); r != nil {
+			metrics.WorkerJobPanicsTotal.WithLabelValues(job.Name).Inc()
+			w.logger.Error("Background job panicked", map[string]interface{}{
+				"job":   job.Name,
+				"panic": fmt.Sprint(r),
+				"stack": string(debug.Stack()),
+			})
+			err = fmt.Errorf("job %s panicked: %v", job.Name, r)
 		}
 	}()
+
+	return job.Run(ctx)
 }
 
-// корректная остановка фоновых задач
+// корректная остановка фоновых задач: выполняющиеся запуски получают отмену контекста
 func (w *BackgroundWorker) Stop() {
 	w.stopOnce.Do(func() {
-		close(w.stopChan)
+		w.cancel()
 		w.wg.Wait()
 	})
 }
 
 // удаление просроченных задач
-func (w *BackgroundWorker) cleanupExpiredTasks() error {
-	ctx := context.Background()
-	expiredDate := time.Now().AddDate(0, 0, -7) // Tasks expired for 7 days
+func (w *BackgroundWorker) cleanupExpiredTasks(ctx context.Context) error {
+	expiredDate := w.clock.Now().AddDate(0, 0, -7) // Tasks expired for 7 days
 	filters := models.TaskFilters{
 		DueDate: &expiredDate,
 	}
@@ -97,6 +173,10 @@ func (w *BackgroundWorker) cleanupExpiredTasks() error {
 	}
 
 	for _, task := range tasks {
+		// остановка worker прерывает очистку между задачами, оставшиеся удалятся следующим запуском
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 		if err := w.taskService.Delete(ctx, task.ID, task.UserID); err != nil {
 			w.logger.Error("Failed to delete expired task", map[string]interface{}{
 				"task_id": task.ID,
@@ -108,18 +188,27 @@ func (w *BackgroundWorker) cleanupExpiredTasks() error {
 	return nil
 }
 
-// генеририруем и кэширует аналитику для всех пользователей
-func (w *BackgroundWorker) generateAnalytics() error {
-	ctx := context.Background()
+// генеририруем и кэширует аналитику пользователей, задачи которых менялись с прошлого запуска.
+// Первый запуск пересчитывает аналитику всех пользователей с задачами
+func (w *BackgroundWorker) generateAnalytics(ctx context.Context) error {
+	started := w.clock.Now()
 
-	// Получаем список всех пользователей с активными задачами
-	users, err := w.taskService.GetActiveUsers(ctx)
+	var since time.Time
+	if !w.lastAnalyticsRun.IsZero() {
+		since = w.lastAnalyticsRun.Add(-analyticsSinceMargin)
+	}
+
+	users, err := w.taskService.GetUsersWithTasksUpdatedSince(ctx, since)
 	if err != nil {
 		return err
 	}
 
 	// Для каждого пользователя обновляем кэш аналитики
 	for _, userID := range users {
+		// прерванный пересчет не сдвигает lastAnalyticsRun, следующий запуск повторит его целиком
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 		// Генерируем аналитику за разные периоды
 		periods := []string{"day", "week", "month"}
 		for _, period := range periods {
@@ -138,7 +227,7 @@ func (w *BackgroundWorker) generateAnalytics() error {
 				UserID:    userID,
 				Period:    period,
 				Analytics: analytics,
-				CachedAt:  time.Now(),
+				CachedAt:  w.clock.Now(),
 			}); err != nil {
 				w.logger.Error("Failed to cache analytics", map[string]interface{}{
 					"user_id": userID,
@@ -148,6 +237,7 @@ func (w *BackgroundWorker) generateAnalytics() error {
 			}
 		}
 	}
+	w.lastAnalyticsRun = started
 
 	return nil
 }
@@ -3,13 +3,18 @@ package worker
 import (
 	"context"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/jmoloko/taskmange/internal/cache"
+	"github.com/jmoloko/taskmange/internal/clock"
 	"github.com/jmoloko/taskmange/internal/domain/models"
 	"github.com/jmoloko/taskmange/internal/domain/repository"
+	domainService "github.com/jmoloko/taskmange/internal/domain/service"
 	"github.com/jmoloko/taskmange/internal/logger"
+	"github.com/jmoloko/taskmange/internal/metrics"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/redis/go-redis/v9"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -30,26 +35,112 @@ func (m *MockTaskService) GetUserTask(ctx context.Context, userID, taskID string
 	return args.Get(0).(models.Task), args.Error(1)
 }
 
+func (m *MockTaskService) UnlockUserTask(ctx context.Context, userID, taskID string) (models.Task, error) {
+	args := m.Called(ctx, userID, taskID)
+	return args.Get(0).(models.Task), args.Error(1)
+}
+
 func (m *MockTaskService) GetUserTasks(ctx context.Context, userID string, filters models.TaskFilters) ([]models.Task, error) {
 	args := m.Called(ctx, userID, filters)
 	return args.Get(0).([]models.Task), args.Error(1)
 }
 
+func (m *MockTaskService) CountUserTasks(ctx context.Context, userID string, filters models.TaskFilters) (int, error) {
+	args := m.Called(ctx, userID, filters)
+	return args.Int(0), args.Error(1)
+}
+
 func (m *MockTaskService) UpdateUserTask(ctx context.Context, userID string, task models.Task) (models.Task, error) {
 	args := m.Called(ctx, userID, task)
 	return args.Get(0).(models.Task), args.Error(1)
 }
 
+func (m *MockTaskService) PatchUserTask(ctx context.Context, userID, taskID string, req models.UpdateTaskRequest) (models.Task, error) {
+	args := m.Called(ctx, userID, taskID, req)
+	return args.Get(0).(models.Task), args.Error(1)
+}
+
+func (m *MockTaskService) CompleteTasks(ctx context.Context, userID string, ids []string) (models.CompleteTasksResult, error) {
+	args := m.Called(ctx, userID, ids)
+	return args.Get(0).(models.CompleteTasksResult), args.Error(1)
+}
+
 func (m *MockTaskService) DeleteUserTask(ctx context.Context, userID, taskID string) error {
 	args := m.Called(ctx, userID, taskID)
 	return args.Error(0)
 }
 
+func (m *MockTaskService) RelateTasks(ctx context.Context, userID, taskID, relatedID string) error {
+	args := m.Called(ctx, userID, taskID, relatedID)
+	return args.Error(0)
+}
+
+func (m *MockTaskService) UnrelateTasks(ctx context.Context, userID, taskID, relatedID string) error {
+	args := m.Called(ctx, userID, taskID, relatedID)
+	return args.Error(0)
+}
+
+func (m *MockTaskService) ExpandRelated(ctx context.Context, userID string, tasks []models.Task) ([]models.Task, error) {
+	args := m.Called(ctx, userID, tasks)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Task), args.Error(1)
+}
+
+func (m *MockTaskService) GetSubtasks(ctx context.Context, userID, taskID string) ([]models.Task, error) {
+	args := m.Called(ctx, userID, taskID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Task), args.Error(1)
+}
+
+func (m *MockTaskService) AssignTask(ctx context.Context, userID, taskID, assigneeID string) (models.Task, error) {
+	args := m.Called(ctx, userID, taskID, assigneeID)
+	return args.Get(0).(models.Task), args.Error(1)
+}
+
+func (m *MockTaskService) UnassignTask(ctx context.Context, userID, taskID string) (models.Task, error) {
+	args := m.Called(ctx, userID, taskID)
+	return args.Get(0).(models.Task), args.Error(1)
+}
+
+func (m *MockTaskService) GetRecurrence(ctx context.Context, userID, recurrenceID string) (models.Recurrence, error) {
+	args := m.Called(ctx, userID, recurrenceID)
+	return args.Get(0).(models.Recurrence), args.Error(1)
+}
+
+func (m *MockTaskService) PauseRecurrence(ctx context.Context, userID, recurrenceID string) (models.Recurrence, error) {
+	args := m.Called(ctx, userID, recurrenceID)
+	return args.Get(0).(models.Recurrence), args.Error(1)
+}
+
+func (m *MockTaskService) ResumeRecurrence(ctx context.Context, userID, recurrenceID string) (models.Recurrence, error) {
+	args := m.Called(ctx, userID, recurrenceID)
+	return args.Get(0).(models.Recurrence), args.Error(1)
+}
+
+func (m *MockTaskService) MaterializeRecurrences(ctx context.Context) error {
+	args := m.Called(ctx)
+	return args.Error(0)
+}
+
 func (m *MockTaskService) ImportTasks(ctx context.Context, userID string, tasks []models.Task) error {
 	args := m.Called(ctx, userID, tasks)
 	return args.Error(0)
 }
 
+func (m *MockTaskService) ImportTaskFile(ctx context.Context, userID string, rows domainService.ImportRowSource, skipInvalid bool) (models.ImportReport, error) {
+	args := m.Called(ctx, userID, rows, skipInvalid)
+	return args.Get(0).(models.ImportReport), args.Error(1)
+}
+
+func (m *MockTaskService) PreviewImport(ctx context.Context, userID string, batch models.ImportBatch) (models.ImportPreview, error) {
+	args := m.Called(ctx, userID, batch)
+	return args.Get(0).(models.ImportPreview), args.Error(1)
+}
+
 func (m *MockTaskService) ExportUserTasks(ctx context.Context, userID string) ([]models.Task, error) {
 	args := m.Called(ctx, userID)
 	return args.Get(0).([]models.Task), args.Error(1)
@@ -65,11 +156,26 @@ func (m *MockTaskService) Delete(ctx context.Context, taskID, userID string) err
 	return args.Error(0)
 }
 
+func (m *MockTaskService) PurgeUserTasks(ctx context.Context, userID string) (int, error) {
+	args := m.Called(ctx, userID)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockTaskService) DeleteTasks(ctx context.Context, userID string, filter models.TaskDeleteFilter, progress func(models.BulkDeleteProgress) error) (models.BulkDeleteProgress, error) {
+	args := m.Called(ctx, userID, filter, progress)
+	return args.Get(0).(models.BulkDeleteProgress), args.Error(1)
+}
+
 func (m *MockTaskService) GetActiveUsers(ctx context.Context) ([]string, error) {
 	args := m.Called(ctx)
 	return args.Get(0).([]string), args.Error(1)
 }
 
+func (m *MockTaskService) GetUsersWithTasksUpdatedSince(ctx context.Context, since time.Time) ([]string, error) {
+	args := m.Called(ctx, since)
+	return args.Get(0).([]string), args.Error(1)
+}
+
 func (m *MockTaskService) GetAll(ctx context.Context, userID string, filters models.TaskFilters) ([]models.Task, error) {
 	args := m.Called(ctx, userID, filters)
 	return args.Get(0).([]models.Task), args.Error(1)
@@ -80,6 +186,21 @@ func (m *MockTaskService) GetAnalytics(ctx context.Context, userID string, perio
 	return args.Get(0).(models.Analytics), args.Error(1)
 }
 
+func (m *MockTaskService) GetUserAnalyticsRange(ctx context.Context, userID string, from, to time.Time) (models.Analytics, error) {
+	args := m.Called(ctx, userID, from, to)
+	return args.Get(0).(models.Analytics), args.Error(1)
+}
+
+func (m *MockTaskService) GetProjectAnalytics(ctx context.Context, userID, projectID, period string) (models.Analytics, error) {
+	args := m.Called(ctx, userID, projectID, period)
+	return args.Get(0).(models.Analytics), args.Error(1)
+}
+
+func (m *MockTaskService) GetDashboard(ctx context.Context, userID string) (models.Dashboard, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(models.Dashboard), args.Error(1)
+}
+
 // MockCache реализует интерфейс AnalyticsCache для тестирования
 type MockCache struct {
 	mock.Mock
@@ -166,18 +287,21 @@ func TestBackgroundWorker_CleanupExpiredTasks(t *testing.T) {
 
 	worker := NewBackgroundWorker(mockTaskService, mockCache, mockLogger)
 	assert.NotNil(t, worker)
+	now := time.Date(2024, 3, 31, 1, 30, 0, 0, time.UTC)
+	worker.clock = clock.NewFake(now)
 
 	expiredTasks := []models.Task{
 		{ID: "1", UserID: "user1", Title: "Expired Task 1"},
 		{ID: "2", UserID: "user2", Title: "Expired Task 2"},
 	}
 
-	mockTaskService.On("GetAll", mock.Anything, "", mock.Anything).Return(expiredTasks, nil)
+	expiredDate := now.AddDate(0, 0, -7)
+	mockTaskService.On("GetAll", mock.Anything, "", models.TaskFilters{DueDate: &expiredDate}).Return(expiredTasks, nil)
 	mockTaskService.On("Delete", mock.Anything, "1", "user1").Return(nil)
 	mockTaskService.On("Delete", mock.Anything, "2", "user2").Return(nil)
 	mockLogger.On("Error", mock.Anything, mock.Anything, mock.Anything).Return()
 
-	err := worker.cleanupExpiredTasks()
+	err := worker.cleanupExpiredTasks(context.Background())
 	assert.NoError(t, err)
 
 	mockTaskService.AssertExpectations(t)
@@ -190,6 +314,8 @@ func TestBackgroundWorker_GenerateAnalytics(t *testing.T) {
 
 	worker := NewBackgroundWorker(mockTaskService, mockCache, mockLogger)
 	assert.NotNil(t, worker)
+	fake := clock.NewFake(time.Date(2024, 6, 10, 12, 0, 0, 0, time.UTC))
+	worker.clock = fake
 
 	users := []string{"user1", "user2"}
 	analytics := models.Analytics{
@@ -200,20 +326,31 @@ func TestBackgroundWorker_GenerateAnalytics(t *testing.T) {
 		AvgCompletionTime: 24.5,
 	}
 
-	mockTaskService.On("GetActiveUsers", mock.Anything).Return(users, nil)
+	// первый запуск пересчитывает всех пользователей с задачами
+	mockTaskService.On("GetUsersWithTasksUpdatedSince", mock.Anything, time.Time{}).Return(users, nil).Once()
 	for _, userID := range users {
 		for _, period := range []string{"day", "week", "month"} {
 			mockTaskService.On("GetAnalytics", mock.Anything, userID, period).Return(analytics, nil)
 			mockCache.On("SetUserAnalytics", mock.Anything, mock.MatchedBy(func(a repository.CachedAnalytics) bool {
-				return a.UserID == userID && a.Period == period
+				return a.UserID == userID && a.Period == period && a.CachedAt.Equal(fake.Now())
 			})).Return(nil)
 		}
 	}
 	mockLogger.On("Error", mock.Anything, mock.Anything, mock.Anything).Return()
 
-	err := worker.generateAnalytics()
+	err := worker.generateAnalytics(context.Background())
 	assert.NoError(t, err)
 
+	// следующий запуск берет только пользователей с изменениями после предыдущего
+	firstRun := worker.lastAnalyticsRun
+	assert.Equal(t, fake.Now(), firstRun)
+	fake.Advance(time.Hour)
+	mockTaskService.On("GetUsersWithTasksUpdatedSince", mock.Anything, firstRun.Add(-analyticsSinceMargin)).Return([]string{}, nil).Once()
+
+	err = worker.generateAnalytics(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, firstRun.Add(time.Hour), worker.lastAnalyticsRun)
+
 	mockTaskService.AssertExpectations(t)
 	mockCache.AssertExpectations(t)
 }
@@ -222,7 +359,7 @@ func TestBackgroundWorker_StartStop(t *testing.T) {
 	// Подготовка моков
 	mockTaskService := new(MockTaskService)
 	redisClient := redis.NewClient(&redis.Options{})
-	mockCache := cache.NewRedisCache(redisClient)
+	mockCache := cache.NewRedisCache(redisClient, nil)
 	mockLogger := new(MockLogger)
 
 	// Создаем worker
@@ -267,3 +404,147 @@ func TestBackgroundWorker_StartStop(t *testing.T) {
 		t.Fatal("Worker.Stop() заблокировался")
 	}
 }
+
+func TestBackgroundWorker_AddJob(t *testing.T) {
+	mockTaskService := new(MockTaskService)
+	mockCache := new(MockCache)
+	mockLogger := new(MockLogger)
+
+	worker := NewBackgroundWorker(mockTaskService, mockCache, mockLogger)
+
+	ran := make(chan struct{}, 1)
+	worker.AddJob(Job{
+		Name:     "test",
+		Interval: 10 * time.Millisecond,
+		Run: func(ctx context.Context) error {
+			select {
+			case ran <- struct{}{}:
+			default:
+			}
+			return nil
+		},
+	})
+
+	worker.Start()
+
+	select {
+	case <-ran:
+	case <-time.After(time.Second):
+		t.Fatal("job was not run")
+	}
+	// задача и две встроенные
+	assert.Eventually(t, func() bool {
+		return testutil.ToFloat64(metrics.WorkerJobsRunning) == 3
+	}, time.Second, 10*time.Millisecond)
+
+	worker.Stop()
+	assert.Equal(t, float64(0), testutil.ToFloat64(metrics.WorkerJobsRunning))
+	assert.Equal(t, float64(0), testutil.ToFloat64(metrics.WorkerJobsInFlight.WithLabelValues("test")))
+}
+
+func TestBackgroundWorker_JobPanicIsolated(t *testing.T) {
+	mockLogger := new(MockLogger)
+	mockLogger.On("Error", "Background job panicked", mock.Anything).Return().Twice()
+	mockLogger.On("Error", "Background job failed", mock.Anything).Return().Twice()
+	mockLogger.On("Info", "Background job interrupted by shutdown", []interface{}{
+		map[string]interface{}{"job": "panicky"},
+	}).Return().Once()
+	worker := NewBackgroundWorker(new(MockTaskService), new(MockCache), mockLogger)
+
+	// первые два запуска паникуют, третий ждет остановки, чтобы Stop прерывал заведомо выполняющийся запуск
+	runs := make(chan struct{}, 10)
+	var count atomic.Int32
+	worker.AddJob(Job{
+		Name:     "panicky",
+		Interval: 10 * time.Millisecond,
+		Timeout:  time.Hour,
+		Run: func(ctx context.Context) error {
+			runs <- struct{}{}
+			if count.Add(1) <= 2 {
+				panic("boom")
+			}
+			<-ctx.Done()
+			return ctx.Err()
+		},
+	})
+	worker.Start()
+
+	// после паники задача продолжает запускаться по расписанию
+	for range 3 {
+		select {
+		case <-runs:
+		case <-time.After(time.Second):
+			worker.Stop()
+			t.Fatal("job was not run after panic")
+		}
+	}
+	worker.Stop()
+
+	assert.GreaterOrEqual(t, testutil.ToFloat64(metrics.WorkerJobPanicsTotal.WithLabelValues("panicky")), float64(2))
+	mockLogger.AssertExpectations(t)
+}
+
+func TestBackgroundWorker_StopCancelsRunningJob(t *testing.T) {
+	mockLogger := new(MockLogger)
+	mockLogger.On("Info", "Background job interrupted by shutdown", mock.Anything).Return()
+	worker := NewBackgroundWorker(new(MockTaskService), new(MockCache), mockLogger)
+
+	started := make(chan struct{})
+	worker.AddJob(Job{
+		Name:     "long",
+		Interval: 10 * time.Millisecond,
+		Timeout:  time.Hour,
+		Run: func(ctx context.Context) error {
+			close(started)
+			<-ctx.Done()
+			return ctx.Err()
+		},
+	})
+	worker.Start()
+
+	select {
+	case <-started:
+	case <-time.After(time.Second):
+		t.Fatal("job was not run")
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		worker.Stop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("Stop() did not cancel the running job")
+	}
+	mockLogger.AssertExpectations(t)
+}
+
+func TestBackgroundWorker_JobTimeout(t *testing.T) {
+	worker := NewBackgroundWorker(new(MockTaskService), new(MockCache), new(MockLogger))
+
+	err := worker.run(Job{
+		Name:     "slow",
+		Interval: time.Hour,
+		Timeout:  10 * time.Millisecond,
+		Run: func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		},
+	})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// без Timeout запуск ограничен интервалом
+	err = worker.run(Job{
+		Name:     "default",
+		Interval: 10 * time.Millisecond,
+		Run: func(ctx context.Context) error {
+			deadline, ok := ctx.Deadline()
+			assert.True(t, ok)
+			assert.WithinDuration(t, time.Now().Add(10*time.Millisecond), deadline, 10*time.Millisecond)
+			return nil
+		},
+	})
+	assert.NoError(t, err)
+}
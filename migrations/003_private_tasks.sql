@@ -0,0 +1,7 @@
+-- Приватные задачи: заголовок и описание хранятся зашифрованными
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS private BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Шифртекст в base64 длиннее исходного заголовка
+ALTER TABLE tasks ALTER COLUMN title TYPE TEXT;
+
+COMMENT ON COLUMN tasks.private IS 'Title and description are AES-GCM encrypted with the owner key';
@@ -0,0 +1,38 @@
+-- Временные метки назначаются базой данных, а не часами сервера приложения
+ALTER TABLE users ALTER COLUMN created_at SET DEFAULT now();
+ALTER TABLE users ALTER COLUMN updated_at SET DEFAULT now();
+ALTER TABLE tasks ALTER COLUMN created_at SET DEFAULT now();
+ALTER TABLE tasks ALTER COLUMN updated_at SET DEFAULT now();
+
+-- Обновление updated_at при любом изменении строки
+CREATE OR REPLACE FUNCTION set_updated_at() RETURNS TRIGGER AS $$
+BEGIN
+    NEW.updated_at = now();
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Проставление completed_at при переходе задачи в статус done
+CREATE OR REPLACE FUNCTION set_task_completed_at() RETURNS TRIGGER AS $$
+BEGIN
+    IF NEW.status = 'done' AND NEW.completed_at IS NULL THEN
+        NEW.completed_at = now();
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS users_set_updated_at ON users;
+CREATE TRIGGER users_set_updated_at
+    BEFORE UPDATE ON users
+    FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+
+DROP TRIGGER IF EXISTS tasks_set_updated_at ON tasks;
+CREATE TRIGGER tasks_set_updated_at
+    BEFORE UPDATE ON tasks
+    FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+
+DROP TRIGGER IF EXISTS tasks_set_completed_at ON tasks;
+CREATE TRIGGER tasks_set_completed_at
+    BEFORE INSERT OR UPDATE OF status ON tasks
+    FOR EACH ROW EXECUTE FUNCTION set_task_completed_at();
@@ -0,0 +1,27 @@
+-- Подписки браузеров на Web Push
+CREATE TABLE IF NOT EXISTS push_subscriptions (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    endpoint TEXT NOT NULL UNIQUE,
+    p256dh VARCHAR(255) NOT NULL,
+    auth VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_push_subscriptions_user_id ON push_subscriptions(user_id);
+
+-- Настройки уведомлений пользователя
+CREATE TABLE IF NOT EXISTS notification_preferences (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    push_enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    due_soon_window_minutes INTEGER NOT NULL DEFAULT 60,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
+
+-- Отправленные напоминания: одно на пару (задача, срок)
+CREATE TABLE IF NOT EXISTS task_reminders (
+    task_id UUID NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    due_date TIMESTAMP WITH TIME ZONE NOT NULL,
+    sent_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    PRIMARY KEY (task_id, due_date)
+);
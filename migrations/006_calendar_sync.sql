@@ -0,0 +1,35 @@
+-- Двусторонняя синхронизация сроков задач с календарями Google и Apple.
+-- sync_token — позиция в журнале изменений календаря, channel_* — канал push-уведомлений Google
+CREATE TABLE IF NOT EXISTS calendar_links (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    provider VARCHAR(20) NOT NULL,
+    calendar_id TEXT NOT NULL,
+    username TEXT NOT NULL DEFAULT '',
+    secret TEXT NOT NULL,
+    conflict_policy VARCHAR(20) NOT NULL DEFAULT 'latest',
+    sync_token TEXT NOT NULL DEFAULT '',
+    channel_id VARCHAR(64),
+    channel_token VARCHAR(64),
+    channel_resource_id TEXT,
+    channel_expires_at TIMESTAMP WITH TIME ZONE,
+    synced_at TIMESTAMP WITH TIME ZONE,
+    last_error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    UNIQUE (user_id, provider, calendar_id)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_calendar_links_channel_id ON calendar_links(channel_id) WHERE channel_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_calendar_links_synced_at ON calendar_links(synced_at NULLS FIRST);
+
+-- Согласованный срок задачи и события. Внешнего ключа на tasks нет: событие удаляется из календаря
+-- по событию удаления задачи, которое обрабатывается уже после удаления строки задачи
+CREATE TABLE IF NOT EXISTS calendar_sync_state (
+    link_id UUID NOT NULL REFERENCES calendar_links(id) ON DELETE CASCADE,
+    task_id UUID NOT NULL,
+    event_id TEXT NOT NULL,
+    due_date TIMESTAMP WITH TIME ZONE NOT NULL,
+    synced_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    PRIMARY KEY (link_id, task_id),
+    UNIQUE (link_id, event_id)
+);
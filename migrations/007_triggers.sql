@@ -0,0 +1,13 @@
+-- Пользовательские триггеры: событие, фильтр и действие
+CREATE TABLE IF NOT EXISTS triggers (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    event VARCHAR(50) NOT NULL,
+    filter TEXT NOT NULL DEFAULT '',
+    action_type VARCHAR(20) NOT NULL CHECK (action_type IN ('webhook', 'slack', 'email')),
+    action_target TEXT NOT NULL,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_triggers_user_event ON triggers(user_id, event) WHERE enabled;
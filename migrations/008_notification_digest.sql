@@ -0,0 +1,3 @@
+-- Окно группировки уведомлений в дайджест, 0 — отправлять сразу
+ALTER TABLE notification_preferences
+    ADD COLUMN IF NOT EXISTS digest_window_minutes INTEGER NOT NULL DEFAULT 15;
@@ -0,0 +1,11 @@
+-- Каналы, типы событий и тихие часы уведомлений
+ALTER TABLE notification_preferences
+    ADD COLUMN IF NOT EXISTS channels TEXT[] NOT NULL DEFAULT '{push}',
+    ADD COLUMN IF NOT EXISTS event_types TEXT[] NOT NULL DEFAULT '{}',
+    ADD COLUMN IF NOT EXISTS quiet_hours_start VARCHAR(5) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS quiet_hours_end VARCHAR(5) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS timezone VARCHAR(64) NOT NULL DEFAULT 'UTC';
+
+-- push_enabled заменен списком каналов
+UPDATE notification_preferences SET channels = '{}' WHERE NOT push_enabled;
+ALTER TABLE notification_preferences DROP COLUMN IF EXISTS push_enabled;
@@ -0,0 +1,8 @@
+-- Ежедневные снимки аналитики пользователя для исторических трендов
+CREATE TABLE IF NOT EXISTS analytics_snapshots (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    snapshot_date DATE NOT NULL,
+    analytics JSONB NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    PRIMARY KEY (user_id, snapshot_date)
+);
@@ -0,0 +1,21 @@
+-- Уведомление об изменении задач через LISTEN/NOTIFY: payload — ID владельца задачи.
+-- Все экземпляры приложения слушают канал и сбрасывают кэши пользователя
+CREATE OR REPLACE FUNCTION notify_task_change() RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        PERFORM pg_notify('task_changes', OLD.user_id::text);
+        RETURN OLD;
+    END IF;
+
+    PERFORM pg_notify('task_changes', NEW.user_id::text);
+    IF TG_OP = 'UPDATE' AND OLD.user_id IS DISTINCT FROM NEW.user_id THEN
+        PERFORM pg_notify('task_changes', OLD.user_id::text);
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS tasks_notify_change ON tasks;
+CREATE TRIGGER tasks_notify_change
+    AFTER INSERT OR UPDATE OR DELETE ON tasks
+    FOR EACH ROW EXECUTE FUNCTION notify_task_change();
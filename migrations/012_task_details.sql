@@ -0,0 +1,12 @@
+-- Описание необязательно: пустое описание хранится как NULL
+ALTER TABLE tasks ALTER COLUMN description DROP NOT NULL;
+UPDATE tasks SET description = NULL WHERE description = '';
+
+-- Полнотекстовый индекс не должен обнуляться для задач без описания
+DROP INDEX IF EXISTS idx_tasks_title_description;
+CREATE INDEX IF NOT EXISTS idx_tasks_title_description
+    ON tasks USING GIN (to_tsvector('english', title || ' ' || COALESCE(description, '')));
+
+-- Подробные заметки и ссылки задачи
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS notes TEXT;
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS links JSONB NOT NULL DEFAULT '[]';
@@ -0,0 +1,11 @@
+-- Связи "related to" между задачами, без семантики блокировки.
+-- Связь симметрична и хранится одной строкой: task_id < related_task_id
+CREATE TABLE IF NOT EXISTS task_relations (
+    task_id VARCHAR(255) NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    related_task_id VARCHAR(255) NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    PRIMARY KEY (task_id, related_task_id),
+    CHECK (task_id < related_task_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_task_relations_related ON task_relations(related_task_id);
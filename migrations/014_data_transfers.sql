@@ -0,0 +1,14 @@
+-- История импорта и экспорта задач пользователя
+CREATE TABLE IF NOT EXISTS data_transfers (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    direction VARCHAR(10) NOT NULL CHECK (direction IN ('import', 'export')),
+    format VARCHAR(10) NOT NULL,
+    status VARCHAR(10) NOT NULL CHECK (status IN ('completed', 'failed')),
+    item_count INTEGER NOT NULL DEFAULT 0,
+    duration_ms BIGINT NOT NULL DEFAULT 0,
+    error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_data_transfers_user_created ON data_transfers(user_id, created_at DESC);
@@ -0,0 +1,2 @@
+-- Выборка пользователей с недавно измененными задачами для пересчета аналитики
+CREATE INDEX IF NOT EXISTS idx_tasks_updated_at ON tasks(updated_at);
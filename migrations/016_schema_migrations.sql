@@ -0,0 +1,6 @@
+-- Версия схемы: entrypoint записывает номер последней примененной миграции,
+-- приложение сверяет его с каталогом миграций при запуске
+CREATE TABLE IF NOT EXISTS schema_migrations (
+    version INTEGER PRIMARY KEY,
+    applied_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,11 @@
+-- Ежедневная статистика запросов пользователей к API, переносится из счетчиков Redis
+CREATE TABLE IF NOT EXISTS api_usage (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    usage_date DATE NOT NULL,
+    requests BIGINT NOT NULL DEFAULT 0,
+    errors BIGINT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    PRIMARY KEY (user_id, usage_date)
+);
+
+CREATE INDEX IF NOT EXISTS idx_api_usage_date ON api_usage(usage_date);
@@ -0,0 +1,27 @@
+-- Имперсонация: администратор поддержки получает токен от имени пользователя.
+-- Токен действует до expires_at, отозванный (revoked_at) отклоняется при каждом запросе
+CREATE TABLE IF NOT EXISTS impersonations (
+    id UUID PRIMARY KEY,
+    admin_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    reason TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    revoked_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_impersonations_user_id ON impersonations(user_id);
+
+-- Журнал аудита. Без внешних ключей: записи сохраняются после удаления пользователя
+CREATE TABLE IF NOT EXISTS audit_events (
+    id BIGSERIAL PRIMARY KEY,
+    action VARCHAR(64) NOT NULL,
+    actor_id UUID NOT NULL,
+    impersonator_id UUID,
+    target TEXT NOT NULL DEFAULT '',
+    details JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_events_actor ON audit_events(actor_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_events_impersonator ON audit_events(impersonator_id, created_at DESC) WHERE impersonator_id IS NOT NULL;
@@ -0,0 +1,11 @@
+-- Секреты подписи webhook-триггеров. После ротации предыдущий секрет продолжает
+-- подписывать запросы сутки, чтобы получатель успел обновить ключ
+ALTER TABLE triggers
+    ADD COLUMN IF NOT EXISTS secret TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS previous_secret TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS secret_rotated_at TIMESTAMP WITH TIME ZONE;
+
+-- существующие webhook-триггеры получают секрет сразу
+UPDATE triggers
+SET secret = 'whsec_' || replace(gen_random_uuid()::text || gen_random_uuid()::text, '-', '')
+WHERE action_type = 'webhook' AND secret = '';
@@ -0,0 +1,12 @@
+-- Входящие webhook: токен хранится только в виде SHA-256
+CREATE TABLE IF NOT EXISTS incoming_hooks (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    token_hash TEXT NOT NULL UNIQUE,
+    mapping JSONB NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_used_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_incoming_hooks_user_id ON incoming_hooks(user_id);
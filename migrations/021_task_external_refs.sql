@@ -0,0 +1,19 @@
+-- Связи задач с задачами внешних трекеров (GitHub issues, Jira).
+-- Состояние внешней задачи периодически обновляется фоновой задачей
+CREATE TABLE IF NOT EXISTS task_external_refs (
+    id UUID PRIMARY KEY,
+    task_id VARCHAR(255) NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    provider VARCHAR(20) NOT NULL,
+    external_key VARCHAR(255) NOT NULL,
+    url TEXT NOT NULL DEFAULT '',
+    title TEXT NOT NULL DEFAULT '',
+    status VARCHAR(100) NOT NULL DEFAULT '',
+    closed BOOLEAN NOT NULL DEFAULT FALSE,
+    mirror_completion BOOLEAN NOT NULL DEFAULT FALSE,
+    checked_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    UNIQUE (task_id, provider, external_key)
+);
+
+CREATE INDEX IF NOT EXISTS idx_task_external_refs_checked_at ON task_external_refs(checked_at NULLS FIRST);
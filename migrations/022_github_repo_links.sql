@@ -0,0 +1,9 @@
+-- Репозитории GitHub, push-события которых закрывают задачи пользователя по сообщениям коммитов
+CREATE TABLE IF NOT EXISTS github_repo_links (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    repository VARCHAR(255) NOT NULL,
+    secret TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    UNIQUE (user_id, repository)
+);
@@ -0,0 +1,3 @@
+-- Деактивация учетных записей администратором: вход и токены отклоняются, задачи сохраняются
+ALTER TABLE users ADD COLUMN IF NOT EXISTS active BOOLEAN NOT NULL DEFAULT true;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS deactivated_at TIMESTAMP WITH TIME ZONE;
@@ -0,0 +1,18 @@
+-- Теги задач и правила автоматической расстановки тегов по заголовку и описанию
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS tags TEXT[] NOT NULL DEFAULT '{}';
+
+CREATE INDEX IF NOT EXISTS idx_tasks_tags ON tasks USING GIN (tags);
+
+CREATE TABLE IF NOT EXISTS tagging_rules (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    match_type VARCHAR(20) NOT NULL CHECK (match_type IN ('keyword', 'regex')),
+    field VARCHAR(20) NOT NULL CHECK (field IN ('title', 'description', 'any')),
+    pattern TEXT NOT NULL,
+    tags TEXT[] NOT NULL,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_tagging_rules_user_id ON tagging_rules(user_id) WHERE enabled;
@@ -0,0 +1,7 @@
+-- Подзадачи: ссылка на родительскую задачу того же пользователя. При удалении родителя подзадачи
+-- становятся задачами верхнего уровня. Проверка отложена до конца транзакции, чтобы восстановление
+-- из резервной копии могло вставлять задачи в любом порядке
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS parent_id VARCHAR(255)
+    REFERENCES tasks(id) ON DELETE SET NULL DEFERRABLE INITIALLY DEFERRED;
+
+CREATE INDEX IF NOT EXISTS idx_tasks_parent_id ON tasks(parent_id) WHERE parent_id IS NOT NULL;
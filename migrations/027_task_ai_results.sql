@@ -0,0 +1,13 @@
+-- Результаты AI-ассистента по задаче (краткое содержание, предложенные подзадачи), по одному на действие.
+-- Хранятся отдельно от tasks, чтобы сохранение результата не меняло updated_at и не рассылало task_changes.
+-- source_hash — хэш текста задачи, по которому получен результат: после правки задачи он устаревает
+CREATE TABLE IF NOT EXISTS task_ai_results (
+    task_id VARCHAR(255) NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    action VARCHAR(20) NOT NULL CHECK (action IN ('summary', 'subtasks')),
+    summary TEXT NOT NULL DEFAULT '',
+    subtasks TEXT[] NOT NULL DEFAULT '{}',
+    model VARCHAR(255) NOT NULL,
+    source_hash VARCHAR(64) NOT NULL,
+    generated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    PRIMARY KEY (task_id, action)
+);
@@ -0,0 +1,24 @@
+-- Повторяющиеся задачи. Серия хранит состояние, правило (RRULE) копируется в каждый экземпляр.
+-- last_task_id — последний созданный экземпляр: следующий создается из него, когда он выполнен
+-- или его срок прошел. NULL — серия завершена (COUNT, UNTIL или экземпляр удален)
+CREATE TABLE IF NOT EXISTS task_recurrences (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    rule TEXT NOT NULL,
+    paused BOOLEAN NOT NULL DEFAULT false,
+    occurrences INTEGER NOT NULL DEFAULT 1,
+    last_task_id VARCHAR(255),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
+
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS recurrence TEXT;
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS recurrence_id UUID REFERENCES task_recurrences(id) ON DELETE SET NULL;
+
+-- серия и первый экземпляр создаются одним запросом, поэтому проверка ссылки отложена до конца транзакции
+ALTER TABLE task_recurrences DROP CONSTRAINT IF EXISTS task_recurrences_last_task_id_fkey;
+ALTER TABLE task_recurrences ADD CONSTRAINT task_recurrences_last_task_id_fkey
+    FOREIGN KEY (last_task_id) REFERENCES tasks(id) ON DELETE SET NULL DEFERRABLE INITIALLY DEFERRED;
+
+CREATE INDEX IF NOT EXISTS idx_task_recurrences_active ON task_recurrences(last_task_id)
+    WHERE last_task_id IS NOT NULL AND NOT paused;
@@ -0,0 +1,22 @@
+-- Векторные представления задач для поиска похожих (pgvector). Размерность зависит от модели,
+-- поэтому колонка без размерности: сравниваются только векторы одной модели одного пользователя.
+-- Расширение есть не в каждом образе Postgres: без него таблица не создается, и приложение
+-- отключает поиск похожих задач. После установки pgvector миграцию можно выполнить повторно
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM pg_available_extensions WHERE name = 'vector') THEN
+        CREATE EXTENSION IF NOT EXISTS vector;
+
+        CREATE TABLE IF NOT EXISTS task_embeddings (
+            task_id VARCHAR(255) PRIMARY KEY REFERENCES tasks(id) ON DELETE CASCADE,
+            user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+            model VARCHAR(255) NOT NULL,
+            source_hash VARCHAR(64) NOT NULL,
+            embedding vector NOT NULL,
+            updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+        );
+
+        CREATE INDEX IF NOT EXISTS idx_task_embeddings_user_model ON task_embeddings(user_id, model);
+    END IF;
+END
+$$;
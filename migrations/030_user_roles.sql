@@ -0,0 +1,6 @@
+-- Роль пользователя: admin дает доступ к административному API. Роль попадает в токен доступа,
+-- поэтому ее изменение действует со следующего входа
+ALTER TABLE users ADD COLUMN IF NOT EXISTS role VARCHAR(20) NOT NULL DEFAULT 'user';
+
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_role_check;
+ALTER TABLE users ADD CONSTRAINT users_role_check CHECK (role IN ('user', 'admin'));
@@ -0,0 +1,7 @@
+-- Пороги матрицы Эйзенхауэра пользователя. Нет строки — пороги по умолчанию
+CREATE TABLE IF NOT EXISTS matrix_settings (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    urgent_within_hours INTEGER NOT NULL,
+    important_priority task_priority NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
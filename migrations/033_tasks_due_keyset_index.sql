@@ -0,0 +1,2 @@
+-- Постраничная выдача по курсору: задачи пользователя после (due_date, id) в том же порядке
+CREATE INDEX IF NOT EXISTS idx_tasks_user_due_id ON tasks(user_id, due_date, id);
@@ -0,0 +1,5 @@
+-- Исполнитель задачи: владелец может назначить задачу другому пользователю. Исполнитель видит и меняет
+-- задачу, но не удаляет ее; при удалении исполнителя задача остается неназначенной
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS assignee_id UUID REFERENCES users(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_tasks_assignee_id ON tasks(assignee_id, due_date) WHERE assignee_id IS NOT NULL;
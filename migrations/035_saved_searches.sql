@@ -0,0 +1,26 @@
+-- Сохраненные поиски и оповещения о новых задачах, подходящих под них
+CREATE TABLE IF NOT EXISTS saved_searches (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    query TEXT NOT NULL,
+    timezone VARCHAR(64) NOT NULL DEFAULT 'UTC',
+    alert BOOLEAN NOT NULL DEFAULT FALSE,
+    alert_interval_minutes INTEGER NOT NULL DEFAULT 60 CHECK (alert_interval_minutes >= 0),
+    last_alert_at TIMESTAMP WITH TIME ZONE,
+    -- совпадения, о которых не уведомили из-за интервала; попадают в следующее уведомление
+    suppressed_matches INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_saved_searches_user_id ON saved_searches(user_id) WHERE alert;
+
+-- задачи, о которых оповещение уже сработало: изменение задачи, которая уже подходила, не уведомляет повторно
+CREATE TABLE IF NOT EXISTS saved_search_matches (
+    search_id UUID NOT NULL REFERENCES saved_searches(id) ON DELETE CASCADE,
+    task_id UUID NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    matched_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    PRIMARY KEY (search_id, task_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_saved_search_matches_task_id ON saved_search_matches(task_id);
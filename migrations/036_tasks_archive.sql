@@ -0,0 +1,22 @@
+-- Холодное хранилище задач: фоновая задача archive_tasks переносит сюда задачи, выполненные раньше
+-- TASK_ARCHIVE_AFTER_MONTHS месяцев назад, чтобы tasks и его индексы оставались небольшими.
+-- Репозиторий читает обе таблицы, изменение архивной задачи возвращает ее в tasks.
+-- Колонки повторяют tasks: новую колонку tasks нужно добавлять и сюда.
+-- parent_id без внешнего ключа: родитель может лежать в любой из таблиц
+CREATE TABLE IF NOT EXISTS tasks_archive (
+    LIKE tasks INCLUDING DEFAULTS,
+    PRIMARY KEY (id),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (assignee_id) REFERENCES users(id) ON DELETE SET NULL,
+    FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE SET NULL,
+    FOREIGN KEY (recurrence_id) REFERENCES task_recurrences(id) ON DELETE SET NULL
+);
+
+ALTER TABLE tasks_archive ADD COLUMN IF NOT EXISTS archived_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now();
+
+CREATE INDEX IF NOT EXISTS idx_tasks_archive_user_due_id ON tasks_archive(user_id, due_date, id);
+CREATE INDEX IF NOT EXISTS idx_tasks_archive_assignee_id ON tasks_archive(assignee_id, due_date) WHERE assignee_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_tasks_archive_parent_id ON tasks_archive(parent_id) WHERE parent_id IS NOT NULL;
+
+-- выборка кандидатов на перенос
+CREATE INDEX IF NOT EXISTS idx_tasks_completed_at ON tasks(completed_at) WHERE status = 'done';
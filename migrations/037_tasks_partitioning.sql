@@ -0,0 +1,136 @@
+-- Необязательное секционирование tasks по хэшу user_id для установок со 100M+ задач: индексы каждой секции
+-- остаются небольшими, а запросы с условием на user_id читают одну секцию.
+-- Миграция только создает функции, секционирует таблицу оператор: ./taskmanager task partition --partitions 16 --yes
+-- Требуется Postgres 13+ (BEFORE-триггеры на секционированной таблице).
+--
+-- Уникальный ключ секционированной таблицы обязан включать user_id, поэтому внешние ключи на tasks(id)
+-- заменяются триггерами: проверка ссылки при записи и ON DELETE CASCADE / SET NULL при удалении задачи.
+-- Триггеры проверки называются как прежние ограничения, и ошибки переводятся в ответы API так же
+
+-- ссылки на tasks(id), которые после секционирования поддерживают триггеры
+CREATE TABLE IF NOT EXISTS tasks_references (
+    table_name TEXT NOT NULL,
+    column_name TEXT NOT NULL,
+    -- cascade или set null
+    on_delete TEXT NOT NULL,
+    PRIMARY KEY (table_name, column_name)
+);
+
+-- проверка ссылки на задачу вместо внешнего ключа, TG_ARGV[0] — колонка со ссылкой.
+-- FOR KEY SHARE, как у внешнего ключа, не дает параллельно удалить задачу
+CREATE OR REPLACE FUNCTION tasks_check_reference() RETURNS TRIGGER AS $$
+DECLARE
+    found BOOLEAN;
+BEGIN
+    EXECUTE format(
+        'SELECT ($1).%1$I IS NULL OR EXISTS (SELECT 1 FROM tasks WHERE id = ($1).%1$I FOR KEY SHARE)', TG_ARGV[0]
+    ) INTO found USING NEW;
+
+    IF NOT found THEN
+        RAISE foreign_key_violation USING
+            MESSAGE = format('insert or update on table "%s" violates foreign key constraint "%s"', TG_TABLE_NAME, TG_NAME),
+            CONSTRAINT = TG_NAME,
+            TABLE = TG_TABLE_NAME,
+            COLUMN = TG_ARGV[0];
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+-- ON DELETE прежних внешних ключей для удаленной задачи
+CREATE OR REPLACE FUNCTION tasks_delete_references() RETURNS TRIGGER AS $$
+DECLARE
+    ref RECORD;
+BEGIN
+    FOR ref IN SELECT table_name, column_name, on_delete FROM tasks_references LOOP
+        IF ref.on_delete = 'cascade' THEN
+            EXECUTE format('DELETE FROM %I WHERE %I = $1', ref.table_name, ref.column_name) USING OLD.id;
+        ELSE
+            EXECUTE format('UPDATE %1$I SET %2$I = NULL WHERE %2$I = $1', ref.table_name, ref.column_name) USING OLD.id;
+        END IF;
+    END LOOP;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+-- partition_tasks переносит tasks в секционированную по хэшу user_id таблицу из partitions секций.
+-- Индексы, триггеры и внешние ключи tasks на другие таблицы пересоздаются на новой таблице.
+-- Таблица блокируется на все время переноса, запускать в окно обслуживания
+CREATE OR REPLACE FUNCTION partition_tasks(partitions INT) RETURNS VOID AS $$
+DECLARE
+    item RECORD;
+    index_defs TEXT[];
+    trigger_defs TEXT[];
+    fkey_defs TEXT[];
+    def TEXT;
+BEGIN
+    IF partitions < 2 THEN
+        RAISE EXCEPTION 'partitions must be at least 2';
+    END IF;
+    IF EXISTS (SELECT 1 FROM pg_partitioned_table WHERE partrelid = 'tasks'::regclass) THEN
+        RAISE EXCEPTION 'tasks is already partitioned';
+    END IF;
+
+    LOCK TABLE tasks IN ACCESS EXCLUSIVE MODE;
+
+    -- внешние ключи на tasks(id) заменяются триггерами проверки
+    FOR item IN
+        SELECT c.conname, c.conrelid::regclass::text AS table_name, a.attname AS column_name,
+            c.confdeltype, c.condeferrable, c.condeferred
+        FROM pg_constraint c
+        JOIN pg_attribute a ON a.attrelid = c.conrelid AND a.attnum = c.conkey[1]
+        WHERE c.contype = 'f' AND c.confrelid = 'tasks'::regclass
+    LOOP
+        INSERT INTO tasks_references (table_name, column_name, on_delete)
+        VALUES (item.table_name, item.column_name, CASE item.confdeltype WHEN 'c' THEN 'cascade' ELSE 'set null' END)
+        ON CONFLICT DO NOTHING;
+
+        EXECUTE format('ALTER TABLE %s DROP CONSTRAINT %I', item.table_name, item.conname);
+
+        -- ссылка подзадачи на родителя проверяется триггером каждой секции ниже
+        IF item.table_name <> 'tasks' THEN
+            EXECUTE format(
+                'CREATE CONSTRAINT TRIGGER %I AFTER INSERT OR UPDATE OF %I ON %s %s FOR EACH ROW EXECUTE FUNCTION tasks_check_reference(%L)',
+                item.conname, item.column_name, item.table_name,
+                CASE WHEN item.condeferred THEN 'DEFERRABLE INITIALLY DEFERRED' WHEN item.condeferrable THEN 'DEFERRABLE' ELSE '' END,
+                item.column_name);
+        END IF;
+    END LOOP;
+
+    -- определения запоминаются до удаления прежней таблицы, первичный ключ создается заново
+    SELECT array_agg(pg_get_indexdef(i.indexrelid)) INTO index_defs
+    FROM pg_index i WHERE i.indrelid = 'tasks'::regclass AND NOT i.indisprimary;
+    SELECT array_agg(pg_get_triggerdef(t.oid)) INTO trigger_defs
+    FROM pg_trigger t WHERE t.tgrelid = 'tasks'::regclass AND NOT t.tgisinternal;
+    SELECT array_agg(format('ALTER TABLE tasks ADD CONSTRAINT %I %s', c.conname, pg_get_constraintdef(c.oid))) INTO fkey_defs
+    FROM pg_constraint c WHERE c.conrelid = 'tasks'::regclass AND c.contype = 'f';
+
+    ALTER TABLE tasks RENAME TO tasks_unpartitioned;
+    CREATE TABLE tasks (
+        LIKE tasks_unpartitioned INCLUDING DEFAULTS INCLUDING CONSTRAINTS,
+        PRIMARY KEY (id, user_id)
+    ) PARTITION BY HASH (user_id);
+
+    FOR i IN 0..partitions - 1 LOOP
+        EXECUTE format('CREATE TABLE %I PARTITION OF tasks FOR VALUES WITH (MODULUS %s, REMAINDER %s)',
+            'tasks_p' || i, partitions, i);
+        EXECUTE format(
+            'CREATE CONSTRAINT TRIGGER tasks_parent_id_fkey AFTER INSERT OR UPDATE OF parent_id ON %I DEFERRABLE INITIALLY DEFERRED FOR EACH ROW EXECUTE FUNCTION tasks_check_reference(%L)',
+            'tasks_p' || i, 'parent_id');
+    END LOOP;
+
+    INSERT INTO tasks SELECT * FROM tasks_unpartitioned;
+    DROP TABLE tasks_unpartitioned;
+
+    FOREACH def IN ARRAY COALESCE(index_defs, '{}') || COALESCE(trigger_defs, '{}') LOOP
+        EXECUTE regexp_replace(def, ' ON (\S+\.)?tasks_unpartitioned ', ' ON tasks ');
+    END LOOP;
+    FOREACH def IN ARRAY COALESCE(fkey_defs, '{}') LOOP
+        EXECUTE def;
+    END LOOP;
+
+    CREATE TRIGGER tasks_delete_references
+        AFTER DELETE ON tasks
+        FOR EACH ROW EXECUTE FUNCTION tasks_delete_references();
+END;
+$$ LANGUAGE plpgsql;
@@ -0,0 +1,24 @@
+-- Журнал изменений задач для выгрузки в хранилища данных (CDC). Записи пишутся из конвейера событий
+-- по одной на задачу, seq возрастает в порядке фиксации: запись ведется под advisory-блокировкой,
+-- так что потребитель может читать журнал после последнего полученного seq без пропусков.
+-- Внешних ключей нет: журнал переживает удаление задач и пользователей до истечения CDC_RETENTION
+CREATE TABLE IF NOT EXISTS task_changes (
+    seq BIGSERIAL PRIMARY KEY,
+    task_id VARCHAR(255) NOT NULL,
+    user_id VARCHAR(255) NOT NULL,
+    -- номер изменения задачи: по нему потребитель отбрасывает устаревшие версии
+    version BIGINT NOT NULL,
+    type VARCHAR(32) NOT NULL,
+    -- состояние задачи после изменения, для удаления — последнее известное
+    task JSONB NOT NULL,
+    occurred_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    recorded_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_task_changes_recorded_at ON task_changes(recorded_at);
+
+-- последний номер изменения задачи; хранится отдельно, чтобы очистка журнала не сбрасывала нумерацию
+CREATE TABLE IF NOT EXISTS task_change_versions (
+    task_id VARCHAR(255) PRIMARY KEY,
+    version BIGINT NOT NULL
+);
@@ -0,0 +1,12 @@
+-- Чаты Telegram, в которые бот отправляет уведомления пользователя.
+-- daily_digest_at — время ежедневной сводки в часовом поясе настроек уведомлений, NULL — без сводки;
+-- last_digest_on — локальная дата последней отправленной сводки
+CREATE TABLE IF NOT EXISTS telegram_links (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    chat_id BIGINT NOT NULL,
+    daily_digest_at VARCHAR(5),
+    last_digest_on DATE,
+    linked_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_telegram_links_digest ON telegram_links(user_id) WHERE daily_digest_at IS NOT NULL;
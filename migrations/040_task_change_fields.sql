@@ -0,0 +1,2 @@
+-- Измененные поля задачи для записей task.updated журнала изменений: [{"field", "from", "to"}]
+ALTER TABLE task_changes ADD COLUMN IF NOT EXISTS changes JSONB;
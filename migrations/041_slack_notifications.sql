@@ -0,0 +1,12 @@
+-- Личный Slack incoming webhook пользователя для канала уведомлений slack
+ALTER TABLE notification_preferences
+    ADD COLUMN IF NOT EXISTS slack_webhook_url TEXT NOT NULL DEFAULT '';
+
+-- Отправленные уведомления о просроченных задачах: одно на пару (задача, срок).
+-- Без внешнего ключа: после секционирования tasks (миграция 037) id задачи не уникален сам по себе
+CREATE TABLE IF NOT EXISTS task_overdue_alerts (
+    task_id UUID NOT NULL,
+    due_date TIMESTAMP WITH TIME ZONE NOT NULL,
+    sent_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    PRIMARY KEY (task_id, due_date)
+);
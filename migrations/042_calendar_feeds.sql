@@ -0,0 +1,8 @@
+-- Подписки календарей на задачи пользователя. Ссылка подписки подписана ключом сервера и содержит version:
+-- перевыпуск увеличивает version, и прежние ссылки перестают действовать, удаление строки отзывает подписку
+CREATE TABLE IF NOT EXISTS calendar_feeds (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    version INTEGER NOT NULL DEFAULT 1,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
@@ -0,0 +1,57 @@
+package client
+
+import (
+	"context"
+	"net/http"
+)
+
+type credentials struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+// Register регистрирует пользователя. Запрос не повторяется: после потерянного ответа
+// повтор получил бы 409 для уже созданного пользователя
+func (c *Client) Register(ctx context.Context, email, password string) error {
+	return c.do(ctx, request{
+		method: http.MethodPost,
+		path:   "/api/auth/register",
+		body:   credentials{Email: email, Password: password},
+	}, nil)
+}
+
+// Login входит по email и паролю и запоминает полученный токен для следующих запросов
+func (c *Client) Login(ctx context.Context, email, password string) (LoginResponse, error) {
+	var resp LoginResponse
+	err := c.do(ctx, request{
+		method: http.MethodPost,
+		path:   "/api/auth/login",
+		body:   credentials{Email: email, Password: password},
+		retry:  true,
+	}, &resp)
+	if err != nil {
+		return LoginResponse{}, err
+	}
+
+	c.SetToken(resp.Token)
+	return resp, nil
+}
+
+// Me текущий пользователь
+func (c *Client) Me(ctx context.Context) (WhoAmI, error) {
+	var me WhoAmI
+	err := c.do(ctx, request{method: http.MethodGet, path: "/api/auth/me", retry: true}, &me)
+	return me, err
+}
+
+// ChangePassword меняет пароль текущего пользователя
+func (c *Client) ChangePassword(ctx context.Context, currentPassword, newPassword string) error {
+	return c.do(ctx, request{
+		method: http.MethodPut,
+		path:   "/api/auth/password",
+		body: struct {
+			CurrentPassword string `json:"current_password"`
+			NewPassword     string `json:"new_password"`
+		}{currentPassword, newPassword},
+	}, nil)
+}
@@ -0,0 +1,302 @@
+// Package client Go-клиент REST API менеджера задач для других сервисов: вход, регистрация
+// и операции с задачами с типизированными запросами и ответами. Запросы принимают context,
+// временные сбои (сетевые ошибки, 429, 502, 503, 504) повторяются с экспоненциальной задержкой.
+// Клиент работает с версией API apiVersion и не зависит от внутренних пакетов сервиса
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// apiVersion версия API, под которую написан клиент: список задач приходит страницей,
+	// удаление отвечает 204
+	apiVersion = "4"
+
+	apiVersionHeader     = "API-Version"
+	idempotencyKeyHeader = "Idempotency-Key"
+
+	// ответ ограничен по размеру, чтобы сбойный сервер не исчерпал память
+	maxResponseBytes = 32 << 20
+
+	defaultRetries    = 3
+	defaultBackoff    = 200 * time.Millisecond
+	defaultMaxBackoff = 5 * time.Second
+	defaultTimeout    = 30 * time.Second
+)
+
+// APIError ответ сервера с кодом не 2xx. Message — поле error тела ответа
+type APIError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("taskmanager: status %d", e.StatusCode)
+	}
+	return fmt.Sprintf("taskmanager: status %d: %s", e.StatusCode, e.Message)
+}
+
+// IsNotFound сервер ответил 404
+func IsNotFound(err error) bool {
+	return statusOf(err) == http.StatusNotFound
+}
+
+// IsUnauthorized сервер ответил 401: токена нет или он истек
+func IsUnauthorized(err error) bool {
+	return statusOf(err) == http.StatusUnauthorized
+}
+
+func statusOf(err error) int {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode
+	}
+	return 0
+}
+
+// Client клиент API. Безопасен для одновременного использования из нескольких горутин
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+	retries    int
+	backoff    time.Duration
+	maxBackoff time.Duration
+	newKey     func() string
+
+	mu    sync.RWMutex
+	token string
+}
+
+// Option настройка New
+type Option func(*Client)
+
+// WithHTTPClient HTTP-клиент вместо клиента с таймаутом 30 секунд
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithToken токен доступа, например выданный сервису заранее; Login заменяет его
+func WithToken(token string) Option {
+	return func(c *Client) {
+		c.token = token
+	}
+}
+
+// WithRetries число повторов после первой попытки и начальная задержка между ними,
+// задержка удваивается с каждым повтором. retries 0 отключает повторы
+func WithRetries(retries int, backoff time.Duration) Option {
+	return func(c *Client) {
+		c.retries = retries
+		c.backoff = backoff
+	}
+}
+
+// New создает клиент. baseURL — адрес сервиса без /api, например https://tasks.example.com
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: &http.Client{Timeout: defaultTimeout},
+		retries:    defaultRetries,
+		backoff:    defaultBackoff,
+		maxBackoff: defaultMaxBackoff,
+		newKey:     newIdempotencyKey,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Token текущий токен доступа
+func (c *Client) Token() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.token
+}
+
+// SetToken заменяет токен доступа
+func (c *Client) SetToken(token string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.token = token
+}
+
+// request описание запроса к API
+type request struct {
+	method string
+	path   string
+	query  url.Values
+	body   interface{}
+	// retry запрос можно повторить без побочных эффектов
+	retry bool
+	// idempotent запрос отправляется с Idempotency-Key, один на все попытки
+	idempotent bool
+}
+
+// do выполняет запрос с повторами и декодирует тело ответа в out, если out не nil
+func (c *Client) do(ctx context.Context, req request, out interface{}) error {
+	var body []byte
+	if req.body != nil {
+		data, err := json.Marshal(req.body)
+		if err != nil {
+			return fmt.Errorf("failed to encode request: %w", err)
+		}
+		body = data
+	}
+
+	target := c.baseURL + req.path
+	if len(req.query) > 0 {
+		target += "?" + req.query.Encode()
+	}
+
+	var key string
+	if req.idempotent {
+		key = c.newKey()
+	}
+
+	for attempt := 0; ; attempt++ {
+		resp, err := c.send(ctx, req.method, target, body, key)
+		retryable := req.retry || req.idempotent
+		if err == nil {
+			if !retryable || !retryStatus(resp.StatusCode) || attempt >= c.retries {
+				defer resp.Body.Close()
+				return decodeResponse(resp, out)
+			}
+			delay := retryAfter(resp.Header.Get("Retry-After"))
+			drain(resp)
+			if delay == 0 {
+				delay = c.delay(attempt)
+			}
+			if err := sleep(ctx, delay); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if ctx.Err() != nil || !retryable || attempt >= c.retries {
+			return err
+		}
+		if err := sleep(ctx, c.delay(attempt)); err != nil {
+			return err
+		}
+	}
+}
+
+// send одна попытка запроса
+func (c *Client) send(ctx context.Context, method, target string, body []byte, key string) (*http.Response, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, method, target, reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	httpReq.Header.Set("Accept", "application/json")
+	httpReq.Header.Set(apiVersionHeader, apiVersion)
+	if body != nil {
+		httpReq.Header.Set("Content-Type", "application/json")
+	}
+	if key != "" {
+		httpReq.Header.Set(idempotencyKeyHeader, key)
+	}
+	if token := c.Token(); token != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	return resp, nil
+}
+
+// delay задержка перед повтором номер attempt+1
+func (c *Client) delay(attempt int) time.Duration {
+	delay := c.backoff << attempt
+	if delay <= 0 || delay > c.maxBackoff {
+		return c.maxBackoff
+	}
+	return delay
+}
+
+// retryStatus ответы, после которых запрос стоит повторить
+func retryStatus(status int) bool {
+	switch status {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// retryAfter задержка из заголовка Retry-After в секундах, 0 — заголовка нет
+func retryAfter(value string) time.Duration {
+	seconds, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || seconds <= 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// sleep ждет delay или отмены ctx
+func sleep(ctx context.Context, delay time.Duration) error {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// drain дочитывает и закрывает тело, чтобы соединение вернулось в пул
+func drain(resp *http.Response) {
+	io.Copy(io.Discard, io.LimitReader(resp.Body, maxResponseBytes))
+	resp.Body.Close()
+}
+
+// decodeResponse переводит ответ не 2xx в *APIError, тело успешного ответа декодирует в out
+func decodeResponse(resp *http.Response, out interface{}) error {
+	body := io.LimitReader(resp.Body, maxResponseBytes)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		var payload struct {
+			Error string `json:"error"`
+		}
+		json.NewDecoder(body).Decode(&payload)
+		return &APIError{StatusCode: resp.StatusCode, Message: payload.Error}
+	}
+
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	if err := json.NewDecoder(body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+// newIdempotencyKey случайный ключ идемпотентности для создающего запроса
+func newIdempotencyKey() string {
+	return uuid.NewString()
+}
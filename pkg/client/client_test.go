@@ -0,0 +1,133 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestClient(t *testing.T, handler http.HandlerFunc) *Client {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	return New(server.URL, WithRetries(2, time.Millisecond))
+}
+
+func TestLogin_StoresToken(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/auth/login":
+			var creds credentials
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&creds))
+			assert.Equal(t, "user@example.com", creds.Email)
+			json.NewEncoder(w).Encode(LoginResponse{Token: "secret", TokenType: "Bearer"})
+		case "/api/auth/me":
+			assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+			json.NewEncoder(w).Encode(WhoAmI{ID: "user1", Email: "user@example.com"})
+		}
+	})
+
+	resp, err := c.Login(context.Background(), "user@example.com", "password")
+	require.NoError(t, err)
+	assert.Equal(t, "secret", resp.Token)
+	assert.Equal(t, "secret", c.Token())
+
+	me, err := c.Me(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "user1", me.ID)
+}
+
+func TestListTasks(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, apiVersion, r.Header.Get(apiVersionHeader))
+		assert.Equal(t, "done", r.URL.Query().Get("status"))
+		assert.Equal(t, "me", r.URL.Query().Get("assignee"))
+		assert.Equal(t, "10", r.URL.Query().Get("limit"))
+		assert.False(t, r.URL.Query().Has("offset"))
+		json.NewEncoder(w).Encode(TaskPage{Tasks: []Task{{ID: "task1"}}, Total: 11, NextCursor: "next"})
+	})
+
+	page, err := c.ListTasks(context.Background(), ListOptions{Status: StatusDone, Assigned: true, Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, 11, page.Total)
+	assert.Equal(t, "next", page.NextCursor)
+	require.Len(t, page.Tasks, 1)
+	assert.Equal(t, "task1", page.Tasks[0].ID)
+}
+
+func TestCreateTask_RetriesWithSameIdempotencyKey(t *testing.T) {
+	var calls atomic.Int32
+	keys := make(chan string, 3)
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		keys <- r.Header.Get(idempotencyKeyHeader)
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(Task{ID: "task1", Title: "New"})
+	})
+
+	task, err := c.CreateTask(context.Background(), Task{Title: "New"})
+	require.NoError(t, err)
+	assert.Equal(t, "task1", task.ID)
+
+	first, second := <-keys, <-keys
+	assert.NotEmpty(t, first)
+	assert.Equal(t, first, second)
+}
+
+func TestDo_Errors(t *testing.T) {
+	var calls atomic.Int32
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		switch r.URL.Path {
+		case "/api/tasks/missing":
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error":"Task not found"}`))
+		default:
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	})
+
+	// 404 не повторяется
+	_, err := c.GetTask(context.Background(), "missing")
+	assert.True(t, IsNotFound(err))
+	assert.EqualError(t, err, "taskmanager: status 404: Task not found")
+	assert.Equal(t, int32(1), calls.Load())
+
+	// временный сбой повторяется retries раз, затем возвращается последний ответ
+	calls.Store(0)
+	err = c.DeleteTask(context.Background(), "task1")
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusBadGateway, apiErr.StatusCode)
+	assert.Equal(t, int32(3), calls.Load())
+
+	// регистрация без ключа идемпотентности не повторяется
+	calls.Store(0)
+	assert.Error(t, c.Register(context.Background(), "user@example.com", "password"))
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestDo_ContextCancelStopsRetries(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "60")
+		w.WriteHeader(http.StatusTooManyRequests)
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := c.GetTask(ctx, "task1")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
@@ -0,0 +1,112 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// ListTasks страница задач текущего пользователя по фильтрам
+func (c *Client) ListTasks(ctx context.Context, opts ListOptions) (TaskPage, error) {
+	var page TaskPage
+	err := c.do(ctx, request{
+		method: http.MethodGet,
+		path:   "/api/tasks",
+		query:  opts.values(),
+		retry:  true,
+	}, &page)
+	return page, err
+}
+
+// GetTask задача по ID
+func (c *Client) GetTask(ctx context.Context, id string) (Task, error) {
+	var task Task
+	err := c.do(ctx, request{method: http.MethodGet, path: taskPath(id), retry: true}, &task)
+	return task, err
+}
+
+// UnlockTask приватная задача с расшифрованными полями
+func (c *Client) UnlockTask(ctx context.Context, id string) (Task, error) {
+	var task Task
+	err := c.do(ctx, request{method: http.MethodPost, path: taskPath(id) + "/unlock", retry: true}, &task)
+	return task, err
+}
+
+// CreateTask создает задачу. Все попытки отправляются с одним Idempotency-Key,
+// поэтому повтор после потерянного ответа не создает вторую задачу
+func (c *Client) CreateTask(ctx context.Context, task Task) (Task, error) {
+	var created Task
+	err := c.do(ctx, request{
+		method:     http.MethodPost,
+		path:       "/api/tasks",
+		body:       task,
+		idempotent: true,
+	}, &created)
+	return created, err
+}
+
+// UpdateTask заменяет поля задачи; пустые поля, кроме описания, остаются без изменений
+func (c *Client) UpdateTask(ctx context.Context, id string, task Task) (Task, error) {
+	var updated Task
+	err := c.do(ctx, request{method: http.MethodPut, path: taskPath(id), body: task, retry: true}, &updated)
+	return updated, err
+}
+
+// PatchTask меняет только переданные поля задачи
+func (c *Client) PatchTask(ctx context.Context, id string, patch TaskPatch) (Task, error) {
+	var updated Task
+	err := c.do(ctx, request{method: http.MethodPatch, path: taskPath(id), body: patch, retry: true}, &updated)
+	return updated, err
+}
+
+// DeleteTask удаляет задачу
+func (c *Client) DeleteTask(ctx context.Context, id string) error {
+	return c.do(ctx, request{method: http.MethodDelete, path: taskPath(id), retry: true}, nil)
+}
+
+// CompleteTasks выполняет задачи одним запросом; уже выполненные попадают в Skipped
+func (c *Client) CompleteTasks(ctx context.Context, ids []string) (CompleteTasksResult, error) {
+	var result CompleteTasksResult
+	err := c.do(ctx, request{
+		method: http.MethodPost,
+		path:   "/api/tasks/complete",
+		body: struct {
+			IDs []string `json:"ids"`
+		}{ids},
+		retry: true,
+	}, &result)
+	return result, err
+}
+
+func taskPath(id string) string {
+	return "/api/tasks/" + url.PathEscape(id)
+}
+
+// values параметры запроса списка
+func (o ListOptions) values() url.Values {
+	query := url.Values{}
+	set := func(name, value string) {
+		if value != "" {
+			query.Set(name, value)
+		}
+	}
+
+	set("status", string(o.Status))
+	set("priority", string(o.Priority))
+	set("tag", o.Tag)
+	set("project_id", o.ProjectID)
+	set("q", o.Query)
+	set("sort", o.Sort)
+	set("cursor", o.Cursor)
+	if o.Assigned {
+		query.Set("assignee", "me")
+	}
+	if o.Limit > 0 {
+		query.Set("limit", strconv.Itoa(o.Limit))
+	}
+	if o.Offset > 0 {
+		query.Set("offset", strconv.Itoa(o.Offset))
+	}
+	return query
+}
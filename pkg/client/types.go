@@ -0,0 +1,130 @@
+package client
+
+import "time"
+
+// Status статус задачи
+type Status string
+
+// Priority приоритет задачи
+type Priority string
+
+const (
+	StatusPending    Status = "pending"
+	StatusInProgress Status = "in_progress"
+	StatusDone       Status = "done"
+	// StatusOverdue только для ListOptions: невыполненные задачи с прошедшим сроком
+	StatusOverdue Status = "overdue"
+
+	PriorityLow    Priority = "low"
+	PriorityMedium Priority = "medium"
+	PriorityHigh   Priority = "high"
+)
+
+// Task задача в ответах API и в запросах на создание и замену
+type Task struct {
+	ID          string     `json:"id,omitempty"`
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	Notes       *string    `json:"notes,omitempty"`
+	Links       []TaskLink `json:"links,omitempty"`
+	Tags        []string   `json:"tags,omitempty"`
+	Status      Status     `json:"status,omitempty"`
+	Priority    Priority   `json:"priority,omitempty"`
+	UserID      string     `json:"user_id,omitempty"`
+	ParentID    *string    `json:"parent_id,omitempty"`
+	ProjectID   *string    `json:"project_id,omitempty"`
+	AssigneeID  *string    `json:"assignee_id,omitempty"`
+	Recurrence  string     `json:"recurrence,omitempty"`
+	DueDate     time.Time  `json:"due_date"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	// Private заголовок и описание хранятся зашифрованными
+	Private bool `json:"private"`
+	// Locked приватная задача отдана без расшифровки, для просмотра нужен UnlockTask
+	Locked bool `json:"locked,omitempty"`
+	// IsOverdue задача не выполнена и срок прошел, вычисляется сервером
+	IsOverdue bool `json:"is_overdue,omitempty"`
+}
+
+// TaskLink ссылка, прикрепленная к задаче
+type TaskLink struct {
+	URL   string `json:"url"`
+	Title string `json:"title,omitempty"`
+}
+
+// TaskPatch частичное обновление задачи: меняются только поля, отличные от nil
+type TaskPatch struct {
+	Title       *string     `json:"title,omitempty"`
+	Description *string     `json:"description,omitempty"`
+	Notes       *string     `json:"notes,omitempty"`
+	Links       *[]TaskLink `json:"links,omitempty"`
+	Tags        *[]string   `json:"tags,omitempty"`
+	Status      *Status     `json:"status,omitempty"`
+	Priority    *Priority   `json:"priority,omitempty"`
+	DueDate     *time.Time  `json:"due_date,omitempty"`
+	Private     *bool       `json:"private,omitempty"`
+	ParentID    *string     `json:"parent_id,omitempty"`
+	ProjectID   *string     `json:"project_id,omitempty"`
+	// CompleteSubtasks при переводе в done выполняет и открытые подзадачи
+	CompleteSubtasks bool `json:"complete_subtasks,omitempty"`
+}
+
+// ListOptions фильтры и страница списка задач, пустые поля не передаются
+type ListOptions struct {
+	Status    Status
+	Priority  Priority
+	Tag       string
+	ProjectID string
+	// Query поисковый запрос в синтаксисе параметра q, например "status:done #work"
+	Query string
+	// Sort "smart" или "due"
+	Sort string
+	// Assigned задачи других пользователей, назначенные текущему
+	Assigned bool
+	Limit    int
+	Offset   int
+	// Cursor NextCursor предыдущей страницы
+	Cursor string
+}
+
+// TaskPage страница списка задач
+type TaskPage struct {
+	Tasks []Task `json:"tasks"`
+	// Total число задач, подходящих под фильтры, на всех страницах
+	Total int `json:"total"`
+	// NextCursor курсор следующей страницы, у последней страницы пустой
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// CompleteTasksResult результат пакетного выполнения задач
+type CompleteTasksResult struct {
+	Completed []Task `json:"completed"`
+	// Skipped ID задач, которые уже были выполнены
+	Skipped []string `json:"skipped"`
+}
+
+// UserProfile пользователь в ответе на вход
+type UserProfile struct {
+	ID        string    `json:"id"`
+	Email     string    `json:"email"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// LoginResponse ответ на вход
+type LoginResponse struct {
+	Token     string      `json:"token"`
+	TokenType string      `json:"token_type"`
+	ExpiresAt time.Time   `json:"expires_at"`
+	ExpiresIn int         `json:"expires_in"`
+	User      UserProfile `json:"user"`
+}
+
+// WhoAmI текущий пользователь
+type WhoAmI struct {
+	ID             string    `json:"id"`
+	Email          string    `json:"email"`
+	Roles          []string  `json:"roles"`
+	ImpersonatorID string    `json:"impersonator_id,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+}
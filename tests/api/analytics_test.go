@@ -0,0 +1,142 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// createAnalyticsTask создает задачу с заданными статусом, приоритетом и сроком
+func createAnalyticsTask(t *testing.T, env *TestEnv, token string, status models.Status, priority models.Priority, due time.Time) models.Task {
+	req := CreateTaskRequest{
+		Title:    "Analytics " + string(status),
+		Status:   string(status),
+		Priority: string(priority),
+		DueDate:  due,
+	}
+
+	resp, err := makeRequest(env, "POST", "/api/tasks", req, token)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	var task models.Task
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&task))
+	return task
+}
+
+// setTaskTimes выставляет время создания и завершения задачи в обход API: их назначает база
+func setTaskTimes(t *testing.T, env *TestEnv, taskID string, createdAt time.Time, completedAt *time.Time) {
+	_, err := env.DB.Exec(`UPDATE tasks SET created_at = $1, completed_at = $2 WHERE id = $3`, createdAt, completedAt, taskID)
+	require.NoError(t, err)
+}
+
+// TestAnalytics проверяет точные числа аналитики на известном наборе задач для каждого периода
+func TestAnalytics(t *testing.T) {
+	env, cleanup := SetupTestEnv(t)
+	defer cleanup()
+
+	_, token := createTestUser(t, env)
+	_, otherToken := createTestUser(t, env)
+	now := time.Now()
+
+	// выполнена в срок за 6 часов
+	onTime := createAnalyticsTask(t, env, token, models.StatusDone, models.PriorityHigh, now.Add(48*time.Hour))
+	setTaskTimes(t, env, onTime.ID, now.Add(-10*time.Hour), ptr(now.Add(-4*time.Hour)))
+
+	// выполнена на сутки позже срока за 48 часов
+	late := createAnalyticsTask(t, env, token, models.StatusDone, models.PriorityMedium, now.Add(-48*time.Hour))
+	setTaskTimes(t, env, late.ID, now.Add(-72*time.Hour), ptr(now.Add(-24*time.Hour)))
+
+	// не выполнена, срок прошел
+	overdue := createAnalyticsTask(t, env, token, models.StatusPending, models.PriorityLow, now.Add(-24*time.Hour))
+	setTaskTimes(t, env, overdue.ID, now.Add(-96*time.Hour), nil)
+
+	// выполнена и снова открыта: прежнее время завершения остается в базе, но задача считается открытой и просроченной
+	reopened := createAnalyticsTask(t, env, token, models.StatusDone, models.PriorityMedium, now.Add(-2*time.Hour))
+	resp, err := makeRequest(env, "PATCH", "/api/tasks/"+reopened.ID, UpdateTaskRequest{Status: ptr(string(models.StatusInProgress))}, token)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	setTaskTimes(t, env, reopened.ID, now.Add(-30*time.Hour), ptr(now.Add(-20*time.Hour)))
+
+	// не выполнена, срок впереди
+	createAnalyticsTask(t, env, token, models.StatusPending, models.PriorityLow, now.Add(24*time.Hour))
+
+	// задачи другого пользователя не учитываются
+	createAnalyticsTask(t, env, otherToken, models.StatusDone, models.PriorityHigh, now.Add(-time.Hour))
+	createAnalyticsTask(t, env, otherToken, models.StatusPending, models.PriorityHigh, now.Add(-time.Hour))
+
+	// за сутки учитываются задачи, созданные и выполненные в последние 24 часа;
+	// просроченные считаются на текущий момент независимо от периода
+	day := models.Analytics{
+		StatusCount:          map[models.Status]int{models.StatusDone: 1, models.StatusPending: 1},
+		PriorityCount:        map[models.Priority]int{models.PriorityHigh: 1, models.PriorityLow: 1},
+		AvgCompletionTime:    6,
+		OnTimeCompletionRate: 100,
+		OverdueTasks:         2,
+	}
+	// за неделю и месяц — все задачи пользователя
+	all := models.Analytics{
+		StatusCount: map[models.Status]int{
+			models.StatusDone:       2,
+			models.StatusPending:    2,
+			models.StatusInProgress: 1,
+		},
+		PriorityCount: map[models.Priority]int{
+			models.PriorityHigh:   1,
+			models.PriorityMedium: 2,
+			models.PriorityLow:    2,
+		},
+		// (6 + 48) / 2 часа
+		AvgCompletionTime:    27,
+		OnTimeCompletionRate: 50,
+		OverdueTasks:         2,
+	}
+
+	for period, want := range map[string]models.Analytics{"day": day, "week": all, "month": all} {
+		t.Run(period, func(t *testing.T) {
+			resp, err := makeRequest(env, "GET", "/api/task-analytics?period="+period, nil, token)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			require.Equal(t, http.StatusOK, resp.StatusCode)
+
+			var analytics models.Analytics
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&analytics))
+
+			assert.Equal(t, period, analytics.Period)
+			assert.Equal(t, want.StatusCount, analytics.StatusCount)
+			assert.Equal(t, want.PriorityCount, analytics.PriorityCount)
+			assert.InDelta(t, want.AvgCompletionTime, analytics.AvgCompletionTime, 0.01)
+			assert.InDelta(t, want.OnTimeCompletionRate, analytics.OnTimeCompletionRate, 0.01)
+			assert.Equal(t, want.OverdueTasks, analytics.OverdueTasks)
+		})
+	}
+
+	t.Run("No tasks", func(t *testing.T) {
+		_, emptyToken := createTestUser(t, env)
+		resp, err := makeRequest(env, "GET", "/api/task-analytics?period=week", nil, emptyToken)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var analytics models.Analytics
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&analytics))
+		assert.Empty(t, analytics.StatusCount)
+		assert.Zero(t, analytics.AvgCompletionTime)
+		assert.Zero(t, analytics.OnTimeCompletionRate)
+		assert.Zero(t, analytics.OverdueTasks)
+	})
+
+	t.Run("Invalid period", func(t *testing.T) {
+		resp, err := makeRequest(env, "GET", "/api/task-analytics?period=year", nil, token)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+}
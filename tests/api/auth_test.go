@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -120,6 +121,11 @@ func TestAuth(t *testing.T) {
 					err := json.NewDecoder(resp.Body).Decode(&loginResp)
 					require.NoError(t, err)
 					assert.NotEmpty(t, loginResp.Token)
+					assert.Equal(t, "Bearer", loginResp.TokenType)
+					assert.Positive(t, loginResp.ExpiresIn)
+					assert.True(t, loginResp.ExpiresAt.After(time.Now()))
+					assert.Equal(t, validUser.Email, loginResp.User.Email)
+					assert.NotEmpty(t, loginResp.User.ID)
 				}
 			})
 		}
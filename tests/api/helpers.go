@@ -80,7 +80,7 @@ func createTestTasks(t *testing.T, env *TestEnv, token string, count int) []mode
 func verifyTaskInDB(t *testing.T, env *TestEnv, taskID string) models.Task {
 	var task models.Task
 	err := env.DB.QueryRow(
-		"SELECT id, title, description, status, priority, user_id, due_date, created_at, updated_at FROM tasks WHERE id = $1",
+		"SELECT id, title, COALESCE(description, ''), status, priority, user_id, due_date, created_at, updated_at FROM tasks WHERE id = $1",
 		taskID,
 	).Scan(
 		&task.ID,
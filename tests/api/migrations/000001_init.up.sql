@@ -9,12 +9,718 @@ CREATE TABLE IF NOT EXISTS users (
 CREATE TABLE IF NOT EXISTS tasks (
     id UUID PRIMARY KEY,
     user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
-    title VARCHAR(255) NOT NULL,
+    title TEXT NOT NULL,
     description TEXT,
     status VARCHAR(50) NOT NULL,
     priority VARCHAR(50) NOT NULL,
     due_date TIMESTAMP WITH TIME ZONE NOT NULL,
     completed_at TIMESTAMP WITH TIME ZONE,
     created_at TIMESTAMP WITH TIME ZONE NOT NULL,
-    updated_at TIMESTAMP WITH TIME ZONE NOT NULL
-); 
\ No newline at end of file
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    private BOOLEAN NOT NULL DEFAULT FALSE
+);
+
+ALTER TABLE users ALTER COLUMN created_at SET DEFAULT now();
+ALTER TABLE users ALTER COLUMN updated_at SET DEFAULT now();
+ALTER TABLE tasks ALTER COLUMN created_at SET DEFAULT now();
+ALTER TABLE tasks ALTER COLUMN updated_at SET DEFAULT now();
+
+-- Обновление updated_at при любом изменении строки
+CREATE OR REPLACE FUNCTION set_updated_at() RETURNS TRIGGER AS $$
+BEGIN
+    NEW.updated_at = now();
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Проставление completed_at при переходе задачи в статус done
+CREATE OR REPLACE FUNCTION set_task_completed_at() RETURNS TRIGGER AS $$
+BEGIN
+    IF NEW.status = 'done' AND NEW.completed_at IS NULL THEN
+        NEW.completed_at = now();
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS users_set_updated_at ON users;
+CREATE TRIGGER users_set_updated_at
+    BEFORE UPDATE ON users
+    FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+
+DROP TRIGGER IF EXISTS tasks_set_updated_at ON tasks;
+CREATE TRIGGER tasks_set_updated_at
+    BEFORE UPDATE ON tasks
+    FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+
+DROP TRIGGER IF EXISTS tasks_set_completed_at ON tasks;
+CREATE TRIGGER tasks_set_completed_at
+    BEFORE INSERT OR UPDATE OF status ON tasks
+    FOR EACH ROW EXECUTE FUNCTION set_task_completed_at();
+
+-- Подписки браузеров на Web Push
+CREATE TABLE IF NOT EXISTS push_subscriptions (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    endpoint TEXT NOT NULL UNIQUE,
+    p256dh VARCHAR(255) NOT NULL,
+    auth VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_push_subscriptions_user_id ON push_subscriptions(user_id);
+
+-- Настройки уведомлений пользователя
+CREATE TABLE IF NOT EXISTS notification_preferences (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    push_enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    due_soon_window_minutes INTEGER NOT NULL DEFAULT 60,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
+
+-- Отправленные напоминания: одно на пару (задача, срок)
+CREATE TABLE IF NOT EXISTS task_reminders (
+    task_id UUID NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    due_date TIMESTAMP WITH TIME ZONE NOT NULL,
+    sent_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    PRIMARY KEY (task_id, due_date)
+);
+
+-- Двусторонняя синхронизация сроков задач с календарями Google и Apple.
+-- sync_token — позиция в журнале изменений календаря, channel_* — канал push-уведомлений Google
+CREATE TABLE IF NOT EXISTS calendar_links (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    provider VARCHAR(20) NOT NULL,
+    calendar_id TEXT NOT NULL,
+    username TEXT NOT NULL DEFAULT '',
+    secret TEXT NOT NULL,
+    conflict_policy VARCHAR(20) NOT NULL DEFAULT 'latest',
+    sync_token TEXT NOT NULL DEFAULT '',
+    channel_id VARCHAR(64),
+    channel_token VARCHAR(64),
+    channel_resource_id TEXT,
+    channel_expires_at TIMESTAMP WITH TIME ZONE,
+    synced_at TIMESTAMP WITH TIME ZONE,
+    last_error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    UNIQUE (user_id, provider, calendar_id)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_calendar_links_channel_id ON calendar_links(channel_id) WHERE channel_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_calendar_links_synced_at ON calendar_links(synced_at NULLS FIRST);
+
+-- Согласованный срок задачи и события. Внешнего ключа на tasks нет: событие удаляется из календаря
+-- по событию удаления задачи, которое обрабатывается уже после удаления строки задачи
+CREATE TABLE IF NOT EXISTS calendar_sync_state (
+    link_id UUID NOT NULL REFERENCES calendar_links(id) ON DELETE CASCADE,
+    task_id UUID NOT NULL,
+    event_id TEXT NOT NULL,
+    due_date TIMESTAMP WITH TIME ZONE NOT NULL,
+    synced_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    PRIMARY KEY (link_id, task_id),
+    UNIQUE (link_id, event_id)
+);
+
+-- Пользовательские триггеры: событие, фильтр и действие
+CREATE TABLE IF NOT EXISTS triggers (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    event VARCHAR(50) NOT NULL,
+    filter TEXT NOT NULL DEFAULT '',
+    action_type VARCHAR(20) NOT NULL CHECK (action_type IN ('webhook', 'slack', 'email')),
+    action_target TEXT NOT NULL,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_triggers_user_event ON triggers(user_id, event) WHERE enabled;
+
+-- Окно группировки уведомлений в дайджест, 0 — отправлять сразу
+ALTER TABLE notification_preferences
+    ADD COLUMN IF NOT EXISTS digest_window_minutes INTEGER NOT NULL DEFAULT 15;
+
+-- Каналы, типы событий и тихие часы уведомлений
+ALTER TABLE notification_preferences
+    ADD COLUMN IF NOT EXISTS channels TEXT[] NOT NULL DEFAULT '{push}',
+    ADD COLUMN IF NOT EXISTS event_types TEXT[] NOT NULL DEFAULT '{}',
+    ADD COLUMN IF NOT EXISTS quiet_hours_start VARCHAR(5) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS quiet_hours_end VARCHAR(5) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS timezone VARCHAR(64) NOT NULL DEFAULT 'UTC';
+
+-- push_enabled заменен списком каналов
+UPDATE notification_preferences SET channels = '{}' WHERE NOT push_enabled;
+ALTER TABLE notification_preferences DROP COLUMN IF EXISTS push_enabled;
+
+-- Ежедневные снимки аналитики пользователя для исторических трендов
+CREATE TABLE IF NOT EXISTS analytics_snapshots (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    snapshot_date DATE NOT NULL,
+    analytics JSONB NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    PRIMARY KEY (user_id, snapshot_date)
+);
+
+-- Уведомление об изменении задач через LISTEN/NOTIFY: payload — ID владельца задачи.
+-- Все экземпляры приложения слушают канал и сбрасывают кэши пользователя
+CREATE OR REPLACE FUNCTION notify_task_change() RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        PERFORM pg_notify('task_changes', OLD.user_id::text);
+        RETURN OLD;
+    END IF;
+
+    PERFORM pg_notify('task_changes', NEW.user_id::text);
+    IF TG_OP = 'UPDATE' AND OLD.user_id IS DISTINCT FROM NEW.user_id THEN
+        PERFORM pg_notify('task_changes', OLD.user_id::text);
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS tasks_notify_change ON tasks;
+CREATE TRIGGER tasks_notify_change
+    AFTER INSERT OR UPDATE OR DELETE ON tasks
+    FOR EACH ROW EXECUTE FUNCTION notify_task_change();
+
+-- Описание необязательно: пустое описание хранится как NULL
+ALTER TABLE tasks ALTER COLUMN description DROP NOT NULL;
+UPDATE tasks SET description = NULL WHERE description = '';
+
+-- Полнотекстовый индекс не должен обнуляться для задач без описания
+DROP INDEX IF EXISTS idx_tasks_title_description;
+CREATE INDEX IF NOT EXISTS idx_tasks_title_description
+    ON tasks USING GIN (to_tsvector('english', title || ' ' || COALESCE(description, '')));
+
+-- Подробные заметки и ссылки задачи
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS notes TEXT;
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS links JSONB NOT NULL DEFAULT '[]';
+
+-- Связи "related to" между задачами, без семантики блокировки.
+-- Связь симметрична и хранится одной строкой: task_id < related_task_id
+CREATE TABLE IF NOT EXISTS task_relations (
+    task_id UUID NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    related_task_id UUID NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    PRIMARY KEY (task_id, related_task_id),
+    CHECK (task_id < related_task_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_task_relations_related ON task_relations(related_task_id);
+
+-- История импорта и экспорта задач пользователя
+CREATE TABLE IF NOT EXISTS data_transfers (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    direction VARCHAR(10) NOT NULL CHECK (direction IN ('import', 'export')),
+    format VARCHAR(10) NOT NULL,
+    status VARCHAR(10) NOT NULL CHECK (status IN ('completed', 'failed')),
+    item_count INTEGER NOT NULL DEFAULT 0,
+    duration_ms BIGINT NOT NULL DEFAULT 0,
+    error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_data_transfers_user_created ON data_transfers(user_id, created_at DESC);
+
+-- Выборка пользователей с недавно измененными задачами для пересчета аналитики
+CREATE INDEX IF NOT EXISTS idx_tasks_updated_at ON tasks(updated_at);
+
+-- Версия схемы: entrypoint записывает номер последней примененной миграции,
+-- приложение сверяет его с каталогом миграций при запуске
+CREATE TABLE IF NOT EXISTS schema_migrations (
+    version INTEGER PRIMARY KEY,
+    applied_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Ежедневная статистика запросов пользователей к API, переносится из счетчиков Redis
+CREATE TABLE IF NOT EXISTS api_usage (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    usage_date DATE NOT NULL,
+    requests BIGINT NOT NULL DEFAULT 0,
+    errors BIGINT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    PRIMARY KEY (user_id, usage_date)
+);
+
+CREATE INDEX IF NOT EXISTS idx_api_usage_date ON api_usage(usage_date);
+
+-- Имперсонация: администратор поддержки получает токен от имени пользователя.
+-- Токен действует до expires_at, отозванный (revoked_at) отклоняется при каждом запросе
+CREATE TABLE IF NOT EXISTS impersonations (
+    id UUID PRIMARY KEY,
+    admin_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    reason TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    revoked_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_impersonations_user_id ON impersonations(user_id);
+
+-- Журнал аудита. Без внешних ключей: записи сохраняются после удаления пользователя
+CREATE TABLE IF NOT EXISTS audit_events (
+    id BIGSERIAL PRIMARY KEY,
+    action VARCHAR(64) NOT NULL,
+    actor_id UUID NOT NULL,
+    impersonator_id UUID,
+    target TEXT NOT NULL DEFAULT '',
+    details JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_events_actor ON audit_events(actor_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_events_impersonator ON audit_events(impersonator_id, created_at DESC) WHERE impersonator_id IS NOT NULL;
+
+-- Секреты подписи webhook-триггеров. После ротации предыдущий секрет продолжает
+-- подписывать запросы сутки, чтобы получатель успел обновить ключ
+ALTER TABLE triggers
+    ADD COLUMN IF NOT EXISTS secret TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS previous_secret TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS secret_rotated_at TIMESTAMP WITH TIME ZONE;
+
+-- существующие webhook-триггеры получают секрет сразу
+UPDATE triggers
+SET secret = 'whsec_' || replace(gen_random_uuid()::text || gen_random_uuid()::text, '-', '')
+WHERE action_type = 'webhook' AND secret = '';
+
+-- Входящие webhook: токен хранится только в виде SHA-256
+CREATE TABLE IF NOT EXISTS incoming_hooks (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    token_hash TEXT NOT NULL UNIQUE,
+    mapping JSONB NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_used_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_incoming_hooks_user_id ON incoming_hooks(user_id);
+
+-- Связи задач с задачами внешних трекеров (GitHub issues, Jira).
+-- Состояние внешней задачи периодически обновляется фоновой задачей
+CREATE TABLE IF NOT EXISTS task_external_refs (
+    id UUID PRIMARY KEY,
+    task_id UUID NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    provider VARCHAR(20) NOT NULL,
+    external_key VARCHAR(255) NOT NULL,
+    url TEXT NOT NULL DEFAULT '',
+    title TEXT NOT NULL DEFAULT '',
+    status VARCHAR(100) NOT NULL DEFAULT '',
+    closed BOOLEAN NOT NULL DEFAULT FALSE,
+    mirror_completion BOOLEAN NOT NULL DEFAULT FALSE,
+    checked_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    UNIQUE (task_id, provider, external_key)
+);
+
+CREATE INDEX IF NOT EXISTS idx_task_external_refs_checked_at ON task_external_refs(checked_at NULLS FIRST);
+
+-- Репозитории GitHub, push-события которых закрывают задачи пользователя по сообщениям коммитов
+CREATE TABLE IF NOT EXISTS github_repo_links (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    repository VARCHAR(255) NOT NULL,
+    secret TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    UNIQUE (user_id, repository)
+);
+
+-- Прежние хэши паролей пользователя для запрета повторного использования пароля
+CREATE TABLE IF NOT EXISTS password_history (
+    id BIGSERIAL PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    password_hash VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_password_history_user_id ON password_history(user_id, id DESC);
+
+-- Деактивация учетных записей администратором: вход и токены отклоняются, задачи сохраняются
+ALTER TABLE users ADD COLUMN IF NOT EXISTS active BOOLEAN NOT NULL DEFAULT true;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS deactivated_at TIMESTAMP WITH TIME ZONE;
+
+-- Теги задач и правила автоматической расстановки тегов по заголовку и описанию
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS tags TEXT[] NOT NULL DEFAULT '{}';
+
+CREATE INDEX IF NOT EXISTS idx_tasks_tags ON tasks USING GIN (tags);
+
+CREATE TABLE IF NOT EXISTS tagging_rules (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    match_type VARCHAR(20) NOT NULL CHECK (match_type IN ('keyword', 'regex')),
+    field VARCHAR(20) NOT NULL CHECK (field IN ('title', 'description', 'any')),
+    pattern TEXT NOT NULL,
+    tags TEXT[] NOT NULL,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_tagging_rules_user_id ON tagging_rules(user_id) WHERE enabled;
+
+-- Подзадачи: ссылка на родительскую задачу того же пользователя. При удалении родителя подзадачи
+-- становятся задачами верхнего уровня. Проверка отложена до конца транзакции, чтобы восстановление
+-- из резервной копии могло вставлять задачи в любом порядке
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS parent_id UUID
+    REFERENCES tasks(id) ON DELETE SET NULL DEFERRABLE INITIALLY DEFERRED;
+
+CREATE INDEX IF NOT EXISTS idx_tasks_parent_id ON tasks(parent_id) WHERE parent_id IS NOT NULL;
+
+-- Результаты AI-ассистента по задаче (краткое содержание, предложенные подзадачи), по одному на действие.
+-- Хранятся отдельно от tasks, чтобы сохранение результата не меняло updated_at и не рассылало task_changes.
+-- source_hash — хэш текста задачи, по которому получен результат: после правки задачи он устаревает
+CREATE TABLE IF NOT EXISTS task_ai_results (
+    task_id UUID NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    action VARCHAR(20) NOT NULL CHECK (action IN ('summary', 'subtasks')),
+    summary TEXT NOT NULL DEFAULT '',
+    subtasks TEXT[] NOT NULL DEFAULT '{}',
+    model VARCHAR(255) NOT NULL,
+    source_hash VARCHAR(64) NOT NULL,
+    generated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    PRIMARY KEY (task_id, action)
+);
+
+-- Повторяющиеся задачи. Серия хранит состояние, правило (RRULE) копируется в каждый экземпляр.
+-- last_task_id — последний созданный экземпляр: следующий создается из него, когда он выполнен
+-- или его срок прошел. NULL — серия завершена (COUNT, UNTIL или экземпляр удален)
+CREATE TABLE IF NOT EXISTS task_recurrences (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    rule TEXT NOT NULL,
+    paused BOOLEAN NOT NULL DEFAULT false,
+    occurrences INTEGER NOT NULL DEFAULT 1,
+    last_task_id UUID,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
+
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS recurrence TEXT;
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS recurrence_id UUID REFERENCES task_recurrences(id) ON DELETE SET NULL;
+
+-- серия и первый экземпляр создаются одним запросом, поэтому проверка ссылки отложена до конца транзакции
+ALTER TABLE task_recurrences DROP CONSTRAINT IF EXISTS task_recurrences_last_task_id_fkey;
+ALTER TABLE task_recurrences ADD CONSTRAINT task_recurrences_last_task_id_fkey
+    FOREIGN KEY (last_task_id) REFERENCES tasks(id) ON DELETE SET NULL DEFERRABLE INITIALLY DEFERRED;
+
+CREATE INDEX IF NOT EXISTS idx_task_recurrences_active ON task_recurrences(last_task_id)
+    WHERE last_task_id IS NOT NULL AND NOT paused;
+
+-- Векторные представления задач для поиска похожих (pgvector). Размерность зависит от модели,
+-- поэтому колонка без размерности: сравниваются только векторы одной модели одного пользователя.
+-- Расширение есть не в каждом образе Postgres: без него таблица не создается, и приложение
+-- отключает поиск похожих задач. После установки pgvector миграцию можно выполнить повторно
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM pg_available_extensions WHERE name = 'vector') THEN
+        CREATE EXTENSION IF NOT EXISTS vector;
+
+        CREATE TABLE IF NOT EXISTS task_embeddings (
+            task_id UUID PRIMARY KEY REFERENCES tasks(id) ON DELETE CASCADE,
+            user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+            model VARCHAR(255) NOT NULL,
+            source_hash VARCHAR(64) NOT NULL,
+            embedding vector NOT NULL,
+            updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+        );
+
+        CREATE INDEX IF NOT EXISTS idx_task_embeddings_user_model ON task_embeddings(user_id, model);
+    END IF;
+END
+$$;
+
+-- Роль пользователя: admin дает доступ к административному API. Роль попадает в токен доступа,
+-- поэтому ее изменение действует со следующего входа
+ALTER TABLE users ADD COLUMN IF NOT EXISTS role VARCHAR(20) NOT NULL DEFAULT 'user';
+
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_role_check;
+ALTER TABLE users ADD CONSTRAINT users_role_check CHECK (role IN ('user', 'admin'));
+
+-- Пороги матрицы Эйзенхауэра пользователя. Нет строки — пороги по умолчанию
+CREATE TABLE IF NOT EXISTS matrix_settings (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    urgent_within_hours INTEGER NOT NULL,
+    important_priority task_priority NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
+
+-- Проекты пользователя для группировки задач. При удалении проекта задачи остаются без проекта
+CREATE TABLE IF NOT EXISTS projects (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    description TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    UNIQUE (user_id, name)
+);
+
+DROP TRIGGER IF EXISTS projects_set_updated_at ON projects;
+CREATE TRIGGER projects_set_updated_at
+    BEFORE UPDATE ON projects
+    FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS project_id UUID REFERENCES projects(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_tasks_project_id ON tasks(project_id) WHERE project_id IS NOT NULL;
+
+-- Постраничная выдача по курсору: задачи пользователя после (due_date, id) в том же порядке
+CREATE INDEX IF NOT EXISTS idx_tasks_user_due_id ON tasks(user_id, due_date, id);
+
+-- Исполнитель задачи: владелец может назначить задачу другому пользователю. Исполнитель видит и меняет
+-- задачу, но не удаляет ее; при удалении исполнителя задача остается неназначенной
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS assignee_id UUID REFERENCES users(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_tasks_assignee_id ON tasks(assignee_id, due_date) WHERE assignee_id IS NOT NULL;
+
+-- Сохраненные поиски и оповещения о новых задачах, подходящих под них
+CREATE TABLE IF NOT EXISTS saved_searches (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    query TEXT NOT NULL,
+    timezone VARCHAR(64) NOT NULL DEFAULT 'UTC',
+    alert BOOLEAN NOT NULL DEFAULT FALSE,
+    alert_interval_minutes INTEGER NOT NULL DEFAULT 60 CHECK (alert_interval_minutes >= 0),
+    last_alert_at TIMESTAMP WITH TIME ZONE,
+    -- совпадения, о которых не уведомили из-за интервала; попадают в следующее уведомление
+    suppressed_matches INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_saved_searches_user_id ON saved_searches(user_id) WHERE alert;
+
+-- задачи, о которых оповещение уже сработало: изменение задачи, которая уже подходила, не уведомляет повторно
+CREATE TABLE IF NOT EXISTS saved_search_matches (
+    search_id UUID NOT NULL REFERENCES saved_searches(id) ON DELETE CASCADE,
+    task_id UUID NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    matched_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    PRIMARY KEY (search_id, task_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_saved_search_matches_task_id ON saved_search_matches(task_id);
+
+-- Холодное хранилище задач: фоновая задача archive_tasks переносит сюда задачи, выполненные раньше
+-- TASK_ARCHIVE_AFTER_MONTHS месяцев назад, чтобы tasks и его индексы оставались небольшими.
+-- Репозиторий читает обе таблицы, изменение архивной задачи возвращает ее в tasks.
+-- Колонки повторяют tasks: новую колонку tasks нужно добавлять и сюда.
+-- parent_id без внешнего ключа: родитель может лежать в любой из таблиц
+CREATE TABLE IF NOT EXISTS tasks_archive (
+    LIKE tasks INCLUDING DEFAULTS,
+    PRIMARY KEY (id),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (assignee_id) REFERENCES users(id) ON DELETE SET NULL,
+    FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE SET NULL,
+    FOREIGN KEY (recurrence_id) REFERENCES task_recurrences(id) ON DELETE SET NULL
+);
+
+ALTER TABLE tasks_archive ADD COLUMN IF NOT EXISTS archived_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now();
+
+CREATE INDEX IF NOT EXISTS idx_tasks_archive_user_due_id ON tasks_archive(user_id, due_date, id);
+CREATE INDEX IF NOT EXISTS idx_tasks_archive_assignee_id ON tasks_archive(assignee_id, due_date) WHERE assignee_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_tasks_archive_parent_id ON tasks_archive(parent_id) WHERE parent_id IS NOT NULL;
+
+-- выборка кандидатов на перенос
+CREATE INDEX IF NOT EXISTS idx_tasks_completed_at ON tasks(completed_at) WHERE status = 'done';
+
+-- Необязательное секционирование tasks по хэшу user_id для установок со 100M+ задач: индексы каждой секции
+-- остаются небольшими, а запросы с условием на user_id читают одну секцию.
+-- Миграция только создает функции, секционирует таблицу оператор: ./taskmanager task partition --partitions 16 --yes
+-- Требуется Postgres 13+ (BEFORE-триггеры на секционированной таблице).
+--
+-- Уникальный ключ секционированной таблицы обязан включать user_id, поэтому внешние ключи на tasks(id)
+-- заменяются триггерами: проверка ссылки при записи и ON DELETE CASCADE / SET NULL при удалении задачи.
+-- Триггеры проверки называются как прежние ограничения, и ошибки переводятся в ответы API так же
+
+-- ссылки на tasks(id), которые после секционирования поддерживают триггеры
+CREATE TABLE IF NOT EXISTS tasks_references (
+    table_name TEXT NOT NULL,
+    column_name TEXT NOT NULL,
+    -- cascade или set null
+    on_delete TEXT NOT NULL,
+    PRIMARY KEY (table_name, column_name)
+);
+
+-- проверка ссылки на задачу вместо внешнего ключа, TG_ARGV[0] — колонка со ссылкой.
+-- FOR KEY SHARE, как у внешнего ключа, не дает параллельно удалить задачу
+CREATE OR REPLACE FUNCTION tasks_check_reference() RETURNS TRIGGER AS $$
+DECLARE
+    found BOOLEAN;
+BEGIN
+    EXECUTE format(
+        'SELECT ($1).%1$I IS NULL OR EXISTS (SELECT 1 FROM tasks WHERE id = ($1).%1$I FOR KEY SHARE)', TG_ARGV[0]
+    ) INTO found USING NEW;
+
+    IF NOT found THEN
+        RAISE foreign_key_violation USING
+            MESSAGE = format('insert or update on table "%s" violates foreign key constraint "%s"', TG_TABLE_NAME, TG_NAME),
+            CONSTRAINT = TG_NAME,
+            TABLE = TG_TABLE_NAME,
+            COLUMN = TG_ARGV[0];
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+-- ON DELETE прежних внешних ключей для удаленной задачи
+CREATE OR REPLACE FUNCTION tasks_delete_references() RETURNS TRIGGER AS $$
+DECLARE
+    ref RECORD;
+BEGIN
+    FOR ref IN SELECT table_name, column_name, on_delete FROM tasks_references LOOP
+        IF ref.on_delete = 'cascade' THEN
+            EXECUTE format('DELETE FROM %I WHERE %I = $1', ref.table_name, ref.column_name) USING OLD.id;
+        ELSE
+            EXECUTE format('UPDATE %1$I SET %2$I = NULL WHERE %2$I = $1', ref.table_name, ref.column_name) USING OLD.id;
+        END IF;
+    END LOOP;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+-- partition_tasks переносит tasks в секционированную по хэшу user_id таблицу из partitions секций.
+-- Индексы, триггеры и внешние ключи tasks на другие таблицы пересоздаются на новой таблице.
+-- Таблица блокируется на все время переноса, запускать в окно обслуживания
+CREATE OR REPLACE FUNCTION partition_tasks(partitions INT) RETURNS VOID AS $$
+DECLARE
+    item RECORD;
+    index_defs TEXT[];
+    trigger_defs TEXT[];
+    fkey_defs TEXT[];
+    def TEXT;
+BEGIN
+    IF partitions < 2 THEN
+        RAISE EXCEPTION 'partitions must be at least 2';
+    END IF;
+    IF EXISTS (SELECT 1 FROM pg_partitioned_table WHERE partrelid = 'tasks'::regclass) THEN
+        RAISE EXCEPTION 'tasks is already partitioned';
+    END IF;
+
+    LOCK TABLE tasks IN ACCESS EXCLUSIVE MODE;
+
+    -- внешние ключи на tasks(id) заменяются триггерами проверки
+    FOR item IN
+        SELECT c.conname, c.conrelid::regclass::text AS table_name, a.attname AS column_name,
+            c.confdeltype, c.condeferrable, c.condeferred
+        FROM pg_constraint c
+        JOIN pg_attribute a ON a.attrelid = c.conrelid AND a.attnum = c.conkey[1]
+        WHERE c.contype = 'f' AND c.confrelid = 'tasks'::regclass
+    LOOP
+        INSERT INTO tasks_references (table_name, column_name, on_delete)
+        VALUES (item.table_name, item.column_name, CASE item.confdeltype WHEN 'c' THEN 'cascade' ELSE 'set null' END)
+        ON CONFLICT DO NOTHING;
+
+        EXECUTE format('ALTER TABLE %s DROP CONSTRAINT %I', item.table_name, item.conname);
+
+        -- ссылка подзадачи на родителя проверяется триггером каждой секции ниже
+        IF item.table_name <> 'tasks' THEN
+            EXECUTE format(
+                'CREATE CONSTRAINT TRIGGER %I AFTER INSERT OR UPDATE OF %I ON %s %s FOR EACH ROW EXECUTE FUNCTION tasks_check_reference(%L)',
+                item.conname, item.column_name, item.table_name,
+                CASE WHEN item.condeferred THEN 'DEFERRABLE INITIALLY DEFERRED' WHEN item.condeferrable THEN 'DEFERRABLE' ELSE '' END,
+                item.column_name);
+        END IF;
+    END LOOP;
+
+    -- определения запоминаются до удаления прежней таблицы, первичный ключ создается заново
+    SELECT array_agg(pg_get_indexdef(i.indexrelid)) INTO index_defs
+    FROM pg_index i WHERE i.indrelid = 'tasks'::regclass AND NOT i.indisprimary;
+    SELECT array_agg(pg_get_triggerdef(t.oid)) INTO trigger_defs
+    FROM pg_trigger t WHERE t.tgrelid = 'tasks'::regclass AND NOT t.tgisinternal;
+    SELECT array_agg(format('ALTER TABLE tasks ADD CONSTRAINT %I %s', c.conname, pg_get_constraintdef(c.oid))) INTO fkey_defs
+    FROM pg_constraint c WHERE c.conrelid = 'tasks'::regclass AND c.contype = 'f';
+
+    ALTER TABLE tasks RENAME TO tasks_unpartitioned;
+    CREATE TABLE tasks (
+        LIKE tasks_unpartitioned INCLUDING DEFAULTS INCLUDING CONSTRAINTS,
+        PRIMARY KEY (id, user_id)
+    ) PARTITION BY HASH (user_id);
+
+    FOR i IN 0..partitions - 1 LOOP
+        EXECUTE format('CREATE TABLE %I PARTITION OF tasks FOR VALUES WITH (MODULUS %s, REMAINDER %s)',
+            'tasks_p' || i, partitions, i);
+        EXECUTE format(
+            'CREATE CONSTRAINT TRIGGER tasks_parent_id_fkey AFTER INSERT OR UPDATE OF parent_id ON %I DEFERRABLE INITIALLY DEFERRED FOR EACH ROW EXECUTE FUNCTION tasks_check_reference(%L)',
+            'tasks_p' || i, 'parent_id');
+    END LOOP;
+
+    INSERT INTO tasks SELECT * FROM tasks_unpartitioned;
+    DROP TABLE tasks_unpartitioned;
+
+    FOREACH def IN ARRAY COALESCE(index_defs, '{}') || COALESCE(trigger_defs, '{}') LOOP
+        EXECUTE regexp_replace(def, ' ON (\S+\.)?tasks_unpartitioned ', ' ON tasks ');
+    END LOOP;
+    FOREACH def IN ARRAY COALESCE(fkey_defs, '{}') LOOP
+        EXECUTE def;
+    END LOOP;
+
+    CREATE TRIGGER tasks_delete_references
+        AFTER DELETE ON tasks
+        FOR EACH ROW EXECUTE FUNCTION tasks_delete_references();
+END;
+$$ LANGUAGE plpgsql;
+
+-- Журнал изменений задач для выгрузки в хранилища данных (CDC). Записи пишутся из конвейера событий
+-- по одной на задачу, seq возрастает в порядке фиксации: запись ведется под advisory-блокировкой,
+-- так что потребитель может читать журнал после последнего полученного seq без пропусков.
+-- Внешних ключей нет: журнал переживает удаление задач и пользователей до истечения CDC_RETENTION
+CREATE TABLE IF NOT EXISTS task_changes (
+    seq BIGSERIAL PRIMARY KEY,
+    task_id VARCHAR(255) NOT NULL,
+    user_id VARCHAR(255) NOT NULL,
+    -- номер изменения задачи: по нему потребитель отбрасывает устаревшие версии
+    version BIGINT NOT NULL,
+    type VARCHAR(32) NOT NULL,
+    -- состояние задачи после изменения, для удаления — последнее известное
+    task JSONB NOT NULL,
+    occurred_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    recorded_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_task_changes_recorded_at ON task_changes(recorded_at);
+
+-- последний номер изменения задачи; хранится отдельно, чтобы очистка журнала не сбрасывала нумерацию
+CREATE TABLE IF NOT EXISTS task_change_versions (
+    task_id VARCHAR(255) PRIMARY KEY,
+    version BIGINT NOT NULL
+);
+
+-- Чаты Telegram, в которые бот отправляет уведомления пользователя.
+-- daily_digest_at — время ежедневной сводки в часовом поясе настроек уведомлений, NULL — без сводки;
+-- last_digest_on — локальная дата последней отправленной сводки
+CREATE TABLE IF NOT EXISTS telegram_links (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    chat_id BIGINT NOT NULL,
+    daily_digest_at VARCHAR(5),
+    last_digest_on DATE,
+    linked_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_telegram_links_digest ON telegram_links(user_id) WHERE daily_digest_at IS NOT NULL;
+
+-- Измененные поля задачи для записей task.updated журнала изменений: [{"field", "from", "to"}]
+ALTER TABLE task_changes ADD COLUMN IF NOT EXISTS changes JSONB;
+
+-- Личный Slack incoming webhook пользователя для канала уведомлений slack
+ALTER TABLE notification_preferences
+    ADD COLUMN IF NOT EXISTS slack_webhook_url TEXT NOT NULL DEFAULT '';
+
+-- Отправленные уведомления о просроченных задачах: одно на пару (задача, срок).
+-- Без внешнего ключа: после секционирования tasks (миграция 037) id задачи не уникален сам по себе
+CREATE TABLE IF NOT EXISTS task_overdue_alerts (
+    task_id UUID NOT NULL,
+    due_date TIMESTAMP WITH TIME ZONE NOT NULL,
+    sent_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    PRIMARY KEY (task_id, due_date)
+);
+
+-- Подписки календарей на задачи пользователя. Ссылка подписки подписана ключом сервера и содержит version:
+-- перевыпуск увеличивает version, и прежние ссылки перестают действовать, удаление строки отзывает подписку
+CREATE TABLE IF NOT EXISTS calendar_feeds (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    version INTEGER NOT NULL DEFAULT 1,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
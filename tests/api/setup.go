@@ -15,13 +15,8 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/jmoloko/taskmange/internal/cache"
+	"github.com/jmoloko/taskmange/internal/app"
 	"github.com/jmoloko/taskmange/internal/config"
-	"github.com/jmoloko/taskmange/internal/handler"
-	"github.com/jmoloko/taskmange/internal/logger"
-	"github.com/jmoloko/taskmange/internal/middleware"
-	"github.com/jmoloko/taskmange/internal/repository/postgres"
-	"github.com/jmoloko/taskmange/internal/service"
 	_ "github.com/lib/pq"
 	"github.com/redis/go-redis/v9"
 	"github.com/stretchr/testify/require"
@@ -31,7 +26,7 @@ import (
 
 // TestEnv содержит все зависимости для тестов
 type TestEnv struct {
-	API       *gin.Engine
+	App       *app.App
 	DB        *sql.DB
 	Redis     *redis.Client
 	PostgresC testcontainers.Container
@@ -150,49 +145,35 @@ func connectRedis(t *testing.T, container testcontainers.Container) *redis.Clien
 	return client
 }
 
-// setupAPI создает и настраивает экземпляр API
-func setupAPI(db *sql.DB, redisClient *redis.Client) *gin.Engine {
-	gin.SetMode(gin.TestMode)
+// testConfig конфигурация приложения для тестовых контейнеров
+func testConfig(t *testing.T) *config.Config {
+	cfg, err := config.Load()
+	require.NoError(t, err)
 
-	// Инициализируем зависимости
-	taskRepo := postgres.NewTaskRepository(db)
-	userRepo := postgres.NewUserRepository(db)
-	redisCache := cache.NewRedisCache(redisClient)
-	log := &logger.MockLogger{} // Используем мок логгер для тестов
-
-	// Создаем сервисы
-	taskService := service.NewTaskService(taskRepo, redisCache, log)
-	authService := service.NewAuthService(userRepo, log, "your-secret-key")
-
-	// Создаем обработчики
-	taskHandler := handler.NewTaskHandler(taskService, log)
-	authHandler := handler.NewAuthHandler(authService, log)
-
-	// Создаем и настраиваем роутер
-	router := gin.New()
-
-	// Регистрируем маршруты
-	api := router.Group("/api")
-	{
-		auth := api.Group("/auth")
-		{
-			auth.POST("/register", authHandler.Register)
-			auth.POST("/login", authHandler.Login)
-		}
+	cfg.Database.Host = "localhost"
+	cfg.Database.Port = "5433"
+	cfg.Database.User = "postgres"
+	cfg.Database.Password = "postgres"
+	cfg.Database.DBName = "taskmanager"
+	cfg.Database.SSLMode = "disable"
+	cfg.Redis.Host = "localhost"
+	cfg.Redis.Port = "6380"
+	cfg.Redis.DB = 0
+	cfg.Logger.Level = "warn"
+
+	return cfg
+}
 
-		tasks := api.Group("/tasks")
-		tasks.Use(middleware.AuthMiddleware(authService))
-		{
-			tasks.POST("", taskHandler.CreateTask)
-			tasks.GET("", taskHandler.GetTasks)
-			tasks.GET("/:id", taskHandler.GetTask)
-			tasks.PUT("/:id", taskHandler.UpdateTask)
-			tasks.DELETE("/:id", taskHandler.DeleteTask)
-			tasks.GET("/analytics", taskHandler.GetAnalytics)
-		}
-	}
+// setupAPI собирает приложение целиком, как cmd/app, и запускает его компоненты без прослушивания порта.
+// Запросы идут в обработчик приложения в том же процессе
+func setupAPI(t *testing.T, cfg *config.Config) *app.App {
+	gin.SetMode(gin.TestMode)
+
+	application, err := app.New(cfg)
+	require.NoError(t, err)
+	require.NoError(t, application.Start(context.Background()))
 
-	return router
+	return application
 }
 
 // SetupTestEnv создает тестовое окружение
@@ -216,20 +197,22 @@ func SetupTestEnv(t *testing.T) (*TestEnv, func()) {
 
 	// Создаем API
 	log.Printf("Setting up API...")
-	api := setupAPI(db, redis)
+	cfg := testConfig(t)
+	application := setupAPI(t, cfg)
 	log.Printf("API setup completed")
 
 	// Создаем тестовый сервер
 	log.Printf("Creating test server...")
-	server := httptest.NewServer(api)
+	server := httptest.NewServer(application.Handler())
 	log.Printf("Test server created at: %s", server.URL)
 
 	env := &TestEnv{
-		API:       api,
+		App:       application,
 		DB:        db,
 		Redis:     redis,
 		PostgresC: postgresC,
 		RedisC:    redisC,
+		Config:    cfg,
 		Server:    server,
 	}
 
@@ -237,6 +220,7 @@ func SetupTestEnv(t *testing.T) (*TestEnv, func()) {
 	cleanup := func() {
 		log.Printf("Cleaning up test environment...")
 		server.Close()
+		application.Shutdown(context.Background())
 		db.Close()
 		redis.Close()
 		postgresC.Terminate(context.Background())
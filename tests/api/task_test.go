@@ -1,9 +1,13 @@
 package api
 
 import (
+	"encoding/json"
 	"net/http"
 	"testing"
+	"time"
 
+	"github.com/jmoloko/taskmange/internal/domain/models"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
@@ -26,3 +30,35 @@ func TestTask(t *testing.T) {
 		require.Equal(t, http.StatusOK, resp.StatusCode)
 	})
 }
+
+func TestDeleteTasksByFilter(t *testing.T) {
+	env, cleanup := SetupTestEnv(t)
+	defer cleanup()
+
+	_, token := createTestUser(t, env)
+	tasks := createTestTasks(t, env, token, 3)
+
+	// две задачи выполнены год назад, третья остается открытой
+	completedAt := time.Now().AddDate(-1, 0, 0)
+	for _, task := range tasks[:2] {
+		_, err := env.DB.Exec(`UPDATE tasks SET status = 'done', completed_at = $1 WHERE id = $2`, completedAt, task.ID)
+		require.NoError(t, err)
+	}
+
+	resp, err := makeRequest(env, "DELETE", "/api/tasks", nil, token)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	resp, err = makeRequest(env, "DELETE", "/api/tasks?status=done&completed_before="+time.Now().AddDate(0, -1, 0).Format("2006-01-02"), nil, token)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var result models.BulkDeleteProgress
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+	assert.Equal(t, models.BulkDeleteProgress{Deleted: 2, Batches: 1, Done: true}, result)
+
+	resp, err = makeRequest(env, "GET", "/api/tasks/"+tasks[0].ID, nil, token)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	verifyTaskInDB(t, env, tasks[2].ID)
+}
@@ -16,7 +16,14 @@ type LoginRequest struct {
 
 // LoginResponse представляет ответ на успешную аутентификацию
 type LoginResponse struct {
-	Token string `json:"token"`
+	Token     string    `json:"token"`
+	TokenType string    `json:"token_type"`
+	ExpiresAt time.Time `json:"expires_at"`
+	ExpiresIn int       `json:"expires_in"`
+	User      struct {
+		ID    string `json:"id"`
+		Email string `json:"email"`
+	} `json:"user"`
 }
 
 // CreateTaskRequest представляет запрос на создание задачи
@@ -0,0 +1,1707 @@
+// Code generated by go run ./cmd/tsgen; DO NOT EDIT.
+// Source: Task Management API 1.0
+
+/** Версия API, ответы которой описывают типы клиента; отправляется в заголовке API-Version */
+export const API_VERSION = 4;
+
+/** Версия спецификации, из которой сгенерирован клиент */
+export const SPEC_VERSION = "1.0";
+
+export interface HandlerLiveResponse {
+  status?: SelfcheckStatus;
+}
+
+export interface HandlerReadyResponse {
+  checks?: SelfcheckResult[];
+  startup?: SelfcheckReport;
+  status?: SelfcheckStatus;
+}
+
+export type AIAction = "summary" | "subtasks";
+
+export interface AIResult {
+  action?: AIAction;
+  /** Cached результат взят из сохраненного на задаче, модель не вызывалась */
+  cached?: boolean;
+  generated_at?: string;
+  /** Model модель, сгенерировавшая результат */
+  model?: string;
+  /** Subtasks предложенные заголовки подзадач (действие subtasks), сами задачи не создаются */
+  subtasks?: string[];
+  /** Summary краткое содержание задачи (действие summary) */
+  summary?: string;
+}
+
+export interface Analytics {
+  /** Среднее время выполнения задачи (от создания до завершения) в часах */
+  avg_completion_time?: number;
+  /** Границы окна [from, to), только для period=custom */
+  from?: string;
+  /** Дата и время формирования отчета */
+  generated_at?: string;
+  /** Процент задач, выполненных в срок */
+  on_time_completion_rate?: number;
+  /** Текущее количество просроченных задач */
+  overdue_tasks?: number;
+  /** Период, за который собрана аналитика: day, week, month или custom для окна from–to */
+  period?: string;
+  /** Количество задач по приоритетам */
+  priority_count?: Record<string, number>;
+  /** Количество задач по статусам */
+  status_count?: Record<string, number>;
+  to?: string;
+}
+
+export interface AnalyticsSnapshot {
+  analytics?: Analytics;
+  /** День снимка, снимок за текущий день обновляется в течение дня */
+  date?: string;
+}
+
+export interface AssignTaskRequest {
+  assignee_id: string;
+}
+
+export type AuditAction = "impersonation.started" | "impersonation.revoked" | "impersonation.request" | "user.deactivated" | "user.reactivated" | "user.role_changed" | "task.deleted_by_admin";
+
+export interface AuditEvent {
+  action?: AuditAction;
+  /** ActorID пользователь, от имени которого выполнено действие */
+  actor_id?: string;
+  created_at?: string;
+  details?: Record<string, string>;
+  id?: number;
+  /** ImpersonatorID администратор, действовавший от имени ActorID */
+  impersonator_id?: string;
+  /** Target объект действия: ID имперсонации или метод и путь запроса */
+  target?: string;
+}
+
+export interface BulkDeleteProgress {
+  /** Batches сколько пачек удалено */
+  batches?: number;
+  /** Deleted сколько задач удалено с начала запроса */
+  deleted?: number;
+  /** Done удаление завершено, подходящих задач не осталось */
+  done?: boolean;
+  /** Error причина остановки удаления в потоке NDJSON; удаленные до нее задачи не восстанавливаются */
+  error?: string;
+}
+
+export type CalendarConflictPolicy = "latest" | "task" | "calendar";
+
+export interface CalendarFeed {
+  token?: string;
+  updated_at?: string;
+  /** URL путь ленты вместе с токеном, клиент дополняет его адресом сервера */
+  url?: string;
+}
+
+export interface CalendarLink {
+  /** CalendarID ID календаря Google или адрес коллекции CalDAV */
+  calendar_id?: string;
+  conflict_policy?: CalendarConflictPolicy;
+  created_at?: string;
+  id?: string;
+  /** LastError ошибка последней синхронизации, пустая после успешной */
+  last_error?: string;
+  provider?: CalendarProvider;
+  synced_at?: string;
+  /** Username Apple ID для CalDAV */
+  username?: string;
+}
+
+export interface CalendarLinkRequest {
+  /** CalendarID для Google ID календаря, по умолчанию primary; для Apple адрес коллекции CalDAV */
+  calendar_id?: string;
+  /** ConflictPolicy по умолчанию latest */
+  conflict_policy?: CalendarConflictPolicy;
+  password?: string;
+  provider: CalendarProvider;
+  /** RefreshToken OAuth refresh token Google с доступом к календарю, выданный клиенту сервера */
+  refresh_token?: string;
+  /** Username и Password Apple ID и пароль приложения для iCloud */
+  username?: string;
+}
+
+export type CalendarProvider = "google" | "apple";
+
+export interface ChangePasswordRequest {
+  current_password: string;
+  new_password: string;
+}
+
+export interface CommitAutomationResult {
+  /** Completed ID задач, выполненных по сообщениям коммитов */
+  completed?: string[];
+  /** Unresolved ссылки, которым не соответствует ровно одна задача пользователя */
+  unresolved?: string[];
+}
+
+export interface CompleteTasksRequest {
+  ids: string[];
+}
+
+export interface CompleteTasksResult {
+  /** Задачи, переведенные в статус done */
+  completed?: Task[];
+  /** ID задач, которые уже были выполнены */
+  skipped?: string[];
+}
+
+export interface Dashboard {
+  /** Количество задач со сроком на сегодня */
+  due_today?: number;
+  /** Дата и время формирования сводки */
+  generated_at?: string;
+  /** Количество просроченных задач */
+  overdue?: number;
+  /** Количество задач по статусам */
+  status_count?: Record<string, number>;
+  /** Общее количество задач */
+  total?: number;
+}
+
+export interface DataTransfer {
+  created_at?: string;
+  direction?: TransferDirection;
+  /** DurationMs длительность операции в миллисекундах */
+  duration_ms?: number;
+  /** Error текст ошибки неудачной операции */
+  error?: string;
+  format?: string;
+  id?: string;
+  /** Items число задач в запросе импорта или в выгрузке */
+  items?: number;
+  status?: TransferStatus;
+}
+
+export interface DeactivateUserRequest {
+  reason: string;
+}
+
+export type EventType = "task.created" | "task.updated" | "task.completed" | "task.deleted" | "tasks.completed" | "tasks.deleted";
+
+export type ExternalProvider = "github" | "jira";
+
+export interface ExternalRef {
+  checked_at?: string;
+  /** Closed задача закрыта: issue в состоянии closed, тикет Jira в категории статусов done */
+  closed?: boolean;
+  created_at?: string;
+  id?: string;
+  /** Key канонический ключ: owner/repo#123 для GitHub, PROJ-42 для Jira */
+  key?: string;
+  /** MirrorCompletion закрытие внешней задачи переводит задачу в статус done */
+  mirror_completion?: boolean;
+  provider?: ExternalProvider;
+  status?: string;
+  task_id?: string;
+  title?: string;
+  url?: string;
+}
+
+export interface FieldChange {
+  field?: string;
+  from?: string;
+  to?: string;
+}
+
+export interface GitHubRepoLink {
+  created_at?: string;
+  id?: string;
+  /** Repository полное имя репозитория owner/repo */
+  repository?: string;
+  /** Secret секрет webhook в настройках репозитория, возвращается только при подключении */
+  secret?: string;
+  /** WebhookPath путь, который указывается как Payload URL webhook в GitHub */
+  webhook_path?: string;
+}
+
+export interface GitHubRepoLinkRequest {
+  repository: string;
+}
+
+export interface HookDelivery {
+  task_id?: string;
+}
+
+export interface HookMapping {
+  /** DefaultPriority приоритет, если в запросе его нет или он не low/medium/high */
+  default_priority?: Priority;
+  description?: string;
+  due_date?: string;
+  priority?: string;
+  title?: string;
+}
+
+export interface ImpersonateRequest {
+  reason: string;
+}
+
+export interface Impersonation {
+  admin_id?: string;
+  created_at?: string;
+  expires_at?: string;
+  id?: string;
+  reason?: string;
+  revoked_at?: string;
+  user_id?: string;
+}
+
+export interface ImpersonationToken {
+  impersonation?: Impersonation;
+  token?: string;
+}
+
+export interface ImportPreview {
+  /** Create число задач, которые будут созданы */
+  create?: number;
+  /** DuplicateRows номера строк-дубликатов */
+  duplicate_rows?: number[];
+  /** Duplicates число строк, совпадающих с существующей задачей или строкой выше */
+  duplicates?: number;
+  errors?: ImportRowError[];
+  /** Invalid число строк с ошибками */
+  invalid?: number;
+  /** Mapping соответствие колонок файла полям задачи, пустое значение — колонка не используется */
+  mapping?: Record<string, string>;
+  /** Total число строк в файле */
+  total?: number;
+}
+
+export interface ImportReport {
+  /** Errors ошибки строк, не больше первой тысячи */
+  errors?: ImportRowError[];
+  /** Imported число созданных задач */
+  imported?: number;
+  /** Invalid число строк с ошибками */
+  invalid?: number;
+  /** Total число строк с данными в файле */
+  total?: number;
+}
+
+export interface ImportRowError {
+  field?: string;
+  message?: string;
+  row?: number;
+}
+
+export interface IncomingHook {
+  created_at?: string;
+  id?: string;
+  last_used_at?: string;
+  mapping?: HookMapping;
+  name?: string;
+  /** Token секрет URL, возвращается только при создании, в базе хранится его SHA-256 */
+  token?: string;
+  user_id?: string;
+}
+
+export interface IncomingHookRequest {
+  /** Mapping если не задан, используется DefaultHookMapping */
+  mapping?: HookMapping;
+  name: string;
+}
+
+export interface LinkExternalRequest {
+  mirror_completion?: boolean;
+  provider: ExternalProvider;
+  /** Ref ключ (owner/repo#123, PROJ-42) или ссылка на задачу трекера */
+  ref: string;
+}
+
+export interface LoginRequest {
+  email: string;
+  password: string;
+}
+
+export interface LoginResponse {
+  expires_at?: string;
+  expires_in?: number;
+  refresh_token?: string;
+  token?: string;
+  token_type?: string;
+  user?: UserProfile;
+}
+
+export interface MatrixSettings {
+  /** ImportantPriority наименьший приоритет важной задачи */
+  important_priority?: Priority;
+  /** UrgentWithinHours задача срочная, если до ее срока не больше стольких часов; просроченные срочные всегда */
+  urgent_within_hours?: number;
+}
+
+export type NotificationChannel = "push" | "email" | "slack" | "telegram";
+
+export interface NotificationPreferences {
+  /** Channels каналы доставки, пустой список отключает уведомления */
+  channels?: NotificationChannel[];
+  /** DigestWindowMinutes за сколько минут собирать уведомления в один дайджест, 0 — отправлять сразу */
+  digest_window_minutes?: number;
+  /** DueSoonWindowMinutes за сколько минут до срока напоминать о задаче */
+  due_soon_window_minutes?: number;
+  /** EventTypes события, о которых уведомлять; пустой список — все события */
+  event_types?: string[];
+  quiet_hours_end?: string;
+  /**
+   * QuietHoursStart и QuietHoursEnd тихие часы в формате HH:MM в часовом поясе Timezone.
+   * Уведомления в тихие часы откладываются до их окончания; пустые значения отключают тихие часы
+   */
+  quiet_hours_start?: string;
+  /** SlackWebhookURL Slack incoming webhook, в который уходят уведомления канала slack */
+  slack_webhook_url?: string;
+  /** Timezone часовой пояс IANA, например Europe/Moscow */
+  timezone?: string;
+}
+
+export interface NotificationPreviewRequest {
+  channel?: string;
+  event?: TaskEvent;
+  template?: string;
+}
+
+export type Priority = "low" | "medium" | "high";
+
+export interface Project {
+  created_at?: string;
+  description?: string;
+  id?: string;
+  name?: string;
+  updated_at?: string;
+  user_id?: string;
+}
+
+export interface ProjectRequest {
+  description?: string;
+  name: string;
+}
+
+export interface PushSubscription {
+  auth?: string;
+  created_at?: string;
+  endpoint?: string;
+  id?: string;
+  p256dh?: string;
+  user_id?: string;
+}
+
+export interface PushSubscriptionRequest {
+  endpoint?: string;
+  keys?: {
+    auth?: string;
+    p256dh?: string;
+  };
+}
+
+export interface PushUnsubscribeRequest {
+  endpoint?: string;
+}
+
+export interface QuickAddRequest {
+  /** Create создать задачу сразу; иначе задача только разбирается и возвращается для подтверждения */
+  create?: boolean;
+  text: string;
+  /** Timezone часовой пояс IANA для сроков в строке, пустой берется из настроек уведомлений */
+  timezone?: string;
+}
+
+export interface Recurrence {
+  created_at?: string;
+  id?: string;
+  /** LastTaskID последний экземпляр, из него создается следующий; пустой — серия завершена */
+  last_task_id?: string;
+  /** Occurrences сколько экземпляров создано, включая первый */
+  occurrences?: number;
+  paused?: boolean;
+  /** Rule правило повторения в каноническом виде RRULE */
+  rule?: string;
+  updated_at?: string;
+}
+
+export interface RegisterRequest {
+  email: string;
+  password: string;
+}
+
+export interface RelateTaskRequest {
+  task_id: string;
+}
+
+export interface SavedSearch {
+  /** Alert уведомлять о новых совпадениях */
+  alert?: boolean;
+  /**
+   * AlertIntervalMinutes не чаще одного уведомления за столько минут, 0 — о каждом совпадении.
+   * Совпадения внутри интервала не теряются: их число приходит в следующем уведомлении
+   */
+  alert_interval_minutes?: number;
+  created_at?: string;
+  id?: string;
+  last_alert_at?: string;
+  name?: string;
+  query?: string;
+  /** Timezone часовой пояс IANA, в котором читаются даты запроса (today, due<2024-07-01) */
+  timezone?: string;
+}
+
+export interface SavedSearchRequest {
+  alert?: boolean;
+  /** AlertIntervalMinutes по умолчанию 60 */
+  alert_interval_minutes?: number;
+  name: string;
+  query: string;
+  /** Timezone по умолчанию UTC */
+  timezone?: string;
+}
+
+export interface SetRoleRequest {
+  role: string;
+}
+
+export interface SimilarTask {
+  score?: number;
+  task?: Task;
+}
+
+export type Status = "pending" | "in_progress" | "done" | "overdue";
+
+export type TaggingField = "title" | "description" | "any";
+
+export type TaggingMatch = "keyword" | "regex";
+
+export interface TaggingRule {
+  /** CreatedAt правила применяются в порядке создания */
+  created_at?: string;
+  enabled?: boolean;
+  field?: TaggingField;
+  id?: string;
+  match?: TaggingMatch;
+  name?: string;
+  pattern?: string;
+  tags?: string[];
+  user_id?: string;
+}
+
+export interface TaggingRuleRequest {
+  enabled?: boolean;
+  /** Field по умолчанию any — заголовок и описание */
+  field?: TaggingField;
+  match?: TaggingMatch;
+  name: string;
+  pattern?: string;
+  tags?: string[];
+}
+
+export interface TaggingTestRequest {
+  description?: string;
+  /** Rule если задано, проверяется только это правило, а не сохраненные */
+  rule?: TaggingRuleRequest;
+  title?: string;
+}
+
+export interface TaggingTestResult {
+  matched?: TaggingRule[];
+  tags?: string[];
+}
+
+export interface Task {
+  /** AssigneeID исполнитель, которому владелец назначил задачу; меняется только через назначение */
+  assignee_id?: string;
+  completed_at?: string;
+  created_at?: string;
+  /** Description описание задачи, пустое значение хранится в БД как NULL */
+  description?: string;
+  due_date?: string;
+  id?: string;
+  /**
+   * IsOverdue задача не выполнена и срок прошел; сервис отмечает его по своим часам при отдаче задачи,
+   * в запросах игнорируется
+   */
+  is_overdue?: boolean;
+  /** Links ссылки задачи; nil при обновлении оставляет ссылки без изменений, пустой список удаляет их */
+  links?: TaskLink[];
+  /** Locked приватная задача отдана без расшифровки, для просмотра нужен unlock */
+  locked?: boolean;
+  /** Notes подробные заметки; nil при обновлении оставляет заметки без изменений, пустая строка удаляет их */
+  notes?: string;
+  /**
+   * ParentID родительская задача того же пользователя; nil при обновлении оставляет родителя без изменений,
+   * пустая строка делает задачу задачей верхнего уровня
+   */
+  parent_id?: string;
+  priority?: Priority;
+  /** Private заголовок и описание хранятся зашифрованными ключом владельца */
+  private?: boolean;
+  /**
+   * ProjectID проект пользователя; nil при обновлении оставляет проект без изменений,
+   * пустая строка убирает задачу из проекта
+   */
+  project_id?: string;
+  /** Recurrence правило повторения (RRULE), задается при создании и копируется в следующие экземпляры */
+  recurrence?: string;
+  /** RecurrenceID серия, к которой относится задача; назначается сервером */
+  recurrence_id?: string;
+  /** Related связанные задачи, заполняется только при expand=links */
+  related?: TaskSummary[];
+  status?: Status;
+  /**
+   * Tags теги задачи в нижнем регистре; nil при обновлении оставляет теги без изменений, пустой список удаляет их.
+   * Правила автотегирования только добавляют теги, поставленные вручную не снимаются
+   */
+  tags?: string[];
+  title?: string;
+  updated_at?: string;
+  user_id?: string;
+}
+
+export interface TaskChange {
+  /** Changes измененные поля для записей task.updated */
+  changes?: FieldChange[];
+  occurred_at?: string;
+  seq?: number;
+  task?: Task;
+  task_id?: string;
+  type?: EventType;
+  user_id?: string;
+  version?: number;
+}
+
+export interface TaskChangeFeed {
+  changes?: TaskChange[];
+  next?: number;
+}
+
+export interface TaskEvent {
+  changes?: FieldChange[];
+  occurred_at?: string;
+  task?: Task;
+  tasks?: Task[];
+  type?: EventType;
+  user_id?: string;
+}
+
+export interface TaskLink {
+  title?: string;
+  url?: string;
+}
+
+export interface TaskMatrix {
+  /** Delegate срочные, но не важные */
+  delegate?: Task[];
+  /** DoFirst срочные и важные */
+  do_first?: Task[];
+  /** Eliminate не срочные и не важные */
+  eliminate?: Task[];
+  /** Schedule важные, но не срочные */
+  schedule?: Task[];
+  /** Settings пороги, по которым построена матрица */
+  settings?: MatrixSettings;
+}
+
+export interface TaskPage {
+  /** NextCursor значение cursor для следующей страницы, у последней страницы отсутствует */
+  next_cursor?: string;
+  tasks?: Task[];
+  /** Total число задач, подходящих под фильтры, на всех страницах */
+  total?: number;
+}
+
+export interface TaskSummary {
+  due_date?: string;
+  id?: string;
+  locked?: boolean;
+  priority?: Priority;
+  private?: boolean;
+  status?: Status;
+  title?: string;
+}
+
+export interface TelegramLink {
+  chat_id?: number;
+  /**
+   * DailyDigestAt время ежедневной сводки задач на сегодня (HH:MM) в часовом поясе настроек уведомлений,
+   * пустое значение отключает сводку
+   */
+  daily_digest_at?: string;
+  linked_at?: string;
+}
+
+export interface TelegramLinkRequest {
+  chat_id: number;
+  daily_digest_at?: string;
+}
+
+export type TransferDirection = "import" | "export";
+
+export type TransferStatus = "completed" | "failed";
+
+export interface Trigger {
+  action?: TriggerAction;
+  created_at?: string;
+  enabled?: boolean;
+  /** Filter выражение вида `priority == high && title ~ "report"`, пустой фильтр пропускает все события */
+  filter?: string;
+  id?: string;
+  on?: EventType;
+  /** Secret ключ HMAC-подписи webhook, возвращается только при создании триггера */
+  secret?: string;
+  secret_rotated_at?: string;
+  user_id?: string;
+}
+
+export interface TriggerAction {
+  target?: string;
+  type?: TriggerActionType;
+}
+
+export type TriggerActionType = "webhook" | "slack" | "email";
+
+export interface TriggerRequest {
+  action?: TriggerAction;
+  enabled?: boolean;
+  filter?: string;
+  on?: EventType;
+}
+
+export interface UpdateTaskRequest {
+  /**
+   * CompleteSubtasks при переводе в done выполняет и все открытые подзадачи; без него
+   * задачу с открытыми подзадачами выполнить нельзя
+   */
+  complete_subtasks?: boolean;
+  description?: string;
+  due_date?: string;
+  links?: TaskLink[];
+  notes?: string;
+  /** ParentID переносит задачу под другую родительскую, пустая строка отвязывает ее от родителя */
+  parent_id?: string;
+  priority?: Priority;
+  /** Private включает шифрование; снять его с приватной задачи нельзя */
+  private?: boolean;
+  /** ProjectID переносит задачу в другой проект, пустая строка убирает ее из проекта */
+  project_id?: string;
+  status?: Status;
+  tags?: string[];
+  title?: string;
+}
+
+export interface UsageReport {
+  days?: number;
+  users?: UserUsage[];
+}
+
+export interface User {
+  /** Active false — учетная запись деактивирована администратором: вход и токены отклоняются */
+  active?: boolean;
+  created_at?: string;
+  deactivated_at?: string;
+  email?: string;
+  id?: string;
+  /** Role RoleUser или RoleAdmin, попадает в токен доступа */
+  role?: string;
+  updated_at?: string;
+}
+
+export interface UserProfile {
+  created_at?: string;
+  email?: string;
+  id?: string;
+}
+
+export interface UserUsage {
+  email?: string;
+  /** ErrorRate доля ответов с ошибкой, от 0 до 1 */
+  error_rate?: number;
+  errors?: number;
+  requests?: number;
+  user_id?: string;
+}
+
+export interface WebhookSecret {
+  /** PreviousSecretExpiresAt до этого момента запросы подписываются и предыдущим ключом */
+  previous_secret_expires_at?: string;
+  secret?: string;
+}
+
+export interface WhoAmI {
+  created_at?: string;
+  email?: string;
+  id?: string;
+  impersonator_id?: string;
+  roles?: string[];
+}
+
+export interface NotificationMessage {
+  html?: string;
+  subject?: string;
+  text?: string;
+}
+
+export interface SelfcheckReport {
+  checked_at?: string;
+  checks?: SelfcheckResult[];
+  status?: SelfcheckStatus;
+}
+
+export interface SelfcheckResult {
+  duration_ms?: number;
+  message?: string;
+  name?: string;
+  status?: SelfcheckStatus;
+}
+
+export type SelfcheckStatus = "ok" | "warn" | "fail";
+
+/** Параметры отдельного запроса */
+export interface RequestOptions {
+  /** Дополнительные заголовки, например Idempotency-Key */
+  headers?: Record<string, string>;
+  signal?: AbortSignal;
+}
+
+/** Ответ API с кодом не 2xx; message — поле error тела ответа */
+export class ApiError extends Error {
+  constructor(
+    readonly status: number,
+    readonly body: unknown,
+  ) {
+    super(
+      typeof body === "object" && body !== null && "error" in body
+        ? String((body as { error: unknown }).error)
+        : `HTTP ${status}`,
+    );
+    this.name = "ApiError";
+  }
+}
+
+export interface ClientOptions {
+  /** Токен доступа из ответа на вход */
+  token?: string;
+  fetch?: typeof fetch;
+}
+
+/** Клиент API менеджера задач */
+export class TaskManagerClient {
+  token?: string;
+  private readonly baseUrl: string;
+  private readonly fetchImpl: typeof fetch;
+
+  constructor(baseUrl = "", options: ClientOptions = {}) {
+    this.baseUrl = baseUrl.replace(/\/+$/, "");
+    this.token = options.token;
+    this.fetchImpl = options.fetch ?? globalThis.fetch.bind(globalThis);
+  }
+
+  protected async request<T>(
+    method: string,
+    path: string,
+    query?: object,
+    body?: unknown,
+    options: RequestOptions = {},
+  ): Promise<T> {
+    const params = new URLSearchParams();
+    for (const [key, value] of Object.entries(query ?? {})) {
+      if (value !== undefined && value !== null) {
+        params.append(key, String(value));
+      }
+    }
+    const search = params.toString();
+
+    const headers: Record<string, string> = {
+      Accept: "application/json",
+      "API-Version": String(API_VERSION),
+      ...options.headers,
+    };
+    if (this.token) {
+      headers.Authorization = `Bearer ${this.token}`;
+    }
+    let payload: string | undefined;
+    if (body !== undefined) {
+      headers["Content-Type"] = "application/json";
+      payload = JSON.stringify(body);
+    }
+
+    const response = await this.fetchImpl(this.baseUrl + path + (search ? `?${search}` : ""), {
+      method,
+      headers,
+      body: payload,
+      signal: options.signal,
+    });
+
+    const text = await response.text();
+    let data: unknown;
+    try {
+      data = text ? JSON.parse(text) : undefined;
+    } catch {
+      data = text;
+    }
+    if (!response.ok) {
+      throw new ApiError(response.status, data);
+    }
+    return data as T;
+  }
+
+  /** Get audit log */
+  getAuditLog(
+    query?: {
+      /** User the action was performed as */
+      actor_id?: string;
+      /** Admin who impersonated the user */
+      impersonator_id?: string;
+      /** Number of events (1-500) */
+      limit?: number;
+    },
+    options?: RequestOptions,
+  ): Promise<AuditEvent[]> {
+    return this.request("GET", `/api/admin/audit`, query, undefined, options);
+  }
+
+  /** Get the task change feed */
+  getTaskChangeFeed(
+    query?: {
+      /** Sequence number of the last received change */
+      after?: number;
+      /** Number of changes (1-1000) */
+      limit?: number;
+      /** Comma-separated redaction profiles, e.g. descriptions */
+      redact?: string;
+    },
+    options?: RequestOptions,
+  ): Promise<TaskChangeFeed> {
+    return this.request("GET", `/api/admin/changes`, query, undefined, options);
+  }
+
+  /** Impersonate a user */
+  impersonateUser(
+    userID: string | number,
+    body: ImpersonateRequest,
+    options?: RequestOptions,
+  ): Promise<ImpersonationToken> {
+    return this.request("POST", `/api/admin/impersonate/${encodeURIComponent(String(userID))}`, undefined, body, options);
+  }
+
+  /** Revoke an impersonation */
+  revokeImpersonation(
+    id: string | number,
+    options?: RequestOptions,
+  ): Promise<void> {
+    return this.request("DELETE", `/api/admin/impersonations/${encodeURIComponent(String(id))}`, undefined, undefined, options);
+  }
+
+  /** Preview a notification template */
+  previewNotificationTemplate(
+    body: NotificationPreviewRequest,
+    options?: RequestOptions,
+  ): Promise<NotificationMessage> {
+    return this.request("POST", `/api/admin/notifications/preview`, undefined, body, options);
+  }
+
+  /** Delete any task */
+  deleteAnyTask(
+    id: string | number,
+    options?: RequestOptions,
+  ): Promise<void> {
+    return this.request("DELETE", `/api/admin/tasks/${encodeURIComponent(String(id))}`, undefined, undefined, options);
+  }
+
+  /** Get API usage by user */
+  getAPIUsageByUser(
+    query?: {
+      /** Number of days (1-90) */
+      days?: number;
+      /** Number of users (1-100) */
+      limit?: number;
+      /** Comma-separated redaction profiles, e.g. emails */
+      redact?: string;
+    },
+    options?: RequestOptions,
+  ): Promise<UsageReport> {
+    return this.request("GET", `/api/admin/usage`, query, undefined, options);
+  }
+
+  /** List users */
+  listUsers(
+    query?: {
+      /** Page size, 1-500 */
+      limit?: number;
+      /** Number of users to skip */
+      offset?: number;
+      /** Comma-separated redaction profiles, e.g. emails */
+      redact?: string;
+    },
+    options?: RequestOptions,
+  ): Promise<User[]> {
+    return this.request("GET", `/api/admin/users`, query, undefined, options);
+  }
+
+  /** Deactivate a user */
+  deactivateUser(
+    id: string | number,
+    body: DeactivateUserRequest,
+    options?: RequestOptions,
+  ): Promise<void> {
+    return this.request("POST", `/api/admin/users/${encodeURIComponent(String(id))}/deactivate`, undefined, body, options);
+  }
+
+  /** Reactivate a user */
+  reactivateUser(
+    id: string | number,
+    options?: RequestOptions,
+  ): Promise<void> {
+    return this.request("POST", `/api/admin/users/${encodeURIComponent(String(id))}/reactivate`, undefined, undefined, options);
+  }
+
+  /** Set user role */
+  setUserRole(
+    id: string | number,
+    body: SetRoleRequest,
+    options?: RequestOptions,
+  ): Promise<User> {
+    return this.request("PUT", `/api/admin/users/${encodeURIComponent(String(id))}/role`, undefined, body, options);
+  }
+
+  /** Login user */
+  loginUser(
+    body: LoginRequest,
+    options?: RequestOptions,
+  ): Promise<LoginResponse> {
+    return this.request("POST", `/api/auth/login`, undefined, body, options);
+  }
+
+  /** Current user */
+  currentUser(
+    options?: RequestOptions,
+  ): Promise<WhoAmI> {
+    return this.request("GET", `/api/auth/me`, undefined, undefined, options);
+  }
+
+  /** Change password */
+  changePassword(
+    body: ChangePasswordRequest,
+    options?: RequestOptions,
+  ): Promise<void> {
+    return this.request("PUT", `/api/auth/password`, undefined, body, options);
+  }
+
+  /** Register a new user */
+  registerNewUser(
+    body: RegisterRequest,
+    options?: RequestOptions,
+  ): Promise<Record<string, unknown>> {
+    return this.request("POST", `/api/auth/register`, undefined, body, options);
+  }
+
+  /** Create a task from an external system */
+  createTaskFromExternalSystem(
+    token: string | number,
+    body: Record<string, unknown>,
+    options?: RequestOptions,
+  ): Promise<HookDelivery> {
+    return this.request("POST", `/api/hooks/${encodeURIComponent(String(token))}`, undefined, body, options);
+  }
+
+  /** List incoming webhooks */
+  listIncomingWebhooks(
+    options?: RequestOptions,
+  ): Promise<IncomingHook[]> {
+    return this.request("GET", `/api/inbound-hooks`, undefined, undefined, options);
+  }
+
+  /** Create an incoming webhook */
+  createIncomingWebhook(
+    body: IncomingHookRequest,
+    options?: RequestOptions,
+  ): Promise<IncomingHook> {
+    return this.request("POST", `/api/inbound-hooks`, undefined, body, options);
+  }
+
+  /** Delete an incoming webhook */
+  deleteIncomingWebhook(
+    id: string | number,
+    options?: RequestOptions,
+  ): Promise<void> {
+    return this.request("DELETE", `/api/inbound-hooks/${encodeURIComponent(String(id))}`, undefined, undefined, options);
+  }
+
+  /** List connected calendars */
+  listConnectedCalendars(
+    options?: RequestOptions,
+  ): Promise<CalendarLink[]> {
+    return this.request("GET", `/api/integrations/calendars`, undefined, undefined, options);
+  }
+
+  /** Connect a calendar */
+  connectCalendar(
+    body: CalendarLinkRequest,
+    options?: RequestOptions,
+  ): Promise<CalendarLink> {
+    return this.request("POST", `/api/integrations/calendars`, undefined, body, options);
+  }
+
+  /** Receive a Google Calendar notification */
+  receiveGoogleCalendarNotification(
+    options?: RequestOptions,
+  ): Promise<void> {
+    return this.request("POST", `/api/integrations/calendars/google/webhook`, undefined, undefined, options);
+  }
+
+  /** Disconnect a calendar */
+  disconnectCalendar(
+    id: string | number,
+    options?: RequestOptions,
+  ): Promise<void> {
+    return this.request("DELETE", `/api/integrations/calendars/${encodeURIComponent(String(id))}`, undefined, undefined, options);
+  }
+
+  /** List connected GitHub repositories */
+  listConnectedGitHubRepositories(
+    options?: RequestOptions,
+  ): Promise<GitHubRepoLink[]> {
+    return this.request("GET", `/api/integrations/github/repos`, undefined, undefined, options);
+  }
+
+  /** Connect a GitHub repository */
+  connectGitHubRepository(
+    body: GitHubRepoLinkRequest,
+    options?: RequestOptions,
+  ): Promise<GitHubRepoLink> {
+    return this.request("POST", `/api/integrations/github/repos`, undefined, body, options);
+  }
+
+  /** Disconnect a GitHub repository */
+  disconnectGitHubRepository(
+    id: string | number,
+    options?: RequestOptions,
+  ): Promise<void> {
+    return this.request("DELETE", `/api/integrations/github/repos/${encodeURIComponent(String(id))}`, undefined, undefined, options);
+  }
+
+  /** Receive a GitHub webhook */
+  receiveGitHubWebhook(
+    id: string | number,
+    options?: RequestOptions,
+  ): Promise<CommitAutomationResult> {
+    return this.request("POST", `/api/integrations/github/webhook/${encodeURIComponent(String(id))}`, undefined, undefined, options);
+  }
+
+  /** Get notification preferences */
+  getNotificationPreferences(
+    options?: RequestOptions,
+  ): Promise<NotificationPreferences> {
+    return this.request("GET", `/api/notifications/preferences`, undefined, undefined, options);
+  }
+
+  /** Update notification preferences */
+  updateNotificationPreferences(
+    body: NotificationPreferences,
+    options?: RequestOptions,
+  ): Promise<NotificationPreferences> {
+    return this.request("PUT", `/api/notifications/preferences`, undefined, body, options);
+  }
+
+  /** Subscribe to push notifications */
+  subscribeToPushNotifications(
+    body: PushSubscriptionRequest,
+    options?: RequestOptions,
+  ): Promise<PushSubscription> {
+    return this.request("POST", `/api/notifications/push/subscriptions`, undefined, body, options);
+  }
+
+  /** Unsubscribe from push notifications */
+  unsubscribeFromPushNotifications(
+    body: PushUnsubscribeRequest,
+    options?: RequestOptions,
+  ): Promise<void> {
+    return this.request("DELETE", `/api/notifications/push/subscriptions`, undefined, body, options);
+  }
+
+  /** Get VAPID public key */
+  getVAPIDPublicKey(
+    options?: RequestOptions,
+  ): Promise<Record<string, string>> {
+    return this.request("GET", `/api/notifications/push/vapid-key`, undefined, undefined, options);
+  }
+
+  /** Get the linked Telegram chat */
+  getLinkedTelegramChat(
+    options?: RequestOptions,
+  ): Promise<TelegramLink> {
+    return this.request("GET", `/api/notifications/telegram`, undefined, undefined, options);
+  }
+
+  /** Link a Telegram chat */
+  linkTelegramChat(
+    body: TelegramLinkRequest,
+    options?: RequestOptions,
+  ): Promise<TelegramLink> {
+    return this.request("PUT", `/api/notifications/telegram`, undefined, body, options);
+  }
+
+  /** Unlink the Telegram chat */
+  unlinkTelegramChat(
+    options?: RequestOptions,
+  ): Promise<void> {
+    return this.request("DELETE", `/api/notifications/telegram`, undefined, undefined, options);
+  }
+
+  /** List projects */
+  listProjects(
+    options?: RequestOptions,
+  ): Promise<Project[]> {
+    return this.request("GET", `/api/projects`, undefined, undefined, options);
+  }
+
+  /** Create a project */
+  createProject(
+    body: ProjectRequest,
+    options?: RequestOptions,
+  ): Promise<Project> {
+    return this.request("POST", `/api/projects`, undefined, body, options);
+  }
+
+  /** Get a project */
+  getProject(
+    id: string | number,
+    options?: RequestOptions,
+  ): Promise<Project> {
+    return this.request("GET", `/api/projects/${encodeURIComponent(String(id))}`, undefined, undefined, options);
+  }
+
+  /** Update a project */
+  updateProject(
+    id: string | number,
+    body: ProjectRequest,
+    options?: RequestOptions,
+  ): Promise<Project> {
+    return this.request("PUT", `/api/projects/${encodeURIComponent(String(id))}`, undefined, body, options);
+  }
+
+  /** Delete a project */
+  deleteProject(
+    id: string | number,
+    options?: RequestOptions,
+  ): Promise<void> {
+    return this.request("DELETE", `/api/projects/${encodeURIComponent(String(id))}`, undefined, undefined, options);
+  }
+
+  /** Get project analytics */
+  getProjectAnalytics(
+    id: string | number,
+    query?: {
+      /** Analytics period (day/week/month) */
+      period?: string;
+    },
+    options?: RequestOptions,
+  ): Promise<Analytics> {
+    return this.request("GET", `/api/projects/${encodeURIComponent(String(id))}/analytics`, query, undefined, options);
+  }
+
+  /** Get project tasks */
+  getProjectTasks(
+    id: string | number,
+    query?: {
+      /** Filter by status */
+      status?: string;
+      /** Filter by priority */
+      priority?: string;
+      /** Filter by tag */
+      tag?: string;
+      /** Set to smart to order by priority, due date proximity and age, or to due to order by due date and ID */
+      sort?: string;
+    },
+    options?: RequestOptions,
+  ): Promise<Task[]> {
+    return this.request("GET", `/api/projects/${encodeURIComponent(String(id))}/tasks`, query, undefined, options);
+  }
+
+  /** Get a recurrence series */
+  getRecurrenceSeries(
+    id: string | number,
+    options?: RequestOptions,
+  ): Promise<Recurrence> {
+    return this.request("GET", `/api/recurrences/${encodeURIComponent(String(id))}`, undefined, undefined, options);
+  }
+
+  /** Pause a recurrence series */
+  pauseRecurrenceSeries(
+    id: string | number,
+    options?: RequestOptions,
+  ): Promise<Recurrence> {
+    return this.request("POST", `/api/recurrences/${encodeURIComponent(String(id))}/pause`, undefined, undefined, options);
+  }
+
+  /** Resume a recurrence series */
+  resumeRecurrenceSeries(
+    id: string | number,
+    options?: RequestOptions,
+  ): Promise<Recurrence> {
+    return this.request("POST", `/api/recurrences/${encodeURIComponent(String(id))}/resume`, undefined, undefined, options);
+  }
+
+  /** List saved searches */
+  listSavedSearches(
+    options?: RequestOptions,
+  ): Promise<SavedSearch[]> {
+    return this.request("GET", `/api/saved-searches`, undefined, undefined, options);
+  }
+
+  /** Create a saved search */
+  createSavedSearch(
+    body: SavedSearchRequest,
+    options?: RequestOptions,
+  ): Promise<SavedSearch> {
+    return this.request("POST", `/api/saved-searches`, undefined, body, options);
+  }
+
+  /** Update a saved search */
+  updateSavedSearch(
+    id: string | number,
+    body: SavedSearchRequest,
+    options?: RequestOptions,
+  ): Promise<SavedSearch> {
+    return this.request("PUT", `/api/saved-searches/${encodeURIComponent(String(id))}`, undefined, body, options);
+  }
+
+  /** Delete a saved search */
+  deleteSavedSearch(
+    id: string | number,
+    options?: RequestOptions,
+  ): Promise<void> {
+    return this.request("DELETE", `/api/saved-searches/${encodeURIComponent(String(id))}`, undefined, undefined, options);
+  }
+
+  /** List tagging rules */
+  listTaggingRules(
+    options?: RequestOptions,
+  ): Promise<TaggingRule[]> {
+    return this.request("GET", `/api/tagging-rules`, undefined, undefined, options);
+  }
+
+  /** Create a tagging rule */
+  createTaggingRule(
+    body: TaggingRuleRequest,
+    options?: RequestOptions,
+  ): Promise<TaggingRule> {
+    return this.request("POST", `/api/tagging-rules`, undefined, body, options);
+  }
+
+  /** Dry-run tagging rules */
+  dryRunTaggingRules(
+    body: TaggingTestRequest,
+    options?: RequestOptions,
+  ): Promise<TaggingTestResult> {
+    return this.request("POST", `/api/tagging-rules/test`, undefined, body, options);
+  }
+
+  /** Delete a tagging rule */
+  deleteTaggingRule(
+    id: string | number,
+    options?: RequestOptions,
+  ): Promise<void> {
+    return this.request("DELETE", `/api/tagging-rules/${encodeURIComponent(String(id))}`, undefined, undefined, options);
+  }
+
+  /** Get task analytics */
+  getTaskAnalytics(
+    query?: {
+      /** Analytics period (day/week/month) */
+      period?: string;
+      /** Window start, inclusive (RFC3339 or YYYY-MM-DD, UTC); requires to */
+      from?: string;
+      /** Window end, exclusive (RFC3339 or YYYY-MM-DD, UTC); requires from */
+      to?: string;
+    },
+    options?: RequestOptions,
+  ): Promise<Analytics> {
+    return this.request("GET", `/api/task-analytics`, query, undefined, options);
+  }
+
+  /** Get analytics history */
+  getAnalyticsHistory(
+    query?: {
+      /** Number of days including today (1-365) */
+      days?: number;
+    },
+    options?: RequestOptions,
+  ): Promise<AnalyticsSnapshot[]> {
+    return this.request("GET", `/api/task-analytics/history`, query, undefined, options);
+  }
+
+  /** Export tasks */
+  exportTasks(
+    query?: {
+      /** Export format: json, csv or xlsx */
+      format?: string;
+      /** Comma-separated redaction profiles, e.g. descriptions,emails */
+      redact?: string;
+    },
+    options?: RequestOptions,
+  ): Promise<Task[]> {
+    return this.request("GET", `/api/task-exports`, query, undefined, options);
+  }
+
+  /** Import tasks */
+  importTasks(
+    body: Task[],
+    query?: {
+      /** Import valid rows of an uploaded file and report the rest */
+      skip_invalid?: boolean;
+    },
+    options?: RequestOptions,
+  ): Promise<ImportReport | Record<string, string>> {
+    return this.request("POST", `/api/task-imports`, query, body, options);
+  }
+
+  /** Preview task import */
+  previewTaskImport(
+    body: Task[],
+    options?: RequestOptions,
+  ): Promise<ImportPreview> {
+    return this.request("POST", `/api/task-imports/preview`, undefined, body, options);
+  }
+
+  /** Get all tasks */
+  getAllTasks(
+    query?: {
+      /** Filter by status; overdue selects open tasks whose due date has passed, the same tasks that have is_overdue set */
+      status?: string;
+      /** Filter by priority */
+      priority?: string;
+      /** Filter by due date (RFC3339 format) */
+      due_date?: string;
+      /** Search in title and description */
+      search?: string;
+      /** Search query, e.g. status:done priority:high due<2024-07-01 \ */
+      q?: string;
+      /** IANA timezone for dates in q, UTC by default */
+      tz?: string;
+      /** Filter by tag */
+      tag?: string;
+      /** Filter by project */
+      project_id?: string;
+      /** Set to me to list tasks of other users assigned to the current user instead of own tasks */
+      assignee?: string;
+      /** Set to smart to order by priority, due date proximity and age, or to due to order by due date and ID for cursor pagination */
+      sort?: string;
+      /** Set to links to include related task summaries */
+      expand?: string;
+      /** Page size, 1-500; without it all matching tasks are returned */
+      limit?: number;
+      /** Number of tasks to skip */
+      offset?: number;
+      /** Cursor: next_cursor or X-Next-Cursor of the previous page, implies sort=due */
+      cursor?: string;
+      /** Cursor: ID of the last task of the previous page, together with after_due */
+      after_id?: string;
+      /** Cursor: due date of the last task of the previous page (RFC3339), together with after_id */
+      after_due?: string;
+    },
+    options?: RequestOptions,
+  ): Promise<TaskPage> {
+    return this.request("GET", `/api/tasks`, query, undefined, options);
+  }
+
+  /** Create a new task */
+  createNewTask(
+    body: Task,
+    options?: RequestOptions,
+  ): Promise<Task> {
+    return this.request("POST", `/api/tasks`, undefined, body, options);
+  }
+
+  /** Delete tasks by filter */
+  deleteTasksByFilter(
+    query?: {
+      /** Filter by status */
+      status?: string;
+      /** Filter by priority */
+      priority?: string;
+      /** Filter by project */
+      project_id?: string;
+      /** Only tasks completed before this time (RFC3339 or YYYY-MM-DD, UTC) */
+      completed_before?: string;
+      /** Only tasks created before this time (RFC3339 or YYYY-MM-DD, UTC) */
+      created_before?: string;
+    },
+    options?: RequestOptions,
+  ): Promise<BulkDeleteProgress> {
+    return this.request("DELETE", `/api/tasks`, query, undefined, options);
+  }
+
+  /** Create a calendar subscription link */
+  createCalendarSubscriptionLink(
+    options?: RequestOptions,
+  ): Promise<CalendarFeed> {
+    return this.request("POST", `/api/tasks/calendar/token`, undefined, undefined, options);
+  }
+
+  /** Revoke the calendar subscription link */
+  revokeCalendarSubscriptionLink(
+    options?: RequestOptions,
+  ): Promise<void> {
+    return this.request("DELETE", `/api/tasks/calendar/token`, undefined, undefined, options);
+  }
+
+  /** Complete tasks in batch */
+  completeTasksInBatch(
+    body: CompleteTasksRequest,
+    options?: RequestOptions,
+  ): Promise<CompleteTasksResult> {
+    return this.request("POST", `/api/tasks/complete`, undefined, body, options);
+  }
+
+  /** Get task dashboard */
+  getTaskDashboard(
+    options?: RequestOptions,
+  ): Promise<Dashboard> {
+    return this.request("GET", `/api/tasks/dashboard`, undefined, undefined, options);
+  }
+
+  /** Get the priority matrix */
+  getPriorityMatrix(
+    options?: RequestOptions,
+  ): Promise<TaskMatrix> {
+    return this.request("GET", `/api/tasks/matrix`, undefined, undefined, options);
+  }
+
+  /** Get priority matrix settings */
+  getPriorityMatrixSettings(
+    options?: RequestOptions,
+  ): Promise<MatrixSettings> {
+    return this.request("GET", `/api/tasks/matrix/settings`, undefined, undefined, options);
+  }
+
+  /** Update priority matrix settings */
+  updatePriorityMatrixSettings(
+    body: MatrixSettings,
+    options?: RequestOptions,
+  ): Promise<MatrixSettings> {
+    return this.request("PUT", `/api/tasks/matrix/settings`, undefined, body, options);
+  }
+
+  /** Quick-add a task */
+  quickAddTask(
+    body: QuickAddRequest,
+    options?: RequestOptions,
+  ): Promise<Task> {
+    return this.request("POST", `/api/tasks/quick-add`, undefined, body, options);
+  }
+
+  /** Get today's tasks */
+  getTodaysTasks(
+    query?: {
+      /** IANA timezone, e.g. Europe/Moscow */
+      tz?: string;
+    },
+    options?: RequestOptions,
+  ): Promise<Task[]> {
+    return this.request("GET", `/api/tasks/today`, query, undefined, options);
+  }
+
+  /** Get upcoming tasks */
+  getUpcomingTasks(
+    query?: {
+      /** Number of days (1-30) */
+      days?: number;
+      /** IANA timezone, e.g. Europe/Moscow */
+      tz?: string;
+    },
+    options?: RequestOptions,
+  ): Promise<Task[]> {
+    return this.request("GET", `/api/tasks/upcoming`, query, undefined, options);
+  }
+
+  /** Get a task by ID */
+  getTaskByID(
+    id: string | number,
+    query?: {
+      /** Set to links to include related task summaries */
+      expand?: string;
+    },
+    options?: RequestOptions,
+  ): Promise<Task> {
+    return this.request("GET", `/api/tasks/${encodeURIComponent(String(id))}`, query, undefined, options);
+  }
+
+  /** Update a task */
+  updateTask(
+    id: string | number,
+    body: Task,
+    options?: RequestOptions,
+  ): Promise<Task> {
+    return this.request("PUT", `/api/tasks/${encodeURIComponent(String(id))}`, undefined, body, options);
+  }
+
+  /** Partially update a task */
+  partiallyUpdateTask(
+    id: string | number,
+    body: UpdateTaskRequest,
+    options?: RequestOptions,
+  ): Promise<Task> {
+    return this.request("PATCH", `/api/tasks/${encodeURIComponent(String(id))}`, undefined, body, options);
+  }
+
+  /** Delete a task */
+  deleteTask(
+    id: string | number,
+    options?: RequestOptions,
+  ): Promise<Record<string, string> | void> {
+    return this.request("DELETE", `/api/tasks/${encodeURIComponent(String(id))}`, undefined, undefined, options);
+  }
+
+  /** Assign a task */
+  assignTask(
+    id: string | number,
+    body: AssignTaskRequest,
+    options?: RequestOptions,
+  ): Promise<Task> {
+    return this.request("PUT", `/api/tasks/${encodeURIComponent(String(id))}/assignee`, undefined, body, options);
+  }
+
+  /** Unassign a task */
+  unassignTask(
+    id: string | number,
+    options?: RequestOptions,
+  ): Promise<Task> {
+    return this.request("DELETE", `/api/tasks/${encodeURIComponent(String(id))}/assignee`, undefined, undefined, options);
+  }
+
+  /** List external links of a task */
+  listExternalLinksOfTask(
+    id: string | number,
+    options?: RequestOptions,
+  ): Promise<ExternalRef[]> {
+    return this.request("GET", `/api/tasks/${encodeURIComponent(String(id))}/external`, undefined, undefined, options);
+  }
+
+  /** Link a task to a GitHub issue or Jira ticket */
+  linkTaskToGitHubIssueOrJiraTicket(
+    id: string | number,
+    body: LinkExternalRequest,
+    options?: RequestOptions,
+  ): Promise<ExternalRef> {
+    return this.request("POST", `/api/tasks/${encodeURIComponent(String(id))}/external`, undefined, body, options);
+  }
+
+  /** Unlink an external issue */
+  unlinkExternalIssue(
+    id: string | number,
+    ref_id: string | number,
+    options?: RequestOptions,
+  ): Promise<void> {
+    return this.request("DELETE", `/api/tasks/${encodeURIComponent(String(id))}/external/${encodeURIComponent(String(ref_id))}`, undefined, undefined, options);
+  }
+
+  /** Relate tasks */
+  relateTasks(
+    id: string | number,
+    body: RelateTaskRequest,
+    options?: RequestOptions,
+  ): Promise<void> {
+    return this.request("POST", `/api/tasks/${encodeURIComponent(String(id))}/related`, undefined, body, options);
+  }
+
+  /** Remove a task relation */
+  removeTaskRelation(
+    id: string | number,
+    related_id: string | number,
+    options?: RequestOptions,
+  ): Promise<void> {
+    return this.request("DELETE", `/api/tasks/${encodeURIComponent(String(id))}/related/${encodeURIComponent(String(related_id))}`, undefined, undefined, options);
+  }
+
+  /** List similar tasks */
+  listSimilarTasks(
+    id: string | number,
+    query?: {
+      /** Maximum number of tasks (1-20) */
+      limit?: number;
+    },
+    options?: RequestOptions,
+  ): Promise<SimilarTask[]> {
+    return this.request("GET", `/api/tasks/${encodeURIComponent(String(id))}/similar`, query, undefined, options);
+  }
+
+  /** List subtasks */
+  listSubtasks(
+    id: string | number,
+    options?: RequestOptions,
+  ): Promise<Task[]> {
+    return this.request("GET", `/api/tasks/${encodeURIComponent(String(id))}/subtasks`, undefined, undefined, options);
+  }
+
+  /** Suggest subtasks */
+  suggestSubtasks(
+    id: string | number,
+    query?: {
+      /** Generate a new result instead of the stored one */
+      refresh?: boolean;
+    },
+    options?: RequestOptions,
+  ): Promise<AIResult> {
+    return this.request("POST", `/api/tasks/${encodeURIComponent(String(id))}/suggest-subtasks`, query, undefined, options);
+  }
+
+  /** Summarize a task */
+  summarizeTask(
+    id: string | number,
+    query?: {
+      /** Generate a new result instead of the stored one */
+      refresh?: boolean;
+    },
+    options?: RequestOptions,
+  ): Promise<AIResult> {
+    return this.request("POST", `/api/tasks/${encodeURIComponent(String(id))}/summarize`, query, undefined, options);
+  }
+
+  /** Unlock a private task */
+  unlockPrivateTask(
+    id: string | number,
+    options?: RequestOptions,
+  ): Promise<Task> {
+    return this.request("POST", `/api/tasks/${encodeURIComponent(String(id))}/unlock`, undefined, undefined, options);
+  }
+
+  /** List triggers */
+  listTriggers(
+    options?: RequestOptions,
+  ): Promise<Trigger[]> {
+    return this.request("GET", `/api/triggers`, undefined, undefined, options);
+  }
+
+  /** Create a trigger */
+  createTrigger(
+    body: TriggerRequest,
+    options?: RequestOptions,
+  ): Promise<Trigger> {
+    return this.request("POST", `/api/triggers`, undefined, body, options);
+  }
+
+  /** Delete a trigger */
+  deleteTrigger(
+    id: string | number,
+    options?: RequestOptions,
+  ): Promise<void> {
+    return this.request("DELETE", `/api/triggers/${encodeURIComponent(String(id))}`, undefined, undefined, options);
+  }
+
+  /** Rotate a webhook signing secret */
+  rotateWebhookSigningSecret(
+    id: string | number,
+    options?: RequestOptions,
+  ): Promise<WebhookSecret> {
+    return this.request("POST", `/api/triggers/${encodeURIComponent(String(id))}/secret/rotate`, undefined, undefined, options);
+  }
+
+  /** Get import/export history */
+  getImportExportHistory(
+    query?: {
+      /** Number of records (1-200) */
+      limit?: number;
+    },
+    options?: RequestOptions,
+  ): Promise<DataTransfer[]> {
+    return this.request("GET", `/api/users/me/transfers`, query, undefined, options);
+  }
+}
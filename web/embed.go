@@ -0,0 +1,22 @@
+// Package web встроенная в бинарник сборка фронтенда
+package web
+
+import (
+	"embed"
+	"io/fs"
+)
+
+// dist результат сборки SPA; в репозитории лежит заглушка index.html,
+// перед сборкой бинарника ее заменяет сборка фронтенда
+//
+//go:embed all:dist
+var dist embed.FS
+
+// Dist файлы SPA с корнем в web/dist
+func Dist() fs.FS {
+	sub, err := fs.Sub(dist, "dist")
+	if err != nil {
+		panic(err)
+	}
+	return sub
+}
@@ -0,0 +1,6 @@
+// Package web сгенерированные артефакты для фронтенда: клиент API на TypeScript в client/.
+// После изменения моделей или аннотаций обработчиков: swag init -g cmd/app/main.go -o docs,
+// затем go generate ./web
+package web
+
+//go:generate go run ../cmd/tsgen -spec ../docs/swagger.json -ts client/api.ts -schemas ../docs/schemas